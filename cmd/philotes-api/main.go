@@ -5,6 +5,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -13,12 +14,19 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/alerting/channels"
 	"github.com/janovincze/philotes/internal/api"
 	"github.com/janovincze/philotes/internal/api/middleware"
 	"github.com/janovincze/philotes/internal/api/repositories"
 	"github.com/janovincze/philotes/internal/api/services"
 	"github.com/janovincze/philotes/internal/cdc/health"
 	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/crypto"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/query"
+	"github.com/janovincze/philotes/internal/query/duckdb"
+	"github.com/janovincze/philotes/internal/secrets"
 	"github.com/janovincze/philotes/internal/vault"
 )
 
@@ -40,6 +48,13 @@ func main() {
 		logger.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn("configuration warning", "warning", warning)
+	}
 
 	logger.Info("starting Philotes API",
 		"version", cfg.Version,
@@ -47,40 +62,59 @@ func main() {
 		"listen_addr", cfg.API.ListenAddr,
 	)
 
-	// Initialize secret provider (Vault or environment fallback)
-	vaultCfg := &vault.Config{
-		Enabled:               cfg.Vault.Enabled,
-		Address:               cfg.Vault.Address,
-		Namespace:             cfg.Vault.Namespace,
-		AuthMethod:            cfg.Vault.AuthMethod,
-		Role:                  cfg.Vault.Role,
-		TokenPath:             cfg.Vault.TokenPath,
-		Token:                 cfg.Vault.Token,
-		TLSSkipVerify:         cfg.Vault.TLSSkipVerify,
-		CACert:                cfg.Vault.CACert,
-		SecretMountPath:       cfg.Vault.SecretMountPath,
-		TokenRenewalInterval:  cfg.Vault.TokenRenewalInterval,
-		SecretRefreshInterval: cfg.Vault.SecretRefreshInterval,
-		FallbackToEnv:         cfg.Vault.FallbackToEnv,
-		SecretPaths: vault.SecretPaths{
-			DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
-			DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
-			StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+	// Initialize secret provider (Vault, AWS Secrets Manager or environment)
+	secretsCfg := secrets.Config{
+		Backend: cfg.Secrets.Backend,
+		Vault: vault.Config{
+			Address:               cfg.Vault.Address,
+			Namespace:             cfg.Vault.Namespace,
+			AuthMethod:            cfg.Vault.AuthMethod,
+			Role:                  cfg.Vault.Role,
+			TokenPath:             cfg.Vault.TokenPath,
+			Token:                 cfg.Vault.Token,
+			TLSSkipVerify:         cfg.Vault.TLSSkipVerify,
+			CACert:                cfg.Vault.CACert,
+			SecretMountPath:       cfg.Vault.SecretMountPath,
+			TokenRenewalInterval:  cfg.Vault.TokenRenewalInterval,
+			SecretRefreshInterval: cfg.Vault.SecretRefreshInterval,
+			FallbackToEnv:         cfg.Vault.FallbackToEnv,
+			SecretPaths: vault.SecretPaths{
+				DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
+				DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
+				StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+			},
+		},
+		AWS: secrets.AWSConfig{
+			Region:   cfg.Secrets.AWS.Region,
+			Endpoint: cfg.Secrets.AWS.Endpoint,
+			SecretIDs: vault.SecretPaths{
+				DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
+				DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
+				StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+			},
+			RefreshInterval: cfg.Secrets.AWS.RefreshInterval,
 		},
 	}
 
-	secretProvider, err := vault.NewSecretProvider(context.Background(), vaultCfg, logger)
+	secretProvider, err := secrets.New(context.Background(), secretsCfg, logger)
 	if err != nil {
 		logger.Error("failed to create secret provider", "error", err)
 		os.Exit(1)
 	}
 	defer secretProvider.Close()
 
-	// Get database password from secret provider if Vault is enabled
-	if cfg.Vault.Enabled {
+	// Resolve vault://path#key and env://NAME references in credential fields
+	secretResolver := vault.NewResolver(secretProvider)
+	if err := cfg.ResolveSecrets(context.Background(), secretResolver.Resolve); err != nil {
+		logger.Error("failed to resolve secret references", "error", err)
+		os.Exit(1)
+	}
+
+	// Get database password from the secret backend unless it is the environment
+	if cfg.Secrets.Backend != config.SecretBackendEnv {
 		dbPassword, err := secretProvider.GetDatabasePassword(context.Background())
 		if err != nil {
-			logger.Warn("failed to get database password from vault, using config value", "error", err)
+			logger.Warn("failed to get database password from secret backend, using config value", "error", err)
 		} else {
 			cfg.Database.Password = dbPassword
 		}
@@ -105,7 +139,7 @@ func main() {
 		logger.Error("failed to connect to database", "error", err)
 		os.Exit(1)
 	}
-	logger.Info("database connection established")
+	logger.Info("database connection established", "database", cfg.Database)
 
 	// Create repositories
 	sourceRepo := repositories.NewSourceRepository(db)
@@ -113,14 +147,103 @@ func main() {
 	userRepo := repositories.NewUserRepository(db)
 	apiKeyRepo := repositories.NewAPIKeyRepository(db)
 	auditRepo := repositories.NewAuditRepository(db)
+	auditRetentionRepo := repositories.NewAuditRetentionRepository(db)
+	deadLetterRepo := repositories.NewDeadLetterRepository(db)
+	alertRepo := repositories.NewAlertRepository(db)
+	savedQueryRepo := repositories.NewSavedQueryRepository(db)
+	queryHistoryRepo := repositories.NewQueryHistoryRepository(db)
+
+	// Alert instance changes are published to a hub that alert streams
+	// subscribe to
+	alertHub := alerting.NewHub()
+	alertRepo.SetEventHub(alertHub)
 
-	// Create services
+	// Source passwords are encrypted with the OAuth encryption key; those
+	// stored in plaintext, or with a previous key, are encrypted now
+	if cfg.OAuth.EncryptionKey != "" {
+		encryptor, err := crypto.NewEncryptorFromString(cfg.OAuth.EncryptionKey, cfg.OAuth.PreviousEncryptionKeys...)
+		if err != nil {
+			logger.Error("failed to create source password encryptor", "error", err)
+			os.Exit(1)
+		}
+		sourceRepo.SetEncryptor(encryptor)
+		encrypted, err := sourceRepo.EncryptPasswords(context.Background())
+		if err != nil {
+			logger.Warn("failed to encrypt stored source passwords", "error", err)
+		} else if encrypted > 0 {
+			logger.Info("stored source passwords encrypted", "sources", encrypted)
+		}
+	}
+
+	// Create services; stored source passwords and channel configs may hold
+	// secret references, resolved each time they are used
 	sourceService := services.NewSourceService(sourceRepo, logger)
+	sourceService.SetSecretResolver(secretResolver.Resolve)
+	sourceService.SetRequireSecretReferences(cfg.Secrets.RequireReferences)
+	defer sourceService.Close()
+	alertService := services.NewAlertService(alertRepo, logger)
+	alertService.SetConfigResolver(secretResolver.ResolveConfig)
+	alertService.SetEventHub(alertHub)
+	alertService.SetAuditRepository(auditRepo)
 	pipelineService := services.NewPipelineService(pipelineRepo, sourceRepo, logger)
+	pipelineService.SetSourceValidator(sourceService)
+	deadLetterService := services.NewDeadLetterService(deadLetterRepo, logger)
+
+	// Create table service for Iceberg metadata inspection
+	var tableService *services.TableService
+	if cfg.Iceberg.MetadataTablesEnabled {
+		icebergCatalog := catalog.NewRESTCatalog(catalog.Config{
+			CatalogURL: cfg.Iceberg.CatalogURL,
+			Warehouse:  cfg.Iceberg.Warehouse,
+		}, logger)
+		defer icebergCatalog.Close()
+		tableService = services.NewTableService(pipelineRepo, icebergCatalog, cfg.Iceberg, logger)
+	}
+
+	// Create audit retention service and start the cleanup job
+	auditRetentionService := services.NewAuditRetentionService(auditRetentionRepo, auditRepo, &cfg.Auth, logger)
+	auditRetentionService.Start(context.Background())
+	defer auditRetentionService.Stop()
+
+	// Create the audit service that records mutating API requests
+	auditService := services.NewAuditService(auditRepo, logger)
+
+	// Create the idempotency service for retried POST requests and start the
+	// cleanup of expired keys
+	var idempotencyService *services.IdempotencyService
+	if cfg.API.IdempotencyTTL > 0 {
+		idempotencyService = services.NewIdempotencyService(
+			repositories.NewIdempotencyRepository(db), cfg.API.IdempotencyTTL, logger)
+		idempotencyService.Start(context.Background())
+		defer idempotencyService.Stop()
+	}
+
+	// Upgrade stored OAuth tokens and OIDC client secrets to the primary
+	// encryption key once the key is rotated
+	if cfg.OAuth.EncryptionKey != "" && len(cfg.OAuth.PreviousEncryptionKeys) > 0 {
+		if err := reencryptSecrets(context.Background(), db, cfg, logger); err != nil {
+			logger.Warn("failed to re-encrypt stored secrets with the primary encryption key", "error", err)
+		}
+	}
+
+	// Create the OAuth service for cloud provider authentication and start
+	// the job that refreshes expiring tokens
+	var oauthService *services.OAuthService
+	if cfg.OAuth.Hetzner.Enabled || cfg.OAuth.OVH.Enabled || cfg.OAuth.Generic.Enabled {
+		oauthService, err = services.NewOAuthService(repositories.NewOAuthRepository(db), cfg.OAuth)
+		if err != nil {
+			logger.Error("failed to create oauth service", "error", err)
+			os.Exit(1)
+		}
+		oauthService.SetAuditRepository(auditRepo)
+		oauthService.Start(context.Background())
+		defer oauthService.Stop()
+	}
 
 	// Create auth services (only if auth is enabled or admin credentials are provided)
 	var authService *services.AuthService
 	var apiKeyService *services.APIKeyService
+	var tokenRevocationService *services.TokenRevocationService
 	if cfg.Auth.Enabled || cfg.Auth.AdminEmail != "" {
 		// Validate JWT secret when auth is enabled
 		if cfg.Auth.Enabled && len(cfg.Auth.JWTSecret) < 32 {
@@ -131,45 +254,168 @@ func main() {
 		authService = services.NewAuthService(userRepo, auditRepo, &cfg.Auth, logger)
 		apiKeyService = services.NewAPIKeyService(apiKeyRepo, userRepo, auditRepo, &cfg.Auth, logger)
 
+		// Revoked tokens are denied until they expire
+		tokenRevocationService = services.NewTokenRevocationService(
+			repositories.NewTokenRevocationRepository(db), cfg.Auth.JWTExpiration, logger)
+		tokenRevocationService.Start(context.Background())
+		defer tokenRevocationService.Stop()
+		authService.SetTokenRevocationService(tokenRevocationService)
+
 		// Bootstrap admin user if configured
 		if err := authService.BootstrapAdmin(context.Background()); err != nil {
 			logger.Warn("failed to bootstrap admin user", "error", err)
 		}
 	}
 
+	// Create the remote-write receiver and start its cleanup job
+	var remoteWriteService *services.RemoteWriteService
+	var sampleRepo *repositories.MetricSampleRepository
+	if cfg.Alerting.Enabled && cfg.Alerting.RemoteWrite.Enabled {
+		sampleRepo = repositories.NewMetricSampleRepository(db)
+		remoteWriteService = services.NewRemoteWriteService(sampleRepo, cfg.Alerting.RemoteWrite, logger)
+		remoteWriteService.Start(context.Background())
+		defer remoteWriteService.Stop()
+	}
+
+	// Start the alert manager
+	var alertManager *alerting.Manager
+	if cfg.Alerting.Enabled {
+		alertManager, err = alerting.NewManager(alertRepo, cfg.Alerting, logger)
+		if err != nil {
+			logger.Error("failed to create alert manager", "error", err)
+			os.Exit(1)
+		}
+		alertManager.SetChannelFactory(channels.Factory)
+		alertManager.SetConfigResolver(secretResolver.ResolveConfig)
+		if sampleRepo != nil {
+			alertManager.SetRemoteWriteProvider(alerting.NewRemoteWriteProvider(sampleRepo, cfg.Alerting.RemoteWrite.Staleness))
+		}
+		if err := alertManager.Start(context.Background()); err != nil {
+			logger.Error("failed to start alert manager", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := alertManager.Stop(); err != nil {
+				logger.Error("failed to stop alert manager", "error", err)
+			}
+		}()
+	}
+
 	// Create health manager
-	healthManager := health.NewManager(health.DefaultManagerConfig(), logger)
+	healthCfg := health.DefaultManagerConfig()
+	healthCfg.RunbookURL = cfg.CDC.Health.RunbookURL
+	healthManager := health.NewManager(healthCfg, logger)
 
 	// Register health checkers
 	healthManager.Register(health.NewComponentChecker("api", func(ctx context.Context) (health.Status, string, error) {
 		return health.StatusHealthy, "API server is running", nil
 	}))
-	healthManager.Register(health.NewComponentChecker("database", func(ctx context.Context) (health.Status, string, error) {
+	databaseChecker := health.NewComponentChecker("database", func(ctx context.Context) (health.Status, string, error) {
 		if err := db.PingContext(ctx); err != nil {
 			return health.StatusUnhealthy, "database connection failed", err
 		}
 		return health.StatusHealthy, "database connection OK", nil
-	}))
+	})
+	databaseChecker.SetComponent(health.ComponentDatabase)
+	healthManager.Register(databaseChecker)
 
-	// Register Vault health checker if enabled
-	if cfg.Vault.Enabled {
-		healthManager.Register(health.NewComponentChecker("vault", func(ctx context.Context) (health.Status, string, error) {
+	// Register secret backend health checker unless it is the environment
+	if cfg.Secrets.Backend != config.SecretBackendEnv {
+		secretsChecker := health.NewComponentChecker(cfg.Secrets.Backend, func(ctx context.Context) (health.Status, string, error) {
 			if err := secretProvider.Refresh(ctx); err != nil {
-				return health.StatusDegraded, "vault connection degraded", err
+				return health.StatusDegraded, cfg.Secrets.Backend + " connection degraded", err
 			}
-			return health.StatusHealthy, "vault connection OK", nil
-		}))
+			return health.StatusHealthy, cfg.Secrets.Backend + " connection OK", nil
+		})
+		secretsChecker.SetComponent(health.ComponentVault)
+		healthManager.Register(secretsChecker)
+	}
+
+	// Register the reachability of each source
+	sourceService.SetHealthManager(healthManager)
+	if err := sourceService.RegisterHealthChecks(context.Background()); err != nil {
+		logger.Warn("failed to register source health checks", "error", err)
 	}
 
+	// Create query service on the configured engine and register the
+	// engine's health checker if the query layer is enabled
+	var queryService *services.QueryService
+	switch cfg.Query.Engine {
+	case config.QueryEngineDuckDB:
+		duckdbEngine, err := duckdb.NewEngine(context.Background(), cfg.Query.DuckDB, cfg.Iceberg, cfg.Storage, logger)
+		if err != nil {
+			logger.Error("failed to create DuckDB query engine", "error", err)
+			os.Exit(1)
+		}
+		defer duckdbEngine.Close()
+		queryService = services.NewQueryService(cfg.Trino, logger)
+		queryService.SetEngine(duckdbEngine)
+	case config.QueryEngineTrino:
+		if cfg.Trino.Enabled {
+			queryService = services.NewQueryService(cfg.Trino, logger)
+		}
+	default:
+		logger.Error("unknown query engine", "engine", cfg.Query.Engine)
+		os.Exit(1)
+	}
+	if queryService != nil {
+		queryService.SetMaxResultRows(cfg.Query.MaxResultRows)
+		healthManager.Register(queryService.HealthChecker())
+	}
+
+	// Cache query results if configured; cached results of a table are
+	// dropped when its Iceberg snapshot changes
+	if queryService != nil && cfg.Query.Cache.TTL > 0 {
+		queryService.SetCache(query.NewCache(cfg.Query.Cache.TTL, cfg.Query.Cache.MaxEntries))
+		if cfg.Query.Cache.SnapshotInvalidation {
+			snapshotCatalog := catalog.NewRESTCatalog(catalog.Config{
+				CatalogURL: cfg.Iceberg.CatalogURL,
+				Warehouse:  cfg.Iceberg.Warehouse,
+			}, logger)
+			defer snapshotCatalog.Close()
+			queryService.SetSnapshotSource(services.NewCatalogSnapshotSource(snapshotCatalog))
+		}
+	}
+
+	// Saved queries can be managed without a query engine but only run with one
+	savedQueryService := services.NewSavedQueryService(savedQueryRepo, queryService, logger)
+
+	// Record executed statements unless the query history is disabled
+	var queryHistoryService *services.QueryHistoryService
+	if cfg.Query.History.Enabled {
+		queryHistoryService = services.NewQueryHistoryService(queryHistoryRepo, cfg.Query.History.RedactLiterals, logger)
+	}
+
+	// Create the status summary service; worker, buffer and lag figures
+	// come from Prometheus when it is configured
+	var statusMetrics services.MetricsQuerier
+	if cfg.Alerting.PrometheusURL != "" {
+		statusMetrics = services.NewPrometheusClient(cfg.Alerting.PrometheusURL, logger)
+	}
+	statusService := services.NewStatusService(pipelineRepo, healthManager, statusMetrics, logger)
+
 	// Create server configuration
 	serverCfg := api.ServerConfig{
-		Config:          cfg,
-		Logger:          logger,
-		HealthManager:   healthManager,
-		SourceService:   sourceService,
-		PipelineService: pipelineService,
-		AuthService:     authService,
-		APIKeyService:   apiKeyService,
+		Config:                 cfg,
+		Logger:                 logger,
+		HealthManager:          healthManager,
+		SourceService:          sourceService,
+		PipelineService:        pipelineService,
+		AuthService:            authService,
+		APIKeyService:          apiKeyService,
+		TokenRevocationService: tokenRevocationService,
+		IdempotencyService:     idempotencyService,
+		TableService:           tableService,
+		AuditRetentionService:  auditRetentionService,
+		AuditService:           auditService,
+		OAuthService:           oauthService,
+		AlertService:           alertService,
+		DeadLetterService:      deadLetterService,
+		StatusService:          statusService,
+		RemoteWriteService:     remoteWriteService,
+		QueryService:           queryService,
+		SavedQueryService:      savedQueryService,
+		QueryHistoryService:    queryHistoryService,
 		CORSConfig: middleware.CORSConfig{
 			AllowedOrigins:   cfg.API.CORSOrigins,
 			AllowCredentials: false,
@@ -179,6 +425,18 @@ func main() {
 			RequestsPerSecond: cfg.API.RateLimitRPS,
 			BurstSize:         cfg.API.RateLimitBurst,
 			PerClient:         true,
+			Overrides:         rateLimitOverrides(cfg.API.RateLimitOverrides),
+			APIKeyPrefix:      cfg.Auth.APIKeyPrefix,
+		},
+		CompressionConfig: middleware.CompressionConfig{
+			Enabled:       cfg.API.CompressionEnabled,
+			MinSize:       cfg.API.CompressionMinSize,
+			ExcludedPaths: []string{"/metrics"},
+		},
+		ETagConfig: middleware.ETagConfig{
+			Enabled:       cfg.API.ETagEnabled,
+			MaxSize:       middleware.DefaultETagConfig().MaxSize,
+			ExcludedPaths: []string{"/metrics"},
 		},
 	}
 
@@ -189,6 +447,16 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	// Apply the settings that can change at runtime on SIGHUP
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	go cfg.Watch(reloadCh, logger, func(reloaded *config.Config) {
+		server.ApplyConfig(reloaded)
+		if alertManager != nil {
+			alertManager.SetEvaluationInterval(reloaded.Alerting.EvaluationInterval)
+		}
+	})
+
 	// Start server in goroutine
 	errCh := make(chan error, 1)
 	go func() {
@@ -216,3 +484,40 @@ func main() {
 
 	logger.Info("server stopped")
 }
+
+// reencryptSecrets re-encrypts the stored secrets not encrypted with the
+// primary OAuth encryption key. OIDC client secrets share the OAuth keys
+// unless a separate OIDC key is configured.
+func reencryptSecrets(ctx context.Context, db *sql.DB, cfg *config.Config, logger *slog.Logger) error {
+	encryptor, err := crypto.NewEncryptorFromString(cfg.OAuth.EncryptionKey, cfg.OAuth.PreviousEncryptionKeys...)
+	if err != nil {
+		return fmt.Errorf("failed to create encryptor: %w", err)
+	}
+	oidcEncryptor := encryptor
+	if cfg.OIDC.EncryptionKey != "" {
+		if oidcEncryptor, err = crypto.NewEncryptorFromString(cfg.OIDC.EncryptionKey); err != nil {
+			return fmt.Errorf("failed to create oidc encryptor: %w", err)
+		}
+	}
+
+	rotation := services.NewKeyRotationService(
+		repositories.NewOAuthRepository(db),
+		repositories.NewOIDCRepository(db, oidcEncryptor),
+		encryptor,
+		logger,
+	)
+	return rotation.Reencrypt(ctx)
+}
+
+// rateLimitOverrides converts the configured route rate limits.
+func rateLimitOverrides(overrides []config.RateLimitOverride) []middleware.RateLimitOverride {
+	result := make([]middleware.RateLimitOverride, 0, len(overrides))
+	for _, o := range overrides {
+		result = append(result, middleware.RateLimitOverride{
+			PathPrefix:        o.PathPrefix,
+			RequestsPerSecond: o.RPS,
+			BurstSize:         o.Burst,
+		})
+	}
+	return result
+}
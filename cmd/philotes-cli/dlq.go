@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/client"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+const dlqUsage = `Usage:
+  philotes dlq list [--source <id>] [--since <time>] [--limit <n>] [--all] [--output table|json]
+  philotes dlq inspect <id> [--output table|json]
+  philotes dlq replay [--source <id>] [--since <time>] [--dry-run] [--output table|json]
+
+<time> is an RFC 3339 timestamp or a duration ago, such as 24h.`
+
+// cmdDLQ inspects and replays dead-letter events.
+func cmdDLQ(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Println(dlqUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return dlqList(args[1:])
+	case "inspect":
+		return dlqInspect(args[1:])
+	case "replay":
+		return dlqReplay(args[1:])
+	}
+	fmt.Fprintln(os.Stderr, dlqUsage)
+	return fmt.Errorf("unknown dlq command: %s", args[0])
+}
+
+func dlqList(args []string) error {
+	fs := flag.NewFlagSet("dlq list", flag.ContinueOnError)
+	sourceID := fs.String("source", "", "only list events of this source")
+	since := fs.String("since", "", "only list events dead-lettered since this time")
+	limit := fs.Int("limit", 0, "maximum number of events to list (default 100)")
+	all := fs.Bool("all", false, "include events that were already replayed")
+	output := addOutputFlag(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *sourceID != "" {
+		query.Set("source_id", *sourceID)
+	}
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		query.Set("since", t.Format(time.RFC3339))
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+	if *all {
+		query.Set("include_replayed", "true")
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	path := "/api/v1/dlq"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var resp models.DeadLetterListResponse
+	if err := c.Get(context.Background(), path, &resp); err != nil {
+		return fmt.Errorf("list dead-letter events: %w", err)
+	}
+
+	if *output == outputJSON {
+		return printJSON(resp)
+	}
+	if len(resp.Events) == 0 {
+		fmt.Println("No dead-letter events")
+		return nil
+	}
+
+	w := newTable()
+	printRow(w, "ID", "SOURCE", "TABLE", "OPERATION", "ERROR TYPE", "RETRIES", "CREATED", "REPLAYED")
+	for _, e := range resp.Events {
+		printRow(w, e.ID, e.SourceID, e.SchemaName+"."+e.TableName, e.Operation, e.ErrorType,
+			e.RetryCount, formatTime(&e.CreatedAt), formatTime(e.ReplayedAt))
+	}
+	return w.Flush()
+}
+
+func dlqInspect(args []string) error {
+	fs := flag.NewFlagSet("dlq inspect", flag.ContinueOnError)
+	output := addOutputFlag(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: philotes dlq inspect <id>")
+	}
+	id, err := strconv.ParseInt(positional[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid dead-letter event ID %q", positional[0])
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	var resp models.DeadLetterEventResponse
+	if err := c.Get(context.Background(), "/api/v1/dlq/"+strconv.FormatInt(id, 10), &resp); err != nil {
+		return describeAPIError(fmt.Errorf("inspect dead-letter event %d: %w", id, err), "dead-letter event", positional[0])
+	}
+
+	if *output == outputJSON {
+		return printJSON(resp.Event)
+	}
+
+	e := resp.Event
+	w := newTable()
+	printRow(w, "ID:", e.ID)
+	printRow(w, "Source:", e.SourceID)
+	printRow(w, "Table:", e.SchemaName+"."+e.TableName)
+	printRow(w, "Operation:", e.Operation)
+	printRow(w, "Error type:", e.ErrorType)
+	printRow(w, "Error:", e.ErrorMessage)
+	printRow(w, "Retries:", e.RetryCount)
+	printRow(w, "Created:", formatTime(&e.CreatedAt))
+	printRow(w, "Last retry:", formatTime(e.LastRetryAt))
+	printRow(w, "Expires:", formatTime(e.ExpiresAt))
+	printRow(w, "Replayed:", formatTime(e.ReplayedAt))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println("\nEvent:")
+	var data any
+	if err := json.Unmarshal(e.EventData, &data); err != nil {
+		fmt.Println(string(e.EventData))
+		return nil
+	}
+	return printJSON(data)
+}
+
+func dlqReplay(args []string) error {
+	fs := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+	sourceID := fs.String("source", "", "only replay events of this source")
+	since := fs.String("since", "", "only replay events dead-lettered since this time")
+	dryRun := fs.Bool("dry-run", false, "report how many events would be replayed without replaying them")
+	output := addOutputFlag(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	req := models.ReplayDeadLetterRequest{SourceID: *sourceID, DryRun: *dryRun}
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		req.Since = &t
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	var resp models.ReplayDeadLetterResponse
+	if err := c.Post(context.Background(), "/api/v1/dlq/replay", req, &resp); err != nil {
+		return fmt.Errorf("replay dead-letter events: %w", err)
+	}
+
+	if *output == outputJSON {
+		return printJSON(resp)
+	}
+	if resp.DryRun {
+		fmt.Printf("%d dead-letter events would be replayed\n", resp.Replayed)
+	} else {
+		fmt.Printf("Replayed %d dead-letter events into the buffer\n", resp.Replayed)
+	}
+	return nil
+}
+
+// parseSince parses an RFC 3339 timestamp or a duration before now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q (want an RFC 3339 timestamp or a duration such as 24h)", s)
+	}
+	return now.Add(-d), nil
+}
+
+// loadClient loads the CLI configuration and creates an API client.
+func loadClient() (*client.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	return newClient(cfg)
+}
+
+// describeAPIError rewrites 404 and 409 responses from the API as short
+// errors naming the resource; other errors are returned unchanged.
+func describeAPIError(err error, resource, id string) error {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%s %s not found", resource, id)
+	case http.StatusConflict:
+		if apiErr.Detail != "" {
+			return fmt.Errorf("conflict: %s", apiErr.Detail)
+		}
+		return fmt.Errorf("conflict: %s", apiErr.Title)
+	}
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/janovincze/philotes/internal/client"
 	"github.com/janovincze/philotes/internal/config"
 )
 
@@ -31,9 +32,11 @@ func run() error {
 	case "help", "-h", "--help":
 		printUsage()
 	case "status":
-		return cmdStatus()
+		return cmdStatus(os.Args[2:])
 	case "pipelines":
-		return cmdPipelines()
+		return cmdPipelines(os.Args[2:])
+	case "dlq":
+		return cmdDLQ(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
 		printUsage()
@@ -50,27 +53,26 @@ Usage:
 
 Commands:
   version     Show version information
-  status      Show system status
+  status      Show system status [--output table|json]
   pipelines   List and manage pipelines
+  dlq         Inspect and replay dead-letter events
   help        Show this help message
 
 Use "philotes <command> --help" for more information about a command.`)
 }
 
-func cmdStatus() error {
-	cfg, err := config.Load()
+// newClient creates an API client from the CLI configuration.
+func newClient(cfg *config.Config) (*client.Client, error) {
+	c, err := client.New(client.Config{
+		BaseURLs:            cfg.Client.Endpoints,
+		APIKey:              cfg.Client.APIKey,
+		Timeout:             cfg.Client.Timeout,
+		MaxAttempts:         cfg.Client.MaxAttempts,
+		HealthCheckInterval: cfg.Client.HealthCheckInterval,
+		HealthCheckTimeout:  cfg.Client.HealthCheckTimeout,
+	}, nil)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to create API client: %w", err)
 	}
-
-	fmt.Printf("Philotes Status\n")
-	fmt.Printf("---------------\n")
-	fmt.Printf("API URL: %s\n", cfg.API.BaseURL)
-	fmt.Println("Status check not yet implemented")
-	return nil
-}
-
-func cmdPipelines() error {
-	fmt.Println("Pipeline management not yet implemented")
-	return nil
+	return c, nil
 }
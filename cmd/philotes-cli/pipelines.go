@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+const pipelinesUsage = `Usage:
+  philotes pipelines list [--output table|json]
+  philotes pipelines create --name <name> --source <id> --tables <schema.table,...> [--output table|json]
+  philotes pipelines start <id>
+  philotes pipelines stop <id>
+
+Tables without a schema use the source's default schema.`
+
+// cmdPipelines lists, creates, starts and stops pipelines.
+func cmdPipelines(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Println(pipelinesUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return pipelinesList(args[1:])
+	case "create":
+		return pipelinesCreate(args[1:])
+	case "start":
+		return pipelinesAction(args[1:], "start", "started")
+	case "stop":
+		return pipelinesAction(args[1:], "stop", "stopped")
+	}
+	fmt.Fprintln(os.Stderr, pipelinesUsage)
+	return fmt.Errorf("unknown pipelines command: %s", args[0])
+}
+
+func pipelinesList(args []string) error {
+	fs := flag.NewFlagSet("pipelines list", flag.ContinueOnError)
+	output := addOutputFlag(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	var resp models.PipelineListResponse
+	if err := c.Get(context.Background(), "/api/v1/pipelines", &resp); err != nil {
+		return fmt.Errorf("list pipelines: %w", err)
+	}
+
+	if *output == outputJSON {
+		return printJSON(resp)
+	}
+	if len(resp.Pipelines) == 0 {
+		fmt.Println("No pipelines")
+		return nil
+	}
+
+	w := newTable()
+	printRow(w, "ID", "NAME", "SOURCE", "STATUS", "STARTED")
+	for i := range resp.Pipelines {
+		p := &resp.Pipelines[i]
+		status := string(p.Status)
+		if p.ErrorMessage != "" {
+			status += " (" + p.ErrorMessage + ")"
+		}
+		printRow(w, p.ID, p.Name, p.SourceID, status, formatTime(p.StartedAt))
+	}
+	return w.Flush()
+}
+
+func pipelinesCreate(args []string) error {
+	fs := flag.NewFlagSet("pipelines create", flag.ContinueOnError)
+	name := fs.String("name", "", "pipeline name (required)")
+	source := fs.String("source", "", "ID of the source to replicate from (required)")
+	tables := fs.String("tables", "", "comma-separated tables to replicate, as schema.table or table")
+	output := addOutputFlag(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if *name == "" || *source == "" {
+		return errors.New("usage: philotes pipelines create --name <name> --source <id> --tables <schema.table,...>")
+	}
+	sourceID, err := uuid.Parse(*source)
+	if err != nil {
+		return fmt.Errorf("invalid source ID %q", *source)
+	}
+
+	req := models.CreatePipelineRequest{Name: *name, SourceID: sourceID}
+	for _, t := range strings.Split(*tables, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		mapping := models.CreateTableMappingRequest{Table: t}
+		if schema, table, ok := strings.Cut(t, "."); ok {
+			mapping.Schema, mapping.Table = schema, table
+		}
+		req.Tables = append(req.Tables, mapping)
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	var resp models.PipelineResponse
+	if err := c.Post(context.Background(), "/api/v1/pipelines", req, &resp); err != nil {
+		return describeAPIError(fmt.Errorf("create pipeline: %w", err), "source", *source)
+	}
+
+	if *output == outputJSON {
+		return printJSON(resp.Pipeline)
+	}
+	fmt.Printf("Created pipeline %s (%s) with %d tables\n", resp.Pipeline.Name, resp.Pipeline.ID, len(resp.Pipeline.Tables))
+	return nil
+}
+
+// pipelinesAction starts or stops a pipeline.
+func pipelinesAction(args []string, action, done string) error {
+	fs := flag.NewFlagSet("pipelines "+action, flag.ContinueOnError)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: philotes pipelines %s <id>", action)
+	}
+	id, err := uuid.Parse(positional[0])
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID %q", positional[0])
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+
+	path := "/api/v1/pipelines/" + id.String() + "/" + action
+	if err := c.Post(context.Background(), path, nil, nil); err != nil {
+		return describeAPIError(fmt.Errorf("%s pipeline %s: %w", action, id, err), "pipeline", id.String())
+	}
+
+	fmt.Printf("Pipeline %s %s\n", id, done)
+	return nil
+}
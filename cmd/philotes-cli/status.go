@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// statusOutput is the JSON form of the status command.
+type statusOutput struct {
+	Endpoints []endpointOutput     `json:"endpoints"`
+	Status    *models.SystemStatus `json:"status,omitempty"`
+}
+
+// endpointOutput is the reachability of one configured API endpoint.
+type endpointOutput struct {
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// cmdStatus prints a summary of the deployment. It fails if no API
+// endpoint is reachable or the API reports the system unhealthy.
+func cmdStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	output := addOutputFlag(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	c, err := loadClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var out statusOutput
+	reachable := 0
+	for _, ep := range c.CheckHealth(ctx) {
+		out.Endpoints = append(out.Endpoints, endpointOutput{URL: ep.BaseURL, Reachable: ep.Healthy, Error: ep.Error})
+		if ep.Healthy {
+			reachable++
+		}
+	}
+
+	var statusErr error
+	if reachable == 0 {
+		statusErr = errors.New("API is unreachable")
+	} else {
+		var resp models.SystemStatusResponse
+		if err := c.Get(ctx, "/api/v1/status", &resp); err != nil {
+			statusErr = fmt.Errorf("get status: %w", err)
+		} else {
+			out.Status = &resp.Status
+			if resp.Status.Status == "unhealthy" {
+				statusErr = errors.New("system is unhealthy")
+			}
+		}
+	}
+
+	if *output == outputJSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return statusErr
+	}
+
+	w := newTable()
+	for _, ep := range out.Endpoints {
+		state := "reachable"
+		if !ep.Reachable {
+			state = "unreachable (" + ep.Error + ")"
+		}
+		printRow(w, "Endpoint", ep.URL+" "+state)
+	}
+	if s := out.Status; s != nil {
+		printRow(w, "Status", s.Status)
+		printRow(w, "API", s.API)
+		printRow(w, "Pipelines", fmt.Sprintf("%d (%d running, %d stopped, %d error)",
+			s.Pipelines.Total, s.Pipelines.Running, s.Pipelines.Stopped, s.Pipelines.Error))
+		workers := s.Workers.Status
+		if s.Workers.Total > 0 {
+			workers = fmt.Sprintf("%s (%d/%d up)", s.Workers.Status, s.Workers.Up, s.Workers.Total)
+		}
+		printRow(w, "Workers", workers)
+		printRow(w, "Buffer depth", formatOptional(s.BufferDepth, func(v int64) string { return fmt.Sprintf("%d events", v) }))
+		printRow(w, "Replication lag", formatOptional(s.ReplicationLagBytes, formatBytes))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return statusErr
+}
+
+// formatOptional formats v, or "unknown" when it is nil.
+func formatOptional(v *int64, format func(int64) string) string {
+	if v == nil {
+		return "unknown"
+	}
+	return format(*v)
+}
+
+// formatBytes renders a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
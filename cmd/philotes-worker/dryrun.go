@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/iceberg/writer"
+)
+
+// errDryRunFailed is returned when a dry-run check fails, so the worker
+// exits non-zero after printing the report.
+var errDryRunFailed = errors.New("dry run failed")
+
+// dryRunCheck is the outcome of one dry-run check.
+type dryRunCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// dryRunReport is printed by a dry run.
+type dryRunReport struct {
+	OK         bool          `json:"ok"`
+	Source     string        `json:"source"`
+	Namespaces []string      `json:"namespaces"`
+	Checks     []dryRunCheck `json:"checks"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   string        `json:"duration"`
+}
+
+// add records the outcome of a check.
+func (r *dryRunReport) add(name, detail string, err error) {
+	check := dryRunCheck{Name: name, OK: err == nil, Detail: detail}
+	if err != nil {
+		check.Error = err.Error()
+		r.OK = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// runDryRun checks that the source, buffer, catalog and storage are ready
+// for the configured pipeline, writes a JSON report to out and returns.
+// Nothing is created on the source and no replication is started.
+func runDryRun(ctx context.Context, cfg *config.Config, out io.Writer, logger *slog.Logger) error {
+	report := &dryRunReport{
+		OK:         true,
+		Source:     cfg.CDC.Source.SourceID(),
+		Namespaces: dryRunNamespaces(cfg.CDC.Replication.Tables),
+		StartedAt:  time.Now().UTC(),
+	}
+
+	// Source connection, slot and publication
+	reader, err := source.New(cfg.CDC.Source.Type, cfg, logger)
+	if err != nil {
+		report.add("source", "", fmt.Errorf("create source reader: %w", err))
+	} else if validator, ok := reader.(source.Validator); ok {
+		detail, err := validator.Validate(ctx)
+		report.add("source", detail, err)
+	} else {
+		report.add("source", fmt.Sprintf("source type %s cannot be validated", cfg.CDC.Source.Type), nil)
+	}
+
+	// Buffer database
+	if cfg.CDC.Buffer.Enabled {
+		report.add("buffer", "buffer database reachable", pingBuffer(ctx, cfg))
+	}
+
+	// Catalog and target namespaces
+	cat := catalog.NewRESTCatalog(catalog.Config{
+		CatalogURL: cfg.Iceberg.CatalogURL,
+		Warehouse:  cfg.Iceberg.Warehouse,
+	}, logger)
+	defer cat.Close()
+
+	for _, namespace := range report.Namespaces {
+		exists, err := cat.NamespaceExists(ctx, namespace)
+		detail := fmt.Sprintf("namespace %s exists", namespace)
+		if err == nil && !exists {
+			detail = fmt.Sprintf("namespace %s will be created", namespace)
+		}
+		report.add("catalog:"+namespace, detail, err)
+	}
+
+	// Storage: every namespace's data prefix must accept writes
+	storage, err := writer.NewObjectStore(objectStorageConfig(cfg.Storage), logger)
+	if err != nil {
+		report.add("storage", "", fmt.Errorf("create storage client: %w", err))
+	} else {
+		for _, namespace := range report.Namespaces {
+			key := fmt.Sprintf("warehouse/%s/_philotes_dry_run_%d", namespace, report.StartedAt.UnixNano())
+			err := probeStorage(ctx, storage, cfg.Storage.Bucket, key)
+			report.add("storage:"+namespace, storage.ObjectURL(cfg.Storage.Bucket, "warehouse/"+namespace)+" is writable", err)
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt).Round(time.Millisecond).String()
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("write dry run report: %w", err)
+	}
+
+	if !report.OK {
+		var failed []string
+		for _, check := range report.Checks {
+			if !check.OK {
+				failed = append(failed, check.Name)
+			}
+		}
+		return fmt.Errorf("%w: %s", errDryRunFailed, strings.Join(failed, ", "))
+	}
+	logger.Info("dry run passed", "checks", len(report.Checks))
+	return nil
+}
+
+// pingBuffer checks that the buffer database accepts connections.
+func pingBuffer(ctx context.Context, cfg *config.Config) error {
+	db, err := sql.Open("postgres", cfg.Database.DSN())
+	if err != nil {
+		return fmt.Errorf("open buffer database: %w", err)
+	}
+	defer db.Close()
+	return db.PingContext(ctx)
+}
+
+// probeStorage uploads and deletes a small object.
+func probeStorage(ctx context.Context, storage writer.ObjectStore, bucket, key string) error {
+	data := []byte("philotes dry run\n")
+	if err := storage.Upload(ctx, bucket, key, bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		return fmt.Errorf("upload probe object: %w", err)
+	}
+	if err := storage.Delete(ctx, bucket, key); err != nil {
+		return fmt.Errorf("delete probe object %s: %w", key, err)
+	}
+	return nil
+}
+
+// dryRunNamespaces returns the Iceberg namespaces the configured tables are
+// written to, one per source schema. With no tables configured every table
+// of the publication is replicated, so only the public schema is known.
+func dryRunNamespaces(tables []string) []string {
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		schema, _, ok := strings.Cut(table, ".")
+		if !ok {
+			schema = "public"
+		}
+		seen[schema] = true
+	}
+	if len(seen) == 0 {
+		seen["public"] = true
+	}
+
+	namespaces := make([]string, 0, len(seen))
+	for namespace := range seen {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -6,22 +6,40 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/janovincze/philotes/internal/cdc/buffer"
 	"github.com/janovincze/philotes/internal/cdc/checkpoint"
 	"github.com/janovincze/philotes/internal/cdc/deadletter"
 	"github.com/janovincze/philotes/internal/cdc/health"
+	"github.com/janovincze/philotes/internal/cdc/orphan"
 	"github.com/janovincze/philotes/internal/cdc/pipeline"
-	"github.com/janovincze/philotes/internal/cdc/source/postgres"
+	"github.com/janovincze/philotes/internal/cdc/shadow"
+	"github.com/janovincze/philotes/internal/cdc/sink"
+	"github.com/janovincze/philotes/internal/cdc/sink/kafka"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
+	"github.com/janovincze/philotes/internal/cdc/source"
+	_ "github.com/janovincze/philotes/internal/cdc/source/postgres" // registers the postgres source
+	"github.com/janovincze/philotes/internal/cdc/transform"
+	"github.com/janovincze/philotes/internal/cdc/verify"
 	"github.com/janovincze/philotes/internal/config"
 	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/iceberg/maintenance"
+	"github.com/janovincze/philotes/internal/iceberg/schema"
 	"github.com/janovincze/philotes/internal/iceberg/writer"
+	"github.com/janovincze/philotes/internal/metrics"
+	"github.com/janovincze/philotes/internal/secrets"
 	"github.com/janovincze/philotes/internal/vault"
 )
 
@@ -50,6 +68,13 @@ func main() {
 		logger.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn("configuration warning", "warning", warning)
+	}
 
 	if err := run(ctx, cfg, logger); err != nil {
 		logger.Error("worker failed", "error", err)
@@ -63,40 +88,58 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		"environment", cfg.Environment,
 	)
 
-	// Initialize secret provider (Vault or environment fallback)
-	vaultCfg := &vault.Config{
-		Enabled:               cfg.Vault.Enabled,
-		Address:               cfg.Vault.Address,
-		Namespace:             cfg.Vault.Namespace,
-		AuthMethod:            cfg.Vault.AuthMethod,
-		Role:                  cfg.Vault.Role,
-		TokenPath:             cfg.Vault.TokenPath,
-		Token:                 cfg.Vault.Token,
-		TLSSkipVerify:         cfg.Vault.TLSSkipVerify,
-		CACert:                cfg.Vault.CACert,
-		SecretMountPath:       cfg.Vault.SecretMountPath,
-		TokenRenewalInterval:  cfg.Vault.TokenRenewalInterval,
-		SecretRefreshInterval: cfg.Vault.SecretRefreshInterval,
-		FallbackToEnv:         cfg.Vault.FallbackToEnv,
-		SecretPaths: vault.SecretPaths{
-			DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
-			DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
-			StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+	// Initialize secret provider (Vault, AWS Secrets Manager or environment)
+	secretsCfg := secrets.Config{
+		Backend: cfg.Secrets.Backend,
+		Vault: vault.Config{
+			Address:               cfg.Vault.Address,
+			Namespace:             cfg.Vault.Namespace,
+			AuthMethod:            cfg.Vault.AuthMethod,
+			Role:                  cfg.Vault.Role,
+			TokenPath:             cfg.Vault.TokenPath,
+			Token:                 cfg.Vault.Token,
+			TLSSkipVerify:         cfg.Vault.TLSSkipVerify,
+			CACert:                cfg.Vault.CACert,
+			SecretMountPath:       cfg.Vault.SecretMountPath,
+			TokenRenewalInterval:  cfg.Vault.TokenRenewalInterval,
+			SecretRefreshInterval: cfg.Vault.SecretRefreshInterval,
+			FallbackToEnv:         cfg.Vault.FallbackToEnv,
+			SecretPaths: vault.SecretPaths{
+				DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
+				DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
+				StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+			},
+		},
+		AWS: secrets.AWSConfig{
+			Region:   cfg.Secrets.AWS.Region,
+			Endpoint: cfg.Secrets.AWS.Endpoint,
+			SecretIDs: vault.SecretPaths{
+				DatabaseBuffer: cfg.Vault.SecretPaths.DatabaseBuffer,
+				DatabaseSource: cfg.Vault.SecretPaths.DatabaseSource,
+				StorageMinio:   cfg.Vault.SecretPaths.StorageMinio,
+			},
+			RefreshInterval: cfg.Secrets.AWS.RefreshInterval,
 		},
 	}
 
-	secretProvider, err := vault.NewSecretProvider(ctx, vaultCfg, logger)
+	secretProvider, err := secrets.New(ctx, secretsCfg, logger)
 	if err != nil {
 		return fmt.Errorf("create secret provider: %w", err)
 	}
 	defer secretProvider.Close()
 
-	// Get secrets from Vault if enabled
-	if cfg.Vault.Enabled {
+	// Resolve vault://path#key and env://NAME references in credential fields
+	secretResolver := vault.NewResolver(secretProvider)
+	if err := cfg.ResolveSecrets(ctx, secretResolver.Resolve); err != nil {
+		return fmt.Errorf("resolve secret references: %w", err)
+	}
+
+	// Get secrets from the secret backend unless it is the environment
+	if cfg.Secrets.Backend != config.SecretBackendEnv {
 		// Get buffer database password
 		dbPassword, err := secretProvider.GetDatabasePassword(ctx)
 		if err != nil {
-			logger.Warn("failed to get database password from vault, using config value", "error", err)
+			logger.Warn("failed to get database password from secret backend, using config value", "error", err)
 		} else {
 			cfg.Database.Password = dbPassword
 		}
@@ -104,7 +147,7 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		// Get source database password
 		sourcePassword, err := secretProvider.GetSourcePassword(ctx)
 		if err != nil {
-			logger.Warn("failed to get source password from vault, using config value", "error", err)
+			logger.Warn("failed to get source password from secret backend, using config value", "error", err)
 		} else {
 			cfg.CDC.Source.Password = sourcePassword
 		}
@@ -112,15 +155,52 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		// Get storage credentials
 		accessKey, secretKey, err := secretProvider.GetStorageCredentials(ctx)
 		if err != nil {
-			logger.Warn("failed to get storage credentials from vault, using config values", "error", err)
+			logger.Warn("failed to get storage credentials from secret backend, using config values", "error", err)
 		} else {
 			cfg.Storage.AccessKey = accessKey
 			cfg.Storage.SecretKey = secretKey
 		}
 	}
 
+	// Validate the deployment and exit without replicating
+	if cfg.CDC.DryRun {
+		return runDryRun(ctx, cfg, os.Stdout, logger)
+	}
+
+	// Run as a shadow of the configured pipeline, or promote or discard one
+	var shadowCfg *shadow.Config
+	if cfg.CDC.Shadow.Enabled {
+		sc, action, err := newShadowConfig(cfg)
+		if err != nil {
+			return err
+		}
+		if action != shadow.ActionRun {
+			return runShadowAction(ctx, cfg, sc, action, logger)
+		}
+		shadowCfg = &sc
+		if !cfg.CDC.Checkpoint.Enabled {
+			return fmt.Errorf("shadow pipeline requires checkpointing to record which tables it snapshotted")
+		}
+
+		// Copy every table before streaming so a promoted shadow holds the
+		// existing rows too, not just the changes made while it ran. A
+		// restarted shadow only copies the tables it has not finished.
+		cfg.CDC.Snapshot.Enabled = true
+		cfg.CDC.Snapshot.Mode = string(snapshot.TriggerWhenNeeded)
+		cfg.CDC.Snapshot.Order = string(snapshot.OrderSnapshotFirst)
+		cfg.CDC.Verification.Mode = string(verify.ModeOff)
+	}
+
 	// Create health manager
-	healthMgr := health.NewManager(health.DefaultManagerConfig(), logger)
+	healthCfg := health.DefaultManagerConfig()
+	healthCfg.RunbookURL = cfg.CDC.Health.RunbookURL
+	healthMgr := health.NewManager(healthCfg, logger)
+
+	// Fail readiness as soon as shutdown starts, while the pipeline drains
+	go func() {
+		<-ctx.Done()
+		healthMgr.SetDraining()
+	}()
 
 	// Start health server if enabled
 	var healthServer *health.Server
@@ -141,30 +221,108 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		logger.Info("health server started", "addr", cfg.CDC.Health.ListenAddr)
 	}
 
-	// Register Vault health checker if enabled
-	if cfg.Vault.Enabled {
-		healthMgr.Register(health.NewComponentChecker("vault", func(ctx context.Context) (health.Status, string, error) {
+	// Start metrics server if enabled
+	if cfg.Metrics.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsServer := &http.Server{
+			Addr:              cfg.Metrics.ListenAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server failed", "error", err)
+			}
+		}()
+		defer metricsServer.Shutdown(context.Background())
+
+		logger.Info("metrics server started", "addr", cfg.Metrics.ListenAddr)
+	}
+
+	// Register secret backend health checker unless it is the environment
+	if cfg.Secrets.Backend != config.SecretBackendEnv {
+		secretsChecker := health.NewComponentChecker(cfg.Secrets.Backend, func(ctx context.Context) (health.Status, string, error) {
 			if err := secretProvider.Refresh(ctx); err != nil {
-				return health.StatusDegraded, "vault connection degraded", err
+				return health.StatusDegraded, cfg.Secrets.Backend + " connection degraded", err
 			}
-			return health.StatusHealthy, "vault connection OK", nil
-		}))
+			return health.StatusHealthy, cfg.Secrets.Backend + " connection OK", nil
+		})
+		secretsChecker.SetComponent(health.ComponentVault)
+		healthMgr.Register(secretsChecker)
 	}
 
-	// Create the PostgreSQL source reader
-	readerCfg := postgres.Config{
-		ConnectionURL:   cfg.CDC.Source.URL(),
-		SlotName:        cfg.CDC.Replication.SlotName,
-		PublicationName: cfg.CDC.Replication.PublicationName,
-		Tables:          cfg.CDC.Replication.Tables,
-		EventBufferSize: cfg.CDC.BufferSize,
+	// Register catalog health checks
+	healthCatalog := catalog.NewRESTCatalog(catalog.Config{
+		CatalogURL: cfg.Iceberg.CatalogURL,
+		Warehouse:  cfg.Iceberg.Warehouse,
+	}, logger)
+	defer healthCatalog.Close()
+
+	catalogChecker := health.NewComponentChecker("iceberg-catalog", func(ctx context.Context) (health.Status, string, error) {
+		if _, err := healthCatalog.NamespaceExists(ctx, "cdc"); err != nil {
+			return health.StatusUnhealthy, "iceberg catalog unavailable", err
+		}
+		return health.StatusHealthy, "iceberg catalog OK", nil
+	})
+	catalogChecker.SetComponent(health.ComponentCatalog)
+	healthMgr.Register(catalogChecker)
+
+	// Create the source reader through the source registry. A shadow reads
+	// from its own slot under its own source name.
+	srcCfg := *cfg
+	if shadowCfg != nil {
+		srcCfg.CDC.Replication.SlotName = shadowCfg.SlotName(cfg.CDC.Replication.SlotName)
+		srcCfg.CDC.Source.Name = shadowCfg.SourceName(cfg.CDC.Source.SourceID())
+		// Nothing else creates the shadow's slot, so always let the source do it
+		srcCfg.CDC.Replication.AutoCreateSlot = true
+		logger.Info("running as shadow pipeline",
+			"slot", srcCfg.CDC.Replication.SlotName,
+			"namespace_prefix", shadowCfg.NamespacePrefix,
+			"max_duration", shadowCfg.MaxDuration,
+		)
 	}
-	readerCfg.Name = fmt.Sprintf("postgres-%s", cfg.CDC.Source.Database)
+	slotName := srcCfg.CDC.Replication.SlotName
 
-	reader, err := postgres.New(readerCfg, logger)
+	reader, err := source.New(cfg.CDC.Source.Type, &srcCfg, logger)
 	if err != nil {
 		return fmt.Errorf("create source reader: %w", err)
 	}
+	sourceName := reader.Name()
+
+	// Make sure the slot and publication exist before anything inspects them
+	if bootstrapper, ok := reader.(source.Bootstrapper); ok {
+		if err := bootstrapper.Bootstrap(ctx); err != nil {
+			return fmt.Errorf("bootstrap source: %w", err)
+		}
+	}
+
+	if checker, ok := reader.(source.HealthChecker); ok {
+		sourceChecker := health.NewDatabaseChecker("source-database", checker.HealthCheck)
+		sourceChecker.SetComponent(health.ComponentSource)
+		healthMgr.Register(sourceChecker)
+	}
+
+	// Poll how far the slot trails the source and degrade when it falls behind
+	if reporter, ok := reader.(source.LagReporter); ok {
+		lagMonitor := source.NewLagMonitor(reporter, sourceName, cfg.CDC.Health.ReplicationLagInterval, logger)
+		go lagMonitor.Run(ctx)
+
+		lagThreshold := cfg.CDC.Health.ReplicationLagThreshold
+		lagChecker := health.NewComponentChecker("replication-lag", func(ctx context.Context) (health.Status, string, error) {
+			lag, err := lagMonitor.Lag()
+			if err != nil {
+				return health.StatusDegraded, "replication lag unavailable", err
+			}
+			if lagThreshold > 0 && lag > lagThreshold {
+				return health.StatusDegraded, fmt.Sprintf("replication lag %d bytes exceeds %d", lag, lagThreshold), nil
+			}
+			return health.StatusHealthy, fmt.Sprintf("replication lag %d bytes", lag), nil
+		})
+		lagChecker.SetComponent(health.ComponentSource)
+		healthMgr.Register(lagChecker)
+	}
 
 	// Create the checkpoint manager
 	var checkpointMgr checkpoint.Manager
@@ -182,12 +340,28 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		defer checkpointMgr.Close()
 	}
 
+	// Drop changes the slot redelivers after a reconnect
+	if cfg.CDC.Replication.DedupEnabled {
+		var hwmStore source.HighWaterMarkStore
+		if store, ok := checkpointMgr.(source.HighWaterMarkStore); ok {
+			hwmStore = store
+		} else {
+			logger.Warn("checkpointing is disabled, deduplication high-water-mark will not be persisted")
+		}
+		if dedup, ok := reader.(source.DeduplicatingSource); ok {
+			dedup.SetDeduplicator(source.NewDeduplicator(slotName, hwmStore))
+		} else {
+			logger.Warn("source does not support deduplication", "source_type", cfg.CDC.Source.Type)
+		}
+	}
+
 	// Create the buffer manager
 	var bufferMgr buffer.Manager
 	var db *sql.DB
 	if cfg.CDC.Buffer.Enabled {
 		bufCfg := buffer.Config{
 			Enabled:         true,
+			SourceID:        sourceName,
 			DSN:             cfg.Database.DSN(),
 			MaxOpenConns:    cfg.Database.MaxOpenConns,
 			MaxIdleConns:    cfg.Database.MaxIdleConns,
@@ -209,9 +383,11 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		defer db.Close()
 
 		// Register buffer health check
-		healthMgr.Register(health.NewDatabaseChecker("buffer-database", func(ctx context.Context) error {
+		bufferChecker := health.NewDatabaseChecker("buffer-database", func(ctx context.Context) error {
 			return db.PingContext(ctx)
-		}))
+		})
+		bufferChecker.SetComponent(health.ComponentBuffer)
+		healthMgr.Register(bufferChecker)
 	}
 
 	// Create the dead-letter queue manager if enabled
@@ -224,35 +400,245 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		logger.Info("dead-letter queue enabled", "retention", cfg.CDC.DeadLetter.Retention)
 	}
 
+	// Build the column name mapping and reject collisions before streaming starts
+	columnCase, err := schema.ParseCasePolicy(cfg.Iceberg.ColumnCase)
+	if err != nil {
+		return fmt.Errorf("parse column case policy: %w", err)
+	}
+	columnRenames, err := schema.ParseRenames(cfg.Iceberg.ColumnRenames)
+	if err != nil {
+		return fmt.Errorf("parse column renames: %w", err)
+	}
+	columnMapper, err := schema.NewColumnMapper(columnCase, columnRenames)
+	if err != nil {
+		return fmt.Errorf("create column mapper: %w", err)
+	}
+
+	// Limit table width and row size; wide tables are rejected before streaming
+	// starts unless they are split or overflowed
+	widthGuard, err := schema.NewWidthGuard(schema.WidthLimits{
+		Strategy:    schema.WidthStrategy(cfg.Iceberg.WideTableStrategy),
+		MaxColumns:  cfg.Iceberg.MaxColumns,
+		MaxRowBytes: cfg.Iceberg.MaxRowBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("create wide table guard: %w", err)
+	}
+
+	// Parse partitioning; referenced columns are checked against the source
+	// tables below
+	partitioning, err := schema.ParsePartitionRules(cfg.Iceberg.Partitioning)
+	if err != nil {
+		return fmt.Errorf("parse partitioning: %w", err)
+	}
+	if len(partitioning) == 0 {
+		partitioning = nil
+	}
+	tablePartitioning, err := schema.ParseTablePartitions(cfg.Iceberg.TablePartitioning)
+	if err != nil {
+		return fmt.Errorf("parse table partitioning: %w", err)
+	}
+	partitioned := partitioning != nil || len(tablePartitioning) > 0
+
+	deleteMode, err := writer.ParseDeleteMode(cfg.Iceberg.DeleteMode)
+	if err != nil {
+		return fmt.Errorf("parse delete mode: %w", err)
+	}
+	tableDeleteModes, err := writer.ParseTableDeleteModes(cfg.Iceberg.TableDeleteModes)
+	if err != nil {
+		return fmt.Errorf("parse table delete modes: %w", err)
+	}
+	timestampMode, err := writer.ParseTimestampMode(cfg.Iceberg.TimestampMode)
+	if err != nil {
+		return fmt.Errorf("parse timestamp mode: %w", err)
+	}
+	tableTimestampModes, err := writer.ParseTableTimestampModes(cfg.Iceberg.TableTimestampModes)
+	if err != nil {
+		return fmt.Errorf("parse table timestamp modes: %w", err)
+	}
+
+	if (!columnMapper.IsIdentity() || widthGuard.Enabled() || partitioned) && len(cfg.CDC.Replication.Tables) > 0 {
+		columnsDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+		if err != nil {
+			return fmt.Errorf("open source database for column checks: %w", err)
+		}
+		columnsReader := snapshot.NewPostgresReader(columnsDB)
+		for _, name := range cfg.CDC.Replication.Tables {
+			tableSchema, table, ok := strings.Cut(name, ".")
+			if !ok {
+				tableSchema, table = "public", name
+			}
+			columns, err := columnsReader.Columns(ctx, tableSchema, table)
+			if err == nil {
+				err = columnMapper.Check(columns)
+			}
+			if err != nil {
+				columnsDB.Close()
+				return fmt.Errorf("column mapping for %s: %w", name, err)
+			}
+
+			var keyColumns []string
+			if key, err := columnsReader.KeyColumn(ctx, tableSchema, table); err == nil {
+				keyColumns = []string{key}
+			}
+			if err := widthGuard.Check(tableSchema+"."+table, columns, keyColumns); err != nil {
+				columnsDB.Close()
+				return err
+			}
+
+			rules, ok := tablePartitioning[tableSchema+"."+table]
+			if !ok {
+				rules = partitioning
+			}
+			mapped := make([]string, len(columns))
+			for i, column := range columns {
+				mapped[i] = columnMapper.Name(column)
+			}
+			if err := schema.CheckPartitionColumns(rules, mapped); err != nil {
+				columnsDB.Close()
+				return fmt.Errorf("partitioning for %s: %w", name, err)
+			}
+		}
+		columnsDB.Close()
+		if !columnMapper.IsIdentity() {
+			logger.Info("column mapping enabled", "case", columnCase, "renames", len(columnRenames))
+		}
+		for _, layout := range widthGuard.Layouts() {
+			logger.Warn("wide table", "strategy", layout.Strategy, "layout", layout.String())
+		}
+	}
+
+	// Report how wide tables are written alongside the pipeline status
+	widthChecker := health.NewComponentChecker("wide-tables", func(context.Context) (health.Status, string, error) {
+		layouts := widthGuard.Layouts()
+		if len(layouts) == 0 {
+			return health.StatusHealthy, fmt.Sprintf("strategy %s, no wide tables", widthGuard.Strategy()), nil
+		}
+		described := make([]string, len(layouts))
+		for i, layout := range layouts {
+			described[i] = layout.String()
+		}
+		return health.StatusHealthy, fmt.Sprintf("strategy %s: %s", widthGuard.Strategy(), strings.Join(described, "; ")), nil
+	})
+	healthMgr.Register(widthChecker)
+
 	// Create the Iceberg writer and batch processor if buffering is enabled
 	var batchProcessor *buffer.BatchProcessor
+	var flushLatency *buffer.LatencyTracker
 	if cfg.CDC.Buffer.Enabled && bufferMgr != nil {
+		sinkTypes, err := sink.ParseTypes(cfg.CDC.Sinks)
+		if err != nil {
+			return fmt.Errorf("parse sinks: %w", err)
+		}
+		var sinks []sink.Sink
+
 		// Create Iceberg writer
 		writerCfg := writer.Config{
 			Catalog: catalog.Config{
 				CatalogURL: cfg.Iceberg.CatalogURL,
 				Warehouse:  cfg.Iceberg.Warehouse,
 			},
-			S3: writer.S3Config{
-				Endpoint:  cfg.Storage.Endpoint,
-				AccessKey: cfg.Storage.AccessKey,
-				SecretKey: cfg.Storage.SecretKey,
-				UseSSL:    cfg.Storage.UseSSL,
+			Storage:             objectStorageConfig(cfg.Storage),
+			Bucket:              cfg.Storage.Bucket,
+			WarehousePath:       "warehouse",
+			DefaultNamespace:    "cdc",
+			Branch:              cfg.Iceberg.Branch,
+			ColumnMapper:        columnMapper,
+			Width:               widthGuard,
+			Partitioning:        partitioning,
+			TablePartitioning:   tablePartitioning,
+			DeleteMode:          deleteMode,
+			TableDeleteModes:    tableDeleteModes,
+			TimestampMode:       timestampMode,
+			TableTimestampModes: tableTimestampModes,
+			CommitRetry: catalog.CommitRetryConfig{
+				MaxRetries:     cfg.Iceberg.CommitMaxRetries,
+				InitialBackoff: cfg.Iceberg.CommitRetryBackoff,
+				MaxBackoff:     cfg.Iceberg.CommitRetryMaxBackoff,
 			},
-			Bucket:           cfg.Storage.Bucket,
-			WarehousePath:    "warehouse",
-			DefaultNamespace: "cdc",
 		}
 
-		icebergWriter, err := writer.NewIcebergWriter(writerCfg, logger)
+		if shadowCfg != nil {
+			writerCfg.NamespacePrefix = shadowCfg.NamespacePrefix
+		}
+
+		if slices.Contains(sinkTypes, sink.TypeIceberg) {
+			// Look up source column types so numeric columns keep their
+			// precision and scale as Iceberg decimals and timestamps their
+			// zone
+			typesDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+			if err != nil {
+				return fmt.Errorf("open source database for column types: %w", err)
+			}
+			defer typesDB.Close()
+			writerCfg.SourceColumns = snapshot.NewPostgresReader(typesDB)
+			writerCfg.Decimals = schema.DecimalMapping{
+				DefaultPrecision: cfg.Iceberg.NumericPrecision,
+				DefaultScale:     cfg.Iceberg.NumericScale,
+			}
+
+			icebergWriter, err := writer.NewIcebergWriter(writerCfg, logger)
+			if err != nil {
+				return fmt.Errorf("create iceberg writer: %w", err)
+			}
+			defer icebergWriter.Close()
+			// Time Iceberg writes for the flush latency backpressure signal
+			flushLatency = buffer.NewLatencyTracker(cfg.CDC.Backpressure.LatencyWindow)
+			sinks = append(sinks, sink.Sink{Name: sink.TypeIceberg, Handler: flushLatency.Wrap(writer.BatchHandler(icebergWriter))})
+		}
+
+		// Publish the same batches to Kafka for consumers outside Iceberg
+		if slices.Contains(sinkTypes, sink.TypeKafka) {
+			kafkaCfg := kafka.DefaultConfig()
+			kafkaCfg.SourceID = sourceName
+			kafkaCfg.Brokers = cfg.Kafka.Brokers
+			kafkaCfg.TopicTemplate = cfg.Kafka.TopicTemplate
+			kafkaCfg.SchemaRegistryURL = cfg.Kafka.SchemaRegistryURL
+			kafkaCfg.ClientID = cfg.Kafka.ClientID
+			kafkaCfg.RequiredAcks = cfg.Kafka.RequiredAcks
+			kafkaCfg.Timeout = cfg.Kafka.Timeout
+			kafkaCfg.Compression = cfg.Kafka.Compression
+			kafkaCfg.Idempotent = cfg.Kafka.Idempotent
+			kafkaCfg.TLS = kafka.TLSConfig{
+				Enabled:    cfg.Kafka.TLSEnabled,
+				CAFile:     cfg.Kafka.TLSCAFile,
+				CertFile:   cfg.Kafka.TLSCertFile,
+				KeyFile:    cfg.Kafka.TLSKeyFile,
+				SkipVerify: cfg.Kafka.TLSSkipVerify,
+			}
+			kafkaCfg.SASL = kafka.SASLConfig{
+				Mechanism: cfg.Kafka.SASLMechanism,
+				Username:  cfg.Kafka.SASLUsername,
+				Password:  cfg.Kafka.SASLPassword,
+			}
+			kafkaCfg.SchemaRegistryUsername = cfg.Kafka.SchemaRegistryUsername
+			kafkaCfg.SchemaRegistryPassword = cfg.Kafka.SchemaRegistryPassword
+
+			kafkaSink, err := kafka.NewSink(kafkaCfg, logger)
+			if err != nil {
+				return fmt.Errorf("create kafka sink: %w", err)
+			}
+			defer kafkaSink.Close()
+			sinks = append(sinks, sink.Sink{Name: sink.TypeKafka, Handler: kafka.BatchHandler(kafkaSink)})
+
+			logger.Info("Kafka sink configured",
+				"brokers", kafkaCfg.Brokers,
+				"topic_template", kafkaCfg.TopicTemplate,
+				"avro", kafkaCfg.SchemaRegistryURL != "",
+				"compression", kafkaCfg.Compression,
+				"tls", kafkaCfg.TLS.Enabled,
+				"sasl", kafkaCfg.SASL.Mechanism,
+			)
+		}
+
+		tableOverrides, err := buffer.ParseTableOverrides(cfg.CDC.TableOverrides)
 		if err != nil {
-			return fmt.Errorf("create iceberg writer: %w", err)
+			return err
 		}
-		defer icebergWriter.Close()
 
-		// Create batch processor with Iceberg handler
+		// Create batch processor writing to every enabled sink
 		batchCfg := buffer.BatchConfig{
-			SourceID:             fmt.Sprintf("postgres-%s", cfg.CDC.Source.Database),
+			SourceID:             sourceName,
 			BatchSize:            cfg.CDC.BatchSize,
 			FlushInterval:        cfg.CDC.FlushInterval,
 			Retention:            cfg.CDC.Buffer.Retention,
@@ -263,11 +649,33 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 			RetryMultiplier:      cfg.CDC.Retry.Multiplier,
 			DLQEnabled:           cfg.CDC.DeadLetter.Enabled,
 			DLQRetention:         cfg.CDC.DeadLetter.Retention,
+			Priority:             cfg.CDC.Priority,
+			TableOverrides:       tableOverrides,
+			MaxParallelTables:    cfg.CDC.MaxParallelTables,
+		}
+
+		// Flag non-unique source keys before rows reach Iceberg
+		keyConflictPolicy, err := buffer.ParseKeyConflictPolicy(cfg.CDC.KeyConflictPolicy)
+		if err != nil {
+			return fmt.Errorf("parse key conflict policy: %w", err)
+		}
+		if keyConflictPolicy.DropsRows() {
+			// Every sink appends a change log, so dropping a version would
+			// lose a real source insert rather than resolve an upsert
+			logger.Warn("key conflict policy needs an upsert sink; only detecting conflicts",
+				"policy", keyConflictPolicy,
+				"sinks", cfg.CDC.Sinks,
+			)
+			keyConflictPolicy = buffer.KeyConflictDetect
+		}
+		keyConflicts, err := buffer.NewKeyConflictHandler(keyConflictPolicy, sourceName, dlqMgr, cfg.CDC.DeadLetter.Retention, logger)
+		if err != nil {
+			return fmt.Errorf("create key conflict handler: %w", err)
 		}
 
 		batchProcessor = buffer.NewBatchProcessor(
 			bufferMgr,
-			writer.BatchHandler(icebergWriter),
+			keyConflicts.Wrap(sink.Tee(sourceName, sinks, logger)),
 			batchCfg,
 			logger,
 		)
@@ -277,21 +685,38 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 			batchProcessor.SetDeadLetterManager(dlqMgr)
 		}
 
+		// Record the buffer state after each cleanup for the API
+		if cfg.CDC.PipelineID != "" {
+			batchProcessor.SetCleanupStatsRecorder(buffer.NewPostgresCleanupStatsRecorder(db, cfg.CDC.PipelineID))
+		}
+
+		// Share writer slots by priority if configured
+		if cfg.CDC.WriterSlots > 0 {
+			batchProcessor.SetScheduler(buffer.NewScheduler(cfg.CDC.WriterSlots))
+		}
+
 		// Start the batch processor
 		if err := batchProcessor.Start(ctx); err != nil {
 			return fmt.Errorf("start batch processor: %w", err)
 		}
 		defer batchProcessor.Stop(context.Background())
 
-		logger.Info("Iceberg writer configured",
+		logger.Info("batch processor configured",
+			"sinks", sinkTypes,
 			"catalog_url", cfg.Iceberg.CatalogURL,
 			"warehouse", cfg.Iceberg.Warehouse,
+			"storage_provider", cfg.Storage.Provider,
 			"storage_endpoint", cfg.Storage.Endpoint,
 			"bucket", cfg.Storage.Bucket,
 		)
 	}
 
 	// Create and run the pipeline
+	gapPolicy, err := pipeline.ParseGapPolicy(cfg.CDC.Replication.GapPolicy)
+	if err != nil {
+		return fmt.Errorf("parse gap policy: %w", err)
+	}
+
 	pipelineCfg := pipeline.Config{
 		CheckpointInterval: cfg.CDC.Checkpoint.Interval,
 		CheckpointEnabled:  cfg.CDC.Checkpoint.Enabled,
@@ -304,23 +729,46 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 			Jitter:          true,
 		},
 		BackpressureConfig: pipeline.BackpressureConfig{
-			Enabled:       cfg.CDC.Backpressure.Enabled,
-			HighWatermark: cfg.CDC.Backpressure.HighWatermark,
-			LowWatermark:  cfg.CDC.Backpressure.LowWatermark,
-			CheckInterval: cfg.CDC.Backpressure.CheckInterval,
+			Enabled:              cfg.CDC.Backpressure.Enabled,
+			HighWatermark:        cfg.CDC.Backpressure.HighWatermark,
+			LowWatermark:         cfg.CDC.Backpressure.LowWatermark,
+			LatencyHighWatermark: cfg.CDC.Backpressure.LatencyHighWatermark,
+			LatencyLowWatermark:  cfg.CDC.Backpressure.LatencyLowWatermark,
+			CheckInterval:        cfg.CDC.Backpressure.CheckInterval,
 		},
+		GapPolicy: gapPolicy,
+		GapAckLSN: cfg.CDC.Replication.GapAckLSN,
 	}
 
 	p := pipeline.New(reader, checkpointMgr, bufferMgr, pipelineCfg, logger)
 
+	// Mask columns before they are buffered
+	columnRules, err := transform.ParseRules(cfg.CDC.ColumnRules)
+	if err != nil {
+		return err
+	}
+	if len(columnRules) > 0 {
+		p.SetTransformer(transform.New(columnRules))
+		logger.Info("column rules enabled", "tables", len(columnRules))
+	}
+
+	// Checkpoint only what Iceberg committed; deduplicated changes are keyed,
+	// so the buffer drops the ones a restart replays
+	if batchProcessor != nil && cfg.CDC.Replication.DedupEnabled {
+		p.SetCommitTracker(batchProcessor)
+		logger.Info("exactly-once delivery enabled")
+	}
+
 	// Setup backpressure controller if enabled and buffer manager exists
 	if cfg.CDC.Backpressure.Enabled && bufferMgr != nil {
 		bpController := pipeline.NewBackpressureController(
 			pipeline.BackpressureConfig{
-				Enabled:       true,
-				HighWatermark: cfg.CDC.Backpressure.HighWatermark,
-				LowWatermark:  cfg.CDC.Backpressure.LowWatermark,
-				CheckInterval: cfg.CDC.Backpressure.CheckInterval,
+				Enabled:              true,
+				HighWatermark:        cfg.CDC.Backpressure.HighWatermark,
+				LowWatermark:         cfg.CDC.Backpressure.LowWatermark,
+				LatencyHighWatermark: cfg.CDC.Backpressure.LatencyHighWatermark,
+				LatencyLowWatermark:  cfg.CDC.Backpressure.LatencyLowWatermark,
+				CheckInterval:        cfg.CDC.Backpressure.CheckInterval,
 			},
 			func(ctx context.Context) (int, error) {
 				stats, err := bufferMgr.Stats(ctx)
@@ -332,21 +780,216 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 			nil, // state machine will be set internally
 			logger,
 		)
+		if flushLatency != nil {
+			bpController.SetFlushLatencyFunc(flushLatency.Average)
+		}
 		p.SetBackpressureController(bpController)
 		logger.Info("backpressure controller enabled",
 			"high_watermark", cfg.CDC.Backpressure.HighWatermark,
 			"low_watermark", cfg.CDC.Backpressure.LowWatermark,
+			"latency_high_watermark", cfg.CDC.Backpressure.LatencyHighWatermark,
+			"latency_low_watermark", cfg.CDC.Backpressure.LatencyLowWatermark,
+		)
+	}
+
+	// Follow the pause and resume requests for the pipeline registered
+	// through the API, including a pause made before a restart
+	if cfg.CDC.PipelineID != "" {
+		if db == nil {
+			return fmt.Errorf("pipeline control requires the buffer database")
+		}
+		if _, err := uuid.Parse(cfg.CDC.PipelineID); err != nil {
+			return fmt.Errorf("invalid pipeline ID %q: %w", cfg.CDC.PipelineID, err)
+		}
+		control := pipeline.NewPostgresControlStore(db, cfg.CDC.PipelineID)
+		if err := p.SyncControl(ctx, control); err != nil {
+			return fmt.Errorf("read pipeline control: %w", err)
+		}
+		go p.WatchControl(ctx, control, cfg.CDC.ControlPollInterval)
+		logger.Info("pipeline control enabled",
+			"pipeline_id", cfg.CDC.PipelineID,
+			"poll_interval", cfg.CDC.ControlPollInterval,
+		)
+	}
+
+	// Report, and optionally prune, orphaned replication slots and publications
+	if cfg.CDC.Orphans.Enabled {
+		orphanDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+		if err != nil {
+			return fmt.Errorf("open source database for orphan reconciler: %w", err)
+		}
+		defer orphanDB.Close()
+
+		static := orphan.References{
+			Slots: append([]string{
+				slotName,
+				cfg.CDC.Replication.SlotName,
+				cfg.CDC.Replication.SlotName + "_" + cfg.CDC.Shadow.Name,
+			}, cfg.CDC.Orphans.KeepSlots...),
+			Publications: append([]string{cfg.CDC.Replication.PublicationName}, cfg.CDC.Orphans.KeepPublications...),
+		}
+		var listers []orphan.ReferenceLister
+		if db != nil {
+			listers = append(listers, orphan.RegisteredSources(db))
+		}
+
+		reconciler := orphan.NewReconciler(orphan.Config{
+			Prune:    cfg.CDC.Orphans.Prune,
+			Prefixes: cfg.CDC.Orphans.Prefixes,
+			Interval: cfg.CDC.Orphans.Interval,
+			MinAge:   cfg.CDC.Orphans.MinAge,
+		}, sourceName, orphan.NewPostgresInventory(orphanDB), orphan.Combine(static, listers...), logger)
+		reconciler.Start(ctx)
+		defer reconciler.Stop()
+	}
+
+	// Compact small data files and expire old snapshots if enabled
+	if cfg.Iceberg.CompactionEnabled {
+		maintenanceCatalog := catalog.NewRESTCatalog(catalog.Config{
+			CatalogURL: cfg.Iceberg.CatalogURL,
+			Warehouse:  cfg.Iceberg.Warehouse,
+		}, logger)
+		defer maintenanceCatalog.Close()
+
+		maintenanceStorage, err := writer.NewObjectStore(objectStorageConfig(cfg.Storage), logger)
+		if err != nil {
+			return fmt.Errorf("create storage client for maintenance: %w", err)
+		}
+
+		maintenanceJob := maintenance.NewJob(maintenance.Config{
+			Interval:           cfg.Iceberg.CompactionInterval,
+			TargetFileSize:     int64(cfg.Iceberg.CompactionTargetFileSizeBytes),
+			MinInputFiles:      cfg.Iceberg.CompactionMinInputFiles,
+			SnapshotMaxAge:     cfg.Iceberg.SnapshotMaxAge,
+			MinSnapshotsToKeep: cfg.Iceberg.SnapshotsToKeep,
+			CommitRetry: catalog.CommitRetryConfig{
+				MaxRetries:     cfg.Iceberg.CommitMaxRetries,
+				InitialBackoff: cfg.Iceberg.CommitRetryBackoff,
+				MaxBackoff:     cfg.Iceberg.CommitRetryMaxBackoff,
+			},
+		}, maintenanceCatalog, maintenanceStorage, logger)
+		maintenanceJob.Start(ctx)
+		defer maintenanceJob.Stop()
+	}
+
+	// Setup post-snapshot verification if enabled
+	verifyMode, err := verify.ParseMode(cfg.CDC.Verification.Mode)
+	if err != nil {
+		return fmt.Errorf("parse verification mode: %w", err)
+	}
+	if verifyMode != verify.ModeOff {
+		sourceDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+		if err != nil {
+			return fmt.Errorf("open source database for verification: %w", err)
+		}
+		defer sourceDB.Close()
+
+		verifyCatalog := catalog.NewRESTCatalog(catalog.Config{
+			CatalogURL: cfg.Iceberg.CatalogURL,
+			Warehouse:  cfg.Iceberg.Warehouse,
+		}, logger)
+		defer verifyCatalog.Close()
+
+		// Checksums read the data files the snapshot wrote
+		icebergCounter := verify.NewIcebergCounter(verifyCatalog)
+		if verifyMode == verify.ModeChecksum {
+			verifyStorage, err := writer.NewObjectStore(objectStorageConfig(cfg.Storage), logger)
+			if err != nil {
+				return fmt.Errorf("create storage client for verification: %w", err)
+			}
+			icebergCounter.SetDataFileReader(writer.NewDataFileReader(verifyStorage))
+		}
+
+		p.SetVerifier(verify.New(
+			verify.Config{
+				Mode:            verifyMode,
+				SampleRanges:    cfg.CDC.Verification.SampleRanges,
+				RerunOnMismatch: cfg.CDC.Verification.RerunOnMismatch,
+			},
+			sourceName,
+			verify.NewPostgresCounter(sourceDB),
+			icebergCounter,
+			logger,
+		))
+		logger.Info("snapshot verification enabled", "mode", verifyMode)
+	}
+
+	// Setup the initial snapshot if enabled. PHILOTES_CDC_SNAPSHOT_MODE used
+	// to hold the read mode; those values still select it.
+	snapshotMode, snapshotReadMode := cfg.CDC.Snapshot.Mode, cfg.CDC.Snapshot.ReadMode
+	if m := snapshot.Mode(snapshotMode); m == snapshot.ModeConsistent || m == snapshot.ModeChunked {
+		logger.Warn("PHILOTES_CDC_SNAPSHOT_MODE now selects when to snapshot; set the read mode with PHILOTES_CDC_SNAPSHOT_READ_MODE",
+			"mode", snapshotMode,
 		)
+		snapshotMode, snapshotReadMode = string(snapshot.TriggerInitial), snapshotMode
+	}
+	snapshotTrigger, err := snapshot.ParseTrigger(snapshotMode)
+	if err != nil {
+		return err
+	}
+
+	// The snapshot gap policy backfills a replication gap with the
+	// snapshotter, so it needs one even when the initial snapshot is off.
+	snapshotEnabled := cfg.CDC.Snapshot.Enabled && snapshotTrigger != snapshot.TriggerNever
+	if !snapshotEnabled {
+		snapshotTrigger = snapshot.TriggerNever
 	}
+	if snapshotEnabled || gapPolicy == pipeline.GapPolicySnapshot {
+		if len(cfg.CDC.Replication.Tables) == 0 {
+			if !snapshotEnabled {
+				return fmt.Errorf("gap policy %q requires PHILOTES_CDC_TABLES", gapPolicy)
+			}
+			return fmt.Errorf("initial snapshot requires PHILOTES_CDC_TABLES")
+		}
 
-	// Register pipeline health check
+		window, err := snapshot.ParseWindow(cfg.CDC.Snapshot.Window)
+		if err != nil {
+			return fmt.Errorf("parse snapshot window: %w", err)
+		}
+
+		snapshotDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+		if err != nil {
+			return fmt.Errorf("open source database for snapshot: %w", err)
+		}
+		defer snapshotDB.Close()
+
+		snapshotReader := snapshot.NewPostgresReader(snapshotDB)
+		snapshotReader.SetJSONAsStruct(cfg.CDC.JSONAsStruct)
+
+		snapshotter, err := snapshot.New(snapshot.Config{
+			Tables:        cfg.CDC.Replication.Tables,
+			Trigger:       snapshotTrigger,
+			Mode:          snapshot.Mode(snapshotReadMode),
+			Order:         snapshot.Order(cfg.CDC.Snapshot.Order),
+			Window:        window,
+			MaxTxDuration: cfg.CDC.Snapshot.MaxTxDuration,
+			ChunkSize:     cfg.CDC.Snapshot.ChunkSize,
+		}, snapshotReader, logger)
+		if err != nil {
+			return fmt.Errorf("create snapshotter: %w", err)
+		}
+		p.SetSnapshotter(snapshotter)
+		if snapshotEnabled {
+			logger.Info("initial snapshot enabled",
+				"trigger", snapshotTrigger,
+				"mode", snapshotReadMode,
+				"order", cfg.CDC.Snapshot.Order,
+				"window", window,
+				"max_tx_duration", cfg.CDC.Snapshot.MaxTxDuration,
+			)
+		} else {
+			logger.Info("snapshotter enabled to backfill replication gaps", "gap_policy", gapPolicy)
+		}
+	}
+
+	// Register pipeline health check, and hold readiness back until the
+	// pipeline has caught up with its checkpoint
 	healthMgr.Register(p.HealthChecker())
+	healthMgr.RegisterReadinessGate(p.ReadinessGate())
 
 	logger.Info("CDC pipeline configured",
-		"source_host", cfg.CDC.Source.Host,
-		"source_port", cfg.CDC.Source.Port,
-		"source_database", cfg.CDC.Source.Database,
-		"replication_slot", cfg.CDC.Replication.SlotName,
+		"source", cfg.CDC.Source,
+		"replication_slot", slotName,
 		"checkpoint_enabled", cfg.CDC.Checkpoint.Enabled,
 		"checkpoint_interval", cfg.CDC.Checkpoint.Interval,
 		"buffer_enabled", cfg.CDC.Buffer.Enabled,
@@ -356,10 +999,48 @@ func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
 		"backpressure_enabled", cfg.CDC.Backpressure.Enabled,
 	)
 
-	if err := p.Run(ctx); err != nil {
+	// Apply the settings that can change at runtime on SIGHUP
+	reloadCh := make(chan os.Signal, 1)
+	signal.Notify(reloadCh, syscall.SIGHUP)
+	defer signal.Stop(reloadCh)
+	go cfg.Watch(reloadCh, logger, func(reloaded *config.Config) {
+		if batchProcessor != nil {
+			batchProcessor.SetBatchSettings(reloaded.CDC.BatchSize, reloaded.CDC.FlushInterval)
+		}
+	})
+
+	// Time-box shadow runs
+	runCtx := ctx
+	if shadowCfg != nil && shadowCfg.MaxDuration > 0 {
+		var cancelRun context.CancelFunc
+		runCtx, cancelRun = context.WithTimeout(ctx, shadowCfg.MaxDuration)
+		defer cancelRun()
+	}
+
+	if err := p.Run(runCtx); err != nil {
 		return fmt.Errorf("pipeline error: %w", err)
 	}
 
+	if shadowCfg != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		logger.Info("shadow pipeline time box elapsed", "max_duration", shadowCfg.MaxDuration)
+		if shadowCfg.DiscardOnExpiry {
+			return runShadowAction(ctx, cfg, *shadowCfg, shadow.ActionDiscard, logger)
+		}
+	}
+
 	logger.Info("CDC worker stopped gracefully")
 	return nil
 }
+
+// objectStorageConfig returns the object store configuration of the data
+// files.
+func objectStorageConfig(storage config.StorageConfig) writer.StorageConfig {
+	return writer.StorageConfig{
+		Provider:  storage.Provider,
+		Endpoint:  storage.Endpoint,
+		AccessKey: storage.AccessKey,
+		SecretKey: storage.SecretKey,
+		UseSSL:    storage.UseSSL,
+		Region:    storage.Region,
+	}
+}
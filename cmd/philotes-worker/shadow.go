@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/janovincze/philotes/internal/cdc/checkpoint"
+	"github.com/janovincze/philotes/internal/cdc/pipeline"
+	"github.com/janovincze/philotes/internal/cdc/shadow"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+)
+
+// newShadowConfig builds the shadow configuration and action from cfg.
+func newShadowConfig(cfg *config.Config) (shadow.Config, shadow.Action, error) {
+	shadowCfg := shadow.Config{
+		Name:            cfg.CDC.Shadow.Name,
+		NamespacePrefix: cfg.CDC.Shadow.NamespacePrefix,
+		MaxDuration:     cfg.CDC.Shadow.MaxDuration,
+		DiscardOnExpiry: cfg.CDC.Shadow.DiscardOnExpiry,
+	}
+	if err := shadowCfg.Validate(); err != nil {
+		return shadow.Config{}, "", fmt.Errorf("invalid shadow config: %w", err)
+	}
+
+	action, err := shadow.ParseAction(cfg.CDC.Shadow.Action)
+	if err != nil {
+		return shadow.Config{}, "", err
+	}
+	return shadowCfg, action, nil
+}
+
+// runShadowAction promotes or discards a shadow pipeline and returns.
+func runShadowAction(ctx context.Context, cfg *config.Config, shadowCfg shadow.Config, action shadow.Action, logger *slog.Logger) error {
+	tables := cfg.CDC.Replication.Tables
+	if len(tables) == 0 {
+		return fmt.Errorf("shadow %s requires PHILOTES_CDC_TABLES", action)
+	}
+
+	sourceDB, err := sql.Open("pgx", cfg.CDC.Source.URL())
+	if err != nil {
+		return fmt.Errorf("open source database: %w", err)
+	}
+	defer sourceDB.Close()
+
+	cat := catalog.NewRESTCatalog(catalog.Config{
+		CatalogURL: cfg.Iceberg.CatalogURL,
+		Warehouse:  cfg.Iceberg.Warehouse,
+	}, logger)
+	defer cat.Close()
+
+	controller := shadow.NewController(shadowCfg, shadow.NewPostgresSlotDropper(sourceDB), cat, logger)
+
+	switch action {
+	case shadow.ActionPromote:
+		snapshotted, err := shadowSnapshotted(ctx, cfg, shadowCfg, logger)
+		if err != nil {
+			return err
+		}
+		comparisons, err := shadow.Compare(ctx, shadowCfg, verify.NewIcebergCounter(cat), tables)
+		if err != nil {
+			return fmt.Errorf("compare shadow output: %w", err)
+		}
+		for _, c := range comparisons {
+			logger.Info("shadow comparison",
+				"table", c.Table,
+				"production_rows", c.ProductionRows,
+				"shadow_rows", c.ShadowRows,
+				"matches", c.Matches(),
+			)
+		}
+		opts := shadow.PromoteOptions{
+			Snapshotted: snapshotted,
+			Comparisons: comparisons,
+			Force:       cfg.CDC.Shadow.ForcePromote,
+		}
+		if err := controller.Promote(ctx, cfg.CDC.Replication.SlotName, tables, opts); err != nil {
+			return fmt.Errorf("promote shadow: %w", err)
+		}
+	case shadow.ActionDiscard:
+		if err := controller.Discard(ctx, cfg.CDC.Replication.SlotName, tables); err != nil {
+			return fmt.Errorf("discard shadow: %w", err)
+		}
+	}
+
+	logger.Info("shadow action completed", "action", action)
+	return nil
+}
+
+// shadowSnapshotted returns the tables the shadow finished snapshotting, as
+// recorded in its checkpoint.
+func shadowSnapshotted(ctx context.Context, cfg *config.Config, shadowCfg shadow.Config, logger *slog.Logger) ([]string, error) {
+	mgr, err := checkpoint.NewPostgresManager(ctx, checkpoint.PostgresConfig{
+		DSN:          cfg.Database.DSN(),
+		MaxOpenConns: cfg.Database.MaxOpenConns,
+		MaxIdleConns: cfg.Database.MaxIdleConns,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create checkpoint manager: %w", err)
+	}
+	defer mgr.Close()
+
+	cp, err := mgr.Load(ctx, shadowCfg.SourceName(cfg.CDC.Source.SourceID()))
+	if err != nil {
+		return nil, fmt.Errorf("load shadow checkpoint: %w", err)
+	}
+	if cp == nil {
+		return nil, nil
+	}
+	return slices.Sorted(maps.Keys(pipeline.SnapshottedTables(cp.Metadata))), nil
+}
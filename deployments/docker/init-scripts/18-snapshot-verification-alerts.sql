@@ -0,0 +1,17 @@
+-- Philotes Snapshot Verification Alerts
+-- Raises a critical alert when post-snapshot verification finds discrepancies
+-- between a source table and its backfilled Iceberg table.
+
+INSERT INTO philotes.alert_rules (name, description, metric_name, operator, threshold, duration_seconds, severity, labels, annotations)
+VALUES (
+    'snapshot_verification_failed',
+    'Post-snapshot verification found rows missing or differing in Iceberg',
+    'philotes_cdc_snapshot_verification_discrepancies',
+    'gt',
+    0,
+    0,
+    'critical',
+    '{"component": "cdc"}',
+    '{"summary": "Snapshot verification failed", "runbook": "Check the worker logs for the affected table and re-run the backfill"}'
+)
+ON CONFLICT (name) DO NOTHING;
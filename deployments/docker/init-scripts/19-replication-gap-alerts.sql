@@ -0,0 +1,17 @@
+-- Philotes Replication Gap Alerts
+-- Raises a critical alert when a CDC pipeline halts because its replication
+-- slot lost the checkpointed position (e.g. the slot was recreated).
+
+INSERT INTO philotes.alert_rules (name, description, metric_name, operator, threshold, duration_seconds, severity, labels, annotations)
+VALUES (
+    'replication_gap_detected',
+    'A CDC pipeline halted because its replication slot can no longer deliver changes after the last checkpoint',
+    'philotes_cdc_replication_gap',
+    'gt',
+    0,
+    0,
+    'critical',
+    '{"component": "cdc"}',
+    '{"summary": "Replication gap detected", "runbook": "Backfill the gap, then set PHILOTES_CDC_GAP_ACK_LSN to the checkpoint LSN from the worker logs to resume"}'
+)
+ON CONFLICT (name) DO NOTHING;
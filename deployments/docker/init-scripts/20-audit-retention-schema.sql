@@ -0,0 +1,33 @@
+-- 20-audit-retention-schema.sql
+-- Per-tenant audit log retention and legal holds.
+-- Tenants without a retention policy use PHILOTES_AUTH_AUDIT_RETENTION_DAYS.
+
+-- Per-tenant retention overrides
+CREATE TABLE IF NOT EXISTS philotes.audit_retention_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES philotes.tenants(id) ON DELETE CASCADE,
+    retention_days INTEGER NOT NULL CHECK (retention_days > 0),
+    updated_by UUID REFERENCES philotes.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Legal holds exempt a tenant's audit logs (optionally limited to a time
+-- range) from retention cleanup until released.
+CREATE TABLE IF NOT EXISTS philotes.audit_legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES philotes.tenants(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES philotes.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    released_by UUID REFERENCES philotes.users(id) ON DELETE SET NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at <= ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_legal_holds_tenant_id ON philotes.audit_legal_holds(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_legal_holds_active ON philotes.audit_legal_holds(tenant_id) WHERE released_at IS NULL;
+
+-- Cleanup deletes by tenant and age
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created_at ON philotes.audit_logs(tenant_id, created_at);
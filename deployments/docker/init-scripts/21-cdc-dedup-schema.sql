@@ -0,0 +1,11 @@
+-- 21-cdc-dedup-schema.sql
+-- Source-side deduplication high-water-marks, one per replication slot.
+-- Changes at or below the mark are dropped when a slot redelivers them after
+-- a reconnect (PHILOTES_CDC_DEDUP_ENABLED).
+
+CREATE TABLE IF NOT EXISTS philotes.cdc_dedup_high_water_marks (
+    slot_name TEXT PRIMARY KEY,
+    lsn TEXT NOT NULL,
+    sequence BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+-- 22-alert-flapping-schema.sql
+-- Per-rule notification throttling. State changes (fired/resolved) closer
+-- together than min_notification_interval_seconds are collapsed into a single
+-- "flapping" notification, which is also recorded in alert history.
+-- 0 falls back to PHILOTES_ALERTING_MIN_NOTIFICATION_INTERVAL.
+
+ALTER TABLE philotes.alert_rules
+    ADD COLUMN IF NOT EXISTS min_notification_interval_seconds INTEGER NOT NULL DEFAULT 0
+        CHECK (min_notification_interval_seconds >= 0);
+
+ALTER TABLE philotes.alert_history DROP CONSTRAINT IF EXISTS alert_history_event_type_check;
+ALTER TABLE philotes.alert_history ADD CONSTRAINT alert_history_event_type_check
+    CHECK (event_type IN ('fired', 'resolved', 'flapping', 'acknowledged', 'notification_sent', 'notification_failed'));
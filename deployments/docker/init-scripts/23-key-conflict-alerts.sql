@@ -0,0 +1,26 @@
+-- Philotes Key Conflict Alerts
+-- Raises a warning when a batch contains differing rows for the same key,
+-- which means the source key a table is replicated by is not unique. The
+-- gauge covers the latest batch per table, so the alert resolves once the
+-- table's batches are clean again. Requires a key conflict policy other
+-- than "off".
+
+INSERT INTO philotes.alert_rules (name, description, metric_name, operator, threshold, duration_seconds, severity, labels, annotations)
+VALUES (
+    'cdc_key_conflicts_detected',
+    'A CDC batch contained conflicting non-identical rows for the same key',
+    'philotes_cdc_key_conflicts_last_batch',
+    'gt',
+    0,
+    0,
+    'warning',
+    '{"component": "cdc"}',
+    '{"summary": "Conflicting rows share a key", "runbook": "Check the source table for a missing or invalid unique constraint on the key columns; the worker log names the conflicting keys (PHILOTES_CDC_KEY_CONFLICT_POLICY=detect)"}'
+)
+ON CONFLICT (name) DO NOTHING;
+
+-- Rules seeded before the gauge existed alerted on the counter, which never resolves
+UPDATE philotes.alert_rules
+SET metric_name = 'philotes_cdc_key_conflicts_last_batch'
+WHERE name = 'cdc_key_conflicts_detected'
+  AND metric_name = 'philotes_cdc_key_conflicts_total';
@@ -0,0 +1,11 @@
+-- 24-dead-letter-replay.sql
+-- Dead-letter events replayed into the buffer are kept for inspection but
+-- marked, so they are not replayed or counted again.
+
+ALTER TABLE philotes.dead_letter_events ADD COLUMN IF NOT EXISTS replayed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_events_pending
+    ON philotes.dead_letter_events (source_id, created_at)
+    WHERE replayed_at IS NULL;
+
+COMMENT ON COLUMN philotes.dead_letter_events.replayed_at IS 'When this event was re-enqueued into the buffer (NULL = not replayed)';
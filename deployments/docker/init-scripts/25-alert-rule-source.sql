@@ -0,0 +1,10 @@
+-- 25-alert-rule-source.sql
+-- Where a rule's metric is read from: 'prometheus' queries
+-- PHILOTES_ALERTING_PROMETHEUS_URL, 'internal' reads the in-process metrics
+-- registry. '' uses Prometheus when a URL is configured, otherwise internal.
+
+ALTER TABLE philotes.alert_rules
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT ''
+        CHECK (source IN ('', 'prometheus', 'internal'));
+
+COMMENT ON COLUMN philotes.alert_rules.source IS 'Metric source: prometheus, internal or empty for the default';
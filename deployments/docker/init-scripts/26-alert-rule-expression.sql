@@ -0,0 +1,18 @@
+-- 26-alert-rule-expression.sql
+-- Rules can fire on an expression instead of comparing one metric with a
+-- threshold, e.g. "philotes_cdc_lag_seconds > 60 and
+-- rate(philotes_buffer_dlq_total[5m]) > 0". A rule has
+-- either an expression or a metric_name and operator, never both.
+
+ALTER TABLE philotes.alert_rules
+    ADD COLUMN IF NOT EXISTS expression TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE philotes.alert_rules DROP CONSTRAINT IF EXISTS alert_rules_operator_check;
+ALTER TABLE philotes.alert_rules ADD CONSTRAINT alert_rules_operator_check
+    CHECK (operator IN ('', 'gt', 'lt', 'eq', 'gte', 'lte'));
+
+ALTER TABLE philotes.alert_rules DROP CONSTRAINT IF EXISTS alert_rules_condition_check;
+ALTER TABLE philotes.alert_rules ADD CONSTRAINT alert_rules_condition_check
+    CHECK ((expression = '') = (metric_name <> '' AND operator <> ''));
+
+COMMENT ON COLUMN philotes.alert_rules.expression IS 'Rule expression, evaluated instead of metric_name, operator and threshold when set';
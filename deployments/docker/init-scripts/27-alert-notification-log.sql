@@ -0,0 +1,15 @@
+-- 27-alert-notification-log.sql
+-- Records when each alert was last notified through a route, so the alert
+-- manager honours repeat intervals across restarts instead of notifying
+-- every firing alert again when it starts.
+
+CREATE TABLE IF NOT EXISTS philotes.alert_notification_log (
+    route_id UUID NOT NULL REFERENCES philotes.alert_routes(id) ON DELETE CASCADE,
+    fingerprint TEXT NOT NULL,
+    notified_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (route_id, fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_notification_log_fingerprint ON philotes.alert_notification_log(fingerprint);
+
+COMMENT ON TABLE philotes.alert_notification_log IS 'Last notification time per alert route and alert fingerprint';
@@ -0,0 +1,9 @@
+-- 28-alert-silence-schedule.sql
+-- Silences can recur weekly, e.g. a maintenance window every night from
+-- 02:00 to 03:00 Europe/Budapest. Between starts_at and ends_at a silence
+-- with a schedule is only active within the schedule's windows.
+
+ALTER TABLE philotes.alert_silences
+    ADD COLUMN IF NOT EXISTS schedule JSONB;
+
+COMMENT ON COLUMN philotes.alert_silences.schedule IS 'Weekly recurrence: weekdays, start_time, end_time and timezone; NULL for a one-off silence';
@@ -0,0 +1,27 @@
+-- 29-alert-tenant-scope.sql
+-- Scopes alert rules and notification channels to tenants, so each tenant
+-- only sees and is notified about alerts on its own pipelines. Alert
+-- instances and routes belong to the tenant of their rule. Rows without a
+-- tenant are global and only visible to administrators.
+
+ALTER TABLE philotes.alert_rules ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant_id ON philotes.alert_rules(tenant_id);
+
+ALTER TABLE philotes.notification_channels ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_notification_channels_tenant_id ON philotes.notification_channels(tenant_id);
+
+-- Migrate existing rules and channels to the default tenant
+UPDATE philotes.alert_rules SET tenant_id = '00000000-0000-0000-0000-000000000001' WHERE tenant_id IS NULL;
+UPDATE philotes.notification_channels SET tenant_id = '00000000-0000-0000-0000-000000000001' WHERE tenant_id IS NULL;
+
+-- Names only need to be unique within a tenant
+ALTER TABLE philotes.alert_rules DROP CONSTRAINT IF EXISTS alert_rules_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_rules_tenant_name
+    ON philotes.alert_rules(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), name);
+
+ALTER TABLE philotes.notification_channels DROP CONSTRAINT IF EXISTS notification_channels_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_channels_tenant_name
+    ON philotes.notification_channels(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), name);
+
+COMMENT ON COLUMN philotes.alert_rules.tenant_id IS 'Owning tenant; NULL for global rules';
+COMMENT ON COLUMN philotes.notification_channels.tenant_id IS 'Owning tenant; NULL for global channels';
@@ -0,0 +1,24 @@
+-- 30-remote-write-samples.sql
+-- Metric samples pushed over Prometheus remote write by workers the API
+-- cannot scrape. Alert rules with the 'remote_write' source read them.
+-- Samples are short-lived: expired rows are deleted by the API's cleanup job.
+
+CREATE TABLE IF NOT EXISTS philotes.metric_samples (
+    id BIGSERIAL PRIMARY KEY,
+    metric_name TEXT NOT NULL,
+    labels JSONB NOT NULL DEFAULT '{}',
+    value DOUBLE PRECISION NOT NULL,
+    sampled_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_metric_samples_name_time ON philotes.metric_samples(metric_name, sampled_at);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_expires_at ON philotes.metric_samples(expires_at);
+
+-- Allow rules to read pushed metrics
+ALTER TABLE philotes.alert_rules DROP CONSTRAINT IF EXISTS alert_rules_source_check;
+ALTER TABLE philotes.alert_rules ADD CONSTRAINT alert_rules_source_check
+    CHECK (source IN ('', 'prometheus', 'internal', 'remote_write'));
+
+COMMENT ON TABLE philotes.metric_samples IS 'Metric samples received over Prometheus remote write';
+COMMENT ON COLUMN philotes.alert_rules.source IS 'Metric source: prometheus, internal, remote_write or empty for the default';
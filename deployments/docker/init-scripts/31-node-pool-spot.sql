@@ -0,0 +1,21 @@
+-- 31-node-pool-spot.sql
+-- Spot instance support for node pools. Spot-enabled pools provision spot
+-- capacity up to spot_max_percent of their nodes and replace reclaimed spot
+-- nodes, falling back to on-demand after repeated spot failures.
+
+ALTER TABLE philotes.node_pools
+    ADD COLUMN IF NOT EXISTS spot_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS spot_max_percent INT NOT NULL DEFAULT 50;
+
+-- A pool must never run entirely on spot capacity
+ALTER TABLE philotes.node_pools DROP CONSTRAINT IF EXISTS node_pools_spot_max_percent_check;
+ALTER TABLE philotes.node_pools ADD CONSTRAINT node_pools_spot_max_percent_check
+    CHECK (spot_max_percent >= 0 AND spot_max_percent <= 99);
+
+-- Record replacements of reclaimed spot nodes
+ALTER TABLE philotes.node_scaling_operations DROP CONSTRAINT IF EXISTS node_scaling_operations_action_check;
+ALTER TABLE philotes.node_scaling_operations ADD CONSTRAINT node_scaling_operations_action_check
+    CHECK (action IN ('scale_up', 'scale_down', 'spot_replace'));
+
+COMMENT ON COLUMN philotes.node_pools.spot_enabled IS 'Whether the pool provisions spot instances when the instance type supports them';
+COMMENT ON COLUMN philotes.node_pools.spot_max_percent IS 'Maximum share of the pool, in percent, allowed to run on spot instances';
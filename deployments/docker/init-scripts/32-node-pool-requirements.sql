@@ -0,0 +1,11 @@
+-- 32-node-pool-requirements.sql
+-- Optional instance requirements for node pools. When set, scale-up picks the
+-- cheapest priced instance type meeting them instead of the pool's fixed
+-- instance_type; NULL in both columns keeps the fixed type.
+
+ALTER TABLE philotes.node_pools
+    ADD COLUMN IF NOT EXISTS min_cpu_cores INT CHECK (min_cpu_cores >= 0),
+    ADD COLUMN IF NOT EXISTS min_memory_mb INT CHECK (min_memory_mb >= 0);
+
+COMMENT ON COLUMN philotes.node_pools.min_cpu_cores IS 'Minimum CPU cores of the instance type selected on scale-up';
+COMMENT ON COLUMN philotes.node_pools.min_memory_mb IS 'Minimum memory in MB of the instance type selected on scale-up';
@@ -0,0 +1,10 @@
+-- 33-scaling-policy-cooldown.sql
+-- Make the scaling policy cooldown optional. A NULL cooldown_seconds uses the
+-- configured default (PHILOTES_SCALING_DEFAULT_COOLDOWN), so pools only need
+-- their own cooldown when their volatility differs from the rest.
+
+ALTER TABLE scaling_policies
+    ALTER COLUMN cooldown_seconds DROP NOT NULL,
+    ALTER COLUMN cooldown_seconds DROP DEFAULT;
+
+COMMENT ON COLUMN scaling_policies.cooldown_seconds IS 'Seconds to wait after a scaling operation finishes; NULL uses the configured default';
@@ -0,0 +1,9 @@
+-- 34-node-pool-scaling-notifications.sql
+-- Per-pool opt-in for scaling notifications. Pools with notify_scaling set
+-- send a notification to the configured scaling channel whenever one of
+-- their scaling operations completes.
+
+ALTER TABLE philotes.node_pools
+    ADD COLUMN IF NOT EXISTS notify_scaling BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN philotes.node_pools.notify_scaling IS 'Send a notification when a scaling operation of the pool completes';
@@ -0,0 +1,25 @@
+-- 35-saved-queries.sql
+-- Named SQL statements that analysts re-run with typed {{name}}
+-- placeholders. Saved queries belong to a tenant; rows without a tenant are
+-- global and only visible to administrators.
+
+CREATE TABLE IF NOT EXISTS philotes.saved_queries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES philotes.tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    sql TEXT NOT NULL,
+    schema_name VARCHAR(255) NOT NULL DEFAULT '',
+    parameters JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_queries_tenant_id ON philotes.saved_queries(tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_queries_tenant_name
+    ON philotes.saved_queries(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), name);
+
+COMMENT ON TABLE philotes.saved_queries IS 'Named SQL statements with typed parameters';
+COMMENT ON COLUMN philotes.saved_queries.tenant_id IS 'Owning tenant; NULL for global saved queries';
+COMMENT ON COLUMN philotes.saved_queries.schema_name IS 'Session schema the statement runs in; empty for the engine default';
+COMMENT ON COLUMN philotes.saved_queries.parameters IS 'Declared parameters: name, type, description and default value';
@@ -0,0 +1,30 @@
+-- 36-query-history.sql
+-- Every statement run through the query endpoint or as a saved query, with
+-- who ran it and how it went. Literals in WHERE clauses are redacted before
+-- the SQL is stored when PHILOTES_QUERY_HISTORY_REDACT_LITERALS is set.
+
+CREATE TABLE IF NOT EXISTS philotes.query_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES philotes.tenants(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES philotes.users(id) ON DELETE SET NULL,
+    api_key_id UUID REFERENCES philotes.api_keys(id) ON DELETE SET NULL,
+    saved_query_id UUID REFERENCES philotes.saved_queries(id) ON DELETE SET NULL,
+    engine VARCHAR(50) NOT NULL,
+    query_id VARCHAR(255),
+    sql TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('success', 'error')),
+    error_message TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    bytes_scanned BIGINT NOT NULL DEFAULT 0,
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_history_executed_at ON philotes.query_history(executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_query_history_tenant ON philotes.query_history(tenant_id, executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_query_history_user ON philotes.query_history(user_id, executed_at DESC);
+
+COMMENT ON TABLE philotes.query_history IS 'Statements executed through the query API';
+COMMENT ON COLUMN philotes.query_history.tenant_id IS 'Tenant the statement ran for; NULL without tenancy';
+COMMENT ON COLUMN philotes.query_history.query_id IS 'Query ID assigned by the engine';
+COMMENT ON COLUMN philotes.query_history.bytes_scanned IS 'Bytes read by the engine, when it reports them';
@@ -0,0 +1,33 @@
+-- 37-token-revocations.sql
+-- Denylist of JWTs revoked before they expire, checked on each authenticated
+-- request. A token is revoked by its ID on logout, by its identity provider
+-- session on OIDC back-channel logout, or together with every other token of
+-- its user issued before a point in time. Rows are deleted once every token
+-- they could match has expired.
+
+CREATE TABLE IF NOT EXISTS philotes.revoked_tokens (
+    token_id VARCHAR(64) PRIMARY KEY,
+    user_id UUID REFERENCES philotes.users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS philotes.revoked_sessions (
+    session_id VARCHAR(255) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS philotes.user_token_revocations (
+    user_id UUID PRIMARY KEY REFERENCES philotes.users(id) ON DELETE CASCADE,
+    revoked_before TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON philotes.revoked_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_sessions_expires_at ON philotes.revoked_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_token_revocations_expires_at ON philotes.user_token_revocations(expires_at);
+
+COMMENT ON TABLE philotes.revoked_tokens IS 'JWTs revoked by their jti claim';
+COMMENT ON TABLE philotes.revoked_sessions IS 'Identity provider sessions ended by back-channel logout, by sid claim';
+COMMENT ON TABLE philotes.user_token_revocations IS 'Users whose tokens issued before revoked_before are revoked';
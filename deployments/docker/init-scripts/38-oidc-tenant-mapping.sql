@@ -0,0 +1,11 @@
+-- 38-oidc-tenant-mapping.sql
+-- Maps identity provider groups to tenant memberships. On each login, SSO
+-- users are added to the mapped tenants of their groups with the mapped
+-- role. Removing memberships of mapped tenants the user's groups no longer
+-- grant is opt-in per provider.
+
+ALTER TABLE philotes.oidc_providers ADD COLUMN IF NOT EXISTS tenant_mapping JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE philotes.oidc_providers ADD COLUMN IF NOT EXISTS remove_unmapped_memberships BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN philotes.oidc_providers.tenant_mapping IS 'JSON list of group, tenant_id and role assignments';
+COMMENT ON COLUMN philotes.oidc_providers.remove_unmapped_memberships IS 'Remove memberships of mapped tenants the user''s groups no longer grant';
@@ -0,0 +1,31 @@
+-- 39-scim-provisioning.sql
+-- SCIM 2.0 provisioning of the users and groups of OIDC providers. Each
+-- provider authenticates its SCIM client with its own bearer token, of which
+-- only the SHA-256 hash is stored. Provisioned group memberships become the
+-- IdP groups of their users, mapped to roles and tenants like on login.
+
+ALTER TABLE philotes.oidc_providers ADD COLUMN IF NOT EXISTS scim_token_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oidc_providers_scim_token_hash
+    ON philotes.oidc_providers(scim_token_hash) WHERE scim_token_hash IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS philotes.scim_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider_id UUID NOT NULL REFERENCES philotes.oidc_providers(id) ON DELETE CASCADE,
+    display_name VARCHAR(255) NOT NULL,
+    external_id VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider_id, display_name)
+);
+
+CREATE TABLE IF NOT EXISTS philotes.scim_group_members (
+    group_id UUID NOT NULL REFERENCES philotes.scim_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES philotes.users(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_group_members_user ON philotes.scim_group_members(user_id);
+
+COMMENT ON COLUMN philotes.oidc_providers.scim_token_hash IS 'SHA-256 hash of the SCIM bearer token; NULL when SCIM is disabled';
+COMMENT ON TABLE philotes.scim_groups IS 'Groups provisioned over SCIM by an OIDC provider';
+COMMENT ON TABLE philotes.scim_group_members IS 'Users in SCIM-provisioned groups';
@@ -0,0 +1,7 @@
+-- 40-api-key-rate-limits.sql
+-- Per-API-key rate limits. Requests made with a valid key are counted against
+-- the key rather than the client IP, at the key's own limit or, while it is
+-- NULL, the global one.
+
+ALTER TABLE philotes.api_keys ADD COLUMN IF NOT EXISTS rate_limit_rps DOUBLE PRECISION;
+ALTER TABLE philotes.api_keys ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER;
@@ -0,0 +1,18 @@
+-- 41-idempotency-keys.sql
+-- Idempotency keys of POST requests. The first request with a key reserves
+-- it; its response is stored and replayed to retries with the same key and
+-- request body until the key expires. Keys are scoped per user and tenant.
+
+CREATE TABLE IF NOT EXISTS philotes.idempotency_keys (
+    scope VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON philotes.idempotency_keys(expires_at);
@@ -0,0 +1,7 @@
+-- 42-audit-request-actions.sql
+-- Mutating API requests are audited with the method and route as the action,
+-- such as "DELETE /alerts/channels/:id", which can exceed 50 characters.
+
+ALTER TABLE philotes.audit_logs ALTER COLUMN action TYPE VARCHAR(255);
+
+COMMENT ON COLUMN philotes.audit_logs.action IS 'Type of action: login, api_key_created, etc., or the method and route of an audited API request';
@@ -0,0 +1,13 @@
+-- 43-buffer-event-keys.sql
+-- A restarted pipeline replays changes from its last checkpoint, which only
+-- advances once the changes are committed to Iceberg. Changes that were
+-- buffered but not yet committed are redelivered; their event key, the
+-- change's replication position, lets the buffer skip the copies.
+
+ALTER TABLE philotes.cdc_events ADD COLUMN IF NOT EXISTS event_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cdc_events_event_key
+    ON philotes.cdc_events (source_id, event_key)
+    WHERE event_key IS NOT NULL;
+
+COMMENT ON COLUMN philotes.cdc_events.event_key IS 'Replication position (LSN#sequence) of a streamed change, NULL for snapshot rows';
@@ -0,0 +1,12 @@
+-- 44-source-password-encryption.sql
+-- Source passwords are encrypted with the OAuth encryption key, like cloud
+-- provider credentials, and stored in password_encrypted. The API server
+-- encrypts the existing plaintext passwords on startup once the key is
+-- configured, clearing password.
+
+ALTER TABLE philotes.sources ADD COLUMN IF NOT EXISTS password_encrypted BYTEA;
+
+ALTER TABLE philotes.sources ALTER COLUMN password DROP NOT NULL;
+
+COMMENT ON COLUMN philotes.sources.password IS 'Plaintext password, NULL once encrypted into password_encrypted';
+COMMENT ON COLUMN philotes.sources.password_encrypted IS 'Password encrypted with the OAuth encryption key (AES-256-GCM)';
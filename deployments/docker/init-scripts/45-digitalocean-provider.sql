@@ -0,0 +1,26 @@
+-- 45-digitalocean-provider.sql
+-- DigitalOcean is a supported node-scaling provider.
+
+ALTER TABLE philotes.node_pools DROP CONSTRAINT IF EXISTS node_pools_provider_check;
+ALTER TABLE philotes.node_pools ADD CONSTRAINT node_pools_provider_check
+    CHECK (provider IN ('hetzner', 'scaleway', 'ovh', 'exoscale', 'contabo', 'digitalocean'));
+
+ALTER TABLE philotes.instance_type_pricing DROP CONSTRAINT IF EXISTS instance_type_pricing_provider_check;
+ALTER TABLE philotes.instance_type_pricing ADD CONSTRAINT instance_type_pricing_provider_check
+    CHECK (provider IN ('hetzner', 'scaleway', 'ovh', 'exoscale', 'contabo', 'digitalocean'));
+
+INSERT INTO philotes.instance_type_pricing (provider, instance_type, region, hourly_cost, cpu_cores, memory_mb, disk_gb, supports_spot)
+VALUES
+    ('digitalocean', 's-2vcpu-2gb', 'fra1', 0.02679, 2, 2048, 60, false),
+    ('digitalocean', 's-2vcpu-4gb', 'fra1', 0.03571, 2, 4096, 80, false),
+    ('digitalocean', 's-4vcpu-8gb', 'fra1', 0.07143, 4, 8192, 160, false),
+    ('digitalocean', 's-8vcpu-16gb', 'fra1', 0.14286, 8, 16384, 320, false),
+    ('digitalocean', 's-2vcpu-4gb', 'ams3', 0.03571, 2, 4096, 80, false),
+    ('digitalocean', 's-4vcpu-8gb', 'ams3', 0.07143, 4, 8192, 160, false)
+ON CONFLICT (provider, instance_type, region) DO UPDATE SET
+    hourly_cost = EXCLUDED.hourly_cost,
+    cpu_cores = EXCLUDED.cpu_cores,
+    memory_mb = EXCLUDED.memory_mb,
+    disk_gb = EXCLUDED.disk_gb,
+    supports_spot = EXCLUDED.supports_spot,
+    last_updated = NOW();
@@ -0,0 +1,7 @@
+-- 46-deployment-delete-protection.sql
+-- Deployments with delete protection cannot be destroyed or deleted until
+-- it is disabled.
+
+ALTER TABLE philotes.deployments ADD COLUMN IF NOT EXISTS delete_protection BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN philotes.deployments.delete_protection IS 'Whether destroying or deleting the deployment is refused';
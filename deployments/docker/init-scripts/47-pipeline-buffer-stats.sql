@@ -0,0 +1,18 @@
+-- 47-pipeline-buffer-stats.sql
+-- Buffer state of pipelines registered through the API. The worker replaces
+-- its pipeline's row after each buffer cleanup run; the API reads it, since
+-- the two run separately.
+
+CREATE TABLE IF NOT EXISTS philotes.pipeline_buffer_stats (
+    pipeline_id UUID PRIMARY KEY REFERENCES philotes.pipelines(id) ON DELETE CASCADE,
+    unprocessed_events BIGINT NOT NULL DEFAULT 0,
+    processed_events BIGINT NOT NULL DEFAULT 0,
+    oldest_unprocessed_at TIMESTAMP WITH TIME ZONE,
+    last_cleanup_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_cleanup_deleted BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE philotes.pipeline_buffer_stats IS 'Buffer counts and last cleanup run of each pipeline, written by the worker';
+COMMENT ON COLUMN philotes.pipeline_buffer_stats.processed_events IS 'Processed events still retained in the buffer';
+COMMENT ON COLUMN philotes.pipeline_buffer_stats.last_cleanup_deleted IS 'Processed events deleted by the last cleanup run';
@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/ovh/pulumi-ovh/sdk v0.48.0
 	github.com/pulumi/pulumi-command/sdk v1.0.1
+	github.com/pulumi/pulumi-digitalocean/sdk/v4 v4.40.0
 	github.com/pulumi/pulumi-hcloud/sdk v1.21.2
 	github.com/pulumi/pulumi-kubernetes/sdk/v4 v4.18.3
 	github.com/pulumi/pulumi/sdk/v3 v3.190.0
@@ -11,6 +11,7 @@ import (
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/platform"
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider/contabo"
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider/digitalocean"
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider/exoscale"
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider/hetzner"
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider/ovh"
@@ -25,6 +26,10 @@ func main() {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if cfg.Mode == config.ModeExistingCluster {
+			return deployToCluster(ctx, cfg)
+		}
+
 		// Select cloud provider
 		cloudProvider, err := selectProvider(cfg)
 		if err != nil {
@@ -56,6 +61,22 @@ func main() {
 	})
 }
 
+// deployToCluster installs Philotes on an existing cluster.
+func deployToCluster(ctx *pulumi.Context, cfg *config.Config) error {
+	ctx.Log.Info(fmt.Sprintf("Deploying Philotes to an existing cluster in %s environment", cfg.Environment), nil)
+
+	result, err := platform.DeployToCluster(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to deploy platform: %w", err)
+	}
+
+	ctx.Export("provider", pulumi.String(config.ModeExistingCluster))
+	ctx.Export("environment", pulumi.String(cfg.Environment))
+	ctx.Export("kubeconfig", pulumi.ToSecret(result.Kubeconfig).(pulumi.StringOutput))
+
+	return nil
+}
+
 // selectProvider creates the appropriate cloud provider based on configuration.
 func selectProvider(cfg *config.Config) (provider.CloudProvider, error) {
 	switch cfg.Provider {
@@ -69,7 +90,9 @@ func selectProvider(cfg *config.Config) (provider.CloudProvider, error) {
 		return exoscale.New(cfg.Region), nil
 	case "contabo":
 		return contabo.New(cfg.Region), nil
+	case "digitalocean":
+		return digitalocean.New(cfg.Region), nil
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s (supported: hetzner, scaleway, ovh, exoscale, contabo)", cfg.Provider)
+		return nil, fmt.Errorf("unsupported provider: %s (supported: hetzner, scaleway, ovh, exoscale, contabo, digitalocean)", cfg.Provider)
 	}
 }
@@ -13,9 +13,18 @@ import (
 	"github.com/janovincze/philotes/deployments/pulumi/pkg/sshkeys"
 )
 
+// ModeExistingCluster is the deployment mode installing Philotes on an
+// existing cluster instead of provisioning one.
+const ModeExistingCluster = "existing-cluster"
+
 // Config holds all configuration for a Philotes deployment.
 type Config struct {
-	// Provider is the cloud provider name (hetzner, scaleway).
+	// Mode is ModeExistingCluster for a deployment to an existing cluster,
+	// and empty when the cluster is provisioned.
+	Mode string
+	// Kubeconfig is the kubeconfig of an existing cluster.
+	Kubeconfig pulumi.StringOutput
+	// Provider is the cloud provider name (hetzner, scaleway, ovh, exoscale, contabo, digitalocean).
 	Provider string
 	// Region is the cloud provider region.
 	Region string
@@ -97,10 +106,23 @@ func ContaboDefaults() map[string]string {
 	}
 }
 
+// DigitalOceanDefaults returns default values for DigitalOcean.
+func DigitalOceanDefaults() map[string]string {
+	return map[string]string{
+		"region":           "fra1",
+		"controlPlaneType": "s-2vcpu-4gb",
+		"workerType":       "s-4vcpu-8gb",
+	}
+}
+
 // LoadConfig loads configuration from the Pulumi stack.
 func LoadConfig(ctx *pulumi.Context) (*Config, error) {
 	cfg := pulumiconfig.New(ctx, "philotes")
 
+	if cfg.Get("mode") == ModeExistingCluster {
+		return loadClusterConfig(cfg), nil
+	}
+
 	provider := cfg.Get("provider")
 	if provider == "" {
 		provider = "hetzner"
@@ -119,8 +141,10 @@ func LoadConfig(ctx *pulumi.Context) (*Config, error) {
 		defaults = ExoscaleDefaults()
 	case "contabo":
 		defaults = ContaboDefaults()
+	case "digitalocean":
+		defaults = DigitalOceanDefaults()
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s (supported: hetzner, scaleway, ovh, exoscale, contabo)", provider)
+		return nil, fmt.Errorf("unsupported provider: %s (supported: hetzner, scaleway, ovh, exoscale, contabo, digitalocean)", provider)
 	}
 
 	region := cfg.Get("region")
@@ -221,24 +245,7 @@ func LoadConfig(ctx *pulumi.Context) (*Config, error) {
 		return nil, fmt.Errorf("failed to load SSH private key: %w", err)
 	}
 
-	// Helm chart configuration
-	chartRegistry := cfg.Get("chartRegistry")
-	if chartRegistry == "" {
-		chartRegistry = "oci://ghcr.io/janovincze/philotes/charts"
-	}
-
-	chartVersion := cfg.Get("chartVersion")
-	if chartVersion == "" {
-		chartVersion = "0.1.0"
-	}
-
-	useLocalChartsStr := cfg.Get("useLocalCharts")
-	useLocalCharts := false
-	if useLocalChartsStr == "true" {
-		useLocalCharts = true
-	}
-
-	return &Config{
+	c := &Config{
 		Provider:          provider,
 		Region:            region,
 		Environment:       environment,
@@ -250,10 +257,42 @@ func LoadConfig(ctx *pulumi.Context) (*Config, error) {
 		SSHPrivateKey:     sshPrivateKey,
 		SSHKeySource:      sshKeySource,
 		SSHPrivateKeyPath: sshPrivateKeyPath, // Kept for backward compatibility
-		ChartRegistry:     chartRegistry,
-		ChartVersion:      chartVersion,
-		UseLocalCharts:    useLocalCharts,
-	}, nil
+	}
+	loadChartConfig(cfg, c)
+	return c, nil
+}
+
+// loadClusterConfig loads the configuration of a deployment to an existing
+// cluster, which needs its kubeconfig instead of cloud infrastructure
+// settings and SSH keys.
+func loadClusterConfig(cfg *pulumiconfig.Config) *Config {
+	environment := cfg.Get("environment")
+	if environment == "" {
+		environment = "dev"
+	}
+
+	c := &Config{
+		Mode:        ModeExistingCluster,
+		Kubeconfig:  cfg.RequireSecret("kubeconfig"),
+		Environment: environment,
+	}
+	loadChartConfig(cfg, c)
+	return c
+}
+
+// loadChartConfig loads the Helm chart configuration into c.
+func loadChartConfig(cfg *pulumiconfig.Config, c *Config) {
+	c.ChartRegistry = cfg.Get("chartRegistry")
+	if c.ChartRegistry == "" {
+		c.ChartRegistry = "oci://ghcr.io/janovincze/philotes/charts"
+	}
+
+	c.ChartVersion = cfg.Get("chartVersion")
+	if c.ChartVersion == "" {
+		c.ChartVersion = "0.1.0"
+	}
+
+	c.UseLocalCharts = cfg.Get("useLocalCharts") == "true"
 }
 
 // ResourceName returns a prefixed resource name for the environment.
@@ -47,6 +47,8 @@ func EstimateCost(providerName string, cfg *config.Config) *CostEstimate {
 		return estimateExoscale(cfg)
 	case "contabo":
 		return estimateContabo(cfg)
+	case "digitalocean":
+		return estimateDigitalOcean(cfg)
 	default:
 		return &CostEstimate{
 			Provider: providerName,
@@ -233,3 +235,37 @@ func contaboServerCost(planType string) float64 {
 	}
 	return 10.0 // conservative default
 }
+
+// estimateDigitalOcean calculates costs for DigitalOcean.
+// Prices as of 2024 (USD list prices converted to EUR/month).
+func estimateDigitalOcean(cfg *config.Config) *CostEstimate {
+	cpCost := digitalOceanServerCost(cfg.ControlPlaneType)
+	workerCost := digitalOceanServerCost(cfg.WorkerType) * float64(cfg.WorkerCount)
+	storageCost := float64(cfg.StorageSizeGB) * 0.09 // €0.09/GB/month for volumes
+	lbCost := 11.00                                  // 1 LB node
+
+	return &CostEstimate{
+		Provider:     "digitalocean",
+		ControlPlane: cpCost,
+		Workers:      workerCost,
+		Storage:      storageCost,
+		LoadBalancer: lbCost,
+		Total:        cpCost + workerCost + storageCost + lbCost,
+		Currency:     "EUR",
+	}
+}
+
+// digitalOceanServerCost returns the monthly cost for a DigitalOcean droplet size.
+func digitalOceanServerCost(size string) float64 {
+	costs := map[string]float64{
+		"s-1vcpu-2gb":  11.00, // 1 vCPU, 2GB RAM
+		"s-2vcpu-2gb":  16.50, // 2 vCPU, 2GB RAM
+		"s-2vcpu-4gb":  22.00, // 2 vCPU, 4GB RAM
+		"s-4vcpu-8gb":  44.00, // 4 vCPU, 8GB RAM
+		"s-8vcpu-16gb": 88.00, // 8 vCPU, 16GB RAM
+	}
+	if cost, ok := costs[size]; ok {
+		return cost
+	}
+	return 44.0 // conservative default
+}
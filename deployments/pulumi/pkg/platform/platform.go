@@ -174,3 +174,25 @@ func Deploy(ctx *pulumi.Context, cp provider.CloudProvider, cfg *config.Config)
 		Kubeconfig:     kubeconfig,
 	}, nil
 }
+
+// DeployToCluster installs the Philotes Helm chart on an existing cluster,
+// reached with the configured kubeconfig. The cluster's own ingress,
+// certificates and monitoring are left as they are.
+func DeployToCluster(ctx *pulumi.Context, cfg *config.Config) (*DeployResult, error) {
+	k8sProvider, err := kubernetes.NewProvider(ctx, cfg.ResourceName("k8s"), &kubernetes.ProviderArgs{
+		Kubeconfig: cfg.Kubeconfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("k8s provider creation failed: %w", err)
+	}
+
+	if _, err := DeployPhilotes(ctx, cfg, k8sProvider); err != nil {
+		return nil, fmt.Errorf("philotes deployment failed: %w", err)
+	}
+
+	return &DeployResult{
+		ControlPlaneIP: pulumi.String("").ToStringOutput(),
+		LoadBalancerIP: pulumi.String("").ToStringOutput(),
+		Kubeconfig:     cfg.Kubeconfig,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package digitalocean
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-digitalocean/sdk/v4/go/digitalocean"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
+)
+
+// CreateServer creates a DigitalOcean droplet with cloud-init.
+func (p *Provider) CreateServer(ctx *pulumi.Context, name string, opts provider.ServerOptions) (*provider.ServerResult, error) {
+	image := opts.Image
+	if image == "" {
+		image = "ubuntu-24-04-x64"
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = p.region
+	}
+
+	sshKey, err := digitalocean.NewSshKey(ctx, name+"-key", &digitalocean.SshKeyArgs{
+		Name:      pulumi.String(name + "-key"),
+		PublicKey: pulumi.String(opts.SSHPublicKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSH key: %w", err)
+	}
+
+	// DigitalOcean has tags instead of labels, so labels become "key:value" tags
+	tags := pulumi.StringArray{pulumi.String("managed-by:pulumi"), pulumi.String("project:philotes")}
+	for k, v := range opts.Labels {
+		tags = append(tags, pulumi.String(k+":"+v))
+	}
+
+	// Apply the firewall through its tag
+	if opts.FirewallID != (pulumi.IDOutput{}) {
+		tags = append(tags, p.firewallTag)
+	}
+
+	dropletArgs := &digitalocean.DropletArgs{
+		Name:    pulumi.String(name),
+		Size:    pulumi.String(opts.ServerType),
+		Image:   pulumi.String(image),
+		Region:  pulumi.String(region),
+		SshKeys: pulumi.StringArray{sshKey.Fingerprint},
+		Tags:    tags,
+	}
+
+	// Add cloud-init user data if provided
+	if opts.UserData != nil {
+		dropletArgs.UserData = opts.UserData.ToStringOutput()
+	}
+
+	// Place in the VPC if specified
+	if opts.NetworkID != (pulumi.IDOutput{}) {
+		dropletArgs.VpcUuid = opts.NetworkID.ToStringOutput()
+	}
+
+	droplet, err := digitalocean.NewDroplet(ctx, name, dropletArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create droplet: %w", err)
+	}
+
+	return &provider.ServerResult{
+		ServerID:  droplet.ID(),
+		PublicIP:  droplet.Ipv4Address,
+		PrivateIP: droplet.Ipv4AddressPrivate,
+		SSHKeyID:  sshKey.ID(),
+	}, nil
+}
+
+// dropletID converts a droplet ID to the integer the DigitalOcean API expects.
+func dropletID(id pulumi.IDOutput) pulumi.IntOutput {
+	return id.ToStringOutput().ApplyT(func(id string) int {
+		var i int
+		fmt.Sscanf(id, "%d", &i)
+		return i
+	}).(pulumi.IntOutput)
+}
@@ -0,0 +1,61 @@
+package digitalocean
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-digitalocean/sdk/v4/go/digitalocean"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
+)
+
+// CreateLoadBalancer creates a DigitalOcean load balancer.
+func (p *Provider) CreateLoadBalancer(ctx *pulumi.Context, name string, opts provider.LBOptions) (*provider.LBResult, error) {
+	region := opts.Region
+	if region == "" {
+		region = p.region
+	}
+
+	// Forward TCP as-is so TLS terminates at the ingress controller
+	var rules digitalocean.LoadBalancerForwardingRuleArray
+	for _, port := range opts.Ports {
+		rules = append(rules, &digitalocean.LoadBalancerForwardingRuleArgs{
+			EntryPort:      pulumi.Int(port.ListenPort),
+			EntryProtocol:  pulumi.String(port.Protocol),
+			TargetPort:     pulumi.Int(port.TargetPort),
+			TargetProtocol: pulumi.String(port.Protocol),
+		})
+	}
+
+	var dropletIDs pulumi.IntArray
+	for _, serverID := range opts.TargetServerIDs {
+		dropletIDs = append(dropletIDs, dropletID(serverID))
+	}
+
+	lbArgs := &digitalocean.LoadBalancerArgs{
+		Name:            pulumi.String(name),
+		Region:          pulumi.String(region),
+		SizeUnit:        pulumi.Int(1),
+		ForwardingRules: rules,
+		DropletIds:      dropletIDs,
+		Healthcheck: &digitalocean.LoadBalancerHealthcheckArgs{
+			Protocol: pulumi.String("tcp"),
+			Port:     pulumi.Int(80),
+		},
+	}
+
+	// Reach the droplets over the VPC if specified
+	if opts.NetworkID != (pulumi.IDOutput{}) {
+		lbArgs.VpcUuid = opts.NetworkID.ToStringOutput()
+	}
+
+	lb, err := digitalocean.NewLoadBalancer(ctx, name, lbArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create load balancer: %w", err)
+	}
+
+	return &provider.LBResult{
+		LBID:     lb.ID(),
+		PublicIP: lb.Ip,
+	}, nil
+}
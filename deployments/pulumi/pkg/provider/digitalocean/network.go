@@ -0,0 +1,107 @@
+package digitalocean
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-digitalocean/sdk/v4/go/digitalocean"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
+)
+
+// CreateNetwork creates a DigitalOcean VPC.
+func (p *Provider) CreateNetwork(ctx *pulumi.Context, name string, opts provider.NetworkOptions) (*provider.NetworkResult, error) {
+	cidr := opts.CIDRBlock
+	if cidr == "" {
+		cidr = "10.0.0.0/16"
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = p.region
+	}
+
+	vpc, err := digitalocean.NewVpc(ctx, name, &digitalocean.VpcArgs{
+		Name:        pulumi.String(name),
+		Region:      pulumi.String(region),
+		IpRange:     pulumi.String(cidr),
+		Description: pulumi.String("Philotes cluster network"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VPC: %w", err)
+	}
+
+	return &provider.NetworkResult{
+		NetworkID: vpc.ID(),
+		SubnetID:  vpc.ID(), // DigitalOcean VPCs have no separate subnet resources
+	}, nil
+}
+
+// CreateFirewall creates a DigitalOcean cloud firewall. The firewall is
+// applied to droplets by tag; see Provider.firewallTag.
+func (p *Provider) CreateFirewall(ctx *pulumi.Context, name string, rules []provider.FirewallRule) (*provider.FirewallResult, error) {
+	tag, err := digitalocean.NewTag(ctx, name+"-tag", &digitalocean.TagArgs{
+		Name: pulumi.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firewall tag: %w", err)
+	}
+
+	var inbound digitalocean.FirewallInboundRuleArray
+	var outbound digitalocean.FirewallOutboundRuleArray
+	for _, rule := range rules {
+		// ICMP rules take no port range
+		var portRange pulumi.StringPtrInput
+		if rule.Protocol != "icmp" {
+			portRange = pulumi.String(rule.Port)
+		}
+
+		switch rule.Direction {
+		case "in":
+			inbound = append(inbound, &digitalocean.FirewallInboundRuleArgs{
+				Protocol:        pulumi.String(rule.Protocol),
+				PortRange:       portRange,
+				SourceAddresses: pulumi.ToStringArray(rule.SourceIPs),
+			})
+		case "out":
+			outbound = append(outbound, &digitalocean.FirewallOutboundRuleArgs{
+				Protocol:             pulumi.String(rule.Protocol),
+				PortRange:            portRange,
+				DestinationAddresses: pulumi.ToStringArray(rule.SourceIPs),
+			})
+		}
+	}
+
+	// DigitalOcean firewalls deny all traffic that no rule allows, outbound
+	// included, so allow all outbound traffic unless rules were given for it
+	if len(outbound) == 0 {
+		anywhere := pulumi.StringArray{pulumi.String("0.0.0.0/0"), pulumi.String("::/0")}
+		for _, protocol := range []string{"tcp", "udp"} {
+			outbound = append(outbound, &digitalocean.FirewallOutboundRuleArgs{
+				Protocol:             pulumi.String(protocol),
+				PortRange:            pulumi.String("1-65535"),
+				DestinationAddresses: anywhere,
+			})
+		}
+		outbound = append(outbound, &digitalocean.FirewallOutboundRuleArgs{
+			Protocol:             pulumi.String("icmp"),
+			DestinationAddresses: anywhere,
+		})
+	}
+
+	firewall, err := digitalocean.NewFirewall(ctx, name, &digitalocean.FirewallArgs{
+		Name:          pulumi.String(name),
+		Tags:          pulumi.StringArray{tag.Name},
+		InboundRules:  inbound,
+		OutboundRules: outbound,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firewall: %w", err)
+	}
+
+	p.firewallTag = tag.Name
+
+	return &provider.FirewallResult{
+		FirewallID: firewall.ID(),
+	}, nil
+}
@@ -0,0 +1,34 @@
+// Package digitalocean implements the CloudProvider interface for DigitalOcean.
+package digitalocean
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
+)
+
+// Provider implements provider.CloudProvider for DigitalOcean.
+type Provider struct {
+	region string
+
+	// firewallTag is the tag the firewall is applied to. DigitalOcean
+	// firewalls select droplets by tag, so servers created with a firewall
+	// are given this tag.
+	firewallTag pulumi.StringOutput
+}
+
+// New creates a new DigitalOcean provider.
+func New(region string) *Provider {
+	if region == "" {
+		region = "fra1" // Frankfurt, Germany (default)
+	}
+	return &Provider{region: region}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "digitalocean"
+}
+
+// Ensure Provider implements CloudProvider at compile time.
+var _ provider.CloudProvider = (*Provider)(nil)
@@ -0,0 +1,44 @@
+package digitalocean
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-digitalocean/sdk/v4/go/digitalocean"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+
+	"github.com/janovincze/philotes/deployments/pulumi/pkg/provider"
+)
+
+// CreateVolume creates a DigitalOcean block storage volume.
+func (p *Provider) CreateVolume(ctx *pulumi.Context, name string, sizeGB int, opts provider.VolumeOptions) (*provider.VolumeResult, error) {
+	region := opts.Region
+	if region == "" {
+		region = p.region
+	}
+
+	volume, err := digitalocean.NewVolume(ctx, name, &digitalocean.VolumeArgs{
+		Name:                  pulumi.String(name),
+		Region:                pulumi.String(region),
+		Size:                  pulumi.Int(sizeGB),
+		InitialFilesystemType: pulumi.String("ext4"),
+		Tags:                  pulumi.StringArray{pulumi.String("managed-by:pulumi"), pulumi.String("project:philotes")},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume: %w", err)
+	}
+
+	// Attach to droplet if specified
+	if opts.ServerID != (pulumi.IDOutput{}) {
+		_, err = digitalocean.NewVolumeAttachment(ctx, name+"-attachment", &digitalocean.VolumeAttachmentArgs{
+			DropletId: dropletID(opts.ServerID),
+			VolumeId:  volume.ID().ToStringOutput(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach volume: %w", err)
+		}
+	}
+
+	return &provider.VolumeResult{
+		VolumeID: volume.ID(),
+	}, nil
+}
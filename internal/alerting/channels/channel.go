@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/janovincze/philotes/internal/alerting"
 )
@@ -21,6 +22,17 @@ type Channel interface {
 	Test(ctx context.Context) error
 }
 
+// ConfigError describes an invalid channel configuration setting.
+type ConfigError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
 // NewChannel creates a channel from its type and configuration.
 // This is the factory function for creating channel implementations.
 func NewChannel(channelType alerting.ChannelType, config map[string]interface{}, logger *slog.Logger) (Channel, error) {
@@ -36,12 +48,22 @@ func NewChannel(channelType alerting.ChannelType, config map[string]interface{},
 	case alerting.ChannelWebhook:
 		return NewWebhookChannel(config, logger)
 	case alerting.ChannelPagerDuty:
-		return nil, fmt.Errorf("PagerDuty channel not yet implemented")
+		return NewPagerDutyChannel(config, logger)
 	default:
 		return nil, fmt.Errorf("unsupported channel type: %s", channelType)
 	}
 }
 
+// Factory creates channel senders for the alert notifier; it can be passed
+// to Manager.SetChannelFactory.
+func Factory(channelType alerting.ChannelType, config map[string]interface{}, logger *slog.Logger) (alerting.ChannelSender, error) {
+	channel, err := NewChannel(channelType, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
 // getStringConfig safely retrieves a string value from the config map.
 func getStringConfig(config map[string]interface{}, key string) (string, bool) {
 	if v, ok := config[key]; ok {
@@ -133,16 +155,31 @@ func SeverityColor(severity alerting.AlertSeverity) string {
 	}
 }
 
-// FormatAlertTitle creates a formatted title for an alert notification.
-func FormatAlertTitle(notification alerting.Notification) string {
-	status := "FIRING"
-	if notification.Event == alerting.EventResolved {
-		status = "RESOLVED"
+// eventStatus returns the status shown in titles for a notification event.
+func eventStatus(event alerting.EventType) string {
+	switch event {
+	case alerting.EventResolved:
+		return "RESOLVED"
+	case alerting.EventFlapping:
+		return "FLAPPING"
+	case alerting.EventScaling:
+		return "SCALING"
+	default:
+		return "FIRING"
+	}
+}
+
+// FormatAlertTitle creates a formatted title for an alert notification,
+// using the severity labels of format.
+func FormatAlertTitle(notification alerting.Notification, format Format) string {
+	status := eventStatus(notification.Event)
+	if len(notification.Grouped) > 0 {
+		status = fmt.Sprintf("%s:%d", status, len(notification.Grouped)+1)
 	}
 
 	severity := ""
 	if notification.Rule != nil {
-		severity = string(notification.Rule.Severity)
+		severity = format.Severity(notification.Rule.Severity)
 	}
 
 	ruleName := "Unknown Rule"
@@ -156,12 +193,37 @@ func FormatAlertTitle(notification alerting.Notification) string {
 	return fmt.Sprintf("[%s] %s", status, ruleName)
 }
 
-// FormatAlertDescription creates a formatted description for an alert notification.
+// FormatAlertDescription creates a formatted description for an alert
+// notification, listing the other alerts of a grouped notification after it.
 func FormatAlertDescription(notification alerting.Notification) string {
+	description := formatSingleDescription(notification)
+	if len(notification.Grouped) == 0 {
+		return description
+	}
+
+	var b strings.Builder
+	b.WriteString(description)
+	fmt.Fprintf(&b, "\n\n%d more alerts in this group:", len(notification.Grouped))
+	for _, grouped := range notification.Grouped {
+		name := "Unknown Rule"
+		if grouped.Rule != nil {
+			name = grouped.Rule.Name
+		}
+		fmt.Fprintf(&b, "\n- [%s] %s: %s", eventStatus(grouped.Event), name, formatSingleDescription(grouped))
+	}
+	return b.String()
+}
+
+// formatSingleDescription describes one alert of a notification.
+func formatSingleDescription(notification alerting.Notification) string {
 	if notification.Rule != nil && notification.Rule.Description != "" {
 		return notification.Rule.Description
 	}
 
+	if notification.Alert != nil && notification.Alert.CurrentValue != nil && notification.Rule != nil && notification.Rule.Expression != "" {
+		return fmt.Sprintf("Expression %s holds (value: %.2f)", notification.Rule.Expression, *notification.Alert.CurrentValue)
+	}
+
 	if notification.Alert != nil && notification.Alert.CurrentValue != nil && notification.Rule != nil {
 		return fmt.Sprintf("Metric %s is %s %.2f (threshold: %.2f)",
 			notification.Rule.MetricName,
@@ -23,6 +23,7 @@ type EmailChannel struct {
 	from     string
 	to       []string
 	useTLS   bool
+	format   Format
 	logger   *slog.Logger
 }
 
@@ -56,6 +57,11 @@ func NewEmailChannel(config map[string]interface{}, logger *slog.Logger) (*Email
 		useTLS = v
 	}
 
+	format, err := FormatFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("email channel: %w", err)
+	}
+
 	return &EmailChannel{
 		smtpHost: smtpHost,
 		smtpPort: smtpPort,
@@ -64,6 +70,7 @@ func NewEmailChannel(config map[string]interface{}, logger *slog.Logger) (*Email
 		from:     from,
 		to:       to,
 		useTLS:   useTLS,
+		format:   format,
 		logger:   logger.With("component", "email-channel"),
 	}, nil
 }
@@ -75,7 +82,7 @@ func (c *EmailChannel) Type() alerting.ChannelType {
 
 // Send sends a notification via email.
 func (c *EmailChannel) Send(ctx context.Context, notification alerting.Notification) error {
-	subject := FormatAlertTitle(notification)
+	subject := FormatAlertTitle(notification, c.format)
 	body, err := c.buildHTMLBody(notification)
 	if err != nil {
 		return fmt.Errorf("failed to build email body: %w", err)
@@ -146,7 +153,7 @@ func (c *EmailChannel) buildTestEmailBody() (string, error) {
 	data := struct {
 		SentAt string
 	}{
-		SentAt: time.Now().Format(time.RFC3339),
+		SentAt: c.format.Time(time.Now()),
 	}
 
 	tmpl, err := template.New("test-email").Parse(testEmailTemplate)
@@ -407,7 +414,7 @@ type emailTemplateData struct {
 // buildTemplateData builds template data from a notification.
 func (c *EmailChannel) buildTemplateData(notification alerting.Notification) emailTemplateData {
 	data := emailTemplateData{
-		Title:       FormatAlertTitle(notification),
+		Title:       FormatAlertTitle(notification, c.format),
 		HeaderColor: SeverityColor(alerting.SeverityInfo),
 		Status:      "FIRING",
 		StatusClass: "status-firing",
@@ -423,18 +430,22 @@ func (c *EmailChannel) buildTemplateData(notification alerting.Notification) ema
 	if notification.Rule != nil {
 		data.HeaderColor = SeverityColor(notification.Rule.Severity)
 		data.Description = notification.Rule.Description
-		data.Severity = string(notification.Rule.Severity)
+		data.Severity = c.format.Severity(notification.Rule.Severity)
 		data.Metric = notification.Rule.MetricName
 		data.Threshold = fmt.Sprintf("%s %.2f", notification.Rule.Operator.String(), notification.Rule.Threshold)
+		if notification.Rule.Expression != "" {
+			data.Metric = notification.Rule.Expression
+			data.Threshold = ""
+		}
 	}
 
 	if notification.Alert != nil {
 		if notification.Alert.CurrentValue != nil {
 			data.CurrentValue = fmt.Sprintf("%.2f", *notification.Alert.CurrentValue)
 		}
-		data.FiredAt = notification.Alert.FiredAt.Format(time.RFC3339)
+		data.FiredAt = c.format.Time(notification.Alert.FiredAt)
 		if notification.Alert.ResolvedAt != nil {
-			data.ResolvedAt = notification.Alert.ResolvedAt.Format(time.RFC3339)
+			data.ResolvedAt = c.format.Time(*notification.Alert.ResolvedAt)
 		}
 		data.Labels = notification.Alert.Labels
 	}
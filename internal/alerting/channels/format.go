@@ -0,0 +1,114 @@
+package channels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+// DefaultTimeFormat is the layout used for timestamps when a channel does not
+// configure one.
+const DefaultTimeFormat = "2006-01-02 15:04:05"
+
+// timeFormatPresets are named layouts accepted for the time_format setting.
+// Any other value is used as a Go time layout.
+var timeFormatPresets = map[string]string{
+	"iso":     DefaultTimeFormat,
+	"rfc3339": time.RFC3339,
+	"us":      "01/02/2006 03:04:05 PM",
+	"eu":      "02/01/2006 15:04:05",
+	"de":      "02.01.2006 15:04:05",
+}
+
+// Format controls how a channel presents times and severities to its
+// recipients. Timestamps always carry an explicit zone label, so a reader
+// never has to guess which timezone an alert was rendered in.
+type Format struct {
+	// Location is the timezone timestamps are rendered in.
+	Location *time.Location
+
+	// TimeFormat is the Go layout for timestamps, without the zone.
+	TimeFormat string
+
+	// SeverityLabels replaces severity names, e.g. for localized labels.
+	SeverityLabels map[alerting.AlertSeverity]string
+}
+
+// DefaultFormat renders timestamps in UTC with DefaultTimeFormat.
+func DefaultFormat() Format {
+	return Format{
+		Location:   time.UTC,
+		TimeFormat: DefaultTimeFormat,
+	}
+}
+
+// FormatFromConfig reads the presentation settings shared by all channel
+// types: timezone (an IANA name such as "Europe/Berlin"), time_format (a
+// preset or a Go layout) and severity_labels (a map from severity to label).
+func FormatFromConfig(config map[string]interface{}) (Format, error) {
+	format := DefaultFormat()
+
+	if tz, ok := getStringConfig(config, "timezone"); ok && tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return Format{}, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		format.Location = loc
+	}
+
+	if layout, ok := getStringConfig(config, "time_format"); ok && layout != "" {
+		if preset, ok := timeFormatPresets[strings.ToLower(layout)]; ok {
+			layout = preset
+		}
+		format.TimeFormat = layout
+	}
+
+	if labels, ok := getMapConfig(config, "severity_labels"); ok {
+		format.SeverityLabels = make(map[alerting.AlertSeverity]string, len(labels))
+		for severity, label := range labels {
+			s := alerting.AlertSeverity(strings.ToLower(severity))
+			if !s.IsValid() {
+				return Format{}, fmt.Errorf("invalid severity %q in severity_labels", severity)
+			}
+			format.SeverityLabels[s] = label
+		}
+	}
+
+	return format, nil
+}
+
+// Time renders t in the configured timezone followed by its zone label, e.g.
+// "2024-03-10 09:30:00 EDT (UTC-04:00)" or "2024-03-10 13:30:00 UTC".
+func (f Format) Time(t time.Time) string {
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	layout := f.TimeFormat
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+
+	local := t.In(loc)
+	if loc == time.UTC {
+		return local.Format(layout) + " UTC"
+	}
+
+	offset := "UTC" + local.Format("-07:00")
+	abbr := local.Format("MST")
+	if strings.HasPrefix(abbr, "+") || strings.HasPrefix(abbr, "-") {
+		// Zones without an abbreviation only have a numeric name
+		return local.Format(layout) + " " + offset
+	}
+	return local.Format(layout) + " " + abbr + " (" + offset + ")"
+}
+
+// Severity returns the label for a severity.
+func (f Format) Severity(severity alerting.AlertSeverity) string {
+	if label, ok := f.SeverityLabels[severity]; ok && label != "" {
+		return label
+	}
+	return string(severity)
+}
@@ -0,0 +1,146 @@
+package channels
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+func testNotification() alerting.Notification {
+	resolvedAt := time.Date(2024, 7, 1, 15, 45, 0, 0, time.UTC)
+	return alerting.Notification{
+		Event: alerting.EventResolved,
+		Rule: &alerting.AlertRule{
+			ID:         uuid.New(),
+			Name:       "replication_lag",
+			MetricName: "philotes_cdc_lag_seconds",
+			Severity:   alerting.SeverityCritical,
+		},
+		Alert: &alerting.AlertInstance{
+			ID:         uuid.New(),
+			Status:     alerting.StatusResolved,
+			FiredAt:    time.Date(2024, 7, 1, 14, 30, 0, 0, time.UTC),
+			ResolvedAt: &resolvedAt,
+		},
+	}
+}
+
+func slackFieldValue(msg slackMessage, title string) string {
+	for _, f := range msg.Attachments[0].Fields {
+		if f.Title == title {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestFormat_SameAlertInTwoTimezones(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	notification := testNotification()
+
+	tests := []struct {
+		timezone       string
+		wantFiredAt    string
+		wantResolvedAt string
+	}{
+		{"America/New_York", "2024-07-01 10:30:00 EDT (UTC-04:00)", "2024-07-01 11:45:00 EDT (UTC-04:00)"},
+		{"Asia/Tokyo", "2024-07-01 23:30:00 JST (UTC+09:00)", "2024-07-02 00:45:00 JST (UTC+09:00)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			config := map[string]interface{}{"timezone": tt.timezone}
+
+			config["webhook_url"] = "https://hooks.slack.com/services/test"
+			slack, err := NewSlackChannel(config, logger)
+			if err != nil {
+				t.Fatalf("NewSlackChannel() error = %v", err)
+			}
+			msg := slack.buildMessage(notification)
+			if got := slackFieldValue(msg, "Fired At"); got != tt.wantFiredAt {
+				t.Errorf("slack Fired At = %q, want %q", got, tt.wantFiredAt)
+			}
+			if got := slackFieldValue(msg, "Resolved At"); got != tt.wantResolvedAt {
+				t.Errorf("slack Resolved At = %q, want %q", got, tt.wantResolvedAt)
+			}
+
+			config["smtp_host"] = "smtp.example.com"
+			config["from"] = "alerts@example.com"
+			config["to"] = []string{"oncall@example.com"}
+			email, err := NewEmailChannel(config, logger)
+			if err != nil {
+				t.Fatalf("NewEmailChannel() error = %v", err)
+			}
+			data := email.buildTemplateData(notification)
+			if data.FiredAt != tt.wantFiredAt || data.ResolvedAt != tt.wantResolvedAt {
+				t.Errorf("email times = %q, %q, want %q, %q", data.FiredAt, data.ResolvedAt, tt.wantFiredAt, tt.wantResolvedAt)
+			}
+
+			config["url"] = "https://example.com/hook"
+			webhook, err := NewWebhookChannel(config, logger)
+			if err != nil {
+				t.Fatalf("NewWebhookChannel() error = %v", err)
+			}
+			payload := webhook.buildPayload(notification)
+			if payload.Display.Timezone != tt.timezone || payload.Display.FiredAt != tt.wantFiredAt {
+				t.Errorf("webhook display = %+v, want %s at %q", payload.Display, tt.timezone, tt.wantFiredAt)
+			}
+			if !payload.Alert.FiredAt.Equal(notification.Alert.FiredAt) {
+				t.Errorf("webhook fired_at = %v, want the original instant", payload.Alert.FiredAt)
+			}
+		})
+	}
+}
+
+func TestFormat_DefaultsToLabeledUTC(t *testing.T) {
+	format, err := FormatFromConfig(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("FormatFromConfig() error = %v", err)
+	}
+	fired := time.Date(2024, 7, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	if got, want := format.Time(fired), "2024-07-01 12:30:00 UTC"; got != want {
+		t.Errorf("Time() = %q, want %q", got, want)
+	}
+}
+
+func TestFormat_TimeFormatAndSeverityLabels(t *testing.T) {
+	format, err := FormatFromConfig(map[string]interface{}{
+		"timezone":        "Europe/Berlin",
+		"time_format":     "de",
+		"severity_labels": map[string]interface{}{"critical": "Kritisch"},
+	})
+	if err != nil {
+		t.Fatalf("FormatFromConfig() error = %v", err)
+	}
+
+	fired := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+	if got, want := format.Time(fired), "15.01.2024 09:00:00 CET (UTC+01:00)"; got != want {
+		t.Errorf("Time() = %q, want %q", got, want)
+	}
+	if got := FormatAlertTitle(testNotification(), format); got != "[RESOLVED] Kritisch: replication_lag" {
+		t.Errorf("FormatAlertTitle() = %q", got)
+	}
+	if got := format.Severity(alerting.SeverityWarning); got != "warning" {
+		t.Errorf("Severity(warning) = %q, want the unlocalized name", got)
+	}
+}
+
+func TestFormatFromConfig_Invalid(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"timezone": "Mars/Olympus_Mons"},
+		{"severity_labels": map[string]interface{}{"fatal": "Fatal"}},
+	}
+	for _, config := range tests {
+		if _, err := FormatFromConfig(config); err == nil {
+			t.Errorf("FormatFromConfig(%v) expected an error", config)
+		}
+	}
+	if _, err := NewWebhookChannel(map[string]interface{}{"url": "https://example.com", "timezone": "Nowhere"}, slog.Default()); err == nil {
+		t.Error("NewWebhookChannel() expected an error for an invalid timezone")
+	}
+}
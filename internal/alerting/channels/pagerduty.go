@@ -0,0 +1,311 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event actions.
+const (
+	pagerDutyTrigger     = "trigger"
+	pagerDutyAcknowledge = "acknowledge"
+	pagerDutyResolve     = "resolve"
+)
+
+// pagerDutySeverities are the severities the Events API accepts.
+var pagerDutySeverities = map[string]bool{
+	"critical": true,
+	"error":    true,
+	"warning":  true,
+	"info":     true,
+}
+
+// PagerDutyChannel implements the Channel interface for the PagerDuty
+// Events API v2. A firing alert triggers an incident keyed by the alert
+// fingerprint, so repeats update the same incident and resolving the alert
+// resolves it.
+type PagerDutyChannel struct {
+	routingKey  string
+	eventsURL   string
+	source      string
+	severityMap map[alerting.AlertSeverity]string
+	format      Format
+	httpClient  *http.Client
+	logger      *slog.Logger
+}
+
+// pagerDutyEvent is an Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+}
+
+// pagerDutyPayload describes the incident of a trigger event.
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// NewPagerDutyChannel creates a new PagerDuty notification channel.
+func NewPagerDutyChannel(config map[string]interface{}, logger *slog.Logger) (*PagerDutyChannel, error) {
+	if err := ValidatePagerDutyConfig(config); err != nil {
+		return nil, fmt.Errorf("pagerduty channel: %w", err)
+	}
+
+	routingKey, _ := getStringConfig(config, "routing_key")
+
+	eventsURL, _ := getStringConfig(config, "events_url")
+	if eventsURL == "" {
+		eventsURL = pagerDutyEventsURL
+	}
+
+	source, _ := getStringConfig(config, "source")
+	if source == "" {
+		source = "philotes"
+	}
+
+	severityMap := map[alerting.AlertSeverity]string{
+		alerting.SeverityInfo:     "info",
+		alerting.SeverityWarning:  "warning",
+		alerting.SeverityCritical: "critical",
+	}
+	overrides, _ := getMapConfig(config, "severity_map")
+	for severity, pdSeverity := range overrides {
+		severityMap[alerting.AlertSeverity(severity)] = strings.ToLower(pdSeverity)
+	}
+
+	format, err := FormatFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("pagerduty channel: %w", err)
+	}
+
+	return &PagerDutyChannel{
+		routingKey:  routingKey,
+		eventsURL:   eventsURL,
+		source:      source,
+		severityMap: severityMap,
+		format:      format,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: logger.With("component", "pagerduty-channel"),
+	}, nil
+}
+
+// ValidatePagerDutyConfig checks a PagerDuty channel configuration: the
+// routing key is required and severity_map may only map alert severities
+// to PagerDuty severities. It returns a *ConfigError naming the bad setting.
+func ValidatePagerDutyConfig(config map[string]interface{}) error {
+	routingKey, ok := getStringConfig(config, "routing_key")
+	if !ok || routingKey == "" {
+		return &ConfigError{Field: "routing_key", Message: "routing_key is required"}
+	}
+
+	if raw, ok := config["severity_map"]; ok {
+		overrides, ok := getMapConfig(config, "severity_map")
+		if m, isMap := raw.(map[string]interface{}); !ok && (!isMap || len(m) > 0) {
+			return &ConfigError{Field: "severity_map", Message: "severity_map must map alert severities to PagerDuty severities"}
+		}
+		for severity, pdSeverity := range overrides {
+			if !alerting.AlertSeverity(severity).IsValid() {
+				return &ConfigError{Field: "severity_map", Message: fmt.Sprintf("unknown alert severity %q: must be info, warning or critical", severity)}
+			}
+			if !pagerDutySeverities[strings.ToLower(pdSeverity)] {
+				return &ConfigError{Field: "severity_map", Message: fmt.Sprintf("unknown PagerDuty severity %q: must be critical, error, warning or info", pdSeverity)}
+			}
+		}
+	}
+	return nil
+}
+
+// Type returns the channel type.
+func (c *PagerDutyChannel) Type() alerting.ChannelType {
+	return alerting.ChannelPagerDuty
+}
+
+// Send sends a notification to PagerDuty: firing and flapping alerts
+// trigger, acknowledged alerts acknowledge and resolved alerts resolve the
+// incident keyed by the alert fingerprint. Grouped alerts are sent as
+// events of their own, so each keeps its incident.
+func (c *PagerDutyChannel) Send(ctx context.Context, notification alerting.Notification) error {
+	for _, single := range notification.Split() {
+		if err := c.sendAlert(ctx, single); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendAlert sends the event of a single alert.
+func (c *PagerDutyChannel) sendAlert(ctx context.Context, notification alerting.Notification) error {
+	if notification.Alert == nil {
+		return fmt.Errorf("pagerduty notification has no alert")
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey: c.routingKey,
+		DedupKey:   notification.Alert.Fingerprint,
+	}
+	switch notification.Event {
+	case alerting.EventResolved:
+		event.EventAction = pagerDutyResolve
+	case alerting.EventAcknowledged:
+		event.EventAction = pagerDutyAcknowledge
+	default:
+		event.EventAction = pagerDutyTrigger
+		event.Payload = c.buildPayload(notification)
+		event.Client = "Philotes"
+	}
+
+	c.logger.Debug("sending pagerduty event",
+		"action", event.EventAction,
+		"dedup_key", event.DedupKey,
+	)
+
+	if err := c.send(ctx, event); err != nil {
+		return err
+	}
+
+	c.logger.Info("pagerduty event sent successfully",
+		"action", event.EventAction,
+		"dedup_key", event.DedupKey,
+		"event", notification.Event,
+	)
+	return nil
+}
+
+// Test triggers a low-severity test incident and immediately resolves it.
+func (c *PagerDutyChannel) Test(ctx context.Context) error {
+	dedupKey := "philotes-test-" + uuid.NewString()
+
+	trigger := pagerDutyEvent{
+		RoutingKey:  c.routingKey,
+		EventAction: pagerDutyTrigger,
+		DedupKey:    dedupKey,
+		Client:      "Philotes",
+		Payload: &pagerDutyPayload{
+			Summary:   "Test notification from Philotes",
+			Source:    c.source,
+			Severity:  "info",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Class:     "test",
+		},
+	}
+	if err := c.send(ctx, trigger); err != nil {
+		return fmt.Errorf("pagerduty test trigger failed: %w", err)
+	}
+
+	resolve := pagerDutyEvent{
+		RoutingKey:  c.routingKey,
+		EventAction: pagerDutyResolve,
+		DedupKey:    dedupKey,
+	}
+	if err := c.send(ctx, resolve); err != nil {
+		return fmt.Errorf("pagerduty test resolve failed: %w", err)
+	}
+	return nil
+}
+
+// send posts an event to the Events API.
+func (c *PagerDutyChannel) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pagerduty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.eventsURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send pagerduty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	// The Events API answers 202 Accepted
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pagerduty returned non-success status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// buildPayload builds the incident details of a trigger event.
+func (c *PagerDutyChannel) buildPayload(notification alerting.Notification) *pagerDutyPayload {
+	payload := &pagerDutyPayload{
+		Summary:   FormatAlertTitle(notification, c.format) + ": " + FormatAlertDescription(notification),
+		Source:    c.source,
+		Severity:  "info",
+		Timestamp: notification.Alert.FiredAt.UTC().Format(time.RFC3339),
+		CustomDetails: map[string]any{
+			"fingerprint": notification.Alert.Fingerprint,
+			"status":      string(notification.Alert.Status),
+			"event":       string(notification.Event),
+			"fired_at":    c.format.Time(notification.Alert.FiredAt),
+		},
+	}
+
+	if notification.Alert.CurrentValue != nil {
+		payload.CustomDetails["current_value"] = *notification.Alert.CurrentValue
+	}
+	if len(notification.Alert.Labels) > 0 {
+		payload.CustomDetails["labels"] = notification.Alert.Labels
+	}
+	if len(notification.Alert.Annotations) > 0 {
+		payload.CustomDetails["annotations"] = notification.Alert.Annotations
+	}
+
+	if notification.Rule != nil {
+		if severity, ok := c.severityMap[notification.Rule.Severity]; ok {
+			payload.Severity = severity
+		}
+		payload.Component = notification.Rule.MetricName
+		payload.Class = notification.Rule.Name
+		payload.CustomDetails["rule"] = notification.Rule.Name
+		payload.CustomDetails["threshold"] = notification.Rule.Threshold
+		payload.CustomDetails["operator"] = string(notification.Rule.Operator)
+		if notification.Rule.Expression != "" {
+			payload.CustomDetails["expression"] = notification.Rule.Expression
+		}
+	}
+
+	// PagerDuty truncates summaries at 1024 characters
+	if len(payload.Summary) > 1024 {
+		payload.Summary = payload.Summary[:1021] + "..."
+	}
+	return payload
+}
+
+// SetHTTPClient allows setting a custom HTTP client (useful for testing).
+func (c *PagerDutyChannel) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// Ensure PagerDutyChannel implements Channel interface.
+var _ Channel = (*PagerDutyChannel)(nil)
@@ -0,0 +1,133 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+// pagerDutyServer records the events posted to it.
+type pagerDutyServer struct {
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+func (s *pagerDutyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event pagerDutyEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte(`{"status":"success","dedup_key":"` + event.DedupKey + `"}`))
+}
+
+func newTestPagerDutyChannel(t *testing.T, url string, config map[string]interface{}) *PagerDutyChannel {
+	t.Helper()
+	config["events_url"] = url
+	ch, err := NewPagerDutyChannel(config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewPagerDutyChannel() error = %v", err)
+	}
+	return ch
+}
+
+func TestPagerDutyChannel_TriggerAndResolve(t *testing.T) {
+	recorder := &pagerDutyServer{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	ch := newTestPagerDutyChannel(t, server.URL, map[string]interface{}{
+		"routing_key":  "R0UT1NGKEY",
+		"severity_map": map[string]interface{}{"critical": "error"},
+	})
+
+	notification := testNotification()
+	notification.Alert.Fingerprint = "fp-123"
+	notification.Event = alerting.EventFired
+	if err := ch.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send(fired) error = %v", err)
+	}
+	notification.Event = alerting.EventResolved
+	if err := ch.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send(resolved) error = %v", err)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("got %d events, want 2", len(recorder.events))
+	}
+	trigger, resolve := recorder.events[0], recorder.events[1]
+	if trigger.EventAction != "trigger" || trigger.DedupKey != "fp-123" || trigger.RoutingKey != "R0UT1NGKEY" {
+		t.Errorf("trigger = %+v, want a trigger keyed by the fingerprint", trigger)
+	}
+	if trigger.Payload == nil || trigger.Payload.Severity != "error" || trigger.Payload.Component != "philotes_cdc_lag_seconds" {
+		t.Errorf("trigger payload = %+v, want mapped severity and metric component", trigger.Payload)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != "fp-123" || resolve.Payload != nil {
+		t.Errorf("resolve = %+v, want a resolve of the same incident", resolve)
+	}
+}
+
+func TestPagerDutyChannel_TestTriggersThenResolves(t *testing.T) {
+	recorder := &pagerDutyServer{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	ch := newTestPagerDutyChannel(t, server.URL, map[string]interface{}{"routing_key": "R0UT1NGKEY"})
+	if err := ch.Test(context.Background()); err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("got %d events, want 2", len(recorder.events))
+	}
+	trigger, resolve := recorder.events[0], recorder.events[1]
+	if trigger.EventAction != "trigger" || trigger.Payload.Severity != "info" {
+		t.Errorf("trigger = %+v, want an info trigger", trigger)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("resolve = %+v, want the test incident resolved", resolve)
+	}
+}
+
+func TestValidatePagerDutyConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		field  string
+	}{
+		{"valid", map[string]interface{}{"routing_key": "key"}, ""},
+		{"valid severity map", map[string]interface{}{"routing_key": "key", "severity_map": map[string]interface{}{"warning": "error"}}, ""},
+		{"missing routing key", map[string]interface{}{}, "routing_key"},
+		{"unknown alert severity", map[string]interface{}{"routing_key": "key", "severity_map": map[string]interface{}{"fatal": "critical"}}, "severity_map"},
+		{"unknown pagerduty severity", map[string]interface{}{"routing_key": "key", "severity_map": map[string]interface{}{"info": "low"}}, "severity_map"},
+		{"severity map not a map", map[string]interface{}{"routing_key": "key", "severity_map": "critical"}, "severity_map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePagerDutyConfig(tt.config)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("ValidatePagerDutyConfig() error = %v", err)
+				}
+				return
+			}
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.field {
+				t.Fatalf("ValidatePagerDutyConfig() error = %v, want a %s error", err, tt.field)
+			}
+		})
+	}
+}
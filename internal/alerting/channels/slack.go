@@ -18,6 +18,7 @@ type SlackChannel struct {
 	webhookURL string
 	channel    string
 	username   string
+	format     Format
 	httpClient *http.Client
 	logger     *slog.Logger
 }
@@ -70,10 +71,16 @@ func NewSlackChannel(config map[string]interface{}, logger *slog.Logger) (*Slack
 		username = "Philotes Alerts"
 	}
 
+	format, err := FormatFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("slack channel: %w", err)
+	}
+
 	return &SlackChannel{
 		webhookURL: webhookURL,
 		channel:    channel,
 		username:   username,
+		format:     format,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -151,7 +158,7 @@ func (c *SlackChannel) Test(ctx context.Context) error {
 					},
 					{
 						Title: "Time",
-						Value: time.Now().Format(time.RFC3339),
+						Value: c.format.Time(time.Now()),
 						Short: true,
 					},
 				},
@@ -187,7 +194,7 @@ func (c *SlackChannel) Test(ctx context.Context) error {
 
 // buildMessage builds a Slack message from a notification.
 func (c *SlackChannel) buildMessage(notification alerting.Notification) slackMessage {
-	title := FormatAlertTitle(notification)
+	title := FormatAlertTitle(notification, c.format)
 	description := FormatAlertDescription(notification)
 
 	severity := alerting.SeverityInfo
@@ -205,14 +212,21 @@ func (c *SlackChannel) buildMessage(notification alerting.Notification) slackMes
 	if notification.Rule != nil {
 		fields = append(fields, slackField{
 			Title: "Severity",
-			Value: string(notification.Rule.Severity),
-			Short: true,
-		})
-		fields = append(fields, slackField{
-			Title: "Metric",
-			Value: notification.Rule.MetricName,
+			Value: c.format.Severity(notification.Rule.Severity),
 			Short: true,
 		})
+		if notification.Rule.Expression != "" {
+			fields = append(fields, slackField{
+				Title: "Expression",
+				Value: notification.Rule.Expression,
+			})
+		} else {
+			fields = append(fields, slackField{
+				Title: "Metric",
+				Value: notification.Rule.MetricName,
+				Short: true,
+			})
+		}
 	}
 
 	if notification.Alert != nil && notification.Alert.CurrentValue != nil {
@@ -234,14 +248,14 @@ func (c *SlackChannel) buildMessage(notification alerting.Notification) slackMes
 	if notification.Alert != nil {
 		fields = append(fields, slackField{
 			Title: "Fired At",
-			Value: notification.Alert.FiredAt.Format(time.RFC3339),
+			Value: c.format.Time(notification.Alert.FiredAt),
 			Short: true,
 		})
 
 		if notification.Alert.ResolvedAt != nil {
 			fields = append(fields, slackField{
 				Title: "Resolved At",
-				Value: notification.Alert.ResolvedAt.Format(time.RFC3339),
+				Value: c.format.Time(*notification.Alert.ResolvedAt),
 				Short: true,
 			})
 		}
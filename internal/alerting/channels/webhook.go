@@ -3,23 +3,60 @@ package channels
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+// Webhook retry defaults.
+const (
+	defaultWebhookMaxRetries     = 3
+	defaultWebhookInitialBackoff = 500 * time.Millisecond
+	webhookMaxBackoff            = 10 * time.Second
+)
+
+// Webhook signature headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the channel secret, prefixed "sha256=".
+const (
+	WebhookSignatureHeader = "X-Philotes-Signature"
+	WebhookTimestampHeader = "X-Philotes-Timestamp"
 )
 
 // WebhookChannel implements the Channel interface for generic HTTP webhooks.
+//
+// The body is the WebhookPayload as JSON unless a body_template is
+// configured: a Go text/template rendered with the payload, whose output
+// must be JSON. The template function json encodes a value, as in
+// {"summary": {{json .Display.Title}}}. With a secret configured, each
+// request is signed in WebhookSignatureHeader.
+//
+// A request is retried with exponential backoff on network errors, 429 and
+// 5xx responses, up to max_retries times, but never past the deadline of the
+// context, which the notifier sets from the notification timeout.
 type WebhookChannel struct {
-	url        string
-	method     string
-	headers    map[string]string
-	httpClient *http.Client
-	logger     *slog.Logger
+	url            string
+	method         string
+	headers        map[string]string
+	bodyTemplate   *template.Template
+	secret         string
+	maxRetries     int
+	initialBackoff time.Duration
+	format         Format
+	httpClient     *http.Client
+	logger         *slog.Logger
 }
 
 // WebhookPayload represents the JSON payload sent to webhooks.
@@ -30,6 +67,28 @@ type WebhookPayload struct {
 	Alert     *WebhookAlertPayload   `json:"alert"`
 	Rule      *WebhookRulePayload    `json:"rule,omitempty"`
 	Channel   *WebhookChannelPayload `json:"channel,omitempty"`
+	Display   *WebhookDisplayPayload `json:"display,omitempty"`
+
+	// Group lists the other alerts of a grouped notification.
+	Group []WebhookGroupedPayload `json:"group,omitempty"`
+}
+
+// WebhookGroupedPayload is an alert batched into a grouped notification.
+type WebhookGroupedPayload struct {
+	Event string               `json:"event"`
+	Rule  string               `json:"rule"`
+	Alert *WebhookAlertPayload `json:"alert"`
+}
+
+// WebhookDisplayPayload holds human-readable values rendered with the
+// channel's timezone, time format and severity labels. The machine-readable
+// fields elsewhere in the payload are unaffected.
+type WebhookDisplayPayload struct {
+	Timezone   string `json:"timezone"`
+	Title      string `json:"title"`
+	Severity   string `json:"severity,omitempty"`
+	FiredAt    string `json:"fired_at,omitempty"`
+	ResolvedAt string `json:"resolved_at,omitempty"`
 }
 
 // WebhookAlertPayload represents alert information in the webhook payload.
@@ -54,6 +113,7 @@ type WebhookRulePayload struct {
 	MetricName      string            `json:"metric_name"`
 	Operator        string            `json:"operator"`
 	Threshold       float64           `json:"threshold"`
+	Expression      string            `json:"expression,omitempty"`
 	DurationSeconds int               `json:"duration_seconds"`
 	Severity        string            `json:"severity"`
 	Labels          map[string]string `json:"labels,omitempty"`
@@ -69,11 +129,11 @@ type WebhookChannelPayload struct {
 
 // NewWebhookChannel creates a new webhook notification channel.
 func NewWebhookChannel(config map[string]interface{}, logger *slog.Logger) (*WebhookChannel, error) {
-	url, ok := getStringConfig(config, "url")
-	if !ok || url == "" {
-		return nil, fmt.Errorf("webhook channel requires url configuration")
+	if err := ValidateWebhookConfig(config); err != nil {
+		return nil, fmt.Errorf("webhook channel: %w", err)
 	}
 
+	url, _ := getStringConfig(config, "url")
 	method, _ := getStringConfig(config, "method")
 	if method == "" {
 		method = http.MethodPost
@@ -84,10 +144,32 @@ func NewWebhookChannel(config map[string]interface{}, logger *slog.Logger) (*Web
 		headers = make(map[string]string)
 	}
 
+	var bodyTemplate *template.Template
+	if text, _ := getStringConfig(config, "body_template"); text != "" {
+		bodyTemplate, _ = parseWebhookTemplate(text)
+	}
+
+	secret, _ := getStringConfig(config, "secret")
+
+	maxRetries := defaultWebhookMaxRetries
+	if n, ok := getIntConfig(config, "max_retries"); ok {
+		maxRetries = n
+	}
+
+	format, err := FormatFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("webhook channel: %w", err)
+	}
+
 	return &WebhookChannel{
-		url:     url,
-		method:  method,
-		headers: headers,
+		url:            url,
+		method:         strings.ToUpper(method),
+		headers:        headers,
+		bodyTemplate:   bodyTemplate,
+		secret:         secret,
+		maxRetries:     maxRetries,
+		initialBackoff: defaultWebhookInitialBackoff,
+		format:         format,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -95,6 +177,46 @@ func NewWebhookChannel(config map[string]interface{}, logger *slog.Logger) (*Web
 	}, nil
 }
 
+// ValidateWebhookConfig checks a webhook channel configuration: the URL must
+// be an absolute http or https URL (or a secret reference), the method a
+// known HTTP method, max_retries non-negative, and the body template must
+// parse and render JSON. It returns a *ConfigError naming the bad setting.
+func ValidateWebhookConfig(config map[string]interface{}) error {
+	raw, ok := getStringConfig(config, "url")
+	if !ok || raw == "" {
+		return &ConfigError{Field: "url", Message: "url is required"}
+	}
+	if !vault.IsReference(raw) {
+		u, err := neturl.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ConfigError{Field: "url", Message: "url must be an absolute http or https URL"}
+		}
+	}
+
+	if method, _ := getStringConfig(config, "method"); method != "" {
+		switch strings.ToUpper(method) {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return &ConfigError{Field: "method", Message: "method must be POST, PUT or PATCH"}
+		}
+	}
+
+	if n, ok := getIntConfig(config, "max_retries"); ok && n < 0 {
+		return &ConfigError{Field: "max_retries", Message: "max_retries cannot be negative"}
+	}
+
+	if text, _ := getStringConfig(config, "body_template"); text != "" {
+		tmpl, err := parseWebhookTemplate(text)
+		if err != nil {
+			return &ConfigError{Field: "body_template", Message: err.Error()}
+		}
+		if _, err := renderWebhookTemplate(tmpl, testWebhookPayload()); err != nil {
+			return &ConfigError{Field: "body_template", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
 // Type returns the channel type.
 func (c *WebhookChannel) Type() alerting.ChannelType {
 	return alerting.ChannelWebhook
@@ -102,13 +224,62 @@ func (c *WebhookChannel) Type() alerting.ChannelType {
 
 // Send sends a notification via webhook.
 func (c *WebhookChannel) Send(ctx context.Context, notification alerting.Notification) error {
-	payload := c.buildPayload(notification)
+	if err := c.deliver(ctx, c.buildPayload(notification)); err != nil {
+		return err
+	}
 
-	body, err := json.Marshal(payload)
+	c.logger.Info("webhook notification sent successfully",
+		"url", c.url,
+		"event", notification.Event,
+	)
+	return nil
+}
+
+// Test sends a test notification to verify the channel configuration.
+func (c *WebhookChannel) Test(ctx context.Context) error {
+	if err := c.deliver(ctx, testWebhookPayload()); err != nil {
+		return fmt.Errorf("webhook test failed: %w", err)
+	}
+	return nil
+}
+
+// deliver renders and sends a payload, retrying transient failures.
+func (c *WebhookChannel) deliver(ctx context.Context, payload WebhookPayload) error {
+	body, err := c.render(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+		return err
+	}
+
+	backoff := c.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= c.maxRetries {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+
+		c.logger.Warn("webhook request failed, retrying",
+			"url", c.url,
+			"attempt", attempt+1,
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, webhookMaxBackoff)
 	}
+}
 
+// post makes one request and reports whether a failure is worth retrying.
+func (c *WebhookChannel) post(ctx context.Context, body []byte) (bool, error) {
 	c.logger.Debug("sending webhook notification",
 		"url", c.url,
 		"method", c.method,
@@ -116,7 +287,7 @@ func (c *WebhookChannel) Send(ctx context.Context, notification alerting.Notific
 
 	req, err := http.NewRequestWithContext(ctx, c.method, c.url, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set default content type
@@ -128,9 +299,15 @@ func (c *WebhookChannel) Send(ctx context.Context, notification alerting.Notific
 		req.Header.Set(k, v)
 	}
 
+	if c.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(c.secret, timestamp, body))
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send webhook: %w", err)
+		return ctx.Err() == nil, fmt.Errorf("failed to send webhook: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -138,23 +315,66 @@ func (c *WebhookChannel) Send(ctx context.Context, notification alerting.Notific
 
 	// Accept 2xx status codes
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned non-success status %d: %s", resp.StatusCode, string(respBody))
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retryable, fmt.Errorf("webhook returned non-success status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return false, nil
+}
+
+// render encodes a payload as the request body.
+func (c *WebhookChannel) render(payload WebhookPayload) ([]byte, error) {
+	if c.bodyTemplate != nil {
+		return renderWebhookTemplate(c.bodyTemplate, payload)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
 	}
+	return body, nil
+}
 
-	c.logger.Info("webhook notification sent successfully",
-		"url", c.url,
-		"status_code", resp.StatusCode,
-		"event", notification.Event,
-	)
+// SignWebhookPayload returns the WebhookSignatureHeader value for a body
+// sent at timestamp (Unix seconds), so receivers can verify requests.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
 
-	return nil
+// parseWebhookTemplate parses a body template.
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("body").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	return tmpl, nil
 }
 
-// Test sends a test notification to verify the channel configuration.
-func (c *WebhookChannel) Test(ctx context.Context) error {
-	payload := WebhookPayload{
+// renderWebhookTemplate renders a body template and checks the result is JSON.
+func renderWebhookTemplate(tmpl *template.Template, payload WebhookPayload) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("render body template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("body template did not render valid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// testWebhookPayload returns the payload sent by Test.
+func testWebhookPayload() WebhookPayload {
+	now := time.Now()
+	return WebhookPayload{
 		Version:   "1.0",
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Event:     "test",
 		Alert: &WebhookAlertPayload{
 			ID:          "test-alert-id",
@@ -163,7 +383,7 @@ func (c *WebhookChannel) Test(ctx context.Context) error {
 			Labels: map[string]string{
 				"test": "true",
 			},
-			FiredAt: time.Now(),
+			FiredAt: now,
 		},
 		Rule: &WebhookRulePayload{
 			ID:          "test-rule-id",
@@ -174,37 +394,18 @@ func (c *WebhookChannel) Test(ctx context.Context) error {
 			Threshold:   100,
 			Severity:    "info",
 		},
+		Channel: &WebhookChannelPayload{
+			ID:   "test-channel-id",
+			Name: "Test Channel",
+			Type: string(alerting.ChannelWebhook),
+		},
+		Display: &WebhookDisplayPayload{
+			Timezone: "UTC",
+			Title:    "[TEST] Test Rule",
+			Severity: "info",
+			FiredAt:  now.UTC().Format(time.RFC3339),
+		},
 	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal test payload: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, c.method, c.url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("failed to create test request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Philotes-Alerting/1.0")
-
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("webhook test failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("webhook test failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	return nil
 }
 
 // buildPayload builds a webhook payload from a notification.
@@ -213,20 +414,17 @@ func (c *WebhookChannel) buildPayload(notification alerting.Notification) Webhoo
 		Version:   "1.0",
 		Timestamp: time.Now(),
 		Event:     string(notification.Event),
+		Display: &WebhookDisplayPayload{
+			Timezone: c.format.Location.String(),
+			Title:    FormatAlertTitle(notification, c.format),
+		},
 	}
 
 	if notification.Alert != nil {
-		payload.Alert = &WebhookAlertPayload{
-			ID:             notification.Alert.ID.String(),
-			Fingerprint:    notification.Alert.Fingerprint,
-			Status:         string(notification.Alert.Status),
-			Labels:         notification.Alert.Labels,
-			Annotations:    notification.Alert.Annotations,
-			CurrentValue:   notification.Alert.CurrentValue,
-			FiredAt:        notification.Alert.FiredAt,
-			ResolvedAt:     notification.Alert.ResolvedAt,
-			AcknowledgedAt: notification.Alert.AcknowledgedAt,
-			AcknowledgedBy: notification.Alert.AcknowledgedBy,
+		payload.Alert = webhookAlertPayload(notification.Alert)
+		payload.Display.FiredAt = c.format.Time(notification.Alert.FiredAt)
+		if notification.Alert.ResolvedAt != nil {
+			payload.Display.ResolvedAt = c.format.Time(*notification.Alert.ResolvedAt)
 		}
 	}
 
@@ -238,11 +436,13 @@ func (c *WebhookChannel) buildPayload(notification alerting.Notification) Webhoo
 			MetricName:      notification.Rule.MetricName,
 			Operator:        string(notification.Rule.Operator),
 			Threshold:       notification.Rule.Threshold,
+			Expression:      notification.Rule.Expression,
 			DurationSeconds: notification.Rule.DurationSeconds,
 			Severity:        string(notification.Rule.Severity),
 			Labels:          notification.Rule.Labels,
 			Annotations:     notification.Rule.Annotations,
 		}
+		payload.Display.Severity = c.format.Severity(notification.Rule.Severity)
 	}
 
 	if notification.Channel != nil {
@@ -253,9 +453,36 @@ func (c *WebhookChannel) buildPayload(notification alerting.Notification) Webhoo
 		}
 	}
 
+	for _, grouped := range notification.Grouped {
+		entry := WebhookGroupedPayload{Event: string(grouped.Event)}
+		if grouped.Rule != nil {
+			entry.Rule = grouped.Rule.Name
+		}
+		if grouped.Alert != nil {
+			entry.Alert = webhookAlertPayload(grouped.Alert)
+		}
+		payload.Group = append(payload.Group, entry)
+	}
+
 	return payload
 }
 
+// webhookAlertPayload converts an alert instance for the webhook payload.
+func webhookAlertPayload(alert *alerting.AlertInstance) *WebhookAlertPayload {
+	return &WebhookAlertPayload{
+		ID:             alert.ID.String(),
+		Fingerprint:    alert.Fingerprint,
+		Status:         string(alert.Status),
+		Labels:         alert.Labels,
+		Annotations:    alert.Annotations,
+		CurrentValue:   alert.CurrentValue,
+		FiredAt:        alert.FiredAt,
+		ResolvedAt:     alert.ResolvedAt,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		AcknowledgedBy: alert.AcknowledgedBy,
+	}
+}
+
 // SetHTTPClient allows setting a custom HTTP client (useful for testing).
 func (c *WebhookChannel) SetHTTPClient(client *http.Client) {
 	c.httpClient = client
@@ -0,0 +1,130 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookChannel_TemplateAndSignature(t *testing.T) {
+	var got map[string]any
+	var signature, timestamp string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		timestamp = r.Header.Get(WebhookTimestampHeader)
+		if r.Header.Get("X-Team") != "data" {
+			t.Errorf("custom header = %q, want data", r.Header.Get("X-Team"))
+		}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	ch, err := NewWebhookChannel(map[string]interface{}{
+		"url":           server.URL,
+		"headers":       map[string]interface{}{"X-Team": "data"},
+		"secret":        "s3cret",
+		"body_template": `{"summary": {{json .Display.Title}}, "rule": {{json .Rule.Name}}, "status": {{json .Alert.Status}}}`,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+
+	if err := ch.Send(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got["rule"] != "replication_lag" || got["status"] != "resolved" || got["summary"] == "" {
+		t.Errorf("body = %v, want the rendered template", got)
+	}
+	if want := SignWebhookPayload("s3cret", timestamp, body); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestWebhookChannel_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	ch, err := NewWebhookChannel(map[string]interface{}{"url": server.URL}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+	ch.initialBackoff = time.Millisecond
+
+	if err := ch.Test(context.Background()); err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server called %d times, want 3", calls.Load())
+	}
+}
+
+func TestWebhookChannel_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	ch, err := NewWebhookChannel(map[string]interface{}{"url": server.URL}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+	ch.initialBackoff = time.Millisecond
+
+	if err := ch.Test(context.Background()); err == nil {
+		t.Fatal("Test() error = nil, want the 400 response")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server called %d times, want 1", calls.Load())
+	}
+}
+
+func TestValidateWebhookConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		field  string
+	}{
+		{"valid", map[string]interface{}{"url": "https://example.com/hook", "body_template": `{"t": {{json .Event}}}`}, ""},
+		{"secret reference url", map[string]interface{}{"url": "vault://alerting/webhook#url"}, ""},
+		{"missing url", map[string]interface{}{}, "url"},
+		{"relative url", map[string]interface{}{"url": "/hook"}, "url"},
+		{"bad scheme", map[string]interface{}{"url": "ftp://example.com"}, "url"},
+		{"bad method", map[string]interface{}{"url": "https://example.com", "method": "DELETE"}, "method"},
+		{"negative retries", map[string]interface{}{"url": "https://example.com", "max_retries": -1}, "max_retries"},
+		{"unparsable template", map[string]interface{}{"url": "https://example.com", "body_template": `{{.Event`}, "body_template"},
+		{"template not json", map[string]interface{}{"url": "https://example.com", "body_template": `event {{.Event}}`}, "body_template"},
+		{"unknown field", map[string]interface{}{"url": "https://example.com", "body_template": `{"x": {{json .Nope}}}`}, "body_template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookConfig(tt.config)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("ValidateWebhookConfig() error = %v", err)
+				}
+				return
+			}
+			var cfgErr *ConfigError
+			if !errors.As(err, &cfgErr) || cfgErr.Field != tt.field {
+				t.Fatalf("ValidateWebhookConfig() error = %v, want a %s error", err, tt.field)
+			}
+		})
+	}
+}
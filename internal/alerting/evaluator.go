@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/prometheus/common/model"
 )
 
 // Evaluator queries Prometheus and evaluates alert rules.
@@ -62,13 +64,48 @@ func NewEvaluator(prometheusURL string, logger *slog.Logger) *Evaluator {
 // Evaluate queries Prometheus and checks if the rule condition is met.
 // Returns one EvaluationResult per metric series returned by Prometheus.
 func (e *Evaluator) Evaluate(ctx context.Context, rule AlertRule) ([]EvaluationResult, error) {
-	metrics, err := e.queryPrometheus(ctx, rule.MetricName, rule.Labels)
+	return EvaluateRule(ctx, e, rule, e.logger)
+}
+
+// Query returns the Prometheus series of a metric, so the evaluator can be
+// used as a MetricsProvider.
+func (e *Evaluator) Query(ctx context.Context, metricName string, labels map[string]string) ([]MetricValue, error) {
+	metrics, err := e.queryPrometheus(ctx, e.buildQuery(metricName, labels))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	return metrics, nil
+}
+
+// QueryOverTime applies a range function to a metric in PromQL, so the
+// evaluator can be used as a RangeProvider.
+func (e *Evaluator) QueryOverTime(ctx context.Context, function, metricName string, labels map[string]string, window time.Duration) ([]MetricValue, error) {
+	query := fmt.Sprintf("%s(%s[%s])", function, e.buildQuery(metricName, labels), model.Duration(window))
+	metrics, err := e.queryPrometheus(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query prometheus: %w", err)
 	}
+	return metrics, nil
+}
+
+// EvaluateRule reads the rule's metric from provider and checks if the rule
+// condition is met. Returns one EvaluationResult per metric series.
+func EvaluateRule(ctx context.Context, provider MetricsProvider, rule AlertRule, logger *slog.Logger) ([]EvaluationResult, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if rule.Expression != "" {
+		return evaluateExpression(ctx, provider, rule, logger)
+	}
+
+	metrics, err := provider.Query(ctx, rule.MetricName, rule.Labels)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(metrics) == 0 {
-		e.logger.Debug("no metrics found for rule",
+		logger.Debug("no metrics found for rule",
 			"rule_id", rule.ID,
 			"rule_name", rule.Name,
 			"metric_name", rule.MetricName,
@@ -101,7 +138,7 @@ func (e *Evaluator) Evaluate(ctx context.Context, rule AlertRule) ([]EvaluationR
 
 		results = append(results, result)
 
-		e.logger.Debug("evaluated rule",
+		logger.Debug("evaluated rule",
 			"rule_id", rule.ID,
 			"rule_name", rule.Name,
 			"value", m.Value,
@@ -114,11 +151,44 @@ func (e *Evaluator) Evaluate(ctx context.Context, rule AlertRule) ([]EvaluationR
 	return results, nil
 }
 
-// queryPrometheus queries the Prometheus HTTP API.
-func (e *Evaluator) queryPrometheus(ctx context.Context, metricName string, labels map[string]string) ([]MetricValue, error) {
-	// Build the PromQL query
-	query := e.buildQuery(metricName, labels)
+// evaluateExpression evaluates a rule's expression. The rule yields a single
+// result labelled with the rule's labels, since the metrics of an expression
+// need not share labels.
+func evaluateExpression(ctx context.Context, provider MetricsProvider, rule AlertRule, logger *slog.Logger) ([]EvaluationResult, error) {
+	expr, err := ParseExpression(rule.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	shouldFire, value, err := expr.Evaluate(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]string, len(rule.Labels))
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+
+	logger.Debug("evaluated rule expression",
+		"rule_id", rule.ID,
+		"rule_name", rule.Name,
+		"expression", rule.Expression,
+		"value", value,
+		"should_fire", shouldFire,
+	)
+
+	return []EvaluationResult{{
+		Rule:        &rule,
+		Value:       value,
+		Labels:      labels,
+		ShouldFire:  shouldFire,
+		EvaluatedAt: time.Now(),
+	}}, nil
+}
 
+// queryPrometheus runs a PromQL instant query against the Prometheus HTTP API.
+func (e *Evaluator) queryPrometheus(ctx context.Context, query string) ([]MetricValue, error) {
 	// Construct the URL
 	queryURL := fmt.Sprintf("%s/api/v1/query", e.prometheusURL)
 	reqURL, err := url.Parse(queryURL)
@@ -246,3 +316,6 @@ func (e *Evaluator) parseResult(result prometheusResult) (MetricValue, error) {
 func (e *Evaluator) SetHTTPClient(client *http.Client) {
 	e.httpClient = client
 }
+
+// Ensure Evaluator implements RangeProvider.
+var _ RangeProvider = (*Evaluator)(nil)
@@ -0,0 +1,505 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Functions available in rule expressions.
+const (
+	// FuncRate is the per-second increase of a counter over a window.
+	FuncRate = "rate"
+
+	// FuncAvgOverTime is the average of a metric over a window.
+	FuncAvgOverTime = "avg_over_time"
+)
+
+// ErrInvalidExpression is returned for rule expressions that do not parse.
+var ErrInvalidExpression = errors.New("invalid alert expression")
+
+// RangeProvider is a MetricsProvider that can also aggregate a metric over a
+// time window, as needed by the rate and avg_over_time expression functions.
+type RangeProvider interface {
+	MetricsProvider
+
+	// QueryOverTime returns one value per matching series of function
+	// (FuncRate or FuncAvgOverTime) applied to the metric over window.
+	QueryOverTime(ctx context.Context, function, metricName string, labels map[string]string, window time.Duration) ([]MetricValue, error)
+}
+
+// Expression is a parsed rule expression: comparisons of metrics with
+// numbers, combined with and, or and not. For example:
+//
+//	philotes_cdc_lag_seconds > 60 and rate(philotes_buffer_dlq_total[5m]) > 0
+//
+// The language supports:
+//
+//   - comparisons with >, <, >=, <= and ==, which hold when any series of
+//     the metric satisfies them
+//   - label filters, as in philotes_cdc_lag_seconds{source="orders"}
+//   - rate(metric[window]), the per-second increase of a counter
+//   - avg_over_time(metric[window]), the average of a metric
+//   - and, or and not, with parentheses for grouping
+//
+// Windows use Go duration syntax (30s, 5m, 1h30m).
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ParseExpression parses a rule expression.
+func ParseExpression(s string) (*Expression, error) {
+	p := &exprParser{lexer: exprLexer{input: s}}
+	p.next()
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidExpression, p.tok.text, p.tok.pos)
+	}
+	return &Expression{source: s, root: root}, nil
+}
+
+// String returns the expression as written.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate evaluates the expression against provider. value is the value
+// of the first comparison that made the expression hold, or 0.
+func (e *Expression) Evaluate(ctx context.Context, provider MetricsProvider) (holds bool, value float64, err error) {
+	return e.root.eval(ctx, provider)
+}
+
+// exprNode is a boolean node of an expression.
+type exprNode interface {
+	eval(ctx context.Context, provider MetricsProvider) (bool, float64, error)
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(ctx context.Context, provider MetricsProvider) (bool, float64, error) {
+	ok, value, err := n.left.eval(ctx, provider)
+	if err != nil || !ok {
+		return false, 0, err
+	}
+	ok, _, err = n.right.eval(ctx, provider)
+	if err != nil || !ok {
+		return false, 0, err
+	}
+	return true, value, nil
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(ctx context.Context, provider MetricsProvider) (bool, float64, error) {
+	ok, value, err := n.left.eval(ctx, provider)
+	if err != nil || ok {
+		return ok, value, err
+	}
+	return n.right.eval(ctx, provider)
+}
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) eval(ctx context.Context, provider MetricsProvider) (bool, float64, error) {
+	ok, _, err := n.operand.eval(ctx, provider)
+	if err != nil {
+		return false, 0, err
+	}
+	return !ok, 0, nil
+}
+
+// compareNode compares every series of a metric with a threshold.
+type compareNode struct {
+	metric    metricRef
+	operator  Operator
+	threshold float64
+}
+
+func (n compareNode) eval(ctx context.Context, provider MetricsProvider) (bool, float64, error) {
+	values, err := n.metric.query(ctx, provider)
+	if err != nil {
+		return false, 0, err
+	}
+	for _, v := range values {
+		if n.operator.Evaluate(v.Value, n.threshold) {
+			return true, v.Value, nil
+		}
+	}
+	return false, 0, nil
+}
+
+// metricRef is a metric selector, optionally wrapped in a range function.
+type metricRef struct {
+	name     string
+	labels   map[string]string
+	function string
+	window   time.Duration
+}
+
+func (m metricRef) query(ctx context.Context, provider MetricsProvider) ([]MetricValue, error) {
+	if m.function == "" {
+		return provider.Query(ctx, m.name, m.labels)
+	}
+	ranges, ok := provider.(RangeProvider)
+	if !ok {
+		return nil, fmt.Errorf("metric source does not support %s", m.function)
+	}
+	return ranges.QueryOverTime(ctx, m.function, m.name, m.labels, m.window)
+}
+
+// exprParser is a recursive descent parser for rule expressions:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = operand op number | number op operand
+//	operand    = selector | function "(" selector "[" duration "]" ")"
+//	selector   = name [ "{" label "=" string { "," label "=" string } "}" ]
+type exprParser struct {
+	lexer exprLexer
+	tok   exprToken
+}
+
+func (p *exprParser) next() {
+	p.tok = p.lexer.next()
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.is(tokIdent, "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.is(tokIdent, "and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch {
+	case p.tok.is(tokIdent, "not"):
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	case p.tok.is(tokPunct, "("):
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokPunct, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	// number op operand is flipped to operand op number
+	if p.tok.kind == tokNumber {
+		threshold, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		op, err := p.parseOperator()
+		if err != nil {
+			return nil, err
+		}
+		metric, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{metric: metric, operator: op.flip(), threshold: threshold}, nil
+	}
+
+	metric, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := p.parseNumber()
+	if err != nil {
+		return nil, err
+	}
+	return compareNode{metric: metric, operator: op, threshold: threshold}, nil
+}
+
+func (p *exprParser) parseOperand() (metricRef, error) {
+	if p.tok.kind != tokIdent || isKeyword(p.tok.text) {
+		return metricRef{}, p.unexpected("a metric name")
+	}
+	name := p.tok.text
+	p.next()
+
+	if !p.tok.is(tokPunct, "(") {
+		return p.parseSelector(name)
+	}
+
+	if name != FuncRate && name != FuncAvgOverTime {
+		return metricRef{}, fmt.Errorf("unknown function %q: must be %s or %s", name, FuncRate, FuncAvgOverTime)
+	}
+	p.next()
+	if p.tok.kind != tokIdent || isKeyword(p.tok.text) {
+		return metricRef{}, p.unexpected("a metric name")
+	}
+	metricName := p.tok.text
+	p.next()
+	ref, err := p.parseSelector(metricName)
+	if err != nil {
+		return metricRef{}, err
+	}
+	if p.tok.kind != tokWindow {
+		return metricRef{}, p.unexpected(fmt.Sprintf("a [window] for %s", name))
+	}
+	window, err := time.ParseDuration(p.tok.text)
+	if err != nil || window <= 0 {
+		return metricRef{}, fmt.Errorf("invalid window %q: must be a positive duration such as 5m", p.tok.text)
+	}
+	p.next()
+	if err := p.expect(tokPunct, ")"); err != nil {
+		return metricRef{}, err
+	}
+	ref.function, ref.window = name, window
+	return ref, nil
+}
+
+func (p *exprParser) parseSelector(name string) (metricRef, error) {
+	ref := metricRef{name: name}
+	if !p.tok.is(tokPunct, "{") {
+		return ref, nil
+	}
+	p.next()
+
+	ref.labels = make(map[string]string)
+	for !p.tok.is(tokPunct, "}") {
+		if len(ref.labels) > 0 {
+			if err := p.expect(tokPunct, ","); err != nil {
+				return metricRef{}, err
+			}
+		}
+		if p.tok.kind != tokIdent {
+			return metricRef{}, p.unexpected("a label name")
+		}
+		label := p.tok.text
+		p.next()
+		if err := p.expect(tokPunct, "="); err != nil {
+			return metricRef{}, err
+		}
+		if p.tok.kind != tokString {
+			return metricRef{}, p.unexpected("a quoted label value")
+		}
+		ref.labels[label] = p.tok.text
+		p.next()
+	}
+	p.next()
+	return ref, nil
+}
+
+func (p *exprParser) parseOperator() (Operator, error) {
+	if p.tok.kind != tokOperator {
+		return "", p.unexpected("a comparison operator")
+	}
+	var op Operator
+	switch p.tok.text {
+	case ">":
+		op = OpGreaterThan
+	case "<":
+		op = OpLessThan
+	case ">=":
+		op = OpGreaterThanEqual
+	case "<=":
+		op = OpLessThanEqual
+	case "==":
+		op = OpEqual
+	default:
+		return "", fmt.Errorf("unsupported operator %q", p.tok.text)
+	}
+	p.next()
+	return op, nil
+}
+
+func (p *exprParser) parseNumber() (float64, error) {
+	if p.tok.kind != tokNumber {
+		return 0, p.unexpected("a number")
+	}
+	value, err := strconv.ParseFloat(p.tok.text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", p.tok.text)
+	}
+	p.next()
+	return value, nil
+}
+
+func (p *exprParser) expect(kind tokenKind, text string) error {
+	if !p.tok.is(kind, text) {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) unexpected(want string) error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("expected %s, got end of expression", want)
+	}
+	if p.tok.kind == tokError {
+		return errors.New(p.tok.text)
+	}
+	return fmt.Errorf("expected %s, got %q at offset %d", want, p.tok.text, p.tok.pos)
+}
+
+// flip returns the operator with its operands swapped.
+func (o Operator) flip() Operator {
+	switch o {
+	case OpGreaterThan:
+		return OpLessThan
+	case OpLessThan:
+		return OpGreaterThan
+	case OpGreaterThanEqual:
+		return OpLessThanEqual
+	case OpLessThanEqual:
+		return OpGreaterThanEqual
+	}
+	return o
+}
+
+// isKeyword reports whether s is a reserved word of the expression language.
+func isKeyword(s string) bool {
+	return s == "and" || s == "or" || s == "not"
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokError
+	tokIdent
+	tokNumber
+	tokString
+	tokWindow
+	tokOperator
+	tokPunct
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t exprToken) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+// exprLexer splits an expression into tokens.
+type exprLexer struct {
+	input string
+	pos   int
+}
+
+func (l *exprLexer) next() exprToken {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return exprToken{kind: tokEOF, pos: l.pos}
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	switch {
+	case isIdentStart(c):
+		for l.pos < len(l.input) && isIdentPart(l.input[l.pos]) {
+			l.pos++
+		}
+		return exprToken{kind: tokIdent, text: l.input[start:l.pos], pos: start}
+	case c >= '0' && c <= '9' || c == '.' || c == '-':
+		l.pos++
+		for l.pos < len(l.input) && strings.IndexByte("0123456789.eE", l.input[l.pos]) >= 0 {
+			// Allow a sign after an exponent
+			if (l.input[l.pos] == 'e' || l.input[l.pos] == 'E') && l.pos+1 < len(l.input) && strings.IndexByte("+-", l.input[l.pos+1]) >= 0 {
+				l.pos++
+			}
+			l.pos++
+		}
+		return exprToken{kind: tokNumber, text: l.input[start:l.pos], pos: start}
+	case c == '"':
+		l.pos++
+		var b strings.Builder
+		for l.pos < len(l.input) && l.input[l.pos] != '"' {
+			if l.input[l.pos] == '\\' && l.pos+1 < len(l.input) {
+				l.pos++
+			}
+			b.WriteByte(l.input[l.pos])
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			return exprToken{kind: tokError, text: fmt.Sprintf("unterminated string at offset %d", start), pos: start}
+		}
+		l.pos++
+		return exprToken{kind: tokString, text: b.String(), pos: start}
+	case c == '[':
+		end := strings.IndexByte(l.input[l.pos:], ']')
+		if end < 0 {
+			return exprToken{kind: tokError, text: fmt.Sprintf("unterminated window at offset %d", start), pos: start}
+		}
+		l.pos += end + 1
+		return exprToken{kind: tokWindow, text: strings.TrimSpace(l.input[start+1 : l.pos-1]), pos: start}
+	case c == '>' || c == '<' || c == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+		}
+		return exprToken{kind: tokOperator, text: l.input[start:l.pos], pos: start}
+	case strings.IndexByte("(){},=", c) >= 0:
+		l.pos++
+		return exprToken{kind: tokPunct, text: string(c), pos: start}
+	}
+	l.pos++
+	return exprToken{kind: tokError, text: fmt.Sprintf("unexpected character %q at offset %d", c, start), pos: start}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// staticProvider returns fixed series per metric, and per function for
+// range queries.
+type staticProvider map[string][]MetricValue
+
+func (p staticProvider) Query(_ context.Context, metricName string, labels map[string]string) ([]MetricValue, error) {
+	var values []MetricValue
+	for _, v := range p[metricName] {
+		if matchesLabels(v.Labels, labels) {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func (p staticProvider) QueryOverTime(ctx context.Context, function, metricName string, labels map[string]string, _ time.Duration) ([]MetricValue, error) {
+	return p.Query(ctx, function+"("+metricName+")", labels)
+}
+
+func TestParseExpression(t *testing.T) {
+	valid := []string{
+		"philotes_cdc_lag_seconds > 60",
+		"60 < philotes_cdc_lag_seconds",
+		`philotes_cdc_lag_seconds{source="orders"} >= 1e3`,
+		"philotes_cdc_lag_seconds > 60 and rate(philotes_buffer_dlq_total[5m]) > 0",
+		"not (avg_over_time(philotes_buffer_unprocessed_events[1h30m]) <= 10 or up == 0)",
+	}
+	for _, s := range valid {
+		if _, err := ParseExpression(s); err != nil {
+			t.Errorf("ParseExpression(%q) error = %v", s, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"philotes_cdc_lag_seconds",
+		"philotes_cdc_lag_seconds > ",
+		"philotes_cdc_lag_seconds > 60 and",
+		"increase(philotes_buffer_dlq_total[5m]) > 0",
+		"rate(philotes_buffer_dlq_total) > 0",
+		"rate(philotes_buffer_dlq_total[5 minutes]) > 0",
+		`philotes_cdc_lag_seconds{source="orders} > 60`,
+		"philotes_cdc_lag_seconds != 60",
+		"(philotes_cdc_lag_seconds > 60",
+	}
+	for _, s := range invalid {
+		if _, err := ParseExpression(s); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("ParseExpression(%q) error = %v, want ErrInvalidExpression", s, err)
+		}
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	provider := staticProvider{
+		"lag": {
+			{Labels: map[string]string{"source": "orders"}, Value: 90},
+			{Labels: map[string]string{"source": "users"}, Value: 5},
+		},
+		"rate(dlq)": {{Value: 0.5}},
+	}
+
+	tests := []struct {
+		expr  string
+		holds bool
+		value float64
+	}{
+		{"lag > 60", true, 90},
+		{`lag{source="users"} > 60`, false, 0},
+		{"lag > 60 and rate(dlq[5m]) > 0", true, 90},
+		{"lag > 60 and rate(dlq[5m]) > 1", false, 0},
+		{"lag > 100 or rate(dlq[5m]) > 0", true, 0.5},
+		{"not lag > 100", true, 0},
+		{"10 > lag", true, 5},
+		{"missing > 0", false, 0},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseExpression(%q) error = %v", tt.expr, err)
+		}
+		holds, value, err := expr.Evaluate(context.Background(), provider)
+		if err != nil {
+			t.Fatalf("Evaluate(%q) error = %v", tt.expr, err)
+		}
+		if holds != tt.holds || value != tt.value {
+			t.Errorf("Evaluate(%q) = %v, %v, want %v, %v", tt.expr, holds, value, tt.holds, tt.value)
+		}
+	}
+}
+
+func TestEvaluateRule_Expression(t *testing.T) {
+	provider := staticProvider{"lag": {{Labels: map[string]string{"source": "orders"}, Value: 90}}}
+	rule := AlertRule{Name: "lag", Expression: "lag > 60", Labels: map[string]string{"team": "data"}}
+
+	results, err := EvaluateRule(context.Background(), provider, rule, nil)
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].ShouldFire || results[0].Value != 90 {
+		t.Fatalf("EvaluateRule() = %+v, want one firing result at 90", results)
+	}
+	if len(results[0].Labels) != 1 || results[0].Labels["team"] != "data" {
+		t.Errorf("labels = %v, want only the rule labels", results[0].Labels)
+	}
+}
+
+func TestInternalProvider_QueryOverTime(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	dlq := prometheus.NewCounter(prometheus.CounterOpts{Name: "philotes_buffer_dlq_total"})
+	reg.MustRegister(dlq)
+
+	provider := NewInternalProvider(reg)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A single sample has no rate yet
+	values, err := provider.QueryOverTime(ctx, FuncRate, "philotes_buffer_dlq_total", nil, 5*time.Minute)
+	if err != nil || len(values) != 0 {
+		t.Fatalf("QueryOverTime() = %+v, %v, want no series", values, err)
+	}
+
+	dlq.Add(60)
+	now = now.Add(time.Minute)
+	values, err = provider.QueryOverTime(ctx, FuncRate, "philotes_buffer_dlq_total", nil, 5*time.Minute)
+	if err != nil || len(values) != 1 || values[0].Value != 1 {
+		t.Fatalf("QueryOverTime(rate) = %+v, %v, want 1/s", values, err)
+	}
+
+	values, err = provider.QueryOverTime(ctx, FuncAvgOverTime, "philotes_buffer_dlq_total", nil, 5*time.Minute)
+	if err != nil || len(values) != 1 || values[0].Value != 30 {
+		t.Fatalf("QueryOverTime(avg_over_time) = %+v, %v, want 30", values, err)
+	}
+
+	// Samples older than the longest window are dropped
+	now = now.Add(10 * time.Minute)
+	values, err = provider.QueryOverTime(ctx, FuncRate, "philotes_buffer_dlq_total", nil, 5*time.Minute)
+	if err != nil || len(values) != 0 {
+		t.Errorf("QueryOverTime() after the window = %+v, %v, want no series", values, err)
+	}
+}
@@ -0,0 +1,105 @@
+// Package alerting provides the alerting framework for Philotes.
+package alerting
+
+import (
+	"sync"
+	"time"
+)
+
+// flapDecision is the outcome of observing an alert state change.
+type flapDecision int
+
+const (
+	// flapNotify means the state change should be notified normally.
+	flapNotify flapDecision = iota
+	// flapDetected means the alert just started flapping; a single flapping
+	// notification should be sent instead of the state change.
+	flapDetected
+	// flapSuppress means the alert is flapping and the state change must not
+	// be notified now; the final state is notified once the alert settles.
+	flapSuppress
+)
+
+// flapNotification is a state change observed by the flap detector, kept so
+// the final state of a flapping alert can be notified once it settles.
+type flapNotification struct {
+	instance  AlertInstance
+	rule      AlertRule
+	eventType EventType
+}
+
+// flapState tracks state changes for a single alert fingerprint.
+type flapState struct {
+	lastChange  time.Time
+	interval    time.Duration
+	flapping    bool
+	transitions int
+	last        flapNotification
+}
+
+// flapDetector throttles state-change notifications per alert. A state change
+// within the minimum interval of the previous one marks the alert as flapping;
+// it stays flapping until it has been quiet for a full interval, at which
+// point Settled hands back its final state.
+type flapDetector struct {
+	mu     sync.Mutex
+	states map[string]*flapState
+	now    func() time.Time
+}
+
+// newFlapDetector creates a new flap detector.
+func newFlapDetector() *flapDetector {
+	return &flapDetector{
+		states: make(map[string]*flapState),
+		now:    time.Now,
+	}
+}
+
+// Observe records a state change and decides how it should be notified. An
+// interval of 0 disables throttling.
+func (d *flapDetector) Observe(change flapNotification, interval time.Duration) (flapDecision, int) {
+	if interval <= 0 {
+		return flapNotify, 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	fingerprint := change.instance.Fingerprint
+	state, ok := d.states[fingerprint]
+	if !ok || now.Sub(state.lastChange) >= interval {
+		d.states[fingerprint] = &flapState{lastChange: now, interval: interval, last: change}
+		return flapNotify, 0
+	}
+
+	state.lastChange = now
+	state.interval = interval
+	state.last = change
+	state.transitions++
+	if state.flapping {
+		return flapSuppress, state.transitions
+	}
+	state.flapping = true
+	return flapDetected, state.transitions
+}
+
+// Settled returns the final state change of every alert that stopped flapping,
+// i.e. has been quiet for its interval, and forgets those alerts.
+func (d *flapDetector) Settled() []flapNotification {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	var settled []flapNotification
+	for fingerprint, state := range d.states {
+		if now.Sub(state.lastChange) < state.interval {
+			continue
+		}
+		if state.flapping {
+			settled = append(settled, state.last)
+		}
+		delete(d.states, fingerprint)
+	}
+	return settled
+}
@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// hubBufferSize is how many events a subscriber may fall behind before
+// further events to it are dropped.
+const hubBufferSize = 64
+
+// AlertEvent is a change in the lifecycle of an alert instance: it fired,
+// was acknowledged or resolved.
+type AlertEvent struct {
+	Type  EventType     `json:"type"`
+	Alert AlertInstance `json:"alert"`
+	Time  time.Time     `json:"time"`
+
+	// TenantID is the tenant of the alert's rule, nil for global rules.
+	TenantID *uuid.UUID `json:"-"`
+}
+
+// Hub fans alert events out to subscribers, such as dashboard streams.
+// Publishing never blocks; a subscriber that falls behind misses events.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[chan AlertEvent]*uuid.UUID
+}
+
+// NewHub creates a new Hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[chan AlertEvent]*uuid.UUID)}
+}
+
+// Subscribe returns a channel receiving the events of alerts belonging to
+// tenantID, or of every alert when tenantID is nil. The subscription ends and
+// the channel is closed when ctx is done.
+func (h *Hub) Subscribe(ctx context.Context, tenantID *uuid.UUID) <-chan AlertEvent {
+	ch := make(chan AlertEvent, hubBufferSize)
+
+	h.mu.Lock()
+	h.subs[ch] = tenantID
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subs, ch)
+		close(ch)
+		h.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Publish sends event to every subscriber in its scope.
+func (h *Hub) Publish(event AlertEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch, tenantID := range h.subs {
+		if tenantID != nil && (event.TenantID == nil || *event.TenantID != *tenantID) {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of active subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
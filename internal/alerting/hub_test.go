@@ -0,0 +1,67 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	tenant := uuid.New()
+	other := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	all := hub.Subscribe(ctx, nil)
+	scoped := hub.Subscribe(ctx, &tenant)
+
+	hub.Publish(AlertEvent{Type: EventFired, TenantID: &tenant})
+	hub.Publish(AlertEvent{Type: EventAcknowledged, TenantID: &other})
+	hub.Publish(AlertEvent{Type: EventResolved})
+
+	for _, want := range []EventType{EventFired, EventAcknowledged, EventResolved} {
+		if got := (<-all).Type; got != want {
+			t.Errorf("unscoped subscriber got %s, want %s", got, want)
+		}
+	}
+	if got := <-scoped; got.Type != EventFired || got.Time.IsZero() {
+		t.Errorf("scoped subscriber got %+v, want a timestamped fired event", got)
+	}
+	select {
+	case got := <-scoped:
+		t.Errorf("scoped subscriber got %+v from another tenant", got)
+	default:
+	}
+
+	cancel()
+	for _, ch := range []<-chan AlertEvent{all, scoped} {
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Error("channel delivered an event after cancellation")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed after cancellation")
+		}
+	}
+	if n := hub.Subscribers(); n != 0 {
+		t.Errorf("Subscribers() = %d after cancellation, want 0", n)
+	}
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := hub.Subscribe(ctx, nil)
+
+	// Publishing past the buffer must not block
+	for i := 0; i < hubBufferSize*2; i++ {
+		hub.Publish(AlertEvent{Type: EventFired})
+	}
+	if len(ch) != hubBufferSize {
+		t.Errorf("buffered %d events, want %d", len(ch), hubBufferSize)
+	}
+}
@@ -7,23 +7,37 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/metrics"
 )
 
 // Manager is the main alert manager that coordinates rule evaluation and notifications.
 type Manager struct {
 	repo      AlertRepository
-	evaluator *Evaluator
+	evaluator *Evaluator // nil when no Prometheus URL is configured
+	internal  MetricsProvider
+	pushed    MetricsProvider // nil unless the remote-write receiver is enabled
 	notifier  *Notifier
 	logger    *slog.Logger
 	config    config.AlertingConfig
 
+	// interval is the evaluation interval; SetEvaluationInterval changes it
+	// and signals intervalCh so a running loop resets its ticker
+	interval   atomic.Int64
+	intervalCh chan struct{}
+
 	// Track pending alerts for duration-based evaluation
 	pendingAlerts map[string]time.Time // fingerprint -> first triggered time
 	mu            sync.RWMutex
 
+	// Throttle state-change notifications for flapping alerts
+	flaps *flapDetector
+
 	// Control channels
 	stopCh    chan struct{}
 	stoppedCh chan struct{}
@@ -37,23 +51,35 @@ func NewManager(repo AlertRepository, cfg config.AlertingConfig, logger *slog.Lo
 		logger = slog.Default()
 	}
 
-	if cfg.PrometheusURL == "" {
-		return nil, fmt.Errorf("prometheus URL is required")
+	// Without Prometheus, rules are evaluated against in-process metrics
+	var evaluator *Evaluator
+	if cfg.PrometheusURL != "" {
+		evaluator = NewEvaluator(cfg.PrometheusURL, logger)
 	}
-
-	evaluator := NewEvaluator(cfg.PrometheusURL, logger)
 	notifier := NewNotifier(repo, nil, cfg.NotificationTimeout, logger)
 
-	return &Manager{
+	// The internal source reads this process's registry plus the metrics
+	// endpoints of other processes, such as the CDC worker
+	gatherer := metrics.Gatherer()
+	if len(cfg.MetricsEndpoints) > 0 {
+		gatherer = prometheus.Gatherers{gatherer, NewEndpointGatherer(cfg.MetricsEndpoints)}
+	}
+
+	m := &Manager{
 		repo:          repo,
 		evaluator:     evaluator,
+		internal:      NewInternalProvider(gatherer),
 		notifier:      notifier,
 		logger:        logger.With("component", "alert-manager"),
 		config:        cfg,
 		pendingAlerts: make(map[string]time.Time),
+		flaps:         newFlapDetector(),
 		stopCh:        make(chan struct{}),
 		stoppedCh:     make(chan struct{}),
-	}, nil
+		intervalCh:    make(chan struct{}, 1),
+	}
+	m.interval.Store(int64(cfg.EvaluationInterval))
+	return m, nil
 }
 
 // SetChannelFactory sets the channel factory for the notifier.
@@ -62,6 +88,36 @@ func (m *Manager) SetChannelFactory(factory ChannelFactory) {
 	m.notifier.channelFactory = factory
 }
 
+// SetInternalProvider replaces the provider of rules with the internal
+// metric source, which reads the default Prometheus registry otherwise.
+func (m *Manager) SetInternalProvider(provider MetricsProvider) {
+	m.internal = provider
+}
+
+// SetRemoteWriteProvider sets the provider of rules with the remote_write
+// metric source, which fail to evaluate until one is set.
+func (m *Manager) SetRemoteWriteProvider(provider MetricsProvider) {
+	m.pushed = provider
+}
+
+// SetConfigResolver sets the resolver for secret references (such as
+// vault://path#key) in channel configurations.
+func (m *Manager) SetConfigResolver(resolver ConfigResolver) {
+	m.notifier.configResolver = resolver
+}
+
+// SetEvaluationInterval changes the interval between evaluation cycles. A
+// running manager waits the new interval from the time of the change.
+func (m *Manager) SetEvaluationInterval(interval time.Duration) {
+	if interval <= 0 || time.Duration(m.interval.Swap(int64(interval))) == interval {
+		return
+	}
+	select {
+	case m.intervalCh <- struct{}{}:
+	default:
+	}
+}
+
 // Start starts the alert manager evaluation loop.
 func (m *Manager) Start(ctx context.Context) error {
 	m.runMu.Lock()
@@ -75,8 +131,10 @@ func (m *Manager) Start(ctx context.Context) error {
 	m.runMu.Unlock()
 
 	m.logger.Info("starting alert manager",
-		"evaluation_interval", m.config.EvaluationInterval,
+		"evaluation_interval", time.Duration(m.interval.Load()),
 		"prometheus_url", m.config.PrometheusURL,
+		"metrics_endpoints", m.config.MetricsEndpoints,
+		"default_source", m.defaultSource(),
 	)
 
 	go m.evaluationLoop(ctx)
@@ -113,7 +171,7 @@ func (m *Manager) Stop() error {
 func (m *Manager) evaluationLoop(ctx context.Context) {
 	defer close(m.stoppedCh)
 
-	ticker := time.NewTicker(m.config.EvaluationInterval)
+	ticker := time.NewTicker(time.Duration(m.interval.Load()))
 	defer ticker.Stop()
 
 	// Run initial evaluation
@@ -127,6 +185,10 @@ func (m *Manager) evaluationLoop(ctx context.Context) {
 		case <-m.stopCh:
 			m.logger.Info("stop signal received, stopping evaluation loop")
 			return
+		case <-m.intervalCh:
+			interval := time.Duration(m.interval.Load())
+			m.logger.Info("evaluation interval changed", "evaluation_interval", interval)
+			ticker.Reset(interval)
 		case <-ticker.C:
 			m.runEvaluation(ctx)
 		}
@@ -149,8 +211,15 @@ func (m *Manager) runEvaluation(ctx context.Context) {
 
 // evaluateRules evaluates all enabled alert rules.
 func (m *Manager) evaluateRules(ctx context.Context) error {
+	// Notify the final state of alerts that stopped flapping
+	m.notifySettledFlaps(ctx)
+
+	// Send the notification groups that are due once this cycle's alerts
+	// joined them
+	defer m.flushNotifications(ctx)
+
 	// Load all enabled rules
-	rules, err := m.repo.ListRules(ctx, true)
+	rules, err := m.repo.ListRules(ctx, nil, true)
 	if err != nil {
 		return fmt.Errorf("failed to list rules: %w", err)
 	}
@@ -166,7 +235,7 @@ func (m *Manager) evaluateRules(ctx context.Context) error {
 	seenFingerprints := make(map[string]bool)
 
 	for _, rule := range rules {
-		results, err := m.evaluator.Evaluate(ctx, rule)
+		results, err := m.evaluate(ctx, rule)
 		if err != nil {
 			m.logger.Error("failed to evaluate rule",
 				"rule_id", rule.ID,
@@ -178,7 +247,9 @@ func (m *Manager) evaluateRules(ctx context.Context) error {
 
 		for _, result := range results {
 			fingerprint := GenerateFingerprint(rule.ID, result.Labels)
-			seenFingerprints[fingerprint] = true
+			if result.ShouldFire {
+				seenFingerprints[fingerprint] = true
+			}
 
 			if err := m.processEvaluation(ctx, rule, result, fingerprint); err != nil {
 				m.logger.Error("failed to process evaluation",
@@ -310,11 +381,15 @@ func (m *Manager) fireAlert(ctx context.Context, rule AlertRule, result Evaluati
 	}
 
 	// Record in history
+	message := fmt.Sprintf("Alert fired: %s %s %.2f", rule.MetricName, rule.Operator.String(), result.Value)
+	if rule.Expression != "" {
+		message = fmt.Sprintf("Alert fired: %s (value %.2f)", rule.Expression, result.Value)
+	}
 	if _, err := m.repo.CreateHistory(ctx, &AlertHistory{
 		AlertID:   created.ID,
 		RuleID:    rule.ID,
 		EventType: EventFired,
-		Message:   fmt.Sprintf("Alert fired: %s %s %.2f", rule.MetricName, rule.Operator.String(), result.Value),
+		Message:   message,
 		Value:     &result.Value,
 	}); err != nil {
 		m.logger.Warn("failed to create alert history", "error", err)
@@ -326,7 +401,7 @@ func (m *Manager) fireAlert(ctx context.Context, rule AlertRule, result Evaluati
 	m.mu.Unlock()
 
 	// Send notification
-	return m.notifier.Notify(ctx, *created, rule, EventFired)
+	return m.notifyStateChange(ctx, *created, rule, EventFired)
 }
 
 // resolveAlert resolves an alert instance.
@@ -359,21 +434,100 @@ func (m *Manager) resolveAlert(ctx context.Context, instance AlertInstance) erro
 	}
 
 	// Clear notification tracking
-	m.notifier.ClearLastNotified(instance.Fingerprint)
+	m.notifier.ClearLastNotified(ctx, instance.Fingerprint)
 
 	// Update instance for notification
 	instance.Status = StatusResolved
 	instance.ResolvedAt = &now
 
 	// Send notification
-	return m.notifier.Notify(ctx, instance, *rule, EventResolved)
+	return m.notifyStateChange(ctx, instance, *rule, EventResolved)
+}
+
+// notifyStateChange notifies a fired or resolved transition, collapsing
+// transitions that happen within the rule's minimum notification interval
+// into a single flapping notification.
+func (m *Manager) notifyStateChange(ctx context.Context, instance AlertInstance, rule AlertRule, eventType EventType) error {
+	interval := m.minNotificationInterval(rule)
+	decision, transitions := m.flaps.Observe(flapNotification{instance: instance, rule: rule, eventType: eventType}, interval)
+
+	switch decision {
+	case flapSuppress:
+		m.logger.Debug("suppressing notification for flapping alert",
+			"rule_name", rule.Name,
+			"fingerprint", instance.Fingerprint,
+			"event_type", eventType,
+		)
+		return nil
+	case flapDetected:
+		m.logger.Warn("alert is flapping, suppressing state-change notifications",
+			"rule_name", rule.Name,
+			"fingerprint", instance.Fingerprint,
+			"min_notification_interval", interval,
+		)
+
+		if _, err := m.repo.CreateHistory(ctx, &AlertHistory{
+			AlertID:   instance.ID,
+			RuleID:    rule.ID,
+			EventType: EventFlapping,
+			Message:   fmt.Sprintf("Alert is flapping: state changed within %s, notifications suppressed until stable", interval),
+			Value:     instance.CurrentValue,
+			Metadata: map[string]any{
+				"last_event":                        string(eventType),
+				"transitions":                       transitions,
+				"min_notification_interval_seconds": int(interval.Seconds()),
+			},
+		}); err != nil {
+			m.logger.Warn("failed to create alert history", "error", err)
+		}
+
+		return m.notifier.Notify(ctx, instance, rule, EventFlapping)
+	}
+
+	return m.notifier.Notify(ctx, instance, rule, eventType)
+}
+
+// flushNotifications sends the notification groups whose wait or interval
+// has passed.
+func (m *Manager) flushNotifications(ctx context.Context) {
+	if err := m.notifier.Flush(ctx); err != nil {
+		m.logger.Error("failed to send grouped notifications", "error", err)
+	}
+}
+
+// notifySettledFlaps notifies the final state of alerts that stopped flapping,
+// since the state changes suppressed while they flapped were never sent.
+func (m *Manager) notifySettledFlaps(ctx context.Context) {
+	for _, n := range m.flaps.Settled() {
+		m.logger.Info("alert stopped flapping, notifying final state",
+			"rule_name", n.rule.Name,
+			"fingerprint", n.instance.Fingerprint,
+			"event_type", n.eventType,
+		)
+		if err := m.notifier.Notify(ctx, n.instance, n.rule, n.eventType); err != nil {
+			m.logger.Error("failed to notify settled alert",
+				"rule_name", n.rule.Name,
+				"fingerprint", n.instance.Fingerprint,
+				"error", err,
+			)
+		}
+	}
+}
+
+// minNotificationInterval returns the minimum interval between state-change
+// notifications for a rule, falling back to the configured default.
+func (m *Manager) minNotificationInterval(rule AlertRule) time.Duration {
+	if rule.MinNotificationIntervalSeconds > 0 {
+		return time.Duration(rule.MinNotificationIntervalSeconds) * time.Second
+	}
+	return m.config.MinNotificationInterval
 }
 
 // checkForResolutions checks for alerts that should be resolved.
 func (m *Manager) checkForResolutions(ctx context.Context, seenFingerprints map[string]bool) error {
 	// Get all firing alerts
 	status := StatusFiring
-	firingAlerts, err := m.repo.ListInstances(ctx, &status, nil)
+	firingAlerts, err := m.repo.ListInstances(ctx, nil, &status, nil)
 	if err != nil {
 		return fmt.Errorf("failed to list firing alerts: %w", err)
 	}
@@ -434,7 +588,42 @@ func (m *Manager) IsRunning() bool {
 	return m.running
 }
 
-// Evaluator returns the underlying evaluator (useful for testing).
+// evaluate evaluates a rule against the provider of its metric source.
+func (m *Manager) evaluate(ctx context.Context, rule AlertRule) ([]EvaluationResult, error) {
+	source := rule.Source
+	if source == "" {
+		source = m.defaultSource()
+	}
+
+	switch source {
+	case MetricSourcePrometheus:
+		if m.evaluator == nil {
+			return nil, fmt.Errorf("rule reads prometheus but no prometheus URL is configured")
+		}
+		return m.evaluator.Evaluate(ctx, rule)
+	case MetricSourceInternal:
+		return EvaluateRule(ctx, m.internal, rule, m.logger)
+	case MetricSourceRemoteWrite:
+		if m.pushed == nil {
+			return nil, fmt.Errorf("rule reads remote-write metrics but the receiver is not enabled")
+		}
+		return EvaluateRule(ctx, m.pushed, rule, m.logger)
+	default:
+		return nil, fmt.Errorf("unknown metric source %q", source)
+	}
+}
+
+// defaultSource returns the metric source of rules that don't set one:
+// Prometheus when a URL is configured, otherwise the internal registry.
+func (m *Manager) defaultSource() MetricSource {
+	if m.evaluator != nil {
+		return MetricSourcePrometheus
+	}
+	return MetricSourceInternal
+}
+
+// Evaluator returns the underlying Prometheus evaluator, or nil when no
+// Prometheus URL is configured (useful for testing).
 func (m *Manager) Evaluator() *Evaluator {
 	return m.evaluator
 }
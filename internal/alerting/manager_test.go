@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,11 +24,12 @@ type mockRepository struct {
 	channels       []NotificationChannel
 	routes         []AlertRoute
 	histories      []AlertHistory
+	notified       []NotificationLogEntry
 	getInstanceErr error
 	listRulesErr   error
 }
 
-func (m *mockRepository) ListRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error) {
+func (m *mockRepository) ListRules(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]AlertRule, error) {
 	if m.listRulesErr != nil {
 		return nil, m.listRulesErr
 	}
@@ -63,7 +66,9 @@ func (m *mockRepository) GetInstanceByFingerprint(ctx context.Context, ruleID uu
 	if m.getInstanceErr != nil {
 		return nil, m.getInstanceErr
 	}
-	for _, i := range m.instances {
+	// Return the most recent instance, like the database does
+	for idx := len(m.instances) - 1; idx >= 0; idx-- {
+		i := m.instances[idx]
 		if i.RuleID == ruleID && i.Fingerprint == fingerprint {
 			return &i, nil
 		}
@@ -71,7 +76,7 @@ func (m *mockRepository) GetInstanceByFingerprint(ctx context.Context, ruleID uu
 	return nil, fmt.Errorf("instance not found")
 }
 
-func (m *mockRepository) ListInstances(ctx context.Context, status *AlertStatus, ruleID *uuid.UUID) ([]AlertInstance, error) {
+func (m *mockRepository) ListInstances(ctx context.Context, tenantID *uuid.UUID, status *AlertStatus, ruleID *uuid.UUID) ([]AlertInstance, error) {
 	var result []AlertInstance
 	for _, i := range m.instances {
 		if status != nil && i.Status != *status {
@@ -127,7 +132,7 @@ func (m *mockRepository) GetChannel(ctx context.Context, id uuid.UUID) (*Notific
 	return nil, fmt.Errorf("channel not found")
 }
 
-func (m *mockRepository) ListChannels(ctx context.Context, enabledOnly bool) ([]NotificationChannel, error) {
+func (m *mockRepository) ListChannels(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]NotificationChannel, error) {
 	if enabledOnly {
 		var result []NotificationChannel
 		for _, c := range m.channels {
@@ -154,11 +159,38 @@ func (m *mockRepository) ListRoutes(ctx context.Context, ruleID *uuid.UUID, enab
 	return result, nil
 }
 
+func (m *mockRepository) ListNotificationLog(ctx context.Context) ([]NotificationLogEntry, error) {
+	return m.notified, nil
+}
+
+func (m *mockRepository) RecordNotification(ctx context.Context, routeID uuid.UUID, fingerprint string, notifiedAt time.Time) error {
+	for i, e := range m.notified {
+		if e.RouteID == routeID && e.Fingerprint == fingerprint {
+			m.notified[i].NotifiedAt = notifiedAt
+			return nil
+		}
+	}
+	m.notified = append(m.notified, NotificationLogEntry{RouteID: routeID, Fingerprint: fingerprint, NotifiedAt: notifiedAt})
+	return nil
+}
+
+func (m *mockRepository) DeleteNotificationLog(ctx context.Context, fingerprint string) error {
+	kept := m.notified[:0]
+	for _, e := range m.notified {
+		if e.Fingerprint != fingerprint {
+			kept = append(kept, e)
+		}
+	}
+	m.notified = kept
+	return nil
+}
+
 func TestNewManager(t *testing.T) {
 	tests := []struct {
-		name    string
-		cfg     config.AlertingConfig
-		wantErr bool
+		name       string
+		cfg        config.AlertingConfig
+		wantErr    bool
+		wantSource MetricSource
 	}{
 		{
 			name: "valid config",
@@ -166,14 +198,16 @@ func TestNewManager(t *testing.T) {
 				PrometheusURL:      "http://localhost:9090",
 				EvaluationInterval: 30 * time.Second,
 			},
-			wantErr: false,
+			wantErr:    false,
+			wantSource: MetricSourcePrometheus,
 		},
 		{
-			name: "missing prometheus URL",
+			name: "no prometheus URL uses internal metrics",
 			cfg: config.AlertingConfig{
 				EvaluationInterval: 30 * time.Second,
 			},
-			wantErr: true,
+			wantErr:    false,
+			wantSource: MetricSourceInternal,
 		},
 	}
 
@@ -191,8 +225,11 @@ func TestNewManager(t *testing.T) {
 				if m == nil {
 					t.Fatal("NewManager() returned nil")
 				}
-				if m.evaluator == nil {
-					t.Error("evaluator should not be nil")
+				if got := m.defaultSource(); got != tt.wantSource {
+					t.Errorf("defaultSource() = %s, want %s", got, tt.wantSource)
+				}
+				if m.internal == nil {
+					t.Error("internal provider should not be nil")
 				}
 				if m.notifier == nil {
 					t.Error("notifier should not be nil")
@@ -556,6 +593,135 @@ func TestManager_AlertNotFiringClearsPending(t *testing.T) {
 	}
 }
 
+// recordingSender records the events of the notifications it sends.
+type recordingSender struct {
+	events *[]EventType
+}
+
+func (s recordingSender) Type() ChannelType { return ChannelWebhook }
+
+func (s recordingSender) Send(ctx context.Context, notification Notification) error {
+	*s.events = append(*s.events, notification.Event)
+	return nil
+}
+
+func TestManager_FlappingAlertSendsSingleNotification(t *testing.T) {
+	ruleID := uuid.New()
+	channelID := uuid.New()
+	callCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		// Hold each value for two evaluations so the alert fires (pending
+		// first) and then resolves, over and over.
+		value := "100"
+		if (callCount-1)/2%2 == 1 {
+			value = "30"
+		}
+
+		response := prometheusResponse{
+			Status: "success",
+			Data: struct {
+				ResultType string             `json:"resultType"`
+				Result     []prometheusResult `json:"result"`
+			}{
+				ResultType: "vector",
+				Result: []prometheusResult{
+					{
+						Metric: map[string]string{"source": "db1"},
+						Value:  []interface{}{float64(time.Now().Unix()), value},
+					},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	repo := &mockRepository{
+		rules: []AlertRule{
+			{
+				ID:                             ruleID,
+				Name:                           "flapping-rule",
+				MetricName:                     "test_metric",
+				Operator:                       OpGreaterThan,
+				Threshold:                      50,
+				MinNotificationIntervalSeconds: 300,
+				Enabled:                        true,
+			},
+		},
+		channels: []NotificationChannel{
+			{ID: channelID, Name: "hook", Type: ChannelWebhook, Enabled: true},
+		},
+		routes: []AlertRoute{
+			{ID: uuid.New(), RuleID: ruleID, ChannelID: channelID, Enabled: true},
+		},
+	}
+
+	cfg := config.AlertingConfig{
+		PrometheusURL:      server.URL,
+		EvaluationInterval: 10 * time.Second,
+	}
+
+	m, err := NewManager(repo, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	var events []EventType
+	m.SetChannelFactory(func(ChannelType, map[string]interface{}, *slog.Logger) (ChannelSender, error) {
+		return recordingSender{events: &events}, nil
+	})
+
+	now := time.Now()
+	m.flaps.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 16; i++ {
+		if err := m.EvaluateNow(ctx); err != nil {
+			t.Fatalf("EvaluateNow() error = %v", err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+
+	want := []EventType{EventFired, EventFlapping}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Fatalf("notifications = %v, want %v", events, want)
+	}
+
+	var flapping, fired, resolved int
+	for _, h := range repo.histories {
+		switch h.EventType {
+		case EventFlapping:
+			flapping++
+		case EventFired:
+			fired++
+		case EventResolved:
+			resolved++
+		}
+	}
+	if flapping != 1 {
+		t.Errorf("flapping history entries = %d, want 1", flapping)
+	}
+	if fired != 4 || resolved != 4 {
+		t.Errorf("history fired/resolved = %d/%d, want 4/4", fired, resolved)
+	}
+
+	// Once the alert has been stable for the interval, its final state is
+	// notified once, and later state changes are notified normally again.
+	now = now.Add(5 * time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := m.EvaluateNow(ctx); err != nil {
+			t.Fatalf("EvaluateNow() error = %v", err)
+		}
+	}
+	want = []EventType{EventFired, EventFlapping, EventResolved, EventFired}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("notifications after quiet period = %v, want %v", events, want)
+	}
+}
+
 func TestManager_SilencedAlert(t *testing.T) {
 	ruleID := uuid.New()
 	now := time.Now()
@@ -668,6 +834,41 @@ func TestManager_ContextCancellation(t *testing.T) {
 	}
 }
 
+// countingRepository counts evaluation cycles by their ListRules calls.
+type countingRepository struct {
+	*mockRepository
+	cycles atomic.Int32
+}
+
+func (r *countingRepository) ListRules(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]AlertRule, error) {
+	r.cycles.Add(1)
+	return r.mockRepository.ListRules(ctx, tenantID, enabledOnly)
+}
+
+func TestManager_SetEvaluationInterval(t *testing.T) {
+	repo := &countingRepository{mockRepository: &mockRepository{}}
+	cfg := config.AlertingConfig{EvaluationInterval: time.Hour}
+
+	m, err := NewManager(repo, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer m.Stop()
+
+	m.SetEvaluationInterval(10 * time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.cycles.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("ran %d evaluation cycles, want the new interval used without waiting for the old one", repo.cycles.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
 func TestManager_NoEnabledRules(t *testing.T) {
 	ruleID := uuid.New()
 
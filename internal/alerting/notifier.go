@@ -5,7 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,13 +16,13 @@ import (
 // This is defined here to avoid circular imports with the repositories package.
 type AlertRepository interface {
 	// Rule operations
-	ListRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error)
+	ListRules(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]AlertRule, error)
 	GetRule(ctx context.Context, id uuid.UUID) (*AlertRule, error)
 
 	// Instance operations
 	CreateInstance(ctx context.Context, instance *AlertInstance) (*AlertInstance, error)
 	GetInstanceByFingerprint(ctx context.Context, ruleID uuid.UUID, fingerprint string) (*AlertInstance, error)
-	ListInstances(ctx context.Context, status *AlertStatus, ruleID *uuid.UUID) ([]AlertInstance, error)
+	ListInstances(ctx context.Context, tenantID *uuid.UUID, status *AlertStatus, ruleID *uuid.UUID) ([]AlertInstance, error)
 	UpdateInstance(ctx context.Context, id uuid.UUID, status AlertStatus, currentValue *float64, resolvedAt *time.Time) error
 
 	// History operations
@@ -33,10 +33,15 @@ type AlertRepository interface {
 
 	// Channel operations
 	GetChannel(ctx context.Context, id uuid.UUID) (*NotificationChannel, error)
-	ListChannels(ctx context.Context, enabledOnly bool) ([]NotificationChannel, error)
+	ListChannels(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]NotificationChannel, error)
 
 	// Route operations
 	ListRoutes(ctx context.Context, ruleID *uuid.UUID, enabledOnly bool) ([]AlertRoute, error)
+
+	// Notification log operations
+	ListNotificationLog(ctx context.Context) ([]NotificationLogEntry, error)
+	RecordNotification(ctx context.Context, routeID uuid.UUID, fingerprint string, notifiedAt time.Time) error
+	DeleteNotificationLog(ctx context.Context, fingerprint string) error
 }
 
 // ChannelSender defines the interface for sending notifications through a channel.
@@ -48,16 +53,54 @@ type ChannelSender interface {
 // ChannelFactory creates channel senders from configuration.
 type ChannelFactory func(channelType ChannelType, config map[string]interface{}, logger *slog.Logger) (ChannelSender, error)
 
-// Notifier dispatches notifications to configured channels.
+// ConfigResolver resolves secret references in a channel configuration,
+// returning a copy with the referenced secrets filled in.
+type ConfigResolver func(ctx context.Context, config map[string]any) (map[string]any, error)
+
+// Notifier dispatches notifications to configured channels. Alerts routed
+// to the same channel whose rules carry the same labels are batched into
+// groups: a new group is sent once it has waited the route's group wait, and
+// alerts added to it later go out together every group interval. A firing
+// alert is not notified again through a route until its repeat interval has
+// passed; the last notification times are persisted so a restart does not
+// notify again early.
 type Notifier struct {
 	repo           AlertRepository
 	channelFactory ChannelFactory
+	configResolver ConfigResolver
 	logger         *slog.Logger
 	timeout        time.Duration
+	now            func() time.Time
+
+	// Track last notification time per route and alert
+	lastNotified map[notifiedKey]time.Time
+	logLoaded    bool
+	groups       map[string]*notificationGroup
+	mu           sync.Mutex
+}
+
+// notifiedKey identifies an alert notified through a route.
+type notifiedKey struct {
+	routeID     uuid.UUID
+	fingerprint string
+}
+
+// notificationGroup batches the alerts waiting to be sent through a channel.
+type notificationGroup struct {
+	channelID uuid.UUID
+	wait      time.Duration
+	interval  time.Duration
+	createdAt time.Time
+	lastSent  time.Time
+	pending   []pendingNotification
+}
 
-	// Track last notification time per alert+channel
-	lastNotified map[string]time.Time // fingerprint:channel_id -> time
-	mu           sync.RWMutex
+// pendingNotification is an alert event waiting in a group.
+type pendingNotification struct {
+	alert AlertInstance
+	rule  AlertRule
+	route AlertRoute
+	event EventType
 }
 
 // NewNotifier creates a new notifier.
@@ -74,11 +117,14 @@ func NewNotifier(repo AlertRepository, channelFactory ChannelFactory, timeout ti
 		channelFactory: channelFactory,
 		logger:         logger.With("component", "alert-notifier"),
 		timeout:        timeout,
-		lastNotified:   make(map[string]time.Time),
+		now:            time.Now,
+		lastNotified:   make(map[notifiedKey]time.Time),
+		groups:         make(map[string]*notificationGroup),
 	}
 }
 
-// Notify sends notifications for an alert to all configured channels.
+// Notify queues notifications for an alert on all configured routes, and
+// sends the groups it joined that are already due.
 func (n *Notifier) Notify(ctx context.Context, alert AlertInstance, rule AlertRule, eventType EventType) error {
 	n.logger.Debug("processing notification",
 		"alert_id", alert.ID,
@@ -101,56 +147,119 @@ func (n *Notifier) Notify(ctx context.Context, alert AlertInstance, rule AlertRu
 		return nil
 	}
 
-	var notifyErrors []error
+	n.loadNotificationLog(ctx)
 
+	joined := make(map[string]bool)
 	for _, route := range routes {
 		// Check if we should skip due to repeat interval
-		if !n.shouldNotify(alert.Fingerprint, route.ChannelID, route.RepeatIntervalSeconds, eventType) {
+		if !n.shouldNotify(route.ID, alert.Fingerprint, route.RepeatIntervalSeconds, eventType) {
 			n.logger.Debug("skipping notification due to repeat interval",
 				"alert_fingerprint", alert.Fingerprint,
-				"channel_id", route.ChannelID,
+				"route_id", route.ID,
 			)
 			continue
 		}
 
-		// Get the channel
-		channel, err := n.repo.GetChannel(ctx, route.ChannelID)
-		if err != nil {
-			n.logger.Error("failed to get channel",
-				"channel_id", route.ChannelID,
-				"error", err,
-			)
-			notifyErrors = append(notifyErrors, fmt.Errorf("failed to get channel %s: %w", route.ChannelID, err))
-			continue
+		joined[n.enqueue(pendingNotification{alert: alert, rule: rule, route: route, event: eventType})] = true
+	}
+
+	return n.flush(ctx, func(key string) bool { return joined[key] })
+}
+
+// Flush sends every group that is due. It is called on each evaluation
+// cycle, so groups are sent once their wait or interval has passed even
+// when no new alerts arrive.
+func (n *Notifier) Flush(ctx context.Context) error {
+	return n.flush(ctx, func(string) bool { return true })
+}
+
+// groupKey identifies the group of a notification: alerts for the same
+// channel whose rules carry the same labels are sent together.
+func groupKey(channelID uuid.UUID, ruleLabels map[string]string) string {
+	return channelID.String() + ":" + GenerateFingerprint(uuid.Nil, ruleLabels)
+}
+
+// enqueue adds a notification to its group and returns the group key. A
+// newer event for an alert already waiting in the group replaces it, and an
+// alert that resolves before its firing notification went out is dropped.
+func (n *Notifier) enqueue(p pendingNotification) string {
+	key := groupKey(p.route.ChannelID, p.rule.Labels)
+	wait := time.Duration(p.route.GroupWaitSeconds) * time.Second
+	interval := time.Duration(p.route.GroupIntervalSeconds) * time.Second
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	group, ok := n.groups[key]
+	if !ok {
+		group = &notificationGroup{
+			channelID: p.route.ChannelID,
+			wait:      wait,
+			interval:  interval,
+			createdAt: n.now(),
 		}
+		n.groups[key] = group
+	}
+	// Routes sharing a group use the shortest timings among them
+	group.wait = min(group.wait, wait)
+	group.interval = min(group.interval, interval)
 
-		if !channel.Enabled {
-			n.logger.Debug("skipping disabled channel",
-				"channel_id", channel.ID,
-				"channel_name", channel.Name,
-			)
+	for i, queued := range group.pending {
+		if queued.route.ID != p.route.ID || queued.alert.Fingerprint != p.alert.Fingerprint {
 			continue
 		}
+		if p.event == EventResolved && queued.event == EventFired {
+			group.pending = append(group.pending[:i], group.pending[i+1:]...)
+			return key
+		}
+		group.pending[i] = p
+		return key
+	}
+	group.pending = append(group.pending, p)
+	return key
+}
 
-		// Create the notification
-		notification := Notification{
-			Alert:   &alert,
-			Rule:    &rule,
-			Channel: channel,
-			Route:   &route,
-			Event:   eventType,
+// due reports whether the group has alerts to send and has waited long
+// enough: the group wait before its first notification, the group interval
+// after that.
+func (g *notificationGroup) due(now time.Time) bool {
+	if len(g.pending) == 0 {
+		return false
+	}
+	if g.lastSent.IsZero() {
+		return !now.Before(g.createdAt.Add(g.wait))
+	}
+	return !now.Before(g.lastSent.Add(g.interval))
+}
+
+// flush sends the due groups selected by include, one notification per
+// group.
+func (n *Notifier) flush(ctx context.Context, include func(key string) bool) error {
+	now := n.now()
+
+	n.mu.Lock()
+	var batches [][]pendingNotification
+	for key, group := range n.groups {
+		if !include(key) {
+			continue
+		}
+		if group.due(now) {
+			batches = append(batches, group.pending)
+			group.pending = nil
+			group.lastSent = now
+			continue
+		}
+		// Forget idle groups, so the next alert waits the group wait again
+		if len(group.pending) == 0 && !now.Before(group.lastSent.Add(group.interval)) {
+			delete(n.groups, key)
 		}
+	}
+	n.mu.Unlock()
 
-		// Send the notification
-		if err := n.sendNotification(ctx, notification); err != nil {
+	var notifyErrors []error
+	for _, batch := range batches {
+		if err := n.sendGroup(ctx, batch); err != nil {
 			notifyErrors = append(notifyErrors, err)
-			// Record notification failure in history
-			n.recordNotificationEvent(ctx, alert, rule, channel, EventNotificationFailed, err.Error())
-		} else {
-			// Update last notified time
-			n.updateLastNotified(alert.Fingerprint, channel.ID)
-			// Record successful notification in history
-			n.recordNotificationEvent(ctx, alert, rule, channel, EventNotificationSent, "")
 		}
 	}
 
@@ -161,34 +270,136 @@ func (n *Notifier) Notify(ctx context.Context, alert AlertInstance, rule AlertRu
 	return nil
 }
 
+// sendGroup sends a batch of alerts for one channel as a single
+// notification, led by the most urgent alert.
+func (n *Notifier) sendGroup(ctx context.Context, batch []pendingNotification) error {
+	channelID := batch[0].route.ChannelID
+
+	// Get the channel
+	channel, err := n.repo.GetChannel(ctx, channelID)
+	if err != nil {
+		n.logger.Error("failed to get channel",
+			"channel_id", channelID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to get channel %s: %w", channelID, err)
+	}
+
+	if !channel.Enabled {
+		n.logger.Debug("skipping disabled channel",
+			"channel_id", channel.ID,
+			"channel_name", channel.Name,
+		)
+		return nil
+	}
+
+	// Firing and flapping alerts lead, oldest first
+	sort.SliceStable(batch, func(i, j int) bool {
+		ri, rj := batch[i].event == EventResolved, batch[j].event == EventResolved
+		if ri != rj {
+			return rj
+		}
+		return batch[i].alert.FiredAt.Before(batch[j].alert.FiredAt)
+	})
+
+	notifications := make([]Notification, len(batch))
+	for i := range batch {
+		notifications[i] = Notification{
+			Alert:   &batch[i].alert,
+			Rule:    &batch[i].rule,
+			Channel: channel,
+			Route:   &batch[i].route,
+			Event:   batch[i].event,
+		}
+	}
+	notification := notifications[0]
+	if len(notifications) > 1 {
+		notification.Grouped = notifications[1:]
+	}
+
+	// Send the notification
+	if err := n.sendNotification(ctx, notification); err != nil {
+		// Record notification failure in history
+		for _, p := range batch {
+			n.recordNotificationEvent(ctx, p.alert, p.rule, channel, EventNotificationFailed, err.Error())
+		}
+		return err
+	}
+
+	for _, p := range batch {
+		// Resolved alerts had their notification times cleared already
+		if p.event != EventResolved {
+			n.updateLastNotified(ctx, p.route.ID, p.alert.Fingerprint)
+		}
+		// Record successful notification in history
+		n.recordNotificationEvent(ctx, p.alert, p.rule, channel, EventNotificationSent, "")
+	}
+
+	return nil
+}
+
+// loadNotificationLog loads the persisted last notification times once,
+// retrying on the next notification if the repository fails.
+func (n *Notifier) loadNotificationLog(ctx context.Context) {
+	n.mu.Lock()
+	loaded := n.logLoaded
+	n.mu.Unlock()
+	if loaded {
+		return
+	}
+
+	entries, err := n.repo.ListNotificationLog(ctx)
+	if err != nil {
+		n.logger.Warn("failed to load notification log", "error", err)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, e := range entries {
+		key := notifiedKey{routeID: e.RouteID, fingerprint: e.Fingerprint}
+		if e.NotifiedAt.After(n.lastNotified[key]) {
+			n.lastNotified[key] = e.NotifiedAt
+		}
+	}
+	n.logLoaded = true
+}
+
 // shouldNotify checks if we should send a notification based on repeat interval.
-func (n *Notifier) shouldNotify(fingerprint string, channelID uuid.UUID, repeatIntervalSeconds int, eventType EventType) bool {
-	// Always notify on resolved events
-	if eventType == EventResolved {
+func (n *Notifier) shouldNotify(routeID uuid.UUID, fingerprint string, repeatIntervalSeconds int, eventType EventType) bool {
+	// Always notify on resolved and flapping events
+	if eventType == EventResolved || eventType == EventFlapping {
 		return true
 	}
 
-	key := fmt.Sprintf("%s:%s", fingerprint, channelID.String())
-
-	n.mu.RLock()
-	lastTime, exists := n.lastNotified[key]
-	n.mu.RUnlock()
+	n.mu.Lock()
+	lastTime, exists := n.lastNotified[notifiedKey{routeID: routeID, fingerprint: fingerprint}]
+	n.mu.Unlock()
 
 	if !exists {
 		return true
 	}
 
 	repeatInterval := time.Duration(repeatIntervalSeconds) * time.Second
-	return time.Since(lastTime) >= repeatInterval
+	return n.now().Sub(lastTime) >= repeatInterval
 }
 
-// updateLastNotified updates the last notification time for an alert+channel.
-func (n *Notifier) updateLastNotified(fingerprint string, channelID uuid.UUID) {
-	key := fmt.Sprintf("%s:%s", fingerprint, channelID.String())
+// updateLastNotified updates and persists the last notification time for an
+// alert on a route.
+func (n *Notifier) updateLastNotified(ctx context.Context, routeID uuid.UUID, fingerprint string) {
+	now := n.now()
 
 	n.mu.Lock()
-	n.lastNotified[key] = time.Now()
+	n.lastNotified[notifiedKey{routeID: routeID, fingerprint: fingerprint}] = now
 	n.mu.Unlock()
+
+	if err := n.repo.RecordNotification(ctx, routeID, fingerprint, now); err != nil {
+		n.logger.Warn("failed to record notification time",
+			"route_id", routeID,
+			"alert_fingerprint", fingerprint,
+			"error", err,
+		)
+	}
 }
 
 // sendNotification sends a notification through a channel.
@@ -201,8 +412,18 @@ func (n *Notifier) sendNotification(ctx context.Context, notification Notificati
 		return fmt.Errorf("channel factory not configured")
 	}
 
+	// Resolve secret references at send time so rotated secrets are picked up
+	config := notification.Channel.Config
+	if n.configResolver != nil {
+		resolved, err := n.configResolver(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secrets for channel %s: %w", notification.Channel.Name, err)
+		}
+		config = resolved
+	}
+
 	// Create the channel sender
-	sender, err := n.channelFactory(notification.Channel.Type, notification.Channel.Config, n.logger)
+	sender, err := n.channelFactory(notification.Channel.Type, config, n.logger)
 	if err != nil {
 		return fmt.Errorf("failed to create channel sender for %s: %w", notification.Channel.Type, err)
 	}
@@ -266,19 +487,23 @@ func (n *Notifier) recordNotificationEvent(ctx context.Context, alert AlertInsta
 	}
 }
 
-// ClearLastNotified clears the last notification time for an alert.
-// This should be called when an alert is resolved.
-func (n *Notifier) ClearLastNotified(fingerprint string) {
+// ClearLastNotified clears the last notification times of an alert on all
+// routes. This should be called when an alert is resolved.
+func (n *Notifier) ClearLastNotified(ctx context.Context, fingerprint string) {
 	n.mu.Lock()
-	defer n.mu.Unlock()
-
-	// Clear all entries for this fingerprint
-	prefix := fingerprint + ":"
 	for key := range n.lastNotified {
-		if strings.HasPrefix(key, prefix) {
+		if key.fingerprint == fingerprint {
 			delete(n.lastNotified, key)
 		}
 	}
+	n.mu.Unlock()
+
+	if err := n.repo.DeleteNotificationLog(ctx, fingerprint); err != nil {
+		n.logger.Warn("failed to clear notification times",
+			"alert_fingerprint", fingerprint,
+			"error", err,
+		)
+	}
 }
 
 // NotifyBatch sends notifications for multiple alerts efficiently.
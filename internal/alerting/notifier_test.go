@@ -0,0 +1,223 @@
+package alerting
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// capturingSender keeps the notifications it sends.
+type capturingSender struct {
+	sent *[]Notification
+}
+
+func (s capturingSender) Type() ChannelType { return ChannelWebhook }
+
+func (s capturingSender) Send(ctx context.Context, notification Notification) error {
+	*s.sent = append(*s.sent, notification)
+	return nil
+}
+
+// newTestNotifier creates a notifier on repo with a controllable clock.
+func newTestNotifier(repo *mockRepository, now *time.Time, sent *[]Notification) *Notifier {
+	n := NewNotifier(repo, func(ChannelType, map[string]interface{}, *slog.Logger) (ChannelSender, error) {
+		return capturingSender{sent: sent}, nil
+	}, 0, nil)
+	n.now = func() time.Time { return *now }
+	return n
+}
+
+func TestNotifier_GroupsAlertsSharingLabels(t *testing.T) {
+	channelID := uuid.New()
+	labels := map[string]string{"team": "data"}
+	lag := AlertRule{ID: uuid.New(), Name: "lag", Labels: labels}
+	dlq := AlertRule{ID: uuid.New(), Name: "dlq", Labels: labels}
+	other := AlertRule{ID: uuid.New(), Name: "other", Labels: map[string]string{"team": "web"}}
+
+	repo := &mockRepository{
+		channels: []NotificationChannel{{ID: channelID, Name: "hook", Type: ChannelWebhook, Enabled: true}},
+	}
+	for _, rule := range []AlertRule{lag, dlq, other} {
+		repo.routes = append(repo.routes, AlertRoute{
+			ID: uuid.New(), RuleID: rule.ID, ChannelID: channelID, Enabled: true,
+			RepeatIntervalSeconds: 3600, GroupWaitSeconds: 30, GroupIntervalSeconds: 300,
+		})
+	}
+
+	now := time.Now()
+	var sent []Notification
+	n := newTestNotifier(repo, &now, &sent)
+	ctx := context.Background()
+
+	for _, rule := range []AlertRule{lag, dlq, other} {
+		alert := AlertInstance{ID: uuid.New(), RuleID: rule.ID, Fingerprint: rule.Name, FiredAt: now}
+		if err := n.Notify(ctx, alert, rule, EventFired); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	if len(sent) != 0 {
+		t.Fatalf("sent %d notifications during the group wait, want 0", len(sent))
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("sent %d notifications, want one per group", len(sent))
+	}
+	for _, notification := range sent {
+		switch notification.Rule.Name {
+		case "lag":
+			if len(notification.Grouped) != 1 || notification.Grouped[0].Rule.Name != "dlq" {
+				t.Errorf("lag notification grouped %d alerts, want dlq", len(notification.Grouped))
+			}
+		case "other":
+			if len(notification.Grouped) != 0 {
+				t.Errorf("other notification grouped %d alerts, want none", len(notification.Grouped))
+			}
+		default:
+			t.Errorf("unexpected notification led by %s", notification.Rule.Name)
+		}
+	}
+
+	// Still firing: nothing is sent again within the repeat interval
+	sent = nil
+	now = now.Add(10 * time.Minute)
+	alert := AlertInstance{ID: uuid.New(), RuleID: lag.ID, Fingerprint: lag.Name, FiredAt: now}
+	if err := n.Notify(ctx, alert, lag, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("sent %d notifications within the repeat interval, want 0", len(sent))
+	}
+}
+
+func TestNotifier_GroupIntervalBatchesLaterAlerts(t *testing.T) {
+	channelID := uuid.New()
+	rule := AlertRule{ID: uuid.New(), Name: "lag"}
+	repo := &mockRepository{
+		channels: []NotificationChannel{{ID: channelID, Name: "hook", Type: ChannelWebhook, Enabled: true}},
+		routes: []AlertRoute{{
+			ID: uuid.New(), RuleID: rule.ID, ChannelID: channelID, Enabled: true,
+			RepeatIntervalSeconds: 3600, GroupIntervalSeconds: 300,
+		}},
+	}
+
+	now := time.Now()
+	var sent []Notification
+	n := newTestNotifier(repo, &now, &sent)
+	ctx := context.Background()
+
+	// Without a group wait the first alert goes out right away
+	if err := n.Notify(ctx, AlertInstance{Fingerprint: "a"}, rule, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+
+	// Later alerts wait for the group interval
+	now = now.Add(time.Minute)
+	for _, fp := range []string{"b", "c"} {
+		if err := n.Notify(ctx, AlertInstance{Fingerprint: fp}, rule, EventFired); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications within the group interval, want 1", len(sent))
+	}
+
+	now = now.Add(4 * time.Minute)
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sent) != 2 || len(sent[1].Grouped) != 1 {
+		t.Fatalf("sent = %d notifications, want the two later alerts in one", len(sent))
+	}
+}
+
+func TestNotifier_ResolvedBeforeSendIsDropped(t *testing.T) {
+	channelID := uuid.New()
+	rule := AlertRule{ID: uuid.New(), Name: "lag"}
+	repo := &mockRepository{
+		channels: []NotificationChannel{{ID: channelID, Name: "hook", Type: ChannelWebhook, Enabled: true}},
+		routes: []AlertRoute{{
+			ID: uuid.New(), RuleID: rule.ID, ChannelID: channelID, Enabled: true,
+			RepeatIntervalSeconds: 3600, GroupWaitSeconds: 30,
+		}},
+	}
+
+	now := time.Now()
+	var sent []Notification
+	n := newTestNotifier(repo, &now, &sent)
+	ctx := context.Background()
+
+	alert := AlertInstance{Fingerprint: "a"}
+	if err := n.Notify(ctx, alert, rule, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := n.Notify(ctx, alert, rule, EventResolved); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("sent %d notifications for an alert resolved before it was notified, want 0", len(sent))
+	}
+}
+
+func TestNotifier_PersistsLastNotified(t *testing.T) {
+	channelID := uuid.New()
+	rule := AlertRule{ID: uuid.New(), Name: "lag"}
+	repo := &mockRepository{
+		channels: []NotificationChannel{{ID: channelID, Name: "hook", Type: ChannelWebhook, Enabled: true}},
+		routes: []AlertRoute{{
+			ID: uuid.New(), RuleID: rule.ID, ChannelID: channelID, Enabled: true,
+			RepeatIntervalSeconds: 3600,
+		}},
+	}
+
+	now := time.Now()
+	var sent []Notification
+	ctx := context.Background()
+	alert := AlertInstance{Fingerprint: "a"}
+
+	if err := newTestNotifier(repo, &now, &sent).Notify(ctx, alert, rule, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(repo.notified) != 1 {
+		t.Fatalf("notification log has %d entries, want 1", len(repo.notified))
+	}
+
+	// A restarted notifier does not notify again within the repeat interval
+	now = now.Add(time.Minute)
+	restarted := newTestNotifier(repo, &now, &sent)
+	if err := restarted.Notify(ctx, alert, rule, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications after restart, want 1", len(sent))
+	}
+
+	// Resolving clears the log, so the next firing is notified
+	restarted.ClearLastNotified(ctx, alert.Fingerprint)
+	if len(repo.notified) != 0 {
+		t.Errorf("notification log has %d entries after resolve, want 0", len(repo.notified))
+	}
+	if err := restarted.Notify(ctx, alert, rule, EventFired); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(sent) != 2 {
+		t.Errorf("sent %d notifications after the alert fired again, want 2", len(sent))
+	}
+}
@@ -0,0 +1,305 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// MetricsProvider returns the current values of a metric.
+type MetricsProvider interface {
+	// Query returns one value per series of the named metric whose labels
+	// include all of the given labels.
+	Query(ctx context.Context, metricName string, labels map[string]string) ([]MetricValue, error)
+}
+
+// InternalProvider reads metrics from an in-process Prometheus registry,
+// so rules can be evaluated without a Prometheus server. Counters, gauges
+// and untyped metrics are read by name; histograms and summaries expose
+// their name_sum and name_count series.
+//
+// The registry only holds current values, so the provider keeps the samples
+// read by QueryOverTime for as long as the longest window asked for.
+// Samples are taken once per evaluation, so windows should span several
+// evaluation intervals.
+type InternalProvider struct {
+	gatherer prometheus.Gatherer
+	now      func() time.Time
+
+	mu      sync.Mutex
+	history map[string]*seriesHistory
+}
+
+// sampleResolution is the least time between two kept samples of a series.
+const sampleResolution = time.Second
+
+// seriesHistory is the samples kept of one series.
+type seriesHistory struct {
+	samples []MetricValue
+	retain  time.Duration
+}
+
+// NewInternalProvider creates a provider reading from gatherer, or from the
+// default registry when gatherer is nil.
+func NewInternalProvider(gatherer prometheus.Gatherer) *InternalProvider {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	return &InternalProvider{gatherer: gatherer, now: time.Now, history: make(map[string]*seriesHistory)}
+}
+
+// Query gathers the registry and returns the matching series.
+func (p *InternalProvider) Query(_ context.Context, metricName string, labels map[string]string) ([]MetricValue, error) {
+	families, err := p.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	now := p.now()
+	var values []MetricValue
+	for _, family := range families {
+		name := family.GetName()
+		for _, m := range family.GetMetric() {
+			value, ok := metricValue(family.GetType(), m, name, metricName)
+			if !ok {
+				continue
+			}
+			seriesLabels := make(map[string]string, len(m.GetLabel()))
+			for _, pair := range m.GetLabel() {
+				seriesLabels[pair.GetName()] = pair.GetValue()
+			}
+			if !matchesLabels(seriesLabels, labels) {
+				continue
+			}
+			values = append(values, MetricValue{Labels: seriesLabels, Value: value, Time: now})
+		}
+	}
+	return values, nil
+}
+
+// QueryOverTime samples the matching series and applies function to the
+// samples kept of each over window. Series with too few samples, such as
+// rate over a single sample, are left out.
+func (p *InternalProvider) QueryOverTime(ctx context.Context, function, metricName string, labels map[string]string, window time.Duration) ([]MetricValue, error) {
+	if function != FuncRate && function != FuncAvgOverTime {
+		return nil, fmt.Errorf("unknown function %q", function)
+	}
+
+	current, err := p.Query(ctx, metricName, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var values []MetricValue
+	for _, sample := range current {
+		key := seriesKey(metricName, sample.Labels)
+		h, ok := p.history[key]
+		if !ok {
+			h = &seriesHistory{}
+			p.history[key] = h
+		}
+		h.retain = max(h.retain, window)
+
+		// Rules reading the series in the same evaluation share a sample
+		if n := len(h.samples); n > 0 && sample.Time.Sub(h.samples[n-1].Time) < sampleResolution {
+			h.samples[n-1] = sample
+		} else {
+			h.samples = append(h.samples, sample)
+		}
+
+		// Drop samples no window needs any more
+		keep := 0
+		for keep < len(h.samples) && sample.Time.Sub(h.samples[keep].Time) > h.retain {
+			keep++
+		}
+		h.samples = h.samples[keep:]
+
+		var inWindow []MetricValue
+		for _, s := range h.samples {
+			if sample.Time.Sub(s.Time) <= window {
+				inWindow = append(inWindow, s)
+			}
+		}
+
+		value, ok := overTime(function, inWindow)
+		if !ok {
+			continue
+		}
+		values = append(values, MetricValue{Labels: sample.Labels, Value: value, Time: sample.Time})
+	}
+	return values, nil
+}
+
+// overTime applies a range function to samples, oldest first.
+func overTime(function string, samples []MetricValue) (float64, bool) {
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	if function == FuncAvgOverTime {
+		var sum float64
+		for _, s := range samples {
+			sum += s.Value
+		}
+		return sum / float64(len(samples)), true
+	}
+
+	// rate: the increase per second, treating a drop as a counter reset
+	elapsed := samples[len(samples)-1].Time.Sub(samples[0].Time).Seconds()
+	if len(samples) < 2 || elapsed <= 0 {
+		return 0, false
+	}
+	var increase float64
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Value < samples[i-1].Value {
+			increase += samples[i].Value
+		} else {
+			increase += samples[i].Value - samples[i-1].Value
+		}
+	}
+	return increase / elapsed, true
+}
+
+// seriesKey identifies a series by its metric name and labels.
+func seriesKey(metricName string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return metricName + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// metricValue returns the value of series m of a family called name when
+// the family provides metricName.
+func metricValue(metricType dto.MetricType, m *dto.Metric, name, metricName string) (float64, bool) {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue(), name == metricName
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue(), name == metricName
+	case dto.MetricType_UNTYPED:
+		return m.GetUntyped().GetValue(), name == metricName
+	case dto.MetricType_HISTOGRAM:
+		switch metricName {
+		case name + "_sum":
+			return m.GetHistogram().GetSampleSum(), true
+		case name + "_count":
+			return float64(m.GetHistogram().GetSampleCount()), true
+		}
+	case dto.MetricType_SUMMARY:
+		switch metricName {
+		case name + "_sum":
+			return m.GetSummary().GetSampleSum(), true
+		case name + "_count":
+			return float64(m.GetSummary().GetSampleCount()), true
+		}
+	}
+	return math.NaN(), false
+}
+
+// matchesLabels reports whether series has every label of selector.
+func matchesLabels(series, selector map[string]string) bool {
+	for k, v := range selector {
+		if series[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// EndpointGatherer gathers metrics from the /metrics endpoints of other
+// processes. The alert manager runs in the API, while buffer and replication
+// lag metrics live in the CDC worker's registry; listing the worker's
+// endpoint lets internal rules read them without a Prometheus server.
+type EndpointGatherer struct {
+	endpoints  []string
+	httpClient *http.Client
+}
+
+// NewEndpointGatherer creates a gatherer scraping the given /metrics URLs.
+func NewEndpointGatherer(endpoints []string) *EndpointGatherer {
+	return &EndpointGatherer{
+		endpoints:  endpoints,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Gather scrapes every endpoint. Endpoints that fail are reported in the
+// returned error while the families of the others are still returned.
+func (g *EndpointGatherer) Gather() ([]*dto.MetricFamily, error) {
+	byName := make(map[string]*dto.MetricFamily)
+	var errs []error
+	for _, endpoint := range g.endpoints {
+		families, err := g.scrape(endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			continue
+		}
+		for name, family := range families {
+			if existing, ok := byName[name]; ok && existing.GetType() == family.GetType() {
+				existing.Metric = append(existing.Metric, family.Metric...)
+				continue
+			}
+			byName[name] = family
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*dto.MetricFamily, 0, len(names))
+	for _, name := range names {
+		result = append(result, byName[name])
+	}
+	return result, errors.Join(errs...)
+}
+
+// scrape reads the text exposition format from a single endpoint.
+func (g *EndpointGatherer) scrape(endpoint string) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metrics endpoint returned status %d", resp.StatusCode)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return families, nil
+}
+
+// Ensure InternalProvider implements RangeProvider.
+var _ RangeProvider = (*InternalProvider)(nil)
+
+// Ensure EndpointGatherer implements prometheus.Gatherer.
+var _ prometheus.Gatherer = (*EndpointGatherer)(nil)
@@ -0,0 +1,189 @@
+package alerting
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/janovincze/philotes/internal/config"
+)
+
+func TestInternalProvider_Query(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	unprocessed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "philotes_buffer_unprocessed_events",
+	}, []string{"source"})
+	batches := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "philotes_buffer_batch_duration_seconds",
+	}, []string{"source"})
+	reg.MustRegister(unprocessed, batches)
+
+	unprocessed.WithLabelValues("db1").Set(120)
+	unprocessed.WithLabelValues("db2").Set(5)
+	batches.WithLabelValues("db1").Observe(2)
+	batches.WithLabelValues("db1").Observe(3)
+
+	provider := NewInternalProvider(reg)
+	ctx := context.Background()
+
+	values, err := provider.Query(ctx, "philotes_buffer_unprocessed_events", nil)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("Query() returned %d series, want 2", len(values))
+	}
+
+	values, err = provider.Query(ctx, "philotes_buffer_unprocessed_events", map[string]string{"source": "db1"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(values) != 1 || values[0].Value != 120 || values[0].Labels["source"] != "db1" {
+		t.Errorf("Query(source=db1) = %+v, want the db1 series at 120", values)
+	}
+
+	values, err = provider.Query(ctx, "philotes_buffer_batch_duration_seconds_count", nil)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(values) != 1 || values[0].Value != 2 {
+		t.Errorf("Query(_count) = %+v, want a count of 2", values)
+	}
+
+	values, err = provider.Query(ctx, "philotes_cdc_replication_lag_bytes", nil)
+	if err != nil || len(values) != 0 {
+		t.Errorf("Query(unknown) = %+v, %v, want no series", values, err)
+	}
+}
+
+func TestEndpointGatherer_ReadsRemoteProcess(t *testing.T) {
+	// The worker's registry, exposed on its /metrics endpoint
+	workerReg := prometheus.NewRegistry()
+	unprocessed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "philotes_buffer_unprocessed_events",
+	}, []string{"source"})
+	workerReg.MustRegister(unprocessed)
+	unprocessed.WithLabelValues("db1").Set(42)
+
+	server := httptest.NewServer(promhttp.HandlerFor(workerReg, promhttp.HandlerOpts{}))
+	defer server.Close()
+
+	// The API's own registry, which knows nothing about buffer metrics
+	apiReg := prometheus.NewRegistry()
+	provider := NewInternalProvider(prometheus.Gatherers{apiReg, NewEndpointGatherer([]string{server.URL})})
+
+	values, err := provider.Query(context.Background(), "philotes_buffer_unprocessed_events", map[string]string{"source": "db1"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(values) != 1 || values[0].Value != 42 {
+		t.Errorf("Query() = %+v, want the worker's series at 42", values)
+	}
+}
+
+func TestEndpointGatherer_UnreachableEndpoint(t *testing.T) {
+	server := httptest.NewServer(nil)
+	server.Close()
+
+	families, err := NewEndpointGatherer([]string{server.URL}).Gather()
+	if err == nil {
+		t.Error("Gather() error = nil, want the scrape failure")
+	}
+	if len(families) != 0 {
+		t.Errorf("Gather() returned %d families, want none", len(families))
+	}
+}
+
+func TestManager_InternalSourceThresholdAndDuration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	lag := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "philotes_cdc_replication_lag_bytes",
+	}, []string{"source"})
+	reg.MustRegister(lag)
+
+	repo := &mockRepository{
+		rules: []AlertRule{
+			{
+				ID:              uuid.New(),
+				Name:            "replication-lag",
+				MetricName:      "philotes_cdc_replication_lag_bytes",
+				Operator:        OpGreaterThan,
+				Threshold:       1000,
+				DurationSeconds: 60,
+				Enabled:         true,
+			},
+			{
+				ID:         uuid.New(),
+				Name:       "needs-prometheus",
+				MetricName: "philotes_cdc_replication_lag_bytes",
+				Operator:   OpGreaterThan,
+				Threshold:  0,
+				Source:     MetricSourcePrometheus,
+				Enabled:    true,
+			},
+		},
+	}
+
+	// No Prometheus URL: rules without a source read the internal registry
+	m, err := NewManager(repo, config.AlertingConfig{EvaluationInterval: 10 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.SetInternalProvider(NewInternalProvider(reg))
+	ctx := context.Background()
+
+	// Below the threshold nothing is pending
+	lag.WithLabelValues("db1").Set(10)
+	if err := m.EvaluateNow(ctx); err != nil {
+		t.Fatalf("EvaluateNow() error = %v", err)
+	}
+	if len(m.GetPendingAlerts()) != 0 {
+		t.Fatalf("pending alerts = %v, want none below the threshold", m.GetPendingAlerts())
+	}
+
+	// Above the threshold the alert is pending until the duration elapses
+	lag.WithLabelValues("db1").Set(5000)
+	if err := m.EvaluateNow(ctx); err != nil {
+		t.Fatalf("EvaluateNow() error = %v", err)
+	}
+	if len(m.GetPendingAlerts()) != 1 || len(repo.instances) != 0 {
+		t.Fatalf("pending = %d, instances = %d, want 1 pending and none fired", len(m.GetPendingAlerts()), len(repo.instances))
+	}
+
+	m.mu.Lock()
+	for fingerprint := range m.pendingAlerts {
+		m.pendingAlerts[fingerprint] = time.Now().Add(-2 * time.Minute)
+	}
+	m.mu.Unlock()
+
+	if err := m.EvaluateNow(ctx); err != nil {
+		t.Fatalf("EvaluateNow() error = %v", err)
+	}
+	if len(repo.instances) != 1 || repo.instances[0].Status != StatusFiring {
+		t.Fatalf("instances = %+v, want one firing alert", repo.instances)
+	}
+	if repo.instances[0].Labels["source"] != "db1" || *repo.instances[0].CurrentValue != 5000 {
+		t.Errorf("instance = %+v, want the db1 series at 5000", repo.instances[0])
+	}
+
+	// Back below the threshold the alert resolves
+	lag.WithLabelValues("db1").Set(10)
+	if err := m.EvaluateNow(ctx); err != nil {
+		t.Fatalf("EvaluateNow() error = %v", err)
+	}
+	if repo.instances[0].Status != StatusResolved {
+		t.Errorf("instance status = %s, want resolved", repo.instances[0].Status)
+	}
+
+	// The rule pinned to Prometheus never fired without a Prometheus URL
+	for _, instance := range repo.instances {
+		if instance.RuleID == repo.rules[1].ID {
+			t.Errorf("prometheus rule fired without a prometheus URL: %+v", instance)
+		}
+	}
+}
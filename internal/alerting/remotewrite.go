@@ -0,0 +1,248 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ErrInvalidRemoteWrite is returned for remote-write payloads that do not decode.
+var ErrInvalidRemoteWrite = errors.New("invalid remote-write payload")
+
+// DefaultStaleness is how old the latest sample of a pushed series may be
+// for rules to still read it, when none is configured.
+const DefaultStaleness = 5 * time.Minute
+
+// MetricSample is a sample of a named series.
+type MetricSample struct {
+	Name string
+	MetricValue
+}
+
+// DecodeRemoteWrite decodes a Prometheus remote-write request: a
+// snappy-compressed protobuf WriteRequest. The __name__ label becomes the
+// sample name; series without one and stale markers are left out.
+func DecodeRemoteWrite(body []byte) ([]MetricSample, error) {
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+
+	var samples []MetricSample
+	err = protoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		// WriteRequest.timeseries = 1
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		series, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		samples = append(samples, series...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRemoteWrite, err)
+	}
+	return samples, nil
+}
+
+// decodeTimeSeries decodes a TimeSeries message into its samples.
+func decodeTimeSeries(data []byte) ([]MetricSample, error) {
+	labels := make(map[string]string)
+	var values []MetricValue
+	err := protoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1: // labels
+			name, v, err := decodeLabel(value)
+			if err != nil {
+				return err
+			}
+			labels[name] = v
+		case 2: // samples
+			sample, err := decodeSample(value)
+			if err != nil {
+				return err
+			}
+			values = append(values, sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name := labels["__name__"]
+	if name == "" {
+		return nil, nil
+	}
+	delete(labels, "__name__")
+
+	samples := make([]MetricSample, 0, len(values))
+	for _, v := range values {
+		if math.IsNaN(v.Value) {
+			continue
+		}
+		v.Labels = labels
+		samples = append(samples, MetricSample{Name: name, MetricValue: v})
+	}
+	return samples, nil
+}
+
+// decodeLabel decodes a Label message.
+func decodeLabel(data []byte) (string, string, error) {
+	var name, value string
+	err := protoFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			value = string(v)
+		}
+		return nil
+	})
+	return name, value, err
+}
+
+// decodeSample decodes a Sample message: a double value and a timestamp in
+// milliseconds.
+func decodeSample(data []byte) (MetricValue, error) {
+	var sample MetricValue
+	err := protoFields(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			bits, _ := protowire.ConsumeFixed64(v)
+			sample.Value = math.Float64frombits(bits)
+		case num == 2 && typ == protowire.VarintType:
+			ms, _ := protowire.ConsumeVarint(v)
+			sample.Time = time.UnixMilli(int64(ms))
+		}
+		return nil
+	})
+	return sample, err
+}
+
+// protoFields calls fn with each field of a protobuf message. Length-delimited
+// values are passed without their length prefix, others as encoded.
+func protoFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		value := data[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// SampleStore keeps samples received over remote write.
+type SampleStore interface {
+	// ListSamples returns the samples of the named metric taken at or after
+	// since whose labels include all of the given labels, oldest first.
+	ListSamples(ctx context.Context, metricName string, labels map[string]string, since time.Time) ([]MetricValue, error)
+}
+
+// RemoteWriteProvider reads metrics pushed by workers over Prometheus remote
+// write, for workers the API cannot scrape. Query returns the latest sample
+// of each series, unless it is older than the staleness period.
+type RemoteWriteProvider struct {
+	store     SampleStore
+	staleness time.Duration
+	now       func() time.Time
+}
+
+// NewRemoteWriteProvider creates a provider reading samples from store.
+func NewRemoteWriteProvider(store SampleStore, staleness time.Duration) *RemoteWriteProvider {
+	if staleness <= 0 {
+		staleness = DefaultStaleness
+	}
+	return &RemoteWriteProvider{store: store, staleness: staleness, now: time.Now}
+}
+
+// Query returns the latest sample of each matching series.
+func (p *RemoteWriteProvider) Query(ctx context.Context, metricName string, labels map[string]string) ([]MetricValue, error) {
+	samples, err := p.store.ListSamples(ctx, metricName, labels, p.now().Add(-p.staleness))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+
+	series := groupSeries(metricName, samples)
+	values := make([]MetricValue, 0, len(series))
+	for _, s := range series {
+		values = append(values, s[len(s)-1])
+	}
+	return values, nil
+}
+
+// QueryOverTime applies function to the samples of each matching series
+// over window.
+func (p *RemoteWriteProvider) QueryOverTime(ctx context.Context, function, metricName string, labels map[string]string, window time.Duration) ([]MetricValue, error) {
+	if function != FuncRate && function != FuncAvgOverTime {
+		return nil, fmt.Errorf("unknown function %q", function)
+	}
+
+	samples, err := p.store.ListSamples(ctx, metricName, labels, p.now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+
+	var values []MetricValue
+	for _, s := range groupSeries(metricName, samples) {
+		value, ok := overTime(function, s)
+		if !ok {
+			continue
+		}
+		last := s[len(s)-1]
+		values = append(values, MetricValue{Labels: last.Labels, Value: value, Time: last.Time})
+	}
+	return values, nil
+}
+
+// groupSeries splits samples, oldest first, by series, ordered by series key.
+func groupSeries(metricName string, samples []MetricValue) [][]MetricValue {
+	byKey := make(map[string][]MetricValue)
+	for _, s := range samples {
+		key := seriesKey(metricName, s.Labels)
+		byKey[key] = append(byKey[key], s)
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	series := make([][]MetricValue, 0, len(keys))
+	for _, key := range keys {
+		series = append(series, byKey[key])
+	}
+	return series
+}
+
+// Ensure RemoteWriteProvider implements RangeProvider.
+var _ RangeProvider = (*RemoteWriteProvider)(nil)
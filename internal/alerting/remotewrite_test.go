@@ -0,0 +1,123 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// encodeWriteRequest builds a snappy-compressed WriteRequest with one series
+// per entry of series, keyed by its labels.
+func encodeWriteRequest(series []map[string]string, samples [][]MetricValue) []byte {
+	var req []byte
+	for i, labels := range series {
+		var ts []byte
+		for name, value := range labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		for _, s := range samples[i] {
+			var sample []byte
+			sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+			sample = protowire.AppendFixed64(sample, math.Float64bits(s.Value))
+			sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+			sample = protowire.AppendVarint(sample, uint64(s.Time.UnixMilli()))
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sample)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return snappy.Encode(nil, req)
+}
+
+func TestDecodeRemoteWrite(t *testing.T) {
+	at := time.UnixMilli(1_700_000_000_000)
+	body := encodeWriteRequest(
+		[]map[string]string{
+			{"__name__": "philotes_cdc_lag_seconds", "source": "orders"},
+			{"job": "no-name"},
+		},
+		[][]MetricValue{
+			{{Value: 42, Time: at}, {Value: math.Float64frombits(0x7ff0000000000002), Time: at.Add(time.Second)}},
+			{{Value: 1, Time: at}},
+		},
+	)
+
+	samples, err := DecodeRemoteWrite(body)
+	if err != nil {
+		t.Fatalf("DecodeRemoteWrite() error = %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("DecodeRemoteWrite() = %+v, want one sample", samples)
+	}
+	s := samples[0]
+	if s.Name != "philotes_cdc_lag_seconds" || s.Value != 42 || !s.Time.Equal(at) {
+		t.Errorf("sample = %+v, want philotes_cdc_lag_seconds 42 at %v", s, at)
+	}
+	if len(s.Labels) != 1 || s.Labels["source"] != "orders" {
+		t.Errorf("labels = %v, want only source=orders", s.Labels)
+	}
+
+	if _, err := DecodeRemoteWrite([]byte("not snappy")); !errors.Is(err, ErrInvalidRemoteWrite) {
+		t.Errorf("DecodeRemoteWrite(garbage) error = %v, want ErrInvalidRemoteWrite", err)
+	}
+	if _, err := DecodeRemoteWrite(snappy.Encode(nil, []byte{0x0a, 0x05, 0x01})); !errors.Is(err, ErrInvalidRemoteWrite) {
+		t.Errorf("DecodeRemoteWrite(truncated) error = %v, want ErrInvalidRemoteWrite", err)
+	}
+}
+
+// memorySampleStore keeps samples in memory, oldest first.
+type memorySampleStore map[string][]MetricValue
+
+func (m memorySampleStore) ListSamples(_ context.Context, metricName string, labels map[string]string, since time.Time) ([]MetricValue, error) {
+	var values []MetricValue
+	for _, v := range m[metricName] {
+		if !v.Time.Before(since) && matchesLabels(v.Labels, labels) {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func TestRemoteWriteProvider(t *testing.T) {
+	now := time.Now()
+	orders := map[string]string{"source": "orders"}
+	users := map[string]string{"source": "users"}
+	store := memorySampleStore{
+		"philotes_buffer_dlq_total": {
+			{Labels: users, Value: 7, Time: now.Add(-time.Hour)},
+			{Labels: orders, Value: 10, Time: now.Add(-2 * time.Minute)},
+			{Labels: orders, Value: 70, Time: now.Add(-time.Minute)},
+		},
+	}
+	provider := NewRemoteWriteProvider(store, 5*time.Minute)
+	provider.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// The users series is stale
+	values, err := provider.Query(ctx, "philotes_buffer_dlq_total", nil)
+	if err != nil || len(values) != 1 || values[0].Value != 70 {
+		t.Fatalf("Query() = %+v, %v, want the latest orders sample", values, err)
+	}
+
+	values, err = provider.QueryOverTime(ctx, FuncRate, "philotes_buffer_dlq_total", orders, 5*time.Minute)
+	if err != nil || len(values) != 1 || values[0].Value != 1 {
+		t.Fatalf("QueryOverTime(rate) = %+v, %v, want 1/s", values, err)
+	}
+
+	values, err = provider.QueryOverTime(ctx, FuncAvgOverTime, "philotes_buffer_dlq_total", nil, 2*time.Hour)
+	if err != nil || len(values) != 2 || values[0].Value != 40 || values[1].Value != 7 {
+		t.Errorf("QueryOverTime(avg_over_time) = %+v, %v, want 40 for orders and 7 for users", values, err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package alerting provides the alerting framework for Philotes.
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned for a malformed silence schedule.
+var ErrInvalidSchedule = errors.New("invalid silence schedule")
+
+// weekdayNames maps the accepted weekday names to weekdays.
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// SilenceSchedule makes a silence recur weekly, such as a maintenance window
+// every night from 02:00 to 03:00. Times are wall-clock times in Timezone,
+// so the window keeps its local hours across DST changes; a window starting
+// in the hour skipped when clocks go forward starts once they have. A window
+// whose end is not after its start runs past midnight into the next day, and
+// belongs to the weekday it starts on.
+type SilenceSchedule struct {
+	// Weekdays the window starts on, such as "mon" or "saturday". Empty
+	// means every day.
+	Weekdays []string `json:"weekdays,omitempty"`
+
+	// StartTime and EndTime are "HH:MM" times of day.
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+
+	// Timezone is an IANA time zone name; empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Validate checks the schedule's weekdays, times and time zone.
+func (s *SilenceSchedule) Validate() error {
+	for _, day := range s.Weekdays {
+		if _, ok := weekdayNames[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("%w: unknown weekday %q", ErrInvalidSchedule, day)
+		}
+	}
+	start, err := parseTimeOfDay(s.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := parseTimeOfDay(s.EndTime)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("%w: start_time and end_time are equal", ErrInvalidSchedule)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, s.Timezone)
+	}
+	return nil
+}
+
+// Contains reports whether t falls in a window of the schedule. An invalid
+// schedule contains no time.
+func (s *SilenceSchedule) Contains(t time.Time) bool {
+	start, err := parseTimeOfDay(s.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(s.EndTime)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return false
+	}
+
+	// t can only be in the window starting on its own day or, for windows
+	// past midnight, the one starting the day before
+	local := t.In(loc)
+	for _, offset := range []int{0, -1} {
+		year, month, day := local.AddDate(0, 0, offset).Date()
+		if !s.onWeekday(time.Date(year, month, day, 12, 0, 0, 0, loc).Weekday()) {
+			continue
+		}
+		from := time.Date(year, month, day, 0, int(start/time.Minute), 0, 0, loc)
+		endDay := day
+		if end <= start {
+			endDay++
+		}
+		until := time.Date(year, month, endDay, 0, int(end/time.Minute), 0, 0, loc)
+		// A window in the hour skipped when clocks go forward starts once
+		// they have, and keeps its length
+		if !until.After(from) {
+			until = from.Add((end - start + 24*time.Hour) % (24 * time.Hour))
+		}
+		if !t.Before(from) && t.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// onWeekday reports whether windows start on day.
+func (s *SilenceSchedule) onWeekday(day time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, name := range s.Weekdays {
+		if weekdayNames[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeOfDay parses an "HH:MM" time of day into the time since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time of day %q must be HH:MM", ErrInvalidSchedule, s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,107 @@
+package alerting
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSilenceSchedule_Validate(t *testing.T) {
+	valid := []SilenceSchedule{
+		{StartTime: "02:00", EndTime: "03:00"},
+		{Weekdays: []string{"Mon", "friday"}, StartTime: "22:00", EndTime: "02:00", Timezone: "Europe/Budapest"},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", s, err)
+		}
+	}
+
+	invalid := []SilenceSchedule{
+		{StartTime: "2am", EndTime: "03:00"},
+		{StartTime: "02:00", EndTime: "24:00"},
+		{StartTime: "02:00", EndTime: "02:00"},
+		{Weekdays: []string{"someday"}, StartTime: "02:00", EndTime: "03:00"},
+		{StartTime: "02:00", EndTime: "03:00", Timezone: "Mars/Olympus"},
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidSchedule", s, err)
+		}
+	}
+}
+
+func TestSilenceSchedule_Contains(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	nightly := SilenceSchedule{StartTime: "02:00", EndTime: "03:00", Timezone: "Europe/Budapest"}
+	overnight := SilenceSchedule{Weekdays: []string{"fri"}, StartTime: "22:00", EndTime: "02:00", Timezone: "Europe/Budapest"}
+
+	tests := []struct {
+		name     string
+		schedule SilenceSchedule
+		at       time.Time
+		want     bool
+	}{
+		{"inside the window", nightly, time.Date(2026, 6, 10, 2, 30, 0, 0, budapest), true},
+		{"at the start", nightly, time.Date(2026, 6, 10, 2, 0, 0, 0, budapest), true},
+		{"at the end", nightly, time.Date(2026, 6, 10, 3, 0, 0, 0, budapest), false},
+		{"outside the window", nightly, time.Date(2026, 6, 10, 12, 0, 0, 0, budapest), false},
+		{"local hours, not UTC", nightly, time.Date(2026, 6, 10, 2, 30, 0, 0, time.UTC), false},
+		{"UTC by default", SilenceSchedule{StartTime: "02:00", EndTime: "03:00"}, time.Date(2026, 6, 10, 2, 30, 0, 0, time.UTC), true},
+
+		// Summer and winter time keep the same local hours
+		{"winter time", nightly, time.Date(2026, 1, 10, 1, 30, 0, 0, time.UTC), true},
+		{"summer time", nightly, time.Date(2026, 7, 10, 0, 30, 0, 0, time.UTC), true},
+		{"summer time, winter offset", nightly, time.Date(2026, 7, 10, 1, 30, 0, 0, time.UTC), false},
+
+		// On 2026-03-29 clocks skip from 02:00 to 03:00: the window runs 03:00-04:00
+		{"skipped hour, after the jump", nightly, time.Date(2026, 3, 29, 3, 30, 0, 0, budapest), true},
+		{"skipped hour, before the jump", nightly, time.Date(2026, 3, 29, 0, 59, 0, 0, time.UTC), false},
+		{"skipped hour, window over", nightly, time.Date(2026, 3, 29, 4, 0, 0, 0, budapest), false},
+
+		// On 2026-10-25 clocks go back from 03:00 to 02:00
+		{"repeated hour", nightly, time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC), true},
+		{"after the repeated hour", nightly, time.Date(2026, 10, 25, 2, 30, 0, 0, time.UTC), false},
+
+		// The overnight window belongs to Friday, the day it starts on
+		{"overnight, friday evening", overnight, time.Date(2026, 6, 12, 23, 0, 0, 0, budapest), true},
+		{"overnight, saturday morning", overnight, time.Date(2026, 6, 13, 1, 0, 0, 0, budapest), true},
+		{"overnight, friday morning", overnight, time.Date(2026, 6, 12, 1, 0, 0, 0, budapest), false},
+		{"overnight, saturday evening", overnight, time.Date(2026, 6, 13, 23, 0, 0, 0, budapest), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Contains(tt.at); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertSilence_ActiveAt_Recurring(t *testing.T) {
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	silence := AlertSilence{
+		StartsAt: start,
+		EndsAt:   start.AddDate(0, 1, 0),
+		Schedule: &SilenceSchedule{StartTime: "02:00", EndTime: "03:00"},
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 6, 15, 2, 30, 0, 0, time.UTC), true},
+		{time.Date(2026, 6, 15, 4, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 5, 31, 2, 30, 0, 0, time.UTC), false},
+		{time.Date(2026, 7, 2, 2, 30, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := silence.ActiveAt(tt.at); got != tt.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
@@ -141,29 +141,62 @@ const (
 	EventFired EventType = "fired"
 	// EventResolved indicates an alert was resolved.
 	EventResolved EventType = "resolved"
+	// EventFlapping indicates an alert changed state too often and further
+	// state-change notifications are being suppressed.
+	EventFlapping EventType = "flapping"
 	// EventAcknowledged indicates an alert was acknowledged.
 	EventAcknowledged EventType = "acknowledged"
 	// EventNotificationSent indicates a notification was sent.
 	EventNotificationSent EventType = "notification_sent"
 	// EventNotificationFailed indicates a notification failed.
 	EventNotificationFailed EventType = "notification_failed"
+	// EventScaling indicates an auto-scaling operation completed. It is only
+	// sent as an audit notification and never recorded for an alert.
+	EventScaling EventType = "scaling"
 )
 
+// MetricSource selects where a rule's metric is read from.
+type MetricSource string
+
+const (
+	// MetricSourcePrometheus queries the configured Prometheus server.
+	MetricSourcePrometheus MetricSource = "prometheus"
+	// MetricSourceInternal reads the in-process metrics registry.
+	MetricSourceInternal MetricSource = "internal"
+	// MetricSourceRemoteWrite reads metrics pushed over Prometheus remote write.
+	MetricSourceRemoteWrite MetricSource = "remote_write"
+)
+
+// IsValid checks if the metric source is valid. The empty source is valid
+// and means the manager's default.
+func (s MetricSource) IsValid() bool {
+	switch s {
+	case "", MetricSourcePrometheus, MetricSourceInternal, MetricSourceRemoteWrite:
+		return true
+	default:
+		return false
+	}
+}
+
 // AlertRule represents an alert rule definition.
 type AlertRule struct {
-	ID              uuid.UUID         `json:"id"`
-	Name            string            `json:"name"`
-	Description     string            `json:"description,omitempty"`
-	MetricName      string            `json:"metric_name"`
-	Operator        Operator          `json:"operator"`
-	Threshold       float64           `json:"threshold"`
-	DurationSeconds int               `json:"duration_seconds"`
-	Severity        AlertSeverity     `json:"severity"`
-	Labels          map[string]string `json:"labels,omitempty"`
-	Annotations     map[string]string `json:"annotations,omitempty"`
-	Enabled         bool              `json:"enabled"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
+	ID                             uuid.UUID         `json:"id"`
+	TenantID                       *uuid.UUID        `json:"tenant_id,omitempty"`
+	Name                           string            `json:"name"`
+	Description                    string            `json:"description,omitempty"`
+	MetricName                     string            `json:"metric_name"`
+	Operator                       Operator          `json:"operator"`
+	Threshold                      float64           `json:"threshold"`
+	Expression                     string            `json:"expression,omitempty"`
+	DurationSeconds                int               `json:"duration_seconds"`
+	MinNotificationIntervalSeconds int               `json:"min_notification_interval_seconds"`
+	Source                         MetricSource      `json:"source,omitempty"`
+	Severity                       AlertSeverity     `json:"severity"`
+	Labels                         map[string]string `json:"labels,omitempty"`
+	Annotations                    map[string]string `json:"annotations,omitempty"`
+	Enabled                        bool              `json:"enabled"`
+	CreatedAt                      time.Time         `json:"created_at"`
+	UpdatedAt                      time.Time         `json:"updated_at"`
 }
 
 // AlertInstance represents an active or resolved alert instance.
@@ -233,12 +266,23 @@ type AlertSilence struct {
 	Comment   string            `json:"comment,omitempty"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
+
+	// Schedule, when set, makes the silence recur: between StartsAt and
+	// EndsAt it is only active within the schedule's windows.
+	Schedule *SilenceSchedule `json:"schedule,omitempty"`
 }
 
 // IsActive checks if the silence is currently active.
 func (s *AlertSilence) IsActive() bool {
-	now := time.Now()
-	return now.After(s.StartsAt) && now.Before(s.EndsAt)
+	return s.ActiveAt(time.Now())
+}
+
+// ActiveAt checks if the silence is active at t.
+func (s *AlertSilence) ActiveAt(t time.Time) bool {
+	if !t.After(s.StartsAt) || !t.Before(s.EndsAt) {
+		return false
+	}
+	return s.Schedule == nil || s.Schedule.Contains(t)
 }
 
 // Matches checks if the given labels match the silence matchers.
@@ -254,6 +298,7 @@ func (s *AlertSilence) Matches(labels map[string]string) bool {
 // NotificationChannel represents a notification channel configuration.
 type NotificationChannel struct {
 	ID        uuid.UUID      `json:"id"`
+	TenantID  *uuid.UUID     `json:"tenant_id,omitempty"`
 	Name      string         `json:"name"`
 	Type      ChannelType    `json:"type"`
 	Config    map[string]any `json:"config"`
@@ -295,4 +340,24 @@ type Notification struct {
 	Channel *NotificationChannel
 	Route   *AlertRoute
 	Event   EventType
+
+	// Grouped holds the other alerts batched into this notification, sent
+	// through the same channel. It is empty for a single alert.
+	Grouped []Notification
+}
+
+// Split returns the notification and its grouped alerts as separate
+// notifications, for channels that track every alert on its own.
+func (n Notification) Split() []Notification {
+	single := n
+	single.Grouped = nil
+	return append([]Notification{single}, n.Grouped...)
+}
+
+// NotificationLogEntry records when an alert was last notified through a
+// route.
+type NotificationLogEntry struct {
+	RouteID     uuid.UUID
+	Fingerprint string
+	NotifiedAt  time.Time
 }
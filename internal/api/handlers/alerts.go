@@ -2,24 +2,29 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/janovincze/philotes/internal/api/middleware"
 	"github.com/janovincze/philotes/internal/api/models"
 	"github.com/janovincze/philotes/internal/api/services"
 )
 
 // AlertHandler handles alerting-related HTTP requests.
 type AlertHandler struct {
-	service *services.AlertService
+	service       *services.AlertService
+	tenantService *services.TenantService
 }
 
-// NewAlertHandler creates a new AlertHandler.
-func NewAlertHandler(service *services.AlertService) *AlertHandler {
-	return &AlertHandler{service: service}
+// NewAlertHandler creates a new AlertHandler. tenantService may be nil when
+// multi-tenancy is not configured.
+func NewAlertHandler(service *services.AlertService, tenantService *services.TenantService) *AlertHandler {
+	return &AlertHandler{service: service, tenantService: tenantService}
 }
 
 // Register adds all alert routes to the router.
@@ -34,6 +39,9 @@ func (h *AlertHandler) Register(rg *gin.RouterGroup) {
 	// Alert Instances
 	rg.GET("/alerts", h.ListAlerts)
 	rg.GET("/alerts/summary", h.GetSummary)
+	rg.GET("/alerts/stream", h.StreamAlerts)
+	rg.POST("/alerts/acknowledge", h.BulkAcknowledge)
+	rg.POST("/alerts/silence", h.BulkSilence)
 	rg.GET("/alerts/:id", h.GetAlert)
 	rg.POST("/alerts/:id/acknowledge", h.AcknowledgeAlert)
 	rg.GET("/alerts/:id/history", h.GetAlertHistory)
@@ -65,6 +73,11 @@ func (h *AlertHandler) Register(rg *gin.RouterGroup) {
 // CreateRule creates a new alert rule.
 // POST /api/v1/alerts/rules
 func (h *AlertHandler) CreateRule(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	var req models.CreateAlertRuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -74,7 +87,7 @@ func (h *AlertHandler) CreateRule(c *gin.Context) {
 		return
 	}
 
-	rule, err := h.service.CreateRule(c.Request.Context(), &req)
+	rule, err := h.service.CreateRule(c.Request.Context(), h.ownerTenant(c, scope), &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -86,6 +99,11 @@ func (h *AlertHandler) CreateRule(c *gin.Context) {
 // GetRule retrieves an alert rule by ID.
 // GET /api/v1/alerts/rules/:id
 func (h *AlertHandler) GetRule(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -95,11 +113,14 @@ func (h *AlertHandler) GetRule(c *gin.Context) {
 		return
 	}
 
-	rule, err := h.service.GetRule(c.Request.Context(), id)
+	rule, err := h.service.GetRule(c.Request.Context(), id, scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
+	if middleware.NotModified(c, rule.ID, rule.UpdatedAt) {
+		return
+	}
 
 	c.JSON(http.StatusOK, models.AlertRuleResponse{Rule: rule})
 }
@@ -107,9 +128,14 @@ func (h *AlertHandler) GetRule(c *gin.Context) {
 // ListRules lists all alert rules.
 // GET /api/v1/alerts/rules
 func (h *AlertHandler) ListRules(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	limit, offset := parsePagination(c)
 
-	response, err := h.service.ListRules(c.Request.Context(), limit, offset)
+	response, err := h.service.ListRules(c.Request.Context(), scope, limit, offset)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -121,6 +147,11 @@ func (h *AlertHandler) ListRules(c *gin.Context) {
 // UpdateRule updates an alert rule.
 // PUT /api/v1/alerts/rules/:id
 func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -139,7 +170,7 @@ func (h *AlertHandler) UpdateRule(c *gin.Context) {
 		return
 	}
 
-	rule, err := h.service.UpdateRule(c.Request.Context(), id, &req)
+	rule, err := h.service.UpdateRule(c.Request.Context(), id, scope, &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -151,6 +182,11 @@ func (h *AlertHandler) UpdateRule(c *gin.Context) {
 // DeleteRule deletes an alert rule.
 // DELETE /api/v1/alerts/rules/:id
 func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -160,7 +196,7 @@ func (h *AlertHandler) DeleteRule(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteRule(c.Request.Context(), id); err != nil {
+	if err := h.service.DeleteRule(c.Request.Context(), id, scope); err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
@@ -173,6 +209,11 @@ func (h *AlertHandler) DeleteRule(c *gin.Context) {
 // GetAlert retrieves an alert instance by ID.
 // GET /api/v1/alerts/:id
 func (h *AlertHandler) GetAlert(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -182,7 +223,7 @@ func (h *AlertHandler) GetAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.service.GetAlert(c.Request.Context(), id)
+	alert, err := h.service.GetAlert(c.Request.Context(), id, scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -194,11 +235,16 @@ func (h *AlertHandler) GetAlert(c *gin.Context) {
 // ListAlerts lists alert instances.
 // GET /api/v1/alerts
 func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	limit, offset := parsePagination(c)
 	status := c.Query("status")
 	severity := c.Query("severity")
 
-	response, err := h.service.ListAlerts(c.Request.Context(), status, severity, limit, offset)
+	response, err := h.service.ListAlerts(c.Request.Context(), scope, status, severity, limit, offset)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -210,6 +256,11 @@ func (h *AlertHandler) ListAlerts(c *gin.Context) {
 // AcknowledgeAlert acknowledges an alert instance.
 // POST /api/v1/alerts/:id/acknowledge
 func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -228,7 +279,7 @@ func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.AcknowledgeAlert(c.Request.Context(), id, &req); err != nil {
+	if err := h.service.AcknowledgeAlert(c.Request.Context(), id, scope, &req); err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
@@ -236,9 +287,77 @@ func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "alert acknowledged"})
 }
 
+// BulkAcknowledge acknowledges several alerts, selected by ID or by labels.
+// POST /api/v1/alerts/acknowledge
+func (h *AlertHandler) BulkAcknowledge(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkAcknowledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	resp, err := h.service.BulkAcknowledgeAlerts(c.Request.Context(), scope, &req,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// BulkSilence silences several alerts with one silence on their common labels.
+// POST /api/v1/alerts/silence
+func (h *AlertHandler) BulkSilence(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	resp, err := h.service.BulkSilenceAlerts(c.Request.Context(), scope, &req,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// auditUserID returns the ID of the authenticated user, if any.
+func auditUserID(c *gin.Context) *uuid.UUID {
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil || authContext.User == nil {
+		return nil
+	}
+	return &authContext.User.ID
+}
+
 // GetAlertHistory retrieves history for an alert instance.
 // GET /api/v1/alerts/:id/history
 func (h *AlertHandler) GetAlertHistory(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -250,7 +369,7 @@ func (h *AlertHandler) GetAlertHistory(c *gin.Context) {
 
 	limit, offset := parsePagination(c)
 
-	response, err := h.service.GetAlertHistory(c.Request.Context(), id, limit, offset)
+	response, err := h.service.GetAlertHistory(c.Request.Context(), id, scope, limit, offset)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -343,6 +462,11 @@ func (h *AlertHandler) DeleteSilence(c *gin.Context) {
 // CreateChannel creates a new notification channel.
 // POST /api/v1/notifications/channels
 func (h *AlertHandler) CreateChannel(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	var req models.CreateChannelRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -352,7 +476,7 @@ func (h *AlertHandler) CreateChannel(c *gin.Context) {
 		return
 	}
 
-	channel, err := h.service.CreateChannel(c.Request.Context(), &req)
+	channel, err := h.service.CreateChannel(c.Request.Context(), h.ownerTenant(c, scope), &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -364,6 +488,11 @@ func (h *AlertHandler) CreateChannel(c *gin.Context) {
 // GetChannel retrieves a notification channel by ID.
 // GET /api/v1/notifications/channels/:id
 func (h *AlertHandler) GetChannel(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -373,11 +502,14 @@ func (h *AlertHandler) GetChannel(c *gin.Context) {
 		return
 	}
 
-	channel, err := h.service.GetChannel(c.Request.Context(), id)
+	channel, err := h.service.GetChannel(c.Request.Context(), id, scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
+	if middleware.NotModified(c, channel.ID, channel.UpdatedAt) {
+		return
+	}
 
 	c.JSON(http.StatusOK, models.ChannelResponse{Channel: channel})
 }
@@ -385,9 +517,14 @@ func (h *AlertHandler) GetChannel(c *gin.Context) {
 // ListChannels lists notification channels.
 // GET /api/v1/notifications/channels
 func (h *AlertHandler) ListChannels(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	limit, offset := parsePagination(c)
 
-	response, err := h.service.ListChannels(c.Request.Context(), limit, offset)
+	response, err := h.service.ListChannels(c.Request.Context(), scope, limit, offset)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -399,6 +536,11 @@ func (h *AlertHandler) ListChannels(c *gin.Context) {
 // UpdateChannel updates a notification channel.
 // PUT /api/v1/notifications/channels/:id
 func (h *AlertHandler) UpdateChannel(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -417,7 +559,7 @@ func (h *AlertHandler) UpdateChannel(c *gin.Context) {
 		return
 	}
 
-	channel, err := h.service.UpdateChannel(c.Request.Context(), id, &req)
+	channel, err := h.service.UpdateChannel(c.Request.Context(), id, scope, &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -429,6 +571,11 @@ func (h *AlertHandler) UpdateChannel(c *gin.Context) {
 // DeleteChannel deletes a notification channel.
 // DELETE /api/v1/notifications/channels/:id
 func (h *AlertHandler) DeleteChannel(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -438,7 +585,7 @@ func (h *AlertHandler) DeleteChannel(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteChannel(c.Request.Context(), id); err != nil {
+	if err := h.service.DeleteChannel(c.Request.Context(), id, scope); err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
@@ -449,6 +596,11 @@ func (h *AlertHandler) DeleteChannel(c *gin.Context) {
 // TestChannel tests a notification channel.
 // POST /api/v1/notifications/channels/:id/test
 func (h *AlertHandler) TestChannel(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -458,7 +610,7 @@ func (h *AlertHandler) TestChannel(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.TestChannel(c.Request.Context(), id)
+	result, err := h.service.TestChannel(c.Request.Context(), id, scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -472,7 +624,12 @@ func (h *AlertHandler) TestChannel(c *gin.Context) {
 // GetSummary retrieves alert statistics summary.
 // GET /api/v1/alerts/summary
 func (h *AlertHandler) GetSummary(c *gin.Context) {
-	summary, err := h.service.GetSummary(c.Request.Context())
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.GetSummary(c.Request.Context(), scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -481,11 +638,69 @@ func (h *AlertHandler) GetSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
+// Stream
+
+// alertStreamKeepAlive is how often an idle alert stream sends a comment, so
+// proxies and clients do not time the connection out.
+const alertStreamKeepAlive = 15 * time.Second
+
+// StreamAlerts streams alert lifecycle events (fired, acknowledged and
+// resolved) as server-sent events until the client disconnects.
+// GET /api/v1/alerts/stream
+func (h *AlertHandler) StreamAlerts(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	events, ok := h.service.SubscribeAlerts(ctx, scope)
+	if !ok {
+		models.RespondWithError(c, models.NewNotImplementedError(c.Request.URL.Path))
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(alertStreamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(string(event.Type), event)
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
 // Alert Routes
 
 // CreateRoute creates a new alert route.
 // POST /api/v1/alerts/routes
 func (h *AlertHandler) CreateRoute(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	var req models.CreateRouteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -495,7 +710,7 @@ func (h *AlertHandler) CreateRoute(c *gin.Context) {
 		return
 	}
 
-	route, err := h.service.CreateRoute(c.Request.Context(), &req)
+	route, err := h.service.CreateRoute(c.Request.Context(), scope, &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -507,6 +722,11 @@ func (h *AlertHandler) CreateRoute(c *gin.Context) {
 // GetRoute retrieves an alert route by ID.
 // GET /api/v1/alerts/routes/:id
 func (h *AlertHandler) GetRoute(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -516,7 +736,7 @@ func (h *AlertHandler) GetRoute(c *gin.Context) {
 		return
 	}
 
-	route, err := h.service.GetRoute(c.Request.Context(), id)
+	route, err := h.service.GetRoute(c.Request.Context(), id, scope)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -528,6 +748,11 @@ func (h *AlertHandler) GetRoute(c *gin.Context) {
 // ListRoutes lists alert routes.
 // GET /api/v1/alerts/routes
 func (h *AlertHandler) ListRoutes(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	limit, offset := parsePagination(c)
 
 	var ruleID *uuid.UUID
@@ -543,7 +768,7 @@ func (h *AlertHandler) ListRoutes(c *gin.Context) {
 		ruleID = &id
 	}
 
-	response, err := h.service.ListRoutes(c.Request.Context(), ruleID, limit, offset)
+	response, err := h.service.ListRoutes(c.Request.Context(), scope, ruleID, limit, offset)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -555,6 +780,11 @@ func (h *AlertHandler) ListRoutes(c *gin.Context) {
 // UpdateRoute updates an alert route.
 // PUT /api/v1/alerts/routes/:id
 func (h *AlertHandler) UpdateRoute(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -573,7 +803,7 @@ func (h *AlertHandler) UpdateRoute(c *gin.Context) {
 		return
 	}
 
-	route, err := h.service.UpdateRoute(c.Request.Context(), id, &req)
+	route, err := h.service.UpdateRoute(c.Request.Context(), id, scope, &req)
 	if err != nil {
 		respondWithServiceError(c, err)
 		return
@@ -585,6 +815,11 @@ func (h *AlertHandler) UpdateRoute(c *gin.Context) {
 // DeleteRoute deletes an alert route.
 // DELETE /api/v1/alerts/routes/:id
 func (h *AlertHandler) DeleteRoute(c *gin.Context) {
+	scope, ok := h.alertScope(c)
+	if !ok {
+		return
+	}
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		models.RespondWithError(c, models.NewBadRequestError(
@@ -594,7 +829,7 @@ func (h *AlertHandler) DeleteRoute(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteRoute(c.Request.Context(), id); err != nil {
+	if err := h.service.DeleteRoute(c.Request.Context(), id, scope); err != nil {
 		respondWithServiceError(c, err)
 		return
 	}
@@ -602,6 +837,89 @@ func (h *AlertHandler) DeleteRoute(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// alertScope resolves the tenant whose alerts the request may access, or nil
+// for every tenant. Global admins, and requests when auth is disabled, see
+// every tenant unless they name one in the tenant header. Other users are
+// limited to the tenant they name and are a member of, or else their only
+// tenant. It writes the error response and
+// returns false when the request must not proceed.
+func (h *AlertHandler) alertScope(c *gin.Context) (*uuid.UUID, bool) {
+	requested, err := requestTenant(c)
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid tenant ID format",
+		))
+		return nil, false
+	}
+
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil {
+		return requested, true
+	}
+	if authContext.TenantID != nil {
+		return authContext.TenantID, true
+	}
+	if authContext.User == nil || authContext.User.Role == models.RoleAdmin || h.tenantService == nil {
+		return requested, true
+	}
+
+	if requested != nil {
+		isMember, memberErr := h.tenantService.IsMember(c.Request.Context(), *requested, authContext.User.ID)
+		if memberErr != nil {
+			respondWithServiceError(c, memberErr)
+			return nil, false
+		}
+		if !isMember {
+			models.RespondWithError(c, models.NewNotTenantMemberError(c.Request.URL.Path))
+			return nil, false
+		}
+		return requested, true
+	}
+
+	tenants, err := h.tenantService.ListByUser(c.Request.Context(), authContext.User.ID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return nil, false
+	}
+	if len(tenants) != 1 {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"a tenant ID must be specified via the X-Tenant-ID header",
+		))
+		return nil, false
+	}
+	return &tenants[0].ID, true
+}
+
+// ownerTenant returns the tenant owning resources created in scope. Callers
+// seeing every tenant create resources in the tenant they name, or global
+// resources when they name none.
+func (h *AlertHandler) ownerTenant(c *gin.Context, scope *uuid.UUID) *uuid.UUID {
+	if scope != nil {
+		return scope
+	}
+	tenantID, _ := requestTenant(c)
+	return tenantID
+}
+
+// requestTenant returns the tenant named by the request, from the tenant
+// middleware or the X-Tenant-ID header, or nil when it names none.
+func requestTenant(c *gin.Context) (*uuid.UUID, error) {
+	if tenantID := tenantScope(c); tenantID != nil {
+		return tenantID, nil
+	}
+	header := c.GetHeader("X-Tenant-ID")
+	if header == "" {
+		return nil, nil
+	}
+	tenantID, err := uuid.Parse(header)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantID, nil
+}
+
 // parsePagination extracts pagination parameters from the query string.
 func parsePagination(c *gin.Context) (limit, offset int) {
 	limit = 100 // default limit
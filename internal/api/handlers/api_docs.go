@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/openapi"
+)
+
+// Tags of documented operations.
+const (
+	tagSystem    = "system"
+	tagTenants   = "tenants"
+	tagAudit     = "audit"
+	tagSources   = "sources"
+	tagPipelines = "pipelines"
+	tagAlerts    = "alerts"
+	tagChannels  = "notification channels"
+	tagOIDC      = "oidc"
+	tagOAuth     = "oauth"
+)
+
+// paginationParams are the query parameters of paginated lists.
+var paginationParams = []openapi.Param{
+	{Name: "limit", Type: "integer", Description: "Page size (default 100, max 1000)"},
+	{Name: "offset", Type: "integer", Description: "Page offset"},
+}
+
+// APIDocs documents the routes of the API for the generated OpenAPI
+// document, keyed by method and route as registered. Request and response
+// schemas are generated from the model types given here, so they follow
+// changes to the models; a route missing here is still listed, without a
+// description.
+func APIDocs() map[string]openapi.Operation {
+	return map[string]openapi.Operation{
+		// System
+		"GET /health": {
+			Summary: "Get the health of the API and its components",
+			Tags:    []string{tagSystem}, Response: models.HealthResponse{}, Public: true,
+		},
+		"GET /health/live": {
+			Summary: "Liveness probe",
+			Tags:    []string{tagSystem}, Response: models.LivenessResponse{}, Public: true,
+		},
+		"GET /health/ready": {
+			Summary: "Readiness probe",
+			Tags:    []string{tagSystem}, Response: models.ReadinessResponse{}, Public: true,
+		},
+		"GET /api/v1/version": {
+			Summary: "Get the version of the API server",
+			Tags:    []string{tagSystem}, Response: models.VersionResponse{}, Public: true,
+		},
+		"GET /api/v1/config": {
+			Summary:     "Get the effective configuration",
+			Description: "Credentials are redacted.",
+			Tags:        []string{tagSystem}, Response: models.ConfigResponse{},
+		},
+		"GET /api/v1/openapi.json": {
+			Summary: "Get this OpenAPI document",
+			Tags:    []string{tagSystem}, Public: true,
+		},
+
+		// Tenants
+		"POST /api/v1/tenants": {
+			Summary: "Create a tenant",
+			Tags:    []string{tagTenants}, Request: models.CreateTenantRequest{}, Response: models.TenantResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/tenants": {
+			Summary: "List the tenants of the caller",
+			Tags:    []string{tagTenants}, Response: models.TenantListResponse{},
+		},
+		"GET /api/v1/tenants/:id": {
+			Summary: "Get a tenant",
+			Tags:    []string{tagTenants}, Response: models.TenantResponse{},
+		},
+		"PUT /api/v1/tenants/:id": {
+			Summary: "Update a tenant",
+			Tags:    []string{tagTenants}, Request: models.UpdateTenantRequest{}, Response: models.TenantResponse{},
+		},
+		"DELETE /api/v1/tenants/:id": {
+			Summary: "Delete a tenant",
+			Tags:    []string{tagTenants},
+		},
+		"GET /api/v1/tenants/:id/members": {
+			Summary: "List the members of a tenant",
+			Tags:    []string{tagTenants}, Response: models.MemberListResponse{},
+		},
+		"POST /api/v1/tenants/:id/members": {
+			Summary: "Add a member to a tenant",
+			Tags:    []string{tagTenants}, Request: models.AddMemberRequest{}, Response: models.MemberResponse{}, Status: http.StatusCreated,
+		},
+		"PUT /api/v1/tenants/:id/members/:user_id": {
+			Summary: "Change the role of a tenant member",
+			Tags:    []string{tagTenants}, Request: models.UpdateMemberRequest{}, Response: models.MemberResponse{},
+		},
+		"DELETE /api/v1/tenants/:id/members/:user_id": {
+			Summary: "Remove a member from a tenant",
+			Tags:    []string{tagTenants},
+		},
+		"GET /api/v1/tenants/:id/roles": {
+			Summary: "List the custom roles of a tenant",
+			Tags:    []string{tagTenants}, Response: models.CustomRoleListResponse{},
+		},
+		"POST /api/v1/tenants/:id/roles": {
+			Summary: "Create a custom role",
+			Tags:    []string{tagTenants}, Request: models.CreateCustomRoleRequest{}, Response: models.CustomRoleResponse{}, Status: http.StatusCreated,
+		},
+		"PUT /api/v1/tenants/:id/roles/:role_id": {
+			Summary: "Update a custom role",
+			Tags:    []string{tagTenants}, Request: models.UpdateCustomRoleRequest{}, Response: models.CustomRoleResponse{},
+		},
+		"DELETE /api/v1/tenants/:id/roles/:role_id": {
+			Summary: "Delete a custom role",
+			Tags:    []string{tagTenants},
+		},
+		"GET /api/v1/tenants/:id/audit/retention": {
+			Summary: "Get the audit log retention of a tenant",
+			Tags:    []string{tagAudit}, Response: models.AuditRetentionResponse{},
+		},
+		"PUT /api/v1/tenants/:id/audit/retention": {
+			Summary: "Set the audit log retention of a tenant",
+			Tags:    []string{tagAudit}, Request: models.UpdateAuditRetentionRequest{}, Response: models.AuditRetentionResponse{},
+		},
+		"GET /api/v1/tenants/:id/audit/holds": {
+			Summary: "List the legal holds of a tenant",
+			Tags:    []string{tagAudit}, Response: models.LegalHoldListResponse{},
+			Query: []openapi.Param{{Name: "active", Type: "boolean", Description: "Only holds that are not released"}},
+		},
+		"POST /api/v1/tenants/:id/audit/holds": {
+			Summary: "Place a legal hold on the audit log of a tenant",
+			Tags:    []string{tagAudit}, Request: models.CreateLegalHoldRequest{}, Response: models.LegalHoldResponse{}, Status: http.StatusCreated,
+		},
+		"POST /api/v1/tenants/:id/audit/holds/:hold_id/release": {
+			Summary: "Release a legal hold",
+			Tags:    []string{tagAudit}, Response: models.LegalHoldResponse{},
+		},
+		"GET /api/v1/audit": {
+			Summary:     "List audit logs",
+			Description: "Global admins see all tenants unless tenant_id is given; tenant admins must give the tenant_id of their tenant.",
+			Tags:        []string{tagAudit}, Response: models.AuditLogListResponse{},
+			Query: append([]openapi.Param{
+				{Name: "tenant_id", Format: "uuid"},
+				{Name: "user_id", Format: "uuid"},
+				{Name: "action", Description: "Action, such as login or DELETE /sources/:id"},
+				{Name: "resource_type", Description: "Resource type, such as sources"},
+				{Name: "resource_id", Format: "uuid"},
+				{Name: "since", Format: "date-time"},
+				{Name: "until", Format: "date-time"},
+			}, paginationParams...),
+		},
+
+		// Sources
+		"POST /api/v1/sources": {
+			Summary: "Create a source database",
+			Tags:    []string{tagSources}, Request: models.CreateSourceRequest{}, Response: models.SourceResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/sources": {
+			Summary: "List source databases",
+			Tags:    []string{tagSources}, Response: models.SourceListResponse{},
+		},
+		"GET /api/v1/sources/:id": {
+			Summary: "Get a source database",
+			Tags:    []string{tagSources}, Response: models.SourceResponse{},
+		},
+		"PUT /api/v1/sources/:id": {
+			Summary: "Update a source database",
+			Tags:    []string{tagSources}, Request: models.UpdateSourceRequest{}, Response: models.SourceResponse{},
+		},
+		"DELETE /api/v1/sources/:id": {
+			Summary: "Delete a source database",
+			Tags:    []string{tagSources},
+		},
+		"POST /api/v1/sources/:id/test": {
+			Summary: "Test the connection to a source database",
+			Tags:    []string{tagSources}, Response: models.ConnectionTestResult{},
+		},
+		"POST /api/v1/sources/:id/validate": {
+			Summary: "Check that a source is ready to replicate the given tables",
+			Tags:    []string{tagSources}, Request: models.ValidateSourceRequest{}, Response: models.SourceValidationResult{},
+		},
+		"GET /api/v1/sources/:id/health": {
+			Summary: "Check that a source database is reachable",
+			Tags:    []string{tagSources}, Response: models.SourceHealth{},
+		},
+		"GET /api/v1/sources/:id/schemas": {
+			Summary: "Discover the schemas of a source database",
+			Tags:    []string{tagSources}, Response: models.SchemaDiscoveryResponse{},
+		},
+		"GET /api/v1/sources/:id/tables": {
+			Summary: "Discover the tables of a source schema, with their columns and CDC readiness",
+			Tags:    []string{tagSources}, Response: models.TableDiscoveryResponse{},
+			Query: []openapi.Param{{Name: "schema", Description: "Schema to list the tables of"}},
+		},
+
+		// Pipelines
+		"POST /api/v1/pipelines": {
+			Summary: "Create a pipeline",
+			Tags:    []string{tagPipelines}, Request: models.CreatePipelineRequest{}, Response: models.PipelineResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/pipelines": {
+			Summary: "List pipelines",
+			Tags:    []string{tagPipelines}, Response: models.PipelineListResponse{},
+		},
+		"GET /api/v1/pipelines/:id": {
+			Summary: "Get a pipeline",
+			Tags:    []string{tagPipelines}, Response: models.PipelineResponse{},
+		},
+		"PUT /api/v1/pipelines/:id": {
+			Summary: "Update a pipeline",
+			Tags:    []string{tagPipelines}, Request: models.UpdatePipelineRequest{}, Response: models.PipelineResponse{},
+		},
+		"DELETE /api/v1/pipelines/:id": {
+			Summary: "Delete a pipeline",
+			Tags:    []string{tagPipelines},
+		},
+		"POST /api/v1/pipelines/:id/start": {
+			Summary: "Start a pipeline",
+			Tags:    []string{tagPipelines}, Response: gin.H{},
+		},
+		"POST /api/v1/pipelines/:id/stop": {
+			Summary: "Stop a pipeline",
+			Tags:    []string{tagPipelines}, Response: gin.H{},
+		},
+		"POST /api/v1/pipelines/:id/pause": {
+			Summary: "Pause a running pipeline, keeping its replication slot",
+			Tags:    []string{tagPipelines}, Response: gin.H{},
+		},
+		"POST /api/v1/pipelines/:id/resume": {
+			Summary: "Resume a paused pipeline",
+			Tags:    []string{tagPipelines}, Response: gin.H{},
+		},
+		"GET /api/v1/pipelines/:id/status": {
+			Summary: "Get the status of a pipeline",
+			Tags:    []string{tagPipelines}, Response: models.PipelineStatusResponse{},
+		},
+		"GET /api/v1/pipelines/:id/buffer": {
+			Summary: "Get the buffer retention and cleanup stats of a pipeline",
+			Tags:    []string{tagPipelines}, Response: models.PipelineBufferStats{},
+		},
+		"POST /api/v1/pipelines/:id/tables": {
+			Summary: "Add a table mapping to a pipeline",
+			Tags:    []string{tagPipelines}, Request: models.AddTableMappingRequest{}, Response: models.TableMapping{}, Status: http.StatusCreated,
+		},
+		"DELETE /api/v1/pipelines/:id/tables/:mappingId": {
+			Summary: "Remove a table mapping from a pipeline",
+			Tags:    []string{tagPipelines},
+		},
+		"GET /api/v1/pipelines/:id/metrics": {
+			Summary: "Get the current metrics of a pipeline",
+			Tags:    []string{tagPipelines}, Response: models.PipelineMetricsResponse{},
+		},
+		"GET /api/v1/pipelines/:id/metrics/history": {
+			Summary: "Get the metrics history of a pipeline",
+			Tags:    []string{tagPipelines}, Response: models.MetricsHistoryResponse{},
+		},
+
+		// Alerts
+		"POST /api/v1/alerts/rules": {
+			Summary: "Create an alert rule",
+			Tags:    []string{tagAlerts}, Request: models.CreateAlertRuleRequest{}, Response: models.AlertRuleResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/alerts/rules": {
+			Summary: "List alert rules",
+			Tags:    []string{tagAlerts}, Response: models.AlertRuleListResponse{}, Query: paginationParams,
+		},
+		"GET /api/v1/alerts/rules/:id": {
+			Summary: "Get an alert rule",
+			Tags:    []string{tagAlerts}, Response: models.AlertRuleResponse{},
+		},
+		"PUT /api/v1/alerts/rules/:id": {
+			Summary: "Update an alert rule",
+			Tags:    []string{tagAlerts}, Request: models.UpdateAlertRuleRequest{}, Response: models.AlertRuleResponse{},
+		},
+		"DELETE /api/v1/alerts/rules/:id": {
+			Summary: "Delete an alert rule",
+			Tags:    []string{tagAlerts},
+		},
+		"GET /api/v1/alerts": {
+			Summary: "List alerts",
+			Tags:    []string{tagAlerts}, Response: models.AlertInstanceListResponse{},
+			Query: append([]openapi.Param{
+				{Name: "status", Description: "firing or resolved"},
+				{Name: "severity", Description: "info, warning or critical"},
+			}, paginationParams...),
+		},
+		"GET /api/v1/alerts/summary": {
+			Summary: "Get a summary of alerts",
+			Tags:    []string{tagAlerts}, Response: models.AlertSummaryResponse{},
+		},
+		"GET /api/v1/alerts/stream": {
+			Summary:     "Stream alert changes",
+			Description: "Server-sent events of alerts firing, resolving and being acknowledged.",
+			Tags:        []string{tagAlerts},
+		},
+		"POST /api/v1/alerts/acknowledge": {
+			Summary: "Acknowledge alerts in bulk",
+			Tags:    []string{tagAlerts}, Request: models.BulkAcknowledgeRequest{}, Response: models.BulkAcknowledgeResponse{},
+		},
+		"POST /api/v1/alerts/silence": {
+			Summary: "Silence alerts in bulk",
+			Tags:    []string{tagAlerts}, Request: models.BulkSilenceRequest{}, Response: models.BulkSilenceResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/alerts/:id": {
+			Summary: "Get an alert",
+			Tags:    []string{tagAlerts}, Response: models.AlertInstanceResponse{},
+		},
+		"POST /api/v1/alerts/:id/acknowledge": {
+			Summary: "Acknowledge an alert",
+			Tags:    []string{tagAlerts}, Request: models.AcknowledgeAlertRequest{}, Response: gin.H{},
+		},
+		"GET /api/v1/alerts/:id/history": {
+			Summary: "Get the history of an alert",
+			Tags:    []string{tagAlerts}, Response: models.AlertHistoryResponse{}, Query: paginationParams,
+		},
+		"POST /api/v1/alerts/silences": {
+			Summary: "Create a silence",
+			Tags:    []string{tagAlerts}, Request: models.CreateSilenceRequest{}, Response: models.SilenceResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/alerts/silences": {
+			Summary: "List silences",
+			Tags:    []string{tagAlerts}, Response: models.SilenceListResponse{},
+			Query: append([]openapi.Param{
+				{Name: "active", Type: "boolean", Description: "Only silences in effect"},
+			}, paginationParams...),
+		},
+		"GET /api/v1/alerts/silences/:id": {
+			Summary: "Get a silence",
+			Tags:    []string{tagAlerts}, Response: models.SilenceResponse{},
+		},
+		"DELETE /api/v1/alerts/silences/:id": {
+			Summary: "Delete a silence",
+			Tags:    []string{tagAlerts},
+		},
+		"POST /api/v1/alerts/routes": {
+			Summary: "Create an alert route",
+			Tags:    []string{tagAlerts}, Request: models.CreateRouteRequest{}, Response: models.RouteResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/alerts/routes": {
+			Summary: "List alert routes",
+			Tags:    []string{tagAlerts}, Response: models.RouteListResponse{},
+			Query: append([]openapi.Param{
+				{Name: "rule_id", Format: "uuid", Description: "Only routes of this rule"},
+			}, paginationParams...),
+		},
+		"GET /api/v1/alerts/routes/:id": {
+			Summary: "Get an alert route",
+			Tags:    []string{tagAlerts}, Response: models.RouteResponse{},
+		},
+		"PUT /api/v1/alerts/routes/:id": {
+			Summary: "Update an alert route",
+			Tags:    []string{tagAlerts}, Request: models.UpdateRouteRequest{}, Response: models.RouteResponse{},
+		},
+		"DELETE /api/v1/alerts/routes/:id": {
+			Summary: "Delete an alert route",
+			Tags:    []string{tagAlerts},
+		},
+		"POST /api/v1/notifications/channels": {
+			Summary: "Create a notification channel",
+			Tags:    []string{tagChannels}, Request: models.CreateChannelRequest{}, Response: models.ChannelResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/notifications/channels": {
+			Summary: "List notification channels",
+			Tags:    []string{tagChannels}, Response: models.ChannelListResponse{}, Query: paginationParams,
+		},
+		"GET /api/v1/notifications/channels/:id": {
+			Summary: "Get a notification channel",
+			Tags:    []string{tagChannels}, Response: models.ChannelResponse{},
+		},
+		"PUT /api/v1/notifications/channels/:id": {
+			Summary: "Update a notification channel",
+			Tags:    []string{tagChannels}, Request: models.UpdateChannelRequest{}, Response: models.ChannelResponse{},
+		},
+		"DELETE /api/v1/notifications/channels/:id": {
+			Summary: "Delete a notification channel",
+			Tags:    []string{tagChannels},
+		},
+		"POST /api/v1/notifications/channels/:id/test": {
+			Summary: "Send a test notification",
+			Tags:    []string{tagChannels}, Response: models.TestChannelResponse{},
+		},
+
+		// OIDC
+		"GET /api/v1/auth/oidc/providers": {
+			Summary: "List the enabled SSO providers",
+			Tags:    []string{tagOIDC}, Response: models.OIDCProvidersResponse{}, Public: true,
+		},
+		"POST /api/v1/auth/oidc/:provider/authorize": {
+			Summary: "Start an SSO login",
+			Tags:    []string{tagOIDC}, Request: models.OIDCAuthorizeRequest{}, Response: models.OIDCAuthorizeResponse{}, Public: true,
+		},
+		"POST /api/v1/auth/oidc/callback": {
+			Summary: "Complete an SSO login",
+			Tags:    []string{tagOIDC}, Request: models.OIDCCallbackRequest{}, Response: models.OIDCCallbackResponse{}, Public: true,
+		},
+		"GET /api/v1/auth/oidc/callback": {
+			Summary: "Complete an SSO login redirected from the identity provider",
+			Tags:    []string{tagOIDC}, Response: models.OIDCCallbackResponse{}, Public: true,
+			Query: []openapi.Param{{Name: "code", Required: true}, {Name: "state", Required: true}, {Name: "error"}},
+		},
+		"POST /api/v1/auth/oidc/:provider/backchannel-logout": {
+			Summary:     "OIDC back-channel logout",
+			Description: "Receives a logout_token form field from the identity provider and ends the sessions it names.",
+			Tags:        []string{tagOIDC}, Public: true, Status: http.StatusOK,
+		},
+		"GET /api/v1/settings/oidc/providers": {
+			Summary: "List SSO providers",
+			Tags:    []string{tagOIDC}, Response: models.OIDCProvidersResponse{},
+		},
+		"POST /api/v1/settings/oidc/providers": {
+			Summary: "Create an SSO provider",
+			Tags:    []string{tagOIDC}, Request: models.CreateOIDCProviderRequest{}, Response: models.OIDCProviderResponse{}, Status: http.StatusCreated,
+		},
+		"GET /api/v1/settings/oidc/providers/:id": {
+			Summary: "Get an SSO provider",
+			Tags:    []string{tagOIDC}, Response: models.OIDCProviderResponse{},
+		},
+		"PUT /api/v1/settings/oidc/providers/:id": {
+			Summary: "Update an SSO provider",
+			Tags:    []string{tagOIDC}, Request: models.UpdateOIDCProviderRequest{}, Response: models.OIDCProviderResponse{},
+		},
+		"DELETE /api/v1/settings/oidc/providers/:id": {
+			Summary: "Delete an SSO provider",
+			Tags:    []string{tagOIDC},
+		},
+		"POST /api/v1/settings/oidc/providers/:id/test": {
+			Summary: "Test the discovery of an SSO provider",
+			Tags:    []string{tagOIDC}, Response: gin.H{},
+		},
+		"POST /api/v1/settings/oidc/providers/:id/scim-token": {
+			Summary:     "Rotate the SCIM token of an SSO provider",
+			Description: "Enables SCIM provisioning for the provider. The token is only returned once.",
+			Tags:        []string{tagOIDC}, Response: models.SCIMTokenResponse{}, Status: http.StatusCreated,
+		},
+		"DELETE /api/v1/settings/oidc/providers/:id/scim-token": {
+			Summary: "Disable SCIM provisioning for an SSO provider",
+			Tags:    []string{tagOIDC},
+		},
+
+		// OAuth
+		"GET /api/v1/installer/oauth/providers": {
+			Summary: "List the cloud providers supporting OAuth",
+			Tags:    []string{tagOAuth}, Response: models.OAuthProvidersResponse{}, Public: true,
+		},
+		"POST /api/v1/installer/oauth/:provider/authorize": {
+			Summary: "Start authorizing a cloud provider",
+			Tags:    []string{tagOAuth}, Request: models.OAuthAuthorizeRequest{}, Response: models.OAuthAuthorizeResponse{}, Public: true,
+		},
+		"GET /api/v1/installer/oauth/:provider/callback": {
+			Summary:     "Complete authorizing a cloud provider",
+			Description: "Redirects to the redirect URI of the authorization with the result, or returns it without one.",
+			Tags:        []string{tagOAuth}, Response: models.OAuthCallbackResponse{}, Public: true,
+			Query: []openapi.Param{{Name: "code"}, {Name: "state"}, {Name: "error"}, {Name: "error_description"}},
+		},
+		"POST /api/v1/installer/credentials/:provider": {
+			Summary: "Store cloud provider credentials",
+			Tags:    []string{tagOAuth}, Request: models.StoreCredentialRequest{}, Response: models.StoreCredentialResponse{}, Status: http.StatusCreated, Public: true,
+		},
+		"GET /api/v1/installer/credentials": {
+			Summary: "List stored cloud provider credentials",
+			Tags:    []string{tagOAuth}, Response: models.CredentialListResponse{},
+		},
+		"DELETE /api/v1/installer/credentials/:provider": {
+			Summary: "Delete stored cloud provider credentials",
+			Tags:    []string{tagOAuth},
+		},
+	}
+}
@@ -189,6 +189,57 @@ func (h *APIKeyHandler) Revoke(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// SetRateLimit sets or removes the rate limit of an API key. Only admins
+// can change rate limits, so key owners cannot raise their own.
+// PUT /api/v1/api-keys/:id/rate-limit
+func (h *APIKeyHandler) SetRateLimit(c *gin.Context) {
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil {
+		models.RespondWithError(c, models.NewUnauthorizedError(
+			c.Request.URL.Path,
+			"Authentication required",
+		))
+		return
+	}
+
+	if authContext.User == nil || authContext.User.Role != models.RoleAdmin {
+		models.RespondWithError(c, models.NewForbiddenError(
+			c.Request.URL.Path,
+			"only admins can set API key rate limits",
+		))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid API key ID format",
+		))
+		return
+	}
+
+	var req models.UpdateAPIKeyRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	ipAddress := middleware.GetClientIP(c)
+	userAgent := middleware.GetUserAgent(c)
+
+	apiKey, err := h.apiKeyService.SetRateLimit(c.Request.Context(), id, &req, authContext.User.ID, ipAddress, userAgent)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIKeyResponse{APIKey: apiKey})
+}
+
 // Register registers routes for the API key handler.
 func (h *APIKeyHandler) Register(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	apiKeys := rg.Group("/api-keys")
@@ -198,6 +249,7 @@ func (h *APIKeyHandler) Register(rg *gin.RouterGroup, authMiddleware gin.Handler
 	apiKeys.GET("/:id", h.Get)
 	apiKeys.DELETE("/:id", h.Delete)
 	apiKeys.POST("/:id/revoke", h.Revoke)
+	apiKeys.PUT("/:id/rate-limit", h.SetRateLimit)
 }
 
 // respondWithError converts service errors to HTTP responses.
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// AuditHandler handles audit log requests.
+type AuditHandler struct {
+	auditService  *services.AuditService
+	tenantService *services.TenantService
+}
+
+// NewAuditHandler creates a new AuditHandler. tenantService may be nil, in
+// which case only global admins can list the audit log.
+func NewAuditHandler(auditService *services.AuditService, tenantService *services.TenantService) *AuditHandler {
+	return &AuditHandler{
+		auditService:  auditService,
+		tenantService: tenantService,
+	}
+}
+
+// List lists audit logs, newest first.
+// @Summary List audit logs
+// @Description Returns audit log entries, newest first: logins, API key changes and every
+// @Description mutating API request. Global admins see all tenants unless tenant_id is given;
+// @Description tenant admins must give the tenant_id of their tenant.
+// @Tags audit
+// @Produce json
+// @Param tenant_id query string false "Tenant ID"
+// @Param user_id query string false "User ID"
+// @Param action query string false "Action, such as login or DELETE /sources/:id"
+// @Param resource_type query string false "Resource type, such as sources"
+// @Param resource_id query string false "Resource ID"
+// @Param since query string false "RFC 3339 timestamp"
+// @Param until query string false "RFC 3339 timestamp"
+// @Param limit query int false "Page size (default 100, max 1000)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} models.AuditLogListResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Router /audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := models.AuditLogFilter{
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+	}
+
+	var ok bool
+	if filter.TenantID, ok = queryUUID(c, "tenant_id"); !ok {
+		return
+	}
+	if filter.UserID, ok = queryUUID(c, "user_id"); !ok {
+		return
+	}
+	if filter.ResourceID, ok = queryUUID(c, "resource_id"); !ok {
+		return
+	}
+	if filter.Since, ok = queryTime(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = queryTime(c, "until"); !ok {
+		return
+	}
+	if filter.TenantID == nil {
+		filter.TenantID = tenantScope(c)
+	}
+
+	if !h.authorize(c, filter.TenantID) {
+		return
+	}
+
+	limit, offset := parsePagination(c)
+	response, err := h.auditService.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes registers the audit log routes.
+func (h *AuditHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	r.GET("/audit", authMiddleware, h.List)
+}
+
+// authorize requires the caller to be a global admin or, for a tenant's
+// audit log, an admin of that tenant. It writes the error response and
+// returns false when the request must not proceed.
+func (h *AuditHandler) authorize(c *gin.Context, tenantID *uuid.UUID) bool {
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil || authContext.User == nil {
+		models.RespondWithError(c, models.NewUnauthorizedError(
+			c.Request.URL.Path,
+			"Authentication required",
+		))
+		return false
+	}
+	if authContext.User.Role == models.RoleAdmin {
+		return true
+	}
+
+	if tenantID == nil || h.tenantService == nil {
+		models.RespondWithError(c, models.NewForbiddenError(
+			c.Request.URL.Path,
+			"listing the audit log of all tenants requires the admin role; give a tenant_id",
+		))
+		return false
+	}
+	role, err := h.tenantService.GetMemberRole(c.Request.Context(), *tenantID, authContext.User.ID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return false
+	}
+	if role != models.TenantRoleAdmin {
+		models.RespondWithError(c, models.NewInsufficientRoleError(c.Request.URL.Path))
+		return false
+	}
+	return true
+}
+
+// queryUUID parses an optional UUID query parameter. It writes the error
+// response and returns false when the parameter is not a UUID.
+func queryUUID(c *gin.Context, name string) (*uuid.UUID, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid "+name+" format",
+		))
+		return nil, false
+	}
+	return &id, true
+}
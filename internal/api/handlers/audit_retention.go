@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// AuditRetentionHandler handles tenant audit retention and legal hold requests.
+type AuditRetentionHandler struct {
+	retentionService *services.AuditRetentionService
+	tenantService    *services.TenantService
+}
+
+// NewAuditRetentionHandler creates a new AuditRetentionHandler.
+func NewAuditRetentionHandler(retentionService *services.AuditRetentionService, tenantService *services.TenantService) *AuditRetentionHandler {
+	return &AuditRetentionHandler{
+		retentionService: retentionService,
+		tenantService:    tenantService,
+	}
+}
+
+// GetRetention returns a tenant's audit retention policy.
+// GET /api/v1/tenants/:id/audit/retention
+func (h *AuditRetentionHandler) GetRetention(c *gin.Context) {
+	tenantID, _, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.retentionService.GetPolicy(c.Request.Context(), tenantID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditRetentionResponse{Policy: policy})
+}
+
+// UpdateRetention sets a tenant's audit retention policy.
+// PUT /api/v1/tenants/:id/audit/retention
+func (h *AuditRetentionHandler) UpdateRetention(c *gin.Context) {
+	tenantID, userID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAuditRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	policy, err := h.retentionService.SetPolicy(c.Request.Context(), tenantID, &req, userID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditRetentionResponse{Policy: policy})
+}
+
+// ListHolds lists a tenant's legal holds.
+// GET /api/v1/tenants/:id/audit/holds
+func (h *AuditRetentionHandler) ListHolds(c *gin.Context) {
+	tenantID, _, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	activeOnly := c.Query("active") == "true"
+	holds, err := h.retentionService.ListHolds(c.Request.Context(), tenantID, activeOnly)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LegalHoldListResponse{
+		Holds:      holds,
+		TotalCount: len(holds),
+	})
+}
+
+// PlaceHold places a legal hold on a tenant's audit logs.
+// POST /api/v1/tenants/:id/audit/holds
+func (h *AuditRetentionHandler) PlaceHold(c *gin.Context) {
+	tenantID, userID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	hold, err := h.retentionService.PlaceHold(c.Request.Context(), tenantID, &req, userID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.LegalHoldResponse{Hold: hold})
+}
+
+// ReleaseHold releases a legal hold.
+// POST /api/v1/tenants/:id/audit/holds/:hold_id/release
+func (h *AuditRetentionHandler) ReleaseHold(c *gin.Context) {
+	tenantID, userID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid legal hold ID format",
+		))
+		return
+	}
+
+	hold, err := h.retentionService.ReleaseHold(c.Request.Context(), tenantID, holdID, userID)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LegalHoldResponse{Hold: hold})
+}
+
+// Register registers routes for the audit retention handler.
+func (h *AuditRetentionHandler) Register(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	audit := rg.Group("/tenants/:id/audit")
+	audit.Use(authMiddleware)
+
+	audit.GET("/retention", h.GetRetention)
+	audit.PUT("/retention", h.UpdateRetention)
+	audit.GET("/holds", h.ListHolds)
+	audit.POST("/holds", h.PlaceHold)
+	audit.POST("/holds/:hold_id/release", h.ReleaseHold)
+}
+
+// authorize resolves the tenant from the path and requires the caller to be a
+// global admin or an admin of that tenant. It writes the error response and
+// returns false when the request must not proceed.
+func (h *AuditRetentionHandler) authorize(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil || authContext.User == nil {
+		models.RespondWithError(c, models.NewUnauthorizedError(
+			c.Request.URL.Path,
+			"Authentication required",
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	tenantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid tenant ID format",
+		))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if authContext.User.Role != models.RoleAdmin {
+		role, roleErr := h.tenantService.GetMemberRole(c.Request.Context(), tenantID, authContext.User.ID)
+		if roleErr != nil {
+			respondWithServiceError(c, roleErr)
+			return uuid.Nil, uuid.Nil, false
+		}
+		if role != models.TenantRoleAdmin {
+			models.RespondWithError(c, models.NewInsufficientRoleError(c.Request.URL.Path))
+			return uuid.Nil, uuid.Nil, false
+		}
+	}
+
+	return tenantID, authContext.User.ID, true
+}
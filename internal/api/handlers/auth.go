@@ -89,6 +89,49 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, models.UserResponse{User: authContext.User})
 }
 
+// Logout revokes the token the current user authenticated with.
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	authContext := middleware.GetAuthContext(c)
+	if authContext == nil {
+		models.RespondWithError(c, models.NewUnauthorizedError(
+			c.Request.URL.Path,
+			"Authentication required",
+		))
+		return
+	}
+	if authContext.Claims == nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"only tokens can be logged out; revoke API keys instead",
+		))
+		return
+	}
+
+	ipAddress := middleware.GetClientIP(c)
+	userAgent := middleware.GetUserAgent(c)
+
+	if err := h.authService.Logout(c.Request.Context(), authContext.Claims, ipAddress, userAgent); err != nil {
+		if errors.Is(err, services.ErrLogoutUnavailable) {
+			models.RespondWithError(c, &models.ProblemDetails{
+				Type:     "https://philotes.io/errors/service-unavailable",
+				Title:    "Service Unavailable",
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "logout is not enabled",
+				Instance: c.Request.URL.Path,
+			})
+			return
+		}
+		models.RespondWithError(c, models.NewInternalError(
+			c.Request.URL.Path,
+			"an unexpected error occurred",
+		))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // RegisterAdmin creates the first admin user during onboarding.
 // POST /api/v1/auth/register
 func (h *AuthHandler) RegisterAdmin(c *gin.Context) {
@@ -162,4 +205,5 @@ func (h *AuthHandler) Register(rg *gin.RouterGroup, authMiddleware gin.HandlerFu
 	auth.POST("/register", h.RegisterAdmin)
 	// Protected routes
 	auth.GET("/me", authMiddleware, h.GetMe)
+	auth.POST("/logout", authMiddleware, h.Logout)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,40 +12,48 @@ import (
 
 // ConfigHandler handles configuration endpoints.
 type ConfigHandler struct {
-	cfg *config.Config
+	cfg atomic.Pointer[config.Config]
 }
 
 // NewConfigHandler creates a new ConfigHandler.
 func NewConfigHandler(cfg *config.Config) *ConfigHandler {
-	return &ConfigHandler{cfg: cfg}
+	h := &ConfigHandler{}
+	h.cfg.Store(cfg)
+	return h
 }
 
-// GetConfig returns a safe subset of the system configuration.
+// SetConfig replaces the configuration served, such as after a reload.
+func (h *ConfigHandler) SetConfig(cfg *config.Config) {
+	h.cfg.Store(cfg)
+}
+
+// GetConfig returns the effective configuration of the API server.
 // GET /api/v1/config
 //
-// SECURITY WARNING: This endpoint exposes configuration to unauthenticated users.
-// Only include non-sensitive configuration values here. Never expose:
-// - Database passwords or connection strings
-// - API keys or secrets
-// - Storage credentials
-// - Internal hostnames or IPs that could aid attackers
+// Credentials are redacted from the settings, but the rest of the
+// configuration, such as internal hostnames, is exposed, so the endpoint
+// requires authentication when auth is enabled.
 func (h *ConfigHandler) GetConfig(c *gin.Context) {
-	// Return only safe, non-sensitive configuration
+	cfg := h.cfg.Load()
+
 	response := models.ConfigResponse{
-		Environment: h.cfg.Environment,
+		Environment: cfg.Environment,
 		API: models.APIConfig{
-			ListenAddr: h.cfg.API.ListenAddr,
-			BaseURL:    h.cfg.API.BaseURL,
+			ListenAddr:     cfg.API.ListenAddr,
+			BaseURL:        cfg.API.BaseURL,
+			RateLimitRPS:   cfg.API.RateLimitRPS,
+			RateLimitBurst: cfg.API.RateLimitBurst,
 		},
 		CDC: models.CDCConfig{
-			BufferSize:    h.cfg.CDC.BufferSize,
-			BatchSize:     h.cfg.CDC.BatchSize,
-			FlushInterval: h.cfg.CDC.FlushInterval.String(),
+			BufferSize:    cfg.CDC.BufferSize,
+			BatchSize:     cfg.CDC.BatchSize,
+			FlushInterval: cfg.CDC.FlushInterval.String(),
 		},
 		Metrics: models.MetricConfig{
-			Enabled:    h.cfg.Metrics.Enabled,
-			ListenAddr: h.cfg.Metrics.ListenAddr,
+			Enabled:    cfg.Metrics.Enabled,
+			ListenAddr: cfg.Metrics.ListenAddr,
 		},
+		Settings: cfg.Settings(),
 	}
 
 	c.JSON(http.StatusOK, response)
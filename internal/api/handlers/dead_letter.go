@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// DeadLetterHandler handles dead-letter queue requests.
+type DeadLetterHandler struct {
+	service *services.DeadLetterService
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler.
+func NewDeadLetterHandler(service *services.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// RegisterRoutes registers the dead-letter queue routes.
+func (h *DeadLetterHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	dlq := r.Group("/dlq")
+	dlq.Use(authMiddleware)
+	dlq.GET("", h.List)
+	dlq.GET("/:id", h.Get)
+	dlq.POST("/replay", h.Replay)
+}
+
+// List lists dead-letter events.
+// GET /api/v1/dlq?source_id=&since=&limit=&include_replayed=
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	filter := models.DeadLetterFilter{
+		SourceID:        c.Query("source_id"),
+		IncludeReplayed: c.Query("include_replayed") == "true",
+	}
+
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"since must be an RFC 3339 timestamp",
+			))
+			return
+		}
+		filter.Since = &since
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"limit must be an integer",
+			))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeadLetterListResponse{
+		Events:     events,
+		TotalCount: len(events),
+	})
+}
+
+// Get returns a dead-letter event with its event data.
+// GET /api/v1/dlq/:id
+func (h *DeadLetterHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid dead-letter event ID format",
+		))
+		return
+	}
+
+	event, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeadLetterEventResponse{Event: event})
+}
+
+// Replay re-enqueues dead-letter events into the buffer.
+// POST /api/v1/dlq/replay
+func (h *DeadLetterHandler) Replay(c *gin.Context) {
+	var req models.ReplayDeadLetterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"invalid request body: "+err.Error(),
+			))
+			return
+		}
+	}
+
+	resp, err := h.service.Replay(c.Request.Context(), &req)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
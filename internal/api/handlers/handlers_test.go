@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/openapi"
 	"github.com/janovincze/philotes/internal/cdc/health"
 	"github.com/janovincze/philotes/internal/config"
 )
@@ -210,3 +211,64 @@ func TestConfigHandler_GetConfig(t *testing.T) {
 		t.Errorf("expected buffer_size 10000, got %d", response.CDC.BufferSize)
 	}
 }
+
+func TestAPIDocs_CoverRegisteredRoutes(t *testing.T) {
+	noop := func(c *gin.Context) {}
+
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	NewTenantHandler(nil).Register(v1, noop)
+	NewAuditRetentionHandler(nil, nil).Register(v1, noop)
+	NewAuditHandler(nil, nil).RegisterRoutes(v1, noop)
+	NewAlertHandler(nil, nil).Register(v1)
+	NewOIDCHandler(nil).Register(v1, noop)
+
+	docs := APIDocs()
+	for _, route := range router.Routes() {
+		if op, ok := docs[route.Method+" "+route.Path]; !ok || op.Summary == "" {
+			t.Errorf("%s %s is not documented in APIDocs", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPIHandler_GetDocument(t *testing.T) {
+	handler := NewOpenAPIHandler()
+
+	router := gin.New()
+	router.GET("/api/v1/openapi.json", handler.GetDocument)
+	router.GET("/api/v1/version", NewVersionHandler("1.0.0").GetVersion)
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the document is set, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	doc := openapi.Build(openapi.Config{
+		Info:          openapi.Info{Title: "Philotes", Version: "1.0.0"},
+		ErrorResponse: models.ProblemDetails{},
+	}, router.Routes(), APIDocs())
+	if err := handler.SetDocument(doc); err != nil {
+		t.Fatalf("SetDocument() error = %v", err)
+	}
+
+	w := serve()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var served struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&served); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if served.OpenAPI != openapi.Version || served.Paths["/api/v1/version"]["get"] == nil {
+		t.Errorf("unexpected document: %+v", served)
+	}
+}
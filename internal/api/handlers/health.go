@@ -45,14 +45,7 @@ func (h *HealthHandler) GetHealth(c *gin.Context) {
 	}
 
 	for name, result := range status.Components {
-		response.Components[name] = models.ComponentHealth{
-			Name:       result.Name,
-			Status:     string(result.Status),
-			Message:    result.Message,
-			DurationMs: result.Duration.Milliseconds(),
-			LastCheck:  result.LastCheck,
-			Error:      result.Error,
-		}
+		response.Components[name] = models.NewComponentHealth(result)
 	}
 
 	// Set status code based on health
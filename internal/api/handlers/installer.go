@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/janovincze/philotes/internal/api/middleware"
 	"github.com/janovincze/philotes/internal/api/models"
 	"github.com/janovincze/philotes/internal/api/services"
 	"github.com/janovincze/philotes/internal/installer"
@@ -79,6 +80,28 @@ func (h *InstallerHandler) GetCostEstimate(c *gin.Context) {
 	c.JSON(http.StatusOK, models.CostEstimateResponse{Estimate: estimate})
 }
 
+// EstimateCost estimates the monthly cost of a deployment before it is
+// created.
+// POST /api/v1/installer/estimate
+func (h *InstallerHandler) EstimateCost(c *gin.Context) {
+	var req models.CostEstimateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	estimate, err := h.service.EstimateCost(c.Request.Context(), &req)
+	if err != nil {
+		respondWithInstallerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CostEstimateResponse{Estimate: estimate})
+}
+
 // CreateDeployment creates a new deployment.
 // POST /api/v1/installer/deployments
 func (h *InstallerHandler) CreateDeployment(c *gin.Context) {
@@ -188,10 +211,86 @@ func (h *InstallerHandler) DeleteDeployment(c *gin.Context) {
 		respondWithInstallerError(c, err)
 		return
 	}
+	if h.logHub != nil {
+		h.logHub.Forget(id)
+	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// DestroyDeployment destroys a deployment's infrastructure. The request
+// must confirm the deployment name and stack name.
+// POST /api/v1/installer/deployments/:id/destroy
+func (h *InstallerHandler) DestroyDeployment(c *gin.Context) {
+	if h.orchestrator == nil {
+		models.RespondWithError(c, models.NewInternalError(
+			c.Request.URL.Path,
+			"deployment orchestration not configured",
+		))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid deployment ID format",
+		))
+		return
+	}
+
+	var req models.DestroyDeploymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	if err := h.service.DestroyDeployment(c.Request.Context(), id, &req,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c), h.orchestrator); err != nil {
+		respondWithInstallerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":       "destroy started",
+		"deployment_id": id,
+	})
+}
+
+// UpdateDeleteProtection enables or disables a deployment's delete protection.
+// PUT /api/v1/installer/deployments/:id/delete-protection
+func (h *InstallerHandler) UpdateDeleteProtection(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid deployment ID format",
+		))
+		return
+	}
+
+	var req models.UpdateDeleteProtectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	deployment, err := h.service.UpdateDeleteProtection(c.Request.Context(), id, *req.Enabled,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithInstallerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeploymentResponse{Deployment: deployment})
+}
+
 // GetDeploymentLogs retrieves logs for a deployment.
 // GET /api/v1/installer/deployments/:id/logs
 func (h *InstallerHandler) GetDeploymentLogs(c *gin.Context) {
@@ -230,6 +329,7 @@ func respondWithInstallerError(c *gin.Context, err error) {
 	var validationErr *services.ValidationError
 	var notFoundErr *services.NotFoundError
 	var conflictErr *services.ConflictError
+	var previewErr *services.PreviewError
 
 	switch {
 	case errors.As(err, &validationErr):
@@ -247,6 +347,14 @@ func respondWithInstallerError(c *gin.Context, err error) {
 			c.Request.URL.Path,
 			conflictErr.Message,
 		))
+	case errors.As(err, &previewErr):
+		models.RespondWithError(c, &models.ProblemDetails{
+			Type:     "https://philotes.io/errors/preview-failed",
+			Title:    "Preview Failed",
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   previewErr.Error(),
+			Instance: c.Request.URL.Path,
+		})
 	default:
 		models.RespondWithError(c, models.NewInternalError(
 			c.Request.URL.Path,
@@ -270,7 +378,9 @@ func parsePositiveInt(s string) (int, error) {
 	return n, nil
 }
 
-// StreamDeploymentLogs streams deployment logs via WebSocket.
+// StreamDeploymentLogs streams deployment logs and progress via WebSocket,
+// starting with the recent history.
+// GET /api/v1/installer/deployments/:id/logs/ws (WebSocket)
 // GET /api/v1/installer/deployments/:id/logs/stream (WebSocket)
 func (h *InstallerHandler) StreamDeploymentLogs(c *gin.Context) {
 	if h.logHub == nil {
@@ -403,6 +513,37 @@ func (h *InstallerHandler) RetryDeployment(c *gin.Context) {
 	})
 }
 
+// PreviewDeployment previews the changes deploying a deployment would make.
+// POST /api/v1/installer/deployments/:id/preview
+func (h *InstallerHandler) PreviewDeployment(c *gin.Context) {
+	if h.orchestrator == nil {
+		models.RespondWithError(c, models.NewInternalError(
+			c.Request.URL.Path,
+			"deployment orchestration not configured",
+		))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid deployment ID format",
+		))
+		return
+	}
+
+	preview, err := h.service.PreviewDeployment(c.Request.Context(), id, h.orchestrator)
+	if err != nil {
+		respondWithInstallerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeploymentPreviewResponse{
+		Preview: preview,
+	})
+}
+
 // GetCleanupResources returns resources that would be cleaned up on cancel.
 // GET /api/v1/installer/deployments/:id/cleanup-preview
 func (h *InstallerHandler) GetCleanupResources(c *gin.Context) {
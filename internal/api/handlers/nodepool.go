@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -45,6 +46,7 @@ func (h *NodePoolHandler) RegisterRoutes(r *gin.RouterGroup, requireAuth gin.Han
 	cluster.Use(requireAuth)
 	cluster.GET("/capacity", h.GetClusterCapacity)
 	cluster.GET("/node-pools/status", h.GetAllPoolStatuses)
+	cluster.GET("/node-pools/costs", h.GetCostReport)
 	cluster.GET("/pending-pods", h.GetPendingPods)
 
 	// Operations
@@ -428,6 +430,45 @@ func (h *NodePoolHandler) GetAllPoolStatuses(c *gin.Context) {
 	})
 }
 
+// GetCostReport returns the cost of all node pools.
+// GET /api/v1/cluster/node-pools/costs?from=&to=
+func (h *NodePoolHandler) GetCostReport(c *gin.Context) {
+	from, ok := queryTime(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := queryTime(c, "to")
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetCostReport(c.Request.Context(), from, to)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NodePoolCostReportResponse{Report: report})
+}
+
+// queryTime parses an optional RFC 3339 query parameter, responding with a
+// bad request error if it is malformed.
+func queryTime(c *gin.Context, name string) (*time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			name+" must be an RFC 3339 timestamp",
+		))
+		return nil, false
+	}
+	return &t, true
+}
+
 // GetPendingPods returns pending pods summary.
 func (h *NodePoolHandler) GetPendingPods(c *gin.Context) {
 	pending, err := h.service.GetPendingPods(c.Request.Context())
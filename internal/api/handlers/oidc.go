@@ -154,6 +154,54 @@ func (h *OIDCHandler) Callback(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// BackChannelLogout handles a back-channel logout request from the identity
+// provider, ending the sessions its logout token names.
+// POST /api/v1/auth/oidc/:provider/backchannel-logout
+func (h *OIDCHandler) BackChannelLogout(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	logoutToken := c.PostForm("logout_token")
+	if logoutToken == "" {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"logout_token is required",
+		))
+		return
+	}
+
+	ipAddress := middleware.GetClientIP(c)
+	userAgent := middleware.GetUserAgent(c)
+
+	err := h.oidcService.BackChannelLogout(c.Request.Context(), c.Param("provider"), logoutToken, ipAddress, userAgent)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCProviderNotFound):
+			models.RespondWithError(c, models.NewNotFoundError(
+				c.Request.URL.Path,
+				"provider not found",
+			))
+		case errors.Is(err, services.ErrOIDCProviderDisabled):
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"provider is disabled",
+			))
+		case errors.Is(err, services.ErrOIDCLogoutTokenInvalid):
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"invalid logout token",
+			))
+		default:
+			models.RespondWithError(c, models.NewInternalError(
+				c.Request.URL.Path,
+				"logout failed",
+			))
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // --- Admin Endpoints ---
 
 // ListProviders lists all OIDC providers (admin).
@@ -360,6 +408,74 @@ func (h *OIDCHandler) TestProvider(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "provider connection successful"})
 }
 
+// RotateSCIMToken generates a new SCIM bearer token for an OIDC provider,
+// replacing its previous token. The token is returned only once.
+// POST /api/v1/settings/oidc/providers/:id/scim-token
+func (h *OIDCHandler) RotateSCIMToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid provider ID",
+		))
+		return
+	}
+
+	response, err := h.oidcService.RotateSCIMToken(c.Request.Context(), id,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		var notFoundErr *services.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			models.RespondWithError(c, models.NewNotFoundError(
+				c.Request.URL.Path,
+				"provider not found",
+			))
+			return
+		}
+		models.RespondWithError(c, models.NewInternalError(
+			c.Request.URL.Path,
+			"failed to generate scim token",
+		))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, response)
+}
+
+// DisableSCIM removes the SCIM bearer token of an OIDC provider.
+// DELETE /api/v1/settings/oidc/providers/:id/scim-token
+func (h *OIDCHandler) DisableSCIM(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid provider ID",
+		))
+		return
+	}
+
+	err = h.oidcService.DisableSCIM(c.Request.Context(), id,
+		auditUserID(c), middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		var notFoundErr *services.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			models.RespondWithError(c, models.NewNotFoundError(
+				c.Request.URL.Path,
+				"provider not found",
+			))
+			return
+		}
+		models.RespondWithError(c, models.NewInternalError(
+			c.Request.URL.Path,
+			"failed to remove scim token",
+		))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // Register registers OIDC routes.
 func (h *OIDCHandler) Register(rg *gin.RouterGroup, requireAuth gin.HandlerFunc) {
 	// Public OIDC auth endpoints
@@ -368,6 +484,7 @@ func (h *OIDCHandler) Register(rg *gin.RouterGroup, requireAuth gin.HandlerFunc)
 	auth.POST("/:provider/authorize", h.Authorize)
 	auth.POST("/callback", h.Callback)
 	auth.GET("/callback", h.Callback) // Also support GET for IdP redirects
+	auth.POST("/:provider/backchannel-logout", h.BackChannelLogout)
 
 	// Admin settings endpoints (protected)
 	settings := rg.Group("/settings/oidc")
@@ -378,4 +495,6 @@ func (h *OIDCHandler) Register(rg *gin.RouterGroup, requireAuth gin.HandlerFunc)
 	settings.PUT("/providers/:id", h.UpdateProvider)
 	settings.DELETE("/providers/:id", h.DeleteProvider)
 	settings.POST("/providers/:id/test", h.TestProvider)
+	settings.POST("/providers/:id/scim-token", h.RotateSCIMToken)
+	settings.DELETE("/providers/:id/scim-token", h.DisableSCIM)
 }
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/openapi"
+)
+
+// swaggerUIPage renders the served OpenAPI document with Swagger UI.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Philotes API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+// OpenAPIHandler serves the OpenAPI document of the API and Swagger UI.
+type OpenAPIHandler struct {
+	document atomic.Pointer[[]byte]
+}
+
+// NewOpenAPIHandler creates a new OpenAPIHandler. The document is served once
+// it is set with SetDocument, after all routes are registered.
+func NewOpenAPIHandler() *OpenAPIHandler {
+	return &OpenAPIHandler{}
+}
+
+// SetDocument sets the served document.
+func (h *OpenAPIHandler) SetDocument(doc *openapi.Document) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	h.document.Store(&data)
+	return nil
+}
+
+// GetDocument returns the OpenAPI document.
+// GET /api/v1/openapi.json
+func (h *OpenAPIHandler) GetDocument(c *gin.Context) {
+	data := h.document.Load()
+	if data == nil {
+		models.RespondWithError(c, &models.ProblemDetails{
+			Type:     "https://philotes.io/errors/service-unavailable",
+			Title:    "Service Unavailable",
+			Status:   http.StatusServiceUnavailable,
+			Detail:   "the OpenAPI document is not available yet",
+			Instance: c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", *data)
+}
+
+// GetUI returns the Swagger UI page.
+// GET /docs
+func (h *OpenAPIHandler) GetUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -168,6 +168,46 @@ func (h *PipelineHandler) Stop(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "pipeline stopped"})
 }
 
+// Pause pauses a pipeline.
+// POST /api/v1/pipelines/:id/pause
+func (h *PipelineHandler) Pause(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid pipeline ID format",
+		))
+		return
+	}
+
+	if err := h.service.Pause(c.Request.Context(), id); err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pipeline paused"})
+}
+
+// Resume resumes a paused pipeline.
+// POST /api/v1/pipelines/:id/resume
+func (h *PipelineHandler) Resume(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid pipeline ID format",
+		))
+		return
+	}
+
+	if err := h.service.Resume(c.Request.Context(), id); err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pipeline resumed"})
+}
+
 // GetStatus gets the status of a pipeline.
 // GET /api/v1/pipelines/:id/status
 func (h *PipelineHandler) GetStatus(c *gin.Context) {
@@ -189,6 +229,27 @@ func (h *PipelineHandler) GetStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+// GetBufferStats gets the buffer retention and cleanup stats of a pipeline.
+// GET /api/v1/pipelines/:id/buffer
+func (h *PipelineHandler) GetBufferStats(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid pipeline ID format",
+		))
+		return
+	}
+
+	stats, err := h.service.GetBufferStats(c.Request.Context(), id)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // AddTableMapping adds a table mapping to a pipeline.
 // POST /api/v1/pipelines/:id/tables
 func (h *PipelineHandler) AddTableMapping(c *gin.Context) {
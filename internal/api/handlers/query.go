@@ -2,41 +2,54 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
 	"github.com/janovincze/philotes/internal/api/services"
+	"github.com/janovincze/philotes/internal/query"
 )
 
 // QueryHandler handles query layer API endpoints.
 type QueryHandler struct {
 	service *services.QueryService
+	history *services.QueryHistoryService
 	logger  *slog.Logger
 }
 
-// NewQueryHandler creates a new QueryHandler.
-func NewQueryHandler(service *services.QueryService, logger *slog.Logger) *QueryHandler {
+// NewQueryHandler creates a new QueryHandler. history may be nil when the
+// query history is disabled.
+func NewQueryHandler(service *services.QueryService, history *services.QueryHistoryService, logger *slog.Logger) *QueryHandler {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
 	return &QueryHandler{
 		service: service,
+		history: history,
 		logger:  logger.With("component", "query-handler"),
 	}
 }
 
 // RegisterRoutes registers the query layer routes.
 func (h *QueryHandler) RegisterRoutes(r *gin.RouterGroup) {
-	query := r.Group("/query")
-	query.GET("/status", h.GetStatus)
-	query.GET("/health", h.GetHealth)
-	query.GET("/catalogs", h.ListCatalogs)
-	query.GET("/catalogs/:catalog/schemas", h.ListSchemas)
-	query.GET("/catalogs/:catalog/schemas/:schema/tables", h.ListTables)
-	query.GET("/catalogs/:catalog/schemas/:schema/tables/:table", h.GetTableInfo)
+	queries := r.Group("/query")
+	queries.POST("", h.ExecuteQuery)
+	queries.GET("/status", h.GetStatus)
+	queries.GET("/health", h.GetHealth)
+	queries.GET("/history", h.ListHistory)
+	queries.GET("/catalogs", h.ListCatalogs)
+	queries.GET("/catalogs/:catalog/schemas", h.ListSchemas)
+	queries.GET("/catalogs/:catalog/schemas/:schema/tables", h.ListTables)
+	queries.GET("/catalogs/:catalog/schemas/:schema/tables/:table", h.GetTableInfo)
 }
 
 // GetStatus godoc
@@ -181,3 +194,290 @@ func (h *QueryHandler) GetTableInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, info)
 }
+
+// ExecuteQuery godoc
+// @Summary Execute a SQL statement
+// @Description Runs a SQL statement on the configured query engine (Trino or DuckDB) and streams
+// @Description the result as JSON: the column metadata, the rows and the query stats. Results
+// @Description are capped at the configured maximum number of rows. Statements other than
+// @Description queries require the query:write permission and are rejected by DuckDB.
+// @Description Tenant-scoped requests run in the tenant's schema and may only reference the
+// @Description tenant's schemas. With the result cache enabled, query results report
+// @Description cache (hit or miss) and cached_at; no_cache bypasses the cached result.
+// @Tags query
+// @Accept json
+// @Produce json
+// @Param request body models.ExecuteQueryRequest true "Statement to execute"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 403 {object} models.ProblemDetails
+// @Failure 504 {object} models.ProblemDetails
+// @Router /query [post]
+func (h *QueryHandler) ExecuteQuery(c *gin.Context) {
+	var req models.ExecuteQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(c.Request.URL.Path, "invalid request body: "+err.Error()))
+		return
+	}
+
+	authContext := middleware.GetAuthContext(c)
+	canWrite := authContext != nil && authContext.HasPermission(models.PermissionQueryWrite)
+
+	started := time.Now()
+	result, err := h.service.Execute(c.Request.Context(), &req, tenantScope(c), canWrite)
+	if err != nil {
+		recordQuery(c, h.history, h.service, req.SQL, nil, started, nil, 0, err)
+		respondWithQueryError(c, err, h.logger)
+		return
+	}
+	defer result.Close()
+
+	rowCount, streamErr := streamQueryResult(c, result, started, h.service, h.logger)
+	recordQuery(c, h.history, h.service, req.SQL, nil, started, result, rowCount, streamErr)
+}
+
+// ListHistory godoc
+// @Summary List executed queries
+// @Description Returns the statements run through the query endpoint and as saved queries,
+// @Description newest first: who ran them, the engine, duration, rows, bytes scanned and
+// @Description outcome. Tenant-scoped requests only see the tenant's queries, and callers
+// @Description without the users:read permission only their own.
+// @Tags query
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param status query string false "success or error"
+// @Param since query string false "RFC 3339 timestamp"
+// @Param until query string false "RFC 3339 timestamp"
+// @Param limit query int false "Page size (default 100, max 1000)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} models.QueryHistoryListResponse
+// @Failure 400 {object} models.ProblemDetails
+// @Failure 503 {object} models.ProblemDetails
+// @Router /query/history [get]
+func (h *QueryHandler) ListHistory(c *gin.Context) {
+	if h.history == nil {
+		models.RespondWithError(c, &models.ProblemDetails{
+			Type:     "https://philotes.io/errors/service-unavailable",
+			Title:    "Service Unavailable",
+			Status:   http.StatusServiceUnavailable,
+			Detail:   "query history is not enabled",
+			Instance: c.Request.URL.Path,
+		})
+		return
+	}
+
+	filter := models.QueryHistoryFilter{
+		TenantID: tenantScope(c),
+		Status:   models.QueryStatus(c.Query("status")),
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(c.Request.URL.Path, "invalid user ID format"))
+			return
+		}
+		filter.UserID = &userID
+	}
+	var ok bool
+	if filter.Since, ok = queryTime(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = queryTime(c, "until"); !ok {
+		return
+	}
+
+	// Callers who may not see other users only see their own queries
+	if authContext := middleware.GetAuthContext(c); authContext != nil && !authContext.HasPermission(models.PermissionUsersRead) {
+		userID := historyUserID(authContext)
+		if userID == nil || (filter.UserID != nil && *filter.UserID != *userID) {
+			models.RespondWithError(c, models.NewForbiddenError(c.Request.URL.Path, "listing queries of other users requires the users:read permission"))
+			return
+		}
+		filter.UserID = userID
+	}
+
+	limit, offset := parsePagination(c)
+	response, err := h.history.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// historyUserID returns the user a request is attributed to in the query
+// history: the authenticated user or the owner of the API key.
+func historyUserID(authContext *models.AuthContext) *uuid.UUID {
+	switch {
+	case authContext.User != nil:
+		return &authContext.User.ID
+	case authContext.APIKey != nil:
+		return &authContext.APIKey.UserID
+	default:
+		return nil
+	}
+}
+
+// recordQuery adds a run of statement to the query history when history is
+// set. result is nil when the statement failed before it ran. Requests
+// rejected as invalid are not recorded.
+func recordQuery(c *gin.Context, history *services.QueryHistoryService, service *services.QueryService, statement string, savedQueryID *uuid.UUID,
+	started time.Time, result query.Result, rowCount int, err error,
+) {
+	var validationErr *services.ValidationError
+	if history == nil || service == nil || errors.As(err, &validationErr) {
+		return
+	}
+
+	entry := &models.QueryHistoryEntry{
+		TenantID:     tenantScope(c),
+		SavedQueryID: savedQueryID,
+		Engine:       service.EngineName(),
+		SQL:          statement,
+		Status:       models.QueryStatusSuccess,
+		DurationMs:   time.Since(started).Milliseconds(),
+		RowCount:     int64(rowCount),
+		ExecutedAt:   started,
+	}
+	if authContext := middleware.GetAuthContext(c); authContext != nil {
+		entry.UserID = historyUserID(authContext)
+		if authContext.APIKey != nil {
+			entry.APIKeyID = &authContext.APIKey.ID
+		}
+	}
+	if result != nil {
+		entry.QueryID = result.ID()
+		if scanned, ok := result.(query.ScanStats); ok {
+			entry.BytesScanned = scanned.BytesScanned()
+		}
+	}
+	if err != nil {
+		entry.Status = models.QueryStatusError
+		entry.Error = err.Error()
+	}
+
+	history.Record(entry)
+}
+
+// respondWithQueryError maps a query error to a problem response.
+func respondWithQueryError(c *gin.Context, err error, logger *slog.Logger) {
+	switch {
+	case errors.Is(err, services.ErrStatementNotPermitted), errors.Is(err, services.ErrSchemaAccessDenied),
+		errors.Is(err, query.ErrReadOnly):
+		models.RespondWithError(c, models.NewForbiddenError(c.Request.URL.Path, err.Error()))
+	case errors.Is(err, query.ErrQueryTimeout):
+		models.RespondWithError(c, &models.ProblemDetails{
+			Type:     "https://philotes.io/errors/query-timeout",
+			Title:    "Query Timeout",
+			Status:   http.StatusGatewayTimeout,
+			Detail:   err.Error(),
+			Instance: c.Request.URL.Path,
+		})
+	case errors.Is(err, query.ErrNotEnabled):
+		models.RespondWithError(c, &models.ProblemDetails{
+			Type:     "https://philotes.io/errors/service-unavailable",
+			Title:    "Service Unavailable",
+			Status:   http.StatusServiceUnavailable,
+			Detail:   err.Error(),
+			Instance: c.Request.URL.Path,
+		})
+	case errors.Is(err, query.ErrStatementFailed):
+		models.RespondWithError(c, models.NewBadRequestError(c.Request.URL.Path, err.Error()))
+	default:
+		var validationErr *services.ValidationError
+		if !errors.As(err, &validationErr) {
+			logger.Error("failed to execute query", "error", err)
+		}
+		respondWithServiceError(c, err)
+	}
+}
+
+// cachedResult is implemented by results that went through the result
+// cache.
+type cachedResult interface {
+	CacheStatus() (query.CacheStatus, time.Time)
+}
+
+// queryStats summarizes an executed statement.
+type queryStats struct {
+	Engine    string `json:"engine"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Truncated bool   `json:"truncated"`
+}
+
+// streamQueryResult writes the result as a JSON object, flushing every page
+// of rows as it arrives. Rows beyond the service's maximum are dropped and
+// the result is marked truncated. Results that went through the result cache
+// report whether they were a cache hit and when they were cached. An error
+// after the response has started is reported in the object's error field.
+// It returns the number of rows written and that error.
+func streamQueryResult(c *gin.Context, result query.Result, started time.Time, service *services.QueryService, logger *slog.Logger) (int, error) {
+	columns := make([]models.QueryResultColumn, 0, len(result.Columns()))
+	for _, col := range result.Columns() {
+		columns = append(columns, models.QueryResultColumn{Name: col.Name, Type: col.Type})
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	enc := json.NewEncoder(w)
+	write := func(s string) { _, _ = io.WriteString(w, s) } //nolint:errcheck // client disconnects surface as context cancellation
+
+	write(`{"query_id":`)
+	_ = enc.Encode(result.ID()) //nolint:errcheck // see write
+	write(`,"columns":`)
+	_ = enc.Encode(columns) //nolint:errcheck // see write
+	write(`,"rows":[`)
+
+	maxRows := service.MaxResultRows()
+	stats := queryStats{Engine: service.EngineName()}
+	rowCount := 0
+	var streamErr error
+	for !stats.Truncated {
+		rows, err := result.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			streamErr = err
+			break
+		}
+
+		for _, row := range rows {
+			if maxRows > 0 && rowCount == maxRows {
+				stats.Truncated = true
+				break
+			}
+			if rowCount > 0 {
+				write(",")
+			}
+			_ = enc.Encode(row) //nolint:errcheck // see write
+			rowCount++
+		}
+		w.Flush()
+	}
+	stats.ElapsedMs = time.Since(started).Milliseconds()
+
+	write(`],"row_count":`)
+	_ = enc.Encode(rowCount) //nolint:errcheck // see write
+	write(`,"stats":`)
+	_ = enc.Encode(stats) //nolint:errcheck // see write
+	if cached, ok := result.(cachedResult); ok {
+		status, cachedAt := cached.CacheStatus()
+		write(`,"cache":`)
+		_ = enc.Encode(status) //nolint:errcheck // see write
+		write(`,"cached_at":`)
+		_ = enc.Encode(cachedAt) //nolint:errcheck // see write
+	}
+	if streamErr != nil {
+		logger.Warn("query failed while streaming results", "query_id", result.ID(), "error", streamErr)
+		write(`,"error":`)
+		_ = enc.Encode(streamErr.Error()) //nolint:errcheck // see write
+	}
+	write("}")
+	w.Flush()
+
+	return rowCount, streamErr
+}
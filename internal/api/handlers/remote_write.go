@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// maxRemoteWriteBytes is the largest compressed remote-write request accepted.
+const maxRemoteWriteBytes = 10 << 20
+
+// RemoteWriteHandler handles Prometheus remote-write requests.
+type RemoteWriteHandler struct {
+	service *services.RemoteWriteService
+}
+
+// NewRemoteWriteHandler creates a new RemoteWriteHandler.
+func NewRemoteWriteHandler(service *services.RemoteWriteService) *RemoteWriteHandler {
+	return &RemoteWriteHandler{service: service}
+}
+
+// RegisterRoutes registers the remote-write route.
+func (h *RemoteWriteHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	r.POST("/metrics/write", authMiddleware, h.Write)
+}
+
+// Write stores the samples of a snappy-compressed protobuf remote-write request.
+// POST /api/v1/metrics/write
+func (h *RemoteWriteHandler) Write(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRemoteWriteBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			models.RespondWithError(c, &models.ProblemDetails{
+				Type:     "https://philotes.io/errors/payload-too-large",
+				Title:    "Payload Too Large",
+				Status:   http.StatusRequestEntityTooLarge,
+				Detail:   "remote-write request exceeds the maximum size",
+				Instance: c.Request.URL.Path,
+			})
+			return
+		}
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"failed to read request body: "+err.Error(),
+		))
+		return
+	}
+
+	if _, err := h.service.Ingest(c.Request.Context(), body); err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,202 @@
+// Package handlers provides HTTP handlers for API endpoints.
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// SavedQueryHandler handles saved query HTTP requests. Saved queries are
+// scoped to the tenant of the request; requests without a tenant see every
+// tenant's queries and create global ones.
+type SavedQueryHandler struct {
+	service      *services.SavedQueryService
+	queryService *services.QueryService
+	history      *services.QueryHistoryService
+	logger       *slog.Logger
+}
+
+// NewSavedQueryHandler creates a new SavedQueryHandler. queryService may be
+// nil when no query engine is configured, and history when the query
+// history is disabled.
+func NewSavedQueryHandler(service *services.SavedQueryService, queryService *services.QueryService, history *services.QueryHistoryService, logger *slog.Logger) *SavedQueryHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SavedQueryHandler{
+		service:      service,
+		queryService: queryService,
+		history:      history,
+		logger:       logger.With("component", "saved-query-handler"),
+	}
+}
+
+// RegisterRoutes registers the saved query routes.
+func (h *SavedQueryHandler) RegisterRoutes(r *gin.RouterGroup) {
+	savedQueries := r.Group("/saved-queries")
+	savedQueries.POST("", h.Create)
+	savedQueries.GET("", h.List)
+	savedQueries.GET("/:id", h.Get)
+	savedQueries.PUT("/:id", h.Update)
+	savedQueries.DELETE("/:id", h.Delete)
+	savedQueries.POST("/:id/run", h.Run)
+}
+
+// Create creates a new saved query.
+// POST /api/v1/saved-queries
+func (h *SavedQueryHandler) Create(c *gin.Context) {
+	var req models.CreateSavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	q, err := h.service.Create(c.Request.Context(), tenantScope(c), &req)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SavedQueryResponse{SavedQuery: q})
+}
+
+// List lists saved queries.
+// GET /api/v1/saved-queries
+func (h *SavedQueryHandler) List(c *gin.Context) {
+	limit, offset := parsePagination(c)
+
+	response, err := h.service.List(c.Request.Context(), tenantScope(c), limit, offset)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get retrieves a saved query by ID.
+// GET /api/v1/saved-queries/:id
+func (h *SavedQueryHandler) Get(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	q, err := h.service.Get(c.Request.Context(), id, tenantScope(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+	if middleware.NotModified(c, q.ID, q.UpdatedAt) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SavedQueryResponse{SavedQuery: q})
+}
+
+// Update updates a saved query.
+// PUT /api/v1/saved-queries/:id
+func (h *SavedQueryHandler) Update(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	q, err := h.service.Update(c.Request.Context(), id, tenantScope(c), &req)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SavedQueryResponse{SavedQuery: q})
+}
+
+// Delete deletes a saved query.
+// DELETE /api/v1/saved-queries/:id
+func (h *SavedQueryHandler) Delete(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id, tenantScope(c)); err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Run runs a saved query with the parameter values of the request body and
+// streams the result like POST /api/v1/query. The body may be empty when
+// every parameter has a default.
+// POST /api/v1/saved-queries/:id/run
+func (h *SavedQueryHandler) Run(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RunSavedQueryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"invalid request body: "+err.Error(),
+			))
+			return
+		}
+	}
+
+	authContext := middleware.GetAuthContext(c)
+	canWrite := authContext != nil && authContext.HasPermission(models.PermissionQueryWrite)
+
+	started := time.Now()
+	q, result, err := h.service.Run(c.Request.Context(), id, tenantScope(c), &req, canWrite)
+	if err != nil {
+		if q != nil {
+			recordQuery(c, h.history, h.queryService, q.SQL, &q.ID, started, nil, 0, err)
+		}
+		respondWithQueryError(c, err, h.logger)
+		return
+	}
+	defer result.Close()
+
+	rowCount, streamErr := streamQueryResult(c, result, started, h.queryService, h.logger)
+	recordQuery(c, h.history, h.queryService, q.SQL, &q.ID, started, result, rowCount, streamErr)
+}
+
+// parseID parses the saved query ID path parameter. It writes the error
+// response and returns false when the ID is invalid.
+func (h *SavedQueryHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid saved query ID format",
+		))
+		return uuid.Nil, false
+	}
+	return id, true
+}
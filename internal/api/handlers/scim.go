@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// scimContentType is the media type of SCIM requests and responses.
+const scimContentType = "application/scim+json"
+
+// scimProviderKey is the context key of the provider a SCIM request is
+// authenticated for.
+const scimProviderKey = "scim_provider"
+
+// SCIMHandler handles SCIM 2.0 provisioning requests.
+type SCIMHandler struct {
+	scimService *services.SCIMService
+}
+
+// NewSCIMHandler creates a new SCIMHandler.
+func NewSCIMHandler(scimService *services.SCIMService) *SCIMHandler {
+	return &SCIMHandler{scimService: scimService}
+}
+
+// Authenticate authenticates SCIM requests with the bearer token of an
+// OIDC provider.
+func (h *SCIMHandler) Authenticate(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		respondSCIMError(c, http.StatusUnauthorized, "", "bearer token required")
+		c.Abort()
+		return
+	}
+
+	provider, err := h.scimService.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrSCIMUnauthorized) {
+			respondSCIMError(c, http.StatusUnauthorized, "", "invalid bearer token")
+		} else {
+			respondSCIMError(c, http.StatusInternalServerError, "", "authentication failed")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set(scimProviderKey, provider)
+	c.Next()
+}
+
+// provider returns the provider the request is authenticated for.
+func (h *SCIMHandler) provider(c *gin.Context) *models.OIDCProvider {
+	return c.MustGet(scimProviderKey).(*models.OIDCProvider)
+}
+
+// ServiceProviderConfig describes the supported SCIM features.
+// GET /scim/v2/ServiceProviderConfig
+func (h *SCIMHandler) ServiceProviderConfig(c *gin.Context) {
+	respondSCIM(c, http.StatusOK, h.scimService.ServiceProviderConfig())
+}
+
+// ListUsers lists provisioned users.
+// GET /scim/v2/Users
+func (h *SCIMHandler) ListUsers(c *gin.Context) {
+	startIndex, count := scimPaging(c)
+	response, err := h.scimService.ListUsers(c.Request.Context(), h.provider(c), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, response)
+}
+
+// GetUser retrieves a provisioned user.
+// GET /scim/v2/Users/:id
+func (h *SCIMHandler) GetUser(c *gin.Context) {
+	user, err := h.scimService.GetUser(c.Request.Context(), h.provider(c), c.Param("id"))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// CreateUser provisions a user.
+// POST /scim/v2/Users
+func (h *SCIMHandler) CreateUser(c *gin.Context) {
+	var req models.SCIMUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	user, err := h.scimService.CreateUser(c.Request.Context(), h.provider(c), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusCreated, user)
+}
+
+// ReplaceUser replaces a provisioned user.
+// PUT /scim/v2/Users/:id
+func (h *SCIMHandler) ReplaceUser(c *gin.Context) {
+	var req models.SCIMUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	user, err := h.scimService.ReplaceUser(c.Request.Context(), h.provider(c), c.Param("id"), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// PatchUser patches a provisioned user.
+// PATCH /scim/v2/Users/:id
+func (h *SCIMHandler) PatchUser(c *gin.Context) {
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	user, err := h.scimService.PatchUser(c.Request.Context(), h.provider(c), c.Param("id"), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, user)
+}
+
+// DeleteUser deactivates a provisioned user.
+// DELETE /scim/v2/Users/:id
+func (h *SCIMHandler) DeleteUser(c *gin.Context) {
+	err := h.scimService.DeleteUser(c.Request.Context(), h.provider(c), c.Param("id"),
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListGroups lists provisioned groups.
+// GET /scim/v2/Groups
+func (h *SCIMHandler) ListGroups(c *gin.Context) {
+	startIndex, count := scimPaging(c)
+	response, err := h.scimService.ListGroups(c.Request.Context(), h.provider(c), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, response)
+}
+
+// GetGroup retrieves a provisioned group.
+// GET /scim/v2/Groups/:id
+func (h *SCIMHandler) GetGroup(c *gin.Context) {
+	group, err := h.scimService.GetGroup(c.Request.Context(), h.provider(c), c.Param("id"))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// CreateGroup provisions a group.
+// POST /scim/v2/Groups
+func (h *SCIMHandler) CreateGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	group, err := h.scimService.CreateGroup(c.Request.Context(), h.provider(c), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusCreated, group)
+}
+
+// ReplaceGroup replaces a provisioned group and its members.
+// PUT /scim/v2/Groups/:id
+func (h *SCIMHandler) ReplaceGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	group, err := h.scimService.ReplaceGroup(c.Request.Context(), h.provider(c), c.Param("id"), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// PatchGroup patches a provisioned group, typically its members.
+// PATCH /scim/v2/Groups/:id
+func (h *SCIMHandler) PatchGroup(c *gin.Context) {
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondSCIMError(c, http.StatusBadRequest, models.SCIMErrorInvalidValue, "invalid request body: "+err.Error())
+		return
+	}
+
+	group, err := h.scimService.PatchGroup(c.Request.Context(), h.provider(c), c.Param("id"), &req,
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	respondSCIM(c, http.StatusOK, group)
+}
+
+// DeleteGroup deletes a provisioned group.
+// DELETE /scim/v2/Groups/:id
+func (h *SCIMHandler) DeleteGroup(c *gin.Context) {
+	err := h.scimService.DeleteGroup(c.Request.Context(), h.provider(c), c.Param("id"),
+		middleware.GetClientIP(c), middleware.GetUserAgent(c))
+	if err != nil {
+		respondWithSCIMServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Register registers the SCIM routes on a group mounted at /scim/v2.
+func (h *SCIMHandler) Register(rg *gin.RouterGroup) {
+	rg.Use(h.Authenticate)
+	rg.GET("/ServiceProviderConfig", h.ServiceProviderConfig)
+
+	rg.GET("/Users", h.ListUsers)
+	rg.POST("/Users", h.CreateUser)
+	rg.GET("/Users/:id", h.GetUser)
+	rg.PUT("/Users/:id", h.ReplaceUser)
+	rg.PATCH("/Users/:id", h.PatchUser)
+	rg.DELETE("/Users/:id", h.DeleteUser)
+
+	rg.GET("/Groups", h.ListGroups)
+	rg.POST("/Groups", h.CreateGroup)
+	rg.GET("/Groups/:id", h.GetGroup)
+	rg.PUT("/Groups/:id", h.ReplaceGroup)
+	rg.PATCH("/Groups/:id", h.PatchGroup)
+	rg.DELETE("/Groups/:id", h.DeleteGroup)
+}
+
+// scimPaging returns the 1-based start index and page size of a list
+// request.
+func scimPaging(c *gin.Context) (startIndex, count int) {
+	startIndex, count = 1, services.SCIMDefaultCount
+	if v, err := strconv.Atoi(c.Query("startIndex")); err == nil {
+		startIndex = v
+	}
+	if v, err := strconv.Atoi(c.Query("count")); err == nil {
+		count = v
+	}
+	return startIndex, count
+}
+
+// respondSCIM writes a SCIM response.
+func respondSCIM(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, body)
+}
+
+// respondSCIMError writes a SCIM error response.
+func respondSCIMError(c *gin.Context, status int, scimType, detail string) {
+	respondSCIM(c, status, models.SCIMError{
+		Schemas:  []string{models.SCIMSchemaError},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
+
+// respondWithSCIMServiceError maps service errors to SCIM error responses.
+func respondWithSCIMServiceError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	var notFoundErr *services.NotFoundError
+	var conflictErr *services.ConflictError
+
+	switch {
+	case errors.As(err, &validationErr):
+		scimType := models.SCIMErrorInvalidValue
+		detail := "invalid request"
+		if len(validationErr.Errors) > 0 {
+			switch validationErr.Errors[0].Field {
+			case "filter":
+				scimType = models.SCIMErrorInvalidFilter
+			case "path", "op":
+				scimType = models.SCIMErrorInvalidPath
+			}
+			detail = validationErr.Errors[0].Message
+		}
+		respondSCIMError(c, http.StatusBadRequest, scimType, detail)
+	case errors.As(err, &notFoundErr):
+		respondSCIMError(c, http.StatusNotFound, "", notFoundErr.Error())
+	case errors.As(err, &conflictErr):
+		respondSCIMError(c, http.StatusConflict, models.SCIMErrorUniqueness, conflictErr.Error())
+	default:
+		respondSCIMError(c, http.StatusInternalServerError, "", "internal error")
+	}
+}
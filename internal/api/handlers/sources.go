@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/janovincze/philotes/internal/api/middleware"
 	"github.com/janovincze/philotes/internal/api/models"
 	"github.com/janovincze/philotes/internal/api/services"
 )
@@ -75,6 +76,9 @@ func (h *SourceHandler) Get(c *gin.Context) {
 		respondWithServiceError(c, err)
 		return
 	}
+	if middleware.NotModified(c, source.ID, source.UpdatedAt) {
+		return
+	}
 
 	c.JSON(http.StatusOK, models.SourceResponse{Source: source})
 }
@@ -150,6 +154,80 @@ func (h *SourceHandler) TestConnection(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// Validate checks that a source is ready to replicate.
+// POST /api/v1/sources/:id/validate
+func (h *SourceHandler) Validate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid source ID format",
+		))
+		return
+	}
+
+	var req models.ValidateSourceRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"invalid request body: "+err.Error(),
+			))
+			return
+		}
+	}
+
+	result, err := h.service.Validate(c.Request.Context(), id, req.Tables)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Health checks that a source database is reachable.
+// GET /api/v1/sources/:id/health
+func (h *SourceHandler) Health(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid source ID format",
+		))
+		return
+	}
+
+	result, err := h.service.Health(c.Request.Context(), id)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DiscoverSchemas lists the schemas in a source database.
+// GET /api/v1/sources/:id/schemas
+func (h *SourceHandler) DiscoverSchemas(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid source ID format",
+		))
+		return
+	}
+
+	result, err := h.service.DiscoverSchemas(c.Request.Context(), id)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // DiscoverTables discovers tables in a source database.
 // GET /api/v1/sources/:id/tables
 func (h *SourceHandler) DiscoverTables(c *gin.Context) {
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// StatusHandler serves the system status summary.
+type StatusHandler struct {
+	service *services.StatusService
+}
+
+// NewStatusHandler creates a new StatusHandler.
+func NewStatusHandler(service *services.StatusService) *StatusHandler {
+	return &StatusHandler{service: service}
+}
+
+// RegisterRoutes registers the status route.
+func (h *StatusHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	r.GET("/status", authMiddleware, h.GetStatus)
+}
+
+// GetStatus returns a summary of pipelines, workers, buffer depth and
+// replication lag.
+// GET /api/v1/status
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	status, err := h.service.GetStatus(c.Request.Context())
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SystemStatusResponse{Status: *status})
+}
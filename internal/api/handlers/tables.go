@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// TableHandler handles Iceberg table metadata requests.
+type TableHandler struct {
+	service *services.TableService
+}
+
+// NewTableHandler creates a new TableHandler.
+func NewTableHandler(service *services.TableService) *TableHandler {
+	return &TableHandler{service: service}
+}
+
+// RegisterRoutes registers the table metadata routes.
+func (h *TableHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	tables := r.Group("/tables")
+	tables.Use(authMiddleware)
+	tables.GET("/:id/snapshots", h.GetSnapshots)
+	tables.GET("/:id/files-summary", h.GetFilesSummary)
+	tables.GET("/:id/partitions", h.GetPartitions)
+	tables.GET("/:id/branches", h.ListBranches)
+	tables.POST("/:id/branches", h.CreateBranch)
+	tables.POST("/:id/branches/:branch/promote", h.PromoteBranch)
+	tables.DELETE("/:id/branches/:branch", h.DeleteBranch)
+}
+
+// GetSnapshots lists the snapshots of a managed table.
+// GET /api/v1/tables/:id/snapshots
+func (h *TableHandler) GetSnapshots(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.GetSnapshots(c.Request.Context(), id, tenantScope(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetFilesSummary returns data file statistics of a managed table.
+// GET /api/v1/tables/:id/files-summary
+func (h *TableHandler) GetFilesSummary(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.GetFilesSummary(c.Request.Context(), id, tenantScope(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetPartitions returns per-partition statistics of a managed table.
+// GET /api/v1/tables/:id/partitions
+func (h *TableHandler) GetPartitions(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.GetPartitions(c.Request.Context(), id, tenantScope(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListBranches lists the branches and tags of a managed table.
+// GET /api/v1/tables/:id/branches
+func (h *TableHandler) ListBranches(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.ListBranches(c.Request.Context(), id, tenantScope(c))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateBranch creates a branch or tag at the current snapshot of a managed table.
+// POST /api/v1/tables/:id/branches
+func (h *TableHandler) CreateBranch(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTableBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid request body: "+err.Error(),
+		))
+		return
+	}
+
+	resp, err := h.service.CreateBranch(c.Request.Context(), id, tenantScope(c), &req)
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// PromoteBranch atomically makes a branch the main branch of a managed table.
+// POST /api/v1/tables/:id/branches/:branch/promote
+func (h *TableHandler) PromoteBranch(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.PromoteBranch(c.Request.Context(), id, tenantScope(c), c.Param("branch"))
+	if err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteBranch removes a branch or tag of a managed table.
+// DELETE /api/v1/tables/:id/branches/:branch
+func (h *TableHandler) DeleteBranch(c *gin.Context) {
+	id, ok := parseTableID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBranch(c.Request.Context(), id, tenantScope(c), c.Param("branch")); err != nil {
+		respondWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseTableID parses the table mapping ID path parameter.
+func parseTableID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		models.RespondWithError(c, models.NewBadRequestError(
+			c.Request.URL.Path,
+			"invalid table ID format",
+		))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// tenantScope returns the request's tenant ID, or nil when tenancy is not in effect.
+func tenantScope(c *gin.Context) *uuid.UUID {
+	tenantID, ok := middleware.GetTenantID(c)
+	if !ok {
+		return nil
+	}
+	return &tenantID
+}
@@ -127,6 +127,9 @@ func (h *TenantHandler) Get(c *gin.Context) {
 		h.respondWithError(c, err)
 		return
 	}
+	if middleware.NotModified(c, tenant.ID, tenant.UpdatedAt) {
+		return
+	}
 
 	c.JSON(http.StatusOK, models.TenantResponse{Tenant: tenant})
 }
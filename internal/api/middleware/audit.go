@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// auditRoutePrefix is trimmed from routes in audit actions.
+const auditRoutePrefix = "/api/v1"
+
+// AuditConfig holds request audit middleware configuration.
+type AuditConfig struct {
+	// AuditService records the audit log. The middleware does nothing when
+	// it is nil.
+	AuditService *services.AuditService
+
+	// SkipRoutes are routes, as registered, such as "/api/v1/metrics/write",
+	// whose requests are not audited.
+	SkipRoutes []string
+
+	// MaxBodySize is the largest JSON request body, in bytes, that is stored
+	// with secrets redacted. Larger bodies are not stored. Defaults to 16 KiB
+	// if not set.
+	MaxBodySize int
+}
+
+// Audit returns a middleware that records an audit log entry for every
+// request that is not a GET, HEAD or OPTIONS request: who made it, for which
+// tenant, the method and route as the action, the resource from the path,
+// the status code and latency. JSON request bodies are stored with the
+// values of secret fields redacted. Requests that match no route are not
+// audited. Must be used after Authenticate.
+func Audit(cfg AuditConfig) gin.HandlerFunc {
+	if cfg.AuditService == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 16 << 10
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if route == "" || slices.Contains(cfg.SkipRoutes, route) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		body, truncated := peekBody(c.Request, maxBodySize)
+
+		c.Next()
+
+		action := strings.TrimPrefix(route, auditRoutePrefix)
+		details := map[string]interface{}{
+			"method":     c.Request.Method,
+			"route":      action,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+		if requestID := c.GetString(RequestIDKey); requestID != "" {
+			details["request_id"] = requestID
+		}
+		if truncated {
+			details["body_truncated"] = true
+		} else if len(body) > 0 && strings.Contains(c.ContentType(), "json") {
+			if redacted, ok := services.RedactRequestBody(body); ok {
+				details["body"] = redacted
+			}
+		}
+
+		resourceType, resourceID := auditResource(c, action)
+		log := &models.AuditLog{
+			TenantID:     auditTenant(c, action),
+			Action:       c.Request.Method + " " + action,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			IPAddress:    GetClientIP(c),
+			UserAgent:    GetUserAgent(c),
+			Details:      details,
+		}
+		if authContext := GetAuthContext(c); authContext != nil {
+			if authContext.User != nil {
+				log.UserID = &authContext.User.ID
+			}
+			if authContext.APIKey != nil {
+				log.APIKeyID = &authContext.APIKey.ID
+			}
+		}
+
+		cfg.AuditService.Record(log)
+	}
+}
+
+// peekBody reads up to max bytes of a request body and puts them back. It
+// reports true when the body is larger, in which case the bytes read are not
+// returned.
+func peekBody(r *http.Request, max int) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > max {
+		return nil, len(body) > max
+	}
+	return body, false
+}
+
+// auditResource derives the audited resource from a route: the last path
+// parameter that is a UUID and the path segment before it, such as members
+// for /tenants/:id/members/:user_id. Without one the resource type is the
+// first path segment.
+func auditResource(c *gin.Context, route string) (string, *uuid.UUID) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+
+	var resourceType string
+	var resourceID *uuid.UUID
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok || i == 0 {
+			continue
+		}
+		if id, err := uuid.Parse(c.Param(name)); err == nil {
+			resourceType, resourceID = segments[i-1], &id
+		}
+	}
+	if resourceID == nil && len(segments) > 0 {
+		resourceType = segments[0]
+	}
+	return resourceType, resourceID
+}
+
+// auditTenant returns the tenant of an audited request: the tenant context,
+// the tenant in the path of tenant routes or the X-Tenant-ID header. It
+// returns nil when the request names no tenant, so that the audit log
+// attributes the entry to the tenant of the user or API key.
+func auditTenant(c *gin.Context, route string) *uuid.UUID {
+	if tenantID, ok := GetTenantID(c); ok {
+		return &tenantID
+	}
+	if strings.HasPrefix(route, "/tenants/:id") {
+		if tenantID, err := uuid.Parse(c.Param("id")); err == nil {
+			return &tenantID
+		}
+	}
+	if tenantID, err := uuid.Parse(c.GetHeader("X-Tenant-ID")); err == nil {
+		return &tenantID
+	}
+	return nil
+}
@@ -26,6 +26,9 @@ type AuthConfig struct {
 	// APIKeyService is the API key service for API key validation
 	APIKeyService *services.APIKeyService
 
+	// TokenRevocationService is the denylist JWTs are checked against
+	TokenRevocationService *services.TokenRevocationService
+
 	// APIKeyPrefix is the prefix used for API keys (e.g., "pk_")
 	APIKeyPrefix string
 }
@@ -185,6 +188,14 @@ func validateJWT(c *gin.Context, cfg AuthConfig, token string) *models.AuthConte
 		return nil
 	}
 
+	// Reject revoked tokens, and fail closed if the denylist is unavailable
+	if cfg.TokenRevocationService != nil {
+		revoked, err := cfg.TokenRevocationService.IsRevoked(c.Request.Context(), claims)
+		if err != nil || revoked {
+			return nil
+		}
+	}
+
 	// Get user from claims
 	user, err := cfg.AuthService.GetUserByID(c.Request.Context(), claims.UserID)
 	if err != nil {
@@ -200,6 +211,7 @@ func validateJWT(c *gin.Context, cfg AuthConfig, token string) *models.AuthConte
 		User:        user,
 		Permissions: claims.Permissions,
 		IsAPIKey:    false,
+		Claims:      claims,
 	}
 }
 
@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig holds response compression middleware configuration.
+type CompressionConfig struct {
+	// Enabled enables gzip compression of responses.
+	Enabled bool
+
+	// MinSize is the smallest response body, in bytes, that is compressed.
+	MinSize int
+
+	// Level is the gzip compression level (0 uses the default level).
+	Level int
+
+	// ExcludedPaths are path prefixes whose responses are never compressed.
+	ExcludedPaths []string
+}
+
+// DefaultCompressionConfig returns a CompressionConfig with sensible defaults.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Enabled: true,
+		MinSize: 1024,
+		Level:   gzip.DefaultCompression,
+	}
+}
+
+// compressibleTypes are the content types worth compressing.
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/x-yaml",
+	"application/yaml",
+	"text/",
+}
+
+// Compression returns a middleware that gzips responses for clients that
+// accept it. Small responses, non-text content, streaming responses and
+// WebSocket upgrades are passed through unchanged.
+func Compression(cfg CompressionConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	level := cfg.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	pool := sync.Pool{
+		New: func() any {
+			w, err := gzip.NewWriterLevel(nil, level)
+			if err != nil {
+				w = gzip.NewWriter(nil)
+			}
+			return w
+		},
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead ||
+			!acceptsGzip(c.Request) ||
+			isStreamingRequest(c.Request, cfg.ExcludedPaths) {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			pool:           &pool,
+			minSize:        cfg.MinSize,
+			status:         http.StatusOK,
+		}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			// Restore the writer so gin's default 404/405 body is not buffered.
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Header("Vary", "Accept-Encoding")
+		c.Next()
+	}
+}
+
+// compressWriter buffers the start of a response until it can decide whether
+// to compress it.
+type compressWriter struct {
+	gin.ResponseWriter
+
+	pool    *sync.Pool
+	minSize int
+
+	status  int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	decided bool
+	size    int
+}
+
+// WriteHeader records the status code; it is sent once the body is decided.
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow forces the response to be decided and its header sent.
+func (w *compressWriter) WriteHeaderNow() {
+	_ = w.decide(false)
+}
+
+// Write buffers data until MinSize is reached, then compresses.
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes a string.
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends buffered data. A handler that flushes is streaming, so a
+// response still undecided is sent uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Status returns the response status code.
+func (w *compressWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of uncompressed body bytes written.
+func (w *compressWriter) Size() int {
+	return w.size
+}
+
+// Written reports whether the response has been started.
+func (w *compressWriter) Written() bool {
+	return w.decided || w.buf.Len() > 0
+}
+
+// decide sends the header and the buffered body, compressed if allowed.
+func (w *compressWriter) decide(large bool) error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	if large && w.compressible(header) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	} else if w.buf.Len() > 0 && header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// compressible reports whether the response may be compressed.
+func (w *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(contentType, t) && contentType != "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
+
+// finish sends any buffered response and closes the gzip stream.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if w.buf.Len() == 0 && w.status == http.StatusOK {
+			// Nothing was written; let gin send its default response.
+			return
+		}
+		_ = w.decide(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		w.gz.Reset(nil)
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// acceptsGzip reports whether the client accepts gzip-encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.TrimSpace(coding) != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isStreamingRequest reports whether a request is for a streaming response
+// that must not be buffered.
+func isStreamingRequest(r *http.Request, excludedPaths []string) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return true
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	for _, prefix := range excludedPaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// etagContextKey marks requests whose responses the ETag middleware tags.
+const etagContextKey = "etag_enabled"
+
+// ETagConfig holds conditional GET middleware configuration.
+type ETagConfig struct {
+	// Enabled enables ETag generation and If-None-Match handling.
+	Enabled bool
+
+	// MaxSize is the largest response body, in bytes, that is buffered to
+	// compute an ETag. Larger responses are sent without one.
+	MaxSize int
+
+	// ExcludedPaths are path prefixes whose responses never get an ETag.
+	ExcludedPaths []string
+}
+
+// DefaultETagConfig returns an ETagConfig with sensible defaults.
+func DefaultETagConfig() ETagConfig {
+	return ETagConfig{
+		Enabled: true,
+		MaxSize: 8 << 20,
+	}
+}
+
+// ETag returns a middleware that adds an ETag to successful GET responses
+// and answers 304 Not Modified when the client already has the same body.
+// The ETag is a hash of the uncompressed body, so it only changes when the
+// data does. Handlers that set their own ETag are left alone.
+func ETag(cfg ETagConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || isStreamingRequest(c.Request, cfg.ExcludedPaths) {
+			c.Next()
+			return
+		}
+
+		c.Set(etagContextKey, true)
+		w := &etagWriter{
+			ResponseWriter: c.Writer,
+			maxSize:        cfg.MaxSize,
+			status:         http.StatusOK,
+		}
+		c.Writer = w
+		c.Next()
+		w.finish(c.Request)
+
+		// Restore the writer so gin's default 404/405 body is not buffered.
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// NotModified tags the response to a GET of a single resource with an ETag
+// derived from its ID and last update time, and reports whether the
+// client's copy is current. In that case 304 Not Modified has been sent and
+// the handler returns without rendering the resource:
+//
+//	if middleware.NotModified(c, source.ID, source.UpdatedAt) {
+//		return
+//	}
+//
+// Lists are tagged by the ETag middleware with a hash of their body. It
+// reports false when the ETag middleware is not in use for the request.
+func NotModified(c *gin.Context, id uuid.UUID, updatedAt time.Time) bool {
+	if !c.GetBool(etagContextKey) {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(id.String() + "/" + strconv.FormatInt(updatedAt.UnixNano(), 10)))
+	tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", tag)
+
+	if !etagMatches(c.GetHeader("If-None-Match"), tag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// etagWriter buffers a response so its ETag can be computed before the
+// header is sent. It falls back to passing the response through when the
+// body grows past the limit or the handler flushes.
+type etagWriter struct {
+	gin.ResponseWriter
+
+	maxSize     int
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+// WriteHeader records the status code.
+func (w *etagWriter) WriteHeader(code int) {
+	if !w.passthrough {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow sends the response without an ETag.
+func (w *etagWriter) WriteHeaderNow() {
+	_ = w.startPassthrough()
+}
+
+// Write buffers the body.
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if !w.passthrough {
+		if w.buf.Len()+len(data) <= w.maxSize {
+			return w.buf.Write(data)
+		}
+		if err := w.startPassthrough(); err != nil {
+			return 0, err
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes a string.
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends the response without an ETag; a flushing handler is streaming.
+func (w *etagWriter) Flush() {
+	_ = w.startPassthrough()
+	w.ResponseWriter.Flush()
+}
+
+// Status returns the response status code.
+func (w *etagWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of body bytes written.
+func (w *etagWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+// Written reports whether the response has been started.
+func (w *etagWriter) Written() bool {
+	return w.passthrough || w.buf.Len() > 0
+}
+
+// startPassthrough sends the header and buffered body as-is.
+func (w *etagWriter) startPassthrough() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish tags a buffered successful response and answers conditional requests.
+func (w *etagWriter) finish(r *http.Request) {
+	if w.passthrough {
+		return
+	}
+	if w.buf.Len() == 0 && w.status == http.StatusOK {
+		// Nothing was written; let gin send its default response.
+		return
+	}
+
+	header := w.ResponseWriter.Header()
+	if w.status == http.StatusOK {
+		tag := header.Get("ETag")
+		if tag == "" {
+			sum := sha256.Sum256(w.buf.Bytes())
+			tag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", tag)
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), tag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.buf.Reset()
+			w.status = http.StatusNotModified
+		}
+	}
+
+	_ = w.startPassthrough()
+}
+
+// etagMatches reports whether an If-None-Match header matches tag, using the
+// weak comparison RFC 9110 requires for GET.
+func etagMatches(ifNoneMatch, tag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
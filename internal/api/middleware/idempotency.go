@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/services"
+)
+
+// IdempotencyKeyHeader is the header clients send idempotency keys in.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed for a retried
+// request.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength is the longest accepted idempotency key.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyConfig holds idempotency key middleware configuration.
+type IdempotencyConfig struct {
+	// IdempotencyService stores idempotency keys and responses. The
+	// middleware does nothing when it is nil.
+	IdempotencyService *services.IdempotencyService
+
+	// AuthEnabled scopes keys to the authenticated user. Keys sent with
+	// unauthenticated requests are then ignored; with auth disabled all
+	// requests share one scope per tenant.
+	AuthEnabled bool
+
+	// MaxResponseSize is the largest response body, in bytes, that is
+	// stored for replay. The keys of larger responses are released.
+	// Defaults to 1 MiB if not set.
+	MaxResponseSize int
+}
+
+// Idempotency returns a middleware that makes POST requests with an
+// Idempotency-Key header safe to retry. The first request with a key is
+// executed and its response stored; retries with the same key and body get
+// the stored response, and reuse of the key for a different request is
+// rejected with 409 Conflict. Keys are scoped per user and tenant. Server
+// errors are not stored, so those requests can be retried with the same key.
+// Must be used after Authenticate.
+func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.IdempotencyService == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	maxResponseSize := cfg.MaxResponseSize
+	if maxResponseSize <= 0 {
+		maxResponseSize = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"Idempotency-Key must be at most 255 characters",
+			))
+			c.Abort()
+			return
+		}
+
+		scope, ok := idempotencyScope(c, cfg.AuthEnabled)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			models.RespondWithError(c, models.NewBadRequestError(
+				c.Request.URL.Path,
+				"failed to read request body",
+			))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := cfg.IdempotencyService.Begin(c.Request.Context(), scope, key, requestHash(c.Request, body))
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyReused), errors.Is(err, services.ErrIdempotencyKeyInProgress):
+			models.RespondWithError(c, models.NewConflictError(c.Request.URL.Path, err.Error()))
+			c.Abort()
+			return
+		case err != nil:
+			models.RespondWithError(c, models.NewInternalError(
+				c.Request.URL.Path,
+				"failed to check idempotency key",
+			))
+			c.Abort()
+			return
+		case record != nil:
+			replayResponse(c, record)
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer, maxSize: maxResponseSize}
+		c.Writer = w
+
+		// The key is released unless the response is stored, also when the
+		// handler panics. Both outlive a disconnected client.
+		ctx := context.WithoutCancel(c.Request.Context())
+		stored := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if !stored {
+				_ = cfg.IdempotencyService.Release(ctx, scope, key)
+			}
+		}()
+
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError || w.skip {
+			return
+		}
+		err = cfg.IdempotencyService.Complete(ctx, scope, key, w.Status(), w.Header().Get("Content-Type"), w.buf.Bytes())
+		stored = err == nil
+	}
+}
+
+// idempotencyScope returns the scope of a request's idempotency keys: its
+// user and tenant. It reports false when keys cannot be scoped to a user.
+func idempotencyScope(c *gin.Context, authEnabled bool) (string, bool) {
+	scope := "anonymous"
+	if authContext := GetAuthContext(c); authContext != nil && authContext.User != nil {
+		scope = "user:" + authContext.User.ID.String()
+	} else if authEnabled {
+		return "", false
+	}
+
+	if tenantID, ok := GetTenantID(c); ok {
+		scope += "/tenant:" + tenantID.String()
+	} else if tenantID, err := uuid.Parse(c.GetHeader("X-Tenant-ID")); err == nil {
+		scope += "/tenant:" + tenantID.String()
+	}
+	return scope, true
+}
+
+// requestHash hashes the method, URI and body of a request.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayResponse writes the stored response of a completed request.
+func replayResponse(c *gin.Context, record *models.IdempotencyRecord) {
+	if record.ContentType != "" {
+		c.Header("Content-Type", record.ContentType)
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(record.StatusCode)
+	if len(record.ResponseBody) > 0 {
+		_, _ = c.Writer.Write(record.ResponseBody)
+	}
+	c.Abort()
+}
+
+// idempotencyWriter passes a response through while keeping a copy of its
+// body for replay. Responses that grow past the limit or are flushed, such
+// as streams, are not kept.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+
+	maxSize int
+	buf     bytes.Buffer
+	skip    bool
+}
+
+// Write writes and keeps a copy of the body.
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes and keeps a copy of the body.
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush marks the response as streamed.
+func (w *idempotencyWriter) Flush() {
+	w.skip = true
+	w.ResponseWriter.Flush()
+}
+
+func (w *idempotencyWriter) keep(data []byte) {
+	if w.skip {
+		return
+	}
+	if w.buf.Len()+len(data) > w.maxSize {
+		w.skip = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(data)
+}
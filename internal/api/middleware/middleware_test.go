@@ -1,13 +1,26 @@
 package middleware
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/api/services"
 )
 
 func init() {
@@ -106,6 +119,187 @@ func TestRateLimiter_AllowsRequests(t *testing.T) {
 	}
 }
 
+func TestRateLimit_SetLimits(t *testing.T) {
+	for _, perClient := range []bool{false, true} {
+		limit := NewRateLimit(RateLimitConfig{
+			RequestsPerSecond: 0.001,
+			BurstSize:         1,
+			PerClient:         perClient,
+		})
+
+		router := gin.New()
+		router.Use(limit.Handler())
+		router.GET("/test", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+
+		serve := func() int {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.168.1.1:12345"
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		if code := serve(); code != http.StatusOK {
+			t.Fatalf("perClient=%v: first request status = %d, want %d", perClient, code, http.StatusOK)
+		}
+		if code := serve(); code != http.StatusTooManyRequests {
+			t.Fatalf("perClient=%v: second request status = %d, want %d", perClient, code, http.StatusTooManyRequests)
+		}
+
+		limit.SetLimits(1000, 10)
+		time.Sleep(10 * time.Millisecond)
+		if code := serve(); code != http.StatusOK {
+			t.Errorf("perClient=%v: status after raising the limit = %d, want %d", perClient, code, http.StatusOK)
+		}
+	}
+}
+
+// testAPIKeys resolves API keys for rate limit tests and counts lookups.
+type testAPIKeys struct {
+	keys    map[string]uuid.UUID
+	limits  map[string]*models.APIKeyRateLimit
+	lookups int
+}
+
+func (k *testAPIKeys) resolve(_ context.Context, key string) (uuid.UUID, *models.APIKeyRateLimit, error) {
+	k.lookups++
+	id, ok := k.keys[key]
+	if !ok {
+		return uuid.Nil, nil, errors.New("api key not found")
+	}
+	return id, k.limits[key], nil
+}
+
+// newRateLimitRouter serves any path behind a rate limit.
+func newRateLimitRouter(cfg RateLimitConfig) *gin.Engine {
+	router := gin.New()
+	router.Use(RateLimiter(cfg))
+	router.NoRoute(func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+// serveRateLimited serves a request from 192.168.1.1 with an optional API
+// key.
+func serveRateLimited(router *gin.Engine, path, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_OverridePrecedence(t *testing.T) {
+	apiKeys := &testAPIKeys{
+		keys:   map[string]uuid.UUID{"pk_live_a": uuid.New()},
+		limits: map[string]*models.APIKeyRateLimit{"pk_live_a": {RequestsPerSecond: 0.001, BurstSize: 3}},
+	}
+	router := newRateLimitRouter(RateLimitConfig{
+		RequestsPerSecond: 1000,
+		BurstSize:         100,
+		PerClient:         true,
+		Overrides: []RateLimitOverride{
+			{PathPrefix: "/api/v1", RequestsPerSecond: 0.001, BurstSize: 2},
+			{PathPrefix: "/api/v1/query/", RequestsPerSecond: 0.001, BurstSize: 1},
+		},
+		APIKeyLimits: apiKeys.resolve,
+	})
+
+	// allowed serves requests until one is rate limited.
+	allowed := func(path, apiKey string) int {
+		for n := 0; n < 10; n++ {
+			if w := serveRateLimited(router, path, apiKey); w.Code == http.StatusTooManyRequests {
+				return n
+			}
+		}
+		return 10
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		apiKey string
+		want   int
+	}{
+		{"longest prefix wins", "/api/v1/query/run", "", 1},
+		{"route override wins over key limit", "/api/v1/query", "pk_live_a", 1},
+		{"prefix matches whole segments", "/api/v1/queryx", "", 2},
+		{"key limit wins over global limit", "/health", "pk_live_a", 3},
+		{"global limit", "/health", "", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowed(tt.path, tt.apiKey); got != tt.want {
+				t.Errorf("allowed requests = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit_PerAPIKeyIsolation(t *testing.T) {
+	apiKeys := &testAPIKeys{
+		keys: map[string]uuid.UUID{"pk_live_a": uuid.New(), "pk_live_b": uuid.New()},
+		limits: map[string]*models.APIKeyRateLimit{
+			"pk_live_a": {RequestsPerSecond: 0.5, BurstSize: 1},
+			"pk_live_b": {RequestsPerSecond: 0.5, BurstSize: 1},
+		},
+	}
+	router := newRateLimitRouter(RateLimitConfig{
+		RequestsPerSecond: 0.001,
+		BurstSize:         1,
+		PerClient:         true,
+		APIKeyLimits:      apiKeys.resolve,
+	})
+
+	if w := serveRateLimited(router, "/test", "pk_live_a"); w.Code != http.StatusOK {
+		t.Fatalf("first request with key a status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	w := serveRateLimited(router, "/test", "pk_live_a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request with key a status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "0.5" {
+		t.Errorf("X-RateLimit-Limit = %q, want %q", got, "0.5")
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want %q", got, "0")
+	}
+	if got := w.Header().Get("X-RateLimit-Reset"); got != "2" {
+		t.Errorf("X-RateLimit-Reset = %q, want %q", got, "2")
+	}
+
+	// Another key from the same client has its own limit.
+	if w := serveRateLimited(router, "/test", "pk_live_b"); w.Code != http.StatusOK {
+		t.Errorf("request with key b status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// Unknown keys share the limit of the client.
+	if w := serveRateLimited(router, "/test", "pk_live_unknown"); w.Code != http.StatusOK {
+		t.Errorf("first request with an unknown key status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := serveRateLimited(router, "/test", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("request without a key status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := serveRateLimited(router, "/test", "pk_live_unknown"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request with an unknown key status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	if apiKeys.lookups != 3 {
+		t.Errorf("api key lookups = %d, want one per key", apiKeys.lookups)
+	}
+}
+
 func TestLogger_LogsRequest(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelError,
@@ -210,3 +404,478 @@ func TestRateLimiter_PerClient(t *testing.T) {
 		t.Errorf("expected X-RateLimit-Limit '100', got '%s'", rateLimitHeader)
 	}
 }
+
+// newConditionalRouter serves a list whose contents the test can change.
+func newConditionalRouter(items *[]string) *gin.Engine {
+	router := gin.New()
+	router.Use(Compression(DefaultCompressionConfig()))
+	router.Use(ETag(DefaultETagConfig()))
+	router.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": *items})
+	})
+	router.GET("/events", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString(strings.Repeat("data: tick\n\n", 200))
+	})
+	return router
+}
+
+func TestETag_NotModified(t *testing.T) {
+	items := []string{"orders", "customers"}
+	router := newConditionalRouter(&items)
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d and %q", w.Code, etag)
+	}
+
+	// Unchanged data returns 304 with no body
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag %q on 304, got %q", etag, got)
+	}
+
+	// Changed data returns 200 with a new ETag
+	items = append(items, "invoices")
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d after change, got %d", http.StatusOK, w.Code)
+	}
+	if got := w.Header().Get("ETag"); got == "" || got == etag {
+		t.Errorf("expected a new ETag after change, got %q (was %q)", got, etag)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	id := uuid.New()
+	updatedAt := time.Now()
+	renders := 0
+
+	newRouter := func(cfg ETagConfig) *gin.Engine {
+		router := gin.New()
+		router.Use(ETag(cfg))
+		router.GET("/sources/:id", func(c *gin.Context) {
+			if NotModified(c, id, updatedAt) {
+				return
+			}
+			renders++
+			c.JSON(http.StatusOK, gin.H{"id": id, "updated_at": updatedAt})
+		})
+		return router
+	}
+	router := newRouter(DefaultETagConfig())
+
+	serve := func(router *gin.Engine, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/sources/"+id.String(), nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(router, "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d and %q", w.Code, etag)
+	}
+
+	w = serve(router, etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected an empty 304, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag %q on 304, got %q", etag, got)
+	}
+	if renders != 1 {
+		t.Errorf("expected the resource to be rendered once, got %d", renders)
+	}
+
+	// An update changes the ETag
+	updatedAt = updatedAt.Add(time.Second)
+	w = serve(router, etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("expected 200 with a new ETag after an update, got %d and %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// Without the ETag middleware responses are not tagged
+	w = serve(newRouter(ETagConfig{Enabled: false}), etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("expected 200 without an ETag when disabled, got %d and %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestETag_IgnoresNonGet(t *testing.T) {
+	router := gin.New()
+	router.Use(ETag(DefaultETagConfig()))
+	router.POST("/items", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/items", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if got := w.Header().Get("ETag"); got != "" {
+		t.Errorf("expected no ETag on POST, got %q", got)
+	}
+}
+
+func TestETag_UnmatchedRouteKeepsNotFound(t *testing.T) {
+	items := []string{"orders"}
+	router := newConditionalRouter(&items)
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if got := w.Body.String(); got != "404 page not found" {
+		t.Errorf("expected gin's default 404 body, got %q", got)
+	}
+}
+
+func TestCompression_GzipsLargeResponses(t *testing.T) {
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = "table_name"
+	}
+	router := newConditionalRouter(&items)
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip Content-Encoding, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+	}
+
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+
+	var decoded struct {
+		Items []string `json:"items"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(decoded.Items) != len(items) {
+		t.Errorf("expected %d items, got %d", len(items), len(decoded.Items))
+	}
+}
+
+func TestCompression_SkipsSmallAndStreamingResponses(t *testing.T) {
+	items := []string{"orders"}
+	router := newConditionalRouter(&items)
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{name: "small response", path: "/items"},
+		{name: "event stream", path: "/events", accept: "text/event-stream"},
+		{name: "flushed response", path: "/events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("expected no Content-Encoding, got %q", got)
+			}
+		})
+	}
+}
+
+func TestCompression_NotAccepted(t *testing.T) {
+	items := make([]string, 200)
+	router := newConditionalRouter(&items)
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no Content-Encoding, got %q", got)
+	}
+	if !json.Valid(w.Body.Bytes()) {
+		t.Error("expected an uncompressed JSON body")
+	}
+}
+
+// fakeIdempotencyStore keeps idempotency keys in memory.
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyStore) Reserve(_ context.Context, scope, key, requestHash string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if record, ok := f.records[scope+" "+key]; ok && record.ExpiresAt.After(now) {
+		return record, false, nil
+	}
+	record := &models.IdempotencyRecord{Scope: scope, Key: key, RequestHash: requestHash, CreatedAt: now, ExpiresAt: expiresAt}
+	f.records[scope+" "+key] = record
+	return record, true, nil
+}
+
+func (f *fakeIdempotencyStore) Complete(_ context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record := f.records[scope+" "+key]
+	record.StatusCode, record.ContentType, record.ResponseBody = statusCode, contentType, body
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(_ context.Context, scope, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if record, ok := f.records[scope+" "+key]; ok && !record.Completed() {
+		delete(f.records, scope+" "+key)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyStore) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newIdempotencyRouter serves POST /deployments, failing with 500 while
+// fail is set, behind the idempotency middleware. The user is taken from
+// the X-User-ID header.
+func newIdempotencyRouter(calls *int, fail *bool) *gin.Engine {
+	store := &fakeIdempotencyStore{records: make(map[string]*models.IdempotencyRecord)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if id, err := uuid.Parse(c.GetHeader("X-User-ID")); err == nil {
+			c.Set(AuthContextKey, &models.AuthContext{User: &models.User{ID: id}})
+		}
+	})
+	router.Use(Idempotency(IdempotencyConfig{
+		IdempotencyService: services.NewIdempotencyService(store, time.Hour, logger),
+		AuthEnabled:        true,
+	}))
+	router.POST("/deployments", func(c *gin.Context) {
+		*calls++
+		if *fail {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "provider unavailable"})
+			return
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusCreated, gin.H{"deployment": *calls, "request": string(body)})
+	})
+	return router
+}
+
+func serveIdempotent(router *gin.Engine, key, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/deployments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysResponse(t *testing.T) {
+	var calls int
+	var fail bool
+	router := newIdempotencyRouter(&calls, &fail)
+	user := uuid.NewString()
+
+	first := serveIdempotent(router, "key-1", user, `{"name":"prod"}`)
+	retry := serveIdempotent(router, "key-1", user, `{"name":"prod"}`)
+
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want the stored %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if got := retry.Header().Get("Content-Type"); got != first.Header().Get("Content-Type") {
+		t.Errorf("retry Content-Type = %q, want %q", got, first.Header().Get("Content-Type"))
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("%s header not set on the replayed response only", IdempotentReplayedHeader)
+	}
+
+	if w := serveIdempotent(router, "key-1", user, `{"name":"staging"}`); w.Code != http.StatusConflict {
+		t.Errorf("reuse with a different body status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if calls != 1 {
+		t.Errorf("handler calls = %d after rejected reuse, want 1", calls)
+	}
+}
+
+func TestIdempotency_Scope(t *testing.T) {
+	var calls int
+	var fail bool
+	router := newIdempotencyRouter(&calls, &fail)
+
+	serveIdempotent(router, "key-1", uuid.NewString(), `{}`)
+	serveIdempotent(router, "key-1", uuid.NewString(), `{}`)
+	if calls != 2 {
+		t.Errorf("handler calls for two users = %d, want 2", calls)
+	}
+
+	// Unauthenticated requests and requests without a key are not deduplicated.
+	serveIdempotent(router, "key-1", "", `{}`)
+	serveIdempotent(router, "key-1", "", `{}`)
+	serveIdempotent(router, "", uuid.NewString(), `{}`)
+	if calls != 5 {
+		t.Errorf("handler calls = %d, want 5", calls)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreRetried(t *testing.T) {
+	var calls int
+	fail := true
+	router := newIdempotencyRouter(&calls, &fail)
+	user := uuid.NewString()
+
+	if w := serveIdempotent(router, "key-1", user, `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	fail = false
+	if w := serveIdempotent(router, "key-1", user, `{}`); w.Code != http.StatusCreated {
+		t.Errorf("retry status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
+
+type fakeAuditLogStore struct {
+	logs chan *models.AuditLog
+}
+
+func (f *fakeAuditLogStore) Create(_ context.Context, log *models.AuditLog) error {
+	f.logs <- log
+	return nil
+}
+
+func (f *fakeAuditLogStore) List(context.Context, repositories.AuditListOptions) ([]models.AuditLog, int, error) {
+	return nil, 0, nil
+}
+
+func TestAudit_RecordsMutatingRequests(t *testing.T) {
+	store := &fakeAuditLogStore{logs: make(chan *models.AuditLog, 4)}
+	userID, tenantID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(AuthContextKey, &models.AuthContext{User: &models.User{ID: userID}})
+	})
+	router.Use(Audit(AuditConfig{
+		AuditService: services.NewAuditService(store, slog.New(slog.NewTextHandler(io.Discard, nil))),
+		SkipRoutes:   []string{"/api/v1/metrics/write"},
+	}))
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	}
+	router.GET("/api/v1/tenants/:id/members/:user_id", handler)
+	router.PUT("/api/v1/tenants/:id/members/:user_id", handler)
+	router.POST("/api/v1/metrics/write", handler)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	path := "/api/v1/tenants/" + tenantID.String() + "/members/" + memberID.String()
+	serve(http.MethodGet, path, "")
+	serve(http.MethodPost, "/api/v1/metrics/write", "{}")
+	serve(http.MethodPost, "/api/v1/unknown", "{}")
+	body := `{"role":"admin","password":"hunter2"}`
+	if w := serve(http.MethodPut, path, body); w.Body.String() != body {
+		t.Errorf("handler read body %q, want the request body", w.Body.String())
+	}
+
+	var log *models.AuditLog
+	select {
+	case log = <-store.logs:
+	case <-time.After(time.Second):
+		t.Fatal("no audit log recorded")
+	}
+	if log.Action != "PUT /tenants/:id/members/:user_id" {
+		t.Errorf("Action = %q, want the method and route", log.Action)
+	}
+	if log.ResourceType != "members" || log.ResourceID == nil || *log.ResourceID != memberID {
+		t.Errorf("resource = %s %v, want the member", log.ResourceType, log.ResourceID)
+	}
+	if log.TenantID == nil || *log.TenantID != tenantID || log.UserID == nil || *log.UserID != userID {
+		t.Errorf("tenant, user = %v, %v, want %s, %s", log.TenantID, log.UserID, tenantID, userID)
+	}
+	if log.Details["status"] != http.StatusOK {
+		t.Errorf("status = %v, want %d", log.Details["status"], http.StatusOK)
+	}
+	if got, _ := json.Marshal(log.Details["body"]); string(got) != `{"password":"[REDACTED]","role":"admin"}` {
+		t.Errorf("body = %s, want the password redacted", got)
+	}
+
+	select {
+	case log = <-store.logs:
+		t.Errorf("unexpected audit log for %s", log.Action)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
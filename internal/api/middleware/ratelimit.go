@@ -1,16 +1,31 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 
 	"github.com/janovincze/philotes/internal/api/models"
 )
 
+// apiKeyCacheTTL is how long a resolved API key and its limit are cached.
+// Limit changes take effect on running limiters within this time.
+const apiKeyCacheTTL = time.Minute
+
+// maxAPIKeyCacheEntries bounds the API key cache so that requests with
+// random keys cannot grow it without limit.
+const maxAPIKeyCacheEntries = 10000
+
 // RateLimitConfig holds rate limiter configuration.
 type RateLimitConfig struct {
 	// RequestsPerSecond is the rate limit in requests per second.
@@ -29,8 +44,38 @@ type RateLimitConfig struct {
 	// CleanupInterval is how often to run the cleanup routine.
 	// Defaults to 10 minutes if not set.
 	CleanupInterval time.Duration
+
+	// Overrides replace the global limit on route groups. The longest
+	// matching prefix applies, also to requests made with an API key.
+	Overrides []RateLimitOverride
+
+	// APIKeyLimits resolves the API key of a request to its ID and limit.
+	// When set, requests made with a valid key are limited per key, at the
+	// key's limit or else the global one; invalid keys are limited like
+	// requests without a key.
+	APIKeyLimits APIKeyLimitFunc
+
+	// APIKeyPrefix is the prefix of API keys sent as bearer tokens.
+	// Defaults to "pk_" if not set.
+	APIKeyPrefix string
+}
+
+// RateLimitOverride is the rate limit of a route group.
+type RateLimitOverride struct {
+	// PathPrefix is the path of the route group, e.g. "/api/v1/query".
+	PathPrefix string
+
+	// RequestsPerSecond is the rate limit in requests per second.
+	RequestsPerSecond float64
+
+	// BurstSize is the maximum burst size.
+	BurstSize int
 }
 
+// APIKeyLimitFunc resolves a plaintext API key to its ID and rate limit.
+// A nil limit means the key uses the global limit.
+type APIKeyLimitFunc func(ctx context.Context, key string) (uuid.UUID, *models.APIKeyRateLimit, error)
+
 // DefaultRateLimitConfig returns a RateLimitConfig with sensible defaults.
 func DefaultRateLimitConfig() RateLimitConfig {
 	return RateLimitConfig{
@@ -44,33 +89,196 @@ func DefaultRateLimitConfig() RateLimitConfig {
 
 // RateLimiter returns a middleware that limits request rate.
 func RateLimiter(cfg RateLimitConfig) gin.HandlerFunc {
-	if cfg.PerClient {
-		return perClientRateLimiter(cfg)
-	}
-	return globalRateLimiter(cfg)
+	return NewRateLimit(cfg).Handler()
 }
 
-// globalRateLimiter creates a single limiter for all requests.
-func globalRateLimiter(cfg RateLimitConfig) gin.HandlerFunc {
-	limiter := rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.BurstSize)
+// RateLimit limits the request rate. Its global limits can be changed while
+// it serves requests.
+type RateLimit struct {
+	store        *rateLimiterStore
+	perClient    bool
+	overrides    []RateLimitOverride // longest prefix first
+	apiKeyLimits APIKeyLimitFunc
+	apiKeyPrefix string
+}
 
+// NewRateLimit creates a rate limit and starts the cleanup of inactive
+// clients.
+func NewRateLimit(cfg RateLimitConfig) *RateLimit {
+	// Set defaults if not configured
+	clientTTL := cfg.ClientTTL
+	if clientTTL == 0 {
+		clientTTL = time.Hour
+	}
+	cleanupInterval := cfg.CleanupInterval
+	if cleanupInterval == 0 {
+		cleanupInterval = 10 * time.Minute
+	}
+	apiKeyPrefix := cfg.APIKeyPrefix
+	if apiKeyPrefix == "" {
+		apiKeyPrefix = "pk_"
+	}
+
+	overrides := make([]RateLimitOverride, 0, len(cfg.Overrides))
+	for _, o := range cfg.Overrides {
+		o.PathPrefix = strings.TrimSuffix(o.PathPrefix, "/")
+		overrides = append(overrides, o)
+	}
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return len(overrides[i].PathPrefix) > len(overrides[j].PathPrefix)
+	})
+
+	// Create store for this rate limiter instance
+	store := &rateLimiterStore{
+		limiters: make(map[string]*clientLimiter),
+		apiKeys:  make(map[string]apiKeyCacheEntry),
+		ttl:      clientTTL,
+		interval: cleanupInterval,
+		rps:      cfg.RequestsPerSecond,
+		burst:    cfg.BurstSize,
+	}
+
+	// Start cleanup goroutine (only once per store instance)
+	store.startCleanup()
+
+	return &RateLimit{
+		store:        store,
+		perClient:    cfg.PerClient,
+		overrides:    overrides,
+		apiKeyLimits: cfg.APIKeyLimits,
+		apiKeyPrefix: apiKeyPrefix,
+	}
+}
+
+// Handler returns the middleware.
+func (r *RateLimit) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
+		bucket, l := r.bucket(c)
+		limiter, rps, burst := r.store.getOrCreateLimiter(bucket, l)
+
+		now := time.Now()
+		reservation := limiter.ReserveN(now, 1)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			retryAfter := time.Second
+			if reservation.OK() {
+				retryAfter = max(reservation.DelayFrom(now), time.Second)
+				reservation.CancelAt(now)
+			}
+
+			c.Header("Retry-After", formatSeconds(retryAfter))
+			c.Header("X-RateLimit-Limit", formatFloat(rps))
+			c.Header("X-RateLimit-Remaining", "0")
+			if rps > 0 {
+				// Seconds until the bucket is full again
+				c.Header("X-RateLimit-Reset", formatSeconds(time.Duration(float64(burst)/rps*float64(time.Second))))
+			}
 			models.RespondWithError(c, models.NewRateLimitedError(c.Request.URL.Path))
 			c.Abort()
 			return
 		}
+
+		c.Header("X-RateLimit-Limit", formatFloat(rps))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(max(int(limiter.TokensAt(now)), 0)))
 		c.Next()
 	}
 }
 
+// SetLimits changes the global rate and burst size, including those of
+// clients already seen. Route and API key limits are kept.
+func (r *RateLimit) SetLimits(rps float64, burst int) {
+	r.store.setLimits(rps, burst)
+}
+
+// bucket returns the limiter key of a request and its limit, nil for the
+// global limit. Route overrides take precedence over API key limits.
+func (r *RateLimit) bucket(c *gin.Context) (string, *limit) {
+	client := ""
+	if r.perClient {
+		client = "ip:" + c.ClientIP()
+	}
+
+	var keyLimit *limit
+	if id, apiKeyLimit, ok := r.resolveAPIKey(c); ok {
+		client = "key:" + id.String()
+		if apiKeyLimit != nil {
+			keyLimit = &limit{rps: apiKeyLimit.RequestsPerSecond, burst: apiKeyLimit.BurstSize}
+		}
+	}
+
+	if o := r.override(c.Request.URL.Path); o != nil {
+		return client + " " + o.PathPrefix, &limit{rps: o.RequestsPerSecond, burst: o.BurstSize}
+	}
+	return client, keyLimit
+}
+
+// override returns the override of the longest route group prefix that
+// contains the path, or nil.
+func (r *RateLimit) override(path string) *RateLimitOverride {
+	for i := range r.overrides {
+		prefix := r.overrides[i].PathPrefix
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return &r.overrides[i]
+		}
+	}
+	return nil
+}
+
+// resolveAPIKey returns the ID and limit of the API key a request is made
+// with. Results, including invalid keys, are cached for apiKeyCacheTTL.
+func (r *RateLimit) resolveAPIKey(c *gin.Context) (uuid.UUID, *models.APIKeyRateLimit, bool) {
+	if r.apiKeyLimits == nil {
+		return uuid.Nil, nil, false
+	}
+
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "bearer") || !strings.HasPrefix(credential, r.apiKeyPrefix) {
+			return uuid.Nil, nil, false
+		}
+		key = credential
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	cacheKey := hex.EncodeToString(hash[:])
+	if entry, ok := r.store.cachedAPIKey(cacheKey); ok {
+		return entry.id, entry.limit, entry.id != uuid.Nil
+	}
+
+	id, apiKeyLimit, err := r.apiKeyLimits(c.Request.Context(), key)
+	if err != nil {
+		id, apiKeyLimit = uuid.Nil, nil
+	}
+	r.store.cacheAPIKey(cacheKey, apiKeyCacheEntry{
+		id:      id,
+		limit:   apiKeyLimit,
+		expires: time.Now().Add(apiKeyCacheTTL),
+	})
+	return id, apiKeyLimit, id != uuid.Nil
+}
+
+// limit is a rate limit other than the global one.
+type limit struct {
+	rps   float64
+	burst int
+}
+
 // clientLimiter holds a rate limiter and its last access time.
 type clientLimiter struct {
 	limiter    *rate.Limiter
 	lastAccess time.Time
+	global     bool // whether the limiter uses the global limit
 }
 
-// rateLimiterStore holds the shared state for per-client rate limiting.
+// apiKeyCacheEntry is a resolved API key. The ID is uuid.Nil for keys that
+// did not resolve.
+type apiKeyCacheEntry struct {
+	id      uuid.UUID
+	limit   *models.APIKeyRateLimit
+	expires time.Time
+}
+
+// rateLimiterStore holds the shared state for rate limiting.
 // Using a singleton pattern ensures only one cleanup goroutine runs globally.
 type rateLimiterStore struct {
 	mu       sync.Mutex
@@ -78,19 +286,33 @@ type rateLimiterStore struct {
 	once     sync.Once
 	ttl      time.Duration
 	interval time.Duration
+	rps      float64
+	burst    int
+
+	apiKeysMu sync.Mutex
+	apiKeys   map[string]apiKeyCacheEntry
 }
 
-// cleanup runs periodically to remove stale client limiters.
+// cleanup runs periodically to remove stale client limiters and expired
+// API keys.
 func (s *rateLimiterStore) cleanup() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	now := time.Now()
-	for ip, cl := range s.limiters {
+
+	s.mu.Lock()
+	for key, cl := range s.limiters {
 		if now.Sub(cl.lastAccess) > s.ttl {
-			delete(s.limiters, ip)
+			delete(s.limiters, key)
 		}
 	}
+	s.mu.Unlock()
+
+	s.apiKeysMu.Lock()
+	for key, entry := range s.apiKeys {
+		if now.After(entry.expires) {
+			delete(s.apiKeys, key)
+		}
+	}
+	s.apiKeysMu.Unlock()
 }
 
 // startCleanup starts the cleanup goroutine exactly once.
@@ -107,65 +329,74 @@ func (s *rateLimiterStore) startCleanup() {
 	})
 }
 
-// getOrCreateLimiter returns the limiter for a client IP, creating one if needed.
-func (s *rateLimiterStore) getOrCreateLimiter(clientIP string, rps float64, burst int) *rate.Limiter {
+// setLimits changes the global limit of new and existing limiters.
+func (s *rateLimiterStore) setLimits(rps float64, burst int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now()
-	cl, exists := s.limiters[clientIP]
-	if !exists {
-		cl = &clientLimiter{
-			limiter:    rate.NewLimiter(rate.Limit(rps), burst),
-			lastAccess: now,
+	s.rps, s.burst = rps, burst
+	for _, cl := range s.limiters {
+		if cl.global {
+			cl.limiter.SetLimit(rate.Limit(rps))
+			cl.limiter.SetBurst(burst)
 		}
-		s.limiters[clientIP] = cl
-	} else {
-		cl.lastAccess = now
 	}
-	return cl.limiter
 }
 
-// perClientRateLimiter creates a limiter per client IP with automatic cleanup.
-func perClientRateLimiter(cfg RateLimitConfig) gin.HandlerFunc {
-	// Set defaults if not configured
-	clientTTL := cfg.ClientTTL
-	if clientTTL == 0 {
-		clientTTL = time.Hour
-	}
-	cleanupInterval := cfg.CleanupInterval
-	if cleanupInterval == 0 {
-		cleanupInterval = 10 * time.Minute
+// getOrCreateLimiter returns the limiter for a key, creating one if needed,
+// and its rate and burst size. A nil limit selects the global limit; an
+// existing limiter is updated when its limit has changed.
+func (s *rateLimiterStore) getOrCreateLimiter(key string, l *limit) (*rate.Limiter, float64, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rps, burst := s.rps, s.burst
+	if l != nil {
+		rps, burst = l.rps, l.burst
 	}
 
-	// Create store for this rate limiter instance
-	store := &rateLimiterStore{
-		limiters: make(map[string]*clientLimiter),
-		ttl:      clientTTL,
-		interval: cleanupInterval,
+	now := time.Now()
+	cl, exists := s.limiters[key]
+	if !exists {
+		cl = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+		s.limiters[key] = cl
+	} else if cl.limiter.Limit() != rate.Limit(rps) || cl.limiter.Burst() != burst {
+		cl.limiter.SetLimit(rate.Limit(rps))
+		cl.limiter.SetBurst(burst)
 	}
+	cl.lastAccess = now
+	cl.global = l == nil
+	return cl.limiter, rps, burst
+}
 
-	// Start cleanup goroutine (only once per store instance)
-	store.startCleanup()
+// cachedAPIKey returns an unexpired cached API key.
+func (s *rateLimiterStore) cachedAPIKey(key string) (apiKeyCacheEntry, bool) {
+	s.apiKeysMu.Lock()
+	defer s.apiKeysMu.Unlock()
 
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		limiter := store.getOrCreateLimiter(clientIP, cfg.RequestsPerSecond, cfg.BurstSize)
+	entry, ok := s.apiKeys[key]
+	if !ok || time.Now().After(entry.expires) {
+		return apiKeyCacheEntry{}, false
+	}
+	return entry, true
+}
 
-		if !limiter.Allow() {
-			c.Header("Retry-After", "1")
-			c.Header("X-RateLimit-Limit", formatFloat(cfg.RequestsPerSecond))
-			c.Header("X-RateLimit-Remaining", "0")
-			models.RespondWithError(c, models.NewRateLimitedError(c.Request.URL.Path))
-			c.Abort()
-			return
-		}
+// cacheAPIKey caches a resolved API key unless the cache is full.
+func (s *rateLimiterStore) cacheAPIKey(key string, entry apiKeyCacheEntry) {
+	s.apiKeysMu.Lock()
+	defer s.apiKeysMu.Unlock()
 
-		c.Header("X-RateLimit-Limit", formatFloat(cfg.RequestsPerSecond))
-		c.Next()
+	if _, exists := s.apiKeys[key]; !exists && len(s.apiKeys) >= maxAPIKeyCacheEntries {
+		return
 	}
+	s.apiKeys[key] = entry
 }
 
 func formatFloat(f float64) string {
-	return fmt.Sprintf("%.0f", f)
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatSeconds formats a duration as whole seconds, rounded up.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
 }
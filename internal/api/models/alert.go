@@ -2,6 +2,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,33 +12,39 @@ import (
 
 // AlertRule represents an alert rule in API responses.
 type AlertRule struct {
-	ID              uuid.UUID              `json:"id"`
-	Name            string                 `json:"name"`
-	Description     string                 `json:"description,omitempty"`
-	MetricName      string                 `json:"metric_name"`
-	Operator        alerting.Operator      `json:"operator"`
-	Threshold       float64                `json:"threshold"`
-	DurationSeconds int                    `json:"duration_seconds"`
-	Severity        alerting.AlertSeverity `json:"severity"`
-	Labels          map[string]string      `json:"labels,omitempty"`
-	Annotations     map[string]string      `json:"annotations,omitempty"`
-	Enabled         bool                   `json:"enabled"`
-	CreatedAt       time.Time              `json:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at"`
+	ID                             uuid.UUID              `json:"id"`
+	Name                           string                 `json:"name"`
+	Description                    string                 `json:"description,omitempty"`
+	MetricName                     string                 `json:"metric_name"`
+	Operator                       alerting.Operator      `json:"operator"`
+	Threshold                      float64                `json:"threshold"`
+	Expression                     string                 `json:"expression,omitempty"`
+	DurationSeconds                int                    `json:"duration_seconds"`
+	MinNotificationIntervalSeconds int                    `json:"min_notification_interval_seconds"`
+	Source                         alerting.MetricSource  `json:"source,omitempty"`
+	Severity                       alerting.AlertSeverity `json:"severity"`
+	Labels                         map[string]string      `json:"labels,omitempty"`
+	Annotations                    map[string]string      `json:"annotations,omitempty"`
+	Enabled                        bool                   `json:"enabled"`
+	CreatedAt                      time.Time              `json:"created_at"`
+	UpdatedAt                      time.Time              `json:"updated_at"`
 }
 
 // CreateAlertRuleRequest represents a request to create an alert rule.
 type CreateAlertRuleRequest struct {
-	Name            string                 `json:"name" binding:"required,min=1,max=255"`
-	Description     string                 `json:"description,omitempty"`
-	MetricName      string                 `json:"metric_name" binding:"required"`
-	Operator        alerting.Operator      `json:"operator" binding:"required"`
-	Threshold       float64                `json:"threshold"`
-	DurationSeconds int                    `json:"duration_seconds,omitempty"`
-	Severity        alerting.AlertSeverity `json:"severity,omitempty"`
-	Labels          map[string]string      `json:"labels,omitempty"`
-	Annotations     map[string]string      `json:"annotations,omitempty"`
-	Enabled         *bool                  `json:"enabled,omitempty"`
+	Name                           string                 `json:"name" binding:"required,min=1,max=255"`
+	Description                    string                 `json:"description,omitempty"`
+	MetricName                     string                 `json:"metric_name,omitempty"`
+	Operator                       alerting.Operator      `json:"operator,omitempty"`
+	Threshold                      float64                `json:"threshold"`
+	Expression                     string                 `json:"expression,omitempty"`
+	DurationSeconds                int                    `json:"duration_seconds,omitempty"`
+	MinNotificationIntervalSeconds int                    `json:"min_notification_interval_seconds,omitempty"`
+	Source                         alerting.MetricSource  `json:"source,omitempty"`
+	Severity                       alerting.AlertSeverity `json:"severity,omitempty"`
+	Labels                         map[string]string      `json:"labels,omitempty"`
+	Annotations                    map[string]string      `json:"annotations,omitempty"`
+	Enabled                        *bool                  `json:"enabled,omitempty"`
 }
 
 // Validate validates the create alert rule request.
@@ -47,19 +54,43 @@ func (r *CreateAlertRuleRequest) Validate() []FieldError {
 	if r.Name == "" {
 		errors = append(errors, FieldError{Field: "name", Message: "name is required"})
 	}
-	if r.MetricName == "" {
-		errors = append(errors, FieldError{Field: "metric_name", Message: "metric_name is required"})
-	}
-	if !r.Operator.IsValid() {
-		errors = append(errors, FieldError{Field: "operator", Message: "operator must be one of: gt, lt, eq, gte, lte"})
-	}
+	errors = append(errors, validateCondition(r.MetricName, r.Operator, r.Threshold, r.Expression)...)
 	if r.DurationSeconds < 0 {
 		errors = append(errors, FieldError{Field: "duration_seconds", Message: "duration_seconds cannot be negative"})
 	}
+	if r.MinNotificationIntervalSeconds < 0 {
+		errors = append(errors, FieldError{Field: "min_notification_interval_seconds", Message: "min_notification_interval_seconds cannot be negative"})
+	}
 	if r.Severity != "" && !r.Severity.IsValid() {
 		errors = append(errors, FieldError{Field: "severity", Message: "severity must be one of: info, warning, critical"})
 	}
+	if !r.Source.IsValid() {
+		errors = append(errors, FieldError{Field: "source", Message: "source must be one of: prometheus, internal, remote_write"})
+	}
+
+	return errors
+}
+
+// validateCondition checks that a rule sets exactly one of a metric compared
+// with a threshold, or an expression.
+func validateCondition(metricName string, operator alerting.Operator, threshold float64, expression string) []FieldError {
+	if expression != "" {
+		if metricName != "" || operator != "" || threshold != 0 {
+			return []FieldError{{Field: "expression", Message: "set either expression or metric_name, operator and threshold, not both"}}
+		}
+		if _, err := alerting.ParseExpression(expression); err != nil {
+			return []FieldError{{Field: "expression", Message: err.Error()}}
+		}
+		return nil
+	}
 
+	var errors []FieldError
+	if metricName == "" {
+		errors = append(errors, FieldError{Field: "metric_name", Message: "metric_name is required unless expression is set"})
+	}
+	if !operator.IsValid() {
+		errors = append(errors, FieldError{Field: "operator", Message: "operator must be one of: gt, lt, eq, gte, lte"})
+	}
 	return errors
 }
 
@@ -82,16 +113,19 @@ func (r *CreateAlertRuleRequest) ApplyDefaults() {
 
 // UpdateAlertRuleRequest represents a request to update an alert rule.
 type UpdateAlertRuleRequest struct {
-	Name            *string                 `json:"name,omitempty"`
-	Description     *string                 `json:"description,omitempty"`
-	MetricName      *string                 `json:"metric_name,omitempty"`
-	Operator        *alerting.Operator      `json:"operator,omitempty"`
-	Threshold       *float64                `json:"threshold,omitempty"`
-	DurationSeconds *int                    `json:"duration_seconds,omitempty"`
-	Severity        *alerting.AlertSeverity `json:"severity,omitempty"`
-	Labels          map[string]string       `json:"labels,omitempty"`
-	Annotations     map[string]string       `json:"annotations,omitempty"`
-	Enabled         *bool                   `json:"enabled,omitempty"`
+	Name                           *string                 `json:"name,omitempty"`
+	Description                    *string                 `json:"description,omitempty"`
+	MetricName                     *string                 `json:"metric_name,omitempty"`
+	Operator                       *alerting.Operator      `json:"operator,omitempty"`
+	Threshold                      *float64                `json:"threshold,omitempty"`
+	Expression                     *string                 `json:"expression,omitempty"`
+	DurationSeconds                *int                    `json:"duration_seconds,omitempty"`
+	MinNotificationIntervalSeconds *int                    `json:"min_notification_interval_seconds,omitempty"`
+	Source                         *alerting.MetricSource  `json:"source,omitempty"`
+	Severity                       *alerting.AlertSeverity `json:"severity,omitempty"`
+	Labels                         map[string]string       `json:"labels,omitempty"`
+	Annotations                    map[string]string       `json:"annotations,omitempty"`
+	Enabled                        *bool                   `json:"enabled,omitempty"`
 }
 
 // Validate validates the update alert rule request.
@@ -110,13 +144,48 @@ func (r *UpdateAlertRuleRequest) Validate() []FieldError {
 	if r.DurationSeconds != nil && *r.DurationSeconds < 0 {
 		errors = append(errors, FieldError{Field: "duration_seconds", Message: "duration_seconds cannot be negative"})
 	}
+	if r.MinNotificationIntervalSeconds != nil && *r.MinNotificationIntervalSeconds < 0 {
+		errors = append(errors, FieldError{Field: "min_notification_interval_seconds", Message: "min_notification_interval_seconds cannot be negative"})
+	}
 	if r.Severity != nil && !r.Severity.IsValid() {
 		errors = append(errors, FieldError{Field: "severity", Message: "severity must be one of: info, warning, critical"})
 	}
+	if r.Source != nil && !r.Source.IsValid() {
+		errors = append(errors, FieldError{Field: "source", Message: "source must be one of: prometheus, internal, remote_write"})
+	}
+	if r.Expression != nil && *r.Expression != "" && (r.MetricName != nil || r.Operator != nil || r.Threshold != nil) {
+		errors = append(errors, FieldError{Field: "expression", Message: "set either expression or metric_name, operator and threshold, not both"})
+	}
 
 	return errors
 }
 
+// ValidateFor checks the condition rule would have after the update.
+// Setting an expression clears the rule's metric, operator and threshold,
+// and setting a metric name clears its expression.
+func (r *UpdateAlertRuleRequest) ValidateFor(rule *alerting.AlertRule) []FieldError {
+	metricName, operator, threshold, expression := rule.MetricName, rule.Operator, rule.Threshold, rule.Expression
+	if r.Expression != nil {
+		expression = *r.Expression
+		if expression != "" {
+			metricName, operator, threshold = "", "", 0
+		}
+	}
+	if r.MetricName != nil {
+		metricName = *r.MetricName
+		if metricName != "" && r.Expression == nil {
+			expression = ""
+		}
+	}
+	if r.Operator != nil {
+		operator = *r.Operator
+	}
+	if r.Threshold != nil {
+		threshold = *r.Threshold
+	}
+	return validateCondition(metricName, operator, threshold, expression)
+}
+
 // AlertRuleResponse wraps an alert rule for API responses.
 type AlertRuleResponse struct {
 	Rule *alerting.AlertRule `json:"rule"`
@@ -174,6 +243,99 @@ func (r *AcknowledgeAlertRequest) Validate() []FieldError {
 	return errors
 }
 
+// MaxBulkAlerts is the most alerts a bulk request may select by ID.
+const MaxBulkAlerts = 500
+
+// BulkAcknowledgeRequest represents a request to acknowledge several alerts,
+// selected either by ID or by label matchers. Matchers select the firing,
+// unacknowledged alerts whose labels include all of them.
+type BulkAcknowledgeRequest struct {
+	IDs            []uuid.UUID       `json:"ids,omitempty"`
+	Matchers       map[string]string `json:"matchers,omitempty"`
+	AcknowledgedBy string            `json:"acknowledged_by" binding:"required"`
+	Comment        string            `json:"comment,omitempty"`
+}
+
+// Validate validates the bulk acknowledge request.
+func (r *BulkAcknowledgeRequest) Validate() []FieldError {
+	var errors []FieldError
+
+	switch {
+	case len(r.IDs) == 0 && len(r.Matchers) == 0:
+		errors = append(errors, FieldError{Field: "ids", Message: "either ids or matchers is required"})
+	case len(r.IDs) > 0 && len(r.Matchers) > 0:
+		errors = append(errors, FieldError{Field: "matchers", Message: "ids and matchers cannot be combined"})
+	case len(r.IDs) > MaxBulkAlerts:
+		errors = append(errors, FieldError{Field: "ids", Message: fmt.Sprintf("at most %d ids can be acknowledged at once", MaxBulkAlerts)})
+	}
+	if r.AcknowledgedBy == "" {
+		errors = append(errors, FieldError{Field: "acknowledged_by", Message: "acknowledged_by is required"})
+	}
+
+	return errors
+}
+
+// BulkSilenceRequest represents a request to silence the selected alerts
+// with one silence matching the labels they have in common.
+type BulkSilenceRequest struct {
+	IDs       []uuid.UUID `json:"ids" binding:"required"`
+	StartsAt  time.Time   `json:"starts_at,omitempty"`
+	EndsAt    time.Time   `json:"ends_at" binding:"required"`
+	CreatedBy string      `json:"created_by" binding:"required"`
+	Comment   string      `json:"comment,omitempty"`
+}
+
+// Validate validates the bulk silence request.
+func (r *BulkSilenceRequest) Validate() []FieldError {
+	var errors []FieldError
+
+	if len(r.IDs) == 0 {
+		errors = append(errors, FieldError{Field: "ids", Message: "ids is required and cannot be empty"})
+	}
+	if len(r.IDs) > MaxBulkAlerts {
+		errors = append(errors, FieldError{Field: "ids", Message: fmt.Sprintf("at most %d ids can be silenced at once", MaxBulkAlerts)})
+	}
+	if r.EndsAt.IsZero() {
+		errors = append(errors, FieldError{Field: "ends_at", Message: "ends_at is required"})
+	}
+	if !r.StartsAt.IsZero() && !r.EndsAt.IsZero() && r.EndsAt.Before(r.StartsAt) {
+		errors = append(errors, FieldError{Field: "ends_at", Message: "ends_at must be after starts_at"})
+	}
+	if r.CreatedBy == "" {
+		errors = append(errors, FieldError{Field: "created_by", Message: "created_by is required"})
+	}
+
+	return errors
+}
+
+// ApplyDefaults starts the silence now when no start is given.
+func (r *BulkSilenceRequest) ApplyDefaults() {
+	if r.StartsAt.IsZero() {
+		r.StartsAt = time.Now()
+	}
+}
+
+// BulkAlertResult is the outcome of a bulk operation for one alert.
+type BulkAlertResult struct {
+	ID      uuid.UUID `json:"id"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// BulkAcknowledgeResponse reports the outcome of a bulk acknowledge.
+type BulkAcknowledgeResponse struct {
+	Results      []BulkAlertResult `json:"results"`
+	Acknowledged int               `json:"acknowledged"`
+	Failed       int               `json:"failed"`
+}
+
+// BulkSilenceResponse reports the silence created by a bulk silence and
+// which of the selected alerts it was built from.
+type BulkSilenceResponse struct {
+	Silence *alerting.AlertSilence `json:"silence"`
+	Results []BulkAlertResult      `json:"results"`
+}
+
 // AlertHistoryResponse wraps alert history for API responses.
 type AlertHistoryResponse struct {
 	History    []alerting.AlertHistory `json:"history"`
@@ -187,6 +349,10 @@ type CreateSilenceRequest struct {
 	EndsAt    time.Time         `json:"ends_at" binding:"required"`
 	CreatedBy string            `json:"created_by" binding:"required"`
 	Comment   string            `json:"comment,omitempty"`
+
+	// Schedule makes the silence recur within StartsAt and EndsAt, e.g.
+	// every night from 02:00 to 03:00.
+	Schedule *alerting.SilenceSchedule `json:"schedule,omitempty"`
 }
 
 // Validate validates the create silence request.
@@ -208,6 +374,11 @@ func (r *CreateSilenceRequest) Validate() []FieldError {
 	if r.CreatedBy == "" {
 		errors = append(errors, FieldError{Field: "created_by", Message: "created_by is required"})
 	}
+	if r.Schedule != nil {
+		if err := r.Schedule.Validate(); err != nil {
+			errors = append(errors, FieldError{Field: "schedule", Message: err.Error()})
+		}
+	}
 
 	return errors
 }
@@ -268,9 +439,24 @@ func (r *CreateChannelRequest) Validate() []FieldError {
 		}
 	}
 
+	errors = append(errors, validateChannelTimezone(r.Config)...)
+
 	return errors
 }
 
+// validateChannelTimezone checks the optional timezone setting shared by all
+// channel types.
+func validateChannelTimezone(config map[string]any) []FieldError {
+	tz, ok := config["timezone"].(string)
+	if !ok || tz == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return []FieldError{{Field: "config.timezone", Message: "timezone must be an IANA timezone name, e.g. Europe/Berlin"}}
+	}
+	return nil
+}
+
 // ApplyDefaults applies default values to the request.
 func (r *CreateChannelRequest) ApplyDefaults() {
 	if r.Enabled == nil {
@@ -293,6 +479,7 @@ func (r *UpdateChannelRequest) Validate() []FieldError {
 	if r.Name != nil && *r.Name == "" {
 		errors = append(errors, FieldError{Field: "name", Message: "name cannot be empty"})
 	}
+	errors = append(errors, validateChannelTimezone(r.Config)...)
 
 	return errors
 }
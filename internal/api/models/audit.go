@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogFilter contains filters for listing audit logs.
+type AuditLogFilter struct {
+	// TenantID restricts logs to one tenant (nil = all tenants).
+	TenantID *uuid.UUID
+
+	// UserID restricts logs to one user (nil = all users).
+	UserID *uuid.UUID
+
+	// Action restricts logs to one action, such as "login" or
+	// "DELETE /alerts/channels/:id" (empty = all).
+	Action string
+
+	// ResourceType and ResourceID restrict logs to one kind of resource or
+	// one resource.
+	ResourceType string
+	ResourceID   *uuid.UUID
+
+	// Since and Until restrict logs to those created in the range.
+	Since *time.Time
+	Until *time.Time
+}
+
+// AuditLogListResponse is the response for listing audit logs.
+type AuditLogListResponse struct {
+	Logs       []AuditLog `json:"logs"`
+	TotalCount int        `json:"total_count"`
+}
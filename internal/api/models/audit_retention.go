@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditRetentionPolicy is a tenant's audit log retention override.
+type AuditRetentionPolicy struct {
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	RetentionDays int        `json:"retention_days"`
+	IsDefault     bool       `json:"is_default"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// AuditLegalHold exempts a tenant's audit logs from retention cleanup.
+// A hold without StartsAt/EndsAt covers all of the tenant's audit logs.
+type AuditLegalHold struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	Reason     string     `json:"reason"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedBy *uuid.UUID `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// IsActive reports whether the hold has not been released.
+func (h *AuditLegalHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
+
+// Covers reports whether an audit log created at t is protected by the hold.
+// Both ends of the range are inclusive.
+func (h *AuditLegalHold) Covers(t time.Time) bool {
+	if !h.IsActive() {
+		return false
+	}
+	if h.StartsAt != nil && t.Before(*h.StartsAt) {
+		return false
+	}
+	if h.EndsAt != nil && t.After(*h.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// UpdateAuditRetentionRequest sets a tenant's audit retention.
+type UpdateAuditRetentionRequest struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+// Validate validates the update audit retention request.
+func (r *UpdateAuditRetentionRequest) Validate() []FieldError {
+	var errors []FieldError
+	if r.RetentionDays <= 0 {
+		errors = append(errors, FieldError{Field: "retention_days", Message: "retention_days must be positive"})
+	}
+	return errors
+}
+
+// CreateLegalHoldRequest places a legal hold on a tenant's audit logs.
+type CreateLegalHoldRequest struct {
+	Reason   string     `json:"reason"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// Validate validates the create legal hold request.
+func (r *CreateLegalHoldRequest) Validate() []FieldError {
+	var errors []FieldError
+	if r.Reason == "" {
+		errors = append(errors, FieldError{Field: "reason", Message: "reason is required"})
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && r.EndsAt.Before(*r.StartsAt) {
+		errors = append(errors, FieldError{Field: "ends_at", Message: "ends_at must not be before starts_at"})
+	}
+	return errors
+}
+
+// AuditRetentionResponse wraps a retention policy.
+type AuditRetentionResponse struct {
+	Policy *AuditRetentionPolicy `json:"policy"`
+}
+
+// LegalHoldResponse wraps a single legal hold.
+type LegalHoldResponse struct {
+	Hold *AuditLegalHold `json:"hold"`
+}
+
+// LegalHoldListResponse wraps a list of legal holds.
+type LegalHoldListResponse struct {
+	Holds      []AuditLegalHold `json:"holds"`
+	TotalCount int              `json:"total_count"`
+}
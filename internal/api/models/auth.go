@@ -36,6 +36,9 @@ const (
 	PermissionScalingWrite   = "scaling:write"
 	PermissionAlertsRead     = "alerts:read"
 	PermissionAlertsWrite    = "alerts:write"
+	// PermissionQueryWrite allows running SQL statements other than queries
+	// through the query endpoint.
+	PermissionQueryWrite = "query:write"
 )
 
 // RolePermissions maps roles to their default permissions.
@@ -47,6 +50,7 @@ var RolePermissions = map[UserRole][]string{
 		PermissionUsersRead, PermissionUsersWrite,
 		PermissionScalingRead, PermissionScalingWrite,
 		PermissionAlertsRead, PermissionAlertsWrite,
+		PermissionQueryWrite,
 	},
 	RoleOperator: {
 		PermissionSourcesRead, PermissionSourcesWrite,
@@ -91,6 +95,16 @@ type APIKey struct {
 	IsActive    bool       `json:"is_active"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// RateLimit replaces the global rate limit for requests made with the
+	// key; nil uses the global limit.
+	RateLimit *APIKeyRateLimit `json:"rate_limit,omitempty"`
+}
+
+// APIKeyRateLimit is the request rate limit of an API key.
+type APIKeyRateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	BurstSize         int     `json:"burst_size"`
 }
 
 // AuditLog represents an audit log entry.
@@ -98,6 +112,7 @@ type AuditLog struct {
 	ID           uuid.UUID              `json:"id"`
 	UserID       *uuid.UUID             `json:"user_id,omitempty"`
 	APIKeyID     *uuid.UUID             `json:"api_key_id,omitempty"`
+	TenantID     *uuid.UUID             `json:"tenant_id,omitempty"`
 	Action       string                 `json:"action"`
 	ResourceType string                 `json:"resource_type,omitempty"`
 	ResourceID   *uuid.UUID             `json:"resource_id,omitempty"`
@@ -121,6 +136,29 @@ const (
 	AuditActionUserDeleted   = "user_deleted"
 	AuditActionUnauthorized  = "unauthorized"
 	AuditActionForbidden     = "forbidden"
+
+	AuditActionRetentionUpdated  = "audit_retention_updated"
+	AuditActionLegalHoldPlaced   = "legal_hold_placed"
+	AuditActionLegalHoldReleased = "legal_hold_released"
+
+	AuditActionAlertsAcknowledged = "alerts_acknowledged"
+	AuditActionAlertsSilenced     = "alerts_silenced"
+
+	AuditActionCredentialRevoked = "cloud_credential_revoked"
+
+	AuditActionTenantMemberAdded   = "tenant_member_added"
+	AuditActionTenantMemberUpdated = "tenant_member_updated"
+	AuditActionTenantMemberRemoved = "tenant_member_removed"
+
+	AuditActionSCIMTokenRotated = "scim_token_rotated"
+	AuditActionSCIMGroupCreated = "scim_group_created"
+	AuditActionSCIMGroupUpdated = "scim_group_updated"
+	AuditActionSCIMGroupDeleted = "scim_group_deleted"
+
+	AuditActionAPIKeyRateLimitUpdated = "api_key_rate_limit_updated"
+
+	AuditActionDeploymentDestroyInitiated  = "deployment_destroy_initiated"
+	AuditActionDeploymentProtectionUpdated = "deployment_protection_updated"
 )
 
 // JWTClaims represents the claims in a JWT token.
@@ -130,6 +168,11 @@ type JWTClaims struct {
 	Email       string    `json:"email"`
 	Role        UserRole  `json:"role"`
 	Permissions []string  `json:"permissions,omitempty"`
+
+	// SessionID is the identity provider session the token was issued for,
+	// qualified by the provider ID, for OIDC logins whose provider reports
+	// one
+	SessionID string `json:"sid,omitempty"`
 }
 
 // LoginRequest represents a login request.
@@ -212,6 +255,27 @@ func (r *CreateAPIKeyRequest) Validate() []FieldError {
 	return errors
 }
 
+// UpdateAPIKeyRateLimitRequest sets or, with a null rate limit, removes
+// the rate limit of an API key.
+type UpdateAPIKeyRateLimitRequest struct {
+	RateLimit *APIKeyRateLimit `json:"rate_limit"`
+}
+
+// Validate validates the update API key rate limit request.
+func (r *UpdateAPIKeyRateLimitRequest) Validate() []FieldError {
+	if r.RateLimit == nil {
+		return nil
+	}
+	var errors []FieldError
+	if r.RateLimit.RequestsPerSecond <= 0 {
+		errors = append(errors, FieldError{Field: "rate_limit.requests_per_second", Message: "requests_per_second must be positive"})
+	}
+	if r.RateLimit.BurstSize < 1 {
+		errors = append(errors, FieldError{Field: "rate_limit.burst_size", Message: "burst_size must be at least 1"})
+	}
+	return errors
+}
+
 // CreateAPIKeyResponse represents the response when creating an API key.
 type CreateAPIKeyResponse struct {
 	APIKey *APIKey `json:"api_key"`
@@ -275,6 +339,9 @@ type AuthContext struct {
 	Permissions []string
 	IsAPIKey    bool
 
+	// Claims are the claims of the JWT the request authenticated with
+	Claims *JWTClaims
+
 	// TenantID is the current tenant context (set by tenant middleware)
 	TenantID *uuid.UUID
 	// TenantRole is the user's role within the current tenant
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetterEvent is a CDC event that failed processing and was moved to the
+// dead-letter queue.
+type DeadLetterEvent struct {
+	ID              int64           `json:"id"`
+	OriginalEventID *int64          `json:"original_event_id,omitempty"`
+	SourceID        string          `json:"source_id"`
+	SchemaName      string          `json:"schema_name"`
+	TableName       string          `json:"table_name"`
+	Operation       string          `json:"operation"`
+	EventData       json.RawMessage `json:"event_data,omitempty"`
+	ErrorMessage    string          `json:"error_message"`
+	ErrorType       string          `json:"error_type,omitempty"`
+	RetryCount      int             `json:"retry_count"`
+	CreatedAt       time.Time       `json:"created_at"`
+	LastRetryAt     *time.Time      `json:"last_retry_at,omitempty"`
+	ExpiresAt       *time.Time      `json:"expires_at,omitempty"`
+	ReplayedAt      *time.Time      `json:"replayed_at,omitempty"`
+}
+
+// DeadLetterFilter selects dead-letter events.
+type DeadLetterFilter struct {
+	// SourceID restricts events to one source (empty = all sources).
+	SourceID string
+
+	// Since restricts events to those dead-lettered at or after this time.
+	Since *time.Time
+
+	// IncludeReplayed also selects events already replayed.
+	IncludeReplayed bool
+
+	// Limit caps the number of events listed (0 = no cap).
+	Limit int
+}
+
+// DeadLetterListResponse is the response for listing dead-letter events.
+type DeadLetterListResponse struct {
+	Events     []DeadLetterEvent `json:"events"`
+	TotalCount int               `json:"total_count"`
+}
+
+// DeadLetterEventResponse wraps a single dead-letter event.
+type DeadLetterEventResponse struct {
+	Event *DeadLetterEvent `json:"event"`
+}
+
+// ReplayDeadLetterRequest re-enqueues dead-letter events into the buffer.
+type ReplayDeadLetterRequest struct {
+	SourceID string     `json:"source_id,omitempty"`
+	Since    *time.Time `json:"since,omitempty"`
+	DryRun   bool       `json:"dry_run,omitempty"`
+}
+
+// ReplayDeadLetterResponse reports how many events were, or with DryRun
+// would be, replayed.
+type ReplayDeadLetterResponse struct {
+	Replayed int64 `json:"replayed"`
+	DryRun   bool  `json:"dry_run"`
+}
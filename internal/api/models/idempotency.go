@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is a reserved idempotency key and, once the request has
+// completed, its response.
+type IdempotencyRecord struct {
+	Scope        string
+	Key          string
+	RequestHash  string
+	StatusCode   int // zero while the request is in progress
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Completed reports whether the response of the request has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
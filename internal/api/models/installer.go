@@ -27,6 +27,10 @@ const (
 	DeploymentStatusFailed DeploymentStatus = "failed"
 	// DeploymentStatusCancelled indicates the deployment was canceled.
 	DeploymentStatusCancelled DeploymentStatus = "canceled"
+	// DeploymentStatusDestroying indicates infrastructure is being destroyed.
+	DeploymentStatusDestroying DeploymentStatus = "destroying"
+	// DeploymentStatusDestroyed indicates the infrastructure was destroyed.
+	DeploymentStatusDestroyed DeploymentStatus = "destroyed"
 )
 
 // DeploymentSize represents the size preset for a deployment.
@@ -41,33 +45,46 @@ const (
 	DeploymentSizeLarge DeploymentSize = "large"
 )
 
+// DeploymentMode is how a deployment gets its Kubernetes cluster.
+type DeploymentMode string
+
+const (
+	// DeploymentModeProvision provisions cloud infrastructure and a K3s cluster.
+	DeploymentModeProvision DeploymentMode = "provision"
+	// DeploymentModeExistingCluster installs Philotes on a cluster the user
+	// already runs, reached with a user-supplied kubeconfig.
+	DeploymentModeExistingCluster DeploymentMode = "existing-cluster"
+)
+
 // Deployment represents a cloud infrastructure deployment.
 type Deployment struct {
-	ID              uuid.UUID         `json:"id"`
-	UserID          *uuid.UUID        `json:"user_id,omitempty"`
-	Name            string            `json:"name"`
-	Provider        string            `json:"provider"`
-	Region          string            `json:"region"`
-	Size            DeploymentSize    `json:"size"`
-	Status          DeploymentStatus  `json:"status"`
-	Environment     string            `json:"environment"`
-	Config          *DeploymentConfig `json:"config,omitempty"`
-	Outputs         *DeploymentOutput `json:"outputs,omitempty"`
-	PulumiStackName string            `json:"pulumi_stack_name,omitempty"`
-	ErrorMessage    string            `json:"error_message,omitempty"`
-	StartedAt       *time.Time        `json:"started_at,omitempty"`
-	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
+	ID               uuid.UUID         `json:"id"`
+	UserID           *uuid.UUID        `json:"user_id,omitempty"`
+	Name             string            `json:"name"`
+	Provider         string            `json:"provider"`
+	Region           string            `json:"region"`
+	Size             DeploymentSize    `json:"size"`
+	Status           DeploymentStatus  `json:"status"`
+	Environment      string            `json:"environment"`
+	DeleteProtection bool              `json:"delete_protection"`
+	Config           *DeploymentConfig `json:"config,omitempty"`
+	Outputs          *DeploymentOutput `json:"outputs,omitempty"`
+	PulumiStackName  string            `json:"pulumi_stack_name,omitempty"`
+	ErrorMessage     string            `json:"error_message,omitempty"`
+	StartedAt        *time.Time        `json:"started_at,omitempty"`
+	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 // DeploymentConfig holds the configuration for a deployment.
 type DeploymentConfig struct {
-	Domain        string `json:"domain,omitempty"`
-	SSHPublicKey  string `json:"ssh_public_key,omitempty"`
-	ChartVersion  string `json:"chart_version,omitempty"`
-	WorkerCount   int    `json:"worker_count,omitempty"`
-	StorageSizeGB int    `json:"storage_size_gb,omitempty"`
+	Mode          DeploymentMode `json:"mode,omitempty"`
+	Domain        string         `json:"domain,omitempty"`
+	SSHPublicKey  string         `json:"ssh_public_key,omitempty"`
+	ChartVersion  string         `json:"chart_version,omitempty"`
+	WorkerCount   int            `json:"worker_count,omitempty"`
+	StorageSizeGB int            `json:"storage_size_gb,omitempty"`
 }
 
 // DeploymentOutput holds the outputs from a completed deployment.
@@ -125,17 +142,19 @@ type ProviderSize struct {
 
 // CreateDeploymentRequest represents a request to create a new deployment.
 type CreateDeploymentRequest struct {
-	Name          string               `json:"name" binding:"required,min=1,max=255"`
-	Provider      string               `json:"provider" binding:"required"`
-	Region        string               `json:"region" binding:"required"`
-	Size          DeploymentSize       `json:"size" binding:"required"`
-	Environment   string               `json:"environment,omitempty"`
-	Domain        string               `json:"domain,omitempty"`
-	SSHPublicKey  string               `json:"ssh_public_key,omitempty"`
-	ChartVersion  string               `json:"chart_version,omitempty"`
-	WorkerCount   int                  `json:"worker_count,omitempty"`
-	StorageSizeGB int                  `json:"storage_size_gb,omitempty"`
-	Credentials   *ProviderCredentials `json:"credentials,omitempty"`
+	Name             string               `json:"name" binding:"required,min=1,max=255"`
+	Mode             DeploymentMode       `json:"mode,omitempty"`
+	Provider         string               `json:"provider,omitempty"`
+	Region           string               `json:"region,omitempty"`
+	Size             DeploymentSize       `json:"size,omitempty"`
+	Environment      string               `json:"environment,omitempty"`
+	DeleteProtection bool                 `json:"delete_protection,omitempty"`
+	Domain           string               `json:"domain,omitempty"`
+	SSHPublicKey     string               `json:"ssh_public_key,omitempty"`
+	ChartVersion     string               `json:"chart_version,omitempty"`
+	WorkerCount      int                  `json:"worker_count,omitempty"`
+	StorageSizeGB    int                  `json:"storage_size_gb,omitempty"`
+	Credentials      *ProviderCredentials `json:"credentials,omitempty"`
 }
 
 // ProviderCredentials holds cloud provider authentication credentials.
@@ -164,6 +183,12 @@ type ProviderCredentials struct {
 	ContaboClientSecret string `json:"contabo_client_secret,omitempty"`
 	ContaboAPIUser      string `json:"contabo_api_user,omitempty"`
 	ContaboAPIPassword  string `json:"contabo_api_password,omitempty"`
+
+	// DigitalOcean
+	DigitalOceanToken string `json:"digitalocean_token,omitempty"`
+
+	// Existing cluster
+	Kubeconfig string `json:"kubeconfig,omitempty"`
 }
 
 // Validate validates the create deployment request.
@@ -174,11 +199,23 @@ func (r *CreateDeploymentRequest) Validate() []FieldError {
 		errors = append(errors, FieldError{Field: "name", Message: "name is required"})
 	}
 
+	switch r.Mode {
+	case "", DeploymentModeProvision:
+	case DeploymentModeExistingCluster:
+		// The cluster exists, so there is no infrastructure to choose
+		if r.Credentials == nil || r.Credentials.Kubeconfig == "" {
+			errors = append(errors, FieldError{Field: "credentials.kubeconfig", Message: "kubeconfig is required to deploy to an existing cluster"})
+		}
+		return errors
+	default:
+		errors = append(errors, FieldError{Field: "mode", Message: "mode must be one of: provision, existing-cluster"})
+	}
+
 	validProviders := map[string]bool{
-		"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "contabo": true,
+		"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "contabo": true, "digitalocean": true,
 	}
 	if !validProviders[r.Provider] {
-		errors = append(errors, FieldError{Field: "provider", Message: "provider must be one of: hetzner, scaleway, ovh, exoscale, contabo"})
+		errors = append(errors, FieldError{Field: "provider", Message: "provider must be one of: hetzner, scaleway, ovh, exoscale, contabo, digitalocean"})
 	}
 
 	if r.Region == "" {
@@ -197,6 +234,9 @@ func (r *CreateDeploymentRequest) Validate() []FieldError {
 
 // ApplyDefaults applies default values to the request.
 func (r *CreateDeploymentRequest) ApplyDefaults() {
+	if r.Mode == "" {
+		r.Mode = DeploymentModeProvision
+	}
 	if r.Environment == "" {
 		r.Environment = "production"
 	}
@@ -210,6 +250,22 @@ type DeploymentResponse struct {
 	Deployment *Deployment `json:"deployment"`
 }
 
+// DestroyDeploymentRequest represents a request to destroy a deployment's
+// infrastructure.
+type DestroyDeploymentRequest struct {
+	// Confirmation is the deployment name and stack name joined by a slash,
+	// such as "analytics/organization/hetzner-1a2b3c4d".
+	Confirmation string `json:"confirmation" binding:"required"`
+	// OverrideProduction must be set to destroy a production deployment.
+	OverrideProduction bool `json:"override_production,omitempty"`
+}
+
+// UpdateDeleteProtectionRequest represents a request to enable or disable a
+// deployment's delete protection.
+type UpdateDeleteProtectionRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
 // DeploymentListResponse wraps a list of deployments for API responses.
 type DeploymentListResponse struct {
 	Deployments []Deployment `json:"deployments"`
@@ -232,9 +288,18 @@ type DeploymentLogsResponse struct {
 	TotalCount int             `json:"total_count"`
 }
 
+// CostEstimateRequest asks for the cost of a deployment before it is
+// created.
+type CostEstimateRequest struct {
+	Provider string         `json:"provider" binding:"required"`
+	Region   string         `json:"region" binding:"required"`
+	Size     DeploymentSize `json:"size" binding:"required"`
+}
+
 // CostEstimate represents a cost breakdown for a deployment.
 type CostEstimate struct {
 	Provider     string  `json:"provider"`
+	Region       string  `json:"region,omitempty"`
 	Size         string  `json:"size"`
 	ControlPlane float64 `json:"control_plane"`
 	Workers      float64 `json:"workers"`
@@ -255,6 +320,11 @@ type DeploymentProgressResponse struct {
 	Message  string      `json:"message,omitempty"`
 }
 
+// DeploymentPreviewResponse wraps a deployment preview for API responses.
+type DeploymentPreviewResponse struct {
+	Preview interface{} `json:"preview"`
+}
+
 // CleanupResourcesResponse wraps cleanup resources for API responses.
 type CleanupResourcesResponse struct {
 	Resources interface{} `json:"resources"`
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestCreateDeploymentRequest_Validate_ExistingCluster(t *testing.T) {
+	req := &CreateDeploymentRequest{
+		Name:        "prod",
+		Mode:        DeploymentModeExistingCluster,
+		Credentials: &ProviderCredentials{Kubeconfig: "apiVersion: v1"},
+	}
+
+	// No provider, region or size is needed for an existing cluster
+	if errs := req.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+
+	req.Credentials = nil
+	errs := req.Validate()
+	if len(errs) != 1 || errs[0].Field != "credentials.kubeconfig" {
+		t.Errorf("Validate() without a kubeconfig = %v, want a credentials.kubeconfig error", errs)
+	}
+
+	req.Mode = "serverless"
+	errs = req.Validate()
+	if len(errs) == 0 || errs[0].Field != "mode" {
+		t.Errorf("Validate() with an unknown mode = %v, want a mode error", errs)
+	}
+}
@@ -17,10 +17,38 @@ type TaintRequest struct {
 	Effect string `json:"effect" binding:"required,oneof=NoSchedule PreferNoSchedule NoExecute"`
 }
 
+// InstanceRequirementsRequest represents node pool instance requirements in
+// API requests.
+type InstanceRequirementsRequest struct {
+	MinCPUCores int `json:"min_cpu_cores" binding:"gte=0"`
+	MinMemoryMB int `json:"min_memory_mb" binding:"gte=0"`
+}
+
+// validate validates the requirements, reporting errors under field.
+func (r *InstanceRequirementsRequest) validate(field string) []FieldError {
+	var errors []FieldError
+	if r.MinCPUCores < 0 {
+		errors = append(errors, FieldError{Field: field + ".min_cpu_cores", Message: "min_cpu_cores must be >= 0"})
+	}
+	if r.MinMemoryMB < 0 {
+		errors = append(errors, FieldError{Field: field + ".min_memory_mb", Message: "min_memory_mb must be >= 0"})
+	}
+	return errors
+}
+
+// toRequirements converts the request to pool requirements, nil when it sets
+// no minimum.
+func (r *InstanceRequirementsRequest) toRequirements() *nodepool.InstanceRequirements {
+	if r.MinCPUCores == 0 && r.MinMemoryMB == 0 {
+		return nil
+	}
+	return &nodepool.InstanceRequirements{MinCPUCores: r.MinCPUCores, MinMemoryMB: r.MinMemoryMB}
+}
+
 // CreateNodePoolRequest represents a request to create a node pool.
 type CreateNodePoolRequest struct {
 	Name             string            `json:"name" binding:"required,min=1,max=100"`
-	Provider         string            `json:"provider" binding:"required,oneof=hetzner scaleway ovh exoscale contabo"`
+	Provider         string            `json:"provider" binding:"required,oneof=hetzner scaleway ovh exoscale contabo digitalocean"`
 	Region           string            `json:"region" binding:"required"`
 	InstanceType     string            `json:"instance_type" binding:"required"`
 	Image            string            `json:"image,omitempty"`
@@ -33,6 +61,13 @@ type CreateNodePoolRequest struct {
 	NetworkID        string            `json:"network_id,omitempty"`
 	FirewallID       string            `json:"firewall_id,omitempty"`
 	Enabled          *bool             `json:"enabled,omitempty"`
+	SpotEnabled      bool              `json:"spot_enabled,omitempty"`
+	SpotMaxPercent   *int              `json:"spot_max_percent,omitempty" binding:"omitempty,gte=0,lte=99"`
+	NotifyScaling    bool              `json:"notify_scaling,omitempty"`
+
+	// Requirements makes scale-up pick the cheapest instance type meeting
+	// them instead of InstanceType.
+	Requirements *InstanceRequirementsRequest `json:"requirements,omitempty"`
 }
 
 // Validate validates the create node pool request.
@@ -46,7 +81,7 @@ func (r *CreateNodePoolRequest) Validate() []FieldError {
 	if r.Provider == "" {
 		errors = append(errors, FieldError{Field: "provider", Message: "provider is required"})
 	} else if !nodepool.Provider(r.Provider).IsValid() {
-		errors = append(errors, FieldError{Field: "provider", Message: "provider must be one of: hetzner, scaleway, ovh, exoscale, contabo"})
+		errors = append(errors, FieldError{Field: "provider", Message: "provider must be one of: hetzner, scaleway, ovh, exoscale, contabo, digitalocean"})
 	}
 
 	if r.Region == "" {
@@ -69,6 +104,14 @@ func (r *CreateNodePoolRequest) Validate() []FieldError {
 		errors = append(errors, FieldError{Field: "min_nodes", Message: "min_nodes cannot be greater than max_nodes"})
 	}
 
+	if r.SpotMaxPercent != nil && (*r.SpotMaxPercent < 0 || *r.SpotMaxPercent > 99) {
+		errors = append(errors, FieldError{Field: "spot_max_percent", Message: "spot_max_percent must be between 0 and 99"})
+	}
+
+	if r.Requirements != nil {
+		errors = append(errors, r.Requirements.validate("requirements")...)
+	}
+
 	for i, taint := range r.Taints {
 		if taint.Key == "" {
 			errors = append(errors, FieldError{Field: "taints[" + itoa(i) + "].key", Message: "key is required"})
@@ -97,6 +140,10 @@ func (r *CreateNodePoolRequest) ApplyDefaults() {
 	if r.Labels == nil {
 		r.Labels = make(map[string]string)
 	}
+	if r.SpotMaxPercent == nil {
+		spotMaxPercent := nodepool.DefaultSpotMaxPercent
+		r.SpotMaxPercent = &spotMaxPercent
+	}
 }
 
 // ToNodePool converts the request to a NodePool.
@@ -115,6 +162,12 @@ func (r *CreateNodePoolRequest) ToNodePool() *nodepool.NodePool {
 		NetworkID:        r.NetworkID,
 		FirewallID:       r.FirewallID,
 		Enabled:          *r.Enabled,
+		SpotEnabled:      r.SpotEnabled,
+		SpotMaxPercent:   *r.SpotMaxPercent,
+		NotifyScaling:    r.NotifyScaling,
+	}
+	if r.Requirements != nil {
+		pool.Requirements = r.Requirements.toRequirements()
 	}
 
 	for _, taint := range r.Taints {
@@ -140,6 +193,13 @@ type UpdateNodePoolRequest struct {
 	NetworkID        *string           `json:"network_id,omitempty"`
 	FirewallID       *string           `json:"firewall_id,omitempty"`
 	Enabled          *bool             `json:"enabled,omitempty"`
+	SpotEnabled      *bool             `json:"spot_enabled,omitempty"`
+	SpotMaxPercent   *int              `json:"spot_max_percent,omitempty" binding:"omitempty,gte=0,lte=99"`
+	NotifyScaling    *bool             `json:"notify_scaling,omitempty"`
+
+	// Requirements replaces the pool's instance requirements; zero minimums
+	// remove them.
+	Requirements *InstanceRequirementsRequest `json:"requirements,omitempty"`
 }
 
 // Validate validates the update node pool request.
@@ -158,6 +218,14 @@ func (r *UpdateNodePoolRequest) Validate() []FieldError {
 		errors = append(errors, FieldError{Field: "max_nodes", Message: "max_nodes must be >= 1"})
 	}
 
+	if r.SpotMaxPercent != nil && (*r.SpotMaxPercent < 0 || *r.SpotMaxPercent > 99) {
+		errors = append(errors, FieldError{Field: "spot_max_percent", Message: "spot_max_percent must be between 0 and 99"})
+	}
+
+	if r.Requirements != nil {
+		errors = append(errors, r.Requirements.validate("requirements")...)
+	}
+
 	return errors
 }
 
@@ -200,6 +268,18 @@ func (r *UpdateNodePoolRequest) ApplyToPool(pool *nodepool.NodePool) {
 	if r.Enabled != nil {
 		pool.Enabled = *r.Enabled
 	}
+	if r.SpotEnabled != nil {
+		pool.SpotEnabled = *r.SpotEnabled
+	}
+	if r.SpotMaxPercent != nil {
+		pool.SpotMaxPercent = *r.SpotMaxPercent
+	}
+	if r.NotifyScaling != nil {
+		pool.NotifyScaling = *r.NotifyScaling
+	}
+	if r.Requirements != nil {
+		pool.Requirements = r.Requirements.toRequirements()
+	}
 }
 
 // ScaleNodePoolRequest represents a request to scale a node pool.
@@ -281,6 +361,11 @@ type ClusterCapacityResponse struct {
 	NodePools           []nodepool.PoolStatus `json:"node_pools"`
 }
 
+// NodePoolCostReportResponse wraps a node pool cost report for API responses.
+type NodePoolCostReportResponse struct {
+	Report *nodepool.CostReport `json:"report"`
+}
+
 // PendingPodsResponse represents pending pods summary for API responses.
 type PendingPodsResponse struct {
 	TotalPending        int            `json:"total_pending"`
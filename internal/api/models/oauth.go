@@ -142,7 +142,7 @@ func (r *StoreCredentialRequest) Validate() []FieldError {
 	var errors []FieldError
 
 	validProviders := map[string]bool{
-		"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "contabo": true,
+		"hetzner": true, "scaleway": true, "ovh": true, "exoscale": true, "contabo": true, "digitalocean": true,
 	}
 	if !validProviders[r.Provider] {
 		errors = append(errors, FieldError{Field: "provider", Message: "invalid provider"})
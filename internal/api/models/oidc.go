@@ -34,6 +34,14 @@ var ValidOIDCProviderTypes = map[OIDCProviderType]bool{
 	OIDCProviderTypeGeneric: true,
 }
 
+// OIDCTenantMapping assigns the members of an IdP group to a tenant with a
+// tenant role.
+type OIDCTenantMapping struct {
+	Group    string     `json:"group"`
+	TenantID uuid.UUID  `json:"tenant_id"`
+	Role     TenantRole `json:"role"`
+}
+
 // OIDCProvider represents an OIDC identity provider configuration.
 type OIDCProvider struct {
 	ID                    uuid.UUID           `json:"id"`
@@ -46,11 +54,16 @@ type OIDCProvider struct {
 	Scopes                []string            `json:"scopes"`
 	GroupsClaim           string              `json:"groups_claim"`
 	RoleMapping           map[string]UserRole `json:"role_mapping"`
+	TenantMapping         []OIDCTenantMapping `json:"tenant_mapping"`
 	DefaultRole           UserRole            `json:"default_role"`
 	Enabled               bool                `json:"enabled"`
 	AutoCreateUsers       bool                `json:"auto_create_users"`
 	CreatedAt             time.Time           `json:"created_at"`
 	UpdatedAt             time.Time           `json:"updated_at"`
+
+	// RemoveUnmappedMemberships removes users from mapped tenants their
+	// groups no longer grant membership of
+	RemoveUnmappedMemberships bool `json:"remove_unmapped_memberships"`
 }
 
 // OIDCState represents a temporary OIDC state for authorization flow.
@@ -78,9 +91,12 @@ type CreateOIDCProviderRequest struct {
 	Scopes          []string            `json:"scopes,omitempty"`
 	GroupsClaim     string              `json:"groups_claim,omitempty"`
 	RoleMapping     map[string]UserRole `json:"role_mapping,omitempty"`
+	TenantMapping   []OIDCTenantMapping `json:"tenant_mapping,omitempty"`
 	DefaultRole     UserRole            `json:"default_role,omitempty"`
 	Enabled         *bool               `json:"enabled,omitempty"`
 	AutoCreateUsers *bool               `json:"auto_create_users,omitempty"`
+
+	RemoveUnmappedMemberships *bool `json:"remove_unmapped_memberships,omitempty"`
 }
 
 // Validate validates the create OIDC provider request.
@@ -138,6 +154,8 @@ func (r *CreateOIDCProviderRequest) Validate() []FieldError {
 		}
 	}
 
+	errors = append(errors, validateTenantMapping(r.TenantMapping)...)
+
 	return errors
 }
 
@@ -152,6 +170,13 @@ func (r *CreateOIDCProviderRequest) ApplyDefaults() {
 	if r.RoleMapping == nil {
 		r.RoleMapping = make(map[string]UserRole)
 	}
+	if r.TenantMapping == nil {
+		r.TenantMapping = []OIDCTenantMapping{}
+	}
+	if r.RemoveUnmappedMemberships == nil {
+		removeUnmapped := false
+		r.RemoveUnmappedMemberships = &removeUnmapped
+	}
 	if r.DefaultRole == "" {
 		r.DefaultRole = RoleViewer
 	}
@@ -174,9 +199,12 @@ type UpdateOIDCProviderRequest struct {
 	Scopes          []string            `json:"scopes,omitempty"`
 	GroupsClaim     *string             `json:"groups_claim,omitempty"`
 	RoleMapping     map[string]UserRole `json:"role_mapping,omitempty"`
+	TenantMapping   []OIDCTenantMapping `json:"tenant_mapping,omitempty"`
 	DefaultRole     *UserRole           `json:"default_role,omitempty"`
 	Enabled         *bool               `json:"enabled,omitempty"`
 	AutoCreateUsers *bool               `json:"auto_create_users,omitempty"`
+
+	RemoveUnmappedMemberships *bool `json:"remove_unmapped_memberships,omitempty"`
 }
 
 // Validate validates the update OIDC provider request.
@@ -207,6 +235,25 @@ func (r *UpdateOIDCProviderRequest) Validate() []FieldError {
 		}
 	}
 
+	errors = append(errors, validateTenantMapping(r.TenantMapping)...)
+
+	return errors
+}
+
+// validateTenantMapping validates group to tenant assignments. Custom
+// tenant roles cannot be assigned, since they need explicit permissions.
+func validateTenantMapping(mapping []OIDCTenantMapping) []FieldError {
+	var errors []FieldError
+	for _, m := range mapping {
+		switch {
+		case m.Group == "":
+			errors = append(errors, FieldError{Field: "tenant_mapping", Message: "group is required"})
+		case m.TenantID == uuid.Nil:
+			errors = append(errors, FieldError{Field: "tenant_mapping", Message: "tenant_id is required for group: " + m.Group})
+		case m.Role != TenantRoleAdmin && m.Role != TenantRoleOperator && m.Role != TenantRoleViewer:
+			errors = append(errors, FieldError{Field: "tenant_mapping", Message: "invalid tenant role for group: " + m.Group})
+		}
+	}
 	return errors
 }
 
@@ -267,11 +314,14 @@ type OIDCProviderSummary struct {
 	Scopes          []string            `json:"scopes"`
 	GroupsClaim     string              `json:"groups_claim"`
 	RoleMapping     map[string]UserRole `json:"role_mapping"`
+	TenantMapping   []OIDCTenantMapping `json:"tenant_mapping"`
 	DefaultRole     UserRole            `json:"default_role"`
 	Enabled         bool                `json:"enabled"`
 	AutoCreateUsers bool                `json:"auto_create_users"`
 	CreatedAt       time.Time           `json:"created_at"`
 	UpdatedAt       time.Time           `json:"updated_at"`
+
+	RemoveUnmappedMemberships bool `json:"remove_unmapped_memberships"`
 }
 
 // ToSummary converts an OIDCProvider to OIDCProviderSummary.
@@ -286,11 +336,14 @@ func (p *OIDCProvider) ToSummary() *OIDCProviderSummary {
 		Scopes:          p.Scopes,
 		GroupsClaim:     p.GroupsClaim,
 		RoleMapping:     p.RoleMapping,
+		TenantMapping:   p.TenantMapping,
 		DefaultRole:     p.DefaultRole,
 		Enabled:         p.Enabled,
 		AutoCreateUsers: p.AutoCreateUsers,
 		CreatedAt:       p.CreatedAt,
 		UpdatedAt:       p.UpdatedAt,
+
+		RemoveUnmappedMemberships: p.RemoveUnmappedMemberships,
 	}
 }
 
@@ -2,6 +2,8 @@ package models
 
 import (
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestCreateOIDCProviderRequest_Validate(t *testing.T) {
@@ -443,6 +445,32 @@ func TestUpdateOIDCProviderRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid tenant mapping",
+			req: &UpdateOIDCProviderRequest{
+				TenantMapping: []OIDCTenantMapping{
+					{Group: "team-a", TenantID: uuid.New(), Role: TenantRoleOperator},
+					{Group: "team-a-admins", TenantID: uuid.New(), Role: TenantRoleAdmin},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tenant mapping without tenant",
+			req: &UpdateOIDCProviderRequest{
+				TenantMapping: []OIDCTenantMapping{{Group: "team-a", Role: TenantRoleViewer}},
+			},
+			wantErr:   true,
+			errFields: []string{"tenant_mapping"},
+		},
+		{
+			name: "tenant mapping with custom role",
+			req: &UpdateOIDCProviderRequest{
+				TenantMapping: []OIDCTenantMapping{{Group: "team-a", TenantID: uuid.New(), Role: TenantRoleCustom}},
+			},
+			wantErr:   true,
+			errFields: []string{"tenant_mapping"},
+		},
 	}
 
 	for _, tt := range tests {
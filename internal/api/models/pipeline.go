@@ -18,6 +18,9 @@ const (
 	PipelineStatusStarting PipelineStatus = "starting"
 	// PipelineStatusRunning indicates the pipeline is running.
 	PipelineStatusRunning PipelineStatus = "running"
+	// PipelineStatusPaused indicates the pipeline was paused by an operator.
+	// Its worker stops consuming changes but keeps the replication slot.
+	PipelineStatusPaused PipelineStatus = "paused"
 	// PipelineStatusStopping indicates the pipeline is stopping.
 	PipelineStatusStopping PipelineStatus = "stopping"
 	// PipelineStatusError indicates the pipeline has an error.
@@ -143,6 +146,23 @@ type PipelineStatusResponse struct {
 	Uptime          string         `json:"uptime,omitempty"`
 }
 
+// PipelineBufferStats is the buffer state of a pipeline as its worker
+// recorded it after its last buffer cleanup run. Reported is false until
+// the worker has recorded a cleanup.
+type PipelineBufferStats struct {
+	PipelineID        uuid.UUID  `json:"pipeline_id"`
+	Reported          bool       `json:"reported"`
+	UnprocessedEvents int64      `json:"unprocessed_events"`
+	ProcessedEvents   int64      `json:"processed_events"`
+	OldestUnprocessed *time.Time `json:"oldest_unprocessed_at,omitempty"`
+	// OldestUnprocessedAgeSeconds is how long the oldest unprocessed event
+	// had been buffered at the last cleanup.
+	OldestUnprocessedAgeSeconds float64    `json:"oldest_unprocessed_age_seconds"`
+	LastCleanupAt               *time.Time `json:"last_cleanup_at,omitempty"`
+	LastCleanupDeleted          int64      `json:"last_cleanup_deleted"`
+	UpdatedAt                   *time.Time `json:"updated_at,omitempty"`
+}
+
 // AddTableMappingRequest represents a request to add a table mapping to a pipeline.
 type AddTableMappingRequest struct {
 	Schema  string         `json:"schema,omitempty"`
@@ -1,7 +1,14 @@
 // Package models provides request and response types for the API.
 package models
 
-import "time"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// identifierPattern matches unquoted SQL identifiers.
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
 // TimeNow returns the current time. This is a helper for testability.
 func TimeNow() time.Time {
@@ -121,3 +128,36 @@ type TrinoClusterStats struct {
 	TotalInputBytes  int64   `json:"totalInputBytes"`
 	TotalCPUTimeSecs float64 `json:"totalCpuTimeSecs"`
 }
+
+// ExecuteQueryRequest represents a request to execute a SQL statement.
+type ExecuteQueryRequest struct {
+	SQL string `json:"sql" binding:"required"`
+
+	// Schema overrides the configured default schema. For tenant-scoped
+	// requests it is prefixed with the tenant's schema prefix.
+	Schema string `json:"schema,omitempty"`
+
+	// NoCache runs the query even if its result is cached, replacing the
+	// cached result.
+	NoCache bool `json:"no_cache,omitempty"`
+}
+
+// Validate validates the execute query request.
+func (r *ExecuteQueryRequest) Validate() []FieldError {
+	var errors []FieldError
+
+	if strings.TrimSpace(r.SQL) == "" {
+		errors = append(errors, FieldError{Field: "sql", Message: "sql is required"})
+	}
+	if r.Schema != "" && !identifierPattern.MatchString(r.Schema) {
+		errors = append(errors, FieldError{Field: "schema", Message: "schema must contain only alphanumeric characters and underscores"})
+	}
+
+	return errors
+}
+
+// QueryResultColumn describes a column of a query result.
+type QueryResultColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,64 @@
+// Package models provides API request and response types.
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryStatus is the outcome of an executed query.
+type QueryStatus string
+
+// Query statuses.
+const (
+	QueryStatusSuccess QueryStatus = "success"
+	QueryStatusError   QueryStatus = "error"
+)
+
+// IsValid reports whether s is a known query status.
+func (s QueryStatus) IsValid() bool {
+	return s == QueryStatusSuccess || s == QueryStatusError
+}
+
+// QueryHistoryEntry records a statement run through the query endpoint or
+// as a saved query.
+type QueryHistoryEntry struct {
+	ID           uuid.UUID   `json:"id"`
+	TenantID     *uuid.UUID  `json:"tenant_id,omitempty"`
+	UserID       *uuid.UUID  `json:"user_id,omitempty"`
+	APIKeyID     *uuid.UUID  `json:"api_key_id,omitempty"`
+	SavedQueryID *uuid.UUID  `json:"saved_query_id,omitempty"`
+	Engine       string      `json:"engine"`
+	QueryID      string      `json:"query_id,omitempty"`
+	SQL          string      `json:"sql"`
+	Status       QueryStatus `json:"status"`
+	Error        string      `json:"error,omitempty"`
+	DurationMs   int64       `json:"duration_ms"`
+	RowCount     int64       `json:"row_count"`
+	BytesScanned int64       `json:"bytes_scanned"`
+	ExecutedAt   time.Time   `json:"executed_at"`
+}
+
+// QueryHistoryFilter selects query history entries.
+type QueryHistoryFilter struct {
+	// TenantID restricts entries to one tenant (nil = all tenants).
+	TenantID *uuid.UUID
+
+	// UserID restricts entries to one user, including those of the user's
+	// API keys (nil = all users).
+	UserID *uuid.UUID
+
+	// Status restricts entries to one outcome (empty = all).
+	Status QueryStatus
+
+	// Since and Until restrict entries to those executed in the range.
+	Since *time.Time
+	Until *time.Time
+}
+
+// QueryHistoryListResponse is the response for listing query history.
+type QueryHistoryListResponse struct {
+	Queries    []QueryHistoryEntry `json:"queries"`
+	TotalCount int                 `json:"total_count"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc/health"
+)
 
 // VersionResponse contains version information.
 type VersionResponse struct {
@@ -11,18 +15,25 @@ type VersionResponse struct {
 	GitCommit  string `json:"git_commit,omitempty"`
 }
 
-// ConfigResponse contains safe configuration information.
+// ConfigResponse contains the effective configuration with credentials
+// redacted.
 type ConfigResponse struct {
 	Environment string       `json:"environment"`
 	API         APIConfig    `json:"api"`
 	CDC         CDCConfig    `json:"cdc,omitempty"`
 	Metrics     MetricConfig `json:"metrics,omitempty"`
+
+	// Settings holds the effective value of every setting, keyed by its
+	// environment variable, with credentials redacted.
+	Settings map[string]string `json:"settings"`
 }
 
 // APIConfig contains API configuration (safe subset).
 type APIConfig struct {
-	ListenAddr string `json:"listen_addr"`
-	BaseURL    string `json:"base_url"`
+	ListenAddr     string  `json:"listen_addr"`
+	BaseURL        string  `json:"base_url"`
+	RateLimitRPS   float64 `json:"rate_limit_rps"`
+	RateLimitBurst int     `json:"rate_limit_burst"`
 }
 
 // CDCConfig contains CDC configuration (safe subset).
@@ -53,6 +64,39 @@ type ComponentHealth struct {
 	DurationMs int64     `json:"duration_ms"`
 	LastCheck  time.Time `json:"last_check"`
 	Error      string    `json:"error,omitempty"`
+
+	// Failure is the structured failure reason; Message and Error are kept
+	// for existing clients.
+	Failure *HealthFailure `json:"failure,omitempty"`
+}
+
+// HealthFailure describes why a component check failed.
+type HealthFailure struct {
+	Code        string     `json:"code"`
+	Message     string     `json:"message"`
+	Remediation string     `json:"remediation"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// NewComponentHealth converts a health check result to its API form.
+func NewComponentHealth(result health.CheckResult) ComponentHealth {
+	component := ComponentHealth{
+		Name:       result.Name,
+		Status:     string(result.Status),
+		Message:    result.Message,
+		DurationMs: result.Duration.Milliseconds(),
+		LastCheck:  result.LastCheck,
+		Error:      result.Error,
+	}
+	if result.Failure != nil {
+		component.Failure = &HealthFailure{
+			Code:        string(result.Failure.Code),
+			Message:     result.Failure.Message,
+			Remediation: result.Failure.Remediation,
+			LastSuccess: result.Failure.LastSuccess,
+		}
+	}
+	return component
 }
 
 // LivenessResponse represents the liveness probe response.
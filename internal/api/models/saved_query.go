@@ -0,0 +1,196 @@
+// Package models provides API request and response types.
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// SavedQueryParameter declares a {{name}} placeholder of a saved query.
+type SavedQueryParameter struct {
+	Name        string              `json:"name"`
+	Type        query.ParameterType `json:"type"`
+	Description string              `json:"description,omitempty"`
+
+	// Default is used when a run does not set the parameter. Without a
+	// default the parameter is required.
+	Default interface{} `json:"default,omitempty"`
+}
+
+// SavedQuery is a named SQL statement with typed parameters.
+type SavedQuery struct {
+	ID          uuid.UUID             `json:"id"`
+	TenantID    *uuid.UUID            `json:"tenant_id,omitempty"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	SQL         string                `json:"sql"`
+	Schema      string                `json:"schema,omitempty"`
+	Parameters  []SavedQueryParameter `json:"parameters"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// Parameter returns the declared parameter called name.
+func (q *SavedQuery) Parameter(name string) (SavedQueryParameter, bool) {
+	for _, p := range q.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return SavedQueryParameter{}, false
+}
+
+// CreateSavedQueryRequest represents a request to create a saved query.
+type CreateSavedQueryRequest struct {
+	Name        string                `json:"name" binding:"required,min=1,max=255"`
+	Description string                `json:"description,omitempty"`
+	SQL         string                `json:"sql" binding:"required"`
+	Schema      string                `json:"schema,omitempty"`
+	Parameters  []SavedQueryParameter `json:"parameters,omitempty"`
+}
+
+// Validate validates the create saved query request.
+func (r *CreateSavedQueryRequest) Validate() []FieldError {
+	var errors []FieldError
+
+	if strings.TrimSpace(r.Name) == "" {
+		errors = append(errors, FieldError{Field: "name", Message: "name is required"})
+	} else if len(r.Name) > 255 {
+		errors = append(errors, FieldError{Field: "name", Message: "name must be at most 255 characters"})
+	}
+	errors = append(errors, validateSavedQuerySQL(r.SQL, r.Schema, r.Parameters)...)
+
+	return errors
+}
+
+// ApplyDefaults applies default values to the request.
+func (r *CreateSavedQueryRequest) ApplyDefaults() {
+	if r.Parameters == nil {
+		r.Parameters = []SavedQueryParameter{}
+	}
+}
+
+// validateSavedQuerySQL checks the statement, its schema and its declared
+// parameters, which must match the statement's placeholders one to one.
+func validateSavedQuerySQL(sql, schema string, params []SavedQueryParameter) []FieldError {
+	var errors []FieldError
+
+	if schema != "" && !identifierPattern.MatchString(schema) {
+		errors = append(errors, FieldError{Field: "schema", Message: "schema must contain only alphanumeric characters and underscores"})
+	}
+
+	declared := make(map[string]bool, len(params))
+	for i, p := range params {
+		field := fmt.Sprintf("parameters[%d]", i)
+		switch {
+		case !identifierPattern.MatchString(p.Name):
+			errors = append(errors, FieldError{Field: field + ".name", Message: "name must contain only alphanumeric characters and underscores"})
+		case declared[p.Name]:
+			errors = append(errors, FieldError{Field: field + ".name", Message: fmt.Sprintf("parameter %q is declared more than once", p.Name)})
+		}
+		declared[p.Name] = true
+
+		if !p.Type.IsValid() {
+			errors = append(errors, FieldError{Field: field + ".type", Message: "type must be one of: string, integer, number, boolean, date, timestamp"})
+		} else if p.Default != nil {
+			if _, err := query.ConvertParameter(p.Type, p.Default); err != nil {
+				errors = append(errors, FieldError{Field: field + ".default", Message: "default " + err.Error()})
+			}
+		}
+	}
+
+	if strings.TrimSpace(sql) == "" {
+		return append(errors, FieldError{Field: "sql", Message: "sql is required"})
+	}
+	bound, names, err := query.BindPlaceholders(sql)
+	if err != nil {
+		return append(errors, FieldError{Field: "sql", Message: err.Error()})
+	}
+	if _, err := query.ParseStatement(bound); err != nil {
+		return append(errors, FieldError{Field: "sql", Message: err.Error()})
+	}
+
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !used[name] && !declared[name] {
+			errors = append(errors, FieldError{Field: "sql", Message: fmt.Sprintf("placeholder {{%s}} is not a declared parameter", name)})
+		}
+		used[name] = true
+	}
+	for i, p := range params {
+		if identifierPattern.MatchString(p.Name) && !used[p.Name] {
+			errors = append(errors, FieldError{Field: fmt.Sprintf("parameters[%d].name", i), Message: fmt.Sprintf("parameter %q is not used in sql", p.Name)})
+		}
+	}
+
+	return errors
+}
+
+// UpdateSavedQueryRequest represents a request to update a saved query.
+type UpdateSavedQueryRequest struct {
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	SQL         *string                `json:"sql,omitempty"`
+	Schema      *string                `json:"schema,omitempty"`
+	Parameters  *[]SavedQueryParameter `json:"parameters,omitempty"`
+}
+
+// Validate validates the update saved query request.
+func (r *UpdateSavedQueryRequest) Validate() []FieldError {
+	var errors []FieldError
+
+	if r.Name != nil {
+		if strings.TrimSpace(*r.Name) == "" {
+			errors = append(errors, FieldError{Field: "name", Message: "name cannot be empty"})
+		} else if len(*r.Name) > 255 {
+			errors = append(errors, FieldError{Field: "name", Message: "name must be at most 255 characters"})
+		}
+	}
+	if r.SQL != nil && strings.TrimSpace(*r.SQL) == "" {
+		errors = append(errors, FieldError{Field: "sql", Message: "sql cannot be empty"})
+	}
+
+	return errors
+}
+
+// ValidateFor checks that the statement, schema and parameters of q still
+// match after the update.
+func (r *UpdateSavedQueryRequest) ValidateFor(q *SavedQuery) []FieldError {
+	sql, schema, params := q.SQL, q.Schema, q.Parameters
+	if r.SQL != nil {
+		sql = *r.SQL
+	}
+	if r.Schema != nil {
+		schema = *r.Schema
+	}
+	if r.Parameters != nil {
+		params = *r.Parameters
+	}
+	return validateSavedQuerySQL(sql, schema, params)
+}
+
+// SavedQueryResponse wraps a saved query for API responses.
+type SavedQueryResponse struct {
+	SavedQuery *SavedQuery `json:"saved_query"`
+}
+
+// SavedQueryListResponse wraps a list of saved queries for API responses.
+type SavedQueryListResponse struct {
+	SavedQueries []SavedQuery `json:"saved_queries"`
+	TotalCount   int          `json:"total_count"`
+}
+
+// RunSavedQueryRequest represents a request to run a saved query.
+type RunSavedQueryRequest struct {
+	// Parameters maps parameter names to values. Parameters left out take
+	// their default.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+
+	// NoCache runs the query even if its result is cached.
+	NoCache bool `json:"no_cache,omitempty"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/janovincze/philotes/internal/query"
+)
+
+func TestCreateSavedQueryRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *CreateSavedQueryRequest
+		errFields []string
+	}{
+		{
+			name: "valid request",
+			req: &CreateSavedQueryRequest{
+				Name: "daily-orders",
+				SQL:  "SELECT * FROM orders WHERE day >= {{start_date}} AND customer = {{customer}} AND note <> '{{literal}}'",
+				Parameters: []SavedQueryParameter{
+					{Name: "start_date", Type: query.ParameterDate, Default: "2026-01-01"},
+					{Name: "customer", Type: query.ParameterString},
+				},
+			},
+		},
+		{
+			name: "undeclared placeholder",
+			req: &CreateSavedQueryRequest{
+				Name: "daily-orders",
+				SQL:  "SELECT * FROM orders WHERE day >= {{start_date}}",
+			},
+			errFields: []string{"sql"},
+		},
+		{
+			name: "unused parameter",
+			req: &CreateSavedQueryRequest{
+				Name:       "all-orders",
+				SQL:        "SELECT * FROM orders",
+				Parameters: []SavedQueryParameter{{Name: "limit", Type: query.ParameterInteger}},
+			},
+			errFields: []string{"parameters[0].name"},
+		},
+		{
+			name: "duplicate parameter and invalid type",
+			req: &CreateSavedQueryRequest{
+				Name: "orders",
+				SQL:  "SELECT * FROM orders WHERE id = {{id}}",
+				Parameters: []SavedQueryParameter{
+					{Name: "id", Type: query.ParameterInteger},
+					{Name: "id", Type: "uuid"},
+				},
+			},
+			errFields: []string{"parameters[1].name", "parameters[1].type"},
+		},
+		{
+			name: "default of the wrong type",
+			req: &CreateSavedQueryRequest{
+				Name:       "orders",
+				SQL:        "SELECT * FROM orders WHERE id = {{id}}",
+				Parameters: []SavedQueryParameter{{Name: "id", Type: query.ParameterInteger, Default: "one"}},
+			},
+			errFields: []string{"parameters[0].default"},
+		},
+		{
+			name: "multiple statements and invalid schema",
+			req: &CreateSavedQueryRequest{
+				Name:   "orders",
+				SQL:    "SELECT 1; SELECT 2",
+				Schema: "sales-eu",
+			},
+			errFields: []string{"schema", "sql"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.req.Validate()
+			if len(errs) != len(tt.errFields) {
+				t.Fatalf("Validate() = %+v, want errors for %v", errs, tt.errFields)
+			}
+			for i, field := range tt.errFields {
+				if errs[i].Field != field {
+					t.Errorf("Validate()[%d].Field = %q, want %q", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateSavedQueryRequest_ValidateFor(t *testing.T) {
+	existing := &SavedQuery{
+		SQL:        "SELECT * FROM orders WHERE day >= {{start_date}}",
+		Parameters: []SavedQueryParameter{{Name: "start_date", Type: query.ParameterDate}},
+	}
+
+	sql := "SELECT * FROM orders WHERE day >= {{start_date}} AND day < {{end_date}}"
+	if errs := (&UpdateSavedQueryRequest{SQL: &sql}).ValidateFor(existing); len(errs) != 1 || errs[0].Field != "sql" {
+		t.Errorf("ValidateFor(new placeholder) = %+v, want an undeclared placeholder error", errs)
+	}
+
+	params := []SavedQueryParameter{
+		{Name: "start_date", Type: query.ParameterDate},
+		{Name: "end_date", Type: query.ParameterDate},
+	}
+	if errs := (&UpdateSavedQueryRequest{SQL: &sql, Parameters: &params}).ValidateFor(existing); len(errs) != 0 {
+		t.Errorf("ValidateFor(placeholder and parameter) = %+v, want no errors", errs)
+	}
+}
@@ -116,10 +116,6 @@ func (r *CreateScalingPolicyRequest) Validate() []FieldError {
 
 // ApplyDefaults applies default values to the request.
 func (r *CreateScalingPolicyRequest) ApplyDefaults() {
-	if r.CooldownSeconds == nil {
-		cooldown := 300
-		r.CooldownSeconds = &cooldown
-	}
 	if r.ScaleToZero == nil {
 		scaleToZero := false
 		r.ScaleToZero = &scaleToZero
@@ -147,7 +143,7 @@ func (r *CreateScalingPolicyRequest) ToScalingPolicy() *scaling.Policy {
 		TargetID:        r.TargetID,
 		MinReplicas:     r.MinReplicas,
 		MaxReplicas:     r.MaxReplicas,
-		CooldownSeconds: *r.CooldownSeconds,
+		CooldownSeconds: r.CooldownSeconds,
 		MaxHourlyCost:   r.MaxHourlyCost,
 		ScaleToZero:     *r.ScaleToZero,
 		Enabled:         *r.Enabled,
@@ -240,7 +236,8 @@ func (r *UpdateScalingPolicyRequest) ApplyToPolicy(policy *scaling.Policy) {
 		policy.MaxReplicas = *r.MaxReplicas
 	}
 	if r.CooldownSeconds != nil {
-		policy.CooldownSeconds = *r.CooldownSeconds
+		cooldown := *r.CooldownSeconds
+		policy.CooldownSeconds = &cooldown
 	}
 	if r.MaxHourlyCost != nil {
 		policy.MaxHourlyCost = r.MaxHourlyCost
@@ -0,0 +1,205 @@
+// Package models provides API request and response types.
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCIM 2.0 schema URNs.
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// SCIM error types, set as scimType on 400 and 409 errors.
+const (
+	SCIMErrorInvalidFilter = "invalidFilter"
+	SCIMErrorInvalidValue  = "invalidValue"
+	SCIMErrorInvalidPath   = "invalidPath"
+	SCIMErrorUniqueness    = "uniqueness"
+)
+
+// SCIMMeta holds the metadata of a SCIM resource.
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// SCIMName is the name of a SCIM user.
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is an email address of a SCIM user.
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMember references a member of a SCIM group, or a group of a SCIM
+// user.
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMUser is a SCIM user resource. The userName is the user's email
+// address; the externalId, or else the userName, is their OIDC subject.
+type SCIMUser struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *SCIMName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []SCIMEmail  `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []SCIMMember `json:"groups,omitempty"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns the primary email address of the user, the first
+// one without a primary, or else the userName.
+func (u *SCIMUser) PrimaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary && email.Value != "" {
+			return email.Value
+		}
+	}
+	for _, email := range u.Emails {
+		if email.Value != "" {
+			return email.Value
+		}
+	}
+	return u.UserName
+}
+
+// FullName returns the display name of the user, or else their formatted
+// or given and family name.
+func (u *SCIMUser) FullName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	switch {
+	case u.Name.GivenName != "" && u.Name.FamilyName != "":
+		return u.Name.GivenName + " " + u.Name.FamilyName
+	case u.Name.GivenName != "":
+		return u.Name.GivenName
+	default:
+		return u.Name.FamilyName
+	}
+}
+
+// IsActive reports whether the user is active; users are active unless
+// set otherwise.
+func (u *SCIMUser) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// SCIMGroup is a SCIM group resource. Its displayName is the group name
+// matched against the provider's role and tenant mappings.
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []SCIMMember `json:"members,omitempty"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMListResponse is a page of SCIM resources.
+type SCIMListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// SCIMPatchRequest is a SCIM PATCH request.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is a single operation of a SCIM PATCH request. Without
+// a path the value is an object of attributes.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SCIMError is a SCIM error response.
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMSupported reports whether an optional SCIM feature is supported.
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+// SCIMFilterSupport describes the supported filtering.
+type SCIMFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// SCIMAuthenticationScheme describes how SCIM clients authenticate.
+type SCIMAuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SCIMServiceProviderConfig describes the SCIM features of the endpoint.
+type SCIMServiceProviderConfig struct {
+	Schemas               []string                   `json:"schemas"`
+	Patch                 SCIMSupported              `json:"patch"`
+	Bulk                  SCIMSupported              `json:"bulk"`
+	Filter                SCIMFilterSupport          `json:"filter"`
+	ChangePassword        SCIMSupported              `json:"changePassword"`
+	Sort                  SCIMSupported              `json:"sort"`
+	ETag                  SCIMSupported              `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationScheme `json:"authenticationSchemes"`
+}
+
+// SCIMGroupRecord is a group provisioned over SCIM by an OIDC provider.
+type SCIMGroupRecord struct {
+	ID          uuid.UUID   `json:"id"`
+	ProviderID  uuid.UUID   `json:"provider_id"`
+	DisplayName string      `json:"display_name"`
+	ExternalID  string      `json:"external_id,omitempty"`
+	MemberIDs   []uuid.UUID `json:"member_ids"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// SCIMTokenResponse returns a newly generated SCIM bearer token. The token
+// is shown only once.
+type SCIMTokenResponse struct {
+	Token string `json:"token"`
+}
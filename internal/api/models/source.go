@@ -140,11 +140,55 @@ type ConnectionTestResult struct {
 	ErrorDetail string `json:"error_detail,omitempty"`
 }
 
+// SourceHealth is the reachability of a source database.
+type SourceHealth struct {
+	SourceID  uuid.UUID      `json:"source_id"`
+	Status    string         `json:"status"`
+	Message   string         `json:"message"`
+	LatencyMs int64          `json:"latency_ms,omitempty"`
+	Failure   *HealthFailure `json:"failure,omitempty"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// ValidateSourceRequest lists the tables a pipeline would replicate from a
+// source, as "table" or "schema.table".
+type ValidateSourceRequest struct {
+	Tables []string `json:"tables,omitempty"`
+}
+
+// SourceValidationResult is the outcome of a source pre-flight check. Errors
+// name what keeps the source from replicating.
+type SourceValidationResult struct {
+	Valid  bool         `json:"valid"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// SchemaInfo represents a schema in a source database.
+type SchemaInfo struct {
+	Name       string `json:"name"`
+	TableCount int    `json:"table_count"`
+}
+
+// SchemaDiscoveryResponse wraps schema discovery results. Results are
+// cached briefly; DiscoveredAt is when the source was introspected.
+type SchemaDiscoveryResponse struct {
+	Schemas      []SchemaInfo `json:"schemas"`
+	Count        int          `json:"count"`
+	DiscoveredAt time.Time    `json:"discovered_at"`
+}
+
 // TableInfo represents information about a table in a source database.
 type TableInfo struct {
 	Schema  string       `json:"schema"`
 	Name    string       `json:"name"`
 	Columns []ColumnInfo `json:"columns,omitempty"`
+	// ReplicaIdentity is the table's replica identity: default, full,
+	// index or nothing.
+	ReplicaIdentity string `json:"replica_identity"`
+	// CDCReady is set if the replica identity identifies the rows of
+	// updates and deletes; CDCProblem explains why it does not.
+	CDCReady   bool   `json:"cdc_ready"`
+	CDCProblem string `json:"cdc_problem,omitempty"`
 }
 
 // ColumnInfo represents information about a column in a table.
@@ -157,7 +201,10 @@ type ColumnInfo struct {
 }
 
 // TableDiscoveryResponse wraps table discovery results.
+// Results are cached briefly; DiscoveredAt is when the source was
+// introspected.
 type TableDiscoveryResponse struct {
-	Tables []TableInfo `json:"tables"`
-	Count  int         `json:"count"`
+	Tables       []TableInfo `json:"tables"`
+	Count        int         `json:"count"`
+	DiscoveredAt time.Time   `json:"discovered_at"`
 }
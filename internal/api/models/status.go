@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// SystemStatus is a summary of the whole deployment, for dashboards and
+// the CLI.
+type SystemStatus struct {
+	// Status is the overall status: healthy, degraded, unhealthy or unknown.
+	Status string `json:"status"`
+
+	// API is the health of the API server and its dependencies.
+	API string `json:"api"`
+
+	// Pipelines summarizes pipelines by status.
+	Pipelines PipelineStatusCounts `json:"pipelines"`
+
+	// Workers summarizes the CDC workers Prometheus scrapes.
+	Workers WorkerStatus `json:"workers"`
+
+	// BufferDepth is the number of buffered events not yet written to
+	// Iceberg, or nil if metrics are unavailable.
+	BufferDepth *int64 `json:"buffer_depth"`
+
+	// ReplicationLagBytes is the largest replication lag in bytes across
+	// sources, or nil if metrics are unavailable.
+	ReplicationLagBytes *int64 `json:"replication_lag_bytes"`
+
+	// Timestamp is when the summary was computed.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PipelineStatusCounts counts pipelines by status.
+type PipelineStatusCounts struct {
+	// Total is the number of pipelines.
+	Total int `json:"total"`
+
+	// Running is the number of running pipelines.
+	Running int `json:"running"`
+
+	// Stopped is the number of stopped pipelines.
+	Stopped int `json:"stopped"`
+
+	// Error is the number of pipelines in the error state.
+	Error int `json:"error"`
+}
+
+// WorkerStatus summarizes worker availability.
+type WorkerStatus struct {
+	// Status is healthy, degraded, unhealthy or unknown.
+	Status string `json:"status"`
+
+	// Up is the number of workers answering scrapes.
+	Up int `json:"up"`
+
+	// Total is the number of workers Prometheus knows about.
+	Total int `json:"total"`
+}
+
+// SystemStatusResponse wraps a SystemStatus.
+type SystemStatusResponse struct {
+	Status SystemStatus `json:"status"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// TableRef identifies the Iceberg table backing a table mapping.
+type TableRef struct {
+	MappingID  uuid.UUID `json:"mapping_id"`
+	PipelineID uuid.UUID `json:"pipeline_id"`
+	Namespace  string    `json:"namespace"`
+	Table      string    `json:"table"`
+}
+
+// TableSnapshot describes an Iceberg snapshot of a managed table.
+type TableSnapshot struct {
+	SnapshotID       int64             `json:"snapshot_id"`
+	ParentSnapshotID int64             `json:"parent_snapshot_id,omitempty"`
+	Timestamp        time.Time         `json:"timestamp"`
+	Operation        string            `json:"operation,omitempty"`
+	IsCurrent        bool              `json:"is_current"`
+	Summary          map[string]string `json:"summary,omitempty"`
+}
+
+// TableSnapshotsResponse lists the snapshots of a managed table.
+type TableSnapshotsResponse struct {
+	Table             TableRef        `json:"table"`
+	CurrentSnapshotID int64           `json:"current_snapshot_id,omitempty"`
+	Snapshots         []TableSnapshot `json:"snapshots"`
+	TotalCount        int             `json:"total_count"`
+}
+
+// TableFilesSummaryResponse summarizes the data files of a managed table.
+type TableFilesSummaryResponse struct {
+	Table   TableRef             `json:"table"`
+	Summary iceberg.FilesSummary `json:"summary"`
+
+	// CompactionRecommended is set when files are smaller than the configured threshold.
+	CompactionRecommended bool `json:"compaction_recommended"`
+}
+
+// TablePartitionsResponse lists per-partition statistics of a managed table.
+type TablePartitionsResponse struct {
+	Table         TableRef                 `json:"table"`
+	PartitionSpec iceberg.PartitionSpec    `json:"partition_spec"`
+	Partitions    []iceberg.PartitionStats `json:"partitions"`
+}
+
+// TableBranch is a branch or tag of a managed table.
+type TableBranch struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	SnapshotID int64  `json:"snapshot_id"`
+}
+
+// TableBranchesResponse lists the branches and tags of a managed table.
+type TableBranchesResponse struct {
+	Table    TableRef      `json:"table"`
+	Branches []TableBranch `json:"branches"`
+}
+
+// CreateTableBranchRequest creates a branch or tag at the table's current snapshot.
+type CreateTableBranchRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Type is "branch" (default) or "tag".
+	Type string `json:"type,omitempty"`
+}
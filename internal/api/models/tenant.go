@@ -53,6 +53,7 @@ var ValidTenantPermissions = map[string]bool{
 	PermissionScalingWrite:   true,
 	PermissionAlertsRead:     true,
 	PermissionAlertsWrite:    true,
+	PermissionQueryWrite:     true,
 }
 
 // IsValidPermission checks if a permission string is valid.
@@ -82,6 +83,7 @@ var TenantRolePermissions = map[TenantRole][]string{
 		PermissionAPIKeysRead, PermissionAPIKeysWrite,
 		PermissionScalingRead, PermissionScalingWrite,
 		PermissionAlertsRead, PermissionAlertsWrite,
+		PermissionQueryWrite,
 	},
 	TenantRoleOperator: {
 		PermissionTenantsRead,
@@ -0,0 +1,290 @@
+// Package openapi generates the OpenAPI 3 document of the management API
+// from its registered routes and the model types of their requests and
+// responses.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.0.3"
+
+// Operation documents a route. The request and response schemas are
+// generated from the types of Request and Response.
+type Operation struct {
+	// Summary is a short description of the operation.
+	Summary string
+
+	// Description is a longer description of the operation.
+	Description string
+
+	// Tags group operations in the documentation.
+	Tags []string
+
+	// Query lists the query parameters of the operation.
+	Query []Param
+
+	// Request is a value of the JSON request body type, or nil for
+	// operations without a request body.
+	Request any
+
+	// Response is a value of the JSON response body type, or nil for
+	// operations that respond without a body.
+	Response any
+
+	// Status is the status code of a successful response. Defaults to 200,
+	// or 204 for DELETE operations without a Response.
+	Status int
+
+	// Public marks operations that do not require authentication.
+	Public bool
+}
+
+// Param describes a query parameter.
+type Param struct {
+	Name        string
+	Description string
+
+	// Type is the parameter type: string (the default), integer or boolean.
+	Type string
+
+	// Format is the format of string parameters, such as uuid or date-time.
+	Format string
+
+	Required bool
+}
+
+// Config configures a generated document.
+type Config struct {
+	// Info is the metadata of the document.
+	Info Info
+
+	// ErrorResponse is a value of the error response body type, documented
+	// as the default response of every operation.
+	ErrorResponse any
+}
+
+// Info is the metadata of a document.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       Info                                   `json:"info"`
+	Tags       []Tag                                  `json:"tags,omitempty"`
+	Paths      map[string]map[string]*OperationObject `json:"paths"`
+	Components Components                             `json:"components"`
+	Security   []map[string][]string                  `json:"security,omitempty"`
+}
+
+// Tag describes a group of operations.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// OperationObject is an operation of a document.
+type OperationObject struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the request body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes of a document.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is an authentication method of the API.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Build generates the document of routes. Routes with an operation in docs,
+// keyed by method and path as registered, such as "GET /api/v1/sources/:id",
+// are documented with it; the others are listed with their path parameters
+// only.
+func Build(cfg Config, routes gin.RoutesInfo, docs map[string]Operation) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    cfg.Info,
+		Paths:   make(map[string]map[string]*OperationObject),
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"apiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}},
+	}
+	var errorResponse *Response
+	if cfg.ErrorResponse != nil {
+		errorResponse = &Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/problem+json": {Schema: g.schemaFor(cfg.ErrorResponse)}},
+		}
+	}
+
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		op := docs[route.Method+" "+route.Path]
+		path, params := convertPath(route.Path)
+
+		ob := &OperationObject{
+			OperationID: operationID(route.Method, route.Path),
+			Summary:     op.Summary,
+			Description: op.Description,
+			Tags:        op.Tags,
+			Parameters:  params,
+			Responses:   make(map[string]*Response),
+		}
+		if op.Public {
+			// An empty requirement makes authentication optional
+			ob.Security = []map[string][]string{{}}
+		}
+		for _, p := range op.Query {
+			ob.Parameters = append(ob.Parameters, Parameter{
+				Name:        p.Name,
+				In:          "query",
+				Description: p.Description,
+				Required:    p.Required,
+				Schema:      paramSchema(p),
+			})
+		}
+		if op.Request != nil {
+			ob.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: g.schemaFor(op.Request)}},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+			if op.Response == nil && route.Method == http.MethodDelete {
+				status = http.StatusNoContent
+			}
+		}
+		success := &Response{Description: http.StatusText(status)}
+		if op.Response != nil {
+			success.Content = map[string]MediaType{"application/json": {Schema: g.schemaFor(op.Response)}}
+		}
+		ob.Responses[strconv.Itoa(status)] = success
+		if errorResponse != nil {
+			ob.Responses["default"] = errorResponse
+		}
+
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OperationObject)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = ob
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
+
+// convertPath converts a gin route to an OpenAPI path and its path
+// parameters, such as /sources/:id to /sources/{id}.
+func convertPath(route string) (string, []Parameter) {
+	segments := strings.Split(route, "/")
+	var params []Parameter
+	for i, segment := range segments {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a unique operation ID from a method and route, such
+// as getSourcesById for GET /api/v1/sources/:id.
+func operationID(method, route string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(route, "/api/v1"), "/") {
+		if segment == "" {
+			continue
+		}
+		if segment[0] == ':' || segment[0] == '*' {
+			b.WriteString("By")
+			segment = segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// paramSchema returns the schema of a query parameter.
+func paramSchema(p Param) *Schema {
+	if p.Type == "" {
+		return &Schema{Type: "string", Format: p.Format}
+	}
+	return &Schema{Type: p.Type, Format: p.Format}
+}
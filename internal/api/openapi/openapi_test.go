@@ -0,0 +1,133 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type testProblem struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+type testSource struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	Port      int               `json:"port"`
+	Tables    []string          `json:"tables,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Parent    *testSource       `json:"parent,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	password  string
+}
+
+type testCreateSourceRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+	Port     int    `json:"port,omitempty"`
+}
+
+type testSourceResponse struct {
+	Source *testSource `json:"source"`
+}
+
+func TestBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {}
+	router.GET("/health", handler)
+	router.POST("/api/v1/sources", handler)
+	router.GET("/api/v1/sources/:id", handler)
+	router.DELETE("/api/v1/sources/:id", handler)
+
+	doc := Build(Config{Info: Info{Title: "Test", Version: "1"}, ErrorResponse: testProblem{}}, router.Routes(), map[string]Operation{
+		"GET /health": {Summary: "Health", Tags: []string{"health"}, Public: true},
+		"POST /api/v1/sources": {
+			Summary:  "Create a source",
+			Tags:     []string{"sources"},
+			Request:  testCreateSourceRequest{},
+			Response: testSourceResponse{},
+			Status:   http.StatusCreated,
+		},
+		"GET /api/v1/sources/:id": {
+			Summary:  "Get a source",
+			Tags:     []string{"sources"},
+			Query:    []Param{{Name: "verbose", Type: "boolean"}},
+			Response: testSourceResponse{},
+		},
+	})
+
+	// The document must be valid JSON
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("failed to marshal document: %v", err)
+	}
+
+	if len(doc.Tags) != 2 || doc.Tags[0].Name != "health" || doc.Tags[1].Name != "sources" {
+		t.Errorf("Tags = %+v, want health and sources", doc.Tags)
+	}
+
+	health := doc.Paths["/health"]["get"]
+	if health == nil || len(health.Security) != 1 || len(health.Security[0]) != 0 {
+		t.Errorf("GET /health = %+v, want optional authentication", health)
+	}
+
+	create := doc.Paths["/api/v1/sources"]["post"]
+	if create == nil || create.OperationID != "postSources" {
+		t.Fatalf("POST /api/v1/sources = %+v", create)
+	}
+	if create.Responses["201"] == nil || create.Responses["default"] == nil {
+		t.Errorf("responses = %v, want 201 and the error response", create.Responses)
+	}
+	request := create.RequestBody.Content["application/json"].Schema
+	if request.Ref != "#/components/schemas/testCreateSourceRequest" {
+		t.Errorf("request schema = %+v, want a component reference", request)
+	}
+	if required := doc.Components.Schemas["testCreateSourceRequest"].Required; len(required) != 2 {
+		t.Errorf("required = %v, want name and password", required)
+	}
+
+	get := doc.Paths["/api/v1/sources/{id}"]["get"]
+	if get == nil || get.OperationID != "getSourcesById" || len(get.Parameters) != 2 {
+		t.Fatalf("GET /api/v1/sources/{id} = %+v, want the path and query parameters", get)
+	}
+	if p := get.Parameters[0]; p.In != "path" || p.Name != "id" || !p.Required {
+		t.Errorf("path parameter = %+v", p)
+	}
+
+	source := doc.Components.Schemas["testSource"]
+	if source == nil {
+		t.Fatal("testSource schema not generated")
+	}
+	if _, ok := source.Properties["password"]; ok {
+		t.Error("unexported field documented")
+	}
+	if s := source.Properties["id"]; s.Type != "string" || s.Format != "uuid" {
+		t.Errorf("id schema = %+v", s)
+	}
+	if s := source.Properties["created_at"]; s.Format != "date-time" {
+		t.Errorf("created_at schema = %+v", s)
+	}
+	if s := source.Properties["tables"]; s.Type != "array" || s.Items.Type != "string" {
+		t.Errorf("tables schema = %+v", s)
+	}
+	if s := source.Properties["labels"]; s.Type != "object" || s.AdditionalProperties.Type != "string" {
+		t.Errorf("labels schema = %+v", s)
+	}
+	if s := source.Properties["parent"]; s.Ref != "#/components/schemas/testSource" {
+		t.Errorf("parent schema = %+v, want a reference to itself", s)
+	}
+	if doc.Components.Schemas["testProblem"] == nil {
+		t.Error("error response schema not generated")
+	}
+
+	// Undocumented routes are still listed
+	remove := doc.Paths["/api/v1/sources/{id}"]["delete"]
+	if remove == nil || remove.Responses["204"] == nil {
+		t.Errorf("DELETE /api/v1/sources/{id} = %+v, want a 204 response", remove)
+	}
+}
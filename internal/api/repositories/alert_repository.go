@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/janovincze/philotes/internal/alerting"
 	"github.com/janovincze/philotes/internal/api/models"
@@ -30,7 +31,8 @@ var (
 
 // AlertRepository handles database operations for alerting.
 type AlertRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	hub *alerting.Hub
 }
 
 // NewAlertRepository creates a new AlertRepository.
@@ -38,38 +40,71 @@ func NewAlertRepository(db *sql.DB) *AlertRepository {
 	return &AlertRepository{db: db}
 }
 
+// SetEventHub sets the hub that alert instance lifecycle changes are
+// published to.
+func (r *AlertRepository) SetEventHub(hub *alerting.Hub) {
+	r.hub = hub
+}
+
+// publish sends an instance lifecycle event to the event hub, if one is set.
+func (r *AlertRepository) publish(eventType alerting.EventType, instance *alerting.AlertInstance, tenantID uuid.NullUUID) {
+	if r.hub == nil {
+		return
+	}
+	event := alerting.AlertEvent{Type: eventType, Alert: *instance}
+	if tenantID.Valid {
+		event.TenantID = &tenantID.UUID
+	}
+	r.hub.Publish(event)
+}
+
+// instanceTenantColumn selects the tenant of an alert instance's rule, for
+// RETURNING clauses of statements on philotes.alert_instances.
+const instanceTenantColumn = `(SELECT r.tenant_id FROM philotes.alert_rules r WHERE r.id = alert_instances.rule_id)`
+
 // alertRuleRow represents a database row for an alert rule.
 type alertRuleRow struct {
-	ID              uuid.UUID
-	Name            string
-	Description     sql.NullString
-	MetricName      string
-	Operator        string
-	Threshold       float64
-	DurationSeconds int
-	Severity        string
-	Labels          []byte
-	Annotations     []byte
-	Enabled         bool
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID                             uuid.UUID
+	TenantID                       uuid.NullUUID
+	Name                           string
+	Description                    sql.NullString
+	MetricName                     string
+	Operator                       string
+	Threshold                      float64
+	Expression                     string
+	DurationSeconds                int
+	MinNotificationIntervalSeconds int
+	Source                         string
+	Severity                       string
+	Labels                         []byte
+	Annotations                    []byte
+	Enabled                        bool
+	CreatedAt                      time.Time
+	UpdatedAt                      time.Time
 }
 
 // toModel converts a database row to an alerting model.
 func (r *alertRuleRow) toModel() *alerting.AlertRule {
 	rule := &alerting.AlertRule{
-		ID:              r.ID,
-		Name:            r.Name,
-		MetricName:      r.MetricName,
-		Operator:        alerting.Operator(r.Operator),
-		Threshold:       r.Threshold,
-		DurationSeconds: r.DurationSeconds,
-		Severity:        alerting.AlertSeverity(r.Severity),
-		Enabled:         r.Enabled,
-		CreatedAt:       r.CreatedAt,
-		UpdatedAt:       r.UpdatedAt,
+		ID:                             r.ID,
+		Name:                           r.Name,
+		MetricName:                     r.MetricName,
+		Operator:                       alerting.Operator(r.Operator),
+		Threshold:                      r.Threshold,
+		Expression:                     r.Expression,
+		DurationSeconds:                r.DurationSeconds,
+		MinNotificationIntervalSeconds: r.MinNotificationIntervalSeconds,
+		Source:                         alerting.MetricSource(r.Source),
+		Severity:                       alerting.AlertSeverity(r.Severity),
+		Enabled:                        r.Enabled,
+		CreatedAt:                      r.CreatedAt,
+		UpdatedAt:                      r.UpdatedAt,
+	}
+
+	if r.TenantID.Valid {
+		tenantID := r.TenantID.UUID
+		rule.TenantID = &tenantID
 	}
-
 	if r.Description.Valid {
 		rule.Description = r.Description.String
 	}
@@ -88,7 +123,8 @@ func (r *alertRuleRow) toModel() *alerting.AlertRule {
 }
 
 // CreateRule creates a new alert rule in the database.
-func (r *AlertRepository) CreateRule(ctx context.Context, req *models.CreateAlertRuleRequest) (*alerting.AlertRule, error) {
+// The rule belongs to tenantID, or to no tenant when it is nil.
+func (r *AlertRepository) CreateRule(ctx context.Context, tenantID *uuid.UUID, req *models.CreateAlertRuleRequest) (*alerting.AlertRule, error) {
 	labelsJSON, err := json.Marshal(req.Labels)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal labels: %w", err)
@@ -105,11 +141,11 @@ func (r *AlertRepository) CreateRule(ctx context.Context, req *models.CreateAler
 
 	query := `
 		INSERT INTO philotes.alert_rules (
-			name, description, metric_name, operator, threshold,
-			duration_seconds, severity, labels, annotations, enabled
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, name, description, metric_name, operator, threshold,
-			duration_seconds, severity, labels, annotations, enabled, created_at, updated_at
+			name, description, metric_name, operator, threshold, expression,
+			duration_seconds, min_notification_interval_seconds, source, severity, labels, annotations, enabled, tenant_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, tenant_id, name, description, metric_name, operator, threshold, expression,
+			duration_seconds, min_notification_interval_seconds, source, severity, labels, annotations, enabled, created_at, updated_at
 	`
 
 	var row alertRuleRow
@@ -119,19 +155,27 @@ func (r *AlertRepository) CreateRule(ctx context.Context, req *models.CreateAler
 		req.MetricName,
 		req.Operator,
 		req.Threshold,
+		req.Expression,
 		req.DurationSeconds,
+		req.MinNotificationIntervalSeconds,
+		req.Source,
 		req.Severity,
 		labelsJSON,
 		annotationsJSON,
 		enabled,
+		tenantID,
 	).Scan(
 		&row.ID,
+		&row.TenantID,
 		&row.Name,
 		&row.Description,
 		&row.MetricName,
 		&row.Operator,
 		&row.Threshold,
+		&row.Expression,
 		&row.DurationSeconds,
+		&row.MinNotificationIntervalSeconds,
+		&row.Source,
 		&row.Severity,
 		&row.Labels,
 		&row.Annotations,
@@ -149,11 +193,41 @@ func (r *AlertRepository) CreateRule(ctx context.Context, req *models.CreateAler
 	return row.toModel(), nil
 }
 
+// listRulesQuery builds the query listing alert rules.
+func listRulesQuery(tenantID *uuid.UUID, enabledOnly bool, limit, offset int) (string, []any) {
+	query := `
+		SELECT id, tenant_id, name, description, metric_name, operator, threshold, expression,
+			duration_seconds, min_notification_interval_seconds, source, severity, labels, annotations, enabled, created_at, updated_at
+		FROM philotes.alert_rules
+		WHERE 1=1
+	`
+	args := []any{}
+	argIdx := 1
+
+	if tenantID != nil {
+		query += fmt.Sprintf(" AND tenant_id = $%d", argIdx)
+		args = append(args, *tenantID)
+		argIdx++
+	}
+	if enabledOnly {
+		query += " AND enabled = true"
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+		args = append(args, limit, offset)
+	}
+
+	return query, args
+}
+
 // GetRule retrieves an alert rule by its ID.
 func (r *AlertRepository) GetRule(ctx context.Context, id uuid.UUID) (*alerting.AlertRule, error) {
 	query := `
-		SELECT id, name, description, metric_name, operator, threshold,
-			duration_seconds, severity, labels, annotations, enabled, created_at, updated_at
+		SELECT id, tenant_id, name, description, metric_name, operator, threshold, expression,
+			duration_seconds, min_notification_interval_seconds, source, severity, labels, annotations, enabled, created_at, updated_at
 		FROM philotes.alert_rules
 		WHERE id = $1
 	`
@@ -161,12 +235,16 @@ func (r *AlertRepository) GetRule(ctx context.Context, id uuid.UUID) (*alerting.
 	var row alertRuleRow
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&row.ID,
+		&row.TenantID,
 		&row.Name,
 		&row.Description,
 		&row.MetricName,
 		&row.Operator,
 		&row.Threshold,
+		&row.Expression,
 		&row.DurationSeconds,
+		&row.MinNotificationIntervalSeconds,
+		&row.Source,
 		&row.Severity,
 		&row.Labels,
 		&row.Annotations,
@@ -184,34 +262,18 @@ func (r *AlertRepository) GetRule(ctx context.Context, id uuid.UUID) (*alerting.
 	return row.toModel(), nil
 }
 
-// ListRules retrieves all alert rules.
+// ListRules retrieves all alert rules, optionally of a single tenant.
 // Note: For internal use by the alerting manager (enabledOnly=true), this returns all enabled rules.
 // For API pagination, use ListRulesPaginated instead.
-func (r *AlertRepository) ListRules(ctx context.Context, enabledOnly bool) ([]alerting.AlertRule, error) {
-	return r.ListRulesPaginated(ctx, enabledOnly, 0, 0)
+func (r *AlertRepository) ListRules(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]alerting.AlertRule, error) {
+	return r.ListRulesPaginated(ctx, tenantID, enabledOnly, 0, 0)
 }
 
 // ListRulesPaginated retrieves alert rules with optional pagination.
-// If limit is 0, all matching rules are returned.
-func (r *AlertRepository) ListRulesPaginated(ctx context.Context, enabledOnly bool, limit, offset int) ([]alerting.AlertRule, error) {
-	query := `
-		SELECT id, name, description, metric_name, operator, threshold,
-			duration_seconds, severity, labels, annotations, enabled, created_at, updated_at
-		FROM philotes.alert_rules
-	`
-	args := []any{}
-	argIdx := 1
-
-	if enabledOnly {
-		query += " WHERE enabled = true"
-	}
-
-	query += " ORDER BY created_at DESC"
-
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-		args = append(args, limit, offset)
-	}
+// If limit is 0, all matching rules are returned; if tenantID is nil,
+// rules of every tenant are.
+func (r *AlertRepository) ListRulesPaginated(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool, limit, offset int) ([]alerting.AlertRule, error) {
+	query, args := listRulesQuery(tenantID, enabledOnly, limit, offset)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -224,12 +286,16 @@ func (r *AlertRepository) ListRulesPaginated(ctx context.Context, enabledOnly bo
 		var row alertRuleRow
 		err := rows.Scan(
 			&row.ID,
+			&row.TenantID,
 			&row.Name,
 			&row.Description,
 			&row.MetricName,
 			&row.Operator,
 			&row.Threshold,
+			&row.Expression,
 			&row.DurationSeconds,
+			&row.MinNotificationIntervalSeconds,
+			&row.Source,
 			&row.Severity,
 			&row.Labels,
 			&row.Annotations,
@@ -273,10 +339,23 @@ func (r *AlertRepository) UpdateRule(ctx context.Context, id uuid.UUID, req *mod
 		args = append(args, nullString(*req.Description))
 		argIdx++
 	}
+	if req.Expression != nil {
+		query += fmt.Sprintf(", expression = $%d", argIdx)
+		args = append(args, *req.Expression)
+		argIdx++
+		// An expression replaces the metric condition
+		if *req.Expression != "" {
+			query += ", metric_name = '', operator = '', threshold = 0"
+		}
+	}
 	if req.MetricName != nil {
 		query += fmt.Sprintf(", metric_name = $%d", argIdx)
 		args = append(args, *req.MetricName)
 		argIdx++
+		// A metric condition replaces the expression
+		if *req.MetricName != "" && req.Expression == nil {
+			query += ", expression = ''"
+		}
 	}
 	if req.Operator != nil {
 		query += fmt.Sprintf(", operator = $%d", argIdx)
@@ -293,6 +372,16 @@ func (r *AlertRepository) UpdateRule(ctx context.Context, id uuid.UUID, req *mod
 		args = append(args, *req.DurationSeconds)
 		argIdx++
 	}
+	if req.MinNotificationIntervalSeconds != nil {
+		query += fmt.Sprintf(", min_notification_interval_seconds = $%d", argIdx)
+		args = append(args, *req.MinNotificationIntervalSeconds)
+		argIdx++
+	}
+	if req.Source != nil {
+		query += fmt.Sprintf(", source = $%d", argIdx)
+		args = append(args, *req.Source)
+		argIdx++
+	}
 	if req.Severity != nil {
 		query += fmt.Sprintf(", severity = $%d", argIdx)
 		args = append(args, *req.Severity)
@@ -433,10 +522,12 @@ func (r *AlertRepository) CreateInstance(ctx context.Context, instance *alerting
 			rule_id, fingerprint, status, labels, annotations, current_value, fired_at
 		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, rule_id, fingerprint, status, labels, annotations, current_value,
-			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at
+			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at,
+			` + instanceTenantColumn + `
 	`
 
 	var row alertInstanceRow
+	var tenantID uuid.NullUUID
 	err = r.db.QueryRowContext(ctx, query,
 		instance.RuleID,
 		instance.Fingerprint,
@@ -459,12 +550,17 @@ func (r *AlertRepository) CreateInstance(ctx context.Context, instance *alerting
 		&row.AcknowledgedBy,
 		&row.CreatedAt,
 		&row.UpdatedAt,
+		&tenantID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create alert instance: %w", err)
 	}
 
-	return row.toModel(), nil
+	created := row.toModel()
+	if created.Status == alerting.StatusFiring {
+		r.publish(alerting.EventFired, created, tenantID)
+	}
+	return created, nil
 }
 
 // GetInstance retrieves an alert instance by its ID.
@@ -537,29 +633,10 @@ func (r *AlertRepository) GetInstanceByFingerprint(ctx context.Context, ruleID u
 	return row.toModel(), nil
 }
 
-// ListInstances retrieves alert instances with optional filtering.
-func (r *AlertRepository) ListInstances(ctx context.Context, status *alerting.AlertStatus, ruleID *uuid.UUID) ([]alerting.AlertInstance, error) {
-	query := `
-		SELECT id, rule_id, fingerprint, status, labels, annotations, current_value,
-			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at
-		FROM philotes.alert_instances
-		WHERE 1=1
-	`
-	args := []any{}
-	argIdx := 1
-
-	if status != nil {
-		query += fmt.Sprintf(" AND status = $%d", argIdx)
-		args = append(args, *status)
-		argIdx++
-	}
-	if ruleID != nil {
-		query += fmt.Sprintf(" AND rule_id = $%d", argIdx)
-		args = append(args, *ruleID)
-		argIdx++
-	}
-
-	query += " ORDER BY fired_at DESC"
+// ListInstances retrieves alert instances with optional filtering. Instances
+// belong to the tenant of their rule.
+func (r *AlertRepository) ListInstances(ctx context.Context, tenantID *uuid.UUID, status *alerting.AlertStatus, ruleID *uuid.UUID) ([]alerting.AlertInstance, error) {
+	query, args := listInstancesQuery(tenantID, status, ruleID)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -598,12 +675,49 @@ func (r *AlertRepository) ListInstances(ctx context.Context, status *alerting.Al
 	return instances, nil
 }
 
+// listInstancesQuery builds the query listing alert instances.
+func listInstancesQuery(tenantID *uuid.UUID, status *alerting.AlertStatus, ruleID *uuid.UUID) (string, []any) {
+	query := `
+		SELECT id, rule_id, fingerprint, status, labels, annotations, current_value,
+			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at
+		FROM philotes.alert_instances
+		WHERE 1=1
+	`
+	args := []any{}
+	argIdx := 1
+
+	if tenantID != nil {
+		query += fmt.Sprintf(" AND rule_id IN (SELECT id FROM philotes.alert_rules WHERE tenant_id = $%d)", argIdx)
+		args = append(args, *tenantID)
+		argIdx++
+	}
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, *status)
+		argIdx++
+	}
+	if ruleID != nil {
+		query += fmt.Sprintf(" AND rule_id = $%d", argIdx)
+		args = append(args, *ruleID)
+	}
+
+	query += " ORDER BY fired_at DESC"
+
+	return query, args
+}
+
 // UpdateInstance updates an alert instance in the database.
 func (r *AlertRepository) UpdateInstance(ctx context.Context, id uuid.UUID, status alerting.AlertStatus, currentValue *float64, resolvedAt *time.Time) error {
+	// Joining the row to itself returns its status from before the update
 	query := `
-		UPDATE philotes.alert_instances
+		UPDATE philotes.alert_instances AS i
 		SET status = $1, current_value = $2, resolved_at = $3, updated_at = NOW()
-		WHERE id = $4
+		FROM philotes.alert_instances AS prev
+		WHERE i.id = $4 AND prev.id = i.id
+		RETURNING prev.status, i.id, i.rule_id, i.fingerprint, i.status, i.labels, i.annotations,
+			i.current_value, i.fired_at, i.resolved_at, i.acknowledged_at, i.acknowledged_by,
+			i.created_at, i.updated_at,
+			(SELECT r.tenant_id FROM philotes.alert_rules r WHERE r.id = i.rule_id)
 	`
 
 	var cv sql.NullFloat64
@@ -616,18 +730,42 @@ func (r *AlertRepository) UpdateInstance(ctx context.Context, id uuid.UUID, stat
 		ra = sql.NullTime{Time: *resolvedAt, Valid: true}
 	}
 
-	result, err := r.db.ExecContext(ctx, query, status, cv, ra, id)
+	var prevStatus string
+	var row alertInstanceRow
+	var tenantID uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, query, status, cv, ra, id).Scan(
+		&prevStatus,
+		&row.ID,
+		&row.RuleID,
+		&row.Fingerprint,
+		&row.Status,
+		&row.Labels,
+		&row.Annotations,
+		&row.CurrentValue,
+		&row.FiredAt,
+		&row.ResolvedAt,
+		&row.AcknowledgedAt,
+		&row.AcknowledgedBy,
+		&row.CreatedAt,
+		&row.UpdatedAt,
+		&tenantID,
+	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlertInstanceNotFound
+		}
 		return fmt.Errorf("failed to update alert instance: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return ErrAlertInstanceNotFound
+	// Evaluations update firing instances with their latest value; only
+	// status changes are lifecycle events
+	if prevStatus != string(status) {
+		switch status {
+		case alerting.StatusFiring:
+			r.publish(alerting.EventFired, row.toModel(), tenantID)
+		case alerting.StatusResolved:
+			r.publish(alerting.EventResolved, row.toModel(), tenantID)
+		}
 	}
 
 	return nil
@@ -639,23 +777,130 @@ func (r *AlertRepository) AcknowledgeInstance(ctx context.Context, id uuid.UUID,
 		UPDATE philotes.alert_instances
 		SET acknowledged_at = NOW(), acknowledged_by = $1, updated_at = NOW()
 		WHERE id = $2
+		RETURNING id, rule_id, fingerprint, status, labels, annotations, current_value,
+			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at,
+			` + instanceTenantColumn + `
 	`
 
-	result, err := r.db.ExecContext(ctx, query, acknowledgedBy, id)
+	var row alertInstanceRow
+	var tenantID uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, query, acknowledgedBy, id).Scan(
+		&row.ID,
+		&row.RuleID,
+		&row.Fingerprint,
+		&row.Status,
+		&row.Labels,
+		&row.Annotations,
+		&row.CurrentValue,
+		&row.FiredAt,
+		&row.ResolvedAt,
+		&row.AcknowledgedAt,
+		&row.AcknowledgedBy,
+		&row.CreatedAt,
+		&row.UpdatedAt,
+		&tenantID,
+	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlertInstanceNotFound
+		}
 		return fmt.Errorf("failed to acknowledge alert instance: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	r.publish(alerting.EventAcknowledged, row.toModel(), tenantID)
+	return nil
+}
+
+// AcknowledgeInstances acknowledges the instances among ids that are not yet
+// acknowledged, recording a history entry with message and metadata for each,
+// in one transaction. It returns the instances it acknowledged.
+func (r *AlertRepository) AcknowledgeInstances(ctx context.Context, ids []uuid.UUID, acknowledgedBy, message string, metadata map[string]any) ([]alerting.AlertInstance, error) {
+	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return ErrAlertInstanceNotFound
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
 	}
 
-	return nil
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE philotes.alert_instances
+		SET acknowledged_at = NOW(), acknowledged_by = $1, updated_at = NOW()
+		WHERE id = ANY($2::uuid[]) AND acknowledged_at IS NULL
+		RETURNING id, rule_id, fingerprint, status, labels, annotations, current_value,
+			fired_at, resolved_at, acknowledged_at, acknowledged_by, created_at, updated_at,
+			` + instanceTenantColumn + `
+	`
+
+	rows, err := tx.QueryContext(ctx, query, acknowledgedBy, pq.Array(idStrings))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge alert instances: %w", err)
+	}
+
+	var instances []alerting.AlertInstance
+	var tenantIDs []uuid.NullUUID
+	for rows.Next() {
+		var row alertInstanceRow
+		var tenantID uuid.NullUUID
+		if err := rows.Scan(
+			&row.ID,
+			&row.RuleID,
+			&row.Fingerprint,
+			&row.Status,
+			&row.Labels,
+			&row.Annotations,
+			&row.CurrentValue,
+			&row.FiredAt,
+			&row.ResolvedAt,
+			&row.AcknowledgedAt,
+			&row.AcknowledgedBy,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&tenantID,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan alert instance: %w", err)
+		}
+		instances = append(instances, *row.toModel())
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to iterate alert instances: %w", err)
+	}
+	rows.Close()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO philotes.alert_history (alert_id, rule_id, event_type, message, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare history insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, instance := range instances {
+		if _, err := stmt.ExecContext(ctx, instance.ID, instance.RuleID, alerting.EventAcknowledged, message, metadataJSON); err != nil {
+			return nil, fmt.Errorf("failed to create alert history: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit acknowledgments: %w", err)
+	}
+
+	for i := range instances {
+		r.publish(alerting.EventAcknowledged, &instances[i], tenantIDs[i])
+	}
+	return instances, nil
 }
 
 // CreateHistory creates an alert history entry.
@@ -796,6 +1041,7 @@ type silenceRow struct {
 	EndsAt    time.Time
 	CreatedBy string
 	Comment   sql.NullString
+	Schedule  []byte
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -819,6 +1065,11 @@ func (r *silenceRow) toModel() *alerting.AlertSilence {
 	if r.Comment.Valid {
 		silence.Comment = r.Comment.String
 	}
+	if r.Schedule != nil {
+		if err := json.Unmarshal(r.Schedule, &silence.Schedule); err != nil {
+			slog.Warn("failed to unmarshal silence schedule", "silence_id", r.ID, "error", err)
+		}
+	}
 
 	return silence
 }
@@ -830,10 +1081,18 @@ func (r *AlertRepository) CreateSilence(ctx context.Context, req *models.CreateS
 		return nil, fmt.Errorf("failed to marshal matchers: %w", err)
 	}
 
+	var scheduleJSON []byte
+	if req.Schedule != nil {
+		scheduleJSON, err = json.Marshal(req.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schedule: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO philotes.alert_silences (matchers, starts_at, ends_at, created_by, comment)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, matchers, starts_at, ends_at, created_by, comment, created_at, updated_at
+		INSERT INTO philotes.alert_silences (matchers, starts_at, ends_at, created_by, comment, schedule)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, matchers, starts_at, ends_at, created_by, comment, schedule, created_at, updated_at
 	`
 
 	var row silenceRow
@@ -843,6 +1102,7 @@ func (r *AlertRepository) CreateSilence(ctx context.Context, req *models.CreateS
 		req.EndsAt,
 		req.CreatedBy,
 		nullString(req.Comment),
+		scheduleJSON,
 	).Scan(
 		&row.ID,
 		&row.Matchers,
@@ -850,6 +1110,7 @@ func (r *AlertRepository) CreateSilence(ctx context.Context, req *models.CreateS
 		&row.EndsAt,
 		&row.CreatedBy,
 		&row.Comment,
+		&row.Schedule,
 		&row.CreatedAt,
 		&row.UpdatedAt,
 	)
@@ -863,7 +1124,7 @@ func (r *AlertRepository) CreateSilence(ctx context.Context, req *models.CreateS
 // GetSilence retrieves a silence by its ID.
 func (r *AlertRepository) GetSilence(ctx context.Context, id uuid.UUID) (*alerting.AlertSilence, error) {
 	query := `
-		SELECT id, matchers, starts_at, ends_at, created_by, comment, created_at, updated_at
+		SELECT id, matchers, starts_at, ends_at, created_by, comment, schedule, created_at, updated_at
 		FROM philotes.alert_silences
 		WHERE id = $1
 	`
@@ -876,6 +1137,7 @@ func (r *AlertRepository) GetSilence(ctx context.Context, id uuid.UUID) (*alerti
 		&row.EndsAt,
 		&row.CreatedBy,
 		&row.Comment,
+		&row.Schedule,
 		&row.CreatedAt,
 		&row.UpdatedAt,
 	)
@@ -889,20 +1151,24 @@ func (r *AlertRepository) GetSilence(ctx context.Context, id uuid.UUID) (*alerti
 	return row.toModel(), nil
 }
 
-// ListSilences retrieves all silences, optionally filtering to only active ones.
+// ListSilences retrieves all silences, optionally filtering to only active
+// ones. Recurring silences are only active within their schedule's windows.
 func (r *AlertRepository) ListSilences(ctx context.Context, activeOnly bool) ([]alerting.AlertSilence, error) {
 	query := `
-		SELECT id, matchers, starts_at, ends_at, created_by, comment, created_at, updated_at
+		SELECT id, matchers, starts_at, ends_at, created_by, comment, schedule, created_at, updated_at
 		FROM philotes.alert_silences
 	`
 
+	args := []any{}
+	now := time.Now()
 	if activeOnly {
-		query += " WHERE starts_at <= NOW() AND ends_at > NOW()"
+		query += " WHERE starts_at <= $1 AND ends_at > $1"
+		args = append(args, now)
 	}
 
 	query += " ORDER BY created_at DESC"
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list silences: %w", err)
 	}
@@ -918,13 +1184,18 @@ func (r *AlertRepository) ListSilences(ctx context.Context, activeOnly bool) ([]
 			&row.EndsAt,
 			&row.CreatedBy,
 			&row.Comment,
+			&row.Schedule,
 			&row.CreatedAt,
 			&row.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan silence row: %w", err)
 		}
-		silences = append(silences, *row.toModel())
+		silence := row.toModel()
+		if activeOnly && !silence.ActiveAt(now) {
+			continue
+		}
+		silences = append(silences, *silence)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -958,6 +1229,7 @@ func (r *AlertRepository) DeleteSilence(ctx context.Context, id uuid.UUID) error
 // channelRow represents a database row for a notification channel.
 type channelRow struct {
 	ID        uuid.UUID
+	TenantID  uuid.NullUUID
 	Name      string
 	Type      string
 	Config    []byte
@@ -977,6 +1249,10 @@ func (r *channelRow) toModel() *alerting.NotificationChannel {
 		UpdatedAt: r.UpdatedAt,
 	}
 
+	if r.TenantID.Valid {
+		tenantID := r.TenantID.UUID
+		channel.TenantID = &tenantID
+	}
 	if r.Config != nil {
 		if err := json.Unmarshal(r.Config, &channel.Config); err != nil {
 			slog.Warn("failed to unmarshal channel config", "channel_id", r.ID, "error", err)
@@ -987,7 +1263,8 @@ func (r *channelRow) toModel() *alerting.NotificationChannel {
 }
 
 // CreateChannel creates a new notification channel in the database.
-func (r *AlertRepository) CreateChannel(ctx context.Context, req *models.CreateChannelRequest) (*alerting.NotificationChannel, error) {
+// The channel belongs to tenantID, or to no tenant when it is nil.
+func (r *AlertRepository) CreateChannel(ctx context.Context, tenantID *uuid.UUID, req *models.CreateChannelRequest) (*alerting.NotificationChannel, error) {
 	configJSON, err := json.Marshal(req.Config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal config: %w", err)
@@ -999,9 +1276,9 @@ func (r *AlertRepository) CreateChannel(ctx context.Context, req *models.CreateC
 	}
 
 	query := `
-		INSERT INTO philotes.notification_channels (name, type, config, enabled)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, type, config, enabled, created_at, updated_at
+		INSERT INTO philotes.notification_channels (name, type, config, enabled, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, tenant_id, name, type, config, enabled, created_at, updated_at
 	`
 
 	var row channelRow
@@ -1010,8 +1287,10 @@ func (r *AlertRepository) CreateChannel(ctx context.Context, req *models.CreateC
 		req.Type,
 		configJSON,
 		enabled,
+		tenantID,
 	).Scan(
 		&row.ID,
+		&row.TenantID,
 		&row.Name,
 		&row.Type,
 		&row.Config,
@@ -1032,7 +1311,7 @@ func (r *AlertRepository) CreateChannel(ctx context.Context, req *models.CreateC
 // GetChannel retrieves a notification channel by its ID.
 func (r *AlertRepository) GetChannel(ctx context.Context, id uuid.UUID) (*alerting.NotificationChannel, error) {
 	query := `
-		SELECT id, name, type, config, enabled, created_at, updated_at
+		SELECT id, tenant_id, name, type, config, enabled, created_at, updated_at
 		FROM philotes.notification_channels
 		WHERE id = $1
 	`
@@ -1040,6 +1319,7 @@ func (r *AlertRepository) GetChannel(ctx context.Context, id uuid.UUID) (*alerti
 	var row channelRow
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&row.ID,
+		&row.TenantID,
 		&row.Name,
 		&row.Type,
 		&row.Config,
@@ -1057,20 +1337,12 @@ func (r *AlertRepository) GetChannel(ctx context.Context, id uuid.UUID) (*alerti
 	return row.toModel(), nil
 }
 
-// ListChannels retrieves all notification channels.
-func (r *AlertRepository) ListChannels(ctx context.Context, enabledOnly bool) ([]alerting.NotificationChannel, error) {
-	query := `
-		SELECT id, name, type, config, enabled, created_at, updated_at
-		FROM philotes.notification_channels
-	`
-
-	if enabledOnly {
-		query += " WHERE enabled = true"
-	}
-
-	query += " ORDER BY created_at DESC"
+// ListChannels retrieves all notification channels, optionally of a single
+// tenant.
+func (r *AlertRepository) ListChannels(ctx context.Context, tenantID *uuid.UUID, enabledOnly bool) ([]alerting.NotificationChannel, error) {
+	query, args := listChannelsQuery(tenantID, enabledOnly)
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list notification channels: %w", err)
 	}
@@ -1081,6 +1353,7 @@ func (r *AlertRepository) ListChannels(ctx context.Context, enabledOnly bool) ([
 		var row channelRow
 		err := rows.Scan(
 			&row.ID,
+			&row.TenantID,
 			&row.Name,
 			&row.Type,
 			&row.Config,
@@ -1101,6 +1374,28 @@ func (r *AlertRepository) ListChannels(ctx context.Context, enabledOnly bool) ([
 	return channels, nil
 }
 
+// listChannelsQuery builds the query listing notification channels.
+func listChannelsQuery(tenantID *uuid.UUID, enabledOnly bool) (string, []any) {
+	query := `
+		SELECT id, tenant_id, name, type, config, enabled, created_at, updated_at
+		FROM philotes.notification_channels
+		WHERE 1=1
+	`
+	args := []any{}
+
+	if tenantID != nil {
+		query += " AND tenant_id = $1"
+		args = append(args, *tenantID)
+	}
+	if enabledOnly {
+		query += " AND enabled = true"
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	return query, args
+}
+
 // UpdateChannel updates a notification channel in the database.
 func (r *AlertRepository) UpdateChannel(ctx context.Context, id uuid.UUID, req *models.UpdateChannelRequest) (*alerting.NotificationChannel, error) {
 	// First check if channel exists
@@ -1409,8 +1704,64 @@ func (r *AlertRepository) DeleteRoute(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// ListNotificationLog returns the last notification time of every alert on
+// every route.
+func (r *AlertRepository) ListNotificationLog(ctx context.Context) ([]alerting.NotificationLogEntry, error) {
+	query := `
+		SELECT route_id, fingerprint, notified_at
+		FROM philotes.alert_notification_log
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []alerting.NotificationLogEntry
+	for rows.Next() {
+		var e alerting.NotificationLogEntry
+		if err := rows.Scan(&e.RouteID, &e.Fingerprint, &e.NotifiedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification log row: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// RecordNotification stores when an alert was last notified through a route.
+func (r *AlertRepository) RecordNotification(ctx context.Context, routeID uuid.UUID, fingerprint string, notifiedAt time.Time) error {
+	query := `
+		INSERT INTO philotes.alert_notification_log (route_id, fingerprint, notified_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (route_id, fingerprint) DO UPDATE SET notified_at = EXCLUDED.notified_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, routeID, fingerprint, notifiedAt); err != nil {
+		return fmt.Errorf("failed to record notification: %w", err)
+	}
+	return nil
+}
+
+// DeleteNotificationLog removes the notification times of an alert on all
+// routes.
+func (r *AlertRepository) DeleteNotificationLog(ctx context.Context, fingerprint string) error {
+	query := `DELETE FROM philotes.alert_notification_log WHERE fingerprint = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, fingerprint); err != nil {
+		return fmt.Errorf("failed to delete notification log: %w", err)
+	}
+	return nil
+}
+
 // GetAlertSummary returns summary statistics for alerts.
-func (r *AlertRepository) GetAlertSummary(ctx context.Context) (*models.AlertSummaryResponse, error) {
+// Rules, alerts and channels are counted for tenantID only unless it is nil.
+func (r *AlertRepository) GetAlertSummary(ctx context.Context, tenantID *uuid.UUID) (*models.AlertSummaryResponse, error) {
 	summary := &models.AlertSummaryResponse{}
 
 	// Count total and enabled rules
@@ -1419,7 +1770,8 @@ func (r *AlertRepository) GetAlertSummary(ctx context.Context) (*models.AlertSum
 			COUNT(*) as total,
 			COUNT(*) FILTER (WHERE enabled = true) as enabled
 		FROM philotes.alert_rules
-	`).Scan(&summary.TotalRules, &summary.EnabledRules)
+		WHERE $1::uuid IS NULL OR tenant_id = $1
+	`, tenantID).Scan(&summary.TotalRules, &summary.EnabledRules)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count rules: %w", err)
 	}
@@ -1430,20 +1782,18 @@ func (r *AlertRepository) GetAlertSummary(ctx context.Context) (*models.AlertSum
 			COUNT(*) FILTER (WHERE status = 'firing') as firing,
 			COUNT(*) FILTER (WHERE status = 'resolved') as resolved
 		FROM philotes.alert_instances
-	`).Scan(&summary.FiringAlerts, &summary.ResolvedAlerts)
+		WHERE $1::uuid IS NULL OR rule_id IN (SELECT id FROM philotes.alert_rules WHERE tenant_id = $1)
+	`, tenantID).Scan(&summary.FiringAlerts, &summary.ResolvedAlerts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count alerts: %w", err)
 	}
 
-	// Count active silences
-	err = r.db.QueryRowContext(ctx, `
-		SELECT COUNT(*)
-		FROM philotes.alert_silences
-		WHERE starts_at <= NOW() AND ends_at > NOW()
-	`).Scan(&summary.ActiveSilences)
+	// Count active silences; recurring ones depend on their schedule
+	silences, err := r.ListSilences(ctx, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count silences: %w", err)
 	}
+	summary.ActiveSilences = len(silences)
 
 	// Count total and enabled channels
 	err = r.db.QueryRowContext(ctx, `
@@ -1451,7 +1801,8 @@ func (r *AlertRepository) GetAlertSummary(ctx context.Context) (*models.AlertSum
 			COUNT(*) as total,
 			COUNT(*) FILTER (WHERE enabled = true) as enabled
 		FROM philotes.notification_channels
-	`).Scan(&summary.TotalChannels, &summary.EnabledChannels)
+		WHERE $1::uuid IS NULL OR tenant_id = $1
+	`, tenantID).Scan(&summary.TotalChannels, &summary.EnabledChannels)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count channels: %w", err)
 	}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+func TestListRulesQuery(t *testing.T) {
+	tenantID := uuid.New()
+
+	query, args := listRulesQuery(nil, true, 0, 0)
+	if strings.Contains(query, "tenant_id =") || len(args) != 0 {
+		t.Errorf("unscoped query = %q, %v, want no tenant filter", query, args)
+	}
+
+	query, args = listRulesQuery(&tenantID, true, 10, 20)
+	if !strings.Contains(query, "AND tenant_id = $1") || !strings.Contains(query, "LIMIT $2 OFFSET $3") {
+		t.Errorf("scoped query = %q, want tenant filter at $1 and pagination after it", query)
+	}
+	if want := []any{tenantID, 10, 20}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestListChannelsQuery(t *testing.T) {
+	tenantID := uuid.New()
+
+	query, args := listChannelsQuery(&tenantID, false)
+	if !strings.Contains(query, "AND tenant_id = $1") || strings.Contains(query, "enabled = true") {
+		t.Errorf("query = %q, want only the tenant filter", query)
+	}
+	if want := []any{tenantID}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestListInstancesQuery(t *testing.T) {
+	tenantID := uuid.New()
+	ruleID := uuid.New()
+	status := alerting.StatusFiring
+
+	query, args := listInstancesQuery(&tenantID, &status, &ruleID)
+	for _, cond := range []string{
+		"rule_id IN (SELECT id FROM philotes.alert_rules WHERE tenant_id = $1)",
+		"status = $2",
+		"rule_id = $3",
+	} {
+		if !strings.Contains(query, cond) {
+			t.Errorf("query = %q, want condition %q", query, cond)
+		}
+	}
+	if want := []any{tenantID, status, ruleID}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	_, args = listInstancesQuery(nil, nil, &ruleID)
+	if want := []any{ruleID}; !reflect.DeepEqual(args, want) {
+		t.Errorf("unscoped args = %v, want %v", args, want)
+	}
+}
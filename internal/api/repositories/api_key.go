@@ -42,6 +42,9 @@ type apiKeyRow struct {
 	IsActive    bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	RateLimitRPS   sql.NullFloat64
+	RateLimitBurst sql.NullInt64
 }
 
 // toModel converts a database row to an API model.
@@ -62,6 +65,12 @@ func (r *apiKeyRow) toModel() *models.APIKey {
 	if r.ExpiresAt.Valid {
 		key.ExpiresAt = &r.ExpiresAt.Time
 	}
+	if r.RateLimitRPS.Valid && r.RateLimitBurst.Valid {
+		key.RateLimit = &models.APIKeyRateLimit{
+			RequestsPerSecond: r.RateLimitRPS.Float64,
+			BurstSize:         int(r.RateLimitBurst.Int64),
+		}
+	}
 	return key
 }
 
@@ -70,7 +79,8 @@ func (r *APIKeyRepository) Create(ctx context.Context, userID uuid.UUID, name, k
 	query := `
 		INSERT INTO philotes.api_keys (user_id, name, key_prefix, key_hash, permissions, expires_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at
+		RETURNING id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at,
+		          rate_limit_rps, rate_limit_burst
 	`
 
 	var row apiKeyRow
@@ -91,6 +101,8 @@ func (r *APIKeyRepository) Create(ctx context.Context, userID uuid.UUID, name, k
 		&row.IsActive,
 		&row.CreatedAt,
 		&row.UpdatedAt,
+		&row.RateLimitRPS,
+		&row.RateLimitBurst,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create api key: %w", err)
@@ -102,7 +114,8 @@ func (r *APIKeyRepository) Create(ctx context.Context, userID uuid.UUID, name, k
 // GetByID retrieves an API key by ID.
 func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
 	query := `
-		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at
+		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at,
+		       rate_limit_rps, rate_limit_burst
 		FROM philotes.api_keys
 		WHERE id = $1
 	`
@@ -120,6 +133,8 @@ func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.A
 		&row.IsActive,
 		&row.CreatedAt,
 		&row.UpdatedAt,
+		&row.RateLimitRPS,
+		&row.RateLimitBurst,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -134,7 +149,8 @@ func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.A
 // GetByHash retrieves an API key by its hash.
 func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
 	query := `
-		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at
+		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at,
+		       rate_limit_rps, rate_limit_burst
 		FROM philotes.api_keys
 		WHERE key_hash = $1 AND is_active = true
 	`
@@ -152,6 +168,8 @@ func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*mode
 		&row.IsActive,
 		&row.CreatedAt,
 		&row.UpdatedAt,
+		&row.RateLimitRPS,
+		&row.RateLimitBurst,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -166,7 +184,8 @@ func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*mode
 // ListByUserID retrieves all API keys for a user.
 func (r *APIKeyRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
 	query := `
-		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at
+		SELECT id, user_id, name, key_prefix, key_hash, permissions, last_used_at, expires_at, is_active, created_at, updated_at,
+		       rate_limit_rps, rate_limit_burst
 		FROM philotes.api_keys
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -193,6 +212,8 @@ func (r *APIKeyRepository) ListByUserID(ctx context.Context, userID uuid.UUID) (
 			&row.IsActive,
 			&row.CreatedAt,
 			&row.UpdatedAt,
+			&row.RateLimitRPS,
+			&row.RateLimitBurst,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan api key row: %w", err)
@@ -232,6 +253,38 @@ func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) err
 	return nil
 }
 
+// SetRateLimit sets the rate limit of an API key; nil removes it.
+func (r *APIKeyRepository) SetRateLimit(ctx context.Context, id uuid.UUID, limit *models.APIKeyRateLimit) error {
+	query := `
+		UPDATE philotes.api_keys
+		SET rate_limit_rps = $1, rate_limit_burst = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	var rps sql.NullFloat64
+	var burst sql.NullInt64
+	if limit != nil {
+		rps = sql.NullFloat64{Float64: limit.RequestsPerSecond, Valid: true}
+		burst = sql.NullInt64{Int64: int64(limit.BurstSize), Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, rps, burst, id)
+	if err != nil {
+		return fmt.Errorf("failed to set api key rate limit: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
 // Revoke deactivates an API key.
 func (r *APIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -23,11 +24,21 @@ func NewAuditRepository(db *sql.DB) *AuditRepository {
 	return &AuditRepository{db: db}
 }
 
-// Create creates a new audit log entry.
+// Create creates a new audit log entry. An entry without a tenant is
+// attributed to the tenant its API key or user belongs to, when that is
+// unambiguous, so tenant retention policies and legal holds apply to it.
 func (r *AuditRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	if log.TenantID == nil {
+		tenantID, err := r.resolveTenant(ctx, log.UserID, log.APIKeyID)
+		if err != nil {
+			return err
+		}
+		log.TenantID = tenantID
+	}
+
 	query := `
-		INSERT INTO philotes.audit_logs (user_id, api_key_id, action, resource_type, resource_id, ip_address, user_agent, details)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO philotes.audit_logs (user_id, api_key_id, tenant_id, action, resource_type, resource_id, ip_address, user_agent, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	var detailsJSON []byte
@@ -42,6 +53,7 @@ func (r *AuditRepository) Create(ctx context.Context, log *models.AuditLog) erro
 	_, err = r.db.ExecContext(ctx, query,
 		log.UserID,
 		log.APIKeyID,
+		log.TenantID,
 		log.Action,
 		nullString(log.ResourceType),
 		log.ResourceID,
@@ -56,10 +68,56 @@ func (r *AuditRepository) Create(ctx context.Context, log *models.AuditLog) erro
 	return nil
 }
 
+// resolveTenant returns the tenant of an API key or, failing that, of a user
+// who is a member of exactly one tenant. It returns nil when neither
+// identifies a single tenant.
+func (r *AuditRepository) resolveTenant(ctx context.Context, userID, apiKeyID *uuid.UUID) (*uuid.UUID, error) {
+	if apiKeyID != nil {
+		var tenantID sql.NullString
+		err := r.db.QueryRowContext(ctx,
+			`SELECT tenant_id FROM philotes.api_keys WHERE id = $1`, *apiKeyID,
+		).Scan(&tenantID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to resolve API key tenant: %w", err)
+		}
+		if id := parseNullUUID(tenantID); id != nil {
+			return id, nil
+		}
+	}
+	if userID == nil {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT tenant_id FROM philotes.tenant_members WHERE user_id = $1 LIMIT 2`, *userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve user tenant: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var tenantID uuid.UUID
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan user tenant: %w", err)
+		}
+		tenants = append(tenants, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to resolve user tenant: %w", err)
+	}
+	if len(tenants) != 1 {
+		return nil, nil
+	}
+	return &tenants[0], nil
+}
+
 // AuditListOptions contains options for listing audit logs.
 type AuditListOptions struct {
 	UserID       *uuid.UUID
 	APIKeyID     *uuid.UUID
+	TenantID     *uuid.UUID
 	Action       string
 	ResourceType string
 	ResourceID   *uuid.UUID
@@ -86,6 +144,11 @@ func (r *AuditRepository) List(ctx context.Context, opts AuditListOptions) ([]mo
 		args = append(args, *opts.APIKeyID)
 		argIdx++
 	}
+	if opts.TenantID != nil {
+		baseQuery += fmt.Sprintf(" AND tenant_id = $%d", argIdx)
+		args = append(args, *opts.TenantID)
+		argIdx++
+	}
 	if opts.Action != "" {
 		baseQuery += fmt.Sprintf(" AND action = $%d", argIdx)
 		args = append(args, opts.Action)
@@ -121,7 +184,7 @@ func (r *AuditRepository) List(ctx context.Context, opts AuditListOptions) ([]mo
 	}
 
 	// Get logs with pagination
-	selectQuery := `SELECT id, user_id, api_key_id, action, resource_type, resource_id, ip_address, user_agent, details, created_at ` +
+	selectQuery := `SELECT id, user_id, api_key_id, tenant_id, action, resource_type, resource_id, ip_address, user_agent, details, created_at ` +
 		baseQuery + " ORDER BY created_at DESC"
 
 	if opts.Limit > 0 {
@@ -143,7 +206,7 @@ func (r *AuditRepository) List(ctx context.Context, opts AuditListOptions) ([]mo
 	var logs []models.AuditLog
 	for rows.Next() {
 		var log models.AuditLog
-		var userID, apiKeyID, resourceID sql.NullString
+		var userID, apiKeyID, tenantID, resourceID sql.NullString
 		var resourceType, ipAddress, userAgent sql.NullString
 		var detailsJSON []byte
 
@@ -151,6 +214,7 @@ func (r *AuditRepository) List(ctx context.Context, opts AuditListOptions) ([]mo
 			&log.ID,
 			&userID,
 			&apiKeyID,
+			&tenantID,
 			&log.Action,
 			&resourceType,
 			&resourceID,
@@ -173,6 +237,11 @@ func (r *AuditRepository) List(ctx context.Context, opts AuditListOptions) ([]mo
 				log.APIKeyID = &id
 			}
 		}
+		if tenantID.Valid {
+			if id, err := uuid.Parse(tenantID.String); err == nil {
+				log.TenantID = &id
+			}
+		}
 		if resourceID.Valid {
 			if id, err := uuid.Parse(resourceID.String); err == nil {
 				log.ResourceID = &id
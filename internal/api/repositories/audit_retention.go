@@ -0,0 +1,300 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// Audit retention repository errors.
+var (
+	ErrRetentionPolicyNotFound = errors.New("audit retention policy not found")
+	ErrLegalHoldNotFound       = errors.New("legal hold not found")
+	ErrLegalHoldReleased       = errors.New("legal hold already released")
+)
+
+// AuditRetentionRepository handles database operations for audit log
+// retention policies and legal holds.
+type AuditRetentionRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRetentionRepository creates a new AuditRetentionRepository.
+func NewAuditRetentionRepository(db *sql.DB) *AuditRetentionRepository {
+	return &AuditRetentionRepository{db: db}
+}
+
+// GetPolicy retrieves a tenant's retention override.
+func (r *AuditRetentionRepository) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	query := `
+		SELECT tenant_id, retention_days, updated_by, updated_at
+		FROM philotes.audit_retention_policies
+		WHERE tenant_id = $1
+	`
+
+	var policy models.AuditRetentionPolicy
+	var updatedBy sql.NullString
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&policy.TenantID, &policy.RetentionDays, &updatedBy, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRetentionPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get audit retention policy: %w", err)
+	}
+
+	policy.UpdatedBy = parseNullUUID(updatedBy)
+	policy.UpdatedAt = &updatedAt
+	return &policy, nil
+}
+
+// ListPolicies returns every tenant's retention override, keyed by tenant.
+func (r *AuditRetentionRepository) ListPolicies(ctx context.Context) (map[uuid.UUID]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tenant_id, retention_days FROM philotes.audit_retention_policies`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var tenantID uuid.UUID
+		var days int
+		if err := rows.Scan(&tenantID, &days); err != nil {
+			return nil, fmt.Errorf("failed to scan audit retention policy: %w", err)
+		}
+		policies[tenantID] = days
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit retention policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// UpsertPolicy creates or replaces a tenant's retention override.
+func (r *AuditRetentionRepository) UpsertPolicy(ctx context.Context, tenantID uuid.UUID, days int, updatedBy *uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	query := `
+		INSERT INTO philotes.audit_retention_policies (tenant_id, retention_days, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET retention_days = EXCLUDED.retention_days, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	if err := r.db.QueryRowContext(ctx, query, tenantID, days, updatedBy).Scan(&updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to upsert audit retention policy: %w", err)
+	}
+
+	return &models.AuditRetentionPolicy{
+		TenantID:      tenantID,
+		RetentionDays: days,
+		UpdatedBy:     updatedBy,
+		UpdatedAt:     &updatedAt,
+	}, nil
+}
+
+// ListTenantIDs returns the IDs of all tenants.
+func (r *AuditRetentionRepository) ListTenantIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM philotes.tenants`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tenants: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CreateHold places a legal hold.
+func (r *AuditRetentionRepository) CreateHold(ctx context.Context, hold *models.AuditLegalHold) error {
+	query := `
+		INSERT INTO philotes.audit_legal_holds (tenant_id, reason, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		hold.TenantID, hold.Reason, hold.StartsAt, hold.EndsAt, hold.CreatedBy,
+	).Scan(&hold.ID, &hold.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create legal hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetHold retrieves a legal hold by ID.
+func (r *AuditRetentionRepository) GetHold(ctx context.Context, id uuid.UUID) (*models.AuditLegalHold, error) {
+	query := `
+		SELECT id, tenant_id, reason, starts_at, ends_at, created_by, created_at, released_by, released_at
+		FROM philotes.audit_legal_holds
+		WHERE id = $1
+	`
+
+	hold, err := scanLegalHold(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLegalHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get legal hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ListHolds lists a tenant's legal holds, newest first. When activeOnly is
+// set, released holds are omitted.
+func (r *AuditRetentionRepository) ListHolds(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.AuditLegalHold, error) {
+	query := `
+		SELECT id, tenant_id, reason, starts_at, ends_at, created_by, created_at, released_by, released_at
+		FROM philotes.audit_legal_holds
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += ` AND released_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []models.AuditLegalHold
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan legal hold: %w", err)
+		}
+		holds = append(holds, *hold)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate legal holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// ReleaseHold releases an active legal hold.
+func (r *AuditRetentionRepository) ReleaseHold(ctx context.Context, id uuid.UUID, releasedBy *uuid.UUID) (*models.AuditLegalHold, error) {
+	query := `
+		UPDATE philotes.audit_legal_holds
+		SET released_by = $2, released_at = NOW()
+		WHERE id = $1 AND released_at IS NULL
+		RETURNING id, tenant_id, reason, starts_at, ends_at, created_by, created_at, released_by, released_at
+	`
+
+	hold, err := scanLegalHold(r.db.QueryRowContext(ctx, query, id, releasedBy))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, getErr := r.GetHold(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrLegalHoldReleased
+		}
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// DeleteLogs deletes audit logs created strictly between after and before.
+// A nil tenantID selects logs without a tenant; a nil after is unbounded.
+func (r *AuditRetentionRepository) DeleteLogs(ctx context.Context, tenantID *uuid.UUID, after *time.Time, before time.Time) (int64, error) {
+	query := `DELETE FROM philotes.audit_logs WHERE created_at < $1`
+	args := []any{before}
+
+	if tenantID != nil {
+		args = append(args, *tenantID)
+		query += fmt.Sprintf(" AND tenant_id = $%d", len(args))
+	} else {
+		query += " AND tenant_id IS NULL"
+	}
+	if after != nil {
+		args = append(args, *after)
+		query += fmt.Sprintf(" AND created_at > $%d", len(args))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLegalHold(row rowScanner) (*models.AuditLegalHold, error) {
+	var hold models.AuditLegalHold
+	var startsAt, endsAt, releasedAt sql.NullTime
+	var createdBy, releasedBy sql.NullString
+
+	err := row.Scan(
+		&hold.ID,
+		&hold.TenantID,
+		&hold.Reason,
+		&startsAt,
+		&endsAt,
+		&createdBy,
+		&hold.CreatedAt,
+		&releasedBy,
+		&releasedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if startsAt.Valid {
+		hold.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		hold.EndsAt = &endsAt.Time
+	}
+	if releasedAt.Valid {
+		hold.ReleasedAt = &releasedAt.Time
+	}
+	hold.CreatedBy = parseNullUUID(createdBy)
+	hold.ReleasedBy = parseNullUUID(releasedBy)
+
+	return &hold, nil
+}
+
+func parseNullUUID(s sql.NullString) *uuid.UUID {
+	if !s.Valid {
+		return nil
+	}
+	id, err := uuid.Parse(s.String)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
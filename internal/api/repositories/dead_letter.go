@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// ErrDeadLetterEventNotFound is returned when a dead-letter event does not exist.
+var ErrDeadLetterEventNotFound = errors.New("dead-letter event not found")
+
+// DeadLetterRepository handles database operations for the dead-letter queue.
+type DeadLetterRepository struct {
+	db *sql.DB
+}
+
+// NewDeadLetterRepository creates a new DeadLetterRepository.
+func NewDeadLetterRepository(db *sql.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+
+const deadLetterColumns = `
+	id, original_event_id, source_id, schema_name, table_name, operation,
+	event_data, error_message, error_type, retry_count, created_at,
+	last_retry_at, expires_at, replayed_at
+`
+
+// List returns dead-letter events matching filter, oldest first. Event data
+// is omitted; Get returns it.
+func (r *DeadLetterRepository) List(ctx context.Context, filter models.DeadLetterFilter) ([]models.DeadLetterEvent, error) {
+	where, args := deadLetterWhere(filter)
+	query := `SELECT ` + deadLetterColumns + ` FROM philotes.dead_letter_events` + where + ` ORDER BY created_at ASC, id ASC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead-letter events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.DeadLetterEvent
+	for rows.Next() {
+		event, err := scanDeadLetterEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		event.EventData = nil
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate dead-letter events: %w", err)
+	}
+
+	return events, nil
+}
+
+// Get retrieves a dead-letter event with its event data.
+func (r *DeadLetterRepository) Get(ctx context.Context, id int64) (*models.DeadLetterEvent, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM philotes.dead_letter_events WHERE id = $1`
+
+	event, err := scanDeadLetterEvent(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeadLetterEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// CountReplayable returns how many events Replay would re-enqueue.
+func (r *DeadLetterRepository) CountReplayable(ctx context.Context, filter models.DeadLetterFilter) (int64, error) {
+	filter.IncludeReplayed = false
+	where, args := deadLetterWhere(filter)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM philotes.dead_letter_events`+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count dead-letter events: %w", err)
+	}
+	return count, nil
+}
+
+// Replay re-enqueues the events matching filter into the CDC buffer and
+// marks them replayed, in one statement, so concurrent replays cannot
+// enqueue an event twice. Events already replayed are never selected.
+func (r *DeadLetterRepository) Replay(ctx context.Context, filter models.DeadLetterFilter) (int64, error) {
+	filter.IncludeReplayed = false
+	where, args := deadLetterWhere(filter)
+
+	// The columns mirror how the buffer stores a cdc.Event
+	query := `
+		WITH replayed AS (
+			UPDATE philotes.dead_letter_events
+			SET replayed_at = NOW(), retry_count = retry_count + 1, last_retry_at = NOW()` + where + `
+			RETURNING event_data
+		)
+		INSERT INTO philotes.cdc_events (
+			source_id, schema_name, table_name, operation, lsn,
+			transaction_id, key_columns, before_data, after_data,
+			event_time, metadata
+		)
+		SELECT
+			event_data->>'id',
+			event_data->>'schema',
+			event_data->>'table',
+			event_data->>'operation',
+			event_data->>'lsn',
+			(event_data->>'transaction_id')::BIGINT,
+			event_data->'key_columns',
+			event_data->'before',
+			event_data->'after',
+			(event_data->>'timestamp')::TIMESTAMPTZ,
+			event_data->'metadata'
+		FROM replayed
+	`
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay dead-letter events: %w", err)
+	}
+	replayed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return replayed, nil
+}
+
+// deadLetterWhere builds the WHERE clause and arguments for filter.
+func deadLetterWhere(filter models.DeadLetterFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if !filter.IncludeReplayed {
+		conditions = append(conditions, "replayed_at IS NULL")
+	}
+	if filter.SourceID != "" {
+		args = append(args, filter.SourceID)
+		conditions = append(conditions, fmt.Sprintf("source_id = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanDeadLetterEvent(row rowScanner) (*models.DeadLetterEvent, error) {
+	var event models.DeadLetterEvent
+	var originalEventID sql.NullInt64
+	var errorType sql.NullString
+	var lastRetryAt, expiresAt, replayedAt sql.NullTime
+
+	err := row.Scan(
+		&event.ID,
+		&originalEventID,
+		&event.SourceID,
+		&event.SchemaName,
+		&event.TableName,
+		&event.Operation,
+		&event.EventData,
+		&event.ErrorMessage,
+		&errorType,
+		&event.RetryCount,
+		&event.CreatedAt,
+		&lastRetryAt,
+		&expiresAt,
+		&replayedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan dead-letter event: %w", err)
+	}
+
+	if originalEventID.Valid {
+		event.OriginalEventID = &originalEventID.Int64
+	}
+	event.ErrorType = errorType.String
+	if lastRetryAt.Valid {
+		event.LastRetryAt = &lastRetryAt.Time
+	}
+	if expiresAt.Valid {
+		event.ExpiresAt = &expiresAt.Time
+	}
+	if replayedAt.Valid {
+		event.ReplayedAt = &replayedAt.Time
+	}
+	return &event, nil
+}
@@ -34,36 +34,38 @@ func NewDeploymentRepository(db *sql.DB) *DeploymentRepository {
 
 // deploymentRow represents a database row for a deployment.
 type deploymentRow struct {
-	ID              uuid.UUID
-	UserID          uuid.NullUUID
-	Name            string
-	Provider        string
-	Region          string
-	Size            string
-	Status          string
-	Environment     string
-	Config          []byte
-	Outputs         []byte
-	PulumiStackName sql.NullString
-	ErrorMessage    sql.NullString
-	StartedAt       sql.NullTime
-	CompletedAt     sql.NullTime
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID               uuid.UUID
+	UserID           uuid.NullUUID
+	Name             string
+	Provider         string
+	Region           string
+	Size             string
+	Status           string
+	Environment      string
+	DeleteProtection bool
+	Config           []byte
+	Outputs          []byte
+	PulumiStackName  sql.NullString
+	ErrorMessage     sql.NullString
+	StartedAt        sql.NullTime
+	CompletedAt      sql.NullTime
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 // toModel converts a database row to an API model.
 func (r *deploymentRow) toModel() *models.Deployment {
 	deployment := &models.Deployment{
-		ID:          r.ID,
-		Name:        r.Name,
-		Provider:    r.Provider,
-		Region:      r.Region,
-		Size:        models.DeploymentSize(r.Size),
-		Status:      models.DeploymentStatus(r.Status),
-		Environment: r.Environment,
-		CreatedAt:   r.CreatedAt,
-		UpdatedAt:   r.UpdatedAt,
+		ID:               r.ID,
+		Name:             r.Name,
+		Provider:         r.Provider,
+		Region:           r.Region,
+		Size:             models.DeploymentSize(r.Size),
+		Status:           models.DeploymentStatus(r.Status),
+		Environment:      r.Environment,
+		DeleteProtection: r.DeleteProtection,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
 	}
 
 	if r.UserID.Valid {
@@ -115,11 +117,11 @@ func (r *DeploymentRepository) Create(ctx context.Context, deployment *models.De
 
 	query := `
 		INSERT INTO philotes.deployments (
-			name, user_id, provider, region, size, status, environment, config, pulumi_stack_name
+			name, user_id, provider, region, size, status, environment, delete_protection, config, pulumi_stack_name
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, user_id, name, provider, region, size, status, environment,
-			config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
+			delete_protection, config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
 			created_at, updated_at
 	`
 
@@ -132,6 +134,7 @@ func (r *DeploymentRepository) Create(ctx context.Context, deployment *models.De
 		deployment.Size,
 		deployment.Status,
 		deployment.Environment,
+		deployment.DeleteProtection,
 		configJSON,
 		sql.NullString{String: deployment.PulumiStackName, Valid: deployment.PulumiStackName != ""},
 	).Scan(
@@ -143,6 +146,7 @@ func (r *DeploymentRepository) Create(ctx context.Context, deployment *models.De
 		&row.Size,
 		&row.Status,
 		&row.Environment,
+		&row.DeleteProtection,
 		&row.Config,
 		&row.Outputs,
 		&row.PulumiStackName,
@@ -166,7 +170,7 @@ func (r *DeploymentRepository) Create(ctx context.Context, deployment *models.De
 func (r *DeploymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Deployment, error) {
 	query := `
 		SELECT id, user_id, name, provider, region, size, status, environment,
-			config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
+			delete_protection, config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
 			created_at, updated_at
 		FROM philotes.deployments
 		WHERE id = $1
@@ -182,6 +186,7 @@ func (r *DeploymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*mode
 		&row.Size,
 		&row.Status,
 		&row.Environment,
+		&row.DeleteProtection,
 		&row.Config,
 		&row.Outputs,
 		&row.PulumiStackName,
@@ -209,7 +214,7 @@ func (r *DeploymentRepository) List(ctx context.Context, userID *uuid.UUID) ([]m
 	if userID != nil {
 		query = `
 			SELECT id, user_id, name, provider, region, size, status, environment,
-				config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
+				delete_protection, config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
 				created_at, updated_at
 			FROM philotes.deployments
 			WHERE user_id = $1
@@ -219,7 +224,7 @@ func (r *DeploymentRepository) List(ctx context.Context, userID *uuid.UUID) ([]m
 	} else {
 		query = `
 			SELECT id, user_id, name, provider, region, size, status, environment,
-				config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
+				delete_protection, config, outputs, pulumi_stack_name, error_message, started_at, completed_at,
 				created_at, updated_at
 			FROM philotes.deployments
 			ORDER BY created_at DESC
@@ -244,6 +249,7 @@ func (r *DeploymentRepository) List(ctx context.Context, userID *uuid.UUID) ([]m
 			&row.Size,
 			&row.Status,
 			&row.Environment,
+			&row.DeleteProtection,
 			&row.Config,
 			&row.Outputs,
 			&row.PulumiStackName,
@@ -381,6 +387,32 @@ func (r *DeploymentRepository) UpdatePulumiStackName(ctx context.Context, id uui
 	return nil
 }
 
+// UpdateDeleteProtection enables or disables the delete protection of a
+// deployment.
+func (r *DeploymentRepository) UpdateDeleteProtection(ctx context.Context, id uuid.UUID, enabled bool) error {
+	query := `
+		UPDATE philotes.deployments
+		SET delete_protection = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, enabled, id)
+	if err != nil {
+		return fmt.Errorf("failed to update delete protection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeploymentNotFound
+	}
+
+	return nil
+}
+
 // Delete deletes a deployment from the database.
 func (r *DeploymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Logs and credentials are deleted by CASCADE
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// Idempotency repository errors.
+var (
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+)
+
+// IdempotencyRepository handles database operations for the idempotency
+// keys of mutating requests.
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository.
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve reserves an idempotency key for a request until expiresAt. When
+// the key is already held and unexpired, the existing record is returned
+// with reserved false.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, scope, key, requestHash string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	// Expired keys are taken over in place.
+	query := `
+		INSERT INTO philotes.idempotency_keys (scope, idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING scope, idempotency_key, request_hash, status_code, content_type, response_body, created_at, expires_at
+	`
+
+	record, err := scanIdempotencyRecord(r.db.QueryRowContext(ctx, query, scope, key, requestHash, now, expiresAt))
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	record, err = r.Get(ctx, scope, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+// Get retrieves an idempotency key.
+func (r *IdempotencyRepository) Get(ctx context.Context, scope, key string) (*models.IdempotencyRecord, error) {
+	query := `
+		SELECT scope, idempotency_key, request_hash, status_code, content_type, response_body, created_at, expires_at
+		FROM philotes.idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2
+	`
+
+	record, err := scanIdempotencyRecord(r.db.QueryRowContext(ctx, query, scope, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIdempotencyKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return record, nil
+}
+
+// Complete stores the response of the request an idempotency key was
+// reserved for.
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE philotes.idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE scope = $4 AND idempotency_key = $5
+	`
+
+	result, err := r.db.ExecContext(ctx, query, statusCode, nullString(contentType), body, scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// Release deletes an idempotency key whose request has not completed, so
+// that it can be retried.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	query := `
+		DELETE FROM philotes.idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the expired idempotency keys and returns how many
+// were deleted.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM philotes.idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// scanIdempotencyRecord scans an idempotency key row.
+func scanIdempotencyRecord(row *sql.Row) (*models.IdempotencyRecord, error) {
+	var record models.IdempotencyRecord
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+
+	err := row.Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&contentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	return &record, nil
+}
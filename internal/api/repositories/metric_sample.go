@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+// MetricSampleRepository handles database operations for metric samples
+// received over Prometheus remote write.
+type MetricSampleRepository struct {
+	db *sql.DB
+}
+
+// NewMetricSampleRepository creates a new MetricSampleRepository.
+func NewMetricSampleRepository(db *sql.DB) *MetricSampleRepository {
+	return &MetricSampleRepository{db: db}
+}
+
+// WriteSamples stores samples, to be deleted after expiresAt.
+func (r *MetricSampleRepository) WriteSamples(ctx context.Context, samples []alerting.MetricSample, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO philotes.metric_samples (metric_name, labels, value, sampled_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare sample insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range samples {
+		labels := s.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labelsJSON, err := json.Marshal(labels)
+		if err != nil {
+			return fmt.Errorf("failed to marshal labels: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, s.Name, labelsJSON, s.Value, s.Time, expiresAt); err != nil {
+			return fmt.Errorf("failed to insert sample: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit samples: %w", err)
+	}
+	return nil
+}
+
+// ListSamples returns the samples of the named metric taken at or after
+// since whose labels include all of the given labels, oldest first.
+func (r *MetricSampleRepository) ListSamples(ctx context.Context, metricName string, labels map[string]string, since time.Time) ([]alerting.MetricValue, error) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	selectorJSON, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	query := `
+		SELECT labels, value, sampled_at
+		FROM philotes.metric_samples
+		WHERE metric_name = $1 AND sampled_at >= $2 AND labels @> $3
+		ORDER BY sampled_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, metricName, since, selectorJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+	defer rows.Close()
+
+	var values []alerting.MetricValue
+	for rows.Next() {
+		var labelsJSON []byte
+		var v alerting.MetricValue
+		if err := rows.Scan(&labelsJSON, &v.Value, &v.Time); err != nil {
+			return nil, fmt.Errorf("failed to scan sample: %w", err)
+		}
+		if err := json.Unmarshal(labelsJSON, &v.Labels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate samples: %w", err)
+	}
+	return values, nil
+}
+
+// DeleteExpiredSamples deletes samples that expired before now and returns
+// how many were deleted.
+func (r *MetricSampleRepository) DeleteExpiredSamples(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM philotes.metric_samples WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired samples: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
+// Ensure MetricSampleRepository implements alerting.SampleStore.
+var _ alerting.SampleStore = (*MetricSampleRepository)(nil)
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/crypto"
 )
 
 // OAuth state repository errors
@@ -228,6 +229,38 @@ func (r *OAuthRepository) ListCredentialsByUser(ctx context.Context, userID uuid
 	return credentials, rows.Err()
 }
 
+// ListRefreshableCredentials lists the non-expired OAuth credentials with a
+// refresh token whose access token expires before the given time.
+func (r *OAuthRepository) ListRefreshableCredentials(ctx context.Context, before time.Time) ([]models.CloudCredential, error) {
+	query := `
+		SELECT id, deployment_id, user_id, provider, credential_type,
+		       credentials_encrypted, refresh_token_encrypted, token_expires_at, expires_at, created_at
+		FROM philotes.cloud_credentials
+		WHERE credential_type = $1
+		  AND refresh_token_encrypted IS NOT NULL
+		  AND token_expires_at < $2
+		  AND expires_at > NOW()
+		ORDER BY token_expires_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.CredentialTypeOAuth, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refreshable credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []models.CloudCredential
+	for rows.Next() {
+		cred, err := r.scanCredentialFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, *cred)
+	}
+
+	return credentials, rows.Err()
+}
+
 // UpdateCredential updates a credential's token data.
 func (r *OAuthRepository) UpdateCredential(ctx context.Context, cred *models.CloudCredential) error {
 	query := `
@@ -300,6 +333,69 @@ func (r *OAuthRepository) CleanupExpiredCredentials(ctx context.Context) (int64,
 	return result.RowsAffected()
 }
 
+// ReencryptCredentials re-encrypts the stored credentials and refresh tokens
+// that were not encrypted with the primary key of encryptor, and returns the
+// number of credentials updated. It runs in one transaction, so either
+// every credential is upgraded or none is.
+func (r *OAuthRepository) ReencryptCredentials(ctx context.Context, encryptor *crypto.Encryptor) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, credentials_encrypted, refresh_token_encrypted
+		FROM philotes.cloud_credentials
+		FOR UPDATE
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	var stale []models.CloudCredential
+	for rows.Next() {
+		var cred models.CloudCredential
+		if err := rows.Scan(&cred.ID, &cred.CredentialsEncrypted, &cred.RefreshTokenEncrypted); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		if encryptor.NeedsReencrypt(cred.CredentialsEncrypted) ||
+			(cred.RefreshTokenEncrypted != nil && encryptor.NeedsReencrypt(cred.RefreshTokenEncrypted)) {
+			stale = append(stale, cred)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	for i := range stale {
+		cred := &stale[i]
+		if cred.CredentialsEncrypted, err = encryptor.Reencrypt(cred.CredentialsEncrypted); err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt credential %s: %w", cred.ID, err)
+		}
+		if cred.RefreshTokenEncrypted != nil {
+			if cred.RefreshTokenEncrypted, err = encryptor.Reencrypt(cred.RefreshTokenEncrypted); err != nil {
+				return 0, fmt.Errorf("failed to re-encrypt refresh token of credential %s: %w", cred.ID, err)
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE philotes.cloud_credentials
+			SET credentials_encrypted = $1, refresh_token_encrypted = $2
+			WHERE id = $3
+		`, cred.CredentialsEncrypted, cred.RefreshTokenEncrypted, cred.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update credential %s: %w", cred.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(stale), nil
+}
+
 // --- Helper Functions ---
 
 func (r *OAuthRepository) scanCredential(row *sql.Row) (*models.CloudCredential, error) {
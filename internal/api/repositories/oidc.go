@@ -59,17 +59,25 @@ func (r *OIDCRepository) CreateProvider(ctx context.Context, req *models.CreateO
 		return nil, fmt.Errorf("failed to marshal role mapping: %w", err)
 	}
 
+	// Marshal tenant mapping to JSON
+	tenantMappingJSON, err := json.Marshal(req.TenantMapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tenant mapping: %w", err)
+	}
+
 	query := `
 		INSERT INTO philotes.oidc_providers (
 			name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-			scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships,
+			default_role, enabled, auto_create_users
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-		          scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users, created_at, updated_at
+		          scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		          auto_create_users, created_at, updated_at
 	`
 
 	var provider models.OIDCProvider
-	var roleMappingRaw []byte
+	var roleMappingRaw, tenantMappingRaw []byte
 	err = r.db.QueryRowContext(ctx, query,
 		req.Name,
 		req.DisplayName,
@@ -80,6 +88,8 @@ func (r *OIDCRepository) CreateProvider(ctx context.Context, req *models.CreateO
 		pq.Array(req.Scopes),
 		req.GroupsClaim,
 		roleMappingJSON,
+		tenantMappingJSON,
+		*req.RemoveUnmappedMemberships,
 		req.DefaultRole,
 		*req.Enabled,
 		*req.AutoCreateUsers,
@@ -94,6 +104,8 @@ func (r *OIDCRepository) CreateProvider(ctx context.Context, req *models.CreateO
 		pq.Array(&provider.Scopes),
 		&provider.GroupsClaim,
 		&roleMappingRaw,
+		&tenantMappingRaw,
+		&provider.RemoveUnmappedMemberships,
 		&provider.DefaultRole,
 		&provider.Enabled,
 		&provider.AutoCreateUsers,
@@ -112,6 +124,11 @@ func (r *OIDCRepository) CreateProvider(ctx context.Context, req *models.CreateO
 		return nil, fmt.Errorf("failed to unmarshal role mapping: %w", err)
 	}
 
+	// Unmarshal tenant mapping
+	if err := json.Unmarshal(tenantMappingRaw, &provider.TenantMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tenant mapping: %w", err)
+	}
+
 	return &provider, nil
 }
 
@@ -119,7 +136,8 @@ func (r *OIDCRepository) CreateProvider(ctx context.Context, req *models.CreateO
 func (r *OIDCRepository) GetProviderByID(ctx context.Context, id uuid.UUID) (*models.OIDCProvider, error) {
 	query := `
 		SELECT id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-		       scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users, created_at, updated_at
+		       scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		       auto_create_users, created_at, updated_at
 		FROM philotes.oidc_providers
 		WHERE id = $1
 	`
@@ -131,7 +149,8 @@ func (r *OIDCRepository) GetProviderByID(ctx context.Context, id uuid.UUID) (*mo
 func (r *OIDCRepository) GetProviderByName(ctx context.Context, name string) (*models.OIDCProvider, error) {
 	query := `
 		SELECT id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-		       scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users, created_at, updated_at
+		       scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		       auto_create_users, created_at, updated_at
 		FROM philotes.oidc_providers
 		WHERE name = $1
 	`
@@ -143,7 +162,8 @@ func (r *OIDCRepository) GetProviderByName(ctx context.Context, name string) (*m
 func (r *OIDCRepository) ListProviders(ctx context.Context) ([]models.OIDCProvider, error) {
 	query := `
 		SELECT id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-		       scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users, created_at, updated_at
+		       scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		       auto_create_users, created_at, updated_at
 		FROM philotes.oidc_providers
 		ORDER BY created_at DESC
 	`
@@ -174,7 +194,8 @@ func (r *OIDCRepository) ListProviders(ctx context.Context) ([]models.OIDCProvid
 func (r *OIDCRepository) ListEnabledProviders(ctx context.Context) ([]models.OIDCProvider, error) {
 	query := `
 		SELECT id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
-		       scopes, groups_claim, role_mapping, default_role, enabled, auto_create_users, created_at, updated_at
+		       scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		       auto_create_users, created_at, updated_at
 		FROM philotes.oidc_providers
 		WHERE enabled = true
 		ORDER BY display_name ASC
@@ -258,6 +279,20 @@ func (r *OIDCRepository) UpdateProvider(ctx context.Context, id uuid.UUID, req *
 		args = append(args, roleMappingJSON)
 		argIdx++
 	}
+	if req.TenantMapping != nil {
+		tenantMappingJSON, marshalErr := json.Marshal(req.TenantMapping)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("failed to marshal tenant mapping: %w", marshalErr)
+		}
+		query += fmt.Sprintf(", tenant_mapping = $%d", argIdx)
+		args = append(args, tenantMappingJSON)
+		argIdx++
+	}
+	if req.RemoveUnmappedMemberships != nil {
+		query += fmt.Sprintf(", remove_unmapped_memberships = $%d", argIdx)
+		args = append(args, *req.RemoveUnmappedMemberships)
+		argIdx++
+	}
 	if req.DefaultRole != nil {
 		query += fmt.Sprintf(", default_role = $%d", argIdx)
 		args = append(args, *req.DefaultRole)
@@ -306,6 +341,46 @@ func (r *OIDCRepository) DeleteProvider(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// GetProviderBySCIMTokenHash retrieves the OIDC provider a SCIM bearer
+// token belongs to by the token's hash.
+func (r *OIDCRepository) GetProviderBySCIMTokenHash(ctx context.Context, tokenHash string) (*models.OIDCProvider, error) {
+	query := `
+		SELECT id, name, display_name, provider_type, issuer_url, client_id, client_secret_encrypted,
+		       scopes, groups_claim, role_mapping, tenant_mapping, remove_unmapped_memberships, default_role, enabled,
+		       auto_create_users, created_at, updated_at
+		FROM philotes.oidc_providers
+		WHERE scim_token_hash = $1
+	`
+
+	return r.scanProvider(r.db.QueryRowContext(ctx, query, tokenHash))
+}
+
+// SetSCIMTokenHash sets the hash of a provider's SCIM bearer token,
+// replacing the previous token. An empty hash disables SCIM.
+func (r *OIDCRepository) SetSCIMTokenHash(ctx context.Context, id uuid.UUID, tokenHash string) error {
+	query := `
+		UPDATE philotes.oidc_providers
+		SET scim_token_hash = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, nullString(tokenHash), id)
+	if err != nil {
+		return fmt.Errorf("failed to set scim token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrOIDCProviderNotFound
+	}
+
+	return nil
+}
+
 // GetProviderClientSecret retrieves and decrypts the client secret for a provider.
 func (r *OIDCRepository) GetProviderClientSecret(ctx context.Context, id uuid.UUID) (string, error) {
 	provider, err := r.GetProviderByID(ctx, id)
@@ -321,6 +396,64 @@ func (r *OIDCRepository) GetProviderClientSecret(ctx context.Context, id uuid.UU
 	return secret, nil
 }
 
+// ReencryptClientSecrets re-encrypts the provider client secrets that were
+// not encrypted with the primary key, and returns the number of providers
+// updated. It runs in one transaction, so either every secret is upgraded
+// or none is.
+func (r *OIDCRepository) ReencryptClientSecrets(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, client_secret_encrypted
+		FROM philotes.oidc_providers
+		FOR UPDATE
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list oidc providers: %w", err)
+	}
+	type providerSecret struct {
+		id     uuid.UUID
+		secret []byte
+	}
+	var stale []providerSecret
+	for rows.Next() {
+		var p providerSecret
+		if err := rows.Scan(&p.id, &p.secret); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan oidc provider: %w", err)
+		}
+		if r.encryptor.NeedsReencrypt(p.secret) {
+			stale = append(stale, p)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list oidc providers: %w", err)
+	}
+
+	for _, p := range stale {
+		secret, err := r.encryptor.Reencrypt(p.secret)
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt client secret of oidc provider %s: %w", p.id, err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE philotes.oidc_providers SET client_secret_encrypted = $1 WHERE id = $2
+		`, secret, p.id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update oidc provider %s: %w", p.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(stale), nil
+}
+
 // --- OIDC State Operations ---
 
 // CreateState creates a new OIDC state.
@@ -408,7 +541,7 @@ func (r *OIDCRepository) CleanupExpiredStates(ctx context.Context) (int64, error
 
 func (r *OIDCRepository) scanProvider(row *sql.Row) (*models.OIDCProvider, error) {
 	var provider models.OIDCProvider
-	var roleMappingRaw []byte
+	var roleMappingRaw, tenantMappingRaw []byte
 
 	err := row.Scan(
 		&provider.ID,
@@ -421,6 +554,8 @@ func (r *OIDCRepository) scanProvider(row *sql.Row) (*models.OIDCProvider, error
 		pq.Array(&provider.Scopes),
 		&provider.GroupsClaim,
 		&roleMappingRaw,
+		&tenantMappingRaw,
+		&provider.RemoveUnmappedMemberships,
 		&provider.DefaultRole,
 		&provider.Enabled,
 		&provider.AutoCreateUsers,
@@ -439,12 +574,17 @@ func (r *OIDCRepository) scanProvider(row *sql.Row) (*models.OIDCProvider, error
 		return nil, fmt.Errorf("failed to unmarshal role mapping: %w", err)
 	}
 
+	// Unmarshal tenant mapping
+	if err := json.Unmarshal(tenantMappingRaw, &provider.TenantMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tenant mapping: %w", err)
+	}
+
 	return &provider, nil
 }
 
 func (r *OIDCRepository) scanProviderFromRows(rows *sql.Rows) (*models.OIDCProvider, error) {
 	var provider models.OIDCProvider
-	var roleMappingRaw []byte
+	var roleMappingRaw, tenantMappingRaw []byte
 
 	err := rows.Scan(
 		&provider.ID,
@@ -457,6 +597,8 @@ func (r *OIDCRepository) scanProviderFromRows(rows *sql.Rows) (*models.OIDCProvi
 		pq.Array(&provider.Scopes),
 		&provider.GroupsClaim,
 		&roleMappingRaw,
+		&tenantMappingRaw,
+		&provider.RemoveUnmappedMemberships,
 		&provider.DefaultRole,
 		&provider.Enabled,
 		&provider.AutoCreateUsers,
@@ -472,5 +614,10 @@ func (r *OIDCRepository) scanProviderFromRows(rows *sql.Rows) (*models.OIDCProvi
 		return nil, fmt.Errorf("failed to unmarshal role mapping: %w", err)
 	}
 
+	// Unmarshal tenant mapping
+	if err := json.Unmarshal(tenantMappingRaw, &provider.TenantMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tenant mapping: %w", err)
+	}
+
 	return &provider, nil
 }
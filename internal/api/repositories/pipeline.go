@@ -266,6 +266,43 @@ func (r *PipelineRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return pipeline, nil
 }
 
+// GetBufferStats retrieves the buffer stats the worker recorded for a
+// pipeline. The stats are not reported if the worker has not recorded any.
+func (r *PipelineRepository) GetBufferStats(ctx context.Context, pipelineID uuid.UUID) (*models.PipelineBufferStats, error) {
+	query := `
+		SELECT unprocessed_events, processed_events, oldest_unprocessed_at,
+			last_cleanup_at, last_cleanup_deleted, updated_at
+		FROM philotes.pipeline_buffer_stats
+		WHERE pipeline_id = $1
+	`
+
+	stats := &models.PipelineBufferStats{PipelineID: pipelineID}
+	var oldestUnprocessed sql.NullTime
+	var lastCleanupAt, updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, pipelineID).Scan(
+		&stats.UnprocessedEvents,
+		&stats.ProcessedEvents,
+		&oldestUnprocessed,
+		&lastCleanupAt,
+		&stats.LastCleanupDeleted,
+		&updatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buffer stats: %w", err)
+	}
+
+	stats.Reported = true
+	stats.LastCleanupAt = &lastCleanupAt
+	stats.UpdatedAt = &updatedAt
+	if oldestUnprocessed.Valid {
+		stats.OldestUnprocessed = &oldestUnprocessed.Time
+	}
+	return stats, nil
+}
+
 // GetTableMappings retrieves table mappings for a pipeline.
 func (r *PipelineRepository) GetTableMappings(ctx context.Context, pipelineID uuid.UUID) ([]models.TableMapping, error) {
 	query := `
@@ -706,3 +743,33 @@ func (r *PipelineRepository) GetByIDAndTenant(ctx context.Context, id, tenantID
 
 	return pipeline, nil
 }
+
+// GetTableMappingByID retrieves a table mapping by ID. If tenantID is non-nil,
+// the mapping's pipeline must belong to that tenant.
+func (r *PipelineRepository) GetTableMappingByID(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*models.TableMapping, error) {
+	query := `
+		SELECT tm.id, tm.pipeline_id, tm.source_schema, tm.source_table, tm.enabled, tm.config, tm.created_at
+		FROM philotes.table_mappings tm
+		JOIN philotes.pipelines p ON p.id = tm.pipeline_id
+		WHERE tm.id = $1 AND ($2::uuid IS NULL OR p.tenant_id = $2)
+	`
+
+	var row tableMappingRow
+	err := r.db.QueryRowContext(ctx, query, id, tenantID).Scan(
+		&row.ID,
+		&row.PipelineID,
+		&row.SourceSchema,
+		&row.SourceTable,
+		&row.Enabled,
+		&row.Config,
+		&row.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTableMappingNotFound
+		}
+		return nil, fmt.Errorf("failed to get table mapping: %w", err)
+	}
+
+	return row.toModel(), nil
+}
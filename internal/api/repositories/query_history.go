@@ -0,0 +1,185 @@
+// Package repositories provides data access layer for API resources.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// QueryHistoryRepository handles database operations for the query history.
+type QueryHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewQueryHistoryRepository creates a new QueryHistoryRepository.
+func NewQueryHistoryRepository(db *sql.DB) *QueryHistoryRepository {
+	return &QueryHistoryRepository{db: db}
+}
+
+const queryHistoryColumns = `
+	id, tenant_id, user_id, api_key_id, saved_query_id, engine, query_id, sql, status,
+	error_message, duration_ms, row_count, bytes_scanned, executed_at
+`
+
+// Create records an executed query.
+func (r *QueryHistoryRepository) Create(ctx context.Context, entry *models.QueryHistoryEntry) error {
+	query := `
+		INSERT INTO philotes.query_history (tenant_id, user_id, api_key_id, saved_query_id, engine, query_id, sql, status,
+			error_message, duration_ms, row_count, bytes_scanned, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		entry.TenantID,
+		entry.UserID,
+		entry.APIKeyID,
+		entry.SavedQueryID,
+		entry.Engine,
+		nullString(entry.QueryID),
+		entry.SQL,
+		entry.Status,
+		nullString(entry.Error),
+		entry.DurationMs,
+		entry.RowCount,
+		entry.BytesScanned,
+		entry.ExecutedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create query history entry: %w", err)
+	}
+
+	return nil
+}
+
+// listQueryHistoryQuery builds the query listing entries matching filter,
+// newest first. If limit is 0, all matching entries are returned.
+func listQueryHistoryQuery(filter models.QueryHistoryFilter, limit, offset int) (string, []any) {
+	where, args := queryHistoryWhere(filter)
+	query := `SELECT ` + queryHistoryColumns + ` FROM philotes.query_history` + where + ` ORDER BY executed_at DESC, id`
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, limit, offset)
+	}
+
+	return query, args
+}
+
+// ListPaginated retrieves query history entries matching filter with
+// optional pagination. If limit is 0, all matching entries are returned.
+func (r *QueryHistoryRepository) ListPaginated(ctx context.Context, filter models.QueryHistoryFilter, limit, offset int) ([]models.QueryHistoryEntry, error) {
+	query, args := listQueryHistoryQuery(filter, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.QueryHistoryEntry
+	for rows.Next() {
+		entry, err := scanQueryHistoryEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan query history row: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate query history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Count returns the number of query history entries matching filter.
+func (r *QueryHistoryRepository) Count(ctx context.Context, filter models.QueryHistoryFilter) (int, error) {
+	where, args := queryHistoryWhere(filter)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM philotes.query_history`+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count query history: %w", err)
+	}
+
+	return count, nil
+}
+
+func queryHistoryWhere(filter models.QueryHistoryFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.TenantID != nil {
+		args = append(args, *filter.TenantID)
+		conditions = append(conditions, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("executed_at >= $%d", len(args)))
+	}
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		conditions = append(conditions, fmt.Sprintf("executed_at <= $%d", len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanQueryHistoryEntry(row rowScanner) (*models.QueryHistoryEntry, error) {
+	var entry models.QueryHistoryEntry
+	var tenantID, userID, apiKeyID, savedQueryID uuid.NullUUID
+	var queryID, errorMessage sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&tenantID,
+		&userID,
+		&apiKeyID,
+		&savedQueryID,
+		&entry.Engine,
+		&queryID,
+		&entry.SQL,
+		&entry.Status,
+		&errorMessage,
+		&entry.DurationMs,
+		&entry.RowCount,
+		&entry.BytesScanned,
+		&entry.ExecutedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.TenantID = nullUUIDPtr(tenantID)
+	entry.UserID = nullUUIDPtr(userID)
+	entry.APIKeyID = nullUUIDPtr(apiKeyID)
+	entry.SavedQueryID = nullUUIDPtr(savedQueryID)
+	entry.QueryID = queryID.String
+	entry.Error = errorMessage.String
+
+	return &entry, nil
+}
+
+// nullUUIDPtr returns the UUID of id, or nil when it is NULL.
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
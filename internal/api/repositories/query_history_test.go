@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+func TestListQueryHistoryQuery(t *testing.T) {
+	query, args := listQueryHistoryQuery(models.QueryHistoryFilter{}, 0, 0)
+	if strings.Contains(query, "WHERE") || strings.Contains(query, "LIMIT") || len(args) != 0 {
+		t.Errorf("unfiltered query = %q, %v, want no conditions and no pagination", query, args)
+	}
+
+	tenantID := uuid.New()
+	userID := uuid.New()
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	query, args = listQueryHistoryQuery(models.QueryHistoryFilter{
+		TenantID: &tenantID,
+		UserID:   &userID,
+		Status:   models.QueryStatusError,
+		Since:    &since,
+		Until:    &until,
+	}, 50, 100)
+
+	for _, cond := range []string{
+		"WHERE tenant_id = $1",
+		"user_id = $2",
+		"status = $3",
+		"executed_at >= $4",
+		"executed_at <= $5",
+		"ORDER BY executed_at DESC, id LIMIT $6 OFFSET $7",
+	} {
+		if !strings.Contains(query, cond) {
+			t.Errorf("query = %q, want %q", query, cond)
+		}
+	}
+	if want := []any{tenantID, userID, models.QueryStatusError, since, until, 50, 100}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,251 @@
+// Package repositories provides data access layer for API resources.
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// Saved query repository errors.
+var (
+	ErrSavedQueryNotFound   = errors.New("saved query not found")
+	ErrSavedQueryNameExists = errors.New("saved query with this name already exists")
+)
+
+// SavedQueryRepository handles database operations for saved queries.
+type SavedQueryRepository struct {
+	db *sql.DB
+}
+
+// NewSavedQueryRepository creates a new SavedQueryRepository.
+func NewSavedQueryRepository(db *sql.DB) *SavedQueryRepository {
+	return &SavedQueryRepository{db: db}
+}
+
+const savedQueryColumns = `
+	id, tenant_id, name, description, sql, schema_name, parameters, created_at, updated_at
+`
+
+// scanSavedQuery scans a row selected with savedQueryColumns.
+func scanSavedQuery(row rowScanner) (*models.SavedQuery, error) {
+	var q models.SavedQuery
+	var tenantID uuid.NullUUID
+	var description sql.NullString
+	var parameters []byte
+
+	err := row.Scan(
+		&q.ID,
+		&tenantID,
+		&q.Name,
+		&description,
+		&q.SQL,
+		&q.Schema,
+		&parameters,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID.Valid {
+		q.TenantID = &tenantID.UUID
+	}
+	q.Description = description.String
+	if err := json.Unmarshal(parameters, &q.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parameters of saved query %s: %w", q.ID, err)
+	}
+	if q.Parameters == nil {
+		q.Parameters = []models.SavedQueryParameter{}
+	}
+
+	return &q, nil
+}
+
+// Create creates a new saved query owned by tenantID, or by no tenant when
+// it is nil.
+func (r *SavedQueryRepository) Create(ctx context.Context, tenantID *uuid.UUID, req *models.CreateSavedQueryRequest) (*models.SavedQuery, error) {
+	parameters, err := json.Marshal(req.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	query := `
+		INSERT INTO philotes.saved_queries (tenant_id, name, description, sql, schema_name, parameters)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + savedQueryColumns
+
+	q, err := scanSavedQuery(r.db.QueryRowContext(ctx, query,
+		tenantID,
+		req.Name,
+		nullString(req.Description),
+		req.SQL,
+		req.Schema,
+		parameters,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrSavedQueryNameExists
+		}
+		return nil, fmt.Errorf("failed to create saved query: %w", err)
+	}
+
+	return q, nil
+}
+
+// Get retrieves a saved query by its ID.
+func (r *SavedQueryRepository) Get(ctx context.Context, id uuid.UUID) (*models.SavedQuery, error) {
+	query := `SELECT ` + savedQueryColumns + ` FROM philotes.saved_queries WHERE id = $1`
+
+	q, err := scanSavedQuery(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSavedQueryNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved query: %w", err)
+	}
+
+	return q, nil
+}
+
+// List retrieves saved queries ordered by name. If limit is 0, all matching
+// queries are returned; if tenantID is nil, queries of every tenant are.
+func (r *SavedQueryRepository) List(ctx context.Context, tenantID *uuid.UUID, limit, offset int) ([]models.SavedQuery, error) {
+	query := `SELECT ` + savedQueryColumns + ` FROM philotes.saved_queries WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if tenantID != nil {
+		query += fmt.Sprintf(" AND tenant_id = $%d", argIdx)
+		args = append(args, *tenantID)
+		argIdx++
+	}
+
+	query += " ORDER BY name, id"
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+		args = append(args, limit, offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved queries: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []models.SavedQuery
+	for rows.Next() {
+		q, err := scanSavedQuery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved query row: %w", err)
+		}
+		queries = append(queries, *q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate saved queries: %w", err)
+	}
+
+	return queries, nil
+}
+
+// Count returns the number of saved queries, of a single tenant when
+// tenantID is set.
+func (r *SavedQueryRepository) Count(ctx context.Context, tenantID *uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM philotes.saved_queries`
+	args := []any{}
+	if tenantID != nil {
+		query += ` WHERE tenant_id = $1`
+		args = append(args, *tenantID)
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count saved queries: %w", err)
+	}
+
+	return count, nil
+}
+
+// Update updates a saved query in the database.
+func (r *SavedQueryRepository) Update(ctx context.Context, id uuid.UUID, req *models.UpdateSavedQueryRequest) (*models.SavedQuery, error) {
+	// Build dynamic update query
+	query := `UPDATE philotes.saved_queries SET updated_at = NOW()`
+	args := []any{}
+	argIdx := 1
+
+	if req.Name != nil {
+		query += fmt.Sprintf(", name = $%d", argIdx)
+		args = append(args, *req.Name)
+		argIdx++
+	}
+	if req.Description != nil {
+		query += fmt.Sprintf(", description = $%d", argIdx)
+		args = append(args, nullString(*req.Description))
+		argIdx++
+	}
+	if req.SQL != nil {
+		query += fmt.Sprintf(", sql = $%d", argIdx)
+		args = append(args, *req.SQL)
+		argIdx++
+	}
+	if req.Schema != nil {
+		query += fmt.Sprintf(", schema_name = $%d", argIdx)
+		args = append(args, *req.Schema)
+		argIdx++
+	}
+	if req.Parameters != nil {
+		parameters, err := json.Marshal(*req.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal parameters: %w", err)
+		}
+		query += fmt.Sprintf(", parameters = $%d", argIdx)
+		args = append(args, parameters)
+		argIdx++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING ", argIdx) + savedQueryColumns
+	args = append(args, id)
+
+	q, err := scanSavedQuery(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSavedQueryNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrSavedQueryNameExists
+		}
+		return nil, fmt.Errorf("failed to update saved query: %w", err)
+	}
+
+	return q, nil
+}
+
+// Delete deletes a saved query from the database.
+func (r *SavedQueryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM philotes.saved_queries WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSavedQueryNotFound
+	}
+
+	return nil
+}
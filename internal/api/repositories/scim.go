@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// SCIM repository errors.
+var (
+	ErrSCIMGroupNotFound   = errors.New("scim group not found")
+	ErrSCIMGroupNameExists = errors.New("scim group with this name already exists")
+)
+
+// SCIMRepository handles database operations for groups provisioned over
+// SCIM.
+type SCIMRepository struct {
+	db *sql.DB
+}
+
+// NewSCIMRepository creates a new SCIMRepository.
+func NewSCIMRepository(db *sql.DB) *SCIMRepository {
+	return &SCIMRepository{db: db}
+}
+
+// scimGroupSelect selects groups with the IDs of their members.
+const scimGroupSelect = `
+	SELECT g.id, g.provider_id, g.display_name, g.external_id, g.created_at, g.updated_at,
+	       COALESCE(array_agg(m.user_id::text) FILTER (WHERE m.user_id IS NOT NULL), '{}')
+	FROM philotes.scim_groups g
+	LEFT JOIN philotes.scim_group_members m ON m.group_id = g.id
+`
+
+// CreateGroup creates a group of a provider.
+func (r *SCIMRepository) CreateGroup(ctx context.Context, providerID uuid.UUID, displayName, externalID string) (*models.SCIMGroupRecord, error) {
+	query := `
+		INSERT INTO philotes.scim_groups (provider_id, display_name, external_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, provider_id, display_name, external_id, created_at, updated_at, '{}'::text[]
+	`
+
+	group, err := scanSCIMGroup(r.db.QueryRowContext(ctx, query, providerID, displayName, nullString(externalID)))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrSCIMGroupNameExists
+		}
+		return nil, fmt.Errorf("failed to create scim group: %w", err)
+	}
+	return group, nil
+}
+
+// GetGroup retrieves a group of a provider with its members.
+func (r *SCIMRepository) GetGroup(ctx context.Context, providerID, id uuid.UUID) (*models.SCIMGroupRecord, error) {
+	query := scimGroupSelect + `
+		WHERE g.provider_id = $1 AND g.id = $2
+		GROUP BY g.id
+	`
+
+	group, err := scanSCIMGroup(r.db.QueryRowContext(ctx, query, providerID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSCIMGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim group: %w", err)
+	}
+	return group, nil
+}
+
+// ListGroups retrieves the groups of a provider with their members.
+func (r *SCIMRepository) ListGroups(ctx context.Context, providerID uuid.UUID) ([]models.SCIMGroupRecord, error) {
+	query := scimGroupSelect + `
+		WHERE g.provider_id = $1
+		GROUP BY g.id
+		ORDER BY g.created_at
+	`
+
+	return r.queryGroups(ctx, query, providerID)
+}
+
+// ListGroupsByMember retrieves the groups of a provider a user is in. The
+// groups' members are not loaded.
+func (r *SCIMRepository) ListGroupsByMember(ctx context.Context, providerID, userID uuid.UUID) ([]models.SCIMGroupRecord, error) {
+	query := `
+		SELECT g.id, g.provider_id, g.display_name, g.external_id, g.created_at, g.updated_at, '{}'::text[]
+		FROM philotes.scim_groups g
+		JOIN philotes.scim_group_members m ON m.group_id = g.id
+		WHERE g.provider_id = $1 AND m.user_id = $2
+		ORDER BY g.display_name
+	`
+
+	return r.queryGroups(ctx, query, providerID, userID)
+}
+
+// UpdateGroup updates the names of a group.
+func (r *SCIMRepository) UpdateGroup(ctx context.Context, providerID, id uuid.UUID, displayName, externalID string) error {
+	query := `
+		UPDATE philotes.scim_groups
+		SET display_name = $1, external_id = $2, updated_at = NOW()
+		WHERE provider_id = $3 AND id = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, displayName, nullString(externalID), providerID, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSCIMGroupNameExists
+		}
+		return fmt.Errorf("failed to update scim group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSCIMGroupNotFound
+	}
+
+	return nil
+}
+
+// SetGroupMembers replaces the members of a group.
+func (r *SCIMRepository) SetGroupMembers(ctx context.Context, id uuid.UUID, userIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM philotes.scim_group_members WHERE group_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to clear scim group members: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO philotes.scim_group_members (group_id, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, id, userID); err != nil {
+			return fmt.Errorf("failed to add scim group member: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE philotes.scim_groups SET updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update scim group: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteGroup deletes a group of a provider.
+func (r *SCIMRepository) DeleteGroup(ctx context.Context, providerID, id uuid.UUID) error {
+	query := `DELETE FROM philotes.scim_groups WHERE provider_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, providerID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scim group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSCIMGroupNotFound
+	}
+
+	return nil
+}
+
+// --- Helper Functions ---
+
+func (r *SCIMRepository) queryGroups(ctx context.Context, query string, args ...any) ([]models.SCIMGroupRecord, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scim groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []models.SCIMGroupRecord
+	for rows.Next() {
+		group, err := scanSCIMGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scim group: %w", err)
+		}
+		groups = append(groups, *group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scim groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// scanSCIMGroup scans a group row from a *sql.Row or *sql.Rows.
+func scanSCIMGroup(row interface{ Scan(...any) error }) (*models.SCIMGroupRecord, error) {
+	var group models.SCIMGroupRecord
+	var externalID sql.NullString
+	var memberIDs []string
+
+	err := row.Scan(
+		&group.ID,
+		&group.ProviderID,
+		&group.DisplayName,
+		&externalID,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+		pq.Array(&memberIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	group.ExternalID = externalID.String
+	group.MemberIDs = make([]uuid.UUID, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		memberID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member id %q: %w", id, err)
+		}
+		group.MemberIDs = append(group.MemberIDs, memberID)
+	}
+	return &group, nil
+}
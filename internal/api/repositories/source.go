@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/crypto"
 )
 
 // Common repository errors.
@@ -23,7 +24,8 @@ var (
 
 // SourceRepository handles database operations for sources.
 type SourceRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	encryptor *crypto.Encryptor
 }
 
 // NewSourceRepository creates a new SourceRepository.
@@ -31,23 +33,32 @@ func NewSourceRepository(db *sql.DB) *SourceRepository {
 	return &SourceRepository{db: db}
 }
 
+// SetEncryptor encrypts the passwords of sources created or updated from
+// now on with encryptor, storing them in password_encrypted instead of
+// password. Passwords already encrypted can only be read once it is set;
+// EncryptPasswords encrypts the existing plaintext ones.
+func (r *SourceRepository) SetEncryptor(encryptor *crypto.Encryptor) {
+	r.encryptor = encryptor
+}
+
 // sourceRow represents a database row for a source.
 type sourceRow struct {
-	ID              uuid.UUID
-	TenantID        sql.NullString
-	Name            string
-	Type            string
-	Host            string
-	Port            int
-	DatabaseName    string
-	Username        string
-	Password        string
-	SSLMode         string
-	SlotName        sql.NullString
-	PublicationName sql.NullString
-	Status          string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID                uuid.UUID
+	TenantID          sql.NullString
+	Name              string
+	Type              string
+	Host              string
+	Port              int
+	DatabaseName      string
+	Username          string
+	Password          sql.NullString
+	PasswordEncrypted []byte
+	SSLMode           string
+	SlotName          sql.NullString
+	PublicationName   sql.NullString
+	Status            string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
 }
 
 // toModel converts a database row to an API model.
@@ -80,21 +91,27 @@ func (r *SourceRepository) Create(ctx context.Context, req *models.CreateSourceR
 	query := `
 		INSERT INTO philotes.sources (
 			name, type, host, port, database_name, username, password,
-			ssl_mode, slot_name, publication_name, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			password_encrypted, ssl_mode, slot_name, publication_name, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, name, type, host, port, database_name, username, password,
 			ssl_mode, slot_name, publication_name, status, created_at, updated_at
 	`
 
+	password, passwordEncrypted, err := r.storedPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	var row sourceRow
-	err := r.db.QueryRowContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		req.Name,
 		req.Type,
 		req.Host,
 		req.Port,
 		req.DatabaseName,
 		req.Username,
-		req.Password,
+		password,
+		passwordEncrypted,
 		req.SSLMode,
 		nullString(req.SlotName),
 		nullString(req.PublicationName),
@@ -165,7 +182,8 @@ func (r *SourceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.S
 func (r *SourceRepository) GetByIDWithPassword(ctx context.Context, id uuid.UUID) (*models.Source, string, error) {
 	query := `
 		SELECT id, name, type, host, port, database_name, username, password,
-			ssl_mode, slot_name, publication_name, status, created_at, updated_at
+			password_encrypted, ssl_mode, slot_name, publication_name, status,
+			created_at, updated_at
 		FROM philotes.sources
 		WHERE id = $1
 	`
@@ -180,6 +198,7 @@ func (r *SourceRepository) GetByIDWithPassword(ctx context.Context, id uuid.UUID
 		&row.DatabaseName,
 		&row.Username,
 		&row.Password,
+		&row.PasswordEncrypted,
 		&row.SSLMode,
 		&row.SlotName,
 		&row.PublicationName,
@@ -194,7 +213,11 @@ func (r *SourceRepository) GetByIDWithPassword(ctx context.Context, id uuid.UUID
 		return nil, "", fmt.Errorf("failed to get source: %w", err)
 	}
 
-	return row.toModel(), row.Password, nil
+	password, err := r.decryptPassword(&row)
+	if err != nil {
+		return nil, "", err
+	}
+	return row.toModel(), password, nil
 }
 
 // List retrieves all sources.
@@ -283,9 +306,13 @@ func (r *SourceRepository) Update(ctx context.Context, id uuid.UUID, req *models
 		argIdx++
 	}
 	if req.Password != nil {
-		query += fmt.Sprintf(", password = $%d", argIdx)
-		args = append(args, *req.Password)
-		argIdx++
+		password, passwordEncrypted, err := r.storedPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		query += fmt.Sprintf(", password = $%d, password_encrypted = $%d", argIdx, argIdx+1)
+		args = append(args, password, passwordEncrypted)
+		argIdx += 2
 	}
 	if req.SSLMode != nil {
 		query += fmt.Sprintf(", ssl_mode = $%d", argIdx)
@@ -375,6 +402,102 @@ func (r *SourceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// EncryptPasswords encrypts the plaintext source passwords, and re-encrypts
+// those not encrypted with the primary key, with the repository's
+// encryptor. It returns the number of sources updated and runs in one
+// transaction, so either every password is upgraded or none is.
+func (r *SourceRepository) EncryptPasswords(ctx context.Context) (int, error) {
+	if r.encryptor == nil {
+		return 0, errors.New("no encryption key configured")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, password, password_encrypted
+		FROM philotes.sources
+		FOR UPDATE
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sources: %w", err)
+	}
+	var stale []sourceRow
+	for rows.Next() {
+		var row sourceRow
+		if err := rows.Scan(&row.ID, &row.Password, &row.PasswordEncrypted); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan source row: %w", err)
+		}
+		if row.PasswordEncrypted == nil || r.encryptor.NeedsReencrypt(row.PasswordEncrypted) {
+			stale = append(stale, row)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list sources: %w", err)
+	}
+
+	for i := range stale {
+		row := &stale[i]
+		var encrypted []byte
+		if row.PasswordEncrypted == nil {
+			encrypted, err = r.encryptor.EncryptToBytes(row.Password.String)
+		} else {
+			encrypted, err = r.encryptor.Reencrypt(row.PasswordEncrypted)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt password of source %s: %w", row.ID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE philotes.sources
+			SET password = NULL, password_encrypted = $1
+			WHERE id = $2
+		`, encrypted, row.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update source %s: %w", row.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(stale), nil
+}
+
+// storedPassword returns the password and encrypted password columns of a
+// source password: encrypted if an encryptor is set, plaintext otherwise.
+func (r *SourceRepository) storedPassword(password string) (sql.NullString, []byte, error) {
+	if r.encryptor == nil {
+		return sql.NullString{String: password, Valid: true}, nil, nil
+	}
+	encrypted, err := r.encryptor.EncryptToBytes(password)
+	if err != nil {
+		return sql.NullString{}, nil, fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	return sql.NullString{}, encrypted, nil
+}
+
+// decryptPassword returns the password of a source row, decrypting it if
+// it is stored encrypted.
+func (r *SourceRepository) decryptPassword(row *sourceRow) (string, error) {
+	if row.PasswordEncrypted == nil {
+		return row.Password.String, nil
+	}
+	if r.encryptor == nil {
+		return "", errors.New("source password is encrypted but no encryption key is configured")
+	}
+	password, err := r.encryptor.DecryptFromBytes(row.PasswordEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	return password, nil
+}
+
 // nullString converts a string to sql.NullString.
 func nullString(s string) sql.NullString {
 	if s == "" {
@@ -401,14 +524,19 @@ func (r *SourceRepository) CreateWithTenant(ctx context.Context, req *models.Cre
 	query := `
 		INSERT INTO philotes.sources (
 			tenant_id, name, type, host, port, database_name, username, password,
-			ssl_mode, slot_name, publication_name, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			password_encrypted, ssl_mode, slot_name, publication_name, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, tenant_id, name, type, host, port, database_name, username, password,
 			ssl_mode, slot_name, publication_name, status, created_at, updated_at
 	`
 
+	password, passwordEncrypted, err := r.storedPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	var row sourceRow
-	err := r.db.QueryRowContext(ctx, query,
+	err = r.db.QueryRowContext(ctx, query,
 		tenantID,
 		req.Name,
 		req.Type,
@@ -416,7 +544,8 @@ func (r *SourceRepository) CreateWithTenant(ctx context.Context, req *models.Cre
 		req.Port,
 		req.DatabaseName,
 		req.Username,
-		req.Password,
+		password,
+		passwordEncrypted,
 		req.SSLMode,
 		nullString(req.SlotName),
 		nullString(req.PublicationName),
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/janovincze/philotes/internal/crypto"
+)
+
+func TestSourceRepository_StoredPassword(t *testing.T) {
+	r := NewSourceRepository(nil)
+
+	// Without an encryptor passwords are stored as they are
+	password, encrypted, err := r.storedPassword("secret")
+	if err != nil || password.String != "secret" || !password.Valid || encrypted != nil {
+		t.Fatalf("storedPassword() = %v, %v, %v, want the plaintext password", password, encrypted, err)
+	}
+	plaintext := sourceRow{Password: password}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	encryptor, err := crypto.NewEncryptor(key)
+	if err != nil {
+		t.Fatalf("NewEncryptor() error = %v", err)
+	}
+	r.SetEncryptor(encryptor)
+
+	password, encrypted, err = r.storedPassword("secret")
+	if err != nil || password.Valid || encrypted == nil {
+		t.Fatalf("storedPassword() = %v, %v, %v, want only the encrypted password", password, encrypted, err)
+	}
+
+	row := sourceRow{Password: password, PasswordEncrypted: encrypted}
+	if got, err := r.decryptPassword(&row); err != nil || got != "secret" {
+		t.Errorf("decryptPassword() = %q, %v, want the password", got, err)
+	}
+	if got, err := r.decryptPassword(&plaintext); err != nil || got != "secret" {
+		t.Errorf("decryptPassword(plaintext) = %q, %v, want the password", got, err)
+	}
+
+	if _, err := NewSourceRepository(nil).decryptPassword(&row); err == nil {
+		t.Error("decryptPassword() without an encryptor error = nil, want an error")
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenRevocationRepository handles database operations for the denylist of
+// revoked JWTs.
+type TokenRevocationRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRevocationRepository creates a new TokenRevocationRepository.
+func NewTokenRevocationRepository(db *sql.DB) *TokenRevocationRepository {
+	return &TokenRevocationRepository{db: db}
+}
+
+// RevokeToken revokes the token with the given ID until it expires.
+func (r *TokenRevocationRepository) RevokeToken(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO philotes.revoked_tokens (token_id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenID, userID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession revokes the tokens issued for an identity provider session
+// until expiresAt.
+func (r *TokenRevocationRepository) RevokeSession(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO philotes.revoked_sessions (session_id, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (session_id) DO UPDATE SET expires_at = GREATEST(revoked_sessions.expires_at, EXCLUDED.expires_at)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, sessionID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserTokens revokes the tokens of a user issued before the given
+// time, until expiresAt.
+func (r *TokenRevocationRepository) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	query := `
+		INSERT INTO philotes.user_token_revocations (user_id, revoked_before, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before),
+			expires_at = GREATEST(user_token_revocations.expires_at, EXCLUDED.expires_at)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, before, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token is revoked by its ID, its identity
+// provider session, or its user. Empty IDs never match.
+func (r *TokenRevocationRepository) IsRevoked(ctx context.Context, tokenID, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM philotes.revoked_tokens WHERE $1 <> '' AND token_id = $1
+		) OR EXISTS (
+			SELECT 1 FROM philotes.revoked_sessions WHERE $2 <> '' AND session_id = $2
+		) OR EXISTS (
+			SELECT 1 FROM philotes.user_token_revocations WHERE user_id = $3 AND revoked_before > $4
+		)
+	`
+
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, query, tokenID, sessionID, userID, issuedAt).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// DeleteExpired deletes the revocations that no unexpired token can match
+// any more and returns how many were deleted.
+func (r *TokenRevocationRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for _, table := range []string{"revoked_tokens", "revoked_sessions", "user_token_revocations"} {
+		result, err := r.db.ExecContext(ctx, `DELETE FROM philotes.`+table+` WHERE expires_at <= $1`, now)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete expired %s: %w", table, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		deleted += rows
+	}
+	return deleted, nil
+}
@@ -291,6 +291,34 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, req *models.U
 	return r.GetByID(ctx, id)
 }
 
+// UpdateEmail updates the email address of a user.
+func (r *UserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	query := `
+		UPDATE philotes.users
+		SET email = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, email, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrUserEmailExists
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 // UpdateLastLogin updates the last login time for a user.
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -424,6 +452,52 @@ func (r *UserRepository) GetByOIDCSubject(ctx context.Context, providerID uuid.U
 	return row.toModel(), nil
 }
 
+// ListByOIDCProvider retrieves the users linked to an OIDC provider.
+func (r *UserRepository) ListByOIDCProvider(ctx context.Context, providerID uuid.UUID) ([]models.User, error) {
+	query := `
+		SELECT id, email, password_hash, name, role, is_active, last_login_at,
+		       oidc_provider_id, oidc_subject, oidc_groups, created_at, updated_at
+		FROM philotes.users
+		WHERE oidc_provider_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oidc users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var row userRow
+		err := rows.Scan(
+			&row.ID,
+			&row.Email,
+			&row.PasswordHash,
+			&row.Name,
+			&row.Role,
+			&row.IsActive,
+			&row.LastLoginAt,
+			&row.OIDCProviderID,
+			&row.OIDCSubject,
+			pq.Array(&row.OIDCGroups),
+			&row.CreatedAt,
+			&row.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, *row.toModel())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
 // CreateOIDCUser creates a new user from OIDC authentication.
 func (r *UserRepository) CreateOIDCUser(ctx context.Context, email, name string, role models.UserRole, providerID uuid.UUID, subject string, groups []string) (*models.User, error) {
 	query := `
@@ -13,6 +13,8 @@ import (
 
 	"github.com/janovincze/philotes/internal/api/handlers"
 	"github.com/janovincze/philotes/internal/api/middleware"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/openapi"
 	"github.com/janovincze/philotes/internal/api/services"
 	"github.com/janovincze/philotes/internal/cdc/health"
 	"github.com/janovincze/philotes/internal/config"
@@ -22,27 +24,40 @@ import (
 
 // Server is the HTTP API server.
 type Server struct {
-	cfg                   *config.Config
-	logger                *slog.Logger
-	healthManager         *health.Manager
-	sourceService         *services.SourceService
-	pipelineService       *services.PipelineService
-	alertService          *services.AlertService
-	metricsService        *services.MetricsService
-	installerService      *services.InstallerService
-	installerLogHub       *installer.LogHub
-	installerOrchestrator *installer.DeploymentOrchestrator
-	authService           *services.AuthService
-	apiKeyService         *services.APIKeyService
-	oauthService          *services.OAuthService
-	oidcService           *services.OIDCService
-	onboardingService     *services.OnboardingService
-	nodePoolService       *services.NodePoolService
-	queryService          *services.QueryService
-	queryScalingService   *services.QueryScalingService
-	tenantService         *services.TenantService
-	httpServer            *http.Server
-	router                *gin.Engine
+	cfg                    *config.Config
+	logger                 *slog.Logger
+	healthManager          *health.Manager
+	sourceService          *services.SourceService
+	pipelineService        *services.PipelineService
+	alertService           *services.AlertService
+	metricsService         *services.MetricsService
+	installerService       *services.InstallerService
+	installerLogHub        *installer.LogHub
+	installerOrchestrator  *installer.DeploymentOrchestrator
+	authService            *services.AuthService
+	apiKeyService          *services.APIKeyService
+	tokenRevocationService *services.TokenRevocationService
+	idempotencyService     *services.IdempotencyService
+	oauthService           *services.OAuthService
+	oidcService            *services.OIDCService
+	scimService            *services.SCIMService
+	onboardingService      *services.OnboardingService
+	nodePoolService        *services.NodePoolService
+	queryService           *services.QueryService
+	queryScalingService    *services.QueryScalingService
+	savedQueryService      *services.SavedQueryService
+	queryHistoryService    *services.QueryHistoryService
+	tenantService          *services.TenantService
+	tableService           *services.TableService
+	auditRetentionService  *services.AuditRetentionService
+	auditService           *services.AuditService
+	deadLetterService      *services.DeadLetterService
+	statusService          *services.StatusService
+	remoteWriteService     *services.RemoteWriteService
+	httpServer             *http.Server
+	router                 *gin.Engine
+	rateLimit              *middleware.RateLimit
+	configHandler          *handlers.ConfigHandler
 }
 
 // ServerConfig holds server configuration options.
@@ -83,12 +98,22 @@ type ServerConfig struct {
 	// APIKeyService is the API key service for API key management.
 	APIKeyService *services.APIKeyService
 
+	// TokenRevocationService is the denylist of revoked JWTs.
+	TokenRevocationService *services.TokenRevocationService
+
+	// IdempotencyService stores the responses of POST requests sent with an
+	// Idempotency-Key header.
+	IdempotencyService *services.IdempotencyService
+
 	// OAuthService is the OAuth service for cloud provider authentication.
 	OAuthService *services.OAuthService
 
 	// OIDCService is the OIDC service for SSO authentication.
 	OIDCService *services.OIDCService
 
+	// SCIMService is the SCIM service for user and group provisioning.
+	SCIMService *services.SCIMService
+
 	// OnboardingService is the onboarding service for post-installation wizard.
 	OnboardingService *services.OnboardingService
 
@@ -101,24 +126,58 @@ type ServerConfig struct {
 	// QueryScalingService is the query scaling service for query engine auto-scaling.
 	QueryScalingService *services.QueryScalingService
 
+	// SavedQueryService is the saved query service for saved query CRUD and runs.
+	SavedQueryService *services.SavedQueryService
+
+	// QueryHistoryService is the query history service recording executed statements.
+	QueryHistoryService *services.QueryHistoryService
+
 	// TenantService is the tenant service for multi-tenancy operations.
 	TenantService *services.TenantService
 
+	// TableService is the table service for Iceberg metadata inspection.
+	TableService *services.TableService
+
+	// AuditRetentionService is the service for tenant audit retention and legal holds.
+	AuditRetentionService *services.AuditRetentionService
+
+	// AuditService records mutating API requests in the audit log and lists
+	// it; nil leaves requests unaudited.
+	AuditService *services.AuditService
+
+	// DeadLetterService is the service for inspecting and replaying dead-letter events.
+	DeadLetterService *services.DeadLetterService
+
+	// StatusService is the service for the system status summary.
+	StatusService *services.StatusService
+
+	// RemoteWriteService receives Prometheus remote-write requests; nil
+	// leaves the endpoint disabled.
+	RemoteWriteService *services.RemoteWriteService
+
 	// CORSConfig is the CORS configuration.
 	CORSConfig middleware.CORSConfig
 
 	// RateLimitConfig is the rate limiting configuration.
 	RateLimitConfig middleware.RateLimitConfig
+
+	// CompressionConfig is the response compression configuration.
+	CompressionConfig middleware.CompressionConfig
+
+	// ETagConfig is the conditional GET configuration.
+	ETagConfig middleware.ETagConfig
 }
 
 // DefaultServerConfig returns a ServerConfig with sensible defaults.
 func DefaultServerConfig(cfg *config.Config, logger *slog.Logger) ServerConfig {
 	return ServerConfig{
-		Config:          cfg,
-		Logger:          logger,
-		HealthManager:   nil,
-		CORSConfig:      middleware.DefaultCORSConfig(),
-		RateLimitConfig: middleware.DefaultRateLimitConfig(),
+		Config:            cfg,
+		Logger:            logger,
+		HealthManager:     nil,
+		CORSConfig:        middleware.DefaultCORSConfig(),
+		RateLimitConfig:   middleware.DefaultRateLimitConfig(),
+		CompressionConfig: middleware.DefaultCompressionConfig(),
+		ETagConfig:        middleware.DefaultETagConfig(),
 	}
 }
 
@@ -150,30 +209,49 @@ func NewServer(serverCfg ServerConfig) *Server {
 	}
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.CORS(serverCfg.CORSConfig))
-	router.Use(middleware.RateLimiter(serverCfg.RateLimitConfig))
+	rateLimitCfg := serverCfg.RateLimitConfig
+	if rateLimitCfg.APIKeyLimits == nil && serverCfg.APIKeyService != nil {
+		rateLimitCfg.APIKeyLimits = serverCfg.APIKeyService.RateLimit
+	}
+	rateLimit := middleware.NewRateLimit(rateLimitCfg)
+	router.Use(rateLimit.Handler())
+	router.Use(middleware.Compression(serverCfg.CompressionConfig))
+	router.Use(middleware.ETag(serverCfg.ETagConfig))
 
 	// Create server
 	s := &Server{
-		cfg:                   serverCfg.Config,
-		logger:                logger.With("component", "api-server"),
-		healthManager:         serverCfg.HealthManager,
-		sourceService:         serverCfg.SourceService,
-		pipelineService:       serverCfg.PipelineService,
-		alertService:          serverCfg.AlertService,
-		metricsService:        serverCfg.MetricsService,
-		installerService:      serverCfg.InstallerService,
-		installerLogHub:       serverCfg.InstallerLogHub,
-		installerOrchestrator: serverCfg.InstallerOrchestrator,
-		authService:           serverCfg.AuthService,
-		apiKeyService:         serverCfg.APIKeyService,
-		oauthService:          serverCfg.OAuthService,
-		oidcService:           serverCfg.OIDCService,
-		onboardingService:     serverCfg.OnboardingService,
-		nodePoolService:       serverCfg.NodePoolService,
-		queryService:          serverCfg.QueryService,
-		queryScalingService:   serverCfg.QueryScalingService,
-		tenantService:         serverCfg.TenantService,
-		router:                router,
+		cfg:                    serverCfg.Config,
+		logger:                 logger.With("component", "api-server"),
+		healthManager:          serverCfg.HealthManager,
+		sourceService:          serverCfg.SourceService,
+		pipelineService:        serverCfg.PipelineService,
+		alertService:           serverCfg.AlertService,
+		metricsService:         serverCfg.MetricsService,
+		installerService:       serverCfg.InstallerService,
+		installerLogHub:        serverCfg.InstallerLogHub,
+		installerOrchestrator:  serverCfg.InstallerOrchestrator,
+		authService:            serverCfg.AuthService,
+		apiKeyService:          serverCfg.APIKeyService,
+		tokenRevocationService: serverCfg.TokenRevocationService,
+		idempotencyService:     serverCfg.IdempotencyService,
+		oauthService:           serverCfg.OAuthService,
+		oidcService:            serverCfg.OIDCService,
+		scimService:            serverCfg.SCIMService,
+		onboardingService:      serverCfg.OnboardingService,
+		nodePoolService:        serverCfg.NodePoolService,
+		queryService:           serverCfg.QueryService,
+		queryScalingService:    serverCfg.QueryScalingService,
+		savedQueryService:      serverCfg.SavedQueryService,
+		queryHistoryService:    serverCfg.QueryHistoryService,
+		tenantService:          serverCfg.TenantService,
+		tableService:           serverCfg.TableService,
+		auditRetentionService:  serverCfg.AuditRetentionService,
+		auditService:           serverCfg.AuditService,
+		deadLetterService:      serverCfg.DeadLetterService,
+		statusService:          serverCfg.StatusService,
+		remoteWriteService:     serverCfg.RemoteWriteService,
+		router:                 router,
+		rateLimit:              rateLimit,
 	}
 
 	// Register routes
@@ -196,7 +274,8 @@ func (s *Server) registerRoutes() {
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(s.healthManager)
 	versionHandler := handlers.NewVersionHandler(s.cfg.Version)
-	configHandler := handlers.NewConfigHandler(s.cfg)
+	s.configHandler = handlers.NewConfigHandler(s.cfg)
+	openAPIHandler := handlers.NewOpenAPIHandler()
 
 	// Create source, pipeline, and alert handlers (may be nil if services not provided)
 	var sourceHandler *handlers.SourceHandler
@@ -209,7 +288,7 @@ func (s *Server) registerRoutes() {
 		pipelineHandler = handlers.NewPipelineHandler(s.pipelineService)
 	}
 	if s.alertService != nil {
-		alertHandler = handlers.NewAlertHandler(s.alertService)
+		alertHandler = handlers.NewAlertHandler(s.alertService, s.tenantService)
 	}
 
 	// Create auth handlers
@@ -236,10 +315,11 @@ func (s *Server) registerRoutes() {
 
 	// Configure auth middleware
 	authConfig := middleware.AuthConfig{
-		Enabled:       s.cfg.Auth.Enabled,
-		AuthService:   s.authService,
-		APIKeyService: s.apiKeyService,
-		APIKeyPrefix:  s.cfg.Auth.APIKeyPrefix,
+		Enabled:                s.cfg.Auth.Enabled,
+		AuthService:            s.authService,
+		APIKeyService:          s.apiKeyService,
+		APIKeyPrefix:           s.cfg.Auth.APIKeyPrefix,
+		TokenRevocationService: s.tokenRevocationService,
 	}
 
 	// Auth middleware: extracts credentials but doesn't require auth
@@ -253,18 +333,36 @@ func (s *Server) registerRoutes() {
 	s.router.GET("/health/live", healthHandler.GetLiveness)
 	s.router.GET("/health/ready", healthHandler.GetReadiness)
 
+	// Swagger UI for the OpenAPI document (no versioning, no auth)
+	s.router.GET("/docs", openAPIHandler.GetUI)
+
 	// Metrics endpoint (no versioning, no auth)
 	if s.cfg.Metrics.Enabled {
 		s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	}
 
+	// SCIM provisioning endpoints (no versioning, SCIM bearer token auth)
+	if s.scimService != nil {
+		scimHandler := handlers.NewSCIMHandler(s.scimService)
+		scimHandler.Register(s.router.Group("/scim/v2"))
+	}
+
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	v1.Use(authMiddleware) // Apply auth middleware to extract credentials
+	v1.Use(middleware.Audit(middleware.AuditConfig{
+		AuditService: s.auditService,
+		SkipRoutes:   []string{"/api/v1/metrics/write"},
+	}))
+	v1.Use(middleware.Idempotency(middleware.IdempotencyConfig{
+		IdempotencyService: s.idempotencyService,
+		AuthEnabled:        s.cfg.Auth.Enabled,
+	}))
 	{
-		// System endpoints (public)
+		// System endpoints
 		v1.GET("/version", versionHandler.GetVersion)
-		v1.GET("/config", configHandler.GetConfig)
+		v1.GET("/config", requireAuth, s.configHandler.GetConfig)
+		v1.GET("/openapi.json", openAPIHandler.GetDocument)
 
 		// Auth endpoints (registered by handler)
 		if authHandler != nil {
@@ -290,6 +388,17 @@ func (s *Server) registerRoutes() {
 		if s.tenantService != nil {
 			tenantHandler := handlers.NewTenantHandler(s.tenantService)
 			tenantHandler.Register(v1, requireAuth)
+
+			if s.auditRetentionService != nil {
+				auditRetentionHandler := handlers.NewAuditRetentionHandler(s.auditRetentionService, s.tenantService)
+				auditRetentionHandler.Register(v1, requireAuth)
+			}
+		}
+
+		// Audit log endpoint (admins and tenant admins)
+		if s.auditService != nil {
+			auditHandler := handlers.NewAuditHandler(s.auditService, s.tenantService)
+			auditHandler.RegisterRoutes(v1, requireAuth)
 		}
 
 		// Source endpoints (protected when auth is enabled)
@@ -302,6 +411,9 @@ func (s *Server) registerRoutes() {
 			sources.PUT("/:id", sourceHandler.Update)
 			sources.DELETE("/:id", sourceHandler.Delete)
 			sources.POST("/:id/test", sourceHandler.TestConnection)
+			sources.POST("/:id/validate", sourceHandler.Validate)
+			sources.GET("/:id/health", sourceHandler.Health)
+			sources.GET("/:id/schemas", sourceHandler.DiscoverSchemas)
 			sources.GET("/:id/tables", sourceHandler.DiscoverTables)
 		}
 
@@ -316,7 +428,10 @@ func (s *Server) registerRoutes() {
 			pipelines.DELETE("/:id", pipelineHandler.Delete)
 			pipelines.POST("/:id/start", pipelineHandler.Start)
 			pipelines.POST("/:id/stop", pipelineHandler.Stop)
+			pipelines.POST("/:id/pause", pipelineHandler.Pause)
+			pipelines.POST("/:id/resume", pipelineHandler.Resume)
 			pipelines.GET("/:id/status", pipelineHandler.GetStatus)
+			pipelines.GET("/:id/buffer", pipelineHandler.GetBufferStats)
 			pipelines.POST("/:id/tables", pipelineHandler.AddTableMapping)
 			pipelines.DELETE("/:id/tables/:mappingId", pipelineHandler.RemoveTableMapping)
 
@@ -344,12 +459,44 @@ func (s *Server) registerRoutes() {
 
 		// Query layer endpoints (protected when auth is enabled)
 		if s.queryService != nil {
-			queryHandler := handlers.NewQueryHandler(s.queryService, s.logger)
+			queryHandler := handlers.NewQueryHandler(s.queryService, s.queryHistoryService, s.logger)
 			protected := v1.Group("")
 			protected.Use(requireAuth)
 			queryHandler.RegisterRoutes(protected)
 		}
 
+		// Saved query endpoints (protected when auth is enabled)
+		if s.savedQueryService != nil {
+			savedQueryHandler := handlers.NewSavedQueryHandler(s.savedQueryService, s.queryService, s.queryHistoryService, s.logger)
+			protected := v1.Group("")
+			protected.Use(requireAuth)
+			savedQueryHandler.RegisterRoutes(protected)
+		}
+
+		// Iceberg table metadata endpoints (protected when auth is enabled)
+		if s.tableService != nil && s.cfg.Iceberg.MetadataTablesEnabled {
+			tableHandler := handlers.NewTableHandler(s.tableService)
+			tableHandler.RegisterRoutes(v1, requireAuth)
+		}
+
+		// Dead-letter queue endpoints (protected when auth is enabled)
+		if s.deadLetterService != nil {
+			deadLetterHandler := handlers.NewDeadLetterHandler(s.deadLetterService)
+			deadLetterHandler.RegisterRoutes(v1, requireAuth)
+		}
+
+		// Prometheus remote-write receiver (protected when auth is enabled)
+		if s.remoteWriteService != nil {
+			remoteWriteHandler := handlers.NewRemoteWriteHandler(s.remoteWriteService)
+			remoteWriteHandler.RegisterRoutes(v1, requireAuth)
+		}
+
+		// System status summary (protected when auth is enabled)
+		if s.statusService != nil {
+			statusHandler := handlers.NewStatusHandler(s.statusService)
+			statusHandler.RegisterRoutes(v1, requireAuth)
+		}
+
 		// Query scaling endpoints (protected when auth is enabled)
 		if s.queryScalingService != nil {
 			queryScalingHandler := handlers.NewQueryScalingHandler(s.queryScalingService, s.logger)
@@ -365,6 +512,7 @@ func (s *Server) registerRoutes() {
 			installerGroup.GET("/providers", installerHandler.ListProviders)
 			installerGroup.GET("/providers/:id", installerHandler.GetProvider)
 			installerGroup.GET("/providers/:id/estimate", installerHandler.GetCostEstimate)
+			installerGroup.POST("/estimate", installerHandler.EstimateCost)
 
 			// OAuth endpoints (registered if OAuth service is available)
 			if s.oauthService != nil {
@@ -395,16 +543,41 @@ func (s *Server) registerRoutes() {
 			deployments.GET("/:id", installerHandler.GetDeployment)
 			deployments.POST("/:id/cancel", installerHandler.CancelDeployment)
 			deployments.DELETE("/:id", installerHandler.DeleteDeployment)
+			deployments.POST("/:id/destroy", installerHandler.DestroyDeployment)
+			deployments.PUT("/:id/delete-protection", installerHandler.UpdateDeleteProtection)
 			deployments.GET("/:id/logs", installerHandler.GetDeploymentLogs)
-			// WebSocket endpoint for real-time log streaming
+			// WebSocket endpoints for real-time log streaming
+			deployments.GET("/:id/logs/ws", installerHandler.StreamDeploymentLogs)
 			deployments.GET("/:id/logs/stream", installerHandler.StreamDeploymentLogs)
 			// Progress tracking endpoints
 			deployments.GET("/:id/progress", installerHandler.GetDeploymentProgress)
 			deployments.POST("/:id/retry", installerHandler.RetryDeployment)
+			deployments.POST("/:id/preview", installerHandler.PreviewDeployment)
 			deployments.GET("/:id/cleanup-preview", installerHandler.GetCleanupResources)
 			deployments.GET("/:id/retry-info", installerHandler.GetRetryInfo)
 		}
 	}
+
+	// Generate the OpenAPI document from the registered routes
+	doc := openapi.Build(openapi.Config{
+		Info: openapi.Info{
+			Title:       "Philotes Management API",
+			Description: "Manages Philotes CDC sources, pipelines, alerting and tenants. Errors are RFC 7807 problem details.",
+			Version:     s.cfg.Version,
+		},
+		ErrorResponse: models.ProblemDetails{},
+	}, s.router.Routes(), handlers.APIDocs())
+	if err := openAPIHandler.SetDocument(doc); err != nil {
+		s.logger.Error("failed to generate OpenAPI document", "error", err)
+	}
+}
+
+// ApplyConfig applies the settings of a reloaded configuration that take
+// effect without a restart, the rate limits, and serves cfg from
+// GET /api/v1/config.
+func (s *Server) ApplyConfig(cfg *config.Config) {
+	s.rateLimit.SetLimits(cfg.API.RateLimitRPS, cfg.API.RateLimitBurst)
+	s.configHandler.SetConfig(cfg)
 }
 
 // Start starts the HTTP server.
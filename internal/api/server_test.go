@@ -131,6 +131,44 @@ func TestServer_ConfigEndpoint(t *testing.T) {
 	}
 }
 
+func TestServer_ConfigEndpointRequiresAuth(t *testing.T) {
+	server := newTestServer(t)
+	cfg := *server.cfg
+	cfg.Auth.Enabled = true
+	server = NewServer(DefaultServerConfig(&cfg, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+	w := httptest.NewRecorder()
+
+	server.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestServer_ApplyConfig(t *testing.T) {
+	server := newTestServer(t)
+
+	reloaded := *server.cfg
+	reloaded.API.RateLimitRPS = 5
+	server.ApplyConfig(&reloaded)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+	w := httptest.NewRecorder()
+
+	server.Router().ServeHTTP(w, req)
+
+	var response models.ConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.API.RateLimitRPS != 5 {
+		t.Errorf("expected rate_limit_rps 5, got %v", response.API.RateLimitRPS)
+	}
+}
+
 func TestServer_EndpointsWithoutServices(t *testing.T) {
 	// When no services are configured, source/pipeline endpoints are not registered
 	// and should return 404
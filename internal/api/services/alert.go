@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -17,8 +18,11 @@ import (
 
 // AlertService provides business logic for alerting operations.
 type AlertService struct {
-	repo   *repositories.AlertRepository
-	logger *slog.Logger
+	repo           *repositories.AlertRepository
+	configResolver alerting.ConfigResolver
+	hub            *alerting.Hub
+	auditRepo      *repositories.AuditRepository
+	logger         *slog.Logger
 }
 
 // NewAlertService creates a new AlertService.
@@ -29,10 +33,37 @@ func NewAlertService(repo *repositories.AlertRepository, logger *slog.Logger) *A
 	}
 }
 
+// SetConfigResolver sets the resolver for secret references (such as
+// vault://path#key) in channel configurations.
+func (s *AlertService) SetConfigResolver(resolver alerting.ConfigResolver) {
+	s.configResolver = resolver
+}
+
+// SetAuditRepository sets the repository that bulk operations are audited
+// to. Without one they are not audited.
+func (s *AlertService) SetAuditRepository(auditRepo *repositories.AuditRepository) {
+	s.auditRepo = auditRepo
+}
+
+// SetEventHub sets the hub that alert lifecycle events are streamed from.
+func (s *AlertService) SetEventHub(hub *alerting.Hub) {
+	s.hub = hub
+}
+
+// Rules and channels belong to a tenant, and alert instances and routes to
+// the tenant of their rule. Methods take the caller's tenant scope: with a
+// non-nil scope, resources of other tenants are reported as not found; a nil
+// scope sees every tenant.
+
+// inTenantScope reports whether a resource owned by owner is visible in scope.
+func inTenantScope(scope, owner *uuid.UUID) bool {
+	return scope == nil || (owner != nil && *owner == *scope)
+}
+
 // Alert Rules
 
-// CreateRule creates a new alert rule.
-func (s *AlertService) CreateRule(ctx context.Context, req *models.CreateAlertRuleRequest) (*alerting.AlertRule, error) {
+// CreateRule creates a new alert rule owned by tenantID.
+func (s *AlertService) CreateRule(ctx context.Context, tenantID *uuid.UUID, req *models.CreateAlertRuleRequest) (*alerting.AlertRule, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
@@ -42,7 +73,7 @@ func (s *AlertService) CreateRule(ctx context.Context, req *models.CreateAlertRu
 	req.ApplyDefaults()
 
 	// Create rule
-	rule, err := s.repo.CreateRule(ctx, req)
+	rule, err := s.repo.CreateRule(ctx, tenantID, req)
 	if err != nil {
 		if errors.Is(err, repositories.ErrAlertRuleNameExists) {
 			return nil, &ConflictError{Message: "alert rule with this name already exists"}
@@ -56,7 +87,7 @@ func (s *AlertService) CreateRule(ctx context.Context, req *models.CreateAlertRu
 }
 
 // GetRule retrieves an alert rule by ID.
-func (s *AlertService) GetRule(ctx context.Context, id uuid.UUID) (*alerting.AlertRule, error) {
+func (s *AlertService) GetRule(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*alerting.AlertRule, error) {
 	rule, err := s.repo.GetRule(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrAlertRuleNotFound) {
@@ -64,13 +95,16 @@ func (s *AlertService) GetRule(ctx context.Context, id uuid.UUID) (*alerting.Ale
 		}
 		return nil, fmt.Errorf("failed to get alert rule: %w", err)
 	}
+	if !inTenantScope(tenantID, rule.TenantID) {
+		return nil, &NotFoundError{Resource: "alert rule", ID: id.String()}
+	}
 	return rule, nil
 }
 
 // ListRules retrieves all alert rules with pagination.
-func (s *AlertService) ListRules(ctx context.Context, limit, offset int) (*models.AlertRuleListResponse, error) {
+func (s *AlertService) ListRules(ctx context.Context, tenantID *uuid.UUID, limit, offset int) (*models.AlertRuleListResponse, error) {
 	// Get total count first
-	allRules, err := s.repo.ListRulesPaginated(ctx, false, 0, 0)
+	allRules, err := s.repo.ListRulesPaginated(ctx, tenantID, false, 0, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count alert rules: %w", err)
 	}
@@ -79,7 +113,7 @@ func (s *AlertService) ListRules(ctx context.Context, limit, offset int) (*model
 	// Get paginated rules
 	var rules []alerting.AlertRule
 	if limit > 0 {
-		rules, err = s.repo.ListRulesPaginated(ctx, false, limit, offset)
+		rules, err = s.repo.ListRulesPaginated(ctx, tenantID, false, limit, offset)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list alert rules: %w", err)
 		}
@@ -98,12 +132,24 @@ func (s *AlertService) ListRules(ctx context.Context, limit, offset int) (*model
 }
 
 // UpdateRule updates an alert rule.
-func (s *AlertService) UpdateRule(ctx context.Context, id uuid.UUID, req *models.UpdateAlertRuleRequest) (*alerting.AlertRule, error) {
+func (s *AlertService) UpdateRule(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.UpdateAlertRuleRequest) (*alerting.AlertRule, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
 	}
 
+	existing, err := s.GetRule(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the rule still has exactly one condition after the update
+	if req.MetricName != nil || req.Operator != nil || req.Threshold != nil || req.Expression != nil {
+		if errs := req.ValidateFor(existing); len(errs) > 0 {
+			return nil, &ValidationError{Errors: errs}
+		}
+	}
+
 	// Update rule
 	rule, err := s.repo.UpdateRule(ctx, id, req)
 	if err != nil {
@@ -121,7 +167,11 @@ func (s *AlertService) UpdateRule(ctx context.Context, id uuid.UUID, req *models
 }
 
 // DeleteRule deletes an alert rule.
-func (s *AlertService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+func (s *AlertService) DeleteRule(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) error {
+	if _, err := s.GetRule(ctx, id, tenantID); err != nil {
+		return err
+	}
+
 	err := s.repo.DeleteRule(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrAlertRuleNotFound) {
@@ -137,7 +187,7 @@ func (s *AlertService) DeleteRule(ctx context.Context, id uuid.UUID) error {
 // Alert Instances
 
 // GetAlert retrieves an alert instance by ID.
-func (s *AlertService) GetAlert(ctx context.Context, id uuid.UUID) (*alerting.AlertInstance, error) {
+func (s *AlertService) GetAlert(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*alerting.AlertInstance, error) {
 	alert, err := s.repo.GetInstance(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrAlertInstanceNotFound) {
@@ -145,11 +195,20 @@ func (s *AlertService) GetAlert(ctx context.Context, id uuid.UUID) (*alerting.Al
 		}
 		return nil, fmt.Errorf("failed to get alert: %w", err)
 	}
+	if tenantID != nil {
+		if _, err := s.GetRule(ctx, alert.RuleID, tenantID); err != nil {
+			var notFound *NotFoundError
+			if errors.As(err, &notFound) {
+				return nil, &NotFoundError{Resource: "alert", ID: id.String()}
+			}
+			return nil, err
+		}
+	}
 	return alert, nil
 }
 
 // ListAlerts retrieves alert instances with optional filtering.
-func (s *AlertService) ListAlerts(ctx context.Context, status string, severity string, limit, offset int) (*models.AlertInstanceListResponse, error) {
+func (s *AlertService) ListAlerts(ctx context.Context, tenantID *uuid.UUID, status string, severity string, limit, offset int) (*models.AlertInstanceListResponse, error) {
 	var statusFilter *alerting.AlertStatus
 	if status != "" {
 		s := alerting.AlertStatus(status)
@@ -161,7 +220,7 @@ func (s *AlertService) ListAlerts(ctx context.Context, status string, severity s
 		statusFilter = &s
 	}
 
-	alerts, err := s.repo.ListInstances(ctx, statusFilter, nil)
+	alerts, err := s.repo.ListInstances(ctx, tenantID, statusFilter, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list alerts: %w", err)
 	}
@@ -208,19 +267,16 @@ func (s *AlertService) ListAlerts(ctx context.Context, status string, severity s
 }
 
 // AcknowledgeAlert acknowledges an alert instance.
-func (s *AlertService) AcknowledgeAlert(ctx context.Context, id uuid.UUID, req *models.AcknowledgeAlertRequest) error {
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.AcknowledgeAlertRequest) error {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return &ValidationError{Errors: errs}
 	}
 
 	// Check alert exists
-	alert, err := s.repo.GetInstance(ctx, id)
+	alert, err := s.GetAlert(ctx, id, tenantID)
 	if err != nil {
-		if errors.Is(err, repositories.ErrAlertInstanceNotFound) {
-			return &NotFoundError{Resource: "alert", ID: id.String()}
-		}
-		return fmt.Errorf("failed to get alert: %w", err)
+		return err
 	}
 
 	// Check if already acknowledged
@@ -263,14 +319,10 @@ func (s *AlertService) AcknowledgeAlert(ctx context.Context, id uuid.UUID, req *
 }
 
 // GetAlertHistory retrieves history for an alert instance.
-func (s *AlertService) GetAlertHistory(ctx context.Context, alertID uuid.UUID, limit, offset int) (*models.AlertHistoryResponse, error) {
+func (s *AlertService) GetAlertHistory(ctx context.Context, alertID uuid.UUID, tenantID *uuid.UUID, limit, offset int) (*models.AlertHistoryResponse, error) {
 	// Check alert exists
-	_, err := s.repo.GetInstance(ctx, alertID)
-	if err != nil {
-		if errors.Is(err, repositories.ErrAlertInstanceNotFound) {
-			return nil, &NotFoundError{Resource: "alert", ID: alertID.String()}
-		}
-		return nil, fmt.Errorf("failed to get alert: %w", err)
+	if _, err := s.GetAlert(ctx, alertID, tenantID); err != nil {
+		return nil, err
 	}
 
 	// Get history
@@ -299,6 +351,239 @@ func (s *AlertService) GetAlertHistory(ctx context.Context, alertID uuid.UUID, l
 	}, nil
 }
 
+// Bulk Operations
+
+// BulkAcknowledgeAlerts acknowledges the alerts selected by ID or by label
+// matchers in one transaction, and reports the outcome for each.
+func (s *AlertService) BulkAcknowledgeAlerts(ctx context.Context, tenantID *uuid.UUID, req *models.BulkAcknowledgeRequest, userID *uuid.UUID, ipAddress, userAgent string) (*models.BulkAcknowledgeResponse, error) {
+	if errs := req.Validate(); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	// Outcomes are reported in request order; a failed alert maps to its error
+	var order []uuid.UUID
+	failures := make(map[uuid.UUID]string)
+	var candidates []uuid.UUID
+
+	if len(req.Matchers) > 0 {
+		status := alerting.StatusFiring
+		instances, err := s.repo.ListInstances(ctx, tenantID, &status, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alerts: %w", err)
+		}
+		for _, instance := range instances {
+			if instance.AcknowledgedAt == nil && labelsMatch(instance.Labels, req.Matchers) {
+				order = append(order, instance.ID)
+				candidates = append(candidates, instance.ID)
+			}
+		}
+	} else {
+		for _, id := range uniqueIDs(req.IDs) {
+			order = append(order, id)
+			alert, err := s.GetAlert(ctx, id, tenantID)
+			if err != nil {
+				var notFoundErr *NotFoundError
+				if !errors.As(err, &notFoundErr) {
+					return nil, err
+				}
+				failures[id] = "alert not found"
+				continue
+			}
+			if alert.AcknowledgedAt != nil {
+				failures[id] = "alert has already been acknowledged"
+				continue
+			}
+			candidates = append(candidates, id)
+		}
+	}
+
+	if len(candidates) > 0 {
+		metadata := map[string]any{
+			"acknowledged_by": req.AcknowledgedBy,
+			"bulk":            true,
+		}
+		if req.Comment != "" {
+			metadata["comment"] = req.Comment
+		}
+		acknowledged, err := s.repo.AcknowledgeInstances(ctx, candidates,
+			req.AcknowledgedBy, fmt.Sprintf("Alert acknowledged by %s", req.AcknowledgedBy), metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acknowledge alerts: %w", err)
+		}
+
+		// Candidates acknowledged concurrently are left out of the update
+		done := make(map[uuid.UUID]bool, len(acknowledged))
+		for _, instance := range acknowledged {
+			done[instance.ID] = true
+		}
+		for _, id := range candidates {
+			if !done[id] {
+				failures[id] = "alert has already been acknowledged"
+			}
+		}
+	}
+
+	resp := &models.BulkAcknowledgeResponse{Results: bulkResults(order, failures)}
+	resp.Failed = len(failures)
+	resp.Acknowledged = len(order) - resp.Failed
+
+	details := map[string]any{
+		"acknowledged_by": req.AcknowledgedBy,
+		"acknowledged":    resp.Acknowledged,
+		"failed":          resp.Failed,
+	}
+	if len(req.Matchers) > 0 {
+		details["matchers"] = req.Matchers
+	} else {
+		details["ids"] = req.IDs
+	}
+	s.logAuditEvent(ctx, userID, tenantID, models.AuditActionAlertsAcknowledged, ipAddress, userAgent, details)
+
+	s.logger.Info("alerts acknowledged", "acknowledged", resp.Acknowledged, "failed", resp.Failed, "by", req.AcknowledgedBy)
+	return resp, nil
+}
+
+// BulkSilenceAlerts creates a silence matching the labels that the selected
+// alerts have in common, and reports which of them it was built from.
+func (s *AlertService) BulkSilenceAlerts(ctx context.Context, tenantID *uuid.UUID, req *models.BulkSilenceRequest, userID *uuid.UUID, ipAddress, userAgent string) (*models.BulkSilenceResponse, error) {
+	if errs := req.Validate(); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	req.ApplyDefaults()
+
+	order := uniqueIDs(req.IDs)
+	failures := make(map[uuid.UUID]string)
+	var selected []*alerting.AlertInstance
+	for _, id := range order {
+		alert, err := s.GetAlert(ctx, id, tenantID)
+		if err != nil {
+			var notFoundErr *NotFoundError
+			if !errors.As(err, &notFoundErr) {
+				return nil, err
+			}
+			failures[id] = "alert not found"
+			continue
+		}
+		selected = append(selected, alert)
+	}
+
+	if len(selected) == 0 {
+		return nil, &ValidationError{Errors: []models.FieldError{{Field: "ids", Message: "none of the selected alerts were found"}}}
+	}
+	matchers := commonLabels(selected)
+	if len(matchers) == 0 {
+		return nil, &ValidationError{Errors: []models.FieldError{{Field: "ids", Message: "the selected alerts have no labels in common"}}}
+	}
+
+	silence, err := s.CreateSilence(ctx, &models.CreateSilenceRequest{
+		Matchers:  matchers,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: req.CreatedBy,
+		Comment:   req.Comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logAuditEvent(ctx, userID, tenantID, models.AuditActionAlertsSilenced, ipAddress, userAgent, map[string]any{
+		"silence_id": silence.ID,
+		"matchers":   matchers,
+		"ids":        req.IDs,
+		"created_by": req.CreatedBy,
+	})
+
+	return &models.BulkSilenceResponse{
+		Silence: silence,
+		Results: bulkResults(order, failures),
+	}, nil
+}
+
+// uniqueIDs returns ids without duplicates, in their original order.
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// bulkResults reports the outcome for each of ids, failed when it has an
+// entry in failures.
+func bulkResults(ids []uuid.UUID, failures map[uuid.UUID]string) []models.BulkAlertResult {
+	results := make([]models.BulkAlertResult, 0, len(ids))
+	for _, id := range ids {
+		msg, failed := failures[id]
+		results = append(results, models.BulkAlertResult{ID: id, Success: !failed, Error: msg})
+	}
+	return results
+}
+
+// labelsMatch reports whether labels include all of matchers.
+func labelsMatch(labels, matchers map[string]string) bool {
+	for key, value := range matchers {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// commonLabels returns the labels that all instances have with the same value.
+func commonLabels(instances []*alerting.AlertInstance) map[string]string {
+	common := make(map[string]string, len(instances[0].Labels))
+	for key, value := range instances[0].Labels {
+		common[key] = value
+	}
+	for _, instance := range instances[1:] {
+		for key, value := range common {
+			if v, ok := instance.Labels[key]; !ok || v != value {
+				delete(common, key)
+			}
+		}
+	}
+	return common
+}
+
+// logAuditEvent logs an audit event for an alert operation asynchronously.
+func (s *AlertService) logAuditEvent(_ context.Context, userID, tenantID *uuid.UUID, action, ipAddress, userAgent string, details map[string]any) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	log := &models.AuditLog{
+		UserID:       userID,
+		TenantID:     tenantID,
+		Action:       action,
+		ResourceType: "alert",
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		Details:      details,
+	}
+
+	go func() {
+		auditCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.auditRepo.Create(auditCtx, log); err != nil {
+			s.logger.Warn("failed to create audit log", "action", action, "error", err)
+		}
+	}()
+}
+
+// SubscribeAlerts returns a channel of lifecycle events of the alerts in the
+// tenant scope, closed once ctx is done. It reports false when no event hub
+// is configured.
+func (s *AlertService) SubscribeAlerts(ctx context.Context, tenantID *uuid.UUID) (<-chan alerting.AlertEvent, bool) {
+	if s.hub == nil {
+		return nil, false
+	}
+	return s.hub.Subscribe(ctx, tenantID), true
+}
+
 // Silences
 
 // CreateSilence creates a new alert silence.
@@ -374,18 +659,22 @@ func (s *AlertService) DeleteSilence(ctx context.Context, id uuid.UUID) error {
 
 // Notification Channels
 
-// CreateChannel creates a new notification channel.
-func (s *AlertService) CreateChannel(ctx context.Context, req *models.CreateChannelRequest) (*alerting.NotificationChannel, error) {
+// CreateChannel creates a new notification channel owned by tenantID.
+func (s *AlertService) CreateChannel(ctx context.Context, tenantID *uuid.UUID, req *models.CreateChannelRequest) (*alerting.NotificationChannel, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
 	}
 
+	if err := s.validateChannelConfig(ctx, req.Type, req.Config); err != nil {
+		return nil, err
+	}
+
 	// Apply defaults
 	req.ApplyDefaults()
 
 	// Create channel
-	channel, err := s.repo.CreateChannel(ctx, req)
+	channel, err := s.repo.CreateChannel(ctx, tenantID, req)
 	if err != nil {
 		if errors.Is(err, repositories.ErrChannelNameExists) {
 			return nil, &ConflictError{Message: "notification channel with this name already exists"}
@@ -399,7 +688,7 @@ func (s *AlertService) CreateChannel(ctx context.Context, req *models.CreateChan
 }
 
 // GetChannel retrieves a notification channel by ID.
-func (s *AlertService) GetChannel(ctx context.Context, id uuid.UUID) (*alerting.NotificationChannel, error) {
+func (s *AlertService) GetChannel(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*alerting.NotificationChannel, error) {
 	channel, err := s.repo.GetChannel(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrChannelNotFound) {
@@ -407,12 +696,15 @@ func (s *AlertService) GetChannel(ctx context.Context, id uuid.UUID) (*alerting.
 		}
 		return nil, fmt.Errorf("failed to get notification channel: %w", err)
 	}
+	if !inTenantScope(tenantID, channel.TenantID) {
+		return nil, &NotFoundError{Resource: "notification channel", ID: id.String()}
+	}
 	return channel, nil
 }
 
 // ListChannels retrieves notification channels with pagination.
-func (s *AlertService) ListChannels(ctx context.Context, limit, offset int) (*models.ChannelListResponse, error) {
-	channelList, err := s.repo.ListChannels(ctx, false)
+func (s *AlertService) ListChannels(ctx context.Context, tenantID *uuid.UUID, limit, offset int) (*models.ChannelListResponse, error) {
+	channelList, err := s.repo.ListChannels(ctx, tenantID, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list notification channels: %w", err)
 	}
@@ -438,12 +730,23 @@ func (s *AlertService) ListChannels(ctx context.Context, limit, offset int) (*mo
 }
 
 // UpdateChannel updates a notification channel.
-func (s *AlertService) UpdateChannel(ctx context.Context, id uuid.UUID, req *models.UpdateChannelRequest) (*alerting.NotificationChannel, error) {
+func (s *AlertService) UpdateChannel(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.UpdateChannelRequest) (*alerting.NotificationChannel, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
 	}
 
+	existing, err := s.GetChannel(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Config != nil {
+		if err := s.validateChannelConfig(ctx, existing.Type, req.Config); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update channel
 	channel, err := s.repo.UpdateChannel(ctx, id, req)
 	if err != nil {
@@ -460,8 +763,43 @@ func (s *AlertService) UpdateChannel(ctx context.Context, id uuid.UUID, req *mod
 	return channel, nil
 }
 
+// validateChannelConfig checks settings the channel implementation
+// validates itself, such as webhook URLs and body templates, so a bad
+// configuration is rejected when saved rather than when an alert fires.
+// Secret references are resolved first, so a missing secret is reported too.
+func (s *AlertService) validateChannelConfig(ctx context.Context, channelType alerting.ChannelType, config map[string]any) error {
+	config, err := s.resolveConfig(ctx, config)
+	if err != nil {
+		return &ValidationError{Errors: []models.FieldError{{Field: "config", Message: err.Error()}}}
+	}
+
+	switch channelType {
+	case alerting.ChannelWebhook:
+		err = channels.ValidateWebhookConfig(config)
+	case alerting.ChannelPagerDuty:
+		err = channels.ValidatePagerDutyConfig(config)
+	}
+	var cfgErr *channels.ConfigError
+	if errors.As(err, &cfgErr) {
+		return &ValidationError{Errors: []models.FieldError{{Field: "config." + cfgErr.Field, Message: cfgErr.Message}}}
+	}
+	return err
+}
+
+// resolveConfig returns config with its secret references resolved.
+func (s *AlertService) resolveConfig(ctx context.Context, config map[string]any) (map[string]any, error) {
+	if s.configResolver == nil {
+		return config, nil
+	}
+	return s.configResolver(ctx, config)
+}
+
 // DeleteChannel deletes a notification channel.
-func (s *AlertService) DeleteChannel(ctx context.Context, id uuid.UUID) error {
+func (s *AlertService) DeleteChannel(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) error {
+	if _, err := s.GetChannel(ctx, id, tenantID); err != nil {
+		return err
+	}
+
 	err := s.repo.DeleteChannel(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrChannelNotFound) {
@@ -475,18 +813,24 @@ func (s *AlertService) DeleteChannel(ctx context.Context, id uuid.UUID) error {
 }
 
 // TestChannel tests a notification channel by sending a test notification.
-func (s *AlertService) TestChannel(ctx context.Context, id uuid.UUID) (*models.TestChannelResponse, error) {
+func (s *AlertService) TestChannel(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*models.TestChannelResponse, error) {
 	// Get channel
-	channel, err := s.repo.GetChannel(ctx, id)
+	channel, err := s.GetChannel(ctx, id, tenantID)
 	if err != nil {
-		if errors.Is(err, repositories.ErrChannelNotFound) {
-			return nil, &NotFoundError{Resource: "notification channel", ID: id.String()}
-		}
-		return nil, fmt.Errorf("failed to get notification channel: %w", err)
+		return nil, err
+	}
+
+	config, err := s.resolveConfig(ctx, channel.Config)
+	if err != nil {
+		return &models.TestChannelResponse{
+			Success:     false,
+			Message:     "Failed to resolve channel secrets",
+			ErrorDetail: err.Error(),
+		}, nil
 	}
 
 	// Create channel sender
-	sender, err := channels.NewChannel(channel.Type, channel.Config, s.logger)
+	sender, err := channels.NewChannel(channel.Type, config, s.logger)
 	if err != nil {
 		return &models.TestChannelResponse{
 			Success:     false,
@@ -515,8 +859,8 @@ func (s *AlertService) TestChannel(ctx context.Context, id uuid.UUID) (*models.T
 // Summary
 
 // GetSummary retrieves alert statistics summary.
-func (s *AlertService) GetSummary(ctx context.Context) (*models.AlertSummaryResponse, error) {
-	summary, err := s.repo.GetAlertSummary(ctx)
+func (s *AlertService) GetSummary(ctx context.Context, tenantID *uuid.UUID) (*models.AlertSummaryResponse, error) {
+	summary, err := s.repo.GetAlertSummary(ctx, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get alert summary: %w", err)
 	}
@@ -526,7 +870,7 @@ func (s *AlertService) GetSummary(ctx context.Context) (*models.AlertSummaryResp
 // Routes
 
 // CreateRoute creates a new alert route.
-func (s *AlertService) CreateRoute(ctx context.Context, req *models.CreateRouteRequest) (*alerting.AlertRoute, error) {
+func (s *AlertService) CreateRoute(ctx context.Context, tenantID *uuid.UUID, req *models.CreateRouteRequest) (*alerting.AlertRoute, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
@@ -535,22 +879,21 @@ func (s *AlertService) CreateRoute(ctx context.Context, req *models.CreateRouteR
 	// Apply defaults
 	req.ApplyDefaults()
 
-	// Verify rule exists
-	_, err := s.repo.GetRule(ctx, req.RuleID)
+	// Verify rule and channel exist
+	rule, err := s.GetRule(ctx, req.RuleID, tenantID)
 	if err != nil {
-		if errors.Is(err, repositories.ErrAlertRuleNotFound) {
-			return nil, &NotFoundError{Resource: "alert rule", ID: req.RuleID.String()}
-		}
-		return nil, fmt.Errorf("failed to verify alert rule: %w", err)
+		return nil, err
 	}
-
-	// Verify channel exists
-	_, err = s.repo.GetChannel(ctx, req.ChannelID)
+	channel, err := s.GetChannel(ctx, req.ChannelID, tenantID)
 	if err != nil {
-		if errors.Is(err, repositories.ErrChannelNotFound) {
-			return nil, &NotFoundError{Resource: "notification channel", ID: req.ChannelID.String()}
-		}
-		return nil, fmt.Errorf("failed to verify notification channel: %w", err)
+		return nil, err
+	}
+
+	// A tenant's alerts must not be sent to another tenant's channel
+	if channel.TenantID != nil && !inTenantScope(rule.TenantID, channel.TenantID) {
+		return nil, &ValidationError{Errors: []models.FieldError{
+			{Field: "channel_id", Message: "channel belongs to a different tenant than the rule"},
+		}}
 	}
 
 	// Create route
@@ -568,7 +911,7 @@ func (s *AlertService) CreateRoute(ctx context.Context, req *models.CreateRouteR
 }
 
 // GetRoute retrieves an alert route by ID.
-func (s *AlertService) GetRoute(ctx context.Context, id uuid.UUID) (*alerting.AlertRoute, error) {
+func (s *AlertService) GetRoute(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*alerting.AlertRoute, error) {
 	route, err := s.repo.GetRoute(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrRouteNotFound) {
@@ -576,16 +919,50 @@ func (s *AlertService) GetRoute(ctx context.Context, id uuid.UUID) (*alerting.Al
 		}
 		return nil, fmt.Errorf("failed to get alert route: %w", err)
 	}
+	if tenantID != nil {
+		if _, err := s.GetRule(ctx, route.RuleID, tenantID); err != nil {
+			var notFound *NotFoundError
+			if errors.As(err, &notFound) {
+				return nil, &NotFoundError{Resource: "alert route", ID: id.String()}
+			}
+			return nil, err
+		}
+	}
 	return route, nil
 }
 
 // ListRoutes retrieves alert routes with optional filtering.
-func (s *AlertService) ListRoutes(ctx context.Context, ruleID *uuid.UUID, limit, offset int) (*models.RouteListResponse, error) {
+func (s *AlertService) ListRoutes(ctx context.Context, tenantID *uuid.UUID, ruleID *uuid.UUID, limit, offset int) (*models.RouteListResponse, error) {
+	if ruleID != nil {
+		if _, err := s.GetRule(ctx, *ruleID, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
 	routes, err := s.repo.ListRoutes(ctx, ruleID, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list alert routes: %w", err)
 	}
 
+	// Keep only routes of the tenant's rules
+	if tenantID != nil && ruleID == nil {
+		rules, err := s.repo.ListRules(ctx, tenantID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alert rules: %w", err)
+		}
+		visible := make(map[uuid.UUID]bool, len(rules))
+		for _, rule := range rules {
+			visible[rule.ID] = true
+		}
+		scoped := routes[:0]
+		for _, route := range routes {
+			if visible[route.RuleID] {
+				scoped = append(scoped, route)
+			}
+		}
+		routes = scoped
+	}
+
 	if routes == nil {
 		routes = []alerting.AlertRoute{}
 	}
@@ -607,12 +984,16 @@ func (s *AlertService) ListRoutes(ctx context.Context, ruleID *uuid.UUID, limit,
 }
 
 // UpdateRoute updates an alert route.
-func (s *AlertService) UpdateRoute(ctx context.Context, id uuid.UUID, req *models.UpdateRouteRequest) (*alerting.AlertRoute, error) {
+func (s *AlertService) UpdateRoute(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.UpdateRouteRequest) (*alerting.AlertRoute, error) {
 	// Validate request
 	if errs := req.Validate(); len(errs) > 0 {
 		return nil, &ValidationError{Errors: errs}
 	}
 
+	if _, err := s.GetRoute(ctx, id, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Update route
 	route, err := s.repo.UpdateRoute(ctx, id, req)
 	if err != nil {
@@ -627,7 +1008,11 @@ func (s *AlertService) UpdateRoute(ctx context.Context, id uuid.UUID, req *model
 }
 
 // DeleteRoute deletes an alert route.
-func (s *AlertService) DeleteRoute(ctx context.Context, id uuid.UUID) error {
+func (s *AlertService) DeleteRoute(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) error {
+	if _, err := s.GetRoute(ctx, id, tenantID); err != nil {
+		return err
+	}
+
 	err := s.repo.DeleteRoute(ctx, id)
 	if err != nil {
 		if errors.Is(err, repositories.ErrRouteNotFound) {
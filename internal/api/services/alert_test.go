@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+)
+
+func TestCommonLabels(t *testing.T) {
+	instances := []*alerting.AlertInstance{
+		{Labels: map[string]string{"source": "orders", "severity": "critical", "pod": "a"}},
+		{Labels: map[string]string{"source": "orders", "severity": "critical", "pod": "b"}},
+		{Labels: map[string]string{"source": "orders", "severity": "critical"}},
+	}
+
+	common := commonLabels(instances)
+	if len(common) != 2 || common["source"] != "orders" || common["severity"] != "critical" {
+		t.Errorf("commonLabels() = %v, want source=orders and severity=critical", common)
+	}
+	if common := commonLabels(instances[:1]); len(common) != 3 {
+		t.Errorf("commonLabels(one) = %v, want all of its labels", common)
+	}
+}
+
+func TestBulkResults(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ids := uniqueIDs([]uuid.UUID{a, b, a})
+	if len(ids) != 2 || ids[0] != a || ids[1] != b {
+		t.Fatalf("uniqueIDs() = %v, want [%s %s]", ids, a, b)
+	}
+
+	results := bulkResults(ids, map[uuid.UUID]string{b: "alert not found"})
+	if len(results) != 2 || !results[0].Success || results[1].Success || results[1].Error != "alert not found" {
+		t.Errorf("bulkResults() = %+v, want %s to succeed and %s to fail", results, a, b)
+	}
+}
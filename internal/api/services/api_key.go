@@ -165,6 +165,58 @@ func (s *APIKeyService) Validate(ctx context.Context, plaintextKey string) (*mod
 	return user, apiKey, nil
 }
 
+// RateLimit resolves an API key to its ID and rate limit for the rate
+// limiter. Unlike Validate it does not load the user or record the use.
+// The limit is nil when the key uses the global limit.
+func (s *APIKeyService) RateLimit(ctx context.Context, plaintextKey string) (uuid.UUID, *models.APIKeyRateLimit, error) {
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, s.hashKey(plaintextKey))
+	if err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return uuid.Nil, nil, repositories.ErrAPIKeyNotFound
+		}
+		return uuid.Nil, nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if !apiKey.IsActive {
+		return uuid.Nil, nil, ErrAPIKeyInactive
+	}
+	if s.apiKeyRepo.IsExpired(apiKey) {
+		return uuid.Nil, nil, ErrAPIKeyExpired
+	}
+
+	return apiKey.ID, apiKey.RateLimit, nil
+}
+
+// SetRateLimit sets or removes the rate limit of an API key.
+func (s *APIKeyService) SetRateLimit(ctx context.Context, id uuid.UUID, req *models.UpdateAPIKeyRateLimitRequest, userID uuid.UUID, ipAddress, userAgent string) (*models.APIKey, error) {
+	if fieldErrors := req.Validate(); len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	if err := s.apiKeyRepo.SetRateLimit(ctx, id, req.RateLimit); err != nil {
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return nil, &NotFoundError{Resource: "api_key", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to set api key rate limit: %w", err)
+	}
+
+	apiKey, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"key_name": apiKey.Name}
+	if req.RateLimit != nil {
+		details["requests_per_second"] = req.RateLimit.RequestsPerSecond
+		details["burst_size"] = req.RateLimit.BurstSize
+	}
+	s.logAuditEvent(ctx, &userID, &id, models.AuditActionAPIKeyRateLimitUpdated, ipAddress, userAgent, details)
+
+	s.logger.Info("api key rate limit updated", "api_key_id", id, "user_id", userID)
+
+	return apiKey, nil
+}
+
 // List lists all API keys for a user.
 func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
 	keys, err := s.apiKeyRepo.ListByUserID(ctx, userID)
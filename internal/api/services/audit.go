@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// redactedValue replaces secret values in audited request bodies.
+const redactedValue = "[REDACTED]"
+
+// secretFieldNames are substrings of the names of request body fields whose
+// values are not stored in the audit log.
+var secretFieldNames = []string{
+	"password", "secret", "token", "credential", "authorization",
+	"private", "passphrase", "dsn", "connection_string",
+}
+
+// AuditLogStore is the persistence used by AuditService.
+type AuditLogStore interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	List(ctx context.Context, opts repositories.AuditListOptions) ([]models.AuditLog, int, error)
+}
+
+var _ AuditLogStore = (*repositories.AuditRepository)(nil)
+
+// AuditService records audited API requests and lists the audit log.
+type AuditService struct {
+	store  AuditLogStore
+	logger *slog.Logger
+}
+
+// NewAuditService creates a new AuditService.
+func NewAuditService(store AuditLogStore, logger *slog.Logger) *AuditService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &AuditService{
+		store:  store,
+		logger: logger.With("component", "audit-service"),
+	}
+}
+
+// Record writes an audit log entry asynchronously, so that a slow metadata
+// database does not hold up responses.
+func (s *AuditService) Record(log *models.AuditLog) {
+	go func() {
+		auditCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.store.Create(auditCtx, log); err != nil {
+			s.logger.Warn("failed to create audit log", "action", log.Action, "error", err)
+		}
+	}()
+}
+
+// List retrieves audit logs matching filter, newest first, with pagination.
+func (s *AuditService) List(ctx context.Context, filter models.AuditLogFilter, limit, offset int) (*models.AuditLogListResponse, error) {
+	if filter.Since != nil && filter.Until != nil && filter.Until.Before(*filter.Since) {
+		return nil, &ValidationError{Errors: []models.FieldError{
+			{Field: "until", Message: "until must not be before since"},
+		}}
+	}
+
+	logs, total, err := s.store.List(ctx, repositories.AuditListOptions{
+		UserID:       filter.UserID,
+		TenantID:     filter.TenantID,
+		Action:       filter.Action,
+		ResourceType: filter.ResourceType,
+		ResourceID:   filter.ResourceID,
+		StartTime:    filter.Since,
+		EndTime:      filter.Until,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	if logs == nil {
+		logs = []models.AuditLog{}
+	}
+
+	return &models.AuditLogListResponse{
+		Logs:       logs,
+		TotalCount: total,
+	}, nil
+}
+
+// RedactRequestBody decodes a JSON request body for the audit log, with the
+// values of fields that hold secrets, such as passwords, tokens and keys,
+// replaced at any depth. It reports false for bodies that are not JSON.
+func RedactRequestBody(body []byte) (any, bool) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, false
+	}
+	return redactSecrets(v), true
+}
+
+// redactSecrets replaces the values of secret fields in a decoded JSON value.
+func redactSecrets(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if isSecretField(name) {
+				v[name] = redactedValue
+			} else {
+				v[name] = redactSecrets(value)
+			}
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = redactSecrets(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isSecretField reports whether a field name denotes a secret. Names ending
+// in "key", such as api_key or accessKey, are secrets too.
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, "key") {
+		return true
+	}
+	for _, secret := range secretFieldNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
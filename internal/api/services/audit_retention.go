@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// AuditRetentionStore is the persistence used by AuditRetentionService.
+type AuditRetentionStore interface {
+	GetPolicy(ctx context.Context, tenantID uuid.UUID) (*models.AuditRetentionPolicy, error)
+	ListPolicies(ctx context.Context) (map[uuid.UUID]int, error)
+	UpsertPolicy(ctx context.Context, tenantID uuid.UUID, days int, updatedBy *uuid.UUID) (*models.AuditRetentionPolicy, error)
+	ListTenantIDs(ctx context.Context) ([]uuid.UUID, error)
+	CreateHold(ctx context.Context, hold *models.AuditLegalHold) error
+	GetHold(ctx context.Context, id uuid.UUID) (*models.AuditLegalHold, error)
+	ListHolds(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.AuditLegalHold, error)
+	ReleaseHold(ctx context.Context, id uuid.UUID, releasedBy *uuid.UUID) (*models.AuditLegalHold, error)
+	DeleteLogs(ctx context.Context, tenantID *uuid.UUID, after *time.Time, before time.Time) (int64, error)
+}
+
+// AuditLogWriter records audit log entries.
+type AuditLogWriter interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+}
+
+// AuditRetentionService manages per-tenant audit log retention and legal
+// holds, and periodically deletes expired audit logs.
+type AuditRetentionService struct {
+	store     AuditRetentionStore
+	auditRepo AuditLogWriter
+	cfg       *config.AuthConfig
+	logger    *slog.Logger
+	now       func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAuditRetentionService creates a new AuditRetentionService.
+func NewAuditRetentionService(
+	store AuditRetentionStore,
+	auditRepo AuditLogWriter,
+	cfg *config.AuthConfig,
+	logger *slog.Logger,
+) *AuditRetentionService {
+	return &AuditRetentionService{
+		store:     store,
+		auditRepo: auditRepo,
+		cfg:       cfg,
+		logger:    logger.With("component", "audit-retention-service"),
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// --- Retention Policies ---
+
+// GetPolicy returns a tenant's effective retention policy.
+func (s *AuditRetentionService) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	policy, err := s.store.GetPolicy(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRetentionPolicyNotFound) {
+			return &models.AuditRetentionPolicy{
+				TenantID:      tenantID,
+				RetentionDays: s.cfg.AuditRetentionDays,
+				IsDefault:     true,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get audit retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SetPolicy sets a tenant's audit retention override.
+func (s *AuditRetentionService) SetPolicy(ctx context.Context, tenantID uuid.UUID, req *models.UpdateAuditRetentionRequest, userID uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	if fieldErrors := req.Validate(); len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	policy, err := s.store.UpsertPolicy(ctx, tenantID, req.RetentionDays, &userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set audit retention policy: %w", err)
+	}
+
+	s.logAuditEvent(ctx, userID, tenantID, models.AuditActionRetentionUpdated, nil, map[string]interface{}{
+		"retention_days": req.RetentionDays,
+	})
+	s.logger.Info("audit retention updated", "tenant_id", tenantID, "retention_days", req.RetentionDays, "user_id", userID)
+
+	return policy, nil
+}
+
+// --- Legal Holds ---
+
+// PlaceHold places a legal hold on a tenant's audit logs.
+func (s *AuditRetentionService) PlaceHold(ctx context.Context, tenantID uuid.UUID, req *models.CreateLegalHoldRequest, userID uuid.UUID) (*models.AuditLegalHold, error) {
+	if fieldErrors := req.Validate(); len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	hold := &models.AuditLegalHold{
+		TenantID:  tenantID,
+		Reason:    req.Reason,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: &userID,
+	}
+	if err := s.store.CreateHold(ctx, hold); err != nil {
+		return nil, fmt.Errorf("failed to place legal hold: %w", err)
+	}
+
+	details := map[string]interface{}{"reason": hold.Reason}
+	if hold.StartsAt != nil {
+		details["starts_at"] = hold.StartsAt.Format(time.RFC3339)
+	}
+	if hold.EndsAt != nil {
+		details["ends_at"] = hold.EndsAt.Format(time.RFC3339)
+	}
+	s.logAuditEvent(ctx, userID, tenantID, models.AuditActionLegalHoldPlaced, &hold.ID, details)
+	s.logger.Info("legal hold placed", "tenant_id", tenantID, "hold_id", hold.ID, "user_id", userID)
+
+	return hold, nil
+}
+
+// ListHolds lists a tenant's legal holds.
+func (s *AuditRetentionService) ListHolds(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.AuditLegalHold, error) {
+	holds, err := s.store.ListHolds(ctx, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	if holds == nil {
+		holds = []models.AuditLegalHold{}
+	}
+	return holds, nil
+}
+
+// ReleaseHold releases a tenant's legal hold.
+func (s *AuditRetentionService) ReleaseHold(ctx context.Context, tenantID, holdID, userID uuid.UUID) (*models.AuditLegalHold, error) {
+	existing, err := s.store.GetHold(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrLegalHoldNotFound) {
+			return nil, &NotFoundError{Resource: "legal hold", ID: holdID.String()}
+		}
+		return nil, fmt.Errorf("failed to get legal hold: %w", err)
+	}
+	if existing.TenantID != tenantID {
+		return nil, &NotFoundError{Resource: "legal hold", ID: holdID.String()}
+	}
+
+	hold, err := s.store.ReleaseHold(ctx, holdID, &userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrLegalHoldReleased) {
+			return nil, &ConflictError{Message: "legal hold " + holdID.String() + " is already released"}
+		}
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	s.logAuditEvent(ctx, userID, tenantID, models.AuditActionLegalHoldReleased, &hold.ID, map[string]interface{}{
+		"reason": hold.Reason,
+	})
+	s.logger.Info("legal hold released", "tenant_id", tenantID, "hold_id", hold.ID, "user_id", userID)
+
+	return hold, nil
+}
+
+// --- Cleanup ---
+
+// Start starts the periodic cleanup job. It is a no-op when the cleanup
+// interval is not positive.
+func (s *AuditRetentionService) Start(ctx context.Context) {
+	if s.cfg.AuditCleanupInterval <= 0 {
+		s.logger.Info("audit log cleanup disabled")
+		return
+	}
+
+	s.logger.Info("starting audit log cleanup",
+		"interval", s.cfg.AuditCleanupInterval,
+		"default_retention_days", s.cfg.AuditRetentionDays,
+	)
+
+	s.wg.Add(1)
+	go s.runLoop(ctx)
+}
+
+// Stop stops the periodic cleanup job.
+func (s *AuditRetentionService) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *AuditRetentionService) runLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.AuditCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Cleanup(ctx); err != nil {
+			s.logger.Error("audit log cleanup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup deletes audit logs past their tenant's retention, skipping logs
+// covered by an active legal hold. Logs without a tenant are kept while any
+// tenant holds the period they fall in. It returns the number of deleted logs.
+func (s *AuditRetentionService) Cleanup(ctx context.Context) (int64, error) {
+	now := s.now()
+
+	policies, err := s.store.ListPolicies(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	tenantIDs, err := s.store.ListTenantIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	var total int64
+	var allHolds []models.AuditLegalHold
+	for _, tenantID := range tenantIDs {
+		holds, err := s.store.ListHolds(ctx, tenantID, true)
+		if err != nil {
+			return total, fmt.Errorf("failed to list legal holds for tenant %s: %w", tenantID, err)
+		}
+		allHolds = append(allHolds, holds...)
+
+		days, ok := policies[tenantID]
+		if !ok {
+			days = s.cfg.AuditRetentionDays
+		}
+		if days <= 0 {
+			continue
+		}
+
+		id := tenantID
+		for _, w := range deletionWindows(retentionCutoff(now, days), holds) {
+			n, err := s.store.DeleteLogs(ctx, &id, w.after, w.before)
+			if err != nil {
+				return total, err
+			}
+			total += n
+		}
+	}
+
+	// Logs without a tenant follow the default retention. They cannot be
+	// attributed to a tenant, so any tenant's active hold protects them.
+	if s.cfg.AuditRetentionDays > 0 {
+		for _, w := range deletionWindows(retentionCutoff(now, s.cfg.AuditRetentionDays), allHolds) {
+			n, err := s.store.DeleteLogs(ctx, nil, w.after, w.before)
+			if err != nil {
+				return total, err
+			}
+			total += n
+		}
+	}
+
+	if total > 0 {
+		s.logger.Info("deleted expired audit logs", "count", total)
+	}
+
+	return total, nil
+}
+
+func retentionCutoff(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
+
+// deletionWindow selects logs created strictly between after and before.
+// A nil after is unbounded.
+type deletionWindow struct {
+	after  *time.Time
+	before time.Time
+}
+
+// deletionWindows splits the range before cutoff into the windows not
+// covered by any active hold. Hold ranges are inclusive, so windows exclude
+// their boundaries.
+func deletionWindows(cutoff time.Time, holds []models.AuditLegalHold) []deletionWindow {
+	active := make([]models.AuditLegalHold, 0, len(holds))
+	for _, h := range holds {
+		if h.IsActive() {
+			active = append(active, h)
+		}
+	}
+
+	// Unbounded starts sort first.
+	sort.Slice(active, func(i, j int) bool {
+		a, b := active[i].StartsAt, active[j].StartsAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	var windows []deletionWindow
+	var after *time.Time
+	for _, h := range active {
+		if h.StartsAt != nil && (after == nil || h.StartsAt.After(*after)) {
+			before := *h.StartsAt
+			if cutoff.Before(before) {
+				before = cutoff
+			}
+			windows = appendWindow(windows, after, before)
+		}
+		if h.EndsAt == nil {
+			return windows
+		}
+		if after == nil || h.EndsAt.After(*after) {
+			end := *h.EndsAt
+			after = &end
+		}
+		if !after.Before(cutoff) {
+			return windows
+		}
+	}
+
+	return appendWindow(windows, after, cutoff)
+}
+
+func appendWindow(windows []deletionWindow, after *time.Time, before time.Time) []deletionWindow {
+	if after != nil && !after.Before(before) {
+		return windows
+	}
+	return append(windows, deletionWindow{after: after, before: before})
+}
+
+// logAuditEvent logs a tenant-scoped audit event asynchronously.
+func (s *AuditRetentionService) logAuditEvent(_ context.Context, userID, tenantID uuid.UUID, action string, resourceID *uuid.UUID, details map[string]interface{}) {
+	log := &models.AuditLog{
+		UserID:       &userID,
+		TenantID:     &tenantID,
+		Action:       action,
+		ResourceType: "audit_retention",
+		ResourceID:   resourceID,
+		Details:      details,
+	}
+	if resourceID != nil {
+		log.ResourceType = "audit_legal_hold"
+	}
+
+	go func() {
+		auditCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.auditRepo.Create(auditCtx, log); err != nil {
+			s.logger.Warn("failed to create audit log", "action", action, "error", err)
+		}
+	}()
+}
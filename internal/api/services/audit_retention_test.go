@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// fakeAuditStore keeps audit logs, policies and holds in memory.
+type fakeAuditStore struct {
+	tenants  []uuid.UUID
+	policies map[uuid.UUID]int
+	holds    []models.AuditLegalHold
+	logs     []models.AuditLog
+}
+
+func newFakeAuditStore(tenants ...uuid.UUID) *fakeAuditStore {
+	return &fakeAuditStore{tenants: tenants, policies: map[uuid.UUID]int{}}
+}
+
+func (f *fakeAuditStore) addLog(tenantID *uuid.UUID, createdAt time.Time) uuid.UUID {
+	id := uuid.New()
+	f.logs = append(f.logs, models.AuditLog{ID: id, TenantID: tenantID, CreatedAt: createdAt})
+	return id
+}
+
+func (f *fakeAuditStore) hasLog(id uuid.UUID) bool {
+	for _, l := range f.logs {
+		if l.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeAuditStore) GetPolicy(_ context.Context, tenantID uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	days, ok := f.policies[tenantID]
+	if !ok {
+		return nil, repositories.ErrRetentionPolicyNotFound
+	}
+	return &models.AuditRetentionPolicy{TenantID: tenantID, RetentionDays: days}, nil
+}
+
+func (f *fakeAuditStore) ListPolicies(_ context.Context) (map[uuid.UUID]int, error) {
+	return f.policies, nil
+}
+
+func (f *fakeAuditStore) UpsertPolicy(_ context.Context, tenantID uuid.UUID, days int, updatedBy *uuid.UUID) (*models.AuditRetentionPolicy, error) {
+	f.policies[tenantID] = days
+	return &models.AuditRetentionPolicy{TenantID: tenantID, RetentionDays: days, UpdatedBy: updatedBy}, nil
+}
+
+func (f *fakeAuditStore) ListTenantIDs(_ context.Context) ([]uuid.UUID, error) {
+	return f.tenants, nil
+}
+
+func (f *fakeAuditStore) CreateHold(_ context.Context, hold *models.AuditLegalHold) error {
+	hold.ID = uuid.New()
+	hold.CreatedAt = time.Now()
+	f.holds = append(f.holds, *hold)
+	return nil
+}
+
+func (f *fakeAuditStore) GetHold(_ context.Context, id uuid.UUID) (*models.AuditLegalHold, error) {
+	for i := range f.holds {
+		if f.holds[i].ID == id {
+			h := f.holds[i]
+			return &h, nil
+		}
+	}
+	return nil, repositories.ErrLegalHoldNotFound
+}
+
+func (f *fakeAuditStore) ListHolds(_ context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.AuditLegalHold, error) {
+	var holds []models.AuditLegalHold
+	for _, h := range f.holds {
+		if h.TenantID == tenantID && (!activeOnly || h.IsActive()) {
+			holds = append(holds, h)
+		}
+	}
+	return holds, nil
+}
+
+func (f *fakeAuditStore) ReleaseHold(_ context.Context, id uuid.UUID, releasedBy *uuid.UUID) (*models.AuditLegalHold, error) {
+	for i := range f.holds {
+		if f.holds[i].ID != id {
+			continue
+		}
+		if !f.holds[i].IsActive() {
+			return nil, repositories.ErrLegalHoldReleased
+		}
+		now := time.Now()
+		f.holds[i].ReleasedAt = &now
+		f.holds[i].ReleasedBy = releasedBy
+		h := f.holds[i]
+		return &h, nil
+	}
+	return nil, repositories.ErrLegalHoldNotFound
+}
+
+func (f *fakeAuditStore) DeleteLogs(_ context.Context, tenantID *uuid.UUID, after *time.Time, before time.Time) (int64, error) {
+	var kept []models.AuditLog
+	var deleted int64
+	for _, l := range f.logs {
+		sameTenant := (tenantID == nil && l.TenantID == nil) ||
+			(tenantID != nil && l.TenantID != nil && *tenantID == *l.TenantID)
+		if sameTenant && l.CreatedAt.Before(before) && (after == nil || l.CreatedAt.After(*after)) {
+			deleted++
+			continue
+		}
+		kept = append(kept, l)
+	}
+	f.logs = kept
+	return deleted, nil
+}
+
+// fakeAuditWriter records audit events written by the service.
+type fakeAuditWriter struct {
+	mu   sync.Mutex
+	logs []*models.AuditLog
+	done chan struct{}
+}
+
+func (w *fakeAuditWriter) Create(_ context.Context, log *models.AuditLog) error {
+	w.mu.Lock()
+	w.logs = append(w.logs, log)
+	w.mu.Unlock()
+	w.done <- struct{}{}
+	return nil
+}
+
+func newRetentionService(store *fakeAuditStore, now time.Time) (*AuditRetentionService, *fakeAuditWriter) {
+	writer := &fakeAuditWriter{done: make(chan struct{}, 16)}
+	svc := NewAuditRetentionService(store, writer, &config.AuthConfig{
+		AuditRetentionDays:   90,
+		AuditCleanupInterval: time.Hour,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.now = func() time.Time { return now }
+	return svc, writer
+}
+
+func TestAuditRetentionService_Cleanup(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+
+	held, ranged, short, plain := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store := newFakeAuditStore(held, ranged, short, plain)
+	store.policies[short] = 30
+
+	start, end := daysAgo(200), daysAgo(150)
+	store.holds = []models.AuditLegalHold{
+		{ID: uuid.New(), TenantID: held, Reason: "litigation"},
+		{ID: uuid.New(), TenantID: ranged, Reason: "investigation", StartsAt: &start, EndsAt: &end},
+	}
+
+	keep := map[string]uuid.UUID{
+		"fully held tenant":        store.addLog(&held, daysAgo(400)),
+		"inside held range":        store.addLog(&ranged, daysAgo(180)),
+		"held range boundary":      store.addLog(&ranged, end),
+		"ranged tenant recent":     store.addLog(&ranged, daysAgo(10)),
+		"short retention recent":   store.addLog(&short, daysAgo(20)),
+		"default retention recent": store.addLog(&plain, daysAgo(60)),
+		"no tenant recent":         store.addLog(nil, daysAgo(60)),
+		"no tenant, held tenant":   store.addLog(nil, daysAgo(120)),
+	}
+	remove := map[string]uuid.UUID{
+		"before held range":     store.addLog(&ranged, daysAgo(300)),
+		"after held range":      store.addLog(&ranged, daysAgo(100)),
+		"short retention old":   store.addLog(&short, daysAgo(40)),
+		"default retention old": store.addLog(&plain, daysAgo(120)),
+	}
+
+	svc, _ := newRetentionService(store, now)
+	deleted, err := svc.Cleanup(context.Background())
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if deleted != int64(len(remove)) {
+		t.Errorf("Cleanup() deleted %d, want %d", deleted, len(remove))
+	}
+
+	for name, id := range keep {
+		if !store.hasLog(id) {
+			t.Errorf("%s: log was deleted, want kept", name)
+		}
+	}
+	for name, id := range remove {
+		if store.hasLog(id) {
+			t.Errorf("%s: log was kept, want deleted", name)
+		}
+	}
+}
+
+func TestAuditRetentionService_CleanupHoldsTenantlessLogs(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+
+	held, plain := uuid.New(), uuid.New()
+	store := newFakeAuditStore(held, plain)
+	start, end := daysAgo(200), daysAgo(150)
+	store.holds = []models.AuditLegalHold{
+		{ID: uuid.New(), TenantID: held, Reason: "investigation", StartsAt: &start, EndsAt: &end},
+	}
+
+	inHold := store.addLog(nil, daysAgo(180))
+	outsideHold := store.addLog(nil, daysAgo(120))
+
+	svc, _ := newRetentionService(store, now)
+	if _, err := svc.Cleanup(context.Background()); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if !store.hasLog(inHold) {
+		t.Error("log without a tenant inside another tenant's hold was deleted")
+	}
+	if store.hasLog(outsideHold) {
+		t.Error("log without a tenant outside every hold was kept")
+	}
+}
+
+func TestAuditRetentionService_ReleasedHoldNoLongerProtects(t *testing.T) {
+	now := time.Now()
+	tenant, user := uuid.New(), uuid.New()
+	store := newFakeAuditStore(tenant)
+	old := store.addLog(&tenant, now.AddDate(0, 0, -365))
+
+	svc, writer := newRetentionService(store, now)
+	ctx := context.Background()
+
+	hold, err := svc.PlaceHold(ctx, tenant, &models.CreateLegalHoldRequest{Reason: "audit"}, user)
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	<-writer.done
+
+	if _, err := svc.Cleanup(ctx); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if !store.hasLog(old) {
+		t.Fatal("held log was deleted")
+	}
+
+	released, err := svc.ReleaseHold(ctx, tenant, hold.ID, user)
+	if err != nil {
+		t.Fatalf("ReleaseHold() error = %v", err)
+	}
+	<-writer.done
+	if released.ReleasedBy == nil || *released.ReleasedBy != user {
+		t.Errorf("ReleasedBy = %v, want %v", released.ReleasedBy, user)
+	}
+
+	if _, err := svc.Cleanup(ctx); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if store.hasLog(old) {
+		t.Error("log past retention survived after hold release")
+	}
+
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	if len(writer.logs) != 2 {
+		t.Fatalf("recorded %d audit events, want 2", len(writer.logs))
+	}
+	for i, action := range []string{models.AuditActionLegalHoldPlaced, models.AuditActionLegalHoldReleased} {
+		got := writer.logs[i]
+		if got.Action != action || got.UserID == nil || *got.UserID != user || got.TenantID == nil || *got.TenantID != tenant {
+			t.Errorf("audit event %d = %+v, want %s by %s", i, got, action, user)
+		}
+	}
+}
+
+func TestAuditRetentionService_ReleaseHold_Errors(t *testing.T) {
+	tenant, other, user := uuid.New(), uuid.New(), uuid.New()
+	store := newFakeAuditStore(tenant, other)
+	svc, writer := newRetentionService(store, time.Now())
+	ctx := context.Background()
+
+	hold, err := svc.PlaceHold(ctx, tenant, &models.CreateLegalHoldRequest{Reason: "audit"}, user)
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	<-writer.done
+
+	var notFound *NotFoundError
+	if _, err := svc.ReleaseHold(ctx, other, hold.ID, user); !errors.As(err, &notFound) {
+		t.Errorf("ReleaseHold() from another tenant error = %v, want NotFoundError", err)
+	}
+
+	if _, err := svc.ReleaseHold(ctx, tenant, hold.ID, user); err != nil {
+		t.Fatalf("ReleaseHold() error = %v", err)
+	}
+	<-writer.done
+	var conflict *ConflictError
+	if _, err := svc.ReleaseHold(ctx, tenant, hold.ID, user); !errors.As(err, &conflict) {
+		t.Errorf("second ReleaseHold() error = %v, want ConflictError", err)
+	}
+}
+
+func TestAuditRetentionService_Validation(t *testing.T) {
+	svc, _ := newRetentionService(newFakeAuditStore(), time.Now())
+	ctx := context.Background()
+	tenant, user := uuid.New(), uuid.New()
+
+	var validationErr *ValidationError
+	if _, err := svc.SetPolicy(ctx, tenant, &models.UpdateAuditRetentionRequest{RetentionDays: 0}, user); !errors.As(err, &validationErr) {
+		t.Errorf("SetPolicy(0) error = %v, want ValidationError", err)
+	}
+
+	start := time.Now()
+	end := start.Add(-time.Hour)
+	req := &models.CreateLegalHoldRequest{Reason: "audit", StartsAt: &start, EndsAt: &end}
+	if _, err := svc.PlaceHold(ctx, tenant, req, user); !errors.As(err, &validationErr) {
+		t.Errorf("PlaceHold() with inverted range error = %v, want ValidationError", err)
+	}
+
+	policy, err := svc.GetPolicy(ctx, tenant)
+	if err != nil {
+		t.Fatalf("GetPolicy() error = %v", err)
+	}
+	if !policy.IsDefault || policy.RetentionDays != 90 {
+		t.Errorf("GetPolicy() = %+v, want default 90 days", policy)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+func TestRedactRequestBody(t *testing.T) {
+	body := []byte(`{
+		"name": "orders",
+		"password": "hunter2",
+		"config": {"webhook_url": "https://example.com", "bot_token": "xoxb", "routing_key": "r1"},
+		"credentials": {"access_key_id": "AKIA"},
+		"channels": [{"type": "email", "smtpPassword": "p"}]
+	}`)
+
+	redacted, ok := RedactRequestBody(body)
+	if !ok {
+		t.Fatal("RedactRequestBody() did not decode a JSON body")
+	}
+	got, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"channels":[{"smtpPassword":"[REDACTED]","type":"email"}],` +
+		`"config":{"bot_token":"[REDACTED]","routing_key":"[REDACTED]","webhook_url":"https://example.com"},` +
+		`"credentials":"[REDACTED]","name":"orders","password":"[REDACTED]"}`
+	if string(got) != want {
+		t.Errorf("RedactRequestBody() = %s, want %s", got, want)
+	}
+
+	if _, ok := RedactRequestBody([]byte("password=hunter2")); ok {
+		t.Error("RedactRequestBody() decoded a form body")
+	}
+}
+
+type fakeAuditLogStore struct {
+	opts repositories.AuditListOptions
+}
+
+func (s *fakeAuditLogStore) Create(context.Context, *models.AuditLog) error { return nil }
+
+func (s *fakeAuditLogStore) List(_ context.Context, opts repositories.AuditListOptions) ([]models.AuditLog, int, error) {
+	s.opts = opts
+	return nil, 0, nil
+}
+
+func TestAuditServiceList(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	service := NewAuditService(store, nil)
+	since := time.Now().Add(-time.Hour)
+	until := time.Now()
+
+	response, err := service.List(context.Background(), models.AuditLogFilter{Action: "login", Since: &since, Until: &until}, 10, 20)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if response.Logs == nil || response.TotalCount != 0 {
+		t.Errorf("List() = %+v, want an empty page", response)
+	}
+	if store.opts.Action != "login" || store.opts.StartTime != &since || store.opts.Limit != 10 || store.opts.Offset != 20 {
+		t.Errorf("List() options = %+v, want the filter and page", store.opts)
+	}
+
+	var validationErr *ValidationError
+	_, err = service.List(context.Background(), models.AuditLogFilter{Since: &until, Until: &since}, 10, 0)
+	if !errors.As(err, &validationErr) {
+		t.Errorf("List() error = %v, want a validation error for until before since", err)
+	}
+}
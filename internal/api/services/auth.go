@@ -23,14 +23,16 @@ var (
 	ErrUserInactive       = errors.New("user account is inactive")
 	ErrInvalidToken       = errors.New("invalid or expired token")
 	ErrAdminAlreadyExists = errors.New("admin user already exists")
+	ErrLogoutUnavailable  = errors.New("token revocation is not configured")
 )
 
 // AuthService provides authentication business logic.
 type AuthService struct {
-	userRepo  *repositories.UserRepository
-	auditRepo *repositories.AuditRepository
-	cfg       *config.AuthConfig
-	logger    *slog.Logger
+	userRepo   *repositories.UserRepository
+	auditRepo  *repositories.AuditRepository
+	revocation *TokenRevocationService
+	cfg        *config.AuthConfig
+	logger     *slog.Logger
 }
 
 // NewAuthService creates a new AuthService.
@@ -48,6 +50,12 @@ func NewAuthService(
 	}
 }
 
+// SetTokenRevocationService sets the service tokens are revoked through on
+// logout. Without one logging out is not supported.
+func (s *AuthService) SetTokenRevocationService(revocation *TokenRevocationService) {
+	s.revocation = revocation
+}
+
 // Login authenticates a user and returns a JWT token.
 func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
 	// Validate request
@@ -130,6 +138,23 @@ func (s *AuthService) ValidateJWT(tokenString string) (*models.JWTClaims, error)
 	return claims, nil
 }
 
+// Logout revokes the token a user authenticated with.
+func (s *AuthService) Logout(ctx context.Context, claims *models.JWTClaims, ipAddress, userAgent string) error {
+	if s.revocation == nil {
+		return ErrLogoutUnavailable
+	}
+
+	if err := s.revocation.RevokeToken(ctx, claims); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	s.logAuditEvent(ctx, &claims.UserID, nil, models.AuditActionLogout, ipAddress, userAgent, nil)
+
+	s.logger.Info("user logged out", "user_id", claims.UserID)
+
+	return nil
+}
+
 // GetUserByID retrieves a user by ID.
 func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
@@ -265,6 +290,7 @@ func (s *AuthService) generateJWT(user *models.User, expiresAt time.Time) (strin
 
 	claims := &models.JWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   user.ID.String(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
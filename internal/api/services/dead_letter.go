@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// defaultDeadLetterListLimit caps how many events are listed when the
+// request does not set a limit.
+const defaultDeadLetterListLimit = 100
+
+// DeadLetterStore is the persistence used by DeadLetterService.
+type DeadLetterStore interface {
+	List(ctx context.Context, filter models.DeadLetterFilter) ([]models.DeadLetterEvent, error)
+	Get(ctx context.Context, id int64) (*models.DeadLetterEvent, error)
+	CountReplayable(ctx context.Context, filter models.DeadLetterFilter) (int64, error)
+	Replay(ctx context.Context, filter models.DeadLetterFilter) (int64, error)
+}
+
+// DeadLetterService lists dead-letter events and replays them into the CDC
+// buffer, where the batch processor picks them up again.
+type DeadLetterService struct {
+	store  DeadLetterStore
+	logger *slog.Logger
+}
+
+// NewDeadLetterService creates a new DeadLetterService.
+func NewDeadLetterService(store DeadLetterStore, logger *slog.Logger) *DeadLetterService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &DeadLetterService{
+		store:  store,
+		logger: logger.With("component", "dead-letter-service"),
+	}
+}
+
+// List returns dead-letter events matching filter, oldest first.
+func (s *DeadLetterService) List(ctx context.Context, filter models.DeadLetterFilter) ([]models.DeadLetterEvent, error) {
+	if filter.Limit < 0 {
+		return nil, &ValidationError{Errors: []models.FieldError{{Field: "limit", Message: "limit must not be negative"}}}
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDeadLetterListLimit
+	}
+
+	events, err := s.store.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead-letter events: %w", err)
+	}
+	if events == nil {
+		events = []models.DeadLetterEvent{}
+	}
+	return events, nil
+}
+
+// Get returns a dead-letter event with its event data.
+func (s *DeadLetterService) Get(ctx context.Context, id int64) (*models.DeadLetterEvent, error) {
+	event, err := s.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDeadLetterEventNotFound) {
+			return nil, &NotFoundError{Resource: "dead-letter event", ID: strconv.FormatInt(id, 10)}
+		}
+		return nil, fmt.Errorf("failed to get dead-letter event: %w", err)
+	}
+	return event, nil
+}
+
+// Replay re-enqueues the dead-letter events selected by req into the buffer
+// and marks them replayed. With DryRun it only counts them.
+func (s *DeadLetterService) Replay(ctx context.Context, req *models.ReplayDeadLetterRequest) (*models.ReplayDeadLetterResponse, error) {
+	filter := models.DeadLetterFilter{SourceID: req.SourceID, Since: req.Since}
+
+	if req.DryRun {
+		count, err := s.store.CountReplayable(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count dead-letter events: %w", err)
+		}
+		return &models.ReplayDeadLetterResponse{Replayed: count, DryRun: true}, nil
+	}
+
+	replayed, err := s.store.Replay(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay dead-letter events: %w", err)
+	}
+
+	s.logger.Info("replayed dead-letter events",
+		"replayed", replayed,
+		"source_id", req.SourceID,
+		"since", req.Since,
+	)
+	return &models.ReplayDeadLetterResponse{Replayed: replayed}, nil
+}
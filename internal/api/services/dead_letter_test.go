@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// fakeDeadLetterStore holds pending events per source.
+type fakeDeadLetterStore struct {
+	pending  map[string]int64
+	replayed int64
+	filter   models.DeadLetterFilter
+}
+
+func (f *fakeDeadLetterStore) List(_ context.Context, filter models.DeadLetterFilter) ([]models.DeadLetterEvent, error) {
+	f.filter = filter
+	return nil, nil
+}
+
+func (f *fakeDeadLetterStore) Get(_ context.Context, _ int64) (*models.DeadLetterEvent, error) {
+	return nil, repositories.ErrDeadLetterEventNotFound
+}
+
+func (f *fakeDeadLetterStore) CountReplayable(_ context.Context, filter models.DeadLetterFilter) (int64, error) {
+	return f.pending[filter.SourceID], nil
+}
+
+func (f *fakeDeadLetterStore) Replay(_ context.Context, filter models.DeadLetterFilter) (int64, error) {
+	n := f.pending[filter.SourceID]
+	f.pending[filter.SourceID] = 0
+	f.replayed += n
+	return n, nil
+}
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	store := &fakeDeadLetterStore{pending: map[string]int64{"orders": 3}}
+	svc := NewDeadLetterService(store, nil)
+	ctx := context.Background()
+
+	resp, err := svc.Replay(ctx, &models.ReplayDeadLetterRequest{SourceID: "orders", DryRun: true})
+	if err != nil {
+		t.Fatalf("Replay(dry run) error = %v", err)
+	}
+	if resp.Replayed != 3 || !resp.DryRun || store.replayed != 0 {
+		t.Errorf("dry run = %+v with %d replayed, want 3 counted and none replayed", resp, store.replayed)
+	}
+
+	resp, err = svc.Replay(ctx, &models.ReplayDeadLetterRequest{SourceID: "orders"})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if resp.Replayed != 3 || resp.DryRun {
+		t.Errorf("Replay() = %+v, want 3 replayed", resp)
+	}
+
+	// Replayed events are not replayed again
+	if resp, _ := svc.Replay(ctx, &models.ReplayDeadLetterRequest{SourceID: "orders"}); resp.Replayed != 0 {
+		t.Errorf("second Replay() = %+v, want nothing left to replay", resp)
+	}
+}
+
+func TestDeadLetterService_ListAndGet(t *testing.T) {
+	store := &fakeDeadLetterStore{}
+	svc := NewDeadLetterService(store, nil)
+	ctx := context.Background()
+
+	events, err := svc.List(ctx, models.DeadLetterFilter{})
+	if err != nil || events == nil || store.filter.Limit != defaultDeadLetterListLimit {
+		t.Errorf("List() = %v, %v with limit %d; want an empty list with the default limit", events, err, store.filter.Limit)
+	}
+
+	var validationErr *ValidationError
+	if _, err := svc.List(ctx, models.DeadLetterFilter{Limit: -1}); !errors.As(err, &validationErr) {
+		t.Errorf("List(limit -1) error = %v, want a ValidationError", err)
+	}
+
+	var notFound *NotFoundError
+	if _, err := svc.Get(ctx, 42); !errors.As(err, &notFound) {
+		t.Errorf("Get() error = %v, want a NotFoundError", err)
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// idempotencyCleanupInterval is how often expired idempotency keys are
+// deleted.
+const idempotencyCleanupInterval = time.Hour
+
+// Idempotency service errors.
+var (
+	// ErrIdempotencyKeyInProgress is returned when the request an
+	// idempotency key was reserved for has not completed yet.
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is sent
+	// with a different request than it was first used for.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
+// IdempotencyStore is the persistence used by IdempotencyService.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, scope, key, requestHash string, now, expiresAt time.Time) (*models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+var _ IdempotencyStore = (*repositories.IdempotencyRepository)(nil)
+
+// IdempotencyService keeps the idempotency keys of mutating requests and
+// their responses, so that retried requests are answered with the stored
+// response instead of being executed again. Keys expire after the TTL.
+type IdempotencyService struct {
+	store  IdempotencyStore
+	ttl    time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewIdempotencyService creates a new IdempotencyService.
+func NewIdempotencyService(store IdempotencyStore, ttl time.Duration, logger *slog.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		store:  store,
+		ttl:    ttl,
+		logger: logger.With("component", "idempotency-service"),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Begin reserves an idempotency key for a request. It returns nil when the
+// request should be executed, or the record of the completed request to
+// replay. A key held by an unfinished request fails with
+// ErrIdempotencyKeyInProgress, and one used for a different request with
+// ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (*models.IdempotencyRecord, error) {
+	for attempt := 0; ; attempt++ {
+		now := s.now()
+		record, reserved, err := s.store.Reserve(ctx, scope, key, requestHash, now, now.Add(s.ttl))
+		if err != nil {
+			// The key was released between the reservation and the lookup.
+			if errors.Is(err, repositories.ErrIdempotencyKeyNotFound) && attempt == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		switch {
+		case reserved:
+			return nil, nil
+		case record.RequestHash != requestHash:
+			return nil, ErrIdempotencyKeyReused
+		case !record.Completed():
+			return nil, ErrIdempotencyKeyInProgress
+		default:
+			return record, nil
+		}
+	}
+}
+
+// Complete stores the response of the request an idempotency key was
+// reserved for.
+func (s *IdempotencyService) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	if err := s.store.Complete(ctx, scope, key, statusCode, contentType, body); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release frees an idempotency key whose request failed without a response
+// worth replaying, so that the client can retry it.
+func (s *IdempotencyService) Release(ctx context.Context, scope, key string) error {
+	if err := s.store.Release(ctx, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// --- Cleanup ---
+
+// Start starts the periodic cleanup of expired idempotency keys.
+func (s *IdempotencyService) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.runLoop(ctx)
+}
+
+// Stop stops the periodic cleanup.
+func (s *IdempotencyService) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *IdempotencyService) runLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Cleanup(ctx); err != nil {
+			s.logger.Error("idempotency key cleanup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup deletes expired idempotency keys and returns how many were
+// deleted.
+func (s *IdempotencyService) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteExpired(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("cleanup expired idempotency keys: %w", err)
+	}
+
+	if deleted > 0 {
+		s.logger.Info("cleaned up expired idempotency keys", "count", deleted)
+	}
+	return deleted, nil
+}
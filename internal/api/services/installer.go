@@ -16,8 +16,9 @@ import (
 
 // InstallerService provides business logic for deployment operations.
 type InstallerService struct {
-	repo   *repositories.DeploymentRepository
-	logger *slog.Logger
+	repo      *repositories.DeploymentRepository
+	auditRepo AuditLogWriter
+	logger    *slog.Logger
 }
 
 // NewInstallerService creates a new InstallerService.
@@ -31,6 +32,12 @@ func NewInstallerService(
 	}
 }
 
+// SetAuditRepository sets the repository that destroys and delete
+// protection changes are audited to. Without one they are not audited.
+func (s *InstallerService) SetAuditRepository(auditRepo AuditLogWriter) {
+	s.auditRepo = auditRepo
+}
+
 // GetProviders returns all supported cloud providers.
 func (s *InstallerService) GetProviders(_ context.Context) []models.Provider {
 	return installer.GetProviders()
@@ -55,6 +62,10 @@ func (s *InstallerService) CreateDeployment(ctx context.Context, req *models.Cre
 	// Apply defaults
 	req.ApplyDefaults()
 
+	if req.Mode == models.DeploymentModeExistingCluster {
+		return s.createClusterDeployment(ctx, req, userID)
+	}
+
 	// Validate provider exists
 	if !installer.ValidateProvider(req.Provider) {
 		return nil, &ValidationError{
@@ -85,14 +96,16 @@ func (s *InstallerService) CreateDeployment(ctx context.Context, req *models.Cre
 
 	// Create deployment model
 	deployment := &models.Deployment{
-		UserID:      userID,
-		Name:        req.Name,
-		Provider:    req.Provider,
-		Region:      req.Region,
-		Size:        req.Size,
-		Status:      models.DeploymentStatusPending,
-		Environment: req.Environment,
+		UserID:           userID,
+		Name:             req.Name,
+		Provider:         req.Provider,
+		Region:           req.Region,
+		Size:             req.Size,
+		Status:           models.DeploymentStatusPending,
+		Environment:      req.Environment,
+		DeleteProtection: req.DeleteProtection,
 		Config: &models.DeploymentConfig{
+			Mode:          req.Mode,
 			Domain:        req.Domain,
 			SSHPublicKey:  req.SSHPublicKey,
 			ChartVersion:  req.ChartVersion,
@@ -110,13 +123,9 @@ func (s *InstallerService) CreateDeployment(ctx context.Context, req *models.Cre
 	}
 
 	// Create in database
-	created, err := s.repo.Create(ctx, deployment)
+	created, err := s.create(ctx, deployment)
 	if err != nil {
-		if errors.Is(err, repositories.ErrDeploymentNameExists) {
-			return nil, &ConflictError{Message: "deployment with this name already exists"}
-		}
-		s.logger.Error("failed to create deployment", "error", err)
-		return nil, fmt.Errorf("failed to create deployment: %w", err)
+		return nil, err
 	}
 
 	s.logger.Info("deployment created",
@@ -130,6 +139,56 @@ func (s *InstallerService) CreateDeployment(ctx context.Context, req *models.Cre
 	return created, nil
 }
 
+// createClusterDeployment creates a deployment installing Philotes on an
+// existing cluster. Its kubeconfig is validated up front, so a broken one is
+// rejected before the deployment is created.
+func (s *InstallerService) createClusterDeployment(ctx context.Context, req *models.CreateDeploymentRequest, userID *uuid.UUID) (*models.Deployment, error) {
+	if err := installer.ValidateKubeconfig(req.Credentials.Kubeconfig); err != nil {
+		return nil, &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "credentials.kubeconfig", Message: err.Error()},
+			},
+		}
+	}
+
+	created, err := s.create(ctx, &models.Deployment{
+		UserID:           userID,
+		Name:             req.Name,
+		Status:           models.DeploymentStatusPending,
+		Environment:      req.Environment,
+		DeleteProtection: req.DeleteProtection,
+		Config: &models.DeploymentConfig{
+			Mode:         models.DeploymentModeExistingCluster,
+			Domain:       req.Domain,
+			ChartVersion: req.ChartVersion,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("deployment created",
+		"id", created.ID,
+		"name", created.Name,
+		"mode", models.DeploymentModeExistingCluster,
+	)
+
+	return created, nil
+}
+
+// create stores a new deployment.
+func (s *InstallerService) create(ctx context.Context, deployment *models.Deployment) (*models.Deployment, error) {
+	created, err := s.repo.Create(ctx, deployment)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDeploymentNameExists) {
+			return nil, &ConflictError{Message: "deployment with this name already exists"}
+		}
+		s.logger.Error("failed to create deployment", "error", err)
+		return nil, fmt.Errorf("failed to create deployment: %w", err)
+	}
+	return created, nil
+}
+
 // GetDeployment retrieves a deployment by ID.
 func (s *InstallerService) GetDeployment(ctx context.Context, id uuid.UUID) (*models.Deployment, error) {
 	deployment, err := s.repo.GetByID(ctx, id)
@@ -202,12 +261,12 @@ func (s *InstallerService) DeleteDeployment(ctx context.Context, id uuid.UUID) e
 	}
 
 	// Don't allow deletion of active deployments
-	if deployment.Status == models.DeploymentStatusProvisioning ||
-		deployment.Status == models.DeploymentStatusConfiguring ||
-		deployment.Status == models.DeploymentStatusDeploying ||
-		deployment.Status == models.DeploymentStatusVerifying {
+	if isActiveDeployment(deployment) {
 		return &ConflictError{Message: "cannot delete an active deployment"}
 	}
+	if deployment.DeleteProtection {
+		return &ConflictError{Message: "deployment has delete protection enabled"}
+	}
 
 	if err := s.repo.Delete(ctx, id); err != nil {
 		if errors.Is(err, repositories.ErrDeploymentNotFound) {
@@ -220,6 +279,138 @@ func (s *InstallerService) DeleteDeployment(ctx context.Context, id uuid.UUID) e
 	return nil
 }
 
+// DestroyDeployment starts destroying a deployment's infrastructure. The
+// request must confirm the deployment and, for production, override; delete
+// protected deployments are refused. The destroy is audited as initiated by
+// userID.
+func (s *InstallerService) DestroyDeployment(
+	ctx context.Context,
+	id uuid.UUID,
+	req *models.DestroyDeploymentRequest,
+	userID *uuid.UUID,
+	ipAddress, userAgent string,
+	orchestrator *installer.DeploymentOrchestrator,
+) error {
+	deployment, err := s.GetDeployment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if isActiveDeployment(deployment) {
+		return &ConflictError{Message: "cannot destroy an active deployment"}
+	}
+	if deployment.Status == models.DeploymentStatusDestroyed {
+		return &ConflictError{Message: "deployment is already destroyed"}
+	}
+
+	cfg := runConfig(id, deployment)
+	destroyReq := &installer.DestroyRequest{
+		DeploymentID:       id,
+		Name:               deployment.Name,
+		StackName:          orchestrator.StackName(cfg),
+		Environment:        deployment.Environment,
+		DeleteProtection:   deployment.DeleteProtection,
+		Confirmation:       req.Confirmation,
+		OverrideProduction: req.OverrideProduction,
+	}
+	if err := destroyCheckError(destroyReq.Check(), destroyReq); err != nil {
+		return err
+	}
+
+	s.logAuditEvent(ctx, userID, &id, models.AuditActionDeploymentDestroyInitiated, ipAddress, userAgent, map[string]any{
+		"name":                deployment.Name,
+		"stack":               destroyReq.StackName,
+		"environment":         deployment.Environment,
+		"override_production": req.OverrideProduction,
+	})
+
+	if err := s.repo.AddLog(ctx, id, "warn", "destroying", "Destroy requested"); err != nil {
+		s.logger.Warn("failed to add destroy log", "deployment_id", id, "error", err)
+	}
+
+	// The destroy outlives the request
+	runCtx := context.WithoutCancel(ctx)
+	statusCallback := func(status string, err error) {
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		if updateErr := s.repo.UpdateStatus(runCtx, id, models.DeploymentStatus(status), errMsg); updateErr != nil {
+			s.logger.Error("failed to update deployment status", "deployment_id", id, "error", updateErr)
+		}
+	}
+
+	if err := orchestrator.DestroyDeployment(runCtx, destroyReq, statusCallback); err != nil {
+		return fmt.Errorf("failed to start destroy: %w", err)
+	}
+
+	s.logger.Info("deployment destroy initiated", "id", id, "stack", destroyReq.StackName, "environment", deployment.Environment)
+	return nil
+}
+
+// destroyCheckError maps a failed destroy check to the service error
+// reported for it.
+func destroyCheckError(err error, req *installer.DestroyRequest) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, installer.ErrDeleteProtected):
+		return &ConflictError{Message: "deployment has delete protection enabled; disable it before destroying"}
+	case errors.Is(err, installer.ErrConfirmationMismatch):
+		return &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "confirmation", Message: fmt.Sprintf("confirmation must be %q", installer.ConfirmationToken(req.Name, req.StackName))},
+			},
+		}
+	case errors.Is(err, installer.ErrProductionOverrideRequired):
+		return &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "override_production", Message: "override_production must be set to destroy a production deployment"},
+			},
+		}
+	default:
+		return err
+	}
+}
+
+// UpdateDeleteProtection enables or disables the delete protection of a
+// deployment, audited as changed by userID.
+func (s *InstallerService) UpdateDeleteProtection(ctx context.Context, id uuid.UUID, enabled bool, userID *uuid.UUID, ipAddress, userAgent string) (*models.Deployment, error) {
+	if err := s.repo.UpdateDeleteProtection(ctx, id, enabled); err != nil {
+		if errors.Is(err, repositories.ErrDeploymentNotFound) {
+			return nil, &NotFoundError{Resource: "deployment", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to update delete protection: %w", err)
+	}
+
+	s.logAuditEvent(ctx, userID, &id, models.AuditActionDeploymentProtectionUpdated, ipAddress, userAgent, map[string]any{
+		"delete_protection": enabled,
+	})
+
+	s.logger.Info("deployment delete protection updated", "id", id, "enabled", enabled)
+	return s.GetDeployment(ctx, id)
+}
+
+// logAuditEvent logs an audit event for a deployment operation.
+func (s *InstallerService) logAuditEvent(ctx context.Context, userID, deploymentID *uuid.UUID, action, ipAddress, userAgent string, details map[string]any) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	log := &models.AuditLog{
+		UserID:       userID,
+		Action:       action,
+		ResourceType: "deployment",
+		ResourceID:   deploymentID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		Details:      details,
+	}
+	if err := s.auditRepo.Create(ctx, log); err != nil {
+		s.logger.Warn("failed to create audit log", "action", action, "error", err)
+	}
+}
+
 // GetDeploymentLogs retrieves logs for a deployment.
 func (s *InstallerService) GetDeploymentLogs(ctx context.Context, deploymentID uuid.UUID, limit int) ([]models.DeploymentLog, error) {
 	// Verify deployment exists
@@ -248,30 +439,46 @@ func (s *InstallerService) GetCostEstimate(_ context.Context, providerID string,
 		return nil, &NotFoundError{Resource: "provider", ID: providerID}
 	}
 
-	sizeConfig := installer.GetSizeConfig(providerID, sizeID)
-	if sizeConfig == nil {
+	estimate := installer.EstimateCost(providerID, sizeID)
+	if estimate == nil {
 		return nil, &ValidationError{
 			Errors: []models.FieldError{
 				{Field: "size", Message: "invalid size for provider"},
 			},
 		}
 	}
+	return estimate, nil
+}
 
-	// Build cost estimate based on size configuration
-	estimate := &models.CostEstimate{
-		Provider: providerID,
-		Size:     string(sizeID),
-		Total:    sizeConfig.MonthlyCostEUR,
-		Currency: "EUR",
+// EstimateCost returns the monthly cost of a deployment of a size in a
+// provider's region, so a size can be chosen before anything is
+// provisioned.
+func (s *InstallerService) EstimateCost(_ context.Context, req *models.CostEstimateRequest) (*models.CostEstimate, error) {
+	if !installer.ValidateProvider(req.Provider) {
+		return nil, &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "provider", Message: "unsupported provider"},
+			},
+		}
 	}
 
-	// Calculate component costs (simplified - actual breakdown may vary)
-	// These are rough estimates based on typical distributions
-	estimate.ControlPlane = sizeConfig.MonthlyCostEUR * 0.2 // ~20% for control plane
-	estimate.Workers = sizeConfig.MonthlyCostEUR * 0.6      // ~60% for workers
-	estimate.Storage = sizeConfig.MonthlyCostEUR * 0.1      // ~10% for storage
-	estimate.LoadBalancer = sizeConfig.MonthlyCostEUR * 0.1 // ~10% for LB
+	if !installer.ValidateRegion(req.Provider, req.Region) {
+		return nil, &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "region", Message: "invalid region for provider"},
+			},
+		}
+	}
 
+	estimate := installer.EstimateCost(req.Provider, req.Size)
+	if estimate == nil {
+		return nil, &ValidationError{
+			Errors: []models.FieldError{
+				{Field: "size", Message: "invalid size for provider"},
+			},
+		}
+	}
+	estimate.Region = req.Region
 	return estimate, nil
 }
 
@@ -293,15 +500,7 @@ func (s *InstallerService) RetryDeployment(ctx context.Context, id uuid.UUID, de
 	}
 
 	// Build deployment config for orchestrator
-	cfg := &installer.DeploymentConfig{
-		DeploymentID: id,
-		StackName:    deployment.PulumiStackName,
-		Provider:     deployment.Provider,
-		Region:       deployment.Region,
-		Environment:  deployment.Environment,
-		Size:         deployment.Size,
-		Config:       deployment.Config,
-	}
+	cfg := runConfig(id, deployment)
 
 	// Create status callback to update database
 	statusCallback := func(status string, err error) {
@@ -327,3 +526,59 @@ func (s *InstallerService) RetryDeployment(ctx context.Context, id uuid.UUID, de
 	s.logger.Info("deployment retry initiated", "id", id)
 	return nil
 }
+
+// PreviewDeployment previews the changes deploying a deployment would make.
+func (s *InstallerService) PreviewDeployment(ctx context.Context, id uuid.UUID, orchestrator *installer.DeploymentOrchestrator) (*installer.DeploymentPreview, error) {
+	deployment, err := s.GetDeployment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isActiveDeployment(deployment) {
+		return nil, &ConflictError{Message: "cannot preview an active deployment"}
+	}
+
+	preview, err := orchestrator.PreviewDeployment(ctx, runConfig(id, deployment))
+	if err != nil {
+		s.logger.Warn("deployment preview failed", "deployment_id", id, "error", err)
+		return nil, &PreviewError{StepError: installer.GetErrorSuggestion(err, "preview")}
+	}
+
+	return preview, nil
+}
+
+// runConfig returns the orchestrator configuration of a deployment.
+func runConfig(id uuid.UUID, deployment *models.Deployment) *installer.DeploymentConfig {
+	return &installer.DeploymentConfig{
+		DeploymentID: id,
+		StackName:    deployment.PulumiStackName,
+		Provider:     deployment.Provider,
+		Region:       deployment.Region,
+		Environment:  deployment.Environment,
+		Size:         deployment.Size,
+		Config:       deployment.Config,
+	}
+}
+
+// isActiveDeployment reports whether a deployment is being run.
+func isActiveDeployment(deployment *models.Deployment) bool {
+	switch deployment.Status {
+	case models.DeploymentStatusProvisioning,
+		models.DeploymentStatusConfiguring,
+		models.DeploymentStatusDeploying,
+		models.DeploymentStatusVerifying,
+		models.DeploymentStatusDestroying:
+		return true
+	}
+	return false
+}
+
+// PreviewError represents a failed deployment preview, such as for invalid
+// configuration or rejected credentials.
+type PreviewError struct {
+	*installer.StepError
+}
+
+func (e *PreviewError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.Details)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/janovincze/philotes/internal/crypto"
+)
+
+// CredentialReencrypter re-encrypts stored cloud provider credentials.
+type CredentialReencrypter interface {
+	ReencryptCredentials(ctx context.Context, encryptor *crypto.Encryptor) (int, error)
+}
+
+// ClientSecretReencrypter re-encrypts stored OIDC provider client secrets.
+type ClientSecretReencrypter interface {
+	ReencryptClientSecrets(ctx context.Context) (int, error)
+}
+
+// KeyRotationService upgrades stored secrets to the primary encryption key
+// once the key is rotated, so previous keys can be removed from the
+// configuration afterwards.
+type KeyRotationService struct {
+	credentials   CredentialReencrypter
+	clientSecrets ClientSecretReencrypter
+	encryptor     *crypto.Encryptor
+	logger        *slog.Logger
+}
+
+// NewKeyRotationService creates a new KeyRotationService. The encryptor
+// re-encrypts cloud credentials; client secrets are re-encrypted with the
+// encryptor of their repository.
+func NewKeyRotationService(
+	credentials CredentialReencrypter,
+	clientSecrets ClientSecretReencrypter,
+	encryptor *crypto.Encryptor,
+	logger *slog.Logger,
+) *KeyRotationService {
+	return &KeyRotationService{
+		credentials:   credentials,
+		clientSecrets: clientSecrets,
+		encryptor:     encryptor,
+		logger:        logger.With("component", "key-rotation-service"),
+	}
+}
+
+// Reencrypt re-encrypts the cloud credentials and OIDC client secrets that
+// were not encrypted with the primary key. It is safe to run repeatedly:
+// secrets already encrypted with the primary key are left alone.
+func (s *KeyRotationService) Reencrypt(ctx context.Context) error {
+	var errs []error
+
+	credentials, err := s.credentials.ReencryptCredentials(ctx, s.encryptor)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to re-encrypt cloud credentials: %w", err))
+	}
+
+	clientSecrets, err := s.clientSecrets.ReencryptClientSecrets(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to re-encrypt oidc client secrets: %w", err))
+	}
+
+	s.logger.Info("stored secrets re-encrypted",
+		"key_id", s.encryptor.KeyID(),
+		"cloud_credentials", credentials,
+		"oidc_client_secrets", clientSecrets,
+	)
+
+	return errors.Join(errs...)
+}
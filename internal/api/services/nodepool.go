@@ -377,6 +377,28 @@ func (s *NodePoolService) GetPendingPods(ctx context.Context) (*models.PendingPo
 	}, nil
 }
 
+// GetCostReport returns the cost of all node pools. When from and to are
+// set, it includes the estimated cost change of scaling operations within
+// that range.
+func (s *NodePoolService) GetCostReport(ctx context.Context, from, to *time.Time) (*nodepool.CostReport, error) {
+	if (from == nil) != (to == nil) {
+		return nil, &ValidationError{Errors: []models.FieldError{
+			{Field: "from", Message: "from and to must be set together"},
+		}}
+	}
+	if from != nil && !from.Before(*to) {
+		return nil, &ValidationError{Errors: []models.FieldError{
+			{Field: "from", Message: "from must be before to"},
+		}}
+	}
+
+	report, err := s.manager.GetCostReport(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost report: %w", err)
+	}
+	return report, nil
+}
+
 // GetPricing retrieves pricing for an instance type.
 func (s *NodePoolService) GetPricing(ctx context.Context, provider nodepool.Provider, instanceType, region string) (*nodepool.InstanceTypePricing, error) {
 	pricing, err := s.manager.GetPricing(ctx, provider, instanceType, region)
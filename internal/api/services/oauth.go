@@ -5,11 +5,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,22 +25,49 @@ import (
 	"github.com/janovincze/philotes/internal/installer/oauth"
 )
 
+// ErrRefreshTokenRevoked indicates the provider rejected a refresh token
+// with invalid_grant: it was revoked, expired or already used, as when a
+// stolen refresh token is replayed after rotation.
+var ErrRefreshTokenRevoked = errors.New("refresh token revoked by provider")
+
+// OAuthStore is the persistence used by OAuthService.
+type OAuthStore interface {
+	DB() *sql.DB
+	CreateState(ctx context.Context, state *models.OAuthState) error
+	GetStateByState(ctx context.Context, state string) (*models.OAuthState, error)
+	CleanupExpiredStates(ctx context.Context) (int64, error)
+	CreateCredential(ctx context.Context, cred *models.CloudCredential) error
+	GetCredentialByID(ctx context.Context, id uuid.UUID) (*models.CloudCredential, error)
+	GetCredentialByProvider(ctx context.Context, userID uuid.UUID, provider string) (*models.CloudCredential, error)
+	ListCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]models.CloudCredential, error)
+	ListRefreshableCredentials(ctx context.Context, before time.Time) ([]models.CloudCredential, error)
+	UpdateCredential(ctx context.Context, cred *models.CloudCredential) error
+	DeleteCredential(ctx context.Context, id uuid.UUID) error
+	DeleteCredentialByProvider(ctx context.Context, userID uuid.UUID, provider string) error
+	CleanupExpiredCredentials(ctx context.Context) (int64, error)
+}
+
 // OAuthService handles OAuth flows for cloud providers.
 type OAuthService struct {
-	repo       *repositories.OAuthRepository
+	repo       OAuthStore
+	auditRepo  AuditLogWriter
 	encryptor  *crypto.Encryptor
 	config     config.OAuthConfig
 	registry   *oauth.ProviderRegistry
 	httpClient *http.Client
+	logger     *slog.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
 }
 
 // NewOAuthService creates a new OAuthService.
 func NewOAuthService(
-	repo *repositories.OAuthRepository,
+	repo OAuthStore,
 	cfg config.OAuthConfig,
 ) (*OAuthService, error) {
 	// Check if any OAuth provider is enabled
-	anyProviderEnabled := cfg.Hetzner.Enabled || cfg.OVH.Enabled
+	anyProviderEnabled := cfg.Hetzner.Enabled || cfg.OVH.Enabled || cfg.Generic.Enabled
 
 	// Fail fast: if OAuth is enabled, encryption key must be configured
 	if anyProviderEnabled && cfg.EncryptionKey == "" {
@@ -47,7 +78,7 @@ func NewOAuthService(
 	var encryptor *crypto.Encryptor
 	if cfg.EncryptionKey != "" {
 		var err error
-		encryptor, err = crypto.NewEncryptorFromString(cfg.EncryptionKey)
+		encryptor, err = crypto.NewEncryptorFromString(cfg.EncryptionKey, cfg.PreviousEncryptionKeys...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create encryptor: %w", err)
 		}
@@ -74,6 +105,30 @@ func NewOAuthService(
 		}))
 	}
 
+	// Register the generic provider if configured
+	if cfg.Generic.ClientID != "" {
+		if _, ok := registry.Get(cfg.Generic.Name); ok {
+			return nil, fmt.Errorf("generic oauth provider name %q is taken by a built-in provider", cfg.Generic.Name)
+		}
+		if !isCredentialField(cfg.Generic.CredentialField) {
+			return nil, fmt.Errorf("generic oauth credential field %q is not a provider credentials field", cfg.Generic.CredentialField)
+		}
+		generic, err := oauth.NewGenericProvider(oauth.GenericConfig{
+			Name:         cfg.Generic.Name,
+			DisplayName:  cfg.Generic.DisplayName,
+			AuthURL:      cfg.Generic.AuthURL,
+			TokenURL:     cfg.Generic.TokenURL,
+			Scopes:       cfg.Generic.Scopes,
+			ClientID:     cfg.Generic.ClientID,
+			ClientSecret: cfg.Generic.ClientSecret,
+			Enabled:      cfg.Generic.Enabled,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create generic oauth provider: %w", err)
+		}
+		registry.Register(generic)
+	}
+
 	return &OAuthService{
 		repo:      repo,
 		encryptor: encryptor,
@@ -82,9 +137,17 @@ func NewOAuthService(
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		logger: slog.Default().With("component", "oauth-service"),
+		stopCh: make(chan struct{}),
 	}, nil
 }
 
+// SetAuditRepository sets the repository that revoked credentials are
+// audited to. Without one they are not audited.
+func (s *OAuthService) SetAuditRepository(auditRepo AuditLogWriter) {
+	s.auditRepo = auditRepo
+}
+
 // validateRedirectURI validates that the redirect URI is allowed to prevent open redirect attacks.
 func (s *OAuthService) validateRedirectURI(redirectURI string) error {
 	parsed, err := url.Parse(redirectURI)
@@ -557,10 +620,39 @@ func (s *OAuthService) buildOAuthCredentials(provider, accessToken string) (*mod
 			OVHConsumerKey: accessToken,
 		}, nil
 	default:
+		if s.config.Generic.ClientID != "" && provider == s.config.Generic.Name {
+			return genericCredentials(s.config.Generic.CredentialField, accessToken)
+		}
 		return nil, fmt.Errorf("unsupported OAuth provider: %s", provider)
 	}
 }
 
+// genericCredentials returns provider credentials holding the access token
+// of the generic provider in the field with the given JSON name.
+func genericCredentials(field, accessToken string) (*models.ProviderCredentials, error) {
+	raw, err := json.Marshal(map[string]string{field: accessToken})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build credentials: %w", err)
+	}
+	var credentials models.ProviderCredentials
+	if err := json.Unmarshal(raw, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to build credentials: %w", err)
+	}
+	return &credentials, nil
+}
+
+// isCredentialField reports whether field is the JSON name of a provider
+// credentials field.
+func isCredentialField(field string) bool {
+	t := reflect.TypeOf(models.ProviderCredentials{})
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name == field && field != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // ListCredentials lists all credentials for a user.
 func (s *OAuthService) ListCredentials(
 	ctx context.Context,
@@ -656,8 +748,12 @@ func (s *OAuthService) RefreshToken(
 		return fmt.Errorf("provider %s not configured", cred.Provider)
 	}
 
-	// Request new token
+	// Request new token. A rejected refresh token may have been replayed
+	// by someone else, so the credential is revoked rather than retried.
 	token, err := s.refreshToken(ctx, provider, refreshToken)
+	if errors.Is(err, ErrRefreshTokenRevoked) {
+		return s.revokeCredential(ctx, cred, err)
+	}
 	if err != nil {
 		return fmt.Errorf("token refresh failed: %w", err)
 	}
@@ -685,6 +781,39 @@ func (s *OAuthService) RefreshToken(
 	return s.repo.UpdateCredential(ctx, cred)
 }
 
+// revokeCredential deletes a credential whose refresh token the provider
+// rejected and audits the revocation. It returns the rejection.
+func (s *OAuthService) revokeCredential(ctx context.Context, cred *models.CloudCredential, reason error) error {
+	s.logger.Warn("refresh token rejected, revoking credential",
+		"credential_id", cred.ID,
+		"provider", cred.Provider,
+		"error", reason,
+	)
+
+	if err := s.repo.DeleteCredential(ctx, cred.ID); err != nil && !errors.Is(err, repositories.ErrCredentialNotFound) {
+		return errors.Join(reason, fmt.Errorf("failed to delete revoked credential: %w", err))
+	}
+
+	if s.auditRepo != nil {
+		credID := cred.ID
+		log := &models.AuditLog{
+			UserID:       cred.UserID,
+			Action:       models.AuditActionCredentialRevoked,
+			ResourceType: "cloud_credential",
+			ResourceID:   &credID,
+			Details: map[string]any{
+				"provider": cred.Provider,
+				"reason":   reason.Error(),
+			},
+		}
+		if err := s.auditRepo.Create(ctx, log); err != nil {
+			s.logger.Warn("failed to create audit log", "action", log.Action, "error", err)
+		}
+	}
+
+	return reason
+}
+
 // refreshToken performs the OAuth token refresh.
 func (s *OAuthService) refreshToken(
 	ctx context.Context,
@@ -726,6 +855,13 @@ func (s *OAuthService) refreshToken(
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error == "invalid_grant" {
+			return nil, fmt.Errorf("%w: %s", ErrRefreshTokenRevoked, errResp.ErrorDescription)
+		}
 		return nil, fmt.Errorf("refresh endpoint returned %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -770,3 +906,84 @@ func (s *OAuthService) CleanupExpired(ctx context.Context) (states, creds int64,
 
 	return states, creds, nil
 }
+
+// RefreshExpiring refreshes the credentials whose access token expires
+// within the refresh window. Credentials whose refresh token is rejected
+// are revoked. It returns the number of refreshed and revoked credentials.
+func (s *OAuthService) RefreshExpiring(ctx context.Context) (refreshed, revoked int, err error) {
+	creds, err := s.repo.ListRefreshableCredentials(ctx, time.Now().Add(s.config.RefreshWindow))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list refreshable credentials: %w", err)
+	}
+
+	var errs []error
+	for i := range creds {
+		switch err := s.RefreshToken(ctx, creds[i].ID); {
+		case err == nil:
+			refreshed++
+		case errors.Is(err, ErrRefreshTokenRevoked):
+			revoked++
+		default:
+			errs = append(errs, fmt.Errorf("credential %s: %w", creds[i].ID, err))
+		}
+	}
+
+	return refreshed, revoked, errors.Join(errs...)
+}
+
+// Start starts the periodic job that cleans up expired states and
+// credentials and refreshes expiring access tokens.
+func (s *OAuthService) Start(ctx context.Context) {
+	if s.config.RefreshInterval <= 0 {
+		s.logger.Info("oauth token refresh disabled")
+		return
+	}
+
+	s.logger.Info("starting oauth token refresh",
+		"interval", s.config.RefreshInterval,
+		"window", s.config.RefreshWindow,
+	)
+
+	s.wg.Add(1)
+	go s.runLoop(ctx)
+}
+
+// Stop stops the periodic job.
+func (s *OAuthService) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *OAuthService) runLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if states, creds, err := s.CleanupExpired(ctx); err != nil {
+			s.logger.Error("oauth cleanup failed", "error", err)
+		} else if states > 0 || creds > 0 {
+			s.logger.Info("expired oauth data cleaned up", "states", states, "credentials", creds)
+		}
+
+		refreshed, revoked, err := s.RefreshExpiring(ctx)
+		if err != nil {
+			s.logger.Error("oauth token refresh failed", "error", err)
+		}
+		if refreshed > 0 || revoked > 0 {
+			s.logger.Info("oauth tokens refreshed", "refreshed", refreshed, "revoked", revoked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ensure OAuthRepository implements OAuthStore.
+var _ OAuthStore = (*repositories.OAuthRepository)(nil)
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/crypto"
+)
+
+// fakeOAuthStore keeps credentials in memory.
+type fakeOAuthStore struct {
+	creds map[uuid.UUID]*models.CloudCredential
+}
+
+func (f *fakeOAuthStore) DB() *sql.DB                                           { return nil }
+func (f *fakeOAuthStore) CreateState(context.Context, *models.OAuthState) error { return nil }
+func (f *fakeOAuthStore) GetStateByState(context.Context, string) (*models.OAuthState, error) {
+	return nil, repositories.ErrOAuthStateNotFound
+}
+func (f *fakeOAuthStore) CleanupExpiredStates(context.Context) (int64, error)      { return 0, nil }
+func (f *fakeOAuthStore) CleanupExpiredCredentials(context.Context) (int64, error) { return 0, nil }
+
+func (f *fakeOAuthStore) CreateCredential(_ context.Context, cred *models.CloudCredential) error {
+	f.creds[cred.ID] = cred
+	return nil
+}
+
+func (f *fakeOAuthStore) GetCredentialByID(_ context.Context, id uuid.UUID) (*models.CloudCredential, error) {
+	cred, ok := f.creds[id]
+	if !ok {
+		return nil, repositories.ErrCredentialNotFound
+	}
+	c := *cred
+	return &c, nil
+}
+
+func (f *fakeOAuthStore) GetCredentialByProvider(context.Context, uuid.UUID, string) (*models.CloudCredential, error) {
+	return nil, repositories.ErrCredentialNotFound
+}
+
+func (f *fakeOAuthStore) ListCredentialsByUser(context.Context, uuid.UUID) ([]models.CloudCredential, error) {
+	return nil, nil
+}
+
+func (f *fakeOAuthStore) ListRefreshableCredentials(_ context.Context, before time.Time) ([]models.CloudCredential, error) {
+	var creds []models.CloudCredential
+	for _, cred := range f.creds {
+		if cred.RefreshTokenEncrypted != nil && cred.TokenExpiresAt != nil && cred.TokenExpiresAt.Before(before) {
+			creds = append(creds, *cred)
+		}
+	}
+	return creds, nil
+}
+
+func (f *fakeOAuthStore) UpdateCredential(_ context.Context, cred *models.CloudCredential) error {
+	if _, ok := f.creds[cred.ID]; !ok {
+		return repositories.ErrCredentialNotFound
+	}
+	c := *cred
+	f.creds[cred.ID] = &c
+	return nil
+}
+
+func (f *fakeOAuthStore) DeleteCredential(_ context.Context, id uuid.UUID) error {
+	if _, ok := f.creds[id]; !ok {
+		return repositories.ErrCredentialNotFound
+	}
+	delete(f.creds, id)
+	return nil
+}
+
+func (f *fakeOAuthStore) DeleteCredentialByProvider(context.Context, uuid.UUID, string) error {
+	return nil
+}
+
+// fakeOAuthProvider is an OAuth provider with a configurable token URL.
+type fakeOAuthProvider struct {
+	tokenURL string
+}
+
+func (p *fakeOAuthProvider) Name() string                           { return "hetzner" }
+func (p *fakeOAuthProvider) DisplayName() string                    { return "Hetzner Cloud" }
+func (p *fakeOAuthProvider) AuthorizationURL(_, _, _ string) string { return "" }
+func (p *fakeOAuthProvider) TokenURL() string                       { return p.tokenURL }
+func (p *fakeOAuthProvider) Scopes() []string                       { return nil }
+func (p *fakeOAuthProvider) ClientID() string                       { return "client" }
+func (p *fakeOAuthProvider) ClientSecret() string                   { return "" }
+func (p *fakeOAuthProvider) IsEnabled() bool                        { return true }
+
+// newOAuthTestService returns a service whose provider exchanges refresh
+// token "valid" for a rotated token pair and rejects any other with
+// invalid_grant.
+func newOAuthTestService(t *testing.T) (*OAuthService, *fakeOAuthStore, *fakeAuditWriter) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "valid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"refresh token already used"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"new-access","refresh_token":"rotated","expires_in":3600}`))
+	}))
+	t.Cleanup(server.Close)
+
+	key, err := crypto.GenerateKeyBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeOAuthStore{creds: map[uuid.UUID]*models.CloudCredential{}}
+	svc, err := NewOAuthService(store, config.OAuthConfig{EncryptionKey: key, RefreshWindow: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("NewOAuthService() error = %v", err)
+	}
+	svc.registry.Register(&fakeOAuthProvider{tokenURL: server.URL})
+	svc.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	audit := &fakeAuditWriter{done: make(chan struct{}, 16)}
+	svc.SetAuditRepository(audit)
+	return svc, store, audit
+}
+
+// addCredential stores an OAuth credential whose access token expires in
+// expiresIn.
+func addCredential(t *testing.T, svc *OAuthService, store *fakeOAuthStore, refreshToken string, expiresIn time.Duration) uuid.UUID {
+	t.Helper()
+	access, err := svc.encryptor.EncryptToBytes("old-access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := svc.encryptor.EncryptToBytes(refreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenExpiresAt := time.Now().Add(expiresIn)
+	cred := &models.CloudCredential{
+		ID:                    uuid.New(),
+		Provider:              "hetzner",
+		CredentialType:        models.CredentialTypeOAuth,
+		CredentialsEncrypted:  access,
+		RefreshTokenEncrypted: refresh,
+		TokenExpiresAt:        &tokenExpiresAt,
+		ExpiresAt:             time.Now().Add(24 * time.Hour),
+	}
+	store.creds[cred.ID] = cred
+	return cred.ID
+}
+
+func TestOAuthRefreshTokenRotation(t *testing.T) {
+	svc, store, audit := newOAuthTestService(t)
+	id := addCredential(t, svc, store, "valid", time.Minute)
+
+	if err := svc.RefreshToken(context.Background(), id); err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+
+	cred := store.creds[id]
+	if access, err := svc.encryptor.DecryptFromBytes(cred.CredentialsEncrypted); err != nil || access != "new-access" {
+		t.Errorf("access token = %q, %v, want the new access token", access, err)
+	}
+	if refresh, err := svc.encryptor.DecryptFromBytes(cred.RefreshTokenEncrypted); err != nil || refresh != "rotated" {
+		t.Errorf("refresh token = %q, %v, want the rotated refresh token", refresh, err)
+	}
+	if time.Until(*cred.TokenExpiresAt) < 50*time.Minute {
+		t.Errorf("TokenExpiresAt = %v, want the new expiry", cred.TokenExpiresAt)
+	}
+	if len(audit.logs) != 0 {
+		t.Errorf("audit logs = %d, want none for a successful rotation", len(audit.logs))
+	}
+}
+
+func TestOAuthRefreshTokenRevocation(t *testing.T) {
+	svc, store, audit := newOAuthTestService(t)
+	id := addCredential(t, svc, store, "replayed", time.Minute)
+
+	err := svc.RefreshToken(context.Background(), id)
+	if !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("RefreshToken() error = %v, want ErrRefreshTokenRevoked", err)
+	}
+	if _, ok := store.creds[id]; ok {
+		t.Error("revoked credential was not deleted")
+	}
+	if len(audit.logs) != 1 {
+		t.Fatalf("audit logs = %d, want 1", len(audit.logs))
+	}
+	log := audit.logs[0]
+	if log.Action != models.AuditActionCredentialRevoked || log.ResourceID == nil || *log.ResourceID != id || log.Details["provider"] != "hetzner" {
+		t.Errorf("audit log = %+v, want the revoked credential", log)
+	}
+}
+
+func TestOAuthRefreshExpiring(t *testing.T) {
+	svc, store, audit := newOAuthTestService(t)
+	expiring := addCredential(t, svc, store, "valid", 5*time.Minute)
+	replayed := addCredential(t, svc, store, "replayed", 5*time.Minute)
+	fresh := addCredential(t, svc, store, "replayed", time.Hour)
+
+	refreshed, revoked, err := svc.RefreshExpiring(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshExpiring() error = %v", err)
+	}
+	if refreshed != 1 || revoked != 1 {
+		t.Errorf("RefreshExpiring() = %d refreshed, %d revoked, want 1 and 1", refreshed, revoked)
+	}
+	if time.Until(*store.creds[expiring].TokenExpiresAt) < 50*time.Minute {
+		t.Error("credential within the refresh window was not refreshed")
+	}
+	if _, ok := store.creds[replayed]; ok {
+		t.Error("credential with a rejected refresh token was not revoked")
+	}
+	if _, ok := store.creds[fresh]; !ok {
+		t.Error("credential outside the refresh window was refreshed")
+	}
+	if len(audit.logs) != 1 {
+		t.Errorf("audit logs = %d, want 1", len(audit.logs))
+	}
+}
+
+func TestOAuthGenericProvider(t *testing.T) {
+	key, err := crypto.GenerateKeyBase64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	generic := config.GenericOAuthConfig{
+		Name:            "acme",
+		DisplayName:     "Acme Cloud",
+		AuthURL:         "https://auth.acme.example/authorize",
+		TokenURL:        "https://auth.acme.example/token",
+		Scopes:          []string{"compute"},
+		ClientID:        "client",
+		CredentialField: "contabo_api_password",
+		Enabled:         true,
+	}
+	store := &fakeOAuthStore{creds: map[uuid.UUID]*models.CloudCredential{}}
+
+	svc, err := NewOAuthService(store, config.OAuthConfig{EncryptionKey: key, Generic: generic})
+	if err != nil {
+		t.Fatalf("NewOAuthService() error = %v", err)
+	}
+	providers := svc.GetOAuthProviders().Providers
+	if len(providers) != 1 || providers[0].Provider != "acme" || providers[0].Name != "Acme Cloud" || !providers[0].Enabled {
+		t.Errorf("GetOAuthProviders() = %+v, want the generic provider", providers)
+	}
+	creds, err := svc.buildOAuthCredentials("acme", "token")
+	if err != nil || creds.ContaboAPIPassword != "token" {
+		t.Errorf("buildOAuthCredentials() = %+v, %v, want the token in the configured field", creds, err)
+	}
+
+	for name, mutate := range map[string]func(*config.GenericOAuthConfig){
+		"missing token URL": func(c *config.GenericOAuthConfig) { c.TokenURL = "" },
+		"relative auth URL": func(c *config.GenericOAuthConfig) { c.AuthURL = "/authorize" },
+		"unknown field":     func(c *config.GenericOAuthConfig) { c.CredentialField = "password" },
+		"built-in name":     func(c *config.GenericOAuthConfig) { c.Name = "hetzner" },
+	} {
+		cfg := config.OAuthConfig{EncryptionKey: key, Generic: generic}
+		cfg.Hetzner = config.HetznerOAuthConfig{ClientID: "hetzner-client"}
+		mutate(&cfg.Generic)
+		if _, err := NewOAuthService(store, cfg); err == nil {
+			t.Errorf("NewOAuthService() with %s error = nil, want an error", name)
+		}
+	}
+}
+
+func TestOAuthValidateRedirectURI(t *testing.T) {
+	svc := &OAuthService{config: config.OAuthConfig{
+		BaseURL:              "https://philotes.example.com",
+		AllowedRedirectHosts: []string{"app.example.com"},
+	}}
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{"base URL host", "https://philotes.example.com/installer", false},
+		{"allowed host", "https://app.example.com/callback", false},
+		{"localhost", "http://localhost:3000/callback", false},
+		{"malformed URL", "http://[::1", true},
+		{"javascript scheme", "javascript:alert(1)", true},
+		{"no host", "https:///installer", true},
+		{"disallowed host", "https://evil.example.com/callback", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.validateRedirectURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRedirectURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -27,14 +27,28 @@ var (
 	ErrOIDCCallbackFailed     = errors.New("oidc callback failed")
 	ErrOIDCUserCreationFailed = errors.New("failed to create oidc user")
 	ErrOIDCDiscoveryFailed    = errors.New("oidc discovery failed")
+	ErrOIDCLogoutTokenInvalid = errors.New("invalid oidc logout token")
 )
 
+// TenantMembershipManager manages the tenant memberships of OIDC users.
+type TenantMembershipManager interface {
+	GetMemberRole(ctx context.Context, tenantID, userID uuid.UUID) (models.TenantRole, error)
+	AddMember(ctx context.Context, tenantID uuid.UUID, req *models.AddMemberRequest) (*models.TenantMember, error)
+	UpdateMember(ctx context.Context, tenantID, userID uuid.UUID, req *models.UpdateMemberRequest) (*models.TenantMember, error)
+	RemoveMember(ctx context.Context, tenantID, userID uuid.UUID) error
+}
+
+var _ TenantMembershipManager = (*TenantService)(nil)
+
 // OIDCService provides OIDC authentication business logic.
 type OIDCService struct {
 	oidcRepo         *repositories.OIDCRepository
 	userRepo         *repositories.UserRepository
 	auditRepo        *repositories.AuditRepository
 	providerRegistry *providers.Registry
+	discoveryCache   *oidc.Cache
+	revocation       *TokenRevocationService
+	tenants          TenantMembershipManager
 	oidcCfg          *config.OIDCConfig
 	authCfg          *config.AuthConfig
 	baseURL          string
@@ -56,6 +70,7 @@ func NewOIDCService(
 		userRepo:         userRepo,
 		auditRepo:        auditRepo,
 		providerRegistry: providers.NewRegistry(),
+		discoveryCache:   oidc.NewCache(oidcCfg.DiscoveryCacheTTL, logger),
 		oidcCfg:          oidcCfg,
 		authCfg:          authCfg,
 		baseURL:          baseURL,
@@ -63,6 +78,42 @@ func NewOIDCService(
 	}
 }
 
+// SetTokenRevocationService sets the service tokens are revoked through on
+// back-channel logout. Without one back-channel logout is not supported.
+func (s *OIDCService) SetTokenRevocationService(revocation *TokenRevocationService) {
+	s.revocation = revocation
+}
+
+// SetTenantService sets the service tenant memberships are assigned
+// through from the providers' group to tenant mappings. Without one the
+// mappings are ignored.
+func (s *OIDCService) SetTenantService(tenants TenantMembershipManager) {
+	s.tenants = tenants
+}
+
+// discoveryRefreshInterval is how often cached discovery documents and key
+// sets are checked for refresh.
+const discoveryRefreshInterval = time.Minute
+
+// Start starts refreshing cached provider discovery documents and key sets
+// before they expire.
+func (s *OIDCService) Start(ctx context.Context) {
+	s.discoveryCache.Start(ctx, discoveryRefreshInterval)
+}
+
+// Stop stops the background refresh.
+func (s *OIDCService) Stop() {
+	s.discoveryCache.Stop()
+}
+
+// newClient creates an OIDC client for a provider that reads discovery
+// documents through the cache.
+func (s *OIDCService) newClient(provider *models.OIDCProvider) *oidc.Client {
+	client := oidc.NewClient(provider.IssuerURL, provider.ClientID, provider.Scopes)
+	client.SetCache(s.discoveryCache)
+	return client
+}
+
 // --- Public Endpoints ---
 
 // ListEnabledProviders returns all enabled OIDC providers.
@@ -141,7 +192,7 @@ func (s *OIDCService) StartAuthorization(ctx context.Context, providerName, redi
 	callbackURL := fmt.Sprintf("%s/api/v1/auth/oidc/callback", s.baseURL)
 
 	// Create OIDC client and get authorization URL
-	client := oidc.NewClient(provider.IssuerURL, provider.ClientID, provider.Scopes)
+	client := s.newClient(provider)
 	authURL, err := client.AuthorizationURL(ctx, state, nonce, codeChallenge, callbackURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build authorization URL: %w", err)
@@ -202,7 +253,7 @@ func (s *OIDCService) HandleCallback(ctx context.Context, code, state, ipAddress
 	callbackURL := fmt.Sprintf("%s/api/v1/auth/oidc/callback", s.baseURL)
 
 	// Exchange code for tokens
-	client := oidc.NewClient(provider.IssuerURL, provider.ClientID, provider.Scopes)
+	client := s.newClient(provider)
 	tokenResp, err := client.Exchange(ctx, code, oidcState.CodeVerifier, callbackURL, clientSecret)
 	if err != nil {
 		s.logger.Error("token exchange failed", "error", err, "provider", provider.Name)
@@ -242,7 +293,7 @@ func (s *OIDCService) HandleCallback(ctx context.Context, code, state, ipAddress
 	}
 
 	// Provision or update user
-	user, err := s.provisionUser(ctx, provider, userInfo)
+	user, err := s.provisionUser(ctx, provider, userInfo, ipAddress, userAgent)
 	if err != nil {
 		s.logger.Error("user provisioning failed", "error", err, "provider", provider.Name, "subject", claims.Subject)
 		return &models.OIDCCallbackResponse{
@@ -254,7 +305,11 @@ func (s *OIDCService) HandleCallback(ctx context.Context, code, state, ipAddress
 
 	// Generate JWT token
 	expiresAt := time.Now().Add(s.authCfg.JWTExpiration)
-	token, err := s.generateJWT(user, expiresAt)
+	var sessionID string
+	if claims.SessionID != "" {
+		sessionID = providerSessionID(provider.ID, claims.SessionID)
+	}
+	token, err := s.generateJWT(user, expiresAt, sessionID)
 	if err != nil {
 		return &models.OIDCCallbackResponse{
 			Success:     false,
@@ -284,6 +339,70 @@ func (s *OIDCService) HandleCallback(ctx context.Context, code, state, ipAddress
 	}, nil
 }
 
+// BackChannelLogout ends the sessions named by a provider's back-channel
+// logout token: the tokens issued for its session ID, and every token of
+// the user with its subject.
+func (s *OIDCService) BackChannelLogout(ctx context.Context, providerName, logoutToken, ipAddress, userAgent string) error {
+	if s.revocation == nil {
+		return ErrLogoutUnavailable
+	}
+
+	provider, err := s.oidcRepo.GetProviderByName(ctx, providerName)
+	if err != nil {
+		if errors.Is(err, repositories.ErrOIDCProviderNotFound) {
+			return ErrOIDCProviderNotFound
+		}
+		return fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	if !provider.Enabled {
+		return ErrOIDCProviderDisabled
+	}
+
+	client := s.newClient(provider)
+	claims, err := client.ParseLogoutToken(ctx, logoutToken)
+	if err != nil {
+		s.logger.Warn("logout token validation failed", "error", err, "provider", provider.Name)
+		if errors.Is(err, oidc.ErrInvalidLogoutToken) {
+			return ErrOIDCLogoutTokenInvalid
+		}
+		return fmt.Errorf("failed to validate logout token: %w", err)
+	}
+
+	if claims.SessionID != "" {
+		if err := s.revocation.RevokeSession(ctx, providerSessionID(provider.ID, claims.SessionID)); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	var userID *uuid.UUID
+	if claims.Subject != "" {
+		user, err := s.userRepo.GetByOIDCSubject(ctx, provider.ID, claims.Subject)
+		switch {
+		case err == nil:
+			if err := s.revocation.RevokeUser(ctx, user.ID); err != nil {
+				return fmt.Errorf("failed to revoke user tokens: %w", err)
+			}
+			userID = &user.ID
+		case !errors.Is(err, repositories.ErrUserNotFound):
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	s.logAuditEvent(ctx, userID, nil, models.AuditActionLogout, ipAddress, userAgent, map[string]interface{}{
+		"method":   "oidc_backchannel",
+		"provider": provider.Name,
+	})
+
+	s.logger.Info("OIDC back-channel logout",
+		"provider", provider.Name,
+		"subject", claims.Subject,
+		"session", claims.SessionID != "",
+	)
+
+	return nil
+}
+
 // --- Admin Endpoints ---
 
 // CreateProvider creates a new OIDC provider.
@@ -390,7 +509,7 @@ func (s *OIDCService) TestProvider(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to get provider: %w", err)
 	}
 
-	// Test OIDC discovery
+	// Test OIDC discovery against the provider itself, not the cache
 	client := oidc.NewClient(provider.IssuerURL, provider.ClientID, provider.Scopes)
 	config, err := client.Discover(ctx)
 	if err != nil {
@@ -406,6 +525,48 @@ func (s *OIDCService) TestProvider(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// RotateSCIMToken generates a new SCIM bearer token for a provider,
+// replacing its previous one. The token is returned only this once.
+func (s *OIDCService) RotateSCIMToken(ctx context.Context, id uuid.UUID, userID *uuid.UUID, ipAddress, userAgent string) (*models.SCIMTokenResponse, error) {
+	token, tokenHash, err := generateSCIMToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.oidcRepo.SetSCIMTokenHash(ctx, id, tokenHash); err != nil {
+		if errors.Is(err, repositories.ErrOIDCProviderNotFound) {
+			return nil, &NotFoundError{Resource: "oidc_provider", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to set scim token: %w", err)
+	}
+
+	s.logAuditEvent(ctx, userID, nil, models.AuditActionSCIMTokenRotated, ipAddress, userAgent, map[string]interface{}{
+		"provider_id": id,
+	})
+	s.logger.Info("SCIM token rotated", "provider_id", id)
+
+	return &models.SCIMTokenResponse{Token: token}, nil
+}
+
+// DisableSCIM removes a provider's SCIM bearer token. Users and groups
+// provisioned before are kept.
+func (s *OIDCService) DisableSCIM(ctx context.Context, id uuid.UUID, userID *uuid.UUID, ipAddress, userAgent string) error {
+	if err := s.oidcRepo.SetSCIMTokenHash(ctx, id, ""); err != nil {
+		if errors.Is(err, repositories.ErrOIDCProviderNotFound) {
+			return &NotFoundError{Resource: "oidc_provider", ID: id.String()}
+		}
+		return fmt.Errorf("failed to remove scim token: %w", err)
+	}
+
+	s.logAuditEvent(ctx, userID, nil, models.AuditActionSCIMTokenRotated, ipAddress, userAgent, map[string]interface{}{
+		"provider_id": id,
+		"disabled":    true,
+	})
+	s.logger.Info("SCIM disabled", "provider_id", id)
+
+	return nil
+}
+
 // CleanupExpiredStates removes expired OIDC states.
 func (s *OIDCService) CleanupExpiredStates(ctx context.Context) (int64, error) {
 	count, err := s.oidcRepo.CleanupExpiredStates(ctx)
@@ -420,8 +581,22 @@ func (s *OIDCService) CleanupExpiredStates(ctx context.Context) (int64, error) {
 
 // --- Helper Methods ---
 
-// provisionUser creates or updates a user based on OIDC claims.
-func (s *OIDCService) provisionUser(ctx context.Context, provider *models.OIDCProvider, userInfo *models.OIDCUserInfo) (*models.User, error) {
+// provisionUser creates or updates a user based on OIDC claims, and
+// assigns the tenant memberships their groups are mapped to.
+func (s *OIDCService) provisionUser(ctx context.Context, provider *models.OIDCProvider, userInfo *models.OIDCUserInfo, ipAddress, userAgent string) (*models.User, error) {
+	user, err := s.findOrCreateUser(ctx, provider, userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncTenantMemberships(ctx, provider, user.ID, userInfo.Groups, ipAddress, userAgent)
+
+	return user, nil
+}
+
+// findOrCreateUser finds the user of OIDC claims, by subject or else by
+// email, or creates one.
+func (s *OIDCService) findOrCreateUser(ctx context.Context, provider *models.OIDCProvider, userInfo *models.OIDCUserInfo) (*models.User, error) {
 	// Try to find existing user by OIDC subject
 	existingUser, err := s.userRepo.GetByOIDCSubject(ctx, provider.ID, userInfo.Subject)
 	if err == nil {
@@ -498,6 +673,27 @@ func (s *OIDCService) provisionUser(ctx context.Context, provider *models.OIDCPr
 	return user, nil
 }
 
+// ApplyGroups sets the IdP groups of a user of the provider and brings
+// their role and tenant memberships in line with them, as a login with
+// those groups would.
+func (s *OIDCService) ApplyGroups(ctx context.Context, provider *models.OIDCProvider, user *models.User, groups []string, ipAddress, userAgent string) error {
+	if err := s.userRepo.UpdateOIDCGroups(ctx, user.ID, groups); err != nil {
+		return fmt.Errorf("failed to update oidc groups: %w", err)
+	}
+	user.OIDCGroups = groups
+
+	if role := s.mapGroupsToRole(provider, groups); role != user.Role {
+		if _, err := s.userRepo.Update(ctx, user.ID, &models.UpdateUserRequest{Role: &role}); err != nil {
+			return fmt.Errorf("failed to update role from groups: %w", err)
+		}
+		user.Role = role
+	}
+
+	s.syncTenantMemberships(ctx, provider, user.ID, groups, ipAddress, userAgent)
+
+	return nil
+}
+
 // mapGroupsToRole maps IdP groups to a Philotes role.
 func (s *OIDCService) mapGroupsToRole(provider *models.OIDCProvider, groups []string) models.UserRole {
 	// Check role mapping
@@ -509,6 +705,89 @@ func (s *OIDCService) mapGroupsToRole(provider *models.OIDCProvider, groups []st
 	return provider.DefaultRole
 }
 
+// tenantRoleRank orders the tenant roles groups can be mapped to, so a
+// user in several groups mapped to one tenant gets the highest role.
+var tenantRoleRank = map[models.TenantRole]int{
+	models.TenantRoleViewer:   1,
+	models.TenantRoleOperator: 2,
+	models.TenantRoleAdmin:    3,
+}
+
+// mapGroupsToTenants maps IdP groups to the tenant roles they grant.
+func mapGroupsToTenants(provider *models.OIDCProvider, groups []string) map[uuid.UUID]models.TenantRole {
+	inGroup := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		inGroup[group] = true
+	}
+
+	roles := make(map[uuid.UUID]models.TenantRole)
+	for _, m := range provider.TenantMapping {
+		if inGroup[m.Group] && tenantRoleRank[m.Role] > tenantRoleRank[roles[m.TenantID]] {
+			roles[m.TenantID] = m.Role
+		}
+	}
+	return roles
+}
+
+// syncTenantMemberships brings a user's memberships of the provider's
+// mapped tenants in line with their groups: memberships are added and
+// their roles updated, and with RemoveUnmappedMemberships set, memberships
+// no group grants any more are removed. Tenants the provider does not map
+// are left alone. Failures are logged and do not fail the login.
+func (s *OIDCService) syncTenantMemberships(ctx context.Context, provider *models.OIDCProvider, userID uuid.UUID, groups []string, ipAddress, userAgent string) {
+	if s.tenants == nil || len(provider.TenantMapping) == 0 {
+		return
+	}
+
+	granted := mapGroupsToTenants(provider, groups)
+	seen := make(map[uuid.UUID]bool)
+	for _, m := range provider.TenantMapping {
+		tenantID := m.TenantID
+		if seen[tenantID] {
+			continue
+		}
+		seen[tenantID] = true
+
+		current, err := s.tenants.GetMemberRole(ctx, tenantID, userID)
+		var notFound *NotFoundError
+		isMember := err == nil
+		if err != nil && !errors.As(err, &notFound) {
+			s.logger.Warn("failed to get tenant membership", "tenant_id", tenantID, "user_id", userID, "error", err)
+			continue
+		}
+
+		role, ok := granted[tenantID]
+		details := map[string]interface{}{
+			"provider": provider.Name,
+			"role":     role,
+		}
+		switch {
+		case ok && !isMember:
+			if _, err := s.tenants.AddMember(ctx, tenantID, &models.AddMemberRequest{UserID: userID, Role: role}); err != nil {
+				s.logger.Warn("failed to add tenant member from groups", "tenant_id", tenantID, "user_id", userID, "error", err)
+				continue
+			}
+			s.logMembershipChange(ctx, tenantID, userID, models.AuditActionTenantMemberAdded, ipAddress, userAgent, details)
+
+		case ok && current != role:
+			if _, err := s.tenants.UpdateMember(ctx, tenantID, userID, &models.UpdateMemberRequest{Role: &role}); err != nil {
+				s.logger.Warn("failed to update tenant member from groups", "tenant_id", tenantID, "user_id", userID, "error", err)
+				continue
+			}
+			details["previous_role"] = current
+			s.logMembershipChange(ctx, tenantID, userID, models.AuditActionTenantMemberUpdated, ipAddress, userAgent, details)
+
+		case !ok && isMember && provider.RemoveUnmappedMemberships:
+			if err := s.tenants.RemoveMember(ctx, tenantID, userID); err != nil {
+				s.logger.Warn("failed to remove tenant member from groups", "tenant_id", tenantID, "user_id", userID, "error", err)
+				continue
+			}
+			details["role"] = current
+			s.logMembershipChange(ctx, tenantID, userID, models.AuditActionTenantMemberRemoved, ipAddress, userAgent, details)
+		}
+	}
+}
+
 // validateRedirectURI validates that the redirect URI is allowed.
 func (s *OIDCService) validateRedirectURI(redirectURI string) error {
 	parsed, err := url.Parse(redirectURI)
@@ -530,19 +809,34 @@ func (s *OIDCService) validateRedirectURI(redirectURI string) error {
 	}
 
 	// Allow same host as base URL
-	if baseURL, err := url.Parse(s.baseURL); err == nil && parsed.Host == baseURL.Host {
+	if baseURL, err := url.Parse(s.baseURL); err == nil && baseURL.Host != "" && parsed.Host == baseURL.Host {
 		return nil
 	}
 
-	return nil
+	// Allow explicitly configured hosts
+	for _, host := range s.oidcCfg.AllowedRedirectHosts {
+		if parsed.Host == host {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("host %q is not in the allowed redirect hosts", parsed.Host)
+}
+
+// providerSessionID qualifies an identity provider session ID with its
+// provider, since session IDs are only unique per provider.
+func providerSessionID(providerID uuid.UUID, sessionID string) string {
+	return providerID.String() + "/" + sessionID
 }
 
-// generateJWT generates a JWT token for a user.
-func (s *OIDCService) generateJWT(user *models.User, expiresAt time.Time) (string, error) {
+// generateJWT generates a JWT token for a user, issued for the given
+// provider session.
+func (s *OIDCService) generateJWT(user *models.User, expiresAt time.Time, sessionID string) (string, error) {
 	permissions := models.RolePermissions[user.Role]
 
 	claims := &models.JWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   user.ID.String(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -553,6 +847,7 @@ func (s *OIDCService) generateJWT(user *models.User, expiresAt time.Time) (strin
 		Email:       user.Email,
 		Role:        user.Role,
 		Permissions: permissions,
+		SessionID:   sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -566,24 +861,42 @@ func (s *OIDCService) generateJWT(user *models.User, expiresAt time.Time) (strin
 
 // logAuditEvent logs an audit event asynchronously.
 func (s *OIDCService) logAuditEvent(ctx context.Context, userID, apiKeyID *uuid.UUID, action, ipAddress, userAgent string, details map[string]interface{}) {
-	if s.auditRepo == nil {
-		return
-	}
-
-	log := &models.AuditLog{
+	s.writeAuditLog(&models.AuditLog{
 		UserID:    userID,
 		APIKeyID:  apiKeyID,
 		Action:    action,
 		IPAddress: ipAddress,
 		UserAgent: userAgent,
 		Details:   details,
+	})
+}
+
+// logMembershipChange logs a tenant membership change made from a user's
+// groups asynchronously.
+func (s *OIDCService) logMembershipChange(ctx context.Context, tenantID, userID uuid.UUID, action, ipAddress, userAgent string, details map[string]interface{}) {
+	s.writeAuditLog(&models.AuditLog{
+		UserID:       &userID,
+		TenantID:     &tenantID,
+		Action:       action,
+		ResourceType: "tenant_member",
+		ResourceID:   &userID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		Details:      details,
+	})
+}
+
+// writeAuditLog writes an audit log entry in the background.
+func (s *OIDCService) writeAuditLog(log *models.AuditLog) {
+	if s.auditRepo == nil {
+		return
 	}
 
 	go func() {
 		auditCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := s.auditRepo.Create(auditCtx, log); err != nil {
-			s.logger.Warn("failed to create audit log", "action", action, "error", err)
+			s.logger.Warn("failed to create audit log", "action", log.Action, "error", err)
 		}
 	}()
 }
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+func TestOIDCValidateRedirectURI(t *testing.T) {
+	svc := NewOIDCService(nil, nil, nil,
+		&config.OIDCConfig{AllowedRedirectHosts: []string{"app.example.com", "admin.example.com:8443"}},
+		&config.AuthConfig{},
+		"https://philotes.example.com",
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{"base URL host", "https://philotes.example.com/dashboard", false},
+		{"allowed host", "https://app.example.com/callback", false},
+		{"allowed host and port", "https://admin.example.com:8443/", false},
+		{"localhost", "http://localhost:3000/callback", false},
+		{"loopback", "http://127.0.0.1:5173/", false},
+		{"malformed URL", "http://[::1", true},
+		{"relative URL", "/dashboard", true},
+		{"javascript scheme", "javascript:alert(1)", true},
+		{"ftp scheme", "ftp://philotes.example.com/", true},
+		{"no host", "https:///dashboard", true},
+		{"disallowed host", "https://evil.example.com/callback", true},
+		{"allowed host on another port", "https://app.example.com:8443/", true},
+		{"base host as subdomain", "https://philotes.example.com.evil.com/", true},
+		{"userinfo trick", "https://philotes.example.com@evil.com/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.validateRedirectURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRedirectURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeTenantMemberships keeps one user's tenant roles in memory.
+type fakeTenantMemberships struct {
+	roles map[uuid.UUID]models.TenantRole
+	fail  map[uuid.UUID]error
+}
+
+func (f *fakeTenantMemberships) GetMemberRole(_ context.Context, tenantID, userID uuid.UUID) (models.TenantRole, error) {
+	role, ok := f.roles[tenantID]
+	if !ok {
+		return "", &NotFoundError{Resource: "member", ID: userID.String()}
+	}
+	return role, nil
+}
+
+func (f *fakeTenantMemberships) AddMember(_ context.Context, tenantID uuid.UUID, req *models.AddMemberRequest) (*models.TenantMember, error) {
+	f.roles[tenantID] = req.Role
+	return &models.TenantMember{TenantID: tenantID, UserID: req.UserID, Role: req.Role}, nil
+}
+
+func (f *fakeTenantMemberships) UpdateMember(_ context.Context, tenantID, userID uuid.UUID, req *models.UpdateMemberRequest) (*models.TenantMember, error) {
+	f.roles[tenantID] = *req.Role
+	return &models.TenantMember{TenantID: tenantID, UserID: userID, Role: *req.Role}, nil
+}
+
+func (f *fakeTenantMemberships) RemoveMember(_ context.Context, tenantID, _ uuid.UUID) error {
+	if err := f.fail[tenantID]; err != nil {
+		return err
+	}
+	delete(f.roles, tenantID)
+	return nil
+}
+
+func TestOIDCSyncTenantMemberships(t *testing.T) {
+	engineering, analytics, finance, unmapped := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	provider := &models.OIDCProvider{
+		Name: "okta",
+		TenantMapping: []models.OIDCTenantMapping{
+			{Group: "engineers", TenantID: engineering, Role: models.TenantRoleViewer},
+			{Group: "engineering-leads", TenantID: engineering, Role: models.TenantRoleAdmin},
+			{Group: "analysts", TenantID: analytics, Role: models.TenantRoleOperator},
+			{Group: "accountants", TenantID: finance, Role: models.TenantRoleViewer},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		removeUnmapped bool
+		groups         []string
+		before         map[uuid.UUID]models.TenantRole
+		want           map[uuid.UUID]models.TenantRole
+	}{
+		{
+			name:   "adds memberships with the highest mapped role",
+			groups: []string{"engineers", "engineering-leads", "analysts"},
+			before: map[uuid.UUID]models.TenantRole{},
+			want:   map[uuid.UUID]models.TenantRole{engineering: models.TenantRoleAdmin, analytics: models.TenantRoleOperator},
+		},
+		{
+			name:   "updates the role of existing memberships",
+			groups: []string{"engineers"},
+			before: map[uuid.UUID]models.TenantRole{engineering: models.TenantRoleAdmin},
+			want:   map[uuid.UUID]models.TenantRole{engineering: models.TenantRoleViewer},
+		},
+		{
+			name:   "keeps memberships without removal enabled",
+			groups: []string{"analysts"},
+			before: map[uuid.UUID]models.TenantRole{finance: models.TenantRoleViewer, unmapped: models.TenantRoleAdmin},
+			want: map[uuid.UUID]models.TenantRole{
+				analytics: models.TenantRoleOperator, finance: models.TenantRoleViewer, unmapped: models.TenantRoleAdmin,
+			},
+		},
+		{
+			name:           "removes memberships of mapped tenants only",
+			removeUnmapped: true,
+			groups:         []string{"analysts"},
+			before:         map[uuid.UUID]models.TenantRole{finance: models.TenantRoleViewer, unmapped: models.TenantRoleAdmin},
+			want:           map[uuid.UUID]models.TenantRole{analytics: models.TenantRoleOperator, unmapped: models.TenantRoleAdmin},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenants := &fakeTenantMemberships{roles: tt.before}
+			svc := NewOIDCService(nil, nil, nil, &config.OIDCConfig{}, &config.AuthConfig{}, "",
+				slog.New(slog.NewTextHandler(io.Discard, nil)))
+			svc.SetTenantService(tenants)
+
+			p := *provider
+			p.RemoveUnmappedMemberships = tt.removeUnmapped
+			svc.syncTenantMemberships(context.Background(), &p, uuid.New(), tt.groups, "", "")
+
+			if len(tenants.roles) != len(tt.want) {
+				t.Fatalf("memberships = %v, want %v", tenants.roles, tt.want)
+			}
+			for tenantID, role := range tt.want {
+				if tenants.roles[tenantID] != role {
+					t.Errorf("role in tenant %s = %q, want %q", tenantID, tenants.roles[tenantID], role)
+				}
+			}
+		})
+	}
+}
+
+func TestOIDCSyncTenantMemberships_ContinuesAfterFailure(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	tenants := &fakeTenantMemberships{
+		roles: map[uuid.UUID]models.TenantRole{first: models.TenantRoleAdmin, second: models.TenantRoleViewer},
+		fail:  map[uuid.UUID]error{first: errors.New("cannot remove the last admin")},
+	}
+	svc := NewOIDCService(nil, nil, nil, &config.OIDCConfig{}, &config.AuthConfig{}, "",
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.SetTenantService(tenants)
+
+	provider := &models.OIDCProvider{
+		TenantMapping: []models.OIDCTenantMapping{
+			{Group: "a", TenantID: first, Role: models.TenantRoleAdmin},
+			{Group: "b", TenantID: second, Role: models.TenantRoleViewer},
+		},
+		RemoveUnmappedMemberships: true,
+	}
+	svc.syncTenantMemberships(context.Background(), provider, uuid.New(), nil, "", "")
+
+	if _, ok := tenants.roles[first]; !ok {
+		t.Error("membership removed despite the failure")
+	}
+	if _, ok := tenants.roles[second]; ok {
+		t.Error("membership of the second tenant was not removed after the first failed")
+	}
+}
@@ -69,17 +69,7 @@ func (s *OnboardingService) GetClusterHealth(ctx context.Context) *models.Cluste
 
 	// Convert component health
 	for name, result := range status.Components {
-		componentHealth := models.ComponentHealth{
-			Name:       result.Name,
-			Status:     string(result.Status),
-			Message:    result.Message,
-			DurationMs: result.Duration.Milliseconds(),
-			LastCheck:  result.LastCheck,
-		}
-		if result.Error != "" {
-			componentHealth.Error = result.Error
-		}
-		response.Components[name] = componentHealth
+		response.Components[name] = models.NewComponentHealth(result)
 
 		// Set specific ready flags based on component names
 		switch name {
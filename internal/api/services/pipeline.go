@@ -18,9 +18,16 @@ import (
 type PipelineService struct {
 	repo       *repositories.PipelineRepository
 	sourceRepo *repositories.SourceRepository
+	validator  SourceValidator
 	logger     *slog.Logger
 }
 
+// SourceValidator checks that a source can replicate tables, given as
+// "schema.table".
+type SourceValidator interface {
+	Validate(ctx context.Context, id uuid.UUID, tables []string) (*models.SourceValidationResult, error)
+}
+
 // NewPipelineService creates a new PipelineService.
 func NewPipelineService(
 	repo *repositories.PipelineRepository,
@@ -34,6 +41,12 @@ func NewPipelineService(
 	}
 }
 
+// SetSourceValidator makes Create check that the source can replicate the
+// pipeline's tables before creating it.
+func (s *PipelineService) SetSourceValidator(v SourceValidator) {
+	s.validator = v
+}
+
 // Create creates a new pipeline.
 func (s *PipelineService) Create(ctx context.Context, req *models.CreatePipelineRequest) (*models.Pipeline, error) {
 	// Validate request
@@ -53,6 +66,21 @@ func (s *PipelineService) Create(ctx context.Context, req *models.CreatePipeline
 		return nil, fmt.Errorf("failed to verify source: %w", err)
 	}
 
+	// Refuse pipelines the source cannot replicate
+	if s.validator != nil {
+		tables := make([]string, len(req.Tables))
+		for i, t := range req.Tables {
+			tables[i] = t.Schema + "." + t.Table
+		}
+		result, err := s.validator.Validate(ctx, req.SourceID, tables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate source: %w", err)
+		}
+		if !result.Valid {
+			return nil, &ValidationError{Errors: result.Errors}
+		}
+	}
+
 	// Create pipeline
 	pipeline, err := s.repo.Create(ctx, req)
 	if err != nil {
@@ -215,6 +243,56 @@ func (s *PipelineService) Stop(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// Pause pauses a running pipeline. Its worker picks up the paused status,
+// stops consuming changes and keeps the replication slot until it is
+// resumed, also across restarts.
+func (s *PipelineService) Pause(ctx context.Context, id uuid.UUID) error {
+	pipeline, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPipelineNotFound) {
+			return &NotFoundError{Resource: "pipeline", ID: id.String()}
+		}
+		return fmt.Errorf("failed to get pipeline: %w", err)
+	}
+
+	switch pipeline.Status {
+	case models.PipelineStatusPaused:
+		return &ConflictError{Message: "pipeline is already paused"}
+	case models.PipelineStatusRunning, models.PipelineStatusStarting:
+	default:
+		return &ConflictError{Message: "pipeline is not running"}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, models.PipelineStatusPaused, ""); err != nil {
+		return fmt.Errorf("failed to update pipeline status: %w", err)
+	}
+
+	s.logger.Info("pipeline paused", "id", id, "name", pipeline.Name)
+	return nil
+}
+
+// Resume resumes a paused pipeline.
+func (s *PipelineService) Resume(ctx context.Context, id uuid.UUID) error {
+	pipeline, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPipelineNotFound) {
+			return &NotFoundError{Resource: "pipeline", ID: id.String()}
+		}
+		return fmt.Errorf("failed to get pipeline: %w", err)
+	}
+
+	if pipeline.Status != models.PipelineStatusPaused {
+		return &ConflictError{Message: "pipeline is not paused"}
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, models.PipelineStatusRunning, ""); err != nil {
+		return fmt.Errorf("failed to update pipeline status: %w", err)
+	}
+
+	s.logger.Info("pipeline resumed", "id", id, "name", pipeline.Name)
+	return nil
+}
+
 // GetStatus gets the status of a pipeline.
 func (s *PipelineService) GetStatus(ctx context.Context, id uuid.UUID) (*models.PipelineStatusResponse, error) {
 	pipeline, err := s.repo.GetByID(ctx, id)
@@ -244,6 +322,27 @@ func (s *PipelineService) GetStatus(ctx context.Context, id uuid.UUID) (*models.
 	return status, nil
 }
 
+// GetBufferStats gets the buffer stats of a pipeline, as its worker
+// recorded them after its last buffer cleanup.
+func (s *PipelineService) GetBufferStats(ctx context.Context, id uuid.UUID) (*models.PipelineBufferStats, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrPipelineNotFound) {
+			return nil, &NotFoundError{Resource: "pipeline", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to get pipeline: %w", err)
+	}
+
+	stats, err := s.repo.GetBufferStats(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buffer stats: %w", err)
+	}
+
+	if stats.OldestUnprocessed != nil && stats.LastCleanupAt != nil {
+		stats.OldestUnprocessedAgeSeconds = max(stats.LastCleanupAt.Sub(*stats.OldestUnprocessed).Seconds(), 0)
+	}
+	return stats, nil
+}
+
 // AddTableMapping adds a table mapping to a pipeline.
 func (s *PipelineService) AddTableMapping(ctx context.Context, pipelineID uuid.UUID, req *models.AddTableMappingRequest) (*models.TableMapping, error) {
 	// Validate request
@@ -206,6 +206,7 @@ func TestPipelineStatus(t *testing.T) {
 		{models.PipelineStatusStopped, "stopped"},
 		{models.PipelineStatusStarting, "starting"},
 		{models.PipelineStatusRunning, "running"},
+		{models.PipelineStatusPaused, "paused"},
 		{models.PipelineStatusStopping, "stopping"},
 		{models.PipelineStatusError, "error"},
 	}
@@ -4,26 +4,49 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/cdc/health"
 	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/query"
+	"github.com/janovincze/philotes/internal/query/trino"
+)
+
+var (
+	// ErrStatementNotPermitted is returned for a statement other than a
+	// query from a caller without the query:write permission.
+	ErrStatementNotPermitted = errors.New("only queries may be executed without the query:write permission")
+
+	// ErrSchemaAccessDenied is returned when a statement references a
+	// catalog or schema outside the caller's tenant.
+	ErrSchemaAccessDenied = errors.New("schema access denied")
 )
 
 // identifierRegex validates SQL identifiers (catalog, schema, table names).
 // Only allows alphanumeric characters and underscores to prevent SQL injection.
 var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
-// QueryService provides operations for the Trino query layer.
+// QueryService provides operations for the Trino query layer and runs
+// statements on the configured query engine.
 type QueryService struct {
-	cfg        config.TrinoConfig
-	httpClient *http.Client
-	logger     *slog.Logger
+	cfg           config.TrinoConfig
+	httpClient    *http.Client
+	client        *trino.Client
+	engine        query.Engine
+	maxResultRows int
+	cache         *query.Cache
+	snapshots     query.SnapshotSource
+	logger        *slog.Logger
 }
 
 // NewQueryService creates a new QueryService.
@@ -32,15 +55,55 @@ func NewQueryService(cfg config.TrinoConfig, logger *slog.Logger) *QueryService
 		logger = slog.Default()
 	}
 
+	client := trino.NewClient(cfg, logger)
+
 	return &QueryService{
 		cfg: cfg,
 		httpClient: &http.Client{
 			Timeout: cfg.QueryTimeout,
 		},
+		client: client,
+		engine: client,
 		logger: logger.With("component", "query-service"),
 	}
 }
 
+// SetEngine sets the engine statements are executed on, replacing Trino.
+// The catalog and cluster operations keep using Trino.
+func (s *QueryService) SetEngine(engine query.Engine) {
+	s.engine = engine
+}
+
+// SetMaxResultRows caps the rows returned for a statement (0 = unlimited).
+func (s *QueryService) SetMaxResultRows(n int) {
+	s.maxResultRows = n
+}
+
+// SetCache sets the cache that results of queries are kept in. Results are
+// only cached when MaxResultRows is set, as they are held in memory.
+func (s *QueryService) SetCache(cache *query.Cache) {
+	s.cache = cache
+}
+
+// SetSnapshotSource sets the source of the table snapshots that are part of
+// cache keys, so that a cached result is not served once a table it reads
+// has a new snapshot. Without one, cached results are only bounded by the
+// cache TTL.
+func (s *QueryService) SetSnapshotSource(source query.SnapshotSource) {
+	s.snapshots = source
+}
+
+// MaxResultRows returns the maximum number of rows returned for a statement,
+// or 0 when unlimited.
+func (s *QueryService) MaxResultRows() int {
+	return s.maxResultRows
+}
+
+// EngineName returns the name of the engine statements are executed on.
+func (s *QueryService) EngineName() string {
+	return s.engine.Name()
+}
+
 // validateIdentifier validates a SQL identifier to prevent SQL injection.
 func validateIdentifier(name, identifierType string) error {
 	if name == "" {
@@ -264,6 +327,88 @@ func (s *QueryService) GetTableInfo(ctx context.Context, catalog, schema, table
 	}, nil
 }
 
+// Execute submits a SQL statement to the query engine and returns its
+// running result, which the caller must close. Statements other than queries require
+// canWrite. For a tenant-scoped request the session schema is the tenant's
+// and qualified table references must stay within the tenant's schemas.
+func (s *QueryService) Execute(ctx context.Context, req *models.ExecuteQueryRequest, tenantID *uuid.UUID, canWrite bool) (query.Result, error) {
+	if fieldErrors := req.Validate(); len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	return s.run(ctx, req.SQL, req.Schema, nil, tenantID, canWrite, req.NoCache)
+}
+
+// run submits statement with params bound to its ? placeholders, applying
+// the permission and tenant checks of Execute. Queries are served from the
+// result cache when one is set, unless noCache is set.
+func (s *QueryService) run(ctx context.Context, statement, schema string, params []query.Parameter, tenantID *uuid.UUID, canWrite, noCache bool) (query.Result, error) {
+	stmt, err := query.ParseStatement(statement)
+	if err != nil {
+		return nil, &ValidationError{Errors: []models.FieldError{{Field: "sql", Message: err.Error()}}}
+	}
+	if !stmt.ReadOnly() && !canWrite {
+		return nil, ErrStatementNotPermitted
+	}
+
+	session := s.engine.DefaultSession()
+	if schema != "" {
+		session.Schema = schema
+	}
+	if tenantID != nil && *tenantID != models.GetDefaultTenantUUID() {
+		session.Schema = query.TenantSchema(*tenantID, session.Schema)
+		if err := stmt.ValidateSchemaAccess(session.Catalog, query.TenantSchemaPrefix(*tenantID)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSchemaAccessDenied, err)
+		}
+	}
+
+	s.logger.Info("executing statement",
+		"engine", s.engine.Name(),
+		"catalog", session.Catalog,
+		"schema", session.Schema,
+		"read_only", stmt.ReadOnly(),
+		"parameters", len(params),
+	)
+
+	if s.cache == nil || s.maxResultRows <= 0 || !stmt.ReadOnly() {
+		return s.engine.Query(ctx, stmt.SQL, session, params...)
+	}
+	return s.cachedQuery(ctx, stmt, session, params, tenantID, noCache)
+}
+
+// cachedQuery serves a query from the result cache, running it and caching
+// its result on a miss. With refresh the cached result is replaced.
+func (s *QueryService) cachedQuery(ctx context.Context, stmt *query.Statement, session query.Session, params []query.Parameter, tenantID *uuid.UUID, refresh bool) (query.Result, error) {
+	var versions string
+	if s.snapshots != nil {
+		var err error
+		versions, err = query.TableVersions(ctx, s.snapshots, stmt, session)
+		if err != nil {
+			s.logger.Warn("failed to get table snapshots, not caching the result", "error", err)
+			return s.engine.Query(ctx, stmt.SQL, session, params...)
+		}
+	}
+
+	key := query.CacheKey(s.engine.Name(), session, tenantID, stmt.SQL, params, versions)
+	cached, status, err := s.cache.Load(ctx, key, refresh, func() (*query.CachedResult, error) {
+		result, err := s.engine.Query(ctx, stmt.SQL, session, params...)
+		if err != nil {
+			return nil, err
+		}
+		return query.Materialize(result, s.maxResultRows, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cached.Replay(status), nil
+}
+
+// HealthChecker returns the health checker of the query engine.
+func (s *QueryService) HealthChecker() *health.ComponentChecker {
+	return s.engine.HealthChecker()
+}
+
 // getClusterInfo fetches Trino cluster info from /v1/info.
 func (s *QueryService) getClusterInfo(ctx context.Context) (*models.TrinoClusterInfo, error) {
 	url := strings.TrimSuffix(s.cfg.URL, "/") + "/v1/info"
@@ -330,97 +475,9 @@ func (s *QueryService) getClusterStats(ctx context.Context) (*models.TrinoCluste
 	return &stats, nil
 }
 
-// executeQuery executes a Trino SQL query and returns the results.
-// This is a simplified implementation using the Trino REST API.
+// executeQuery executes a Trino SQL query in the default session and
+// returns all of its rows.
 func (s *QueryService) executeQuery(ctx context.Context, query string) ([][]interface{}, error) {
-	url := strings.TrimSuffix(s.cfg.URL, "/") + "/v1/statement"
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(query))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "text/plain")
-	req.Header.Set("X-Trino-User", s.cfg.Username)
-	if s.cfg.Username == "" {
-		req.Header.Set("X-Trino-User", "philotes")
-	}
-	req.Header.Set("X-Trino-Catalog", s.cfg.Catalog)
-	req.Header.Set("X-Trino-Schema", s.cfg.Schema)
-
-	if s.cfg.Username != "" && s.cfg.Password != "" {
-		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			return nil, fmt.Errorf("query failed with status %d (failed to read body: %v)", resp.StatusCode, readErr)
-		}
-		return nil, fmt.Errorf("query failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Parse initial response
-	var result struct {
-		ID      string          `json:"id"`
-		NextURI string          `json:"nextUri"`
-		Data    [][]interface{} `json:"data"`
-		Error   *struct {
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode query response: %w", err)
-	}
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("query error: %s", result.Error.Message)
-	}
-
-	allData := result.Data
-
-	// Follow nextUri to get all results
-	for result.NextURI != "" {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.NextURI, http.NoBody)
-		if err != nil {
-			return nil, err
-		}
-
-		if s.cfg.Username != "" && s.cfg.Password != "" {
-			req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
-		}
-
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			return nil, fmt.Errorf("query continuation failed with status %d", resp.StatusCode)
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode query continuation: %w", err)
-		}
-		resp.Body.Close()
-
-		if result.Error != nil {
-			return nil, fmt.Errorf("query error: %s", result.Error.Message)
-		}
-
-		if result.Data != nil {
-			allData = append(allData, result.Data...)
-		}
-	}
-
-	return allData, nil
+	_, rows, err := s.client.QueryAll(ctx, query, s.client.DefaultSession())
+	return rows, err
 }
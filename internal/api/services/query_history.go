@@ -0,0 +1,87 @@
+// Package services provides business logic for API resources.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// QueryHistoryService records executed statements and lists them.
+type QueryHistoryService struct {
+	repo           *repositories.QueryHistoryRepository
+	redactLiterals bool
+	logger         *slog.Logger
+}
+
+// NewQueryHistoryService creates a new QueryHistoryService. With
+// redactLiterals the literals in WHERE clauses are replaced with ? before a
+// statement is stored.
+func NewQueryHistoryService(repo *repositories.QueryHistoryRepository, redactLiterals bool, logger *slog.Logger) *QueryHistoryService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &QueryHistoryService{
+		repo:           repo,
+		redactLiterals: redactLiterals,
+		logger:         logger.With("component", "query-history-service"),
+	}
+}
+
+// Record adds an executed statement to the history asynchronously, so that
+// a slow metadata database does not hold up query responses.
+func (s *QueryHistoryService) Record(entry *models.QueryHistoryEntry) {
+	if s.redactLiterals {
+		entry.SQL = query.RedactLiterals(entry.SQL)
+	}
+	if entry.ExecutedAt.IsZero() {
+		entry.ExecutedAt = time.Now()
+	}
+
+	go func() {
+		historyCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.repo.Create(historyCtx, entry); err != nil {
+			s.logger.Warn("failed to record query history", "query_id", entry.QueryID, "error", err)
+		}
+	}()
+}
+
+// List retrieves history entries matching filter, newest first, with
+// pagination.
+func (s *QueryHistoryService) List(ctx context.Context, filter models.QueryHistoryFilter, limit, offset int) (*models.QueryHistoryListResponse, error) {
+	var fieldErrors []models.FieldError
+	if filter.Status != "" && !filter.Status.IsValid() {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "status", Message: "status must be one of: success, error"})
+	}
+	if filter.Since != nil && filter.Until != nil && filter.Until.Before(*filter.Since) {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "until", Message: "until must not be before since"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count query history: %w", err)
+	}
+
+	entries, err := s.repo.ListPaginated(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list query history: %w", err)
+	}
+	if entries == nil {
+		entries = []models.QueryHistoryEntry{}
+	}
+
+	return &models.QueryHistoryListResponse{
+		Queries:    entries,
+		TotalCount: total,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// MetricSampleStore is the persistence used by RemoteWriteService.
+type MetricSampleStore interface {
+	WriteSamples(ctx context.Context, samples []alerting.MetricSample, expiresAt time.Time) error
+	DeleteExpiredSamples(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RemoteWriteService receives metrics pushed over Prometheus remote write,
+// keeps the series with an accepted name prefix for the configured
+// retention, and periodically deletes expired samples.
+type RemoteWriteService struct {
+	store  MetricSampleStore
+	cfg    config.RemoteWriteConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewRemoteWriteService creates a new RemoteWriteService.
+func NewRemoteWriteService(store MetricSampleStore, cfg config.RemoteWriteConfig, logger *slog.Logger) *RemoteWriteService {
+	return &RemoteWriteService{
+		store:  store,
+		cfg:    cfg,
+		logger: logger.With("component", "remote-write-service"),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Ingest decodes a remote-write request and stores its accepted samples.
+// It returns the number of samples stored.
+func (s *RemoteWriteService) Ingest(ctx context.Context, body []byte) (int, error) {
+	samples, err := alerting.DecodeRemoteWrite(body)
+	if err != nil {
+		if errors.Is(err, alerting.ErrInvalidRemoteWrite) {
+			return 0, &ValidationError{Errors: []models.FieldError{{Field: "body", Message: err.Error()}}}
+		}
+		return 0, err
+	}
+
+	accepted := samples[:0]
+	for _, sample := range samples {
+		if s.accepts(sample.Name) {
+			accepted = append(accepted, sample)
+		}
+	}
+	if len(accepted) == 0 {
+		return 0, nil
+	}
+
+	if err := s.store.WriteSamples(ctx, accepted, s.now().Add(s.cfg.Retention)); err != nil {
+		return 0, fmt.Errorf("failed to store samples: %w", err)
+	}
+	return len(accepted), nil
+}
+
+// accepts reports whether series of the named metric are stored. Without
+// configured prefixes every metric is.
+func (s *RemoteWriteService) accepts(name string) bool {
+	if len(s.cfg.MetricPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range s.cfg.MetricPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Cleanup ---
+
+// Start starts the periodic cleanup job. It is a no-op when the cleanup
+// interval is not positive.
+func (s *RemoteWriteService) Start(ctx context.Context) {
+	if s.cfg.CleanupInterval <= 0 {
+		s.logger.Info("remote-write sample cleanup disabled")
+		return
+	}
+
+	s.logger.Info("starting remote-write sample cleanup",
+		"interval", s.cfg.CleanupInterval,
+		"retention", s.cfg.Retention,
+	)
+
+	s.wg.Add(1)
+	go s.runLoop(ctx)
+}
+
+// Stop stops the periodic cleanup job.
+func (s *RemoteWriteService) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *RemoteWriteService) runLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Cleanup(ctx); err != nil {
+			s.logger.Error("remote-write sample cleanup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup deletes expired samples and returns how many were deleted.
+func (s *RemoteWriteService) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteExpiredSamples(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("cleanup expired samples: %w", err)
+	}
+
+	if deleted > 0 {
+		s.logger.Info("cleaned up expired remote-write samples", "count", deleted)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// fakeSampleStore keeps written samples in memory.
+type fakeSampleStore struct {
+	samples   []alerting.MetricSample
+	expiresAt time.Time
+	deleted   int64
+}
+
+func (f *fakeSampleStore) WriteSamples(_ context.Context, samples []alerting.MetricSample, expiresAt time.Time) error {
+	f.samples = append(f.samples, samples...)
+	f.expiresAt = expiresAt
+	return nil
+}
+
+func (f *fakeSampleStore) DeleteExpiredSamples(_ context.Context, _ time.Time) (int64, error) {
+	return f.deleted, nil
+}
+
+// writeRequest encodes a remote-write request with one sample per metric name.
+func writeRequest(names ...string) []byte {
+	var req []byte
+	for _, name := range names {
+		var label, sample, ts []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, "__name__")
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, name)
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(1))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(time.Now().UnixMilli()))
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, label)
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return snappy.Encode(nil, req)
+}
+
+func TestRemoteWriteService_Ingest(t *testing.T) {
+	store := &fakeSampleStore{}
+	svc := NewRemoteWriteService(store, config.RemoteWriteConfig{
+		MetricPrefixes: []string{"philotes_"},
+		Retention:      time.Hour,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	n, err := svc.Ingest(context.Background(), writeRequest("philotes_cdc_lag_seconds", "go_goroutines"))
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if n != 1 || len(store.samples) != 1 || store.samples[0].Name != "philotes_cdc_lag_seconds" {
+		t.Errorf("Ingest() stored %d: %+v, want only philotes_cdc_lag_seconds", n, store.samples)
+	}
+	if !store.expiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expiresAt = %v, want %v", store.expiresAt, now.Add(time.Hour))
+	}
+
+	var validationErr *ValidationError
+	if _, err := svc.Ingest(context.Background(), []byte("garbage")); !errors.As(err, &validationErr) {
+		t.Errorf("Ingest(garbage) error = %v, want a ValidationError", err)
+	}
+}
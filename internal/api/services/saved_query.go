@@ -0,0 +1,227 @@
+// Package services provides business logic for API resources.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// SavedQueryService provides business logic for saved queries. Saved
+// queries belong to a tenant; methods take the caller's tenant scope like
+// those of AlertService.
+type SavedQueryService struct {
+	repo         *repositories.SavedQueryRepository
+	queryService *QueryService
+	logger       *slog.Logger
+}
+
+// NewSavedQueryService creates a new SavedQueryService. queryService may be
+// nil when no query engine is configured; saved queries can then be managed
+// but not run.
+func NewSavedQueryService(repo *repositories.SavedQueryRepository, queryService *QueryService, logger *slog.Logger) *SavedQueryService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SavedQueryService{
+		repo:         repo,
+		queryService: queryService,
+		logger:       logger.With("component", "saved-query-service"),
+	}
+}
+
+// Create creates a new saved query owned by tenantID.
+func (s *SavedQueryService) Create(ctx context.Context, tenantID *uuid.UUID, req *models.CreateSavedQueryRequest) (*models.SavedQuery, error) {
+	if errs := req.Validate(); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	req.ApplyDefaults()
+
+	q, err := s.repo.Create(ctx, tenantID, req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSavedQueryNameExists) {
+			return nil, &ConflictError{Message: "saved query with this name already exists"}
+		}
+		s.logger.Error("failed to create saved query", "error", err)
+		return nil, fmt.Errorf("failed to create saved query: %w", err)
+	}
+
+	s.logger.Info("saved query created", "id", q.ID, "name", q.Name)
+	return q, nil
+}
+
+// Get retrieves a saved query by ID.
+func (s *SavedQueryService) Get(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) (*models.SavedQuery, error) {
+	q, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSavedQueryNotFound) {
+			return nil, &NotFoundError{Resource: "saved query", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to get saved query: %w", err)
+	}
+	if !inTenantScope(tenantID, q.TenantID) {
+		return nil, &NotFoundError{Resource: "saved query", ID: id.String()}
+	}
+	return q, nil
+}
+
+// List retrieves saved queries with pagination.
+func (s *SavedQueryService) List(ctx context.Context, tenantID *uuid.UUID, limit, offset int) (*models.SavedQueryListResponse, error) {
+	total, err := s.repo.Count(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count saved queries: %w", err)
+	}
+
+	queries, err := s.repo.List(ctx, tenantID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved queries: %w", err)
+	}
+	if queries == nil {
+		queries = []models.SavedQuery{}
+	}
+
+	return &models.SavedQueryListResponse{
+		SavedQueries: queries,
+		TotalCount:   total,
+	}, nil
+}
+
+// Update updates a saved query.
+func (s *SavedQueryService) Update(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.UpdateSavedQueryRequest) (*models.SavedQuery, error) {
+	if errs := req.Validate(); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	existing, err := s.Get(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the placeholders still match the parameters after the update
+	if req.SQL != nil || req.Schema != nil || req.Parameters != nil {
+		if errs := req.ValidateFor(existing); len(errs) > 0 {
+			return nil, &ValidationError{Errors: errs}
+		}
+	}
+
+	q, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSavedQueryNotFound) {
+			return nil, &NotFoundError{Resource: "saved query", ID: id.String()}
+		}
+		if errors.Is(err, repositories.ErrSavedQueryNameExists) {
+			return nil, &ConflictError{Message: "saved query with this name already exists"}
+		}
+		return nil, fmt.Errorf("failed to update saved query: %w", err)
+	}
+
+	s.logger.Info("saved query updated", "id", q.ID, "name", q.Name)
+	return q, nil
+}
+
+// Delete deletes a saved query.
+func (s *SavedQueryService) Delete(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID) error {
+	if _, err := s.Get(ctx, id, tenantID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrSavedQueryNotFound) {
+			return &NotFoundError{Resource: "saved query", ID: id.String()}
+		}
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+
+	s.logger.Info("saved query deleted", "id", id)
+	return nil
+}
+
+// Run executes a saved query on the query engine and returns the saved
+// query and the running result, which the caller must close. The parameter
+// values of req are converted to their declared types and bound to the
+// statement's placeholders as engine parameters, never spliced into the SQL
+// text. Permission and tenant checks are those of QueryService.Execute. The
+// saved query is returned with any error once it has been found.
+func (s *SavedQueryService) Run(ctx context.Context, id uuid.UUID, tenantID *uuid.UUID, req *models.RunSavedQueryRequest, canWrite bool) (*models.SavedQuery, query.Result, error) {
+	q, err := s.Get(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.queryService == nil {
+		return q, nil, query.ErrNotEnabled
+	}
+
+	statement, params, err := bindSavedQuery(q, req.Parameters)
+	if err != nil {
+		return q, nil, err
+	}
+
+	s.logger.Info("running saved query", "id", q.ID, "name", q.Name)
+	result, err := s.queryService.run(ctx, statement, q.Schema, params, tenantID, canWrite, req.NoCache)
+	return q, result, err
+}
+
+// bindSavedQuery replaces the placeholders of q with positional parameters
+// and returns the statement and the parameters in placeholder order.
+func bindSavedQuery(q *models.SavedQuery, values map[string]interface{}) (string, []query.Parameter, error) {
+	var fieldErrors []models.FieldError
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if _, ok := q.Parameter(name); !ok {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "parameters." + name,
+				Message: fmt.Sprintf("saved query has no parameter %q", name),
+			})
+		}
+	}
+
+	converted := make(map[string]query.Parameter, len(q.Parameters))
+	for _, p := range q.Parameters {
+		value, ok := values[p.Name]
+		if !ok || value == nil {
+			value = p.Default
+		}
+		if value == nil {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: "parameters." + p.Name, Message: "parameter is required"})
+			continue
+		}
+		param, err := query.ConvertParameter(p.Type, value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{Field: "parameters." + p.Name, Message: err.Error()})
+			continue
+		}
+		converted[p.Name] = param
+	}
+	if len(fieldErrors) > 0 {
+		return "", nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	statement, names, err := query.BindPlaceholders(q.SQL)
+	if err != nil {
+		return "", nil, &ValidationError{Errors: []models.FieldError{{Field: "sql", Message: err.Error()}}}
+	}
+
+	params := make([]query.Parameter, len(names))
+	for i, name := range names {
+		param, ok := converted[name]
+		if !ok {
+			return "", nil, &ValidationError{Errors: []models.FieldError{{
+				Field:   "sql",
+				Message: fmt.Sprintf("placeholder {{%s}} is not a declared parameter", name),
+			}}}
+		}
+		params[i] = param
+	}
+
+	return statement, params, nil
+}
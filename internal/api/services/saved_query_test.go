@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+func TestBindSavedQuery(t *testing.T) {
+	q := &models.SavedQuery{
+		SQL: "SELECT * FROM orders WHERE day >= {{start_date}} AND customer = {{customer}} OR referrer = {{customer}}",
+		Parameters: []models.SavedQueryParameter{
+			{Name: "start_date", Type: query.ParameterDate, Default: "2026-01-01"},
+			{Name: "customer", Type: query.ParameterString},
+		},
+	}
+
+	statement, params, err := bindSavedQuery(q, map[string]interface{}{"customer": "o'brien"})
+	if err != nil {
+		t.Fatalf("bindSavedQuery() error = %v", err)
+	}
+	if want := "SELECT * FROM orders WHERE day >= ? AND customer = ? OR referrer = ?"; statement != want {
+		t.Errorf("statement = %q, want %q", statement, want)
+	}
+	want := []query.Parameter{
+		{Type: query.ParameterDate, Value: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Type: query.ParameterString, Value: "o'brien"},
+		{Type: query.ParameterString, Value: "o'brien"},
+	}
+	if len(params) != len(want) {
+		t.Fatalf("params = %v, want %v", params, want)
+	}
+	for i := range want {
+		if params[i] != want[i] {
+			t.Errorf("params[%d] = %v, want %v", i, params[i], want[i])
+		}
+	}
+}
+
+func TestBindSavedQuery_InvalidParameters(t *testing.T) {
+	q := &models.SavedQuery{
+		SQL: "SELECT * FROM orders WHERE id = {{id}} AND customer = {{customer}}",
+		Parameters: []models.SavedQueryParameter{
+			{Name: "id", Type: query.ParameterInteger},
+			{Name: "customer", Type: query.ParameterString},
+		},
+	}
+
+	_, _, err := bindSavedQuery(q, map[string]interface{}{"id": "one", "region": "eu"})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("bindSavedQuery() error = %v, want a ValidationError", err)
+	}
+
+	fields := make([]string, 0, len(validationErr.Errors))
+	for _, e := range validationErr.Errors {
+		fields = append(fields, e.Field)
+	}
+	wantFields := []string{"parameters.region", "parameters.id", "parameters.customer"}
+	if len(fields) != len(wantFields) {
+		t.Fatalf("errors for %v, want %v", fields, wantFields)
+	}
+	for i := range wantFields {
+		if fields[i] != wantFields[i] {
+			t.Errorf("errors[%d] for %q, want %q", i, fields[i], wantFields[i])
+		}
+	}
+}
@@ -178,15 +178,16 @@ func (s *ScalingService) GetPolicyState(ctx context.Context, id uuid.UUID) (*mod
 		}, nil
 	}
 
+	cooldown := s.manager.CooldownDuration(policy)
 	response := &models.ScalingStateResponse{
 		State:      state,
 		PolicyID:   id,
 		PolicyName: policy.Name,
-		InCooldown: state.IsInCooldown(policy.CooldownDuration()),
+		InCooldown: state.IsInCooldown(cooldown),
 	}
 
 	if response.InCooldown && state.LastScaleTime != nil {
-		cooldownEnds := state.LastScaleTime.Add(policy.CooldownDuration())
+		cooldownEnds := state.LastScaleTime.Add(cooldown)
 		response.CooldownEnds = &cooldownEnds
 	}
 
@@ -0,0 +1,1093 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// ErrSCIMUnauthorized is returned for a SCIM bearer token that belongs to
+// no enabled provider.
+var ErrSCIMUnauthorized = errors.New("invalid scim token")
+
+// scimTokenPrefix prefixes generated SCIM bearer tokens.
+const scimTokenPrefix = "scim_"
+
+// SCIM list paging limits.
+const (
+	SCIMDefaultCount = 100
+	scimMaxCount     = 1000
+)
+
+// SCIMService provisions the users and groups of OIDC providers over SCIM
+// 2.0. Users are scoped to the provider whose token authenticated the
+// request. A user's SCIM groups become their IdP groups, which are mapped
+// to their role and tenant memberships like on OIDC login.
+type SCIMService struct {
+	scimRepo  *repositories.SCIMRepository
+	userRepo  *repositories.UserRepository
+	oidcRepo  *repositories.OIDCRepository
+	auditRepo *repositories.AuditRepository
+	oidc      *OIDCService
+	baseURL   string
+	logger    *slog.Logger
+}
+
+// NewSCIMService creates a new SCIMService.
+func NewSCIMService(
+	scimRepo *repositories.SCIMRepository,
+	userRepo *repositories.UserRepository,
+	oidcRepo *repositories.OIDCRepository,
+	auditRepo *repositories.AuditRepository,
+	oidcService *OIDCService,
+	baseURL string,
+	logger *slog.Logger,
+) *SCIMService {
+	return &SCIMService{
+		scimRepo:  scimRepo,
+		userRepo:  userRepo,
+		oidcRepo:  oidcRepo,
+		auditRepo: auditRepo,
+		oidc:      oidcService,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		logger:    logger.With("component", "scim-service"),
+	}
+}
+
+// Authenticate returns the enabled provider a SCIM bearer token belongs to.
+func (s *SCIMService) Authenticate(ctx context.Context, token string) (*models.OIDCProvider, error) {
+	if !strings.HasPrefix(token, scimTokenPrefix) {
+		return nil, ErrSCIMUnauthorized
+	}
+
+	provider, err := s.oidcRepo.GetProviderBySCIMTokenHash(ctx, hashSCIMToken(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrOIDCProviderNotFound) {
+			return nil, ErrSCIMUnauthorized
+		}
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	if !provider.Enabled {
+		return nil, ErrSCIMUnauthorized
+	}
+	return provider, nil
+}
+
+// ServiceProviderConfig describes the supported SCIM features.
+func (s *SCIMService) ServiceProviderConfig() *models.SCIMServiceProviderConfig {
+	return &models.SCIMServiceProviderConfig{
+		Schemas: []string{models.SCIMSchemaServiceProviderConfig},
+		Patch:   models.SCIMSupported{Supported: true},
+		Filter:  models.SCIMFilterSupport{Supported: true, MaxResults: scimMaxCount},
+		AuthenticationSchemes: []models.SCIMAuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "The SCIM token generated for the OIDC provider",
+		}},
+	}
+}
+
+// --- Users ---
+
+// ListUsers lists the provider's users matching the filter.
+func (s *SCIMService) ListUsers(ctx context.Context, provider *models.OIDCProvider, filter string, startIndex, count int) (*models.SCIMListResponse, error) {
+	f, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, scimValidationError("filter", err.Error())
+	}
+
+	users, err := s.userRepo.ListByOIDCProvider(ctx, provider.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	resources := make([]models.SCIMUser, 0, len(users))
+	for i := range users {
+		resource := s.toSCIMUser(&users[i], nil)
+		if f.matchesUser(&resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return paginateSCIM(resources, startIndex, count), nil
+}
+
+// GetUser returns a user of the provider.
+func (s *SCIMService) GetUser(ctx context.Context, provider *models.OIDCProvider, id string) (*models.SCIMUser, error) {
+	user, err := s.getUser(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, provider, user)
+}
+
+// CreateUser provisions a user for the provider. An existing user with the
+// same email address that is not linked to another provider is linked,
+// as on OIDC login.
+func (s *SCIMService) CreateUser(ctx context.Context, provider *models.OIDCProvider, req *models.SCIMUser, ipAddress, userAgent string) (*models.SCIMUser, error) {
+	if err := validateSCIMUser(req); err != nil {
+		return nil, err
+	}
+	subject := scimSubject(req)
+
+	if _, err := s.userRepo.GetByOIDCSubject(ctx, provider.ID, subject); err == nil {
+		return nil, &ConflictError{Message: "user already exists"}
+	} else if !errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	email := req.PrimaryEmail()
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if user.OIDCProviderID != nil && *user.OIDCProviderID != provider.ID {
+			return nil, &ConflictError{Message: "a user with this email address belongs to another provider"}
+		}
+		if err := s.userRepo.LinkOIDCProvider(ctx, user.ID, provider.ID, subject, user.OIDCGroups); err != nil {
+			return nil, fmt.Errorf("failed to link OIDC provider: %w", err)
+		}
+		user.OIDCProviderID = &provider.ID
+		user.OIDCSubject = subject
+		s.logger.Info("linked SCIM user to existing user", "user_id", user.ID, "provider", provider.Name)
+
+	case errors.Is(err, repositories.ErrUserNotFound):
+		role := s.oidc.mapGroupsToRole(provider, nil)
+		user, err = s.userRepo.CreateOIDCUser(ctx, email, req.FullName(), role, provider.ID, subject, []string{})
+		if err != nil {
+			if errors.Is(err, repositories.ErrUserEmailExists) {
+				return nil, &ConflictError{Message: "user already exists"}
+			}
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		s.logger.Info("created SCIM user", "user_id", user.ID, "provider", provider.Name, "role", role)
+
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user, err = s.updateProfile(ctx, user, req); err != nil {
+		return nil, err
+	}
+
+	s.logUserEvent(ctx, provider, user.ID, models.AuditActionUserCreated, ipAddress, userAgent, map[string]interface{}{
+		"email":  user.Email,
+		"active": user.IsActive,
+	})
+
+	return s.userResource(ctx, provider, user)
+}
+
+// ReplaceUser replaces the attributes of a user of the provider.
+func (s *SCIMService) ReplaceUser(ctx context.Context, provider *models.OIDCProvider, id string, req *models.SCIMUser, ipAddress, userAgent string) (*models.SCIMUser, error) {
+	user, err := s.getUser(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateUser(ctx, provider, user, req, ipAddress, userAgent)
+}
+
+// PatchUser applies a SCIM PATCH request to a user of the provider.
+func (s *SCIMService) PatchUser(ctx context.Context, provider *models.OIDCProvider, id string, req *models.SCIMPatchRequest, ipAddress, userAgent string) (*models.SCIMUser, error) {
+	user, err := s.getUser(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stored name is patched as name.formatted, so that changes to its
+	// parts and to displayName apply whatever their order
+	resource := s.toSCIMUser(user, nil)
+	resource.DisplayName = ""
+	if err := applyUserPatch(&resource, req); err != nil {
+		return nil, err
+	}
+	return s.updateUser(ctx, provider, user, &resource, ipAddress, userAgent)
+}
+
+// DeleteUser deactivates a user of the provider. Users are not deleted so
+// their audit trail and ownership of resources are kept.
+func (s *SCIMService) DeleteUser(ctx context.Context, provider *models.OIDCProvider, id, ipAddress, userAgent string) error {
+	user, err := s.getUser(ctx, provider, id)
+	if err != nil {
+		return err
+	}
+
+	inactive := false
+	if _, err := s.userRepo.Update(ctx, user.ID, &models.UpdateUserRequest{IsActive: &inactive}); err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	s.logUserEvent(ctx, provider, user.ID, models.AuditActionUserUpdated, ipAddress, userAgent, map[string]interface{}{
+		"active": false,
+	})
+	s.logger.Info("deactivated SCIM user", "user_id", user.ID, "provider", provider.Name)
+
+	return nil
+}
+
+// getUser returns a user of the provider by SCIM ID.
+func (s *SCIMService) getUser(ctx context.Context, provider *models.OIDCProvider, id string) (*models.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, &NotFoundError{Resource: "user", ID: id}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, &NotFoundError{Resource: "user", ID: id}
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.OIDCProviderID == nil || *user.OIDCProviderID != provider.ID {
+		return nil, &NotFoundError{Resource: "user", ID: id}
+	}
+	return user, nil
+}
+
+// updateUser applies the attributes of a SCIM user to a user.
+func (s *SCIMService) updateUser(ctx context.Context, provider *models.OIDCProvider, user *models.User, req *models.SCIMUser, ipAddress, userAgent string) (*models.SCIMUser, error) {
+	if err := validateSCIMUser(req); err != nil {
+		return nil, err
+	}
+
+	if email := req.PrimaryEmail(); email != user.Email {
+		if err := s.userRepo.UpdateEmail(ctx, user.ID, email); err != nil {
+			if errors.Is(err, repositories.ErrUserEmailExists) {
+				return nil, &ConflictError{Message: "a user with this email address already exists"}
+			}
+			return nil, fmt.Errorf("failed to update email: %w", err)
+		}
+	}
+
+	if subject := scimSubject(req); subject != user.OIDCSubject {
+		if other, err := s.userRepo.GetByOIDCSubject(ctx, provider.ID, subject); err == nil && other.ID != user.ID {
+			return nil, &ConflictError{Message: "a user with this externalId already exists"}
+		}
+		if err := s.userRepo.UpdateOIDCInfo(ctx, user.ID, &provider.ID, subject, user.OIDCGroups); err != nil {
+			return nil, fmt.Errorf("failed to update subject: %w", err)
+		}
+	}
+
+	wasActive := user.IsActive
+	updated, err := s.updateProfile(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"email":  updated.Email,
+		"active": updated.IsActive,
+	}
+	s.logUserEvent(ctx, provider, updated.ID, models.AuditActionUserUpdated, ipAddress, userAgent, details)
+	if wasActive && !updated.IsActive {
+		s.logger.Info("deactivated SCIM user", "user_id", updated.ID, "provider", provider.Name)
+	}
+
+	return s.userResource(ctx, provider, updated)
+}
+
+// updateProfile sets the name and active state of a user from a SCIM
+// user.
+func (s *SCIMService) updateProfile(ctx context.Context, user *models.User, req *models.SCIMUser) (*models.User, error) {
+	name := req.FullName()
+	active := req.IsActive()
+	updated, err := s.userRepo.Update(ctx, user.ID, &models.UpdateUserRequest{Name: &name, IsActive: &active})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return updated, nil
+}
+
+// userResource returns the SCIM resource of a user with their groups.
+func (s *SCIMService) userResource(ctx context.Context, provider *models.OIDCProvider, user *models.User) (*models.SCIMUser, error) {
+	groups, err := s.scimRepo.ListGroupsByMember(ctx, provider.ID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user groups: %w", err)
+	}
+	resource := s.toSCIMUser(user, groups)
+	return &resource, nil
+}
+
+// toSCIMUser converts a user to a SCIM resource.
+func (s *SCIMService) toSCIMUser(user *models.User, groups []models.SCIMGroupRecord) models.SCIMUser {
+	active := user.IsActive
+	resource := models.SCIMUser{
+		Schemas:     []string{models.SCIMSchemaUser},
+		ID:          user.ID.String(),
+		ExternalID:  user.OIDCSubject,
+		UserName:    user.Email,
+		DisplayName: user.Name,
+		Emails:      []models.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &models.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     s.baseURL + "/scim/v2/Users/" + user.ID.String(),
+		},
+	}
+	if user.Name != "" {
+		resource.Name = &models.SCIMName{Formatted: user.Name}
+	}
+	for _, group := range groups {
+		resource.Groups = append(resource.Groups, models.SCIMMember{
+			Value:   group.ID.String(),
+			Display: group.DisplayName,
+			Ref:     s.baseURL + "/scim/v2/Groups/" + group.ID.String(),
+		})
+	}
+	return resource
+}
+
+// --- Groups ---
+
+// ListGroups lists the provider's groups matching the filter.
+func (s *SCIMService) ListGroups(ctx context.Context, provider *models.OIDCProvider, filter string, startIndex, count int) (*models.SCIMListResponse, error) {
+	f, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, scimValidationError("filter", err.Error())
+	}
+
+	groups, err := s.scimRepo.ListGroups(ctx, provider.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	resources := make([]models.SCIMGroup, 0, len(groups))
+	for i := range groups {
+		resource := s.toSCIMGroup(&groups[i])
+		if f.matchesGroup(&resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return paginateSCIM(resources, startIndex, count), nil
+}
+
+// GetGroup returns a group of the provider.
+func (s *SCIMService) GetGroup(ctx context.Context, provider *models.OIDCProvider, id string) (*models.SCIMGroup, error) {
+	group, err := s.getGroup(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+	resource := s.toSCIMGroup(group)
+	return &resource, nil
+}
+
+// CreateGroup provisions a group for the provider and applies it to the
+// groups of its members.
+func (s *SCIMService) CreateGroup(ctx context.Context, provider *models.OIDCProvider, req *models.SCIMGroup, ipAddress, userAgent string) (*models.SCIMGroup, error) {
+	if req.DisplayName == "" {
+		return nil, scimValidationError("displayName", "displayName is required")
+	}
+	memberIDs, err := parseSCIMMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, provider, memberIDs); err != nil {
+		return nil, err
+	}
+
+	group, err := s.scimRepo.CreateGroup(ctx, provider.ID, req.DisplayName, req.ExternalID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSCIMGroupNameExists) {
+			return nil, &ConflictError{Message: "group already exists"}
+		}
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if len(memberIDs) > 0 {
+		if err := s.scimRepo.SetGroupMembers(ctx, group.ID, memberIDs); err != nil {
+			return nil, fmt.Errorf("failed to set group members: %w", err)
+		}
+		group.MemberIDs = memberIDs
+		s.applyGroups(ctx, provider, memberIDs, ipAddress, userAgent)
+	}
+
+	s.logGroupEvent(ctx, provider, group.ID, models.AuditActionSCIMGroupCreated, ipAddress, userAgent, map[string]interface{}{
+		"display_name": group.DisplayName,
+		"members":      len(memberIDs),
+	})
+
+	resource := s.toSCIMGroup(group)
+	return &resource, nil
+}
+
+// ReplaceGroup replaces the name and members of a group of the provider.
+func (s *SCIMService) ReplaceGroup(ctx context.Context, provider *models.OIDCProvider, id string, req *models.SCIMGroup, ipAddress, userAgent string) (*models.SCIMGroup, error) {
+	group, err := s.getGroup(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := parseSCIMMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *group
+	updated.DisplayName = req.DisplayName
+	updated.ExternalID = req.ExternalID
+	updated.MemberIDs = memberIDs
+	return s.saveGroup(ctx, provider, group, &updated, ipAddress, userAgent)
+}
+
+// PatchGroup applies a SCIM PATCH request to a group of the provider.
+func (s *SCIMService) PatchGroup(ctx context.Context, provider *models.OIDCProvider, id string, req *models.SCIMPatchRequest, ipAddress, userAgent string) (*models.SCIMGroup, error) {
+	group, err := s.getGroup(ctx, provider, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *group
+	updated.MemberIDs = append([]uuid.UUID(nil), group.MemberIDs...)
+	if err := applyGroupPatch(&updated, req); err != nil {
+		return nil, err
+	}
+	return s.saveGroup(ctx, provider, group, &updated, ipAddress, userAgent)
+}
+
+// DeleteGroup deletes a group of the provider and removes it from the
+// groups of its members.
+func (s *SCIMService) DeleteGroup(ctx context.Context, provider *models.OIDCProvider, id, ipAddress, userAgent string) error {
+	group, err := s.getGroup(ctx, provider, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scimRepo.DeleteGroup(ctx, provider.ID, group.ID); err != nil {
+		if errors.Is(err, repositories.ErrSCIMGroupNotFound) {
+			return &NotFoundError{Resource: "group", ID: id}
+		}
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	s.applyGroups(ctx, provider, group.MemberIDs, ipAddress, userAgent)
+
+	s.logGroupEvent(ctx, provider, group.ID, models.AuditActionSCIMGroupDeleted, ipAddress, userAgent, map[string]interface{}{
+		"display_name": group.DisplayName,
+	})
+
+	return nil
+}
+
+// getGroup returns a group of the provider by SCIM ID.
+func (s *SCIMService) getGroup(ctx context.Context, provider *models.OIDCProvider, id string) (*models.SCIMGroupRecord, error) {
+	groupID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, &NotFoundError{Resource: "group", ID: id}
+	}
+
+	group, err := s.scimRepo.GetGroup(ctx, provider.ID, groupID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSCIMGroupNotFound) {
+			return nil, &NotFoundError{Resource: "group", ID: id}
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return group, nil
+}
+
+// saveGroup stores the changes to a group and applies them to the groups
+// of the users whose membership changed, or of every member when the group
+// was renamed.
+func (s *SCIMService) saveGroup(ctx context.Context, provider *models.OIDCProvider, group, updated *models.SCIMGroupRecord, ipAddress, userAgent string) (*models.SCIMGroup, error) {
+	if updated.DisplayName == "" {
+		return nil, scimValidationError("displayName", "displayName is required")
+	}
+
+	previous := make(map[uuid.UUID]bool, len(group.MemberIDs))
+	for _, id := range group.MemberIDs {
+		previous[id] = true
+	}
+	current := make(map[uuid.UUID]bool, len(updated.MemberIDs))
+	var added []uuid.UUID
+	for _, id := range updated.MemberIDs {
+		current[id] = true
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+	if err := s.checkMembers(ctx, provider, added); err != nil {
+		return nil, err
+	}
+
+	renamed := updated.DisplayName != group.DisplayName
+	if renamed || updated.ExternalID != group.ExternalID {
+		if err := s.scimRepo.UpdateGroup(ctx, provider.ID, group.ID, updated.DisplayName, updated.ExternalID); err != nil {
+			if errors.Is(err, repositories.ErrSCIMGroupNameExists) {
+				return nil, &ConflictError{Message: "group already exists"}
+			}
+			return nil, fmt.Errorf("failed to update group: %w", err)
+		}
+	}
+
+	var affected []uuid.UUID
+	for id := range current {
+		if renamed || !previous[id] {
+			affected = append(affected, id)
+		}
+	}
+	for id := range previous {
+		if !current[id] {
+			affected = append(affected, id)
+		}
+	}
+
+	if len(current) != len(previous) || len(added) > 0 {
+		memberIDs := make([]uuid.UUID, 0, len(current))
+		for id := range current {
+			memberIDs = append(memberIDs, id)
+		}
+		if err := s.scimRepo.SetGroupMembers(ctx, group.ID, memberIDs); err != nil {
+			return nil, fmt.Errorf("failed to set group members: %w", err)
+		}
+	}
+	s.applyGroups(ctx, provider, affected, ipAddress, userAgent)
+
+	s.logGroupEvent(ctx, provider, group.ID, models.AuditActionSCIMGroupUpdated, ipAddress, userAgent, map[string]interface{}{
+		"display_name": updated.DisplayName,
+		"members":      len(current),
+		"changed":      len(affected),
+	})
+
+	saved, err := s.scimRepo.GetGroup(ctx, provider.ID, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	resource := s.toSCIMGroup(saved)
+	return &resource, nil
+}
+
+// checkMembers checks that the users exist and belong to the provider.
+func (s *SCIMService) checkMembers(ctx context.Context, provider *models.OIDCProvider, userIDs []uuid.UUID) error {
+	for _, id := range userIDs {
+		if _, err := s.getUser(ctx, provider, id.String()); err != nil {
+			var notFound *NotFoundError
+			if errors.As(err, &notFound) {
+				return scimValidationError("members", fmt.Sprintf("member %s is not a user of this provider", id))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGroups sets the IdP groups of users to the names of their SCIM
+// groups, updating their roles and tenant memberships. Failures are logged
+// and do not fail the group change.
+func (s *SCIMService) applyGroups(ctx context.Context, provider *models.OIDCProvider, userIDs []uuid.UUID, ipAddress, userAgent string) {
+	for _, id := range userIDs {
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			s.logger.Warn("failed to get user for group sync", "user_id", id, "error", err)
+			continue
+		}
+
+		groups, err := s.scimRepo.ListGroupsByMember(ctx, provider.ID, id)
+		if err != nil {
+			s.logger.Warn("failed to list user groups", "user_id", id, "error", err)
+			continue
+		}
+		names := make([]string, len(groups))
+		for i := range groups {
+			names[i] = groups[i].DisplayName
+		}
+
+		if err := s.oidc.ApplyGroups(ctx, provider, user, names, ipAddress, userAgent); err != nil {
+			s.logger.Warn("failed to apply SCIM groups", "user_id", id, "error", err)
+		}
+	}
+}
+
+// toSCIMGroup converts a group to a SCIM resource.
+func (s *SCIMService) toSCIMGroup(group *models.SCIMGroupRecord) models.SCIMGroup {
+	resource := models.SCIMGroup{
+		Schemas:     []string{models.SCIMSchemaGroup},
+		ID:          group.ID.String(),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     make([]models.SCIMMember, len(group.MemberIDs)),
+		Meta: &models.SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     s.baseURL + "/scim/v2/Groups/" + group.ID.String(),
+		},
+	}
+	for i, id := range group.MemberIDs {
+		resource.Members[i] = models.SCIMMember{
+			Value: id.String(),
+			Ref:   s.baseURL + "/scim/v2/Users/" + id.String(),
+		}
+	}
+	return resource
+}
+
+// --- Helper Methods ---
+
+// logUserEvent logs a SCIM change to a user asynchronously.
+func (s *SCIMService) logUserEvent(ctx context.Context, provider *models.OIDCProvider, userID uuid.UUID, action, ipAddress, userAgent string, details map[string]interface{}) {
+	details["provider"] = provider.Name
+	details["source"] = "scim"
+	s.writeAuditLog(&models.AuditLog{
+		Action:       action,
+		ResourceType: "user",
+		ResourceID:   &userID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		Details:      details,
+	})
+}
+
+// logGroupEvent logs a SCIM change to a group asynchronously.
+func (s *SCIMService) logGroupEvent(ctx context.Context, provider *models.OIDCProvider, groupID uuid.UUID, action, ipAddress, userAgent string, details map[string]interface{}) {
+	details["provider"] = provider.Name
+	s.writeAuditLog(&models.AuditLog{
+		Action:       action,
+		ResourceType: "scim_group",
+		ResourceID:   &groupID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		Details:      details,
+	})
+}
+
+// writeAuditLog writes an audit log entry in the background.
+func (s *SCIMService) writeAuditLog(log *models.AuditLog) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	go func() {
+		auditCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.auditRepo.Create(auditCtx, log); err != nil {
+			s.logger.Warn("failed to create audit log", "action", log.Action, "error", err)
+		}
+	}()
+}
+
+// generateSCIMToken generates a SCIM bearer token and its hash.
+func generateSCIMToken() (token, tokenHash string, err error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	token = scimTokenPrefix + hex.EncodeToString(randomBytes)
+	return token, hashSCIMToken(token), nil
+}
+
+// hashSCIMToken hashes a SCIM bearer token using SHA256, which like for
+// API keys is sufficient for high-entropy random tokens.
+func hashSCIMToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// scimValidationError returns a validation error of a SCIM attribute.
+func scimValidationError(field, message string) error {
+	return &ValidationError{Errors: []models.FieldError{{Field: field, Message: message}}}
+}
+
+// validateSCIMUser validates the attributes of a SCIM user.
+func validateSCIMUser(u *models.SCIMUser) error {
+	if strings.TrimSpace(u.UserName) == "" {
+		return scimValidationError("userName", "userName is required")
+	}
+	if email := u.PrimaryEmail(); !strings.Contains(email, "@") {
+		return scimValidationError("emails", "an email address is required as userName or in emails")
+	}
+	return nil
+}
+
+// scimSubject returns the OIDC subject of a SCIM user: its externalId, or
+// else its userName.
+func scimSubject(u *models.SCIMUser) string {
+	if u.ExternalID != "" {
+		return u.ExternalID
+	}
+	return u.UserName
+}
+
+// paginateSCIM returns a page of resources. The start index is 1-based.
+func paginateSCIM[T any](resources []T, startIndex, count int) *models.SCIMListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+
+	page := []T{}
+	if start := startIndex - 1; start < len(resources) {
+		end := min(start+count, len(resources))
+		page = resources[start:end]
+	}
+
+	return &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// --- Filters ---
+
+// scimFilter is an equality filter, the only kind provisioning clients
+// use to look up users and groups. A nil filter matches everything.
+type scimFilter struct {
+	attribute string
+	value     string
+}
+
+// parseSCIMFilter parses a filter of the form `attribute eq "value"`.
+func parseSCIMFilter(filter string) (*scimFilter, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, nil
+	}
+
+	attribute, rest, ok := strings.Cut(filter, " ")
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter %q", filter)
+	}
+	operator, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(operator, "eq") {
+		return nil, fmt.Errorf("unsupported filter %q: only eq is supported", filter)
+	}
+	unquoted, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter value %s", value)
+	}
+
+	return &scimFilter{attribute: strings.ToLower(attribute), value: unquoted}, nil
+}
+
+// matchesUser reports whether a user matches the filter. Unknown
+// attributes match nothing.
+func (f *scimFilter) matchesUser(u *models.SCIMUser) bool {
+	if f == nil {
+		return true
+	}
+	switch f.attribute {
+	case "id":
+		return u.ID == f.value
+	case "username":
+		return strings.EqualFold(u.UserName, f.value)
+	case "externalid":
+		return u.ExternalID == f.value
+	case "emails", "emails.value":
+		for _, email := range u.Emails {
+			if strings.EqualFold(email.Value, f.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesGroup reports whether a group matches the filter. Unknown
+// attributes match nothing.
+func (f *scimFilter) matchesGroup(g *models.SCIMGroup) bool {
+	if f == nil {
+		return true
+	}
+	switch f.attribute {
+	case "id":
+		return g.ID == f.value
+	case "displayname":
+		return strings.EqualFold(g.DisplayName, f.value)
+	case "externalid":
+		return g.ExternalID == f.value
+	case "members", "members.value":
+		for _, member := range g.Members {
+			if member.Value == f.value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// --- Patching ---
+
+// scimUserSchemaPrefix prefixes fully qualified user attribute paths.
+const scimUserSchemaPrefix = "urn:ietf:params:scim:schemas:core:2.0:user:"
+
+// patchOp returns the normalized operation of a PATCH operation.
+func patchOp(op models.SCIMPatchOperation) (string, error) {
+	switch name := strings.ToLower(op.Op); name {
+	case "add", "replace", "remove":
+		return name, nil
+	default:
+		return "", scimValidationError("op", fmt.Sprintf("unsupported operation %q", op.Op))
+	}
+}
+
+// applyUserPatch applies PATCH operations to a SCIM user. Attributes that
+// are not stored, such as title or addresses, are ignored.
+func applyUserPatch(u *models.SCIMUser, req *models.SCIMPatchRequest) error {
+	for _, op := range req.Operations {
+		name, err := patchOp(op)
+		if err != nil {
+			return err
+		}
+
+		if op.Path != "" {
+			if err := setUserAttribute(u, name, op.Path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "remove" {
+			return scimValidationError("path", "remove requires a path")
+		}
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return scimValidationError("value", "value must be an object of attributes")
+		}
+		for path, value := range attributes {
+			if err := setUserAttribute(u, name, path, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setUserAttribute applies one operation to a user attribute.
+func setUserAttribute(u *models.SCIMUser, op, path string, value json.RawMessage) error {
+	path = strings.TrimPrefix(strings.ToLower(path), scimUserSchemaPrefix)
+
+	if op == "remove" {
+		switch path {
+		case "externalid":
+			u.ExternalID = ""
+		case "displayname":
+			u.DisplayName = ""
+		case "name":
+			u.Name = nil
+		case "name.givenname", "name.familyname", "name.formatted":
+			return setUserAttribute(u, "replace", path, json.RawMessage(`""`))
+		case "emails":
+			u.Emails = nil
+		case "username", "active":
+			return scimValidationError("path", fmt.Sprintf("%s cannot be removed", path))
+		}
+		return nil
+	}
+
+	switch {
+	case path == "username":
+		return decodeSCIMValue(path, value, &u.UserName)
+	case path == "externalid":
+		return decodeSCIMValue(path, value, &u.ExternalID)
+	case path == "displayname":
+		return decodeSCIMValue(path, value, &u.DisplayName)
+	case path == "active":
+		active, err := decodeSCIMBool(value)
+		if err != nil {
+			return scimValidationError(path, "active must be a boolean")
+		}
+		u.Active = &active
+	case path == "name":
+		var name models.SCIMName
+		if err := decodeSCIMValue(path, value, &name); err != nil {
+			return err
+		}
+		u.Name = &name
+	case strings.HasPrefix(path, "name."):
+		if u.Name == nil {
+			u.Name = &models.SCIMName{}
+		}
+		// A changed part invalidates the formatted name it was built into
+		switch path {
+		case "name.givenname":
+			u.Name.Formatted = ""
+			return decodeSCIMValue(path, value, &u.Name.GivenName)
+		case "name.familyname":
+			u.Name.Formatted = ""
+			return decodeSCIMValue(path, value, &u.Name.FamilyName)
+		case "name.formatted":
+			return decodeSCIMValue(path, value, &u.Name.Formatted)
+		}
+	case path == "emails":
+		var emails []models.SCIMEmail
+		if err := decodeSCIMValue(path, value, &emails); err != nil {
+			return err
+		}
+		u.Emails = emails
+	case strings.HasPrefix(path, "emails[") && strings.HasSuffix(path, "].value"):
+		var email string
+		if err := decodeSCIMValue(path, value, &email); err != nil {
+			return err
+		}
+		u.Emails = []models.SCIMEmail{{Value: email, Type: "work", Primary: true}}
+	}
+	return nil
+}
+
+// applyGroupPatch applies PATCH operations to a group.
+func applyGroupPatch(g *models.SCIMGroupRecord, req *models.SCIMPatchRequest) error {
+	for _, op := range req.Operations {
+		name, err := patchOp(op)
+		if err != nil {
+			return err
+		}
+
+		if op.Path != "" {
+			if err := setGroupAttribute(g, name, op.Path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "remove" {
+			return scimValidationError("path", "remove requires a path")
+		}
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return scimValidationError("value", "value must be an object of attributes")
+		}
+		for path, value := range attributes {
+			if strings.EqualFold(path, "id") {
+				continue
+			}
+			if err := setGroupAttribute(g, name, path, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setGroupAttribute applies one operation to a group attribute.
+func setGroupAttribute(g *models.SCIMGroupRecord, op, path string, value json.RawMessage) error {
+	lower := strings.ToLower(path)
+
+	switch {
+	case lower == "displayname":
+		if op == "remove" {
+			return scimValidationError("path", "displayName cannot be removed")
+		}
+		return decodeSCIMValue(path, value, &g.DisplayName)
+
+	case lower == "externalid":
+		if op == "remove" {
+			g.ExternalID = ""
+			return nil
+		}
+		return decodeSCIMValue(path, value, &g.ExternalID)
+
+	case lower == "members":
+		var members []models.SCIMMember
+		if len(value) > 0 && string(value) != "null" {
+			if err := decodeSCIMValue(path, value, &members); err != nil {
+				return err
+			}
+		}
+		ids, err := parseSCIMMembers(members)
+		if err != nil {
+			return err
+		}
+		switch {
+		case op == "add":
+			g.MemberIDs = append(g.MemberIDs, ids...)
+		case op == "replace":
+			g.MemberIDs = ids
+		case len(ids) == 0:
+			g.MemberIDs = nil
+		default:
+			g.MemberIDs = removeMembers(g.MemberIDs, ids)
+		}
+		return nil
+
+	case strings.HasPrefix(lower, "members[") && strings.HasSuffix(lower, "]"):
+		if op != "remove" {
+			return scimValidationError("path", fmt.Sprintf("unsupported path %q for %s", path, op))
+		}
+		f, err := parseSCIMFilter(path[len("members[") : len(path)-1])
+		if err != nil || f == nil || f.attribute != "value" {
+			return scimValidationError("path", fmt.Sprintf("unsupported member filter %q", path))
+		}
+		id, err := uuid.Parse(f.value)
+		if err != nil {
+			return scimValidationError("path", fmt.Sprintf("invalid member id %q", f.value))
+		}
+		g.MemberIDs = removeMembers(g.MemberIDs, []uuid.UUID{id})
+		return nil
+
+	default:
+		return scimValidationError("path", fmt.Sprintf("unsupported path %q", path))
+	}
+}
+
+// parseSCIMMembers parses the user IDs of SCIM group members, dropping
+// duplicates.
+func parseSCIMMembers(members []models.SCIMMember) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(members))
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			return nil, scimValidationError("members", fmt.Sprintf("invalid member id %q", member.Value))
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// removeMembers returns the members without the removed ones.
+func removeMembers(members, removed []uuid.UUID) []uuid.UUID {
+	drop := make(map[uuid.UUID]bool, len(removed))
+	for _, id := range removed {
+		drop[id] = true
+	}
+	kept := make([]uuid.UUID, 0, len(members))
+	for _, id := range members {
+		if !drop[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// decodeSCIMValue decodes the value of an attribute.
+func decodeSCIMValue(path string, value json.RawMessage, target any) error {
+	if err := json.Unmarshal(value, target); err != nil {
+		return scimValidationError(path, fmt.Sprintf("invalid value for %s", path))
+	}
+	return nil
+}
+
+// decodeSCIMBool decodes a boolean, which some clients send as a string.
+func decodeSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var str string
+	if err := json.Unmarshal(value, &str); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(str)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter    string
+		attribute string
+		value     string
+		wantErr   bool
+	}{
+		{filter: ""},
+		{filter: `userName eq "jane@example.com"`, attribute: "username", value: "jane@example.com"},
+		{filter: `externalId EQ "00u1"`, attribute: "externalid", value: "00u1"},
+		{filter: `displayName eq "Data \"Eng\""`, attribute: "displayname", value: `Data "Eng"`},
+		{filter: `userName sw "jane"`, wantErr: true},
+		{filter: `userName eq jane`, wantErr: true},
+		{filter: `userName`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := parseSCIMFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSCIMFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.attribute == "" {
+				if f != nil {
+					t.Errorf("parseSCIMFilter() = %+v, want nil", f)
+				}
+				return
+			}
+			if f.attribute != tt.attribute || f.value != tt.value {
+				t.Errorf("parseSCIMFilter() = %+v, want %s %q", f, tt.attribute, tt.value)
+			}
+		})
+	}
+}
+
+func TestSCIMFilterMatchesUser(t *testing.T) {
+	user := &models.SCIMUser{
+		UserName:   "Jane@Example.com",
+		ExternalID: "00u1",
+		Emails:     []models.SCIMEmail{{Value: "jane@example.com"}},
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{``, true},
+		{`userName eq "jane@example.com"`, true},
+		{`externalId eq "00u1"`, true},
+		{`externalId eq "00U1"`, false},
+		{`emails.value eq "JANE@example.com"`, true},
+		{`title eq "Engineer"`, false},
+	}
+
+	for _, tt := range tests {
+		f, err := parseSCIMFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("parseSCIMFilter(%q) error = %v", tt.filter, err)
+		}
+		if got := f.matchesUser(user); got != tt.want {
+			t.Errorf("matchesUser(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestSCIMUserAttributes(t *testing.T) {
+	active := false
+	user := &models.SCIMUser{
+		UserName: "jdoe",
+		Name:     &models.SCIMName{GivenName: "Jane", FamilyName: "Doe"},
+		Emails: []models.SCIMEmail{
+			{Value: "jane@home.example.com", Type: "home"},
+			{Value: "jane@example.com", Type: "work", Primary: true},
+		},
+		Active: &active,
+	}
+
+	if got := user.PrimaryEmail(); got != "jane@example.com" {
+		t.Errorf("PrimaryEmail() = %q, want the primary email", got)
+	}
+	if got := user.FullName(); got != "Jane Doe" {
+		t.Errorf("FullName() = %q, want %q", got, "Jane Doe")
+	}
+	if user.IsActive() {
+		t.Error("IsActive() = true, want false")
+	}
+	if got := scimSubject(user); got != "jdoe" {
+		t.Errorf("scimSubject() = %q, want the userName without an externalId", got)
+	}
+
+	user.ExternalID = "00u1"
+	if got := scimSubject(user); got != "00u1" {
+		t.Errorf("scimSubject() = %q, want the externalId", got)
+	}
+}
+
+// patchRequest builds a PATCH request from operations given as JSON.
+func patchRequest(t *testing.T, operations string) *models.SCIMPatchRequest {
+	t.Helper()
+	req := &models.SCIMPatchRequest{Schemas: []string{models.SCIMSchemaPatchOp}}
+	if err := json.Unmarshal([]byte(operations), &req.Operations); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	active := true
+	base := func() *models.SCIMUser {
+		return &models.SCIMUser{
+			UserName: "jane@example.com",
+			Name:     &models.SCIMName{Formatted: "Jane Doe"},
+			Emails:   []models.SCIMEmail{{Value: "jane@example.com", Primary: true}},
+			Active:   &active,
+		}
+	}
+
+	t.Run("path operations", func(t *testing.T) {
+		user := base()
+		err := applyUserPatch(user, patchRequest(t, `[
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "replace", "path": "name.familyName", "value": "Smith"},
+			{"op": "replace", "path": "name.givenName", "value": "Jane"},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "jane.smith@example.com"},
+			{"op": "add", "path": "externalId", "value": "00u1"}
+		]`))
+		if err != nil {
+			t.Fatalf("applyUserPatch() error = %v", err)
+		}
+		if user.IsActive() {
+			t.Error("active not patched from a string value")
+		}
+		if got := user.FullName(); got != "Jane Smith" {
+			t.Errorf("FullName() = %q, want the patched name parts", got)
+		}
+		if got := user.PrimaryEmail(); got != "jane.smith@example.com" {
+			t.Errorf("PrimaryEmail() = %q", got)
+		}
+		if user.ExternalID != "00u1" {
+			t.Errorf("ExternalID = %q", user.ExternalID)
+		}
+	})
+
+	t.Run("value object", func(t *testing.T) {
+		user := base()
+		err := applyUserPatch(user, patchRequest(t, `[
+			{"op": "replace", "value": {
+				"displayName": "J. Doe",
+				"name.givenName": "Janet",
+				"active": false,
+				"urn:ietf:params:scim:schemas:core:2.0:User:userName": "janet@example.com",
+				"title": "Engineer"
+			}}
+		]`))
+		if err != nil {
+			t.Fatalf("applyUserPatch() error = %v", err)
+		}
+		if got := user.FullName(); got != "J. Doe" {
+			t.Errorf("FullName() = %q, want displayName to win over name parts", got)
+		}
+		if user.UserName != "janet@example.com" || user.IsActive() {
+			t.Errorf("user = %+v, want userName and active patched", user)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, ops := range []string{
+			`[{"op": "move", "path": "active", "value": true}]`,
+			`[{"op": "remove"}]`,
+			`[{"op": "remove", "path": "userName"}]`,
+			`[{"op": "replace", "path": "active", "value": "maybe"}]`,
+			`[{"op": "replace", "value": "not an object"}]`,
+		} {
+			var validationErr *ValidationError
+			if err := applyUserPatch(base(), patchRequest(t, ops)); !errors.As(err, &validationErr) {
+				t.Errorf("applyUserPatch(%s) error = %v, want a validation error", ops, err)
+			}
+		}
+	})
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	group := func() *models.SCIMGroupRecord {
+		return &models.SCIMGroupRecord{DisplayName: "engineering", MemberIDs: []uuid.UUID{a, b}}
+	}
+	member := func(id uuid.UUID) string { return `{"value": "` + id.String() + `"}` }
+
+	tests := []struct {
+		name string
+		ops  string
+		want []uuid.UUID
+	}{
+		{"add", `[{"op": "add", "path": "members", "value": [` + member(c) + `]}]`, []uuid.UUID{a, b, c}},
+		{"remove by filter", `[{"op": "remove", "path": "members[value eq \"` + a.String() + `\"]"}]`, []uuid.UUID{b}},
+		{"remove listed", `[{"op": "remove", "path": "members", "value": [` + member(b) + `]}]`, []uuid.UUID{a}},
+		{"remove all", `[{"op": "remove", "path": "members"}]`, nil},
+		{"replace", `[{"op": "replace", "path": "members", "value": [` + member(c) + `, ` + member(c) + `]}]`, []uuid.UUID{c}},
+		{"value object", `[{"op": "replace", "value": {"id": "ignored", "members": [` + member(a) + `]}}]`, []uuid.UUID{a}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := group()
+			if err := applyGroupPatch(g, patchRequest(t, tt.ops)); err != nil {
+				t.Fatalf("applyGroupPatch() error = %v", err)
+			}
+			if !slices.Equal(g.MemberIDs, tt.want) {
+				t.Errorf("members = %v, want %v", g.MemberIDs, tt.want)
+			}
+		})
+	}
+
+	g := group()
+	if err := applyGroupPatch(g, patchRequest(t, `[{"op": "replace", "path": "displayName", "value": "data"}]`)); err != nil {
+		t.Fatalf("applyGroupPatch() error = %v", err)
+	}
+	if g.DisplayName != "data" {
+		t.Errorf("DisplayName = %q, want the patched name", g.DisplayName)
+	}
+
+	for _, ops := range []string{
+		`[{"op": "add", "path": "members", "value": [{"value": "not-a-uuid"}]}]`,
+		`[{"op": "add", "path": "members[value eq \"` + a.String() + `\"]"}]`,
+		`[{"op": "remove", "path": "displayName"}]`,
+		`[{"op": "replace", "path": "owner", "value": "x"}]`,
+	} {
+		var validationErr *ValidationError
+		if err := applyGroupPatch(group(), patchRequest(t, ops)); !errors.As(err, &validationErr) {
+			t.Errorf("applyGroupPatch(%s) error = %v, want a validation error", ops, err)
+		}
+	}
+}
+
+func TestPaginateSCIM(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		startIndex, count int
+		want              []int
+	}{
+		{1, 100, []int{1, 2, 3, 4, 5}},
+		{2, 2, []int{2, 3}},
+		{0, 1, []int{1}},
+		{6, 10, []int{}},
+		{1, 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		page := paginateSCIM(items, tt.startIndex, tt.count)
+		got, _ := page.Resources.([]int)
+		if !slices.Equal(got, tt.want) || page.TotalResults != len(items) || page.ItemsPerPage != len(tt.want) {
+			t.Errorf("paginateSCIM(%d, %d) = %+v, want %v", tt.startIndex, tt.count, page, tt.want)
+		}
+	}
+}
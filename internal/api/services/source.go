@@ -8,25 +8,128 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/janovincze/philotes/internal/api/models"
 	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/cdc/health"
+	"github.com/janovincze/philotes/internal/vault"
 )
 
+// SecretResolver returns the secret referenced by value (vault://path#key or
+// env://NAME), or value itself when it is not a reference.
+type SecretResolver func(ctx context.Context, value string) (string, error)
+
 // SourceService provides business logic for source operations.
 type SourceService struct {
-	repo   *repositories.SourceRepository
-	logger *slog.Logger
+	repo          *repositories.SourceRepository
+	resolveSecret SecretResolver
+	pool          *sourcePool
+	schemas       *schemaCache
+	logger        *slog.Logger
+
+	// requireSecretRefs rejects passwords that are not secret references.
+	requireSecretRefs bool
+
+	// healthMgr, if set, checks the reachability of every source; checks
+	// maps each source to the name of its health component.
+	healthMgr *health.Manager
+	checksMu  sync.Mutex
+	checks    map[uuid.UUID]string
 }
 
 // NewSourceService creates a new SourceService.
 func NewSourceService(repo *repositories.SourceRepository, logger *slog.Logger) *SourceService {
 	return &SourceService{
-		repo:   repo,
-		logger: logger.With("component", "source-service"),
+		repo:    repo,
+		pool:    newSourcePool(sourcePoolIdleTimeout),
+		schemas: newSchemaCache(sourceSchemaCacheTTL),
+		logger:  logger.With("component", "source-service"),
+		checks:  make(map[uuid.UUID]string),
+	}
+}
+
+// Close closes the cached source connections.
+func (s *SourceService) Close() {
+	s.pool.close()
+}
+
+// SetSecretResolver sets the resolver for source passwords stored as secret
+// references. References are resolved each time a connection is opened, so
+// rotated secrets are picked up without updating the source.
+func (s *SourceService) SetSecretResolver(resolve SecretResolver) {
+	s.resolveSecret = resolve
+}
+
+// SetRequireSecretReferences makes Create and Update reject source passwords
+// that are not secret references, so that passwords are only ever read from
+// the secret backend and never stored in the source row.
+func (s *SourceService) SetRequireSecretReferences(require bool) {
+	s.requireSecretRefs = require
+}
+
+// SetHealthManager registers the reachability of each source with m as a
+// "source:<name>" component, degraded while the source is unreachable.
+// Sources created, renamed or deleted later are registered accordingly;
+// RegisterHealthChecks registers the existing ones.
+func (s *SourceService) SetHealthManager(m *health.Manager) {
+	s.healthMgr = m
+}
+
+// RegisterHealthChecks registers a health component for every existing
+// source.
+func (s *SourceService) RegisterHealthChecks(ctx context.Context) error {
+	if s.healthMgr == nil {
+		return nil
+	}
+	sources, err := s.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sources: %w", err)
+	}
+	for i := range sources {
+		s.registerHealthCheck(&sources[i])
+	}
+	return nil
+}
+
+// registerHealthCheck registers, or re-registers under its current name,
+// the health component of a source.
+func (s *SourceService) registerHealthCheck(source *models.Source) {
+	if s.healthMgr == nil {
+		return
+	}
+	s.unregisterHealthCheck(source.ID)
+
+	id := source.ID
+	name := "source:" + source.Name
+	checker := health.NewComponentChecker(name, func(ctx context.Context) (health.Status, string, error) {
+		if _, err := s.ping(ctx, id); err != nil {
+			return health.StatusDegraded, "source database unreachable", err
+		}
+		return health.StatusHealthy, "source database reachable", nil
+	})
+	checker.SetComponent(health.ComponentSource)
+	s.healthMgr.Register(checker)
+
+	s.checksMu.Lock()
+	s.checks[id] = name
+	s.checksMu.Unlock()
+}
+
+// unregisterHealthCheck removes the health component of a source.
+func (s *SourceService) unregisterHealthCheck(id uuid.UUID) {
+	if s.healthMgr == nil {
+		return
+	}
+	s.checksMu.Lock()
+	name, ok := s.checks[id]
+	delete(s.checks, id)
+	s.checksMu.Unlock()
+	if ok {
+		s.healthMgr.Unregister(name)
 	}
 }
 
@@ -36,6 +139,9 @@ func (s *SourceService) Create(ctx context.Context, req *models.CreateSourceRequ
 	if errors := req.Validate(); len(errors) > 0 {
 		return nil, &ValidationError{Errors: errors}
 	}
+	if err := s.validatePassword(ctx, req.Password); err != nil {
+		return nil, err
+	}
 
 	// Apply defaults
 	req.ApplyDefaults()
@@ -50,6 +156,8 @@ func (s *SourceService) Create(ctx context.Context, req *models.CreateSourceRequ
 		return nil, fmt.Errorf("failed to create source: %w", err)
 	}
 
+	s.registerHealthCheck(source)
+
 	s.logger.Info("source created", "id", source.ID, "name", source.Name)
 	return source, nil
 }
@@ -84,6 +192,11 @@ func (s *SourceService) Update(ctx context.Context, id uuid.UUID, req *models.Up
 	if errors := req.Validate(); len(errors) > 0 {
 		return nil, &ValidationError{Errors: errors}
 	}
+	if req.Password != nil {
+		if err := s.validatePassword(ctx, *req.Password); err != nil {
+			return nil, err
+		}
+	}
 
 	// Update source
 	source, err := s.repo.Update(ctx, id, req)
@@ -97,6 +210,10 @@ func (s *SourceService) Update(ctx context.Context, id uuid.UUID, req *models.Up
 		return nil, fmt.Errorf("failed to update source: %w", err)
 	}
 
+	s.pool.drop(id)
+	s.schemas.drop(id)
+	s.registerHealthCheck(source)
+
 	s.logger.Info("source updated", "id", source.ID, "name", source.Name)
 	return source, nil
 }
@@ -114,6 +231,10 @@ func (s *SourceService) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to delete source: %w", err)
 	}
 
+	s.pool.drop(id)
+	s.schemas.drop(id)
+	s.unregisterHealthCheck(id)
+
 	s.logger.Info("source deleted", "id", id)
 	return nil
 }
@@ -129,6 +250,16 @@ func (s *SourceService) TestConnection(ctx context.Context, id uuid.UUID) (*mode
 		return nil, fmt.Errorf("failed to get source: %w", err)
 	}
 
+	password, err = s.password(ctx, password)
+	if err != nil {
+		s.logger.Error("failed to resolve source password", "source_id", id, "error", err)
+		return &models.ConnectionTestResult{
+			Success:     false,
+			Message:     "Failed to resolve source password",
+			ErrorDetail: err.Error(),
+		}, nil
+	}
+
 	// Build connection string (password is not logged in errors from sql.Open/Ping)
 	dsn := buildDSN(source.Host, source.Port, source.DatabaseName, source.Username, password, source.SSLMode)
 
@@ -137,7 +268,7 @@ func (s *SourceService) TestConnection(ctx context.Context, id uuid.UUID) (*mode
 	defer cancel()
 
 	start := time.Now()
-	db, err := sql.Open("pgx", dsn)
+	db, err := s.pool.get(id, dsn)
 	if err != nil {
 		// Don't expose internal error details that might contain connection info
 		s.logger.Error("failed to open database connection", "source_id", id, "error", err)
@@ -147,7 +278,6 @@ func (s *SourceService) TestConnection(ctx context.Context, id uuid.UUID) (*mode
 			ErrorDetail: "Could not initialize database driver",
 		}, nil
 	}
-	defer db.Close()
 
 	// Ping database
 	if err := db.PingContext(testCtx); err != nil {
@@ -186,6 +316,203 @@ func (s *SourceService) TestConnection(ctx context.Context, id uuid.UUID) (*mode
 	}, nil
 }
 
+// Validate checks that a source is ready to replicate tables: it accepts
+// connections, has logical WAL, its role may replicate, and each table
+// exists with a replica identity that identifies the rows of updates and
+// deletes. The problems found are returned in the result, not as errors.
+func (s *SourceService) Validate(ctx context.Context, id uuid.UUID, tables []string) (*models.SourceValidationResult, error) {
+	source, password, err := s.repo.GetByIDWithPassword(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSourceNotFound) {
+			return nil, &NotFoundError{Resource: "source", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	invalid := func(field, message string) *models.SourceValidationResult {
+		return &models.SourceValidationResult{Errors: []models.FieldError{{Field: field, Message: message}}}
+	}
+
+	password, err = s.password(ctx, password)
+	if err != nil {
+		s.logger.Error("failed to resolve source password", "source_id", id, "error", err)
+		return invalid("source", "failed to resolve source password"), nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	db, err := s.pool.get(id, buildDSN(source.Host, source.Port, source.DatabaseName, source.Username, password, source.SSLMode))
+	if err != nil {
+		s.logger.Error("failed to open database connection", "source_id", id, "error", err)
+		return invalid("source", "could not initialize database driver"), nil
+	}
+
+	if err := db.PingContext(checkCtx); err != nil {
+		s.logger.Warn("failed to ping database", "source_id", id, "error", err)
+		return invalid("source", "failed to connect: "+sanitizeConnectionError(err)), nil
+	}
+
+	var problems []models.FieldError
+
+	var walLevel string
+	if err := db.QueryRowContext(checkCtx, `SHOW wal_level`).Scan(&walLevel); err != nil {
+		return nil, fmt.Errorf("failed to query wal_level: %w", err)
+	}
+	if walLevel != "logical" {
+		problems = append(problems, models.FieldError{
+			Field:   "source.wal_level",
+			Message: fmt.Sprintf("wal_level is %q, logical replication needs \"logical\"", walLevel),
+		})
+	}
+
+	// Managed services grant replication through a role instead
+	var canReplicate bool
+	err = db.QueryRowContext(checkCtx, `
+		SELECT rolreplication OR rolsuper OR EXISTS (
+			SELECT 1 FROM pg_roles r
+			WHERE r.rolname = 'rds_replication' AND pg_has_role(current_user, r.oid, 'member')
+		)
+		FROM pg_roles WHERE rolname = current_user
+	`).Scan(&canReplicate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role attributes: %w", err)
+	}
+	if !canReplicate {
+		problems = append(problems, models.FieldError{
+			Field:   "source.username",
+			Message: fmt.Sprintf("role %s lacks the REPLICATION attribute", source.Username),
+		})
+	}
+
+	for i, table := range tables {
+		schema, name := splitTableName(table)
+		field := fmt.Sprintf("tables[%d]", i)
+
+		var identity string
+		var hasPrimaryKey, hasIdentityIndex bool
+		err := db.QueryRowContext(checkCtx, `
+			SELECT c.relreplident::text,
+			       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = c.oid AND i.indisprimary),
+			       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = c.oid AND i.indisreplident)
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p')
+		`, schema, name).Scan(&identity, &hasPrimaryKey, &hasIdentityIndex)
+		if errors.Is(err, sql.ErrNoRows) {
+			problems = append(problems, models.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("table %s.%s does not exist", schema, name),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query table %s.%s: %w", schema, name, err)
+		}
+		if problem := replicaIdentityProblem(identity, hasPrimaryKey, hasIdentityIndex); problem != "" {
+			problems = append(problems, models.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("table %s.%s %s", schema, name, problem),
+			})
+		}
+	}
+
+	s.logger.Info("source validated", "id", id, "tables", len(tables), "problems", len(problems))
+	return &models.SourceValidationResult{Valid: len(problems) == 0, Errors: problems}, nil
+}
+
+// splitTableName splits "schema.table" into its parts; a bare table name is
+// in the public schema.
+func splitTableName(table string) (string, string) {
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return schema, name
+	}
+	return "public", table
+}
+
+// replicaIdentityProblem explains why a table's replica identity, as
+// pg_class.relreplident, leaves the rows of updates and deletes
+// unidentified, or returns "" if it does not.
+func replicaIdentityProblem(identity string, hasPrimaryKey, hasIdentityIndex bool) string {
+	switch identity {
+	case "f":
+		return ""
+	case "d":
+		if hasPrimaryKey {
+			return ""
+		}
+		return "has no primary key; set REPLICA IDENTITY FULL or USING INDEX to replicate updates and deletes"
+	case "i":
+		if hasIdentityIndex {
+			return ""
+		}
+		return "has replica identity USING INDEX without a usable index"
+	case "n":
+		return "has REPLICA IDENTITY NOTHING, so updates and deletes cannot be replicated"
+	default:
+		return fmt.Sprintf("has unknown replica identity %q", identity)
+	}
+}
+
+// Health pings a source database through its cached connections.
+func (s *SourceService) Health(ctx context.Context, id uuid.UUID) (*models.SourceHealth, error) {
+	result := &models.SourceHealth{SourceID: id, CheckedAt: time.Now()}
+
+	latency, err := s.ping(ctx, id)
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Warn("source health check failed", "source_id", id, "error", err)
+		failure := health.NewFailure(health.ComponentSource, err)
+		result.Status = string(health.StatusUnhealthy)
+		result.Message = sanitizeConnectionError(err)
+		result.Failure = &models.HealthFailure{
+			Code:        string(failure.Code),
+			Message:     failure.Message,
+			Remediation: failure.Remediation,
+		}
+		return result, nil
+	}
+
+	result.Status = string(health.StatusHealthy)
+	result.Message = "source database reachable"
+	result.LatencyMs = latency.Milliseconds()
+	return result, nil
+}
+
+// ping pings a source database through its cached connections and returns
+// the round trip time.
+func (s *SourceService) ping(ctx context.Context, id uuid.UUID) (time.Duration, error) {
+	source, password, err := s.repo.GetByIDWithPassword(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSourceNotFound) {
+			return 0, &NotFoundError{Resource: "source", ID: id.String()}
+		}
+		return 0, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	password, err = s.password(ctx, password)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve source password: %w", err)
+	}
+
+	db, err := s.pool.get(id, buildDSN(source.Host, source.Port, source.DatabaseName, source.Username, password, source.SSLMode))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open connection: %w", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := db.PingContext(pingCtx); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
 // buildDSN constructs a PostgreSQL connection string.
 func buildDSN(host string, port int, dbname, user, password, sslmode string) string {
 	return fmt.Sprintf(
@@ -223,134 +550,27 @@ func sanitizeConnectionError(err error) string {
 	return "connection failed"
 }
 
-// DiscoverTables discovers tables in a source database.
-func (s *SourceService) DiscoverTables(ctx context.Context, id uuid.UUID, schema string) (*models.TableDiscoveryResponse, error) {
-	// Get source with password
-	source, password, err := s.repo.GetByIDWithPassword(ctx, id)
-	if err != nil {
-		if errors.Is(err, repositories.ErrSourceNotFound) {
-			return nil, &NotFoundError{Resource: "source", ID: id.String()}
-		}
-		return nil, fmt.Errorf("failed to get source: %w", err)
-	}
-
-	// Default schema
-	if schema == "" {
-		schema = "public"
-	}
-
-	// Build connection string
-	dsn := buildDSN(source.Host, source.Port, source.DatabaseName, source.Username, password, source.SSLMode)
-
-	// Connect with timeout
-	discoverCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	db, err := sql.Open("pgx", dsn)
-	if err != nil {
-		s.logger.Error("failed to open connection for table discovery", "source_id", id, "error", err)
-		return nil, fmt.Errorf("failed to open connection to source database")
-	}
-	defer db.Close()
-
-	// Query tables
-	tableQuery := `
-		SELECT table_schema, table_name
-		FROM information_schema.tables
-		WHERE table_schema = $1
-		AND table_type = 'BASE TABLE'
-		ORDER BY table_name
-	`
-
-	rows, err := db.QueryContext(discoverCtx, tableQuery, schema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
+// password returns the source password, resolving a secret reference.
+func (s *SourceService) password(ctx context.Context, stored string) (string, error) {
+	if s.resolveSecret == nil {
+		return stored, nil
 	}
-	defer rows.Close()
-
-	var tables []models.TableInfo
-	for rows.Next() {
-		var tableSchema, tableName string
-		if err := rows.Scan(&tableSchema, &tableName); err != nil {
-			return nil, fmt.Errorf("failed to scan table row: %w", err)
-		}
-
-		// Get columns for this table
-		columns, err := s.discoverColumns(discoverCtx, db, tableSchema, tableName)
-		if err != nil {
-			s.logger.Warn("failed to discover columns", "table", tableName, "error", err)
-			columns = nil
-		}
-
-		tables = append(tables, models.TableInfo{
-			Schema:  tableSchema,
-			Name:    tableName,
-			Columns: columns,
-		})
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate tables: %w", err)
-	}
-
-	s.logger.Info("table discovery completed", "id", id, "schema", schema, "count", len(tables))
-
-	return &models.TableDiscoveryResponse{
-		Tables: tables,
-		Count:  len(tables),
-	}, nil
+	return s.resolveSecret(ctx, stored)
 }
 
-// discoverColumns discovers columns for a table.
-func (s *SourceService) discoverColumns(ctx context.Context, db *sql.DB, schema, table string) ([]models.ColumnInfo, error) {
-	query := `
-		SELECT
-			c.column_name,
-			c.data_type,
-			c.is_nullable = 'YES' as nullable,
-			c.column_default,
-			COALESCE(
-				(SELECT true FROM information_schema.key_column_usage kcu
-				 JOIN information_schema.table_constraints tc
-				   ON kcu.constraint_name = tc.constraint_name
-				 WHERE tc.constraint_type = 'PRIMARY KEY'
-				   AND kcu.table_schema = c.table_schema
-				   AND kcu.table_name = c.table_name
-				   AND kcu.column_name = c.column_name
-				 LIMIT 1), false
-			) as is_primary_key
-		FROM information_schema.columns c
-		WHERE c.table_schema = $1 AND c.table_name = $2
-		ORDER BY c.ordinal_position
-	`
-
-	rows, err := db.QueryContext(ctx, query, schema, table)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query columns: %w", err)
+// validatePassword checks that a password stored as a secret reference can
+// be resolved, so a missing secret is reported when the source is saved.
+func (s *SourceService) validatePassword(ctx context.Context, password string) error {
+	if s.requireSecretRefs && !vault.IsReference(password) {
+		return &ValidationError{Errors: []models.FieldError{{
+			Field:   "password",
+			Message: "must be a secret reference (vault://path#key or env://NAME)",
+		}}}
 	}
-	defer rows.Close()
-
-	var columns []models.ColumnInfo
-	for rows.Next() {
-		var col models.ColumnInfo
-		var columnDefault sql.NullString
-
-		if err := rows.Scan(&col.Name, &col.Type, &col.Nullable, &columnDefault, &col.PrimaryKey); err != nil {
-			return nil, fmt.Errorf("failed to scan column row: %w", err)
-		}
-
-		if columnDefault.Valid {
-			col.Default = &columnDefault.String
-		}
-
-		columns = append(columns, col)
+	if _, err := s.password(ctx, password); err != nil {
+		return &ValidationError{Errors: []models.FieldError{{Field: "password", Message: err.Error()}}}
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate columns: %w", err)
-	}
-
-	return columns, nil
+	return nil
 }
 
 // Service errors.
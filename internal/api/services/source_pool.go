@@ -0,0 +1,109 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sourcePoolIdleTimeout is how long a source's connections stay open
+// without being used.
+const sourcePoolIdleTimeout = 5 * time.Minute
+
+// sourcePool caches a small connection pool per source, so connection tests,
+// validations, table discovery and health checks reuse connections instead
+// of opening one each. A pool is kept for a source's connection string: a
+// changed source or a rotated secret opens a new pool. Connections, and
+// pools, unused for the idle timeout are closed.
+type sourcePool struct {
+	idleTimeout time.Duration
+	open        func(dsn string) (*sql.DB, error)
+	now         func() time.Time
+
+	mu    sync.Mutex
+	conns map[uuid.UUID]*pooledSource
+}
+
+// pooledSource is the cached pool of one source.
+type pooledSource struct {
+	db       *sql.DB
+	dsn      [sha256.Size]byte
+	lastUsed time.Time
+}
+
+func newSourcePool(idleTimeout time.Duration) *sourcePool {
+	return &sourcePool{
+		idleTimeout: idleTimeout,
+		open: func(dsn string) (*sql.DB, error) {
+			return sql.Open("pgx", dsn)
+		},
+		now:   time.Now,
+		conns: make(map[uuid.UUID]*pooledSource),
+	}
+}
+
+// get returns the pool of source id connecting with dsn, opening it if
+// there is none for dsn.
+func (p *sourcePool) get(id uuid.UUID, dsn string) (*sql.DB, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.evictIdle(now)
+
+	sum := sha256.Sum256([]byte(dsn))
+	if c, ok := p.conns[id]; ok {
+		if c.dsn == sum {
+			c.lastUsed = now
+			return c.db, nil
+		}
+		_ = c.db.Close()
+		delete(p.conns, id)
+	}
+
+	db, err := p.open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxIdleTime(p.idleTimeout)
+
+	p.conns[id] = &pooledSource{db: db, dsn: sum, lastUsed: now}
+	return db, nil
+}
+
+// drop closes the pool of source id, such as after it was changed or
+// deleted.
+func (p *sourcePool) drop(id uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.conns[id]; ok {
+		_ = c.db.Close()
+		delete(p.conns, id)
+	}
+}
+
+// close closes every pool.
+func (p *sourcePool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, c := range p.conns {
+		_ = c.db.Close()
+		delete(p.conns, id)
+	}
+}
+
+// evictIdle closes the pools unused for the idle timeout. Must be called
+// with the lock held.
+func (p *sourcePool) evictIdle(now time.Time) {
+	for id, c := range p.conns {
+		if now.Sub(c.lastUsed) >= p.idleTimeout {
+			_ = c.db.Close()
+			delete(p.conns, id)
+		}
+	}
+}
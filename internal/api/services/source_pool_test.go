@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// nopConnector opens a database without connecting.
+type nopConnector struct{}
+
+func (nopConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+
+func (nopConnector) Driver() driver.Driver { return nil }
+
+func TestSourcePool(t *testing.T) {
+	pool := newSourcePool(time.Minute)
+	opened := 0
+	pool.open = func(string) (*sql.DB, error) {
+		opened++
+		return sql.OpenDB(nopConnector{}), nil
+	}
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	id := uuid.New()
+	first, _ := pool.get(id, "host=a password=x")
+	if again, _ := pool.get(id, "host=a password=x"); again != first || opened != 1 {
+		t.Errorf("get() opened %d pools, want the first one reused", opened)
+	}
+
+	// A rotated password opens a new pool
+	if rotated, _ := pool.get(id, "host=a password=y"); rotated == first || opened != 2 {
+		t.Errorf("get() with a changed DSN reused the pool")
+	}
+
+	// Pools unused for the idle timeout are closed
+	other := uuid.New()
+	now = now.Add(2 * time.Minute)
+	_, _ = pool.get(other, "host=b")
+	if _, ok := pool.conns[id]; ok || len(pool.conns) != 1 {
+		t.Errorf("idle pool was not evicted: %d pools", len(pool.conns))
+	}
+
+	pool.drop(other)
+	if len(pool.conns) != 0 {
+		t.Errorf("drop() left %d pools", len(pool.conns))
+	}
+}
+
+func TestSourceService_RequireSecretReferences(t *testing.T) {
+	s := NewSourceService(nil, slog.Default())
+	s.SetRequireSecretReferences(true)
+
+	var validationErr *ValidationError
+	if err := s.validatePassword(context.Background(), "plaintext"); !errors.As(err, &validationErr) {
+		t.Errorf("validatePassword(plaintext) error = %v, want a validation error", err)
+	}
+	if err := s.validatePassword(context.Background(), "env://SOURCE_PASSWORD"); err != nil {
+		t.Errorf("validatePassword(reference) error = %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// sourceSchemaCacheTTL is how long the schemas and tables of a source are
+// served from the cache. A table picker browses a source in bursts; a short
+// TTL spares the source the repeated introspection without hiding new
+// tables for long.
+const sourceSchemaCacheTTL = 30 * time.Second
+
+// replicaIdentities names the pg_class.relreplident values.
+var replicaIdentities = map[string]string{
+	"d": "default",
+	"f": "full",
+	"i": "index",
+	"n": "nothing",
+}
+
+// schemaCache caches the introspection results of each source for a short
+// TTL. Results are keyed by source and by schema; the schema list is kept
+// under the empty schema. A changed or deleted source is dropped.
+type schemaCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]map[string]schemaCacheEntry
+}
+
+// schemaCacheEntry is a cached introspection result.
+type schemaCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	return &schemaCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[uuid.UUID]map[string]schemaCacheEntry),
+	}
+}
+
+// get returns the unexpired result cached for source id under key.
+func (c *schemaCache) get(id uuid.UUID, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id][key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries[id], key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// put caches value for source id under key.
+func (c *schemaCache) put(id uuid.UUID, key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[id] == nil {
+		c.entries[id] = make(map[string]schemaCacheEntry)
+	}
+	c.entries[id][key] = schemaCacheEntry{value: value, expiresAt: c.now().Add(c.ttl)}
+}
+
+// drop removes the cached results of source id.
+func (c *schemaCache) drop(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
+
+// sourceDB returns the cached connections of a source, resolving its
+// password through the secret resolver.
+func (s *SourceService) sourceDB(ctx context.Context, id uuid.UUID) (*sql.DB, error) {
+	source, password, err := s.repo.GetByIDWithPassword(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSourceNotFound) {
+			return nil, &NotFoundError{Resource: "source", ID: id.String()}
+		}
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	password, err = s.password(ctx, password)
+	if err != nil {
+		s.logger.Error("failed to resolve source password", "source_id", id, "error", err)
+		return nil, fmt.Errorf("failed to resolve source password: %w", err)
+	}
+
+	db, err := s.pool.get(id, buildDSN(source.Host, source.Port, source.DatabaseName, source.Username, password, source.SSLMode))
+	if err != nil {
+		s.logger.Error("failed to open connection for schema discovery", "source_id", id, "error", err)
+		return nil, fmt.Errorf("failed to open connection to source database")
+	}
+	return db, nil
+}
+
+// DiscoverSchemas lists the schemas of a source database with the number of
+// tables in each. System schemas are left out.
+func (s *SourceService) DiscoverSchemas(ctx context.Context, id uuid.UUID) (*models.SchemaDiscoveryResponse, error) {
+	if cached, ok := s.schemas.get(id, ""); ok {
+		return cached.(*models.SchemaDiscoveryResponse), nil
+	}
+
+	db, err := s.sourceDB(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	discoverCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT s.schema_name, COUNT(t.table_name)
+		FROM information_schema.schemata s
+		LEFT JOIN information_schema.tables t
+			ON t.table_schema = s.schema_name AND t.table_type = 'BASE TABLE'
+		WHERE s.schema_name NOT IN ('information_schema', 'pg_catalog')
+		AND s.schema_name NOT LIKE 'pg\_toast%'
+		AND s.schema_name NOT LIKE 'pg\_temp\_%'
+		GROUP BY s.schema_name
+		ORDER BY s.schema_name
+	`
+
+	rows, err := db.QueryContext(discoverCtx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []models.SchemaInfo{}
+	for rows.Next() {
+		var schema models.SchemaInfo
+		if err := rows.Scan(&schema.Name, &schema.TableCount); err != nil {
+			return nil, fmt.Errorf("failed to scan schema row: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schemas: %w", err)
+	}
+
+	result := &models.SchemaDiscoveryResponse{
+		Schemas:      schemas,
+		Count:        len(schemas),
+		DiscoveredAt: time.Now(),
+	}
+	s.schemas.put(id, "", result)
+
+	s.logger.Info("schema discovery completed", "id", id, "count", len(schemas))
+	return result, nil
+}
+
+// DiscoverTables lists the tables of a schema in a source database with
+// their columns and whether their replica identity suits CDC.
+func (s *SourceService) DiscoverTables(ctx context.Context, id uuid.UUID, schema string) (*models.TableDiscoveryResponse, error) {
+	// Default schema
+	if schema == "" {
+		schema = "public"
+	}
+
+	if cached, ok := s.schemas.get(id, schema); ok {
+		return cached.(*models.TableDiscoveryResponse), nil
+	}
+
+	db, err := s.sourceDB(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	discoverCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tables, err := discoverTables(discoverCtx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := discoverColumns(discoverCtx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		tables[i].Columns = columns[tables[i].Name]
+	}
+
+	result := &models.TableDiscoveryResponse{
+		Tables:       tables,
+		Count:        len(tables),
+		DiscoveredAt: time.Now(),
+	}
+	s.schemas.put(id, schema, result)
+
+	s.logger.Info("table discovery completed", "id", id, "schema", schema, "count", len(tables))
+	return result, nil
+}
+
+// discoverTables lists the tables of schema with their replica identity.
+func discoverTables(ctx context.Context, db *sql.DB, schema string) ([]models.TableInfo, error) {
+	query := `
+		SELECT t.table_name, c.relreplident::text,
+		       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = c.oid AND i.indisprimary),
+		       EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = c.oid AND i.indisreplident)
+		FROM information_schema.tables t
+		JOIN pg_namespace n ON n.nspname = t.table_schema
+		JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = t.table_name
+		WHERE t.table_schema = $1
+		AND t.table_type = 'BASE TABLE'
+		ORDER BY t.table_name
+	`
+
+	rows, err := db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []models.TableInfo{}
+	for rows.Next() {
+		var name, identity string
+		var hasPrimaryKey, hasIdentityIndex bool
+		if err := rows.Scan(&name, &identity, &hasPrimaryKey, &hasIdentityIndex); err != nil {
+			return nil, fmt.Errorf("failed to scan table row: %w", err)
+		}
+
+		table := models.TableInfo{
+			Schema:          schema,
+			Name:            name,
+			ReplicaIdentity: replicaIdentities[identity],
+		}
+		table.CDCProblem = replicaIdentityProblem(identity, hasPrimaryKey, hasIdentityIndex)
+		table.CDCReady = table.CDCProblem == ""
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate tables: %w", err)
+	}
+	return tables, nil
+}
+
+// discoverColumns lists the columns of every table in schema, keyed by
+// table name. Arrays and user-defined types are named by their type, such
+// as _int4 or a domain or enum name.
+func discoverColumns(ctx context.Context, db *sql.DB, schema string) (map[string][]models.ColumnInfo, error) {
+	query := `
+		SELECT
+			c.table_name,
+			c.column_name,
+			CASE WHEN c.data_type IN ('ARRAY', 'USER-DEFINED') THEN c.udt_name ELSE c.data_type END,
+			c.is_nullable = 'YES' as nullable,
+			c.column_default,
+			EXISTS (
+				SELECT 1 FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+				  ON kcu.constraint_schema = tc.constraint_schema
+				 AND kcu.constraint_name = tc.constraint_name
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+				  AND tc.table_schema = c.table_schema
+				  AND tc.table_name = c.table_name
+				  AND kcu.column_name = c.column_name
+			) as is_primary_key
+		FROM information_schema.columns c
+		WHERE c.table_schema = $1
+		ORDER BY c.table_name, c.ordinal_position
+	`
+
+	rows, err := db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]models.ColumnInfo)
+	for rows.Next() {
+		var table string
+		var col models.ColumnInfo
+		var columnDefault sql.NullString
+
+		if err := rows.Scan(&table, &col.Name, &col.Type, &col.Nullable, &columnDefault, &col.PrimaryKey); err != nil {
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+
+		if columnDefault.Valid {
+			col.Default = &columnDefault.String
+		}
+
+		columns[table] = append(columns[table], col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate columns: %w", err)
+	}
+	return columns, nil
+}
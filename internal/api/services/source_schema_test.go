@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSchemaCache(t *testing.T) {
+	cache := newSchemaCache(30 * time.Second)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	id := uuid.New()
+	if _, ok := cache.get(id, "public"); ok {
+		t.Fatal("get() on an empty cache found a result")
+	}
+
+	cache.put(id, "", "schemas")
+	cache.put(id, "public", "tables")
+	if got, ok := cache.get(id, "public"); !ok || got != "tables" {
+		t.Errorf("get(public) = %v, %v, want the cached tables", got, ok)
+	}
+	if _, ok := cache.get(uuid.New(), "public"); ok {
+		t.Error("get() found a result cached for another source")
+	}
+
+	// Results expire after the TTL
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.get(id, "public"); ok {
+		t.Error("get() returned an expired result")
+	}
+
+	// A changed source drops its results
+	cache.put(id, "public", "tables")
+	cache.drop(id)
+	if _, ok := cache.get(id, "public"); ok {
+		t.Error("get() returned a result of a dropped source")
+	}
+}
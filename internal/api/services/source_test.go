@@ -265,3 +265,33 @@ func TestCreateSourceRequest_Validate_Port(t *testing.T) {
 		t.Error("expected port field error")
 	}
 }
+
+func TestReplicaIdentityProblem(t *testing.T) {
+	tests := []struct {
+		identity         string
+		hasPrimaryKey    bool
+		hasIdentityIndex bool
+		wantProblem      bool
+	}{
+		{"d", true, false, false},
+		{"d", false, false, true},
+		{"f", false, false, false},
+		{"i", false, true, false},
+		{"i", true, false, true},
+		{"n", true, false, true},
+	}
+	for _, tt := range tests {
+		problem := replicaIdentityProblem(tt.identity, tt.hasPrimaryKey, tt.hasIdentityIndex)
+		if (problem != "") != tt.wantProblem {
+			t.Errorf("replicaIdentityProblem(%q, %v, %v) = %q, want problem %v",
+				tt.identity, tt.hasPrimaryKey, tt.hasIdentityIndex, problem, tt.wantProblem)
+		}
+	}
+
+	if schema, name := splitTableName("orders"); schema != "public" || name != "orders" {
+		t.Errorf("splitTableName(orders) = %s.%s, want public.orders", schema, name)
+	}
+	if schema, name := splitTableName("sales.orders"); schema != "sales" || name != "orders" {
+		t.Errorf("splitTableName(sales.orders) = %s.%s", schema, name)
+	}
+}
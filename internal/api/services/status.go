@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/cdc/health"
+)
+
+// workerJob is the Prometheus job the CDC workers are scraped under.
+const workerJob = "philotes-worker"
+
+// PipelineLister lists pipelines for the status summary.
+type PipelineLister interface {
+	List(ctx context.Context) ([]models.Pipeline, error)
+}
+
+// MetricsQuerier runs instant Prometheus queries.
+type MetricsQuerier interface {
+	QueryInstant(ctx context.Context, query string) ([]PrometheusResult, error)
+}
+
+// StatusService builds a one-call summary of the deployment: API health,
+// pipelines, workers, buffer depth and replication lag.
+type StatusService struct {
+	pipelines     PipelineLister
+	healthManager *health.Manager
+	metrics       MetricsQuerier
+	logger        *slog.Logger
+}
+
+// NewStatusService creates a new StatusService. healthManager and metrics
+// may be nil; the parts of the summary they provide are then reported as
+// healthy and unknown respectively.
+func NewStatusService(pipelines PipelineLister, healthManager *health.Manager, metrics MetricsQuerier, logger *slog.Logger) *StatusService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &StatusService{
+		pipelines:     pipelines,
+		healthManager: healthManager,
+		metrics:       metrics,
+		logger:        logger.With("component", "status-service"),
+	}
+}
+
+// GetStatus returns the current system status.
+func (s *StatusService) GetStatus(ctx context.Context) (*models.SystemStatus, error) {
+	pipelines, err := s.pipelines.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+
+	status := &models.SystemStatus{
+		API:       string(health.StatusHealthy),
+		Workers:   models.WorkerStatus{Status: string(health.StatusUnknown)},
+		Timestamp: time.Now(),
+	}
+
+	if s.healthManager != nil {
+		status.API = string(s.healthManager.GetOverallStatus(ctx).Status)
+	}
+
+	status.Pipelines.Total = len(pipelines)
+	for i := range pipelines {
+		switch pipelines[i].Status {
+		case models.PipelineStatusRunning:
+			status.Pipelines.Running++
+		case models.PipelineStatusStopped:
+			status.Pipelines.Stopped++
+		case models.PipelineStatusError:
+			status.Pipelines.Error++
+		}
+	}
+
+	if s.metrics != nil {
+		s.addMetrics(ctx, status)
+	}
+
+	status.Status = overallStatus(status)
+	return status, nil
+}
+
+// addMetrics fills in the parts of the summary that come from Prometheus.
+// Query failures leave the affected fields unknown rather than failing the
+// whole summary.
+func (s *StatusService) addMetrics(ctx context.Context, status *models.SystemStatus) {
+	//nolint:gocritic // PromQL requires literal double quotes, not escaped quotes from %q
+	upQuery := fmt.Sprintf(`up{job="%s"}`, workerJob)
+	if results, err := s.metrics.QueryInstant(ctx, upQuery); err != nil {
+		s.logger.Warn("failed to query worker availability", "error", err)
+	} else {
+		status.Workers = workerStatus(results)
+	}
+
+	if results, err := s.metrics.QueryInstant(ctx, "sum(philotes_buffer_depth)"); err != nil {
+		s.logger.Warn("failed to query buffer depth", "error", err)
+	} else {
+		depth := GetScalarInt(results)
+		status.BufferDepth = &depth
+	}
+
+	if results, err := s.metrics.QueryInstant(ctx, "max(philotes_cdc_replication_lag_bytes)"); err != nil {
+		s.logger.Warn("failed to query replication lag", "error", err)
+	} else {
+		lag := GetScalarInt(results)
+		status.ReplicationLagBytes = &lag
+	}
+}
+
+// workerStatus summarizes the results of an up{} query.
+func workerStatus(results []PrometheusResult) models.WorkerStatus {
+	workers := models.WorkerStatus{Total: len(results)}
+	for _, r := range results {
+		if GetScalarValue([]PrometheusResult{r}) == 1 {
+			workers.Up++
+		}
+	}
+
+	switch {
+	case workers.Total == 0:
+		workers.Status = string(health.StatusUnknown)
+	case workers.Up == 0:
+		workers.Status = string(health.StatusUnhealthy)
+	case workers.Up < workers.Total:
+		workers.Status = string(health.StatusDegraded)
+	default:
+		workers.Status = string(health.StatusHealthy)
+	}
+	return workers
+}
+
+// overallStatus combines the API, worker and pipeline states. Unknown
+// worker health, such as when Prometheus is not configured, does not
+// affect the result.
+func overallStatus(status *models.SystemStatus) string {
+	api := health.Status(status.API)
+	workers := health.Status(status.Workers.Status)
+
+	switch {
+	case api == health.StatusUnhealthy || workers == health.StatusUnhealthy:
+		return string(health.StatusUnhealthy)
+	case api == health.StatusDegraded || workers == health.StatusDegraded || status.Pipelines.Error > 0:
+		return string(health.StatusDegraded)
+	case api == health.StatusUnknown:
+		return string(health.StatusUnknown)
+	}
+	return string(health.StatusHealthy)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+type fakePipelineLister struct {
+	pipelines []models.Pipeline
+}
+
+func (f *fakePipelineLister) List(_ context.Context) ([]models.Pipeline, error) {
+	return f.pipelines, nil
+}
+
+// fakeMetricsQuerier answers queries from a map; missing queries fail.
+type fakeMetricsQuerier struct {
+	results map[string][]PrometheusResult
+}
+
+func (f *fakeMetricsQuerier) QueryInstant(_ context.Context, query string) ([]PrometheusResult, error) {
+	results, ok := f.results[query]
+	if !ok {
+		return nil, errors.New("no data")
+	}
+	return results, nil
+}
+
+func sample(value string) PrometheusResult {
+	return PrometheusResult{Value: []interface{}{float64(0), value}}
+}
+
+func TestStatusService_GetStatus(t *testing.T) {
+	pipelines := &fakePipelineLister{pipelines: []models.Pipeline{
+		{Status: models.PipelineStatusRunning},
+		{Status: models.PipelineStatusRunning},
+		{Status: models.PipelineStatusStopped},
+	}}
+	metrics := &fakeMetricsQuerier{results: map[string][]PrometheusResult{
+		`up{job="philotes-worker"}`:               {sample("1"), sample("1")},
+		"sum(philotes_buffer_depth)":              {sample("42")},
+		"max(philotes_cdc_replication_lag_bytes)": {sample("1024")},
+	}}
+
+	status, err := NewStatusService(pipelines, nil, metrics, nil).GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+
+	if status.Status != "healthy" {
+		t.Errorf("Status = %q, want healthy", status.Status)
+	}
+	want := models.PipelineStatusCounts{Total: 3, Running: 2, Stopped: 1}
+	if status.Pipelines != want {
+		t.Errorf("Pipelines = %+v, want %+v", status.Pipelines, want)
+	}
+	if status.Workers.Up != 2 || status.Workers.Total != 2 || status.Workers.Status != "healthy" {
+		t.Errorf("Workers = %+v, want 2 of 2 healthy", status.Workers)
+	}
+	if status.BufferDepth == nil || *status.BufferDepth != 42 {
+		t.Errorf("BufferDepth = %v, want 42", status.BufferDepth)
+	}
+	if status.ReplicationLagBytes == nil || *status.ReplicationLagBytes != 1024 {
+		t.Errorf("ReplicationLagBytes = %v, want 1024", status.ReplicationLagBytes)
+	}
+}
+
+func TestStatusService_GetStatusDegraded(t *testing.T) {
+	tests := []struct {
+		name      string
+		pipelines []models.Pipeline
+		workers   []PrometheusResult
+		want      string
+	}{
+		{
+			name:      "pipeline in error",
+			pipelines: []models.Pipeline{{Status: models.PipelineStatusError}},
+			workers:   []PrometheusResult{sample("1")},
+			want:      "degraded",
+		},
+		{
+			name:    "some workers down",
+			workers: []PrometheusResult{sample("1"), sample("0")},
+			want:    "degraded",
+		},
+		{
+			name:    "all workers down",
+			workers: []PrometheusResult{sample("0")},
+			want:    "unhealthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &fakeMetricsQuerier{results: map[string][]PrometheusResult{
+				`up{job="philotes-worker"}`: tt.workers,
+			}}
+			svc := NewStatusService(&fakePipelineLister{pipelines: tt.pipelines}, nil, metrics, nil)
+
+			status, err := svc.GetStatus(context.Background())
+			if err != nil {
+				t.Fatalf("GetStatus() error = %v", err)
+			}
+			if status.Status != tt.want {
+				t.Errorf("Status = %q, want %q", status.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusService_GetStatusWithoutMetrics(t *testing.T) {
+	status, err := NewStatusService(&fakePipelineLister{}, nil, nil, nil).GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+
+	// Unknown worker health does not make the system unhealthy
+	if status.Status != "healthy" || status.Workers.Status != "unknown" {
+		t.Errorf("got status %q with workers %q, want healthy with unknown workers", status.Status, status.Workers.Status)
+	}
+	if status.BufferDepth != nil || status.ReplicationLagBytes != nil {
+		t.Errorf("expected no buffer depth or lag without metrics, got %v and %v", status.BufferDepth, status.ReplicationLagBytes)
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// TableService exposes Iceberg metadata for managed tables.
+type TableService struct {
+	pipelineRepo *repositories.PipelineRepository
+	catalog      catalog.Catalog
+	cfg          config.IcebergConfig
+	logger       *slog.Logger
+}
+
+// NewTableService creates a new TableService.
+func NewTableService(
+	pipelineRepo *repositories.PipelineRepository,
+	cat catalog.Catalog,
+	cfg config.IcebergConfig,
+	logger *slog.Logger,
+) *TableService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &TableService{
+		pipelineRepo: pipelineRepo,
+		catalog:      cat,
+		cfg:          cfg,
+		logger:       logger.With("component", "table-service"),
+	}
+}
+
+// GetSnapshots returns the snapshots of a managed table, newest first.
+func (s *TableService) GetSnapshots(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID) (*models.TableSnapshotsResponse, error) {
+	ref, meta, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return buildSnapshotsResponse(ref, meta, s.cfg.MetadataSnapshotLimit), nil
+}
+
+// GetFilesSummary returns data file statistics of a managed table.
+func (s *TableService) GetFilesSummary(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID) (*models.TableFilesSummaryResponse, error) {
+	ref, meta, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := meta.SummarizeFiles()
+	return &models.TableFilesSummaryResponse{
+		Table:   ref,
+		Summary: summary,
+		CompactionRecommended: summary.DataFiles > 1 &&
+			summary.AvgFileSizeBytes < int64(s.cfg.SmallFileThresholdBytes),
+	}, nil
+}
+
+// GetPartitions returns per-partition statistics of a managed table.
+func (s *TableService) GetPartitions(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID) (*models.TablePartitionsResponse, error) {
+	ref, meta, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.TablePartitionsResponse{
+		Table:      ref,
+		Partitions: meta.PartitionStats(),
+	}
+	for _, spec := range meta.PartitionSpecs {
+		if spec.SpecID == meta.DefaultSpecID {
+			resp.PartitionSpec = spec
+		}
+	}
+	return resp, nil
+}
+
+// ListBranches returns the branches and tags of a managed table.
+func (s *TableService) ListBranches(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID) (*models.TableBranchesResponse, error) {
+	ref, meta, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return buildBranchesResponse(ref, meta), nil
+}
+
+// CreateBranch creates a branch or tag at the table's current snapshot.
+func (s *TableService) CreateBranch(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID, req *models.CreateTableBranchRequest) (*models.TableBranchesResponse, error) {
+	refType := req.Type
+	if refType == "" {
+		refType = iceberg.RefTypeBranch
+	}
+	var fieldErrors []models.FieldError
+	if req.Name == iceberg.MainBranch {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "name", Message: "main already exists"})
+	}
+	if refType != iceberg.RefTypeBranch && refType != iceberg.RefTypeTag {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: "type", Message: "must be branch or tag"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &ValidationError{Errors: fieldErrors}
+	}
+
+	branches, err := s.branchCatalog()
+	if err != nil {
+		return nil, err
+	}
+	ref, meta, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := meta.Refs[req.Name]; exists {
+		return nil, &ConflictError{Message: fmt.Sprintf("%s %q already exists", refType, req.Name)}
+	}
+
+	if refType == iceberg.RefTypeTag {
+		err = branches.CreateTag(ctx, ref.Namespace, ref.Table, req.Name)
+	} else {
+		err = branches.CreateBranch(ctx, ref.Namespace, ref.Table, req.Name)
+	}
+	if err != nil {
+		return nil, branchError(err, req.Name)
+	}
+
+	s.logger.Info("table ref created", "table", ref.Namespace+"."+ref.Table, "ref", req.Name, "type", refType)
+	return s.ListBranches(ctx, mappingID, tenantID)
+}
+
+// PromoteBranch atomically points the table's main branch at a branch and
+// removes the branch, so queries switch to the branch's data at once.
+func (s *TableService) PromoteBranch(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID, branch string) (*models.TableBranchesResponse, error) {
+	branches, err := s.branchCatalog()
+	if err != nil {
+		return nil, err
+	}
+	ref, _, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := branches.PromoteBranch(ctx, ref.Namespace, ref.Table, branch); err != nil {
+		return nil, branchError(err, branch)
+	}
+
+	s.logger.Info("table branch promoted", "table", ref.Namespace+"."+ref.Table, "branch", branch)
+	return s.ListBranches(ctx, mappingID, tenantID)
+}
+
+// DeleteBranch removes a branch or tag of a managed table.
+func (s *TableService) DeleteBranch(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID, name string) error {
+	if name == iceberg.MainBranch {
+		return &ValidationError{Errors: []models.FieldError{{Field: "branch", Message: "main cannot be deleted"}}}
+	}
+
+	branches, err := s.branchCatalog()
+	if err != nil {
+		return err
+	}
+	ref, _, err := s.loadTable(ctx, mappingID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := branches.DeleteRef(ctx, ref.Namespace, ref.Table, name); err != nil {
+		return branchError(err, name)
+	}
+
+	s.logger.Info("table ref deleted", "table", ref.Namespace+"."+ref.Table, "ref", name)
+	return nil
+}
+
+// branchCatalog returns the catalog if it supports branches.
+func (s *TableService) branchCatalog() (catalog.BranchCatalog, error) {
+	branches, ok := s.catalog.(catalog.BranchCatalog)
+	if !ok {
+		return nil, &ValidationError{Errors: []models.FieldError{{Field: "catalog", Message: "catalog does not support branches"}}}
+	}
+	return branches, nil
+}
+
+// branchError maps catalog ref errors to service errors.
+func branchError(err error, name string) error {
+	switch {
+	case errors.Is(err, catalog.ErrRefNotFound):
+		return &NotFoundError{Resource: "branch", ID: name}
+	case errors.Is(err, catalog.ErrCommitConflict):
+		return &ConflictError{Message: "table changed concurrently, retry the request"}
+	default:
+		return fmt.Errorf("failed to update table refs: %w", err)
+	}
+}
+
+// loadTable resolves a table mapping and loads its Iceberg metadata.
+func (s *TableService) loadTable(ctx context.Context, mappingID uuid.UUID, tenantID *uuid.UUID) (models.TableRef, *iceberg.TableMetadata, error) {
+	mapping, err := s.pipelineRepo.GetTableMappingByID(ctx, mappingID, tenantID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTableMappingNotFound) {
+			return models.TableRef{}, nil, &NotFoundError{Resource: "table", ID: mappingID.String()}
+		}
+		return models.TableRef{}, nil, fmt.Errorf("failed to get table mapping: %w", err)
+	}
+
+	return s.loadMappedTable(ctx, mapping)
+}
+
+// loadMappedTable loads the Iceberg metadata of the table a mapping writes to.
+func (s *TableService) loadMappedTable(ctx context.Context, mapping *models.TableMapping) (models.TableRef, *iceberg.TableMetadata, error) {
+	ref := models.TableRef{
+		MappingID:  mapping.ID,
+		PipelineID: mapping.PipelineID,
+		Namespace:  mapping.SourceSchema,
+		Table:      mapping.SourceTable,
+	}
+
+	exists, err := s.catalog.TableExists(ctx, ref.Namespace, ref.Table)
+	if err != nil {
+		return ref, nil, fmt.Errorf("failed to check iceberg table: %w", err)
+	}
+	if !exists {
+		return ref, nil, &NotFoundError{Resource: "iceberg table", ID: ref.Namespace + "." + ref.Table}
+	}
+
+	meta, err := s.catalog.LoadTable(ctx, ref.Namespace, ref.Table)
+	if err != nil {
+		s.logger.Error("failed to load iceberg table", "namespace", ref.Namespace, "table", ref.Table, "error", err)
+		return ref, nil, fmt.Errorf("failed to load iceberg table: %w", err)
+	}
+	return ref, meta, nil
+}
+
+// buildSnapshotsResponse converts table metadata to a snapshots response,
+// newest first and capped at limit (0 means unlimited).
+func buildSnapshotsResponse(ref models.TableRef, meta *iceberg.TableMetadata, limit int) *models.TableSnapshotsResponse {
+	snapshots := make([]models.TableSnapshot, 0, len(meta.Snapshots))
+	for _, snap := range meta.Snapshots {
+		snapshots = append(snapshots, models.TableSnapshot{
+			SnapshotID:       snap.SnapshotID,
+			ParentSnapshotID: snap.ParentSnapshotID,
+			Timestamp:        time.UnixMilli(snap.TimestampMs).UTC(),
+			Operation:        snap.Summary["operation"],
+			IsCurrent:        snap.SnapshotID == meta.CurrentSnapshotID,
+			Summary:          snap.Summary,
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.After(snapshots[j].Timestamp)
+	})
+
+	total := len(snapshots)
+	if limit > 0 && len(snapshots) > limit {
+		snapshots = snapshots[:limit]
+	}
+
+	return &models.TableSnapshotsResponse{
+		Table:             ref,
+		CurrentSnapshotID: meta.CurrentSnapshotID,
+		Snapshots:         snapshots,
+		TotalCount:        total,
+	}
+}
+
+// buildBranchesResponse lists a table's refs, with main first and the rest
+// sorted by name.
+func buildBranchesResponse(ref models.TableRef, meta *iceberg.TableMetadata) *models.TableBranchesResponse {
+	branches := make([]models.TableBranch, 0, len(meta.Refs)+1)
+	if _, ok := meta.Refs[iceberg.MainBranch]; !ok && meta.CurrentSnapshotID > 0 {
+		branches = append(branches, models.TableBranch{
+			Name:       iceberg.MainBranch,
+			Type:       iceberg.RefTypeBranch,
+			SnapshotID: meta.CurrentSnapshotID,
+		})
+	}
+	for name, r := range meta.Refs {
+		branches = append(branches, models.TableBranch{Name: name, Type: r.Type, SnapshotID: r.SnapshotID})
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		if (branches[i].Name == iceberg.MainBranch) != (branches[j].Name == iceberg.MainBranch) {
+			return branches[i].Name == iceberg.MainBranch
+		}
+		return branches[i].Name < branches[j].Name
+	})
+
+	return &models.TableBranchesResponse{Table: ref, Branches: branches}
+}
+
+// catalogSnapshots reads the current snapshots of tables from an Iceberg
+// catalog, whose namespaces are the query engine's schemas.
+type catalogSnapshots struct {
+	catalog catalog.Catalog
+}
+
+// NewCatalogSnapshotSource returns a query.SnapshotSource reading table
+// snapshots from cat.
+func NewCatalogSnapshotSource(cat catalog.Catalog) query.SnapshotSource {
+	return catalogSnapshots{catalog: cat}
+}
+
+// CurrentSnapshot returns the current snapshot ID of a table, or 0 when it
+// does not exist, as for names of common table expressions.
+func (c catalogSnapshots) CurrentSnapshot(ctx context.Context, schema, table string) (int64, error) {
+	exists, err := c.catalog.TableExists(ctx, schema, table)
+	if err != nil || !exists {
+		return 0, err
+	}
+	meta, err := c.catalog.LoadTable(ctx, schema, table)
+	if err != nil {
+		return 0, err
+	}
+	return meta.CurrentSnapshotID, nil
+}
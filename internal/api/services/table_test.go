@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+)
+
+// fakeCatalog is an in-memory catalog serving a single table.
+type fakeCatalog struct {
+	catalog.Catalog
+	namespace string
+	table     string
+	meta      *iceberg.TableMetadata
+}
+
+func (f *fakeCatalog) TableExists(_ context.Context, namespace, table string) (bool, error) {
+	return namespace == f.namespace && table == f.table, nil
+}
+
+func (f *fakeCatalog) LoadTable(_ context.Context, _, _ string) (*iceberg.TableMetadata, error) {
+	return f.meta, nil
+}
+
+func TestBuildSnapshotsResponse(t *testing.T) {
+	meta := &iceberg.TableMetadata{
+		CurrentSnapshotID: 2,
+		Snapshots: []iceberg.Snapshot{
+			{SnapshotID: 1, TimestampMs: 1000, Summary: map[string]string{"operation": "append"}},
+			{SnapshotID: 2, ParentSnapshotID: 1, TimestampMs: 2000, Summary: map[string]string{"operation": "overwrite"}},
+			{SnapshotID: 3, TimestampMs: 1500},
+		},
+	}
+
+	resp := buildSnapshotsResponse(models.TableRef{Table: "users"}, meta, 2)
+
+	if resp.TotalCount != 3 {
+		t.Errorf("TotalCount = %d, want 3", resp.TotalCount)
+	}
+	if len(resp.Snapshots) != 2 {
+		t.Fatalf("len(Snapshots) = %d, want 2", len(resp.Snapshots))
+	}
+	if resp.Snapshots[0].SnapshotID != 2 || !resp.Snapshots[0].IsCurrent {
+		t.Errorf("Snapshots[0] = %+v, want current snapshot 2", resp.Snapshots[0])
+	}
+	if resp.Snapshots[0].Operation != "overwrite" {
+		t.Errorf("Operation = %q, want %q", resp.Snapshots[0].Operation, "overwrite")
+	}
+	if resp.Snapshots[1].SnapshotID != 3 {
+		t.Errorf("Snapshots[1].SnapshotID = %d, want 3", resp.Snapshots[1].SnapshotID)
+	}
+}
+
+func TestTableService_LoadMappedTable(t *testing.T) {
+	meta := &iceberg.TableMetadata{TableUUID: "abc"}
+	svc := NewTableService(nil, &fakeCatalog{namespace: "public", table: "users", meta: meta}, config.IcebergConfig{}, nil)
+
+	mapping := &models.TableMapping{ID: uuid.New(), PipelineID: uuid.New(), SourceSchema: "public", SourceTable: "users"}
+	ref, got, err := svc.loadMappedTable(context.Background(), mapping)
+	if err != nil {
+		t.Fatalf("loadMappedTable() error = %v", err)
+	}
+	if got != meta {
+		t.Error("expected catalog metadata to be returned")
+	}
+	if ref.MappingID != mapping.ID || ref.Namespace != "public" || ref.Table != "users" {
+		t.Errorf("unexpected ref: %+v", ref)
+	}
+
+	mapping.SourceTable = "orders"
+	_, _, err = svc.loadMappedTable(context.Background(), mapping)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("error = %v, want NotFoundError", err)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+	"github.com/janovincze/philotes/internal/api/repositories"
+)
+
+// tokenRevocationCleanupInterval is how often expired revocations are
+// deleted.
+const tokenRevocationCleanupInterval = time.Hour
+
+// TokenRevocationStore is the persistence used by TokenRevocationService.
+type TokenRevocationStore interface {
+	RevokeToken(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+var _ TokenRevocationStore = (*repositories.TokenRevocationRepository)(nil)
+
+// TokenRevocationService keeps the denylist of JWTs revoked before they
+// expire: single tokens on logout, and every token of an identity provider
+// session or a user on OIDC back-channel logout. Revocations are deleted
+// once the tokens they match have expired.
+type TokenRevocationService struct {
+	store    TokenRevocationStore
+	tokenTTL time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewTokenRevocationService creates a new TokenRevocationService. The token
+// TTL is the lifetime of issued JWTs; revocations that match tokens by
+// session or user are kept that long.
+func NewTokenRevocationService(store TokenRevocationStore, tokenTTL time.Duration, logger *slog.Logger) *TokenRevocationService {
+	return &TokenRevocationService{
+		store:    store,
+		tokenTTL: tokenTTL,
+		logger:   logger.With("component", "token-revocation-service"),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// RevokeToken revokes a single token. Tokens issued without an ID cannot
+// be revoked alone; for those every token of the user issued up to then is
+// revoked.
+func (s *TokenRevocationService) RevokeToken(ctx context.Context, claims *models.JWTClaims) error {
+	expiresAt := s.now().Add(s.tokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	if claims.ID == "" {
+		return s.store.RevokeUserTokens(ctx, claims.UserID, s.now(), expiresAt)
+	}
+	return s.store.RevokeToken(ctx, claims.ID, claims.UserID, expiresAt)
+}
+
+// RevokeSession revokes every token issued for an identity provider
+// session.
+func (s *TokenRevocationService) RevokeSession(ctx context.Context, sessionID string) error {
+	return s.store.RevokeSession(ctx, sessionID, s.now().Add(s.tokenTTL))
+}
+
+// RevokeUser revokes every token of a user issued until now.
+func (s *TokenRevocationService) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	now := s.now()
+	return s.store.RevokeUserTokens(ctx, userID, now, now.Add(s.tokenTTL))
+}
+
+// IsRevoked reports whether a token has been revoked.
+func (s *TokenRevocationService) IsRevoked(ctx context.Context, claims *models.JWTClaims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return s.store.IsRevoked(ctx, claims.ID, claims.SessionID, claims.UserID, issuedAt)
+}
+
+// --- Cleanup ---
+
+// Start starts the periodic cleanup of expired revocations.
+func (s *TokenRevocationService) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.runLoop(ctx)
+}
+
+// Stop stops the periodic cleanup.
+func (s *TokenRevocationService) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *TokenRevocationService) runLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(tokenRevocationCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Cleanup(ctx); err != nil {
+			s.logger.Error("token revocation cleanup failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cleanup deletes expired revocations and returns how many were deleted.
+func (s *TokenRevocationService) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := s.store.DeleteExpired(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("cleanup expired token revocations: %w", err)
+	}
+
+	if deleted > 0 {
+		s.logger.Info("cleaned up expired token revocations", "count", deleted)
+	}
+	return deleted, nil
+}
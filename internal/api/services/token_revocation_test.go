@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// fakeRevocationStore keeps revocations in memory, matching tokens the way
+// the repository's query does.
+type fakeRevocationStore struct {
+	tokens   map[string]time.Time
+	sessions map[string]time.Time
+	users    map[uuid.UUID][2]time.Time // revoked before, expires at
+}
+
+func newFakeRevocationStore() *fakeRevocationStore {
+	return &fakeRevocationStore{
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		users:    make(map[uuid.UUID][2]time.Time),
+	}
+}
+
+func (f *fakeRevocationStore) RevokeToken(_ context.Context, tokenID string, _ uuid.UUID, expiresAt time.Time) error {
+	f.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (f *fakeRevocationStore) RevokeSession(_ context.Context, sessionID string, expiresAt time.Time) error {
+	f.sessions[sessionID] = expiresAt
+	return nil
+}
+
+func (f *fakeRevocationStore) RevokeUserTokens(_ context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	f.users[userID] = [2]time.Time{before, expiresAt}
+	return nil
+}
+
+func (f *fakeRevocationStore) IsRevoked(_ context.Context, tokenID, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	if _, ok := f.tokens[tokenID]; ok && tokenID != "" {
+		return true, nil
+	}
+	if _, ok := f.sessions[sessionID]; ok && sessionID != "" {
+		return true, nil
+	}
+	if user, ok := f.users[userID]; ok && user[0].After(issuedAt) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (f *fakeRevocationStore) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for id, expiresAt := range f.tokens {
+		if !expiresAt.After(now) {
+			delete(f.tokens, id)
+			deleted++
+		}
+	}
+	for id, expiresAt := range f.sessions {
+		if !expiresAt.After(now) {
+			delete(f.sessions, id)
+			deleted++
+		}
+	}
+	for id, user := range f.users {
+		if !user[1].After(now) {
+			delete(f.users, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTokenRevocationTestService() (*TokenRevocationService, *fakeRevocationStore, *time.Time) {
+	store := newFakeRevocationStore()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewTokenRevocationService(store, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.now = func() time.Time { return now }
+	return s, store, &now
+}
+
+// testClaims returns the claims of a token issued at the given time.
+func testClaims(userID uuid.UUID, tokenID, sessionID string, issuedAt time.Time) *models.JWTClaims {
+	return &models.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		},
+		UserID:    userID,
+		SessionID: sessionID,
+	}
+}
+
+func assertRevoked(t *testing.T, s *TokenRevocationService, claims *models.JWTClaims, want bool) {
+	t.Helper()
+	revoked, err := s.IsRevoked(context.Background(), claims)
+	if err != nil {
+		t.Fatalf("IsRevoked() error = %v", err)
+	}
+	if revoked != want {
+		t.Errorf("IsRevoked(jti %q, sid %q) = %v, want %v", claims.ID, claims.SessionID, revoked, want)
+	}
+}
+
+func TestTokenRevocationService_RevokeToken(t *testing.T) {
+	s, _, now := newTokenRevocationTestService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	current := testClaims(userID, "token-1", "", now.Add(-time.Minute))
+	other := testClaims(userID, "token-2", "", now.Add(-time.Minute))
+	if err := s.RevokeToken(ctx, current); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	assertRevoked(t, s, current, true)
+	assertRevoked(t, s, other, false)
+
+	// Tokens without an ID revoke every token of the user issued until now
+	legacy := testClaims(userID, "", "", now.Add(-time.Minute))
+	if err := s.RevokeToken(ctx, legacy); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	assertRevoked(t, s, legacy, true)
+	assertRevoked(t, s, other, true)
+	assertRevoked(t, s, testClaims(userID, "token-3", "", now.Add(time.Second)), false)
+}
+
+func TestTokenRevocationService_RevokeSessionAndUser(t *testing.T) {
+	s, _, now := newTokenRevocationTestService()
+	ctx := context.Background()
+	userID, otherUserID := uuid.New(), uuid.New()
+
+	if err := s.RevokeSession(ctx, "provider/session-1"); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	assertRevoked(t, s, testClaims(otherUserID, "token-1", "provider/session-1", now.Add(-time.Minute)), true)
+	assertRevoked(t, s, testClaims(otherUserID, "token-2", "provider/session-2", now.Add(-time.Minute)), false)
+	assertRevoked(t, s, testClaims(otherUserID, "token-3", "", now.Add(-time.Minute)), false)
+
+	if err := s.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+	assertRevoked(t, s, testClaims(userID, "token-4", "", now.Add(-time.Minute)), true)
+	assertRevoked(t, s, testClaims(otherUserID, "token-5", "", now.Add(-time.Minute)), false)
+
+	// Logging in again after the logout issues a valid token
+	assertRevoked(t, s, testClaims(userID, "token-6", "", now.Add(time.Second)), false)
+}
+
+func TestTokenRevocationService_Cleanup(t *testing.T) {
+	s, store, now := newTokenRevocationTestService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	claims := testClaims(userID, "token-1", "", now.Add(-30*time.Minute))
+	if err := s.RevokeToken(ctx, claims); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if err := s.RevokeSession(ctx, "provider/session-1"); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if err := s.RevokeUser(ctx, userID); err != nil {
+		t.Fatalf("RevokeUser() error = %v", err)
+	}
+
+	// The token expires after 30 minutes, the others after the token TTL
+	*now = now.Add(31 * time.Minute)
+	if deleted, err := s.Cleanup(ctx); err != nil || deleted != 1 {
+		t.Fatalf("Cleanup() = %d, %v, want the expired token deleted", deleted, err)
+	}
+	*now = now.Add(30 * time.Minute)
+	if deleted, err := s.Cleanup(ctx); err != nil || deleted != 2 {
+		t.Fatalf("Cleanup() = %d, %v, want the session and user deleted", deleted, err)
+	}
+	if len(store.tokens)+len(store.sessions)+len(store.users) != 0 {
+		t.Errorf("revocations left after cleanup: %+v", store)
+	}
+}
@@ -3,11 +3,13 @@ package buffer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"math"
 	"sync"
 	"time"
 
+	"github.com/janovincze/philotes/internal/cdc"
 	"github.com/janovincze/philotes/internal/cdc/deadletter"
 	"github.com/janovincze/philotes/internal/metrics"
 )
@@ -15,19 +17,73 @@ import (
 // BatchHandler is called when a batch of events is ready for processing.
 type BatchHandler func(ctx context.Context, events []BufferedEvent) error
 
+// RejectedEventsError is returned by a BatchHandler that processed a batch
+// except for events it can never process, such as rows whose columns no
+// longer fit the destination schema. The processor sends the rejected
+// events to the dead-letter queue without retrying and marks the whole
+// batch processed.
+type RejectedEventsError struct {
+	// Events are the rejected events.
+	Events []BufferedEvent
+
+	// Type classifies the failure in the dead-letter queue.
+	Type deadletter.ErrorType
+
+	// Err is the reason the events were rejected.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RejectedEventsError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the reason the events were rejected.
+func (e *RejectedEventsError) Unwrap() error {
+	return e.Err
+}
+
 // BatchProcessor reads events from the buffer in batches and processes them.
 type BatchProcessor struct {
 	manager    Manager
 	handler    BatchHandler
 	deadLetter deadletter.Manager
+	scheduler  *Scheduler
 	logger     *slog.Logger
 	config     BatchConfig
 
+	// cleanupStats records the buffer state after each cleanup
+	cleanupStats CleanupStatsRecorder
+
 	mu      sync.RWMutex
 	running bool
 	stopCh  chan struct{}
-	wg      sync.WaitGroup
-	stats   BatchStats
+
+	// settingsCh tells a running loop that SetBatchSettings changed the
+	// flush interval
+	settingsCh chan struct{}
+	wg         sync.WaitGroup
+	stats      BatchStats
+
+	// lanes bounds the tables flushed concurrently
+	lanes chan struct{}
+
+	// busy holds the tables whose batches are queued or being flushed, and
+	// inflight counts their events. Their events are read again on every
+	// tick but left to the table's lane.
+	busy     map[string]bool
+	inflight int
+
+	// backlogTables are the tables whose backlog metrics have been set
+	backlogTables map[string]bool
+
+	// pending are the events read that the committed event has not moved
+	// over yet, oldest first
+	pending []BufferedEvent
+
+	// committed is the last streamed event committed by the handler
+	// together with every event buffered before it
+	committed *cdc.Event
 }
 
 // BatchStats holds batch processing statistics.
@@ -65,6 +121,18 @@ type BatchConfig struct {
 	// DLQ configuration
 	DLQEnabled   bool
 	DLQRetention time.Duration
+
+	// TableOverrides replaces BatchSize and FlushInterval for individual
+	// tables, keyed by "schema.table".
+	TableOverrides map[string]TableBatchConfig
+
+	// MaxParallelTables bounds how many tables' batches are flushed
+	// concurrently. Values below one flush one table at a time.
+	MaxParallelTables int
+
+	// Priority weighs the pipeline's share of the writer slots of a
+	// Scheduler it shares with other pipelines (higher gets more).
+	Priority int
 }
 
 // DefaultBatchConfig returns a BatchConfig with sensible defaults.
@@ -80,6 +148,8 @@ func DefaultBatchConfig() BatchConfig {
 		RetryMultiplier:      2.0,
 		DLQEnabled:           true,
 		DLQRetention:         168 * time.Hour, // 7 days
+		MaxParallelTables:    4,
+		Priority:             DefaultPriority,
 	}
 }
 
@@ -90,11 +160,15 @@ func NewBatchProcessor(manager Manager, handler BatchHandler, cfg BatchConfig, l
 	}
 
 	return &BatchProcessor{
-		manager: manager,
-		handler: handler,
-		logger:  logger.With("component", "batch-processor"),
-		config:  cfg,
-		stopCh:  make(chan struct{}),
+		manager:       manager,
+		handler:       handler,
+		logger:        logger.With("component", "batch-processor"),
+		config:        cfg,
+		stopCh:        make(chan struct{}),
+		settingsCh:    make(chan struct{}, 1),
+		lanes:         make(chan struct{}, max(cfg.MaxParallelTables, 1)),
+		busy:          make(map[string]bool),
+		backlogTables: make(map[string]bool),
 	}
 }
 
@@ -103,6 +177,47 @@ func (p *BatchProcessor) SetDeadLetterManager(dlq deadletter.Manager) {
 	p.deadLetter = dlq
 }
 
+// SetCleanupStatsRecorder sets the recorder of the buffer state after each
+// cleanup run.
+func (p *BatchProcessor) SetCleanupStatsRecorder(recorder CleanupStatsRecorder) {
+	p.cleanupStats = recorder
+}
+
+// SetScheduler makes the processor take a writer slot from s for each
+// buffer read and each batch it writes, registered under the source ID with
+// the configured priority.
+func (p *BatchProcessor) SetScheduler(s *Scheduler) {
+	s.Register(p.config.SourceID, p.config.Priority)
+	p.scheduler = s
+}
+
+// SetBatchSettings changes the batch size and flush interval of tables
+// without overrides; zero values keep the current ones. A running processor
+// waits the new flush interval from the time of the change.
+func (p *BatchProcessor) SetBatchSettings(batchSize int, flushInterval time.Duration) {
+	p.mu.Lock()
+	if batchSize > 0 {
+		p.config.BatchSize = batchSize
+	}
+	if flushInterval > 0 {
+		p.config.FlushInterval = flushInterval
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.settingsCh <- struct{}{}:
+	default:
+	}
+}
+
+// batchConfig returns a copy of the configuration, whose batch settings
+// SetBatchSettings may change while the processor runs.
+func (p *BatchProcessor) batchConfig() BatchConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
 // Start begins processing batches.
 func (p *BatchProcessor) Start(ctx context.Context) error {
 	p.mu.Lock()
@@ -111,13 +226,16 @@ func (p *BatchProcessor) Start(ctx context.Context) error {
 		return nil
 	}
 	p.running = true
+	cfg := p.config
 	p.mu.Unlock()
 
 	p.logger.Info("starting batch processor",
-		"batch_size", p.config.BatchSize,
-		"flush_interval", p.config.FlushInterval,
+		"batch_size", cfg.BatchSize,
+		"flush_interval", cfg.FlushInterval,
 		"retry_max_attempts", p.config.RetryMaxAttempts,
 		"dlq_enabled", p.config.DLQEnabled,
+		"priority", p.config.Priority,
+		"table_overrides", len(p.config.TableOverrides),
 	)
 
 	// Start the processing goroutine
@@ -165,7 +283,8 @@ func (p *BatchProcessor) Stop(ctx context.Context) error {
 func (p *BatchProcessor) processLoop(ctx context.Context) {
 	defer p.wg.Done()
 
-	ticker := time.NewTicker(p.config.FlushInterval)
+	interval := p.batchConfig().tickInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -174,11 +293,18 @@ func (p *BatchProcessor) processLoop(ctx context.Context) {
 			return
 		case <-p.stopCh:
 			return
+		case <-p.settingsCh:
+			if next := p.batchConfig().tickInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
 		case <-ticker.C:
+			cfg := p.batchConfig()
+
 			// Update buffer depth metric
 			p.updateBufferDepthMetric(ctx)
 
-			if err := p.processBatchWithRetry(ctx); err != nil {
+			if err := p.processBatchWithRetry(ctx, cfg); err != nil {
 				p.logger.Error("failed to process batch", "error", err)
 			}
 		}
@@ -195,23 +321,235 @@ func (p *BatchProcessor) updateBufferDepthMetric(ctx context.Context) {
 	metrics.BufferDepth.WithLabelValues(p.config.SourceID).Set(float64(stats.UnprocessedEvents))
 }
 
-func (p *BatchProcessor) processBatchWithRetry(ctx context.Context) error {
-	// Read a batch of unprocessed events
-	events, err := p.manager.ReadBatch(ctx, p.config.SourceID, p.config.BatchSize)
+// processBatchWithRetry reads the oldest unprocessed events and hands the
+// due batches of each table to the table's lane. Lanes flush concurrently,
+// up to MaxParallelTables at a time, and retry or dead-letter their batches
+// independently: a table still busy with an earlier flush is skipped, so a
+// failing table does not hold up the others. It returns without waiting for
+// the flushes.
+func (p *BatchProcessor) processBatchWithRetry(ctx context.Context, cfg BatchConfig) error {
+	// Busy tables' events are read again, so leave room for the rest
+	p.mu.RLock()
+	inflight := p.inflight
+	p.mu.RUnlock()
+
+	events, err := p.readBatch(ctx, cfg.SourceID, cfg.readLimit()+inflight)
 	if err != nil {
 		return err
 	}
 
-	if len(events) == 0 {
-		return nil
+	now := time.Now()
+	p.advanceCommitted(events)
+	p.updateTableMetrics(events, now)
+
+	for _, lane := range tableLanes(cfg.dueBatches(events, now)) {
+		p.startLane(ctx, lane)
+	}
+	return nil
+}
+
+// readBatch reads unprocessed events, holding a writer slot of the
+// scheduler if one is set.
+func (p *BatchProcessor) readBatch(ctx context.Context, sourceID string, limit int) ([]BufferedEvent, error) {
+	if p.scheduler != nil {
+		if err := p.scheduler.Acquire(ctx, sourceID); err != nil {
+			return nil, err
+		}
+		defer p.scheduler.Release(sourceID)
+	}
+	return p.manager.ReadBatch(ctx, sourceID, limit)
+}
+
+// laneBatches are the batches of one table, flushed in order.
+type laneBatches struct {
+	table   string
+	batches [][]BufferedEvent
+}
+
+// tableLanes splits batches by table, keeping the order of the tables and
+// of each table's events.
+func tableLanes(batches [][]BufferedEvent) []laneBatches {
+	index := make(map[string]int)
+	var lanes []laneBatches
+	for _, batch := range batches {
+		split := make(map[string][]BufferedEvent)
+		var order []string
+		for _, e := range batch {
+			key := e.Event.Schema + "." + e.Event.Table
+			if _, ok := split[key]; !ok {
+				order = append(order, key)
+			}
+			split[key] = append(split[key], e)
+		}
+		for _, key := range order {
+			i, ok := index[key]
+			if !ok {
+				i = len(lanes)
+				index[key] = i
+				lanes = append(lanes, laneBatches{table: key})
+			}
+			lanes[i].batches = append(lanes[i].batches, split[key])
+		}
+	}
+	return lanes
+}
+
+// startLane flushes a table's batches in the background, unless the table
+// is still busy with an earlier flush.
+func (p *BatchProcessor) startLane(ctx context.Context, lane laneBatches) {
+	count := 0
+	for _, batch := range lane.batches {
+		count += len(batch)
+	}
+
+	p.mu.Lock()
+	if p.busy[lane.table] {
+		p.mu.Unlock()
+		return
+	}
+	p.busy[lane.table] = true
+	p.inflight += count
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			p.mu.Lock()
+			delete(p.busy, lane.table)
+			p.inflight -= count
+			p.mu.Unlock()
+		}()
+
+		select {
+		case p.lanes <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		defer func() { <-p.lanes }()
+
+		for _, batch := range lane.batches {
+			if err := p.flush(ctx, batch); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				p.logger.Error("failed to process batch", "table", lane.table, "error", err)
+			}
+		}
+	}()
+}
+
+// updateTableMetrics sets the backlog and lag of each table from the
+// events read, and resets them for tables with nothing left to process.
+func (p *BatchProcessor) updateTableMetrics(events []BufferedEvent, now time.Time) {
+	backlog := make(map[string]int)
+	oldest := make(map[string]time.Time)
+	for _, e := range events {
+		key := e.Event.Schema + "." + e.Event.Table
+		if backlog[key] == 0 {
+			oldest[key] = e.CreatedAt
+		}
+		backlog[key]++
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for table := range p.backlogTables {
+		if backlog[table] == 0 {
+			metrics.BufferTableBacklog.WithLabelValues(p.config.SourceID, table).Set(0)
+			metrics.BufferTableLagSeconds.WithLabelValues(p.config.SourceID, table).Set(0)
+		}
+	}
+	for table, n := range backlog {
+		metrics.BufferTableBacklog.WithLabelValues(p.config.SourceID, table).Set(float64(n))
+		metrics.BufferTableLagSeconds.WithLabelValues(p.config.SourceID, table).Set(now.Sub(oldest[table]).Seconds())
+		p.backlogTables[table] = true
+	}
+}
+
+// advanceCommitted moves the committed event forward over the pending
+// events, up to the first one still unprocessed. The events read are the
+// oldest unprocessed ones, so every pending event before the first of them
+// is processed, along with everything buffered before it. Snapshot rows are
+// moved over but never become the committed event, as they carry their
+// snapshot's position rather than their own.
+func (p *BatchProcessor) advanceCommitted(read []BufferedEvent) {
+	unprocessed := make(map[int64]bool, len(read))
+	for _, e := range read {
+		unprocessed[e.ID] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := 0
+	for ; i < len(p.pending) && !unprocessed[p.pending[i].ID]; i++ {
+		if EventKey(p.pending[i].Event) != "" {
+			event := p.pending[i].Event
+			p.committed = &event
+		}
+	}
+
+	// Keep the rest, followed by the events read for the first time
+	pending := append([]BufferedEvent(nil), p.pending[i:]...)
+	seen := make(map[int64]bool, len(pending))
+	for _, e := range pending {
+		seen[e.ID] = true
+	}
+	for _, e := range read {
+		if !seen[e.ID] {
+			pending = append(pending, e)
+		}
+	}
+	p.pending = pending
+}
+
+// CommittedEvent returns the last streamed event the handler committed
+// together with every event buffered before it, and whether there is one.
+// Checkpointing at this event never skips a change that is not committed.
+func (p *BatchProcessor) CommittedEvent() (cdc.Event, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.committed == nil {
+		return cdc.Event{}, false
 	}
+	return *p.committed, true
+}
+
+// flush processes one batch and records how long it took. With a
+// scheduler, it waits for a writer slot first.
+func (p *BatchProcessor) flush(ctx context.Context, events []BufferedEvent) error {
+	if p.scheduler != nil {
+		if err := p.scheduler.Acquire(ctx, p.config.SourceID); err != nil {
+			return err
+		}
+		defer p.scheduler.Release(p.config.SourceID)
+	}
+
+	start := time.Now()
+	err := p.processEvents(ctx, events)
+
+	status := "success"
+	if err != nil {
+		status = "failed"
+	}
+	metrics.BufferFlushDuration.WithLabelValues(p.config.SourceID, status).Observe(time.Since(start).Seconds())
+	return err
+}
 
+// processEvents hands one batch to the handler with retries, sending it to
+// the dead-letter queue once the retries are exhausted.
+func (p *BatchProcessor) processEvents(ctx context.Context, events []BufferedEvent) error {
 	p.logger.Debug("processing batch", "count", len(events))
 
 	// Try to process with retries
 	var lastErr error
 	for attempt := 1; attempt <= p.config.RetryMaxAttempts; attempt++ {
 		err := p.handler(ctx, events)
+		var rejected *RejectedEventsError
+		if errors.As(err, &rejected) {
+			return p.rejectEvents(ctx, events, rejected)
+		}
 		if err == nil {
 			// Success - mark events as processed
 			eventIDs := make([]int64, len(events))
@@ -263,7 +601,7 @@ func (p *BatchProcessor) processBatchWithRetry(ctx context.Context) error {
 
 	// Max retries exceeded - send to DLQ if enabled
 	if p.config.DLQEnabled && p.deadLetter != nil {
-		p.sendToDLQ(ctx, events, lastErr)
+		p.sendToDLQ(ctx, events, lastErr, deadletter.ErrorTypeTransient)
 	}
 
 	p.mu.Lock()
@@ -285,6 +623,41 @@ func (p *BatchProcessor) processBatchWithRetry(ctx context.Context) error {
 	return lastErr
 }
 
+// rejectEvents sends the events a handler rejected to the dead-letter queue
+// and marks the batch processed; the handler processed the rest.
+func (p *BatchProcessor) rejectEvents(ctx context.Context, events []BufferedEvent, rejected *RejectedEventsError) error {
+	p.logger.Warn("batch handler rejected events, sending them to the dead-letter queue",
+		"rejected", len(rejected.Events),
+		"count", len(events),
+		"error", rejected.Err,
+	)
+
+	if p.config.DLQEnabled && p.deadLetter != nil {
+		p.sendToDLQ(ctx, rejected.Events, rejected.Err, rejected.Type)
+	} else {
+		p.logger.Error("dead-letter queue disabled, dropping rejected events", "count", len(rejected.Events))
+	}
+
+	eventIDs := make([]int64, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.ID
+	}
+	if err := p.manager.MarkProcessed(ctx, eventIDs); err != nil {
+		return err
+	}
+
+	written := len(events) - len(rejected.Events)
+	p.mu.Lock()
+	p.stats.BatchesProcessed++
+	p.stats.EventsProcessed += int64(written)
+	p.stats.EventsFailed += int64(len(rejected.Events))
+	p.mu.Unlock()
+
+	metrics.BufferBatchesTotal.WithLabelValues(p.config.SourceID, "partial").Inc()
+	metrics.BufferEventsProcessedTotal.WithLabelValues(p.config.SourceID).Add(float64(written))
+	return nil
+}
+
 func (p *BatchProcessor) calculateBackoff(attempt int) time.Duration {
 	backoff := float64(p.config.RetryInitialInterval) * math.Pow(p.config.RetryMultiplier, float64(attempt-1))
 	if backoff > float64(p.config.RetryMaxInterval) {
@@ -293,7 +666,7 @@ func (p *BatchProcessor) calculateBackoff(attempt int) time.Duration {
 	return time.Duration(backoff)
 }
 
-func (p *BatchProcessor) sendToDLQ(ctx context.Context, events []BufferedEvent, err error) {
+func (p *BatchProcessor) sendToDLQ(ctx context.Context, events []BufferedEvent, err error, errType deadletter.ErrorType) {
 	for _, bufferedEvent := range events {
 		eventData, marshalErr := json.Marshal(bufferedEvent.Event)
 		if marshalErr != nil {
@@ -315,7 +688,7 @@ func (p *BatchProcessor) sendToDLQ(ctx context.Context, events []BufferedEvent,
 			Operation:       string(bufferedEvent.Event.Operation),
 			EventData:       eventData,
 			ErrorMessage:    err.Error(),
-			ErrorType:       deadletter.ErrorTypeTransient,
+			ErrorType:       errType,
 			CreatedAt:       now,
 			ExpiresAt:       &expiresAt,
 		}
@@ -345,7 +718,7 @@ func (p *BatchProcessor) sendToDLQ(ctx context.Context, events []BufferedEvent,
 // Deprecated: This wrapper is kept for backward compatibility.
 // Use processBatchWithRetry instead.
 func (p *BatchProcessor) processBatch(ctx context.Context) error {
-	return p.processBatchWithRetry(ctx)
+	return p.processBatchWithRetry(ctx, p.batchConfig())
 }
 
 func (p *BatchProcessor) cleanupLoop(ctx context.Context) {
@@ -361,24 +734,56 @@ func (p *BatchProcessor) cleanupLoop(ctx context.Context) {
 		case <-p.stopCh:
 			return
 		case <-ticker.C:
-			deleted, err := p.manager.Cleanup(ctx, p.config.Retention)
-			if err != nil {
-				p.logger.Error("cleanup failed", "error", err)
-			} else if deleted > 0 {
-				p.logger.Info("cleanup completed", "deleted", deleted)
-			}
+			p.cleanup(ctx)
+		}
+	}
+}
 
-			// Also cleanup DLQ if enabled
-			if p.deadLetter != nil {
-				dlqDeleted, dlqErr := p.deadLetter.Cleanup(ctx)
-				if dlqErr != nil {
-					p.logger.Error("DLQ cleanup failed", "error", dlqErr)
-				} else if dlqDeleted > 0 {
-					p.logger.Info("DLQ cleanup completed", "deleted", dlqDeleted)
-				}
-			}
+// cleanup removes processed events past retention, records the buffer
+// state if a recorder is set, and cleans up the DLQ.
+func (p *BatchProcessor) cleanup(ctx context.Context) {
+	deleted, err := p.manager.Cleanup(ctx, p.config.Retention)
+	if err != nil {
+		p.logger.Error("cleanup failed", "error", err)
+	} else {
+		if deleted > 0 {
+			p.logger.Info("cleanup completed", "deleted", deleted)
+		}
+		if p.cleanupStats != nil {
+			p.recordCleanup(ctx, deleted, time.Now())
 		}
 	}
+
+	// Also cleanup DLQ if enabled
+	if p.deadLetter != nil {
+		dlqDeleted, dlqErr := p.deadLetter.Cleanup(ctx)
+		if dlqErr != nil {
+			p.logger.Error("DLQ cleanup failed", "error", dlqErr)
+		} else if dlqDeleted > 0 {
+			p.logger.Info("DLQ cleanup completed", "deleted", dlqDeleted)
+		}
+	}
+}
+
+// recordCleanup records the buffer state after a cleanup at cleanupAt that
+// deleted the given number of events. Only the source's own events are
+// counted when the manager supports it.
+func (p *BatchProcessor) recordCleanup(ctx context.Context, deleted int64, cleanupAt time.Time) {
+	var stats Stats
+	var err error
+	if reader, ok := p.manager.(SourceStatsReader); ok {
+		stats, err = reader.SourceStats(ctx)
+	} else {
+		stats, err = p.manager.Stats(ctx)
+	}
+	if err != nil {
+		p.logger.Warn("failed to read buffer stats after cleanup", "error", err)
+		return
+	}
+
+	if err := p.cleanupStats.RecordCleanup(ctx, NewCleanupStats(stats, deleted, cleanupAt)); err != nil {
+		p.logger.Warn("failed to record cleanup stats", "error", err)
+	}
 }
 
 // IsRunning returns whether the processor is currently running.
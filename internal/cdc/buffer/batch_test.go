@@ -2,11 +2,15 @@ package buffer
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/deadletter"
 )
 
 // mockManager implements Manager for testing.
@@ -16,6 +20,7 @@ type mockManager struct {
 	readBatchCalls int
 	processedIDs   []int64
 	cleanupCalls   int
+	cleanupDeleted int64
 	eventsToReturn []BufferedEvent
 	statsFn        func() Stats
 }
@@ -55,7 +60,7 @@ func (m *mockManager) Cleanup(ctx context.Context, retention time.Duration) (int
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.cleanupCalls++
-	return 0, nil
+	return m.cleanupDeleted, nil
 }
 
 func (m *mockManager) Stats(ctx context.Context) (Stats, error) {
@@ -218,6 +223,43 @@ func TestBatchProcessorProcessesEvents(t *testing.T) {
 	}
 }
 
+func TestBatchProcessorSetBatchSettings(t *testing.T) {
+	manager := newMockManager()
+	handler := func(ctx context.Context, events []BufferedEvent) error {
+		return nil
+	}
+
+	cfg := DefaultBatchConfig()
+	cfg.FlushInterval = time.Hour
+	cfg.CleanupInterval = 0
+
+	processor := NewBatchProcessor(manager, handler, cfg, nil)
+	if err := processor.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	defer processor.Stop(context.Background())
+
+	processor.SetBatchSettings(50, 10*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		manager.mu.Lock()
+		calls := manager.readBatchCalls
+		manager.mu.Unlock()
+		if calls >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the new flush interval without waiting for the old one, got %d reads", calls)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := processor.batchConfig().BatchSize; got != 50 {
+		t.Errorf("Expected batch size 50, got %d", got)
+	}
+}
+
 func TestBatchProcessorContextCancellation(t *testing.T) {
 	manager := newMockManager()
 	handler := func(ctx context.Context, events []BufferedEvent) error {
@@ -246,3 +288,190 @@ func TestBatchProcessorContextCancellation(t *testing.T) {
 	// The processor should have stopped due to context cancellation
 	// (though IsRunning may still be true until Stop is called explicitly)
 }
+
+func TestBatchProcessorRejectedEventsGoToDLQWithoutRetry(t *testing.T) {
+	manager := newMockManager()
+	dlq := &memoryDLQ{}
+
+	events := []BufferedEvent{
+		{ID: 1, Event: cdc.Event{Schema: "public", Table: "orders", LSN: "0/1"}},
+		{ID: 2, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/2"}},
+	}
+
+	calls := 0
+	handler := func(ctx context.Context, batch []BufferedEvent) error {
+		calls++
+		return &RejectedEventsError{
+			Events: batch[1:],
+			Type:   deadletter.ErrorTypeSchema,
+			Err:    errors.New("column \"age\" cannot store string value"),
+		}
+	}
+
+	cfg := DefaultBatchConfig()
+	cfg.SourceID = "test-source"
+	cfg.DLQEnabled = true
+	processor := NewBatchProcessor(manager, handler, cfg, nil)
+	processor.SetDeadLetterManager(dlq)
+
+	if err := processor.processEvents(context.Background(), events); err != nil {
+		t.Fatalf("processEvents() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+	if len(dlq.entries) != 1 || dlq.entries[0].TableName != "users" || dlq.entries[0].ErrorType != deadletter.ErrorTypeSchema {
+		t.Errorf("dead-letter entries = %+v, want the users event as a schema error", dlq.entries)
+	}
+	if ids := manager.getProcessedIDs(); len(ids) != 2 {
+		t.Errorf("processed IDs = %v, want both events", ids)
+	}
+
+	stats := processor.Stats()
+	if stats.EventsProcessed != 1 || stats.EventsFailed != 1 {
+		t.Errorf("stats = %+v, want 1 processed and 1 failed", stats)
+	}
+}
+
+func TestBatchProcessorCommittedEvent(t *testing.T) {
+	manager := newMockManager()
+	handler := func(ctx context.Context, batch []BufferedEvent) error { return nil }
+
+	cfg := DefaultBatchConfig()
+	cfg.SourceID = "test-source"
+	cfg.TableOverrides = map[string]TableBatchConfig{
+		"public.countries": {FlushInterval: 10 * time.Minute},
+	}
+	processor := NewBatchProcessor(manager, handler, cfg, nil)
+
+	// process runs one tick reading events and waits for its flushes
+	process := func(events ...BufferedEvent) {
+		t.Helper()
+		manager.setEventsToReturn(events)
+		if err := processor.processBatchWithRetry(context.Background(), cfg); err != nil {
+			t.Fatalf("processBatchWithRetry() error = %v", err)
+		}
+		processor.wg.Wait()
+	}
+
+	old := time.Now().Add(-time.Minute)
+	seq := map[string]any{"sequence": uint64(0)}
+	countries := BufferedEvent{ID: 3, Event: cdc.Event{Schema: "public", Table: "countries", LSN: "0/3", Metadata: seq}, CreatedAt: time.Now()}
+	process(
+		BufferedEvent{ID: 1, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/1", Metadata: seq}, CreatedAt: old},
+		BufferedEvent{ID: 2, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/1", Metadata: map[string]any{"snapshot": true}}, CreatedAt: old},
+		countries,
+		BufferedEvent{ID: 4, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/4", Metadata: seq}, CreatedAt: old},
+	)
+	if _, ok := processor.CommittedEvent(); ok {
+		t.Fatal("expected no committed event before the processed events were read past")
+	}
+
+	// Countries waits for its flush interval, so users at 0/4 is committed
+	// but a checkpoint there would skip it
+	countries.CreatedAt = time.Now().Add(-time.Hour)
+	process(countries)
+	if event, ok := processor.CommittedEvent(); !ok || event.LSN != "0/1" {
+		t.Fatalf("CommittedEvent() = %q, %v, want 0/1", event.LSN, ok)
+	}
+
+	process()
+	if event, ok := processor.CommittedEvent(); !ok || event.LSN != "0/4" {
+		t.Errorf("CommittedEvent() = %q, %v, want 0/4", event.LSN, ok)
+	}
+}
+
+func TestBatchProcessorFailingTableDoesNotBlockOthers(t *testing.T) {
+	manager := newMockManager()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var flushed []string
+	handler := func(ctx context.Context, batch []BufferedEvent) error {
+		if batch[0].Event.Table == "orders" {
+			<-release
+			return errors.New("orders table unavailable")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range batch {
+			flushed = append(flushed, e.Event.Table)
+		}
+		return nil
+	}
+
+	cfg := DefaultBatchConfig()
+	cfg.SourceID = "test-source"
+	cfg.RetryMaxAttempts = 1
+	cfg.DLQEnabled = false
+	processor := NewBatchProcessor(manager, handler, cfg, nil)
+
+	orders := BufferedEvent{ID: 1, Event: cdc.Event{Schema: "public", Table: "orders", LSN: "0/1"}}
+	manager.setEventsToReturn([]BufferedEvent{
+		orders,
+		{ID: 2, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/2"}},
+	})
+	if err := processor.processBatchWithRetry(context.Background(), cfg); err != nil {
+		t.Fatalf("processBatchWithRetry() error = %v", err)
+	}
+
+	// Orders is still flushing on the next tick; users is flushed again
+	manager.setEventsToReturn([]BufferedEvent{
+		orders,
+		{ID: 3, Event: cdc.Event{Schema: "public", Table: "users", LSN: "0/3"}},
+	})
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(flushed)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the users batch while orders is stuck")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := processor.processBatchWithRetry(context.Background(), cfg); err != nil {
+		t.Fatalf("processBatchWithRetry() error = %v", err)
+	}
+
+	close(release)
+	processor.wg.Wait()
+
+	if got := strings.Join(flushed, ","); got != "users,users" {
+		t.Errorf("flushed tables = %s, want users twice without waiting for orders", got)
+	}
+	if ids := manager.getProcessedIDs(); len(ids) != 3 {
+		t.Errorf("processed IDs = %v, want the failed orders event handled once", ids)
+	}
+}
+
+func TestTableLanes(t *testing.T) {
+	event := func(id int64, table string) BufferedEvent {
+		return BufferedEvent{ID: id, Event: cdc.Event{Schema: "public", Table: table}}
+	}
+	lanes := tableLanes([][]BufferedEvent{
+		{event(1, "users"), event(2, "orders"), event(3, "users")},
+		{event(4, "orders")},
+	})
+
+	var got []string
+	for _, lane := range lanes {
+		var batches []string
+		for _, batch := range lane.batches {
+			var ids []string
+			for _, e := range batch {
+				ids = append(ids, strconv.FormatInt(e.ID, 10))
+			}
+			batches = append(batches, strings.Join(ids, " "))
+		}
+		got = append(got, lane.table+"="+strings.Join(batches, "|"))
+	}
+	want := "public.users=1 3;public.orders=2|4"
+	if strings.Join(got, ";") != want {
+		t.Errorf("tableLanes() = %s, want %s", strings.Join(got, ";"), want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/source"
 )
 
 // Manager handles CDC event buffering operations.
@@ -29,6 +30,41 @@ type Manager interface {
 	Close() error
 }
 
+// SnapshotPendingCounter is implemented by managers that can count the
+// snapshot rows of a table that have not been processed yet. A row is only
+// marked processed once the sink has committed it.
+type SnapshotPendingCounter interface {
+	// PendingSnapshotRows counts unprocessed snapshot rows of a table.
+	PendingSnapshotRows(ctx context.Context, schema, table string) (int64, error)
+}
+
+// SourceStatsReader is implemented by managers that can compute statistics
+// for the events of their own source only, where Stats covers the whole
+// buffer.
+type SourceStatsReader interface {
+	// SourceStats returns statistics for the events of the manager's source.
+	SourceStats(ctx context.Context) (Stats, error)
+}
+
+// EventKey returns the key a buffer deduplicates an event by: its
+// replication position, so a change redelivered after a restart is only
+// buffered once. Only changes numbered by a source deduplicator have a key;
+// without a sequence, changes of one transaction may share a position.
+// Snapshot rows share their snapshot's position and have no key either.
+func EventKey(event cdc.Event) string {
+	if snapshot, _ := event.Metadata["snapshot"].(bool); snapshot {
+		return ""
+	}
+	if _, ok := event.Metadata[source.MetadataSequence]; !ok {
+		return ""
+	}
+	pos, err := source.EventPosition(event)
+	if err != nil {
+		return ""
+	}
+	return pos.String()
+}
+
 // BufferedEvent wraps a CDC event with buffer-specific metadata.
 type BufferedEvent struct {
 	// ID is the buffer database ID.
@@ -67,6 +103,10 @@ type Config struct {
 	// DSN is the database connection string.
 	DSN string
 
+	// SourceID identifies the source whose events are written, and is the
+	// source the batch processor reads them for.
+	SourceID string
+
 	// MaxOpenConns is the maximum number of open connections.
 	MaxOpenConns int
 
@@ -3,6 +3,8 @@ package buffer
 import (
 	"testing"
 	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -103,3 +105,24 @@ func TestStats(t *testing.T) {
 		t.Errorf("Expected Lag to be %v, got %v", lag, stats.Lag)
 	}
 }
+
+func TestEventKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		event cdc.Event
+		want  string
+	}{
+		{"change", cdc.Event{LSN: "0/16B3748", Metadata: map[string]any{"sequence": uint64(2)}}, "0/16B3748#2"},
+		{"change without sequence", cdc.Event{LSN: "0/16B3748"}, ""},
+		{"snapshot row", cdc.Event{LSN: "0/16B3748", Metadata: map[string]any{"snapshot": true, "sequence": uint64(0)}}, ""},
+		{"invalid LSN", cdc.Event{LSN: "invalid", Metadata: map[string]any{"sequence": uint64(0)}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EventKey(tt.event); got != tt.want {
+				t.Errorf("EventKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
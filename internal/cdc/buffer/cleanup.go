@@ -0,0 +1,100 @@
+package buffer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CleanupStats is the state of a source's buffer after a cleanup run. The
+// worker records it so the API, which runs separately, can report buffer
+// growth and retention.
+type CleanupStats struct {
+	// UnprocessedEvents is the number of events not yet processed.
+	UnprocessedEvents int64
+
+	// ProcessedEvents is the number of processed events still retained.
+	ProcessedEvents int64
+
+	// OldestUnprocessed is when the oldest unprocessed event was buffered.
+	OldestUnprocessed *time.Time
+
+	// CleanupAt is when the cleanup ran.
+	CleanupAt time.Time
+
+	// RowsDeleted is the number of processed events the cleanup deleted.
+	RowsDeleted int64
+}
+
+// NewCleanupStats returns the stats of a cleanup run at cleanupAt that
+// deleted rowsDeleted events and left the buffer with stats.
+func NewCleanupStats(stats Stats, rowsDeleted int64, cleanupAt time.Time) CleanupStats {
+	return CleanupStats{
+		UnprocessedEvents: stats.UnprocessedEvents,
+		// Events are written between the two counts of a non-transactional
+		// read; never report fewer than none processed
+		ProcessedEvents:   max(stats.TotalEvents-stats.UnprocessedEvents, 0),
+		OldestUnprocessed: stats.OldestUnprocessed,
+		CleanupAt:         cleanupAt,
+		RowsDeleted:       rowsDeleted,
+	}
+}
+
+// OldestUnprocessedAge returns how long the oldest unprocessed event had
+// been buffered when the cleanup ran, or zero if every event was processed.
+func (s CleanupStats) OldestUnprocessedAge() time.Duration {
+	if s.OldestUnprocessed == nil || s.OldestUnprocessed.After(s.CleanupAt) {
+		return 0
+	}
+	return s.CleanupAt.Sub(*s.OldestUnprocessed)
+}
+
+// CleanupStatsRecorder records the stats of each cleanup run.
+type CleanupStatsRecorder interface {
+	RecordCleanup(ctx context.Context, stats CleanupStats) error
+}
+
+// PostgresCleanupStatsRecorder records cleanup stats for a pipeline
+// registered through the API in philotes.pipeline_buffer_stats, where the
+// API's buffer endpoint reads them.
+type PostgresCleanupStatsRecorder struct {
+	db         *sql.DB
+	pipelineID string
+}
+
+// NewPostgresCleanupStatsRecorder creates a PostgresCleanupStatsRecorder
+// for the pipeline with the given ID.
+func NewPostgresCleanupStatsRecorder(db *sql.DB, pipelineID string) *PostgresCleanupStatsRecorder {
+	return &PostgresCleanupStatsRecorder{db: db, pipelineID: pipelineID}
+}
+
+// RecordCleanup replaces the pipeline's recorded stats with stats.
+func (r *PostgresCleanupStatsRecorder) RecordCleanup(ctx context.Context, stats CleanupStats) error {
+	query := `
+		INSERT INTO philotes.pipeline_buffer_stats (
+			pipeline_id, unprocessed_events, processed_events,
+			oldest_unprocessed_at, last_cleanup_at, last_cleanup_deleted, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (pipeline_id) DO UPDATE SET
+			unprocessed_events = EXCLUDED.unprocessed_events,
+			processed_events = EXCLUDED.processed_events,
+			oldest_unprocessed_at = EXCLUDED.oldest_unprocessed_at,
+			last_cleanup_at = EXCLUDED.last_cleanup_at,
+			last_cleanup_deleted = EXCLUDED.last_cleanup_deleted,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		r.pipelineID,
+		stats.UnprocessedEvents,
+		stats.ProcessedEvents,
+		stats.OldestUnprocessed,
+		stats.CleanupAt,
+		stats.RowsDeleted,
+	)
+	if err != nil {
+		return fmt.Errorf("record cleanup stats: %w", err)
+	}
+	return nil
+}
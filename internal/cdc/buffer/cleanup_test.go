@@ -0,0 +1,72 @@
+package buffer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewCleanupStats(t *testing.T) {
+	cleanupAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	oldest := cleanupAt.Add(-90 * time.Second)
+
+	tests := []struct {
+		name          string
+		stats         Stats
+		wantProcessed int64
+		wantAge       time.Duration
+	}{
+		{"empty buffer", Stats{}, 0, 0},
+		{"all processed", Stats{TotalEvents: 40}, 40, 0},
+		{"backlog", Stats{TotalEvents: 100, UnprocessedEvents: 25, OldestUnprocessed: &oldest}, 75, 90 * time.Second},
+		{"counts raced a write", Stats{TotalEvents: 10, UnprocessedEvents: 12, OldestUnprocessed: &oldest}, 0, 90 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCleanupStats(tt.stats, 7, cleanupAt)
+			if got.ProcessedEvents != tt.wantProcessed {
+				t.Errorf("ProcessedEvents = %d, want %d", got.ProcessedEvents, tt.wantProcessed)
+			}
+			if got.UnprocessedEvents != tt.stats.UnprocessedEvents {
+				t.Errorf("UnprocessedEvents = %d, want %d", got.UnprocessedEvents, tt.stats.UnprocessedEvents)
+			}
+			if got.RowsDeleted != 7 || !got.CleanupAt.Equal(cleanupAt) {
+				t.Errorf("cleanup = %d rows at %v, want 7 rows at %v", got.RowsDeleted, got.CleanupAt, cleanupAt)
+			}
+			if age := got.OldestUnprocessedAge(); age != tt.wantAge {
+				t.Errorf("OldestUnprocessedAge() = %v, want %v", age, tt.wantAge)
+			}
+		})
+	}
+}
+
+// recordingStatsRecorder records the cleanup stats it is given.
+type recordingStatsRecorder struct {
+	recorded []CleanupStats
+}
+
+func (r *recordingStatsRecorder) RecordCleanup(ctx context.Context, stats CleanupStats) error {
+	r.recorded = append(r.recorded, stats)
+	return nil
+}
+
+func TestBatchProcessorCleanupRecordsStats(t *testing.T) {
+	manager := newMockManager()
+	manager.cleanupDeleted = 12
+	manager.statsFn = func() Stats {
+		return Stats{TotalEvents: 30, UnprocessedEvents: 5}
+	}
+	recorder := &recordingStatsRecorder{}
+
+	processor := NewBatchProcessor(manager, nil, DefaultBatchConfig(), nil)
+	processor.SetCleanupStatsRecorder(recorder)
+	processor.cleanup(context.Background())
+
+	if len(recorder.recorded) != 1 {
+		t.Fatalf("recorded %d cleanups, want 1", len(recorder.recorded))
+	}
+	got := recorder.recorded[0]
+	if got.RowsDeleted != 12 || got.UnprocessedEvents != 5 || got.ProcessedEvents != 25 {
+		t.Errorf("recorded %+v, want 12 deleted, 5 unprocessed and 25 processed", got)
+	}
+}
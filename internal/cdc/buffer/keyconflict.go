@@ -0,0 +1,309 @@
+package buffer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/deadletter"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// KeyConflictPolicy decides what happens when a batch contains differing
+// rows for the same key.
+type KeyConflictPolicy string
+
+const (
+	// KeyConflictOff disables key conflict detection.
+	KeyConflictOff KeyConflictPolicy = "off"
+
+	// KeyConflictDetect reports key conflicts as a metric and a warning but
+	// writes every row, so it is safe for sinks that append a change log.
+	KeyConflictDetect KeyConflictPolicy = "detect"
+
+	// KeyConflictLastWriteWins keeps the latest row for the key and drops the
+	// rows it supersedes.
+	KeyConflictLastWriteWins KeyConflictPolicy = "last_write_wins"
+
+	// KeyConflictQuarantine moves every change to the key in the batch to the
+	// dead-letter queue, so no version is picked arbitrarily.
+	KeyConflictQuarantine KeyConflictPolicy = "quarantine"
+)
+
+// ParseKeyConflictPolicy parses a key conflict policy. An empty string is
+// KeyConflictOff.
+func ParseKeyConflictPolicy(s string) (KeyConflictPolicy, error) {
+	switch p := KeyConflictPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return KeyConflictOff, nil
+	case KeyConflictOff, KeyConflictDetect, KeyConflictLastWriteWins, KeyConflictQuarantine:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown key conflict policy %q (want off, detect, last_write_wins or quarantine)", s)
+	}
+}
+
+// DropsRows reports whether the policy removes changes from a batch. Such
+// policies only make sense when the sink writes rows by key (upsert or
+// merge); with an append-only change log they discard real source inserts.
+func (p KeyConflictPolicy) DropsRows() bool {
+	return p == KeyConflictLastWriteWins || p == KeyConflictQuarantine
+}
+
+// KeyConflict is a key that was inserted more than once in a batch with
+// differing rows, without being deleted in between. This happens when the
+// source's key is not actually unique, e.g. a missing or invalid constraint
+// or a key override that does not identify rows.
+type KeyConflict struct {
+	// Table is the table as "schema.table".
+	Table string
+
+	// Key identifies the row, as "column=value" pairs.
+	Key string
+
+	// Versions are the differing rows for the key, in batch order. The first
+	// may come from an update; the rest are inserts.
+	Versions []BufferedEvent
+
+	// Events are all changes to the key in the batch, in batch order.
+	Events []BufferedEvent
+}
+
+// DetectKeyConflicts finds keys that a batch inserts more than once with
+// differing rows. Updates and deletes of a key are ordinary changes and do
+// not conflict, and inserting an identical row again is left alone. Events
+// without key columns are ignored.
+func DetectKeyConflicts(events []BufferedEvent) []KeyConflict {
+	type keyState struct {
+		live     *BufferedEvent
+		conflict *KeyConflict
+		events   []BufferedEvent
+	}
+
+	states := make(map[string]*keyState)
+	var order []string
+
+	state := func(id string) *keyState {
+		s, ok := states[id]
+		if !ok {
+			s = &keyState{}
+			states[id] = s
+			order = append(order, id)
+		}
+		return s
+	}
+
+	for i := range events {
+		e := &events[i]
+		table := e.Event.Schema + "." + e.Event.Table
+
+		switch e.Event.Operation {
+		case cdc.OperationInsert:
+			key, ok := rowKey(e.Event.KeyColumns, e.Event.After)
+			if !ok {
+				continue
+			}
+			s := state(table + "\x00" + key)
+			s.events = append(s.events, *e)
+			if s.live != nil && !reflect.DeepEqual(s.live.Event.After, e.Event.After) {
+				if s.conflict == nil {
+					s.conflict = &KeyConflict{Table: table, Key: key, Versions: []BufferedEvent{*s.live}}
+				}
+				s.conflict.Versions = append(s.conflict.Versions, *e)
+			}
+			s.live = e
+
+		case cdc.OperationUpdate:
+			// An update may change the key itself
+			if oldKey, ok := rowKey(e.Event.KeyColumns, e.Event.Before); ok {
+				if newKey, ok := rowKey(e.Event.KeyColumns, e.Event.After); ok && oldKey != newKey {
+					s := state(table + "\x00" + oldKey)
+					s.events = append(s.events, *e)
+					s.live = nil
+				}
+			}
+			if key, ok := rowKey(e.Event.KeyColumns, e.Event.After); ok {
+				s := state(table + "\x00" + key)
+				s.events = append(s.events, *e)
+				s.live = e
+			}
+
+		case cdc.OperationDelete:
+			if key, ok := rowKey(e.Event.KeyColumns, e.Event.Before); ok {
+				s := state(table + "\x00" + key)
+				s.events = append(s.events, *e)
+				s.live = nil
+			}
+		}
+	}
+
+	var conflicts []KeyConflict
+	for _, id := range order {
+		s := states[id]
+		if s.conflict == nil {
+			continue
+		}
+		s.conflict.Events = s.events
+		conflicts = append(conflicts, *s.conflict)
+	}
+	return conflicts
+}
+
+// rowKey formats the key columns of a row. It reports false if the event has
+// no key columns or the row lacks one of them.
+func rowKey(keyColumns []string, row map[string]any) (string, bool) {
+	if len(keyColumns) == 0 || row == nil {
+		return "", false
+	}
+
+	parts := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		v, ok := row[col]
+		if !ok {
+			return "", false
+		}
+		parts[i] = fmt.Sprintf("%s=%v", col, v)
+	}
+	return strings.Join(parts, ","), true
+}
+
+// KeyConflictHandler applies a KeyConflictPolicy to batches before they are
+// written, so a non-unique source key surfaces as a metric, an alert and
+// (when quarantining) dead-letter entries instead of silently wrong data.
+type KeyConflictHandler struct {
+	policy     KeyConflictPolicy
+	sourceID   string
+	deadLetter deadletter.Manager
+	retention  time.Duration
+	logger     *slog.Logger
+}
+
+// NewKeyConflictHandler creates a new KeyConflictHandler. Quarantining needs
+// a dead-letter queue; retention is how long quarantined changes are kept.
+func NewKeyConflictHandler(policy KeyConflictPolicy, sourceID string, dlq deadletter.Manager, retention time.Duration, logger *slog.Logger) (*KeyConflictHandler, error) {
+	if policy == KeyConflictQuarantine && dlq == nil {
+		return nil, fmt.Errorf("key conflict policy %s requires the dead-letter queue", policy)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &KeyConflictHandler{
+		policy:     policy,
+		sourceID:   sourceID,
+		deadLetter: dlq,
+		retention:  retention,
+		logger:     logger.With("component", "key-conflicts"),
+	}, nil
+}
+
+// Wrap returns a BatchHandler that resolves key conflicts before passing the
+// batch on to next.
+func (h *KeyConflictHandler) Wrap(next BatchHandler) BatchHandler {
+	if h.policy == KeyConflictOff {
+		return next
+	}
+	return func(ctx context.Context, events []BufferedEvent) error {
+		resolved, err := h.Resolve(ctx, events)
+		if err != nil {
+			return err
+		}
+		if len(resolved) == 0 {
+			return nil
+		}
+		return next(ctx, resolved)
+	}
+}
+
+// Resolve detects key conflicts in a batch and returns the events to write.
+// Under KeyConflictDetect every event is written; under
+// KeyConflictLastWriteWins the superseded inserts are dropped; under
+// KeyConflictQuarantine every change to a conflicting key is written to the
+// dead-letter queue and dropped from the batch.
+func (h *KeyConflictHandler) Resolve(ctx context.Context, events []BufferedEvent) ([]BufferedEvent, error) {
+	conflicts := DetectKeyConflicts(events)
+	h.recordBatch(events, conflicts)
+	if len(conflicts) == 0 {
+		return events, nil
+	}
+
+	drop := make(map[int64]bool)
+	for _, c := range conflicts {
+		metrics.CDCKeyConflictsTotal.WithLabelValues(h.sourceID, c.Table, string(h.policy)).Inc()
+		h.logger.Warn("conflicting rows share a key; the source key may not be unique",
+			"table", c.Table,
+			"key", c.Key,
+			"versions", len(c.Versions),
+			"policy", h.policy,
+		)
+
+		switch h.policy {
+		case KeyConflictDetect:
+		case KeyConflictQuarantine:
+			if err := h.quarantine(ctx, c); err != nil {
+				return nil, err
+			}
+			for _, e := range c.Events {
+				drop[e.ID] = true
+			}
+		default:
+			for _, e := range c.Versions[:len(c.Versions)-1] {
+				drop[e.ID] = true
+			}
+		}
+	}
+
+	resolved := make([]BufferedEvent, 0, len(events))
+	for _, e := range events {
+		if !drop[e.ID] {
+			resolved = append(resolved, e)
+		}
+	}
+	return resolved, nil
+}
+
+// recordBatch sets the conflicting key gauge of every table in the batch, so
+// it drops back to zero once a table's batches are clean again.
+func (h *KeyConflictHandler) recordBatch(events []BufferedEvent, conflicts []KeyConflict) {
+	counts := make(map[string]int)
+	for _, e := range events {
+		table := e.Event.Schema + "." + e.Event.Table
+		if _, ok := counts[table]; !ok {
+			counts[table] = 0
+		}
+	}
+	for _, c := range conflicts {
+		counts[c.Table]++
+	}
+	for table, n := range counts {
+		metrics.CDCKeyConflictsLastBatch.WithLabelValues(h.sourceID, table).Set(float64(n))
+	}
+}
+
+// quarantine writes every change to a conflicting key to the dead-letter
+// queue. Each entry names the other versions so they can be compared.
+func (h *KeyConflictHandler) quarantine(ctx context.Context, c KeyConflict) error {
+	versions := make([]int64, len(c.Versions))
+	for i, v := range c.Versions {
+		versions[i] = v.ID
+	}
+	reason := fmt.Errorf("key conflict on %s (%s): %d differing rows, buffered events %v",
+		c.Table, c.Key, len(c.Versions), versions)
+
+	for _, e := range c.Events {
+		failed, err := deadletter.FromCDCEvent(e.Event, reason, deadletter.ErrorTypeValidation, h.retention)
+		if err != nil {
+			return fmt.Errorf("encode quarantined event %d: %w", e.ID, err)
+		}
+		failed.OriginalEventID = e.ID
+		if err := h.deadLetter.Write(ctx, failed); err != nil {
+			return fmt.Errorf("quarantine event %d: %w", e.ID, err)
+		}
+		metrics.BufferDLQTotal.WithLabelValues(h.sourceID).Inc()
+	}
+	return nil
+}
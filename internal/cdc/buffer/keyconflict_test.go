@@ -0,0 +1,235 @@
+package buffer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/deadletter"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// gaugeValue reads the current value of a gauge.
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := g.Write(&m); err != nil {
+		t.Fatalf("read gauge: %v", err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+// memoryDLQ keeps dead-letter entries in memory.
+type memoryDLQ struct {
+	deadletter.Manager
+	entries []deadletter.FailedEvent
+}
+
+func (m *memoryDLQ) Write(_ context.Context, event deadletter.FailedEvent) error {
+	m.entries = append(m.entries, event)
+	return nil
+}
+
+func keyedEvent(id int64, op cdc.Operation, before, after map[string]any) BufferedEvent {
+	return BufferedEvent{
+		ID: id,
+		Event: cdc.Event{
+			ID:         "postgres-app",
+			Schema:     "public",
+			Table:      "customers",
+			Operation:  op,
+			Before:     before,
+			After:      after,
+			KeyColumns: []string{"id"},
+		},
+	}
+}
+
+// conflictingBatch inserts two differing rows for id 1 around an unrelated row.
+func conflictingBatch() []BufferedEvent {
+	return []BufferedEvent{
+		keyedEvent(1, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "a@example.com"}),
+		keyedEvent(2, cdc.OperationInsert, nil, map[string]any{"id": 2, "email": "b@example.com"}),
+		keyedEvent(3, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "c@example.com"}),
+	}
+}
+
+func eventIDs(events []BufferedEvent) []int64 {
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func newTestKeyConflictHandler(t *testing.T, policy KeyConflictPolicy, dlq deadletter.Manager) *KeyConflictHandler {
+	t.Helper()
+	h, err := NewKeyConflictHandler(policy, "postgres-app", dlq, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewKeyConflictHandler() error = %v", err)
+	}
+	return h
+}
+
+func TestDetectKeyConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []BufferedEvent
+		want   int
+	}{
+		{"differing inserts", conflictingBatch(), 1},
+		{
+			"identical inserts",
+			[]BufferedEvent{
+				keyedEvent(1, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "a@example.com"}),
+				keyedEvent(2, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "a@example.com"}),
+			},
+			0,
+		},
+		{
+			"update then delete then insert",
+			[]BufferedEvent{
+				keyedEvent(1, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "a@example.com"}),
+				keyedEvent(2, cdc.OperationUpdate, map[string]any{"id": 1}, map[string]any{"id": 1, "email": "b@example.com"}),
+				keyedEvent(3, cdc.OperationDelete, map[string]any{"id": 1}, nil),
+				keyedEvent(4, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "c@example.com"}),
+			},
+			0,
+		},
+		{
+			"key changed by update",
+			[]BufferedEvent{
+				keyedEvent(1, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "a@example.com"}),
+				keyedEvent(2, cdc.OperationUpdate, map[string]any{"id": 1}, map[string]any{"id": 5, "email": "a@example.com"}),
+				keyedEvent(3, cdc.OperationInsert, nil, map[string]any{"id": 1, "email": "c@example.com"}),
+			},
+			0,
+		},
+		{
+			"no key columns",
+			[]BufferedEvent{
+				{ID: 1, Event: cdc.Event{Operation: cdc.OperationInsert, After: map[string]any{"id": 1}}},
+				{ID: 2, Event: cdc.Event{Operation: cdc.OperationInsert, After: map[string]any{"id": 2}}},
+			},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectKeyConflicts(tt.events); len(got) != tt.want {
+				t.Errorf("DetectKeyConflicts() = %+v, want %d conflicts", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyConflictHandler_DetectWritesEveryRow(t *testing.T) {
+	h := newTestKeyConflictHandler(t, KeyConflictDetect, nil)
+
+	var written []BufferedEvent
+	handler := h.Wrap(func(_ context.Context, events []BufferedEvent) error {
+		written = events
+		return nil
+	})
+	if err := handler(context.Background(), conflictingBatch()); err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+
+	if got := eventIDs(written); !reflect.DeepEqual(got, []int64{1, 2, 3}) {
+		t.Errorf("written events = %v, want every row [1 2 3]", got)
+	}
+	if got := gaugeValue(t, metrics.CDCKeyConflictsLastBatch.WithLabelValues("postgres-app", "public.customers")); got != 1 {
+		t.Errorf("conflicting keys in the last batch = %v, want 1", got)
+	}
+
+	// A clean batch for the table clears the gauge, so the alert resolves
+	clean := []BufferedEvent{keyedEvent(4, cdc.OperationInsert, nil, map[string]any{"id": 4, "email": "d@example.com"})}
+	if err := handler(context.Background(), clean); err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	if got := gaugeValue(t, metrics.CDCKeyConflictsLastBatch.WithLabelValues("postgres-app", "public.customers")); got != 0 {
+		t.Errorf("conflicting keys after a clean batch = %v, want 0", got)
+	}
+}
+
+func TestKeyConflictHandler_LastWriteWins(t *testing.T) {
+	dlq := &memoryDLQ{}
+	h := newTestKeyConflictHandler(t, KeyConflictLastWriteWins, dlq)
+
+	var written []BufferedEvent
+	handler := h.Wrap(func(_ context.Context, events []BufferedEvent) error {
+		written = events
+		return nil
+	})
+	if err := handler(context.Background(), conflictingBatch()); err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+
+	if got := eventIDs(written); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Errorf("written events = %v, want [2 3] (the later row for id 1 wins)", got)
+	}
+	if len(dlq.entries) != 0 {
+		t.Errorf("dead-letter entries = %d, want none", len(dlq.entries))
+	}
+}
+
+func TestKeyConflictHandler_Quarantine(t *testing.T) {
+	dlq := &memoryDLQ{}
+	h := newTestKeyConflictHandler(t, KeyConflictQuarantine, dlq)
+
+	var written []BufferedEvent
+	handler := h.Wrap(func(_ context.Context, events []BufferedEvent) error {
+		written = events
+		return nil
+	})
+	if err := handler(context.Background(), conflictingBatch()); err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+
+	if got := eventIDs(written); !reflect.DeepEqual(got, []int64{2}) {
+		t.Errorf("written events = %v, want only the unrelated row [2]", got)
+	}
+
+	if len(dlq.entries) != 2 {
+		t.Fatalf("dead-letter entries = %d, want both versions", len(dlq.entries))
+	}
+	var emails []any
+	for _, entry := range dlq.entries {
+		if entry.ErrorType != deadletter.ErrorTypeValidation {
+			t.Errorf("ErrorType = %s, want %s", entry.ErrorType, deadletter.ErrorTypeValidation)
+		}
+		event, err := entry.ToEvent()
+		if err != nil {
+			t.Fatalf("ToEvent() error = %v", err)
+		}
+		emails = append(emails, event.After["email"])
+	}
+	if want := []any{"a@example.com", "c@example.com"}; !reflect.DeepEqual(emails, want) {
+		t.Errorf("quarantined rows = %v, want %v", emails, want)
+	}
+}
+
+func TestNewKeyConflictHandler_QuarantineNeedsDLQ(t *testing.T) {
+	if _, err := NewKeyConflictHandler(KeyConflictQuarantine, "postgres-app", nil, 0, nil); err == nil {
+		t.Fatal("expected an error without a dead-letter queue")
+	}
+}
+
+func TestParseKeyConflictPolicy(t *testing.T) {
+	if p, err := ParseKeyConflictPolicy(""); err != nil || p != KeyConflictOff {
+		t.Errorf("ParseKeyConflictPolicy(\"\") = %q, %v", p, err)
+	}
+	if p, err := ParseKeyConflictPolicy("Quarantine"); err != nil || p != KeyConflictQuarantine {
+		t.Errorf("ParseKeyConflictPolicy(Quarantine) = %q, %v", p, err)
+	}
+	if _, err := ParseKeyConflictPolicy("first_write_wins"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,78 @@
+package buffer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxLatencySamples bounds the samples a LatencyTracker keeps within its
+// window.
+const maxLatencySamples = 1024
+
+// LatencyTracker keeps a rolling average of how long a batch handler takes
+// to write a batch, over the batches written within a time window. With no
+// recent writes the average is zero, so a stalled flush does not hold a
+// signal up forever.
+type LatencyTracker struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	samples []latencySample
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// NewLatencyTracker creates a LatencyTracker averaging over window.
+func NewLatencyTracker(window time.Duration) *LatencyTracker {
+	return &LatencyTracker{window: window, now: time.Now}
+}
+
+// Wrap returns a handler that records how long next takes to handle each
+// batch, whether it succeeds or not.
+func (t *LatencyTracker) Wrap(next BatchHandler) BatchHandler {
+	return func(ctx context.Context, events []BufferedEvent) error {
+		start := t.now()
+		err := next(ctx, events)
+		t.Observe(t.now().Sub(start))
+		return err
+	}
+}
+
+// Observe records the latency of one batch write.
+func (t *LatencyTracker) Observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples = append(t.samples, latencySample{at: t.now(), duration: d})
+	if len(t.samples) > maxLatencySamples {
+		t.samples = t.samples[len(t.samples)-maxLatencySamples:]
+	}
+}
+
+// Average returns the average latency of the batches written within the
+// window, or zero if there were none.
+func (t *LatencyTracker) Average() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.now().Add(-t.window)
+	first := 0
+	for first < len(t.samples) && t.samples[first].at.Before(cutoff) {
+		first++
+	}
+	t.samples = t.samples[first:]
+
+	if len(t.samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, s := range t.samples {
+		total += s.duration
+	}
+	return total / time.Duration(len(t.samples))
+}
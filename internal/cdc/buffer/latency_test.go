@@ -0,0 +1,55 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker_Average(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewLatencyTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	if got := tracker.Average(); got != 0 {
+		t.Errorf("Average() without samples = %v, want 0", got)
+	}
+
+	tracker.Observe(time.Second)
+	now = now.Add(30 * time.Second)
+	tracker.Observe(3 * time.Second)
+	if got := tracker.Average(); got != 2*time.Second {
+		t.Errorf("Average() = %v, want 2s", got)
+	}
+
+	// The first sample leaves the window
+	now = now.Add(45 * time.Second)
+	if got := tracker.Average(); got != 3*time.Second {
+		t.Errorf("Average() = %v, want 3s once the first sample expired", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := tracker.Average(); got != 0 {
+		t.Errorf("Average() = %v, want 0 without recent writes", got)
+	}
+}
+
+func TestLatencyTracker_Wrap(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewLatencyTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	writeErr := errors.New("slow storage")
+	handler := tracker.Wrap(func(_ context.Context, _ []BufferedEvent) error {
+		now = now.Add(4 * time.Second)
+		return writeErr
+	})
+
+	if err := handler(context.Background(), nil); !errors.Is(err, writeErr) {
+		t.Fatalf("handler() error = %v, want the wrapped error", err)
+	}
+	if got := tracker.Average(); got != 4*time.Second {
+		t.Errorf("Average() = %v, want failed writes recorded too", got)
+	}
+}
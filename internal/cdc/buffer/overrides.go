@@ -0,0 +1,148 @@
+package buffer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TableBatchConfig overrides the batch size and flush interval for one table.
+// Zero values fall back to the processor's global settings.
+type TableBatchConfig struct {
+	// BatchSize is the maximum number of the table's events per batch.
+	BatchSize int
+
+	// FlushInterval is how long the table's oldest event may wait before its
+	// events are flushed.
+	FlushInterval time.Duration
+}
+
+// ParseTableOverrides parses per-table batch settings from JSON keyed by
+// "schema.table", e.g.
+//
+//	{"public.orders": {"batch_size": 10000, "flush_interval": "1s"},
+//	 "public.countries": {"flush_interval": "10m"}}
+//
+// An empty string means no overrides.
+func ParseTableOverrides(s string) (map[string]TableBatchConfig, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var raw map[string]struct {
+		BatchSize     int    `json:"batch_size"`
+		FlushInterval string `json:"flush_interval"`
+	}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("parse table overrides: %w", err)
+	}
+
+	overrides := make(map[string]TableBatchConfig, len(raw))
+	for table, o := range raw {
+		if schema, name, ok := strings.Cut(table, "."); !ok || schema == "" || name == "" {
+			return nil, fmt.Errorf("table override %q: want schema.table", table)
+		}
+		if o.BatchSize < 0 {
+			return nil, fmt.Errorf("table override %q: batch_size must not be negative", table)
+		}
+
+		cfg := TableBatchConfig{BatchSize: o.BatchSize}
+		if o.FlushInterval != "" {
+			d, err := time.ParseDuration(o.FlushInterval)
+			if err != nil {
+				return nil, fmt.Errorf("table override %q: invalid flush_interval: %w", table, err)
+			}
+			if d < 0 {
+				return nil, fmt.Errorf("table override %q: flush_interval must not be negative", table)
+			}
+			cfg.FlushInterval = d
+		}
+		overrides[table] = cfg
+	}
+	return overrides, nil
+}
+
+// tableConfig returns the batch size and flush interval for a group key; the
+// empty key is every table without an override.
+func (c BatchConfig) tableConfig(key string) (int, time.Duration) {
+	size, interval := c.BatchSize, c.FlushInterval
+	if o, ok := c.TableOverrides[key]; ok {
+		if o.BatchSize > 0 {
+			size = o.BatchSize
+		}
+		if o.FlushInterval > 0 {
+			interval = o.FlushInterval
+		}
+	}
+	return size, interval
+}
+
+// tickInterval is how often the processor checks for due batches: the
+// shortest flush interval of any table.
+func (c BatchConfig) tickInterval() time.Duration {
+	interval := c.FlushInterval
+	for key := range c.TableOverrides {
+		if _, d := c.tableConfig(key); d < interval {
+			interval = d
+		}
+	}
+	return interval
+}
+
+// readLimit is how many events the processor reads per tick. With overrides
+// it leaves room for a full batch of every overridden table next to a
+// global batch, so events held back for one table do not starve the rest.
+func (c BatchConfig) readLimit() int {
+	limit := c.BatchSize
+	for key := range c.TableOverrides {
+		size, _ := c.tableConfig(key)
+		limit += size
+	}
+	return limit
+}
+
+// dueBatches groups events by their most specific batch config and returns
+// the batches to process now. Without overrides every event read is one
+// batch. With overrides, a table's events are flushed once they fill a
+// batch or the oldest has waited its flush interval; the others stay in the
+// buffer for a later tick.
+func (c BatchConfig) dueBatches(events []BufferedEvent, now time.Time) [][]BufferedEvent {
+	if len(events) == 0 {
+		return nil
+	}
+	if len(c.TableOverrides) == 0 {
+		return [][]BufferedEvent{events}
+	}
+
+	groups := make(map[string][]BufferedEvent)
+	var order []string
+	for _, e := range events {
+		key := e.Event.Schema + "." + e.Event.Table
+		if _, ok := c.TableOverrides[key]; !ok {
+			key = ""
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	var batches [][]BufferedEvent
+	for _, key := range order {
+		group := groups[key]
+		size, interval := c.tableConfig(key)
+		if size <= 0 {
+			size = len(group)
+		}
+		if len(group) < size && now.Sub(group[0].CreatedAt) < interval {
+			continue
+		}
+		for len(group) > 0 {
+			n := min(size, len(group))
+			batches = append(batches, group[:n])
+			group = group[n:]
+		}
+	}
+	return batches
+}
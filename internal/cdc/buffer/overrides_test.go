@@ -0,0 +1,93 @@
+package buffer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+func tableEvent(id int64, table string, created time.Time) BufferedEvent {
+	return BufferedEvent{ID: id, CreatedAt: created, Event: cdc.Event{Schema: "public", Table: table}}
+}
+
+func TestParseTableOverrides(t *testing.T) {
+	overrides, err := ParseTableOverrides(`{"public.orders": {"batch_size": 3, "flush_interval": "1s"}, "public.countries": {"flush_interval": "10m"}}`)
+	if err != nil {
+		t.Fatalf("ParseTableOverrides() error = %v", err)
+	}
+	if got := overrides["public.orders"]; got.BatchSize != 3 || got.FlushInterval != time.Second {
+		t.Errorf("orders override = %+v", got)
+	}
+	if got := overrides["public.countries"]; got.BatchSize != 0 || got.FlushInterval != 10*time.Minute {
+		t.Errorf("countries override = %+v", got)
+	}
+
+	if o, err := ParseTableOverrides(""); err != nil || o != nil {
+		t.Errorf("ParseTableOverrides(\"\") = %v, %v", o, err)
+	}
+	for _, invalid := range []string{
+		`{"orders": {"batch_size": 10}}`,
+		`{"public.orders": {"flush_interval": "soon"}}`,
+		`{"public.orders": {"batch_size": -1}}`,
+		`[1, 2]`,
+	} {
+		if _, err := ParseTableOverrides(invalid); err == nil {
+			t.Errorf("ParseTableOverrides(%s) expected an error", invalid)
+		}
+	}
+}
+
+func TestBatchConfig_DueBatches(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-100 * time.Millisecond)
+
+	cfg := DefaultBatchConfig()
+	cfg.BatchSize = 100
+	cfg.FlushInterval = 5 * time.Second
+	cfg.TableOverrides = map[string]TableBatchConfig{
+		"public.orders":    {BatchSize: 2, FlushInterval: time.Second},
+		"public.countries": {FlushInterval: 10 * time.Minute},
+	}
+
+	events := []BufferedEvent{
+		tableEvent(1, "orders", recent),
+		tableEvent(2, "countries", now.Add(-time.Minute)),
+		tableEvent(3, "customers", now.Add(-6*time.Second)),
+		tableEvent(4, "orders", recent),
+		tableEvent(5, "orders", recent),
+	}
+
+	batches := cfg.dueBatches(events, now)
+
+	// Orders fill batches of 2, the global group is older than 5s, and
+	// countries waits for its 10 minute interval
+	want := [][]int64{{1, 4}, {5}, {3}}
+	if len(batches) != len(want) {
+		t.Fatalf("got %d batches, want %d", len(batches), len(want))
+	}
+	for i, batch := range batches {
+		if got := eventIDs(batch); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("batch %d = %v, want %v", i, got, want[i])
+		}
+	}
+
+	if got := cfg.tickInterval(); got != time.Second {
+		t.Errorf("tickInterval() = %v, want the shortest interval", got)
+	}
+	if got := cfg.readLimit(); got != 202 {
+		t.Errorf("readLimit() = %d, want room for every table's batch", got)
+	}
+}
+
+func TestBatchConfig_DueBatchesWithoutOverrides(t *testing.T) {
+	cfg := DefaultBatchConfig()
+	events := []BufferedEvent{
+		tableEvent(1, "orders", time.Now()),
+		tableEvent(2, "countries", time.Now()),
+	}
+	if batches := cfg.dueBatches(events, time.Now()); len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("dueBatches() = %v, want every event in one batch", batches)
+	}
+}
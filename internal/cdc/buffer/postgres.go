@@ -15,8 +15,9 @@ import (
 
 // PostgresManager implements buffer persistence using PostgreSQL.
 type PostgresManager struct {
-	db     *sql.DB
-	logger *slog.Logger
+	db       *sql.DB
+	sourceID string
+	logger   *slog.Logger
 }
 
 // NewPostgresManager creates a new PostgreSQL buffer manager.
@@ -45,12 +46,14 @@ func NewPostgresManager(ctx context.Context, cfg Config, logger *slog.Logger) (*
 	}
 
 	return &PostgresManager{
-		db:     db,
-		logger: logger.With("component", "buffer-manager"),
+		db:       db,
+		sourceID: cfg.SourceID,
+		logger:   logger.With("component", "buffer-manager"),
 	}, nil
 }
 
-// Write stores events in the buffer.
+// Write stores events in the buffer. Events whose key is already buffered
+// for the source are skipped.
 func (m *PostgresManager) Write(ctx context.Context, events []cdc.Event) error {
 	if len(events) == 0 {
 		return nil
@@ -66,8 +69,9 @@ func (m *PostgresManager) Write(ctx context.Context, events []cdc.Event) error {
 		INSERT INTO philotes.cdc_events (
 			source_id, schema_name, table_name, operation, lsn,
 			transaction_id, key_columns, before_data, after_data,
-			event_time, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			event_time, metadata, event_key
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (source_id, event_key) WHERE event_key IS NOT NULL DO NOTHING
 	`
 
 	stmt, err := tx.PrepareContext(ctx, query)
@@ -76,6 +80,7 @@ func (m *PostgresManager) Write(ctx context.Context, events []cdc.Event) error {
 	}
 	defer stmt.Close()
 
+	var skipped int64
 	for _, event := range events {
 		keyColumnsJSON, err := jsonMarshalNullable(event.KeyColumns)
 		if err != nil {
@@ -97,8 +102,18 @@ func (m *PostgresManager) Write(ctx context.Context, events []cdc.Event) error {
 			return fmt.Errorf("marshal metadata: %w", err)
 		}
 
-		_, err = stmt.ExecContext(ctx,
-			event.ID,            // source_id (using event ID as source identifier)
+		// Without a configured source, the event ID identifies the source
+		sourceID := m.sourceID
+		if sourceID == "" {
+			sourceID = event.ID
+		}
+		var eventKey sql.NullString
+		if key := EventKey(event); key != "" {
+			eventKey = sql.NullString{String: key, Valid: true}
+		}
+
+		result, err := stmt.ExecContext(ctx,
+			sourceID,            // source_id
 			event.Schema,        // schema_name
 			event.Table,         // table_name
 			event.Operation,     // operation
@@ -109,17 +124,24 @@ func (m *PostgresManager) Write(ctx context.Context, events []cdc.Event) error {
 			afterDataJSON,       // after_data
 			event.Timestamp,     // event_time
 			metadataJSON,        // metadata
+			eventKey,            // event_key
 		)
 		if err != nil {
 			return fmt.Errorf("insert event: %w", err)
 		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			skipped++
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
-	m.logger.Debug("events written to buffer", "count", len(events))
+	if skipped > 0 {
+		m.logger.Debug("skipped events already in buffer", "count", skipped)
+	}
+	m.logger.Debug("events written to buffer", "count", int64(len(events))-skipped)
 	return nil
 }
 
@@ -235,6 +257,17 @@ func (m *PostgresManager) Cleanup(ctx context.Context, retention time.Duration)
 
 // Stats returns buffer statistics.
 func (m *PostgresManager) Stats(ctx context.Context) (Stats, error) {
+	return m.stats(ctx, "")
+}
+
+// SourceStats returns statistics for the events of the manager's source.
+func (m *PostgresManager) SourceStats(ctx context.Context) (Stats, error) {
+	return m.stats(ctx, m.sourceID)
+}
+
+// stats returns statistics for the events of sourceID, or of every source
+// if sourceID is empty.
+func (m *PostgresManager) stats(ctx context.Context, sourceID string) (Stats, error) {
 	var stats Stats
 
 	// Get total and unprocessed counts
@@ -244,10 +277,11 @@ func (m *PostgresManager) Stats(ctx context.Context) (Stats, error) {
 			COUNT(*) FILTER (WHERE processed_at IS NULL) as unprocessed,
 			MIN(created_at) FILTER (WHERE processed_at IS NULL) as oldest_unprocessed
 		FROM philotes.cdc_events
+		WHERE $1 = '' OR source_id = $1
 	`
 
 	var oldestUnprocessed sql.NullTime
-	err := m.db.QueryRowContext(ctx, query).Scan(
+	err := m.db.QueryRowContext(ctx, query, sourceID).Scan(
 		&stats.TotalEvents,
 		&stats.UnprocessedEvents,
 		&oldestUnprocessed,
@@ -264,6 +298,24 @@ func (m *PostgresManager) Stats(ctx context.Context) (Stats, error) {
 	return stats, nil
 }
 
+// PendingSnapshotRows counts unprocessed snapshot rows of a table. Snapshot
+// rows carry {"snapshot": true} in their metadata.
+func (m *PostgresManager) PendingSnapshotRows(ctx context.Context, schema, table string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM philotes.cdc_events
+		WHERE processed_at IS NULL
+		  AND schema_name = $1 AND table_name = $2
+		  AND metadata @> '{"snapshot": true}'
+	`
+
+	var count int64
+	if err := m.db.QueryRowContext(ctx, query, schema, table).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count pending snapshot rows: %w", err)
+	}
+	return count, nil
+}
+
 // Close closes the database connection.
 func (m *PostgresManager) Close() error {
 	return m.db.Close()
@@ -298,5 +350,8 @@ func jsonUnmarshalNullable(data []byte, v any) error {
 	return json.Unmarshal(data, v)
 }
 
-// Ensure PostgresManager implements Manager interface.
-var _ Manager = (*PostgresManager)(nil)
+// Ensure PostgresManager implements Manager and SnapshotPendingCounter.
+var (
+	_ Manager                = (*PostgresManager)(nil)
+	_ SnapshotPendingCounter = (*PostgresManager)(nil)
+)
@@ -0,0 +1,208 @@
+package buffer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// DefaultPriority is the priority of pipelines that do not set one.
+const DefaultPriority = 1
+
+// Scheduler shares a fixed number of writer slots between the batch
+// processors of the pipelines a worker runs. A processor holds a slot while
+// it reads events from the buffer and while it writes each batch, so the
+// slots bound both buffer database connections and writer concurrency.
+//
+// When pipelines contend for slots, each gets a share weighted by its
+// priority: a freed slot goes to the waiting pipeline that has been granted
+// the fewest slots relative to its priority, the higher priority first on a
+// tie. High-priority pipelines therefore drain first during a backlog, while
+// lower-priority ones are throttled to the slots left over but never
+// starved.
+type Scheduler struct {
+	mu        sync.Mutex
+	capacity  int
+	inUse     int
+	pipelines map[string]*scheduledPipeline
+	waiters   []*slotWaiter
+	seq       uint64
+
+	// pass is the pass of the pipeline granted a slot last. A pipeline that
+	// was idle starts from it, so it cannot claim the slots it did not use.
+	pass float64
+}
+
+// scheduledPipeline is the scheduling state of a pipeline.
+type scheduledPipeline struct {
+	priority int
+	held     int
+
+	// pass advances by 1/priority with every slot granted
+	pass float64
+}
+
+// slotWaiter is a pipeline waiting for a writer slot.
+type slotWaiter struct {
+	name  string
+	seq   uint64
+	ready chan struct{}
+}
+
+// NewScheduler creates a Scheduler with capacity writer slots. A capacity
+// below one is treated as one.
+func NewScheduler(capacity int) *Scheduler {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Scheduler{
+		capacity:  capacity,
+		pipelines: make(map[string]*scheduledPipeline),
+	}
+}
+
+// Register sets the priority of a pipeline. Higher values get a larger
+// share of the slots; pipelines that are not registered have
+// DefaultPriority.
+func (s *Scheduler) Register(name string, priority int) {
+	if priority < 1 {
+		priority = DefaultPriority
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pipeline(name).priority = priority
+	metrics.BufferWriterSlots.WithLabelValues(name).Set(float64(s.pipelines[name].held))
+}
+
+// Acquire blocks until a writer slot is available for the pipeline.
+func (s *Scheduler) Acquire(ctx context.Context, name string) error {
+	s.mu.Lock()
+	pl := s.pipeline(name)
+	if pl.held == 0 && !s.isWaiting(name) && pl.pass < s.pass {
+		pl.pass = s.pass
+	}
+	if s.inUse < s.capacity && len(s.waiters) == 0 {
+		s.grant(name)
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.seq++
+	w := &slotWaiter{name: name, seq: s.seq, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, waiter := range s.waiters {
+			if waiter == w {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// Granted while being cancelled; hand the slot on
+		s.release(name)
+		return ctx.Err()
+	}
+}
+
+// Release returns a writer slot acquired by the pipeline.
+func (s *Scheduler) Release(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.release(name)
+}
+
+// Allocation returns the number of writer slots each pipeline holds.
+func (s *Scheduler) Allocation() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allocation := make(map[string]int, len(s.pipelines))
+	for name, pl := range s.pipelines {
+		allocation[name] = pl.held
+	}
+	return allocation
+}
+
+// pipeline returns the scheduling state of a pipeline, adding it with
+// DefaultPriority if it is not registered. The caller must hold s.mu.
+func (s *Scheduler) pipeline(name string) *scheduledPipeline {
+	pl, ok := s.pipelines[name]
+	if !ok {
+		pl = &scheduledPipeline{priority: DefaultPriority}
+		s.pipelines[name] = pl
+	}
+	return pl
+}
+
+// isWaiting reports whether a pipeline is queued for a slot. The caller
+// must hold s.mu.
+func (s *Scheduler) isWaiting(name string) bool {
+	for _, w := range s.waiters {
+		if w.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// grant gives a slot to a pipeline. The caller must hold s.mu.
+func (s *Scheduler) grant(name string) {
+	pl := s.pipeline(name)
+	s.inUse++
+	pl.held++
+	s.pass = pl.pass
+	pl.pass += 1 / float64(pl.priority)
+	metrics.BufferWriterSlots.WithLabelValues(name).Set(float64(pl.held))
+	metrics.BufferWriterSlotGrantsTotal.WithLabelValues(name).Inc()
+}
+
+// release frees a slot and hands it to the next waiters. The caller must
+// hold s.mu.
+func (s *Scheduler) release(name string) {
+	pl := s.pipelines[name]
+	if pl == nil || pl.held == 0 {
+		return
+	}
+	s.inUse--
+	pl.held--
+	metrics.BufferWriterSlots.WithLabelValues(name).Set(float64(pl.held))
+
+	for s.inUse < s.capacity && len(s.waiters) > 0 {
+		i := s.next()
+		next := s.waiters[i]
+		s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+		s.grant(next.name)
+		close(next.ready)
+	}
+}
+
+// next returns the index of the waiter to grant a slot to: the one whose
+// pipeline has the lowest pass, then the highest priority, then the one
+// that has waited longest. The caller must hold s.mu.
+func (s *Scheduler) next() int {
+	best := 0
+	for i := 1; i < len(s.waiters); i++ {
+		a, b := s.pipelines[s.waiters[i].name], s.pipelines[s.waiters[best].name]
+		switch {
+		case a.pass != b.pass:
+			if a.pass < b.pass {
+				best = i
+			}
+		case a.priority != b.priority:
+			if a.priority > b.priority {
+				best = i
+			}
+		case s.waiters[i].seq < s.waiters[best].seq:
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,208 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+func TestScheduler_GrantsHighestPriorityWaiterFirst(t *testing.T) {
+	s := NewScheduler(1)
+	s.Register("audit", 1)
+	s.Register("orders", 10)
+
+	ctx := context.Background()
+	if err := s.Acquire(ctx, "holder"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	waitFor := func(name string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Acquire(ctx, name); err != nil {
+				t.Errorf("Acquire(%s) error = %v", name, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			s.Release(name)
+		}()
+	}
+
+	// The low-priority pipeline queues first
+	waitFor("audit")
+	waitUntil(t, func() bool { return s.waiting() == 1 })
+	waitFor("orders")
+	waitUntil(t, func() bool { return s.waiting() == 2 })
+
+	s.Release("holder")
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != "orders" || order[1] != "audit" {
+		t.Errorf("grant order = %v, want [orders audit]", order)
+	}
+}
+
+func TestScheduler_SharesSlotsByPriority(t *testing.T) {
+	s := NewScheduler(1)
+	s.Register("orders", 3)
+	s.Register("audit", 1)
+
+	ctx := context.Background()
+	if err := s.Acquire(ctx, "holder"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for _, name := range []string{"audit", "orders"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := s.Acquire(ctx, name); err != nil {
+					t.Errorf("Acquire(%s) error = %v", name, err)
+					return
+				}
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				s.Release(name)
+			}()
+		}
+	}
+	waitUntil(t, func() bool { return s.waiting() == 16 })
+
+	s.Release("holder")
+	wg.Wait()
+
+	// While both wait, orders gets three slots for each one audit gets
+	granted := make(map[string]int)
+	for _, name := range order[:8] {
+		granted[name]++
+	}
+	if granted["orders"] != 6 || granted["audit"] != 2 {
+		t.Errorf("first 8 grants = %v, want 6 to orders and 2 to audit", order[:8])
+	}
+}
+
+func TestScheduler_AcquireCancelled(t *testing.T) {
+	s := NewScheduler(1)
+	if err := s.Acquire(context.Background(), "a"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, "b"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() error = %v, want deadline exceeded", err)
+	}
+
+	// The cancelled waiter must not hold on to the slot once it is freed
+	s.Release("a")
+	if got := s.Allocation(); got["a"] != 0 || got["b"] != 0 {
+		t.Errorf("Allocation() = %v, want no slots held", got)
+	}
+	if err := s.Acquire(context.Background(), "c"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+}
+
+// backlogManager always has a full batch of events for every source.
+type backlogManager struct {
+	mockManager
+}
+
+func (m *backlogManager) ReadBatch(_ context.Context, sourceID string, limit int) ([]BufferedEvent, error) {
+	events := make([]BufferedEvent, limit)
+	for i := range events {
+		events[i] = BufferedEvent{ID: int64(i), Event: cdc.Event{ID: sourceID}}
+	}
+	return events, nil
+}
+
+func TestBatchProcessor_PriorityUnderConstrainedWriters(t *testing.T) {
+	scheduler := NewScheduler(1)
+
+	var mu sync.Mutex
+	batches := make(map[string]int)
+
+	newProcessor := func(source string, priority int) *BatchProcessor {
+		cfg := DefaultBatchConfig()
+		cfg.SourceID = source
+		cfg.Priority = priority
+		cfg.BatchSize = 10
+		cfg.FlushInterval = time.Millisecond
+		cfg.CleanupInterval = 0
+
+		handler := func(_ context.Context, _ []BufferedEvent) error {
+			time.Sleep(2 * time.Millisecond)
+			mu.Lock()
+			batches[source]++
+			mu.Unlock()
+			return nil
+		}
+		p := NewBatchProcessor(&backlogManager{}, handler, cfg, nil)
+		p.SetScheduler(scheduler)
+		return p
+	}
+
+	processors := []*BatchProcessor{
+		newProcessor("orders", 10),
+		newProcessor("audit-1", 1),
+		newProcessor("audit-2", 1),
+		newProcessor("audit-3", 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, p := range processors {
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	for _, p := range processors {
+		_ = p.Stop(context.Background())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if batches["orders"] == 0 {
+		t.Fatal("high-priority pipeline made no progress")
+	}
+	for _, source := range []string{"audit-1", "audit-2", "audit-3"} {
+		if batches[source] >= batches["orders"] {
+			t.Errorf("%s processed %d batches, want fewer than the high-priority pipeline's %d",
+				source, batches[source], batches["orders"])
+		}
+	}
+}
+
+// waiting returns the number of queued waiters.
+func (s *Scheduler) waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,61 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// LoadHighWaterMark retrieves the deduplication high-water-mark for a slot.
+func (m *PostgresManager) LoadHighWaterMark(ctx context.Context, slot string) (*source.Position, error) {
+	query := `
+		SELECT lsn, sequence
+		FROM philotes.cdc_dedup_high_water_marks
+		WHERE slot_name = $1
+	`
+
+	var lsn string
+	var seq int64
+	err := m.db.QueryRowContext(ctx, query, slot).Scan(&lsn, &seq)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No high-water-mark yet
+		}
+		return nil, fmt.Errorf("load high-water-mark: %w", err)
+	}
+
+	parsed, err := cdc.ParseLSN(lsn)
+	if err != nil {
+		return nil, fmt.Errorf("load high-water-mark: %w", err)
+	}
+
+	return &source.Position{LSN: parsed, Seq: uint64(seq)}, nil
+}
+
+// SaveHighWaterMark persists the deduplication high-water-mark for a slot.
+func (m *PostgresManager) SaveHighWaterMark(ctx context.Context, slot string, pos source.Position) error {
+	query := `
+		INSERT INTO philotes.cdc_dedup_high_water_marks (slot_name, lsn, sequence, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (slot_name)
+		DO UPDATE SET
+			lsn = EXCLUDED.lsn,
+			sequence = EXCLUDED.sequence,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := m.db.ExecContext(ctx, query, slot, pos.LSN.String(), int64(pos.Seq)); err != nil {
+		return fmt.Errorf("save high-water-mark: %w", err)
+	}
+
+	m.logger.Debug("high-water-mark saved", "slot", slot, "position", pos.String())
+
+	return nil
+}
+
+// Ensure PostgresManager implements source.HighWaterMarkStore interface.
+var _ source.HighWaterMarkStore = (*PostgresManager)(nil)
@@ -252,9 +252,10 @@ func (m *PostgresManager) Cleanup(ctx context.Context) (int64, error) {
 	return rowsAffected, nil
 }
 
-// Count returns the number of events in the dead-letter queue.
+// Count returns the number of events in the dead-letter queue. Events
+// replayed into the buffer are not counted.
 func (m *PostgresManager) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM philotes.dead_letter_events`
+	query := `SELECT COUNT(*) FROM philotes.dead_letter_events WHERE replayed_at IS NULL`
 
 	var count int64
 	err := m.db.QueryRowContext(ctx, query).Scan(&count)
@@ -289,6 +290,7 @@ func (m *PostgresManager) GetStats(ctx context.Context) (Stats, error) {
 	sourceQuery := `
 		SELECT source_id, COUNT(*) as count
 		FROM philotes.dead_letter_events
+		WHERE replayed_at IS NULL
 		GROUP BY source_id
 	`
 	sourceRows, err := m.db.QueryContext(ctx, sourceQuery)
@@ -310,7 +312,7 @@ func (m *PostgresManager) GetStats(ctx context.Context) (Stats, error) {
 	errorQuery := `
 		SELECT error_type, COUNT(*) as count
 		FROM philotes.dead_letter_events
-		WHERE error_type IS NOT NULL
+		WHERE error_type IS NOT NULL AND replayed_at IS NULL
 		GROUP BY error_type
 	`
 	errorRows, err := m.db.QueryContext(ctx, errorQuery)
@@ -332,6 +334,7 @@ func (m *PostgresManager) GetStats(ctx context.Context) (Stats, error) {
 	timeQuery := `
 		SELECT MIN(created_at), MAX(created_at)
 		FROM philotes.dead_letter_events
+		WHERE replayed_at IS NULL
 	`
 	var oldest, newest sql.NullTime
 	if err := m.db.QueryRowContext(ctx, timeQuery).Scan(&oldest, &newest); err != nil {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 )
@@ -44,6 +45,9 @@ type CheckResult struct {
 
 	// Error is the error message if the check failed.
 	Error string `json:"error,omitempty"`
+
+	// Failure is the structured reason the check failed, if known.
+	Failure *Failure `json:"failure,omitempty"`
 }
 
 // Checker is a function that performs a health check.
@@ -60,17 +64,28 @@ type HealthChecker interface {
 
 // Manager manages health checks for multiple components.
 type Manager struct {
-	mu       sync.RWMutex
-	checkers []HealthChecker
-	results  map[string]CheckResult
-	logger   *slog.Logger
-	timeout  time.Duration
+	mu          sync.RWMutex
+	checkers    []HealthChecker
+	results     map[string]CheckResult
+	lastSuccess map[string]time.Time
+	logger      *slog.Logger
+	timeout     time.Duration
+	runbookURL  string
+
+	// gates hold readiness back independently of health, and draining
+	// fails readiness once graceful shutdown has started.
+	gates    []ReadinessGate
+	draining bool
 }
 
 // ManagerConfig holds configuration for the health manager.
 type ManagerConfig struct {
 	// Timeout is the timeout for individual health checks.
 	Timeout time.Duration
+
+	// RunbookURL, if set, is appended to remediation hints as
+	// <RunbookURL>#<code> so operators can link to their own runbooks.
+	RunbookURL string
 }
 
 // DefaultManagerConfig returns a ManagerConfig with sensible defaults.
@@ -87,10 +102,12 @@ func NewManager(cfg ManagerConfig, logger *slog.Logger) *Manager {
 	}
 
 	return &Manager{
-		checkers: make([]HealthChecker, 0),
-		results:  make(map[string]CheckResult),
-		logger:   logger.With("component", "health-manager"),
-		timeout:  cfg.Timeout,
+		checkers:    make([]HealthChecker, 0),
+		results:     make(map[string]CheckResult),
+		lastSuccess: make(map[string]time.Time),
+		logger:      logger.With("component", "health-manager"),
+		timeout:     cfg.Timeout,
+		runbookURL:  cfg.RunbookURL,
 	}
 }
 
@@ -102,6 +119,18 @@ func (m *Manager) Register(checker HealthChecker) {
 	m.logger.Debug("registered health checker", "name", checker.Name())
 }
 
+// Unregister removes the health checker with the given name and its last
+// result, such as for a component that was deleted.
+func (m *Manager) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkers = slices.DeleteFunc(m.checkers, func(c HealthChecker) bool {
+		return c.Name() == name
+	})
+	delete(m.results, name)
+	delete(m.lastSuccess, name)
+}
+
 // CheckAll performs health checks on all registered components.
 func (m *Manager) CheckAll(ctx context.Context) map[string]CheckResult {
 	m.mu.Lock()
@@ -114,6 +143,17 @@ func (m *Manager) CheckAll(ctx context.Context) map[string]CheckResult {
 		result := checker.Check(checkCtx)
 		cancel()
 
+		if result.Status == StatusHealthy {
+			m.lastSuccess[checker.Name()] = result.LastCheck
+		} else if result.Failure != nil {
+			if last, ok := m.lastSuccess[checker.Name()]; ok {
+				result.Failure.LastSuccess = &last
+			}
+			if m.runbookURL != "" {
+				result.Failure.Remediation += " See " + m.runbookURL + "#" + string(result.Failure.Code)
+			}
+		}
+
 		results[checker.Name()] = result
 		m.results[checker.Name()] = result
 	}
@@ -140,9 +180,61 @@ func (m *Manager) IsHealthy(ctx context.Context) bool {
 	return true
 }
 
-// IsReady returns true if the system is ready to serve requests.
+// RegisterReadinessGate adds a readiness gate to the manager.
+func (m *Manager) RegisterReadinessGate(gate ReadinessGate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gates = append(m.gates, gate)
+	m.logger.Debug("registered readiness gate", "name", gate.Name())
+}
+
+// SetDraining marks the system as draining: it stays live but is no longer
+// ready, so that traffic moves away before it stops.
+func (m *Manager) SetDraining() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.draining {
+		m.draining = true
+		m.logger.Info("draining, readiness disabled")
+	}
+}
+
+// IsDraining returns whether graceful shutdown has started.
+func (m *Manager) IsDraining() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.draining
+}
+
+// Readiness returns whether the system is ready to serve requests and, if
+// not, why. The system is ready when it is not draining, every readiness
+// gate is open and all components are healthy or degraded.
+func (m *Manager) Readiness(ctx context.Context) (bool, string) {
+	m.mu.RLock()
+	draining := m.draining
+	gates := make([]ReadinessGate, len(m.gates))
+	copy(gates, m.gates)
+	m.mu.RUnlock()
+
+	if draining {
+		return false, "draining"
+	}
+	for _, gate := range gates {
+		if ready, reason := gate.Ready(); !ready {
+			return false, gate.Name() + ": " + reason
+		}
+	}
+	if !m.IsHealthy(ctx) {
+		return false, "unhealthy"
+	}
+	return true, ""
+}
+
+// IsReady returns true if the system is ready to serve requests. Unlike
+// liveness, readiness is false while starting up and draining.
 func (m *Manager) IsReady(ctx context.Context) bool {
-	return m.IsHealthy(ctx)
+	ready, _ := m.Readiness(ctx)
+	return ready
 }
 
 // OverallStatus computes the overall health status.
@@ -273,7 +365,8 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleLiveness returns whether the service is alive.
 func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
-	// Liveness is always true if the server is responding
+	// Liveness is always true if the server is responding, including while
+	// starting up and draining when the service is not ready
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, `{"status":"alive","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
@@ -283,19 +376,22 @@ func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if s.manager.IsReady(r.Context()) {
+	ready, reason := s.manager.Readiness(r.Context())
+	if ready {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, `{"status":"ready","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
 	} else {
+		quoted, _ := json.Marshal(reason)
 		w.WriteHeader(http.StatusServiceUnavailable)
-		fmt.Fprintf(w, `{"status":"not_ready","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+		fmt.Fprintf(w, `{"status":"not_ready","reason":%s,"timestamp":"%s"}`, quoted, time.Now().Format(time.RFC3339))
 	}
 }
 
 // DatabaseChecker checks database connectivity.
 type DatabaseChecker struct {
-	name string
-	ping func(ctx context.Context) error
+	name      string
+	component Component
+	ping      func(ctx context.Context) error
 }
 
 // NewDatabaseChecker creates a new database health checker.
@@ -308,6 +404,11 @@ func (c *DatabaseChecker) Name() string {
 	return c.name
 }
 
+// SetComponent sets the component kind used to classify failures.
+func (c *DatabaseChecker) SetComponent(component Component) {
+	c.component = component
+}
+
 // Check performs the health check.
 func (c *DatabaseChecker) Check(ctx context.Context) CheckResult {
 	start := time.Now()
@@ -323,6 +424,9 @@ func (c *DatabaseChecker) Check(ctx context.Context) CheckResult {
 		result.Status = StatusUnhealthy
 		result.Error = err.Error()
 		result.Message = "database connection failed"
+		if c.component != "" {
+			result.Failure = NewFailure(c.component, err)
+		}
 	} else {
 		result.Status = StatusHealthy
 		result.Message = "database connection successful"
@@ -331,10 +435,42 @@ func (c *DatabaseChecker) Check(ctx context.Context) CheckResult {
 	return result
 }
 
+// ReadinessGate holds readiness back independently of health, such as a
+// pipeline that has not caught up with its checkpoint yet.
+type ReadinessGate interface {
+	// Ready returns whether the component is ready and, if not, why.
+	Ready() (bool, string)
+
+	// Name returns the name of the component.
+	Name() string
+}
+
+// FuncReadinessGate is a readiness gate backed by a function.
+type FuncReadinessGate struct {
+	name  string
+	ready func() (bool, string)
+}
+
+// NewReadinessGate creates a new readiness gate.
+func NewReadinessGate(name string, ready func() (bool, string)) *FuncReadinessGate {
+	return &FuncReadinessGate{name: name, ready: ready}
+}
+
+// Name returns the name of the component.
+func (g *FuncReadinessGate) Name() string {
+	return g.name
+}
+
+// Ready returns whether the component is ready.
+func (g *FuncReadinessGate) Ready() (bool, string) {
+	return g.ready()
+}
+
 // ComponentChecker checks a generic component.
 type ComponentChecker struct {
-	name  string
-	check func(ctx context.Context) (Status, string, error)
+	name      string
+	component Component
+	check     func(ctx context.Context) (Status, string, error)
 }
 
 // NewComponentChecker creates a new component health checker.
@@ -347,6 +483,11 @@ func (c *ComponentChecker) Name() string {
 	return c.name
 }
 
+// SetComponent sets the component kind used to classify failures.
+func (c *ComponentChecker) SetComponent(component Component) {
+	c.component = component
+}
+
 // Check performs the health check.
 func (c *ComponentChecker) Check(ctx context.Context) CheckResult {
 	start := time.Now()
@@ -362,6 +503,9 @@ func (c *ComponentChecker) Check(ctx context.Context) CheckResult {
 
 	if err != nil {
 		result.Error = err.Error()
+		if c.component != "" && status != StatusHealthy {
+			result.Failure = NewFailure(c.component, err)
+		}
 	}
 
 	return result
@@ -371,4 +515,5 @@ func (c *ComponentChecker) Check(ctx context.Context) CheckResult {
 var (
 	_ HealthChecker = (*DatabaseChecker)(nil)
 	_ HealthChecker = (*ComponentChecker)(nil)
+	_ ReadinessGate = (*FuncReadinessGate)(nil)
 )
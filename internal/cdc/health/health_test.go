@@ -3,6 +3,8 @@ package health
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -56,6 +58,25 @@ func TestManager_Register(t *testing.T) {
 	}
 }
 
+func TestManager_Unregister(t *testing.T) {
+	mgr := NewManager(DefaultManagerConfig(), nil)
+	for _, name := range []string{"source:orders", "source:users"} {
+		mgr.Register(NewComponentChecker(name, func(ctx context.Context) (Status, string, error) {
+			return StatusHealthy, "ok", nil
+		}))
+	}
+	mgr.CheckAll(context.Background())
+
+	mgr.Unregister("source:orders")
+
+	if len(mgr.checkers) != 1 || mgr.checkers[0].Name() != "source:users" {
+		t.Errorf("expected only source:users to remain registered, got %d checkers", len(mgr.checkers))
+	}
+	if _, ok := mgr.GetResult("source:orders"); ok {
+		t.Error("expected the unregistered checker's result to be removed")
+	}
+}
+
 func TestManager_CheckAll(t *testing.T) {
 	mgr := NewManager(DefaultManagerConfig(), nil)
 
@@ -309,3 +330,159 @@ func TestDefaultServerConfig(t *testing.T) {
 		t.Errorf("expected write timeout 10s, got %v", cfg.WriteTimeout)
 	}
 }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Reason
+	}{
+		{"context deadline", fmt.Errorf("ping: %w", context.DeadlineExceeded), ReasonTimeout},
+		{"i/o timeout", errors.New("read tcp 10.0.0.1:5432: i/o timeout"), ReasonTimeout},
+		{"postgres auth", errors.New(`FATAL: password authentication failed for user "cdc" (SQLSTATE 28P01)`), ReasonAuthFailed},
+		{"catalog forbidden", errors.New("catalog error (status 403): forbidden"), ReasonAuthFailed},
+		{"vault permission", errors.New("Code: 403. Errors: * permission denied"), ReasonAuthFailed},
+		{"missing database", errors.New(`FATAL: database "app" does not exist (SQLSTATE 3D000)`), ReasonNotFound},
+		{"missing bucket", errors.New("NoSuchBucket: The specified bucket does not exist"), ReasonNotFound},
+		{"catalog 404", errors.New("catalog error (status 404): warehouse not found"), ReasonNotFound},
+		{"refused", errors.New("dial tcp 10.0.0.1:5432: connect: connection refused"), ReasonUnreachable},
+		{"dns", &net.DNSError{Err: "no such host", Name: "db.internal"}, ReasonUnreachable},
+		{"other", errors.New("something odd"), ReasonError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFailure(t *testing.T) {
+	tests := []struct {
+		component       Component
+		err             error
+		wantCode        ReasonCode
+		wantRemediation string
+	}{
+		{ComponentSource, context.DeadlineExceeded, "source_timeout", "network latency"},
+		{ComponentSource, errors.New("password authentication failed"), "source_auth_failed", "REPLICATION privilege"},
+		{ComponentCatalog, errors.New("catalog error (status 401): unauthorized"), "catalog_auth_failed", "catalog credentials"},
+		{ComponentStorage, errors.New("NoSuchBucket"), "storage_not_found", "PHILOTES_STORAGE_BUCKET"},
+		{ComponentVault, errors.New("secret not found"), "vault_not_found", "secret paths"},
+		{ComponentBuffer, errors.New("connection refused"), "buffer_unreachable", "PHILOTES_DB_HOST"},
+		{ComponentBuffer, errors.New("boom"), "buffer_error", "buffer database logs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.wantCode), func(t *testing.T) {
+			f := NewFailure(tt.component, tt.err)
+			if f.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", f.Code, tt.wantCode)
+			}
+			if !strings.Contains(f.Remediation, tt.wantRemediation) {
+				t.Errorf("Remediation = %q, want it to mention %q", f.Remediation, tt.wantRemediation)
+			}
+			if f.Message == "" {
+				t.Error("expected a message")
+			}
+		})
+	}
+}
+
+func TestManager_FailureLastSuccess(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.RunbookURL = "https://runbooks.example.com/philotes"
+	mgr := NewManager(cfg, nil)
+
+	var pingErr error
+	checker := NewDatabaseChecker("source-database", func(ctx context.Context) error {
+		return pingErr
+	})
+	checker.SetComponent(ComponentSource)
+	mgr.Register(checker)
+
+	// Failing before any success has no last-success time
+	pingErr = errors.New("connection refused")
+	result := mgr.CheckAll(context.Background())["source-database"]
+	if result.Failure == nil || result.Failure.LastSuccess != nil {
+		t.Fatalf("expected failure without last success, got %+v", result.Failure)
+	}
+
+	pingErr = nil
+	healthy := mgr.CheckAll(context.Background())["source-database"]
+	if healthy.Failure != nil {
+		t.Errorf("expected no failure when healthy, got %+v", healthy.Failure)
+	}
+
+	pingErr = errors.New(`password authentication failed for user "cdc"`)
+	result = mgr.CheckAll(context.Background())["source-database"]
+	if result.Failure == nil {
+		t.Fatal("expected failure")
+	}
+	if result.Failure.Code != "source_auth_failed" {
+		t.Errorf("Code = %q", result.Failure.Code)
+	}
+	if result.Failure.LastSuccess == nil || !result.Failure.LastSuccess.Equal(healthy.LastCheck) {
+		t.Errorf("LastSuccess = %v, want %v", result.Failure.LastSuccess, healthy.LastCheck)
+	}
+	if !strings.HasSuffix(result.Failure.Remediation, "See https://runbooks.example.com/philotes#source_auth_failed") {
+		t.Errorf("Remediation = %q, want runbook link", result.Failure.Remediation)
+	}
+	if result.Message != "database connection failed" || result.Error == "" {
+		t.Errorf("legacy message and error must be kept, got %q / %q", result.Message, result.Error)
+	}
+}
+
+func TestManager_Readiness(t *testing.T) {
+	mgr := NewManager(DefaultManagerConfig(), nil)
+	mgr.Register(NewComponentChecker("test", func(ctx context.Context) (Status, string, error) {
+		return StatusHealthy, "ok", nil
+	}))
+
+	caughtUp := false
+	mgr.RegisterReadinessGate(NewReadinessGate("pipeline", func() (bool, string) {
+		if !caughtUp {
+			return false, "catching up"
+		}
+		return true, ""
+	}))
+	server := NewServer(mgr, DefaultServerConfig(), nil)
+
+	readiness := func() (int, string) {
+		w := httptest.NewRecorder()
+		server.handleReadiness(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		return w.Code, w.Body.String()
+	}
+	liveness := func() int {
+		w := httptest.NewRecorder()
+		server.handleLiveness(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+		return w.Code
+	}
+
+	// Starting: alive but not ready
+	if code, body := readiness(); code != http.StatusServiceUnavailable || !strings.Contains(body, `"reason":"pipeline: catching up"`) {
+		t.Errorf("readiness while starting = %d %s, want 503 with the gate reason", code, body)
+	}
+	if code := liveness(); code != http.StatusOK {
+		t.Errorf("liveness while starting = %d, want 200", code)
+	}
+
+	caughtUp = true
+	if code, body := readiness(); code != http.StatusOK {
+		t.Errorf("readiness once caught up = %d %s, want 200", code, body)
+	}
+
+	// Draining: still alive but no longer ready
+	mgr.SetDraining()
+	if !mgr.IsDraining() {
+		t.Error("expected manager to be draining")
+	}
+	if code, body := readiness(); code != http.StatusServiceUnavailable || !strings.Contains(body, `"reason":"draining"`) {
+		t.Errorf("readiness while draining = %d %s, want 503 draining", code, body)
+	}
+	if code := liveness(); code != http.StatusOK {
+		t.Errorf("liveness while draining = %d, want 200", code)
+	}
+}
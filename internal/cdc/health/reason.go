@@ -0,0 +1,200 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Component identifies the kind of component a health check covers.
+type Component string
+
+const (
+	// ComponentSource is the source database being replicated.
+	ComponentSource Component = "source"
+	// ComponentBuffer is the buffer database.
+	ComponentBuffer Component = "buffer"
+	// ComponentDatabase is the API metadata database.
+	ComponentDatabase Component = "database"
+	// ComponentCatalog is the Iceberg REST catalog.
+	ComponentCatalog Component = "catalog"
+	// ComponentStorage is the object storage holding table data.
+	ComponentStorage Component = "storage"
+	// ComponentVault is the secret store.
+	ComponentVault Component = "vault"
+	// ComponentQueryEngine is the query engine (Trino or DuckDB).
+	ComponentQueryEngine Component = "query_engine"
+)
+
+// Reason classifies why a health check failed.
+type Reason string
+
+const (
+	// ReasonTimeout means the component did not answer in time.
+	ReasonTimeout Reason = "timeout"
+	// ReasonUnreachable means the component could not be connected to.
+	ReasonUnreachable Reason = "unreachable"
+	// ReasonAuthFailed means the component rejected the credentials.
+	ReasonAuthFailed Reason = "auth_failed"
+	// ReasonNotFound means a database, bucket, warehouse or secret is missing.
+	ReasonNotFound Reason = "not_found"
+	// ReasonError is any other failure.
+	ReasonError Reason = "error"
+)
+
+// ReasonCode is a stable, machine-readable health failure code such as
+// "source_unreachable".
+type ReasonCode string
+
+// Code returns the reason code for a component and reason.
+func Code(component Component, reason Reason) ReasonCode {
+	return ReasonCode(string(component) + "_" + string(reason))
+}
+
+// Failure describes a failed health check in a form the dashboard and CLI
+// can act on.
+type Failure struct {
+	// Code is the stable reason code.
+	Code ReasonCode `json:"code"`
+
+	// Message is a short human-readable description.
+	Message string `json:"message"`
+
+	// Remediation suggests what the operator should check.
+	Remediation string `json:"remediation"`
+
+	// LastSuccess is when the component last passed its check, if ever.
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// componentLabels are the names used in failure messages.
+var componentLabels = map[Component]string{
+	ComponentSource:      "source database",
+	ComponentBuffer:      "buffer database",
+	ComponentDatabase:    "metadata database",
+	ComponentCatalog:     "Iceberg catalog",
+	ComponentStorage:     "object storage",
+	ComponentVault:       "Vault",
+	ComponentQueryEngine: "query engine",
+}
+
+// remediations holds the remediation hint for each reason code.
+var remediations = map[ReasonCode]string{
+	Code(ComponentSource, ReasonTimeout):          "Check source database load and network latency.",
+	Code(ComponentSource, ReasonUnreachable):      "Check that the source host and port are correct and reachable from the worker (network, firewall, DNS).",
+	Code(ComponentSource, ReasonAuthFailed):       "Check the source user and password, and that the user has the REPLICATION privilege.",
+	Code(ComponentSource, ReasonNotFound):         "Check that the source database, publication and replication slot exist.",
+	Code(ComponentBuffer, ReasonTimeout):          "Check buffer database load and connection pool saturation.",
+	Code(ComponentBuffer, ReasonUnreachable):      "Check that the buffer database is running and reachable (PHILOTES_DB_HOST, PHILOTES_DB_PORT).",
+	Code(ComponentBuffer, ReasonAuthFailed):       "Check the buffer database credentials (PHILOTES_DB_USER, PHILOTES_DB_PASSWORD).",
+	Code(ComponentBuffer, ReasonNotFound):         "Check that the buffer database exists and its migrations have been applied.",
+	Code(ComponentDatabase, ReasonTimeout):        "Check metadata database load and connection pool saturation.",
+	Code(ComponentDatabase, ReasonUnreachable):    "Check that the metadata database is running and reachable (PHILOTES_DB_HOST, PHILOTES_DB_PORT).",
+	Code(ComponentDatabase, ReasonAuthFailed):     "Check the metadata database credentials (PHILOTES_DB_USER, PHILOTES_DB_PASSWORD).",
+	Code(ComponentDatabase, ReasonNotFound):       "Check that the metadata database exists and its migrations have been applied.",
+	Code(ComponentCatalog, ReasonTimeout):         "Check Iceberg catalog load and network latency.",
+	Code(ComponentCatalog, ReasonUnreachable):     "Check that the Iceberg catalog is running and PHILOTES_ICEBERG_CATALOG_URL is correct.",
+	Code(ComponentCatalog, ReasonAuthFailed):      "Check the catalog credentials and that the worker is allowed to access the warehouse.",
+	Code(ComponentCatalog, ReasonNotFound):        "Check that the warehouse named by PHILOTES_ICEBERG_WAREHOUSE exists in the catalog.",
+	Code(ComponentStorage, ReasonTimeout):         "Check object storage load and network latency.",
+	Code(ComponentStorage, ReasonUnreachable):     "Check that object storage is running and PHILOTES_STORAGE_ENDPOINT is correct.",
+	Code(ComponentStorage, ReasonAuthFailed):      "Check the storage access key and secret key.",
+	Code(ComponentStorage, ReasonNotFound):        "Check that the bucket named by PHILOTES_STORAGE_BUCKET exists.",
+	Code(ComponentVault, ReasonTimeout):           "Check Vault load and network latency.",
+	Code(ComponentVault, ReasonUnreachable):       "Check that Vault is unsealed and PHILOTES_VAULT_ADDRESS is reachable.",
+	Code(ComponentVault, ReasonAuthFailed):        "Check the Vault token or Kubernetes auth role, and that it has not expired.",
+	Code(ComponentVault, ReasonNotFound):          "Check that the referenced secret paths exist under PHILOTES_VAULT_SECRET_MOUNT_PATH.",
+	Code(ComponentQueryEngine, ReasonTimeout):     "Check Trino coordinator or DuckDB load and network latency to the Iceberg catalog.",
+	Code(ComponentQueryEngine, ReasonUnreachable): "Check that the Trino coordinator is running and PHILOTES_TRINO_URL is correct, or for DuckDB that PHILOTES_ICEBERG_CATALOG_URL is reachable.",
+	Code(ComponentQueryEngine, ReasonAuthFailed):  "Check the Trino credentials (PHILOTES_TRINO_USERNAME, PHILOTES_TRINO_PASSWORD).",
+	Code(ComponentQueryEngine, ReasonNotFound):    "Check that the configured catalog and schema exist (PHILOTES_TRINO_CATALOG/SCHEMA or PHILOTES_DUCKDB_CATALOG/SCHEMA).",
+}
+
+// NewFailure classifies err and returns the failure for a component.
+func NewFailure(component Component, err error) *Failure {
+	reason := Classify(err)
+	code := Code(component, reason)
+
+	label := componentLabels[component]
+	if label == "" {
+		label = string(component)
+	}
+
+	remediation, ok := remediations[code]
+	if !ok {
+		remediation = "Check the " + label + " logs and the error details."
+	}
+
+	return &Failure{
+		Code:        code,
+		Message:     label + " " + reasonMessages[reason],
+		Remediation: remediation,
+	}
+}
+
+// reasonMessages describe each reason in failure messages.
+var reasonMessages = map[Reason]string{
+	ReasonTimeout:     "timed out",
+	ReasonUnreachable: "unreachable",
+	ReasonAuthFailed:  "rejected the credentials",
+	ReasonNotFound:    "is missing a required resource",
+	ReasonError:       "check failed",
+}
+
+// Classify maps an error to a failure reason. Typed errors are checked first;
+// driver and HTTP errors that only carry text are matched on well-known
+// messages and status codes.
+func Classify(err error) Reason {
+	if err == nil {
+		return ReasonError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return ReasonTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ReasonTimeout
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return ReasonAuthFailed
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return ReasonNotFound
+	}
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return ReasonUnreachable
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case containsAny(msg, "timeout", "timed out", "deadline exceeded"):
+		return ReasonTimeout
+	case containsAny(msg, "password authentication failed", "sqlstate 28p01", "sqlstate 28000",
+		"permission denied", "access denied", "unauthorized", "forbidden", "invalid token",
+		"status 401", "status 403", "code: 401", "code: 403"):
+		return ReasonAuthFailed
+	case containsAny(msg, "connection refused", "no route to host", "network is unreachable",
+		"no such host", "connection reset", "unexpected eof", "dial tcp"):
+		return ReasonUnreachable
+	case containsAny(msg, "does not exist", "not found", "nosuchbucket", "nosuchkey",
+		"status 404", "code: 404", "sqlstate 3d000"):
+		return ReasonNotFound
+	}
+	return ReasonError
+}
+
+// containsAny reports whether s contains any of substrs.
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
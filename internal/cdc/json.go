@@ -0,0 +1,70 @@
+package cdc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IsJSONType reports whether a PostgreSQL type name is json or jsonb.
+func IsJSONType(typeName string) bool {
+	switch strings.ToLower(strings.TrimSpace(typeName)) {
+	case "json", "jsonb":
+		return true
+	}
+	return false
+}
+
+// DecodeJSON normalizes the value of a json or jsonb column. Sources deliver
+// these as JSON text, as a string from logical replication or as bytes from
+// a query, which would otherwise be stored as text in one case and base64 in
+// the other.
+//
+// By default the document is returned as compact JSON text. With asStruct,
+// objects and arrays are returned as nested maps and slices, numbers inside
+// them as json.Number so they keep their precision. A scalar document is
+// returned as JSON text in either mode so a column's Iceberg type does not
+// depend on which documents it holds. A NULL column returns nil.
+func DecodeJSON(value any, asStruct bool) (any, error) {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		// Already decoded by the source
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("encode json value: %w", err)
+		}
+		raw = encoded
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, fmt.Errorf("invalid json value: %w", err)
+	}
+
+	if !asStruct {
+		return compact.String(), nil
+	}
+	switch compact.Bytes()[0] {
+	case '{', '[':
+	default:
+		return compact.String(), nil
+	}
+
+	decoder := json.NewDecoder(&compact)
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode json value: %w", err)
+	}
+	return doc, nil
+}
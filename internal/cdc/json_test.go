@@ -0,0 +1,104 @@
+package cdc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestIsJSONType(t *testing.T) {
+	for typeName, want := range map[string]bool{
+		"json":   true,
+		"jsonb":  true,
+		"JSONB":  true,
+		"text":   false,
+		"_jsonb": false,
+	} {
+		if got := IsJSONType(typeName); got != want {
+			t.Errorf("IsJSONType(%q) = %v, want %v", typeName, got, want)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantString any
+		wantStruct any
+	}{
+		{"null column", nil, nil, nil},
+		{
+			"object",
+			`{"name": "widget", "tags": {"color": "red"}, "size": null}`,
+			`{"name":"widget","tags":{"color":"red"},"size":null}`,
+			map[string]any{"name": "widget", "tags": map[string]any{"color": "red"}, "size": nil},
+		},
+		{
+			"array",
+			[]byte(`[1, "two", [3.5], {"four": true}]`),
+			`[1,"two",[3.5],{"four":true}]`,
+			[]any{json.Number("1"), "two", []any{json.Number("3.5")}, map[string]any{"four": true}},
+		},
+		{
+			"large integer",
+			`{"id": 9007199254740993}`,
+			`{"id":9007199254740993}`,
+			map[string]any{"id": json.Number("9007199254740993")},
+		},
+		{"string scalar", `"hello"`, `"hello"`, `"hello"`},
+		{"number scalar", []byte(` 42 `), `42`, `42`},
+		{"boolean scalar", `true`, `true`, `true`},
+		{"null document", `null`, `null`, `null`},
+		{
+			"decoded by the source",
+			map[string]any{"a": []any{1, 2}},
+			`{"a":[1,2]}`,
+			map[string]any{"a": []any{json.Number("1"), json.Number("2")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeJSON(tt.value, false)
+			if err != nil {
+				t.Fatalf("DecodeJSON(asStruct=false) error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantString) {
+				t.Errorf("DecodeJSON(asStruct=false) = %#v, want %#v", got, tt.wantString)
+			}
+
+			got, err = DecodeJSON(tt.value, true)
+			if err != nil {
+				t.Fatalf("DecodeJSON(asStruct=true) error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantStruct) {
+				t.Errorf("DecodeJSON(asStruct=true) = %#v, want %#v", got, tt.wantStruct)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_RoundTrip(t *testing.T) {
+	doc := `{"a":{"b":[1,2,{"c":null}]},"d":"e","f":12345678901234567890}`
+
+	decoded, err := DecodeJSON(doc, true)
+	if err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(encoded) != doc {
+		t.Errorf("round trip = %s, want %s", encoded, doc)
+	}
+}
+
+func TestDecodeJSON_Invalid(t *testing.T) {
+	for _, value := range []any{`{"a":`, []byte("not json"), ""} {
+		if _, err := DecodeJSON(value, false); err == nil {
+			t.Errorf("DecodeJSON(%q) error = nil, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cdc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LSN is a PostgreSQL Log Sequence Number.
+type LSN uint64
+
+// ParseLSN parses an LSN in PostgreSQL's "XXXXXXXX/XXXXXXXX" text form.
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// String returns the LSN in PostgreSQL's text form.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint64(l)>>32, uint64(l)&0xFFFFFFFF)
+}
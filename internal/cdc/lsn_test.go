@@ -0,0 +1,37 @@
+package cdc
+
+import "testing"
+
+func TestParseLSN(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LSN
+		wantErr bool
+	}{
+		{"0/0", 0, false},
+		{"0/16B3748", 0x16B3748, false},
+		{"1/0", 1 << 32, false},
+		{"16/B374D848", 0x16B374D848, false},
+		{"", 0, true},
+		{"16B3748", 0, true},
+		{"0/xyz", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLSN(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLSN(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLSN(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLSN_String(t *testing.T) {
+	if got := LSN(0x16B374D848).String(); got != "16/B374D848" {
+		t.Errorf("String() = %q, want %q", got, "16/B374D848")
+	}
+}
@@ -0,0 +1,342 @@
+// Package orphan finds and optionally removes replication slots and
+// publications that Philotes created on a source but no pipeline uses any
+// more. An orphaned logical slot keeps the source from recycling WAL, so
+// leftovers from failed onboarding or deleted pipelines slowly fill the
+// source's disk.
+//
+// Only objects whose names carry a Philotes prefix are considered; anything
+// else on the source is never reported or touched.
+package orphan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// Kind is the kind of replication object.
+type Kind string
+
+const (
+	// KindSlot is a logical replication slot.
+	KindSlot Kind = "slot"
+
+	// KindPublication is a publication.
+	KindPublication Kind = "publication"
+)
+
+// State describes an unreferenced replication object.
+type State string
+
+const (
+	// StateOrphaned means nothing references or uses the object.
+	StateOrphaned State = "orphaned"
+
+	// StateSuspicious means no pipeline references the object but something
+	// is still consuming it, so it is reported and never dropped.
+	StateSuspicious State = "suspicious"
+)
+
+// Slot is a logical replication slot on the source.
+type Slot struct {
+	// Name is the slot name.
+	Name string
+
+	// Active reports whether a connection is currently consuming the slot.
+	Active bool
+
+	// RetainedBytes is how much WAL the slot keeps from being recycled.
+	RetainedBytes int64
+}
+
+// Inventory lists and drops replication objects on a source.
+type Inventory interface {
+	ListSlots(ctx context.Context) ([]Slot, error)
+	ListPublications(ctx context.Context) ([]string, error)
+	DropSlot(ctx context.Context, name string) error
+	DropPublication(ctx context.Context, name string) error
+}
+
+// References holds the slot and publication names pipelines use.
+type References struct {
+	Slots        []string
+	Publications []string
+}
+
+// ReferenceLister returns the replication objects referenced by pipelines.
+type ReferenceLister func(ctx context.Context) (References, error)
+
+// Config holds reconciler configuration.
+type Config struct {
+	// Prune drops orphans; when false orphans are only reported.
+	Prune bool
+
+	// Prefixes are the name prefixes of Philotes-managed objects.
+	Prefixes []string
+
+	// Interval is how often the reconciler runs.
+	Interval time.Duration
+
+	// MinAge is how long an object must stay orphaned before it is dropped,
+	// so a pipeline that is being restarted or re-registered keeps its slot.
+	MinAge time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Prefixes: []string{"philotes_"},
+		Interval: time.Hour,
+		MinAge:   24 * time.Hour,
+	}
+}
+
+// Finding is an unreferenced Philotes-managed replication object.
+type Finding struct {
+	// Kind is the kind of object.
+	Kind Kind
+
+	// Name is the object name.
+	Name string
+
+	// State is whether the object is orphaned or suspicious.
+	State State
+
+	// RetainedBytes is the WAL retained by an orphaned slot.
+	RetainedBytes int64
+
+	// Since is when the object was first seen unreferenced.
+	Since time.Time
+
+	// Dropped reports whether the reconciler dropped the object.
+	Dropped bool
+}
+
+// Reconciler cross-references a source's replication objects with the
+// objects pipelines use.
+type Reconciler struct {
+	config     Config
+	sourceName string
+	inventory  Inventory
+	references ReferenceLister
+	logger     *slog.Logger
+
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+	now       func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewReconciler creates a new Reconciler for a source.
+func NewReconciler(cfg Config, sourceName string, inventory Inventory, references ReferenceLister, logger *slog.Logger) *Reconciler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Reconciler{
+		config:     cfg,
+		sourceName: sourceName,
+		inventory:  inventory,
+		references: references,
+		logger:     logger.With("component", "orphan-reconciler"),
+		firstSeen:  make(map[string]time.Time),
+		now:        time.Now,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start starts the periodic reconciliation. It is a no-op when the interval
+// is not positive.
+func (r *Reconciler) Start(ctx context.Context) {
+	if r.config.Interval <= 0 {
+		return
+	}
+
+	r.logger.Info("starting orphan reconciler",
+		"interval", r.config.Interval,
+		"prune", r.config.Prune,
+		"min_age", r.config.MinAge,
+	)
+
+	r.wg.Add(1)
+	go r.runLoop(ctx)
+}
+
+// Stop stops the periodic reconciliation.
+func (r *Reconciler) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+func (r *Reconciler) runLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Reconcile(ctx); err != nil {
+			r.logger.Error("orphan reconciliation failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile finds unreferenced Philotes-managed slots and publications and,
+// when pruning is enabled, drops orphans that have been unreferenced for at
+// least MinAge. Active slots are never dropped.
+func (r *Reconciler) Reconcile(ctx context.Context) ([]Finding, error) {
+	refs, err := r.references(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list referenced replication objects: %w", err)
+	}
+	slots, err := r.inventory.ListSlots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list replication slots: %w", err)
+	}
+	publications, err := r.inventory.ListPublications(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list publications: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	referenced := make(map[string]bool)
+	for _, name := range refs.Slots {
+		referenced[key(KindSlot, name)] = true
+	}
+	for _, name := range refs.Publications {
+		referenced[key(KindPublication, name)] = true
+	}
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	consider := func(kind Kind, name string, active bool, retained int64) {
+		k := key(kind, name)
+		if !r.managed(name) || referenced[k] {
+			return
+		}
+		seen[k] = true
+
+		since, ok := r.firstSeen[k]
+		if !ok {
+			since = now
+			r.firstSeen[k] = now
+		}
+
+		state := StateOrphaned
+		if active {
+			state = StateSuspicious
+		}
+		findings = append(findings, Finding{
+			Kind:          kind,
+			Name:          name,
+			State:         state,
+			RetainedBytes: retained,
+			Since:         since,
+		})
+	}
+	for _, slot := range slots {
+		consider(KindSlot, slot.Name, slot.Active, slot.RetainedBytes)
+	}
+	for _, name := range publications {
+		consider(KindPublication, name, false, 0)
+	}
+
+	// Forget objects that were dropped or are referenced again
+	for k := range r.firstSeen {
+		if !seen[k] {
+			delete(r.firstSeen, k)
+		}
+	}
+
+	var errs []error
+	counts := map[Kind]int{KindSlot: 0, KindPublication: 0}
+	for i := range findings {
+		f := &findings[i]
+		if f.State == StateSuspicious {
+			r.logger.Warn("unreferenced replication slot is in use",
+				"slot", f.Name,
+				"retained_bytes", f.RetainedBytes,
+			)
+			counts[f.Kind]++
+			continue
+		}
+
+		if !r.config.Prune || now.Sub(f.Since) < r.config.MinAge {
+			r.logger.Warn("orphaned replication object",
+				"kind", f.Kind,
+				"name", f.Name,
+				"retained_bytes", f.RetainedBytes,
+				"since", f.Since,
+			)
+			counts[f.Kind]++
+			continue
+		}
+
+		if err := r.drop(ctx, f.Kind, f.Name); err != nil {
+			errs = append(errs, fmt.Errorf("drop %s %s: %w", f.Kind, f.Name, err))
+			counts[f.Kind]++
+			continue
+		}
+		f.Dropped = true
+		delete(r.firstSeen, key(f.Kind, f.Name))
+		r.logger.Info("dropped orphaned replication object",
+			"kind", f.Kind,
+			"name", f.Name,
+			"retained_bytes", f.RetainedBytes,
+		)
+	}
+
+	for kind, count := range counts {
+		metrics.CDCOrphanedReplicationObjects.WithLabelValues(r.sourceName, string(kind)).Set(float64(count))
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind > findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return findings, errors.Join(errs...)
+}
+
+// managed reports whether a name carries a Philotes prefix.
+func (r *Reconciler) managed(name string) bool {
+	for _, prefix := range r.config.Prefixes {
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// drop drops a replication object.
+func (r *Reconciler) drop(ctx context.Context, kind Kind, name string) error {
+	if kind == KindSlot {
+		return r.inventory.DropSlot(ctx, name)
+	}
+	return r.inventory.DropPublication(ctx, name)
+}
+
+// key identifies a replication object across runs.
+func key(kind Kind, name string) string {
+	return string(kind) + ":" + name
+}
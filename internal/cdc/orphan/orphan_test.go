@@ -0,0 +1,207 @@
+package orphan
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeInventory serves slots and publications from memory.
+type fakeInventory struct {
+	slots        []Slot
+	publications []string
+	dropped      []string
+}
+
+func (i *fakeInventory) ListSlots(_ context.Context) ([]Slot, error) { return i.slots, nil }
+func (i *fakeInventory) ListPublications(_ context.Context) ([]string, error) {
+	return i.publications, nil
+}
+
+func (i *fakeInventory) DropSlot(_ context.Context, name string) error {
+	i.dropped = append(i.dropped, "slot:"+name)
+	return nil
+}
+
+func (i *fakeInventory) DropPublication(_ context.Context, name string) error {
+	i.dropped = append(i.dropped, "publication:"+name)
+	return nil
+}
+
+func staticRefs(refs References) ReferenceLister {
+	return func(context.Context) (References, error) { return refs, nil }
+}
+
+func newTestReconciler(cfg Config, inv Inventory, refs ReferenceLister) *Reconciler {
+	return NewReconciler(cfg, "postgres-app", inv, refs, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func names(findings []Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, string(f.Kind)+":"+f.Name+":"+string(f.State))
+	}
+	return out
+}
+
+func TestReconcile_DetectsOnlyUnreferencedManagedObjects(t *testing.T) {
+	inv := &fakeInventory{
+		slots: []Slot{
+			{Name: "philotes_cdc"},                                    // referenced by this worker
+			{Name: "philotes_orders", Active: true},                   // referenced and streaming
+			{Name: "philotes_failed_onboarding", RetainedBytes: 4096}, // orphan
+			{Name: "philotes_rogue", Active: true},                    // unreferenced but consumed
+			{Name: "debezium_slot"},                                   // externally managed
+			{Name: "subscription_123", Active: true},                  // externally managed
+		},
+		publications: []string{"philotes_pub", "philotes_old_pub", "dbz_publication"},
+	}
+	refs := staticRefs(References{
+		Slots:        []string{"philotes_cdc", "philotes_orders"},
+		Publications: []string{"philotes_pub"},
+	})
+
+	cfg := DefaultConfig()
+	cfg.Prune = true
+	r := newTestReconciler(cfg, inv, refs)
+
+	findings, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	want := []string{
+		"slot:philotes_failed_onboarding:orphaned",
+		"slot:philotes_rogue:suspicious",
+		"publication:philotes_old_pub:orphaned",
+	}
+	if got := names(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+	if findings[0].RetainedBytes != 4096 {
+		t.Errorf("RetainedBytes = %d, want 4096", findings[0].RetainedBytes)
+	}
+
+	// Nothing is dropped before MinAge has passed.
+	if len(inv.dropped) != 0 {
+		t.Errorf("dropped = %v, want nothing on first sighting", inv.dropped)
+	}
+}
+
+func TestReconcile_PrunesOrphansAfterMinAge(t *testing.T) {
+	inv := &fakeInventory{
+		slots: []Slot{
+			{Name: "philotes_orphan"},
+			{Name: "philotes_rogue", Active: true},
+			{Name: "external_slot"},
+		},
+		publications: []string{"philotes_orphan_pub", "external_pub"},
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.Prune = true
+	cfg.MinAge = time.Hour
+	r := newTestReconciler(cfg, inv, staticRefs(References{}))
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	findings, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	want := []string{"slot:philotes_orphan", "publication:philotes_orphan_pub"}
+	if !reflect.DeepEqual(inv.dropped, want) {
+		t.Errorf("dropped = %v, want %v", inv.dropped, want)
+	}
+	for _, f := range findings {
+		if f.Dropped != (f.State == StateOrphaned) {
+			t.Errorf("%s %s: Dropped = %v in state %s", f.Kind, f.Name, f.Dropped, f.State)
+		}
+	}
+}
+
+func TestReconcile_ReportOnlyNeverDrops(t *testing.T) {
+	inv := &fakeInventory{slots: []Slot{{Name: "philotes_orphan"}}}
+	cfg := DefaultConfig()
+	cfg.MinAge = 0
+	r := newTestReconciler(cfg, inv, staticRefs(References{}))
+
+	findings, err := r.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Dropped {
+		t.Errorf("findings = %+v, want one undropped orphan", findings)
+	}
+	if len(inv.dropped) != 0 {
+		t.Errorf("dropped = %v, want nothing without Prune", inv.dropped)
+	}
+}
+
+func TestReconcile_ReferencedAgainResetsAge(t *testing.T) {
+	inv := &fakeInventory{slots: []Slot{{Name: "philotes_restarting"}}}
+	refs := References{}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.Prune = true
+	cfg.MinAge = time.Hour
+	r := newTestReconciler(cfg, inv, func(context.Context) (References, error) { return refs, nil })
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	_, _ = r.Reconcile(ctx)
+
+	// The pipeline is registered again, then removed once more
+	now = now.Add(30 * time.Minute)
+	refs = References{Slots: []string{"philotes_restarting"}}
+	_, _ = r.Reconcile(ctx)
+	refs = References{}
+	now = now.Add(45 * time.Minute)
+	findings, _ := r.Reconcile(ctx)
+
+	if len(inv.dropped) != 0 {
+		t.Errorf("dropped = %v, want the age to restart after being referenced", inv.dropped)
+	}
+	if len(findings) != 1 || !findings[0].Since.Equal(now) {
+		t.Errorf("findings = %+v, want orphaned since now", findings)
+	}
+}
+
+func TestReconcile_ReferenceErrorDropsNothing(t *testing.T) {
+	inv := &fakeInventory{slots: []Slot{{Name: "philotes_orphan"}}}
+	cfg := DefaultConfig()
+	cfg.Prune = true
+	cfg.MinAge = 0
+	r := newTestReconciler(cfg, inv, func(context.Context) (References, error) {
+		return References{}, errors.New("relation \"philotes.sources\" does not exist")
+	})
+
+	if _, err := r.Reconcile(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(inv.dropped) != 0 {
+		t.Errorf("dropped = %v, want nothing when references are unknown", inv.dropped)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	list := Combine(References{Slots: []string{"a"}}, staticRefs(References{Slots: []string{"b"}, Publications: []string{"p"}}))
+	refs, err := list(context.Background())
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if !reflect.DeepEqual(refs.Slots, []string{"a", "b"}) || !reflect.DeepEqual(refs.Publications, []string{"p"}) {
+		t.Errorf("Combine() = %+v", refs)
+	}
+}
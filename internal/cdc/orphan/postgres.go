@@ -0,0 +1,140 @@
+package orphan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+)
+
+// PostgresInventory lists and drops replication objects in the source
+// database it is connected to.
+type PostgresInventory struct {
+	db *sql.DB
+}
+
+// NewPostgresInventory creates a new PostgresInventory.
+func NewPostgresInventory(db *sql.DB) *PostgresInventory {
+	return &PostgresInventory{db: db}
+}
+
+// ListSlots lists the logical replication slots of the current database.
+func (i *PostgresInventory) ListSlots(ctx context.Context) ([]Slot, error) {
+	query := `
+		SELECT slot_name, active,
+			COALESCE(pg_wal_lsn_diff(pg_current_wal_lsn(), restart_lsn), 0)::bigint
+		FROM pg_replication_slots
+		WHERE slot_type = 'logical' AND database = current_database()
+	`
+	rows, err := i.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query replication slots: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []Slot
+	for rows.Next() {
+		var s Slot
+		if err := rows.Scan(&s.Name, &s.Active, &s.RetainedBytes); err != nil {
+			return nil, fmt.Errorf("scan replication slot: %w", err)
+		}
+		slots = append(slots, s)
+	}
+	return slots, rows.Err()
+}
+
+// ListPublications lists the publications of the current database.
+func (i *PostgresInventory) ListPublications(ctx context.Context) ([]string, error) {
+	rows, err := i.db.QueryContext(ctx, `SELECT pubname FROM pg_publication`)
+	if err != nil {
+		return nil, fmt.Errorf("query publications: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan publication: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// DropSlot drops an inactive replication slot. A slot that became active in
+// the meantime is left alone.
+func (i *PostgresInventory) DropSlot(ctx context.Context, name string) error {
+	query := `
+		SELECT pg_drop_replication_slot(slot_name)
+		FROM pg_replication_slots
+		WHERE slot_name = $1 AND NOT active
+	`
+	if _, err := i.db.ExecContext(ctx, query, name); err != nil {
+		return fmt.Errorf("drop replication slot: %w", err)
+	}
+	return nil
+}
+
+// DropPublication drops a publication.
+func (i *PostgresInventory) DropPublication(ctx context.Context, name string) error {
+	if _, err := i.db.ExecContext(ctx, "DROP PUBLICATION IF EXISTS "+pgx.Identifier{name}.Sanitize()); err != nil {
+		return fmt.Errorf("drop publication: %w", err)
+	}
+	return nil
+}
+
+// RegisteredSources returns a ReferenceLister that reads the slots and
+// publications of every source registered through the API. Sources on other
+// hosts are included too; treating their names as referenced only makes the
+// reconciler more conservative.
+func RegisteredSources(db *sql.DB) ReferenceLister {
+	return func(ctx context.Context) (References, error) {
+		query := `
+			SELECT COALESCE(slot_name, ''), COALESCE(publication_name, '')
+			FROM philotes.sources
+		`
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return References{}, fmt.Errorf("query sources: %w", err)
+		}
+		defer rows.Close()
+
+		var refs References
+		for rows.Next() {
+			var slot, publication string
+			if err := rows.Scan(&slot, &publication); err != nil {
+				return References{}, fmt.Errorf("scan source: %w", err)
+			}
+			if slot != "" {
+				refs.Slots = append(refs.Slots, slot)
+			}
+			if publication != "" {
+				refs.Publications = append(refs.Publications, publication)
+			}
+		}
+		return refs, rows.Err()
+	}
+}
+
+// Combine returns a ReferenceLister that merges static references with those
+// returned by listers.
+func Combine(static References, listers ...ReferenceLister) ReferenceLister {
+	return func(ctx context.Context) (References, error) {
+		refs := References{
+			Slots:        append([]string(nil), static.Slots...),
+			Publications: append([]string(nil), static.Publications...),
+		}
+		for _, list := range listers {
+			more, err := list(ctx)
+			if err != nil {
+				return References{}, err
+			}
+			refs.Slots = append(refs.Slots, more.Slots...)
+			refs.Publications = append(refs.Publications, more.Publications...)
+		}
+		return refs, nil
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/janovincze/philotes/internal/metrics"
 )
 
 // BackpressureConfig holds configuration for backpressure handling.
@@ -12,16 +14,36 @@ type BackpressureConfig struct {
 	// Enabled enables backpressure handling.
 	Enabled bool
 
-	// HighWatermark is the threshold to trigger pause.
+	// HighWatermark is the buffer depth that triggers a pause. Zero disables
+	// the buffer depth signal.
 	HighWatermark int
 
-	// LowWatermark is the threshold to resume processing.
+	// LowWatermark is the buffer depth to resume processing at.
 	LowWatermark int
 
-	// CheckInterval is how often to check buffer size.
+	// LatencyHighWatermark is the average flush latency that triggers a
+	// pause, even while the buffer depth is below HighWatermark. Zero
+	// disables the flush latency signal.
+	LatencyHighWatermark time.Duration
+
+	// LatencyLowWatermark is the average flush latency to resume processing at.
+	LatencyLowWatermark time.Duration
+
+	// CheckInterval is how often to check the signals.
 	CheckInterval time.Duration
 }
 
+// BackpressureSignal is a signal that can pause the pipeline.
+type BackpressureSignal string
+
+const (
+	// SignalBufferDepth is the number of unprocessed events in the buffer.
+	SignalBufferDepth BackpressureSignal = "buffer_depth"
+
+	// SignalFlushLatency is the average time to flush a batch downstream.
+	SignalFlushLatency BackpressureSignal = "flush_latency"
+)
+
 // DefaultBackpressureConfig returns a BackpressureConfig with sensible defaults.
 func DefaultBackpressureConfig() BackpressureConfig {
 	return BackpressureConfig{
@@ -35,12 +57,19 @@ func DefaultBackpressureConfig() BackpressureConfig {
 // BufferSizeFunc is a function that returns the current buffer size.
 type BufferSizeFunc func(ctx context.Context) (int, error)
 
-// BackpressureController monitors buffer size and signals pause/resume.
+// FlushLatencyFunc is a function that returns the average flush latency.
+type FlushLatencyFunc func() time.Duration
+
+// BackpressureController monitors buffer size and flush latency and signals
+// pause/resume. The pipeline pauses when either signal crosses its high
+// watermark and resumes once both are at or below their low watermarks.
 type BackpressureController struct {
 	config       BackpressureConfig
 	getSize      BufferSizeFunc
+	getLatency   FlushLatencyFunc
 	stateMachine *StateMachine
 	logger       *slog.Logger
+	sourceName   string
 
 	mu          sync.RWMutex
 	paused      bool
@@ -49,6 +78,8 @@ type BackpressureController struct {
 	pauseCount  int64
 	resumeCount int64
 	lastSize    int
+	lastLatency time.Duration
+	pauseSignal BackpressureSignal
 }
 
 // NewBackpressureController creates a new BackpressureController.
@@ -70,7 +101,18 @@ func NewBackpressureController(
 	}
 }
 
-// Start begins monitoring buffer size.
+// SetSourceName sets the source name for metric labels.
+func (c *BackpressureController) SetSourceName(name string) {
+	c.sourceName = name
+}
+
+// SetFlushLatencyFunc sets the function reporting the average flush latency,
+// enabling the flush latency signal when LatencyHighWatermark is set.
+func (c *BackpressureController) SetFlushLatencyFunc(fn FlushLatencyFunc) {
+	c.getLatency = fn
+}
+
+// Start begins monitoring buffer size and flush latency.
 // It runs until the context is cancelled.
 func (c *BackpressureController) Start(ctx context.Context) {
 	if !c.config.Enabled {
@@ -84,6 +126,8 @@ func (c *BackpressureController) Start(ctx context.Context) {
 	c.logger.Info("backpressure controller started",
 		"high_watermark", c.config.HighWatermark,
 		"low_watermark", c.config.LowWatermark,
+		"latency_high_watermark", c.config.LatencyHighWatermark,
+		"latency_low_watermark", c.config.LatencyLowWatermark,
 		"check_interval", c.config.CheckInterval,
 	)
 
@@ -98,16 +142,21 @@ func (c *BackpressureController) Start(ctx context.Context) {
 	}
 }
 
-// check performs a single buffer size check.
+// check performs a single check of the signals.
 func (c *BackpressureController) check(ctx context.Context) {
 	size, err := c.getSize(ctx)
 	if err != nil {
 		c.logger.Warn("failed to get buffer size", "error", err)
 		return
 	}
+	var latency time.Duration
+	if c.getLatency != nil {
+		latency = c.getLatency()
+	}
 
 	c.mu.Lock()
 	c.lastSize = size
+	c.lastLatency = latency
 	c.mu.Unlock()
 
 	currentState := c.stateMachine.State()
@@ -117,15 +166,31 @@ func (c *BackpressureController) check(ctx context.Context) {
 		return
 	}
 
-	if size >= c.config.HighWatermark && currentState == StateRunning {
-		c.pause()
-	} else if size <= c.config.LowWatermark && currentState == StatePaused {
-		c.resume()
+	depthEnabled := c.config.HighWatermark > 0
+	latencyEnabled := c.config.LatencyHighWatermark > 0 && c.getLatency != nil
+
+	switch currentState {
+	case StateRunning:
+		if depthEnabled && size >= c.config.HighWatermark {
+			c.pause(SignalBufferDepth)
+		} else if latencyEnabled && latency >= c.config.LatencyHighWatermark {
+			c.pause(SignalFlushLatency)
+		}
+	case StatePaused:
+		// An operator pause outlasts backpressure
+		if c.stateMachine.IsHeld() {
+			return
+		}
+		depthClear := !depthEnabled || size <= c.config.LowWatermark
+		latencyClear := !latencyEnabled || latency <= c.config.LatencyLowWatermark
+		if depthClear && latencyClear {
+			c.resume()
+		}
 	}
 }
 
-// pause triggers a pause due to backpressure.
-func (c *BackpressureController) pause() {
+// pause triggers a pause due to backpressure from signal.
+func (c *BackpressureController) pause(signal BackpressureSignal) {
 	if err := c.stateMachine.Transition(StatePaused); err != nil {
 		c.logger.Warn("failed to transition to paused state", "error", err)
 		return
@@ -135,11 +200,17 @@ func (c *BackpressureController) pause() {
 	c.paused = true
 	c.pausedAt = time.Now()
 	c.pauseCount++
+	c.pauseSignal = signal
 	c.mu.Unlock()
 
+	metrics.CDCBackpressurePausesTotal.WithLabelValues(c.sourceName, string(signal)).Inc()
+
 	c.logger.Warn("backpressure triggered, pausing pipeline",
+		"signal", signal,
 		"buffer_size", c.lastSize,
 		"high_watermark", c.config.HighWatermark,
+		"flush_latency", c.lastLatency,
+		"latency_high_watermark", c.config.LatencyHighWatermark,
 	)
 }
 
@@ -157,9 +228,13 @@ func (c *BackpressureController) resume() {
 	c.resumeCount++
 	c.mu.Unlock()
 
+	metrics.CDCBackpressureResumesTotal.WithLabelValues(c.sourceName).Inc()
+
 	c.logger.Info("backpressure cleared, resuming pipeline",
 		"buffer_size", c.lastSize,
 		"low_watermark", c.config.LowWatermark,
+		"flush_latency", c.lastLatency,
+		"latency_low_watermark", c.config.LatencyLowWatermark,
 		"pause_duration", pauseDuration,
 	)
 }
@@ -188,6 +263,8 @@ func (c *BackpressureController) Stats() BackpressureStats {
 		PauseCount:  c.pauseCount,
 		ResumeCount: c.resumeCount,
 		LastSize:    c.lastSize,
+		LastLatency: c.lastLatency,
+		PauseSignal: c.pauseSignal,
 	}
 }
 
@@ -210,4 +287,10 @@ type BackpressureStats struct {
 
 	// LastSize is the last observed buffer size.
 	LastSize int `json:"last_size"`
+
+	// LastLatency is the last observed average flush latency.
+	LastLatency time.Duration `json:"last_latency"`
+
+	// PauseSignal is the signal that triggered the last pause.
+	PauseSignal BackpressureSignal `json:"pause_signal,omitempty"`
 }
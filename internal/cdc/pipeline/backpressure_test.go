@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestController returns a controller of a running pipeline whose buffer
+// depth and flush latency are read from size and latency.
+func newTestController(cfg BackpressureConfig, size *int, latency *time.Duration) *BackpressureController {
+	sm := NewStateMachine()
+	_ = sm.Transition(StateRunning)
+
+	c := NewBackpressureController(cfg, func(_ context.Context) (int, error) {
+		return *size, nil
+	}, sm, nil)
+	c.SetSourceName("test")
+	c.SetFlushLatencyFunc(func() time.Duration { return *latency })
+	return c
+}
+
+func TestBackpressure_PausesOnEitherSignal(t *testing.T) {
+	cfg := BackpressureConfig{
+		Enabled:              true,
+		HighWatermark:        100,
+		LowWatermark:         50,
+		LatencyHighWatermark: 5 * time.Second,
+		LatencyLowWatermark:  time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		size    int
+		latency time.Duration
+		want    BackpressureSignal
+	}{
+		{"buffer depth", 100, 0, SignalBufferDepth},
+		{"flush latency below high watermark depth", 10, 6 * time.Second, SignalFlushLatency},
+		{"neither", 99, 4 * time.Second, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(cfg, &tt.size, &tt.latency)
+			c.check(context.Background())
+
+			stats := c.Stats()
+			if paused := tt.want != ""; stats.IsPaused != paused {
+				t.Fatalf("IsPaused = %v, want %v", stats.IsPaused, paused)
+			}
+			if stats.PauseSignal != tt.want {
+				t.Errorf("PauseSignal = %q, want %q", stats.PauseSignal, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackpressure_ResumesWhenBothSignalsClear(t *testing.T) {
+	cfg := BackpressureConfig{
+		Enabled:              true,
+		HighWatermark:        100,
+		LowWatermark:         50,
+		LatencyHighWatermark: 5 * time.Second,
+		LatencyLowWatermark:  time.Second,
+	}
+	size, latency := 10, 6*time.Second
+	c := newTestController(cfg, &size, &latency)
+
+	c.check(context.Background())
+	if !c.IsPaused() {
+		t.Fatal("expected pause on flush latency")
+	}
+
+	// Latency is below the high but above the low watermark
+	latency = 3 * time.Second
+	c.check(context.Background())
+	if !c.IsPaused() {
+		t.Fatal("expected to stay paused until latency reaches the low watermark")
+	}
+
+	latency = time.Second
+	size = 60
+	c.check(context.Background())
+	if !c.IsPaused() {
+		t.Fatal("expected to stay paused until buffer depth reaches the low watermark")
+	}
+
+	size = 50
+	c.check(context.Background())
+	if c.IsPaused() {
+		t.Fatal("expected resume once both signals cleared")
+	}
+	if got := c.stateMachine.State(); got != StateRunning {
+		t.Errorf("state = %v, want running", got)
+	}
+}
+
+func TestBackpressure_DisabledSignals(t *testing.T) {
+	// Only the flush latency signal is enabled
+	cfg := BackpressureConfig{Enabled: true, LatencyHighWatermark: 5 * time.Second}
+	size, latency := 1_000_000, time.Second
+	c := newTestController(cfg, &size, &latency)
+
+	c.check(context.Background())
+	if c.IsPaused() {
+		t.Fatal("expected a disabled buffer depth signal to be ignored")
+	}
+
+	// Only the buffer depth signal is enabled
+	cfg = BackpressureConfig{Enabled: true, HighWatermark: 100, LowWatermark: 50}
+	size, latency = 10, time.Hour
+	c = newTestController(cfg, &size, &latency)
+
+	c.check(context.Background())
+	if c.IsPaused() {
+		t.Fatal("expected a disabled flush latency signal to be ignored")
+	}
+}
+
+func TestBackpressure_DoesNotResumeOperatorPause(t *testing.T) {
+	cfg := BackpressureConfig{Enabled: true, HighWatermark: 100, LowWatermark: 50}
+	size, latency := 100, time.Duration(0)
+	c := newTestController(cfg, &size, &latency)
+
+	c.check(context.Background())
+	if err := c.stateMachine.Hold(); err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+
+	size = 0
+	c.check(context.Background())
+	if got := c.stateMachine.State(); got != StatePaused {
+		t.Errorf("state = %v once backpressure cleared, want paused until released", got)
+	}
+}
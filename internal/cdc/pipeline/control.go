@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ControlStore reports whether an operator paused the pipeline.
+type ControlStore interface {
+	Paused(ctx context.Context) (bool, error)
+}
+
+// PostgresControlStore reads the pause state of a pipeline registered
+// through the API from philotes.pipelines, where the API's pause and resume
+// endpoints record it. Since the state is stored, a restarted worker
+// resumes paused.
+type PostgresControlStore struct {
+	db         *sql.DB
+	pipelineID string
+}
+
+// NewPostgresControlStore creates a PostgresControlStore for the pipeline
+// with the given ID.
+func NewPostgresControlStore(db *sql.DB, pipelineID string) *PostgresControlStore {
+	return &PostgresControlStore{db: db, pipelineID: pipelineID}
+}
+
+// Paused reports whether the pipeline's status is paused.
+func (s *PostgresControlStore) Paused(ctx context.Context) (bool, error) {
+	var status string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT status FROM philotes.pipelines WHERE id = $1`, s.pipelineID,
+	).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("pipeline %s not found", s.pipelineID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read pipeline status: %w", err)
+	}
+	return status == "paused", nil
+}
+
+// SyncControl pauses or resumes the pipeline to match store.
+func (p *Pipeline) SyncControl(ctx context.Context, store ControlStore) error {
+	paused, err := store.Paused(ctx)
+	if err != nil {
+		return err
+	}
+	if paused == p.stateMachine.IsHeld() {
+		return nil
+	}
+
+	if paused {
+		p.logger.Info("pipeline paused by operator")
+		return p.Pause()
+	}
+	p.logger.Info("pipeline resumed by operator")
+	return p.Resume()
+}
+
+// WatchControl syncs the pipeline with store every interval until ctx is
+// done.
+func (p *Pipeline) WatchControl(ctx context.Context, store ControlStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.SyncControl(ctx, store); err != nil {
+				p.logger.Warn("failed to sync pipeline control", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// controlFlag is a control store an operator pauses by setting it.
+type controlFlag struct {
+	paused atomic.Bool
+}
+
+func (f *controlFlag) Paused(_ context.Context) (bool, error) {
+	return f.paused.Load(), nil
+}
+
+func TestRun_PausedByOperator(t *testing.T) {
+	buf := newKeyedBuffer()
+	src := &streamSource{events: make(chan cdc.Event)}
+	cfg := DefaultConfig()
+	cfg.CheckpointEnabled = false
+	p := New(src, nil, buf, cfg, nil)
+
+	// Paused before a restart, the pipeline starts paused
+	control := &controlFlag{}
+	control.paused.Store(true)
+	if err := p.SyncControl(context.Background(), control); err != nil {
+		t.Fatalf("SyncControl() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	src.events <- change(1)
+	waitFor(t, "the pipeline to pause", func() bool { return p.State() == StatePaused })
+	time.Sleep(50 * time.Millisecond)
+	if n := buf.len(); n != 0 {
+		t.Fatalf("buffered %d events while paused, want none", n)
+	}
+
+	control.paused.Store(false)
+	if err := p.SyncControl(ctx, control); err != nil {
+		t.Fatalf("SyncControl() error = %v", err)
+	}
+	waitFor(t, "the change to be buffered", func() bool { return buf.len() == 1 })
+
+	// A pause while running stops consuming until resumed
+	control.paused.Store(true)
+	if err := p.SyncControl(ctx, control); err != nil {
+		t.Fatalf("SyncControl() error = %v", err)
+	}
+	src.events <- change(2)
+	time.Sleep(50 * time.Millisecond)
+	if n := buf.len(); n != 1 {
+		t.Fatalf("buffered %d events, want the second held while paused", n)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v while paused", err)
+	}
+}
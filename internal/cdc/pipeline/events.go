@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// EventType identifies a pipeline event.
+type EventType string
+
+const (
+	// EventVerificationDiscrepancy is emitted when a backfilled table fails
+	// post-snapshot verification.
+	EventVerificationDiscrepancy EventType = "verification_discrepancy"
+)
+
+// Event is something that happened to a pipeline that operators should
+// know about.
+type Event struct {
+	Type   EventType `json:"type"`
+	Source string    `json:"source"`
+	Table  string    `json:"table,omitempty"`
+
+	// SourceRows and IcebergRows are the row counts of a table in the
+	// source database and in Iceberg.
+	SourceRows  int64 `json:"source_rows,omitempty"`
+	IcebergRows int64 `json:"iceberg_rows,omitempty"`
+
+	// Discrepancies is the number of discrepancies verification found.
+	Discrepancies int64 `json:"discrepancies,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// EventListener is called when the pipeline emits an event.
+type EventListener func(Event)
+
+// AddEventListener adds a pipeline event listener.
+func (p *Pipeline) AddEventListener(listener EventListener) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, listener)
+}
+
+// emit logs and counts an event and notifies the listeners.
+func (p *Pipeline) emit(e Event) {
+	e.Source = p.source.Name()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	p.logger.Warn("pipeline event", "type", e.Type, "table", e.Table,
+		"source_rows", e.SourceRows, "iceberg_rows", e.IcebergRows, "discrepancies", e.Discrepancies)
+	metrics.CDCPipelineEventsTotal.WithLabelValues(e.Source, string(e.Type)).Inc()
+
+	// Copy to avoid holding the lock while notifying
+	p.mu.RLock()
+	listeners := make([]EventListener, len(p.listeners))
+	copy(listeners, p.listeners)
+	p.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(e)
+	}
+}
@@ -0,0 +1,181 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// GapPolicy controls what the pipeline does when the replication slot has
+// lost the position of the last checkpoint.
+type GapPolicy string
+
+const (
+	// GapPolicyHalt stops the pipeline until an operator acknowledges the gap.
+	GapPolicyHalt GapPolicy = "halt"
+	// GapPolicySnapshot resumes from the slot's position and requests a backfill snapshot.
+	GapPolicySnapshot GapPolicy = "snapshot"
+	// GapPolicyResume resumes from the slot's position, accepting the data loss.
+	GapPolicyResume GapPolicy = "resume"
+)
+
+// ParseGapPolicy parses a gap policy. An empty string means GapPolicyHalt.
+func ParseGapPolicy(s string) (GapPolicy, error) {
+	switch GapPolicy(s) {
+	case "", GapPolicyHalt:
+		return GapPolicyHalt, nil
+	case GapPolicySnapshot, GapPolicyResume:
+		return GapPolicy(s), nil
+	}
+	return "", fmt.Errorf("invalid gap policy %q (want halt, snapshot or resume)", s)
+}
+
+// ErrReplicationGap is returned when the pipeline halts on a replication gap.
+var ErrReplicationGap = errors.New("replication gap detected")
+
+// Gap describes changes the replication slot can no longer deliver.
+type Gap struct {
+	// CheckpointLSN is the last checkpointed position.
+	CheckpointLSN string
+
+	// SlotLSN is the slot's current confirmed position (empty if the slot is missing).
+	SlotLSN string
+
+	// Reason describes why the gap was detected.
+	Reason string
+}
+
+// GapHandler requests a backfill snapshot for a gap.
+type GapHandler func(ctx context.Context, gap *Gap) error
+
+// DetectGap compares the last checkpoint with the slot's position. It returns
+// nil when the slot can still deliver every change after the checkpoint.
+//
+// Only a missing slot, or one whose restart LSN is past the checkpoint, has
+// lost WAL. A confirmed flush position behind the checkpoint is normal after
+// a restart, since the slot's position is not advanced as changes are
+// checkpointed; the slot then redelivers changes, which deduplication drops.
+func DetectGap(checkpointLSN string, pos *source.SlotPosition) (*Gap, error) {
+	if checkpointLSN == "" || pos == nil {
+		return nil, nil
+	}
+
+	if !pos.Exists {
+		return &Gap{
+			CheckpointLSN: checkpointLSN,
+			Reason:        "replication slot does not exist and will be recreated at the current position",
+		}, nil
+	}
+
+	checkpoint, err := cdc.ParseLSN(checkpointLSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse checkpoint LSN: %w", err)
+	}
+
+	if pos.RestartLSN != "" {
+		restart, err := cdc.ParseLSN(pos.RestartLSN)
+		if err != nil {
+			return nil, fmt.Errorf("parse restart LSN: %w", err)
+		}
+		if restart > checkpoint {
+			return &Gap{
+				CheckpointLSN: checkpointLSN,
+				SlotLSN:       pos.ConfirmedFlushLSN,
+				Reason:        "replication slot no longer retains WAL after the last checkpoint",
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// SetGapHandler sets the handler that requests a backfill for a gap. Without
+// one, a pipeline with a snapshotter backfills the gap by snapshotting every
+// configured table before streaming resumes.
+func (p *Pipeline) SetGapHandler(h GapHandler) {
+	p.gapHandler = h
+}
+
+// backfillGap schedules a snapshot of every configured table regardless of
+// the snapshot trigger and of tables already recorded as complete, so the
+// rows changed during the gap are copied again.
+func (p *Pipeline) backfillGap(_ context.Context, _ *Gap) error {
+	p.mu.Lock()
+	p.snapshotted = nil
+	p.backfill = true
+	p.mu.Unlock()
+	return nil
+}
+
+// checkGap inspects the source's replication slot and applies the gap policy.
+// It returns an error wrapping ErrReplicationGap when the pipeline must halt.
+func (p *Pipeline) checkGap(ctx context.Context) error {
+	inspector, ok := p.source.(source.SlotInspector)
+	if !ok {
+		return nil
+	}
+
+	p.mu.RLock()
+	checkpointLSN := p.lastLSN
+	p.mu.RUnlock()
+
+	pos, err := inspector.SlotPosition(ctx)
+	if err != nil {
+		p.logger.Warn("failed to inspect replication slot", "error", err)
+		return nil
+	}
+
+	gap, err := DetectGap(checkpointLSN, pos)
+	if err != nil {
+		p.logger.Warn("failed to check replication gap", "error", err)
+		return nil
+	}
+	if gap == nil {
+		metrics.CDCReplicationGap.WithLabelValues(p.source.Name()).Set(0)
+		return nil
+	}
+
+	policy := p.config.GapPolicy
+	acknowledged := p.config.GapAckLSN != "" && p.config.GapAckLSN == gap.CheckpointLSN
+	if policy == GapPolicyHalt && acknowledged {
+		// The operator confirmed resuming from here; backfill the gap.
+		policy = GapPolicySnapshot
+	}
+
+	attrs := []any{"checkpoint_lsn", gap.CheckpointLSN, "slot_lsn", gap.SlotLSN, "reason", gap.Reason, "policy", policy}
+
+	switch policy {
+	case GapPolicyResume:
+		p.logger.Warn("replication gap detected, resuming without backfill", attrs...)
+		metrics.CDCReplicationGap.WithLabelValues(p.source.Name()).Set(0)
+		return nil
+
+	case GapPolicySnapshot:
+		handler := p.gapHandler
+		if handler == nil && p.snapshotter != nil {
+			handler = p.backfillGap
+		}
+		if handler != nil {
+			if err := handler(ctx, gap); err != nil {
+				p.logger.Error("failed to request backfill snapshot", append(attrs, "error", err)...)
+				break
+			}
+			p.logger.Warn("replication gap detected, resuming and backfilling via snapshot", attrs...)
+			metrics.CDCReplicationGap.WithLabelValues(p.source.Name()).Set(0)
+			return nil
+		}
+		if acknowledged {
+			p.logger.Warn("replication gap acknowledged, resuming; no snapshot handler is configured so the gap must be backfilled manually", attrs...)
+			metrics.CDCReplicationGap.WithLabelValues(p.source.Name()).Set(0)
+			return nil
+		}
+	}
+
+	metrics.CDCReplicationGap.WithLabelValues(p.source.Name()).Set(1)
+	p.logger.Error("replication gap detected, halting pipeline; set PHILOTES_CDC_GAP_ACK_LSN to the checkpoint LSN to resume", attrs...)
+	return fmt.Errorf("%w: %s (checkpoint %s)", ErrReplicationGap, gap.Reason, gap.CheckpointLSN)
+}
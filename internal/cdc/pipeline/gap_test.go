@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// slotSource is a source whose replication slot position is fixed.
+type slotSource struct {
+	pos     *source.SlotPosition
+	started bool
+	events  chan cdc.Event
+}
+
+func (s *slotSource) Start(_ context.Context) (<-chan cdc.Event, <-chan error) {
+	s.started = true
+	s.events = make(chan cdc.Event)
+	close(s.events)
+	return s.events, make(chan error)
+}
+
+func (s *slotSource) Stop(_ context.Context) error { return nil }
+func (s *slotSource) LastLSN() string              { return "" }
+func (s *slotSource) Name() string                 { return "test" }
+
+func (s *slotSource) SlotPosition(_ context.Context) (*source.SlotPosition, error) {
+	return s.pos, nil
+}
+
+// staticCheckpoint always loads the same checkpoint.
+type staticCheckpoint struct {
+	lsn string
+}
+
+func (c staticCheckpoint) Save(_ context.Context, _ cdc.Checkpoint) error { return nil }
+func (c staticCheckpoint) Delete(_ context.Context, _ string) error       { return nil }
+func (c staticCheckpoint) Close() error                                   { return nil }
+
+func (c staticCheckpoint) Load(_ context.Context, sourceID string) (*cdc.Checkpoint, error) {
+	return &cdc.Checkpoint{SourceID: sourceID, LSN: c.lsn, CommittedAt: time.Now()}, nil
+}
+
+func TestDetectGap(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkpoint string
+		pos        *source.SlotPosition
+		wantGap    bool
+	}{
+		{"no checkpoint", "", &source.SlotPosition{Exists: false}, false},
+		{"slot missing", "0/100", &source.SlotPosition{Exists: false}, true},
+		{"healthy slot", "0/100", &source.SlotPosition{Exists: true, RestartLSN: "0/80", ConfirmedFlushLSN: "0/120"}, false},
+		{"restart at checkpoint", "0/100", &source.SlotPosition{Exists: true, RestartLSN: "0/100", ConfirmedFlushLSN: "0/100"}, false},
+		{"recreated slot", "0/100", &source.SlotPosition{Exists: true, RestartLSN: "0/500", ConfirmedFlushLSN: "0/500"}, true},
+		// The slot's confirmed position is not advanced by checkpoints, so
+		// it trails them after a restart; the slot still has the WAL
+		{"regressed slot", "0/100", &source.SlotPosition{Exists: true, RestartLSN: "0/10", ConfirmedFlushLSN: "0/50"}, false},
+		{"checkpoint ahead of confirmed flush", "0/500", &source.SlotPosition{Exists: true, RestartLSN: "0/80", ConfirmedFlushLSN: "0/100"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gap, err := DetectGap(tt.checkpoint, tt.pos)
+			if err != nil {
+				t.Fatalf("DetectGap() error = %v", err)
+			}
+			if (gap != nil) != tt.wantGap {
+				t.Errorf("DetectGap() = %+v, wantGap %v", gap, tt.wantGap)
+			}
+		})
+	}
+}
+
+func TestParseGapPolicy(t *testing.T) {
+	if p, err := ParseGapPolicy(""); err != nil || p != GapPolicyHalt {
+		t.Errorf("ParseGapPolicy(\"\") = %q, %v; want halt", p, err)
+	}
+	if _, err := ParseGapPolicy("skip"); err == nil {
+		t.Error("expected error for invalid policy")
+	}
+}
+
+func runWithRecreatedSlot(t *testing.T, cfg Config, handler GapHandler) (*slotSource, error) {
+	t.Helper()
+
+	src := &slotSource{pos: &source.SlotPosition{Exists: true, RestartLSN: "0/500", ConfirmedFlushLSN: "0/500"}}
+	cfg.CheckpointEnabled = true
+	cfg.BufferEnabled = false
+
+	p := New(src, staticCheckpoint{lsn: "0/100"}, nil, cfg, nil)
+	if handler != nil {
+		p.SetGapHandler(handler)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return src, p.Run(ctx)
+}
+
+func TestRun_HaltsOnRecreatedSlot(t *testing.T) {
+	src, err := runWithRecreatedSlot(t, Config{GapPolicy: GapPolicyHalt}, nil)
+	if !errors.Is(err, ErrReplicationGap) {
+		t.Fatalf("Run() error = %v, want ErrReplicationGap", err)
+	}
+	if src.started {
+		t.Error("source must not start streaming past a gap")
+	}
+}
+
+func TestRun_RestartBehindCheckpointDoesNotHalt(t *testing.T) {
+	// After a normal restart the checkpoint is ahead of the slot's
+	// confirmed flush position, which only means changes are redelivered
+	src := &slotSource{pos: &source.SlotPosition{Exists: true, RestartLSN: "0/80", ConfirmedFlushLSN: "0/100"}}
+	cfg := Config{GapPolicy: GapPolicyHalt, CheckpointEnabled: true}
+
+	p := New(src, staticCheckpoint{lsn: "0/500"}, nil, cfg, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := p.Run(ctx); errors.Is(err, ErrReplicationGap) {
+		t.Fatalf("Run() error = %v, want no gap", err)
+	}
+	if !src.started {
+		t.Error("expected source to start streaming after a normal restart")
+	}
+}
+
+func TestRun_ResumesWhenGapAcknowledged(t *testing.T) {
+	var requested *Gap
+	src, err := runWithRecreatedSlot(t, Config{GapPolicy: GapPolicyHalt, GapAckLSN: "0/100"}, func(_ context.Context, gap *Gap) error {
+		requested = gap
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !src.started {
+		t.Error("expected source to start after acknowledgement")
+	}
+	if requested == nil || requested.CheckpointLSN != "0/100" {
+		t.Errorf("expected backfill request for checkpoint 0/100, got %+v", requested)
+	}
+}
+
+func TestRun_SnapshotPolicyRequestsBackfill(t *testing.T) {
+	calls := 0
+	src, err := runWithRecreatedSlot(t, Config{GapPolicy: GapPolicySnapshot}, func(_ context.Context, _ *Gap) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if calls != 1 || !src.started {
+		t.Errorf("calls = %d, started = %v; want 1, true", calls, src.started)
+	}
+}
+
+func TestRun_SnapshotPolicyHaltsWithoutHandler(t *testing.T) {
+	_, err := runWithRecreatedSlot(t, Config{GapPolicy: GapPolicySnapshot}, nil)
+	if !errors.Is(err, ErrReplicationGap) {
+		t.Fatalf("Run() error = %v, want ErrReplicationGap", err)
+	}
+}
+
+func TestRun_ResumePolicyContinues(t *testing.T) {
+	src, err := runWithRecreatedSlot(t, Config{GapPolicy: GapPolicyResume}, nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !src.started {
+		t.Error("expected source to start under resume policy")
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/janovincze/philotes/internal/cdc/buffer"
 	"github.com/janovincze/philotes/internal/cdc/checkpoint"
 	"github.com/janovincze/philotes/internal/cdc/health"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
 	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/cdc/transform"
+	"github.com/janovincze/philotes/internal/cdc/verify"
 	"github.com/janovincze/philotes/internal/metrics"
 )
 
@@ -28,10 +31,30 @@ type Pipeline struct {
 	// Optional components
 	backpressure *BackpressureController
 	retryer      *Retryer
-
-	mu      sync.RWMutex
-	lastLSN string
-	stats   Stats
+	verifier     *verify.Verifier
+	gapHandler   GapHandler
+	snapshotter  *snapshot.Snapshotter
+	commits      CommitTracker
+	transformer  *transform.Transformer
+
+	mu        sync.RWMutex
+	listeners []EventListener
+	lastLSN   string
+	lastEvent cdc.Event
+	stats     Stats
+
+	// snapshotted maps tables whose snapshot completed to the LSN it was
+	// consistent with. It is persisted with each checkpoint.
+	snapshotted map[string]string
+
+	// backfill forces a snapshot of every table, set when a replication gap
+	// is backfilled by the snapshotter.
+	backfill bool
+
+	// caughtUp is set once the pipeline has streamed up to its restored
+	// checkpoint, resumeLSN, or saved a checkpoint since it started.
+	caughtUp  bool
+	resumeLSN cdc.LSN
 }
 
 // Config holds pipeline configuration.
@@ -50,6 +73,12 @@ type Config struct {
 
 	// BackpressureConfig configures backpressure handling.
 	BackpressureConfig BackpressureConfig
+
+	// GapPolicy controls what happens when the replication slot lost the checkpointed position.
+	GapPolicy GapPolicy
+
+	// GapAckLSN is an operator acknowledgement of a gap at this checkpoint LSN.
+	GapAckLSN string
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -60,6 +89,7 @@ func DefaultConfig() Config {
 		BufferEnabled:      true,
 		RetryPolicy:        DefaultRetryPolicy(),
 		BackpressureConfig: DefaultBackpressureConfig(),
+		GapPolicy:          GapPolicyHalt,
 	}
 }
 
@@ -73,6 +103,9 @@ type Stats struct {
 	Errors            int64
 	RetryCount        int64
 	State             State
+
+	// VerificationFailures counts tables that failed post-snapshot verification.
+	VerificationFailures int64
 }
 
 // New creates a new CDC pipeline.
@@ -113,6 +146,74 @@ func (p *Pipeline) SetBackpressureController(bp *BackpressureController) {
 	p.backpressure = bp
 	// Set the state machine so the controller can pause/resume the pipeline
 	bp.SetStateMachine(p.stateMachine)
+	bp.SetSourceName(p.source.Name())
+}
+
+// CommitTracker reports the last buffered event committed downstream
+// together with every event buffered before it.
+type CommitTracker interface {
+	CommittedEvent() (cdc.Event, bool)
+}
+
+// SetCommitTracker makes checkpoints follow the events committed downstream
+// rather than the events buffered. A restart then replays every change not
+// yet committed, and the buffer drops the ones it already holds, so each
+// change is delivered exactly once.
+func (p *Pipeline) SetCommitTracker(t CommitTracker) {
+	p.commits = t
+}
+
+// SetTransformer sets the column rules applied to events before they are
+// buffered, so excluded or masked values are never persisted.
+func (p *Pipeline) SetTransformer(t *transform.Transformer) {
+	p.transformer = t
+}
+
+// SetVerifier sets the post-snapshot verifier. Tables that fail
+// verification are backfilled again by the pipeline's snapshot when the
+// verifier re-runs mismatches.
+func (p *Pipeline) SetVerifier(v *verify.Verifier) {
+	p.verifier = v
+	v.SetRerunner(p)
+}
+
+// OnSnapshotComplete verifies the backfilled tables once a snapshot has
+// completed and its rows have been committed to Iceberg. Tables that fail to
+// verify are counted and emitted as EventVerificationDiscrepancy; a failed
+// verification does not stop the pipeline.
+func (p *Pipeline) OnSnapshotComplete(ctx context.Context, tables []verify.Table) []*verify.Result {
+	if p.verifier == nil || !p.verifier.Enabled() {
+		return nil
+	}
+	if err := p.waitForSnapshotFlush(ctx, tables); err != nil {
+		p.logger.Error("snapshot verification skipped, rows were not flushed", "error", err)
+		return nil
+	}
+
+	results := make([]*verify.Result, 0, len(tables))
+	for _, t := range tables {
+		result, err := p.verifier.Verify(ctx, t)
+		if err != nil {
+			p.logger.Error("snapshot verification error", "table", t.String(), "error", err)
+			continue
+		}
+		if !result.Passed {
+			p.mu.Lock()
+			p.stats.VerificationFailures++
+			p.mu.Unlock()
+
+			p.emit(Event{
+				Type:          EventVerificationDiscrepancy,
+				Table:         result.Table,
+				SourceRows:    result.SourceRows,
+				IcebergRows:   result.TargetRows,
+				Discrepancies: result.Discrepancies,
+				Time:          result.VerifiedAt,
+			})
+		}
+		results = append(results, result)
+	}
+	return results
 }
 
 // Run starts the pipeline and blocks until context is cancelled or an error occurs.
@@ -121,6 +222,11 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		// Already starting or running
 		return fmt.Errorf("pipeline state transition failed: %w", err)
 	}
+	if p.stateMachine.IsHeld() {
+		if err := p.stateMachine.Hold(); err != nil {
+			p.logger.Warn("failed to pause held pipeline", "error", err)
+		}
+	}
 
 	defer func() {
 		if err := p.stateMachine.Transition(StateStopped); err != nil {
@@ -130,6 +236,11 @@ func (p *Pipeline) Run(ctx context.Context) error {
 
 	p.logger.Info("starting CDC pipeline")
 
+	p.mu.Lock()
+	p.caughtUp = false
+	p.resumeLSN = 0
+	p.mu.Unlock()
+
 	// Try to restore from last checkpoint
 	if p.config.CheckpointEnabled && p.checkpoint != nil {
 		if err := p.restoreCheckpoint(ctx); err != nil {
@@ -138,6 +249,23 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		}
 	}
 
+	// Refuse to silently skip changes the replication slot can no longer deliver
+	if err := p.checkGap(ctx); err != nil {
+		if transErr := p.stateMachine.Transition(StateFailed); transErr != nil {
+			p.logger.Warn("failed to transition to failed state", "error", transErr)
+		}
+		return err
+	}
+
+	// Backfill existing rows before streaming, or lazily once streaming runs
+	lazySnapshot, err := p.prepareSnapshot(ctx)
+	if err != nil {
+		if transErr := p.stateMachine.Transition(StateFailed); transErr != nil {
+			p.logger.Warn("failed to transition to failed state", "error", transErr)
+		}
+		return err
+	}
+
 	// Start backpressure controller if configured
 	if p.backpressure != nil {
 		go p.backpressure.Start(ctx)
@@ -146,6 +274,18 @@ func (p *Pipeline) Run(ctx context.Context) error {
 	// Start the source
 	events, errors := p.source.Start(ctx)
 
+	if lazySnapshot {
+		go p.runLazySnapshot(ctx)
+	}
+
+	// Without a checkpoint there is nothing to catch up with
+	p.mu.RLock()
+	resumeLSN := p.resumeLSN
+	p.mu.RUnlock()
+	if resumeLSN == 0 {
+		p.markCaughtUp()
+	}
+
 	// Start checkpoint ticker if enabled
 	var checkpointTicker *time.Ticker
 	var checkpointCh <-chan time.Time
@@ -197,17 +337,13 @@ func (p *Pipeline) Run(ctx context.Context) error {
 				return nil
 			}
 
-			// Check if we should process (not paused due to backpressure)
-			if !p.stateMachine.CanProcess() {
-				// Wait for resume or context cancellation
-				p.logger.Debug("pipeline paused, waiting to resume")
-				for !p.stateMachine.CanProcess() {
-					select {
-					case <-ctx.Done():
-						return ctx.Err()
-					case <-time.After(100 * time.Millisecond):
-					}
-				}
+			// Wait while paused by backpressure or an operator
+			if !p.waitUntilProcessing(ctx, checkpointCh) {
+				continue
+			}
+
+			if p.snapshotter != nil {
+				p.snapshotter.Observe(event)
 			}
 
 			if err := p.processEventWithRetry(ctx, event); err != nil {
@@ -223,6 +359,29 @@ func (p *Pipeline) Run(ctx context.Context) error {
 	}
 }
 
+// waitUntilProcessing blocks while the pipeline is paused, saving
+// checkpoints as committed events advance. It returns false if ctx is done
+// first; the event received is then left for the source to replay.
+func (p *Pipeline) waitUntilProcessing(ctx context.Context, checkpointCh <-chan time.Time) bool {
+	if p.stateMachine.CanProcess() {
+		return true
+	}
+
+	p.logger.Debug("pipeline paused, waiting to resume")
+	for !p.stateMachine.CanProcess() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-checkpointCh:
+			if err := p.saveCheckpoint(ctx); err != nil {
+				p.logger.Error("failed to save checkpoint", "error", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return true
+}
+
 // processEventWithRetry processes an event with retry logic.
 func (p *Pipeline) processEventWithRetry(ctx context.Context, event cdc.Event) error {
 	return p.retryer.Execute(ctx, func(ctx context.Context) error {
@@ -232,13 +391,26 @@ func (p *Pipeline) processEventWithRetry(ctx context.Context, event cdc.Event) e
 
 func (p *Pipeline) processEvent(ctx context.Context, event cdc.Event) error {
 	now := time.Now()
+	event = p.transformer.Apply(event)
 
 	p.mu.Lock()
 	p.lastLSN = event.LSN
+	p.lastEvent = event
 	p.stats.EventsProcessed++
 	p.stats.LastEventTime = now
+	caughtUp := p.caughtUp
+	resumeLSN := p.resumeLSN
+	checkpointLSN := p.stats.LastCheckpointLSN
 	p.mu.Unlock()
 
+	p.updateCheckpointLag(event.LSN, checkpointLSN)
+
+	if !caughtUp {
+		if lsn, err := cdc.ParseLSN(event.LSN); err == nil && lsn >= resumeLSN {
+			p.markCaughtUp()
+		}
+	}
+
 	// Record CDC event metric
 	tableName := event.FullyQualifiedTable()
 	metrics.CDCEventsTotal.WithLabelValues(p.source.Name(), tableName, string(event.Operation)).Inc()
@@ -271,6 +443,8 @@ func (p *Pipeline) processEvent(ctx context.Context, event cdc.Event) error {
 		p.mu.Lock()
 		p.stats.EventsBuffered++
 		p.mu.Unlock()
+
+		metrics.CDCEventsWrittenTotal.WithLabelValues(p.source.Name()).Inc()
 	}
 
 	return nil
@@ -278,10 +452,12 @@ func (p *Pipeline) processEvent(ctx context.Context, event cdc.Event) error {
 
 func (p *Pipeline) saveCheckpoint(ctx context.Context) error {
 	p.mu.RLock()
-	lsn := p.lastLSN
+	processedLSN := p.lastLSN
+	lsn, lastEvent := p.checkpointPosition()
+	metadata := p.checkpointMetadata()
 	p.mu.RUnlock()
 
-	if lsn == "" {
+	if lsn == "" && metadata == nil {
 		return nil // Nothing to checkpoint
 	}
 
@@ -289,6 +465,7 @@ func (p *Pipeline) saveCheckpoint(ctx context.Context) error {
 		SourceID:    p.source.Name(),
 		LSN:         lsn,
 		CommittedAt: time.Now(),
+		Metadata:    metadata,
 	}
 
 	if err := p.checkpoint.Save(ctx, checkpoint); err != nil {
@@ -301,10 +478,45 @@ func (p *Pipeline) saveCheckpoint(ctx context.Context) error {
 	p.mu.Unlock()
 
 	p.logger.Debug("checkpoint saved", "lsn", lsn)
+	p.updateCheckpointLag(processedLSN, lsn)
+
+	// An idle source has nothing to replay once a checkpoint was saved
+	if p.stateMachine.IsRunning() {
+		p.markCaughtUp()
+	}
+
+	// Let the source advance its deduplication high-water-mark
+	if ack, ok := p.source.(source.Acknowledger); ok && lsn != "" && lastEvent.LSN == lsn {
+		if err := ack.Acknowledge(ctx, lastEvent); err != nil {
+			p.logger.Warn("failed to acknowledge checkpoint to source", "lsn", lsn, "error", err)
+		}
+	}
 
 	return nil
 }
 
+// checkpointPosition returns the LSN to checkpoint and the event at it. With
+// a commit tracker this is the last committed event, never moving back from
+// the last checkpoint; the event is empty while nothing newer is committed.
+// Callers must hold p.mu.
+func (p *Pipeline) checkpointPosition() (string, cdc.Event) {
+	if p.commits == nil {
+		return p.lastLSN, p.lastEvent
+	}
+
+	checkpointed := p.stats.LastCheckpointLSN
+	committed, ok := p.commits.CommittedEvent()
+	if !ok {
+		return checkpointed, cdc.Event{}
+	}
+	if last, err := cdc.ParseLSN(checkpointed); err == nil {
+		if lsn, err := cdc.ParseLSN(committed.LSN); err != nil || lsn < last {
+			return checkpointed, cdc.Event{}
+		}
+	}
+	return committed.LSN, committed
+}
+
 func (p *Pipeline) restoreCheckpoint(ctx context.Context) error {
 	checkpoint, err := p.checkpoint.Load(ctx, p.source.Name())
 	if err != nil {
@@ -318,8 +530,12 @@ func (p *Pipeline) restoreCheckpoint(ctx context.Context) error {
 
 	p.mu.Lock()
 	p.lastLSN = checkpoint.LSN
+	p.snapshotted = SnapshottedTables(checkpoint.Metadata)
 	p.stats.LastCheckpointLSN = checkpoint.LSN
 	p.stats.LastCheckpointAt = checkpoint.CommittedAt
+	if lsn, err := cdc.ParseLSN(checkpoint.LSN); err == nil {
+		p.resumeLSN = lsn
+	}
 	p.mu.Unlock()
 
 	p.logger.Info("restored checkpoint",
@@ -330,6 +546,56 @@ func (p *Pipeline) restoreCheckpoint(ctx context.Context) error {
 	return nil
 }
 
+// updateCheckpointLag sets the checkpoint lag metric to the bytes of WAL
+// between the last processed event and the last saved checkpoint.
+func (p *Pipeline) updateCheckpointLag(processedLSN, checkpointLSN string) {
+	processed, err := cdc.ParseLSN(processedLSN)
+	if err != nil {
+		return
+	}
+	checkpointed, err := cdc.ParseLSN(checkpointLSN)
+	if err != nil {
+		return
+	}
+	var lag float64
+	if processed > checkpointed {
+		lag = float64(processed - checkpointed)
+	}
+	metrics.CDCCheckpointLagBytes.WithLabelValues(p.source.Name()).Set(lag)
+}
+
+// markCaughtUp records that the pipeline has caught up with its checkpoint.
+func (p *Pipeline) markCaughtUp() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.caughtUp {
+		p.caughtUp = true
+		p.logger.Info("pipeline caught up with checkpoint", "lsn", p.lastLSN)
+	}
+}
+
+// Ready returns whether the pipeline is ready: it is running, possibly
+// paused, and has caught up with its checkpoint. If it is not ready, the
+// reason is returned.
+func (p *Pipeline) Ready() (bool, string) {
+	state := p.stateMachine.State()
+	if state != StateRunning && state != StatePaused {
+		return false, "pipeline is " + state.String()
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.caughtUp {
+		return false, "pipeline is catching up with checkpoint " + p.resumeLSN.String()
+	}
+	return true, ""
+}
+
+// ReadinessGate returns a readiness gate that is open while the pipeline is
+// ready.
+func (p *Pipeline) ReadinessGate() health.ReadinessGate {
+	return health.NewReadinessGate("pipeline", p.Ready)
+}
+
 // Stats returns the current pipeline statistics.
 func (p *Pipeline) Stats() Stats {
 	p.mu.RLock()
@@ -349,14 +615,17 @@ func (p *Pipeline) State() State {
 	return p.stateMachine.State()
 }
 
-// Pause pauses the pipeline.
+// Pause stops the pipeline consuming events until Resume, even once
+// backpressure clears. The source stays connected, so the replication slot
+// is kept, and buffered events keep draining. A pipeline paused before it
+// runs starts paused.
 func (p *Pipeline) Pause() error {
-	return p.stateMachine.Transition(StatePaused)
+	return p.stateMachine.Hold()
 }
 
-// Resume resumes the pipeline.
+// Resume resumes a paused pipeline.
 func (p *Pipeline) Resume() error {
-	return p.stateMachine.Transition(StateRunning)
+	return p.stateMachine.Release()
 }
 
 // HealthChecker returns a health checker for the pipeline.
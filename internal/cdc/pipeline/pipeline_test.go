@@ -0,0 +1,362 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/transform"
+)
+
+// streamSource streams the events sent on its channel.
+type streamSource struct {
+	events chan cdc.Event
+}
+
+func (s *streamSource) Start(_ context.Context) (<-chan cdc.Event, <-chan error) {
+	return s.events, make(chan error)
+}
+
+func (s *streamSource) Stop(_ context.Context) error { return nil }
+func (s *streamSource) LastLSN() string              { return "" }
+func (s *streamSource) Name() string                 { return "test" }
+
+// waitReady waits until the readiness of p is want.
+func waitReady(t *testing.T, p *Pipeline, want bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		ready, reason := p.Ready()
+		if ready == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Ready() = %v (%s), want %v", ready, reason, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRun_ReadyAfterCatchingUpWithCheckpoint(t *testing.T) {
+	src := &streamSource{events: make(chan cdc.Event)}
+	cfg := DefaultConfig()
+	cfg.BufferEnabled = false
+	cfg.CheckpointInterval = 0
+	p := New(src, staticCheckpoint{lsn: "0/200"}, nil, cfg, nil)
+
+	if ready, reason := p.Ready(); ready || reason != "pipeline is starting" {
+		t.Fatalf("Ready() = %v (%s) before Run, want starting", ready, reason)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	// Each send returns once the previous event was processed
+	src.events <- cdc.Event{LSN: "0/100"}
+	src.events <- cdc.Event{LSN: "0/180"}
+	if ready, reason := p.Ready(); ready || !strings.Contains(reason, "catching up") {
+		t.Errorf("Ready() = %v (%s) behind the checkpoint, want catching up", ready, reason)
+	}
+
+	src.events <- cdc.Event{LSN: "0/200"}
+	waitReady(t, p, true)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if ready, reason := p.Ready(); ready || reason != "pipeline is stopped" {
+		t.Errorf("Ready() = %v (%s) after Run, want stopped", ready, reason)
+	}
+}
+
+func TestRun_ReadyWithoutCheckpoint(t *testing.T) {
+	src := &streamSource{events: make(chan cdc.Event)}
+	p := New(src, nil, nil, Config{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	waitReady(t, p, true)
+	gate := p.ReadinessGate()
+	if ready, _ := gate.Ready(); !ready || gate.Name() != "pipeline" {
+		t.Errorf("readiness gate %q ready = %v, want true", gate.Name(), ready)
+	}
+
+	cancel()
+	<-done
+	waitReady(t, p, false)
+}
+
+// keyedBuffer is an in-memory buffer that drops events whose key it already
+// holds, as the Postgres buffer does.
+type keyedBuffer struct {
+	mu     sync.Mutex
+	events []buffer.BufferedEvent
+	done   map[int64]bool
+	keys   map[string]bool
+}
+
+func newKeyedBuffer() *keyedBuffer {
+	return &keyedBuffer{done: make(map[int64]bool), keys: make(map[string]bool)}
+}
+
+func (b *keyedBuffer) Write(_ context.Context, events []cdc.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range events {
+		if key := buffer.EventKey(e); key != "" {
+			if b.keys[key] {
+				continue
+			}
+			b.keys[key] = true
+		}
+		b.events = append(b.events, buffer.BufferedEvent{
+			ID:        int64(len(b.events) + 1),
+			Event:     e,
+			CreatedAt: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (b *keyedBuffer) ReadBatch(_ context.Context, _ string, limit int) ([]buffer.BufferedEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var batch []buffer.BufferedEvent
+	for _, e := range b.events {
+		if !b.done[e.ID] && len(batch) < limit {
+			batch = append(batch, e)
+		}
+	}
+	return batch, nil
+}
+
+func (b *keyedBuffer) MarkProcessed(_ context.Context, ids []int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range ids {
+		b.done[id] = true
+	}
+	return nil
+}
+
+func (b *keyedBuffer) Cleanup(_ context.Context, _ time.Duration) (int64, error) { return 0, nil }
+func (b *keyedBuffer) Stats(_ context.Context) (buffer.Stats, error)             { return buffer.Stats{}, nil }
+func (b *keyedBuffer) Close() error                                              { return nil }
+
+// lockedCheckpoint keeps the last saved checkpoint, safe for concurrent use.
+type lockedCheckpoint struct {
+	mu    sync.Mutex
+	saved *cdc.Checkpoint
+}
+
+func (c *lockedCheckpoint) Save(_ context.Context, cp cdc.Checkpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = &cp
+	return nil
+}
+
+func (c *lockedCheckpoint) Load(_ context.Context, _ string) (*cdc.Checkpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.saved, nil
+}
+
+func (c *lockedCheckpoint) Delete(_ context.Context, _ string) error { return nil }
+func (c *lockedCheckpoint) Close() error                             { return nil }
+
+func (c *lockedCheckpoint) lsn() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.saved == nil {
+		return ""
+	}
+	return c.saved.LSN
+}
+
+// icebergSink records the changes committed to it, failing while down.
+type icebergSink struct {
+	down atomic.Bool
+
+	mu        sync.Mutex
+	committed []string
+}
+
+func (s *icebergSink) handle(_ context.Context, events []buffer.BufferedEvent) error {
+	if s.down.Load() {
+		return errors.New("iceberg unavailable")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range events {
+		s.committed = append(s.committed, e.Event.LSN)
+	}
+	return nil
+}
+
+func (s *icebergSink) changes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.committed...)
+}
+
+// change returns the n-th change of a deduplicated stream.
+func change(n int) cdc.Event {
+	return cdc.Event{
+		Schema:    "public",
+		Table:     "users",
+		Operation: cdc.OperationInsert,
+		LSN:       fmt.Sprintf("0/%X", n*16),
+		Metadata:  map[string]any{"sequence": uint64(0)},
+	}
+}
+
+// waitFor waits until cond holds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// runWorker runs a pipeline and batch processor like the worker does, and
+// returns the source to stream from and a function that stops both abruptly.
+func runWorker(t *testing.T, buf buffer.Manager, cp *lockedCheckpoint, sink *icebergSink) (*streamSource, func()) {
+	t.Helper()
+
+	batchCfg := buffer.DefaultBatchConfig()
+	batchCfg.SourceID = "test"
+	batchCfg.FlushInterval = 5 * time.Millisecond
+	batchCfg.RetryMaxAttempts = 1000
+	batchCfg.RetryInitialInterval = time.Millisecond
+	batchCfg.RetryMaxInterval = 5 * time.Millisecond
+	processor := buffer.NewBatchProcessor(buf, sink.handle, batchCfg, nil)
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 5 * time.Millisecond
+	src := &streamSource{events: make(chan cdc.Event)}
+	p := New(src, cp, buf, cfg, nil)
+	p.SetCommitTracker(processor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := processor.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	return src, func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		_ = processor.Stop(context.Background())
+	}
+}
+
+func TestRun_ExactlyOnceAcrossCrash(t *testing.T) {
+	buf := newKeyedBuffer()
+	cp := &lockedCheckpoint{}
+	sink := &icebergSink{}
+
+	src, crash := runWorker(t, buf, cp, sink)
+	for n := 1; n <= 3; n++ {
+		src.events <- change(n)
+	}
+	waitFor(t, "checkpoint at the third change", func() bool { return cp.lsn() == change(3).LSN })
+
+	// Changes buffered while Iceberg is down are not checkpointed
+	sink.down.Store(true)
+	src.events <- change(4)
+	src.events <- change(5)
+	time.Sleep(50 * time.Millisecond)
+	crash()
+	if got := cp.lsn(); got != change(3).LSN {
+		t.Fatalf("checkpoint = %s after crash, want %s", got, change(3).LSN)
+	}
+
+	// The restarted source replays from the checkpoint
+	sink.down.Store(false)
+	src, stop := runWorker(t, buf, cp, sink)
+	for n := 3; n <= 6; n++ {
+		src.events <- change(n)
+	}
+	waitFor(t, "checkpoint at the last change", func() bool { return cp.lsn() == change(6).LSN })
+	stop()
+
+	var want []string
+	for n := 1; n <= 6; n++ {
+		want = append(want, change(n).LSN)
+	}
+	if got := sink.changes(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("committed changes = %v, want each of %v exactly once", got, want)
+	}
+}
+
+func (b *keyedBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.events)
+}
+
+func TestRun_ColumnRulesApplyBeforeBuffering(t *testing.T) {
+	buf := newKeyedBuffer()
+	src := &streamSource{events: make(chan cdc.Event)}
+	cfg := DefaultConfig()
+	cfg.CheckpointEnabled = false
+	p := New(src, nil, buf, cfg, nil)
+	p.SetTransformer(transform.New(transform.Rules{"public.users": {
+		"ssn":   {Action: transform.ActionExclude},
+		"email": {Action: transform.ActionRedact, Value: "***"},
+	}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	row := map[string]any{"id": 1, "ssn": "123-45-6789", "email": "ada@example.com"}
+	src.events <- cdc.Event{
+		Schema: "public", Table: "users", Operation: cdc.OperationUpdate, LSN: "0/10",
+		KeyColumns: []string{"id"}, Before: row, After: row,
+	}
+	waitFor(t, "the change to be buffered", func() bool { return buf.len() == 1 })
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	snapshotRow := cdc.Event{Schema: "public", Table: "users", Operation: cdc.OperationInsert, After: row}
+	if err := p.writeSnapshot(context.Background(), []cdc.Event{snapshotRow}); err != nil {
+		t.Fatalf("writeSnapshot() error = %v", err)
+	}
+
+	if len(buf.events) != 2 {
+		t.Fatalf("buffered %d events, want the change and the snapshot row", len(buf.events))
+	}
+	for _, e := range buf.events {
+		for _, data := range []map[string]any{e.Event.Before, e.Event.After} {
+			if _, ok := data["ssn"]; ok {
+				t.Errorf("buffered row %v contains the excluded column", data)
+			}
+			if data != nil && data["email"] != "***" {
+				t.Errorf("buffered row %v, want email redacted", data)
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+// snapshotFlushInterval is how often the buffer is polled while waiting for
+// snapshot rows to be committed.
+var snapshotFlushInterval = time.Second
+
+// checkpointSnapshotTables is the checkpoint metadata key holding the tables
+// whose snapshot completed.
+const checkpointSnapshotTables = "snapshot_tables"
+
+// SetSnapshotter sets the snapshot that backfills existing rows of tables
+// that have not been snapshotted yet.
+func (p *Pipeline) SetSnapshotter(s *snapshot.Snapshotter) {
+	p.snapshotter = s
+	s.SetOnTableComplete(p.recordSnapshotted)
+}
+
+// Rerun copies a table's key ranges again, or the whole table for nil
+// ranges, so the verifier can repair a table that failed verification.
+func (p *Pipeline) Rerun(ctx context.Context, table verify.Table, ranges []verify.KeyRange) error {
+	if p.snapshotter == nil {
+		return errors.New("no snapshot is configured to re-run the backfill")
+	}
+	return p.snapshotter.Backfill(ctx, table, ranges, p.writeSnapshot)
+}
+
+// prepareSnapshot runs a snapshot-first backfill before streaming starts. It
+// reports whether a lazy snapshot must be started once the source is running.
+func (p *Pipeline) prepareSnapshot(ctx context.Context) (bool, error) {
+	if p.snapshotter == nil {
+		return false, nil
+	}
+
+	p.mu.RLock()
+	resumed := p.lastLSN != ""
+	backfill := p.backfill
+	completed := slices.Sorted(maps.Keys(p.snapshotted))
+	p.mu.RUnlock()
+
+	switch {
+	case backfill:
+		p.logger.Warn("snapshotting every table to backfill a replication gap")
+	case p.snapshotter.Trigger() == snapshot.TriggerNever:
+		return false, nil
+	case p.snapshotter.Trigger() == snapshot.TriggerInitial && resumed:
+		p.logger.Debug("resuming from checkpoint, skipping initial snapshot")
+		return false, nil
+	}
+	p.snapshotter.MarkCompleted(completed...)
+	// Verification imports the exported snapshot, so its transaction must
+	// outlive the copy
+	p.snapshotter.SetHoldSnapshot(p.verifier != nil && p.verifier.Enabled())
+
+	if err := p.snapshotter.Prepare(ctx); err != nil {
+		return false, fmt.Errorf("prepare snapshot: %w", err)
+	}
+
+	if p.snapshotter.Order() == snapshot.OrderStreamFirst {
+		return true, nil
+	}
+	return false, p.runSnapshot(ctx)
+}
+
+// runSnapshot copies the configured tables and verifies them.
+func (p *Pipeline) runSnapshot(ctx context.Context) error {
+	defer p.snapshotter.Release()
+
+	tables, err := p.snapshotter.Run(ctx, p.writeSnapshot)
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	p.mu.Lock()
+	p.backfill = false
+	p.mu.Unlock()
+	p.OnSnapshotComplete(ctx, tables)
+	return nil
+}
+
+// runLazySnapshot runs a stream-first snapshot in the background. A failed
+// snapshot leaves streaming running; the tables must be backfilled again.
+func (p *Pipeline) runLazySnapshot(ctx context.Context) {
+	if err := p.runSnapshot(ctx); err != nil && ctx.Err() == nil {
+		p.mu.Lock()
+		p.stats.Errors++
+		p.mu.Unlock()
+		p.logger.Error("lazy snapshot failed; streamed changes are unaffected but existing rows were not fully backfilled", "error", err)
+	}
+}
+
+// recordSnapshotted records a table whose rows have all been buffered and
+// saves a checkpoint, so a restart does not copy the table again.
+func (p *Pipeline) recordSnapshotted(table verify.Table) {
+	p.mu.Lock()
+	if p.snapshotted == nil {
+		p.snapshotted = make(map[string]string)
+	}
+	p.snapshotted[table.String()] = table.SnapshotLSN
+	p.mu.Unlock()
+
+	if !p.config.CheckpointEnabled || p.checkpoint == nil {
+		return
+	}
+	if err := p.saveCheckpoint(context.Background()); err != nil {
+		p.logger.Warn("failed to record snapshot completion", "table", table.String(), "error", err)
+	}
+}
+
+// checkpointMetadata returns the metadata saved with a checkpoint, or nil if
+// there is none. The caller must hold p.mu.
+func (p *Pipeline) checkpointMetadata() map[string]any {
+	if len(p.snapshotted) == 0 {
+		return nil
+	}
+	tables := make(map[string]any, len(p.snapshotted))
+	for table, lsn := range p.snapshotted {
+		tables[table] = lsn
+	}
+	return map[string]any{checkpointSnapshotTables: tables}
+}
+
+// SnapshottedTables reads the tables whose snapshot completed, and the LSN
+// each was consistent with, from checkpoint metadata.
+func SnapshottedTables(metadata map[string]any) map[string]string {
+	raw, ok := metadata[checkpointSnapshotTables].(map[string]any)
+	if !ok {
+		return nil
+	}
+	tables := make(map[string]string, len(raw))
+	for table, lsn := range raw {
+		tables[table], _ = lsn.(string)
+	}
+	return tables
+}
+
+// waitForSnapshotFlush blocks until the batch processor has committed the
+// buffered snapshot rows of tables, so verification does not count a table
+// that is still being written.
+func (p *Pipeline) waitForSnapshotFlush(ctx context.Context, tables []verify.Table) error {
+	if !p.config.BufferEnabled || p.buffer == nil {
+		return nil
+	}
+	pending, ok := p.buffer.(buffer.SnapshotPendingCounter)
+	if !ok {
+		p.logger.Warn("buffer cannot report pending snapshot rows, verifying without waiting for the flush")
+		return nil
+	}
+
+	ticker := time.NewTicker(snapshotFlushInterval)
+	defer ticker.Stop()
+
+	for _, t := range tables {
+		for {
+			n, err := pending.PendingSnapshotRows(ctx, t.Schema, t.Name)
+			if err != nil {
+				return fmt.Errorf("count pending snapshot rows of %s: %w", t, err)
+			}
+			if n == 0 {
+				break
+			}
+			p.logger.Debug("waiting for snapshot rows to be flushed", "table", t.String(), "pending", n)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+	return nil
+}
+
+// writeSnapshot writes snapshot rows to the buffer. Snapshot rows bypass
+// checkpointing: they do not advance the replication position.
+func (p *Pipeline) writeSnapshot(ctx context.Context, events []cdc.Event) error {
+	if !p.config.BufferEnabled || p.buffer == nil {
+		return nil
+	}
+
+	if p.transformer != nil {
+		transformed := make([]cdc.Event, len(events))
+		for i, e := range events {
+			transformed[i] = p.transformer.Apply(e)
+		}
+		events = transformed
+	}
+
+	if err := p.buffer.Write(ctx, events); err != nil {
+		return fmt.Errorf("buffer write: %w", err)
+	}
+
+	p.mu.Lock()
+	p.stats.EventsBuffered += int64(len(events))
+	p.mu.Unlock()
+	return nil
+}
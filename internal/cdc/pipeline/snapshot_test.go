@@ -0,0 +1,364 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
+	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+// recordingBuffer records written events and whether the source was running.
+type recordingBuffer struct {
+	src           *slotSource
+	events        []cdc.Event
+	beforeStreams int
+}
+
+func (b *recordingBuffer) Write(_ context.Context, events []cdc.Event) error {
+	if !b.src.started {
+		b.beforeStreams += len(events)
+	}
+	b.events = append(b.events, events...)
+	return nil
+}
+
+func (b *recordingBuffer) ReadBatch(_ context.Context, _ string, _ int) ([]buffer.BufferedEvent, error) {
+	return nil, nil
+}
+func (b *recordingBuffer) MarkProcessed(_ context.Context, _ []int64) error { return nil }
+func (b *recordingBuffer) Cleanup(_ context.Context, _ time.Duration) (int64, error) {
+	return 0, nil
+}
+func (b *recordingBuffer) Stats(_ context.Context) (buffer.Stats, error) { return buffer.Stats{}, nil }
+func (b *recordingBuffer) Close() error                                  { return nil }
+
+// tableReader serves a single three-row table.
+type tableReader struct{}
+
+func (tableReader) KeyColumn(_ context.Context, _, _ string) (string, error) { return "id", nil }
+func (tableReader) Begin(_ context.Context) (snapshot.Tx, error)             { return tableTx{}, nil }
+
+type tableTx struct{}
+
+func (tableTx) Position(_ context.Context) (string, string, error) { return "snap-1", "0/100", nil }
+func (tableTx) Close() error                                       { return nil }
+
+func (tableTx) ReadChunk(_ context.Context, _, _, _ string, after any, _ int) ([]map[string]any, error) {
+	if after != nil {
+		return nil, nil
+	}
+	return []map[string]any{{"id": int64(1)}, {"id": int64(2)}, {"id": int64(3)}}, nil
+}
+
+func runWithSnapshot(t *testing.T, checkpointLSN string) *recordingBuffer {
+	t.Helper()
+
+	src := &slotSource{}
+	buf := &recordingBuffer{src: src}
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 0
+	p := New(src, staticCheckpoint{lsn: checkpointLSN}, buf, cfg, nil)
+
+	snapCfg := snapshot.DefaultConfig()
+	snapCfg.Tables = []string{"public.users"}
+	s, err := snapshot.New(snapCfg, tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return buf
+}
+
+func TestRun_SnapshotFirstBackfillsBeforeStreaming(t *testing.T) {
+	buf := runWithSnapshot(t, "")
+
+	if len(buf.events) != 3 {
+		t.Fatalf("buffered %d events, want 3 snapshot rows", len(buf.events))
+	}
+	if buf.beforeStreams != 3 {
+		t.Errorf("%d snapshot rows written before streaming, want 3", buf.beforeStreams)
+	}
+	for _, e := range buf.events {
+		if e.Metadata[snapshot.MetadataSnapshot] != true || e.LSN != "0/100" {
+			t.Errorf("event = %+v, want a snapshot row at the exported LSN", e)
+		}
+	}
+}
+
+func TestRun_SnapshotSkippedWhenResuming(t *testing.T) {
+	buf := runWithSnapshot(t, "0/200")
+
+	if len(buf.events) != 0 {
+		t.Errorf("buffered %d events, want no snapshot when resuming from a checkpoint", len(buf.events))
+	}
+}
+
+// memoryCheckpoint keeps the last saved checkpoint.
+type memoryCheckpoint struct {
+	saved *cdc.Checkpoint
+}
+
+func (c *memoryCheckpoint) Save(_ context.Context, cp cdc.Checkpoint) error {
+	c.saved = &cp
+	return nil
+}
+
+func (c *memoryCheckpoint) Load(_ context.Context, _ string) (*cdc.Checkpoint, error) {
+	return c.saved, nil
+}
+
+func (c *memoryCheckpoint) Delete(_ context.Context, _ string) error { return nil }
+func (c *memoryCheckpoint) Close() error                             { return nil }
+
+func TestRun_WhenNeededSnapshotsNewTables(t *testing.T) {
+	src := &slotSource{}
+	buf := &recordingBuffer{src: src}
+	cp := &memoryCheckpoint{saved: &cdc.Checkpoint{
+		SourceID: "test",
+		LSN:      "0/200",
+		Metadata: map[string]any{checkpointSnapshotTables: map[string]any{"public.users": "0/100"}},
+	}}
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 0
+	p := New(src, cp, buf, cfg, nil)
+
+	snapCfg := snapshot.DefaultConfig()
+	snapCfg.Trigger = snapshot.TriggerWhenNeeded
+	snapCfg.Tables = []string{"public.users", "public.orders"}
+	s, err := snapshot.New(snapCfg, tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Only the table added since the last run is copied
+	if len(buf.events) != 3 {
+		t.Fatalf("buffered %d events, want 3 rows of the new table", len(buf.events))
+	}
+	for _, e := range buf.events {
+		if e.Table != "orders" {
+			t.Errorf("snapshotted %s, want only orders", e.FullyQualifiedTable())
+		}
+	}
+
+	got := SnapshottedTables(cp.saved.Metadata)
+	if len(got) != 2 || got["public.orders"] != "0/100" || got["public.users"] != "0/100" {
+		t.Errorf("recorded snapshot tables = %v, want users and orders", got)
+	}
+	if cp.saved.LSN != "0/200" {
+		t.Errorf("checkpoint LSN = %q, want the streaming position kept", cp.saved.LSN)
+	}
+}
+
+func TestRun_InitialSnapshotResumesIncompleteTables(t *testing.T) {
+	src := &slotSource{}
+	buf := &recordingBuffer{src: src}
+	// A restart during the first snapshot: no streaming position yet
+	cp := &memoryCheckpoint{saved: &cdc.Checkpoint{
+		SourceID: "test",
+		Metadata: map[string]any{checkpointSnapshotTables: map[string]any{"public.users": "0/100"}},
+	}}
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 0
+	p := New(src, cp, buf, cfg, nil)
+
+	snapCfg := snapshot.DefaultConfig()
+	snapCfg.Tables = []string{"public.users", "public.orders"}
+	s, err := snapshot.New(snapCfg, tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(buf.events) != 3 || buf.events[0].Table != "orders" {
+		t.Errorf("buffered %d events, want the 3 rows of the unfinished table", len(buf.events))
+	}
+}
+
+func TestRun_SnapshotPolicyBackfillsGapWithSnapshotter(t *testing.T) {
+	// The slot was recreated past the checkpoint and snapshots are otherwise off
+	src := &slotSource{pos: &source.SlotPosition{Exists: true, RestartLSN: "0/500", ConfirmedFlushLSN: "0/500"}}
+	buf := &recordingBuffer{src: src}
+	cp := &memoryCheckpoint{saved: &cdc.Checkpoint{
+		SourceID: "test",
+		LSN:      "0/100",
+		Metadata: map[string]any{checkpointSnapshotTables: map[string]any{"public.users": "0/50"}},
+	}}
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 0
+	cfg.GapPolicy = GapPolicySnapshot
+	p := New(src, cp, buf, cfg, nil)
+
+	snapCfg := snapshot.DefaultConfig()
+	snapCfg.Trigger = snapshot.TriggerNever
+	snapCfg.Tables = []string{"public.users"}
+	s, err := snapshot.New(snapCfg, tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !src.started {
+		t.Error("expected source to start after the backfill")
+	}
+	if len(buf.events) != 3 || buf.beforeStreams != 3 {
+		t.Errorf("buffered %d events (%d before streaming), want the 3 rows backfilled before streaming", len(buf.events), buf.beforeStreams)
+	}
+	if got := SnapshottedTables(cp.saved.Metadata); got["public.users"] != "0/100" {
+		t.Errorf("recorded snapshot tables = %v, want users at the backfill LSN", got)
+	}
+}
+
+// flushingBuffer reports the buffered snapshot rows as pending until the
+// batch processor has been polled flushAfter times.
+type flushingBuffer struct {
+	recordingBuffer
+	flushAfter int
+	polls      int
+}
+
+func (b *flushingBuffer) PendingSnapshotRows(_ context.Context, _, _ string) (int64, error) {
+	b.polls++
+	if b.polls > b.flushAfter {
+		return 0, nil
+	}
+	return int64(len(b.events)), nil
+}
+
+// flushCounter counts the rows flushed so far.
+type flushCounter struct {
+	buf *flushingBuffer
+}
+
+func (c flushCounter) CountRows(_ context.Context, _ verify.Table) (int64, error) {
+	if c.buf.polls <= c.buf.flushAfter {
+		return 0, nil
+	}
+	return int64(len(c.buf.events)), nil
+}
+
+func TestRun_VerifiesSnapshotAfterRowsAreFlushed(t *testing.T) {
+	interval := snapshotFlushInterval
+	snapshotFlushInterval = time.Millisecond
+	defer func() { snapshotFlushInterval = interval }()
+
+	src := &slotSource{}
+	buf := &flushingBuffer{recordingBuffer: recordingBuffer{src: src}, flushAfter: 3}
+
+	cfg := DefaultConfig()
+	cfg.CheckpointInterval = 0
+	p := New(src, staticCheckpoint{}, buf, cfg, nil)
+
+	snapCfg := snapshot.DefaultConfig()
+	snapCfg.Tables = []string{"public.users"}
+	s, err := snapshot.New(snapCfg, tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+	p.SetVerifier(verify.New(verify.Config{Mode: verify.ModeCount}, "test", tableCounter{}, flushCounter{buf: buf}, nil))
+
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if buf.polls != buf.flushAfter+1 {
+		t.Errorf("polled pending rows %d times, want %d", buf.polls, buf.flushAfter+1)
+	}
+	if failures := p.Stats().VerificationFailures; failures != 0 {
+		t.Errorf("verification failures = %d, want 0 once the rows are flushed", failures)
+	}
+}
+
+// tableCounter counts the rows tableReader serves.
+type tableCounter struct{}
+
+func (tableCounter) CountRows(_ context.Context, _ verify.Table) (int64, error) { return 3, nil }
+
+func TestRerun_BackfillsTableAgain(t *testing.T) {
+	src := &slotSource{}
+	buf := &recordingBuffer{src: src}
+	p := New(src, staticCheckpoint{}, buf, DefaultConfig(), nil)
+
+	table := verify.Table{Schema: "public", Name: "users", KeyColumn: "id"}
+	if err := p.Rerun(context.Background(), table, nil); err == nil {
+		t.Error("Rerun() without a snapshot: expected an error")
+	}
+
+	s, err := snapshot.New(snapshot.DefaultConfig(), tableReader{}, nil)
+	if err != nil {
+		t.Fatalf("snapshot.New() error = %v", err)
+	}
+	p.SetSnapshotter(s)
+
+	if err := p.Rerun(context.Background(), table, []verify.KeyRange{{Through: int64(2)}}); err != nil {
+		t.Fatalf("Rerun() error = %v", err)
+	}
+	if len(buf.events) != 2 {
+		t.Fatalf("buffered %d events, want the 2 rows in the range", len(buf.events))
+	}
+	for _, e := range buf.events {
+		if e.Metadata[snapshot.MetadataSnapshot] != true || e.Table != "users" {
+			t.Errorf("event = %+v, want a snapshot row of users", e)
+		}
+	}
+}
+
+// staleCounter counts fewer rows than tableReader serves.
+type staleCounter struct{}
+
+func (staleCounter) CountRows(_ context.Context, _ verify.Table) (int64, error) { return 1, nil }
+
+func TestOnSnapshotComplete_EmitsDiscrepancyEvent(t *testing.T) {
+	src := &slotSource{}
+	p := New(src, staticCheckpoint{}, &recordingBuffer{src: src}, DefaultConfig(), nil)
+	p.SetVerifier(verify.New(verify.Config{Mode: verify.ModeCount}, "test", tableCounter{}, staleCounter{}, nil))
+
+	var events []Event
+	p.AddEventListener(func(e Event) { events = append(events, e) })
+
+	table := verify.Table{Schema: "public", Name: "users", KeyColumn: "id"}
+	results := p.OnSnapshotComplete(context.Background(), []verify.Table{table})
+	if len(results) != 1 || results[0].Passed {
+		t.Fatalf("results = %+v, want a failed verification", results)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("emitted %d events, want 1", len(events))
+	}
+	e := events[0]
+	if e.Type != EventVerificationDiscrepancy || e.Table != "public.users" || e.Source != src.Name() {
+		t.Errorf("event = %+v, want a discrepancy of public.users", e)
+	}
+	if e.SourceRows != 3 || e.IcebergRows != 1 || e.Discrepancies != 2 {
+		t.Errorf("event counts = %d source, %d iceberg, %d discrepancies, want 3, 1, 2", e.SourceRows, e.IcebergRows, e.Discrepancies)
+	}
+	if e.Time.IsZero() {
+		t.Error("event has no time")
+	}
+	if failures := p.Stats().VerificationFailures; failures != 1 {
+		t.Errorf("verification failures = %d, want 1", failures)
+	}
+}
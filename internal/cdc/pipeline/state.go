@@ -59,6 +59,9 @@ type StateMachine struct {
 	mu        sync.RWMutex
 	state     State
 	listeners []StateChangeListener
+
+	// held keeps the state machine paused until it is released.
+	held bool
 }
 
 // StateChangeListener is called when state changes.
@@ -82,29 +85,74 @@ func (sm *StateMachine) State() State {
 // Returns an error if the transition is not valid.
 func (sm *StateMachine) Transition(target State) error {
 	sm.mu.Lock()
+	return sm.transitionAndNotify(target)
+}
 
-	if !sm.canTransition(target) {
+// Hold pauses a running state machine and keeps it paused until Release:
+// transitions from paused back to running are refused while it is held. A
+// state machine that is not running is only marked held.
+func (sm *StateMachine) Hold() error {
+	sm.mu.Lock()
+	sm.held = true
+	if sm.state != StateRunning {
 		sm.mu.Unlock()
-		return fmt.Errorf("invalid state transition from %s to %s", sm.state, target)
+		return nil
 	}
+	return sm.transitionAndNotify(StatePaused)
+}
 
-	from := sm.state
-	sm.state = target
+// Release ends a hold and resumes the state machine if it is paused.
+func (sm *StateMachine) Release() error {
+	sm.mu.Lock()
+	sm.held = false
+	if sm.state != StatePaused {
+		sm.mu.Unlock()
+		return nil
+	}
+	return sm.transitionAndNotify(StateRunning)
+}
 
-	// Notify listeners (copy to avoid holding lock)
-	listeners := make([]StateChangeListener, len(sm.listeners))
-	copy(listeners, sm.listeners)
+// IsHeld returns true if the state machine is held paused.
+func (sm *StateMachine) IsHeld() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.held
+}
 
-	// Release lock before calling listeners
+// transitionAndNotify transitions to target and notifies the listeners.
+// Must be called with lock held; it releases the lock before calling the
+// listeners.
+func (sm *StateMachine) transitionAndNotify(target State) error {
+	from, listeners, err := sm.transitionLocked(target)
 	sm.mu.Unlock()
-
+	if err != nil {
+		return err
+	}
 	for _, listener := range listeners {
 		listener(from, target)
 	}
-
 	return nil
 }
 
+// transitionLocked transitions to target and returns the previous state and
+// the listeners to notify. Must be called with lock held.
+func (sm *StateMachine) transitionLocked(target State) (State, []StateChangeListener, error) {
+	if !sm.canTransition(target) {
+		return sm.state, nil, fmt.Errorf("invalid state transition from %s to %s", sm.state, target)
+	}
+	if sm.held && sm.state == StatePaused && target == StateRunning {
+		return sm.state, nil, fmt.Errorf("pipeline is held paused")
+	}
+
+	from := sm.state
+	sm.state = target
+
+	// Notify listeners (copy to avoid holding lock)
+	listeners := make([]StateChangeListener, len(sm.listeners))
+	copy(listeners, sm.listeners)
+	return from, listeners, nil
+}
+
 // canTransition checks if a transition to target is valid.
 // Must be called with lock held.
 func (sm *StateMachine) canTransition(target State) bool {
@@ -193,3 +193,33 @@ func TestStateMachine_IsTerminal(t *testing.T) {
 		})
 	}
 }
+
+func TestStateMachine_Hold(t *testing.T) {
+	sm := NewStateMachine()
+
+	// Held before running, it is only marked
+	if err := sm.Hold(); err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+	if !sm.IsHeld() || sm.State() != StateStarting {
+		t.Fatalf("held = %v in %v, want held while starting", sm.IsHeld(), sm.State())
+	}
+	_ = sm.Transition(StateRunning)
+	if err := sm.Hold(); err != nil || sm.State() != StatePaused {
+		t.Fatalf("Hold() = %v in %v, want paused", err, sm.State())
+	}
+
+	if err := sm.Transition(StateRunning); err == nil {
+		t.Error("Transition(StateRunning) succeeded while held")
+	}
+	if err := sm.Transition(StateStopping); err != nil {
+		t.Errorf("Transition(StateStopping) error = %v, want stopping allowed while held", err)
+	}
+
+	sm = NewStateMachine()
+	_ = sm.Transition(StateRunning)
+	_ = sm.Hold()
+	if err := sm.Release(); err != nil || sm.IsHeld() || sm.State() != StateRunning {
+		t.Errorf("Release() = %v, held = %v in %v, want running", err, sm.IsHeld(), sm.State())
+	}
+}
@@ -0,0 +1,33 @@
+package shadow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+)
+
+// PostgresSlotDropper drops replication slots on a PostgreSQL source.
+type PostgresSlotDropper struct {
+	db *sql.DB
+}
+
+// NewPostgresSlotDropper creates a new PostgresSlotDropper.
+func NewPostgresSlotDropper(db *sql.DB) *PostgresSlotDropper {
+	return &PostgresSlotDropper{db: db}
+}
+
+// DropSlot drops a replication slot. Dropping a slot that does not exist is
+// not an error.
+func (d *PostgresSlotDropper) DropSlot(ctx context.Context, name string) error {
+	query := `
+		SELECT pg_drop_replication_slot(slot_name)
+		FROM pg_replication_slots
+		WHERE slot_name = $1
+	`
+	if _, err := d.db.ExecContext(ctx, query, name); err != nil {
+		return fmt.Errorf("drop replication slot %s: %w", name, err)
+	}
+	return nil
+}
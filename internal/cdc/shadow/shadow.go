@@ -0,0 +1,307 @@
+// Package shadow runs a pipeline alongside production so a config change
+// (new transforms, upsert mode, column mapping) can be tried on live changes
+// without touching production tables.
+//
+// A shadow pipeline reads the same source through its own replication slot,
+// so the extra load on the source is one more slot decoding the WAL plus a
+// snapshot of its tables. It writes to prefixed namespaces
+// ("shadow_public.orders"). Once its output has been compared with
+// production, the shadow is either promoted, replacing the production tables,
+// or discarded. Only a shadow that snapshotted every table and matches
+// production can be promoted without forcing it.
+package shadow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+// Action is what a worker started in shadow mode does.
+type Action string
+
+const (
+	// ActionRun runs the shadow pipeline.
+	ActionRun Action = "run"
+
+	// ActionPromote replaces the production tables with the shadow tables.
+	ActionPromote Action = "promote"
+
+	// ActionDiscard drops the shadow tables and replication slot.
+	ActionDiscard Action = "discard"
+)
+
+// ParseAction parses a shadow action; an empty string means run.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case "":
+		return ActionRun, nil
+	case ActionRun, ActionPromote, ActionDiscard:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid shadow action %q: must be run, promote or discard", s)
+}
+
+// Config holds shadow pipeline configuration.
+type Config struct {
+	// Name distinguishes the shadow's slot, source and backup tables.
+	Name string
+
+	// NamespacePrefix is prepended to the namespaces the shadow writes to.
+	NamespacePrefix string
+
+	// MaxDuration time-boxes the shadow run (0 = until stopped).
+	MaxDuration time.Duration
+
+	// DiscardOnExpiry discards the shadow when MaxDuration elapses.
+	DiscardOnExpiry bool
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Name:            "shadow",
+		NamespacePrefix: "shadow_",
+		MaxDuration:     24 * time.Hour,
+	}
+}
+
+// Validate checks the configuration.
+func (c Config) Validate() error {
+	if c.Name == "" || strings.ContainsAny(c.Name, " .-") {
+		return fmt.Errorf("shadow name %q must be a non-empty identifier", c.Name)
+	}
+	if c.NamespacePrefix == "" {
+		return fmt.Errorf("shadow namespace prefix is required")
+	}
+	if c.MaxDuration < 0 {
+		return fmt.Errorf("shadow max duration must not be negative")
+	}
+	return nil
+}
+
+// SlotName returns the shadow's replication slot for a production slot.
+func (c Config) SlotName(productionSlot string) string {
+	return productionSlot + "_" + c.Name
+}
+
+// SourceName returns the shadow's source name, which keys its checkpoints.
+func (c Config) SourceName(productionSource string) string {
+	return productionSource + "-" + c.Name
+}
+
+// Namespace returns the shadow namespace for a production namespace.
+func (c Config) Namespace(namespace string) string {
+	return c.NamespacePrefix + namespace
+}
+
+// backupName returns the name a promoted production table is kept under.
+func (c Config) backupName(table string) string {
+	return table + "_pre_" + c.Name
+}
+
+// SlotDropper drops replication slots.
+type SlotDropper interface {
+	DropSlot(ctx context.Context, name string) error
+}
+
+// TableAdmin manages Iceberg tables.
+type TableAdmin interface {
+	TableExists(ctx context.Context, namespace, table string) (bool, error)
+	RenameTable(ctx context.Context, fromNamespace, fromTable, toNamespace, toTable string) error
+	DropTable(ctx context.Context, namespace, table string) error
+}
+
+// Controller promotes or discards a shadow pipeline.
+type Controller struct {
+	config Config
+	slots  SlotDropper
+	tables TableAdmin
+	logger *slog.Logger
+}
+
+// NewController creates a new Controller.
+func NewController(cfg Config, slots SlotDropper, tables TableAdmin, logger *slog.Logger) *Controller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Controller{
+		config: cfg,
+		slots:  slots,
+		tables: tables,
+		logger: logger.With("component", "shadow"),
+	}
+}
+
+// Discard drops the shadow's replication slot and its tables. tables are the
+// qualified production table names (schema.table).
+func (c *Controller) Discard(ctx context.Context, productionSlot string, tables []string) error {
+	var errs []error
+	if err := c.slots.DropSlot(ctx, c.config.SlotName(productionSlot)); err != nil {
+		errs = append(errs, fmt.Errorf("drop slot: %w", err))
+	}
+	for _, name := range tables {
+		namespace, table := splitTable(name)
+		if err := c.tables.DropTable(ctx, c.config.Namespace(namespace), table); err != nil {
+			errs = append(errs, fmt.Errorf("drop shadow table %s: %w", name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	c.logger.Info("shadow discarded", "slot", c.config.SlotName(productionSlot), "tables", len(tables))
+	return nil
+}
+
+// ErrNotSnapshotted is returned when promoting a shadow table that was never
+// snapshotted, so it only holds the changes streamed since the shadow started.
+var ErrNotSnapshotted = errors.New("shadow table was not snapshotted")
+
+// ErrMismatch is returned when promoting a shadow whose output does not match
+// production.
+var ErrMismatch = errors.New("shadow does not match production")
+
+// PromoteOptions holds what a promotion is checked against.
+type PromoteOptions struct {
+	// Snapshotted are the qualified tables the shadow copied in full before
+	// streaming, as recorded in its checkpoint.
+	Snapshotted []string
+
+	// Comparisons are the results of Compare for the promoted tables.
+	Comparisons []Comparison
+
+	// Force promotes even if a comparison does not match.
+	Force bool
+}
+
+// Promote replaces each production table with its shadow table and drops the
+// shadow's replication slot. Every table must have been snapshotted by the
+// shadow and, unless opts.Force is set, match production. The replaced
+// production table is kept as <table>_pre_<name> so a promotion can be undone
+// by hand. Production writers must be stopped, and restarted with the
+// shadow's config, around a promotion.
+func (c *Controller) Promote(ctx context.Context, productionSlot string, tables []string, opts PromoteOptions) error {
+	snapshotted := make(map[string]bool, len(opts.Snapshotted))
+	for _, name := range opts.Snapshotted {
+		namespace, table := splitTable(name)
+		snapshotted[namespace+"."+table] = true
+	}
+	compared := make(map[string]Comparison, len(opts.Comparisons))
+	for _, cmp := range opts.Comparisons {
+		compared[cmp.Table] = cmp
+	}
+
+	// Check everything up front so a promotion does not stop half way
+	for _, name := range tables {
+		namespace, table := splitTable(name)
+		qualified := namespace + "." + table
+		if !snapshotted[qualified] {
+			return fmt.Errorf("%w: %s", ErrNotSnapshotted, qualified)
+		}
+		cmp, ok := compared[qualified]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: %s was not compared", ErrMismatch, qualified)
+		case !cmp.Matches() && !opts.Force:
+			return fmt.Errorf("%w: %s has %d production rows and %d shadow rows",
+				ErrMismatch, qualified, cmp.ProductionRows, cmp.ShadowRows)
+		case !cmp.Matches():
+			c.logger.Warn("forcing promotion of mismatched shadow table",
+				"table", qualified,
+				"production_rows", cmp.ProductionRows,
+				"shadow_rows", cmp.ShadowRows,
+			)
+		}
+
+		exists, err := c.tables.TableExists(ctx, c.config.Namespace(namespace), table)
+		if err != nil {
+			return fmt.Errorf("check shadow table %s: %w", name, err)
+		}
+		if !exists {
+			return fmt.Errorf("shadow table %s.%s does not exist", c.config.Namespace(namespace), table)
+		}
+		backup, err := c.tables.TableExists(ctx, namespace, c.config.backupName(table))
+		if err != nil {
+			return fmt.Errorf("check backup table for %s: %w", name, err)
+		}
+		if backup {
+			return fmt.Errorf("backup table %s.%s already exists", namespace, c.config.backupName(table))
+		}
+	}
+
+	for _, name := range tables {
+		namespace, table := splitTable(name)
+
+		exists, err := c.tables.TableExists(ctx, namespace, table)
+		if err != nil {
+			return fmt.Errorf("check production table %s: %w", name, err)
+		}
+		if exists {
+			if err := c.tables.RenameTable(ctx, namespace, table, namespace, c.config.backupName(table)); err != nil {
+				return fmt.Errorf("back up production table %s: %w", name, err)
+			}
+		}
+		if err := c.tables.RenameTable(ctx, c.config.Namespace(namespace), table, namespace, table); err != nil {
+			return fmt.Errorf("promote shadow table %s: %w", name, err)
+		}
+		c.logger.Info("shadow table promoted", "table", name, "backup", namespace+"."+c.config.backupName(table))
+	}
+
+	if err := c.slots.DropSlot(ctx, c.config.SlotName(productionSlot)); err != nil {
+		return fmt.Errorf("drop slot: %w", err)
+	}
+	return nil
+}
+
+// Comparison compares a production table with its shadow.
+type Comparison struct {
+	// Table is the qualified production table name.
+	Table string
+
+	// ProductionRows and ShadowRows are the row counts of each side.
+	ProductionRows int64
+	ShadowRows     int64
+}
+
+// Matches reports whether both sides have the same number of rows.
+func (c Comparison) Matches() bool {
+	return c.ProductionRows == c.ShadowRows
+}
+
+// Compare counts the rows of each production table and its shadow.
+func Compare(ctx context.Context, cfg Config, counter verify.Counter, tables []string) ([]Comparison, error) {
+	comparisons := make([]Comparison, 0, len(tables))
+	for _, name := range tables {
+		namespace, table := splitTable(name)
+
+		production, err := counter.CountRows(ctx, verify.Table{Schema: namespace, Name: table})
+		if err != nil {
+			return nil, fmt.Errorf("count production table %s: %w", name, err)
+		}
+		shadow, err := counter.CountRows(ctx, verify.Table{Schema: cfg.Namespace(namespace), Name: table})
+		if err != nil {
+			return nil, fmt.Errorf("count shadow table %s: %w", name, err)
+		}
+
+		comparisons = append(comparisons, Comparison{
+			Table:          namespace + "." + table,
+			ProductionRows: production,
+			ShadowRows:     shadow,
+		})
+	}
+	return comparisons, nil
+}
+
+// splitTable splits a qualified table name, defaulting to the public schema.
+func splitTable(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return "public", name
+}
@@ -0,0 +1,310 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/pipeline"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeSlots records dropped slots.
+type fakeSlots struct {
+	dropped []string
+}
+
+func (s *fakeSlots) DropSlot(_ context.Context, name string) error {
+	s.dropped = append(s.dropped, name)
+	return nil
+}
+
+// fakeTables keeps a set of namespace.table names in memory.
+type fakeTables struct {
+	tables map[string]bool
+}
+
+func newFakeTables(names ...string) *fakeTables {
+	t := &fakeTables{tables: make(map[string]bool)}
+	for _, name := range names {
+		t.tables[name] = true
+	}
+	return t
+}
+
+func (t *fakeTables) TableExists(_ context.Context, namespace, table string) (bool, error) {
+	return t.tables[namespace+"."+table], nil
+}
+
+func (t *fakeTables) RenameTable(_ context.Context, fromNamespace, fromTable, toNamespace, toTable string) error {
+	from, to := fromNamespace+"."+fromTable, toNamespace+"."+toTable
+	if !t.tables[from] {
+		return errors.New("no such table " + from)
+	}
+	if t.tables[to] {
+		return errors.New("table exists " + to)
+	}
+	delete(t.tables, from)
+	t.tables[to] = true
+	return nil
+}
+
+func (t *fakeTables) DropTable(_ context.Context, namespace, table string) error {
+	delete(t.tables, namespace+"."+table)
+	return nil
+}
+
+func TestConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.SlotName("philotes_cdc"); got != "philotes_cdc_shadow" {
+		t.Errorf("SlotName() = %q", got)
+	}
+	if got := cfg.SourceName("postgres-app"); got != "postgres-app-shadow" {
+		t.Errorf("SourceName() = %q", got)
+	}
+	if got := cfg.Namespace("public"); got != "shadow_public" {
+		t.Errorf("Namespace() = %q", got)
+	}
+
+	for _, modify := range []func(*Config){
+		func(c *Config) { c.Name = "" },
+		func(c *Config) { c.Name = "my-shadow" },
+		func(c *Config) { c.NamespacePrefix = "" },
+		func(c *Config) { c.MaxDuration = -time.Second },
+	} {
+		bad := DefaultConfig()
+		modify(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", bad)
+		}
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	for s, want := range map[string]Action{"": ActionRun, "run": ActionRun, "promote": ActionPromote, "discard": ActionDiscard} {
+		got, err := ParseAction(s)
+		if err != nil || got != want {
+			t.Errorf("ParseAction(%q) = %q, %v; want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseAction("merge"); err == nil {
+		t.Error("expected error for invalid action")
+	}
+}
+
+func TestController_Promote(t *testing.T) {
+	slots := &fakeSlots{}
+	tables := newFakeTables("public.orders", "shadow_public.orders", "shadow_public.users")
+	c := NewController(DefaultConfig(), slots, tables, testLogger)
+
+	if err := c.Promote(context.Background(), "philotes_cdc", []string{"public.orders", "users"}, matchingOptions("public.orders", "public.users")); err != nil {
+		t.Fatalf("Promote() error = %v", err)
+	}
+
+	want := map[string]bool{"public.orders": true, "public.orders_pre_shadow": true, "public.users": true}
+	if !reflect.DeepEqual(tables.tables, want) {
+		t.Errorf("tables = %v, want %v", tables.tables, want)
+	}
+	if !reflect.DeepEqual(slots.dropped, []string{"philotes_cdc_shadow"}) {
+		t.Errorf("dropped slots = %v", slots.dropped)
+	}
+}
+
+// matchingOptions returns promotion options for snapshotted tables that match
+// production.
+func matchingOptions(tables ...string) PromoteOptions {
+	opts := PromoteOptions{Snapshotted: tables}
+	for _, table := range tables {
+		opts.Comparisons = append(opts.Comparisons, Comparison{Table: table, ProductionRows: 5, ShadowRows: 5})
+	}
+	return opts
+}
+
+func TestController_PromoteForcedDespiteMismatch(t *testing.T) {
+	tables := newFakeTables("shadow_public.orders")
+	c := NewController(DefaultConfig(), &fakeSlots{}, tables, testLogger)
+
+	opts := matchingOptions("public.orders")
+	opts.Comparisons[0].ShadowRows = 4
+	if err := c.Promote(context.Background(), "philotes_cdc", []string{"public.orders"}, opts); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("Promote() error = %v, want ErrMismatch", err)
+	}
+
+	opts.Force = true
+	if err := c.Promote(context.Background(), "philotes_cdc", []string{"public.orders"}, opts); err != nil {
+		t.Fatalf("forced Promote() error = %v", err)
+	}
+	if !tables.tables["public.orders"] {
+		t.Errorf("tables = %v, want the shadow table promoted", tables.tables)
+	}
+}
+
+func TestController_PromoteChecksBeforeRenaming(t *testing.T) {
+	mismatched := matchingOptions("public.orders", "public.users")
+	mismatched.Comparisons[1].ShadowRows++
+	notSnapshotted := matchingOptions("public.orders", "public.users")
+	notSnapshotted.Snapshotted = notSnapshotted.Snapshotted[:1]
+	all := func() *fakeTables {
+		return newFakeTables("public.orders", "shadow_public.orders", "shadow_public.users")
+	}
+
+	tests := []struct {
+		name   string
+		tables *fakeTables
+		opts   PromoteOptions
+	}{
+		{"missing shadow table", newFakeTables("public.orders", "shadow_public.orders", "public.users"), matchingOptions("public.orders", "public.users")},
+		{"backup exists", newFakeTables("public.orders", "shadow_public.orders", "shadow_public.users", "public.users_pre_shadow"), matchingOptions("public.orders", "public.users")},
+		{"mismatched rows", all(), mismatched},
+		{"not snapshotted", all(), notSnapshotted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(tt.tables.tables)
+			slots := &fakeSlots{}
+			c := NewController(DefaultConfig(), slots, tt.tables, testLogger)
+
+			if err := c.Promote(context.Background(), "philotes_cdc", []string{"public.orders", "public.users"}, tt.opts); err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.tables.tables["public.orders"] || !tt.tables.tables["shadow_public.orders"] || len(tt.tables.tables) != before {
+				t.Errorf("tables changed by a rejected promotion: %v", tt.tables.tables)
+			}
+			if len(slots.dropped) != 0 {
+				t.Errorf("slot dropped by a rejected promotion: %v", slots.dropped)
+			}
+		})
+	}
+}
+
+func TestController_Discard(t *testing.T) {
+	slots := &fakeSlots{}
+	tables := newFakeTables("public.orders", "shadow_public.orders")
+	c := NewController(DefaultConfig(), slots, tables, testLogger)
+
+	if err := c.Discard(context.Background(), "philotes_cdc", []string{"public.orders"}); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if want := map[string]bool{"public.orders": true}; !reflect.DeepEqual(tables.tables, want) {
+		t.Errorf("tables = %v, want %v", tables.tables, want)
+	}
+	if !reflect.DeepEqual(slots.dropped, []string{"philotes_cdc_shadow"}) {
+		t.Errorf("dropped slots = %v", slots.dropped)
+	}
+}
+
+// replaySource emits a fixed list of events and then closes.
+type replaySource struct {
+	name   string
+	events []cdc.Event
+}
+
+func (s *replaySource) Start(_ context.Context) (<-chan cdc.Event, <-chan error) {
+	events := make(chan cdc.Event, len(s.events))
+	for _, e := range s.events {
+		events <- e
+	}
+	close(events)
+	return events, make(chan error)
+}
+
+func (s *replaySource) Stop(_ context.Context) error { return nil }
+func (s *replaySource) LastLSN() string              { return "" }
+func (s *replaySource) Name() string                 { return s.name }
+
+// tableBuffer applies written events to per-table row counts, the way the
+// writer would to Iceberg tables under namespacePrefix.
+type tableBuffer struct {
+	namespacePrefix string
+	rows            map[verify.Table]int64
+}
+
+func (b *tableBuffer) Write(_ context.Context, events []cdc.Event) error {
+	for _, e := range events {
+		table := verify.Table{Schema: b.namespacePrefix + e.Schema, Name: e.Table}
+		switch e.Operation {
+		case cdc.OperationInsert:
+			b.rows[table]++
+		case cdc.OperationDelete:
+			b.rows[table]--
+		}
+	}
+	return nil
+}
+
+func (b *tableBuffer) ReadBatch(_ context.Context, _ string, _ int) ([]buffer.BufferedEvent, error) {
+	return nil, nil
+}
+func (b *tableBuffer) MarkProcessed(_ context.Context, _ []int64) error { return nil }
+func (b *tableBuffer) Cleanup(_ context.Context, _ time.Duration) (int64, error) {
+	return 0, nil
+}
+func (b *tableBuffer) Stats(_ context.Context) (buffer.Stats, error) { return buffer.Stats{}, nil }
+func (b *tableBuffer) Close() error                                  { return nil }
+
+// mapCounter counts rows from a map.
+type mapCounter map[verify.Table]int64
+
+func (c mapCounter) CountRows(_ context.Context, t verify.Table) (int64, error) {
+	return c[t], nil
+}
+
+func TestShadowMatchesProductionForIdenticalInput(t *testing.T) {
+	events := []cdc.Event{
+		{Schema: "public", Table: "orders", Operation: cdc.OperationInsert, After: map[string]any{"id": 1}},
+		{Schema: "public", Table: "orders", Operation: cdc.OperationInsert, After: map[string]any{"id": 2}},
+		{Schema: "public", Table: "orders", Operation: cdc.OperationUpdate, After: map[string]any{"id": 2}},
+		{Schema: "public", Table: "users", Operation: cdc.OperationInsert, After: map[string]any{"id": 7}},
+		{Schema: "public", Table: "orders", Operation: cdc.OperationDelete, Before: map[string]any{"id": 1}},
+	}
+	cfg := DefaultConfig()
+	rows := make(map[verify.Table]int64)
+
+	run := func(name, prefix string) {
+		pipelineCfg := pipeline.DefaultConfig()
+		pipelineCfg.CheckpointEnabled = false
+		p := pipeline.New(&replaySource{name: name, events: events}, nil,
+			&tableBuffer{namespacePrefix: prefix, rows: rows}, pipelineCfg, testLogger)
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("%s Run() error = %v", name, err)
+		}
+	}
+	run("postgres-app", "")
+	run(cfg.SourceName("postgres-app"), cfg.NamespacePrefix)
+
+	comparisons, err := Compare(context.Background(), cfg, mapCounter(rows), []string{"public.orders", "users"})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	want := []Comparison{
+		{Table: "public.orders", ProductionRows: 1, ShadowRows: 1},
+		{Table: "public.users", ProductionRows: 1, ShadowRows: 1},
+	}
+	if !reflect.DeepEqual(comparisons, want) {
+		t.Errorf("Compare() = %+v, want %+v", comparisons, want)
+	}
+	for _, c := range comparisons {
+		if !c.Matches() {
+			t.Errorf("%s: shadow does not match production", c.Table)
+		}
+	}
+
+	// A shadow that missed an event no longer matches.
+	rows[verify.Table{Schema: "shadow_public", Name: "orders"}]++
+	comparisons, _ = Compare(context.Background(), cfg, mapCounter(rows), []string{"public.orders"})
+	if comparisons[0].Matches() {
+		t.Error("expected mismatch after shadow diverged")
+	}
+}
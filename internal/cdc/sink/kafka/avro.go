@@ -0,0 +1,166 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/registry"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// changeEventSchema is the Avro schema of published change events. Column
+// values are carried as strings: text as-is, everything else JSON-encoded,
+// so one schema serves every table.
+const changeEventSchema = `{
+  "type": "record",
+  "name": "ChangeEvent",
+  "namespace": "io.philotes.cdc",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "lsn", "type": "string"},
+    {"name": "transaction_id", "type": "long"},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+    {"name": "schema", "type": "string"},
+    {"name": "table", "type": "string"},
+    {"name": "operation", "type": "string"},
+    {"name": "before", "type": ["null", {"type": "map", "values": ["null", "string"]}], "default": null},
+    {"name": "after", "type": ["null", {"type": "map", "values": ["null", "string"]}], "default": null},
+    {"name": "key_columns", "type": {"type": "array", "items": "string"}}
+  ]
+}`
+
+// changeEventAvro is a change event shaped for changeEventSchema.
+type changeEventAvro struct {
+	ID            string              `avro:"id"`
+	LSN           string              `avro:"lsn"`
+	TransactionID int64               `avro:"transaction_id"`
+	Timestamp     time.Time           `avro:"timestamp"`
+	Schema        string              `avro:"schema"`
+	Table         string              `avro:"table"`
+	Operation     string              `avro:"operation"`
+	Before        *map[string]*string `avro:"before"`
+	After         *map[string]*string `avro:"after"`
+	KeyColumns    []string            `avro:"key_columns"`
+}
+
+// avroRow converts a row to the nullable map of column strings.
+func avroRow(row map[string]any) *map[string]*string {
+	if row == nil {
+		return nil
+	}
+	values := make(map[string]*string, len(row))
+	for col, v := range row {
+		if v == nil {
+			values[col] = nil
+			continue
+		}
+		s := columnString(v)
+		values[col] = &s
+	}
+	return &values
+}
+
+// columnString renders a column value for the Avro envelope.
+func columnString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// avroSerializer encodes change events in the Confluent wire format, with the
+// change event schema registered under each topic's value subject.
+type avroSerializer struct {
+	registry *registry.Client
+	schema   avro.Schema
+
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+// newAvroSerializer creates a serializer registering schemas with the
+// registry at baseURL.
+func newAvroSerializer(baseURL, username, password string, httpClient *http.Client) (*avroSerializer, error) {
+	schema, err := avro.Parse(changeEventSchema)
+	if err != nil {
+		return nil, fmt.Errorf("parse change event schema: %w", err)
+	}
+
+	opts := []registry.ClientFunc{registry.WithHTTPClient(httpClient)}
+	if username != "" {
+		opts = append(opts, registry.WithBasicAuth(username, password))
+	}
+	client, err := registry.NewClient(baseURL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create schema registry client: %w", err)
+	}
+
+	return &avroSerializer{
+		registry: client,
+		schema:   schema,
+		ids:      make(map[string]int),
+	}, nil
+}
+
+// schemaID returns the id of the change event schema for a topic's value
+// subject, registering it on first use.
+func (s *avroSerializer) schemaID(ctx context.Context, topic string) (int, error) {
+	subject := topic + "-value"
+
+	s.mu.Lock()
+	id, ok := s.ids[subject]
+	s.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, _, err := s.registry.CreateSchema(ctx, subject, changeEventSchema)
+	if err != nil {
+		return 0, fmt.Errorf("register schema for %s: %w", subject, err)
+	}
+
+	s.mu.Lock()
+	s.ids[subject] = id
+	s.mu.Unlock()
+	return id, nil
+}
+
+// Serialize encodes event for topic, prefixed with the schema registry wire
+// format header: a zero magic byte and the big-endian schema id.
+func (s *avroSerializer) Serialize(ctx context.Context, topic string, event cdc.Event) ([]byte, error) {
+	id, err := s.schemaID(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := avro.Marshal(s.schema, changeEventAvro{
+		ID:            event.ID,
+		LSN:           event.LSN,
+		TransactionID: event.TransactionID,
+		Timestamp:     event.Timestamp,
+		Schema:        event.Schema,
+		Table:         event.Table,
+		Operation:     string(event.Operation),
+		Before:        avroRow(event.Before),
+		After:         avroRow(event.After),
+		KeyColumns:    event.KeyColumns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode avro: %w", err)
+	}
+
+	framed := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(framed[1:], uint32(id))
+	return append(framed, payload...), nil
+}
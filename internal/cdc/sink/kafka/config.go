@@ -0,0 +1,161 @@
+// Package kafka provides a sink that publishes CDC events to Kafka topics.
+package kafka
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config holds configuration for the Kafka sink.
+type Config struct {
+	// SourceID identifies the source in logs and metrics.
+	SourceID string
+
+	// Brokers are the bootstrap broker addresses (host:port).
+	Brokers []string
+
+	// TopicTemplate names the topic for a table; {schema} and {table} are
+	// replaced, e.g. "cdc.{schema}.{table}".
+	TopicTemplate string
+
+	// SchemaRegistryURL enables Avro encoding using schemas registered with a
+	// Confluent-compatible schema registry. Empty means JSON encoding.
+	SchemaRegistryURL string
+
+	// ClientID identifies the producer to the brokers.
+	ClientID string
+
+	// RequiredAcks is the number of acknowledgements a write needs: -1 waits
+	// for all in-sync replicas, 1 for the leader only, 0 for none.
+	RequiredAcks int
+
+	// Timeout bounds each request to a broker or the schema registry.
+	Timeout time.Duration
+
+	// MaxAttempts is how often a record is attempted before the write fails.
+	MaxAttempts int
+
+	// Compression is the codec record batches are compressed with: none,
+	// gzip, snappy, lz4 or zstd.
+	Compression string
+
+	// Idempotent enables the idempotent producer, so retried batches are not
+	// written twice. It requires RequiredAcks -1.
+	Idempotent bool
+
+	// TLS configures encryption of broker connections.
+	TLS TLSConfig
+
+	// SASL configures authentication with the brokers.
+	SASL SASLConfig
+
+	// SchemaRegistryUsername and SchemaRegistryPassword authenticate with the
+	// schema registry using basic auth.
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string
+}
+
+// TLSConfig configures TLS for broker connections.
+type TLSConfig struct {
+	// Enabled connects to the brokers over TLS.
+	Enabled bool
+
+	// CAFile is a PEM bundle of certificate authorities to trust instead of
+	// the system pool.
+	CAFile string
+
+	// CertFile and KeyFile are the client certificate for mutual TLS.
+	CertFile string
+	KeyFile  string
+
+	// SkipVerify skips verification of the broker certificates.
+	SkipVerify bool
+}
+
+// SASLConfig configures SASL authentication.
+type SASLConfig struct {
+	// Mechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512. Empty disables SASL.
+	Mechanism string
+
+	// Username and Password are the SASL credentials.
+	Username string
+	Password string
+}
+
+// Compression codecs.
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+	CompressionLZ4    = "lz4"
+	CompressionZstd   = "zstd"
+)
+
+// SASL mechanisms.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		TopicTemplate: "cdc.{schema}.{table}",
+		ClientID:      "philotes-worker",
+		RequiredAcks:  -1,
+		Timeout:       30 * time.Second,
+		MaxAttempts:   3,
+		Compression:   CompressionSnappy,
+		Idempotent:    true,
+	}
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	if len(c.Brokers) == 0 {
+		return ErrMissingBrokers
+	}
+	if c.TopicTemplate == "" {
+		return ErrMissingTopicTemplate
+	}
+	if c.RequiredAcks < -1 || c.RequiredAcks > 1 {
+		return ErrInvalidRequiredAcks
+	}
+	if c.Idempotent && c.RequiredAcks != -1 {
+		return ErrIdempotenceRequiresAllAcks
+	}
+	switch c.Compression {
+	case "", CompressionNone, CompressionGzip, CompressionSnappy, CompressionLZ4, CompressionZstd:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidCompression, c.Compression)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return ErrIncompleteClientCert
+	}
+	switch strings.ToUpper(c.SASL.Mechanism) {
+	case "":
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if c.SASL.Username == "" {
+			return ErrMissingSASLCredentials
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidSASLMechanism, c.SASL.Mechanism)
+	}
+	return nil
+}
+
+// Topic renders the topic name for a table. Characters Kafka does not allow
+// in topic names are replaced with underscores.
+func (c *Config) Topic(schema, table string) string {
+	topic := strings.NewReplacer("{schema}", schema, "{table}", table).Replace(c.TopicTemplate)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, topic)
+}
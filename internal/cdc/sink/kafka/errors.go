@@ -0,0 +1,31 @@
+package kafka
+
+import "errors"
+
+var (
+	// ErrMissingBrokers is returned when no bootstrap brokers are configured.
+	ErrMissingBrokers = errors.New("kafka: at least one broker is required")
+
+	// ErrMissingTopicTemplate is returned when the topic template is empty.
+	ErrMissingTopicTemplate = errors.New("kafka: topic template is required")
+
+	// ErrInvalidRequiredAcks is returned when required acks is not -1, 0 or 1.
+	ErrInvalidRequiredAcks = errors.New("kafka: required acks must be -1, 0 or 1")
+
+	// ErrIdempotenceRequiresAllAcks is returned when the idempotent producer
+	// is enabled with required acks other than -1.
+	ErrIdempotenceRequiresAllAcks = errors.New("kafka: idempotent producer requires required acks -1")
+
+	// ErrInvalidCompression is returned for an unknown compression codec.
+	ErrInvalidCompression = errors.New("kafka: compression must be none, gzip, snappy, lz4 or zstd")
+
+	// ErrIncompleteClientCert is returned when only one of the client
+	// certificate and key is configured.
+	ErrIncompleteClientCert = errors.New("kafka: tls client certificate and key must be set together")
+
+	// ErrInvalidSASLMechanism is returned for an unknown SASL mechanism.
+	ErrInvalidSASLMechanism = errors.New("kafka: sasl mechanism must be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512")
+
+	// ErrMissingSASLCredentials is returned when SASL is enabled without a username.
+	ErrMissingSASLCredentials = errors.New("kafka: sasl username is required")
+)
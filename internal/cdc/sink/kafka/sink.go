@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+)
+
+// Sink publishes batches of CDC events to Kafka, one topic per table. Each
+// record is keyed by the row's key columns, so changes to a row stay in
+// order on one partition.
+type Sink struct {
+	config   Config
+	producer Producer
+	avro     *avroSerializer
+	logger   *slog.Logger
+}
+
+// NewSink creates a Kafka sink.
+func NewSink(cfg Config, logger *slog.Logger) (*Sink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("component", "kafka-sink", "source", cfg.SourceID)
+
+	producer, err := newClient(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSink(cfg, producer, logger)
+	if err != nil {
+		producer.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// newSink creates a sink that publishes through producer.
+func newSink(cfg Config, producer Producer, logger *slog.Logger) (*Sink, error) {
+	s := &Sink{
+		config:   cfg,
+		producer: producer,
+		logger:   logger,
+	}
+	if cfg.SchemaRegistryURL != "" {
+		serializer, err := newAvroSerializer(cfg.SchemaRegistryURL, cfg.SchemaRegistryUsername,
+			cfg.SchemaRegistryPassword, &http.Client{Timeout: cfg.Timeout})
+		if err != nil {
+			return nil, err
+		}
+		s.avro = serializer
+	}
+	return s, nil
+}
+
+// WriteEvents publishes events to their tables' topics.
+func (s *Sink) WriteEvents(ctx context.Context, events []buffer.BufferedEvent) error {
+	messages := make([]Message, 0, len(events))
+	for _, e := range events {
+		msg, err := s.message(ctx, e.Event)
+		if err != nil {
+			return fmt.Errorf("encode event %d: %w", e.ID, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := s.producer.Produce(ctx, messages); err != nil {
+		return fmt.Errorf("publish to kafka: %w", err)
+	}
+
+	s.logger.Debug("published events to kafka", "events", len(messages))
+	return nil
+}
+
+// message builds the Kafka record for an event.
+func (s *Sink) message(ctx context.Context, event cdc.Event) (Message, error) {
+	topic := s.config.Topic(event.Schema, event.Table)
+
+	var value []byte
+	var err error
+	if s.avro != nil {
+		value, err = s.avro.Serialize(ctx, topic, event)
+	} else {
+		value, err = json.Marshal(event)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	key, err := messageKey(event)
+	if err != nil {
+		return Message{}, err
+	}
+
+	ts := event.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return Message{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+		Headers: map[string]string{
+			"operation": string(event.Operation),
+			"source":    s.config.SourceID,
+		},
+		Time: ts,
+	}, nil
+}
+
+// messageKey encodes the event's key columns as a JSON object. Events without
+// key columns have no key.
+func messageKey(event cdc.Event) ([]byte, error) {
+	if len(event.KeyColumns) == 0 {
+		return nil, nil
+	}
+	row := event.After
+	if event.Operation == cdc.OperationDelete || row == nil {
+		row = event.Before
+	}
+	if row == nil {
+		return nil, nil
+	}
+
+	key := make(map[string]any, len(event.KeyColumns))
+	for _, col := range event.KeyColumns {
+		key[col] = row[col]
+	}
+	return json.Marshal(key)
+}
+
+// Close closes the producer.
+func (s *Sink) Close() error {
+	return s.producer.Close()
+}
+
+// BatchHandler returns a buffer.BatchHandler that publishes events to Kafka.
+func BatchHandler(s *Sink) buffer.BatchHandler {
+	return func(ctx context.Context, events []buffer.BufferedEvent) error {
+		return s.WriteEvents(ctx, events)
+	}
+}
@@ -0,0 +1,191 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// recordingProducer keeps produced messages in memory.
+type recordingProducer struct {
+	messages []Message
+}
+
+func (p *recordingProducer) Produce(_ context.Context, messages []Message) error {
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func (p *recordingProducer) Close() error { return nil }
+
+func testEvents() []buffer.BufferedEvent {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []buffer.BufferedEvent{
+		{ID: 1, Event: cdc.Event{
+			ID: "e1", LSN: "0/16B3748", Timestamp: ts, Schema: "public", Table: "orders",
+			Operation: cdc.OperationInsert, After: map[string]any{"id": 7, "status": "new"}, KeyColumns: []string{"id"},
+		}},
+		{ID: 2, Event: cdc.Event{
+			ID: "e2", LSN: "0/16B3790", Timestamp: ts, Schema: "sales", Table: "invoices",
+			Operation: cdc.OperationDelete, Before: map[string]any{"id": 3}, KeyColumns: []string{"id"},
+		}},
+	}
+}
+
+func TestSink_JSON(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SourceID = "postgres-app"
+	producer := &recordingProducer{}
+	s, err := newSink(cfg, producer, testLogger())
+	if err != nil {
+		t.Fatalf("newSink() error = %v", err)
+	}
+
+	if err := BatchHandler(s)(context.Background(), testEvents()); err != nil {
+		t.Fatalf("BatchHandler() error = %v", err)
+	}
+	if len(producer.messages) != 2 {
+		t.Fatalf("produced %d messages, want 2", len(producer.messages))
+	}
+
+	first, second := producer.messages[0], producer.messages[1]
+	if first.Topic != "cdc.public.orders" || second.Topic != "cdc.sales.invoices" {
+		t.Errorf("topics = %s, %s", first.Topic, second.Topic)
+	}
+	if string(first.Key) != `{"id":7}` || string(second.Key) != `{"id":3}` {
+		t.Errorf("keys = %s, %s", first.Key, second.Key)
+	}
+	if first.Headers["operation"] != "INSERT" || first.Headers["source"] != "postgres-app" {
+		t.Errorf("headers = %v", first.Headers)
+	}
+
+	var event cdc.Event
+	if err := json.Unmarshal(first.Value, &event); err != nil {
+		t.Fatalf("value is not a JSON event: %v", err)
+	}
+	if event.After["status"] != "new" {
+		t.Errorf("decoded after = %v", event.After)
+	}
+}
+
+func TestConfig_Topic(t *testing.T) {
+	cfg := Config{TopicTemplate: "philotes-{schema}_{table}"}
+	if got := cfg.Topic("public", "order items"); got != "philotes-public_order_items" {
+		t.Errorf("Topic() = %q", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != ErrMissingBrokers {
+		t.Errorf("Validate() = %v, want ErrMissingBrokers", err)
+	}
+	cfg.Brokers = []string{"localhost:9092"}
+	cfg.RequiredAcks = 2
+	if err := cfg.Validate(); err != ErrInvalidRequiredAcks {
+		t.Errorf("Validate() = %v, want ErrInvalidRequiredAcks", err)
+	}
+	cfg.RequiredAcks = 1
+	if err := cfg.Validate(); err != ErrIdempotenceRequiresAllAcks {
+		t.Errorf("Validate() = %v, want ErrIdempotenceRequiresAllAcks", err)
+	}
+	cfg.Idempotent = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil for leader acks without idempotence", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Brokers = []string{"localhost:9092"}
+	cfg.Compression = "brotli"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidCompression) {
+		t.Errorf("Validate() = %v, want ErrInvalidCompression", err)
+	}
+	cfg.Compression = CompressionZstd
+	cfg.SASL = SASLConfig{Mechanism: "GSSAPI", Username: "philotes"}
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidSASLMechanism) {
+		t.Errorf("Validate() = %v, want ErrInvalidSASLMechanism", err)
+	}
+	cfg.SASL = SASLConfig{Mechanism: "scram-sha-512"}
+	if err := cfg.Validate(); err != ErrMissingSASLCredentials {
+		t.Errorf("Validate() = %v, want ErrMissingSASLCredentials", err)
+	}
+	cfg.SASL.Username = "philotes"
+	cfg.TLS = TLSConfig{Enabled: true, CertFile: "client.pem"}
+	if err := cfg.Validate(); err != ErrIncompleteClientCert {
+		t.Errorf("Validate() = %v, want ErrIncompleteClientCert", err)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Brokers = []string{"localhost:9092"}
+	cfg.SASL = SASLConfig{Mechanism: SASLScramSHA256, Username: "philotes", Password: "secret"}
+	cfg.TLS = TLSConfig{Enabled: true}
+	if _, err := clientOptions(cfg); err != nil {
+		t.Fatalf("clientOptions() error = %v", err)
+	}
+
+	// A CA file without certificates is rejected before connecting
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.TLS.CAFile = caFile
+	if _, err := NewSink(cfg, testLogger()); err == nil {
+		t.Error("NewSink() with an invalid CA file succeeded")
+	}
+}
+
+func TestSink_AvroWithSchemaRegistry(t *testing.T) {
+	var subjects []string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subjects = append(subjects, r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer registry.Close()
+
+	cfg := DefaultConfig()
+	cfg.SchemaRegistryURL = registry.URL
+	producer := &recordingProducer{}
+	s, err := newSink(cfg, producer, testLogger())
+	if err != nil {
+		t.Fatalf("newSink() error = %v", err)
+	}
+
+	events := testEvents()
+	events = append(events, events[0])
+	if err := s.WriteEvents(context.Background(), events); err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+
+	// Each subject is registered once
+	if len(subjects) != 2 || subjects[0] != "/subjects/cdc.public.orders-value/versions" {
+		t.Errorf("registered subjects = %v", subjects)
+	}
+
+	value := producer.messages[0].Value
+	if value[0] != 0 || binary.BigEndian.Uint32(value[1:5]) != 42 {
+		t.Fatalf("value is not framed with schema id 42: % x", value[:5])
+	}
+	// The payload starts with the event id as an Avro string
+	if length, n := binary.Varint(value[5:]); length != 2 || string(value[5+n:5+n+2]) != "e1" {
+		t.Errorf("payload does not start with the event id: % x", value[5:])
+	}
+}
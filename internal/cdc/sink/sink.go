@@ -0,0 +1,119 @@
+// Package sink fans batches of CDC events out to the configured destinations.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+const (
+	// TypeIceberg writes batches to Iceberg tables.
+	TypeIceberg = "iceberg"
+
+	// TypeKafka writes batches to Kafka topics.
+	TypeKafka = "kafka"
+)
+
+// ParseTypes parses and de-duplicates a list of sink types.
+func ParseTypes(types []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		switch t {
+		case TypeIceberg, TypeKafka:
+		default:
+			return nil, fmt.Errorf("unknown sink %q (want iceberg or kafka)", t)
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("at least one sink must be enabled")
+	}
+	return result, nil
+}
+
+// Sink is a named batch handler.
+type Sink struct {
+	// Name identifies the sink in logs and metrics.
+	Name string
+
+	// Handler writes a batch to the sink.
+	Handler buffer.BatchHandler
+}
+
+// Tee returns a BatchHandler that writes each batch to every sink
+// concurrently. The batch fails only if every sink fails; a sink that fails
+// while another succeeds is logged and counted, and does not see the batch
+// again, so sinks that must not miss events should be the only one enabled.
+func Tee(sourceID string, sinks []Sink, logger *slog.Logger) buffer.BatchHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("component", "sink-tee")
+
+	return func(ctx context.Context, events []buffer.BufferedEvent) error {
+		errs := make([]error, len(sinks))
+
+		var wg sync.WaitGroup
+		for i, s := range sinks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = s.Handler(ctx, events)
+			}()
+		}
+		wg.Wait()
+
+		var failed []error
+		for i, s := range sinks {
+			if errs[i] != nil {
+				metrics.CDCSinkBatchesTotal.WithLabelValues(sourceID, s.Name, "failed").Inc()
+				failed = append(failed, fmt.Errorf("%s sink: %w", s.Name, errs[i]))
+				continue
+			}
+			metrics.CDCSinkBatchesTotal.WithLabelValues(sourceID, s.Name, "success").Inc()
+		}
+
+		if len(failed) == len(sinks) {
+			if len(sinks) > 1 {
+				// One sink rejecting events must not mark the batch written
+				// when the other sinks failed outright
+				for i, err := range failed {
+					var rejected *buffer.RejectedEventsError
+					if errors.As(err, &rejected) {
+						failed[i] = errors.New(err.Error())
+					}
+				}
+			}
+			return errors.Join(failed...)
+		}
+
+		// Events a sink rejected still go to the dead-letter queue
+		var rejected *buffer.RejectedEventsError
+		for _, err := range failed {
+			if rejected == nil && errors.As(err, &rejected) {
+				continue
+			}
+			logger.Warn("sink failed to write batch; other sinks succeeded",
+				"events", len(events),
+				"error", err,
+			)
+		}
+		if rejected != nil {
+			return rejected
+		}
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+)
+
+func handler(calls *atomic.Int32, err error) buffer.BatchHandler {
+	return func(context.Context, []buffer.BufferedEvent) error {
+		calls.Add(1)
+		return err
+	}
+}
+
+func TestTee(t *testing.T) {
+	errDown := errors.New("broker down")
+
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr bool
+	}{
+		{"all succeed", []error{nil, nil}, false},
+		{"one fails", []error{nil, errDown}, false},
+		{"all fail", []error{errDown, errDown}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			sinks := []Sink{
+				{Name: TypeIceberg, Handler: handler(&calls, tt.errs[0])},
+				{Name: TypeKafka, Handler: handler(&calls, tt.errs[1])},
+			}
+
+			err := Tee("postgres-app", sinks, nil)(context.Background(), []buffer.BufferedEvent{{ID: 1}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Tee() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errDown) {
+				t.Errorf("Tee() error = %v, want it to wrap the sink errors", err)
+			}
+			if calls.Load() != 2 {
+				t.Errorf("sinks called %d times, want every sink once", calls.Load())
+			}
+		})
+	}
+}
+
+func TestTeeRejectedEvents(t *testing.T) {
+	rejected := &buffer.RejectedEventsError{Events: []buffer.BufferedEvent{{ID: 1}}, Err: errors.New("bad column")}
+	errDown := errors.New("broker down")
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantRejected bool
+	}{
+		{"other sink succeeds", []error{rejected, nil}, true},
+		{"other sink fails", []error{rejected, errDown}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			sinks := []Sink{
+				{Name: TypeIceberg, Handler: handler(&calls, tt.errs[0])},
+				{Name: TypeKafka, Handler: handler(&calls, tt.errs[1])},
+			}
+
+			err := Tee("postgres-app", sinks, nil)(context.Background(), []buffer.BufferedEvent{{ID: 1}})
+			if err == nil {
+				t.Fatal("Tee() error = nil, want an error")
+			}
+			var got *buffer.RejectedEventsError
+			if errors.As(err, &got) != tt.wantRejected {
+				t.Errorf("Tee() error = %v, want rejected events %v", err, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	got, err := ParseTypes([]string{"Iceberg", " kafka", "iceberg"})
+	if err != nil {
+		t.Fatalf("ParseTypes() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{TypeIceberg, TypeKafka}) {
+		t.Errorf("ParseTypes() = %v", got)
+	}
+
+	if _, err := ParseTypes([]string{"kinesis"}); err == nil {
+		t.Error("expected an error for an unknown sink")
+	}
+	if _, err := ParseTypes(nil); err == nil {
+		t.Error("expected an error when no sink is enabled")
+	}
+}
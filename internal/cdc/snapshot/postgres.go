@@ -0,0 +1,255 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// PostgresReader reads snapshot rows from a PostgreSQL source.
+type PostgresReader struct {
+	db           *sql.DB
+	jsonAsStruct bool
+}
+
+// NewPostgresReader creates a new PostgresReader.
+func NewPostgresReader(db *sql.DB) *PostgresReader {
+	return &PostgresReader{db: db}
+}
+
+// SetJSONAsStruct sets whether json and jsonb objects and arrays are read as
+// nested values instead of JSON text, as the replication stream decodes them.
+func (r *PostgresReader) SetJSONAsStruct(asStruct bool) {
+	r.jsonAsStruct = asStruct
+}
+
+// Columns returns the column names of a table in ordinal order.
+func (r *PostgresReader) Columns(ctx context.Context, schema, table string) ([]string, error) {
+	query := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2
+		ORDER BY ordinal_position
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("query columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate columns: %w", err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s not found", schema, table)
+	}
+	return columns, nil
+}
+
+// ColumnTypes returns the declared type of every column of a table as
+// PostgreSQL formats it, such as "numeric(38,9)", keyed by column name.
+func (r *PostgresReader) ColumnTypes(ctx context.Context, schema, table string) (map[string]string, error) {
+	query := `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("query column types: %w", err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var column, columnType string
+		if err := rows.Scan(&column, &columnType); err != nil {
+			return nil, fmt.Errorf("scan column type: %w", err)
+		}
+		types[column] = columnType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate column types: %w", err)
+	}
+	return types, nil
+}
+
+// KeyColumn returns the single-column primary key of a table. Tables without
+// a primary key, or with a composite one, cannot be read in key order.
+func (r *PostgresReader) KeyColumn(ctx context.Context, schema, table string) (string, error) {
+	query := `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = (quote_ident($1) || '.' || quote_ident($2))::regclass
+			AND i.indisprimary
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return "", fmt.Errorf("query primary key: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", fmt.Errorf("scan primary key: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate primary key: %w", err)
+	}
+
+	switch len(columns) {
+	case 0:
+		return "", fmt.Errorf("table has no primary key")
+	case 1:
+		return columns[0], nil
+	}
+	return "", fmt.Errorf("composite primary key (%s) is not supported", strings.Join(columns, ", "))
+}
+
+// Begin opens a read-only repeatable-read transaction. The transaction's
+// statement timeout follows the context deadline so the server stops work
+// when MaxTxDuration is reached.
+func (r *PostgresReader) Begin(ctx context.Context) (Tx, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline).Milliseconds()
+		if timeout < 1 {
+			timeout = 1
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout)); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("set statement timeout: %w", err)
+		}
+	}
+
+	return &postgresTx{tx: tx, jsonAsStruct: r.jsonAsStruct}, nil
+}
+
+// postgresTx is a snapshot read transaction.
+type postgresTx struct {
+	tx           *sql.Tx
+	jsonAsStruct bool
+}
+
+// Position exports the transaction's snapshot.
+func (t *postgresTx) Position(ctx context.Context) (string, string, error) {
+	var name, lsn string
+	err := t.tx.QueryRowContext(ctx, "SELECT pg_export_snapshot(), pg_current_wal_lsn()::text").Scan(&name, &lsn)
+	if err != nil {
+		return "", "", err
+	}
+	return name, lsn, nil
+}
+
+// ReadChunk reads the next rows of a table in key order.
+func (t *postgresTx) ReadChunk(ctx context.Context, schema, table, key string, after any, limit int) ([]map[string]any, error) {
+	qualified := quoteIdent(schema) + "." + quoteIdent(table)
+	keyIdent := quoteIdent(key)
+
+	var rows *sql.Rows
+	var err error
+	if after == nil {
+		rows, err = t.tx.QueryContext(ctx,
+			fmt.Sprintf("SELECT * FROM %s ORDER BY %s LIMIT $1", qualified, keyIdent),
+			limit,
+		)
+	} else {
+		rows, err = t.tx.QueryContext(ctx,
+			fmt.Sprintf("SELECT * FROM %s WHERE %s > $1 ORDER BY %s LIMIT $2", qualified, keyIdent, keyIdent),
+			after, limit,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(columnTypes))
+	jsonColumns := make([]bool, len(columnTypes))
+	timestampColumns := make([]bool, len(columnTypes))
+	withZone := make([]bool, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = columnType.Name()
+		jsonColumns[i] = cdc.IsJSONType(columnType.DatabaseTypeName())
+		withZone[i], timestampColumns[i] = cdc.IsTimestampType(columnType.DatabaseTypeName())
+	}
+
+	var result []map[string]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if jsonColumns[i] {
+				value, err := cdc.DecodeJSON(values[i], t.jsonAsStruct)
+				if err != nil {
+					return nil, fmt.Errorf("decode column %s: %w", column, err)
+				}
+				values[i] = value
+			}
+			if timestampColumns[i] {
+				value, err := cdc.DecodeTimestamp(values[i], withZone[i])
+				if err != nil {
+					return nil, fmt.Errorf("decode column %s: %w", column, err)
+				}
+				values[i] = value
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// Close rolls back the read-only transaction.
+func (t *postgresTx) Close() error {
+	return t.tx.Rollback()
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Ensure PostgresReader implements Reader.
+var _ Reader = (*PostgresReader)(nil)
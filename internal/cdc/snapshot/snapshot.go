@@ -0,0 +1,676 @@
+// Package snapshot backfills the existing rows of source tables so a pipeline
+// that starts streaming from a replication slot also captures data written
+// before the slot existed.
+//
+// Snapshots can be tuned to limit their impact on a busy source:
+//
+//   - Mode "consistent" reads every table in a single repeatable-read
+//     transaction. All rows reflect one point in time and the exported
+//     snapshot can be verified exactly, but the transaction pins the xmin
+//     horizon for its whole duration, so vacuum cannot clean up hot tables
+//     until it ends. When verification is enabled the transaction stays open
+//     until the tables have been verified. MaxTxDuration aborts the snapshot
+//     rather than letting it run unbounded.
+//
+//   - Mode "chunked" reads each table in primary key order, one short
+//     transaction per chunk. No transaction outlives MaxTxDuration, but rows
+//     in different chunks reflect different points in time. The stream is
+//     relied on to bring every row up to date.
+//
+// Order decides whether the snapshot runs before streaming starts or lazily
+// after it:
+//
+//   - "snapshot_first" copies the tables, then starts streaming from the
+//     slot's position. Changes committed while the snapshot ran are replayed
+//     on top of rows that may already contain them. The Iceberg writer
+//     appends change records rather than applying them by key, so such a
+//     row appears twice: once as a snapshot row and once as the replayed
+//     change. Readers that need the current state must deduplicate by key,
+//     keeping the record with the latest _cdc_lsn (snapshot rows carry the
+//     snapshot LSN).
+//
+//   - "stream_first" starts streaming immediately and copies the tables in the
+//     background (chunked mode only). A snapshot row is dropped when the
+//     stream has already delivered a change for its key, because the stream
+//     carries a newer version of the row. This is only correct when update
+//     events carry the full row: tables with unchanged TOASTed columns need
+//     REPLICA IDENTITY FULL, or those columns may be lost.
+//
+// Trigger decides which tables are snapshotted when the pipeline starts:
+//
+//   - "initial" snapshots the tables only until the pipeline has checkpointed
+//     a streaming position, i.e. on its first start.
+//
+//   - "when_needed" snapshots every table that has not been recorded as
+//     complete, also when resuming, so tables added to an existing pipeline
+//     are backfilled.
+//
+//   - "never" does not snapshot.
+//
+// Completed tables are recorded per table, so a snapshot interrupted by a
+// restart resumes with the tables it had not finished.
+//
+// A Window restricts snapshot reads to a daily low-traffic period. Chunked
+// snapshots pause outside the window; consistent snapshots only wait for it
+// to open, since a transaction cannot be paused.
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+// MetadataSnapshot is the event metadata key marking rows read by a snapshot.
+const MetadataSnapshot = "snapshot"
+
+// Mode selects how rows are read from the source.
+type Mode string
+
+const (
+	// ModeConsistent reads all tables in one repeatable-read transaction.
+	ModeConsistent Mode = "consistent"
+	// ModeChunked reads tables in key order with one short transaction per chunk.
+	ModeChunked Mode = "chunked"
+)
+
+// Order selects whether the snapshot runs before or after streaming starts.
+type Order string
+
+const (
+	// OrderSnapshotFirst completes the snapshot before streaming starts.
+	OrderSnapshotFirst Order = "snapshot_first"
+	// OrderStreamFirst starts streaming and snapshots lazily in the background.
+	OrderStreamFirst Order = "stream_first"
+)
+
+// Trigger selects which tables are snapshotted when the pipeline starts.
+type Trigger string
+
+const (
+	// TriggerNever never snapshots.
+	TriggerNever Trigger = "never"
+	// TriggerInitial snapshots incomplete tables until a streaming position is checkpointed.
+	TriggerInitial Trigger = "initial"
+	// TriggerWhenNeeded snapshots every table not recorded as complete.
+	TriggerWhenNeeded Trigger = "when_needed"
+)
+
+// ParseTrigger parses a snapshot trigger. An empty string means TriggerInitial.
+func ParseTrigger(s string) (Trigger, error) {
+	switch Trigger(s) {
+	case "", TriggerInitial:
+		return TriggerInitial, nil
+	case TriggerNever, TriggerWhenNeeded:
+		return Trigger(s), nil
+	}
+	return "", fmt.Errorf("invalid snapshot trigger %q (want never, initial or when_needed)", s)
+}
+
+// ErrTransactionTooLong is returned when a snapshot transaction exceeds MaxTxDuration.
+var ErrTransactionTooLong = errors.New("snapshot transaction exceeded the maximum duration")
+
+// Config holds snapshot configuration.
+type Config struct {
+	// Tables are the qualified ("schema.table") tables to snapshot.
+	Tables []string
+
+	// Trigger selects which tables are snapshotted when the pipeline starts.
+	Trigger Trigger
+
+	// Mode selects how rows are read.
+	Mode Mode
+
+	// Order selects whether the snapshot runs before or after streaming starts.
+	Order Order
+
+	// Window restricts reads to a daily period; nil means any time.
+	Window *Window
+
+	// MaxTxDuration caps how long a snapshot transaction may stay open (0 = no cap).
+	MaxTxDuration time.Duration
+
+	// ChunkSize is the number of rows read per query.
+	ChunkSize int
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Trigger:   TriggerInitial,
+		Mode:      ModeConsistent,
+		Order:     OrderSnapshotFirst,
+		ChunkSize: 10000,
+	}
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	switch c.Trigger {
+	case TriggerNever, TriggerInitial, TriggerWhenNeeded:
+	default:
+		return fmt.Errorf("invalid snapshot trigger %q (want never, initial or when_needed)", c.Trigger)
+	}
+	switch c.Mode {
+	case ModeConsistent, ModeChunked:
+	default:
+		return fmt.Errorf("invalid snapshot mode %q (want consistent or chunked)", c.Mode)
+	}
+	switch c.Order {
+	case OrderSnapshotFirst, OrderStreamFirst:
+	default:
+		return fmt.Errorf("invalid snapshot order %q (want snapshot_first or stream_first)", c.Order)
+	}
+	if c.Order == OrderStreamFirst && c.Mode != ModeChunked {
+		return fmt.Errorf("snapshot order %s requires chunked mode", c.Order)
+	}
+	if c.ChunkSize <= 0 {
+		return fmt.Errorf("snapshot chunk size must be positive")
+	}
+	if c.MaxTxDuration < 0 {
+		return fmt.Errorf("snapshot max transaction duration cannot be negative")
+	}
+	return nil
+}
+
+// Reader opens read transactions against the source database.
+type Reader interface {
+	// KeyColumn returns the single-column primary key of a table.
+	KeyColumn(ctx context.Context, schema, table string) (string, error)
+
+	// Begin opens a read-only repeatable-read transaction.
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Tx is a read transaction used by a snapshot.
+type Tx interface {
+	// Position exports the transaction's snapshot and returns its name with
+	// the WAL position it is consistent with.
+	Position(ctx context.Context) (name, lsn string, err error)
+
+	// ReadChunk returns up to limit rows with a key greater than after, in
+	// key order. A nil after starts from the beginning of the table.
+	ReadChunk(ctx context.Context, schema, table, key string, after any, limit int) ([]map[string]any, error)
+
+	// Close ends the transaction.
+	Close() error
+}
+
+// Sink receives snapshot rows as insert events.
+type Sink func(ctx context.Context, events []cdc.Event) error
+
+// tableState tracks a table being snapshotted.
+type tableState struct {
+	verify.Table
+
+	// touched holds the keys the stream changed while a lazy snapshot runs.
+	touched map[string]struct{}
+	done    bool
+}
+
+// Snapshotter copies the existing rows of source tables.
+type Snapshotter struct {
+	config Config
+	reader Reader
+	logger *slog.Logger
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// onTableComplete is called once a table's rows have all been written.
+	onTableComplete func(table verify.Table)
+
+	// holdSnapshot keeps a consistent snapshot's transaction open after Run
+	// so its exported snapshot can still be imported; release ends it.
+	holdSnapshot bool
+
+	mu        sync.Mutex
+	tables    []*tableState
+	byName    map[string]*tableState
+	completed map[string]struct{}
+	release   func()
+}
+
+// New creates a new Snapshotter.
+func New(cfg Config, reader Reader, logger *slog.Logger) (*Snapshotter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Snapshotter{
+		config:    cfg,
+		reader:    reader,
+		logger:    logger.With("component", "snapshot"),
+		now:       time.Now,
+		sleep:     sleepContext,
+		byName:    make(map[string]*tableState),
+		completed: make(map[string]struct{}),
+	}, nil
+}
+
+// Trigger returns which tables are snapshotted when the pipeline starts.
+func (s *Snapshotter) Trigger() Trigger {
+	return s.config.Trigger
+}
+
+// SetOnTableComplete sets a function called once each table is copied, so
+// completion can be recorded before the whole snapshot ends.
+func (s *Snapshotter) SetOnTableComplete(fn func(table verify.Table)) {
+	s.onTableComplete = fn
+}
+
+// SetHoldSnapshot keeps the transaction of a consistent snapshot open after
+// Run returns, until Release is called, so the exported snapshot named in the
+// returned tables stays importable for verification. MaxTxDuration still
+// bounds the transaction.
+func (s *Snapshotter) SetHoldSnapshot(hold bool) {
+	s.holdSnapshot = hold
+}
+
+// Release ends the transaction a consistent snapshot kept open. It does
+// nothing if no transaction is held.
+func (s *Snapshotter) Release() {
+	s.mu.Lock()
+	release := s.release
+	s.release = nil
+	s.mu.Unlock()
+
+	if release != nil {
+		release()
+	}
+}
+
+// MarkCompleted records tables, qualified as "schema.table", that an earlier
+// run already copied. Prepare skips them.
+func (s *Snapshotter) MarkCompleted(tables ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range tables {
+		schema, table := splitTable(name)
+		s.completed[schema+"."+table] = struct{}{}
+	}
+}
+
+// Order returns whether the snapshot runs before or after streaming starts.
+func (s *Snapshotter) Order() Order {
+	return s.config.Order
+}
+
+// Prepare resolves the key column of every table. It must be called before
+// streaming starts so a lazy snapshot sees every change the stream delivers.
+func (s *Snapshotter) Prepare(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tables = s.tables[:0]
+	s.byName = make(map[string]*tableState)
+	for _, name := range s.config.Tables {
+		schema, table := splitTable(name)
+		if _, ok := s.completed[schema+"."+table]; ok {
+			s.logger.Debug("table already snapshotted, skipping", "table", schema+"."+table)
+			continue
+		}
+		key, err := s.reader.KeyColumn(ctx, schema, table)
+		if err != nil {
+			return fmt.Errorf("resolve key of %s.%s: %w", schema, table, err)
+		}
+
+		state := &tableState{
+			Table: verify.Table{Schema: schema, Name: table, KeyColumn: key},
+		}
+		if s.config.Order == OrderStreamFirst {
+			state.touched = make(map[string]struct{})
+		}
+		s.tables = append(s.tables, state)
+		s.byName[state.String()] = state
+	}
+	return nil
+}
+
+// Observe records a change delivered by the stream. During a lazy snapshot,
+// rows whose key the stream already changed are not copied.
+func (s *Snapshotter) Observe(event cdc.Event) {
+	if s.config.Order != OrderStreamFirst {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.byName[event.FullyQualifiedTable()]
+	if !ok || state.done {
+		return
+	}
+
+	row := event.After
+	if row == nil {
+		row = event.Before
+	}
+	if value, ok := row[state.KeyColumn]; ok {
+		state.touched[keyString(value)] = struct{}{}
+	}
+}
+
+// Run copies every table to sink and returns the tables for verification.
+// Prepare must have been called first.
+func (s *Snapshotter) Run(ctx context.Context, sink Sink) ([]verify.Table, error) {
+	s.mu.Lock()
+	tables := append([]*tableState(nil), s.tables...)
+	s.mu.Unlock()
+
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	s.logger.Info("starting snapshot",
+		"tables", len(tables),
+		"mode", s.config.Mode,
+		"order", s.config.Order,
+		"window", s.config.Window,
+		"max_tx_duration", s.config.MaxTxDuration,
+	)
+
+	var err error
+	if s.config.Mode == ModeConsistent {
+		err = s.runConsistent(ctx, tables, sink)
+	} else {
+		err = s.runChunked(ctx, tables, sink)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]verify.Table, len(tables))
+	for i, state := range tables {
+		result[i] = state.Table
+	}
+	s.logger.Info("snapshot complete", "tables", len(tables))
+	return result, nil
+}
+
+// runConsistent reads every table in one transaction.
+func (s *Snapshotter) runConsistent(ctx context.Context, tables []*tableState, sink Sink) error {
+	if err := s.waitForWindow(ctx); err != nil {
+		return err
+	}
+
+	txCtx, cancel := s.withTxTimeout(ctx)
+	tx, err := s.reader.Begin(txCtx)
+	if err != nil {
+		cancel()
+		return s.txError(ctx, txCtx, fmt.Errorf("begin snapshot transaction: %w", err))
+	}
+	release := func() {
+		_ = tx.Close()
+		cancel()
+	}
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
+
+	name, lsn, err := tx.Position(txCtx)
+	if err != nil {
+		return s.txError(ctx, txCtx, fmt.Errorf("export snapshot: %w", err))
+	}
+
+	for _, state := range tables {
+		state.SnapshotName = name
+		state.SnapshotLSN = lsn
+		state.StartLSN = lsn
+
+		var after any
+		for {
+			rows, err := tx.ReadChunk(txCtx, state.Schema, state.Name, state.KeyColumn, after, s.config.ChunkSize)
+			if err != nil {
+				return s.txError(ctx, txCtx, fmt.Errorf("read %s: %w", state, err))
+			}
+			if err := s.emit(ctx, state, rows, lsn, sink); err != nil {
+				return err
+			}
+			if len(rows) < s.config.ChunkSize {
+				break
+			}
+			after = rows[len(rows)-1][state.KeyColumn]
+		}
+		s.finish(state)
+	}
+
+	if s.holdSnapshot {
+		s.mu.Lock()
+		s.release = release
+		s.mu.Unlock()
+		release = nil
+	}
+	return nil
+}
+
+// runChunked reads each table in key order, one transaction per chunk.
+func (s *Snapshotter) runChunked(ctx context.Context, tables []*tableState, sink Sink) error {
+	for _, state := range tables {
+		var after any
+		for {
+			if err := s.waitForWindow(ctx); err != nil {
+				return err
+			}
+
+			rows, lsn, err := s.readChunk(ctx, state, after)
+			if err != nil {
+				return err
+			}
+			if after == nil {
+				state.StartLSN = lsn
+			}
+			if err := s.emit(ctx, state, rows, lsn, sink); err != nil {
+				return err
+			}
+			if len(rows) < s.config.ChunkSize {
+				break
+			}
+			after = rows[len(rows)-1][state.KeyColumn]
+		}
+		s.finish(state)
+	}
+	return nil
+}
+
+// Backfill copies the rows of a table in key ranges again, one transaction
+// per chunk as in chunked mode, to repair rows a verification found missing
+// or differing. nil ranges copy the whole table. The rows are written as
+// snapshot rows with the current LSN, so readers deduplicating by key keep
+// them over the rows they repair.
+func (s *Snapshotter) Backfill(ctx context.Context, table verify.Table, ranges []verify.KeyRange, sink Sink) error {
+	if ranges == nil {
+		ranges = []verify.KeyRange{{}}
+	}
+
+	state := &tableState{Table: table}
+	for _, r := range ranges {
+		after := r.After
+		for {
+			if err := s.waitForWindow(ctx); err != nil {
+				return err
+			}
+
+			rows, lsn, err := s.readChunk(ctx, state, after)
+			if err != nil {
+				return err
+			}
+			more := len(rows) == s.config.ChunkSize
+			if r.Through != nil {
+				n := 0
+				for n < len(rows) && verify.CompareKeys(rows[n][table.KeyColumn], r.Through) <= 0 {
+					n++
+				}
+				if n < len(rows) {
+					rows, more = rows[:n], false
+				}
+			}
+			if err := s.emit(ctx, state, rows, lsn, sink); err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			after = rows[len(rows)-1][table.KeyColumn]
+		}
+	}
+
+	s.logger.Info("table backfill re-run", "table", table.String(), "ranges", len(ranges))
+	return nil
+}
+
+// readChunk reads one chunk in its own transaction.
+func (s *Snapshotter) readChunk(ctx context.Context, state *tableState, after any) ([]map[string]any, string, error) {
+	txCtx, cancel := s.withTxTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.reader.Begin(txCtx)
+	if err != nil {
+		return nil, "", s.txError(ctx, txCtx, fmt.Errorf("begin chunk transaction: %w", err))
+	}
+	defer func() { _ = tx.Close() }()
+
+	_, lsn, err := tx.Position(txCtx)
+	if err != nil {
+		return nil, "", s.txError(ctx, txCtx, fmt.Errorf("read position: %w", err))
+	}
+
+	rows, err := tx.ReadChunk(txCtx, state.Schema, state.Name, state.KeyColumn, after, s.config.ChunkSize)
+	if err != nil {
+		return nil, "", s.txError(ctx, txCtx, fmt.Errorf("read %s: %w", state, err))
+	}
+	return rows, lsn, nil
+}
+
+// emit converts rows to insert events and writes them to sink, skipping rows
+// the stream has already changed. The lock is held while writing so a change
+// observed meanwhile is written after the snapshot row it supersedes.
+func (s *Snapshotter) emit(ctx context.Context, state *tableState, rows []map[string]any, lsn string, sink Sink) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	events := make([]cdc.Event, 0, len(rows))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range rows {
+		if state.touched != nil {
+			if _, ok := state.touched[keyString(row[state.KeyColumn])]; ok {
+				continue
+			}
+		}
+		events = append(events, cdc.Event{
+			LSN:        lsn,
+			Timestamp:  now,
+			Schema:     state.Schema,
+			Table:      state.Name,
+			Operation:  cdc.OperationInsert,
+			After:      row,
+			KeyColumns: []string{state.KeyColumn},
+			Metadata:   map[string]any{MetadataSnapshot: true},
+		})
+	}
+
+	if skipped := len(rows) - len(events); skipped > 0 {
+		s.logger.Debug("skipped snapshot rows changed by the stream",
+			"table", state.String(),
+			"skipped", skipped,
+		)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err := sink(ctx, events); err != nil {
+		return fmt.Errorf("write snapshot rows of %s: %w", state, err)
+	}
+	return nil
+}
+
+// finish marks a table as copied and stops tracking its keys.
+func (s *Snapshotter) finish(state *tableState) {
+	s.mu.Lock()
+	state.done = true
+	state.touched = nil
+	s.mu.Unlock()
+
+	s.logger.Info("table snapshot complete", "table", state.String())
+	if s.onTableComplete != nil {
+		s.onTableComplete(state.Table)
+	}
+}
+
+// waitForWindow blocks until the snapshot window is open.
+func (s *Snapshotter) waitForWindow(ctx context.Context) error {
+	if s.config.Window == nil {
+		return nil
+	}
+
+	wait := s.config.Window.Until(s.now())
+	if wait <= 0 {
+		return nil
+	}
+
+	s.logger.Info("waiting for snapshot window", "window", s.config.Window, "wait", wait)
+	return s.sleep(ctx, wait)
+}
+
+// withTxTimeout bounds a transaction by MaxTxDuration.
+func (s *Snapshotter) withTxTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.config.MaxTxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.config.MaxTxDuration)
+}
+
+// txError reports a transaction that ran out of time as ErrTransactionTooLong.
+func (s *Snapshotter) txError(ctx, txCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(txCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w (%s): %v", ErrTransactionTooLong, s.config.MaxTxDuration, err)
+	}
+	return err
+}
+
+// splitTable splits a qualified table name, defaulting to the public schema.
+func splitTable(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return "public", name
+}
+
+// keyString normalizes a key value so stream and snapshot keys compare equal.
+// Streamed numbers may be decoded as float64 while the snapshot reads int64.
+func keyString(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,395 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/verify"
+)
+
+// fakeReader serves rows of a single-key table from memory.
+type fakeReader struct {
+	rows   map[string][]map[string]any // qualified table -> rows in key order
+	begins int
+	closes int
+
+	// onRead runs before every chunk read.
+	onRead func(ctx context.Context) error
+}
+
+func (r *fakeReader) KeyColumn(_ context.Context, _, _ string) (string, error) {
+	return "id", nil
+}
+
+func (r *fakeReader) Begin(_ context.Context) (Tx, error) {
+	r.begins++
+	return &fakeTx{reader: r}, nil
+}
+
+type fakeTx struct {
+	reader *fakeReader
+}
+
+func (t *fakeTx) Position(_ context.Context) (string, string, error) {
+	return "00000003-1", "0/16B3748", nil
+}
+
+func (t *fakeTx) ReadChunk(ctx context.Context, schema, table, key string, after any, limit int) ([]map[string]any, error) {
+	if t.reader.onRead != nil {
+		if err := t.reader.onRead(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []map[string]any
+	for _, row := range t.reader.rows[schema+"."+table] {
+		if after != nil && row[key].(int64) <= after.(int64) {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+func (t *fakeTx) Close() error {
+	t.reader.closes++
+	return nil
+}
+
+func tableRows(ids ...int64) []map[string]any {
+	rows := make([]map[string]any, len(ids))
+	for i, id := range ids {
+		rows[i] = map[string]any{"id": id, "name": "row"}
+	}
+	return rows
+}
+
+// collect returns a sink that records the keys of emitted rows.
+func collect(keys *[]int64) Sink {
+	return func(_ context.Context, events []cdc.Event) error {
+		for _, e := range events {
+			if e.Operation != cdc.OperationInsert || e.Metadata[MetadataSnapshot] != true {
+				return errors.New("unexpected snapshot event")
+			}
+			*keys = append(*keys, e.After["id"].(int64))
+		}
+		return nil
+	}
+}
+
+func newTestSnapshotter(t *testing.T, cfg Config, reader Reader) *Snapshotter {
+	t.Helper()
+	s, err := New(cfg, reader, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"defaults", func(*Config) {}, false},
+		{"lazy chunked", func(c *Config) { c.Mode, c.Order = ModeChunked, OrderStreamFirst }, false},
+		{"lazy consistent", func(c *Config) { c.Order = OrderStreamFirst }, true},
+		{"invalid mode", func(c *Config) { c.Mode = "bogus" }, true},
+		{"invalid order", func(c *Config) { c.Order = "bogus" }, true},
+		{"when needed", func(c *Config) { c.Trigger = TriggerWhenNeeded }, false},
+		{"invalid trigger", func(c *Config) { c.Trigger = "always" }, true},
+		{"zero chunk size", func(c *Config) { c.ChunkSize = 0 }, true},
+		{"negative cap", func(c *Config) { c.MaxTxDuration = -time.Second }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTrigger(t *testing.T) {
+	if trigger, err := ParseTrigger(""); err != nil || trigger != TriggerInitial {
+		t.Errorf("ParseTrigger(\"\") = %q, %v; want initial", trigger, err)
+	}
+	if trigger, err := ParseTrigger("when_needed"); err != nil || trigger != TriggerWhenNeeded {
+		t.Errorf("ParseTrigger(when_needed) = %q, %v", trigger, err)
+	}
+	if _, err := ParseTrigger("consistent"); err == nil {
+		t.Error("expected an error for a read mode")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	overnight, err := ParseWindow("22:00-04:30")
+	if err != nil {
+		t.Fatalf("ParseWindow() error = %v", err)
+	}
+	if got := overnight.String(); got != "22:00-04:30" {
+		t.Errorf("String() = %q", got)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want time.Duration
+	}{
+		{at(23, 0), 0},
+		{at(2, 0), 0},
+		{at(4, 30), 17*time.Hour + 30*time.Minute},
+		{at(12, 0), 10 * time.Hour},
+		{at(22, 0), 0},
+	}
+	for _, tt := range tests {
+		if got := overnight.Until(tt.at); got != tt.want {
+			t.Errorf("Until(%s) = %s, want %s", tt.at.Format("15:04"), got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"22:00", "25:00-01:00", "01:00-01:00"} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("ParseWindow(%q) expected error", s)
+		}
+	}
+	if w, err := ParseWindow(""); w != nil || err != nil {
+		t.Errorf("ParseWindow(\"\") = %v, %v; want nil, nil", w, err)
+	}
+}
+
+func TestSnapshotter_LazySkipsRowsChangedByStream(t *testing.T) {
+	reader := &fakeReader{rows: map[string][]map[string]any{
+		"public.orders": tableRows(1, 2, 3, 4, 5),
+	}}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"orders"}
+	cfg.Mode = ModeChunked
+	cfg.Order = OrderStreamFirst
+	cfg.ChunkSize = 2
+
+	s := newTestSnapshotter(t, cfg, reader)
+	ctx := context.Background()
+	if err := s.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	// The stream delivers an update to key 3 before its chunk is read, and a
+	// delete of key 5 while the first chunk is being read. Streamed numbers
+	// arrive as float64.
+	s.Observe(cdc.Event{Schema: "public", Table: "orders", Operation: cdc.OperationUpdate,
+		After: map[string]any{"id": float64(3), "name": "updated"}})
+	reader.onRead = func(context.Context) error {
+		s.Observe(cdc.Event{Schema: "public", Table: "orders", Operation: cdc.OperationDelete,
+			Before: map[string]any{"id": float64(5)}})
+		return nil
+	}
+
+	var keys []int64
+	tables, err := s.Run(ctx, collect(&keys))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	if want := []int64{1, 2, 4}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("emitted keys = %v, want %v", keys, want)
+	}
+	if reader.begins != 3 {
+		t.Errorf("transactions = %d, want one per chunk (3)", reader.begins)
+	}
+	if len(tables) != 1 || tables[0].String() != "public.orders" || tables[0].KeyColumn != "id" {
+		t.Errorf("Run() tables = %+v", tables)
+	}
+
+	// Changes after the table completed are no longer tracked.
+	s.Observe(cdc.Event{Schema: "public", Table: "orders", After: map[string]any{"id": float64(1)}})
+}
+
+func TestSnapshotter_ScheduledWindowPausesChunks(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+
+	reader := &fakeReader{
+		rows: map[string][]map[string]any{"public.events": tableRows(1, 2, 3, 4, 5, 6)},
+		// Each chunk takes 40 minutes, so the one-hour window closes mid-table.
+		onRead: func(context.Context) error {
+			now = now.Add(40 * time.Minute)
+			return nil
+		},
+	}
+
+	window, err := ParseWindow("22:00-23:00")
+	if err != nil {
+		t.Fatalf("ParseWindow() error = %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"public.events"}
+	cfg.Mode = ModeChunked
+	cfg.Window = window
+	cfg.ChunkSize = 2
+
+	s := newTestSnapshotter(t, cfg, reader)
+	s.now = func() time.Time { return now }
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := s.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	var keys []int64
+	tables, err := s.Run(ctx, collect(&keys))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// Chunks have no common snapshot; the table starts at its first chunk
+	if len(tables) != 1 || tables[0].SnapshotLSN != "" || tables[0].StartLSN != "0/16B3748" {
+		t.Errorf("tables = %+v, want one started at the first chunk's LSN", tables)
+	}
+
+	// Wait until 22:00; read two chunks (until 23:20); wait until 22:00 the
+	// next day for the remaining rows.
+	wantSleeps := []time.Duration{10 * time.Hour, 22*time.Hour + 40*time.Minute}
+	if !reflect.DeepEqual(sleeps, wantSleeps) {
+		t.Errorf("sleeps = %v, want %v", sleeps, wantSleeps)
+	}
+	if want := []int64{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("emitted keys = %v, want %v", keys, want)
+	}
+}
+
+func TestSnapshotter_ConsistentSnapshot(t *testing.T) {
+	reader := &fakeReader{rows: map[string][]map[string]any{
+		"public.a": tableRows(1, 2, 3),
+		"public.b": tableRows(10),
+	}}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"public.a", "public.b"}
+	cfg.ChunkSize = 2
+
+	s := newTestSnapshotter(t, cfg, reader)
+	ctx := context.Background()
+	if err := s.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	var keys []int64
+	tables, err := s.Run(ctx, collect(&keys))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reader.begins != 1 {
+		t.Errorf("transactions = %d, want 1", reader.begins)
+	}
+	if want := []int64{1, 2, 3, 10}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("emitted keys = %v, want %v", keys, want)
+	}
+	for _, table := range tables {
+		if table.SnapshotName != "00000003-1" || table.SnapshotLSN != "0/16B3748" {
+			t.Errorf("%s snapshot = %q at %q, want the exported snapshot", table, table.SnapshotName, table.SnapshotLSN)
+		}
+		if table.StartLSN != table.SnapshotLSN {
+			t.Errorf("%s start LSN = %q, want the snapshot LSN", table, table.StartLSN)
+		}
+	}
+}
+
+func TestSnapshotter_HoldSnapshotUntilReleased(t *testing.T) {
+	reader := &fakeReader{rows: map[string][]map[string]any{"public.a": tableRows(1, 2)}}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"public.a"}
+
+	s := newTestSnapshotter(t, cfg, reader)
+	s.SetHoldSnapshot(true)
+	ctx := context.Background()
+	if err := s.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	if _, err := s.Run(ctx, collect(new([]int64))); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if reader.closes != 0 {
+		t.Fatal("snapshot transaction closed before Release, its exported snapshot can no longer be imported")
+	}
+
+	s.Release()
+	s.Release()
+	if reader.closes != 1 {
+		t.Errorf("transaction closed %d times, want once", reader.closes)
+	}
+}
+
+func TestSnapshotter_TransactionDurationCap(t *testing.T) {
+	reader := &fakeReader{
+		rows: map[string][]map[string]any{"public.hot": tableRows(1)},
+		onRead: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"public.hot"}
+	cfg.MaxTxDuration = 10 * time.Millisecond
+
+	s := newTestSnapshotter(t, cfg, reader)
+	ctx := context.Background()
+	if err := s.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	_, err := s.Run(ctx, collect(new([]int64)))
+	if !errors.Is(err, ErrTransactionTooLong) {
+		t.Errorf("Run() error = %v, want ErrTransactionTooLong", err)
+	}
+}
+
+func TestSnapshotter_BackfillReRunsKeyRanges(t *testing.T) {
+	reader := &fakeReader{rows: map[string][]map[string]any{"public.a": tableRows(1, 2, 3, 4, 5, 6, 7, 8)}}
+	cfg := DefaultConfig()
+	cfg.Tables = []string{"public.a"}
+	cfg.ChunkSize = 2
+	s := newTestSnapshotter(t, cfg, reader)
+
+	table := verify.Table{Schema: "public", Name: "a", KeyColumn: "id"}
+	ranges := []verify.KeyRange{
+		{Through: int64(2)},
+		{After: int64(4), Through: int64(7)},
+	}
+	var keys []int64
+	if err := s.Backfill(context.Background(), table, ranges, collect(&keys)); err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if want := []int64{1, 2, 5, 6, 7}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("backfilled keys = %v, want %v", keys, want)
+	}
+
+	keys = nil
+	if err := s.Backfill(context.Background(), table, nil, collect(&keys)); err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if len(keys) != 8 {
+		t.Errorf("backfilled %d rows, want the whole table", len(keys))
+	}
+}
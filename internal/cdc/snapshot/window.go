@@ -0,0 +1,91 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily UTC time range, such as 22:00-04:00, during which a
+// snapshot may read from the source. A window may wrap past midnight.
+type Window struct {
+	// Start is the offset from midnight at which the window opens.
+	Start time.Duration
+
+	// End is the offset from midnight at which the window closes.
+	End time.Duration
+}
+
+// ParseWindow parses a window in "HH:MM-HH:MM" form. An empty string returns nil.
+func ParseWindow(s string) (*Window, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid snapshot window %q (want HH:MM-HH:MM)", s)
+	}
+
+	var w Window
+	var err error
+	if w.Start, err = parseClock(start); err != nil {
+		return nil, fmt.Errorf("invalid snapshot window %q: %w", s, err)
+	}
+	if w.End, err = parseClock(end); err != nil {
+		return nil, fmt.Errorf("invalid snapshot window %q: %w", s, err)
+	}
+	if w.Start == w.End {
+		return nil, fmt.Errorf("invalid snapshot window %q: start and end are equal", s)
+	}
+	return &w, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (w *Window) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// Until returns how long until the window next opens, or 0 if t is inside it.
+func (w *Window) Until(t time.Time) time.Duration {
+	if w.Contains(t) {
+		return 0
+	}
+	wait := w.Start - sinceMidnight(t)
+	if wait < 0 {
+		wait += 24 * time.Hour
+	}
+	return wait
+}
+
+// String returns the window in "HH:MM-HH:MM" form.
+func (w *Window) String() string {
+	if w == nil {
+		return "any"
+	}
+	return formatClock(w.Start) + "-" + formatClock(w.End)
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+}
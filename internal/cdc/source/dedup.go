@@ -0,0 +1,185 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// MetadataSequence is the event metadata key holding a change's sequence
+// among the changes that share its LSN.
+const MetadataSequence = "sequence"
+
+// Position is a change's place in a replication stream. Plugins that report
+// the same LSN for every change of a transaction are ordered by Seq.
+type Position struct {
+	LSN cdc.LSN
+	Seq uint64
+}
+
+// After reports whether p comes strictly after o.
+func (p Position) After(o Position) bool {
+	if p.LSN != o.LSN {
+		return p.LSN > o.LSN
+	}
+	return p.Seq > o.Seq
+}
+
+// String returns the position as "LSN#seq".
+func (p Position) String() string {
+	return fmt.Sprintf("%s#%d", p.LSN, p.Seq)
+}
+
+// EventPosition returns the replication position of an event.
+func EventPosition(event cdc.Event) (Position, error) {
+	lsn, err := cdc.ParseLSN(event.LSN)
+	if err != nil {
+		return Position{}, err
+	}
+	pos := Position{LSN: lsn}
+	switch seq := event.Metadata[MetadataSequence].(type) {
+	case uint64:
+		pos.Seq = seq
+	case int:
+		pos.Seq = uint64(seq)
+	case int64:
+		pos.Seq = uint64(seq)
+	case float64:
+		pos.Seq = uint64(seq)
+	}
+	return pos, nil
+}
+
+// HighWaterMarkStore persists a deduplication high-water-mark per slot.
+type HighWaterMarkStore interface {
+	// LoadHighWaterMark returns the slot's high-water-mark, or nil if none is stored.
+	LoadHighWaterMark(ctx context.Context, slot string) (*Position, error)
+
+	// SaveHighWaterMark stores the slot's high-water-mark.
+	SaveHighWaterMark(ctx context.Context, slot string, pos Position) error
+}
+
+// Acknowledger is implemented by sources that want to know when changes
+// have been durably processed downstream.
+type Acknowledger interface {
+	// Acknowledge reports that event and every change before it are checkpointed.
+	Acknowledge(ctx context.Context, event cdc.Event) error
+}
+
+// Deduplicator drops changes a source redelivers after a reconnect, using the
+// replication position as a monotonic guard.
+//
+// Changes are admitted only if they come after the last admitted position.
+// The persisted high-water-mark only advances on Commit, once changes are
+// durably processed, so a crash never causes unprocessed changes to be dropped.
+type Deduplicator struct {
+	slot  string
+	store HighWaterMarkStore
+
+	mu        sync.Mutex
+	admitted  *Position
+	committed *Position
+	lastLSN   cdc.LSN
+	seq       uint64
+	seen      bool
+}
+
+// NewDeduplicator creates a deduplicator for a replication slot. The store
+// may be nil, in which case the guard only holds within the process.
+func NewDeduplicator(slot string, store HighWaterMarkStore) *Deduplicator {
+	return &Deduplicator{slot: slot, store: store}
+}
+
+// Load restores the persisted high-water-mark.
+func (d *Deduplicator) Load(ctx context.Context) error {
+	if d.store == nil {
+		return nil
+	}
+
+	hwm, err := d.store.LoadHighWaterMark(ctx, d.slot)
+	if err != nil {
+		return fmt.Errorf("load high-water-mark: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.committed = hwm
+	d.admitted = hwm
+	return nil
+}
+
+// Reset rewinds the guard to the committed high-water-mark and restarts
+// sequence numbering. Call it whenever the stream is (re)started: changes
+// admitted but never committed may have been lost and must be redelivered,
+// and a redelivered transaction is numbered from zero again.
+func (d *Deduplicator) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.admitted = d.committed
+	d.seen = false
+	d.seq = 0
+}
+
+// Observe assigns the next position for a change at lsn and reports whether
+// the change should be admitted. Changes with an unparsable LSN are admitted.
+func (d *Deduplicator) Observe(lsn string) (Position, bool, error) {
+	parsed, err := cdc.ParseLSN(lsn)
+	if err != nil {
+		return Position{}, true, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen && parsed == d.lastLSN {
+		d.seq++
+	} else {
+		d.seq = 0
+	}
+	d.lastLSN = parsed
+	d.seen = true
+
+	pos := Position{LSN: parsed, Seq: d.seq}
+	if d.admitted != nil && !pos.After(*d.admitted) {
+		return pos, false, nil
+	}
+	d.admitted = &pos
+	return pos, true, nil
+}
+
+// HighWaterMark returns the last committed position, or nil if none.
+func (d *Deduplicator) HighWaterMark() *Position {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.committed == nil {
+		return nil
+	}
+	pos := *d.committed
+	return &pos
+}
+
+// Commit advances and persists the high-water-mark to pos. Positions at or
+// before the current high-water-mark are ignored.
+func (d *Deduplicator) Commit(ctx context.Context, pos Position) error {
+	d.mu.Lock()
+	if d.committed != nil && !pos.After(*d.committed) {
+		d.mu.Unlock()
+		return nil
+	}
+	d.mu.Unlock()
+
+	if d.store != nil {
+		if err := d.store.SaveHighWaterMark(ctx, d.slot, pos); err != nil {
+			return fmt.Errorf("save high-water-mark: %w", err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.committed == nil || pos.After(*d.committed) {
+		d.committed = &pos
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// memoryHWMStore keeps high-water-marks in memory.
+type memoryHWMStore struct {
+	marks map[string]Position
+	saves int
+}
+
+func newMemoryHWMStore() *memoryHWMStore {
+	return &memoryHWMStore{marks: map[string]Position{}}
+}
+
+func (s *memoryHWMStore) LoadHighWaterMark(_ context.Context, slot string) (*Position, error) {
+	pos, ok := s.marks[slot]
+	if !ok {
+		return nil, nil
+	}
+	return &pos, nil
+}
+
+func (s *memoryHWMStore) SaveHighWaterMark(_ context.Context, slot string, pos Position) error {
+	s.marks[slot] = pos
+	s.saves++
+	return nil
+}
+
+func mustObserve(t *testing.T, d *Deduplicator, lsn string) (Position, bool) {
+	t.Helper()
+	pos, admit, err := d.Observe(lsn)
+	if err != nil {
+		t.Fatalf("Observe(%q) error = %v", lsn, err)
+	}
+	return pos, admit
+}
+
+func TestDeduplicator_DropsRedeliveredChanges(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryHWMStore()
+	store.marks["slot"] = Position{LSN: 0x200}
+
+	d := NewDeduplicator("slot", store)
+	if err := d.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		lsn   string
+		admit bool
+	}{
+		{"0/100", false}, // below the high-water-mark
+		{"0/200", false}, // equal to the high-water-mark
+		{"0/300", true},
+		{"0/300", true}, // same LSN, next sequence
+		{"0/280", false},
+		{"0/400", true},
+	}
+
+	for _, tt := range tests {
+		if _, admit := mustObserve(t, d, tt.lsn); admit != tt.admit {
+			t.Errorf("Observe(%q) admit = %v, want %v", tt.lsn, admit, tt.admit)
+		}
+	}
+}
+
+func TestDeduplicator_HighWaterMarkSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryHWMStore()
+
+	first := NewDeduplicator("slot", store)
+	if err := first.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, lsn := range []string{"0/10", "0/20", "0/30"} {
+		if _, admit := mustObserve(t, first, lsn); !admit {
+			t.Fatalf("Observe(%q) dropped a new change", lsn)
+		}
+	}
+	// Only changes up to 0/20 were checkpointed before the crash.
+	if err := first.Commit(ctx, Position{LSN: 0x20}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	second := NewDeduplicator("slot", store)
+	if err := second.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	second.Reset()
+
+	for lsn, want := range map[string]bool{"0/10": false, "0/20": false} {
+		if _, admit := mustObserve(t, second, lsn); admit != want {
+			t.Errorf("after restart Observe(%q) admit = %v, want %v", lsn, admit, want)
+		}
+	}
+	if _, admit := mustObserve(t, second, "0/30"); !admit {
+		t.Error("uncommitted change 0/30 must be redelivered after restart")
+	}
+}
+
+func TestDeduplicator_SharedLSNUsesSequence(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryHWMStore()
+	d := NewDeduplicator("slot", store)
+
+	// A plugin reporting the commit LSN for every change in a transaction.
+	var positions []Position
+	for i := 0; i < 3; i++ {
+		pos, admit := mustObserve(t, d, "0/50")
+		if !admit {
+			t.Fatalf("change %d of the transaction was dropped", i)
+		}
+		positions = append(positions, pos)
+	}
+	if positions[2].Seq != 2 {
+		t.Fatalf("third change seq = %d, want 2", positions[2].Seq)
+	}
+
+	// Crash after the first two changes were checkpointed.
+	if err := d.Commit(ctx, positions[1]); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	d.Reset()
+
+	for i, want := range []bool{false, false, true} {
+		if _, admit := mustObserve(t, d, "0/50"); admit != want {
+			t.Errorf("redelivered change %d admit = %v, want %v", i, admit, want)
+		}
+	}
+}
+
+func TestDeduplicator_CommitIgnoresOlderPositions(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryHWMStore()
+	d := NewDeduplicator("slot", store)
+
+	if err := d.Commit(ctx, Position{LSN: 0x100}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := d.Commit(ctx, Position{LSN: 0x80}); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if got := store.marks["slot"]; got.LSN != 0x100 {
+		t.Errorf("persisted high-water-mark = %s, want 0/100", got)
+	}
+	if store.saves != 1 {
+		t.Errorf("saves = %d, want 1", store.saves)
+	}
+}
+
+func TestEventPosition(t *testing.T) {
+	event := cdc.Event{LSN: "0/1A", Metadata: map[string]any{MetadataSequence: float64(3)}}
+	pos, err := EventPosition(event)
+	if err != nil {
+		t.Fatalf("EventPosition() error = %v", err)
+	}
+	if pos != (Position{LSN: 0x1A, Seq: 3}) {
+		t.Errorf("EventPosition() = %s, want 0/1A#3", pos)
+	}
+
+	if _, err := EventPosition(cdc.Event{LSN: "bogus"}); err == nil {
+		t.Error("expected error for invalid LSN")
+	}
+}
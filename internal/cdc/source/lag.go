@@ -0,0 +1,89 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// ErrLagUnknown is returned by LagMonitor.Lag before the first poll.
+var ErrLagUnknown = errors.New("replication lag not polled yet")
+
+// DefaultLagInterval is the poll interval used when none is configured.
+const DefaultLagInterval = 15 * time.Second
+
+// LagMonitor polls a LagReporter on an interval and publishes the result as
+// the replication lag gauge, so the metric stays current whether or not
+// anything probes health. Health checks read the last polled value instead
+// of querying the source themselves.
+type LagMonitor struct {
+	reporter LagReporter
+	source   string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu  sync.RWMutex
+	lag int64
+	err error
+}
+
+// NewLagMonitor creates a monitor publishing the lag of the named source.
+func NewLagMonitor(reporter LagReporter, source string, interval time.Duration, logger *slog.Logger) *LagMonitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if interval <= 0 {
+		interval = DefaultLagInterval
+	}
+	return &LagMonitor{
+		reporter: reporter,
+		source:   source,
+		interval: interval,
+		logger:   logger.With("component", "lag-monitor"),
+		err:      ErrLagUnknown,
+	}
+}
+
+// Run polls the lag immediately and then every interval until ctx is done.
+func (m *LagMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll queries the lag once and records the result.
+func (m *LagMonitor) poll(ctx context.Context) {
+	lag, err := m.reporter.ReplicationLag(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		m.logger.Warn("failed to poll replication lag", "error", err)
+	} else {
+		metrics.CDCReplicationLagBytes.WithLabelValues(m.source).Set(float64(lag))
+	}
+
+	m.mu.Lock()
+	m.lag, m.err = lag, err
+	m.mu.Unlock()
+}
+
+// Lag returns the lag in bytes from the last poll, or the error it failed with.
+func (m *LagMonitor) Lag() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lag, m.err
+}
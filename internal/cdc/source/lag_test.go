@@ -0,0 +1,70 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// fakeLagReporter returns a growing lag and counts its queries.
+type fakeLagReporter struct {
+	calls atomic.Int64
+	err   error
+}
+
+func (r *fakeLagReporter) ReplicationLag(context.Context) (int64, error) {
+	n := r.calls.Add(1)
+	return n * 100, r.err
+}
+
+func TestLagMonitor_PublishesPolledLag(t *testing.T) {
+	reporter := &fakeLagReporter{}
+	m := NewLagMonitor(reporter, "lag-test", 5*time.Millisecond, nil)
+
+	if _, err := m.Lag(); !errors.Is(err, ErrLagUnknown) {
+		t.Fatalf("Lag() before polling error = %v, want ErrLagUnknown", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for reporter.calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	lag, err := m.Lag()
+	if err != nil || lag < 300 {
+		t.Fatalf("Lag() = %d, %v; want at least 300 after three polls", lag, err)
+	}
+
+	var metric dto.Metric
+	if err := metrics.CDCReplicationLagBytes.WithLabelValues("lag-test").Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := int64(metric.GetGauge().GetValue()); got != lag {
+		t.Errorf("gauge = %d, want %d", got, lag)
+	}
+}
+
+func TestLagMonitor_ReportsPollError(t *testing.T) {
+	reporter := &fakeLagReporter{err: errors.New("connection refused")}
+	m := NewLagMonitor(reporter, "lag-test-error", time.Minute, nil)
+
+	m.poll(context.Background())
+	if _, err := m.Lag(); err == nil || errors.Is(err, ErrLagUnknown) {
+		t.Errorf("Lag() error = %v, want the poll error", err)
+	}
+}
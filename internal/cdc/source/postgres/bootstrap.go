@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// decodingPlugin is the logical decoding output plugin replication slots are
+// created with. It must match the plugin arguments pgstream streams with,
+// which are wal2json's; a pgoutput slot rejects them when replication starts.
+const decodingPlugin = "wal2json"
+
+// usesPublication reports whether a decoding plugin reads its changes
+// through a publication. wal2json decodes every table and ignores
+// publications; pgoutput requires one.
+func usesPublication(plugin string) bool {
+	return plugin == "pgoutput"
+}
+
+// Bootstrap checks that the reader's replication slot exists, and its
+// publication when the decoding plugin uses one. With AutoCreate set,
+// missing ones are created: the publication for the configured tables (or
+// all tables when none are configured) and the slot with the decoding plugin
+// the reader consumes. Existing ones are left untouched.
+func (r *Reader) Bootstrap(ctx context.Context) error {
+	db, err := sql.Open("pgx", r.config.ConnectionURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	defer db.Close()
+
+	if usesPublication(decodingPlugin) {
+		if err := r.ensurePublication(ctx, db); err != nil {
+			return err
+		}
+	} else {
+		r.logger.Debug("decoding plugin does not use a publication",
+			"plugin", decodingPlugin,
+			"publication", r.config.PublicationName,
+		)
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)`,
+		r.config.SlotName,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("query replication slot: %w", err)
+	}
+	switch {
+	case exists:
+		r.logger.Info("using existing replication slot", "slot", r.config.SlotName)
+	case !r.config.AutoCreate:
+		return fmt.Errorf("%w: %s (set PHILOTES_CDC_AUTO_CREATE_SLOT to create it)", ErrSlotNotFound, r.config.SlotName)
+	default:
+		if _, err := db.ExecContext(ctx,
+			`SELECT pg_create_logical_replication_slot($1, $2)`,
+			r.config.SlotName, decodingPlugin,
+		); err != nil {
+			return fmt.Errorf("create replication slot: %w", err)
+		}
+		r.logger.Info("created replication slot", "slot", r.config.SlotName, "plugin", decodingPlugin)
+	}
+
+	return nil
+}
+
+// ensurePublication checks that the reader's publication exists, creating
+// it when AutoCreate is set.
+func (r *Reader) ensurePublication(ctx context.Context, db *sql.DB) error {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = $1)`,
+		r.config.PublicationName,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("query publication: %w", err)
+	}
+	switch {
+	case exists:
+		r.logger.Info("using existing publication", "publication", r.config.PublicationName)
+	case !r.config.AutoCreate:
+		return fmt.Errorf("%w: %s (set PHILOTES_CDC_AUTO_CREATE_SLOT to create it)", ErrPublicationNotFound, r.config.PublicationName)
+	default:
+		if _, err := db.ExecContext(ctx, createPublicationSQL(r.config.PublicationName, r.config.Tables)); err != nil {
+			return fmt.Errorf("create publication: %w", err)
+		}
+		r.logger.Info("created publication", "publication", r.config.PublicationName, "tables", publicationScope(r.config.Tables))
+	}
+	return nil
+}
+
+// createPublicationSQL returns the statement creating a publication for
+// tables, given as "table" or "schema.table", or for all tables when empty.
+func createPublicationSQL(name string, tables []string) string {
+	stmt := "CREATE PUBLICATION " + pgx.Identifier{name}.Sanitize()
+	if len(tables) == 0 {
+		return stmt + " FOR ALL TABLES"
+	}
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = pgx.Identifier(strings.SplitN(table, ".", 2)).Sanitize()
+	}
+	return stmt + " FOR TABLE " + strings.Join(quoted, ", ")
+}
+
+// publicationScope describes the tables a created publication covers.
+func publicationScope(tables []string) string {
+	if len(tables) == 0 {
+		return "all"
+	}
+	return strings.Join(tables, ",")
+}
+
+// Ensure Reader implements source.Bootstrapper interface.
+var _ source.Bootstrapper = (*Reader)(nil)
@@ -0,0 +1,47 @@
+package postgres
+
+import "testing"
+
+func TestCreatePublicationSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []string
+		want   string
+	}{
+		{"all tables", nil, `CREATE PUBLICATION "philotes_pub" FOR ALL TABLES`},
+		{
+			"configured tables",
+			[]string{"orders", "sales.Invoices"},
+			`CREATE PUBLICATION "philotes_pub" FOR TABLE "orders", "sales"."Invoices"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createPublicationSQL("philotes_pub", tt.tables); got != tt.want {
+				t.Errorf("createPublicationSQL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingTables(t *testing.T) {
+	published := []string{"public.orders", "sales.Invoices"}
+
+	if missing := missingTables([]string{"orders", "sales.Invoices"}, published); len(missing) != 0 {
+		t.Errorf("missingTables() = %v, want none", missing)
+	}
+	missing := missingTables([]string{"orders", "sales.invoices", "customers"}, published)
+	if len(missing) != 2 || missing[0] != "sales.invoices" || missing[1] != "customers" {
+		t.Errorf("missingTables() = %v, want [sales.invoices customers]", missing)
+	}
+}
+
+func TestUsesPublication(t *testing.T) {
+	if usesPublication(decodingPlugin) {
+		t.Errorf("usesPublication(%s) = true, the reader's plugin ignores publications", decodingPlugin)
+	}
+	if !usesPublication("pgoutput") {
+		t.Error("usesPublication(pgoutput) = false, want true")
+	}
+}
@@ -23,6 +23,10 @@ type Config struct {
 	// Tables is a list of tables to capture (empty means all tables in publication).
 	Tables []string
 
+	// AutoCreate creates the publication and replication slot on Bootstrap
+	// when they do not exist.
+	AutoCreate bool
+
 	// ReconnectInterval is the interval between reconnection attempts.
 	ReconnectInterval time.Duration
 
@@ -31,6 +35,14 @@ type Config struct {
 
 	// EventBufferSize is the size of the internal event buffer.
 	EventBufferSize int
+
+	// UnchangedToast is how the unchanged TOAST columns of an UPDATE are
+	// restored.
+	UnchangedToast ToastStrategy
+
+	// JSONAsStruct decodes json and jsonb objects and arrays into nested
+	// values instead of JSON text.
+	JSONAsStruct bool
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -44,6 +56,7 @@ func DefaultConfig() Config {
 		ReconnectInterval:    5 * time.Second,
 		MaxReconnectAttempts: 0, // unlimited
 		EventBufferSize:      1000,
+		UnchangedToast:       ToastIdentity,
 	}
 }
 
@@ -58,5 +71,10 @@ func (c *Config) Validate() error {
 	if c.PublicationName == "" {
 		return ErrMissingPublicationName
 	}
+	if c.UnchangedToast != "" {
+		if _, err := ParseToastStrategy(string(c.UnchangedToast)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
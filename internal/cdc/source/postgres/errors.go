@@ -21,6 +21,12 @@ var (
 	// ErrConnectionFailed is returned when the connection to PostgreSQL fails.
 	ErrConnectionFailed = errors.New("postgres: connection failed")
 
+	// ErrSlotNotFound is returned when the replication slot does not exist.
+	ErrSlotNotFound = errors.New("postgres: replication slot not found")
+
+	// ErrPublicationNotFound is returned when the publication does not exist.
+	ErrPublicationNotFound = errors.New("postgres: publication not found")
+
 	// ErrReplicationFailed is returned when replication streaming fails.
 	ErrReplicationFailed = errors.New("postgres: replication failed")
 )
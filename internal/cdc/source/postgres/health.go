@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// HealthCheck verifies that the source database accepts connections.
+func (r *Reader) HealthCheck(ctx context.Context) error {
+	db, err := sql.Open("pgx", r.config.ConnectionURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	return nil
+}
+
+// Ensure Reader implements source.HealthChecker interface.
+var _ source.HealthChecker = (*Reader)(nil)
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/xataio/pgstream/pkg/wal/replication"
+	pgreplication "github.com/xataio/pgstream/pkg/wal/replication/postgres"
+)
+
+// numericHandler is a replication handler that delivers numeric column
+// values as text. wal2json writes them as JSON numbers, which the listener
+// decodes as float64, rounding any value with more than about 15 significant
+// digits. Quoting them before they are decoded keeps them exact, as the
+// snapshot reads them.
+type numericHandler struct {
+	*pgreplication.Handler
+}
+
+// ReceiveMessage receives the next message and quotes its numeric values.
+func (h numericHandler) ReceiveMessage(ctx context.Context) (*replication.Message, error) {
+	msg, err := h.Handler.ReceiveMessage(ctx)
+	if err != nil || msg == nil {
+		return msg, err
+	}
+	msg.Data = quoteNumerics(msg.Data)
+	return msg, nil
+}
+
+// quoteNumerics rewrites the numeric column values of a wal2json change as
+// JSON strings. Data that holds none, or cannot be parsed, is returned
+// unchanged.
+func quoteNumerics(data []byte) []byte {
+	if !bytes.Contains(data, []byte(`"numeric`)) {
+		return data
+	}
+
+	var change map[string]json.RawMessage
+	if err := json.Unmarshal(data, &change); err != nil {
+		return data
+	}
+
+	changed := false
+	for _, key := range []string{"columns", "identity"} {
+		raw, ok := change[key]
+		if !ok {
+			continue
+		}
+		var columns []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &columns); err != nil {
+			return data
+		}
+		quoted := false
+		for _, col := range columns {
+			var typeName string
+			if err := json.Unmarshal(col["type"], &typeName); err != nil || !isNumericType(typeName) {
+				continue
+			}
+			value := col["value"]
+			if len(value) == 0 || (value[0] != '-' && (value[0] < '0' || value[0] > '9')) {
+				continue
+			}
+			text, err := json.Marshal(string(value))
+			if err != nil {
+				return data
+			}
+			col["value"] = text
+			quoted = true
+		}
+		if !quoted {
+			continue
+		}
+		encoded, err := json.Marshal(columns)
+		if err != nil {
+			return data
+		}
+		change[key] = encoded
+		changed = true
+	}
+	if !changed {
+		return data
+	}
+
+	encoded, err := json.Marshal(change)
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+// isNumericType reports whether a wal2json type name is numeric, with or
+// without a precision.
+func isNumericType(typeName string) bool {
+	return typeName == "numeric" || strings.HasPrefix(typeName, "numeric(")
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/xataio/pgstream/pkg/wal"
+)
+
+func TestQuoteNumerics(t *testing.T) {
+	data := []byte(`{"action":"U","schema":"public","table":"ledger",` +
+		`"columns":[{"name":"id","type":"integer","value":1},` +
+		`{"name":"amount","type":"numeric(38,9)","value":12345678901234567890123456789.123456789},` +
+		`{"name":"rate","type":"numeric","value":-0.000000000000000000012345},` +
+		`{"name":"missing","type":"numeric","value":null},` +
+		`{"name":"special","type":"numeric","value":"NaN"},` +
+		`{"name":"history","type":"numeric[]","value":"{1.5,2}"}],` +
+		`"identity":[{"name":"amount","type":"numeric(38,9)","value":99999999999999999999999999999.999999999}]}`)
+
+	var got wal.Data
+	if err := json.Unmarshal(quoteNumerics(data), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := map[string]any{
+		"id":      float64(1),
+		"amount":  "12345678901234567890123456789.123456789",
+		"rate":    "-0.000000000000000000012345",
+		"missing": nil,
+		"special": "NaN",
+		"history": "{1.5,2}",
+	}
+	for _, col := range got.Columns {
+		if col.Value != want[col.Name] {
+			t.Errorf("column %s = %#v, want %#v", col.Name, col.Value, want[col.Name])
+		}
+	}
+	if len(got.Identity) != 1 || got.Identity[0].Value != "99999999999999999999999999999.999999999" {
+		t.Errorf("identity = %#v, want the exact amount", got.Identity)
+	}
+}
+
+func TestQuoteNumerics_Unchanged(t *testing.T) {
+	for _, data := range []string{
+		`{"action":"I","columns":[{"name":"id","type":"integer","value":1}]}`,
+		`{"action":"I","columns":[{"name":"note","type":"text","value":"\"numeric"}]}`,
+		`not json "numeric`,
+	} {
+		if got := quoteNumerics([]byte(data)); string(got) != data {
+			t.Errorf("quoteNumerics(%s) = %s, want it unchanged", data, got)
+		}
+	}
+}
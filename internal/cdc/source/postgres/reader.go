@@ -3,6 +3,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/janovincze/philotes/internal/cdc"
 	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/metrics"
 )
 
 // Reader is a PostgreSQL CDC source that uses pgstream for logical replication.
@@ -23,6 +25,7 @@ type Reader struct {
 	config   Config
 	logger   *slog.Logger
 	listener listener.Listener
+	dedup    *source.Deduplicator
 
 	events chan cdc.Event
 	errors chan error
@@ -32,6 +35,14 @@ type Reader struct {
 	lastLSN   string
 	stopOnce  sync.Once
 	closeOnce sync.Once
+
+	// queryDB serves the slot and lag queries, opened on first use
+	queryMu sync.Mutex
+	queryDB *sql.DB
+
+	// toast restores unchanged TOAST columns on UPDATE
+	toast        toastSource
+	toastColumns toastColumns
 }
 
 // New creates a new PostgreSQL CDC reader with the given configuration.
@@ -44,12 +55,14 @@ func New(cfg Config, logger *slog.Logger) (*Reader, error) {
 		logger = slog.Default()
 	}
 
-	return &Reader{
+	r := &Reader{
 		config: cfg,
 		logger: logger.With("component", "postgres-reader", "source", cfg.Name),
 		events: make(chan cdc.Event, cfg.EventBufferSize),
 		errors: make(chan error, 1),
-	}, nil
+	}
+	r.toast = &postgresToastSource{reader: r}
+	return r, nil
 }
 
 // Start begins capturing CDC events from PostgreSQL.
@@ -70,6 +83,8 @@ func (r *Reader) Start(ctx context.Context) (<-chan cdc.Event, <-chan error) {
 
 // Stop gracefully stops the reader.
 func (r *Reader) Stop(ctx context.Context) error {
+	defer r.closeQueryDB()
+
 	r.mu.Lock()
 	if !r.started {
 		r.mu.Unlock()
@@ -90,6 +105,25 @@ func (r *Reader) Stop(ctx context.Context) error {
 	return err
 }
 
+// SetDeduplicator enables dropping changes redelivered after a reconnect.
+// It must be called before Start.
+func (r *Reader) SetDeduplicator(d *source.Deduplicator) {
+	r.dedup = d
+}
+
+// Acknowledge advances the deduplication high-water-mark once changes up to
+// event are checkpointed.
+func (r *Reader) Acknowledge(ctx context.Context, event cdc.Event) error {
+	if r.dedup == nil {
+		return nil
+	}
+	pos, err := source.EventPosition(event)
+	if err != nil {
+		return fmt.Errorf("acknowledge: %w", err)
+	}
+	return r.dedup.Commit(ctx, pos)
+}
+
 // LastLSN returns the last processed LSN.
 func (r *Reader) LastLSN() string {
 	r.mu.RLock()
@@ -108,6 +142,15 @@ func (r *Reader) run(ctx context.Context) {
 		"publication", r.config.PublicationName,
 	)
 
+	if r.dedup != nil {
+		r.dedup.Reset()
+		if err := r.dedup.Load(ctx); err != nil {
+			r.logger.Warn("failed to load deduplication high-water-mark", "error", err)
+		} else if hwm := r.dedup.HighWaterMark(); hwm != nil {
+			r.logger.Info("deduplicating redelivered changes", "high_water_mark", hwm.String())
+		}
+	}
+
 	// Create the replication handler
 	handlerCfg := pgreplication.Config{
 		PostgresURL:         r.config.ConnectionURL,
@@ -123,8 +166,9 @@ func (r *Reader) run(ctx context.Context) {
 	}
 	defer handler.Close()
 
-	// Create the WAL listener with our event processor
-	r.listener = pglistener.New(handler, r.processWALEvent)
+	// Create the WAL listener with our event processor, reading numeric
+	// values as text so they keep their precision
+	r.listener = pglistener.New(numericHandler{handler}, r.processWALEvent)
 
 	r.logger.Info("connected to PostgreSQL, starting replication")
 
@@ -160,12 +204,28 @@ func (r *Reader) processWALEvent(ctx context.Context, event *wal.Event) error {
 		return nil // Don't fail on conversion errors, log and continue
 	}
 
+	if r.dedup != nil {
+		pos, admit, err := r.dedup.Observe(cdcEvent.LSN)
+		if err != nil {
+			r.logger.Warn("failed to deduplicate WAL event", "lsn", cdcEvent.LSN, "error", err)
+		}
+		if !admit {
+			r.logger.Debug("dropping redelivered change", "position", pos.String())
+			metrics.CDCDuplicatesDroppedTotal.WithLabelValues(r.config.Name).Inc()
+			return nil
+		}
+		cdcEvent.Metadata[source.MetadataSequence] = pos.Seq
+	}
+
+	r.restoreUnchangedToast(ctx, event.Data, &cdcEvent)
+
 	select {
 	case r.events <- cdcEvent:
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 
+	metrics.CDCEventsReadTotal.WithLabelValues(r.config.Name).Inc()
 	return nil
 }
 
@@ -247,10 +307,38 @@ func (r *Reader) columnsToMap(columns []wal.Column) map[string]any {
 	}
 	result := make(map[string]any, len(columns))
 	for _, col := range columns {
-		result[col.Name] = col.Value
+		result[col.Name] = r.columnValue(col)
 	}
 	return result
 }
 
-// Ensure Reader implements source.Source interface.
-var _ source.Source = (*Reader)(nil)
+// columnValue returns the value of a column, decoding json and jsonb values
+// and normalizing timestamps. A value that cannot be decoded is kept as
+// delivered.
+func (r *Reader) columnValue(col wal.Column) any {
+	if withZone, ok := cdc.IsTimestampType(col.Type); ok {
+		value, err := cdc.DecodeTimestamp(col.Value, withZone)
+		if err != nil {
+			r.logger.Warn("failed to decode timestamp column", "column", col.Name, "type", col.Type, "error", err)
+			return col.Value
+		}
+		return value
+	}
+	if !cdc.IsJSONType(col.Type) {
+		return col.Value
+	}
+	value, err := cdc.DecodeJSON(col.Value, r.config.JSONAsStruct)
+	if err != nil {
+		r.logger.Warn("failed to decode json column", "column", col.Name, "type", col.Type, "error", err)
+		return col.Value
+	}
+	return value
+}
+
+// Ensure Reader implements source.Source, source.Acknowledger and
+// source.DeduplicatingSource interfaces.
+var (
+	_ source.Source              = (*Reader)(nil)
+	_ source.Acknowledger        = (*Reader)(nil)
+	_ source.DeduplicatingSource = (*Reader)(nil)
+)
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// SourceType is the name the PostgreSQL source is registered under.
+const SourceType = "postgres"
+
+func init() {
+	source.Register(SourceType, NewFromConfig)
+}
+
+// NewFromConfig creates a PostgreSQL reader from the service configuration.
+// It is the source.Factory for SourceType.
+func NewFromConfig(cfg *config.Config, logger *slog.Logger) (source.Source, error) {
+	readerCfg := DefaultConfig()
+	readerCfg.Name = cfg.CDC.Source.SourceID()
+	readerCfg.ConnectionURL = cfg.CDC.Source.URL()
+	readerCfg.SlotName = cfg.CDC.Replication.SlotName
+	readerCfg.PublicationName = cfg.CDC.Replication.PublicationName
+	readerCfg.Tables = cfg.CDC.Replication.Tables
+	readerCfg.AutoCreate = cfg.CDC.Replication.AutoCreateSlot
+	readerCfg.JSONAsStruct = cfg.CDC.JSONAsStruct
+	if cfg.CDC.BufferSize > 0 {
+		readerCfg.EventBufferSize = cfg.CDC.BufferSize
+	}
+
+	toast, err := ParseToastStrategy(cfg.CDC.UnchangedToast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	readerCfg.UnchangedToast = toast
+
+	return New(readerCfg, logger)
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// db returns the connection pool for slot queries, opening it on first use.
+// One connection is enough for the periodic lag poll and the occasional slot
+// lookup, and keeping it avoids a new connection per poll.
+func (r *Reader) db() (*sql.DB, error) {
+	r.queryMu.Lock()
+	defer r.queryMu.Unlock()
+
+	if r.queryDB == nil {
+		db, err := sql.Open("pgx", r.config.ConnectionURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+		}
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		r.queryDB = db
+	}
+	return r.queryDB, nil
+}
+
+// closeQueryDB closes the slot query pool if it was opened.
+func (r *Reader) closeQueryDB() {
+	r.queryMu.Lock()
+	defer r.queryMu.Unlock()
+
+	if r.queryDB != nil {
+		_ = r.queryDB.Close()
+		r.queryDB = nil
+	}
+}
+
+// SlotPosition returns the current position of the reader's replication slot.
+func (r *Reader) SlotPosition(ctx context.Context) (*source.SlotPosition, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var restartLSN, confirmedLSN sql.NullString
+	err = db.QueryRowContext(ctx,
+		`SELECT restart_lsn::text, confirmed_flush_lsn::text FROM pg_replication_slots WHERE slot_name = $1`,
+		r.config.SlotName,
+	).Scan(&restartLSN, &confirmedLSN)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &source.SlotPosition{Exists: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query replication slot: %w", err)
+	}
+
+	return &source.SlotPosition{
+		Exists:            true,
+		RestartLSN:        restartLSN.String,
+		ConfirmedFlushLSN: confirmedLSN.String,
+	}, nil
+}
+
+// ReplicationLag returns how many bytes of WAL the source has written past
+// the slot's confirmed flush position.
+func (r *Reader) ReplicationLag(ctx context.Context) (int64, error) {
+	db, err := r.db()
+	if err != nil {
+		return 0, err
+	}
+
+	var lag sql.NullInt64
+	err = db.QueryRowContext(ctx,
+		`SELECT pg_wal_lsn_diff(pg_current_wal_lsn(), confirmed_flush_lsn)::bigint FROM pg_replication_slots WHERE slot_name = $1`,
+		r.config.SlotName,
+	).Scan(&lag)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", ErrSlotNotFound, r.config.SlotName)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query replication lag: %w", err)
+	}
+
+	// A slot that has not confirmed anything yet has no lag to report
+	return max(lag.Int64, 0), nil
+}
+
+// Ensure Reader implements source.SlotInspector and source.LagReporter interfaces.
+var (
+	_ source.SlotInspector = (*Reader)(nil)
+	_ source.LagReporter   = (*Reader)(nil)
+)
@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xataio/pgstream/pkg/wal"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// ToastStrategy decides how the values of unchanged TOAST columns are
+// restored on UPDATE. Logical replication does not send a TOASTed value the
+// update did not change, so without a strategy the column would be written
+// as NULL.
+type ToastStrategy string
+
+const (
+	// ToastIdentity carries the values forward from the old row. It needs
+	// REPLICA IDENTITY FULL, which logs the whole old row; with any other
+	// replica identity the columns are only marked.
+	ToastIdentity ToastStrategy = "identity"
+
+	// ToastReadBack reads the values from the source table by key. The
+	// value read is the row's current value, which may be newer than the
+	// change if the row was updated again since.
+	ToastReadBack ToastStrategy = "read_back"
+
+	// ToastOmit leaves the columns out of the change and only marks them.
+	ToastOmit ToastStrategy = "omit"
+)
+
+// ParseToastStrategy parses an unchanged TOAST strategy. An empty string is
+// ToastIdentity.
+func ParseToastStrategy(s string) (ToastStrategy, error) {
+	switch t := ToastStrategy(strings.ToLower(strings.TrimSpace(s))); t {
+	case "":
+		return ToastIdentity, nil
+	case ToastIdentity, ToastReadBack, ToastOmit:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown unchanged toast strategy %q (want identity, read_back or omit)", s)
+	}
+}
+
+// toastSource reads the table details the TOAST strategies need from the
+// source database.
+type toastSource interface {
+	// ToastableColumns returns the columns of a table whose values can be
+	// TOASTed, with their types.
+	ToastableColumns(ctx context.Context, schema, table string) ([]wal.Column, error)
+
+	// ReadColumns reads the text values of columns from the row of a table
+	// with the given key, or returns nil if the row no longer exists.
+	ReadColumns(ctx context.Context, schema, table string, key, columns []wal.Column) ([]wal.Column, error)
+}
+
+// toastColumns caches the toastable columns of each table.
+type toastColumns struct {
+	mu     sync.Mutex
+	tables map[string][]wal.Column
+}
+
+// get returns the toastable columns of a table, listing them from the
+// source on first use.
+func (c *toastColumns) get(ctx context.Context, src toastSource, schema, table string) ([]wal.Column, error) {
+	name := schema + "." + table
+
+	c.mu.Lock()
+	columns, ok := c.tables[name]
+	c.mu.Unlock()
+	if ok {
+		return columns, nil
+	}
+
+	columns, err := src.ToastableColumns(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[string][]wal.Column)
+	}
+	c.tables[name] = columns
+	return columns, nil
+}
+
+// forget drops the cached columns of a table, so they are listed again
+// after the table changed.
+func (c *toastColumns) forget(schema, table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tables, schema+"."+table)
+}
+
+// restoreUnchangedToast fills in the toastable columns an UPDATE left out
+// using the configured strategy. Columns it cannot restore stay out of the
+// event and are listed under cdc.MetadataUnchangedToast.
+func (r *Reader) restoreUnchangedToast(ctx context.Context, data *wal.Data, event *cdc.Event) {
+	if event.Operation != cdc.OperationUpdate || event.After == nil {
+		return
+	}
+
+	columns, err := r.toastColumns.get(ctx, r.toast, data.Schema, data.Table)
+	if err != nil {
+		r.logger.Warn("failed to list toastable columns", "table", event.FullyQualifiedTable(), "error", err)
+		return
+	}
+
+	var missing []wal.Column
+	for _, col := range columns {
+		if _, ok := event.After[col.Name]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	switch r.config.UnchangedToast {
+	case ToastIdentity:
+		missing = r.restoreFromIdentity(event, missing)
+	case ToastReadBack:
+		missing = r.restoreByReadBack(ctx, data, event, missing)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	names := make([]string, len(missing))
+	for i, col := range missing {
+		names[i] = col.Name
+	}
+	sort.Strings(names)
+	event.Metadata[cdc.MetadataUnchangedToast] = names
+	r.logger.Debug("unchanged toast columns not restored", "table", event.FullyQualifiedTable(), "columns", names)
+}
+
+// restoreFromIdentity copies the missing columns from the old row and
+// returns those it does not hold.
+func (r *Reader) restoreFromIdentity(event *cdc.Event, missing []wal.Column) []wal.Column {
+	var left []wal.Column
+	for _, col := range missing {
+		value, ok := event.Before[col.Name]
+		if !ok {
+			left = append(left, col)
+			continue
+		}
+		event.After[col.Name] = value
+	}
+	return left
+}
+
+// restoreByReadBack reads the missing columns from the source row with the
+// event's key and returns those it could not read.
+func (r *Reader) restoreByReadBack(ctx context.Context, data *wal.Data, event *cdc.Event, missing []wal.Column) []wal.Column {
+	// Key the row by its new values, in case the update changed the key
+	key := make([]wal.Column, 0, len(data.Identity))
+	for _, col := range data.Identity {
+		value, ok := event.After[col.Name]
+		if !ok {
+			return missing
+		}
+		key = append(key, wal.Column{Name: col.Name, Type: col.Type, Value: value})
+	}
+	if len(key) == 0 {
+		return missing
+	}
+
+	values, err := r.toast.ReadColumns(ctx, data.Schema, data.Table, key, missing)
+	if err != nil {
+		// The table may have changed since its columns were listed
+		r.toastColumns.forget(data.Schema, data.Table)
+		r.logger.Warn("failed to read back unchanged toast columns", "table", event.FullyQualifiedTable(), "error", err)
+		return missing
+	}
+	if values == nil {
+		return missing
+	}
+	for _, col := range values {
+		event.After[col.Name] = r.columnValue(col)
+	}
+	return nil
+}
+
+// postgresToastSource is the toastSource of a Reader, querying the source
+// over the reader's query connection.
+type postgresToastSource struct {
+	reader *Reader
+}
+
+// ToastableColumns lists the variable-length columns of a table whose
+// storage allows TOAST.
+func (s *postgresToastSource) ToastableColumns(ctx context.Context, schema, table string) ([]wal.Column, error) {
+	db, err := s.reader.db()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_attribute a
+		WHERE a.attrelid = (quote_ident($1) || '.' || quote_ident($2))::regclass
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+			AND a.attlen = -1
+			AND a.attstorage <> 'p'
+		ORDER BY a.attnum
+	`
+
+	rows, err := db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("query toastable columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []wal.Column
+	for rows.Next() {
+		var col wal.Column
+		if err := rows.Scan(&col.Name, &col.Type); err != nil {
+			return nil, fmt.Errorf("scan toastable column: %w", err)
+		}
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate toastable columns: %w", err)
+	}
+	return columns, nil
+}
+
+// ReadColumns reads columns as text, the form logical replication delivers
+// them in.
+func (s *postgresToastSource) ReadColumns(ctx context.Context, schema, table string, key, columns []wal.Column) ([]wal.Column, error) {
+	db, err := s.reader.db()
+	if err != nil {
+		return nil, err
+	}
+
+	selects := make([]string, len(columns))
+	for i, col := range columns {
+		selects[i] = quoteIdent(col.Name) + "::text"
+	}
+	conditions := make([]string, len(key))
+	args := make([]any, len(key))
+	for i, col := range key {
+		conditions[i] = fmt.Sprintf("%s = $%d", quoteIdent(col.Name), i+1)
+		args[i] = col.Value
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE %s",
+		strings.Join(selects, ", "), quoteIdent(schema), quoteIdent(table), strings.Join(conditions, " AND "))
+
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := db.QueryRowContext(ctx, query, args...).Scan(pointers...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read columns: %w", err)
+	}
+
+	result := make([]wal.Column, len(columns))
+	for i, col := range columns {
+		result[i] = wal.Column{Name: col.Name, Type: col.Type}
+		if values[i].Valid {
+			result[i].Value = values[i].String
+		}
+	}
+	return result, nil
+}
+
+// quoteIdent quotes an identifier for use in a query.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
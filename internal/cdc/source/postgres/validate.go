@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/cdc/source"
+)
+
+// Validate checks that the source is ready to replicate without creating or
+// changing anything: the database accepts connections and has logical WAL,
+// the publication (for plugins that use one) covers the configured tables and
+// the slot uses the decoding plugin the reader consumes and is not in use. A
+// missing publication or slot is only a problem when AutoCreate is off.
+func (r *Reader) Validate(ctx context.Context) (string, error) {
+	db, err := sql.Open("pgx", r.config.ConnectionURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
+	var problems []error
+	var verified []string
+
+	var walLevel string
+	if err := db.QueryRowContext(ctx, `SHOW wal_level`).Scan(&walLevel); err != nil {
+		return "", fmt.Errorf("query wal_level: %w", err)
+	}
+	if walLevel != "logical" {
+		problems = append(problems, fmt.Errorf("wal_level is %q, logical replication needs \"logical\"", walLevel))
+	} else {
+		verified = append(verified, "wal_level logical")
+	}
+
+	if usesPublication(decodingPlugin) {
+		found, missing, err := r.validatePublication(ctx, db)
+		if err != nil {
+			return "", err
+		}
+		verified = append(verified, found...)
+		problems = append(problems, missing...)
+	}
+
+	var plugin string
+	var active bool
+	err = db.QueryRowContext(ctx,
+		`SELECT plugin, active FROM pg_replication_slots WHERE slot_name = $1`,
+		r.config.SlotName,
+	).Scan(&plugin, &active)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && r.config.AutoCreate:
+		verified = append(verified, fmt.Sprintf("slot %s will be created", r.config.SlotName))
+	case errors.Is(err, sql.ErrNoRows):
+		problems = append(problems, fmt.Errorf("%w: %s (set PHILOTES_CDC_AUTO_CREATE_SLOT to create it)", ErrSlotNotFound, r.config.SlotName))
+	case err != nil:
+		return "", fmt.Errorf("query replication slot: %w", err)
+	case plugin != decodingPlugin:
+		problems = append(problems, fmt.Errorf("slot %s uses plugin %s, the reader needs %s", r.config.SlotName, plugin, decodingPlugin))
+	case active:
+		problems = append(problems, fmt.Errorf("slot %s is in use by another consumer", r.config.SlotName))
+	default:
+		verified = append(verified, fmt.Sprintf("slot %s (%s)", r.config.SlotName, plugin))
+	}
+
+	if len(problems) > 0 {
+		return "", errors.Join(problems...)
+	}
+	return strings.Join(verified, "; "), nil
+}
+
+// validatePublication checks that the reader's publication covers the
+// configured tables, returning what was verified and the problems found.
+func (r *Reader) validatePublication(ctx context.Context, db *sql.DB) ([]string, []error, error) {
+	var exists, allTables bool
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) > 0, COALESCE(bool_or(puballtables), false) FROM pg_publication WHERE pubname = $1`,
+		r.config.PublicationName,
+	).Scan(&exists, &allTables)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query publication: %w", err)
+	}
+
+	switch {
+	case !exists && r.config.AutoCreate:
+		return []string{fmt.Sprintf("publication %s will be created", r.config.PublicationName)}, nil, nil
+	case !exists:
+		return nil, []error{fmt.Errorf("%w: %s (set PHILOTES_CDC_AUTO_CREATE_SLOT to create it)", ErrPublicationNotFound, r.config.PublicationName)}, nil
+	case allTables:
+		return []string{fmt.Sprintf("publication %s covers all tables", r.config.PublicationName)}, nil, nil
+	}
+
+	published, err := r.publishedTables(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if missing := missingTables(r.config.Tables, published); len(missing) > 0 {
+		return nil, []error{fmt.Errorf("publication %s does not include %s", r.config.PublicationName, strings.Join(missing, ", "))}, nil
+	}
+	return []string{fmt.Sprintf("publication %s covers %d tables", r.config.PublicationName, len(published))}, nil, nil
+}
+
+// publishedTables returns the tables of the reader's publication as
+// "schema.table".
+func (r *Reader) publishedTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT schemaname, tablename FROM pg_publication_tables WHERE pubname = $1`,
+		r.config.PublicationName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query publication tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			return nil, fmt.Errorf("scan publication table: %w", err)
+		}
+		tables = append(tables, schema+"."+table)
+	}
+	return tables, rows.Err()
+}
+
+// missingTables returns the configured tables, given as "table" or
+// "schema.table", that are not among published "schema.table" names.
+func missingTables(configured, published []string) []string {
+	set := make(map[string]bool, len(published))
+	for _, table := range published {
+		set[table] = true
+	}
+
+	var missing []string
+	for _, table := range configured {
+		qualified := table
+		if !strings.Contains(table, ".") {
+			qualified = "public." + table
+		}
+		if !set[qualified] {
+			missing = append(missing, table)
+		}
+	}
+	return missing
+}
+
+// Ensure Reader implements source.Validator interface.
+var _ source.Validator = (*Reader)(nil)
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// Factory creates a source from the service configuration. Factories read the
+// settings they need from cfg.CDC; the source identifier is
+// cfg.CDC.Source.SourceID().
+type Factory func(cfg *config.Config, logger *slog.Logger) (Source, error)
+
+// Registry holds the registered source factories by name.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates a new source registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// Register adds a source factory to the registry, replacing any factory
+// already registered under name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+}
+
+// Get returns a source factory by name.
+func (r *Registry) Get(name string) (Factory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.factories[name]
+	return f, ok
+}
+
+// Names returns the registered source names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a source with the factory registered under name.
+func (r *Registry) New(name string, cfg *config.Config, logger *slog.Logger) (Source, error) {
+	factory, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown source type %q (registered: %v)", name, r.Names())
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return factory(cfg, logger)
+}
+
+// defaultRegistry holds the sources that register themselves on import.
+var defaultRegistry = NewRegistry()
+
+// Register adds a source factory to the default registry. Source packages
+// call it from init, so importing a package makes its source available.
+func Register(name string, factory Factory) {
+	defaultRegistry.Register(name, factory)
+}
+
+// New creates a source from the default registry.
+func New(name string, cfg *config.Config, logger *slog.Logger) (Source, error) {
+	return defaultRegistry.New(name, cfg, logger)
+}
+
+// Names returns the sources registered in the default registry.
+func Names() []string {
+	return defaultRegistry.Names()
+}
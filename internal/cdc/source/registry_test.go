@@ -0,0 +1,53 @@
+package source
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/config"
+)
+
+// stubSource is a Source that produces no events.
+type stubSource struct {
+	name string
+}
+
+func (s *stubSource) Start(context.Context) (<-chan cdc.Event, <-chan error) { return nil, nil }
+func (s *stubSource) Stop(context.Context) error                             { return nil }
+func (s *stubSource) LastLSN() string                                        { return "" }
+func (s *stubSource) Name() string                                           { return s.name }
+
+func TestRegistry_New(t *testing.T) {
+	r := NewRegistry()
+	r.Register("mysql", func(cfg *config.Config, _ *slog.Logger) (Source, error) {
+		return &stubSource{name: cfg.CDC.Source.SourceID()}, nil
+	})
+	r.Register("mongodb", func(*config.Config, *slog.Logger) (Source, error) {
+		return &stubSource{}, nil
+	})
+
+	cfg := &config.Config{}
+	cfg.CDC.Source.Type = "mysql"
+	cfg.CDC.Source.Database = "shop"
+
+	src, err := r.New("mysql", cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if src.Name() != "mysql-shop" {
+		t.Errorf("Name() = %q, want mysql-shop", src.Name())
+	}
+
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"mongodb", "mysql"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestRegistry_UnknownSource(t *testing.T) {
+	if _, err := NewRegistry().New("oracle", &config.Config{}, nil); err == nil {
+		t.Fatal("expected an error for an unregistered source")
+	}
+}
@@ -31,3 +31,58 @@ type Config struct {
 	// StartLSN is the LSN to start from (empty means start from current).
 	StartLSN string
 }
+
+// SlotPosition describes the server-side position of a replication slot.
+type SlotPosition struct {
+	// Exists is false when the slot does not exist on the source.
+	Exists bool
+
+	// RestartLSN is the oldest WAL position the slot retains.
+	RestartLSN string
+
+	// ConfirmedFlushLSN is the position up to which the consumer has confirmed changes.
+	ConfirmedFlushLSN string
+}
+
+// SlotInspector is implemented by sources that can report the position of
+// their replication slot, so a pipeline can detect a lost position on startup.
+type SlotInspector interface {
+	SlotPosition(ctx context.Context) (*SlotPosition, error)
+}
+
+// LagReporter is implemented by sources that can report how far their
+// replication slot trails the database's current WAL position.
+type LagReporter interface {
+	// ReplicationLag returns the lag in bytes.
+	ReplicationLag(ctx context.Context) (int64, error)
+}
+
+// Bootstrapper is implemented by sources that need server-side objects, such
+// as a replication slot, to exist before they start.
+type Bootstrapper interface {
+	// Bootstrap verifies, and optionally creates, the objects the source
+	// reads from.
+	Bootstrap(ctx context.Context) error
+}
+
+// Validator is implemented by sources that can check, without changing
+// anything on the server, that they are ready to replicate.
+type Validator interface {
+	// Validate returns a summary of what was verified, or an error
+	// describing every problem found.
+	Validate(ctx context.Context) (string, error)
+}
+
+// HealthChecker is implemented by sources that can check connectivity to
+// their database, so the worker can report source health.
+type HealthChecker interface {
+	// HealthCheck returns an error if the source database is unreachable.
+	HealthCheck(ctx context.Context) error
+}
+
+// DeduplicatingSource is implemented by sources that can drop changes
+// redelivered after a reconnect.
+type DeduplicatingSource interface {
+	// SetDeduplicator enables deduplication. It must be called before Start.
+	SetDeduplicator(d *Deduplicator)
+}
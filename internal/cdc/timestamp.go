@@ -0,0 +1,123 @@
+package cdc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timestamp layouts values are normalized to. Values keep microseconds, the
+// precision of PostgreSQL and Iceberg timestamps.
+const (
+	// TimestampLayout is the layout of timestamp without time zone values.
+	TimestampLayout = "2006-01-02T15:04:05.000000"
+
+	// TimestampTZLayout is the layout of timestamp with time zone values,
+	// always in UTC.
+	TimestampTZLayout = "2006-01-02T15:04:05.000000Z"
+)
+
+var (
+	// MinTimestamp is the earliest timestamp written. -infinity and
+	// earlier values, such as BC dates, are clamped to it.
+	MinTimestamp = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// MaxTimestamp is the latest timestamp written. infinity and later
+	// values, past year 9999, are clamped to it.
+	MaxTimestamp = time.Date(9999, time.December, 31, 23, 59, 59, 999999000, time.UTC)
+)
+
+// timestampLayouts are the layouts timestamp text is parsed with: as
+// PostgreSQL writes it, with an offset of hours, minutes or seconds, and as
+// encoding/json writes a time.Time. Fractional seconds are accepted by all.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05-07:00:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// IsTimestampType reports whether a PostgreSQL type name is timestamp or
+// timestamptz, with or without a precision, and whether it has a time zone.
+func IsTimestampType(typeName string) (withZone, ok bool) {
+	name := strings.ToLower(strings.TrimSpace(typeName))
+	if open := strings.Index(name, "("); open >= 0 {
+		if end := strings.Index(name[open:], ")"); end >= 0 {
+			name = name[:open] + name[open+end+1:]
+		}
+	}
+	switch strings.Join(strings.Fields(name), " ") {
+	case "timestamp", "timestamp without time zone":
+		return false, true
+	case "timestamptz", "timestamp with time zone":
+		return true, true
+	}
+	return false, false
+}
+
+// DecodeTimestamp normalizes the value of a timestamp or timestamptz column.
+// Sources deliver these as text from logical replication, in the source's
+// time zone for timestamptz, or as a time.Time from a query, in the
+// worker's; both are rewritten in one layout so a column's values do not
+// depend on how they were read.
+//
+// A timestamptz value is returned in UTC in TimestampTZLayout. A timestamp
+// value keeps its wall clock time and is returned in TimestampLayout; a
+// value with a zone is converted to UTC first, as a query delivers it. Text
+// without a zone read as timestamptz is taken to be UTC. infinity,
+// -infinity and values outside years 1 to 9999 are clamped to MaxTimestamp
+// and MinTimestamp. A NULL column returns nil.
+func DecodeTimestamp(value any, withZone bool) (any, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := parseTimestamp(v)
+		if err != nil {
+			return nil, err
+		}
+		t = parsed
+	default:
+		return nil, fmt.Errorf("invalid timestamp value of type %T", value)
+	}
+
+	t = t.UTC()
+	if t.Before(MinTimestamp) {
+		t = MinTimestamp
+	} else if t.After(MaxTimestamp) {
+		t = MaxTimestamp
+	}
+
+	if withZone {
+		return t.Format(TimestampTZLayout), nil
+	}
+	return t.Format(TimestampLayout), nil
+}
+
+// parseTimestamp parses timestamp text. Values no time.Time layout can
+// hold, infinities, BC dates and years past 9999, are returned as the
+// bound they are clamped to.
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "infinity":
+		return MaxTimestamp, nil
+	case s == "-infinity", strings.HasSuffix(s, " BC"):
+		return MinTimestamp, nil
+	}
+	if year, _, ok := strings.Cut(s, "-"); ok && len(year) > 4 && strings.Trim(year, "0123456789") == "" {
+		return MaxTimestamp, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,138 @@
+package cdc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	_ "time/tzdata" // America/New_York for the DST cases
+)
+
+func TestIsTimestampType(t *testing.T) {
+	tests := []struct {
+		typeName     string
+		wantWithZone bool
+		wantOK       bool
+	}{
+		{"timestamp", false, true},
+		{"timestamp without time zone", false, true},
+		{"timestamp(3) without time zone", false, true},
+		{"TIMESTAMP", false, true},
+		{"timestamptz", true, true},
+		{"TIMESTAMPTZ", true, true},
+		{"timestamp with time zone", true, true},
+		{"timestamp(6) with time zone", true, true},
+		{"date", false, false},
+		{"time with time zone", false, false},
+		{"_timestamptz", false, false},
+	}
+	for _, tt := range tests {
+		withZone, ok := IsTimestampType(tt.typeName)
+		if withZone != tt.wantWithZone || ok != tt.wantOK {
+			t.Errorf("IsTimestampType(%q) = %v, %v; want %v, %v", tt.typeName, withZone, ok, tt.wantWithZone, tt.wantOK)
+		}
+	}
+}
+
+func TestDecodeTimestamp(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    any
+		withZone bool
+		want     any
+	}{
+		{"null timestamp", nil, false, nil},
+		{"null timestamptz", nil, true, nil},
+
+		// Streamed values, as the source writes them
+		{"timestamp", "2024-01-15 10:30:00.123456", false, "2024-01-15T10:30:00.123456"},
+		{"timestamp whole second", "2024-01-15 10:30:00", false, "2024-01-15T10:30:00.000000"},
+		{"timestamptz in UTC", "2024-01-15 10:30:00.123456+00", true, "2024-01-15T10:30:00.123456Z"},
+		{"timestamptz half-hour offset", "2024-01-15 16:00:00.5+05:30", true, "2024-01-15T10:30:00.500000Z"},
+		{"timestamptz historical offset", "1850-06-01 12:00:00+00:53:28", true, "1850-06-01T11:06:32.000000Z"},
+
+		// Either side of the spring forward: 01:59 EST and 03:00 EDT are a
+		// minute apart
+		{"before spring forward", "2024-03-10 01:59:00-05", true, "2024-03-10T06:59:00.000000Z"},
+		{"after spring forward", "2024-03-10 03:00:00-04", true, "2024-03-10T07:00:00.000000Z"},
+		// A wall clock time skipped in New York is kept as written
+		{"skipped wall clock", "2024-03-10 02:30:00", false, "2024-03-10T02:30:00.000000"},
+
+		// 01:30 happens twice at the fall back
+		{"first 01:30 of fall back", "2024-11-03 01:30:00-04", true, "2024-11-03T05:30:00.000000Z"},
+		{"second 01:30 of fall back", "2024-11-03 01:30:00-05", true, "2024-11-03T06:30:00.000000Z"},
+		{"repeated wall clock", "2024-11-03 01:30:00", false, "2024-11-03T01:30:00.000000"},
+
+		// Snapshot values, in the worker's time zone
+		{"snapshot timestamptz across fall back", time.Date(2024, 11, 3, 1, 30, 0, 0, newYork).Add(time.Hour), true, "2024-11-03T06:30:00.000000Z"},
+		{"snapshot timestamp", time.Date(2024, 3, 10, 2, 30, 0, 123456789, time.UTC), false, "2024-03-10T02:30:00.123456"},
+
+		// Values already buffered as JSON
+		{"buffered timestamptz", "2024-03-10T01:59:00-05:00", true, "2024-03-10T06:59:00.000000Z"},
+		{"buffered timestamp", "2024-03-10T02:30:00.123456Z", false, "2024-03-10T02:30:00.123456"},
+		{"timestamp forced to UTC", "2024-03-10 02:30:00.000001", true, "2024-03-10T02:30:00.000001Z"},
+
+		// Edge cases
+		{"postgres epoch", "2000-01-01 00:00:00+00", true, "2000-01-01T00:00:00.000000Z"},
+		{"unix epoch", "1970-01-01 00:00:00", false, "1970-01-01T00:00:00.000000"},
+		{"before unix epoch", "1969-12-31 23:59:59.999999+00", true, "1969-12-31T23:59:59.999999Z"},
+		{"infinity", "infinity", true, "9999-12-31T23:59:59.999999Z"},
+		{"-infinity", "-infinity", false, "0001-01-01T00:00:00.000000"},
+		{"BC", "0044-03-15 12:00:00 BC", false, "0001-01-01T00:00:00.000000"},
+		{"BC with zone", "0044-03-15 12:00:00+00 BC", true, "0001-01-01T00:00:00.000000Z"},
+		{"past year 9999", "10000-01-01 00:00:00", false, "9999-12-31T23:59:59.999999"},
+		{"offset past year 9999", "9999-12-31 23:00:00-05", true, "9999-12-31T23:59:59.999999Z"},
+		{"zero time", time.Time{}, false, "0001-01-01T00:00:00.000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeTimestamp(tt.value, tt.withZone)
+			if err != nil {
+				t.Fatalf("DecodeTimestamp() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeTimestamp(%v, %v) = %v, want %v", tt.value, tt.withZone, got, tt.want)
+			}
+
+			// Decoding is stable, so values buffered after decoding are unchanged
+			if got != nil {
+				again, err := DecodeTimestamp(got, tt.withZone)
+				if err != nil || again != got {
+					t.Errorf("DecodeTimestamp(%v) = %v, %v; want it unchanged", got, again, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeTimestamp_BufferRoundTrip(t *testing.T) {
+	value, err := DecodeTimestamp("2024-03-10 03:00:00.000001-04", true)
+	if err != nil {
+		t.Fatalf("DecodeTimestamp() error = %v", err)
+	}
+
+	encoded, err := json.Marshal(Event{After: map[string]any{"at": value}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var event Event
+	if err := json.Unmarshal(encoded, &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if event.After["at"] != "2024-03-10T07:00:00.000001Z" {
+		t.Errorf("round trip = %v, want microseconds kept", event.After["at"])
+	}
+}
+
+func TestDecodeTimestamp_Invalid(t *testing.T) {
+	for _, value := range []any{"yesterday", "2024-13-01 00:00:00", 1700000000, ""} {
+		if _, err := DecodeTimestamp(value, true); err == nil {
+			t.Errorf("DecodeTimestamp(%v) error = nil, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package transform applies per-table column rules to CDC events before they
+// are buffered, so excluded or masked values never leave the pipeline.
+package transform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// Action is what a rule does to a column.
+type Action string
+
+const (
+	// ActionExclude drops the column.
+	ActionExclude Action = "exclude"
+	// ActionHash replaces the value with its hex-encoded SHA-256 hash.
+	ActionHash Action = "hash"
+	// ActionRedact replaces the value with a constant.
+	ActionRedact Action = "redact"
+)
+
+// DefaultRedaction replaces redacted values when a rule sets no value.
+const DefaultRedaction = "REDACTED"
+
+// Rule transforms one column.
+type Rule struct {
+	// Action is what the rule does.
+	Action Action
+
+	// Salt is prepended to values before they are hashed.
+	Salt string
+
+	// Value replaces redacted values.
+	Value string
+}
+
+// Rules maps "schema.table" to the rules for its columns by column name.
+type Rules map[string]map[string]Rule
+
+// ParseRules parses column rules from JSON keyed by "schema.table" and then
+// column, e.g.
+//
+//	{"public.users": {"ssn": {"action": "exclude"},
+//	                  "email": {"action": "hash", "salt": "pepper"},
+//	                  "phone": {"action": "redact", "value": "***"}}}
+//
+// An empty string means no rules.
+func ParseRules(s string) (Rules, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var raw map[string]map[string]struct {
+		Action string  `json:"action"`
+		Salt   string  `json:"salt"`
+		Value  *string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("parse column rules: %w", err)
+	}
+
+	rules := make(Rules, len(raw))
+	for table, columns := range raw {
+		if schema, name, ok := strings.Cut(table, "."); !ok || schema == "" || name == "" {
+			return nil, fmt.Errorf("column rules %q: want schema.table", table)
+		}
+
+		tableRules := make(map[string]Rule, len(columns))
+		for column, r := range columns {
+			rule := Rule{Action: Action(strings.ToLower(strings.TrimSpace(r.Action)))}
+			switch rule.Action {
+			case ActionExclude:
+			case ActionHash:
+				rule.Salt = r.Salt
+			case ActionRedact:
+				rule.Value = DefaultRedaction
+				if r.Value != nil {
+					rule.Value = *r.Value
+				}
+			default:
+				return nil, fmt.Errorf("column rule %s.%s: unknown action %q (want exclude, hash or redact)", table, column, r.Action)
+			}
+			tableRules[column] = rule
+		}
+		rules[table] = tableRules
+	}
+	return rules, nil
+}
+
+// Transformer applies column rules to events.
+type Transformer struct {
+	rules Rules
+}
+
+// New creates a Transformer applying rules.
+func New(rules Rules) *Transformer {
+	return &Transformer{rules: rules}
+}
+
+// Apply returns event with its table's rules applied to the row before and
+// after the change. Excluded key columns are dropped from the event's key
+// columns too; hashed key columns stay keys, as equal values hash equally.
+// NULLs stay NULL. The event passed in is not modified.
+func (t *Transformer) Apply(event cdc.Event) cdc.Event {
+	if t == nil {
+		return event
+	}
+	rules, ok := t.rules[event.FullyQualifiedTable()]
+	if !ok || len(rules) == 0 {
+		return event
+	}
+
+	event.Before = applyRow(event.Before, rules)
+	event.After = applyRow(event.After, rules)
+
+	var keys []string
+	for _, column := range event.KeyColumns {
+		if rules[column].Action != ActionExclude {
+			keys = append(keys, column)
+		}
+	}
+	event.KeyColumns = keys
+	return event
+}
+
+// applyRow returns a copy of row with rules applied.
+func applyRow(row map[string]any, rules map[string]Rule) map[string]any {
+	if row == nil {
+		return nil
+	}
+
+	out := make(map[string]any, len(row))
+	for column, value := range row {
+		rule, ok := rules[column]
+		switch {
+		case ok && rule.Action == ActionExclude:
+		case !ok || value == nil:
+			out[column] = value
+		case rule.Action == ActionHash:
+			out[column] = hash(rule.Salt, value)
+		case rule.Action == ActionRedact:
+			out[column] = rule.Value
+		}
+	}
+	return out
+}
+
+// hash returns the hex-encoded SHA-256 hash of salt followed by value.
+func hash(salt string, value any) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	switch v := value.(type) {
+	case []byte:
+		h.Write(v)
+	case string:
+		h.Write([]byte(v))
+	default:
+		fmt.Fprint(h, v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
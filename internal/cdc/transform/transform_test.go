@@ -0,0 +1,85 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(`{"public.users": {"ssn": {"action": "exclude"}, "email": {"action": "HASH", "salt": "pepper"}, "phone": {"action": "redact", "value": "***"}, "name": {"action": "redact"}}}`)
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	want := Rules{"public.users": {
+		"ssn":   {Action: ActionExclude},
+		"email": {Action: ActionHash, Salt: "pepper"},
+		"phone": {Action: ActionRedact, Value: "***"},
+		"name":  {Action: ActionRedact, Value: DefaultRedaction},
+	}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseRules() = %v, want %v", rules, want)
+	}
+
+	if r, err := ParseRules(""); err != nil || r != nil {
+		t.Errorf("ParseRules(\"\") = %v, %v", r, err)
+	}
+
+	for _, invalid := range []string{
+		`not json`,
+		`{"users": {"ssn": {"action": "exclude"}}}`,
+		`{"public.users": {"ssn": {"action": "encrypt"}}}`,
+		`{"public.users": {"ssn": {}}}`,
+	} {
+		if _, err := ParseRules(invalid); err == nil {
+			t.Errorf("ParseRules(%s) expected an error", invalid)
+		}
+	}
+}
+
+func TestTransformer_Apply(t *testing.T) {
+	tr := New(Rules{"public.users": {
+		"ssn":   {Action: ActionExclude},
+		"email": {Action: ActionHash, Salt: "pepper"},
+		"phone": {Action: ActionRedact, Value: "***"},
+	}})
+
+	event := cdc.Event{
+		Schema:     "public",
+		Table:      "users",
+		Operation:  cdc.OperationUpdate,
+		KeyColumns: []string{"id", "ssn"},
+		Before:     map[string]any{"id": 1, "ssn": "123-45-6789", "email": "ada@example.com", "phone": nil},
+		After:      map[string]any{"id": 1, "ssn": "123-45-6789", "email": "ada@example.com", "phone": "555-0100"},
+	}
+	got := tr.Apply(event)
+
+	sum := sha256.Sum256([]byte("pepperada@example.com"))
+	wantAfter := map[string]any{"id": 1, "email": hex.EncodeToString(sum[:]), "phone": "***"}
+	if !reflect.DeepEqual(got.After, wantAfter) {
+		t.Errorf("After = %v, want %v", got.After, wantAfter)
+	}
+	wantBefore := map[string]any{"id": 1, "email": hex.EncodeToString(sum[:]), "phone": nil}
+	if !reflect.DeepEqual(got.Before, wantBefore) {
+		t.Errorf("Before = %v, want %v", got.Before, wantBefore)
+	}
+	if !reflect.DeepEqual(got.KeyColumns, []string{"id"}) {
+		t.Errorf("KeyColumns = %v, want the excluded key column dropped", got.KeyColumns)
+	}
+	if event.After["ssn"] != "123-45-6789" || len(event.KeyColumns) != 2 {
+		t.Error("Apply() changed the original event")
+	}
+
+	other := cdc.Event{Schema: "public", Table: "orders", After: map[string]any{"ssn": "123-45-6789"}}
+	if got := tr.Apply(other); got.After["ssn"] != "123-45-6789" {
+		t.Errorf("Apply() changed a table without rules: %v", got.After)
+	}
+
+	var none *Transformer
+	if got := none.Apply(event); !reflect.DeepEqual(got, event) {
+		t.Errorf("nil Transformer changed the event: %v", got)
+	}
+}
@@ -19,6 +19,13 @@ const (
 	OperationTruncate Operation = "TRUNCATE"
 )
 
+// MetadataUnchangedToast is the event metadata key listing the columns an
+// UPDATE left unchanged whose values the source could not restore, such as
+// unchanged TOAST values in PostgreSQL. They are left out of the event's
+// After; a sink that writes rows by key must keep the columns' prior values
+// rather than clearing them.
+const MetadataUnchangedToast = "unchanged_toast"
+
 // Event represents a single CDC event captured from the source database.
 type Event struct {
 	// ID is the unique identifier for this event.
@@ -127,6 +134,24 @@ func (e *Event) HasAfter() bool {
 	return len(e.After) > 0
 }
 
+// UnchangedToast returns the columns listed under MetadataUnchangedToast.
+// Events read back from the buffer list them as decoded JSON.
+func (e *Event) UnchangedToast() []string {
+	switch columns := e.Metadata[MetadataUnchangedToast].(type) {
+	case []string:
+		return columns
+	case []any:
+		names := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if name, ok := column.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
 // FullyQualifiedName returns the fully qualified table name (schema.table).
 func (t *TableSchema) FullyQualifiedName() string {
 	return t.Schema + "." + t.Table
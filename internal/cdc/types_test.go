@@ -150,3 +150,30 @@ func TestCheckpoint(t *testing.T) {
 		t.Errorf("Metadata[version] = %v, want %q", cp.Metadata["version"], "1.0")
 	}
 }
+
+func TestEvent_UnchangedToast(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		want     []string
+	}{
+		{"none", nil, nil},
+		{"from the source", map[string]any{MetadataUnchangedToast: []string{"body"}}, []string{"body"}},
+		{"from the buffer", map[string]any{MetadataUnchangedToast: []any{"attrs", "body"}}, []string{"attrs", "body"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Event{Metadata: tt.metadata}
+			got := e.UnchangedToast()
+			if len(got) != len(tt.want) {
+				t.Fatalf("UnchangedToast() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("UnchangedToast() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
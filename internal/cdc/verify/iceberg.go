@@ -0,0 +1,169 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+)
+
+// DataFileReader reads the rows of an Iceberg data file, keyed by column.
+type DataFileReader interface {
+	ReadRows(ctx context.Context, filePath string) ([]map[string]any, error)
+}
+
+// IcebergCounter counts rows of an Iceberg table from its snapshot
+// summaries, without reading data files. With a DataFileReader it also
+// checksums the rows a table snapshot wrote.
+type IcebergCounter struct {
+	catalog catalog.Catalog
+	files   DataFileReader
+}
+
+// NewIcebergCounter creates a new IcebergCounter.
+func NewIcebergCounter(cat catalog.Catalog) *IcebergCounter {
+	return &IcebergCounter{catalog: cat}
+}
+
+// SetDataFileReader sets the reader data files are checksummed with.
+func (c *IcebergCounter) SetDataFileReader(files DataFileReader) {
+	c.files = files
+}
+
+// CountRows counts the rows of a table. For a table with a StartLSN only
+// the rows its snapshot wrote are counted, so rows streamed since, or
+// written by an earlier snapshot of the table, do not show up as a
+// discrepancy. Otherwise, or if the writer marked no commits as holding
+// snapshot rows, as when it merges changes into the table, the total
+// record count of the table's current snapshot is returned.
+func (c *IcebergCounter) CountRows(ctx context.Context, t Table) (int64, error) {
+	meta, err := c.loadTable(ctx, t)
+	if err != nil || meta == nil {
+		return 0, err
+	}
+	if t.StartLSN == "" {
+		return meta.SummarizeFiles().Records, nil
+	}
+
+	commits, marked, err := snapshotCommits(meta, t.StartLSN)
+	if err != nil {
+		return 0, fmt.Errorf("count snapshot rows of %s: %w", t, err)
+	}
+	if !marked {
+		return meta.SummarizeFiles().Records, nil
+	}
+	var count int64
+	for _, snap := range commits {
+		n, err := strconv.ParseInt(snap.Summary[iceberg.SummarySnapshotRecords], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("count snapshot rows of %s: invalid record count in snapshot %d", t, snap.SnapshotID)
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// Checksums computes the KeyChecksum of the keys in each range over the
+// rows the table's snapshot wrote, reading the data files the writer marked
+// as holding them. It returns ErrChecksumUnsupported without a
+// DataFileReader, a StartLSN or marked data files.
+func (c *IcebergCounter) Checksums(ctx context.Context, t Table, ranges []KeyRange) ([]string, error) {
+	if c.files == nil || t.StartLSN == "" {
+		return nil, ErrChecksumUnsupported
+	}
+	meta, err := c.loadTable(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("%w: iceberg table %s does not exist", ErrChecksumUnsupported, t)
+	}
+
+	commits, marked, err := snapshotCommits(meta, t.StartLSN)
+	if err != nil {
+		return nil, fmt.Errorf("checksum %s: %w", t, err)
+	}
+	if !marked {
+		return nil, fmt.Errorf("%w: no data files of %s are marked as snapshot rows", ErrChecksumUnsupported, t)
+	}
+
+	sums := make([]KeyChecksum, len(ranges))
+	for _, snap := range commits {
+		file := snap.Summary[iceberg.SummarySnapshotFile]
+		rows, err := c.files.ReadRows(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		for _, row := range rows {
+			key, ok := row[t.KeyColumn]
+			if !ok || key == nil {
+				return nil, fmt.Errorf("checksum %s: a row of %s has no key column %q", t, file, t.KeyColumn)
+			}
+			for i, r := range ranges {
+				if r.Contains(key) {
+					sums[i].Add(key)
+					break
+				}
+			}
+		}
+	}
+
+	result := make([]string, len(sums))
+	for i := range sums {
+		result[i] = sums[i].String()
+	}
+	return result, nil
+}
+
+// loadTable loads a table's metadata, or returns nil if it does not exist.
+func (c *IcebergCounter) loadTable(ctx context.Context, t Table) (*iceberg.TableMetadata, error) {
+	exists, err := c.catalog.TableExists(ctx, t.Schema, t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("check iceberg table %s: %w", t, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	meta, err := c.catalog.LoadTable(ctx, t.Schema, t.Name)
+	if err != nil {
+		return nil, fmt.Errorf("load iceberg table %s: %w", t, err)
+	}
+	return meta, nil
+}
+
+// snapshotCommits returns the snapshots of main that hold rows of a table
+// snapshot started at start: those the writer marked with a snapshot LSN
+// of start or later. marked reports whether the writer marked any
+// snapshot at all.
+func snapshotCommits(meta *iceberg.TableMetadata, start string) (commits []iceberg.Snapshot, marked bool, err error) {
+	startLSN, err := cdc.ParseLSN(start)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, snap := range meta.Ancestry() {
+		value, ok := snap.Summary[iceberg.SummarySnapshotLSN]
+		if !ok {
+			continue
+		}
+		marked = true
+		lsn, err := cdc.ParseLSN(value)
+		if err != nil {
+			return nil, true, fmt.Errorf("snapshot %d: %w", snap.SnapshotID, err)
+		}
+		if lsn >= startLSN {
+			commits = append(commits, snap)
+		}
+	}
+	return commits, marked, nil
+}
+
+// Ensure IcebergCounter implements the verification interfaces.
+var (
+	_ Counter     = (*IcebergCounter)(nil)
+	_ Checksummer = (*IcebergCounter)(nil)
+)
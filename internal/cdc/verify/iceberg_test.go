@@ -0,0 +1,149 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+)
+
+// fakeCatalog holds the metadata of a single table in memory.
+type fakeCatalog struct {
+	catalog.Catalog
+
+	meta *iceberg.TableMetadata
+}
+
+func (c *fakeCatalog) TableExists(context.Context, string, string) (bool, error) {
+	return c.meta != nil, nil
+}
+
+func (c *fakeCatalog) LoadTable(context.Context, string, string) (*iceberg.TableMetadata, error) {
+	return c.meta, nil
+}
+
+// commit appends a snapshot adding records rows. A non-empty snapshotLSN
+// marks them as snapshot rows, as the writer does.
+func (c *fakeCatalog) commit(records int64, snapshotLSN, file string) {
+	if c.meta == nil {
+		c.meta = &iceberg.TableMetadata{}
+	}
+	total := records
+	if current := c.meta.CurrentSnapshot(); current != nil {
+		prev, _ := strconv.ParseInt(current.Summary[iceberg.SummaryTotalRecords], 10, 64)
+		total += prev
+	}
+
+	summary := map[string]string{
+		iceberg.SummaryAddedRecords: strconv.FormatInt(records, 10),
+		iceberg.SummaryTotalRecords: strconv.FormatInt(total, 10),
+	}
+	if snapshotLSN != "" {
+		summary[iceberg.SummarySnapshotLSN] = snapshotLSN
+		summary[iceberg.SummarySnapshotRecords] = strconv.FormatInt(records, 10)
+		summary[iceberg.SummarySnapshotFile] = file
+	}
+
+	id := int64(len(c.meta.Snapshots) + 1)
+	c.meta.Snapshots = append(c.meta.Snapshots, iceberg.Snapshot{SnapshotID: id, ParentSnapshotID: c.meta.CurrentSnapshotID, Summary: summary})
+	c.meta.CurrentSnapshotID = id
+}
+
+func TestIcebergCounter_CountsRowsOfThisSnapshot(t *testing.T) {
+	cat := &fakeCatalog{}
+	// The first snapshot of the table, and changes streamed since
+	cat.commit(100, "0/100", "s3://bucket/a.parquet")
+	cat.commit(7, "", "")
+	// A re-snapshot, interleaved with streamed changes
+	cat.commit(60, "0/900", "s3://bucket/b.parquet")
+	cat.commit(3, "", "")
+	cat.commit(40, "0/950", "s3://bucket/c.parquet")
+
+	counter := NewIcebergCounter(cat)
+	ctx := context.Background()
+
+	table := testTable
+	table.StartLSN = "0/900"
+	if got, err := counter.CountRows(ctx, table); err != nil || got != 100 {
+		t.Errorf("CountRows() = %d, %v, want the 100 rows of the re-snapshot", got, err)
+	}
+
+	// Without a start position the whole table is counted
+	if got, err := counter.CountRows(ctx, testTable); err != nil || got != 210 {
+		t.Errorf("CountRows() = %d, %v, want all 210 rows", got, err)
+	}
+}
+
+func TestIcebergCounter_UnmarkedTableCountedInFull(t *testing.T) {
+	cat := &fakeCatalog{}
+	cat.commit(5, "", "")
+	cat.commit(2, "", "")
+
+	table := testTable
+	table.StartLSN = "0/900"
+	if got, err := NewIcebergCounter(cat).CountRows(context.Background(), table); err != nil || got != 7 {
+		t.Errorf("CountRows() = %d, %v, want the table total", got, err)
+	}
+
+	if got, err := NewIcebergCounter(&fakeCatalog{}).CountRows(context.Background(), table); err != nil || got != 0 {
+		t.Errorf("CountRows() of a missing table = %d, %v, want 0", got, err)
+	}
+}
+
+// fakeFiles serves the rows of data files from memory.
+type fakeFiles map[string][]map[string]any
+
+func (f fakeFiles) ReadRows(_ context.Context, filePath string) ([]map[string]any, error) {
+	return f[filePath], nil
+}
+
+func TestIcebergCounter_ChecksumsRowsOfThisSnapshot(t *testing.T) {
+	rows := func(ids ...string) []map[string]any {
+		result := make([]map[string]any, len(ids))
+		for i, id := range ids {
+			result[i] = map[string]any{"id": json.Number(id), "name": "row"}
+		}
+		return result
+	}
+
+	cat := &fakeCatalog{}
+	cat.commit(2, "0/100", "s3://bucket/old.parquet")
+	cat.commit(3, "0/900", "s3://bucket/a.parquet")
+	cat.commit(1, "", "")
+	cat.commit(2, "0/950", "s3://bucket/b.parquet")
+	files := fakeFiles{
+		"s3://bucket/old.parquet": rows("1", "2"),
+		"s3://bucket/a.parquet":   rows("3", "1", "2"),
+		"s3://bucket/b.parquet":   rows("20", "10"),
+	}
+
+	counter := NewIcebergCounter(cat)
+	table := testTable
+	table.StartLSN = "0/900"
+	ranges := []KeyRange{{Through: int64(9)}, {After: int64(9)}}
+
+	if _, err := counter.Checksums(context.Background(), table, ranges); !errors.Is(err, ErrChecksumUnsupported) {
+		t.Fatalf("Checksums() without a data file reader error = %v, want ErrChecksumUnsupported", err)
+	}
+
+	counter.SetDataFileReader(files)
+	sums, err := counter.Checksums(context.Background(), table, ranges)
+	if err != nil {
+		t.Fatalf("Checksums() error = %v", err)
+	}
+	// The sum of the first 60 bits of md5("1"), md5("2") and md5("3"), as
+	// PostgreSQL computes it
+	if sums[0] != "3:2853953765945779037" {
+		t.Errorf("checksum of keys 1-3 = %s, want 3:2853953765945779037", sums[0])
+	}
+	var want KeyChecksum
+	want.Add(int64(10))
+	want.Add(int64(20))
+	if sums[1] != want.String() {
+		t.Errorf("checksum of keys 10 and 20 = %s, want %s", sums[1], want.String())
+	}
+}
@@ -0,0 +1,75 @@
+package verify
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// KeyText returns a key value as PostgreSQL casts it to text, the form key
+// checksums are computed over.
+func KeyText(key any) string {
+	switch v := key.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.Number:
+		return string(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case [16]byte:
+		s := hex.EncodeToString(v[:])
+		return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// CompareKeys orders two key values: numerically when both are numbers, and
+// by their text otherwise, which matches PostgreSQL for integer, uuid and
+// C-collated text keys.
+func CompareKeys(a, b any) int {
+	if x, ok := keyNumber(a); ok {
+		if y, ok := keyNumber(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	return strings.Compare(KeyText(a), KeyText(b))
+}
+
+// keyNumber returns a numeric key value as a rational.
+func keyNumber(key any) (*big.Rat, bool) {
+	switch key.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return new(big.Rat).SetString(KeyText(key))
+	}
+	return nil, false
+}
+
+// KeyChecksum accumulates an order-independent checksum of keys: their
+// count and the sum of the first 60 bits of the MD5 of each key's text.
+// PostgresCounter computes the same checksum in SQL.
+type KeyChecksum struct {
+	count int64
+	sum   big.Int
+}
+
+// Add adds a key to the checksum.
+func (c *KeyChecksum) Add(key any) {
+	digest := md5.Sum([]byte(KeyText(key)))
+	bits, _ := strconv.ParseUint(hex.EncodeToString(digest[:])[:15], 16, 64)
+	c.count++
+	c.sum.Add(&c.sum, new(big.Int).SetUint64(bits))
+}
+
+// String returns the checksum as "count:sum".
+func (c *KeyChecksum) String() string {
+	return strconv.FormatInt(c.count, 10) + ":" + c.sum.String()
+}
@@ -0,0 +1,168 @@
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+)
+
+// PostgresCounter counts and checksums rows in a PostgreSQL source table.
+// Reads run in a repeatable-read transaction pinned to the table's exported
+// snapshot when one is set, so counts match the state the backfill read.
+type PostgresCounter struct {
+	db *sql.DB
+}
+
+// NewPostgresCounter creates a new PostgresCounter.
+func NewPostgresCounter(db *sql.DB) *PostgresCounter {
+	return &PostgresCounter{db: db}
+}
+
+// CountRows counts the rows of a table.
+func (c *PostgresCounter) CountRows(ctx context.Context, t Table) (int64, error) {
+	var count int64
+	err := c.inSnapshot(ctx, t, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, "SELECT count(*) FROM "+qualifiedName(t)).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count rows of %s: %w", t, err)
+	}
+	return count, nil
+}
+
+// Checksums computes the KeyChecksum of the keys in each range, summing the
+// first 60 bits of each key's MD5 so the checksum does not depend on order.
+func (c *PostgresCounter) Checksums(ctx context.Context, t Table, ranges []KeyRange) ([]string, error) {
+	key := quoteIdent(t.KeyColumn)
+	sums := make([]string, len(ranges))
+	err := c.inSnapshot(ctx, t, func(tx *sql.Tx) error {
+		for i, r := range ranges {
+			where, args := rangePredicate(key, r)
+			query := fmt.Sprintf(
+				"SELECT count(*), coalesce(sum(('x' || substr(md5(%s::text), 1, 15))::bit(60)::bigint), 0)::text FROM %s%s",
+				key, qualifiedName(t), where,
+			)
+			var count int64
+			var sum string
+			if err := tx.QueryRowContext(ctx, query, args...).Scan(&count, &sum); err != nil {
+				return err
+			}
+			sums[i] = strconv.FormatInt(count, 10) + ":" + sum
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checksum %s: %w", t, err)
+	}
+	return sums, nil
+}
+
+// SampleRanges splits the key space of a table into n ranges of roughly
+// equal size. The first and last ranges are unbounded, so together the
+// ranges cover every key.
+func (c *PostgresCounter) SampleRanges(ctx context.Context, t Table, n int) ([]KeyRange, error) {
+	key := quoteIdent(t.KeyColumn)
+	query := fmt.Sprintf(
+		"SELECT max(%s) FROM (SELECT %s, ntile($1) OVER (ORDER BY %s) AS bucket FROM %s) s GROUP BY bucket ORDER BY max(%s)",
+		key, key, key, qualifiedName(t), key,
+	)
+
+	var bounds []any
+	err := c.inSnapshot(ctx, t, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, n)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var bound any
+			if err := rows.Scan(&bound); err != nil {
+				return err
+			}
+			bounds = append(bounds, bound)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sample key ranges of %s: %w", t, err)
+	}
+	return rangesThrough(bounds), nil
+}
+
+// rangesThrough returns the ranges ending at each bound, the last one
+// unbounded above. No bounds give one range over the whole key space.
+func rangesThrough(bounds []any) []KeyRange {
+	if len(bounds) == 0 {
+		return []KeyRange{{}}
+	}
+	ranges := make([]KeyRange, len(bounds))
+	for i, bound := range bounds {
+		if i > 0 {
+			ranges[i].After = bounds[i-1]
+		}
+		if i+1 < len(bounds) {
+			ranges[i].Through = bound
+		}
+	}
+	return ranges
+}
+
+// inSnapshot runs fn in a read-only repeatable-read transaction, importing
+// the table's exported snapshot when set.
+func (c *PostgresCounter) inSnapshot(ctx context.Context, t Table, fn func(tx *sql.Tx) error) error {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if t.SnapshotName != "" {
+		if _, err := tx.ExecContext(ctx, "SET TRANSACTION SNAPSHOT "+quoteLiteral(t.SnapshotName)); err != nil {
+			return fmt.Errorf("import snapshot %s: %w", t.SnapshotName, err)
+		}
+	}
+
+	return fn(tx)
+}
+
+// rangePredicate builds a WHERE clause for a key range.
+func rangePredicate(key string, r KeyRange) (string, []any) {
+	var conds []string
+	var args []any
+	if r.After != nil {
+		args = append(args, r.After)
+		conds = append(conds, fmt.Sprintf("%s > $%d", key, len(args)))
+	}
+	if r.Through != nil {
+		args = append(args, r.Through)
+		conds = append(conds, fmt.Sprintf("%s <= $%d", key, len(args)))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func qualifiedName(t Table) string {
+	return quoteIdent(t.Schema) + "." + quoteIdent(t.Name)
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Ensure PostgresCounter implements the verification interfaces.
+var (
+	_ Counter      = (*PostgresCounter)(nil)
+	_ Checksummer  = (*PostgresCounter)(nil)
+	_ RangeSampler = (*PostgresCounter)(nil)
+)
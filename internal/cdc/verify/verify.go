@@ -0,0 +1,302 @@
+// Package verify provides post-snapshot verification that backfilled data in
+// Iceberg matches the source database.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// Mode selects how thoroughly a snapshot is verified.
+type Mode string
+
+const (
+	// ModeOff disables verification.
+	ModeOff Mode = "off"
+	// ModeCount compares row counts only.
+	ModeCount Mode = "count"
+	// ModeChecksum compares row counts and checksums over sampled key ranges.
+	ModeChecksum Mode = "checksum"
+)
+
+// ParseMode parses a verification mode. An empty string means ModeOff.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeOff:
+		return ModeOff, nil
+	case ModeCount, ModeChecksum:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid verification mode %q (want off, count or checksum)", s)
+}
+
+// ErrChecksumUnsupported is returned by a Checksummer that cannot checksum a table.
+var ErrChecksumUnsupported = errors.New("checksum not supported")
+
+// Table identifies a snapshotted table and the point it was snapshotted at.
+type Table struct {
+	// Schema is the source schema, which is also the Iceberg namespace.
+	Schema string
+
+	// Name is the table name.
+	Name string
+
+	// KeyColumn is the primary key column the snapshot read the table by.
+	KeyColumn string
+
+	// SnapshotName is the exported PostgreSQL snapshot the backfill read from.
+	// When set, source rows are counted as of that snapshot.
+	SnapshotName string
+
+	// SnapshotLSN is the LSN the snapshot was consistent at.
+	SnapshotLSN string
+
+	// StartLSN is the LSN the snapshot started reading the table at. The
+	// rows it wrote carry this LSN or a later one, which tells them apart
+	// from rows an earlier snapshot of the table wrote.
+	StartLSN string
+}
+
+// String returns the qualified table name.
+func (t Table) String() string {
+	return t.Schema + "." + t.Name
+}
+
+// KeyRange is a primary key range: keys greater than After, up to and
+// including Through. A nil bound is unbounded. Bounds are key values as the
+// source database returns them.
+type KeyRange struct {
+	After   any `json:"after,omitempty"`
+	Through any `json:"through,omitempty"`
+}
+
+// Contains reports whether key is in the range.
+func (r KeyRange) Contains(key any) bool {
+	if r.After != nil && CompareKeys(key, r.After) <= 0 {
+		return false
+	}
+	return r.Through == nil || CompareKeys(key, r.Through) <= 0
+}
+
+// Counter counts the rows of a table on one side of a verification.
+type Counter interface {
+	CountRows(ctx context.Context, t Table) (int64, error)
+}
+
+// Checksummer computes an order-independent checksum of the keys in each of
+// a table's key ranges. Both sides of a verification must compute it the
+// same way, with KeyChecksum.
+type Checksummer interface {
+	Checksums(ctx context.Context, t Table, ranges []KeyRange) ([]string, error)
+}
+
+// RangeSampler picks key ranges to checksum.
+type RangeSampler interface {
+	SampleRanges(ctx context.Context, t Table, n int) ([]KeyRange, error)
+}
+
+// Rerunner re-runs the backfill of a table. A nil ranges slice means the whole table.
+type Rerunner interface {
+	Rerun(ctx context.Context, t Table, ranges []KeyRange) error
+}
+
+// Config holds verifier configuration.
+type Config struct {
+	// Mode selects the verification mode.
+	Mode Mode
+
+	// SampleRanges is the number of key ranges to checksum in checksum mode.
+	SampleRanges int
+
+	// RerunOnMismatch re-runs the affected ranges when verification fails.
+	RerunOnMismatch bool
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Mode:         ModeCount,
+		SampleRanges: 10,
+	}
+}
+
+// Result is the outcome of verifying one table.
+type Result struct {
+	Table            string        `json:"table"`
+	Mode             Mode          `json:"mode"`
+	Passed           bool          `json:"passed"`
+	SourceRows       int64         `json:"source_rows"`
+	TargetRows       int64         `json:"target_rows"`
+	Discrepancies    int64         `json:"discrepancies"`
+	RangesChecked    int           `json:"ranges_checked"`
+	MismatchedRanges []KeyRange    `json:"mismatched_ranges,omitempty"`
+	ChecksumSkipped  bool          `json:"checksum_skipped,omitempty"`
+	Rerun            bool          `json:"rerun,omitempty"`
+	Duration         time.Duration `json:"duration"`
+	VerifiedAt       time.Time     `json:"verified_at"`
+}
+
+// Verifier compares a backfilled Iceberg table with its source table.
+type Verifier struct {
+	config     Config
+	sourceName string
+	source     Counter
+	target     Counter
+	rerunner   Rerunner
+	logger     *slog.Logger
+}
+
+// New creates a new Verifier. source and target may additionally implement
+// Checksummer (and source RangeSampler) to support checksum mode.
+func New(cfg Config, sourceName string, source, target Counter, logger *slog.Logger) *Verifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.SampleRanges <= 0 {
+		cfg.SampleRanges = DefaultConfig().SampleRanges
+	}
+
+	return &Verifier{
+		config:     cfg,
+		sourceName: sourceName,
+		source:     source,
+		target:     target,
+		logger:     logger.With("component", "snapshot-verifier", "source", sourceName),
+	}
+}
+
+// SetRerunner sets the rerunner used when RerunOnMismatch is enabled.
+func (v *Verifier) SetRerunner(r Rerunner) {
+	v.rerunner = r
+}
+
+// Enabled reports whether verification is enabled.
+func (v *Verifier) Enabled() bool {
+	return v.config.Mode != ModeOff && v.config.Mode != ""
+}
+
+// Verify verifies a snapshotted table. A failed verification is reported via
+// the returned Result, not as an error; errors are reserved for failures to
+// run the verification at all.
+func (v *Verifier) Verify(ctx context.Context, t Table) (*Result, error) {
+	start := time.Now()
+	result := &Result{
+		Table:      t.String(),
+		Mode:       v.config.Mode,
+		VerifiedAt: start,
+	}
+	if !v.Enabled() {
+		result.Passed = true
+		return result, nil
+	}
+
+	var err error
+	if result.SourceRows, err = v.source.CountRows(ctx, t); err != nil {
+		return nil, fmt.Errorf("count source rows: %w", err)
+	}
+	if result.TargetRows, err = v.target.CountRows(ctx, t); err != nil {
+		return nil, fmt.Errorf("count target rows: %w", err)
+	}
+	result.Discrepancies = abs(result.SourceRows - result.TargetRows)
+
+	if v.config.Mode == ModeChecksum {
+		if err := v.compareChecksums(ctx, t, result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Passed = result.Discrepancies == 0
+	result.Duration = time.Since(start)
+
+	if !result.Passed && v.config.RerunOnMismatch && v.rerunner != nil {
+		// Re-run only the mismatched ranges when checksums localized the
+		// discrepancy; otherwise re-run the whole table.
+		if err := v.rerunner.Rerun(ctx, t, result.MismatchedRanges); err != nil {
+			v.logger.Error("failed to re-run backfill", "table", result.Table, "error", err)
+		} else {
+			result.Rerun = true
+		}
+	}
+
+	v.report(result)
+	return result, nil
+}
+
+// compareChecksums checksums sampled key ranges on both sides.
+func (v *Verifier) compareChecksums(ctx context.Context, t Table, result *Result) error {
+	sampler, ok := v.source.(RangeSampler)
+	srcSum, srcOK := v.source.(Checksummer)
+	dstSum, dstOK := v.target.(Checksummer)
+	if !ok || !srcOK || !dstOK || t.KeyColumn == "" {
+		v.logger.Warn("checksum verification unavailable, falling back to count", "table", result.Table)
+		result.ChecksumSkipped = true
+		return nil
+	}
+
+	ranges, err := sampler.SampleRanges(ctx, t, v.config.SampleRanges)
+	if err != nil {
+		return fmt.Errorf("sample key ranges: %w", err)
+	}
+
+	got, err := dstSum.Checksums(ctx, t, ranges)
+	if errors.Is(err, ErrChecksumUnsupported) {
+		v.logger.Warn("target cannot checksum the table, falling back to count", "table", result.Table, "error", err)
+		result.ChecksumSkipped = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("checksum target ranges: %w", err)
+	}
+	want, err := srcSum.Checksums(ctx, t, ranges)
+	if err != nil {
+		return fmt.Errorf("checksum source ranges: %w", err)
+	}
+
+	result.RangesChecked = len(ranges)
+	for i, r := range ranges {
+		if want[i] != got[i] {
+			result.MismatchedRanges = append(result.MismatchedRanges, r)
+		}
+	}
+	result.Discrepancies += int64(len(result.MismatchedRanges))
+	return nil
+}
+
+// report logs the result and records metrics. The discrepancy gauge backs the
+// critical snapshot verification alert rule.
+func (v *Verifier) report(result *Result) {
+	status := "passed"
+	if !result.Passed {
+		status = "failed"
+	}
+	metrics.SnapshotVerificationsTotal.WithLabelValues(v.sourceName, result.Table, status).Inc()
+	metrics.SnapshotVerificationDiscrepancies.WithLabelValues(v.sourceName, result.Table).Set(float64(result.Discrepancies))
+
+	attrs := []any{
+		"table", result.Table,
+		"mode", result.Mode,
+		"source_rows", result.SourceRows,
+		"target_rows", result.TargetRows,
+		"discrepancies", result.Discrepancies,
+		"ranges_checked", result.RangesChecked,
+		"duration", result.Duration,
+	}
+	if result.Passed {
+		v.logger.Info("snapshot verification passed", attrs...)
+		return
+	}
+	v.logger.Error("snapshot verification failed",
+		append(attrs, "mismatched_ranges", len(result.MismatchedRanges), "rerun", result.Rerun)...)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,196 @@
+package verify
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// memTable is an in-memory table side keyed by primary key.
+type memTable struct {
+	keys []string
+}
+
+func (m *memTable) CountRows(_ context.Context, _ Table) (int64, error) {
+	return int64(len(m.keys)), nil
+}
+
+func (m *memTable) Checksums(_ context.Context, _ Table, ranges []KeyRange) ([]string, error) {
+	sums := make([]string, len(ranges))
+	for i, r := range ranges {
+		var sum KeyChecksum
+		for _, k := range m.keys {
+			if r.Contains(k) {
+				sum.Add(k)
+			}
+		}
+		sums[i] = sum.String()
+	}
+	return sums, nil
+}
+
+func (m *memTable) SampleRanges(_ context.Context, _ Table, _ int) ([]KeyRange, error) {
+	return []KeyRange{{Through: "b"}, {After: "b"}}, nil
+}
+
+type recordingRerunner struct {
+	calls  int
+	ranges []KeyRange
+}
+
+func (r *recordingRerunner) Rerun(_ context.Context, _ Table, ranges []KeyRange) error {
+	r.calls++
+	r.ranges = ranges
+	return nil
+}
+
+var testTable = Table{Schema: "public", Name: "users", KeyColumn: "id"}
+
+func TestParseMode(t *testing.T) {
+	for s, want := range map[string]Mode{"": ModeOff, "off": ModeOff, "count": ModeCount, "checksum": ModeChecksum} {
+		if got, err := ParseMode(s); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q", s, got, err, want)
+		}
+	}
+	if _, err := ParseMode("full"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestVerify_CountMatch(t *testing.T) {
+	src := &memTable{keys: []string{"a", "b", "c"}}
+	dst := &memTable{keys: []string{"a", "b", "c"}}
+	v := New(Config{Mode: ModeCount}, "test", src, dst, nil)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Passed || result.Discrepancies != 0 {
+		t.Errorf("expected pass, got %+v", result)
+	}
+	if result.SourceRows != 3 || result.TargetRows != 3 {
+		t.Errorf("rows = %d/%d, want 3/3", result.SourceRows, result.TargetRows)
+	}
+}
+
+func TestVerify_CountMismatchRerunsTable(t *testing.T) {
+	src := &memTable{keys: []string{"a", "b", "c", "d"}}
+	dst := &memTable{keys: []string{"a", "b"}}
+	v := New(Config{Mode: ModeCount, RerunOnMismatch: true}, "test", src, dst, nil)
+	rerunner := &recordingRerunner{}
+	v.SetRerunner(rerunner)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Passed || result.Discrepancies != 2 {
+		t.Errorf("expected 2 discrepancies, got %+v", result)
+	}
+	if rerunner.calls != 1 || rerunner.ranges != nil || !result.Rerun {
+		t.Errorf("expected whole-table rerun, got calls=%d ranges=%v", rerunner.calls, rerunner.ranges)
+	}
+}
+
+func TestVerify_ChecksumDetectsInjectedDiscrepancy(t *testing.T) {
+	src := &memTable{keys: []string{"a", "b", "c", "d"}}
+	// Same row count, but "d" was replaced by "e" in the target.
+	dst := &memTable{keys: []string{"a", "b", "c", "e"}}
+	v := New(Config{Mode: ModeChecksum, RerunOnMismatch: true}, "test", src, dst, nil)
+	rerunner := &recordingRerunner{}
+	v.SetRerunner(rerunner)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Passed {
+		t.Fatal("expected verification to fail")
+	}
+	if result.RangesChecked != 2 || len(result.MismatchedRanges) != 1 {
+		t.Fatalf("ranges checked=%d mismatched=%v", result.RangesChecked, result.MismatchedRanges)
+	}
+	if result.MismatchedRanges[0] != (KeyRange{After: "b"}) {
+		t.Errorf("mismatched range = %+v, want {After: b}", result.MismatchedRanges[0])
+	}
+	if rerunner.calls != 1 || len(rerunner.ranges) != 1 || rerunner.ranges[0] != (KeyRange{After: "b"}) {
+		t.Errorf("expected rerun of the mismatched range only, got %v", rerunner.ranges)
+	}
+}
+
+func TestVerify_ChecksumMatch(t *testing.T) {
+	src := &memTable{keys: []string{"a", "b", "c"}}
+	// The target holds the same keys in another order
+	dst := &memTable{keys: []string{"c", "a", "b"}}
+	v := New(Config{Mode: ModeChecksum, RerunOnMismatch: true}, "test", src, dst, nil)
+	rerunner := &recordingRerunner{}
+	v.SetRerunner(rerunner)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Passed || result.RangesChecked != 2 || result.ChecksumSkipped {
+		t.Errorf("expected checksums to match, got %+v", result)
+	}
+	if rerunner.calls != 0 {
+		t.Errorf("expected no rerun, got %d", rerunner.calls)
+	}
+}
+
+func TestVerify_ChecksumUnsupportedFallsBackToCount(t *testing.T) {
+	src := &memTable{keys: []string{"a", "b"}}
+	v := New(Config{Mode: ModeChecksum}, "test", src, countOnly{n: 2}, nil)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Passed || !result.ChecksumSkipped {
+		t.Errorf("expected count-only pass with checksum skipped, got %+v", result)
+	}
+}
+
+func TestVerify_Off(t *testing.T) {
+	v := New(Config{Mode: ModeOff}, "test", countOnly{n: 1}, countOnly{n: 2}, nil)
+
+	result, err := v.Verify(context.Background(), testTable)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Passed {
+		t.Error("expected disabled verification to pass")
+	}
+}
+
+type countOnly struct {
+	n int64
+}
+
+func (c countOnly) CountRows(_ context.Context, _ Table) (int64, error) {
+	return c.n, nil
+}
+
+func TestRangePredicate(t *testing.T) {
+	where, args := rangePredicate(`"id"`, KeyRange{After: int64(10), Through: int64(20)})
+	if where != ` WHERE "id" > $1 AND "id" <= $2` || len(args) != 2 {
+		t.Errorf("where = %q, args = %v", where, args)
+	}
+
+	where, args = rangePredicate(`"id"`, KeyRange{})
+	if where != "" || args != nil {
+		t.Errorf("expected empty predicate, got %q %v", where, args)
+	}
+}
+
+func TestRangesThrough(t *testing.T) {
+	ranges := rangesThrough([]any{int64(10), int64(20), int64(30)})
+	want := []KeyRange{{Through: int64(10)}, {After: int64(10), Through: int64(20)}, {After: int64(20)}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("rangesThrough() = %v, want %v", ranges, want)
+	}
+	if ranges := rangesThrough(nil); !reflect.DeepEqual(ranges, []KeyRange{{}}) {
+		t.Errorf("rangesThrough(nil) = %v, want the whole key space", ranges)
+	}
+}
@@ -0,0 +1,474 @@
+// Package client provides a Go client for the Philotes management API.
+// It supports multiple API endpoints with health checking and failover so
+// that the CLI keeps working during rolling restarts of HA deployments.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyKeyHeader is the header identifying a logical create request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// APIKeyHeader is the header used for API key authentication.
+	APIKeyHeader = "X-API-Key"
+
+	// healthPath is the liveness endpoint used to probe endpoints.
+	healthPath = "/health/live"
+)
+
+// Config holds API client configuration.
+type Config struct {
+	// BaseURLs is the list of API base URLs to balance across.
+	BaseURLs []string
+
+	// APIKey is sent in the X-API-Key header when set.
+	APIKey string
+
+	// Timeout is the per-attempt HTTP timeout.
+	Timeout time.Duration
+
+	// MaxAttempts is the maximum number of attempts per request across all endpoints.
+	MaxAttempts int
+
+	// HealthCheckInterval is how long an endpoint stays marked unhealthy
+	// before it is probed again.
+	HealthCheckInterval time.Duration
+
+	// MaxRetryAfter caps how long the client honors a Retry-After header.
+	MaxRetryAfter time.Duration
+
+	// HealthCheckTimeout is the timeout for a single endpoint health probe.
+	HealthCheckTimeout time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		BaseURLs:            []string{"http://localhost:8080"},
+		Timeout:             30 * time.Second,
+		MaxAttempts:         3,
+		HealthCheckInterval: 30 * time.Second,
+		MaxRetryAfter:       30 * time.Second,
+		HealthCheckTimeout:  5 * time.Second,
+	}
+}
+
+// endpoint tracks the health of a single API base URL.
+type endpoint struct {
+	baseURL        string
+	healthy        bool
+	unhealthySince time.Time
+}
+
+// Client is an API client that fails over between multiple endpoints.
+type Client struct {
+	config Config
+	http   *http.Client
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+
+	// sleep is overridable for tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a new API client.
+func New(cfg Config, logger *slog.Logger) (*Client, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	defaults := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = defaults.HealthCheckInterval
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = defaults.MaxRetryAfter
+	}
+	if cfg.HealthCheckTimeout <= 0 {
+		cfg.HealthCheckTimeout = defaults.HealthCheckTimeout
+	}
+
+	endpoints := make([]*endpoint, 0, len(cfg.BaseURLs))
+	for _, u := range cfg.BaseURLs {
+		u = strings.TrimRight(strings.TrimSpace(u), "/")
+		if u == "" {
+			continue
+		}
+		endpoints = append(endpoints, &endpoint{baseURL: u, healthy: true})
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one API base URL is required")
+	}
+
+	return &Client{
+		config:    cfg,
+		http:      &http.Client{Timeout: cfg.Timeout},
+		logger:    logger.With("component", "api-client"),
+		endpoints: endpoints,
+		sleep:     sleepContext,
+	}, nil
+}
+
+// Request describes a single API call.
+type Request struct {
+	// Method is the HTTP method.
+	Method string
+
+	// Path is the request path relative to the base URL (e.g. "/api/v1/pipelines").
+	Path string
+
+	// Body is JSON-encoded as the request body when non-nil.
+	Body any
+
+	// IdempotencyKey is sent in the Idempotency-Key header when set.
+	IdempotencyKey string
+}
+
+// retryable reports whether the request may be resent after it possibly
+// reached the server. POST is never resent in that case, since a create the
+// server already processed would be applied twice.
+func (r *Request) retryable() bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// APIError is returned when the API responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("api error %d: %s: %s", e.StatusCode, e.Title, e.Detail)
+	}
+	if e.Title != "" {
+		return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Title)
+	}
+	return fmt.Sprintf("api error %d", e.StatusCode)
+}
+
+// Get performs a GET request and decodes the response into out.
+func (c *Client) Get(ctx context.Context, path string, out any) error {
+	return c.Do(ctx, &Request{Method: http.MethodGet, Path: path}, out)
+}
+
+// Post performs a POST request with a generated idempotency key. The request
+// is only resent to another endpoint if the connection could not be established.
+func (c *Client) Post(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, &Request{
+		Method:         http.MethodPost,
+		Path:           path,
+		Body:           body,
+		IdempotencyKey: uuid.New().String(),
+	}, out)
+}
+
+// Put performs a PUT request and decodes the response into out.
+func (c *Client) Put(ctx context.Context, path string, body, out any) error {
+	return c.Do(ctx, &Request{Method: http.MethodPut, Path: path, Body: body}, out)
+}
+
+// Delete performs a DELETE request.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	return c.Do(ctx, &Request{Method: http.MethodDelete, Path: path}, nil)
+}
+
+// Do executes the request, failing over between endpoints on connection
+// errors and honoring Retry-After on 429 and 503 responses.
+func (c *Client) Do(ctx context.Context, req *Request, out any) error {
+	var payload []byte
+	if req.Body != nil {
+		var err error
+		payload, err = json.Marshal(req.Body)
+		if err != nil {
+			return fmt.Errorf("encode request body: %w", err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= c.config.MaxAttempts; attempt++ {
+		ep := c.pick()
+
+		resp, err := c.send(ctx, ep, req, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.markUnhealthy(ep, err)
+			lastErr = fmt.Errorf("%s %s%s: %w", req.Method, ep.baseURL, req.Path, err)
+
+			// A failed dial never reached the server, so any request is safe to resend.
+			if !isDialError(err) && !req.retryable() {
+				return lastErr
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			wait := c.retryAfter(resp)
+			apiErr := decodeAPIError(resp)
+			lastErr = apiErr
+			if !req.retryable() || attempt == c.config.MaxAttempts {
+				return apiErr
+			}
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				c.markUnhealthy(ep, apiErr)
+				// Failing over to another endpoint does not need to wait.
+				if c.hasOtherHealthy(ep) {
+					continue
+				}
+			}
+			if err := c.sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		c.markHealthy(ep)
+		return decodeResponse(resp, out)
+	}
+
+	return fmt.Errorf("all %d attempts failed: %w", c.config.MaxAttempts, lastErr)
+}
+
+// send performs a single HTTP attempt against an endpoint.
+func (c *Client) send(ctx context.Context, ep *endpoint, req *Request, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, ep.baseURL+req.Path, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set(IdempotencyKeyHeader, req.IdempotencyKey)
+	}
+	if c.config.APIKey != "" {
+		httpReq.Header.Set(APIKeyHeader, c.config.APIKey)
+	}
+
+	return c.http.Do(httpReq)
+}
+
+// pick returns the next healthy endpoint in round-robin order. Endpoints whose
+// unhealthy period has elapsed are considered again; if none are healthy the
+// least recently failed endpoint is used.
+func (c *Client) pick() *endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	n := len(c.endpoints)
+	for i := 0; i < n; i++ {
+		ep := c.endpoints[(c.next+i)%n]
+		if ep.healthy || now.Sub(ep.unhealthySince) >= c.config.HealthCheckInterval {
+			c.next = (c.next + i + 1) % n
+			return ep
+		}
+	}
+
+	oldest := c.endpoints[0]
+	for _, ep := range c.endpoints[1:] {
+		if ep.unhealthySince.Before(oldest.unhealthySince) {
+			oldest = ep
+		}
+	}
+	return oldest
+}
+
+// hasOtherHealthy reports whether pick can return an endpoint other than ep.
+func (c *Client) hasOtherHealthy(ep *endpoint) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, other := range c.endpoints {
+		if other == ep {
+			continue
+		}
+		if other.healthy || now.Sub(other.unhealthySince) >= c.config.HealthCheckInterval {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) markUnhealthy(ep *endpoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ep.healthy {
+		c.logger.Warn("API endpoint marked unhealthy", "endpoint", ep.baseURL, "error", err)
+	}
+	ep.healthy = false
+	ep.unhealthySince = time.Now()
+}
+
+func (c *Client) markHealthy(ep *endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !ep.healthy {
+		c.logger.Info("API endpoint recovered", "endpoint", ep.baseURL)
+	}
+	ep.healthy = true
+}
+
+// EndpointStatus describes the health of a configured endpoint.
+type EndpointStatus struct {
+	BaseURL string
+	Healthy bool
+	Error   string
+}
+
+// CheckHealth probes every endpoint's liveness endpoint concurrently and
+// updates its state. Each probe is bounded by HealthCheckTimeout.
+func (c *Client) CheckHealth(ctx context.Context) []EndpointStatus {
+	c.mu.Lock()
+	endpoints := make([]*endpoint, len(c.endpoints))
+	copy(endpoints, c.endpoints)
+	c.mu.Unlock()
+
+	statuses := make([]EndpointStatus, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			statuses[i] = c.probe(ctx, ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// probe checks a single endpoint's liveness endpoint.
+func (c *Client) probe(ctx context.Context, ep *endpoint) EndpointStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.config.HealthCheckTimeout)
+	defer cancel()
+
+	status := EndpointStatus{BaseURL: ep.baseURL}
+
+	resp, err := c.send(ctx, ep, &Request{Method: http.MethodGet, Path: healthPath}, nil)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	if err != nil {
+		c.markUnhealthy(ep, err)
+		status.Error = err.Error()
+	} else {
+		c.markHealthy(ep)
+		status.Healthy = true
+	}
+	return status
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date.
+func (c *Client) retryAfter(resp *http.Response) time.Duration {
+	wait := time.Second
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			wait = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			wait = time.Until(t)
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > c.config.MaxRetryAfter {
+		wait = c.config.MaxRetryAfter
+	}
+	return wait
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeAPIError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func decodeAPIError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+
+	apiErr := &APIError{}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = json.Unmarshal(body, apiErr)
+	apiErr.StatusCode = resp.StatusCode
+	return apiErr
+}
+
+// isDialError reports whether err occurred while establishing the connection.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, urls ...string) *Client {
+	t.Helper()
+
+	c, err := New(Config{BaseURLs: urls, MaxAttempts: 3}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return c
+}
+
+// deadURL returns the URL of a server that has already been shut down.
+func deadURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func TestNew_RequiresBaseURL(t *testing.T) {
+	if _, err := New(Config{BaseURLs: []string{" "}}, nil); err == nil {
+		t.Fatal("expected error for empty base URLs")
+	}
+}
+
+func TestDo_FailsOverToHealthyEndpoint(t *testing.T) {
+	var hits int32
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"1.2.3"}`))
+	}))
+	defer healthy.Close()
+
+	c := newTestClient(t, deadURL(), healthy.URL)
+
+	var out struct {
+		Version string `json:"version"`
+	}
+	if err := c.Get(context.Background(), "/api/v1/version", &out); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if out.Version != "1.2.3" {
+		t.Errorf("Version = %q, want %q", out.Version, "1.2.3")
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("healthy endpoint hits = %d, want 1", hits)
+	}
+
+	// The failed endpoint should now be skipped in favor of the healthy one.
+	if err := c.Get(context.Background(), "/api/v1/version", &out); err != nil {
+		t.Fatalf("second Get() error = %v", err)
+	}
+	if atomic.LoadInt32(&hits) != 2 {
+		t.Errorf("healthy endpoint hits = %d, want 2", hits)
+	}
+}
+
+func TestDo_HonorsRetryAfter(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	var waited time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waited = d
+		return nil
+	}
+
+	if err := c.Delete(context.Background(), "/api/v1/pipelines/x"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if waited != 7*time.Second {
+		t.Errorf("waited = %v, want %v", waited, 7*time.Second)
+	}
+	if atomic.LoadInt32(&hits) != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+}
+
+func TestDo_PostNotRetriedOnServiceUnavailable(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+
+	err := c.Do(context.Background(), &Request{Method: http.MethodPost, Path: "/api/v1/pipelines"}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("error = %v, want 503 APIError", err)
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("hits = %d, want 1", hits)
+	}
+}
+
+func TestPost_SendsIdempotencyKeyAndFailsOverOnDialError(t *testing.T) {
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get(IdempotencyKeyHeader)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, deadURL(), srv.URL)
+
+	if err := c.Post(context.Background(), "/api/v1/pipelines", map[string]string{"name": "p"}, nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if key == "" {
+		t.Error("expected Idempotency-Key header")
+	}
+}
+
+func TestPost_ReadTimeoutNotResent(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer other.Close()
+
+	c, err := New(Config{BaseURLs: []string{slow.URL, other.URL}, Timeout: 50 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := c.Post(context.Background(), "/api/v1/pipelines", map[string]string{"name": "p"}, nil); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("hits = %d, want 1", hits)
+	}
+}
+
+func TestDo_ServiceUnavailableFailsOverWithoutWaiting(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer healthy.Close()
+
+	c := newTestClient(t, unavailable.URL, healthy.URL)
+	slept := false
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = true
+		return nil
+	}
+
+	if err := c.Get(context.Background(), "/api/v1/pipelines", nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if slept {
+		t.Error("expected failover without honoring Retry-After")
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != healthPath {
+			t.Errorf("path = %q, want %q", r.URL.Path, healthPath)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	c := newTestClient(t, healthy.URL, deadURL())
+
+	statuses := c.CheckHealth(context.Background())
+	if len(statuses) != 2 {
+		t.Fatalf("len(statuses) = %d, want 2", len(statuses))
+	}
+	if !statuses[0].Healthy {
+		t.Errorf("endpoint 0 healthy = false, want true")
+	}
+	if statuses[1].Healthy || statuses[1].Error == "" {
+		t.Errorf("endpoint 1 = %+v, want unhealthy with error", statuses[1])
+	}
+}
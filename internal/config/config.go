@@ -4,8 +4,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +22,9 @@ type Config struct {
 	// API configuration
 	API APIConfig
 
+	// Client configuration for the API client used by the CLI
+	Client ClientConfig
+
 	// Database configuration for the buffer/metadata database
 	Database DatabaseConfig
 
@@ -29,6 +34,9 @@ type Config struct {
 	// Iceberg configuration
 	Iceberg IcebergConfig
 
+	// Kafka sink configuration
+	Kafka KafkaConfig
+
 	// MinIO/S3 configuration
 	Storage StorageConfig
 
@@ -47,6 +55,9 @@ type Config struct {
 	// Auth configuration
 	Auth AuthConfig
 
+	// Secrets selects the secret backend
+	Secrets SecretsConfig
+
 	// Vault configuration for secrets management
 	Vault VaultConfig
 
@@ -59,11 +70,25 @@ type Config struct {
 	// Trino configuration for SQL query layer
 	Trino TrinoConfig
 
+	// Query configuration for the query endpoint
+	Query QueryConfig
+
 	// QueryScaling configuration for query engine auto-scaling
 	QueryScaling QueryScalingConfig
 
 	// MultiTenancy configuration for RBAC and tenant isolation
 	MultiTenancy MultiTenancyConfig
+
+	// envErrors describes the malformed environment values Load replaced
+	// with defaults
+	envErrors []string
+
+	// warnings describes config file keys Load ignored
+	warnings []string
+
+	// settings holds the effective value of every setting, keyed by its
+	// environment variable
+	settings map[string]string
 }
 
 // QueryScalingConfig holds query engine auto-scaling configuration.
@@ -145,12 +170,90 @@ type TrinoConfig struct {
 	HealthCheckInterval time.Duration
 }
 
+// Query engines selectable for the query endpoint.
+const (
+	QueryEngineTrino  = "trino"
+	QueryEngineDuckDB = "duckdb"
+)
+
+// QueryConfig holds query endpoint configuration.
+type QueryConfig struct {
+	// Engine is the engine running queries ("trino" or "duckdb")
+	Engine string
+
+	// MaxResultRows caps the rows returned by a query (0 = unlimited)
+	MaxResultRows int
+
+	// Cache configures the query result cache
+	Cache QueryCacheConfig
+
+	// History configures the query history
+	History QueryHistoryConfig
+
+	// DuckDB configures the embedded DuckDB engine
+	DuckDB DuckDBConfig
+}
+
+// QueryCacheConfig holds query result cache configuration. Results are
+// cached in memory and only when MaxResultRows is set.
+type QueryCacheConfig struct {
+	// TTL is how long results are cached (0 = caching disabled)
+	TTL time.Duration
+
+	// MaxEntries is the number of results kept, least recently used first
+	// evicted
+	MaxEntries int
+
+	// SnapshotInvalidation looks up the current Iceberg snapshots of the
+	// tables a query reads, so that cached results are not served after a
+	// table changed
+	SnapshotInvalidation bool
+}
+
+// QueryHistoryConfig holds query history configuration.
+type QueryHistoryConfig struct {
+	// Enabled records every executed statement in the query history
+	Enabled bool
+
+	// RedactLiterals replaces literals in WHERE clauses with ? before a
+	// statement is recorded
+	RedactLiterals bool
+}
+
+// DuckDBConfig holds embedded DuckDB query engine configuration. DuckDB reads
+// the Iceberg tables through the Iceberg catalog and object storage.
+type DuckDBConfig struct {
+	// Catalog is the name the Iceberg catalog is attached as
+	Catalog string
+
+	// Schema is the default schema name
+	Schema string
+
+	// QueryTimeout is the maximum time for queries
+	QueryTimeout time.Duration
+
+	// MemoryLimit caps DuckDB memory, e.g. "2GB" (empty = DuckDB default)
+	MemoryLimit string
+
+	// Threads is the number of DuckDB worker threads (0 = DuckDB default)
+	Threads int
+
+	// AllowLocalFiles allows queries to read local files. Keep disabled
+	// outside of development: it exposes the API host's file system.
+	AllowLocalFiles bool
+}
+
 // OAuthConfig holds OAuth configuration for cloud providers.
 type OAuthConfig struct {
 	// EncryptionKey is the base64-encoded 32-byte key for encrypting tokens.
 	// Generate with: openssl rand -base64 32
 	EncryptionKey string
 
+	// PreviousEncryptionKeys are base64-encoded keys tokens were encrypted
+	// with before EncryptionKey. They only decrypt, and stored tokens are
+	// re-encrypted with EncryptionKey on startup.
+	PreviousEncryptionKeys []string
+
 	// BaseURL is the base URL of the Philotes API (for OAuth callbacks).
 	// Example: https://philotes.example.com
 	BaseURL string
@@ -160,11 +263,22 @@ type OAuthConfig struct {
 	// Example: ["localhost:3000", "philotes.example.com"]
 	AllowedRedirectHosts []string
 
+	// RefreshInterval is how often expired states and credentials are
+	// cleaned up and expiring access tokens refreshed (0 disables it)
+	RefreshInterval time.Duration
+
+	// RefreshWindow is how long before its access token expires a
+	// credential is refreshed
+	RefreshWindow time.Duration
+
 	// Hetzner OAuth configuration
 	Hetzner HetznerOAuthConfig
 
 	// OVH OAuth configuration
 	OVH OVHOAuthConfig
+
+	// Generic OAuth configuration, for a provider without built-in support
+	Generic GenericOAuthConfig
 }
 
 // HetznerOAuthConfig holds Hetzner Cloud OAuth settings.
@@ -177,6 +291,30 @@ type HetznerOAuthConfig struct {
 	Enabled bool
 }
 
+// GenericOAuthConfig holds the settings of an OAuth 2.0 provider without
+// built-in support.
+type GenericOAuthConfig struct {
+	// Name is the provider identifier used in OAuth routes
+	Name string
+	// DisplayName is the human-readable provider name
+	DisplayName string
+	// AuthURL is the authorization endpoint URL
+	AuthURL string
+	// TokenURL is the token endpoint URL
+	TokenURL string
+	// Scopes are the OAuth scopes requested
+	Scopes []string
+	// ClientID is the OAuth application client ID
+	ClientID string
+	// ClientSecret is the OAuth application client secret
+	ClientSecret string
+	// CredentialField is the provider credentials field the access token
+	// is stored under, such as "hetzner_token"
+	CredentialField string
+	// Enabled indicates if generic OAuth is configured
+	Enabled bool
+}
+
 // OVHOAuthConfig holds OVHcloud OAuth settings.
 type OVHOAuthConfig struct {
 	// ClientID is the OAuth application client ID
@@ -208,6 +346,16 @@ type OIDCConfig struct {
 
 	// StateExpiration is how long OIDC states are valid
 	StateExpiration time.Duration
+
+	// AllowedRedirectHosts is a list of hosts, with their port if not the
+	// default, allowed for redirects after login besides localhost and the
+	// host of the API base URL.
+	// Example: ["app.example.com", "localhost:3000"]
+	AllowedRedirectHosts []string
+
+	// DiscoveryCacheTTL is how long provider discovery documents and key
+	// sets are cached when the provider's Cache-Control does not say
+	DiscoveryCacheTTL time.Duration
 }
 
 // VaultConfig holds HashiCorp Vault configuration.
@@ -267,6 +415,41 @@ type VaultSecretPaths struct {
 	StorageMinio string
 }
 
+// Secret backends selectable for credentials.
+const (
+	SecretBackendVault = "vault"
+	SecretBackendAWS   = "aws"
+	SecretBackendEnv   = "env"
+)
+
+// SecretsConfig selects the backend credentials are read from. Every
+// backend reads the secrets named by the Vault secret paths.
+type SecretsConfig struct {
+	// Backend is the secret backend ("vault", "aws" or "env"); it defaults
+	// to "vault" when Vault is enabled and to "env" otherwise
+	Backend string
+
+	// RequireReferences rejects source passwords that are not secret
+	// references, so no plaintext password is stored with a source
+	RequireReferences bool
+
+	// AWS configures the AWS Secrets Manager backend
+	AWS AWSSecretsConfig
+}
+
+// AWSSecretsConfig holds AWS Secrets Manager configuration. Credentials are
+// read from the standard AWS environment variables.
+type AWSSecretsConfig struct {
+	// Region is the AWS region of the secrets (defaults to AWS_REGION)
+	Region string
+
+	// Endpoint overrides the Secrets Manager endpoint URL
+	Endpoint string
+
+	// RefreshInterval is how often to refresh cached secrets
+	RefreshInterval time.Duration
+}
+
 // APIConfig holds API server configuration.
 type APIConfig struct {
 	// ListenAddr is the address to listen on (e.g., ":8080")
@@ -289,6 +472,52 @@ type APIConfig struct {
 
 	// RateLimitBurst is the maximum burst size for rate limiting
 	RateLimitBurst int
+
+	// RateLimitOverrides sets separate limits for routes under path prefixes
+	RateLimitOverrides []RateLimitOverride
+
+	// CompressionEnabled enables gzip compression of responses for clients that accept it
+	CompressionEnabled bool
+
+	// CompressionMinSize is the smallest response body, in bytes, that is compressed
+	CompressionMinSize int
+
+	// ETagEnabled adds ETags to GET responses and answers If-None-Match with 304 Not Modified
+	ETagEnabled bool
+
+	// IdempotencyTTL is how long responses to POST requests with an Idempotency-Key
+	// header are kept for replay. Zero disables idempotency keys.
+	IdempotencyTTL time.Duration
+}
+
+// RateLimitOverride is the rate limit of the routes under a path prefix,
+// written as prefix=rps:burst, such as /api/v1/query=5:10.
+type RateLimitOverride struct {
+	PathPrefix string
+	RPS        float64
+	Burst      int
+}
+
+// ClientConfig holds API client configuration for the CLI and Go SDK.
+type ClientConfig struct {
+	// Endpoints is a list of API base URLs to fail over between.
+	// If empty, API.BaseURL is used.
+	Endpoints []string
+
+	// APIKey is the API key used to authenticate requests
+	APIKey string
+
+	// Timeout is the per-request timeout
+	Timeout time.Duration
+
+	// MaxAttempts is the maximum number of attempts per request across endpoints
+	MaxAttempts int
+
+	// HealthCheckInterval is how long a failed endpoint is skipped before being retried
+	HealthCheckInterval time.Duration
+
+	// HealthCheckTimeout is the timeout for a single endpoint health probe
+	HealthCheckTimeout time.Duration
 }
 
 // DatabaseConfig holds database connection configuration.
@@ -337,6 +566,55 @@ type CDCConfig struct {
 	// FlushInterval is the interval for flushing events
 	FlushInterval time.Duration
 
+	// Priority weighs this pipeline's share of the writer slots it shares
+	// with the worker's other pipelines (higher gets more)
+	Priority int
+
+	// WriterSlots caps concurrent buffer reads and writes shared by the
+	// worker's pipelines (0 = no shared scheduling)
+	WriterSlots int
+
+	// KeyConflictPolicy handles differing rows sharing a key in one batch
+	// ("off", "detect", "last_write_wins" or "quarantine"). The policies that
+	// drop rows only apply to sinks that write by key; the append-only
+	// Iceberg writer falls back to "detect"
+	KeyConflictPolicy string
+
+	// Sinks lists the sinks batches are written to ("iceberg", "kafka")
+	Sinks []string
+
+	// TableOverrides is JSON with per-table batch_size and flush_interval, keyed by "schema.table"
+	TableOverrides string
+
+	// MaxParallelTables bounds how many tables' batches are flushed
+	// concurrently
+	MaxParallelTables int
+
+	// ColumnRules is JSON with per-table column rules (exclude, hash or
+	// redact), keyed by "schema.table" and column
+	ColumnRules string
+
+	// PipelineID is the ID of the pipeline registered through the API that
+	// this worker runs. When set, the worker follows the pipeline's pause
+	// and resume requests
+	PipelineID string
+
+	// ControlPollInterval is how often the worker checks whether its
+	// pipeline was paused or resumed
+	ControlPollInterval time.Duration
+
+	// DryRun validates the source, catalog and storage, prints a report and
+	// exits without starting replication
+	DryRun bool
+
+	// UnchangedToast is how the unchanged TOAST columns of an UPDATE are
+	// restored ("identity", "read_back" or "omit")
+	UnchangedToast string
+
+	// JSONAsStruct keeps json and jsonb objects and arrays as nested values
+	// in the written rows instead of JSON text
+	JSONAsStruct bool
+
 	// Source is the source PostgreSQL database configuration
 	Source SourceConfig
 
@@ -360,6 +638,18 @@ type CDCConfig struct {
 
 	// Backpressure holds backpressure configuration
 	Backpressure BackpressureConfig
+
+	// Verification holds post-snapshot verification configuration
+	Verification VerificationConfig
+
+	// Snapshot holds initial snapshot configuration
+	Snapshot SnapshotConfig
+
+	// Shadow holds shadow pipeline configuration
+	Shadow ShadowConfig
+
+	// Orphans holds orphaned replication slot and publication configuration
+	Orphans OrphanConfig
 }
 
 // RetryConfig holds retry policy configuration.
@@ -396,6 +686,16 @@ type HealthConfig struct {
 
 	// ReadinessTimeout is how long to wait for readiness checks
 	ReadinessTimeout time.Duration
+
+	// RunbookURL is linked from health failure remediation hints
+	RunbookURL string
+
+	// ReplicationLagThreshold is the replication lag in bytes above which the
+	// source reports degraded (0 = never)
+	ReplicationLagThreshold int64
+
+	// ReplicationLagInterval is how often the replication lag is polled
+	ReplicationLagInterval time.Duration
 }
 
 // BackpressureConfig holds backpressure configuration.
@@ -403,16 +703,116 @@ type BackpressureConfig struct {
 	// Enabled enables backpressure handling
 	Enabled bool
 
-	// HighWatermark is the buffer size threshold to trigger pause
+	// HighWatermark is the buffer size threshold to trigger pause; zero
+	// disables the buffer size signal
 	HighWatermark int
 
 	// LowWatermark is the buffer size threshold to resume processing
 	LowWatermark int
 
-	// CheckInterval is how often to check buffer size
+	// LatencyHighWatermark is the average Iceberg flush latency threshold to
+	// trigger pause; zero disables the flush latency signal
+	LatencyHighWatermark time.Duration
+
+	// LatencyLowWatermark is the average Iceberg flush latency threshold to
+	// resume processing
+	LatencyLowWatermark time.Duration
+
+	// LatencyWindow is the window the flush latency is averaged over
+	LatencyWindow time.Duration
+
+	// CheckInterval is how often to check buffer size and flush latency
 	CheckInterval time.Duration
 }
 
+// VerificationConfig holds post-snapshot verification configuration.
+type VerificationConfig struct {
+	// Mode is the verification mode: off, count, or checksum
+	Mode string
+
+	// SampleRanges is the number of primary key ranges to checksum
+	SampleRanges int
+
+	// RerunOnMismatch re-runs the affected ranges when verification fails
+	RerunOnMismatch bool
+}
+
+// SnapshotConfig holds initial snapshot configuration. The snapshot backfills
+// the replicated tables when the pipeline starts without a checkpoint.
+type SnapshotConfig struct {
+	// Enabled enables the initial snapshot
+	Enabled bool
+
+	// Mode is when tables are snapshotted: never, initial (until streaming is checkpointed)
+	// or when_needed (any table not yet snapshotted, e.g. newly added tables)
+	Mode string
+
+	// ReadMode is how rows are read: consistent (one transaction) or chunked (one short transaction per chunk)
+	ReadMode string
+
+	// Order is snapshot_first (snapshot, then stream) or stream_first (stream, snapshot lazily; chunked only)
+	Order string
+
+	// Window restricts snapshot reads to a daily UTC period such as "22:00-04:00" (empty = any time)
+	Window string
+
+	// MaxTxDuration caps how long a snapshot transaction may stay open (0 = no cap)
+	MaxTxDuration time.Duration
+
+	// ChunkSize is the number of rows read per query
+	ChunkSize int
+}
+
+// ShadowConfig holds shadow pipeline configuration. A shadow pipeline reads
+// the same source through its own slot and writes to prefixed namespaces, so
+// config changes can be compared with production before they are applied.
+type ShadowConfig struct {
+	// Enabled runs this worker as a shadow of the configured pipeline
+	Enabled bool
+
+	// Action is run, promote (replace production tables with the shadow's) or discard
+	Action string
+
+	// Name distinguishes the shadow's slot, source and backup tables
+	Name string
+
+	// NamespacePrefix is prepended to the namespaces the shadow writes to
+	NamespacePrefix string
+
+	// MaxDuration time-boxes the shadow run (0 = until stopped)
+	MaxDuration time.Duration
+
+	// DiscardOnExpiry drops the shadow slot and tables when MaxDuration elapses
+	DiscardOnExpiry bool
+
+	// ForcePromote promotes a shadow whose row counts do not match production
+	ForcePromote bool
+}
+
+// OrphanConfig holds configuration for finding replication slots and
+// publications that Philotes created but no pipeline references.
+type OrphanConfig struct {
+	// Enabled reports orphans on the source
+	Enabled bool
+
+	// Prune drops orphans instead of only reporting them
+	Prune bool
+
+	// Prefixes are the name prefixes of Philotes-managed slots and publications
+	Prefixes []string
+
+	// Interval is how often the source is checked
+	Interval time.Duration
+
+	// MinAge is how long an object must stay orphaned before it is dropped
+	MinAge time.Duration
+
+	// KeepSlots and KeepPublications are treated as referenced, e.g. for
+	// pipelines run by other workers that are not registered through the API
+	KeepSlots        []string
+	KeepPublications []string
+}
+
 // BufferConfig holds buffer database configuration.
 type BufferConfig struct {
 	// Enabled enables event buffering
@@ -427,6 +827,12 @@ type BufferConfig struct {
 
 // SourceConfig holds the source PostgreSQL database configuration.
 type SourceConfig struct {
+	// Type selects the registered CDC source implementation (e.g., "postgres")
+	Type string
+
+	// Name overrides the source identifier that keys checkpoints (default "<type>-<database>")
+	Name string
+
 	// Host is the source database host
 	Host string
 
@@ -446,6 +852,14 @@ type SourceConfig struct {
 	SSLMode string
 }
 
+// SourceID returns the identifier of the source, which keys its checkpoints.
+func (s SourceConfig) SourceID() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%s-%s", s.Type, s.Database)
+}
+
 // DSN returns the source database connection string.
 func (s SourceConfig) DSN() string {
 	return fmt.Sprintf(
@@ -472,6 +886,21 @@ type ReplicationConfig struct {
 
 	// Tables is a list of tables to replicate (empty means all tables in publication)
 	Tables []string
+
+	// GapPolicy is what to do when the slot lost the checkpointed position: halt, snapshot, or resume.
+	// The snapshot policy re-copies PHILOTES_CDC_TABLES before streaming resumes.
+	GapPolicy string
+
+	// GapAckLSN acknowledges a halted gap at this checkpoint LSN so the pipeline can resume
+	GapAckLSN string
+
+	// DedupEnabled drops changes redelivered after a reconnect using a persisted
+	// per-slot LSN high-water-mark
+	DedupEnabled bool
+
+	// AutoCreateSlot creates the replication slot on startup when it does not
+	// exist, and the publication for decoding plugins that use one
+	AutoCreateSlot bool
 }
 
 // CheckpointConfig holds checkpointing configuration.
@@ -483,6 +912,57 @@ type CheckpointConfig struct {
 	Interval time.Duration
 }
 
+// KafkaConfig holds Kafka sink configuration.
+type KafkaConfig struct {
+	// Brokers are the bootstrap broker addresses (host:port)
+	Brokers []string
+
+	// TopicTemplate names the topic for a table; {schema} and {table} are replaced
+	TopicTemplate string
+
+	// SchemaRegistryURL enables Avro encoding with schemas registered there (empty means JSON)
+	SchemaRegistryURL string
+
+	// ClientID identifies the worker to the brokers
+	ClientID string
+
+	// RequiredAcks is the number of acknowledgements a write needs: -1 (all replicas), 0 or 1
+	RequiredAcks int
+
+	// Timeout bounds each request to a broker or the schema registry
+	Timeout time.Duration
+
+	// Compression is the record batch codec: none, gzip, snappy, lz4 or zstd
+	Compression string
+
+	// Idempotent enables the idempotent producer so retries do not duplicate records
+	Idempotent bool
+
+	// TLSEnabled connects to the brokers over TLS
+	TLSEnabled bool
+
+	// TLSCAFile is a PEM bundle of CAs to trust instead of the system pool
+	TLSCAFile string
+
+	// TLSCertFile and TLSKeyFile are the client certificate for mutual TLS
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSSkipVerify skips verification of the broker certificates
+	TLSSkipVerify bool
+
+	// SASLMechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512 (empty disables SASL)
+	SASLMechanism string
+
+	// SASLUsername and SASLPassword are the SASL credentials
+	SASLUsername string
+	SASLPassword string
+
+	// SchemaRegistryUsername and SchemaRegistryPassword authenticate with the schema registry
+	SchemaRegistryUsername string
+	SchemaRegistryPassword string
+}
+
 // IcebergConfig holds Apache Iceberg configuration.
 type IcebergConfig struct {
 	// CatalogURL is the Lakekeeper REST catalog URL
@@ -490,24 +970,118 @@ type IcebergConfig struct {
 
 	// Warehouse is the warehouse name
 	Warehouse string
+
+	// MetadataTablesEnabled exposes snapshot, file and partition metadata via the API
+	MetadataTablesEnabled bool
+
+	// MetadataSnapshotLimit caps the number of snapshots returned per table (0 = unlimited)
+	MetadataSnapshotLimit int
+
+	// SmallFileThresholdBytes is the average file size below which compaction is recommended
+	SmallFileThresholdBytes int
+
+	// ColumnCase is the column name case policy ("identity", "lower" or "snake_case")
+	ColumnCase string
+
+	// ColumnRenames are explicit column renames as "source:target", applied before ColumnCase
+	ColumnRenames []string
+
+	// CommitMaxRetries is how often a commit conflicting with a concurrent writer is retried
+	CommitMaxRetries int
+
+	// CommitRetryBackoff is the initial delay before retrying a conflicting commit
+	CommitRetryBackoff time.Duration
+
+	// CommitRetryMaxBackoff caps the delay between commit retries
+	CommitRetryMaxBackoff time.Duration
+
+	// Branch is the Iceberg branch the worker commits to (empty = main)
+	Branch string
+
+	// WideTableStrategy handles tables over MaxColumns ("reject", "split" or "overflow")
+	WideTableStrategy string
+
+	// MaxColumns is the most source columns per Iceberg table (0 = unlimited)
+	MaxColumns int
+
+	// MaxRowBytes is the largest row, as JSON, that is written (0 = unlimited)
+	MaxRowBytes int
+
+	// Partitioning is the partition spec of new tables as "transform:column" (empty = day of _cdc_timestamp)
+	Partitioning []string
+
+	// TablePartitioning overrides Partitioning per table as "schema.table=transform:column;..."
+	TablePartitioning []string
+
+	// DeleteMode is how changes are materialized in tables ("append_only", "soft" or "hard")
+	DeleteMode string
+
+	// TableDeleteModes overrides DeleteMode per table as "schema.table=mode"
+	TableDeleteModes []string
+
+	// NumericPrecision is the decimal precision of numeric columns declared without one
+	NumericPrecision int
+
+	// NumericScale is the decimal scale of numeric columns declared without a precision
+	NumericScale int
+
+	// TimestampMode is the Iceberg type of timestamp without time zone columns ("preserve" or "utc")
+	TimestampMode string
+
+	// TableTimestampModes overrides TimestampMode per table as "schema.table=mode"
+	TableTimestampModes []string
+
+	// CompactionEnabled runs periodic compaction and snapshot expiration in the worker
+	CompactionEnabled bool
+
+	// CompactionInterval is how often compaction and snapshot expiration run
+	CompactionInterval time.Duration
+
+	// CompactionTargetFileSizeBytes is the size small data files are compacted up to
+	CompactionTargetFileSizeBytes int
+
+	// CompactionMinInputFiles is the fewest small files in a partition worth compacting
+	CompactionMinInputFiles int
+
+	// SnapshotMaxAge is the age after which snapshots expire (0 = never)
+	SnapshotMaxAge time.Duration
+
+	// SnapshotsToKeep is the number of most recent snapshots never expired
+	SnapshotsToKeep int
 }
 
+// Object storage providers selectable for data files.
+const (
+	StorageProviderS3    = "s3"
+	StorageProviderGCS   = "gcs"
+	StorageProviderAzure = "azure"
+)
+
 // StorageConfig holds object storage configuration.
 type StorageConfig struct {
-	// Endpoint is the S3/MinIO endpoint
+	// Provider is the object store ("s3", "gcs" or "azure")
+	Provider string
+
+	// Endpoint is the storage endpoint (empty uses the provider's public
+	// endpoint for GCS and Azure)
 	Endpoint string
 
-	// AccessKey is the access key
+	// AccessKey is the access key (GCS: HMAC access ID, Azure: storage
+	// account name)
 	AccessKey string
 
-	// SecretKey is the secret key
+	// SecretKey is the secret key (GCS: HMAC secret, Azure: storage account
+	// key)
 	SecretKey string
 
-	// Bucket is the default bucket name
+	// Bucket is the default bucket name (Azure: container name)
 	Bucket string
 
 	// UseSSL enables SSL for the connection
 	UseSSL bool
+
+	// Region is the S3 region (optional for MinIO)
+	Region string
 }
 
 // MetricsConfig holds metrics/observability configuration.
@@ -531,10 +1105,47 @@ type AlertingConfig struct {
 	NotificationTimeout time.Duration
 
 	// PrometheusURL is the URL of the Prometheus server to query metrics from
+	// (empty = rules read the in-process metrics registry unless they set
+	// source "prometheus")
 	PrometheusURL string
 
+	// MetricsEndpoints are /metrics URLs of other Philotes processes (such as
+	// the CDC worker) whose metrics rules with the internal source can read
+	// alongside the metrics of the process running the alert manager.
+	MetricsEndpoints []string
+
 	// RetentionDays is the number of days to retain alert history
 	RetentionDays int
+
+	// MinNotificationInterval is the default minimum time between state-change
+	// notifications for an alert, used by rules that don't set their own.
+	// State changes closer together are collapsed into a single "flapping"
+	// notification. 0 disables flap detection.
+	MinNotificationInterval time.Duration
+
+	// RemoteWrite configures the Prometheus remote-write receiver
+	RemoteWrite RemoteWriteConfig
+}
+
+// RemoteWriteConfig holds configuration for the Prometheus remote-write
+// receiver, which stores metrics pushed by workers the API cannot scrape for
+// rules with the remote_write source.
+type RemoteWriteConfig struct {
+	// Enabled exposes the remote-write endpoint
+	Enabled bool
+
+	// MetricPrefixes are the metric name prefixes stored; other series are dropped
+	MetricPrefixes []string
+
+	// Retention is how long received samples are kept
+	Retention time.Duration
+
+	// CleanupInterval is how often expired samples are deleted
+	CleanupInterval time.Duration
+
+	// Staleness is how old the latest sample of a series may be for rules
+	// to still read it
+	Staleness time.Duration
 }
 
 // ScalingConfig holds scaling engine configuration.
@@ -602,6 +1213,17 @@ type NodeScalingConfig struct {
 	// DefaultImage is the default OS image for new nodes
 	DefaultImage string
 
+	// PricingRefreshProviders lists the providers whose instance pricing is
+	// refreshed from their APIs; providers not listed keep their stored pricing
+	PricingRefreshProviders []string
+
+	// PricingRefreshInterval is how often instance pricing is refreshed
+	PricingRefreshInterval time.Duration
+
+	// NotificationChannelID is the ID of the notification channel that
+	// receives scaling operation notifications of opted-in pools (empty to disable)
+	NotificationChannelID string
+
 	// Hetzner cloud provider configuration
 	Hetzner HetznerProviderConfig
 
@@ -616,6 +1238,9 @@ type NodeScalingConfig struct {
 
 	// Contabo cloud provider configuration
 	Contabo ContaboProviderConfig
+
+	// DigitalOcean cloud provider configuration
+	DigitalOcean DigitalOceanProviderConfig
 }
 
 // HetznerProviderConfig holds Hetzner Cloud provider configuration.
@@ -670,6 +1295,12 @@ type ContaboProviderConfig struct {
 	Password string
 }
 
+// DigitalOceanProviderConfig holds DigitalOcean provider configuration.
+type DigitalOceanProviderConfig struct {
+	// Token is the DigitalOcean API token
+	Token string
+}
+
 // AuthConfig holds authentication configuration.
 type AuthConfig struct {
 	// Enabled enables authentication (disabled by default for development)
@@ -692,22 +1323,97 @@ type AuthConfig struct {
 
 	// AdminPassword is the bootstrap admin user password
 	AdminPassword string
+
+	// AuditRetentionDays is how long audit logs are kept for tenants without
+	// their own retention policy (0 keeps them indefinitely)
+	AuditRetentionDays int
+
+	// AuditCleanupInterval is how often expired audit logs are deleted
+	// (0 disables deletion, so audit logs are only removed when opted in)
+	AuditCleanupInterval time.Duration
 }
 
-// Load loads configuration from environment variables.
+// loadMu serializes Load, which hands the get*Env helpers the config file
+// settings in fileValues and collects the variables they read in readKeys,
+// the effective values in settings and the values they could not parse and
+// replaced with defaults in envErrors.
+var (
+	loadMu     sync.Mutex
+	fileValues map[string]fileValue
+	readKeys   map[string]bool
+	settings   map[string]string
+	envErrors  []string
+)
+
+// Load loads configuration from environment variables and, if
+// PHILOTES_CONFIG_FILE names one, a YAML or TOML config file. Environment
+// variables take precedence over the file, and the file over defaults.
+// Malformed values are replaced with defaults and reported by Validate;
+// unknown config file keys are reported by Warnings.
 func Load() (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	envErrors = nil
+	readKeys = make(map[string]bool)
+	settings = make(map[string]string)
+	fileValues = nil
+	defer func() { fileValues, readKeys, settings = nil, nil, nil }()
+
+	if path := os.Getenv(configFileEnv); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+		fileValues = values
+	}
+
+	// Vault remains the secret backend of deployments that enable it
+	secretBackend := SecretBackendEnv
+	if getBoolEnv("PHILOTES_VAULT_ENABLED", false) {
+		secretBackend = SecretBackendVault
+	}
+
+	// The MinIO defaults of a local deployment apply to S3 only
+	storageProvider := getEnv("PHILOTES_STORAGE_PROVIDER", StorageProviderS3)
+	storageEndpoint, storageAccessKey, storageSecretKey := "localhost:9000", "minioadmin", "minioadmin"
+	if storageProvider != StorageProviderS3 {
+		storageEndpoint, storageAccessKey, storageSecretKey = "", "", ""
+	}
+
+	// A key list rotates the OAuth encryption key: the first key encrypts
+	// and the rest only decrypt
+	oauthEncryptionKey := getEnv("PHILOTES_OAUTH_ENCRYPTION_KEY", "")
+	var oauthPreviousEncryptionKeys []string
+	if keys := getSliceEnv("PHILOTES_OAUTH_ENCRYPTION_KEYS", nil); len(keys) > 0 {
+		oauthEncryptionKey, oauthPreviousEncryptionKeys = keys[0], keys[1:]
+	}
+
 	cfg := &Config{
 		Version:     getEnv("PHILOTES_VERSION", "0.1.0"),
 		Environment: getEnv("PHILOTES_ENV", "development"),
 
 		API: APIConfig{
-			ListenAddr:     getEnv("PHILOTES_API_LISTEN_ADDR", ":8080"),
-			BaseURL:        getEnv("PHILOTES_API_BASE_URL", "http://localhost:8080"),
-			ReadTimeout:    getDurationEnv("PHILOTES_API_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout:   getDurationEnv("PHILOTES_API_WRITE_TIMEOUT", 15*time.Second),
-			CORSOrigins:    getSliceEnv("PHILOTES_API_CORS_ORIGINS", []string{"*"}),
-			RateLimitRPS:   getFloatEnv("PHILOTES_API_RATE_LIMIT_RPS", 100),
-			RateLimitBurst: getIntEnv("PHILOTES_API_RATE_LIMIT_BURST", 200),
+			ListenAddr:         getEnv("PHILOTES_API_LISTEN_ADDR", ":8080"),
+			BaseURL:            getEnv("PHILOTES_API_BASE_URL", "http://localhost:8080"),
+			ReadTimeout:        getDurationEnv("PHILOTES_API_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:       getDurationEnv("PHILOTES_API_WRITE_TIMEOUT", 15*time.Second),
+			CORSOrigins:        getSliceEnv("PHILOTES_API_CORS_ORIGINS", []string{"*"}),
+			RateLimitRPS:       getFloatEnv("PHILOTES_API_RATE_LIMIT_RPS", 100),
+			RateLimitBurst:     getIntEnv("PHILOTES_API_RATE_LIMIT_BURST", 200),
+			RateLimitOverrides: getRateLimitOverridesEnv("PHILOTES_API_RATE_LIMIT_OVERRIDES"),
+			CompressionEnabled: getBoolEnv("PHILOTES_API_COMPRESSION_ENABLED", true),
+			CompressionMinSize: getIntEnv("PHILOTES_API_COMPRESSION_MIN_SIZE", 1024),
+			ETagEnabled:        getBoolEnv("PHILOTES_API_ETAG_ENABLED", true),
+			IdempotencyTTL:     getDurationEnv("PHILOTES_API_IDEMPOTENCY_TTL", 24*time.Hour),
+		},
+
+		Client: ClientConfig{
+			Endpoints:           getSliceEnv("PHILOTES_API_ENDPOINTS", []string{getEnv("PHILOTES_API_BASE_URL", "http://localhost:8080")}),
+			APIKey:              getEnv("PHILOTES_API_KEY", ""),
+			Timeout:             getDurationEnv("PHILOTES_CLIENT_TIMEOUT", 30*time.Second),
+			MaxAttempts:         getIntEnv("PHILOTES_CLIENT_MAX_ATTEMPTS", 3),
+			HealthCheckInterval: getDurationEnv("PHILOTES_CLIENT_HEALTH_CHECK_INTERVAL", 30*time.Second),
+			HealthCheckTimeout:  getDurationEnv("PHILOTES_CLIENT_HEALTH_CHECK_TIMEOUT", 5*time.Second),
 		},
 
 		Database: DatabaseConfig{
@@ -722,10 +1428,24 @@ func Load() (*Config, error) {
 		},
 
 		CDC: CDCConfig{
-			BufferSize:    getIntEnv("PHILOTES_CDC_BUFFER_SIZE", 10000),
-			BatchSize:     getIntEnv("PHILOTES_CDC_BATCH_SIZE", 1000),
-			FlushInterval: getDurationEnv("PHILOTES_CDC_FLUSH_INTERVAL", 5*time.Second),
+			BufferSize:          getIntEnv("PHILOTES_CDC_BUFFER_SIZE", 10000),
+			BatchSize:           getIntEnv("PHILOTES_CDC_BATCH_SIZE", 1000),
+			FlushInterval:       getDurationEnv("PHILOTES_CDC_FLUSH_INTERVAL", 5*time.Second),
+			KeyConflictPolicy:   getEnv("PHILOTES_CDC_KEY_CONFLICT_POLICY", "off"),
+			Sinks:               getSliceEnv("PHILOTES_CDC_SINKS", []string{"iceberg"}),
+			TableOverrides:      getEnv("PHILOTES_CDC_TABLE_OVERRIDES", ""),
+			MaxParallelTables:   getIntEnv("PHILOTES_CDC_MAX_PARALLEL_TABLES", 4),
+			Priority:            getIntEnv("PHILOTES_CDC_PRIORITY", 1),
+			WriterSlots:         getIntEnv("PHILOTES_CDC_WRITER_SLOTS", 0),
+			ColumnRules:         getEnv("PHILOTES_CDC_COLUMN_RULES", ""),
+			PipelineID:          getEnv("PHILOTES_CDC_PIPELINE_ID", ""),
+			ControlPollInterval: getDurationEnv("PHILOTES_CDC_CONTROL_POLL_INTERVAL", 5*time.Second),
+			DryRun:              getBoolEnv("PHILOTES_CDC_DRY_RUN", false),
+			UnchangedToast:      getEnv("PHILOTES_CDC_UNCHANGED_TOAST", "identity"),
+			JSONAsStruct:        getBoolEnv("PHILOTES_CDC_JSON_AS_STRUCT", false),
 			Source: SourceConfig{
+				Type:     getEnv("PHILOTES_CDC_SOURCE_TYPE", "postgres"),
+				Name:     getEnv("PHILOTES_CDC_SOURCE_NAME", ""),
 				Host:     getEnv("PHILOTES_CDC_SOURCE_HOST", "localhost"),
 				Port:     getIntEnv("PHILOTES_CDC_SOURCE_PORT", 5433),
 				Database: getEnv("PHILOTES_CDC_SOURCE_DATABASE", "source"),
@@ -737,6 +1457,10 @@ func Load() (*Config, error) {
 				SlotName:        getEnv("PHILOTES_CDC_REPLICATION_SLOT", "philotes_cdc"),
 				PublicationName: getEnv("PHILOTES_CDC_PUBLICATION", "philotes_pub"),
 				Tables:          getSliceEnv("PHILOTES_CDC_TABLES", nil),
+				GapPolicy:       getEnv("PHILOTES_CDC_GAP_POLICY", "halt"),
+				GapAckLSN:       getEnv("PHILOTES_CDC_GAP_ACK_LSN", ""),
+				DedupEnabled:    getBoolEnv("PHILOTES_CDC_DEDUP_ENABLED", false),
+				AutoCreateSlot:  getBoolEnv("PHILOTES_CDC_AUTO_CREATE_SLOT", false),
 			},
 			Checkpoint: CheckpointConfig{
 				Enabled:  getBoolEnv("PHILOTES_CDC_CHECKPOINT_ENABLED", true),
@@ -758,29 +1482,119 @@ func Load() (*Config, error) {
 				Retention: getDurationEnv("PHILOTES_DLQ_RETENTION", 168*time.Hour), // 7 days
 			},
 			Health: HealthConfig{
-				Enabled:          getBoolEnv("PHILOTES_HEALTH_ENABLED", true),
-				ListenAddr:       getEnv("PHILOTES_HEALTH_LISTEN_ADDR", ":8081"),
-				ReadinessTimeout: getDurationEnv("PHILOTES_HEALTH_READINESS_TIMEOUT", 5*time.Second),
+				Enabled:                 getBoolEnv("PHILOTES_HEALTH_ENABLED", true),
+				ListenAddr:              getEnv("PHILOTES_HEALTH_LISTEN_ADDR", ":8081"),
+				ReadinessTimeout:        getDurationEnv("PHILOTES_HEALTH_READINESS_TIMEOUT", 5*time.Second),
+				RunbookURL:              getEnv("PHILOTES_HEALTH_RUNBOOK_URL", ""),
+				ReplicationLagThreshold: int64(getIntEnv("PHILOTES_HEALTH_REPLICATION_LAG_THRESHOLD", 1<<30)),
+				ReplicationLagInterval:  getDurationEnv("PHILOTES_HEALTH_REPLICATION_LAG_INTERVAL", 15*time.Second),
 			},
 			Backpressure: BackpressureConfig{
 				Enabled:       getBoolEnv("PHILOTES_BACKPRESSURE_ENABLED", true),
 				HighWatermark: getIntEnv("PHILOTES_BACKPRESSURE_HIGH_WATERMARK", 8000),
 				LowWatermark:  getIntEnv("PHILOTES_BACKPRESSURE_LOW_WATERMARK", 5000),
 				CheckInterval: getDurationEnv("PHILOTES_BACKPRESSURE_CHECK_INTERVAL", time.Second),
+
+				LatencyHighWatermark: getDurationEnv("PHILOTES_BACKPRESSURE_LATENCY_HIGH_WATERMARK", 0),
+				LatencyLowWatermark:  getDurationEnv("PHILOTES_BACKPRESSURE_LATENCY_LOW_WATERMARK", 0),
+				LatencyWindow:        getDurationEnv("PHILOTES_BACKPRESSURE_LATENCY_WINDOW", time.Minute),
+			},
+			Verification: VerificationConfig{
+				Mode:            getEnv("PHILOTES_CDC_VERIFICATION_MODE", "off"),
+				SampleRanges:    getIntEnv("PHILOTES_CDC_VERIFICATION_SAMPLE_RANGES", 10),
+				RerunOnMismatch: getBoolEnv("PHILOTES_CDC_VERIFICATION_RERUN_ON_MISMATCH", false),
+			},
+			Snapshot: SnapshotConfig{
+				Enabled:       getBoolEnv("PHILOTES_CDC_SNAPSHOT_ENABLED", false),
+				Mode:          getEnv("PHILOTES_CDC_SNAPSHOT_MODE", "initial"),
+				ReadMode:      getEnv("PHILOTES_CDC_SNAPSHOT_READ_MODE", "consistent"),
+				Order:         getEnv("PHILOTES_CDC_SNAPSHOT_ORDER", "snapshot_first"),
+				Window:        getEnv("PHILOTES_CDC_SNAPSHOT_WINDOW", ""),
+				MaxTxDuration: getDurationEnv("PHILOTES_CDC_SNAPSHOT_MAX_TX_DURATION", 0),
+				ChunkSize:     getIntEnv("PHILOTES_CDC_SNAPSHOT_CHUNK_SIZE", 10000),
+			},
+			Shadow: ShadowConfig{
+				Enabled:         getBoolEnv("PHILOTES_CDC_SHADOW_ENABLED", false),
+				Action:          getEnv("PHILOTES_CDC_SHADOW_ACTION", "run"),
+				Name:            getEnv("PHILOTES_CDC_SHADOW_NAME", "shadow"),
+				NamespacePrefix: getEnv("PHILOTES_CDC_SHADOW_NAMESPACE_PREFIX", "shadow_"),
+				MaxDuration:     getDurationEnv("PHILOTES_CDC_SHADOW_MAX_DURATION", 24*time.Hour),
+				DiscardOnExpiry: getBoolEnv("PHILOTES_CDC_SHADOW_DISCARD_ON_EXPIRY", false),
+				ForcePromote:    getBoolEnv("PHILOTES_CDC_SHADOW_FORCE_PROMOTE", false),
+			},
+			Orphans: OrphanConfig{
+				Enabled:          getBoolEnv("PHILOTES_CDC_ORPHANS_ENABLED", false),
+				Prune:            getBoolEnv("PHILOTES_CDC_ORPHANS_PRUNE", false),
+				Prefixes:         getSliceEnv("PHILOTES_CDC_ORPHANS_PREFIXES", []string{"philotes_"}),
+				Interval:         getDurationEnv("PHILOTES_CDC_ORPHANS_INTERVAL", time.Hour),
+				MinAge:           getDurationEnv("PHILOTES_CDC_ORPHANS_MIN_AGE", 24*time.Hour),
+				KeepSlots:        getSliceEnv("PHILOTES_CDC_ORPHANS_KEEP_SLOTS", nil),
+				KeepPublications: getSliceEnv("PHILOTES_CDC_ORPHANS_KEEP_PUBLICATIONS", nil),
 			},
 		},
 
 		Iceberg: IcebergConfig{
 			CatalogURL: getEnv("PHILOTES_ICEBERG_CATALOG_URL", "http://localhost:8181"),
 			Warehouse:  getEnv("PHILOTES_ICEBERG_WAREHOUSE", "philotes"),
+
+			MetadataTablesEnabled:   getBoolEnv("PHILOTES_ICEBERG_METADATA_TABLES_ENABLED", true),
+			MetadataSnapshotLimit:   getIntEnv("PHILOTES_ICEBERG_METADATA_SNAPSHOT_LIMIT", 100),
+			SmallFileThresholdBytes: getIntEnv("PHILOTES_ICEBERG_SMALL_FILE_THRESHOLD_BYTES", 32*1024*1024),
+			ColumnCase:              getEnv("PHILOTES_ICEBERG_COLUMN_CASE", "identity"),
+			ColumnRenames:           getSliceEnv("PHILOTES_ICEBERG_COLUMN_RENAMES", nil),
+			CommitMaxRetries:        getIntEnv("PHILOTES_ICEBERG_COMMIT_MAX_RETRIES", 5),
+			CommitRetryBackoff:      getDurationEnv("PHILOTES_ICEBERG_COMMIT_RETRY_BACKOFF", 100*time.Millisecond),
+			CommitRetryMaxBackoff:   getDurationEnv("PHILOTES_ICEBERG_COMMIT_RETRY_MAX_BACKOFF", 5*time.Second),
+			Branch:                  getEnv("PHILOTES_ICEBERG_BRANCH", ""),
+			WideTableStrategy:       getEnv("PHILOTES_ICEBERG_WIDE_TABLE_STRATEGY", "reject"),
+			MaxColumns:              getIntEnv("PHILOTES_ICEBERG_MAX_COLUMNS", 1000),
+			MaxRowBytes:             getIntEnv("PHILOTES_ICEBERG_MAX_ROW_BYTES", 16*1024*1024),
+			Partitioning:            getSliceEnv("PHILOTES_ICEBERG_PARTITIONING", nil),
+			TablePartitioning:       getSliceEnv("PHILOTES_ICEBERG_TABLE_PARTITIONING", nil),
+			DeleteMode:              getEnv("PHILOTES_ICEBERG_DELETE_MODE", "append_only"),
+			TableDeleteModes:        getSliceEnv("PHILOTES_ICEBERG_TABLE_DELETE_MODES", nil),
+			NumericPrecision:        getIntEnv("PHILOTES_ICEBERG_NUMERIC_PRECISION", 38),
+			NumericScale:            getIntEnv("PHILOTES_ICEBERG_NUMERIC_SCALE", 18),
+			TimestampMode:           getEnv("PHILOTES_ICEBERG_TIMESTAMP_MODE", "preserve"),
+			TableTimestampModes:     getSliceEnv("PHILOTES_ICEBERG_TABLE_TIMESTAMP_MODES", nil),
+
+			CompactionEnabled:             getBoolEnv("PHILOTES_ICEBERG_COMPACTION_ENABLED", false),
+			CompactionInterval:            getDurationEnv("PHILOTES_ICEBERG_COMPACTION_INTERVAL", time.Hour),
+			CompactionTargetFileSizeBytes: getIntEnv("PHILOTES_ICEBERG_COMPACTION_TARGET_FILE_SIZE_BYTES", 128*1024*1024),
+			CompactionMinInputFiles:       getIntEnv("PHILOTES_ICEBERG_COMPACTION_MIN_INPUT_FILES", 5),
+			SnapshotMaxAge:                getDurationEnv("PHILOTES_ICEBERG_SNAPSHOT_MAX_AGE", 5*24*time.Hour),
+			SnapshotsToKeep:               getIntEnv("PHILOTES_ICEBERG_SNAPSHOTS_TO_KEEP", 1),
+		},
+
+		Kafka: KafkaConfig{
+			Brokers:                getSliceEnv("PHILOTES_KAFKA_BROKERS", nil),
+			TopicTemplate:          getEnv("PHILOTES_KAFKA_TOPIC_TEMPLATE", "cdc.{schema}.{table}"),
+			SchemaRegistryURL:      getEnv("PHILOTES_KAFKA_SCHEMA_REGISTRY_URL", ""),
+			ClientID:               getEnv("PHILOTES_KAFKA_CLIENT_ID", "philotes-worker"),
+			RequiredAcks:           getIntEnv("PHILOTES_KAFKA_REQUIRED_ACKS", -1),
+			Timeout:                getDurationEnv("PHILOTES_KAFKA_TIMEOUT", 30*time.Second),
+			Compression:            getEnv("PHILOTES_KAFKA_COMPRESSION", "snappy"),
+			Idempotent:             getBoolEnv("PHILOTES_KAFKA_IDEMPOTENT", true),
+			TLSEnabled:             getBoolEnv("PHILOTES_KAFKA_TLS_ENABLED", false),
+			TLSCAFile:              getEnv("PHILOTES_KAFKA_TLS_CA_FILE", ""),
+			TLSCertFile:            getEnv("PHILOTES_KAFKA_TLS_CERT_FILE", ""),
+			TLSKeyFile:             getEnv("PHILOTES_KAFKA_TLS_KEY_FILE", ""),
+			TLSSkipVerify:          getBoolEnv("PHILOTES_KAFKA_TLS_SKIP_VERIFY", false),
+			SASLMechanism:          getEnv("PHILOTES_KAFKA_SASL_MECHANISM", ""),
+			SASLUsername:           getEnv("PHILOTES_KAFKA_SASL_USERNAME", ""),
+			SASLPassword:           getEnv("PHILOTES_KAFKA_SASL_PASSWORD", ""),
+			SchemaRegistryUsername: getEnv("PHILOTES_KAFKA_SCHEMA_REGISTRY_USERNAME", ""),
+			SchemaRegistryPassword: getEnv("PHILOTES_KAFKA_SCHEMA_REGISTRY_PASSWORD", ""),
 		},
 
 		Storage: StorageConfig{
-			Endpoint:  getEnv("PHILOTES_STORAGE_ENDPOINT", "localhost:9000"),
-			AccessKey: getEnv("PHILOTES_STORAGE_ACCESS_KEY", "minioadmin"),
-			SecretKey: getEnv("PHILOTES_STORAGE_SECRET_KEY", "minioadmin"),
+			Provider:  storageProvider,
+			Endpoint:  getEnv("PHILOTES_STORAGE_ENDPOINT", storageEndpoint),
+			AccessKey: getEnv("PHILOTES_STORAGE_ACCESS_KEY", storageAccessKey),
+			SecretKey: getEnv("PHILOTES_STORAGE_SECRET_KEY", storageSecretKey),
 			Bucket:    getEnv("PHILOTES_STORAGE_BUCKET", "philotes"),
 			UseSSL:    getBoolEnv("PHILOTES_STORAGE_USE_SSL", false),
+			Region:    getEnv("PHILOTES_STORAGE_REGION", ""),
 		},
 
 		Metrics: MetricsConfig{
@@ -789,11 +1603,20 @@ func Load() (*Config, error) {
 		},
 
 		Alerting: AlertingConfig{
-			Enabled:             getBoolEnv("PHILOTES_ALERTING_ENABLED", true),
-			EvaluationInterval:  getDurationEnv("PHILOTES_ALERTING_EVALUATION_INTERVAL", 30*time.Second),
-			NotificationTimeout: getDurationEnv("PHILOTES_ALERTING_NOTIFICATION_TIMEOUT", 10*time.Second),
-			PrometheusURL:       getEnv("PHILOTES_PROMETHEUS_URL", "http://localhost:9090"),
-			RetentionDays:       getIntEnv("PHILOTES_ALERTING_RETENTION_DAYS", 30),
+			Enabled:                 getBoolEnv("PHILOTES_ALERTING_ENABLED", true),
+			EvaluationInterval:      getDurationEnv("PHILOTES_ALERTING_EVALUATION_INTERVAL", 30*time.Second),
+			NotificationTimeout:     getDurationEnv("PHILOTES_ALERTING_NOTIFICATION_TIMEOUT", 10*time.Second),
+			PrometheusURL:           getEnv("PHILOTES_PROMETHEUS_URL", ""),
+			MetricsEndpoints:        getSliceEnv("PHILOTES_ALERTING_METRICS_ENDPOINTS", nil),
+			RetentionDays:           getIntEnv("PHILOTES_ALERTING_RETENTION_DAYS", 30),
+			MinNotificationInterval: getDurationEnv("PHILOTES_ALERTING_MIN_NOTIFICATION_INTERVAL", 0),
+			RemoteWrite: RemoteWriteConfig{
+				Enabled:         getBoolEnv("PHILOTES_ALERTING_REMOTE_WRITE_ENABLED", false),
+				MetricPrefixes:  getSliceEnv("PHILOTES_ALERTING_REMOTE_WRITE_METRIC_PREFIXES", []string{"philotes_"}),
+				Retention:       getDurationEnv("PHILOTES_ALERTING_REMOTE_WRITE_RETENTION", 6*time.Hour),
+				CleanupInterval: getDurationEnv("PHILOTES_ALERTING_REMOTE_WRITE_CLEANUP_INTERVAL", 10*time.Minute),
+				Staleness:       getDurationEnv("PHILOTES_ALERTING_REMOTE_WRITE_STALENESS", 5*time.Minute),
+			},
 		},
 
 		Scaling: ScalingConfig{
@@ -812,14 +1635,17 @@ func Load() (*Config, error) {
 		},
 
 		NodeScaling: NodeScalingConfig{
-			Enabled:              getBoolEnv("PHILOTES_NODE_SCALING_ENABLED", false),
-			KubeconfigPath:       getEnv("PHILOTES_KUBECONFIG", ""),
-			NodeJoinTimeout:      getDurationEnv("PHILOTES_NODE_JOIN_TIMEOUT", 10*time.Minute),
-			NodeDrainTimeout:     getDurationEnv("PHILOTES_NODE_DRAIN_TIMEOUT", 5*time.Minute),
-			NodeDrainGracePeriod: getDurationEnv("PHILOTES_NODE_DRAIN_GRACE_PERIOD", 30*time.Second),
-			DefaultMinNodes:      getIntEnv("PHILOTES_NODE_DEFAULT_MIN", 1),
-			DefaultMaxNodes:      getIntEnv("PHILOTES_NODE_DEFAULT_MAX", 10),
-			DefaultImage:         getEnv("PHILOTES_NODE_DEFAULT_IMAGE", "ubuntu-24.04"),
+			Enabled:                 getBoolEnv("PHILOTES_NODE_SCALING_ENABLED", false),
+			KubeconfigPath:          getEnv("PHILOTES_KUBECONFIG", ""),
+			NodeJoinTimeout:         getDurationEnv("PHILOTES_NODE_JOIN_TIMEOUT", 10*time.Minute),
+			NodeDrainTimeout:        getDurationEnv("PHILOTES_NODE_DRAIN_TIMEOUT", 5*time.Minute),
+			NodeDrainGracePeriod:    getDurationEnv("PHILOTES_NODE_DRAIN_GRACE_PERIOD", 30*time.Second),
+			DefaultMinNodes:         getIntEnv("PHILOTES_NODE_DEFAULT_MIN", 1),
+			DefaultMaxNodes:         getIntEnv("PHILOTES_NODE_DEFAULT_MAX", 10),
+			DefaultImage:            getEnv("PHILOTES_NODE_DEFAULT_IMAGE", "ubuntu-24.04"),
+			PricingRefreshProviders: getSliceEnv("PHILOTES_PRICING_REFRESH_PROVIDERS", nil),
+			PricingRefreshInterval:  getDurationEnv("PHILOTES_PRICING_REFRESH_INTERVAL", 6*time.Hour),
+			NotificationChannelID:   getEnv("PHILOTES_NODE_SCALING_NOTIFICATION_CHANNEL", ""),
 			Hetzner: HetznerProviderConfig{
 				Token: getEnv("PHILOTES_HETZNER_TOKEN", ""),
 			},
@@ -846,6 +1672,9 @@ func Load() (*Config, error) {
 				Username:     getEnv("PHILOTES_CONTABO_USERNAME", ""),
 				Password:     getEnv("PHILOTES_CONTABO_PASSWORD", ""),
 			},
+			DigitalOcean: DigitalOceanProviderConfig{
+				Token: getEnv("PHILOTES_DIGITALOCEAN_TOKEN", ""),
+			},
 		},
 
 		Auth: AuthConfig{
@@ -856,6 +1685,19 @@ func Load() (*Config, error) {
 			BCryptCost:    getIntEnv("PHILOTES_AUTH_BCRYPT_COST", 12),
 			AdminEmail:    getEnv("PHILOTES_AUTH_ADMIN_EMAIL", ""),
 			AdminPassword: getEnv("PHILOTES_AUTH_ADMIN_PASSWORD", ""),
+
+			AuditRetentionDays:   getIntEnv("PHILOTES_AUTH_AUDIT_RETENTION_DAYS", 0),
+			AuditCleanupInterval: getDurationEnv("PHILOTES_AUTH_AUDIT_CLEANUP_INTERVAL", 0),
+		},
+
+		Secrets: SecretsConfig{
+			Backend:           getEnv("PHILOTES_SECRET_BACKEND", secretBackend),
+			RequireReferences: getBoolEnv("PHILOTES_SECRET_REQUIRE_REFERENCES", false),
+			AWS: AWSSecretsConfig{
+				Region:          getEnv("PHILOTES_AWS_SECRETS_REGION", os.Getenv("AWS_REGION")),
+				Endpoint:        getEnv("PHILOTES_AWS_SECRETS_ENDPOINT", ""),
+				RefreshInterval: getDurationEnv("PHILOTES_AWS_SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+			},
 		},
 
 		Vault: VaultConfig{
@@ -880,8 +1722,11 @@ func Load() (*Config, error) {
 		},
 
 		OAuth: OAuthConfig{
-			EncryptionKey: getEnv("PHILOTES_OAUTH_ENCRYPTION_KEY", ""),
-			BaseURL:       getEnv("PHILOTES_OAUTH_BASE_URL", getEnv("PHILOTES_API_BASE_URL", "http://localhost:8080")),
+			EncryptionKey:          oauthEncryptionKey,
+			PreviousEncryptionKeys: oauthPreviousEncryptionKeys,
+			BaseURL:                getEnv("PHILOTES_OAUTH_BASE_URL", getEnv("PHILOTES_API_BASE_URL", "http://localhost:8080")),
+			RefreshInterval:        getDurationEnv("PHILOTES_OAUTH_REFRESH_INTERVAL", 5*time.Minute),
+			RefreshWindow:          getDurationEnv("PHILOTES_OAUTH_REFRESH_WINDOW", 10*time.Minute),
 			Hetzner: HetznerOAuthConfig{
 				ClientID:     getEnv("PHILOTES_OAUTH_HETZNER_CLIENT_ID", ""),
 				ClientSecret: getEnv("PHILOTES_OAUTH_HETZNER_CLIENT_SECRET", ""),
@@ -892,15 +1737,28 @@ func Load() (*Config, error) {
 				ClientSecret: getEnv("PHILOTES_OAUTH_OVH_CLIENT_SECRET", ""),
 				Enabled:      getEnv("PHILOTES_OAUTH_OVH_CLIENT_ID", "") != "",
 			},
+			Generic: GenericOAuthConfig{
+				Name:            getEnv("PHILOTES_OAUTH_GENERIC_NAME", "generic"),
+				DisplayName:     getEnv("PHILOTES_OAUTH_GENERIC_DISPLAY_NAME", ""),
+				AuthURL:         getEnv("PHILOTES_OAUTH_GENERIC_AUTH_URL", ""),
+				TokenURL:        getEnv("PHILOTES_OAUTH_GENERIC_TOKEN_URL", ""),
+				Scopes:          getSliceEnv("PHILOTES_OAUTH_GENERIC_SCOPES", nil),
+				ClientID:        getEnv("PHILOTES_OAUTH_GENERIC_CLIENT_ID", ""),
+				ClientSecret:    getEnv("PHILOTES_OAUTH_GENERIC_CLIENT_SECRET", ""),
+				CredentialField: getEnv("PHILOTES_OAUTH_GENERIC_CREDENTIAL_FIELD", ""),
+				Enabled:         getEnv("PHILOTES_OAUTH_GENERIC_CLIENT_ID", "") != "",
+			},
 		},
 
 		OIDC: OIDCConfig{
-			Enabled:         getBoolEnv("PHILOTES_OIDC_ENABLED", false),
-			AllowLocalLogin: getBoolEnv("PHILOTES_OIDC_ALLOW_LOCAL_LOGIN", true),
-			AutoCreateUsers: getBoolEnv("PHILOTES_OIDC_AUTO_CREATE_USERS", true),
-			DefaultRole:     getEnv("PHILOTES_OIDC_DEFAULT_ROLE", "viewer"),
-			EncryptionKey:   getEnv("PHILOTES_OIDC_ENCRYPTION_KEY", ""),
-			StateExpiration: getDurationEnv("PHILOTES_OIDC_STATE_EXPIRATION", 10*time.Minute),
+			Enabled:              getBoolEnv("PHILOTES_OIDC_ENABLED", false),
+			AllowLocalLogin:      getBoolEnv("PHILOTES_OIDC_ALLOW_LOCAL_LOGIN", true),
+			AutoCreateUsers:      getBoolEnv("PHILOTES_OIDC_AUTO_CREATE_USERS", true),
+			DefaultRole:          getEnv("PHILOTES_OIDC_DEFAULT_ROLE", "viewer"),
+			EncryptionKey:        getEnv("PHILOTES_OIDC_ENCRYPTION_KEY", ""),
+			StateExpiration:      getDurationEnv("PHILOTES_OIDC_STATE_EXPIRATION", 10*time.Minute),
+			AllowedRedirectHosts: getSliceEnv("PHILOTES_OIDC_ALLOWED_REDIRECT_HOSTS", nil),
+			DiscoveryCacheTTL:    getDurationEnv("PHILOTES_OIDC_DISCOVERY_CACHE_TTL", time.Hour),
 		},
 
 		Trino: TrinoConfig{
@@ -914,6 +1772,28 @@ func Load() (*Config, error) {
 			HealthCheckInterval: getDurationEnv("PHILOTES_TRINO_HEALTH_CHECK_INTERVAL", 30*time.Second),
 		},
 
+		Query: QueryConfig{
+			Engine:        getEnv("PHILOTES_QUERY_ENGINE", QueryEngineTrino),
+			MaxResultRows: getIntEnv("PHILOTES_QUERY_MAX_RESULT_ROWS", 10000),
+			Cache: QueryCacheConfig{
+				TTL:                  getDurationEnv("PHILOTES_QUERY_CACHE_TTL", 0),
+				MaxEntries:           getIntEnv("PHILOTES_QUERY_CACHE_MAX_ENTRIES", 256),
+				SnapshotInvalidation: getBoolEnv("PHILOTES_QUERY_CACHE_SNAPSHOT_INVALIDATION", true),
+			},
+			History: QueryHistoryConfig{
+				Enabled:        getBoolEnv("PHILOTES_QUERY_HISTORY_ENABLED", true),
+				RedactLiterals: getBoolEnv("PHILOTES_QUERY_HISTORY_REDACT_LITERALS", false),
+			},
+			DuckDB: DuckDBConfig{
+				Catalog:         getEnv("PHILOTES_DUCKDB_CATALOG", "iceberg"),
+				Schema:          getEnv("PHILOTES_DUCKDB_SCHEMA", "philotes"),
+				QueryTimeout:    getDurationEnv("PHILOTES_DUCKDB_QUERY_TIMEOUT", 5*time.Minute),
+				MemoryLimit:     getEnv("PHILOTES_DUCKDB_MEMORY_LIMIT", ""),
+				Threads:         getIntEnv("PHILOTES_DUCKDB_THREADS", 0),
+				AllowLocalFiles: getBoolEnv("PHILOTES_DUCKDB_ALLOW_LOCAL_FILES", false),
+			},
+		},
+
 		QueryScaling: QueryScalingConfig{
 			Enabled:                        getBoolEnv("PHILOTES_QUERY_SCALING_ENABLED", false),
 			PrometheusURL:                  getEnv("PHILOTES_PROMETHEUS_URL", "http://localhost:9090"),
@@ -936,54 +1816,110 @@ func Load() (*Config, error) {
 		},
 	}
 
+	cfg.envErrors = envErrors
+	cfg.warnings = unknownFileKeys(fileValues, readKeys)
+	cfg.settings = settings
+
+	if err := cfg.validateSecretReferences(); err != nil {
+		return nil, fmt.Errorf("invalid secret reference: %w", err)
+	}
+
 	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
+// Warnings returns the problems found while loading that do not prevent
+// startup, such as unknown config file keys.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// lookupEnv returns the value of the environment variable key or, if it is
+// unset or empty, of the config file setting standing in for it.
+func lookupEnv(key string) string {
+	if readKeys != nil {
+		readKeys[key] = true
+	}
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	return fileValues[key].value
+}
+
+// recordSetting records the effective value of the setting key.
+func recordSetting(key, value string) {
+	if settings != nil {
+		settings[key] = value
+	}
+}
+
+// invalidEnv records that the value of key could not be parsed as kind,
+// naming the config file key when the value came from the file.
+func invalidEnv(key, value, kind string) {
+	source := key
+	if fv, ok := fileValues[key]; ok && os.Getenv(key) == "" {
+		source = fmt.Sprintf("config file key %s (%s)", fv.path, key)
+	}
+	msg := fmt.Sprintf("%s: %q is not a valid %s", source, value, kind)
+	if !slices.Contains(envErrors, msg) {
+		envErrors = append(envErrors, msg)
+	}
+}
+
+func getEnv(key, defaultValue string) (result string) {
+	defer func() { recordSetting(key, result) }()
+	if value := lookupEnv(key); value != "" {
+		return value
+	}
 	return defaultValue
 }
 
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+func getIntEnv(key string, defaultValue int) (result int) {
+	defer func() { recordSetting(key, strconv.Itoa(result)) }()
+	if value := lookupEnv(key); value != "" {
 		if i, err := strconv.Atoi(value); err == nil {
 			return i
 		}
+		invalidEnv(key, value, "integer")
 	}
 	return defaultValue
 }
 
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+func getBoolEnv(key string, defaultValue bool) (result bool) {
+	defer func() { recordSetting(key, strconv.FormatBool(result)) }()
+	if value := lookupEnv(key); value != "" {
 		if b, err := strconv.ParseBool(value); err == nil {
 			return b
 		}
+		invalidEnv(key, value, "boolean")
 	}
 	return defaultValue
 }
 
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+func getDurationEnv(key string, defaultValue time.Duration) (result time.Duration) {
+	defer func() { recordSetting(key, result.String()) }()
+	if value := lookupEnv(key); value != "" {
 		if d, err := time.ParseDuration(value); err == nil {
 			return d
 		}
+		invalidEnv(key, value, "duration")
 	}
 	return defaultValue
 }
 
-func getFloatEnv(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
+func getFloatEnv(key string, defaultValue float64) (result float64) {
+	defer func() { recordSetting(key, strconv.FormatFloat(result, 'f', -1, 64)) }()
+	if value := lookupEnv(key); value != "" {
 		if f, err := strconv.ParseFloat(value, 64); err == nil {
 			return f
 		}
+		invalidEnv(key, value, "number")
 	}
 	return defaultValue
 }
 
-func getSliceEnv(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
+func getSliceEnv(key string, defaultValue []string) (result []string) {
+	defer func() { recordSetting(key, strings.Join(result, ",")) }()
+	if value := lookupEnv(key); value != "" {
 		var result []string
 		for _, v := range splitAndTrim(value, ",") {
 			if v != "" {
@@ -997,6 +1933,32 @@ func getSliceEnv(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getRateLimitOverridesEnv reads a list of prefix=rps:burst rate limit
+// overrides. Malformed entries are reported and skipped.
+func getRateLimitOverridesEnv(key string) (result []RateLimitOverride) {
+	defer func() {
+		entries := make([]string, len(result))
+		for i, o := range result {
+			entries[i] = fmt.Sprintf("%s=%s:%d", o.PathPrefix, strconv.FormatFloat(o.RPS, 'f', -1, 64), o.Burst)
+		}
+		recordSetting(key, strings.Join(entries, ","))
+	}()
+
+	for _, entry := range splitAndTrim(lookupEnv(key), ",") {
+		prefix, limit, ok := strings.Cut(entry, "=")
+		rpsValue, burstValue, ok2 := strings.Cut(limit, ":")
+		rps, rpsErr := strconv.ParseFloat(strings.TrimSpace(rpsValue), 64)
+		burst, burstErr := strconv.Atoi(strings.TrimSpace(burstValue))
+		prefix = strings.TrimSpace(prefix)
+		if !ok || !ok2 || !strings.HasPrefix(prefix, "/") || rpsErr != nil || burstErr != nil || rps <= 0 || burst < 1 {
+			invalidEnv(key, entry, "rate limit override (prefix=rps:burst)")
+			continue
+		}
+		result = append(result, RateLimitOverride{PathPrefix: prefix, RPS: rps, Burst: burst})
+	}
+	return result
+}
+
 func splitAndTrim(s, sep string) []string {
 	parts := make([]string, 0)
 	for _, p := range strings.Split(s, sep) {
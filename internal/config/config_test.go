@@ -1,7 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -112,3 +122,496 @@ func TestGetBoolEnv(t *testing.T) {
 		t.Errorf("getBoolEnv() = %v, want %v", got, false)
 	}
 }
+
+func TestLoadClientEndpoints(t *testing.T) {
+	os.Setenv("PHILOTES_API_ENDPOINTS", "http://api-1:8080, http://api-2:8080")
+	os.Setenv("PHILOTES_API_KEY", "pk_test")
+	os.Setenv("PHILOTES_CLIENT_MAX_ATTEMPTS", "5")
+	defer func() {
+		os.Unsetenv("PHILOTES_API_ENDPOINTS")
+		os.Unsetenv("PHILOTES_API_KEY")
+		os.Unsetenv("PHILOTES_CLIENT_MAX_ATTEMPTS")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := []string{"http://api-1:8080", "http://api-2:8080"}
+	if len(cfg.Client.Endpoints) != len(want) {
+		t.Fatalf("Client.Endpoints = %v, want %v", cfg.Client.Endpoints, want)
+	}
+	for i := range want {
+		if cfg.Client.Endpoints[i] != want[i] {
+			t.Errorf("Client.Endpoints[%d] = %v, want %v", i, cfg.Client.Endpoints[i], want[i])
+		}
+	}
+	if cfg.Client.APIKey != "pk_test" {
+		t.Errorf("Client.APIKey = %v, want %v", cfg.Client.APIKey, "pk_test")
+	}
+	if cfg.Client.MaxAttempts != 5 {
+		t.Errorf("Client.MaxAttempts = %v, want %v", cfg.Client.MaxAttempts, 5)
+	}
+}
+
+func TestLoadClientEndpointsFallbackToBaseURL(t *testing.T) {
+	os.Setenv("PHILOTES_API_BASE_URL", "https://philotes.example.com")
+	defer os.Unsetenv("PHILOTES_API_BASE_URL")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(cfg.Client.Endpoints) != 1 || cfg.Client.Endpoints[0] != "https://philotes.example.com" {
+		t.Errorf("Client.Endpoints = %v, want [%v]", cfg.Client.Endpoints, "https://philotes.example.com")
+	}
+	if cfg.Client.HealthCheckTimeout != 5*time.Second {
+		t.Errorf("Client.HealthCheckTimeout = %v, want %v", cfg.Client.HealthCheckTimeout, 5*time.Second)
+	}
+}
+
+func TestLoadRejectsMalformedSecretReference(t *testing.T) {
+	t.Setenv("PHILOTES_CDC_SOURCE_PASSWORD", "vault://philotes/database/source")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for malformed secret reference")
+	}
+	if !strings.Contains(err.Error(), "PHILOTES_CDC_SOURCE_PASSWORD") {
+		t.Errorf("expected error to name the variable, got %v", err)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("PHILOTES_STORAGE_SECRET_KEY", "vault://philotes/storage/minio#secret_key")
+	t.Setenv("PHILOTES_TRINO_PASSWORD", "vault://philotes/trino#password")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	secrets := map[string]string{
+		"vault://philotes/storage/minio#secret_key": "minio-secret",
+	}
+	resolve := func(_ context.Context, value string) (string, error) {
+		if secret, ok := secrets[value]; ok {
+			return secret, nil
+		}
+		return "", errors.New("secret not found")
+	}
+
+	err = cfg.ResolveSecrets(context.Background(), resolve)
+	if err == nil || !strings.Contains(err.Error(), "PHILOTES_TRINO_PASSWORD") {
+		t.Errorf("ResolveSecrets() error = %v, want error naming PHILOTES_TRINO_PASSWORD", err)
+	}
+	if cfg.Storage.SecretKey != "minio-secret" {
+		t.Errorf("Storage.SecretKey = %q, want minio-secret", cfg.Storage.SecretKey)
+	}
+	if cfg.Database.Password != "philotes" {
+		t.Errorf("expected literal Database.Password to be unchanged, got %q", cfg.Database.Password)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() of the defaults error = %v", err)
+	}
+
+	t.Setenv("PHILOTES_API_READ_TIMEOUT", "15 seconds")
+	t.Setenv("PHILOTES_AUTH_ENABLED", "true")
+	t.Setenv("PHILOTES_AUTH_JWT_SECRET", "short")
+	t.Setenv("PHILOTES_VAULT_ENABLED", "true")
+	t.Setenv("PHILOTES_BACKPRESSURE_LOW_WATERMARK", "9000")
+	t.Setenv("PHILOTES_BACKPRESSURE_LATENCY_HIGH_WATERMARK", "5s")
+	t.Setenv("PHILOTES_BACKPRESSURE_LATENCY_LOW_WATERMARK", "10s")
+	t.Setenv("PHILOTES_OAUTH_HETZNER_CLIENT_ID", "client")
+	t.Setenv("PHILOTES_TRINO_ENABLED", "true")
+	t.Setenv("PHILOTES_TRINO_URL", "trino:8080")
+	t.Setenv("PHILOTES_STORAGE_PROVIDER", "ftp")
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.API.ReadTimeout != 15*time.Second {
+		t.Errorf("API.ReadTimeout = %v, want the default", cfg.API.ReadTimeout)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want every problem reported")
+	}
+	for _, want := range []string{
+		`PHILOTES_API_READ_TIMEOUT: "15 seconds" is not a valid duration`,
+		"PHILOTES_AUTH_JWT_SECRET must be at least 32 characters",
+		"PHILOTES_VAULT_ADDRESS is required",
+		"PHILOTES_BACKPRESSURE_LOW_WATERMARK must be below PHILOTES_BACKPRESSURE_HIGH_WATERMARK",
+		"PHILOTES_BACKPRESSURE_LATENCY_LOW_WATERMARK must be below PHILOTES_BACKPRESSURE_LATENCY_HIGH_WATERMARK",
+		"PHILOTES_OAUTH_ENCRYPTION_KEY is required",
+		"PHILOTES_TRINO_URL must be an http or https URL",
+		"PHILOTES_STORAGE_PROVIDER must be one of: s3, gcs, azure",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestLoadStorageProvider(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.Provider != StorageProviderS3 || cfg.Storage.Endpoint != "localhost:9000" {
+		t.Errorf("Storage = %+v, want S3 on the local MinIO by default", cfg.Storage)
+	}
+
+	t.Setenv("PHILOTES_STORAGE_PROVIDER", "gcs")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.Endpoint != "" || cfg.Storage.AccessKey != "" || cfg.Storage.SecretKey != "" {
+		t.Errorf("Storage = %+v, want no MinIO defaults for GCS", cfg.Storage)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadOAuthEncryptionKeys(t *testing.T) {
+	t.Setenv("PHILOTES_OAUTH_ENCRYPTION_KEY", "single")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.OAuth.EncryptionKey != "single" || len(cfg.OAuth.PreviousEncryptionKeys) != 0 {
+		t.Errorf("OAuth keys = %q, %q, want the single key", cfg.OAuth.EncryptionKey, cfg.OAuth.PreviousEncryptionKeys)
+	}
+
+	t.Setenv("PHILOTES_OAUTH_ENCRYPTION_KEYS", "new, old1,old2")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.OAuth.EncryptionKey != "new" || !slices.Equal(cfg.OAuth.PreviousEncryptionKeys, []string{"old1", "old2"}) {
+		t.Errorf("OAuth keys = %q, %q, want the first key primary and the rest previous", cfg.OAuth.EncryptionKey, cfg.OAuth.PreviousEncryptionKeys)
+	}
+}
+
+func TestLoadRateLimitOverrides(t *testing.T) {
+	t.Setenv("PHILOTES_API_RATE_LIMIT_OVERRIDES", "/api/v1/query=5:10, /api/v1/installer=0.5:1, query=1:1, /api/v1/x=0:1")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := []RateLimitOverride{
+		{PathPrefix: "/api/v1/query", RPS: 5, Burst: 10},
+		{PathPrefix: "/api/v1/installer", RPS: 0.5, Burst: 1},
+	}
+	if !slices.Equal(cfg.API.RateLimitOverrides, want) {
+		t.Errorf("RateLimitOverrides = %+v, want %+v", cfg.API.RateLimitOverrides, want)
+	}
+
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `"query=1:1" is not a valid rate limit override`) {
+		t.Errorf("Validate() error = %v, want the malformed overrides reported", err)
+	}
+}
+
+func TestLoadSecretBackend(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Secrets.Backend != SecretBackendEnv {
+		t.Errorf("Secrets.Backend = %q, want env by default", cfg.Secrets.Backend)
+	}
+
+	t.Setenv("PHILOTES_VAULT_ENABLED", "true")
+	t.Setenv("PHILOTES_VAULT_ADDRESS", "http://vault:8200")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Secrets.Backend != SecretBackendVault {
+		t.Errorf("Secrets.Backend = %q, want vault when Vault is enabled", cfg.Secrets.Backend)
+	}
+
+	t.Setenv("PHILOTES_SECRET_BACKEND", "aws")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	err = cfg.Validate()
+	for _, want := range []string{
+		"PHILOTES_AWS_SECRETS_REGION or AWS_REGION is required",
+		"PHILOTES_VAULT_ENABLED must be false",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+
+	t.Setenv("PHILOTES_VAULT_ENABLED", "false")
+	t.Setenv("AWS_REGION", "eu-central-1")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Secrets.AWS.Region != "eu-central-1" {
+		t.Errorf("Secrets.AWS.Region = %q, want AWS_REGION", cfg.Secrets.AWS.Region)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidateSkipsSecretReferenceLength(t *testing.T) {
+	t.Setenv("PHILOTES_AUTH_ENABLED", "true")
+	t.Setenv("PHILOTES_AUTH_JWT_SECRET", "env://JWT_SECRET")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want the reference checked once resolved", err)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "philotes.yaml")
+	err := os.WriteFile(path, []byte(`
+api:
+  listen_addr: ":9090"
+  read_timeout: 30s
+  cors_origins:
+    - https://a.example.com
+    - https://b.example.com
+  rate_limit_burst: lots
+cdc:
+  buffer_size: 500
+trino_url: http://trino:8080
+unknown_setting: true
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PHILOTES_CONFIG_FILE", path)
+	t.Setenv("PHILOTES_CDC_BUFFER_SIZE", "2000")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.API.ListenAddr != ":9090" {
+		t.Errorf("API.ListenAddr = %q, want the file value", cfg.API.ListenAddr)
+	}
+	if cfg.API.ReadTimeout != 30*time.Second {
+		t.Errorf("API.ReadTimeout = %v, want the file value", cfg.API.ReadTimeout)
+	}
+	if want := []string{"https://a.example.com", "https://b.example.com"}; !slices.Equal(cfg.API.CORSOrigins, want) {
+		t.Errorf("API.CORSOrigins = %v, want %v", cfg.API.CORSOrigins, want)
+	}
+	if cfg.Trino.URL != "http://trino:8080" {
+		t.Errorf("Trino.URL = %q, want the file value", cfg.Trino.URL)
+	}
+	if cfg.CDC.BufferSize != 2000 {
+		t.Errorf("CDC.BufferSize = %d, want the environment to override the file", cfg.CDC.BufferSize)
+	}
+	if cfg.API.WriteTimeout != 15*time.Second {
+		t.Errorf("API.WriteTimeout = %v, want the default", cfg.API.WriteTimeout)
+	}
+
+	err = cfg.Validate()
+	if want := `config file key api.rate_limit_burst (PHILOTES_API_RATE_LIMIT_BURST): "lots" is not a valid integer`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "unknown_setting") {
+		t.Errorf("Warnings() = %v, want one for unknown_setting", warnings)
+	}
+}
+
+func TestLoadConfigFileTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "philotes.toml")
+	err := os.WriteFile(path, []byte(`
+[api]
+listen_addr = ":9090"
+rate_limit_burst = 50
+
+[trino]
+enabled = true
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PHILOTES_CONFIG_FILE", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.API.ListenAddr != ":9090" || cfg.API.RateLimitBurst != 50 || !cfg.Trino.Enabled {
+		t.Errorf("Load() = %+v, %+v, want the file values", cfg.API, cfg.Trino)
+	}
+	if warnings := cfg.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none", warnings)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unsupported.json": `{}`,
+		"malformed.yaml":   "api: [",
+		"duplicate.yaml":   "api_listen_addr: a\napi:\n  listen_addr: b\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PHILOTES_CONFIG_FILE", path)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() of %s error = nil, want an error", name)
+		}
+	}
+
+	t.Setenv("PHILOTES_CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Error("Load() of a missing file error = nil, want an error")
+	}
+}
+
+func TestReload(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	t.Setenv("PHILOTES_CDC_FLUSH_INTERVAL", "1s")
+	t.Setenv("PHILOTES_API_RATE_LIMIT_RPS", "5")
+	t.Setenv("PHILOTES_API_LISTEN_ADDR", ":9090")
+	t.Setenv("PHILOTES_DB_HOST", "db.internal")
+
+	effective, restart, err := cfg.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if effective.CDC.FlushInterval != time.Second || effective.API.RateLimitRPS != 5 {
+		t.Errorf("Reload() = %v, %v, want the live settings applied", effective.CDC.FlushInterval, effective.API.RateLimitRPS)
+	}
+	if effective.API.ListenAddr != ":8080" || effective.Database.Host != "localhost" {
+		t.Errorf("Reload() = %q, %q, want the other settings kept", effective.API.ListenAddr, effective.Database.Host)
+	}
+	if want := []string{"PHILOTES_API_LISTEN_ADDR", "PHILOTES_DB_HOST"}; !slices.Equal(restart, want) {
+		t.Errorf("Reload() restart = %v, want %v", restart, want)
+	}
+	if got := effective.Settings()["PHILOTES_CDC_FLUSH_INTERVAL"]; got != "1s" {
+		t.Errorf("Settings() flush interval = %q, want the reloaded value", got)
+	}
+	if cfg.CDC.FlushInterval != 5*time.Second {
+		t.Errorf("Reload() changed the original FlushInterval to %v", cfg.CDC.FlushInterval)
+	}
+
+	t.Setenv("PHILOTES_BACKPRESSURE_LOW_WATERMARK", "100")
+	t.Setenv("PHILOTES_BACKPRESSURE_HIGH_WATERMARK", "10")
+	if _, _, err := cfg.Reload(); err == nil {
+		t.Error("Reload() of an invalid configuration error = nil, want an error")
+	}
+}
+
+func TestSettingsRedactsSecrets(t *testing.T) {
+	t.Setenv("PHILOTES_DB_PASSWORD", "hunter2")
+	t.Setenv("PHILOTES_KAFKA_SASL_PASSWORD", "hunter3")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	settings := cfg.Settings()
+	for _, key := range []string{"PHILOTES_DB_PASSWORD", "PHILOTES_KAFKA_SASL_PASSWORD"} {
+		if settings[key] != "[REDACTED]" {
+			t.Errorf("Settings()[%s] = %q, want it redacted", key, settings[key])
+		}
+	}
+	if settings["PHILOTES_AUTH_JWT_SECRET"] != "" {
+		t.Errorf("Settings() JWT secret = %q, want an unset secret left empty", settings["PHILOTES_AUTH_JWT_SECRET"])
+	}
+	if settings["PHILOTES_API_LISTEN_ADDR"] != ":8080" {
+		t.Errorf("Settings() listen addr = %q, want the default", settings["PHILOTES_API_LISTEN_ADDR"])
+	}
+}
+
+func TestConfigNeverPrintsSecrets(t *testing.T) {
+	var secrets []string
+	for i, key := range slices.Sorted(maps.Keys((&Config{}).credentialFields())) {
+		secret := fmt.Sprintf("hunter2-%02d", i)
+		t.Setenv(key, secret)
+		secrets = append(secrets, secret)
+	}
+	for key := range (&Config{}).credentialListFields() {
+		t.Setenv(key, "hunter2-primary,hunter2-previous")
+		secrets = append(secrets, "hunter2-primary", "hunter2-previous")
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	marshaled, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var logged bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logged, nil))
+	logger.Info("config",
+		"config", cfg,
+		"database", cfg.Database,
+		"source", cfg.CDC.Source,
+		"vault", cfg.Vault,
+		"hetzner", cfg.NodeScaling.Hetzner,
+		"scaleway", cfg.NodeScaling.Scaleway,
+		"ovh", cfg.NodeScaling.OVH,
+		"exoscale", cfg.NodeScaling.Exoscale,
+		"contabo", cfg.NodeScaling.Contabo,
+		"digitalocean", cfg.NodeScaling.DigitalOcean,
+	)
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("config", "config", cfg, "database", cfg.Database)
+
+	outputs := map[string]string{
+		"json.Marshal":  string(marshaled),
+		"slog":          logged.String(),
+		"String":        cfg.String(),
+		"%+v":           fmt.Sprintf("%+v %+v %+v", cfg.Database, cfg.CDC.Source, cfg.NodeScaling),
+		"RedactedDSN":   cfg.Database.RedactedDSN() + cfg.CDC.Source.RedactedDSN(),
+		"RedactedURL":   cfg.CDC.Source.RedactedURL(),
+		"Settings":      fmt.Sprint(cfg.Settings()),
+		"Redacted copy": fmt.Sprint(*cfg.Redacted()),
+	}
+	for name, output := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s output contains secret %q", name, secret)
+			}
+		}
+		if !strings.Contains(output, "[REDACTED]") {
+			t.Errorf("%s output = %s, want redacted credentials", name, output)
+		}
+	}
+
+	if cfg.Database.Password == "[REDACTED]" || !strings.Contains(cfg.Database.DSN(), cfg.Database.Password) {
+		t.Error("redacting modified the configuration")
+	}
+}
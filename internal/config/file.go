@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileEnv names the environment variable holding the config file path.
+const configFileEnv = "PHILOTES_CONFIG_FILE"
+
+// fileValue is a setting read from the config file.
+type fileValue struct {
+	// path is the dotted key of the setting in the file
+	path  string
+	value string
+}
+
+// readConfigFile reads a YAML or TOML config file and returns its settings
+// keyed by the environment variable each one stands in for. Keys are the
+// variable names without the PHILOTES_ prefix, in any case, and may be
+// nested along underscores: trino: {url: ...} and trino_url: ... both set
+// PHILOTES_TRINO_URL. Lists are joined with commas.
+func readConfigFile(path string) (map[string]fileValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q (want .yaml, .yml or .toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := make(map[string]fileValue)
+	if err := flattenConfigFile("PHILOTES", "", doc, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenConfigFile adds the settings under the file key path, which stand
+// in for variables starting with name, to values.
+func flattenConfigFile(name, path string, node any, values map[string]fileValue) error {
+	if table, ok := node.(map[string]any); ok {
+		for key, child := range table {
+			childName := name + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if err := flattenConfigFile(childName, childPath, child, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var value string
+	if list, ok := node.([]any); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			s, err := configFileScalar(item)
+			if err != nil {
+				return fmt.Errorf("config file key %s: %w", path, err)
+			}
+			items = append(items, s)
+		}
+		value = strings.Join(items, ",")
+	} else {
+		s, err := configFileScalar(node)
+		if err != nil {
+			return fmt.Errorf("config file key %s: %w", path, err)
+		}
+		value = s
+	}
+
+	if existing, ok := values[name]; ok {
+		return fmt.Errorf("config file keys %s and %s both set %s", existing.path, path, name)
+	}
+	values[name] = fileValue{path: path, value: value}
+	return nil
+}
+
+// configFileScalar formats a scalar file value the way it would be written
+// in an environment variable.
+func configFileScalar(node any) (string, error) {
+	switch v := node.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", node)
+	}
+}
+
+// unknownFileKeys returns a warning for every config file setting no
+// configuration field was read from.
+func unknownFileKeys(values map[string]fileValue, read map[string]bool) []string {
+	var warnings []string
+	for name, v := range values {
+		if !read[name] {
+			warnings = append(warnings, fmt.Sprintf("config file key %s (%s) is not a known setting and was ignored", v.path, name))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
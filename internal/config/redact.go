@@ -0,0 +1,274 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// redacted replaces credentials in logs, Settings and marshaled configs.
+const redacted = "[REDACTED]"
+
+// redactSecret returns redacted for a set credential and "" for an unset one.
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// credentialFields returns every credential field, keyed by the environment
+// variable that sets it: those that may hold a secret reference and those
+// that may not.
+func (c *Config) credentialFields() map[string]*string {
+	fields := c.secretFields()
+	fields["PHILOTES_KAFKA_SASL_PASSWORD"] = &c.Kafka.SASLPassword
+	fields["PHILOTES_KAFKA_SCHEMA_REGISTRY_PASSWORD"] = &c.Kafka.SchemaRegistryPassword
+	fields["PHILOTES_OIDC_ENCRYPTION_KEY"] = &c.OIDC.EncryptionKey
+	fields["PHILOTES_OVH_CONSUMER_KEY"] = &c.NodeScaling.OVH.ConsumerKey
+	fields["PHILOTES_VAULT_TOKEN"] = &c.Vault.Token
+	return fields
+}
+
+// credentialListFields returns every field holding a list of credentials,
+// keyed by the environment variable that sets it.
+func (c *Config) credentialListFields() map[string]*[]string {
+	return map[string]*[]string{
+		"PHILOTES_OAUTH_ENCRYPTION_KEYS": &c.OAuth.PreviousEncryptionKeys,
+	}
+}
+
+// Redacted returns a copy of c with every credential redacted.
+func (c *Config) Redacted() *Config {
+	r := *c
+	for _, field := range r.credentialFields() {
+		*field = redactSecret(*field)
+	}
+	for _, field := range r.credentialListFields() {
+		list := make([]string, len(*field))
+		for i, s := range *field {
+			list[i] = redactSecret(s)
+		}
+		*field = list
+	}
+	r.settings = c.Settings()
+	return &r
+}
+
+// String formats the configuration with credentials redacted.
+func (c *Config) String() string {
+	type plain Config
+	return fmt.Sprintf("%+v", plain(*c.Redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with
+// credentials redacted.
+func (c *Config) LogValue() slog.Value {
+	type plain Config
+	return slog.AnyValue(plain(*c.Redacted()))
+}
+
+// MarshalJSON marshals the configuration with credentials redacted.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	return json.Marshal(plain(*c.Redacted()))
+}
+
+func (d DatabaseConfig) redacted() DatabaseConfig {
+	d.Password = redactSecret(d.Password)
+	return d
+}
+
+// RedactedDSN returns the database connection string with the password
+// redacted.
+func (d DatabaseConfig) RedactedDSN() string {
+	return d.redacted().DSN()
+}
+
+// String formats the configuration with the password redacted.
+func (d DatabaseConfig) String() string {
+	type plain DatabaseConfig
+	return fmt.Sprintf("%+v", plain(d.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the connection settings
+// without the password.
+func (d DatabaseConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", d.Host),
+		slog.Int("port", d.Port),
+		slog.String("name", d.Name),
+		slog.String("user", d.User),
+		slog.String("password", redactSecret(d.Password)),
+		slog.String("sslmode", d.SSLMode),
+	)
+}
+
+func (s SourceConfig) redacted() SourceConfig {
+	s.Password = redactSecret(s.Password)
+	return s
+}
+
+// RedactedDSN returns the source connection string with the password
+// redacted.
+func (s SourceConfig) RedactedDSN() string {
+	return s.redacted().DSN()
+}
+
+// RedactedURL returns the source connection URL with the password redacted.
+func (s SourceConfig) RedactedURL() string {
+	return s.redacted().URL()
+}
+
+// String formats the configuration with the password redacted.
+func (s SourceConfig) String() string {
+	type plain SourceConfig
+	return fmt.Sprintf("%+v", plain(s.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the connection settings
+// without the password.
+func (s SourceConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("type", s.Type),
+		slog.String("id", s.SourceID()),
+		slog.String("host", s.Host),
+		slog.Int("port", s.Port),
+		slog.String("database", s.Database),
+		slog.String("user", s.User),
+		slog.String("password", redactSecret(s.Password)),
+		slog.String("sslmode", s.SSLMode),
+	)
+}
+
+func (v VaultConfig) redacted() VaultConfig {
+	v.Token = redactSecret(v.Token)
+	return v
+}
+
+// String formats the configuration with the token redacted.
+func (v VaultConfig) String() string {
+	type plain VaultConfig
+	return fmt.Sprintf("%+v", plain(v.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// token redacted.
+func (v VaultConfig) LogValue() slog.Value {
+	type plain VaultConfig
+	return slog.AnyValue(plain(v.redacted()))
+}
+
+func (h HetznerProviderConfig) redacted() HetznerProviderConfig {
+	h.Token = redactSecret(h.Token)
+	return h
+}
+
+// String formats the configuration with the token redacted.
+func (h HetznerProviderConfig) String() string {
+	type plain HetznerProviderConfig
+	return fmt.Sprintf("%+v", plain(h.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// token redacted.
+func (h HetznerProviderConfig) LogValue() slog.Value {
+	type plain HetznerProviderConfig
+	return slog.AnyValue(plain(h.redacted()))
+}
+
+func (s ScalewayProviderConfig) redacted() ScalewayProviderConfig {
+	s.AccessKey = redactSecret(s.AccessKey)
+	s.SecretKey = redactSecret(s.SecretKey)
+	return s
+}
+
+// String formats the configuration with the keys redacted.
+func (s ScalewayProviderConfig) String() string {
+	type plain ScalewayProviderConfig
+	return fmt.Sprintf("%+v", plain(s.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// keys redacted.
+func (s ScalewayProviderConfig) LogValue() slog.Value {
+	type plain ScalewayProviderConfig
+	return slog.AnyValue(plain(s.redacted()))
+}
+
+func (o OVHProviderConfig) redacted() OVHProviderConfig {
+	o.ApplicationSecret = redactSecret(o.ApplicationSecret)
+	o.ConsumerKey = redactSecret(o.ConsumerKey)
+	return o
+}
+
+// String formats the configuration with the application secret and
+// consumer key redacted.
+func (o OVHProviderConfig) String() string {
+	type plain OVHProviderConfig
+	return fmt.Sprintf("%+v", plain(o.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// application secret and consumer key redacted.
+func (o OVHProviderConfig) LogValue() slog.Value {
+	type plain OVHProviderConfig
+	return slog.AnyValue(plain(o.redacted()))
+}
+
+func (e ExoscaleProviderConfig) redacted() ExoscaleProviderConfig {
+	e.APIKey = redactSecret(e.APIKey)
+	e.APISecret = redactSecret(e.APISecret)
+	return e
+}
+
+// String formats the configuration with the API key and secret redacted.
+func (e ExoscaleProviderConfig) String() string {
+	type plain ExoscaleProviderConfig
+	return fmt.Sprintf("%+v", plain(e.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// API key and secret redacted.
+func (e ExoscaleProviderConfig) LogValue() slog.Value {
+	type plain ExoscaleProviderConfig
+	return slog.AnyValue(plain(e.redacted()))
+}
+
+func (c ContaboProviderConfig) redacted() ContaboProviderConfig {
+	c.ClientSecret = redactSecret(c.ClientSecret)
+	c.Password = redactSecret(c.Password)
+	return c
+}
+
+// String formats the configuration with the client secret and password
+// redacted.
+func (c ContaboProviderConfig) String() string {
+	type plain ContaboProviderConfig
+	return fmt.Sprintf("%+v", plain(c.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// client secret and password redacted.
+func (c ContaboProviderConfig) LogValue() slog.Value {
+	type plain ContaboProviderConfig
+	return slog.AnyValue(plain(c.redacted()))
+}
+
+func (d DigitalOceanProviderConfig) redacted() DigitalOceanProviderConfig {
+	d.Token = redactSecret(d.Token)
+	return d
+}
+
+// String formats the configuration with the token redacted.
+func (d DigitalOceanProviderConfig) String() string {
+	type plain DigitalOceanProviderConfig
+	return fmt.Sprintf("%+v", plain(d.redacted()))
+}
+
+// LogValue implements slog.LogValuer, logging the configuration with the
+// token redacted.
+func (d DigitalOceanProviderConfig) LogValue() slog.Value {
+	type plain DigitalOceanProviderConfig
+	return slog.AnyValue(plain(d.redacted()))
+}
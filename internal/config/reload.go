@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"sort"
+)
+
+// liveSettings are the settings a running process applies when it reloads
+// its configuration, with how each one is copied to the effective
+// configuration. Changing any other setting requires a restart.
+var liveSettings = map[string]func(dst, src *Config){
+	"PHILOTES_CDC_FLUSH_INTERVAL":           func(dst, src *Config) { dst.CDC.FlushInterval = src.CDC.FlushInterval },
+	"PHILOTES_CDC_BATCH_SIZE":               func(dst, src *Config) { dst.CDC.BatchSize = src.CDC.BatchSize },
+	"PHILOTES_API_RATE_LIMIT_RPS":           func(dst, src *Config) { dst.API.RateLimitRPS = src.API.RateLimitRPS },
+	"PHILOTES_API_RATE_LIMIT_BURST":         func(dst, src *Config) { dst.API.RateLimitBurst = src.API.RateLimitBurst },
+	"PHILOTES_ALERTING_EVALUATION_INTERVAL": func(dst, src *Config) { dst.Alerting.EvaluationInterval = src.Alerting.EvaluationInterval },
+}
+
+// Settings returns the effective value of every setting, keyed by its
+// environment variable, with credentials redacted.
+func (c *Config) Settings() map[string]string {
+	credentials, credentialLists := c.credentialFields(), c.credentialListFields()
+	out := make(map[string]string, len(c.settings))
+	for key, value := range c.settings {
+		_, isCredential := credentials[key]
+		_, isCredentialList := credentialLists[key]
+		if isCredential || isCredentialList {
+			value = redactSecret(value)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// Reload loads the configuration again and returns the configuration
+// effective in a running process that started with c: c with the live
+// settings of the new configuration. It also returns the settings that
+// changed but take effect only after a restart. c is not modified, and an
+// invalid new configuration is rejected as a whole.
+func (c *Config) Reload() (*Config, []string, error) {
+	next, err := Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	effective, restart := c.apply(next)
+	return effective, restart, nil
+}
+
+// Watch reloads the configuration each time a signal arrives, typically
+// SIGHUP, until signals is closed, and passes the effective configuration to
+// apply. Changed settings that take effect only after a restart are logged
+// as such; a configuration that fails to load or validate is logged and
+// ignored.
+func (c *Config) Watch(signals <-chan os.Signal, logger *slog.Logger, apply func(*Config)) {
+	current := c
+	for range signals {
+		reloaded, restart, err := current.Reload()
+		if err != nil {
+			logger.Error("failed to reload configuration", "error", err)
+			continue
+		}
+		for _, key := range restart {
+			logger.Warn("configuration change requires restart", "setting", key)
+		}
+
+		apply(reloaded)
+		current = reloaded
+		logger.Info("configuration reloaded")
+	}
+}
+
+// apply returns c with the live settings of next and the other settings
+// whose value differs in next.
+func (c *Config) apply(next *Config) (*Config, []string) {
+	effective := *c
+	effective.settings = maps.Clone(c.settings)
+	if effective.settings == nil {
+		effective.settings = make(map[string]string)
+	}
+
+	var restart []string
+	for key, value := range next.settings {
+		if current, ok := c.settings[key]; ok && current == value {
+			continue
+		}
+		if copyLive, ok := liveSettings[key]; ok {
+			copyLive(&effective, next)
+			effective.settings[key] = value
+			continue
+		}
+		restart = append(restart, key)
+	}
+	sort.Strings(restart)
+
+	return &effective, restart
+}
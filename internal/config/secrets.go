@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+// secretFields returns the credential fields that may hold a secret reference
+// (vault://path#key or env://NAME), keyed by the environment variable that
+// sets them.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"PHILOTES_API_KEY":                     &c.Client.APIKey,
+		"PHILOTES_DB_PASSWORD":                 &c.Database.Password,
+		"PHILOTES_CDC_SOURCE_PASSWORD":         &c.CDC.Source.Password,
+		"PHILOTES_STORAGE_ACCESS_KEY":          &c.Storage.AccessKey,
+		"PHILOTES_STORAGE_SECRET_KEY":          &c.Storage.SecretKey,
+		"PHILOTES_HETZNER_TOKEN":               &c.NodeScaling.Hetzner.Token,
+		"PHILOTES_SCALEWAY_ACCESS_KEY":         &c.NodeScaling.Scaleway.AccessKey,
+		"PHILOTES_SCALEWAY_SECRET_KEY":         &c.NodeScaling.Scaleway.SecretKey,
+		"PHILOTES_OVH_APPLICATION_SECRET":      &c.NodeScaling.OVH.ApplicationSecret,
+		"PHILOTES_EXOSCALE_API_KEY":            &c.NodeScaling.Exoscale.APIKey,
+		"PHILOTES_EXOSCALE_API_SECRET":         &c.NodeScaling.Exoscale.APISecret,
+		"PHILOTES_CONTABO_CLIENT_SECRET":       &c.NodeScaling.Contabo.ClientSecret,
+		"PHILOTES_CONTABO_PASSWORD":            &c.NodeScaling.Contabo.Password,
+		"PHILOTES_DIGITALOCEAN_TOKEN":          &c.NodeScaling.DigitalOcean.Token,
+		"PHILOTES_AUTH_JWT_SECRET":             &c.Auth.JWTSecret,
+		"PHILOTES_AUTH_ADMIN_PASSWORD":         &c.Auth.AdminPassword,
+		"PHILOTES_OAUTH_HETZNER_CLIENT_SECRET": &c.OAuth.Hetzner.ClientSecret,
+		"PHILOTES_OAUTH_OVH_CLIENT_SECRET":     &c.OAuth.OVH.ClientSecret,
+		"PHILOTES_OAUTH_GENERIC_CLIENT_SECRET": &c.OAuth.Generic.ClientSecret,
+		"PHILOTES_OAUTH_ENCRYPTION_KEY":        &c.OAuth.EncryptionKey,
+		"PHILOTES_TRINO_PASSWORD":              &c.Trino.Password,
+	}
+}
+
+// SecretReferences returns the secret references used by credential fields,
+// keyed by the environment variable that sets them.
+func (c *Config) SecretReferences() map[string]string {
+	refs := make(map[string]string)
+	for name, field := range c.secretFields() {
+		if vault.IsReference(*field) {
+			refs[name] = *field
+		}
+	}
+	return refs
+}
+
+// validateSecretReferences checks that every secret reference is well formed.
+func (c *Config) validateSecretReferences() error {
+	var errs []error
+	for _, name := range sortedKeys(c.SecretReferences()) {
+		if _, err := vault.ParseReference(*c.secretFields()[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResolveSecrets replaces every secret reference in a credential field with
+// the secret it references. All failures are reported together, so a missing
+// secret fails startup with the variable that referenced it.
+func (c *Config) ResolveSecrets(ctx context.Context, resolve func(context.Context, string) (string, error)) error {
+	fields := c.secretFields()
+
+	var errs []error
+	for _, name := range sortedKeys(c.SecretReferences()) {
+		value, err := resolve(ctx, *fields[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		*fields[name] = value
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+// minJWTSecretLength is the minimum length of the JWT signing secret.
+const minJWTSecretLength = 32
+
+// Validate checks the configuration for malformed environment values and
+// settings that cannot work together. Every problem is reported, each naming
+// the environment variables to fix. Secrets given as references are checked
+// for length once resolved, not here.
+func (c *Config) Validate() error {
+	var errs []error
+	for _, msg := range c.envErrors {
+		errs = append(errs, errors.New(msg))
+	}
+
+	if c.Auth.Enabled && !vault.IsReference(c.Auth.JWTSecret) && len(c.Auth.JWTSecret) < minJWTSecretLength {
+		errs = append(errs, errors.New("PHILOTES_AUTH_JWT_SECRET must be at least 32 characters when PHILOTES_AUTH_ENABLED is true"))
+	}
+
+	switch c.Secrets.Backend {
+	case SecretBackendVault:
+		if c.Vault.Address == "" {
+			errs = append(errs, errors.New("PHILOTES_VAULT_ADDRESS is required when PHILOTES_SECRET_BACKEND is vault"))
+		}
+	case SecretBackendAWS:
+		if c.Secrets.AWS.Region == "" {
+			errs = append(errs, errors.New("PHILOTES_AWS_SECRETS_REGION or AWS_REGION is required when PHILOTES_SECRET_BACKEND is aws"))
+		}
+	case SecretBackendEnv:
+	default:
+		errs = append(errs, errors.New("PHILOTES_SECRET_BACKEND must be one of: vault, aws, env"))
+	}
+	if c.Vault.Enabled && c.Secrets.Backend != SecretBackendVault {
+		errs = append(errs, errors.New("PHILOTES_VAULT_ENABLED must be false when PHILOTES_SECRET_BACKEND is not vault"))
+	}
+
+	if bp := c.CDC.Backpressure; bp.Enabled {
+		if bp.HighWatermark > 0 && bp.LowWatermark >= bp.HighWatermark {
+			errs = append(errs, errors.New("PHILOTES_BACKPRESSURE_LOW_WATERMARK must be below PHILOTES_BACKPRESSURE_HIGH_WATERMARK when PHILOTES_BACKPRESSURE_ENABLED is true"))
+		}
+		if bp.LatencyHighWatermark > 0 && bp.LatencyLowWatermark >= bp.LatencyHighWatermark {
+			errs = append(errs, errors.New("PHILOTES_BACKPRESSURE_LATENCY_LOW_WATERMARK must be below PHILOTES_BACKPRESSURE_LATENCY_HIGH_WATERMARK when it is set"))
+		}
+	}
+
+	if (c.OAuth.Hetzner.Enabled || c.OAuth.OVH.Enabled || c.OAuth.Generic.Enabled) && c.OAuth.EncryptionKey == "" {
+		errs = append(errs, errors.New("PHILOTES_OAUTH_ENCRYPTION_KEY is required when a cloud provider OAuth client ID is set"))
+	}
+
+	switch c.Storage.Provider {
+	case StorageProviderS3, StorageProviderGCS, StorageProviderAzure:
+	default:
+		errs = append(errs, errors.New("PHILOTES_STORAGE_PROVIDER must be one of: s3, gcs, azure"))
+	}
+
+	if c.Trino.Enabled {
+		if u, err := url.Parse(c.Trino.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("PHILOTES_TRINO_URL must be an http or https URL when PHILOTES_TRINO_ENABLED is true"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
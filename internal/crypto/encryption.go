@@ -2,10 +2,13 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -20,27 +23,83 @@ var (
 	ErrDecryptionFailed = errors.New("decryption failed")
 )
 
-// Encryptor provides AES-256-GCM encryption and decryption.
+// keyIDSize is the length of a key ID.
+const keyIDSize = 4
+
+// ciphertextHeader starts every ciphertext Encrypt returns, followed by the
+// ID of the key that encrypted it. Ciphertexts without it were written
+// before keys were versioned and hold only the nonce and sealed data.
+var ciphertextHeader = []byte("pk1")
+
+// encryptionKey is a key and its ID, the first bytes of its SHA-256 hash.
+type encryptionKey struct {
+	id   [keyIDSize]byte
+	aead cipher.AEAD
+}
+
+// Encryptor provides AES-256-GCM encryption and decryption. It encrypts
+// with a primary key and decrypts with the primary key or any previous key,
+// so the key can be rotated while data encrypted with the old key is still
+// stored.
 type Encryptor struct {
-	key []byte
+	keys []encryptionKey
 }
 
-// NewEncryptor creates a new Encryptor with the given key.
-// The key must be exactly 32 bytes for AES-256.
-func NewEncryptor(key []byte) (*Encryptor, error) {
-	if len(key) != 32 {
-		return nil, ErrInvalidKey
+// NewEncryptor creates a new Encryptor with the given primary key and
+// decrypt-only previous keys.
+// Every key must be exactly 32 bytes for AES-256.
+func NewEncryptor(key []byte, previousKeys ...[]byte) (*Encryptor, error) {
+	e := &Encryptor{}
+	for _, k := range append([][]byte{key}, previousKeys...) {
+		if len(k) != 32 {
+			return nil, ErrInvalidKey
+		}
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM: %w", err)
+		}
+
+		sum := sha256.Sum256(k)
+		ek := encryptionKey{aead: gcm}
+		copy(ek.id[:], sum[:keyIDSize])
+		if e.key(ek.id) == nil {
+			e.keys = append(e.keys, ek)
+		}
 	}
-	return &Encryptor{key: key}, nil
+	return e, nil
 }
 
-// NewEncryptorFromString creates a new Encryptor from a base64-encoded key.
-func NewEncryptorFromString(keyBase64 string) (*Encryptor, error) {
-	key, err := base64.StdEncoding.DecodeString(keyBase64)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base64 key: %w", err)
+// NewEncryptorFromString creates a new Encryptor from a base64-encoded
+// primary key and decrypt-only previous keys.
+func NewEncryptorFromString(keyBase64 string, previousKeysBase64 ...string) (*Encryptor, error) {
+	keys := make([][]byte, 0, 1+len(previousKeysBase64))
+	for _, s := range append([]string{keyBase64}, previousKeysBase64...) {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 key: %w", err)
+		}
+		keys = append(keys, key)
 	}
-	return NewEncryptor(key)
+	return NewEncryptor(keys[0], keys[1:]...)
+}
+
+// KeyID returns the hex-encoded ID of the primary key.
+func (e *Encryptor) KeyID() string {
+	return hex.EncodeToString(e.keys[0].id[:])
+}
+
+// key returns the key with the given ID, or nil if there is none.
+func (e *Encryptor) key(id [keyIDSize]byte) *encryptionKey {
+	for i := range e.keys {
+		if e.keys[i].id == id {
+			return &e.keys[i]
+		}
+	}
+	return nil
 }
 
 // GenerateKey generates a new random 32-byte encryption key.
@@ -61,44 +120,82 @@ func GenerateKeyBase64() (string, error) {
 	return base64.StdEncoding.EncodeToString(key), nil
 }
 
-// Encrypt encrypts plaintext using AES-256-GCM.
-// Returns the ciphertext with nonce prepended.
+// Encrypt encrypts plaintext using AES-256-GCM with the primary key.
+// Returns the ciphertext with the key ID and nonce prepended.
 func (e *Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(e.key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
-	}
+	primary := e.keys[0]
 
 	// Generate random nonce
-	nonce := make([]byte, gcm.NonceSize())
+	nonce := make([]byte, primary.aead.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// Encrypt and prepend nonce to ciphertext
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	return ciphertext, nil
+	// Encrypt and prepend header, key ID and nonce to ciphertext
+	out := make([]byte, 0, len(ciphertextHeader)+keyIDSize+len(nonce)+len(plaintext)+primary.aead.Overhead())
+	out = append(out, ciphertextHeader...)
+	out = append(out, primary.id[:]...)
+	out = append(out, nonce...)
+	return primary.aead.Seal(out, nonce, plaintext, nil), nil
 }
 
-// Decrypt decrypts ciphertext that was encrypted with Encrypt.
-// Expects the nonce to be prepended to the ciphertext.
+// Decrypt decrypts ciphertext that was encrypted with Encrypt, using the
+// key its key ID names. Ciphertexts written before keys were versioned are
+// decrypted with each key in turn.
 func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(e.key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	if id, sealed, ok := splitKeyID(ciphertext); ok {
+		if k := e.key(id); k != nil {
+			if plaintext, err := open(k.aead, sealed); err == nil {
+				return plaintext, nil
+			}
+		}
+	}
+
+	if len(ciphertext) < e.keys[0].aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
 	}
+	for _, k := range e.keys {
+		if plaintext, err := open(k.aead, ciphertext); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrDecryptionFailed
+}
+
+// NeedsReencrypt reports whether ciphertext was not encrypted with the
+// primary key.
+func (e *Encryptor) NeedsReencrypt(ciphertext []byte) bool {
+	id, _, ok := splitKeyID(ciphertext)
+	return !ok || id != e.keys[0].id
+}
 
-	gcm, err := cipher.NewGCM(block)
+// Reencrypt returns ciphertext encrypted with the primary key. Ciphertext
+// already encrypted with it is returned unchanged.
+func (e *Encryptor) Reencrypt(ciphertext []byte) ([]byte, error) {
+	if !e.NeedsReencrypt(ciphertext) {
+		return ciphertext, nil
+	}
+	plaintext, err := e.Decrypt(ciphertext)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+		return nil, err
 	}
+	return e.Encrypt(plaintext)
+}
+
+// splitKeyID splits a versioned ciphertext into its key ID and the nonce
+// and sealed data. It reports false for a ciphertext without a key ID.
+func splitKeyID(ciphertext []byte) (id [keyIDSize]byte, sealed []byte, ok bool) {
+	if !bytes.HasPrefix(ciphertext, ciphertextHeader) || len(ciphertext) < len(ciphertextHeader)+keyIDSize {
+		return id, nil, false
+	}
+	rest := ciphertext[len(ciphertextHeader):]
+	copy(id[:], rest[:keyIDSize])
+	return id, rest[keyIDSize:], true
+}
 
-	nonceSize := gcm.NonceSize()
+// open decrypts sealed data with the nonce prepended.
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
 	if len(ciphertext) < nonceSize {
 		return nil, ErrInvalidCiphertext
 	}
@@ -106,7 +203,7 @@ func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 	// Extract nonce and actual ciphertext
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
 
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
 		return nil, ErrDecryptionFailed
 	}
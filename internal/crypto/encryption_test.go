@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+func mustKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return key
+}
+
+func TestEncryptorRoundTrip(t *testing.T) {
+	keyBase64, err := GenerateKeyBase64()
+	if err != nil {
+		t.Fatalf("GenerateKeyBase64() error = %v", err)
+	}
+	e, err := NewEncryptorFromString(keyBase64)
+	if err != nil {
+		t.Fatalf("NewEncryptorFromString() error = %v", err)
+	}
+
+	ciphertext, err := e.EncryptString("refresh-token")
+	if err != nil {
+		t.Fatalf("EncryptString() error = %v", err)
+	}
+	if got, err := e.DecryptString(ciphertext); err != nil || got != "refresh-token" {
+		t.Errorf("DecryptString() = %q, %v, want the plaintext", got, err)
+	}
+
+	raw, err := e.EncryptToBytes("")
+	if err != nil {
+		t.Fatalf("EncryptToBytes() error = %v", err)
+	}
+	if got, err := e.DecryptFromBytes(raw); err != nil || got != "" {
+		t.Errorf("DecryptFromBytes() = %q, %v, want an empty plaintext", got, err)
+	}
+	if e.NeedsReencrypt(raw) {
+		t.Error("NeedsReencrypt() = true for a ciphertext of the primary key")
+	}
+
+	raw[len(raw)-1] ^= 0xff
+	if _, err := e.Decrypt(raw); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Decrypt() of a tampered ciphertext error = %v, want ErrDecryptionFailed", err)
+	}
+	if _, err := e.Decrypt([]byte("short")); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt() of a short ciphertext error = %v, want ErrInvalidCiphertext", err)
+	}
+}
+
+func TestNewEncryptorInvalidKey(t *testing.T) {
+	if _, err := NewEncryptor(make([]byte, 16)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewEncryptor() error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := NewEncryptor(mustKey(t), make([]byte, 31)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewEncryptor() with an invalid previous key error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := NewEncryptorFromString("not base64!"); err == nil {
+		t.Error("NewEncryptorFromString() error = nil, want an error")
+	}
+}
+
+func TestEncryptorRollover(t *testing.T) {
+	oldKey, newKey := mustKey(t), mustKey(t)
+
+	before, err := NewEncryptor(oldKey)
+	if err != nil {
+		t.Fatalf("NewEncryptor() error = %v", err)
+	}
+	stored, err := before.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Rotate: the new key is primary and the old key decrypts stored data
+	after, err := NewEncryptor(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewEncryptor() error = %v", err)
+	}
+	if after.KeyID() == before.KeyID() {
+		t.Fatal("KeyID() did not change with the primary key")
+	}
+	if got, err := after.Decrypt(stored); err != nil || string(got) != "secret" {
+		t.Fatalf("Decrypt() with the previous key = %q, %v", got, err)
+	}
+	if !after.NeedsReencrypt(stored) {
+		t.Fatal("NeedsReencrypt() = false for a ciphertext of a previous key")
+	}
+
+	upgraded, err := after.Reencrypt(stored)
+	if err != nil {
+		t.Fatalf("Reencrypt() error = %v", err)
+	}
+	if after.NeedsReencrypt(upgraded) {
+		t.Error("NeedsReencrypt() = true after Reencrypt()")
+	}
+	if again, err := after.Reencrypt(upgraded); err != nil || string(again) != string(upgraded) {
+		t.Errorf("Reencrypt() of a current ciphertext = %v, want it unchanged", err)
+	}
+
+	// Once every ciphertext is upgraded the old key can be dropped
+	newOnly, err := NewEncryptor(newKey)
+	if err != nil {
+		t.Fatalf("NewEncryptor() error = %v", err)
+	}
+	if got, err := newOnly.Decrypt(upgraded); err != nil || string(got) != "secret" {
+		t.Errorf("Decrypt() of an upgraded ciphertext = %q, %v", got, err)
+	}
+	if _, err := newOnly.Decrypt(stored); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Decrypt() without the previous key error = %v, want ErrDecryptionFailed", err)
+	}
+}
+
+func TestEncryptorDecryptsUnversionedCiphertext(t *testing.T) {
+	oldKey, newKey := mustKey(t), mustKey(t)
+
+	// Ciphertexts written before keys were versioned hold only the nonce
+	// and sealed data
+	block, err := aes.NewCipher(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := gcm.Seal(nonce, nonce, []byte("secret"), nil)
+
+	e, err := NewEncryptor(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewEncryptor() error = %v", err)
+	}
+	if got, err := e.Decrypt(legacy); err != nil || string(got) != "secret" {
+		t.Fatalf("Decrypt() = %q, %v", got, err)
+	}
+	if !e.NeedsReencrypt(legacy) {
+		t.Error("NeedsReencrypt() = false for an unversioned ciphertext")
+	}
+	upgraded, err := e.Reencrypt(legacy)
+	if err != nil || e.NeedsReencrypt(upgraded) {
+		t.Errorf("Reencrypt() = %v, want a ciphertext of the primary key", err)
+	}
+}
@@ -3,10 +3,18 @@ package catalog
 
 import (
 	"context"
+	"errors"
 
 	"github.com/janovincze/philotes/internal/iceberg"
 )
 
+// ErrCommitConflict is returned when a commit is rejected because another
+// writer changed the table since its metadata was read.
+var ErrCommitConflict = errors.New("commit conflict")
+
+// ErrRefNotFound is returned when a branch or tag does not exist.
+var ErrRefNotFound = errors.New("branch or tag not found")
+
 // Catalog defines the interface for Iceberg catalog operations.
 type Catalog interface {
 	// CreateNamespace creates a new namespace if it doesn't exist.
@@ -24,13 +32,74 @@ type Catalog interface {
 	// LoadTable loads table metadata.
 	LoadTable(ctx context.Context, namespace, table string) (*iceberg.TableMetadata, error)
 
-	// CommitSnapshot commits a new snapshot to the table.
-	CommitSnapshot(ctx context.Context, namespace, table string, dataFiles []iceberg.DataFile) error
+	// CommitSnapshot commits a new snapshot to the table. summary entries
+	// are recorded in the snapshot summary; nil records none.
+	CommitSnapshot(ctx context.Context, namespace, table string, dataFiles []iceberg.DataFile, summary map[string]string) error
 
 	// Close releases any resources held by the catalog.
 	Close() error
 }
 
+// BranchCatalog is a Catalog that can write to Iceberg branches and manage
+// branches and tags. Writing a rebuild to a branch keeps it invisible to
+// queries, which read main, until the branch is promoted.
+type BranchCatalog interface {
+	Catalog
+
+	// CommitSnapshotToBranch commits a new snapshot to a branch, creating the
+	// branch on its first commit.
+	CommitSnapshotToBranch(ctx context.Context, namespace, table, branch string, dataFiles []iceberg.DataFile, summary map[string]string) error
+
+	// CreateBranch creates a branch at main's current snapshot.
+	CreateBranch(ctx context.Context, namespace, table, branch string) error
+
+	// CreateTag tags main's current snapshot.
+	CreateTag(ctx context.Context, namespace, table, tag string) error
+
+	// PromoteBranch atomically points main at the branch's snapshot and
+	// removes the branch.
+	PromoteBranch(ctx context.Context, namespace, table, branch string) error
+
+	// DeleteRef removes a branch or tag.
+	DeleteRef(ctx context.Context, namespace, table, name string) error
+}
+
+// SchemaCatalog is a Catalog that can evolve table schemas, so tables keep
+// up with columns added or widened upstream.
+type SchemaCatalog interface {
+	Catalog
+
+	// UpdateSchema adds schema to the table and makes it current. The
+	// commit asserts the table's current schema is still baseSchemaID and
+	// returns ErrCommitConflict if another writer changed it first.
+	// lastColumnID is the highest column ID schema assigns.
+	UpdateSchema(ctx context.Context, namespace, table string, baseSchemaID int, schema iceberg.Schema, lastColumnID int) error
+}
+
+// MaintenanceCatalog is a Catalog that can list and rewrite a table's data
+// files and expire its snapshots, so small files can be compacted and old
+// snapshots removed.
+type MaintenanceCatalog interface {
+	Catalog
+
+	// ListNamespaces lists the namespaces of the warehouse.
+	ListNamespaces(ctx context.Context) ([]string, error)
+
+	// ListTables lists the tables of a namespace.
+	ListTables(ctx context.Context, namespace string) ([]string, error)
+
+	// ListDataFiles lists the data files of a snapshot.
+	ListDataFiles(ctx context.Context, namespace, table string, snapshotID int64) ([]iceberg.DataFile, error)
+
+	// ReplaceDataFiles commits a snapshot to main that replaces removed
+	// with added without changing the table's rows. The commit asserts main
+	// is still at baseSnapshotID and returns ErrCommitConflict otherwise.
+	ReplaceDataFiles(ctx context.Context, namespace, table string, baseSnapshotID int64, removed, added []iceberg.DataFile) error
+
+	// ExpireSnapshots removes snapshots from the table's metadata.
+	ExpireSnapshots(ctx context.Context, namespace, table string, snapshotIDs []int64) error
+}
+
 // Config holds catalog configuration.
 type Config struct {
 	// CatalogURL is the REST catalog endpoint URL.
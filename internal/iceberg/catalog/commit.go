@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// CommitRetryConfig bounds the retries of commits that conflict with a
+// concurrent writer.
+type CommitRetryConfig struct {
+	// MaxRetries is the number of times a conflicting commit is retried.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultCommitRetryConfig returns a CommitRetryConfig with sensible defaults.
+func DefaultCommitRetryConfig() CommitRetryConfig {
+	return CommitRetryConfig{
+		MaxRetries:     5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// CommitWithRetry commits data files to a table, retrying when the commit
+// conflicts with another writer (for example a second worker, or compaction
+// and snapshot expiration running alongside live writes). Each retry re-reads
+// the current table metadata and re-applies the append on top of it.
+//
+// onConflict, if non-nil, is called for every conflict so callers can count
+// them. Errors other than ErrCommitConflict are returned immediately.
+func CommitWithRetry(ctx context.Context, cat Catalog, namespace, table string, dataFiles []iceberg.DataFile, summary map[string]string, cfg CommitRetryConfig, onConflict func(attempt int, err error)) error {
+	return RetryOnConflict(ctx, cfg, onConflict, func() error {
+		return cat.CommitSnapshot(ctx, namespace, table, dataFiles, summary)
+	})
+}
+
+// CommitToBranchWithRetry is CommitWithRetry for a commit to a branch.
+func CommitToBranchWithRetry(ctx context.Context, cat BranchCatalog, namespace, table, branch string, dataFiles []iceberg.DataFile, summary map[string]string, cfg CommitRetryConfig, onConflict func(attempt int, err error)) error {
+	return RetryOnConflict(ctx, cfg, onConflict, func() error {
+		return cat.CommitSnapshotToBranch(ctx, namespace, table, branch, dataFiles, summary)
+	})
+}
+
+// RetryOnConflict runs commit until it succeeds, fails with an error other
+// than ErrCommitConflict, or runs out of retries. commit must re-read the
+// table metadata it is based on every time it runs.
+func RetryOnConflict(ctx context.Context, cfg CommitRetryConfig, onConflict func(attempt int, err error), commit func() error) error {
+	backoff := cfg.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := commit()
+		if err == nil || !errors.Is(err, ErrCommitConflict) {
+			return err
+		}
+
+		if onConflict != nil {
+			onConflict(attempt+1, err)
+		}
+		if attempt >= cfg.MaxRetries {
+			return fmt.Errorf("giving up after %d retries: %w", cfg.MaxRetries, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
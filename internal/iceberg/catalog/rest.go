@@ -184,35 +184,399 @@ func (c *RESTCatalog) LoadTable(ctx context.Context, namespace, table string) (*
 }
 
 // CommitSnapshot commits a new snapshot with data files to the table.
-func (c *RESTCatalog) CommitSnapshot(ctx context.Context, namespace, table string, dataFiles []iceberg.DataFile) error {
-	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces/%s/tables/%s", c.config.CatalogURL, c.config.Warehouse, namespace, table)
+// The commit is based on the table's current snapshot; if another writer
+// commits first, the catalog rejects it and ErrCommitConflict is returned.
+func (c *RESTCatalog) CommitSnapshot(ctx context.Context, namespace, table string, dataFiles []iceberg.DataFile, summary map[string]string) error {
+	return c.CommitSnapshotToBranch(ctx, namespace, table, iceberg.MainBranch, dataFiles, summary)
+}
+
+// CommitSnapshotToBranch commits a new snapshot with data files to a branch.
+// Like CommitSnapshot, the commit is based on the branch's current snapshot
+// and returns ErrCommitConflict if the branch moved in the meantime.
+func (c *RESTCatalog) CommitSnapshotToBranch(ctx context.Context, namespace, table, branch string, dataFiles []iceberg.DataFile, summary map[string]string) error {
+	// Read the current metadata so the commit only applies on top of it
+	meta, err := c.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("refresh table metadata: %w", err)
+	}
+
+	appendFiles := &appendFilesUpdate{
+		DataFiles: convertDataFilesToREST(dataFiles),
+		Summary:   summary,
+	}
+	if branch != iceberg.MainBranch {
+		appendFiles.Branch = branch
+	}
+
+	body := commitTableRequest{
+		Requirements: []tableRequirement{
+			{Type: "assert-ref-snapshot-id", Ref: branch, SnapshotID: refSnapshotID(meta, branch)},
+		},
+		Updates: []tableUpdate{
+			{Action: "append", AppendFiles: appendFiles},
+		},
+	}
+
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Debug("snapshot committed", "namespace", namespace, "table", table, "branch", branch, "files", len(dataFiles))
+	return nil
+}
+
+// UpdateSchema adds schema to the table and makes it current, provided the
+// current schema is still baseSchemaID.
+func (c *RESTCatalog) UpdateSchema(ctx context.Context, namespace, table string, baseSchemaID int, schema iceberg.Schema, lastColumnID int) error {
+	evolved := convertSchemaToREST(schema)
+	lastAdded := -1 // the schema added by this commit
+
+	body := commitTableRequest{
+		Requirements: []tableRequirement{
+			{Type: "assert-current-schema-id", CurrentSchemaID: &baseSchemaID},
+		},
+		Updates: []tableUpdate{
+			{Action: "add-schema", Schema: &evolved, LastColumnID: &lastColumnID},
+			{Action: "set-current-schema", SchemaID: &lastAdded},
+		},
+	}
+
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Info("schema updated",
+		"namespace", namespace,
+		"table", table,
+		"schema_id", schema.SchemaID,
+		"columns", len(schema.Fields),
+	)
+	return nil
+}
+
+// ListNamespaces lists the namespaces of the warehouse. Nested namespaces
+// are joined with ".".
+func (c *RESTCatalog) ListNamespaces(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces", c.config.CatalogURL, c.config.Warehouse)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseError(resp)
+	}
+
+	var result listNamespacesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode namespaces response: %w", err)
+	}
+
+	namespaces := make([]string, len(result.Namespaces))
+	for i, ns := range result.Namespaces {
+		namespaces[i] = strings.Join(ns, ".")
+	}
+	return namespaces, nil
+}
+
+// ListTables lists the tables of a namespace.
+func (c *RESTCatalog) ListTables(ctx context.Context, namespace string) ([]string, error) {
+	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces/%s/tables", c.config.CatalogURL, c.config.Warehouse, namespace)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list tables request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseError(resp)
+	}
+
+	var result listTablesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode tables response: %w", err)
+	}
+
+	tables := make([]string, len(result.Identifiers))
+	for i, id := range result.Identifiers {
+		tables[i] = id.Name
+	}
+	return tables, nil
+}
+
+// ListDataFiles lists the data files of a snapshot by planning a scan of it.
+func (c *RESTCatalog) ListDataFiles(ctx context.Context, namespace, table string, snapshotID int64) ([]iceberg.DataFile, error) {
+	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces/%s/tables/%s/plan", c.config.CatalogURL, c.config.Warehouse, namespace, table)
+
+	resp, err := c.doRequest(ctx, http.MethodPost, url, planTableScanRequest{SnapshotID: snapshotID})
+	if err != nil {
+		return nil, fmt.Errorf("plan table scan request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.parseError(resp)
+	}
+
+	var result planTableScanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode scan plan response: %w", err)
+	}
+	if result.Status != "" && result.Status != "completed" {
+		return nil, fmt.Errorf("scan plan not completed: status %q", result.Status)
+	}
+
+	files := make([]iceberg.DataFile, len(result.FileScanTasks))
+	for i, task := range result.FileScanTasks {
+		f := task.DataFile
+		files[i] = iceberg.DataFile{
+			FilePath:        f.FilePath,
+			FileFormat:      strings.ToLower(f.FileFormat),
+			RecordCount:     f.RecordCount,
+			FileSizeInBytes: f.FileSizeInBytes,
+			PartitionData:   f.Partition,
+		}
+	}
+	return files, nil
+}
 
-	// Build the append operation
-	updates := []tableUpdate{
-		{
-			Action: "append",
-			AppendFiles: &appendFilesUpdate{
-				DataFiles: convertDataFilesToREST(dataFiles),
-			},
+// ReplaceDataFiles commits a snapshot to main replacing removed with added,
+// provided main is still at baseSnapshotID.
+func (c *RESTCatalog) ReplaceDataFiles(ctx context.Context, namespace, table string, baseSnapshotID int64, removed, added []iceberg.DataFile) error {
+	body := commitTableRequest{
+		Requirements: []tableRequirement{
+			{Type: "assert-ref-snapshot-id", Ref: iceberg.MainBranch, SnapshotID: &baseSnapshotID},
 		},
+		Updates: []tableUpdate{
+			{Action: "replace", ReplaceFiles: &replaceFilesUpdate{
+				DeletedFiles: convertDataFilesToREST(removed),
+				DataFiles:    convertDataFilesToREST(added),
+			}},
+		},
+	}
+
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Debug("data files replaced", "namespace", namespace, "table", table, "removed", len(removed), "added", len(added))
+	return nil
+}
+
+// ExpireSnapshots removes snapshots from the table's metadata.
+func (c *RESTCatalog) ExpireSnapshots(ctx context.Context, namespace, table string, snapshotIDs []int64) error {
+	if len(snapshotIDs) == 0 {
+		return nil
 	}
 
 	body := commitTableRequest{
 		Requirements: []tableRequirement{},
-		Updates:      updates,
+		Updates: []tableUpdate{
+			{Action: "remove-snapshots", SnapshotIDs: snapshotIDs},
+		},
+	}
+
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Debug("snapshots expired", "namespace", namespace, "table", table, "snapshots", len(snapshotIDs))
+	return nil
+}
+
+// CreateBranch creates a branch at main's current snapshot.
+func (c *RESTCatalog) CreateBranch(ctx context.Context, namespace, table, branch string) error {
+	return c.createRef(ctx, namespace, table, branch, iceberg.RefTypeBranch)
+}
+
+// CreateTag tags main's current snapshot.
+func (c *RESTCatalog) CreateTag(ctx context.Context, namespace, table, tag string) error {
+	return c.createRef(ctx, namespace, table, tag, iceberg.RefTypeTag)
+}
+
+// PromoteBranch points main at the branch's snapshot and removes the branch
+// in a single commit, so queries switch from the old data to the rebuilt
+// data at once. The commit asserts that neither main nor the branch moved
+// since they were read; if either did, ErrCommitConflict is returned and
+// nothing changes.
+func (c *RESTCatalog) PromoteBranch(ctx context.Context, namespace, table, branch string) error {
+	if branch == iceberg.MainBranch {
+		return fmt.Errorf("cannot promote the %s branch onto itself", iceberg.MainBranch)
+	}
+
+	meta, err := c.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("refresh table metadata: %w", err)
 	}
 
+	ref, ok := meta.Refs[branch]
+	if !ok || ref.Type != iceberg.RefTypeBranch {
+		return fmt.Errorf("%w: branch %q", ErrRefNotFound, branch)
+	}
+	snapshotID := ref.SnapshotID
+
+	body := commitTableRequest{
+		Requirements: []tableRequirement{
+			{Type: "assert-ref-snapshot-id", Ref: iceberg.MainBranch, SnapshotID: refSnapshotID(meta, iceberg.MainBranch)},
+			{Type: "assert-ref-snapshot-id", Ref: branch, SnapshotID: &snapshotID},
+		},
+		Updates: []tableUpdate{
+			{Action: "set-snapshot-ref", RefName: iceberg.MainBranch, Type: iceberg.RefTypeBranch, SnapshotID: &snapshotID},
+			{Action: "remove-snapshot-ref", RefName: branch},
+		},
+	}
+
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Info("branch promoted",
+		"namespace", namespace,
+		"table", table,
+		"branch", branch,
+		"snapshot_id", snapshotID,
+	)
+	return nil
+}
+
+// DeleteRef removes a branch or tag. The main branch cannot be removed.
+func (c *RESTCatalog) DeleteRef(ctx context.Context, namespace, table, name string) error {
+	if name == iceberg.MainBranch {
+		return fmt.Errorf("cannot delete the %s branch", iceberg.MainBranch)
+	}
+
+	meta, err := c.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("refresh table metadata: %w", err)
+	}
+	if _, ok := meta.Refs[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrRefNotFound, name)
+	}
+
+	body := commitTableRequest{
+		Requirements: []tableRequirement{},
+		Updates:      []tableUpdate{{Action: "remove-snapshot-ref", RefName: name}},
+	}
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Info("ref deleted", "namespace", namespace, "table", table, "ref", name)
+	return nil
+}
+
+// createRef creates a branch or tag at main's current snapshot.
+func (c *RESTCatalog) createRef(ctx context.Context, namespace, table, name, refType string) error {
+	meta, err := c.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("refresh table metadata: %w", err)
+	}
+
+	base := refSnapshotID(meta, iceberg.MainBranch)
+	if base == nil {
+		return fmt.Errorf("table %s.%s has no snapshot to %s", namespace, table, refType)
+	}
+
+	body := commitTableRequest{
+		Requirements: []tableRequirement{
+			{Type: "assert-ref-snapshot-id", Ref: iceberg.MainBranch, SnapshotID: base},
+			{Type: "assert-ref-snapshot-id", Ref: name},
+		},
+		Updates: []tableUpdate{
+			{Action: "set-snapshot-ref", RefName: name, Type: refType, SnapshotID: base},
+		},
+	}
+	if err := c.commitTable(ctx, namespace, table, body); err != nil {
+		return err
+	}
+
+	c.logger.Info("ref created", "namespace", namespace, "table", table, "ref", name, "type", refType, "snapshot_id", *base)
+	return nil
+}
+
+// commitTable posts a commit to the table, mapping rejected requirements to
+// ErrCommitConflict.
+func (c *RESTCatalog) commitTable(ctx context.Context, namespace, table string, body commitTableRequest) error {
+	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces/%s/tables/%s", c.config.CatalogURL, c.config.Warehouse, namespace, table)
+
 	resp, err := c.doRequest(ctx, http.MethodPost, url, body)
 	if err != nil {
-		return fmt.Errorf("commit snapshot request: %w", err)
+		return fmt.Errorf("commit table request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %w", ErrCommitConflict, c.parseError(resp))
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		return c.parseError(resp)
 	}
+	return nil
+}
 
-	c.logger.Debug("snapshot committed", "namespace", namespace, "table", table, "files", len(dataFiles))
+// refSnapshotID returns the snapshot a ref points at, or nil if the ref does
+// not exist. Catalogs that omit refs report main as the current snapshot.
+func refSnapshotID(meta *iceberg.TableMetadata, name string) *int64 {
+	if ref, ok := meta.Refs[name]; ok {
+		id := ref.SnapshotID
+		return &id
+	}
+	if name == iceberg.MainBranch && meta.CurrentSnapshotID > 0 {
+		id := meta.CurrentSnapshotID
+		return &id
+	}
+	return nil
+}
+
+// RenameTable renames a table, possibly into another namespace.
+func (c *RESTCatalog) RenameTable(ctx context.Context, fromNamespace, fromTable, toNamespace, toTable string) error {
+	url := fmt.Sprintf("%s/catalog/v1/%s/tables/rename", c.config.CatalogURL, c.config.Warehouse)
+
+	body := renameTableRequest{
+		Source:      tableIdentifier{Namespace: []string{fromNamespace}, Name: fromTable},
+		Destination: tableIdentifier{Namespace: []string{toNamespace}, Name: toTable},
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("rename table request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return c.parseError(resp)
+	}
+
+	c.logger.Info("table renamed",
+		"from", fromNamespace+"."+fromTable,
+		"to", toNamespace+"."+toTable,
+	)
+	return nil
+}
+
+// DropTable drops a table. Dropping a table that does not exist is not an error.
+func (c *RESTCatalog) DropTable(ctx context.Context, namespace, table string) error {
+	url := fmt.Sprintf("%s/catalog/v1/%s/namespaces/%s/tables/%s", c.config.CatalogURL, c.config.Warehouse, namespace, table)
+
+	resp, err := c.doRequest(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("drop table request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return c.parseError(resp)
+	}
+
+	c.logger.Info("table dropped", "namespace", namespace, "table", table)
 	return nil
 }
 
@@ -314,25 +678,102 @@ type loadTableResponse struct {
 		LastPartitionID   int                 `json:"last-partition-id"`
 		Properties        map[string]string   `json:"properties"`
 		CurrentSnapshotID int64               `json:"current-snapshot-id"`
+		Snapshots         []restSnapshot      `json:"snapshots"`
+		Refs              map[string]restRef  `json:"refs"`
 	} `json:"metadata"`
 }
 
+type restRef struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+type restSnapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID int64             `json:"parent-snapshot-id,omitempty"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary,omitempty"`
+}
+
 type commitTableRequest struct {
 	Requirements []tableRequirement `json:"requirements"`
 	Updates      []tableUpdate      `json:"updates"`
 }
 
+type tableIdentifier struct {
+	Namespace []string `json:"namespace"`
+	Name      string   `json:"name"`
+}
+
+type renameTableRequest struct {
+	Source      tableIdentifier `json:"source"`
+	Destination tableIdentifier `json:"destination"`
+}
+
 type tableRequirement struct {
-	Type string `json:"type"`
+	Type            string `json:"type"`
+	Ref             string `json:"ref,omitempty"`
+	SnapshotID      *int64 `json:"snapshot-id"` // null asserts the ref does not exist yet
+	CurrentSchemaID *int   `json:"current-schema-id,omitempty"`
+}
+
+// MarshalJSON sends snapshot-id, which is meaningful when null, only for
+// ref requirements.
+func (r tableRequirement) MarshalJSON() ([]byte, error) {
+	type plain tableRequirement
+	if r.Type == "assert-ref-snapshot-id" {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		Type            string `json:"type"`
+		CurrentSchemaID *int   `json:"current-schema-id,omitempty"`
+	}{r.Type, r.CurrentSchemaID})
 }
 
 type tableUpdate struct {
 	Action      string             `json:"action"`
 	AppendFiles *appendFilesUpdate `json:"append,omitempty"`
+	RefName     string             `json:"ref-name,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	SnapshotID  *int64             `json:"snapshot-id,omitempty"`
+
+	Schema       *restSchema `json:"schema,omitempty"`
+	LastColumnID *int        `json:"last-column-id,omitempty"`
+	SchemaID     *int        `json:"schema-id,omitempty"`
+
+	ReplaceFiles *replaceFilesUpdate `json:"replace,omitempty"`
+	SnapshotIDs  []int64             `json:"snapshot-ids,omitempty"`
+}
+
+type replaceFilesUpdate struct {
+	DeletedFiles []restDataFile `json:"deleted-files"`
+	DataFiles    []restDataFile `json:"data-files"`
+}
+
+type listNamespacesResponse struct {
+	Namespaces [][]string `json:"namespaces"`
+}
+
+type listTablesResponse struct {
+	Identifiers []tableIdentifier `json:"identifiers"`
+}
+
+type planTableScanRequest struct {
+	SnapshotID int64 `json:"snapshot-id"`
+}
+
+type planTableScanResponse struct {
+	Status        string `json:"status"`
+	FileScanTasks []struct {
+		DataFile restDataFile `json:"data-file"`
+	} `json:"file-scan-tasks"`
 }
 
 type appendFilesUpdate struct {
-	DataFiles []restDataFile `json:"data-files"`
+	DataFiles []restDataFile    `json:"data-files"`
+	Branch    string            `json:"branch,omitempty"`
+	Summary   map[string]string `json:"summary,omitempty"`
 }
 
 type restDataFile struct {
@@ -429,6 +870,25 @@ func convertRESTToMetadata(resp loadTableResponse) *iceberg.TableMetadata {
 		}
 	}
 
+	snapshots := make([]iceberg.Snapshot, len(resp.Metadata.Snapshots))
+	for i, snap := range resp.Metadata.Snapshots {
+		snapshots[i] = iceberg.Snapshot{
+			SnapshotID:       snap.SnapshotID,
+			ParentSnapshotID: snap.ParentSnapshotID,
+			TimestampMs:      snap.TimestampMs,
+			ManifestList:     snap.ManifestList,
+			Summary:          snap.Summary,
+		}
+	}
+
+	var refs map[string]iceberg.SnapshotRef
+	if len(resp.Metadata.Refs) > 0 {
+		refs = make(map[string]iceberg.SnapshotRef, len(resp.Metadata.Refs))
+		for name, ref := range resp.Metadata.Refs {
+			refs[name] = iceberg.SnapshotRef{SnapshotID: ref.SnapshotID, Type: ref.Type}
+		}
+	}
+
 	return &iceberg.TableMetadata{
 		FormatVersion:     resp.Metadata.FormatVersion,
 		TableUUID:         resp.Metadata.TableUUID,
@@ -442,8 +902,15 @@ func convertRESTToMetadata(resp loadTableResponse) *iceberg.TableMetadata {
 		LastPartitionID:   resp.Metadata.LastPartitionID,
 		Properties:        resp.Metadata.Properties,
 		CurrentSnapshotID: resp.Metadata.CurrentSnapshotID,
+		Snapshots:         snapshots,
+		Refs:              refs,
 	}
 }
 
-// Ensure RESTCatalog implements Catalog interface.
-var _ Catalog = (*RESTCatalog)(nil)
+// Ensure RESTCatalog implements the Catalog and BranchCatalog interfaces.
+var (
+	_ Catalog            = (*RESTCatalog)(nil)
+	_ BranchCatalog      = (*RESTCatalog)(nil)
+	_ SchemaCatalog      = (*RESTCatalog)(nil)
+	_ MaintenanceCatalog = (*RESTCatalog)(nil)
+)
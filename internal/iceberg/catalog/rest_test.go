@@ -3,9 +3,12 @@ package catalog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/janovincze/philotes/internal/iceberg"
 )
@@ -154,6 +157,11 @@ func TestLoadTable(t *testing.T) {
 				},
 			},
 		}
+		response.Metadata.CurrentSnapshotID = 2
+		response.Metadata.Snapshots = []restSnapshot{
+			{SnapshotID: 1, TimestampMs: 1000, Summary: map[string]string{"operation": "append"}},
+			{SnapshotID: 2, ParentSnapshotID: 1, TimestampMs: 2000, Summary: map[string]string{"total-records": "10"}},
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // test helper, error handling not needed
@@ -188,6 +196,14 @@ func TestLoadTable(t *testing.T) {
 	if len(meta.Schemas[0].Fields) != 2 {
 		t.Errorf("Expected 2 fields, got %d", len(meta.Schemas[0].Fields))
 	}
+
+	if len(meta.Snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(meta.Snapshots))
+	}
+
+	if meta.Snapshots[1].ParentSnapshotID != 1 || meta.Snapshots[1].Summary["total-records"] != "10" {
+		t.Errorf("Unexpected snapshot: %+v", meta.Snapshots[1])
+	}
 }
 
 func TestConvertSchemaToREST(t *testing.T) {
@@ -244,3 +260,352 @@ func TestConvertPartitionSpecToREST(t *testing.T) {
 		t.Errorf("Expected transform 'day', got %q", rest.Fields[0].Transform)
 	}
 }
+
+func TestCommitWithRetry_RefreshesOnConflict(t *testing.T) {
+	// A concurrent writer commits snapshot 2 after our first metadata read,
+	// so the first commit based on snapshot 1 conflicts.
+	var (
+		currentSnapshot int64 = 1
+		loads           int
+		commits         []int64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			loads++
+			var response loadTableResponse
+			response.Metadata.CurrentSnapshotID = currentSnapshot
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // test helper, error handling not needed
+			if loads == 1 {
+				currentSnapshot = 2
+			}
+		case http.MethodPost:
+			var req commitTableRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode commit request: %v", err)
+			}
+			if len(req.Requirements) != 1 || req.Requirements[0].SnapshotID == nil {
+				t.Errorf("expected a snapshot requirement, got %+v", req.Requirements)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if len(req.Updates) != 1 || req.Updates[0].AppendFiles == nil || req.Updates[0].AppendFiles.Summary["writer.batch"] != "7" {
+				t.Errorf("expected an append carrying the snapshot summary, got %+v", req.Updates)
+			}
+			base := *req.Requirements[0].SnapshotID
+			commits = append(commits, base)
+			if base != currentSnapshot {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error":{"type":"CommitFailedException"}}`))
+				return
+			}
+			currentSnapshot++
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := NewRESTCatalog(Config{CatalogURL: server.URL, Warehouse: "test"}, nil)
+	dataFiles := []iceberg.DataFile{{FilePath: "s3://bucket/data/1.parquet", FileFormat: "parquet", RecordCount: 10}}
+
+	cfg := CommitRetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond}
+	conflicts := 0
+	err := CommitWithRetry(context.Background(), client, "myns", "mytable", dataFiles, map[string]string{"writer.batch": "7"}, cfg, func(int, error) {
+		conflicts++
+	})
+	if err != nil {
+		t.Fatalf("CommitWithRetry() error = %v", err)
+	}
+
+	if conflicts != 1 {
+		t.Errorf("Expected 1 conflict, got %d", conflicts)
+	}
+	if len(commits) != 2 || commits[0] != 1 || commits[1] != 2 {
+		t.Errorf("Expected commits based on snapshots [1 2], got %v", commits)
+	}
+	if currentSnapshot != 3 {
+		t.Errorf("Expected the retried commit to produce snapshot 3, got %d", currentSnapshot)
+	}
+}
+
+func TestCommitWithRetry_GivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"metadata":{"current-snapshot-id":1}}`))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	client := NewRESTCatalog(Config{CatalogURL: server.URL, Warehouse: "test"}, nil)
+
+	conflicts := 0
+	cfg := CommitRetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}
+	err := CommitWithRetry(context.Background(), client, "myns", "mytable", nil, nil, cfg, func(int, error) {
+		conflicts++
+	})
+	if !errors.Is(err, ErrCommitConflict) {
+		t.Fatalf("Expected ErrCommitConflict, got %v", err)
+	}
+	if conflicts != 3 {
+		t.Errorf("Expected 3 conflicts (initial attempt plus 2 retries), got %d", conflicts)
+	}
+}
+
+// branchingCatalog is an in-memory REST catalog serving one table with refs.
+// It enforces ref requirements and applies append and ref updates.
+type branchingCatalog struct {
+	t            *testing.T
+	refs         map[string]restRef
+	nextSnapshot int64
+	commits      int
+
+	// beforeCommit, if set, runs before each commit is checked, to simulate
+	// a concurrent writer.
+	beforeCommit func()
+}
+
+func newBranchingCatalog(t *testing.T) (*branchingCatalog, *RESTCatalog) {
+	t.Helper()
+	b := &branchingCatalog{
+		t:            t,
+		refs:         map[string]restRef{iceberg.MainBranch: {SnapshotID: 1, Type: iceberg.RefTypeBranch}},
+		nextSnapshot: 2,
+	}
+	server := httptest.NewServer(b)
+	t.Cleanup(server.Close)
+	return b, NewRESTCatalog(Config{CatalogURL: server.URL, Warehouse: "test"}, nil)
+}
+
+func (b *branchingCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		var response loadTableResponse
+		response.Metadata.CurrentSnapshotID = b.refs[iceberg.MainBranch].SnapshotID
+		response.Metadata.Refs = b.refs
+		_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // test helper, error handling not needed
+		return
+	}
+
+	var req commitTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.t.Errorf("decode commit request: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if b.beforeCommit != nil {
+		b.beforeCommit()
+	}
+
+	for _, requirement := range req.Requirements {
+		ref, exists := b.refs[requirement.Ref]
+		if requirement.SnapshotID == nil && exists || requirement.SnapshotID != nil && (!exists || ref.SnapshotID != *requirement.SnapshotID) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	}
+
+	for _, update := range req.Updates {
+		switch update.Action {
+		case "append":
+			branch := update.AppendFiles.Branch
+			if branch == "" {
+				branch = iceberg.MainBranch
+			}
+			b.refs[branch] = restRef{SnapshotID: b.nextSnapshot, Type: iceberg.RefTypeBranch}
+			b.nextSnapshot++
+		case "set-snapshot-ref":
+			b.refs[update.RefName] = restRef{SnapshotID: *update.SnapshotID, Type: update.Type}
+		case "remove-snapshot-ref":
+			delete(b.refs, update.RefName)
+		default:
+			b.t.Errorf("unexpected update %q", update.Action)
+		}
+	}
+	b.commits++
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestBranchWritesInvisibleOnMainUntilPromoted(t *testing.T) {
+	b, client := newBranchingCatalog(t)
+	ctx := context.Background()
+	dataFiles := []iceberg.DataFile{{FilePath: "s3://bucket/data/1.parquet", FileFormat: "parquet", RecordCount: 10}}
+
+	if err := client.CreateBranch(ctx, "myns", "mytable", "rebuild"); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	for range 2 {
+		if err := client.CommitSnapshotToBranch(ctx, "myns", "mytable", "rebuild", dataFiles, nil); err != nil {
+			t.Fatalf("CommitSnapshotToBranch() error = %v", err)
+		}
+	}
+
+	meta, err := client.LoadTable(ctx, "myns", "mytable")
+	if err != nil {
+		t.Fatalf("LoadTable() error = %v", err)
+	}
+	if meta.CurrentSnapshotID != 1 {
+		t.Errorf("main snapshot = %d before promotion, want 1", meta.CurrentSnapshotID)
+	}
+	if got := meta.Refs["rebuild"].SnapshotID; got != 3 {
+		t.Errorf("rebuild snapshot = %d, want 3", got)
+	}
+
+	commits := b.commits
+	if err := client.PromoteBranch(ctx, "myns", "mytable", "rebuild"); err != nil {
+		t.Fatalf("PromoteBranch() error = %v", err)
+	}
+	if b.commits != commits+1 {
+		t.Errorf("promotion took %d commits, want 1", b.commits-commits)
+	}
+
+	meta, err = client.LoadTable(ctx, "myns", "mytable")
+	if err != nil {
+		t.Fatalf("LoadTable() error = %v", err)
+	}
+	if meta.CurrentSnapshotID != 3 {
+		t.Errorf("main snapshot = %d after promotion, want 3", meta.CurrentSnapshotID)
+	}
+	if _, ok := meta.Refs["rebuild"]; ok {
+		t.Error("expected the promoted branch to be removed")
+	}
+}
+
+func TestPromoteBranch_ConflictLeavesMainUnchanged(t *testing.T) {
+	b, client := newBranchingCatalog(t)
+	ctx := context.Background()
+
+	if err := client.CommitSnapshotToBranch(ctx, "myns", "mytable", "rebuild", nil, nil); err != nil {
+		t.Fatalf("CommitSnapshotToBranch() error = %v", err)
+	}
+
+	// Live writes move main between reading the refs and promoting
+	b.beforeCommit = func() {
+		b.refs[iceberg.MainBranch] = restRef{SnapshotID: 10, Type: iceberg.RefTypeBranch}
+		b.beforeCommit = nil
+	}
+	err := client.PromoteBranch(ctx, "myns", "mytable", "rebuild")
+	if !errors.Is(err, ErrCommitConflict) {
+		t.Fatalf("PromoteBranch() error = %v, want ErrCommitConflict", err)
+	}
+	if got := b.refs[iceberg.MainBranch].SnapshotID; got != 10 {
+		t.Errorf("main snapshot = %d, want the concurrent writer's 10", got)
+	}
+	if _, ok := b.refs["rebuild"]; !ok {
+		t.Error("expected the branch to survive a failed promotion")
+	}
+}
+
+func TestPromoteBranch_UnknownBranch(t *testing.T) {
+	_, client := newBranchingCatalog(t)
+	err := client.PromoteBranch(context.Background(), "myns", "mytable", "missing")
+	if !errors.Is(err, ErrRefNotFound) {
+		t.Fatalf("PromoteBranch() error = %v, want ErrRefNotFound", err)
+	}
+}
+
+func TestUpdateSchema(t *testing.T) {
+	currentSchemaID := 0
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode commit request: %v", err)
+		}
+		requirement := body["requirements"].([]any)[0].(map[string]any)
+		if requirement["current-schema-id"] != float64(currentSchemaID) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		currentSchemaID++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRESTCatalog(Config{CatalogURL: server.URL, Warehouse: "test"}, nil)
+	schema := iceberg.Schema{SchemaID: 1, Fields: []iceberg.Field{
+		{ID: 1, Name: "id", Type: iceberg.TypeLong},
+		{ID: 2, Name: "email", Type: iceberg.TypeString},
+	}}
+
+	if err := client.UpdateSchema(context.Background(), "myns", "mytable", 0, schema, 2); err != nil {
+		t.Fatalf("UpdateSchema() error = %v", err)
+	}
+
+	requirement := body["requirements"].([]any)[0].(map[string]any)
+	if requirement["type"] != "assert-current-schema-id" || requirement["current-schema-id"] != float64(0) {
+		t.Errorf("requirement = %v, want assert-current-schema-id 0", requirement)
+	}
+	if _, ok := requirement["snapshot-id"]; ok {
+		t.Errorf("schema requirement must not carry a snapshot-id: %v", requirement)
+	}
+	updates := body["updates"].([]any)
+	addSchema := updates[0].(map[string]any)
+	if addSchema["action"] != "add-schema" || addSchema["last-column-id"] != float64(2) {
+		t.Errorf("first update = %v, want add-schema with last-column-id 2", addSchema)
+	}
+	setCurrent := updates[1].(map[string]any)
+	if setCurrent["action"] != "set-current-schema" || setCurrent["schema-id"] != float64(-1) {
+		t.Errorf("second update = %v, want set-current-schema -1", setCurrent)
+	}
+
+	// A schema change based on a stale schema conflicts
+	err := client.UpdateSchema(context.Background(), "myns", "mytable", 0, schema, 2)
+	if !errors.Is(err, ErrCommitConflict) {
+		t.Errorf("UpdateSchema() on a stale schema error = %v, want ErrCommitConflict", err)
+	}
+}
+
+func TestListAndReplaceDataFiles(t *testing.T) {
+	mainSnapshot := int64(7)
+	var commit map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/plan") {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"completed","file-scan-tasks":[
+				{"data-file":{"file-path":"s3://b/t/data/a.parquet","file-format":"PARQUET","record-count":3,"file-size-in-bytes":100}},
+				{"data-file":{"file-path":"s3://b/t/data/b.parquet","file-format":"PARQUET","record-count":2,"file-size-in-bytes":80}}]}`))
+			return
+		}
+		commit = nil
+		if err := json.NewDecoder(r.Body).Decode(&commit); err != nil {
+			t.Errorf("decode commit request: %v", err)
+		}
+		requirement := commit["requirements"].([]any)[0].(map[string]any)
+		if requirement["snapshot-id"] != float64(mainSnapshot) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		mainSnapshot++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRESTCatalog(Config{CatalogURL: server.URL, Warehouse: "test"}, nil)
+	ctx := context.Background()
+
+	files, err := client.ListDataFiles(ctx, "myns", "mytable", 7)
+	if err != nil {
+		t.Fatalf("ListDataFiles() error = %v", err)
+	}
+	if len(files) != 2 || files[0].FileFormat != "parquet" || files[1].FileSizeInBytes != 80 {
+		t.Fatalf("ListDataFiles() = %+v", files)
+	}
+
+	added := []iceberg.DataFile{{FilePath: "s3://b/t/data/c.parquet", FileFormat: "parquet", RecordCount: 5, FileSizeInBytes: 150}}
+	if err := client.ReplaceDataFiles(ctx, "myns", "mytable", 7, files, added); err != nil {
+		t.Fatalf("ReplaceDataFiles() error = %v", err)
+	}
+	update := commit["updates"].([]any)[0].(map[string]any)
+	replace := update["replace"].(map[string]any)
+	if update["action"] != "replace" || len(replace["deleted-files"].([]any)) != 2 || len(replace["data-files"].([]any)) != 1 {
+		t.Errorf("update = %v, want a replace of 2 files with 1", update)
+	}
+
+	// Replacing files on top of a snapshot main has moved past conflicts
+	err = client.ReplaceDataFiles(ctx, "myns", "mytable", 7, files, added)
+	if !errors.Is(err, ErrCommitConflict) {
+		t.Errorf("ReplaceDataFiles() on a stale snapshot error = %v, want ErrCommitConflict", err)
+	}
+}
@@ -0,0 +1,196 @@
+package iceberg
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Snapshot summary keys written by Iceberg writers.
+const (
+	SummaryTotalDataFiles   = "total-data-files"
+	SummaryTotalDeleteFiles = "total-delete-files"
+	SummaryTotalRecords     = "total-records"
+	SummaryTotalFilesSize   = "total-files-size"
+	SummaryAddedDataFiles   = "added-data-files"
+	SummaryAddedRecords     = "added-records"
+	SummaryAddedFilesSize   = "added-files-size"
+	SummaryDeletedDataFiles = "deleted-data-files"
+	SummaryDeletedRecords   = "deleted-records"
+	SummaryRemovedFilesSize = "removed-files-size"
+
+	// summaryPartitionPrefix prefixes per-partition summaries ("partitions.<path>").
+	summaryPartitionPrefix = "partitions."
+)
+
+// Snapshot summary keys the writer records on snapshots that hold only rows
+// read by a table snapshot, so they can be told apart from streamed changes.
+const (
+	// SummarySnapshotLSN is the lowest LSN of the snapshot rows.
+	SummarySnapshotLSN = "philotes.snapshot-lsn"
+
+	// SummarySnapshotRecords is the number of snapshot rows.
+	SummarySnapshotRecords = "philotes.snapshot-records"
+
+	// SummarySnapshotFile is the data file holding the snapshot rows.
+	SummarySnapshotFile = "philotes.snapshot-file"
+)
+
+// CurrentSnapshot returns the table's current snapshot, or nil if the table has none.
+func (m *TableMetadata) CurrentSnapshot() *Snapshot {
+	for i := range m.Snapshots {
+		if m.Snapshots[i].SnapshotID == m.CurrentSnapshotID {
+			return &m.Snapshots[i]
+		}
+	}
+	return nil
+}
+
+// Ancestry returns the current snapshot and its ancestors, newest first.
+func (m *TableMetadata) Ancestry() []Snapshot {
+	return m.ancestryOf(m.CurrentSnapshotID)
+}
+
+// BranchAncestry returns the snapshot a branch points at and its ancestors,
+// newest first. It is empty if the branch does not exist yet.
+func (m *TableMetadata) BranchAncestry(branch string) []Snapshot {
+	if ref, ok := m.Refs[branch]; ok {
+		return m.ancestryOf(ref.SnapshotID)
+	}
+	if branch == MainBranch {
+		return m.Ancestry()
+	}
+	return nil
+}
+
+// ancestryOf returns snapshot id and its ancestors, newest first.
+func (m *TableMetadata) ancestryOf(id int64) []Snapshot {
+	byID := make(map[int64]Snapshot, len(m.Snapshots))
+	for _, snap := range m.Snapshots {
+		byID[snap.SnapshotID] = snap
+	}
+
+	var ancestry []Snapshot
+	for id != 0 {
+		snap, ok := byID[id]
+		if !ok {
+			break
+		}
+		ancestry = append(ancestry, snap)
+		delete(byID, id)
+		id = snap.ParentSnapshotID
+	}
+	return ancestry
+}
+
+// FilesSummary summarizes the data files of a table's current snapshot.
+type FilesSummary struct {
+	// SnapshotID is the snapshot the summary was computed from.
+	SnapshotID int64 `json:"snapshot_id"`
+
+	// DataFiles is the number of live data files.
+	DataFiles int64 `json:"data_files"`
+
+	// DeleteFiles is the number of live delete files.
+	DeleteFiles int64 `json:"delete_files"`
+
+	// Records is the total number of records.
+	Records int64 `json:"records"`
+
+	// TotalSizeBytes is the total size of all files.
+	TotalSizeBytes int64 `json:"total_size_bytes"`
+
+	// AvgFileSizeBytes is the average data file size.
+	AvgFileSizeBytes int64 `json:"avg_file_size_bytes"`
+}
+
+// SummarizeFiles returns file statistics for the current snapshot.
+func (m *TableMetadata) SummarizeFiles() FilesSummary {
+	snap := m.CurrentSnapshot()
+	if snap == nil {
+		return FilesSummary{}
+	}
+
+	summary := FilesSummary{
+		SnapshotID:     snap.SnapshotID,
+		DataFiles:      summaryInt(snap.Summary, SummaryTotalDataFiles),
+		DeleteFiles:    summaryInt(snap.Summary, SummaryTotalDeleteFiles),
+		Records:        summaryInt(snap.Summary, SummaryTotalRecords),
+		TotalSizeBytes: summaryInt(snap.Summary, SummaryTotalFilesSize),
+	}
+	if summary.DataFiles > 0 {
+		summary.AvgFileSizeBytes = summary.TotalSizeBytes / summary.DataFiles
+	}
+	return summary
+}
+
+// PartitionStats holds statistics for a single partition.
+type PartitionStats struct {
+	// Partition is the partition path (e.g. "_cdc_timestamp_day=2024-01-01").
+	Partition string `json:"partition"`
+
+	// DataFiles is the number of data files in the partition.
+	DataFiles int64 `json:"data_files"`
+
+	// Records is the number of records in the partition.
+	Records int64 `json:"records"`
+
+	// SizeBytes is the total file size of the partition.
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// PartitionStats aggregates per-partition statistics from the partition
+// summaries of the current snapshot's ancestry. Writers only record these
+// summaries when partition summaries are enabled, so the result may be empty.
+func (m *TableMetadata) PartitionStats() []PartitionStats {
+	stats := make(map[string]*PartitionStats)
+
+	for _, snap := range m.Ancestry() {
+		for key, value := range snap.Summary {
+			if !strings.HasPrefix(key, summaryPartitionPrefix) {
+				continue
+			}
+			partition := strings.TrimPrefix(key, summaryPartitionPrefix)
+			fields := parsePartitionSummary(value)
+
+			ps, ok := stats[partition]
+			if !ok {
+				ps = &PartitionStats{Partition: partition}
+				stats[partition] = ps
+			}
+			ps.DataFiles += summaryInt(fields, SummaryAddedDataFiles) - summaryInt(fields, SummaryDeletedDataFiles)
+			ps.Records += summaryInt(fields, SummaryAddedRecords) - summaryInt(fields, SummaryDeletedRecords)
+			ps.SizeBytes += summaryInt(fields, SummaryAddedFilesSize) - summaryInt(fields, SummaryRemovedFilesSize)
+		}
+	}
+
+	result := make([]PartitionStats, 0, len(stats))
+	for _, ps := range stats {
+		result = append(result, *ps)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Partition < result[j].Partition
+	})
+	return result
+}
+
+// parsePartitionSummary parses a "key=value,key=value" partition summary.
+func parsePartitionSummary(value string) map[string]string {
+	fields := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok {
+			fields[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return fields
+}
+
+// summaryInt reads an integer summary value, returning 0 if absent or invalid.
+func summaryInt(summary map[string]string, key string) int64 {
+	n, err := strconv.ParseInt(summary[key], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
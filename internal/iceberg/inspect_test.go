@@ -0,0 +1,108 @@
+package iceberg
+
+import "testing"
+
+func testMetadata() *TableMetadata {
+	return &TableMetadata{
+		CurrentSnapshotID: 3,
+		Snapshots: []Snapshot{
+			{
+				SnapshotID: 1,
+				Summary: map[string]string{
+					"partitions.day=2024-01-01": "added-data-files=2,added-records=100,added-files-size=2000",
+				},
+			},
+			{
+				SnapshotID:       2,
+				ParentSnapshotID: 1,
+				Summary: map[string]string{
+					"partitions.day=2024-01-01": "added-data-files=1,added-records=50,added-files-size=1000",
+					"partitions.day=2024-01-02": "added-data-files=1,added-records=10,added-files-size=500",
+				},
+			},
+			{
+				SnapshotID:       3,
+				ParentSnapshotID: 2,
+				Summary: map[string]string{
+					SummaryTotalDataFiles: "4",
+					SummaryTotalRecords:   "160",
+					SummaryTotalFilesSize: "3500",
+				},
+			},
+			{
+				// Orphaned snapshot not in the current ancestry.
+				SnapshotID: 9,
+				Summary: map[string]string{
+					"partitions.day=2024-01-03": "added-data-files=5",
+				},
+			},
+		},
+	}
+}
+
+func TestTableMetadata_Ancestry(t *testing.T) {
+	ancestry := testMetadata().Ancestry()
+
+	want := []int64{3, 2, 1}
+	if len(ancestry) != len(want) {
+		t.Fatalf("len(Ancestry()) = %d, want %d", len(ancestry), len(want))
+	}
+	for i, id := range want {
+		if ancestry[i].SnapshotID != id {
+			t.Errorf("Ancestry()[%d] = %d, want %d", i, ancestry[i].SnapshotID, id)
+		}
+	}
+}
+
+func TestTableMetadata_BranchAncestry(t *testing.T) {
+	meta := testMetadata()
+	meta.Refs = map[string]SnapshotRef{"rebuild": {SnapshotID: 2, Type: RefTypeBranch}}
+
+	ancestry := meta.BranchAncestry("rebuild")
+	if len(ancestry) != 2 || ancestry[0].SnapshotID != 2 || ancestry[1].SnapshotID != 1 {
+		t.Errorf("BranchAncestry(rebuild) = %+v, want snapshots [2 1]", ancestry)
+	}
+	if got := meta.BranchAncestry(MainBranch); len(got) != 3 {
+		t.Errorf("len(BranchAncestry(main)) = %d, want 3", len(got))
+	}
+	if got := meta.BranchAncestry("missing"); got != nil {
+		t.Errorf("BranchAncestry(missing) = %+v, want nil", got)
+	}
+}
+
+func TestTableMetadata_SummarizeFiles(t *testing.T) {
+	summary := testMetadata().SummarizeFiles()
+
+	if summary.SnapshotID != 3 {
+		t.Errorf("SnapshotID = %d, want 3", summary.SnapshotID)
+	}
+	if summary.DataFiles != 4 || summary.Records != 160 || summary.TotalSizeBytes != 3500 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.AvgFileSizeBytes != 875 {
+		t.Errorf("AvgFileSizeBytes = %d, want 875", summary.AvgFileSizeBytes)
+	}
+
+	empty := (&TableMetadata{}).SummarizeFiles()
+	if empty != (FilesSummary{}) {
+		t.Errorf("expected empty summary for table without snapshots, got %+v", empty)
+	}
+}
+
+func TestTableMetadata_PartitionStats(t *testing.T) {
+	stats := testMetadata().PartitionStats()
+
+	if len(stats) != 2 {
+		t.Fatalf("len(PartitionStats()) = %d, want 2", len(stats))
+	}
+
+	first := stats[0]
+	if first.Partition != "day=2024-01-01" || first.DataFiles != 3 || first.Records != 150 || first.SizeBytes != 3000 {
+		t.Errorf("unexpected stats[0]: %+v", first)
+	}
+
+	second := stats[1]
+	if second.Partition != "day=2024-01-02" || second.DataFiles != 1 || second.Records != 10 {
+		t.Errorf("unexpected stats[1]: %+v", second)
+	}
+}
@@ -0,0 +1,605 @@
+// Package maintenance keeps Iceberg tables fast to query. Batches flush every
+// few seconds, so tables accumulate many small data files; a periodic job
+// rewrites them into files near a target size, in the manner of Iceberg's
+// rewrite_data_files, and expires old snapshots.
+//
+// Rewritten files are still referenced by the snapshots that precede the
+// rewrite. They are deleted from storage once those snapshots have expired.
+// Files awaiting deletion are tracked in memory, so a restart leaves them in
+// storage.
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/iceberg/writer"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// errFilesGone is returned when files planned for rewriting were removed by
+// a concurrent commit, so the rewrite must be abandoned.
+var errFilesGone = errors.New("data files removed concurrently")
+
+// Storage reads, writes and deletes data files.
+type Storage interface {
+	Download(ctx context.Context, bucket, key string) ([]byte, error)
+	Upload(ctx context.Context, bucket, key string, data io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, bucket, key string) error
+}
+
+// Config holds maintenance job configuration.
+type Config struct {
+	// Interval is how often the job runs.
+	Interval time.Duration
+
+	// TargetFileSize is the size compacted files are packed up to.
+	TargetFileSize int64
+
+	// MinFileSize is the size below which a file is compacted. Zero uses
+	// three quarters of TargetFileSize.
+	MinFileSize int64
+
+	// MinInputFiles is the fewest small files in a partition worth
+	// compacting.
+	MinInputFiles int
+
+	// SnapshotMaxAge is the age after which snapshots expire (0 = never).
+	SnapshotMaxAge time.Duration
+
+	// MinSnapshotsToKeep is the number of most recent snapshots of main
+	// never expired, whatever their age.
+	MinSnapshotsToKeep int
+
+	// CommitRetry bounds retries of rewrites that conflict with concurrent
+	// writers.
+	CommitRetry catalog.CommitRetryConfig
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Interval:           time.Hour,
+		TargetFileSize:     128 * 1024 * 1024,
+		MinInputFiles:      5,
+		SnapshotMaxAge:     5 * 24 * time.Hour,
+		MinSnapshotsToKeep: 1,
+		CommitRetry:        catalog.DefaultCommitRetryConfig(),
+	}
+}
+
+// Result summarizes a maintenance run.
+type Result struct {
+	// Tables is the number of tables maintained.
+	Tables int
+
+	// FilesRewritten is the number of small files replaced.
+	FilesRewritten int
+
+	// FilesWritten is the number of compacted files written.
+	FilesWritten int
+
+	// SnapshotsExpired is the number of snapshots expired.
+	SnapshotsExpired int
+
+	// BytesReclaimed is the size of rewritten files deleted from storage.
+	BytesReclaimed int64
+}
+
+// pendingDelete is a set of rewritten files that can be deleted once no
+// snapshot older than the rewrite remains.
+type pendingDelete struct {
+	rewrittenAtMs int64
+	files         []iceberg.DataFile
+}
+
+// Job periodically compacts and expires the snapshots of every table in the
+// warehouse.
+type Job struct {
+	config  Config
+	catalog catalog.MaintenanceCatalog
+	storage Storage
+	parquet *writer.ParquetWriter
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	pending map[string][]pendingDelete
+	now     func() time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewJob creates a new maintenance Job.
+func NewJob(cfg Config, cat catalog.MaintenanceCatalog, storage Storage, logger *slog.Logger) *Job {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.MinFileSize <= 0 {
+		cfg.MinFileSize = cfg.TargetFileSize * 3 / 4
+	}
+	if cfg.CommitRetry == (catalog.CommitRetryConfig{}) {
+		cfg.CommitRetry = catalog.DefaultCommitRetryConfig()
+	}
+	return &Job{
+		config:  cfg,
+		catalog: cat,
+		storage: storage,
+		parquet: writer.NewParquetWriter(),
+		logger:  logger.With("component", "iceberg-maintenance"),
+		pending: make(map[string][]pendingDelete),
+		now:     time.Now,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start starts the periodic maintenance. It is a no-op when the interval is
+// not positive.
+func (j *Job) Start(ctx context.Context) {
+	if j.config.Interval <= 0 {
+		return
+	}
+
+	j.logger.Info("starting iceberg maintenance",
+		"interval", j.config.Interval,
+		"target_file_size", j.config.TargetFileSize,
+		"snapshot_max_age", j.config.SnapshotMaxAge,
+	)
+
+	j.wg.Add(1)
+	go j.runLoop(ctx)
+}
+
+// Stop stops the periodic maintenance.
+func (j *Job) Stop() {
+	close(j.stopCh)
+	j.wg.Wait()
+}
+
+func (j *Job) runLoop(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		result, err := j.Run(ctx)
+		if err != nil {
+			j.logger.Error("iceberg maintenance failed", "error", err)
+		}
+		j.logger.Info("iceberg maintenance finished",
+			"tables", result.Tables,
+			"files_rewritten", result.FilesRewritten,
+			"files_written", result.FilesWritten,
+			"snapshots_expired", result.SnapshotsExpired,
+			"bytes_reclaimed", result.BytesReclaimed,
+		)
+	}
+}
+
+// Run compacts, expires and reclaims every table once. A table that fails
+// does not stop the others; their errors are joined.
+func (j *Job) Run(ctx context.Context) (Result, error) {
+	var result Result
+
+	namespaces, err := j.catalog.ListNamespaces(ctx)
+	if err != nil {
+		return result, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	var errs []error
+	for _, namespace := range namespaces {
+		tables, err := j.catalog.ListTables(ctx, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list tables of %s: %w", namespace, err))
+			continue
+		}
+		for _, table := range tables {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if err := j.maintainTable(ctx, namespace, table, &result); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", namespace, table, err))
+			}
+			result.Tables++
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// maintainTable compacts a table, expires its old snapshots and deletes
+// rewritten files no snapshot references any more.
+func (j *Job) maintainTable(ctx context.Context, namespace, table string, result *Result) error {
+	tableKey := namespace + "." + table
+
+	rewritten, written, err := j.compact(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("compact: %w", err)
+	}
+	result.FilesRewritten += rewritten
+	result.FilesWritten += written
+
+	expired, err := j.expire(ctx, namespace, table)
+	if err != nil {
+		return fmt.Errorf("expire snapshots: %w", err)
+	}
+	result.SnapshotsExpired += expired
+
+	reclaimed, err := j.reclaim(ctx, namespace, table)
+	result.BytesReclaimed += reclaimed
+	if err != nil {
+		return fmt.Errorf("delete rewritten files: %w", err)
+	}
+
+	if rewritten > 0 || expired > 0 || reclaimed > 0 {
+		j.logger.Info("table maintained",
+			"table", tableKey,
+			"files_rewritten", rewritten,
+			"files_written", written,
+			"snapshots_expired", expired,
+			"bytes_reclaimed", reclaimed,
+		)
+	}
+	return nil
+}
+
+// compact rewrites the small files of main's current snapshot. It returns
+// the number of files replaced and written.
+func (j *Job) compact(ctx context.Context, namespace, table string) (int, int, error) {
+	tableKey := namespace + "." + table
+
+	meta, err := j.catalog.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return 0, 0, fmt.Errorf("load table: %w", err)
+	}
+	base := mainSnapshotID(meta)
+	if base == 0 {
+		return 0, 0, nil
+	}
+
+	files, err := j.catalog.ListDataFiles(ctx, namespace, table, base)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list data files: %w", err)
+	}
+	bins := j.plan(files)
+	if len(bins) == 0 {
+		return 0, 0, nil
+	}
+
+	var removed, added []iceberg.DataFile
+	for _, bin := range bins {
+		file, err := j.rewrite(ctx, bin)
+		if err != nil {
+			j.deleteFiles(ctx, added)
+			return 0, 0, err
+		}
+		removed = append(removed, bin...)
+		added = append(added, file)
+	}
+
+	// Commit on top of the snapshot the files were planned from; on a
+	// conflict, commit on top of the new snapshot provided it still holds
+	// every rewritten file
+	first := true
+	commit := func() error {
+		if !first {
+			meta, err := j.catalog.LoadTable(ctx, namespace, table)
+			if err != nil {
+				return fmt.Errorf("reload table: %w", err)
+			}
+			base = mainSnapshotID(meta)
+			current, err := j.catalog.ListDataFiles(ctx, namespace, table, base)
+			if err != nil {
+				return fmt.Errorf("list data files: %w", err)
+			}
+			if !containsAll(current, removed) {
+				return errFilesGone
+			}
+		}
+		first = false
+		return j.catalog.ReplaceDataFiles(ctx, namespace, table, base, removed, added)
+	}
+	onConflict := func(attempt int, err error) {
+		metrics.IcebergCommitConflictsTotal.WithLabelValues("maintenance", tableKey).Inc()
+		j.logger.Warn("compaction conflicted with a concurrent writer, retrying",
+			"table", tableKey,
+			"attempt", attempt,
+			"error", err,
+		)
+	}
+	if err := catalog.RetryOnConflict(ctx, j.config.CommitRetry, onConflict, commit); err != nil {
+		j.deleteFiles(ctx, added)
+		if errors.Is(err, errFilesGone) {
+			j.logger.Info("compaction abandoned: files were rewritten concurrently", "table", tableKey)
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("replace data files: %w", err)
+	}
+
+	metrics.IcebergCompactionFilesRewrittenTotal.WithLabelValues(tableKey).Add(float64(len(removed)))
+
+	// Delete the rewritten files once the snapshots before the rewrite are
+	// gone. The current snapshot is the rewrite or a later one, so waiting
+	// for older snapshots to expire is safe.
+	rewrittenAt := j.now().UnixMilli()
+	if meta, err := j.catalog.LoadTable(ctx, namespace, table); err == nil {
+		if snap := meta.CurrentSnapshot(); snap != nil {
+			rewrittenAt = snap.TimestampMs
+		}
+	}
+	j.mu.Lock()
+	j.pending[tableKey] = append(j.pending[tableKey], pendingDelete{rewrittenAtMs: rewrittenAt, files: removed})
+	j.mu.Unlock()
+
+	return len(removed), len(added), nil
+}
+
+// plan groups the small files of a snapshot into bins to rewrite. Files
+// are grouped by partition and directory; partitions with fewer than
+// MinInputFiles small files are left alone. Each bin holds at least two
+// files and at most TargetFileSize bytes.
+func (j *Job) plan(files []iceberg.DataFile) [][]iceberg.DataFile {
+	groups := make(map[string][]iceberg.DataFile)
+	for _, f := range files {
+		if f.FileFormat != "parquet" || f.FileSizeInBytes >= j.config.MinFileSize {
+			continue
+		}
+		if _, _, ok := writer.ParseObjectURL(f.FilePath); !ok {
+			continue
+		}
+		key := partitionKey(f.PartitionData) + "|" + dir(f.FilePath)
+		groups[key] = append(groups[key], f)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var bins [][]iceberg.DataFile
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < max(j.config.MinInputFiles, 2) {
+			continue
+		}
+		sort.Slice(group, func(a, b int) bool { return group[a].FilePath < group[b].FilePath })
+
+		var bin []iceberg.DataFile
+		var size int64
+		for _, f := range group {
+			if len(bin) > 0 && size+f.FileSizeInBytes > j.config.TargetFileSize {
+				if len(bin) > 1 {
+					bins = append(bins, bin)
+				}
+				bin, size = nil, 0
+			}
+			bin = append(bin, f)
+			size += f.FileSizeInBytes
+		}
+		if len(bin) > 1 {
+			bins = append(bins, bin)
+		}
+	}
+	return bins
+}
+
+// rewrite merges the records of files into one file next to them.
+func (j *Job) rewrite(ctx context.Context, files []iceberg.DataFile) (iceberg.DataFile, error) {
+	var records []writer.CDCRecord
+	var expected int64
+	for _, f := range files {
+		bucket, key, _ := writer.ParseObjectURL(f.FilePath)
+		data, err := j.storage.Download(ctx, bucket, key)
+		if err != nil {
+			return iceberg.DataFile{}, fmt.Errorf("download %s: %w", f.FilePath, err)
+		}
+		read, err := writer.ReadRecords(data)
+		if err != nil {
+			return iceberg.DataFile{}, fmt.Errorf("read %s: %w", f.FilePath, err)
+		}
+		records = append(records, read...)
+		expected += f.RecordCount
+	}
+	if int64(len(records)) != expected {
+		return iceberg.DataFile{}, fmt.Errorf("read %d records from %d files, catalog reports %d", len(records), len(files), expected)
+	}
+
+	// Keep change order within the compacted file
+	sort.SliceStable(records, func(a, b int) bool { return records[a].CDCTimestamp < records[b].CDCTimestamp })
+
+	result, err := j.parquet.WriteRecords(records)
+	if err != nil {
+		return iceberg.DataFile{}, fmt.Errorf("write compacted file: %w", err)
+	}
+
+	bucket, key, _ := writer.ParseObjectURL(files[0].FilePath)
+	key = dir(key) + "/" + result.FileName
+	if err := j.storage.Upload(ctx, bucket, key, bytes.NewReader(result.Data), result.FileSizeInBytes, "application/octet-stream"); err != nil {
+		return iceberg.DataFile{}, fmt.Errorf("upload compacted file: %w", err)
+	}
+
+	return iceberg.DataFile{
+		FilePath:        dir(files[0].FilePath) + "/" + result.FileName,
+		FileFormat:      "parquet",
+		RecordCount:     result.RecordCount,
+		FileSizeInBytes: result.FileSizeInBytes,
+		PartitionData:   files[0].PartitionData,
+	}, nil
+}
+
+// expire expires snapshots older than SnapshotMaxAge, keeping the current
+// snapshot and its MinSnapshotsToKeep-1 most recent ancestors, and every
+// snapshot a branch or tag points at.
+func (j *Job) expire(ctx context.Context, namespace, table string) (int, error) {
+	if j.config.SnapshotMaxAge <= 0 {
+		return 0, nil
+	}
+
+	meta, err := j.catalog.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return 0, fmt.Errorf("load table: %w", err)
+	}
+
+	protected := map[int64]bool{meta.CurrentSnapshotID: true}
+	for _, ref := range meta.Refs {
+		protected[ref.SnapshotID] = true
+	}
+	for i, snap := range meta.Ancestry() {
+		if i >= j.config.MinSnapshotsToKeep {
+			break
+		}
+		protected[snap.SnapshotID] = true
+	}
+
+	cutoff := j.now().Add(-j.config.SnapshotMaxAge).UnixMilli()
+	var expired []int64
+	for _, snap := range meta.Snapshots {
+		if !protected[snap.SnapshotID] && snap.TimestampMs < cutoff {
+			expired = append(expired, snap.SnapshotID)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if err := j.catalog.ExpireSnapshots(ctx, namespace, table, expired); err != nil {
+		return 0, err
+	}
+	metrics.IcebergSnapshotsExpiredTotal.WithLabelValues(namespace + "." + table).Add(float64(len(expired)))
+	return len(expired), nil
+}
+
+// reclaim deletes rewritten files of the table once no snapshot older than
+// their rewrite remains, and returns the bytes deleted.
+func (j *Job) reclaim(ctx context.Context, namespace, table string) (int64, error) {
+	tableKey := namespace + "." + table
+
+	j.mu.Lock()
+	pending := j.pending[tableKey]
+	j.mu.Unlock()
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	meta, err := j.catalog.LoadTable(ctx, namespace, table)
+	if err != nil {
+		return 0, fmt.Errorf("load table: %w", err)
+	}
+	oldest := int64(-1)
+	for _, snap := range meta.Snapshots {
+		if oldest < 0 || snap.TimestampMs < oldest {
+			oldest = snap.TimestampMs
+		}
+	}
+
+	var reclaimed int64
+	var remaining []pendingDelete
+	var errs []error
+	for _, p := range pending {
+		if oldest >= 0 && oldest < p.rewrittenAtMs {
+			remaining = append(remaining, p)
+			continue
+		}
+		var failed []iceberg.DataFile
+		for _, f := range p.files {
+			bucket, key, _ := writer.ParseObjectURL(f.FilePath)
+			if err := j.storage.Delete(ctx, bucket, key); err != nil {
+				errs = append(errs, fmt.Errorf("delete %s: %w", f.FilePath, err))
+				failed = append(failed, f)
+				continue
+			}
+			reclaimed += f.FileSizeInBytes
+		}
+		if len(failed) > 0 {
+			remaining = append(remaining, pendingDelete{rewrittenAtMs: p.rewrittenAtMs, files: failed})
+		}
+	}
+
+	j.mu.Lock()
+	if len(remaining) == 0 {
+		delete(j.pending, tableKey)
+	} else {
+		j.pending[tableKey] = remaining
+	}
+	j.mu.Unlock()
+
+	metrics.IcebergCompactionBytesReclaimedTotal.WithLabelValues(tableKey).Add(float64(reclaimed))
+	return reclaimed, errors.Join(errs...)
+}
+
+// deleteFiles removes compacted files that were never committed.
+func (j *Job) deleteFiles(ctx context.Context, files []iceberg.DataFile) {
+	for _, f := range files {
+		bucket, key, _ := writer.ParseObjectURL(f.FilePath)
+		if err := j.storage.Delete(ctx, bucket, key); err != nil {
+			j.logger.Warn("failed to delete uncommitted compacted file", "file", f.FilePath, "error", err)
+		}
+	}
+}
+
+// mainSnapshotID returns the snapshot main points at, or 0 if it has none.
+func mainSnapshotID(meta *iceberg.TableMetadata) int64 {
+	if ref, ok := meta.Refs[iceberg.MainBranch]; ok {
+		return ref.SnapshotID
+	}
+	return meta.CurrentSnapshotID
+}
+
+// containsAll reports whether files holds every file of subset.
+func containsAll(files, subset []iceberg.DataFile) bool {
+	paths := make(map[string]bool, len(files))
+	for _, f := range files {
+		paths[f.FilePath] = true
+	}
+	for _, f := range subset {
+		if !paths[f.FilePath] {
+			return false
+		}
+	}
+	return true
+}
+
+// dir returns the directory of a path.
+func dir(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// partitionKey returns a stable key for partition values.
+func partitionKey(partition map[string]any) string {
+	if len(partition) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(partition))
+	for name := range partition {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%v;", name, partition[name])
+	}
+	return b.String()
+}
@@ -0,0 +1,227 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/iceberg/writer"
+)
+
+// fakeCatalog is a single-table MaintenanceCatalog kept in memory.
+type fakeCatalog struct {
+	catalog.Catalog
+
+	now       time.Time
+	snapshots []iceberg.Snapshot
+	files     map[int64][]iceberg.DataFile
+	current   int64
+	conflicts int
+}
+
+func (c *fakeCatalog) commit(files []iceberg.DataFile) {
+	id := c.current + 1
+	c.snapshots = append(c.snapshots, iceberg.Snapshot{SnapshotID: id, ParentSnapshotID: c.current, TimestampMs: c.now.UnixMilli()})
+	c.files[id] = files
+	c.current = id
+}
+
+func (c *fakeCatalog) ListNamespaces(context.Context) ([]string, error) {
+	return []string{"public"}, nil
+}
+
+func (c *fakeCatalog) ListTables(context.Context, string) ([]string, error) {
+	return []string{"orders"}, nil
+}
+
+func (c *fakeCatalog) LoadTable(context.Context, string, string) (*iceberg.TableMetadata, error) {
+	return &iceberg.TableMetadata{
+		CurrentSnapshotID: c.current,
+		Snapshots:         append([]iceberg.Snapshot(nil), c.snapshots...),
+	}, nil
+}
+
+func (c *fakeCatalog) ListDataFiles(_ context.Context, _, _ string, snapshotID int64) ([]iceberg.DataFile, error) {
+	return c.files[snapshotID], nil
+}
+
+func (c *fakeCatalog) ReplaceDataFiles(_ context.Context, _, _ string, base int64, removed, added []iceberg.DataFile) error {
+	if c.conflicts > 0 {
+		// A writer appends a file first
+		c.conflicts--
+		c.commit(append(c.files[c.current], iceberg.DataFile{FilePath: "s3://bucket/orders/data/late.parquet", FileFormat: "parquet", RecordCount: 1, FileSizeInBytes: 10}))
+	}
+	if base != c.current {
+		return catalog.ErrCommitConflict
+	}
+
+	gone := make(map[string]bool)
+	for _, f := range removed {
+		gone[f.FilePath] = true
+	}
+	var files []iceberg.DataFile
+	for _, f := range c.files[c.current] {
+		if !gone[f.FilePath] {
+			files = append(files, f)
+		}
+	}
+	c.commit(append(files, added...))
+	return nil
+}
+
+func (c *fakeCatalog) ExpireSnapshots(_ context.Context, _, _ string, ids []int64) error {
+	expired := make(map[int64]bool)
+	for _, id := range ids {
+		expired[id] = true
+	}
+	var kept []iceberg.Snapshot
+	for _, snap := range c.snapshots {
+		if !expired[snap.SnapshotID] {
+			kept = append(kept, snap)
+		}
+	}
+	c.snapshots = kept
+	return nil
+}
+
+// memStorage is an in-memory Storage.
+type memStorage map[string][]byte
+
+func (s memStorage) Download(_ context.Context, bucket, key string) ([]byte, error) {
+	data, ok := s[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("no such object %s/%s", bucket, key)
+	}
+	return data, nil
+}
+
+func (s memStorage) Upload(_ context.Context, bucket, key string, data io.Reader, _ int64, _ string) error {
+	b, err := io.ReadAll(data)
+	s[bucket+"/"+key] = b
+	return err
+}
+
+func (s memStorage) Delete(_ context.Context, bucket, key string) error {
+	delete(s, bucket+"/"+key)
+	return nil
+}
+
+// seedTable commits n small files of two records each in one snapshot.
+func seedTable(t *testing.T, cat *fakeCatalog, storage memStorage, n int) {
+	t.Helper()
+	pw := writer.NewParquetWriter()
+	var files []iceberg.DataFile
+	for i := range n {
+		result, err := pw.WriteRecords([]writer.CDCRecord{
+			{Data: fmt.Sprintf(`{"id":%d}`, 2*i), CDCOperation: "INSERT", CDCTimestamp: int64(2 * i)},
+			{Data: fmt.Sprintf(`{"id":%d}`, 2*i+1), CDCOperation: "INSERT", CDCTimestamp: int64(2*i + 1)},
+		})
+		if err != nil {
+			t.Fatalf("WriteRecords() error = %v", err)
+		}
+		key := fmt.Sprintf("orders/data/%02d.parquet", i)
+		storage["bucket/"+key] = result.Data
+		files = append(files, iceberg.DataFile{
+			FilePath:        "s3://bucket/" + key,
+			FileFormat:      "parquet",
+			RecordCount:     result.RecordCount,
+			FileSizeInBytes: result.FileSizeInBytes,
+		})
+	}
+	cat.commit(files)
+}
+
+func TestJobCompactsExpiresAndReclaims(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cat := &fakeCatalog{now: start, files: make(map[int64][]iceberg.DataFile), conflicts: 1}
+	storage := make(memStorage)
+	seedTable(t, cat, storage, 6)
+
+	cfg := DefaultConfig()
+	cfg.CommitRetry = catalog.CommitRetryConfig{MaxRetries: 2}
+	job := NewJob(cfg, cat, storage, nil)
+	job.now = func() time.Time { return cat.now }
+
+	// First run: the small files are compacted, retrying after a conflict
+	cat.now = start.Add(time.Hour)
+	result, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.FilesRewritten != 6 || result.FilesWritten != 1 {
+		t.Fatalf("Run() = %+v, want 6 files rewritten into 1", result)
+	}
+
+	files := cat.files[cat.current]
+	if len(files) != 2 {
+		t.Fatalf("table has %d files, want the late file and the compacted file", len(files))
+	}
+	compacted := files[1]
+	_, key, _ := writer.ParseObjectURL(compacted.FilePath)
+	records, err := writer.ReadRecords(storage["bucket/"+key])
+	if err != nil {
+		t.Fatalf("ReadRecords() error = %v", err)
+	}
+	if len(records) != 12 || compacted.RecordCount != 12 {
+		t.Fatalf("compacted file has %d records (catalog %d), want 12", len(records), compacted.RecordCount)
+	}
+	for i, r := range records {
+		if r.CDCTimestamp != int64(i) {
+			t.Fatalf("record %d has timestamp %d, want records in change order", i, r.CDCTimestamp)
+		}
+	}
+	if _, ok := storage["bucket/orders/data/00.parquet"]; !ok {
+		t.Fatal("rewritten file deleted while older snapshots still reference it")
+	}
+
+	// Later run: the old snapshots expire and the rewritten files are deleted
+	cat.now = start.Add(10 * 24 * time.Hour)
+	result, err = job.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.SnapshotsExpired != 2 || result.BytesReclaimed == 0 {
+		t.Fatalf("Run() = %+v, want 2 snapshots expired and bytes reclaimed", result)
+	}
+	if len(cat.snapshots) != 1 || cat.snapshots[0].SnapshotID != cat.current {
+		t.Errorf("snapshots = %+v, want only the current snapshot", cat.snapshots)
+	}
+	for i := range 6 {
+		if _, ok := storage[fmt.Sprintf("bucket/orders/data/%02d.parquet", i)]; ok {
+			t.Errorf("rewritten file %02d not deleted", i)
+		}
+	}
+	if _, ok := storage["bucket/"+key]; !ok {
+		t.Error("compacted file deleted")
+	}
+}
+
+func TestJobPlanSkipsFewOrLargeFiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TargetFileSize = 100
+	job := NewJob(cfg, nil, nil, nil)
+
+	small := func(name string, size int64, partition map[string]any) iceberg.DataFile {
+		return iceberg.DataFile{FilePath: "s3://b/t/data/" + name, FileFormat: "parquet", FileSizeInBytes: size, PartitionData: partition}
+	}
+	day1 := map[string]any{"day": "2024-01-01"}
+	day2 := map[string]any{"day": "2024-01-02"}
+
+	files := []iceberg.DataFile{
+		// Five small files of day 1, packed into bins of up to 100 bytes
+		small("a", 40, day1), small("b", 40, day1), small("c", 40, day1), small("d", 40, day1), small("e", 40, day1),
+		// A large file of day 1 is left alone
+		small("f", 90, day1),
+		// Too few small files of day 2
+		small("g", 10, day2), small("h", 10, day2),
+	}
+
+	bins := job.plan(files)
+	if len(bins) != 2 || len(bins[0]) != 2 || len(bins[1]) != 2 {
+		t.Fatalf("plan() = %v, want two bins of two day-1 files", bins)
+	}
+}
@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// DefaultNumericScale is the scale of unbounded numeric columns by default,
+// the scale Spark gives decimals of unknown size.
+const DefaultNumericScale = 18
+
+// DecimalMapping maps PostgreSQL numeric columns to Iceberg decimals.
+// Source rows carry numeric values as text, so they are stored exactly.
+type DecimalMapping struct {
+	// DefaultPrecision is the precision of numeric columns declared
+	// without one.
+	DefaultPrecision int
+
+	// DefaultScale is the scale of numeric columns declared without a
+	// precision.
+	DefaultScale int
+}
+
+// DefaultDecimalMapping maps unbounded numeric columns to decimal(38,18).
+func DefaultDecimalMapping() DecimalMapping {
+	return DecimalMapping{
+		DefaultPrecision: iceberg.MaxDecimalPrecision,
+		DefaultScale:     DefaultNumericScale,
+	}
+}
+
+// Validate checks that the default precision and scale form an Iceberg
+// decimal.
+func (m DecimalMapping) Validate() error {
+	if m.DefaultPrecision < 1 || m.DefaultPrecision > iceberg.MaxDecimalPrecision {
+		return fmt.Errorf("numeric precision %d must be between 1 and %d", m.DefaultPrecision, iceberg.MaxDecimalPrecision)
+	}
+	if m.DefaultScale < 0 || m.DefaultScale > m.DefaultPrecision {
+		return fmt.Errorf("numeric scale %d must be between 0 and the precision %d", m.DefaultScale, m.DefaultPrecision)
+	}
+	return nil
+}
+
+// IsNumericType reports whether a PostgreSQL type name is numeric or
+// decimal, with or without a precision.
+func IsNumericType(pgType string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(pgType)), "(")
+	name = strings.TrimSpace(name)
+	return name == "numeric" || name == "decimal"
+}
+
+// MapNumeric returns the Iceberg decimal type for a numeric column type
+// such as "numeric(38,9)". numeric(p) has scale 0, and a numeric without a
+// precision takes the default precision and scale. A precision above
+// iceberg.MaxDecimalPrecision, or a scale Iceberg cannot store, returns an
+// error wrapping ErrIncompatibleChange.
+func (m DecimalMapping) MapNumeric(pgType string) (iceberg.Type, error) {
+	if !IsNumericType(pgType) {
+		return "", fmt.Errorf("%s is not a numeric type", pgType)
+	}
+
+	_, modifier, bounded := strings.Cut(strings.TrimSpace(pgType), "(")
+	if !bounded {
+		return iceberg.DecimalType(m.DefaultPrecision, m.DefaultScale), nil
+	}
+
+	modifier, ok := strings.CutSuffix(strings.TrimSpace(modifier), ")")
+	if !ok {
+		return "", fmt.Errorf("invalid numeric type %s", pgType)
+	}
+	p, s, hasScale := strings.Cut(modifier, ",")
+	precision, err := strconv.Atoi(strings.TrimSpace(p))
+	if err != nil {
+		return "", fmt.Errorf("invalid numeric type %s", pgType)
+	}
+	scale := 0
+	if hasScale {
+		if scale, err = strconv.Atoi(strings.TrimSpace(s)); err != nil {
+			return "", fmt.Errorf("invalid numeric type %s", pgType)
+		}
+	}
+
+	if precision > iceberg.MaxDecimalPrecision {
+		return "", fmt.Errorf("%w: %s has a precision above %d, the largest an Iceberg decimal has",
+			ErrIncompatibleChange, pgType, iceberg.MaxDecimalPrecision)
+	}
+	if scale < 0 || scale > precision {
+		return "", fmt.Errorf("%w: %s has a scale outside 0 to its precision, which an Iceberg decimal cannot store",
+			ErrIncompatibleChange, pgType)
+	}
+	return iceberg.DecimalType(precision, scale), nil
+}
+
+// ColumnTypes returns the Iceberg types of the numeric columns among a
+// table's column types, keyed by source column name. Other columns are left
+// out; their types are inferred from values. An error names the column.
+func (m DecimalMapping) ColumnTypes(pgTypes map[string]string) (map[string]iceberg.Type, error) {
+	types := make(map[string]iceberg.Type)
+	for column, pgType := range pgTypes {
+		if !IsNumericType(pgType) {
+			continue
+		}
+		t, err := m.MapNumeric(pgType)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", column, err)
+		}
+		types[column] = t
+	}
+	return types, nil
+}
+
+// SourceColumns looks up the declared column types of source tables.
+type SourceColumns interface {
+	// ColumnTypes returns the type of every column of a table as
+	// PostgreSQL formats it, e.g. "numeric(38,9)", keyed by column name.
+	ColumnTypes(ctx context.Context, schema, table string) (map[string]string, error)
+}
+
+// decimalDigits returns the number of significant integer digits and
+// fractional digits of a decimal value: text as PostgreSQL writes numerics,
+// a json.Number or a Go number. ok is false for any other value, including
+// NaN and infinities.
+func decimalDigits(value any) (integer, fraction int, ok bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = string(v)
+	case int:
+		s = strconv.Itoa(v)
+	case int32:
+		s = strconv.FormatInt(int64(v), 10)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float32:
+		return decimalDigits(float64(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, 0, false
+		}
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return 0, 0, false
+	}
+
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, 0, false
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, 0, false
+			}
+		}
+	}
+	return len(strings.TrimLeft(intPart, "0")), len(strings.TrimRight(fracPart, "0")), true
+}
+
+// fitDecimal returns the type a decimal column of type t, with the given
+// precision and scale, needs to store value: t itself, or a decimal with a
+// larger precision and the same scale, the only decimal promotion Iceberg
+// allows. ok is false if the value has more fractional digits than the
+// scale or needs a precision above iceberg.MaxDecimalPrecision.
+func fitDecimal(t iceberg.Type, precision, scale int, value any) (iceberg.Type, bool) {
+	integer, fraction, ok := decimalDigits(value)
+	if !ok || fraction > scale {
+		return t, false
+	}
+	if need := integer + scale; need > precision {
+		if need > iceberg.MaxDecimalPrecision {
+			return t, false
+		}
+		return iceberg.DecimalType(need, scale), true
+	}
+	return t, true
+}
@@ -0,0 +1,290 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// ErrIncompatibleChange is returned when a batch holds values a table's
+// column cannot store and Iceberg does not allow widening the column to fit
+// them.
+var ErrIncompatibleChange = errors.New("incompatible schema change")
+
+// TypePromotion is a column whose type was widened.
+type TypePromotion struct {
+	// Column is the column name.
+	Column string
+
+	// From is the column's previous type.
+	From iceberg.Type
+
+	// To is the column's new type.
+	To iceberg.Type
+}
+
+// Evolution describes how a table schema must change to store a batch.
+type Evolution struct {
+	// Schema is the evolved schema, or the current schema if nothing
+	// changed. An evolved schema has the next schema ID.
+	Schema iceberg.Schema
+
+	// LastColumnID is the highest column ID assigned after evolution.
+	LastColumnID int
+
+	// Added are the new columns, all optional.
+	Added []iceberg.Field
+
+	// Promoted are the columns whose type was widened.
+	Promoted []TypePromotion
+
+	// Relaxed are the required columns made optional because the batch
+	// has nulls for them.
+	Relaxed []string
+}
+
+// Changed reports whether the schema must be updated.
+func (e *Evolution) Changed() bool {
+	return len(e.Added) > 0 || len(e.Promoted) > 0 || len(e.Relaxed) > 0
+}
+
+// Evolve compares a batch with a table's current schema and returns the
+// schema needed to store it. It follows Iceberg's schema evolution rules:
+// new columns are added as optional, int is promoted to long and float to
+// double, and required columns with nulls become optional. Values that need
+// any other change, such as text in a long column, return an error wrapping
+// ErrIncompatibleChange that names the column.
+//
+// lastColumnID is the table's highest assigned column ID; new columns are
+// numbered after it.
+func (b *Builder) Evolve(current iceberg.Schema, lastColumnID int, events []cdc.Event) (*Evolution, error) {
+	return b.EvolveWithTypes(current, lastColumnID, events, nil)
+}
+
+// EvolveWithTypes is Evolve with the declared Iceberg types of source
+// columns, keyed by source column name, such as those of DecimalMapping's
+// ColumnTypes. A new column with a declared type is added with it rather
+// than a type inferred from values, and values it cannot store return an
+// error wrapping ErrIncompatibleChange.
+func (b *Builder) EvolveWithTypes(current iceberg.Schema, lastColumnID int, events []cdc.Event, declared map[string]iceberg.Type) (*Evolution, error) {
+	evo := &Evolution{LastColumnID: lastColumnID}
+
+	fields := make([]iceberg.Field, len(current.Fields))
+	copy(fields, current.Fields)
+	index := make(map[string]int, len(fields))
+	for i, f := range fields {
+		index[f.Name] = i
+		evo.LastColumnID = max(evo.LastColumnID, f.ID)
+	}
+
+	// Columns not in the schema, with the type inferred so far; "" until a
+	// non-null value is seen
+	added := make(map[string]iceberg.Type)
+	promotedFrom := make(map[string]iceberg.Type)
+	relaxed := make(map[string]bool)
+
+	observe := func(column string, value any) error {
+		name := b.Mapper.Name(column)
+		if isSystemColumn(name) {
+			return nil
+		}
+
+		i, exists := index[name]
+		if !exists {
+			if t, ok := declared[column]; ok {
+				if current := added[name]; current != "" {
+					t = current
+				}
+				if value != nil {
+					widened, ok := fitValue(t, value)
+					if !ok {
+						return fmt.Errorf("%w: column %q has type %s and cannot store %s value %v",
+							ErrIncompatibleChange, name, t, InferTypeFromValue(value), value)
+					}
+					t = widened
+				}
+				added[name] = t
+				return nil
+			}
+			if value == nil {
+				if _, seen := added[name]; !seen {
+					added[name] = ""
+				}
+				return nil
+			}
+			t := added[name]
+			switch {
+			case t == "":
+				added[name] = InferTypeFromValue(value)
+			default:
+				if widened, ok := fitValue(t, value); ok {
+					added[name] = widened
+				} else {
+					// Mixed types in a new column fall back to string, as
+					// when building a schema
+					added[name] = iceberg.TypeString
+				}
+			}
+			return nil
+		}
+
+		field := &fields[i]
+		if value == nil {
+			if field.Required && !relaxed[name] {
+				field.Required = false
+				relaxed[name] = true
+			}
+			return nil
+		}
+
+		widened, ok := fitValue(field.Type, value)
+		if !ok {
+			return fmt.Errorf("%w: column %q has type %s and cannot store %s value %v",
+				ErrIncompatibleChange, name, field.Type, InferTypeFromValue(value), value)
+		}
+		if widened != field.Type {
+			if _, seen := promotedFrom[name]; !seen {
+				promotedFrom[name] = field.Type
+			}
+			field.Type = widened
+		}
+		return nil
+	}
+
+	for _, event := range events {
+		for column, value := range event.After {
+			if err := observe(column, value); err != nil {
+				return nil, err
+			}
+		}
+		for column, value := range event.Before {
+			if err := observe(column, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if from, ok := promotedFrom[f.Name]; ok {
+			evo.Promoted = append(evo.Promoted, TypePromotion{Column: f.Name, From: from, To: f.Type})
+		}
+		if relaxed[f.Name] {
+			evo.Relaxed = append(evo.Relaxed, f.Name)
+		}
+	}
+
+	names := make([]string, 0, len(added))
+	for name := range added {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := added[name]
+		if t == "" {
+			t = iceberg.TypeString
+		}
+		evo.LastColumnID++
+		field := iceberg.Field{ID: evo.LastColumnID, Name: name, Type: t}
+		fields = append(fields, field)
+		evo.Added = append(evo.Added, field)
+	}
+
+	evo.Schema = current
+	if evo.Changed() {
+		evo.Schema = iceberg.Schema{SchemaID: current.SchemaID + 1, Fields: fields}
+	}
+	return evo, nil
+}
+
+// fitValue returns the type a column of type t needs to store value: t
+// itself, or a type Iceberg allows t to be promoted to. ok is false if no
+// allowed type can store the value.
+//
+// Row data arrives decoded from JSON, so whole numbers may be float64 and
+// dates, times and UUIDs are strings; those are accepted for the matching
+// column types. Numeric values are text, which decimal columns take, as do
+// double columns of tables created before numerics were mapped to
+// decimals.
+func fitValue(t iceberg.Type, value any) (iceberg.Type, bool) {
+	if precision, scale, ok := t.Decimal(); ok {
+		return fitDecimal(t, precision, scale, value)
+	}
+
+	switch t {
+	case iceberg.TypeString:
+		return t, true
+	case iceberg.TypeBoolean:
+		_, ok := value.(bool)
+		return t, ok
+	case iceberg.TypeInt:
+		n, ok := integerValue(value)
+		if !ok {
+			return t, false
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return iceberg.TypeLong, true
+		}
+		return t, true
+	case iceberg.TypeLong:
+		_, ok := integerValue(value)
+		return t, ok
+	case iceberg.TypeFloat:
+		switch value.(type) {
+		case float32:
+			return t, true
+		case float64:
+			return iceberg.TypeDouble, true
+		}
+		_, ok := integerValue(value)
+		return t, ok
+	case iceberg.TypeDouble:
+		switch v := value.(type) {
+		case float32, float64:
+			return t, true
+		case string:
+			_, _, ok := decimalDigits(v)
+			return t, ok
+		}
+		_, ok := integerValue(value)
+		return t, ok
+	case iceberg.TypeDate, iceberg.TypeTime, iceberg.TypeTimestamp, iceberg.TypeTimestampTZ, iceberg.TypeUUID:
+		switch value.(type) {
+		case string, time.Time:
+			return t, true
+		}
+		return t, false
+	case iceberg.TypeBinary:
+		switch value.(type) {
+		case []byte, string:
+			return t, true
+		}
+		return t, false
+	}
+	// Types this package does not produce are left to the catalog
+	return t, true
+}
+
+// integerValue returns value as an int64 if it is a whole number.
+func integerValue(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float32:
+		return integerValue(float64(v))
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
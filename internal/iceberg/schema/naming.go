@@ -0,0 +1,219 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// CasePolicy controls how source column names are transformed into Iceberg
+// column names.
+type CasePolicy string
+
+const (
+	// CaseIdentity keeps source column names unchanged.
+	CaseIdentity CasePolicy = "identity"
+
+	// CaseLower lower-cases column names.
+	CaseLower CasePolicy = "lower"
+
+	// CaseSnake converts column names to snake_case (CustomerID -> customer_id).
+	CaseSnake CasePolicy = "snake_case"
+)
+
+// ErrColumnCollision is returned when two source columns map to the same
+// target column name.
+var ErrColumnCollision = errors.New("column name collision")
+
+// ParseCasePolicy parses a case policy; an empty string means identity.
+func ParseCasePolicy(s string) (CasePolicy, error) {
+	switch p := CasePolicy(s); p {
+	case "":
+		return CaseIdentity, nil
+	case CaseIdentity, CaseLower, CaseSnake:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid column case policy %q: must be identity, lower or snake_case", s)
+}
+
+// ParseRenames parses explicit column renames written as "source:target".
+func ParseRenames(pairs []string) (map[string]string, error) {
+	renames := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		source, target, ok := strings.Cut(pair, ":")
+		source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("invalid column rename %q: expected source:target", pair)
+		}
+		if _, dup := renames[source]; dup {
+			return nil, fmt.Errorf("column %q is renamed more than once", source)
+		}
+		renames[source] = target
+	}
+	return renames, nil
+}
+
+// ColumnMapper maps source column names to target column names. Explicit
+// renames take precedence over the case policy. A nil ColumnMapper keeps
+// names unchanged.
+type ColumnMapper struct {
+	policy  CasePolicy
+	renames map[string]string
+}
+
+// NewColumnMapper creates a ColumnMapper from a case policy and explicit
+// per-column renames (source name -> target name).
+func NewColumnMapper(policy CasePolicy, renames map[string]string) (*ColumnMapper, error) {
+	if _, err := ParseCasePolicy(string(policy)); err != nil {
+		return nil, err
+	}
+	for source, target := range renames {
+		if source == "" || target == "" {
+			return nil, fmt.Errorf("invalid column rename %q -> %q", source, target)
+		}
+		if isSystemColumn(target) {
+			return nil, fmt.Errorf("column rename %q -> %q: target is a reserved CDC column", source, target)
+		}
+	}
+	if policy == "" {
+		policy = CaseIdentity
+	}
+	return &ColumnMapper{policy: policy, renames: renames}, nil
+}
+
+// IsIdentity reports whether the mapper leaves all names unchanged.
+func (m *ColumnMapper) IsIdentity() bool {
+	return m == nil || (m.policy == CaseIdentity && len(m.renames) == 0)
+}
+
+// Name returns the target name for a source column.
+func (m *ColumnMapper) Name(column string) string {
+	if m == nil || column == OverflowColumn {
+		return column
+	}
+	if target, ok := m.renames[column]; ok {
+		return target
+	}
+	switch m.policy {
+	case CaseLower:
+		return strings.ToLower(column)
+	case CaseSnake:
+		return toSnakeCase(column)
+	}
+	return column
+}
+
+// Check returns ErrColumnCollision if two of the given source columns map to
+// the same target name, or if a column maps onto a reserved CDC column.
+func (m *ColumnMapper) Check(columns []string) error {
+	if m.IsIdentity() {
+		return nil
+	}
+
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+
+	seen := make(map[string]string, len(sorted))
+	var errs []error
+	for _, column := range sorted {
+		target := m.Name(column)
+		if isSystemColumn(target) {
+			errs = append(errs, fmt.Errorf("%w: %q maps to reserved column %q", ErrColumnCollision, column, target))
+			continue
+		}
+		if other, ok := seen[target]; ok && other != column {
+			errs = append(errs, fmt.Errorf("%w: %q and %q both map to %q", ErrColumnCollision, other, column, target))
+			continue
+		}
+		seen[target] = column
+	}
+	return errors.Join(errs...)
+}
+
+// CheckEvents checks the columns of a batch of events for collisions.
+func (m *ColumnMapper) CheckEvents(events []cdc.Event) error {
+	if m.IsIdentity() {
+		return nil
+	}
+	return m.Check(eventColumns(events))
+}
+
+// MapRow returns a copy of row with its keys mapped to target names.
+func (m *ColumnMapper) MapRow(row map[string]any) map[string]any {
+	if m.IsIdentity() || row == nil {
+		return row
+	}
+	mapped := make(map[string]any, len(row))
+	for column, value := range row {
+		mapped[m.Name(column)] = value
+	}
+	return mapped
+}
+
+// toSnakeCase converts an identifier to snake_case. Word boundaries are a
+// lower-case letter or digit followed by an upper-case letter, the last
+// upper-case letter of an acronym followed by a lower-case letter, and any
+// non-alphanumeric character.
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+
+	underscore := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					underscore()
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			underscore()
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// eventColumns returns the distinct column names of a batch of events.
+func eventColumns(events []cdc.Event) []string {
+	set := make(map[string]struct{})
+	for _, event := range events {
+		for name := range event.After {
+			set[name] = struct{}{}
+		}
+		for name := range event.Before {
+			set[name] = struct{}{}
+		}
+	}
+	columns := make([]string, 0, len(set))
+	for name := range set {
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// isSystemColumn reports whether name is one of the CDC system columns.
+func isSystemColumn(name string) bool {
+	for _, col := range iceberg.CDCSystemColumns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// ErrPartitionColumn is returned when a partition rule references a column
+// the table does not have, or one its transform cannot be applied to.
+var ErrPartitionColumn = errors.New("invalid partition column")
+
+// partitionFieldIDStart is the first partition field ID; Iceberg numbers
+// partition fields from 1000 so they never clash with column IDs.
+const partitionFieldIDStart = 1000
+
+// PartitionRule partitions a table by a transform of one column.
+type PartitionRule struct {
+	// Transform is the Iceberg transform: identity, year, month, day, hour,
+	// bucket[N] or truncate[W].
+	Transform string
+
+	// Column is the Iceberg column name the transform is applied to.
+	Column string
+}
+
+// String returns the rule as "transform:column".
+func (r PartitionRule) String() string {
+	return r.Transform + ":" + r.Column
+}
+
+// ParsePartitionRule parses a rule written as "transform:column", such as
+// "day:event_ts", "identity:region" or "bucket[16]:customer_id".
+func ParsePartitionRule(s string) (PartitionRule, error) {
+	transform, column, ok := strings.Cut(s, ":")
+	transform, column = strings.ToLower(strings.TrimSpace(transform)), strings.TrimSpace(column)
+	if !ok || transform == "" || column == "" {
+		return PartitionRule{}, fmt.Errorf("invalid partition rule %q: expected transform:column", s)
+	}
+	if err := checkTransform(transform); err != nil {
+		return PartitionRule{}, fmt.Errorf("invalid partition rule %q: %w", s, err)
+	}
+	return PartitionRule{Transform: transform, Column: column}, nil
+}
+
+// ParsePartitionRules parses a list of partition rules.
+func ParsePartitionRules(specs []string) ([]PartitionRule, error) {
+	rules := make([]PartitionRule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := ParsePartitionRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseTablePartitions parses per-table partition rules written as
+// "schema.table=rule;rule", such as "public.orders=day:created_at;identity:region".
+func ParseTablePartitions(entries []string) (map[string][]PartitionRule, error) {
+	tables := make(map[string][]PartitionRule, len(entries))
+	for _, entry := range entries {
+		table, specs, ok := strings.Cut(entry, "=")
+		table = strings.TrimSpace(table)
+		if !ok || table == "" {
+			return nil, fmt.Errorf("invalid table partitioning %q: expected schema.table=transform:column;...", entry)
+		}
+		if _, dup := tables[table]; dup {
+			return nil, fmt.Errorf("table %q is partitioned more than once", table)
+		}
+		rules, err := ParsePartitionRules(strings.Split(specs, ";"))
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		tables[table] = rules
+	}
+	return tables, nil
+}
+
+// BuildPartitionSpec builds the partition spec a table with the given
+// schema is created with. Every rule must reference a column of the schema
+// whose type the transform supports; otherwise an error wrapping
+// ErrPartitionColumn names the rule. No rules gives an unpartitioned spec.
+//
+// Row data carries timestamps as strings, so a string column partitioned by
+// year, month, day or hour is declared a timestamp in the returned schema.
+func BuildPartitionSpec(s iceberg.Schema, rules []PartitionRule) (iceberg.Schema, iceberg.PartitionSpec, error) {
+	fields := make([]iceberg.Field, len(s.Fields))
+	copy(fields, s.Fields)
+	s.Fields = fields
+
+	spec := iceberg.PartitionSpec{SpecID: 0}
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		field := GetFieldByName(s, rule.Column)
+		if field == nil {
+			return iceberg.Schema{}, iceberg.PartitionSpec{}, fmt.Errorf("%w: partition %s: column %q is not in the table schema",
+				ErrPartitionColumn, rule, rule.Column)
+		}
+		if field.Type == iceberg.TypeString && isTimeTransform(rule.Transform) {
+			field.Type = iceberg.TypeTimestamp
+		}
+		if !transformSupports(rule.Transform, field.Type) {
+			return iceberg.Schema{}, iceberg.PartitionSpec{}, fmt.Errorf("%w: partition %s: transform %s cannot be applied to %s column %q",
+				ErrPartitionColumn, rule, rule.Transform, field.Type, rule.Column)
+		}
+
+		name := partitionFieldName(rule)
+		if names[name] {
+			return iceberg.Schema{}, iceberg.PartitionSpec{}, fmt.Errorf("partition %s: duplicate partition field %q", rule, name)
+		}
+		names[name] = true
+
+		spec.Fields = append(spec.Fields, iceberg.PartitionField{
+			SourceID:  field.ID,
+			FieldID:   partitionFieldIDStart + i,
+			Name:      name,
+			Transform: rule.Transform,
+		})
+	}
+	return s, spec, nil
+}
+
+// checkTransform validates a transform name and its parameter.
+func checkTransform(transform string) error {
+	name, param, hasParam := strings.Cut(transform, "[")
+	switch name {
+	case "identity", "year", "month", "day", "hour":
+		if hasParam {
+			return fmt.Errorf("transform %s takes no parameter", name)
+		}
+		return nil
+	case "bucket", "truncate":
+		n, err := strconv.Atoi(strings.TrimSuffix(param, "]"))
+		if !hasParam || !strings.HasSuffix(param, "]") || err != nil || n <= 0 {
+			return fmt.Errorf("transform %s needs a positive parameter, as in %s[16]", name, name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown transform %q: must be identity, year, month, day, hour, bucket[N] or truncate[W]", name)
+}
+
+// isTimeTransform reports whether a transform extracts a time unit.
+func isTimeTransform(transform string) bool {
+	switch transform {
+	case "year", "month", "day", "hour":
+		return true
+	}
+	return false
+}
+
+// transformSupports reports whether a transform can be applied to a column
+// of type t.
+func transformSupports(transform string, t iceberg.Type) bool {
+	name, _, _ := strings.Cut(transform, "[")
+	switch name {
+	case "year", "month", "day":
+		return t == iceberg.TypeDate || t == iceberg.TypeTimestamp || t == iceberg.TypeTimestampTZ
+	case "hour":
+		return t == iceberg.TypeTimestamp || t == iceberg.TypeTimestampTZ
+	case "bucket":
+		return t != iceberg.TypeBoolean && t != iceberg.TypeFloat && t != iceberg.TypeDouble
+	case "truncate":
+		_, _, decimal := t.Decimal()
+		return decimal || t == iceberg.TypeInt || t == iceberg.TypeLong || t == iceberg.TypeString || t == iceberg.TypeBinary
+	}
+	return true
+}
+
+// partitionFieldName names a partition field the way Iceberg does: the
+// column name for identity, otherwise the column and transform.
+func partitionFieldName(rule PartitionRule) string {
+	name, _, _ := strings.Cut(rule.Transform, "[")
+	switch name {
+	case "identity":
+		return rule.Column
+	case "truncate":
+		return rule.Column + "_trunc"
+	}
+	return rule.Column + "_" + name
+}
+
+// CheckPartitionColumns returns an error wrapping ErrPartitionColumn if a
+// rule references a column that is neither among the given Iceberg column
+// names nor a CDC system column. It lets partitioning be checked against
+// source tables before any Iceberg table is created.
+func CheckPartitionColumns(rules []PartitionRule, columns []string) error {
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+	for _, rule := range rules {
+		if !known[rule.Column] && !isSystemColumn(rule.Column) {
+			return fmt.Errorf("%w: partition %s: column %q does not exist", ErrPartitionColumn, rule, rule.Column)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/janovincze/philotes/internal/cdc"
@@ -11,6 +12,10 @@ import (
 type Builder struct {
 	// NextFieldID is the next available field ID.
 	NextFieldID int
+
+	// Mapper maps source column names to Iceberg column names. A nil Mapper
+	// keeps names unchanged.
+	Mapper *ColumnMapper
 }
 
 // NewBuilder creates a new schema builder.
@@ -23,12 +28,51 @@ func NewBuilder() *Builder {
 // BuildFromEvents builds an Iceberg schema from a set of CDC events.
 // It analyzes the event data to determine column names and types.
 func (b *Builder) BuildFromEvents(events []cdc.Event) iceberg.Schema {
+	return b.buildSchema(b.inferColumns(events))
+}
+
+// BuildFromEventsWithTypes builds an Iceberg schema from a set of CDC events
+// and the declared Iceberg types of source columns, keyed by source column
+// name. Declared columns take their declared type, widened as Iceberg allows
+// to store the events' values; values no allowed type can store return an
+// error wrapping ErrIncompatibleChange.
+func (b *Builder) BuildFromEventsWithTypes(events []cdc.Event, declared map[string]iceberg.Type) (iceberg.Schema, error) {
+	columns := b.inferColumns(events)
+
+	for column, t := range declared {
+		name := b.Mapper.Name(column)
+		if _, ok := columns[name]; !ok {
+			continue
+		}
+		for _, event := range events {
+			for _, row := range []map[string]any{event.After, event.Before} {
+				value, ok := row[column]
+				if !ok || value == nil {
+					continue
+				}
+				widened, ok := fitValue(t, value)
+				if !ok {
+					return iceberg.Schema{}, fmt.Errorf("%w: column %q has type %s and cannot store %s value %v",
+						ErrIncompatibleChange, name, t, InferTypeFromValue(value), value)
+				}
+				t = widened
+			}
+		}
+		columns[name] = t
+	}
+
+	return b.buildSchema(columns), nil
+}
+
+// inferColumns infers the Iceberg column names and types of events.
+func (b *Builder) inferColumns(events []cdc.Event) map[string]iceberg.Type {
 	// Collect all column names and their inferred types
 	columns := make(map[string]iceberg.Type)
 
 	for _, event := range events {
 		// Analyze after data (for INSERT/UPDATE)
-		for name, value := range event.After {
+		for column, value := range event.After {
+			name := b.Mapper.Name(column)
 			existingType, exists := columns[name]
 			inferredType := InferTypeFromValue(value)
 
@@ -41,21 +85,22 @@ func (b *Builder) BuildFromEvents(events []cdc.Event) iceberg.Schema {
 		}
 
 		// Also check before data (for UPDATE/DELETE)
-		for name, value := range event.Before {
+		for column, value := range event.Before {
+			name := b.Mapper.Name(column)
 			if _, exists := columns[name]; !exists {
 				columns[name] = InferTypeFromValue(value)
 			}
 		}
 	}
 
-	return b.buildSchema(columns)
+	return columns
 }
 
 // BuildFromData builds an Iceberg schema from a single data map.
 func (b *Builder) BuildFromData(data map[string]any) iceberg.Schema {
 	columns := make(map[string]iceberg.Type)
-	for name, value := range data {
-		columns[name] = InferTypeFromValue(value)
+	for column, value := range data {
+		columns[b.Mapper.Name(column)] = InferTypeFromValue(value)
 	}
 	return b.buildSchema(columns)
 }
@@ -1,6 +1,11 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,7 +30,11 @@ func TestMapPostgresToIceberg(t *testing.T) {
 		{"float4", iceberg.TypeFloat},
 		{"double precision", iceberg.TypeDouble},
 		{"float8", iceberg.TypeDouble},
-		{"numeric", iceberg.TypeDouble},
+		{"numeric", iceberg.DecimalType(38, 18)},
+		{"numeric(38,9)", iceberg.DecimalType(38, 9)},
+		{"decimal(10, 2)", iceberg.DecimalType(10, 2)},
+		{"numeric(12)", iceberg.DecimalType(12, 0)},
+		{"numeric(50,10)", iceberg.TypeString},
 
 		// Boolean
 		{"boolean", iceberg.TypeBoolean},
@@ -40,8 +49,8 @@ func TestMapPostgresToIceberg(t *testing.T) {
 		// Date/time
 		{"date", iceberg.TypeDate},
 		{"timestamp", iceberg.TypeTimestamp},
-		{"timestamptz", iceberg.TypeTimestamp},
-		{"timestamp with time zone", iceberg.TypeTimestamp},
+		{"timestamptz", iceberg.TypeTimestampTZ},
+		{"timestamp with time zone", iceberg.TypeTimestampTZ},
 
 		// Binary
 		{"bytea", iceberg.TypeBinary},
@@ -88,6 +97,9 @@ func TestInferTypeFromValue(t *testing.T) {
 		{"bytes", []byte{1, 2, 3}, iceberg.TypeBinary},
 		{"map", map[string]any{}, iceberg.TypeString},
 		{"slice", []string{}, iceberg.TypeString},
+		{"json object", map[string]any{"a": []any{json.Number("1")}}, iceberg.TypeString},
+		{"json array", []any{"a", map[string]any{}}, iceberg.TypeString},
+		{"json number", json.Number("1"), iceberg.TypeString},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +211,110 @@ func TestDefaultPartitionSpec(t *testing.T) {
 	}
 }
 
+func TestParsePartitionRule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    PartitionRule
+		wantErr bool
+	}{
+		{"day:event_ts", PartitionRule{Transform: "day", Column: "event_ts"}, false},
+		{" identity : region ", PartitionRule{Transform: "identity", Column: "region"}, false},
+		{"bucket[16]:customer_id", PartitionRule{Transform: "bucket[16]", Column: "customer_id"}, false},
+		{"truncate[4]:sku", PartitionRule{Transform: "truncate[4]", Column: "sku"}, false},
+		{"day", PartitionRule{}, true},
+		{"week:event_ts", PartitionRule{}, true},
+		{"bucket:customer_id", PartitionRule{}, true},
+		{"bucket[0]:customer_id", PartitionRule{}, true},
+		{"day[2]:event_ts", PartitionRule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePartitionRule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePartitionRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePartitionRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTablePartitions(t *testing.T) {
+	got, err := ParseTablePartitions([]string{"public.orders=day:created_at;identity:region"})
+	if err != nil {
+		t.Fatalf("ParseTablePartitions() error = %v", err)
+	}
+	want := map[string][]PartitionRule{
+		"public.orders": {{Transform: "day", Column: "created_at"}, {Transform: "identity", Column: "region"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTablePartitions() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseTablePartitions([]string{"day:created_at"}); err == nil {
+		t.Error("ParseTablePartitions() without a table should fail")
+	}
+}
+
+func TestBuildPartitionSpec(t *testing.T) {
+	s := iceberg.Schema{
+		Fields: []iceberg.Field{
+			{ID: 1, Name: "customer_id", Type: iceberg.TypeLong},
+			{ID: 2, Name: "region", Type: iceberg.TypeString},
+			{ID: 3, Name: "event_ts", Type: iceberg.TypeString},
+			{ID: 4, Name: "active", Type: iceberg.TypeBoolean},
+		},
+	}
+	rules := []PartitionRule{
+		{Transform: "day", Column: "event_ts"},
+		{Transform: "identity", Column: "region"},
+		{Transform: "bucket[16]", Column: "customer_id"},
+	}
+
+	got, spec, err := BuildPartitionSpec(s, rules)
+	if err != nil {
+		t.Fatalf("BuildPartitionSpec() error = %v", err)
+	}
+	want := []iceberg.PartitionField{
+		{SourceID: 3, FieldID: 1000, Name: "event_ts_day", Transform: "day"},
+		{SourceID: 2, FieldID: 1001, Name: "region", Transform: "identity"},
+		{SourceID: 1, FieldID: 1002, Name: "customer_id_bucket", Transform: "bucket[16]"},
+	}
+	if !reflect.DeepEqual(spec.Fields, want) {
+		t.Errorf("BuildPartitionSpec() fields = %+v, want %+v", spec.Fields, want)
+	}
+	if got.Fields[2].Type != iceberg.TypeTimestamp {
+		t.Errorf("event_ts type = %s, want timestamp", got.Fields[2].Type)
+	}
+	if s.Fields[2].Type != iceberg.TypeString {
+		t.Error("BuildPartitionSpec() modified the input schema")
+	}
+
+	for _, rule := range []PartitionRule{
+		{Transform: "day", Column: "missing"},
+		{Transform: "day", Column: "active"},
+	} {
+		_, _, err := BuildPartitionSpec(s, []PartitionRule{rule})
+		if !errors.Is(err, ErrPartitionColumn) {
+			t.Errorf("BuildPartitionSpec(%s) error = %v, want ErrPartitionColumn", rule, err)
+		}
+	}
+}
+
+func TestCheckPartitionColumns(t *testing.T) {
+	columns := []string{"id", "region"}
+
+	if err := CheckPartitionColumns([]PartitionRule{{Transform: "identity", Column: "region"}, {Transform: "day", Column: "_cdc_timestamp"}}, columns); err != nil {
+		t.Errorf("CheckPartitionColumns() error = %v", err)
+	}
+	err := CheckPartitionColumns([]PartitionRule{{Transform: "day", Column: "event_ts"}}, columns)
+	if !errors.Is(err, ErrPartitionColumn) || !strings.Contains(err.Error(), "event_ts") {
+		t.Errorf("CheckPartitionColumns() error = %v, want ErrPartitionColumn naming event_ts", err)
+	}
+}
+
 func TestGetFieldByName(t *testing.T) {
 	schema := iceberg.Schema{
 		Fields: []iceberg.Field{
@@ -221,3 +337,568 @@ func TestGetFieldByName(t *testing.T) {
 		t.Error("Expected nil for nonexistent field")
 	}
 }
+
+func TestColumnMapperCasePolicies(t *testing.T) {
+	columns := []string{"CustomerID", "orderDate", "HTTPStatus", "Order Total", "already_snake", "Field2Name"}
+
+	tests := []struct {
+		policy CasePolicy
+		want   []string
+	}{
+		{CaseIdentity, []string{"CustomerID", "orderDate", "HTTPStatus", "Order Total", "already_snake", "Field2Name"}},
+		{CaseLower, []string{"customerid", "orderdate", "httpstatus", "order total", "already_snake", "field2name"}},
+		{CaseSnake, []string{"customer_id", "order_date", "http_status", "order_total", "already_snake", "field2_name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			mapper, err := NewColumnMapper(tt.policy, nil)
+			if err != nil {
+				t.Fatalf("NewColumnMapper() error = %v", err)
+			}
+			for i, column := range columns {
+				if got := mapper.Name(column); got != tt.want[i] {
+					t.Errorf("Name(%q) = %q, want %q", column, got, tt.want[i])
+				}
+			}
+			if err := mapper.Check(columns); err != nil {
+				t.Errorf("Check() error = %v", err)
+			}
+		})
+	}
+
+	if _, err := ParseCasePolicy("camelCase"); err == nil {
+		t.Error("expected error for unknown case policy")
+	}
+}
+
+func TestColumnMapperRenames(t *testing.T) {
+	renames, err := ParseRenames([]string{"CustID:customer_id", " Notes : remarks "})
+	if err != nil {
+		t.Fatalf("ParseRenames() error = %v", err)
+	}
+	mapper, err := NewColumnMapper(CaseLower, renames)
+	if err != nil {
+		t.Fatalf("NewColumnMapper() error = %v", err)
+	}
+
+	row := mapper.MapRow(map[string]any{"CustID": int64(7), "Notes": "vip", "Region": "EU"})
+	want := map[string]any{"customer_id": int64(7), "remarks": "vip", "region": "EU"}
+	if len(row) != len(want) {
+		t.Fatalf("MapRow() = %v, want %v", row, want)
+	}
+	for k, v := range want {
+		if row[k] != v {
+			t.Errorf("MapRow()[%q] = %v, want %v", k, row[k], v)
+		}
+	}
+
+	for _, pairs := range [][]string{{"missing-target"}, {"a:b", "a:c"}} {
+		if _, err := ParseRenames(pairs); err == nil {
+			t.Errorf("ParseRenames(%v) expected error", pairs)
+		}
+	}
+	if _, err := NewColumnMapper(CaseIdentity, map[string]string{"ts": "_cdc_timestamp"}); err == nil {
+		t.Error("expected error renaming onto a CDC system column")
+	}
+}
+
+func TestColumnMapperRejectsCollisions(t *testing.T) {
+	mapper, err := NewColumnMapper(CaseSnake, nil)
+	if err != nil {
+		t.Fatalf("NewColumnMapper() error = %v", err)
+	}
+
+	err = mapper.Check([]string{"id", "userId", "user_id"})
+	if !errors.Is(err, ErrColumnCollision) {
+		t.Fatalf("Check() error = %v, want ErrColumnCollision", err)
+	}
+	for _, name := range []string{`"userId"`, `"user_id"`} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error to name %s, got %v", name, err)
+		}
+	}
+
+	// Explicit renames can also collide
+	mapper, err = NewColumnMapper(CaseIdentity, map[string]string{"legacy_email": "email"})
+	if err != nil {
+		t.Fatalf("NewColumnMapper() error = %v", err)
+	}
+	events := []cdc.Event{{After: map[string]any{"email": "a@example.com", "legacy_email": "b@example.com"}}}
+	if err := mapper.CheckEvents(events); !errors.Is(err, ErrColumnCollision) {
+		t.Errorf("CheckEvents() error = %v, want ErrColumnCollision", err)
+	}
+}
+
+func TestBuilderAppliesColumnMapper(t *testing.T) {
+	mapper, err := NewColumnMapper(CaseSnake, map[string]string{"Notes": "remarks"})
+	if err != nil {
+		t.Fatalf("NewColumnMapper() error = %v", err)
+	}
+	builder := NewBuilder()
+	builder.Mapper = mapper
+
+	schema := builder.BuildFromEvents([]cdc.Event{
+		{Operation: cdc.OperationInsert, After: map[string]any{"CustomerID": int64(1), "Notes": "x"}},
+		{Operation: cdc.OperationDelete, Before: map[string]any{"CustomerID": int64(2), "createdAt": "2026-01-01"}},
+	})
+
+	for _, name := range []string{"customer_id", "remarks", "created_at"} {
+		if GetFieldByName(schema, name) == nil {
+			t.Errorf("expected column %q in schema", name)
+		}
+	}
+	if GetFieldByName(schema, "CustomerID") != nil {
+		t.Error("expected source column name to be mapped")
+	}
+}
+
+// wideEvent returns an insert into a synthetic table with an id key and
+// columns c0000 to c<columns-1>.
+func wideEvent(columns int) cdc.Event {
+	after := map[string]any{"id": 1}
+	for i := range columns {
+		after[fmt.Sprintf("c%04d", i)] = i
+	}
+	return cdc.Event{
+		Schema:     "public",
+		Table:      "wide",
+		Operation:  cdc.OperationInsert,
+		After:      after,
+		KeyColumns: []string{"id"},
+	}
+}
+
+func newTestWidthGuard(t *testing.T, strategy WidthStrategy, maxColumns, maxRowBytes int) *WidthGuard {
+	t.Helper()
+	g, err := NewWidthGuard(WidthLimits{Strategy: strategy, MaxColumns: maxColumns, MaxRowBytes: maxRowBytes})
+	if err != nil {
+		t.Fatalf("NewWidthGuard() error = %v", err)
+	}
+	return g
+}
+
+func TestWidthGuard_Reject(t *testing.T) {
+	g := newTestWidthGuard(t, WidthReject, 100, 0)
+
+	_, err := g.Apply("public.wide", []cdc.Event{wideEvent(2000)})
+	if !errors.Is(err, ErrTableTooWide) {
+		t.Fatalf("Apply() error = %v, want ErrTableTooWide", err)
+	}
+	if !strings.Contains(err.Error(), "PHILOTES_ICEBERG_WIDE_TABLE_STRATEGY") {
+		t.Errorf("error %q should explain how to proceed", err)
+	}
+
+	columns := make([]string, 2001)
+	if err := g.Check("public.wide", columns, nil); !errors.Is(err, ErrTableTooWide) {
+		t.Errorf("Check() error = %v, want ErrTableTooWide at pipeline start", err)
+	}
+
+	parts, err := g.Apply("public.narrow", []cdc.Event{wideEvent(10)})
+	if err != nil || len(parts) != 1 || parts[0].Suffix != "" {
+		t.Errorf("Apply() on a narrow table = %+v, %v, want it unchanged", parts, err)
+	}
+}
+
+func TestWidthGuard_Split(t *testing.T) {
+	g := newTestWidthGuard(t, WidthSplit, 100, 0)
+
+	parts, err := g.Apply("public.wide", []cdc.Event{wideEvent(2000)})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// 99 columns per part next to the repeated key
+	if len(parts) != 21 {
+		t.Fatalf("parts = %d, want 21", len(parts))
+	}
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		if want := fmt.Sprintf("_part%d", i+1); part.Suffix != want {
+			t.Errorf("part %d suffix = %q, want %q", i, part.Suffix, want)
+		}
+		row := part.Events[0].After
+		if len(row) > 100 {
+			t.Errorf("part %s has %d columns, want at most 100", part.Suffix, len(row))
+		}
+		if row["id"] != 1 {
+			t.Errorf("part %s lacks the key column", part.Suffix)
+		}
+		for column := range row {
+			if column != "id" && seen[column] {
+				t.Errorf("column %s is in more than one part", column)
+			}
+			seen[column] = true
+		}
+	}
+	if len(seen) != 2001 {
+		t.Errorf("parts hold %d distinct columns, want all 2001", len(seen))
+	}
+
+	// A later batch keeps columns in the same parts
+	again, err := g.Apply("public.wide", []cdc.Event{wideEvent(2000)})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !reflect.DeepEqual(again[3].Events[0].After, parts[3].Events[0].After) {
+		t.Error("column groups changed between batches")
+	}
+
+	layouts := g.Layouts()
+	if len(layouts) != 1 || layouts[0].Strategy != WidthSplit || len(layouts[0].Parts) != 21 {
+		t.Errorf("Layouts() = %+v, want one split table with 21 parts", layouts)
+	}
+}
+
+func TestWidthGuard_Restore(t *testing.T) {
+	g := newTestWidthGuard(t, WidthSplit, 100, 0)
+
+	// Columns assigned before a restart, in an order Apply would not choose
+	var first []string
+	for i := 100; i < 199; i++ {
+		first = append(first, fmt.Sprintf("c%04d", i))
+	}
+	g.Restore("public.wide", [][]string{append(first, "id", "_cdc_op"), {"id", "c0000"}}, []string{"id"})
+
+	parts, err := g.Apply("public.wide", []cdc.Event{wideEvent(300)})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(parts) != 4 {
+		t.Fatalf("parts = %d, want 4", len(parts))
+	}
+	if _, ok := parts[0].Events[0].After["c0100"]; !ok {
+		t.Error("restored column c0100 moved out of _part1")
+	}
+	if _, ok := parts[1].Events[0].After["c0000"]; !ok {
+		t.Error("restored column c0000 moved out of _part2")
+	}
+	if len(parts[1].Events[0].After) != 100 {
+		t.Errorf("_part2 has %d columns, want new columns to fill it to 100", len(parts[1].Events[0].After))
+	}
+}
+
+func TestWidthGuard_Overflow(t *testing.T) {
+	g := newTestWidthGuard(t, WidthOverflow, 100, 0)
+
+	parts, err := g.Apply("public.wide", []cdc.Event{wideEvent(2000)})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(parts) != 1 || parts[0].Suffix != "" {
+		t.Fatalf("parts = %+v, want the table itself", parts)
+	}
+
+	row := parts[0].Events[0].After
+	if len(row) != 100 {
+		t.Errorf("row has %d columns, want 100", len(row))
+	}
+	if row["id"] != 1 {
+		t.Error("the key column must not overflow")
+	}
+
+	var overflow map[string]any
+	if err := json.Unmarshal([]byte(row[OverflowColumn].(string)), &overflow); err != nil {
+		t.Fatalf("decode %s: %v", OverflowColumn, err)
+	}
+	if len(overflow)+len(row)-1 != 2001 {
+		t.Errorf("%d overflow and %d regular columns, want 2001 in total", len(overflow), len(row)-1)
+	}
+	if overflow["c1999"] != float64(1999) {
+		t.Errorf("overflow c1999 = %v, want 1999", overflow["c1999"])
+	}
+
+	layouts := g.Layouts()
+	if len(layouts) != 1 || layouts[0].OverflowColumns != len(overflow) {
+		t.Errorf("Layouts() = %+v, want %d overflow columns", layouts, len(overflow))
+	}
+}
+
+func TestWidthGuard_RowTooLarge(t *testing.T) {
+	g := newTestWidthGuard(t, WidthReject, 0, 1024)
+
+	event := wideEvent(1)
+	event.After["payload"] = strings.Repeat("x", 2048)
+	if _, err := g.Apply("public.wide", []cdc.Event{event}); !errors.Is(err, ErrRowTooLarge) {
+		t.Fatalf("Apply() error = %v, want ErrRowTooLarge", err)
+	}
+}
+
+func TestBuilderEvolve(t *testing.T) {
+	current := iceberg.Schema{
+		SchemaID: 2,
+		Fields: []iceberg.Field{
+			{ID: 1, Name: "id", Type: iceberg.TypeInt, Required: true},
+			{ID: 2, Name: "price", Type: iceberg.TypeFloat},
+			{ID: 3, Name: "name", Type: iceberg.TypeString},
+			{ID: 4, Name: "_cdc_operation", Type: iceberg.TypeString, Required: true},
+		},
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		// JSON-decoded whole numbers fit int columns
+		events := []cdc.Event{{After: map[string]any{"id": float64(7), "price": float32(1.5), "name": "a"}}}
+		evo, err := NewBuilder().Evolve(current, 4, events)
+		if err != nil {
+			t.Fatalf("Evolve() error = %v", err)
+		}
+		if evo.Changed() || evo.Schema.SchemaID != 2 {
+			t.Errorf("Evolve() = %+v, want no change", evo)
+		}
+	})
+
+	t.Run("adds, promotes and relaxes", func(t *testing.T) {
+		events := []cdc.Event{
+			{After: map[string]any{"id": int64(1) << 40, "price": 9.99, "email": "a@example.com"}},
+			{After: map[string]any{"id": nil, "notes": nil}},
+		}
+		evo, err := NewBuilder().Evolve(current, 10, events)
+		if err != nil {
+			t.Fatalf("Evolve() error = %v", err)
+		}
+
+		if evo.Schema.SchemaID != 3 || evo.LastColumnID != 12 {
+			t.Errorf("schema ID %d and last column ID %d, want 3 and 12", evo.Schema.SchemaID, evo.LastColumnID)
+		}
+		wantAdded := []iceberg.Field{
+			{ID: 11, Name: "email", Type: iceberg.TypeString},
+			{ID: 12, Name: "notes", Type: iceberg.TypeString},
+		}
+		if !reflect.DeepEqual(evo.Added, wantAdded) {
+			t.Errorf("Added = %+v, want %+v", evo.Added, wantAdded)
+		}
+		wantPromoted := []TypePromotion{
+			{Column: "id", From: iceberg.TypeInt, To: iceberg.TypeLong},
+			{Column: "price", From: iceberg.TypeFloat, To: iceberg.TypeDouble},
+		}
+		if !reflect.DeepEqual(evo.Promoted, wantPromoted) {
+			t.Errorf("Promoted = %+v, want %+v", evo.Promoted, wantPromoted)
+		}
+		if !reflect.DeepEqual(evo.Relaxed, []string{"id"}) {
+			t.Errorf("Relaxed = %v, want [id]", evo.Relaxed)
+		}
+
+		// Field IDs of existing columns never change
+		id := GetFieldByName(evo.Schema, "id")
+		if id == nil || id.ID != 1 || id.Type != iceberg.TypeLong || id.Required {
+			t.Errorf("evolved id field = %+v", id)
+		}
+		if current.Fields[0].Type != iceberg.TypeInt {
+			t.Error("Evolve() must not modify the current schema")
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		tests := []struct {
+			column string
+			value  any
+		}{
+			{"id", "not a number"},
+			{"id", 1.5},
+			{"price", true},
+		}
+		for _, tt := range tests {
+			events := []cdc.Event{{After: map[string]any{tt.column: tt.value}}}
+			_, err := NewBuilder().Evolve(current, 4, events)
+			if !errors.Is(err, ErrIncompatibleChange) {
+				t.Errorf("Evolve(%s=%v) error = %v, want ErrIncompatibleChange", tt.column, tt.value, err)
+			} else if !strings.Contains(err.Error(), tt.column) {
+				t.Errorf("error %q does not name column %q", err, tt.column)
+			}
+		}
+	})
+}
+
+func TestDecimalMapping_MapNumeric(t *testing.T) {
+	mapping := DecimalMapping{DefaultPrecision: 30, DefaultScale: 6}
+	tests := []struct {
+		pgType  string
+		want    iceberg.Type
+		wantErr bool
+	}{
+		{"numeric(38,9)", iceberg.DecimalType(38, 9), false},
+		{"NUMERIC(10, 2)", iceberg.DecimalType(10, 2), false},
+		{"decimal(5)", iceberg.DecimalType(5, 0), false},
+		{"numeric", iceberg.DecimalType(30, 6), false},
+		{"numeric(39,2)", "", true},
+		{"numeric(1000)", "", true},
+		{"numeric(5,-2)", "", true},
+		{"numeric(2,5)", "", true},
+	}
+	for _, tt := range tests {
+		got, err := mapping.MapNumeric(tt.pgType)
+		if tt.wantErr {
+			if !errors.Is(err, ErrIncompatibleChange) {
+				t.Errorf("MapNumeric(%q) error = %v, want ErrIncompatibleChange", tt.pgType, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("MapNumeric(%q) = %q, %v; want %q", tt.pgType, got, err, tt.want)
+		}
+	}
+
+	_, err := mapping.ColumnTypes(map[string]string{"id": "integer", "balance": "numeric(40,2)"})
+	if !errors.Is(err, ErrIncompatibleChange) || !strings.Contains(err.Error(), "balance") {
+		t.Errorf("ColumnTypes() error = %v, want ErrIncompatibleChange naming balance", err)
+	}
+
+	for _, invalid := range []DecimalMapping{{0, 0}, {39, 2}, {10, 11}, {10, -1}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", invalid)
+		}
+	}
+	if err := DefaultDecimalMapping().Validate(); err != nil {
+		t.Errorf("DefaultDecimalMapping().Validate() = %v", err)
+	}
+}
+
+func TestBuilderDecimals_RoundTrip(t *testing.T) {
+	amounts := []string{
+		"12345678901234567890123456789.123456789",
+		"-99999999999999999999999999999.999999999",
+		"0.000000001",
+		"100.500000000",
+	}
+	declared, err := DefaultDecimalMapping().ColumnTypes(map[string]string{
+		"id":     "bigint",
+		"amount": "numeric(38,9)",
+	})
+	if err != nil {
+		t.Fatalf("ColumnTypes() error = %v", err)
+	}
+
+	// Events reach the writer through the buffer as JSON
+	var events []cdc.Event
+	for i, amount := range amounts {
+		encoded, err := json.Marshal(cdc.Event{After: map[string]any{"id": i, "amount": amount}})
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var event cdc.Event
+		if err := json.Unmarshal(encoded, &event); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		events = append(events, event)
+	}
+
+	built, err := NewBuilder().BuildFromEventsWithTypes(events, declared)
+	if err != nil {
+		t.Fatalf("BuildFromEventsWithTypes() error = %v", err)
+	}
+	if f := GetFieldByName(built, "amount"); f == nil || f.Type != iceberg.DecimalType(38, 9) {
+		t.Fatalf("amount field = %+v, want decimal(38,9)", f)
+	}
+
+	evo, err := NewBuilder().EvolveWithTypes(built, len(built.Fields), events, declared)
+	if err != nil || evo.Changed() {
+		t.Fatalf("EvolveWithTypes() = %+v, %v; want no change", evo, err)
+	}
+
+	// Row data is written as JSON; the values must come back exactly
+	for i, event := range events {
+		row, err := json.Marshal(event.After)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(row, &decoded); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if decoded["amount"] != amounts[i] {
+			t.Errorf("amount = %v, want %s", decoded["amount"], amounts[i])
+		}
+	}
+}
+
+func TestBuilderDecimals_Evolve(t *testing.T) {
+	current := iceberg.Schema{
+		SchemaID: 1,
+		Fields: []iceberg.Field{
+			{ID: 1, Name: "price", Type: "decimal(10, 2)"},
+			{ID: 2, Name: "legacy", Type: iceberg.TypeDouble},
+		},
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		events := []cdc.Event{{After: map[string]any{"price": "12345678.90", "legacy": "1.25"}}}
+		evo, err := NewBuilder().Evolve(current, 2, events)
+		if err != nil || evo.Changed() {
+			t.Errorf("Evolve() = %+v, %v; want no change", evo, err)
+		}
+	})
+
+	t.Run("widens the precision", func(t *testing.T) {
+		events := []cdc.Event{{After: map[string]any{"price": "1234567890.50"}}}
+		evo, err := NewBuilder().Evolve(current, 2, events)
+		if err != nil {
+			t.Fatalf("Evolve() error = %v", err)
+		}
+		want := []TypePromotion{{Column: "price", From: "decimal(10, 2)", To: iceberg.DecimalType(12, 2)}}
+		if !reflect.DeepEqual(evo.Promoted, want) {
+			t.Errorf("Promoted = %+v, want %+v", evo.Promoted, want)
+		}
+	})
+
+	t.Run("adds declared columns", func(t *testing.T) {
+		events := []cdc.Event{{After: map[string]any{"fee": "0.0001"}}}
+		declared := map[string]iceberg.Type{"fee": iceberg.DecimalType(38, 18)}
+		evo, err := NewBuilder().EvolveWithTypes(current, 2, events, declared)
+		if err != nil {
+			t.Fatalf("EvolveWithTypes() error = %v", err)
+		}
+		want := []iceberg.Field{{ID: 3, Name: "fee", Type: iceberg.DecimalType(38, 18)}}
+		if !reflect.DeepEqual(evo.Added, want) {
+			t.Errorf("Added = %+v, want %+v", evo.Added, want)
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		for _, value := range []any{"1.005", "NaN", "12345678901234567890123456789012345678.9", "abc", true} {
+			events := []cdc.Event{{After: map[string]any{"price": value}}}
+			_, err := NewBuilder().Evolve(current, 2, events)
+			if !errors.Is(err, ErrIncompatibleChange) || !strings.Contains(err.Error(), "price") {
+				t.Errorf("Evolve(price=%v) error = %v, want ErrIncompatibleChange naming price", value, err)
+			}
+		}
+
+		events := []cdc.Event{{After: map[string]any{"fee": "0.5"}}}
+		declared := map[string]iceberg.Type{"fee": iceberg.DecimalType(38, 0)}
+		if _, err := NewBuilder().BuildFromEventsWithTypes(events, declared); !errors.Is(err, ErrIncompatibleChange) {
+			t.Errorf("BuildFromEventsWithTypes() error = %v, want ErrIncompatibleChange", err)
+		}
+	})
+}
+
+func TestTimestampColumnTypes(t *testing.T) {
+	pgTypes := map[string]string{
+		"id":         "bigint",
+		"created_at": "timestamp without time zone",
+		"paid_at":    "timestamp(3) with time zone",
+		"due_on":     "date",
+	}
+
+	got := TimestampColumnTypes(pgTypes, false)
+	want := map[string]iceberg.Type{"created_at": iceberg.TypeTimestamp, "paid_at": iceberg.TypeTimestampTZ}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TimestampColumnTypes(utc=false) = %v, want %v", got, want)
+	}
+
+	got = TimestampColumnTypes(pgTypes, true)
+	want = map[string]iceberg.Type{"created_at": iceberg.TypeTimestampTZ, "paid_at": iceberg.TypeTimestampTZ}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TimestampColumnTypes(utc=true) = %v, want %v", got, want)
+	}
+
+	// Declared timestamp columns are added with their type, nulls included
+	events := []cdc.Event{{After: map[string]any{"created_at": nil, "paid_at": "2024-03-10T07:00:00.000000Z"}}}
+	built, err := NewBuilder().BuildFromEventsWithTypes(events, got)
+	if err != nil {
+		t.Fatalf("BuildFromEventsWithTypes() error = %v", err)
+	}
+	for _, name := range []string{"created_at", "paid_at"} {
+		if f := GetFieldByName(built, name); f == nil || f.Type != iceberg.TypeTimestampTZ {
+			t.Errorf("%s field = %+v, want timestamptz", name, f)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package schema
 import (
 	"strings"
 
+	"github.com/janovincze/philotes/internal/cdc"
 	"github.com/janovincze/philotes/internal/iceberg"
 )
 
@@ -25,8 +26,6 @@ var PostgresTypeMapping = map[string]iceberg.Type{
 	"float4":           iceberg.TypeFloat,
 	"double precision": iceberg.TypeDouble,
 	"float8":           iceberg.TypeDouble,
-	"numeric":          iceberg.TypeDouble,
-	"decimal":          iceberg.TypeDouble,
 
 	// Boolean
 	"boolean": iceberg.TypeBoolean,
@@ -46,8 +45,8 @@ var PostgresTypeMapping = map[string]iceberg.Type{
 	"time with time zone":         iceberg.TypeTime,
 	"timestamp":                   iceberg.TypeTimestamp,
 	"timestamp without time zone": iceberg.TypeTimestamp,
-	"timestamp with time zone":    iceberg.TypeTimestamp,
-	"timestamptz":                 iceberg.TypeTimestamp,
+	"timestamp with time zone":    iceberg.TypeTimestampTZ,
+	"timestamptz":                 iceberg.TypeTimestampTZ,
 
 	// Binary types
 	"bytea": iceberg.TypeBinary,
@@ -55,7 +54,8 @@ var PostgresTypeMapping = map[string]iceberg.Type{
 	// UUID
 	"uuid": iceberg.TypeUUID,
 
-	// JSON types (stored as string)
+	// JSON types (stored as string: JSON text, or nested values written into
+	// the row when objects and arrays are decoded as structs)
 	"json":  iceberg.TypeString,
 	"jsonb": iceberg.TypeString,
 
@@ -67,6 +67,8 @@ var PostgresTypeMapping = map[string]iceberg.Type{
 }
 
 // MapPostgresToIceberg converts a PostgreSQL type name to an Iceberg type.
+// numeric and decimal map to Iceberg decimals as DefaultDecimalMapping maps
+// them, or to string if no Iceberg decimal can hold them.
 func MapPostgresToIceberg(pgType string) iceberg.Type {
 	// Normalize the type name (lowercase, trim whitespace)
 	normalized := strings.ToLower(strings.TrimSpace(pgType))
@@ -76,7 +78,15 @@ func MapPostgresToIceberg(pgType string) iceberg.Type {
 		return iceberg.TypeString
 	}
 
-	// Handle varchar(n), char(n), numeric(p,s), etc.
+	if IsNumericType(normalized) {
+		t, err := DefaultDecimalMapping().MapNumeric(normalized)
+		if err != nil {
+			return iceberg.TypeString
+		}
+		return t
+	}
+
+	// Handle varchar(n), char(n), etc.
 	if idx := strings.Index(normalized, "("); idx > 0 {
 		normalized = normalized[:idx]
 	}
@@ -90,6 +100,26 @@ func MapPostgresToIceberg(pgType string) iceberg.Type {
 	return iceberg.TypeString
 }
 
+// TimestampColumnTypes returns the Iceberg types of the timestamp columns
+// among a table's column types, keyed by source column name: timestamptz
+// for columns with a time zone and timestamp for those without, or
+// timestamptz for both if utc is set. Other columns are left out.
+func TimestampColumnTypes(pgTypes map[string]string, utc bool) map[string]iceberg.Type {
+	types := make(map[string]iceberg.Type)
+	for column, pgType := range pgTypes {
+		withZone, ok := cdc.IsTimestampType(pgType)
+		if !ok {
+			continue
+		}
+		if withZone || utc {
+			types[column] = iceberg.TypeTimestampTZ
+		} else {
+			types[column] = iceberg.TypeTimestamp
+		}
+	}
+	return types
+}
+
 // InferTypeFromValue attempts to infer an Iceberg type from a Go value.
 func InferTypeFromValue(value any) iceberg.Type {
 	if value == nil {
@@ -112,7 +142,8 @@ func InferTypeFromValue(value any) iceberg.Type {
 	case []byte:
 		return iceberg.TypeBinary
 	default:
-		// For complex types (maps, slices), use string (JSON)
+		// For complex types (maps, slices, such as decoded json and jsonb
+		// documents, and their json.Number values), use string (JSON)
 		return iceberg.TypeString
 	}
 }
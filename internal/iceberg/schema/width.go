@@ -0,0 +1,475 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/janovincze/philotes/internal/cdc"
+)
+
+// WidthStrategy controls how tables with more columns than an Iceberg table
+// should practically hold are written.
+type WidthStrategy string
+
+const (
+	// WidthReject refuses to write tables that are too wide.
+	WidthReject WidthStrategy = "reject"
+
+	// WidthSplit splits a wide table into several Iceberg tables by column
+	// group. Every part repeats the key columns so parts can be joined.
+	WidthSplit WidthStrategy = "split"
+
+	// WidthOverflow keeps the first columns as Iceberg columns and stores the
+	// rest as a JSON object in OverflowColumn.
+	WidthOverflow WidthStrategy = "overflow"
+)
+
+// OverflowColumn holds the columns that do not fit a table under WidthOverflow.
+const OverflowColumn = "_overflow"
+
+var (
+	// ErrTableTooWide is returned when a table has more columns than allowed.
+	ErrTableTooWide = errors.New("table too wide")
+
+	// ErrRowTooLarge is returned when a row is larger than allowed.
+	ErrRowTooLarge = errors.New("row too large")
+)
+
+// ParseWidthStrategy parses a width strategy; an empty string means reject.
+func ParseWidthStrategy(s string) (WidthStrategy, error) {
+	switch w := WidthStrategy(s); w {
+	case "":
+		return WidthReject, nil
+	case WidthReject, WidthSplit, WidthOverflow:
+		return w, nil
+	}
+	return "", fmt.Errorf("invalid wide table strategy %q: must be reject, split or overflow", s)
+}
+
+// WidthLimits bounds the width of tables and the size of rows.
+type WidthLimits struct {
+	// Strategy is applied to tables with more than MaxColumns columns.
+	Strategy WidthStrategy
+
+	// MaxColumns is the most columns an Iceberg table may have, excluding
+	// the CDC system columns (0 = unlimited).
+	MaxColumns int
+
+	// MaxRowBytes is the largest row, as JSON, that may be written (0 = unlimited).
+	MaxRowBytes int
+}
+
+// TableLayout describes how a wide table is written.
+type TableLayout struct {
+	// Table is the source table as "schema.table".
+	Table string `json:"table"`
+
+	// Strategy is the strategy applied to the table.
+	Strategy WidthStrategy `json:"strategy"`
+
+	// Columns is the number of source columns seen.
+	Columns int `json:"columns"`
+
+	// Parts are the table suffixes a split table is written to.
+	Parts []string `json:"parts,omitempty"`
+
+	// OverflowColumns is the number of columns stored in OverflowColumn.
+	OverflowColumns int `json:"overflow_columns,omitempty"`
+}
+
+// String describes the layout for status output.
+func (l TableLayout) String() string {
+	switch l.Strategy {
+	case WidthSplit:
+		return fmt.Sprintf("%s (%d columns) split into %s", l.Table, l.Columns, strings.Join(l.Parts, ", "))
+	case WidthOverflow:
+		return fmt.Sprintf("%s (%d columns) with %d in %s", l.Table, l.Columns, l.OverflowColumns, OverflowColumn)
+	}
+	return l.Table
+}
+
+// TablePart is the share of a batch written to one Iceberg table. Events
+// line up with the events passed to WidthGuard.Apply.
+type TablePart struct {
+	// Suffix is appended to the table name; empty for the table itself.
+	Suffix string
+
+	// Events are the events restricted to the part's columns.
+	Events []cdc.Event
+}
+
+// WidthGuard detects tables and rows exceeding WidthLimits and applies the
+// configured strategy. Once a column is assigned to a split part or to the
+// overflow column it stays there, so the layout of a table is stable across
+// batches.
+type WidthGuard struct {
+	limits WidthLimits
+
+	mu      sync.Mutex
+	tables  map[string]*tableColumns
+	layouts map[string]TableLayout
+}
+
+// tableColumns is the column assignment of a wide table. A column is
+// assigned to a part index, or to -1 for the overflow column.
+type tableColumns struct {
+	assigned map[string]int
+	parts    []int
+}
+
+// NewWidthGuard creates a WidthGuard.
+func NewWidthGuard(limits WidthLimits) (*WidthGuard, error) {
+	strategy, err := ParseWidthStrategy(string(limits.Strategy))
+	if err != nil {
+		return nil, err
+	}
+	limits.Strategy = strategy
+	if limits.MaxColumns < 0 || limits.MaxRowBytes < 0 {
+		return nil, fmt.Errorf("wide table limits must not be negative")
+	}
+
+	return &WidthGuard{
+		limits:  limits,
+		tables:  make(map[string]*tableColumns),
+		layouts: make(map[string]TableLayout),
+	}, nil
+}
+
+// Enabled reports whether any limit is set.
+func (g *WidthGuard) Enabled() bool {
+	return g != nil && (g.limits.MaxColumns > 0 || g.limits.MaxRowBytes > 0)
+}
+
+// Strategy returns the configured strategy.
+func (g *WidthGuard) Strategy() WidthStrategy {
+	return g.limits.Strategy
+}
+
+// Check checks the columns of a source table at pipeline start. Under
+// WidthReject a table that is too wide returns ErrTableTooWide; otherwise
+// the table's columns are assigned to parts or the overflow column.
+func (g *WidthGuard) Check(table string, columns, keyColumns []string) error {
+	if !g.Enabled() || g.limits.MaxColumns == 0 || len(columns) <= g.limits.MaxColumns {
+		return nil
+	}
+	if g.limits.Strategy == WidthReject {
+		return g.tooWide(table, len(columns))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.assign(table, columns, keyColumns)
+}
+
+// Apply applies the limits to a batch of events of one table. Tables within
+// MaxColumns are returned as a single part unchanged. Rows larger than
+// MaxRowBytes after applying the strategy return ErrRowTooLarge.
+func (g *WidthGuard) Apply(table string, events []cdc.Event) ([]TablePart, error) {
+	if !g.Enabled() {
+		return []TablePart{{Events: events}}, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	parts := []TablePart{{Events: events}}
+	columns := batchColumns(events)
+	if _, wide := g.tables[table]; wide || (g.limits.MaxColumns > 0 && len(columns) > g.limits.MaxColumns) {
+		if g.limits.Strategy == WidthReject {
+			return nil, g.tooWide(table, len(columns))
+		}
+		if err := g.assign(table, columns, batchKeyColumns(events)); err != nil {
+			return nil, err
+		}
+		if g.limits.Strategy == WidthSplit {
+			parts = g.split(table, events)
+		} else {
+			overflowed, err := g.overflow(table, events)
+			if err != nil {
+				return nil, err
+			}
+			parts = []TablePart{{Events: overflowed}}
+		}
+	}
+
+	if g.limits.MaxRowBytes > 0 {
+		for _, part := range parts {
+			for _, event := range part.Events {
+				if err := g.checkRowSize(table, event); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return parts, nil
+}
+
+// Layouts returns the layout of every table the strategy was applied to,
+// sorted by table.
+func (g *WidthGuard) Layouts() []TableLayout {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	layouts := make([]TableLayout, 0, len(g.layouts))
+	for _, layout := range g.layouts {
+		layout.Parts = append([]string(nil), layout.Parts...)
+		layouts = append(layouts, layout)
+	}
+	sort.Slice(layouts, func(i, j int) bool { return layouts[i].Table < layouts[j].Table })
+	return layouts
+}
+
+// assign assigns columns not seen before to parts or the overflow column.
+// Key columns are part of every split part and are never overflowed. The
+// caller must hold g.mu.
+func (g *WidthGuard) assign(table string, columns, keyColumns []string) error {
+	keys := make(map[string]bool, len(keyColumns))
+	for _, key := range keyColumns {
+		keys[key] = true
+	}
+
+	perTable := g.limits.MaxColumns - len(keyColumns)
+	if perTable < 1 {
+		return fmt.Errorf("%w: %s has %d key columns, more than the %d columns allowed per table",
+			ErrTableTooWide, table, len(keyColumns), g.limits.MaxColumns)
+	}
+
+	t, ok := g.tables[table]
+	if !ok {
+		t = &tableColumns{assigned: make(map[string]int)}
+		for key := range keys {
+			t.assigned[key] = 0
+		}
+		g.tables[table] = t
+	}
+
+	var fresh []string
+	for _, column := range columns {
+		if _, ok := t.assigned[column]; !ok && !keys[column] {
+			fresh = append(fresh, column)
+		}
+	}
+	sort.Strings(fresh)
+
+	for _, column := range fresh {
+		switch g.limits.Strategy {
+		case WidthSplit:
+			if len(t.parts) == 0 || t.parts[len(t.parts)-1] == perTable {
+				t.parts = append(t.parts, 0)
+			}
+			t.assigned[column] = len(t.parts) - 1
+			t.parts[len(t.parts)-1]++
+		case WidthOverflow:
+			// Keys and the overflow column itself take a column each
+			if len(t.parts) == 0 {
+				t.parts = append(t.parts, 0)
+			}
+			if t.parts[0] < perTable-1 {
+				t.assigned[column] = 0
+				t.parts[0]++
+			} else {
+				t.assigned[column] = -1
+			}
+		}
+	}
+
+	g.updateLayout(table, t)
+	return nil
+}
+
+// Restore sets the column assignment of a table from the layout its Iceberg
+// tables already have, so columns keep their part across restarts instead of
+// being reassigned from the columns seen since. parts holds the source
+// columns stored in each split part, or, under WidthOverflow, a single
+// entry with the columns of the table. Columns not restored are assigned by
+// Apply as usual.
+func (g *WidthGuard) Restore(table string, parts [][]string, keyColumns []string) {
+	if !g.Enabled() || g.limits.Strategy == WidthReject || len(parts) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t := &tableColumns{assigned: make(map[string]int), parts: make([]int, len(parts))}
+	for _, key := range keyColumns {
+		t.assigned[key] = 0
+	}
+	for i, columns := range parts {
+		for _, column := range columns {
+			if _, ok := t.assigned[column]; ok || isSystemColumn(column) || column == OverflowColumn {
+				continue
+			}
+			t.assigned[column] = i
+			t.parts[i]++
+		}
+	}
+	g.tables[table] = t
+	g.updateLayout(table, t)
+}
+
+// updateLayout records the layout of a table's column assignment. The
+// caller must hold g.mu.
+func (g *WidthGuard) updateLayout(table string, t *tableColumns) {
+	layout := TableLayout{Table: table, Strategy: g.limits.Strategy, Columns: len(t.assigned)}
+	switch g.limits.Strategy {
+	case WidthSplit:
+		for i := range t.parts {
+			layout.Parts = append(layout.Parts, PartSuffix(i))
+		}
+	case WidthOverflow:
+		for _, part := range t.assigned {
+			if part < 0 {
+				layout.OverflowColumns++
+			}
+		}
+	}
+	g.layouts[table] = layout
+}
+
+// split restricts every event to the columns of each part. Every part gets
+// every event, with the key columns, so parts stay row-aligned.
+func (g *WidthGuard) split(table string, events []cdc.Event) []TablePart {
+	t := g.tables[table]
+	parts := make([]TablePart, len(t.parts))
+	for i := range parts {
+		parts[i] = TablePart{Suffix: PartSuffix(i), Events: make([]cdc.Event, len(events))}
+	}
+
+	for i, event := range events {
+		keys := make(map[string]bool, len(event.KeyColumns))
+		for _, key := range event.KeyColumns {
+			keys[key] = true
+		}
+		for p := range parts {
+			restricted := event
+			restricted.Before = restrictRow(event.Before, func(column string) bool {
+				return keys[column] || t.assigned[column] == p
+			})
+			restricted.After = restrictRow(event.After, func(column string) bool {
+				return keys[column] || t.assigned[column] == p
+			})
+			parts[p].Events[i] = restricted
+		}
+	}
+	return parts
+}
+
+// overflow moves the overflow columns of every event into OverflowColumn.
+func (g *WidthGuard) overflow(table string, events []cdc.Event) ([]cdc.Event, error) {
+	t := g.tables[table]
+	out := make([]cdc.Event, len(events))
+	for i, event := range events {
+		var err error
+		if event.Before, err = overflowRow(event.Before, t); err != nil {
+			return nil, err
+		}
+		if event.After, err = overflowRow(event.After, t); err != nil {
+			return nil, err
+		}
+		out[i] = event
+	}
+	return out, nil
+}
+
+// checkRowSize returns ErrRowTooLarge if an event's row exceeds MaxRowBytes.
+func (g *WidthGuard) checkRowSize(table string, event cdc.Event) error {
+	row := event.After
+	if row == nil {
+		row = event.Before
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("measure row of %s: %w", table, err)
+	}
+	if len(data) <= g.limits.MaxRowBytes {
+		return nil
+	}
+
+	hint := "exclude large columns from the publication or raise PHILOTES_ICEBERG_MAX_ROW_BYTES"
+	if g.limits.Strategy != WidthSplit {
+		hint += ", or set PHILOTES_ICEBERG_WIDE_TABLE_STRATEGY=split to spread columns over several tables"
+	}
+	return fmt.Errorf("%w: %s row at LSN %s is %d bytes, more than the %d allowed; %s",
+		ErrRowTooLarge, table, event.LSN, len(data), g.limits.MaxRowBytes, hint)
+}
+
+// tooWide returns ErrTableTooWide with guidance on how to proceed.
+func (g *WidthGuard) tooWide(table string, columns int) error {
+	return fmt.Errorf("%w: %s has %d columns, more than the %d allowed; set PHILOTES_ICEBERG_WIDE_TABLE_STRATEGY "+
+		"to split (one Iceberg table per column group) or overflow (extra columns in %s as JSON), "+
+		"or exclude columns from the publication",
+		ErrTableTooWide, table, columns, g.limits.MaxColumns, OverflowColumn)
+}
+
+// PartSuffix returns the table name suffix of split part i, counting from 0.
+func PartSuffix(i int) string {
+	return fmt.Sprintf("_part%d", i+1)
+}
+
+// restrictRow returns the columns of row that keep accepts.
+func restrictRow(row map[string]any, keep func(column string) bool) map[string]any {
+	if row == nil {
+		return nil
+	}
+	restricted := make(map[string]any)
+	for column, value := range row {
+		if keep(column) {
+			restricted[column] = value
+		}
+	}
+	return restricted
+}
+
+// overflowRow moves the overflow columns of row into OverflowColumn.
+func overflowRow(row map[string]any, t *tableColumns) (map[string]any, error) {
+	if row == nil {
+		return nil, nil
+	}
+	kept := make(map[string]any, len(row))
+	extra := make(map[string]any)
+	for column, value := range row {
+		if t.assigned[column] < 0 {
+			extra[column] = value
+		} else {
+			kept[column] = value
+		}
+	}
+	if len(extra) > 0 {
+		data, err := json.Marshal(extra)
+		if err != nil {
+			return nil, fmt.Errorf("encode overflow columns: %w", err)
+		}
+		kept[OverflowColumn] = string(data)
+	}
+	return kept, nil
+}
+
+// batchColumns returns the distinct columns of a batch, without CDC system columns.
+func batchColumns(events []cdc.Event) []string {
+	var columns []string
+	for _, column := range eventColumns(events) {
+		if !isSystemColumn(column) && column != OverflowColumn {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// batchKeyColumns returns the key columns of a batch.
+func batchKeyColumns(events []cdc.Event) []string {
+	for _, event := range events {
+		if len(event.KeyColumns) > 0 {
+			return event.KeyColumns
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@
 package iceberg
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -21,8 +22,31 @@ const (
 	TypeString    Type = "string"
 	TypeUUID      Type = "uuid"
 	TypeBinary    Type = "binary"
+
+	// TypeTimestampTZ is a timestamp with time zone, stored in UTC.
+	TypeTimestampTZ Type = "timestamptz"
 )
 
+// MaxDecimalPrecision is the largest precision of an Iceberg decimal.
+const MaxDecimalPrecision = 38
+
+// DecimalType returns the decimal type with the given precision and scale,
+// e.g. "decimal(38,9)".
+func DecimalType(precision, scale int) Type {
+	return Type(fmt.Sprintf("decimal(%d,%d)", precision, scale))
+}
+
+// Decimal returns the precision and scale of a decimal type. ok is false
+// for any other type.
+func (t Type) Decimal() (precision, scale int, ok bool) {
+	var rest string
+	n, _ := fmt.Sscanf(string(t), "decimal(%d,%d%s", &precision, &scale, &rest)
+	if n != 3 || rest != ")" {
+		return 0, 0, false
+	}
+	return precision, scale, true
+}
+
 // Field represents a field in an Iceberg schema.
 type Field struct {
 	// ID is the unique field identifier.
@@ -150,6 +174,41 @@ type TableMetadata struct {
 
 	// Snapshots is the list of snapshots.
 	Snapshots []Snapshot `json:"snapshots,omitempty"`
+
+	// Refs are the table's named branches and tags, including main.
+	Refs map[string]SnapshotRef `json:"refs,omitempty"`
+}
+
+// CurrentSchema returns the table's current schema. ok is false if the
+// metadata has no schema with the current schema ID.
+func (m *TableMetadata) CurrentSchema() (schema Schema, ok bool) {
+	for _, s := range m.Schemas {
+		if s.SchemaID == m.CurrentSchemaID {
+			return s, true
+		}
+	}
+	return Schema{}, false
+}
+
+// Snapshot reference types.
+const (
+	// RefTypeBranch is a branch, which moves as snapshots are committed to it.
+	RefTypeBranch = "branch"
+
+	// RefTypeTag is a tag, which always points at the same snapshot.
+	RefTypeTag = "tag"
+)
+
+// MainBranch is the branch queries read by default.
+const MainBranch = "main"
+
+// SnapshotRef is a named branch or tag pointing at a snapshot.
+type SnapshotRef struct {
+	// SnapshotID is the snapshot the ref points at.
+	SnapshotID int64 `json:"snapshot-id"`
+
+	// Type is RefTypeBranch or RefTypeTag.
+	Type string `json:"type"`
 }
 
 // Namespace represents an Iceberg namespace (database).
@@ -0,0 +1,23 @@
+package iceberg
+
+import "testing"
+
+func TestType_Decimal(t *testing.T) {
+	tests := []struct {
+		t                Type
+		precision, scale int
+		ok               bool
+	}{
+		{DecimalType(38, 9), 38, 9, true},
+		{"decimal(10, 2)", 10, 2, true},
+		{"decimal(10,2)x", 0, 0, false},
+		{"decimal", 0, 0, false},
+		{TypeDouble, 0, 0, false},
+	}
+	for _, tt := range tests {
+		precision, scale, ok := tt.t.Decimal()
+		if precision != tt.precision || scale != tt.scale || ok != tt.ok {
+			t.Errorf("%q.Decimal() = %d, %d, %v; want %d, %d, %v", tt.t, precision, scale, ok, tt.precision, tt.scale, tt.ok)
+		}
+	}
+}
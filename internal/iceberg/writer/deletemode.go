@@ -0,0 +1,515 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+	"github.com/janovincze/philotes/internal/iceberg/schema"
+	"github.com/janovincze/philotes/internal/metrics"
+)
+
+// DeleteMode controls how the inserts, updates and deletes of a source
+// table are materialized in its Iceberg table.
+type DeleteMode string
+
+const (
+	// DeleteModeAppendOnly appends every change as a row, with its
+	// operation in _op and its source commit time in _ts. Deleted rows stay
+	// in the table. This is the default.
+	DeleteModeAppendOnly DeleteMode = "append_only"
+
+	// DeleteModeSoft keeps the latest version of each row. A deleted row is
+	// kept as a tombstone with _deleted set and its delete time in
+	// _deleted_at.
+	DeleteModeSoft DeleteMode = "soft"
+
+	// DeleteModeHard keeps the latest version of each row and removes
+	// deleted rows.
+	DeleteModeHard DeleteMode = "hard"
+)
+
+// Columns the delete modes add to row data.
+const (
+	// ColumnOp holds the operation of a change in append-only tables.
+	ColumnOp = "_op"
+
+	// ColumnTS holds the source commit time of a change in append-only
+	// tables.
+	ColumnTS = "_ts"
+
+	// ColumnDeleted marks the tombstones of soft-deleted rows.
+	ColumnDeleted = "_deleted"
+
+	// ColumnDeletedAt holds the time a soft-deleted row was deleted.
+	ColumnDeletedAt = "_deleted_at"
+)
+
+// errNoKeyColumns is returned for the changes of a soft or hard delete
+// table that do not identify their row.
+var errNoKeyColumns = errors.New("soft and hard deletes need the key columns of every change")
+
+// ParseDeleteMode parses a delete mode; empty is DeleteModeAppendOnly.
+func ParseDeleteMode(s string) (DeleteMode, error) {
+	switch mode := DeleteMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return DeleteModeAppendOnly, nil
+	case DeleteModeAppendOnly, DeleteModeSoft, DeleteModeHard:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown delete mode %q (want hard, soft or append_only)", s)
+	}
+}
+
+// ParseTableDeleteModes parses per-table delete modes written as
+// "schema.table=mode", such as "public.users=soft".
+func ParseTableDeleteModes(entries []string) (map[string]DeleteMode, error) {
+	modes := make(map[string]DeleteMode, len(entries))
+	for _, entry := range entries {
+		table, s, ok := strings.Cut(entry, "=")
+		table = strings.TrimSpace(table)
+		if !ok || table == "" {
+			return nil, fmt.Errorf("invalid table delete mode %q: expected schema.table=mode", entry)
+		}
+		if _, dup := modes[table]; dup {
+			return nil, fmt.Errorf("table %q has more than one delete mode", table)
+		}
+		mode, err := ParseDeleteMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		modes[table] = mode
+	}
+	return modes, nil
+}
+
+// merges reports whether the mode keeps one row per key, rewriting the data
+// files that hold the previous versions of changed rows.
+func (m DeleteMode) merges() bool {
+	return m == DeleteModeSoft || m == DeleteModeHard
+}
+
+// deleteMode returns the delete mode of a source table.
+func (w *IcebergWriter) deleteMode(sourceTable string) DeleteMode {
+	if mode, ok := w.config.TableDeleteModes[sourceTable]; ok {
+		return mode
+	}
+	if w.config.DeleteMode == "" {
+		return DeleteModeAppendOnly
+	}
+	return w.config.DeleteMode
+}
+
+// checkDeleteModes checks that the catalog and the other settings support
+// the configured delete modes. Soft and hard deletes rewrite data files,
+// which needs a catalog that can replace them, commits to main, and tables
+// that are not split by width.
+func checkDeleteModes(cfg Config, cat catalog.Catalog) error {
+	var merged []string
+	if cfg.DeleteMode.merges() {
+		merged = append(merged, "default")
+	}
+	for table, mode := range cfg.TableDeleteModes {
+		if mode.merges() {
+			merged = append(merged, table)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	if _, ok := cat.(catalog.MaintenanceCatalog); !ok {
+		return fmt.Errorf("soft and hard deletes (%s) need a catalog that can replace data files", strings.Join(merged, ", "))
+	}
+	if cfg.Branch != "" && cfg.Branch != iceberg.MainBranch {
+		return fmt.Errorf("soft and hard deletes (%s) cannot be written to branch %q", strings.Join(merged, ", "), cfg.Branch)
+	}
+	if cfg.Width.Enabled() && cfg.Width.Strategy() == schema.WidthSplit {
+		return fmt.Errorf("soft and hard deletes (%s) cannot be used with the %s wide table strategy", strings.Join(merged, ", "), schema.WidthSplit)
+	}
+	return nil
+}
+
+// materialize returns copies of events whose row data carries the columns
+// of mode: _op and _ts in append-only mode, _deleted and _deleted_at
+// otherwise. Row data carries timestamps as strings.
+func materialize(events []buffer.BufferedEvent, mode DeleteMode) []buffer.BufferedEvent {
+	result := make([]buffer.BufferedEvent, len(events))
+	for i, be := range events {
+		event := be.Event
+		row := make(map[string]any)
+		image := event.After
+		if !event.HasAfter() {
+			image = event.Before
+		}
+		for column, value := range image {
+			row[column] = value
+		}
+
+		ts := event.Timestamp.UTC().Format(time.RFC3339Nano)
+		if mode.merges() {
+			deleted := event.Operation == cdc.OperationDelete
+			row[ColumnDeleted] = deleted
+			row[ColumnDeletedAt] = nil
+			if deleted {
+				row[ColumnDeletedAt] = ts
+			}
+		} else {
+			row[ColumnOp] = string(event.Operation)
+			row[ColumnTS] = ts
+		}
+
+		if event.HasAfter() {
+			event.After = row
+		} else {
+			event.Before = row
+		}
+		be.Event = event
+		result[i] = be
+	}
+	return result
+}
+
+// rowKey returns the key of a row by its key columns, and whether the row
+// has all of them.
+func rowKey(row map[string]any, keyColumns []string) (string, bool) {
+	if len(keyColumns) == 0 {
+		return "", false
+	}
+	values := make([]any, len(keyColumns))
+	for i, column := range keyColumns {
+		value, ok := row[column]
+		if !ok {
+			return "", false
+		}
+		values[i] = value
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// eventKey returns the key of the row an event changes, by the Iceberg
+// names of its key columns.
+func (w *IcebergWriter) eventKey(event cdc.Event) (string, bool) {
+	keyColumns := make([]string, len(event.KeyColumns))
+	for i, column := range event.KeyColumns {
+		keyColumns[i] = w.config.ColumnMapper.Name(column)
+	}
+	row := event.After
+	if !event.HasAfter() {
+		row = event.Before
+	}
+	return rowKey(w.config.ColumnMapper.MapRow(row), keyColumns)
+}
+
+// splitKeyed splits events into those that identify their row and those
+// that do not.
+func (w *IcebergWriter) splitKeyed(events []buffer.BufferedEvent) (keyed, keyless []buffer.BufferedEvent) {
+	for _, e := range events {
+		if _, ok := w.eventKey(e.Event); ok {
+			keyed = append(keyed, e)
+		} else {
+			keyless = append(keyless, e)
+		}
+	}
+	return keyed, keyless
+}
+
+// latestChanges returns the last change of each row among events, in the
+// order of those changes, and the keys and key columns of the rows.
+func (w *IcebergWriter) latestChanges(events []buffer.BufferedEvent) ([]buffer.BufferedEvent, map[string]bool, []string) {
+	last := make(map[string]int, len(events))
+	for i, e := range events {
+		key, _ := w.eventKey(e.Event)
+		last[key] = i
+	}
+
+	keys := make(map[string]bool, len(last))
+	var latest []buffer.BufferedEvent
+	for i, e := range events {
+		key, _ := w.eventKey(e.Event)
+		if last[key] == i {
+			latest = append(latest, e)
+			keys[key] = true
+		}
+	}
+
+	keyColumns := make([]string, len(events[0].Event.KeyColumns))
+	for i, column := range events[0].Event.KeyColumns {
+		keyColumns[i] = w.config.ColumnMapper.Name(column)
+	}
+	return latest, keys, keyColumns
+}
+
+// carryForwardToast fills the unchanged TOAST columns the latest change of
+// each row left out (see cdc.MetadataUnchangedToast) from earlier changes of
+// the row in events. It returns, by row key, the Iceberg names of the
+// columns still missing, which are carried over from the row's previous
+// version in the table.
+func (w *IcebergWriter) carryForwardToast(events []buffer.BufferedEvent) map[string][]string {
+	last := make(map[string]int, len(events))
+	for i, e := range events {
+		key, _ := w.eventKey(e.Event)
+		last[key] = i
+	}
+
+	known := make(map[string]map[string]any)
+	pending := make(map[string][]string)
+	for i, e := range events {
+		key, _ := w.eventKey(e.Event)
+		if e.Event.Operation == cdc.OperationDelete {
+			delete(known, key)
+			continue
+		}
+
+		if last[key] == i {
+			for _, column := range e.Event.UnchangedToast() {
+				if _, ok := e.Event.After[column]; ok {
+					continue
+				}
+				if value, ok := known[key][column]; ok {
+					e.Event.After[column] = value
+					continue
+				}
+				pending[key] = append(pending[key], w.config.ColumnMapper.Name(column))
+			}
+			continue
+		}
+
+		if known[key] == nil {
+			known[key] = make(map[string]any)
+		}
+		for column, value := range e.Event.After {
+			known[key][column] = value
+		}
+	}
+	return pending
+}
+
+// fillCarried fills the unchanged TOAST columns of rows with the values
+// carried over from their previous versions, keyed by row key and Iceberg
+// column name.
+func (w *IcebergWriter) fillCarried(rows []buffer.BufferedEvent, carried map[string]map[string]any) {
+	for _, e := range rows {
+		key, _ := w.eventKey(e.Event)
+		values, ok := carried[key]
+		if !ok {
+			continue
+		}
+		for _, column := range e.Event.UnchangedToast() {
+			if _, ok := e.Event.After[column]; ok {
+				continue
+			}
+			if value, ok := values[w.config.ColumnMapper.Name(column)]; ok {
+				e.Event.After[column] = value
+			}
+		}
+	}
+}
+
+// mergeTableEvents writes the changes of a soft or hard delete table copy-
+// on-write: the data files holding previous versions of the changed rows
+// are rewritten without them, and the latest version of each row is
+// appended, except for rows hard-deleted. Unchanged TOAST columns the
+// latest version left out keep the values of the previous version. Every
+// data file of the table is read, so these modes suit tables that are small
+// or rarely changed.
+func (w *IcebergWriter) mergeTableEvents(ctx context.Context, tableKey string, events []buffer.BufferedEvent, mode DeleteMode) error {
+	startTime := time.Now()
+	namespace, tableName := w.parseTableKey(tableKey)
+	cat := w.catalog.(catalog.MaintenanceCatalog)
+
+	source := w.sourceName
+	if source == "" {
+		source = "unknown"
+	}
+
+	pending := w.carryForwardToast(events)
+	latest, keys, keyColumns := w.latestChanges(events)
+	var rows []buffer.BufferedEvent
+	for _, e := range latest {
+		if mode == DeleteModeHard && e.Event.Operation == cdc.OperationDelete {
+			continue
+		}
+		rows = append(rows, e)
+	}
+
+	onConflict := func(attempt int, err error) {
+		metrics.IcebergCommitConflictsTotal.WithLabelValues(source, tableKey).Inc()
+		w.logger.Warn("merge commit conflicted with a concurrent writer, retrying with refreshed metadata",
+			"table", tableKey,
+			"attempt", attempt,
+			"error", err,
+		)
+	}
+
+	var written int64
+	err := catalog.RetryOnConflict(ctx, w.config.CommitRetry, onConflict, func() error {
+		meta, err := cat.LoadTable(ctx, namespace, tableName)
+		if err != nil {
+			return fmt.Errorf("load table metadata: %w", err)
+		}
+		files, err := cat.ListDataFiles(ctx, namespace, tableName, meta.CurrentSnapshotID)
+		if err != nil {
+			return fmt.Errorf("list data files: %w", err)
+		}
+
+		var removed, added []iceberg.DataFile
+		carried := make(map[string]map[string]any)
+		cleanup := func() {
+			for _, f := range added {
+				bucket, key, _ := ParseObjectURL(f.FilePath)
+				_ = w.store.Delete(ctx, bucket, key)
+			}
+		}
+
+		for _, f := range files {
+			rewritten, changed, err := w.rewriteWithout(ctx, f, keys, keyColumns, pending, carried)
+			if err != nil {
+				cleanup()
+				return err
+			}
+			if !changed {
+				continue
+			}
+			removed = append(removed, f)
+			if rewritten != nil {
+				added = append(added, *rewritten)
+			}
+		}
+
+		if len(rows) > 0 {
+			w.fillCarried(rows, carried)
+			result, err := w.parquet.WriteEvents(rows)
+			if err != nil {
+				cleanup()
+				return fmt.Errorf("write parquet: %w", err)
+			}
+			key := fmt.Sprintf("%s/%s", w.getTableDataPath(namespace, tableName), result.FileName)
+			if err := w.store.Upload(ctx, w.config.Bucket, key, bytes.NewReader(result.Data), result.FileSizeInBytes, "application/octet-stream"); err != nil {
+				cleanup()
+				return fmt.Errorf("upload parquet file: %w", err)
+			}
+			added = append(added, iceberg.DataFile{
+				FilePath:        w.store.ObjectURL(w.config.Bucket, key),
+				FileFormat:      "parquet",
+				RecordCount:     result.RecordCount,
+				FileSizeInBytes: result.FileSizeInBytes,
+			})
+			written = result.FileSizeInBytes
+		}
+
+		if len(removed) == 0 && len(added) == 0 {
+			return nil
+		}
+		if err := cat.ReplaceDataFiles(ctx, namespace, tableName, meta.CurrentSnapshotID, removed, added); err != nil {
+			cleanup()
+			return err
+		}
+		w.logger.Debug("merged changes into table",
+			"table", tableKey,
+			"files_rewritten", len(removed),
+			"files_written", len(added),
+		)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("merge changes: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	metrics.IcebergCommitsTotal.WithLabelValues(source, tableKey).Inc()
+	metrics.IcebergCommitDuration.WithLabelValues(source, tableKey).Observe(duration)
+	metrics.IcebergBytesWrittenTotal.WithLabelValues(source, tableKey).Add(float64(written))
+
+	w.logger.Info("events merged into Iceberg",
+		"table", tableKey,
+		"mode", mode,
+		"events", len(events),
+		"rows", len(rows),
+		"duration_ms", int64(duration*1000),
+	)
+	return nil
+}
+
+// rewriteWithout rewrites a data file without the rows whose key is in
+// keys. It reports whether the file holds any of them; the rewritten file
+// is nil if no row is left. The values of the pending columns of the rows
+// removed are collected into carried, by row key.
+func (w *IcebergWriter) rewriteWithout(ctx context.Context, f iceberg.DataFile, keys map[string]bool, keyColumns []string, pending map[string][]string, carried map[string]map[string]any) (*iceberg.DataFile, bool, error) {
+	bucket, key, _ := ParseObjectURL(f.FilePath)
+	data, err := w.store.Download(ctx, bucket, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("download %s: %w", f.FilePath, err)
+	}
+	records, err := ReadRecords(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("read %s: %w", f.FilePath, err)
+	}
+
+	var kept []CDCRecord
+	for _, record := range records {
+		row, err := decodeRow(record.Data)
+		if err != nil {
+			return nil, false, fmt.Errorf("read %s: %w", f.FilePath, err)
+		}
+		if k, ok := rowKey(row, keyColumns); ok && keys[k] {
+			if columns := pending[k]; len(columns) > 0 {
+				values := make(map[string]any, len(columns))
+				for _, column := range columns {
+					if value, ok := row[column]; ok {
+						values[column] = value
+					}
+				}
+				carried[k] = values
+			}
+			continue
+		}
+		kept = append(kept, record)
+	}
+	if len(kept) == len(records) {
+		return nil, false, nil
+	}
+	if len(kept) == 0 {
+		return nil, true, nil
+	}
+
+	result, err := w.parquet.WriteRecords(kept)
+	if err != nil {
+		return nil, false, fmt.Errorf("rewrite %s: %w", f.FilePath, err)
+	}
+	key = key[:strings.LastIndex(key, "/")+1] + result.FileName
+	if err := w.store.Upload(ctx, bucket, key, bytes.NewReader(result.Data), result.FileSizeInBytes, "application/octet-stream"); err != nil {
+		return nil, false, fmt.Errorf("upload rewritten file: %w", err)
+	}
+	return &iceberg.DataFile{
+		FilePath:        w.store.ObjectURL(bucket, key),
+		FileFormat:      "parquet",
+		RecordCount:     result.RecordCount,
+		FileSizeInBytes: result.FileSizeInBytes,
+		PartitionData:   f.PartitionData,
+	}, true, nil
+}
+
+// decodeRow decodes the row data of a record, keeping numbers exact so
+// keys compare equal to those of new changes.
+func decodeRow(data string) (map[string]any, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		return nil, fmt.Errorf("decode row: %w", err)
+	}
+	return row, nil
+}
@@ -0,0 +1,194 @@
+package writer
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/iceberg/catalog"
+)
+
+func TestParseTableDeleteModes(t *testing.T) {
+	modes, err := ParseTableDeleteModes([]string{"public.users=soft", " public.sessions = HARD "})
+	if err != nil {
+		t.Fatalf("ParseTableDeleteModes() error = %v", err)
+	}
+	if modes["public.users"] != DeleteModeSoft || modes["public.sessions"] != DeleteModeHard {
+		t.Errorf("ParseTableDeleteModes() = %v", modes)
+	}
+
+	for _, entries := range [][]string{
+		{"public.users"},
+		{"public.users=upsert"},
+		{"public.users=soft", "public.users=hard"},
+	} {
+		if _, err := ParseTableDeleteModes(entries); err == nil {
+			t.Errorf("ParseTableDeleteModes(%q) error = nil, want an error", entries)
+		}
+	}
+
+	if mode, err := ParseDeleteMode(""); err != nil || mode != DeleteModeAppendOnly {
+		t.Errorf("ParseDeleteMode(\"\") = %q, %v, want append_only", mode, err)
+	}
+}
+
+// appendOnlyCatalog is a catalog that cannot replace data files.
+type appendOnlyCatalog struct {
+	catalog.Catalog
+}
+
+func TestCheckDeleteModes(t *testing.T) {
+	rest := catalog.NewRESTCatalog(catalog.Config{}, slog.Default())
+	soft := map[string]DeleteMode{"public.users": DeleteModeSoft}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		cat     catalog.Catalog
+		wantErr string
+	}{
+		{"append only", Config{}, appendOnlyCatalog{}, ""},
+		{"soft", Config{TableDeleteModes: soft}, rest, ""},
+		{"catalog cannot replace files", Config{TableDeleteModes: soft}, appendOnlyCatalog{}, "replace data files"},
+		{"branch", Config{DeleteMode: DeleteModeHard, Branch: "rebuild"}, rest, "branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDeleteModes(tt.cfg, tt.cat)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDeleteModes() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDeleteModes() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	insert := buffer.BufferedEvent{ID: 1, Event: cdc.Event{
+		Operation: cdc.OperationInsert, Timestamp: at,
+		KeyColumns: []string{"id"}, After: map[string]any{"id": 1, "name": "ada"},
+	}}
+	del := buffer.BufferedEvent{ID: 2, Event: cdc.Event{
+		Operation: cdc.OperationDelete, Timestamp: at,
+		KeyColumns: []string{"id"}, Before: map[string]any{"id": 1},
+	}}
+
+	appended := materialize([]buffer.BufferedEvent{insert, del}, DeleteModeAppendOnly)
+	if row := appended[0].Event.After; row[ColumnOp] != "INSERT" || row[ColumnTS] != "2024-01-02T03:04:05Z" {
+		t.Errorf("append-only insert row = %v", row)
+	}
+	if row := appended[1].Event.Before; row[ColumnOp] != "DELETE" {
+		t.Errorf("append-only delete row = %v", row)
+	}
+	if _, ok := insert.Event.After[ColumnOp]; ok {
+		t.Error("materialize() changed the original event")
+	}
+
+	soft := materialize([]buffer.BufferedEvent{insert, del}, DeleteModeSoft)
+	if row := soft[0].Event.After; row[ColumnDeleted] != false || row[ColumnDeletedAt] != nil {
+		t.Errorf("soft insert row = %v", row)
+	}
+	if row := soft[1].Event.Before; row[ColumnDeleted] != true || row[ColumnDeletedAt] != "2024-01-02T03:04:05Z" {
+		t.Errorf("soft delete row = %v", row)
+	}
+}
+
+func TestLatestChanges(t *testing.T) {
+	w := &IcebergWriter{}
+	change := func(id int64, op cdc.Operation, key int, name string) buffer.BufferedEvent {
+		e := cdc.Event{Operation: op, KeyColumns: []string{"id"}}
+		if op == cdc.OperationDelete {
+			e.Before = map[string]any{"id": key}
+		} else {
+			e.After = map[string]any{"id": key, "name": name}
+		}
+		return buffer.BufferedEvent{ID: id, Event: e}
+	}
+
+	events := []buffer.BufferedEvent{
+		change(1, cdc.OperationInsert, 1, "ada"),
+		change(2, cdc.OperationInsert, 2, "grace"),
+		change(3, cdc.OperationUpdate, 1, "ada lovelace"),
+		change(4, cdc.OperationDelete, 2, ""),
+	}
+	latest, keys, keyColumns := w.latestChanges(events)
+
+	if len(latest) != 2 || latest[0].ID != 3 || latest[1].ID != 4 {
+		t.Errorf("latestChanges() = %+v, want the update of 1 and the delete of 2", latest)
+	}
+	if !keys["[1]"] || !keys["[2]"] || len(keys) != 2 {
+		t.Errorf("keys = %v, want [1] and [2]", keys)
+	}
+	if len(keyColumns) != 1 || keyColumns[0] != "id" {
+		t.Errorf("keyColumns = %v, want id", keyColumns)
+	}
+
+	// Rows read back from data files match by the same key
+	row, err := decodeRow(`{"id": 1, "name": "ada"}`)
+	if err != nil {
+		t.Fatalf("decodeRow() error = %v", err)
+	}
+	if key, ok := rowKey(row, keyColumns); !ok || !keys[key] {
+		t.Errorf("rowKey() = %q, %v, want a changed key", key, ok)
+	}
+
+	keyed, keyless := w.splitKeyed([]buffer.BufferedEvent{events[0], {ID: 5, Event: cdc.Event{After: map[string]any{"id": 3}}}})
+	if len(keyed) != 1 || len(keyless) != 1 || keyless[0].ID != 5 {
+		t.Errorf("splitKeyed() = %d keyed, %v keyless, want event 5 keyless", len(keyed), keyless)
+	}
+}
+
+func TestCarryForwardToast(t *testing.T) {
+	w := &IcebergWriter{}
+	body := strings.Repeat("lorem ipsum ", 1000)
+	update := func(id int64, key int, title string, toasted bool) buffer.BufferedEvent {
+		e := cdc.Event{
+			Operation:  cdc.OperationUpdate,
+			KeyColumns: []string{"id"},
+			After:      map[string]any{"id": key, "title": title},
+			Metadata:   map[string]any{},
+		}
+		if toasted {
+			// As read back from the buffer
+			e.Metadata[cdc.MetadataUnchangedToast] = []any{"body"}
+		} else {
+			e.After["body"] = body
+		}
+		return buffer.BufferedEvent{ID: id, Event: e}
+	}
+
+	events := []buffer.BufferedEvent{
+		update(1, 1, "first", false),
+		update(2, 1, "second", true),
+		update(3, 2, "only", true),
+	}
+	pending := w.carryForwardToast(events)
+
+	if events[1].Event.After["body"] != body {
+		t.Errorf("body of row 1 = %.20q, want the value of the earlier change", events[1].Event.After["body"])
+	}
+	if len(pending) != 1 || len(pending["[2]"]) != 1 || pending["[2]"][0] != "body" {
+		t.Fatalf("pending = %v, want body of row 2", pending)
+	}
+
+	// The previous version of row 2, as read back from its data file
+	previous, err := decodeRow(`{"id": 2, "title": "before", "body": "kept"}`)
+	if err != nil {
+		t.Fatalf("decodeRow() error = %v", err)
+	}
+	carried := map[string]map[string]any{"[2]": {"body": previous["body"]}}
+	w.fillCarried(events[2:], carried)
+
+	if events[2].Event.After["body"] != "kept" || events[2].Event.After["title"] != "only" {
+		t.Errorf("row 2 = %v, want the new title and the carried body", events[2].Event.After)
+	}
+}
@@ -0,0 +1,33 @@
+package writer
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// gcsEndpoint is the endpoint of the Cloud Storage XML API.
+const gcsEndpoint = "storage.googleapis.com"
+
+// NewGCSClient creates a Google Cloud Storage client. It uses the
+// S3-compatible XML API, so the access and secret keys are HMAC keys of a
+// service account. An empty endpoint uses the public one over SSL.
+func NewGCSClient(cfg StorageConfig, logger *slog.Logger) (*MinIOClient, error) {
+	s3Cfg := S3Config{
+		Endpoint:  cfg.Endpoint,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+		UseSSL:    cfg.UseSSL,
+		Region:    cfg.Region,
+	}
+	if s3Cfg.Endpoint == "" {
+		s3Cfg.Endpoint, s3Cfg.UseSSL = gcsEndpoint, true
+	}
+
+	client, err := NewMinIOClient(s3Cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create gcs client: %w", err)
+	}
+	client.scheme = "gs"
+	client.logger = client.logger.With("provider", ProviderGCS)
+	return client, nil
+}
@@ -1,24 +1,31 @@
 package writer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/xitongsys/parquet-go-source/buffer"
 	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/writer"
 
 	"github.com/janovincze/philotes/internal/cdc"
 	cdcbuffer "github.com/janovincze/philotes/internal/cdc/buffer"
 	"github.com/janovincze/philotes/internal/iceberg"
+	"github.com/janovincze/philotes/internal/iceberg/schema"
 )
 
 // ParquetWriter converts CDC events to Parquet format.
 type ParquetWriter struct {
 	// CompressionCodec is the compression codec to use.
 	CompressionCodec parquet.CompressionCodec
+
+	// Mapper maps source column names to target column names in row data.
+	Mapper *schema.ColumnMapper
 }
 
 // NewParquetWriter creates a new Parquet writer.
@@ -71,6 +78,24 @@ func (p *ParquetWriter) WriteEvents(events []cdcbuffer.BufferedEvent) (*ParquetR
 		return nil, fmt.Errorf("no events to write")
 	}
 
+	records := make([]CDCRecord, len(events))
+	for i, be := range events {
+		record, err := eventToRecord(be.Event, p.Mapper)
+		if err != nil {
+			return nil, fmt.Errorf("convert event to record: %w", err)
+		}
+		records[i] = *record
+	}
+
+	return p.WriteRecords(records)
+}
+
+// WriteRecords writes records to a new Parquet file.
+func (p *ParquetWriter) WriteRecords(records []CDCRecord) (*ParquetResult, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records to write")
+	}
+
 	// Create an in-memory buffer file for writing
 	fw := buffer.NewBufferFile()
 
@@ -83,14 +108,9 @@ func (p *ParquetWriter) WriteEvents(events []cdcbuffer.BufferedEvent) (*ParquetR
 	pw.CompressionType = p.CompressionCodec
 	pw.RowGroupSize = 128 * 1024 * 1024 // 128MB row groups
 
-	// Write each event
-	for _, be := range events {
-		record, err := eventToRecord(be.Event)
-		if err != nil {
-			return nil, fmt.Errorf("convert event to record: %w", err)
-		}
-
-		if err := pw.Write(record); err != nil {
+	// Write each record
+	for i := range records {
+		if err := pw.Write(&records[i]); err != nil {
 			return nil, fmt.Errorf("write record: %w", err)
 		}
 	}
@@ -109,13 +129,66 @@ func (p *ParquetWriter) WriteEvents(events []cdcbuffer.BufferedEvent) (*ParquetR
 	return &ParquetResult{
 		Data:            data,
 		FileName:        fileName,
-		RecordCount:     int64(len(events)),
+		RecordCount:     int64(len(records)),
 		FileSizeInBytes: int64(len(data)),
 	}, nil
 }
 
-// eventToRecord converts a CDC event to a CDCRecord.
-func eventToRecord(event cdc.Event) (*CDCRecord, error) {
+// ReadRecords reads the records of a Parquet file written by ParquetWriter.
+func ReadRecords(data []byte) ([]CDCRecord, error) {
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(data), new(CDCRecord), 4)
+	if err != nil {
+		return nil, fmt.Errorf("create parquet reader: %w", err)
+	}
+	defer pr.ReadStop()
+
+	records := make([]CDCRecord, pr.GetNumRows())
+	if err := pr.Read(&records); err != nil {
+		return nil, fmt.Errorf("read records: %w", err)
+	}
+	return records, nil
+}
+
+// DataFileReader reads the rows of data files written by ParquetWriter.
+type DataFileReader struct {
+	store ObjectStore
+}
+
+// NewDataFileReader creates a new DataFileReader.
+func NewDataFileReader(store ObjectStore) *DataFileReader {
+	return &DataFileReader{store: store}
+}
+
+// ReadRows downloads a data file and decodes the row of each record, keyed
+// by column. Numbers are decoded as json.Number so they keep their text.
+func (r *DataFileReader) ReadRows(ctx context.Context, filePath string) ([]map[string]any, error) {
+	bucket, key, ok := ParseObjectURL(filePath)
+	if !ok {
+		return nil, fmt.Errorf("invalid data file path %q", filePath)
+	}
+	data, err := r.store.Download(ctx, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", filePath, err)
+	}
+	records, err := ReadRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]any, len(records))
+	for i, record := range records {
+		decoder := json.NewDecoder(strings.NewReader(record.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rows[i]); err != nil {
+			return nil, fmt.Errorf("decode row %d: %w", i, err)
+		}
+	}
+	return rows, nil
+}
+
+// eventToRecord converts a CDC event to a CDCRecord, renaming columns with
+// mapper.
+func eventToRecord(event cdc.Event, mapper *schema.ColumnMapper) (*CDCRecord, error) {
 	// Get the data to serialize (prefer After for INSERT/UPDATE, Before for DELETE)
 	var data map[string]any
 	if event.HasAfter() {
@@ -125,6 +198,7 @@ func eventToRecord(event cdc.Event) (*CDCRecord, error) {
 	} else {
 		data = make(map[string]any)
 	}
+	data = mapper.MapRow(data)
 
 	// Serialize data to JSON
 	dataJSON, err := json.Marshal(data)
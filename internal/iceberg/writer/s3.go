@@ -43,10 +43,14 @@ type S3Config struct {
 	Region string
 }
 
-// MinIOClient implements S3Client using the MinIO SDK.
+// MinIOClient implements ObjectStore for S3-compatible stores using the
+// MinIO SDK.
 type MinIOClient struct {
 	client *minio.Client
 	logger *slog.Logger
+
+	// scheme is the URL scheme of objects, "s3" unless the store is GCS.
+	scheme string
 }
 
 // NewMinIOClient creates a new MinIO S3 client.
@@ -67,6 +71,7 @@ func NewMinIOClient(cfg S3Config, logger *slog.Logger) (*MinIOClient, error) {
 	return &MinIOClient{
 		client: client,
 		logger: logger.With("component", "s3-client"),
+		scheme: "s3",
 	}, nil
 }
 
@@ -90,6 +95,21 @@ func (c *MinIOClient) Upload(ctx context.Context, bucket, key string, data io.Re
 	return nil
 }
 
+// Download reads an object.
+func (c *MinIOClient) Download(ctx context.Context, bucket, key string) ([]byte, error) {
+	obj, err := c.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, fmt.Errorf("read object: %w", err)
+	}
+	return data, nil
+}
+
 // Delete deletes an object from the bucket.
 func (c *MinIOClient) Delete(ctx context.Context, bucket, key string) error {
 	err := c.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
@@ -139,10 +159,10 @@ func (c *MinIOClient) EnsureBucket(ctx context.Context, bucket string) error {
 	return nil
 }
 
-// GetObjectURL returns the URL for an object.
-func (c *MinIOClient) GetObjectURL(bucket, key string) string {
-	return fmt.Sprintf("s3://%s/%s", bucket, key)
+// ObjectURL returns the URL for an object.
+func (c *MinIOClient) ObjectURL(bucket, key string) string {
+	return fmt.Sprintf("%s://%s/%s", c.scheme, bucket, key)
 }
 
-// Ensure MinIOClient implements S3Client.
-var _ S3Client = (*MinIOClient)(nil)
+// Ensure MinIOClient implements ObjectStore.
+var _ ObjectStore = (*MinIOClient)(nil)
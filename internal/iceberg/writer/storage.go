@@ -0,0 +1,107 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Object storage providers selectable for data files.
+const (
+	// ProviderS3 is Amazon S3 or an S3-compatible store such as MinIO.
+	ProviderS3 = "s3"
+
+	// ProviderGCS is Google Cloud Storage, accessed through its XML API
+	// with HMAC keys.
+	ProviderGCS = "gcs"
+
+	// ProviderAzure is Azure Blob Storage, accessed with a storage account
+	// name and key.
+	ProviderAzure = "azure"
+)
+
+// ObjectStore reads and writes data files in object storage. A bucket is
+// an S3 or GCS bucket or an Azure Blob container.
+type ObjectStore interface {
+	S3Client
+
+	// Download reads an object.
+	Download(ctx context.Context, bucket, key string) ([]byte, error)
+
+	// ObjectURL returns the URL Iceberg metadata refers to an object by,
+	// such as "s3://bucket/key".
+	ObjectURL(bucket, key string) string
+}
+
+// StorageConfig holds object storage configuration.
+type StorageConfig struct {
+	// Provider selects the object store: ProviderS3 (the default),
+	// ProviderGCS or ProviderAzure.
+	Provider string
+
+	// Endpoint is the storage endpoint (e.g., "localhost:9000"). Empty
+	// uses the provider's public endpoint for GCS and Azure.
+	Endpoint string
+
+	// AccessKey is the access key: the HMAC access ID for GCS and the
+	// storage account name for Azure.
+	AccessKey string
+
+	// SecretKey is the secret key: the HMAC secret for GCS and the storage
+	// account key for Azure.
+	SecretKey string
+
+	// UseSSL enables SSL for the connection.
+	UseSSL bool
+
+	// Region is the S3 region (optional for MinIO).
+	Region string
+}
+
+// NewObjectStore creates the object store client of the configured provider.
+func NewObjectStore(cfg StorageConfig, logger *slog.Logger) (ObjectStore, error) {
+	switch cfg.Provider {
+	case "", ProviderS3:
+		return NewMinIOClient(S3Config{
+			Endpoint:  cfg.Endpoint,
+			AccessKey: cfg.AccessKey,
+			SecretKey: cfg.SecretKey,
+			UseSSL:    cfg.UseSSL,
+			Region:    cfg.Region,
+		}, logger)
+	case ProviderGCS:
+		return NewGCSClient(cfg, logger)
+	case ProviderAzure:
+		return NewAzureBlobClient(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage provider %q", cfg.Provider)
+	}
+}
+
+// ParseObjectURL splits an object URL into its bucket and key. It accepts
+// the URLs of every provider: "s3://bucket/key" (or "s3a://"),
+// "gs://bucket/key" and "abfss://container@account.dfs.core.windows.net/key"
+// (or "abfs://", "wasbs://" and "wasb://"), whose bucket is the container.
+func ParseObjectURL(url string) (bucket, key string, ok bool) {
+	scheme, rest, found := strings.Cut(url, "://")
+	if !found {
+		return "", "", false
+	}
+
+	switch scheme {
+	case "s3", "s3a", "gs":
+	case "abfss", "abfs", "wasbs", "wasb":
+		container, path, found := strings.Cut(rest, "@")
+		if !found {
+			return "", "", false
+		}
+		_, key, ok = strings.Cut(path, "/")
+		return container, key, ok && container != "" && key != ""
+	default:
+		return "", "", false
+	}
+
+	bucket, key, ok = strings.Cut(rest, "/")
+	return bucket, key, ok && bucket != "" && key != ""
+}
@@ -0,0 +1,192 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseObjectURL(t *testing.T) {
+	tests := []struct {
+		url         string
+		bucket, key string
+		ok          bool
+	}{
+		{"s3://bucket/warehouse/cdc/orders/data/a.parquet", "bucket", "warehouse/cdc/orders/data/a.parquet", true},
+		{"s3a://bucket/a.parquet", "bucket", "a.parquet", true},
+		{"gs://bucket/warehouse/a.parquet", "bucket", "warehouse/a.parquet", true},
+		{"abfss://data@acct.dfs.core.windows.net/warehouse/a.parquet", "data", "warehouse/a.parquet", true},
+		{"wasbs://data@acct.blob.core.windows.net/a.parquet", "data", "a.parquet", true},
+		{"abfss://acct.dfs.core.windows.net/a.parquet", "", "", false},
+		{"s3://bucket", "", "", false},
+		{"s3:///a.parquet", "", "", false},
+		{"file:///tmp/a.parquet", "", "", false},
+		{"bucket/a.parquet", "", "", false},
+	}
+
+	for _, tt := range tests {
+		bucket, key, ok := ParseObjectURL(tt.url)
+		if !tt.ok && !ok {
+			continue
+		}
+		if bucket != tt.bucket || key != tt.key || ok != tt.ok {
+			t.Errorf("ParseObjectURL(%q) = %q, %q, %v, want %q, %q, %v", tt.url, bucket, key, ok, tt.bucket, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestNewObjectStoreUnknownProvider(t *testing.T) {
+	if _, err := NewObjectStore(StorageConfig{Provider: "ftp"}, nil); err == nil {
+		t.Fatal("NewObjectStore() error = nil, want an error for an unknown provider")
+	}
+}
+
+func TestAzureBlobClientStringToSign(t *testing.T) {
+	client, err := NewAzureBlobClient(StorageConfig{AccessKey: "acct", SecretKey: base64.StdEncoding.EncodeToString([]byte("key"))}, nil)
+	if err != nil {
+		t.Fatalf("NewAzureBlobClient() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://acct.blob.core.windows.net/data/warehouse/a%20b.parquet?restype=container&comp=list", strings.NewReader("abc"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("x-ms-date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+
+	want := "PUT\n\n\n3\n\napplication/octet-stream\n\n\n\n\n\n\n" +
+		"x-ms-blob-type:BlockBlob\nx-ms-date:Mon, 02 Jan 2006 15:04:05 GMT\nx-ms-version:" + azureAPIVersion + "\n" +
+		"/acct/data/warehouse/a%20b.parquet\ncomp:list\nrestype:container"
+	if got := client.stringToSign(req); got != want {
+		t.Errorf("stringToSign() = %q, want %q", got, want)
+	}
+}
+
+// fakeBlobService is an in-memory Blob service that checks Shared Key
+// signatures.
+type fakeBlobService struct {
+	t      *testing.T
+	client *AzureBlobClient
+
+	mu         sync.Mutex
+	containers map[string]bool
+	blobs      map[string][]byte
+}
+
+func (f *fakeBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte(f.client.stringToSign(r)))
+	want := "SharedKey acct:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if got := r.Header.Get("Authorization"); got != want {
+		f.t.Errorf("%s %s: Authorization = %q, want %q", r.Method, r.URL, got, want)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	container, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPut && r.URL.Query().Get("restype") == "container":
+		if f.containers[container] {
+			w.Header().Set("x-ms-error-code", "ContainerAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.containers[container] = true
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		if !f.containers[container] {
+			w.Header().Set("x-ms-error-code", "ContainerNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[container+"/"+key] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.blobs[container+"/"+key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[container+"/"+key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.blobs, container+"/"+key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAzureBlobClient(t *testing.T) {
+	fake := &fakeBlobService{t: t, containers: make(map[string]bool), blobs: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewObjectStore(StorageConfig{
+		Provider:  ProviderAzure,
+		Endpoint:  server.URL,
+		AccessKey: "acct",
+		SecretKey: base64.StdEncoding.EncodeToString([]byte("key")),
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewObjectStore() error = %v", err)
+	}
+	fake.client = store.(*AzureBlobClient)
+	ctx := context.Background()
+
+	if err := store.Upload(ctx, "data", "a.parquet", bytes.NewReader([]byte("x")), 1, "application/octet-stream"); err == nil {
+		t.Fatal("Upload() to a missing container error = nil, want an error")
+	}
+	for range 2 {
+		if err := store.EnsureBucket(ctx, "data"); err != nil {
+			t.Fatalf("EnsureBucket() error = %v", err)
+		}
+	}
+
+	data := []byte("parquet data")
+	if err := store.Upload(ctx, "data", "warehouse/cdc/a b.parquet", bytes.NewReader(data), int64(len(data)), "application/octet-stream"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if exists, err := store.Exists(ctx, "data", "warehouse/cdc/a b.parquet"); err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v, want true", exists, err)
+	}
+	got, err := store.Download(ctx, "data", "warehouse/cdc/a b.parquet")
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Download() = %q, %v, want %q", got, err, data)
+	}
+
+	for range 2 {
+		if err := store.Delete(ctx, "data", "warehouse/cdc/a b.parquet"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+	if exists, err := store.Exists(ctx, "data", "warehouse/cdc/a b.parquet"); err != nil || exists {
+		t.Fatalf("Exists() after Delete() = %v, %v, want false", exists, err)
+	}
+
+	url := store.ObjectURL("data", "warehouse/cdc/a.parquet")
+	if url != "abfss://data@acct.dfs.core.windows.net/warehouse/cdc/a.parquet" {
+		t.Errorf("ObjectURL() = %q", url)
+	}
+	if bucket, key, ok := ParseObjectURL(url); !ok || bucket != "data" || key != "warehouse/cdc/a.parquet" {
+		t.Errorf("ParseObjectURL(ObjectURL()) = %q, %q, %v", bucket, key, ok)
+	}
+}
@@ -0,0 +1,130 @@
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+// TimestampMode controls the Iceberg type of a source table's timestamp
+// without time zone columns. Timestamp with time zone columns are always
+// Iceberg timestamptz, in UTC.
+type TimestampMode string
+
+const (
+	// TimestampModePreserve maps timestamp columns to Iceberg timestamp,
+	// keeping their wall clock time without a zone. This is the default.
+	TimestampModePreserve TimestampMode = "preserve"
+
+	// TimestampModeUTC maps timestamp columns to Iceberg timestamptz,
+	// reading their values as UTC, so every timestamp of a table has a
+	// zone.
+	TimestampModeUTC TimestampMode = "utc"
+)
+
+// ParseTimestampMode parses a timestamp mode; empty is
+// TimestampModePreserve.
+func ParseTimestampMode(s string) (TimestampMode, error) {
+	switch mode := TimestampMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return TimestampModePreserve, nil
+	case TimestampModePreserve, TimestampModeUTC:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown timestamp mode %q (want preserve or utc)", s)
+	}
+}
+
+// ParseTableTimestampModes parses per-table timestamp modes written as
+// "schema.table=mode", such as "public.events=utc".
+func ParseTableTimestampModes(entries []string) (map[string]TimestampMode, error) {
+	modes := make(map[string]TimestampMode, len(entries))
+	for _, entry := range entries {
+		table, s, ok := strings.Cut(entry, "=")
+		table = strings.TrimSpace(table)
+		if !ok || table == "" {
+			return nil, fmt.Errorf("invalid table timestamp mode %q: expected schema.table=mode", entry)
+		}
+		if _, dup := modes[table]; dup {
+			return nil, fmt.Errorf("table %q has more than one timestamp mode", table)
+		}
+		mode, err := ParseTimestampMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		modes[table] = mode
+	}
+	return modes, nil
+}
+
+// timestampMode returns the timestamp mode of a source table.
+func (w *IcebergWriter) timestampMode(sourceTable string) TimestampMode {
+	if mode, ok := w.config.TableTimestampModes[sourceTable]; ok {
+		return mode
+	}
+	if w.config.TimestampMode == "" {
+		return TimestampModePreserve
+	}
+	return w.config.TimestampMode
+}
+
+// normalizeTimestamps rewrites the values of the timestamp columns among
+// declared in the layout of their Iceberg type: with a zone, in UTC, for
+// timestamptz columns, which gives the values of timestamp columns forced
+// to UTC their zone, and without one for timestamp columns. Values that are
+// not timestamps are left for the schema check to reject.
+//
+// Events are shared with the other sinks of a batch, so rows are copied
+// before they are changed.
+func normalizeTimestamps(events []buffer.BufferedEvent, declared map[string]iceberg.Type) []buffer.BufferedEvent {
+	zones := make(map[string]bool)
+	for column, t := range declared {
+		switch t {
+		case iceberg.TypeTimestampTZ:
+			zones[column] = true
+		case iceberg.TypeTimestamp:
+			zones[column] = false
+		}
+	}
+	if len(zones) == 0 {
+		return events
+	}
+
+	result := make([]buffer.BufferedEvent, len(events))
+	for i, be := range events {
+		be.Event.After = normalizeRow(be.Event.After, zones)
+		be.Event.Before = normalizeRow(be.Event.Before, zones)
+		result[i] = be
+	}
+	return result
+}
+
+// normalizeRow returns row with the timestamp values of the columns in
+// zones normalized, copying it if any changed.
+func normalizeRow(row map[string]any, zones map[string]bool) map[string]any {
+	var copied map[string]any
+	for column, withZone := range zones {
+		value, ok := row[column]
+		if !ok || value == nil {
+			continue
+		}
+		normalized, err := cdc.DecodeTimestamp(value, withZone)
+		if err != nil || normalized == value {
+			continue
+		}
+		if copied == nil {
+			copied = make(map[string]any, len(row))
+			for k, v := range row {
+				copied[k] = v
+			}
+		}
+		copied[column] = normalized
+	}
+	if copied == nil {
+		return row
+	}
+	return copied
+}
@@ -0,0 +1,108 @@
+package writer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/iceberg"
+)
+
+func TestParseTableTimestampModes(t *testing.T) {
+	modes, err := ParseTableTimestampModes([]string{"public.events=utc", " public.users = PRESERVE "})
+	if err != nil {
+		t.Fatalf("ParseTableTimestampModes() error = %v", err)
+	}
+	if modes["public.events"] != TimestampModeUTC || modes["public.users"] != TimestampModePreserve {
+		t.Errorf("ParseTableTimestampModes() = %v", modes)
+	}
+
+	for _, entries := range [][]string{
+		{"public.events"},
+		{"public.events=local"},
+		{"public.events=utc", "public.events=preserve"},
+	} {
+		if _, err := ParseTableTimestampModes(entries); err == nil {
+			t.Errorf("ParseTableTimestampModes(%q) error = nil, want an error", entries)
+		}
+	}
+
+	if mode, err := ParseTimestampMode(""); err != nil || mode != TimestampModePreserve {
+		t.Errorf("ParseTimestampMode(\"\") = %q, %v, want preserve", mode, err)
+	}
+}
+
+func TestNormalizeTimestamps(t *testing.T) {
+	update := buffer.BufferedEvent{ID: 1, Event: cdc.Event{
+		Operation: cdc.OperationUpdate,
+		Before: map[string]any{
+			"id":         1,
+			"created_at": "2024-11-03T01:30:00.000000",
+			"paid_at":    "2024-11-03T05:30:00.000000Z",
+		},
+		After: map[string]any{
+			"id": 1,
+			// A wall clock time forced to UTC
+			"created_at": "2024-11-03T01:30:00.000000",
+			// Buffered before timestamps were normalized
+			"paid_at":   "2024-11-03T01:30:00-05:00",
+			"closed_at": nil,
+			"note":      "2024-11-03 01:30:00",
+		},
+	}}
+	before := update.Event.Before
+	after := update.Event.After
+
+	declared := map[string]iceberg.Type{
+		"created_at": iceberg.TypeTimestampTZ,
+		"paid_at":    iceberg.TypeTimestampTZ,
+		"closed_at":  iceberg.TypeTimestampTZ,
+		"amount":     iceberg.DecimalType(10, 2),
+	}
+	got := normalizeTimestamps([]buffer.BufferedEvent{update}, declared)
+
+	wantAfter := map[string]any{
+		"id":         1,
+		"created_at": "2024-11-03T01:30:00.000000Z",
+		"paid_at":    "2024-11-03T06:30:00.000000Z",
+		"closed_at":  nil,
+		"note":       "2024-11-03 01:30:00",
+	}
+	if !reflect.DeepEqual(got[0].Event.After, wantAfter) {
+		t.Errorf("After = %v, want %v", got[0].Event.After, wantAfter)
+	}
+	if got[0].Event.Before["created_at"] != "2024-11-03T01:30:00.000000Z" || got[0].Event.Before["paid_at"] != "2024-11-03T05:30:00.000000Z" {
+		t.Errorf("Before = %v, want UTC timestamps", got[0].Event.Before)
+	}
+
+	// The other sinks of the batch see the rows unchanged
+	if after["created_at"] != "2024-11-03T01:30:00.000000" || after["paid_at"] != "2024-11-03T01:30:00-05:00" {
+		t.Errorf("input After changed to %v", after)
+	}
+	if before["created_at"] != "2024-11-03T01:30:00.000000" {
+		t.Errorf("input Before changed to %v", before)
+	}
+
+	// Without timestamp columns the events are returned as they are
+	events := []buffer.BufferedEvent{update}
+	if got := normalizeTimestamps(events, map[string]iceberg.Type{"amount": iceberg.DecimalType(10, 2)}); &got[0] != &events[0] {
+		t.Error("normalizeTimestamps() copied events without timestamp columns")
+	}
+}
+
+func TestTimestampMode(t *testing.T) {
+	w := &IcebergWriter{config: Config{
+		TimestampMode:       TimestampModeUTC,
+		TableTimestampModes: map[string]TimestampMode{"public.users": TimestampModePreserve},
+	}}
+	if got := w.timestampMode("public.users"); got != TimestampModePreserve {
+		t.Errorf("timestampMode(public.users) = %q, want preserve", got)
+	}
+	if got := w.timestampMode("public.events"); got != TimestampModeUTC {
+		t.Errorf("timestampMode(public.events) = %q, want utc", got)
+	}
+	if got := (&IcebergWriter{}).timestampMode("public.events"); got != TimestampModePreserve {
+		t.Errorf("default timestampMode = %q, want preserve", got)
+	}
+}
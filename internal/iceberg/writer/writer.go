@@ -4,13 +4,18 @@ package writer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/janovincze/philotes/internal/cdc"
 	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/deadletter"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
 	"github.com/janovincze/philotes/internal/iceberg"
 	"github.com/janovincze/philotes/internal/iceberg/catalog"
 	"github.com/janovincze/philotes/internal/iceberg/schema"
@@ -31,10 +36,10 @@ type Config struct {
 	// Catalog is the catalog configuration.
 	Catalog catalog.Config
 
-	// S3 is the S3/MinIO configuration.
-	S3 S3Config
+	// Storage is the object storage configuration.
+	Storage StorageConfig
 
-	// Bucket is the S3 bucket for data files.
+	// Bucket is the bucket, or Azure container, for data files.
 	Bucket string
 
 	// WarehousePath is the base path for table data within the bucket.
@@ -42,19 +47,85 @@ type Config struct {
 
 	// DefaultNamespace is the default namespace for tables.
 	DefaultNamespace string
+
+	// NamespacePrefix is prepended to every namespace, so a shadow pipeline
+	// can write to its own tables (e.g. "shadow_public.orders").
+	NamespacePrefix string
+
+	// Branch is the Iceberg branch snapshots are committed to. Empty commits
+	// to main. Writing a rebuild to a branch keeps it invisible to queries
+	// until the branch is promoted onto main.
+	Branch string
+
+	// ColumnMapper maps source column names to Iceberg column names, for
+	// both the table schema and row data. Nil keeps names unchanged.
+	ColumnMapper *schema.ColumnMapper
+
+	// Width limits table width and row size and splits or overflows wide
+	// tables. Nil writes tables as they are.
+	Width *schema.WidthGuard
+
+	// Partitioning is the partition spec new tables are created with. Nil
+	// partitions by the day of _cdc_timestamp.
+	Partitioning []schema.PartitionRule
+
+	// TablePartitioning overrides Partitioning for source tables, keyed by
+	// "schema.table".
+	TablePartitioning map[string][]schema.PartitionRule
+
+	// CommitRetry bounds retries of commits that conflict with concurrent
+	// writers. The zero value uses catalog.DefaultCommitRetryConfig.
+	CommitRetry catalog.CommitRetryConfig
+
+	// DeleteMode is how changes are materialized in tables. Empty appends
+	// every change.
+	DeleteMode DeleteMode
+
+	// TableDeleteModes overrides DeleteMode for source tables, keyed by
+	// "schema.table".
+	TableDeleteModes map[string]DeleteMode
+
+	// SourceColumns looks up the declared types of source columns, so
+	// numeric columns become decimals of their precision and scale and
+	// timestamp columns Iceberg timestamps. Nil infers every column type
+	// from values.
+	SourceColumns schema.SourceColumns
+
+	// Decimals maps numeric columns to decimals. The zero value uses
+	// schema.DefaultDecimalMapping.
+	Decimals schema.DecimalMapping
+
+	// TimestampMode is the Iceberg type of timestamp without time zone
+	// columns. Empty keeps them without a zone.
+	TimestampMode TimestampMode
+
+	// TableTimestampModes overrides TimestampMode for source tables, keyed
+	// by "schema.table".
+	TableTimestampModes map[string]TimestampMode
 }
 
 // IcebergWriter implements Writer for Iceberg tables.
 type IcebergWriter struct {
 	catalog       catalog.Catalog
-	s3            *MinIOClient
+	store         ObjectStore
 	parquet       *ParquetWriter
 	schemaBuilder *schema.Builder
 	logger        *slog.Logger
 	config        Config
 
+	// mu guards the caches below; tables of a batch may be written
+	// concurrently.
+	mu sync.Mutex
+
 	// tableSchemas caches table schemas to avoid repeated lookups.
-	tableSchemas map[string]iceberg.Schema
+	tableSchemas map[string]tableSchema
+
+	// widthRestored records the source tables whose wide table layout was
+	// restored from the catalog.
+	widthRestored map[string]bool
+
+	// sourceTypes caches the declared column types of source tables.
+	sourceTypes map[string]map[string]iceberg.Type
 
 	// sourceName is used for metric labels.
 	sourceName string
@@ -71,27 +142,70 @@ func NewIcebergWriter(cfg Config, logger *slog.Logger) (*IcebergWriter, error) {
 		logger = slog.Default()
 	}
 
+	if cfg.CommitRetry == (catalog.CommitRetryConfig{}) {
+		cfg.CommitRetry = catalog.DefaultCommitRetryConfig()
+	}
+	if cfg.Decimals == (schema.DecimalMapping{}) {
+		cfg.Decimals = schema.DefaultDecimalMapping()
+	}
+	if err := cfg.Decimals.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid decimal mapping: %w", err)
+	}
+
 	// Create catalog client
 	cat := catalog.NewRESTCatalog(cfg.Catalog, logger)
+	if err := checkDeleteModes(cfg, cat); err != nil {
+		return nil, err
+	}
 
-	// Create S3 client
-	s3Client, err := NewMinIOClient(cfg.S3, logger)
+	// Create object storage client
+	store, err := NewObjectStore(cfg.Storage, logger)
 	if err != nil {
-		return nil, fmt.Errorf("create s3 client: %w", err)
+		return nil, fmt.Errorf("create object storage client: %w", err)
 	}
 
+	parquetWriter := NewParquetWriter()
+	parquetWriter.Mapper = cfg.ColumnMapper
+
+	schemaBuilder := schema.NewBuilder()
+	schemaBuilder.Mapper = cfg.ColumnMapper
+
 	return &IcebergWriter{
 		catalog:       cat,
-		s3:            s3Client,
-		parquet:       NewParquetWriter(),
-		schemaBuilder: schema.NewBuilder(),
+		store:         store,
+		parquet:       parquetWriter,
+		schemaBuilder: schemaBuilder,
 		logger:        logger.With("component", "iceberg-writer"),
 		config:        cfg,
-		tableSchemas:  make(map[string]iceberg.Schema),
+		tableSchemas:  make(map[string]tableSchema),
+		widthRestored: make(map[string]bool),
+		sourceTypes:   make(map[string]map[string]iceberg.Type),
 	}, nil
 }
 
+// Snapshot summary entries the writer commits with, so a retried batch can
+// tell which of its events a previous attempt already committed.
+const (
+	// summarySource is the source that committed the snapshot.
+	summarySource = "philotes.source"
+
+	// summaryMaxEventID is the highest buffer event ID in the snapshot.
+	summaryMaxEventID = "philotes.max-event-id"
+)
+
+// tableSchema is a table's current schema as known to the writer.
+type tableSchema struct {
+	schema       iceberg.Schema
+	lastColumnID int
+}
+
 // WriteEvents writes a batch of buffered events to Iceberg.
+//
+// Before writing a table, its Iceberg schema is evolved to fit the batch.
+// A table whose rows need a change Iceberg does not allow is skipped and
+// its events are returned in a *buffer.RejectedEventsError, so the batch
+// processor sends them to the dead-letter queue while the other tables are
+// written.
 func (w *IcebergWriter) WriteEvents(ctx context.Context, events []buffer.BufferedEvent) error {
 	if len(events) == 0 {
 		return nil
@@ -100,16 +214,236 @@ func (w *IcebergWriter) WriteEvents(ctx context.Context, events []buffer.Buffere
 	// Group events by table
 	eventsByTable := w.groupEventsByTable(events)
 
+	var rejected []buffer.BufferedEvent
+	var reasons []error
+
 	// Process each table's events
 	for tableKey, tableEvents := range eventsByTable {
-		if err := w.writeTableEvents(ctx, tableKey, tableEvents); err != nil {
+		mode := w.deleteMode(tableKey)
+		if mode.merges() {
+			keyed, keyless := w.splitKeyed(tableEvents)
+			if len(keyless) > 0 {
+				w.logger.Warn("changes without key columns cannot be merged, rejecting them",
+					"table", tableKey,
+					"mode", mode,
+					"events", len(keyless),
+				)
+				rejected = append(rejected, keyless...)
+				reasons = append(reasons, fmt.Errorf("%s: %w", tableKey, errNoKeyColumns))
+			}
+			if len(keyed) == 0 {
+				continue
+			}
+			tableEvents = keyed
+		}
+		// Write timestamps in the layout of their column type
+		declared, err := w.declaredTypes(ctx, tableKey, false)
+		if err != nil && !errors.Is(err, schema.ErrIncompatibleChange) {
+			return fmt.Errorf("write events for %s: %w", tableKey, err)
+		}
+		tableEvents = normalizeTimestamps(tableEvents, declared)
+
+		tableEvents = materialize(tableEvents, mode)
+
+		parts, err := w.applyWidth(ctx, tableKey, tableEvents)
+		if err != nil {
 			return fmt.Errorf("write events for %s: %w", tableKey, err)
 		}
+
+		// Prepare every part before writing any, so a table is either
+		// written in full or rejected in full
+		if err := w.prepareTables(ctx, tableKey, parts); err != nil {
+			if !errors.Is(err, schema.ErrIncompatibleChange) {
+				return fmt.Errorf("write events for %s: %w", tableKey, err)
+			}
+			w.logger.Warn("batch does not fit the table schema, rejecting its events",
+				"table", tableKey,
+				"events", len(tableEvents),
+				"error", err,
+			)
+			rejected = append(rejected, tableEvents...)
+			reasons = append(reasons, err)
+			continue
+		}
+
+		for _, part := range parts {
+			if mode.merges() {
+				if err := w.mergeTableEvents(ctx, tableKey+part.suffix, part.events, mode); err != nil {
+					return fmt.Errorf("write events for %s: %w", tableKey+part.suffix, err)
+				}
+				continue
+			}
+			if err := w.writeTableEvents(ctx, tableKey+part.suffix, part.events); err != nil {
+				return fmt.Errorf("write events for %s: %w", tableKey+part.suffix, err)
+			}
+		}
 	}
 
+	if len(rejected) > 0 {
+		return &buffer.RejectedEventsError{
+			Events: rejected,
+			Type:   deadletter.ErrorTypeSchema,
+			Err:    errors.Join(reasons...),
+		}
+	}
 	return nil
 }
 
+// prepareTables checks column mapping and creates or evolves the Iceberg
+// table of every part.
+func (w *IcebergWriter) prepareTables(ctx context.Context, tableKey string, parts []tablePart) error {
+	for _, part := range parts {
+		cdcEvents := make([]cdc.Event, len(part.events))
+		for i, e := range part.events {
+			cdcEvents[i] = e.Event
+		}
+
+		// Reject batches whose columns would collide after renaming
+		if w.config.ColumnMapper != nil {
+			if err := w.config.ColumnMapper.CheckEvents(cdcEvents); err != nil {
+				return fmt.Errorf("column mapping: %w", err)
+			}
+		}
+
+		namespace, tableName := w.parseTableKey(tableKey + part.suffix)
+		if err := w.ensureTable(ctx, tableKey, namespace, tableName, cdcEvents); err != nil {
+			return fmt.Errorf("%s: %w", tableKey+part.suffix, err)
+		}
+	}
+	return nil
+}
+
+// tablePart is the share of a table's events written to one Iceberg table.
+type tablePart struct {
+	suffix string
+	events []buffer.BufferedEvent
+}
+
+// applyWidth applies the width limits to a table's events, splitting them
+// into parts or moving overflow columns as configured.
+func (w *IcebergWriter) applyWidth(ctx context.Context, tableKey string, events []buffer.BufferedEvent) ([]tablePart, error) {
+	if !w.config.Width.Enabled() {
+		return []tablePart{{events: events}}, nil
+	}
+
+	cdcEvents := make([]cdc.Event, len(events))
+	for i, e := range events {
+		cdcEvents[i] = e.Event
+	}
+	if err := w.restoreWidth(ctx, tableKey, cdcEvents); err != nil {
+		return nil, err
+	}
+	applied, err := w.config.Width.Apply(tableKey, cdcEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]tablePart, len(applied))
+	for i, part := range applied {
+		parts[i] = tablePart{suffix: part.Suffix, events: make([]buffer.BufferedEvent, len(events))}
+		for j, e := range events {
+			e.Event = part.Events[j]
+			parts[i].events[j] = e
+		}
+	}
+	return parts, nil
+}
+
+// restoreWidth restores the layout of a wide table from its Iceberg tables
+// the first time the writer sees it: the columns of each split part, or the
+// regular columns of an overflowed table. Columns then keep their part
+// across restarts, whatever order they are seen in.
+func (w *IcebergWriter) restoreWidth(ctx context.Context, tableKey string, events []cdc.Event) error {
+	w.mu.Lock()
+	restored := w.widthRestored[tableKey]
+	w.mu.Unlock()
+	if restored {
+		return nil
+	}
+
+	// Iceberg columns are named by the column mapper; map them back
+	sourceNames := make(map[string]string)
+	var keyColumns []string
+	for _, event := range events {
+		for column := range event.After {
+			sourceNames[w.config.ColumnMapper.Name(column)] = column
+		}
+		for column := range event.Before {
+			sourceNames[w.config.ColumnMapper.Name(column)] = column
+		}
+		if keyColumns == nil {
+			keyColumns = event.KeyColumns
+		}
+	}
+
+	var parts [][]string
+	switch w.config.Width.Strategy() {
+	case schema.WidthSplit:
+		for i := 0; ; i++ {
+			columns, err := w.existingColumns(ctx, tableKey+schema.PartSuffix(i), sourceNames)
+			if err != nil {
+				return err
+			}
+			if columns == nil {
+				break
+			}
+			parts = append(parts, columns)
+		}
+	case schema.WidthOverflow:
+		columns, err := w.existingColumns(ctx, tableKey, sourceNames)
+		if err != nil {
+			return err
+		}
+		// Only a table that already overflowed has a layout to keep
+		for _, column := range columns {
+			if column == schema.OverflowColumn {
+				parts = [][]string{columns}
+				break
+			}
+		}
+	}
+
+	if len(parts) > 0 {
+		w.config.Width.Restore(tableKey, parts, keyColumns)
+		w.logger.Info("wide table layout restored", "table", tableKey, "parts", len(parts))
+	}
+	w.mu.Lock()
+	w.widthRestored[tableKey] = true
+	w.mu.Unlock()
+	return nil
+}
+
+// existingColumns returns the source names of the columns of an Iceberg
+// table, or nil if the table does not exist. Columns missing from
+// sourceNames keep their Iceberg name.
+func (w *IcebergWriter) existingColumns(ctx context.Context, tableKey string, sourceNames map[string]string) ([]string, error) {
+	namespace, tableName := w.parseTableKey(tableKey)
+
+	current, cached := w.cachedSchema(namespace + "." + tableName)
+	if !cached {
+		exists, err := w.catalog.TableExists(ctx, namespace, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("check table exists: %w", err)
+		}
+		if !exists {
+			return nil, nil
+		}
+		if current, err = w.loadSchema(ctx, namespace, tableName); err != nil {
+			return nil, err
+		}
+	}
+
+	columns := make([]string, 0, len(current.schema.Fields))
+	for _, field := range current.schema.Fields {
+		name := field.Name
+		if source, ok := sourceNames[name]; ok {
+			name = source
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
 // groupEventsByTable groups events by their source table.
 func (w *IcebergWriter) groupEventsByTable(events []buffer.BufferedEvent) map[string][]buffer.BufferedEvent {
 	grouped := make(map[string][]buffer.BufferedEvent)
@@ -128,16 +462,42 @@ func (w *IcebergWriter) writeTableEvents(ctx context.Context, tableKey string, e
 		return nil
 	}
 
-	startTime := time.Now()
-
 	// Parse table identifier
 	namespace, tableName := w.parseTableKey(tableKey)
 
-	// Ensure table exists with appropriate schema
-	if err := w.ensureTable(ctx, namespace, tableName, events); err != nil {
-		return fmt.Errorf("ensure table: %w", err)
+	source := w.sourceName
+	if source == "" {
+		source = "unknown"
 	}
 
+	// A split table commits each part on its own. When a batch is retried
+	// after some parts were committed, drop the events those parts already
+	// hold instead of writing them twice.
+	committed, err := w.committedEventID(ctx, namespace, tableName, source)
+	if err != nil {
+		return err
+	}
+	events = eventsAfter(events, committed)
+	if len(events) == 0 {
+		w.logger.Info("events already committed, skipping", "table", tableKey, "max_event_id", committed)
+		return nil
+	}
+
+	// Snapshot rows are committed apart from streamed changes, so
+	// verification can count the rows a snapshot wrote. Runs are committed
+	// in order, which keeps the committed event ID a retry relies on.
+	for _, run := range snapshotRuns(events) {
+		if err := w.writeDataFile(ctx, tableKey, namespace, tableName, source, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDataFile writes events to one data file and commits it.
+func (w *IcebergWriter) writeDataFile(ctx context.Context, tableKey, namespace, tableName, source string, events []buffer.BufferedEvent) error {
+	startTime := time.Now()
+
 	// Write events to Parquet file
 	result, err := w.parquet.WriteEvents(events)
 	if err != nil {
@@ -147,37 +507,51 @@ func (w *IcebergWriter) writeTableEvents(ctx context.Context, tableKey string, e
 	// Determine the data path
 	basePath := w.getTableDataPath(namespace, tableName)
 
-	// Upload to S3
+	// Upload to object storage
 	key := fmt.Sprintf("%s/%s", basePath, result.FileName)
-	if err := w.s3.Upload(ctx, w.config.Bucket, key, bytes.NewReader(result.Data), result.FileSizeInBytes, "application/octet-stream"); err != nil {
+	if err := w.store.Upload(ctx, w.config.Bucket, key, bytes.NewReader(result.Data), result.FileSizeInBytes, "application/octet-stream"); err != nil {
 		return fmt.Errorf("upload parquet file: %w", err)
 	}
 
 	// Create data file metadata
 	dataFile := iceberg.DataFile{
-		FilePath:        fmt.Sprintf("s3://%s/%s", w.config.Bucket, key),
+		FilePath:        w.store.ObjectURL(w.config.Bucket, key),
 		FileFormat:      "parquet",
 		RecordCount:     result.RecordCount,
 		FileSizeInBytes: result.FileSizeInBytes,
 	}
 
-	// Commit snapshot to catalog
-	if err := w.catalog.CommitSnapshot(ctx, namespace, tableName, []iceberg.DataFile{dataFile}); err != nil {
+	summary := map[string]string{
+		summarySource:     source,
+		summaryMaxEventID: strconv.FormatInt(maxEventID(events), 10),
+	}
+	if isSnapshotRow(events[0]) {
+		summary[iceberg.SummarySnapshotLSN] = minLSN(events)
+		summary[iceberg.SummarySnapshotRecords] = strconv.FormatInt(result.RecordCount, 10)
+		summary[iceberg.SummarySnapshotFile] = dataFile.FilePath
+	}
+
+	// Commit snapshot to catalog, retrying on conflicts with concurrent writers
+	onConflict := func(attempt int, err error) {
+		metrics.IcebergCommitConflictsTotal.WithLabelValues(source, tableKey).Inc()
+		w.logger.Warn("snapshot commit conflicted with a concurrent writer, retrying with refreshed metadata",
+			"table", tableKey,
+			"attempt", attempt,
+			"error", err,
+		)
+	}
+	if err := w.commit(ctx, namespace, tableName, []iceberg.DataFile{dataFile}, summary, onConflict); err != nil {
 		// If commit fails, try to clean up the uploaded file
 		w.logger.Warn("snapshot commit failed, cleaning up file",
 			"error", err,
 			"file", key,
 		)
-		_ = w.s3.Delete(ctx, w.config.Bucket, key)
+		_ = w.store.Delete(ctx, w.config.Bucket, key)
 		return fmt.Errorf("commit snapshot: %w", err)
 	}
 
 	// Record Iceberg metrics
 	duration := time.Since(startTime).Seconds()
-	source := w.sourceName
-	if source == "" {
-		source = "unknown"
-	}
 	metrics.IcebergCommitsTotal.WithLabelValues(source, tableKey).Inc()
 	metrics.IcebergCommitDuration.WithLabelValues(source, tableKey).Observe(duration)
 	metrics.IcebergFilesWrittenTotal.WithLabelValues(source, tableKey).Inc()
@@ -193,73 +567,332 @@ func (w *IcebergWriter) writeTableEvents(ctx context.Context, tableKey string, e
 	return nil
 }
 
-// ensureTable ensures the table exists, creating it if necessary.
-func (w *IcebergWriter) ensureTable(ctx context.Context, namespace, tableName string, events []buffer.BufferedEvent) error {
-	tableKey := namespace + "." + tableName
+// commit commits data files to the configured branch, or to main if none is
+// configured.
+func (w *IcebergWriter) commit(ctx context.Context, namespace, tableName string, dataFiles []iceberg.DataFile, summary map[string]string, onConflict func(int, error)) error {
+	if w.config.Branch == "" || w.config.Branch == iceberg.MainBranch {
+		return catalog.CommitWithRetry(ctx, w.catalog, namespace, tableName, dataFiles, summary, w.config.CommitRetry, onConflict)
+	}
 
-	// Check if we have a cached schema
-	if _, exists := w.tableSchemas[tableKey]; exists {
-		return nil
+	branches, ok := w.catalog.(catalog.BranchCatalog)
+	if !ok {
+		return fmt.Errorf("catalog does not support writing to branch %q", w.config.Branch)
 	}
+	return catalog.CommitToBranchWithRetry(ctx, branches, namespace, tableName, w.config.Branch, dataFiles, summary, w.config.CommitRetry, onConflict)
+}
 
-	// Check if table exists in catalog
-	exists, err := w.catalog.TableExists(ctx, namespace, tableName)
+// committedEventID returns the highest buffer event ID source committed to
+// the table on the configured branch, or 0 if it committed none. Snapshots
+// of other writers, such as compaction, are skipped.
+func (w *IcebergWriter) committedEventID(ctx context.Context, namespace, tableName, source string) (int64, error) {
+	meta, err := w.catalog.LoadTable(ctx, namespace, tableName)
 	if err != nil {
-		return fmt.Errorf("check table exists: %w", err)
+		return 0, fmt.Errorf("load table metadata: %w", err)
 	}
 
-	if exists {
-		// Load and cache the schema
-		meta, err := w.catalog.LoadTable(ctx, namespace, tableName)
+	branch := w.config.Branch
+	if branch == "" {
+		branch = iceberg.MainBranch
+	}
+	for _, snap := range meta.BranchAncestry(branch) {
+		if snap.Summary[summarySource] != source {
+			continue
+		}
+		id, err := strconv.ParseInt(snap.Summary[summaryMaxEventID], 10, 64)
 		if err != nil {
-			return fmt.Errorf("load table metadata: %w", err)
+			continue
 		}
-		if len(meta.Schemas) > 0 {
-			w.tableSchemas[tableKey] = meta.Schemas[meta.CurrentSchemaID]
+		return id, nil
+	}
+	return 0, nil
+}
+
+// eventsAfter returns the events with a buffer ID above id. Events that were
+// never buffered have no ID and are always kept.
+func eventsAfter(events []buffer.BufferedEvent, id int64) []buffer.BufferedEvent {
+	if id == 0 {
+		return events
+	}
+	var after []buffer.BufferedEvent
+	for _, e := range events {
+		if e.ID == 0 || e.ID > id {
+			after = append(after, e)
 		}
-		return nil
 	}
+	return after
+}
 
-	// Build schema from events
-	cdcEvents := make([]cdc.Event, len(events))
-	for i, e := range events {
-		cdcEvents[i] = e.Event
+// snapshotRuns splits events into runs of consecutive snapshot rows and
+// streamed changes, in order.
+func snapshotRuns(events []buffer.BufferedEvent) [][]buffer.BufferedEvent {
+	var runs [][]buffer.BufferedEvent
+	start := 0
+	for i := 1; i <= len(events); i++ {
+		if i == len(events) || isSnapshotRow(events[i]) != isSnapshotRow(events[start]) {
+			runs = append(runs, events[start:i])
+			start = i
+		}
+	}
+	return runs
+}
+
+// isSnapshotRow reports whether an event is a row read by a table snapshot.
+func isSnapshotRow(e buffer.BufferedEvent) bool {
+	row, _ := e.Event.Metadata[snapshot.MetadataSnapshot].(bool)
+	return row
+}
+
+// minLSN returns the lowest LSN of events. Events whose LSN does not parse
+// are skipped.
+func minLSN(events []buffer.BufferedEvent) string {
+	var lowest string
+	var lowestLSN cdc.LSN
+	for _, e := range events {
+		lsn, err := cdc.ParseLSN(e.Event.LSN)
+		if err != nil {
+			continue
+		}
+		if lowest == "" || lsn < lowestLSN {
+			lowest, lowestLSN = e.Event.LSN, lsn
+		}
+	}
+	return lowest
+}
+
+// maxEventID returns the highest buffer ID of events.
+func maxEventID(events []buffer.BufferedEvent) int64 {
+	var id int64
+	for _, e := range events {
+		id = max(id, e.ID)
+	}
+	return id
+}
+
+// ensureTable ensures the table exists with a schema that can store
+// events, creating the table or evolving its schema as needed. It returns
+// an error wrapping schema.ErrIncompatibleChange if the schema cannot be
+// evolved to fit.
+//
+// sourceTable is the "schema.table" the events come from; it selects the
+// partitioning of a new table.
+func (w *IcebergWriter) ensureTable(ctx context.Context, sourceTable, namespace, tableName string, events []cdc.Event) error {
+	tableKey := namespace + "." + tableName
+
+	current, cached := w.cachedSchema(tableKey)
+	if !cached {
+		exists, err := w.catalog.TableExists(ctx, namespace, tableName)
+		if err != nil {
+			return fmt.Errorf("check table exists: %w", err)
+		}
+		if !exists {
+			return w.createTable(ctx, sourceTable, namespace, tableName, events)
+		}
+		if current, err = w.loadSchema(ctx, namespace, tableName); err != nil {
+			return err
+		}
+	}
+
+	return w.evolveSchema(ctx, sourceTable, namespace, tableName, current, events)
+}
+
+// declaredTypes returns the Iceberg types sourceTable declares for its
+// numeric and timestamp columns, looking them up on first use or when
+// refresh is set. It returns nil if the writer has no SourceColumns, and an
+// error wrapping schema.ErrIncompatibleChange if a column has no Iceberg
+// decimal.
+func (w *IcebergWriter) declaredTypes(ctx context.Context, sourceTable string, refresh bool) (map[string]iceberg.Type, error) {
+	if w.config.SourceColumns == nil {
+		return nil, nil
+	}
+
+	if !refresh {
+		w.mu.Lock()
+		declared, ok := w.sourceTypes[sourceTable]
+		w.mu.Unlock()
+		if ok {
+			return declared, nil
+		}
+	}
+
+	schemaName, table, _ := strings.Cut(sourceTable, ".")
+	pgTypes, err := w.config.SourceColumns.ColumnTypes(ctx, schemaName, table)
+	if err != nil {
+		return nil, fmt.Errorf("look up source column types: %w", err)
+	}
+	declared, err := w.config.Decimals.ColumnTypes(pgTypes)
+	if err != nil {
+		return nil, err
+	}
+	utc := w.timestampMode(sourceTable) == TimestampModeUTC
+	for column, t := range schema.TimestampColumnTypes(pgTypes, utc) {
+		declared[column] = t
+	}
+
+	w.mu.Lock()
+	w.sourceTypes[sourceTable] = declared
+	w.mu.Unlock()
+	return declared, nil
+}
+
+// createTable creates a table with a schema built from events, partitioned
+// as configured for sourceTable. Numeric and timestamp columns take the
+// type the source declares.
+func (w *IcebergWriter) createTable(ctx context.Context, sourceTable, namespace, tableName string, events []cdc.Event) error {
+	declared, err := w.declaredTypes(ctx, sourceTable, true)
+	if err != nil {
+		return err
+	}
+	built, err := w.schemaBuilder.BuildFromEventsWithTypes(events, declared)
+	if err != nil {
+		return err
 	}
-	tableSchema := w.schemaBuilder.BuildFromEvents(cdcEvents)
 
 	// Create partition spec
-	partitionSpec := schema.DefaultPartitionSpec(tableSchema)
+	partitionSpec := schema.DefaultPartitionSpec(built)
+	if rules := w.partitioning(sourceTable); rules != nil {
+		built, partitionSpec, err = schema.BuildPartitionSpec(built, rules)
+		if err != nil {
+			return fmt.Errorf("partition table: %w", err)
+		}
+	}
 
 	// Ensure bucket exists
-	if err := w.s3.EnsureBucket(ctx, w.config.Bucket); err != nil {
+	if err := w.store.EnsureBucket(ctx, w.config.Bucket); err != nil {
 		return fmt.Errorf("ensure bucket: %w", err)
 	}
 
 	// Create table
-	if err := w.catalog.CreateTable(ctx, namespace, tableName, tableSchema, partitionSpec); err != nil {
+	if err := w.catalog.CreateTable(ctx, namespace, tableName, built, partitionSpec); err != nil {
 		return fmt.Errorf("create table: %w", err)
 	}
 
-	// Cache the schema
-	w.tableSchemas[tableKey] = tableSchema
+	// Cache the schema as the catalog stored it; it assigns its own field IDs
+	if _, err := w.loadSchema(ctx, namespace, tableName); err != nil {
+		return err
+	}
 
 	w.logger.Info("table created",
 		"namespace", namespace,
 		"table", tableName,
-		"columns", len(tableSchema.Fields),
+		"columns", len(built.Fields),
+		"partition_fields", len(partitionSpec.Fields),
 	)
 
 	return nil
 }
 
+// partitioning returns the partition rules for a source table, or nil for
+// the default partitioning.
+func (w *IcebergWriter) partitioning(sourceTable string) []schema.PartitionRule {
+	if rules, ok := w.config.TablePartitioning[sourceTable]; ok {
+		return rules
+	}
+	return w.config.Partitioning
+}
+
+// loadSchema loads a table's current schema from the catalog and caches it.
+func (w *IcebergWriter) loadSchema(ctx context.Context, namespace, tableName string) (tableSchema, error) {
+	meta, err := w.catalog.LoadTable(ctx, namespace, tableName)
+	if err != nil {
+		return tableSchema{}, fmt.Errorf("load table metadata: %w", err)
+	}
+
+	current, ok := meta.CurrentSchema()
+	if !ok {
+		return tableSchema{}, fmt.Errorf("table %s.%s has no current schema", namespace, tableName)
+	}
+
+	ts := tableSchema{schema: current, lastColumnID: meta.LastColumnID}
+	w.cacheSchema(namespace+"."+tableName, ts)
+	return ts, nil
+}
+
+// cachedSchema returns the cached schema of a table.
+func (w *IcebergWriter) cachedSchema(tableKey string) (tableSchema, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ts, ok := w.tableSchemas[tableKey]
+	return ts, ok
+}
+
+// cacheSchema caches the schema of a table.
+func (w *IcebergWriter) cacheSchema(tableKey string, ts tableSchema) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tableSchemas[tableKey] = ts
+}
+
+// evolveSchema updates the table schema if events have new columns, widened
+// types or nulls in required columns. New columns take the type sourceTable
+// declares for them, looked up again in case they were added to the source
+// since. A commit that conflicts with another writer is retried on the
+// refreshed schema, which may already fit.
+func (w *IcebergWriter) evolveSchema(ctx context.Context, sourceTable, namespace, tableName string, current tableSchema, events []cdc.Event) error {
+	declared, err := w.declaredTypes(ctx, sourceTable, false)
+	if err != nil {
+		return err
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		evo, err := w.schemaBuilder.EvolveWithTypes(current.schema, current.lastColumnID, events, declared)
+		if err != nil {
+			return err
+		}
+		if len(evo.Added) > 0 && !refreshed {
+			refreshed = true
+			if declared, err = w.declaredTypes(ctx, sourceTable, true); err != nil {
+				return err
+			}
+			if evo, err = w.schemaBuilder.EvolveWithTypes(current.schema, current.lastColumnID, events, declared); err != nil {
+				return err
+			}
+		}
+		if !evo.Changed() {
+			return nil
+		}
+
+		schemas, ok := w.catalog.(catalog.SchemaCatalog)
+		if !ok {
+			return fmt.Errorf("%w: catalog cannot evolve table schemas", schema.ErrIncompatibleChange)
+		}
+
+		err = schemas.UpdateSchema(ctx, namespace, tableName, current.schema.SchemaID, evo.Schema, evo.LastColumnID)
+		if err == nil {
+			w.cacheSchema(namespace+"."+tableName, tableSchema{schema: evo.Schema, lastColumnID: evo.LastColumnID})
+			w.logger.Info("table schema evolved",
+				"namespace", namespace,
+				"table", tableName,
+				"schema_id", evo.Schema.SchemaID,
+				"added", len(evo.Added),
+				"promoted", len(evo.Promoted),
+				"relaxed", len(evo.Relaxed),
+			)
+			return nil
+		}
+		if !errors.Is(err, catalog.ErrCommitConflict) || attempt >= w.config.CommitRetry.MaxRetries {
+			return fmt.Errorf("update schema: %w", err)
+		}
+
+		w.logger.Warn("schema update conflicted with a concurrent writer, retrying with refreshed metadata",
+			"namespace", namespace,
+			"table", tableName,
+			"attempt", attempt+1,
+		)
+		if current, err = w.loadSchema(ctx, namespace, tableName); err != nil {
+			return err
+		}
+	}
+}
+
 // parseTableKey parses a table key (schema.table) into namespace and table name.
 func (w *IcebergWriter) parseTableKey(tableKey string) (namespace, tableName string) {
 	// Use the source schema as the namespace, or default namespace if not specified
 	parts := strings.SplitN(tableKey, ".", 2)
 	if len(parts) == 2 {
-		return parts[0], parts[1]
+		return w.config.NamespacePrefix + parts[0], parts[1]
 	}
-	return w.config.DefaultNamespace, tableKey
+	return w.config.NamespacePrefix + w.config.DefaultNamespace, tableKey
 }
 
 // getTableDataPath returns the data path for a table.
@@ -0,0 +1,90 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/cdc"
+	"github.com/janovincze/philotes/internal/cdc/buffer"
+	"github.com/janovincze/philotes/internal/cdc/snapshot"
+)
+
+func TestSnapshotRuns(t *testing.T) {
+	row := func(id int64, lsn string) buffer.BufferedEvent {
+		return buffer.BufferedEvent{ID: id, Event: cdc.Event{LSN: lsn, Metadata: map[string]any{snapshot.MetadataSnapshot: true}}}
+	}
+	change := func(id int64, lsn string) buffer.BufferedEvent {
+		return buffer.BufferedEvent{ID: id, Event: cdc.Event{LSN: lsn}}
+	}
+
+	events := []buffer.BufferedEvent{
+		row(1, "0/200"), row(2, "0/1F0"),
+		change(3, "0/210"),
+		row(4, "0/220"),
+		change(5, "0/230"), change(6, "0/240"),
+	}
+	runs := snapshotRuns(events)
+
+	var ids [][]int64
+	for _, run := range runs {
+		var runIDs []int64
+		for _, e := range run {
+			runIDs = append(runIDs, e.ID)
+		}
+		ids = append(ids, runIDs)
+	}
+	if len(ids) != 4 || len(ids[0]) != 2 || ids[1][0] != 3 || ids[2][0] != 4 || len(ids[3]) != 2 {
+		t.Fatalf("snapshotRuns() = %v, want [[1 2] [3] [4] [5 6]]", ids)
+	}
+	if !isSnapshotRow(runs[0][0]) || isSnapshotRow(runs[1][0]) {
+		t.Error("runs do not alternate between snapshot rows and changes")
+	}
+	if got := minLSN(runs[0]); got != "0/1F0" {
+		t.Errorf("minLSN() = %q, want 0/1F0", got)
+	}
+
+	if runs := snapshotRuns(nil); len(runs) != 0 {
+		t.Errorf("snapshotRuns(nil) = %v, want none", runs)
+	}
+}
+
+// memoryStore serves downloads from memory.
+type memoryStore struct {
+	ObjectStore
+
+	objects map[string][]byte
+}
+
+func (s *memoryStore) Download(_ context.Context, bucket, key string) ([]byte, error) {
+	data, ok := s.objects[bucket+"/"+key]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return data, nil
+}
+
+func TestDataFileReader(t *testing.T) {
+	result, err := NewParquetWriter().WriteEvents([]buffer.BufferedEvent{
+		{ID: 1, Event: cdc.Event{Operation: cdc.OperationInsert, After: map[string]any{"id": int64(9007199254740993), "name": "a"}}},
+		{ID: 2, Event: cdc.Event{Operation: cdc.OperationInsert, After: map[string]any{"id": int64(2), "name": "b"}}},
+	})
+	if err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+	store := &memoryStore{objects: map[string][]byte{"warehouse/data/f.parquet": result.Data}}
+
+	rows, err := NewDataFileReader(store).ReadRows(context.Background(), "s3://warehouse/data/f.parquet")
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+	// Keys keep their text rather than losing precision as float64
+	if len(rows) != 2 || rows[0]["id"] != json.Number("9007199254740993") || rows[1]["name"] != "b" {
+		t.Errorf("ReadRows() = %v", rows)
+	}
+
+	if _, err := NewDataFileReader(store).ReadRows(context.Background(), "data/f.parquet"); err == nil {
+		t.Error("ReadRows() of a path without a bucket: expected an error")
+	}
+}
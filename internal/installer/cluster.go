@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeconfig is the part of a kubeconfig file needed to reach its current
+// context's cluster.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server string `yaml:"server"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+	} `yaml:"users"`
+}
+
+// ValidateKubeconfig checks that a kubeconfig for deploying to an existing
+// cluster is complete: its current context must name a cluster with an
+// HTTPS server and a user, all defined in the file. It does not connect to
+// the cluster.
+func ValidateKubeconfig(config string) error {
+	if config == "" {
+		return errors.New("kubeconfig is empty")
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal([]byte(config), &kc); err != nil {
+		return fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if kc.CurrentContext == "" {
+		return errors.New("kubeconfig has no current-context")
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, c := range kc.Contexts {
+		if c.Name == kc.CurrentContext {
+			clusterName, userName = c.Context.Cluster, c.Context.User
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("kubeconfig context %q is not defined", kc.CurrentContext)
+	}
+
+	var server string
+	found = false
+	for _, c := range kc.Clusters {
+		if c.Name == clusterName {
+			server = c.Cluster.Server
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("kubeconfig cluster %q is not defined", clusterName)
+	}
+	serverURL, err := url.Parse(server)
+	if err != nil || serverURL.Scheme != "https" || serverURL.Host == "" {
+		return fmt.Errorf("kubeconfig cluster %q must have an https server", clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name == userName {
+			return nil
+		}
+	}
+	return fmt.Errorf("kubeconfig user %q is not defined", userName)
+}
@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: admin
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://k8s.example.com:6443
+    certificate-authority-data: Y2E=
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestValidateKubeconfig(t *testing.T) {
+	if err := ValidateKubeconfig(testKubeconfig); err != nil {
+		t.Fatalf("ValidateKubeconfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"empty", "", "empty"},
+		{"not yaml", "{", "parse"},
+		{"no current context", strings.Replace(testKubeconfig, "current-context: prod", "", 1), "current-context"},
+		{"unknown context", strings.Replace(testKubeconfig, "current-context: prod", "current-context: dev", 1), `context "dev"`},
+		{"unknown cluster", strings.Replace(testKubeconfig, "cluster: prod-cluster", "cluster: other", 1), `cluster "other"`},
+		{"plain http server", strings.Replace(testKubeconfig, "https://", "http://", 1), "https"},
+		{"unknown user", strings.Replace(testKubeconfig, "user: admin", "user: nobody", 1), `user "nobody"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKubeconfig(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateKubeconfig() error = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package installer
+
+import (
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// pricing is the monthly pricing of a provider's resources in EUR, the same
+// as the Pulumi program estimates a deployment's cost with.
+type pricing struct {
+	// serverCosts maps server types to their monthly cost.
+	serverCosts map[string]float64
+
+	// storagePerGB is the monthly cost of a GB of block storage.
+	storagePerGB float64
+
+	// loadBalancer is the monthly cost of the load balancer.
+	loadBalancer float64
+}
+
+// providerPricing maps provider IDs to their pricing.
+var providerPricing = map[string]pricing{
+	"hetzner":      {serverCosts: hetznerServerCosts, storagePerGB: 0.047, loadBalancer: 5.39},      // lb11
+	"scaleway":     {serverCosts: scalewayServerCosts, storagePerGB: 0.08, loadBalancer: 9.99},      // standard LB
+	"ovh":          {serverCosts: ovhServerCosts, storagePerGB: 0.06, loadBalancer: 9.99},           // small LB
+	"exoscale":     {serverCosts: exoscaleServerCosts, storagePerGB: 0.10, loadBalancer: 15.00},     // NLB base cost
+	"digitalocean": {serverCosts: digitalOceanServerCosts, storagePerGB: 0.09, loadBalancer: 11.00}, // 1 LB node
+	// Storage is included in Contabo's VPS plans, and there is no managed
+	// load balancer; the ingress controller is used instead
+	"contabo": {serverCosts: contaboServerCosts},
+}
+
+// estimate returns the monthly cost breakdown of a cluster.
+func (p pricing) estimate(cpType, workerType string, workerCount, storageGB int) models.CostEstimate {
+	estimate := models.CostEstimate{
+		ControlPlane: p.serverCosts[cpType],
+		Workers:      p.serverCosts[workerType] * float64(workerCount),
+		Storage:      float64(storageGB) * p.storagePerGB,
+		LoadBalancer: p.loadBalancer,
+		Currency:     "EUR",
+	}
+	estimate.Total = estimate.ControlPlane + estimate.Workers + estimate.Storage + estimate.LoadBalancer
+	return estimate
+}
+
+// EstimateCost returns the monthly cost breakdown of deploying a size with
+// a provider, without provisioning anything. It returns nil for an unknown
+// provider or size.
+func EstimateCost(providerID string, sizeID models.DeploymentSize) *models.CostEstimate {
+	p, ok := providerPricing[providerID]
+	if !ok {
+		return nil
+	}
+	size := GetSizeConfig(providerID, sizeID)
+	if size == nil {
+		return nil
+	}
+
+	estimate := p.estimate(size.ControlPlaneType, size.WorkerType, size.WorkerCount, size.StorageSizeGB)
+	estimate.Provider = providerID
+	estimate.Size = string(sizeID)
+	return &estimate
+}
@@ -0,0 +1,55 @@
+package installer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+func TestEstimateCost(t *testing.T) {
+	estimate := EstimateCost("hetzner", models.DeploymentSizeSmall)
+	if estimate == nil {
+		t.Fatal("EstimateCost() = nil")
+	}
+
+	// cpx21 control plane, two cpx21 workers, 50 GB of storage and an lb11
+	want := models.CostEstimate{
+		Provider:     "hetzner",
+		Size:         "small",
+		ControlPlane: 4.35,
+		Workers:      8.70,
+		Storage:      2.35,
+		LoadBalancer: 5.39,
+		Total:        20.79,
+		Currency:     "EUR",
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"control plane", estimate.ControlPlane, want.ControlPlane},
+		{"workers", estimate.Workers, want.Workers},
+		{"storage", estimate.Storage, want.Storage},
+		{"load balancer", estimate.LoadBalancer, want.LoadBalancer},
+		{"total", estimate.Total, want.Total},
+	} {
+		if math.Abs(c.got-c.want) > 0.001 {
+			t.Errorf("%s = %.2f, want %.2f", c.name, c.got, c.want)
+		}
+	}
+
+	// Every size's estimate matches the cost it is listed with
+	for _, provider := range GetProviders() {
+		for _, size := range provider.Sizes {
+			estimate := EstimateCost(provider.ID, size.ID)
+			if estimate == nil || math.Abs(estimate.Total-size.MonthlyCostEUR) > 0.001 {
+				t.Errorf("EstimateCost(%s, %s) = %+v, want a total of %.2f", provider.ID, size.ID, estimate, size.MonthlyCostEUR)
+			}
+		}
+	}
+
+	if EstimateCost("aws", models.DeploymentSizeSmall) != nil || EstimateCost("hetzner", "huge") != nil {
+		t.Error("EstimateCost() of an unknown provider or size != nil")
+	}
+}
@@ -0,0 +1,342 @@
+package installer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovh/go-ovh/ovh"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// ErrCredentialsNotSupplied is returned by CredentialChecker.Check when the
+// deployment has no credentials for its provider, which then authenticates
+// from its own environment variables.
+var ErrCredentialsNotSupplied = errors.New("provider credentials not supplied")
+
+// CredentialErrorKind is why provider credentials failed validation.
+type CredentialErrorKind string
+
+const (
+	// CredentialErrorAuth means the provider rejected the credentials.
+	CredentialErrorAuth CredentialErrorKind = "auth"
+	// CredentialErrorPermission means the credentials are valid but lack
+	// access to the account or project.
+	CredentialErrorPermission CredentialErrorKind = "permission"
+	// CredentialErrorQuota means the provider refused the request because of
+	// a rate limit, quota or billing restriction.
+	CredentialErrorQuota CredentialErrorKind = "quota"
+	// CredentialErrorUnavailable means the provider could not be reached, so
+	// the credentials could not be verified.
+	CredentialErrorUnavailable CredentialErrorKind = "unavailable"
+)
+
+// CredentialError reports provider credentials that failed validation.
+type CredentialError struct {
+	// Provider is the cloud provider.
+	Provider string
+	// Kind is why validation failed.
+	Kind CredentialErrorKind
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error. The messages match the authentication, permission
+// and quota patterns in errorPatterns so the auth step gets the right
+// suggestions.
+func (e *CredentialError) Error() string {
+	var msg string
+	switch e.Kind {
+	case CredentialErrorAuth:
+		msg = "authentication failed: the credentials were rejected"
+	case CredentialErrorPermission:
+		msg = "permission denied: the credentials lack access to the account"
+	case CredentialErrorQuota:
+		msg = "quota exceeded or rate limit reached"
+	default:
+		msg = "service unavailable: the credentials could not be verified"
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %v", e.Provider, msg, e.Err)
+	}
+	return fmt.Sprintf("%s %s", e.Provider, msg)
+}
+
+// Unwrap returns the underlying error.
+func (e *CredentialError) Unwrap() error {
+	return e.Err
+}
+
+// Provider API endpoints used to validate credentials.
+const (
+	hetznerAPIURL      = "https://api.hetzner.cloud/v1"
+	scalewayAPIURL     = "https://api.scaleway.com"
+	digitalOceanAPIURL = "https://api.digitalocean.com/v2"
+	contaboAuthURL     = "https://auth.contabo.com/auth/realms/contabo/protocol/openid-connect/token"
+	contaboAPIURL      = "https://api.contabo.com/v1"
+	// exoscaleAPIURL is formatted with the zone.
+	exoscaleAPIURL = "https://api-%s.exoscale.com/v2"
+)
+
+// CredentialChecker validates cloud provider credentials with a cheap
+// authenticated, read-only API call before any resources are created.
+type CredentialChecker struct {
+	client *http.Client
+	// endpoints overrides the provider API URLs, keyed by the URL constants.
+	endpoints map[string]string
+}
+
+// NewCredentialChecker creates a CredentialChecker using client, or a client
+// with a 30 second timeout if client is nil.
+func NewCredentialChecker(client *http.Client) *CredentialChecker {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &CredentialChecker{client: client}
+}
+
+// Check validates the credentials for provider in region. It returns a
+// *CredentialError if the provider rejects them or cannot be reached.
+func (c *CredentialChecker) Check(ctx context.Context, provider, region string, creds *models.ProviderCredentials) error {
+	if creds == nil {
+		creds = &models.ProviderCredentials{}
+	}
+
+	var err error
+	switch provider {
+	case "hetzner":
+		err = c.checkBearer(ctx, c.endpoint(hetznerAPIURL)+"/locations", creds.HetznerToken)
+	case "scaleway":
+		err = c.checkScaleway(ctx, region, creds)
+	case "ovh":
+		err = c.checkOVH(ctx, creds)
+	case "exoscale":
+		err = c.checkExoscale(ctx, region, creds)
+	case "contabo":
+		err = c.checkContabo(ctx, creds)
+	case "digitalocean":
+		err = c.checkBearer(ctx, c.endpoint(digitalOceanAPIURL)+"/account", creds.DigitalOceanToken)
+	default:
+		return fmt.Errorf("unsupported provider: %s", provider)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrCredentialsNotSupplied) {
+		return err
+	}
+	var credErr *CredentialError
+	if errors.As(err, &credErr) {
+		credErr.Provider = provider
+		return credErr
+	}
+	return &CredentialError{Provider: provider, Kind: CredentialErrorUnavailable, Err: err}
+}
+
+// endpoint returns the URL to use for the provider API URL base.
+func (c *CredentialChecker) endpoint(base string) string {
+	if u, ok := c.endpoints[base]; ok {
+		return u
+	}
+	return base
+}
+
+// checkBearer validates a token sent as a bearer token to target.
+func (c *CredentialChecker) checkBearer(ctx context.Context, target, token string) error {
+	if err := requireCredentials("API token", token); err != nil {
+		return err
+	}
+	return c.get(ctx, target, map[string]string{"Authorization": "Bearer " + token})
+}
+
+// checkScaleway lists the project's servers in the first zone of region.
+func (c *CredentialChecker) checkScaleway(ctx context.Context, region string, creds *models.ProviderCredentials) error {
+	if err := requireCredentials("secret key and project ID", creds.ScalewaySecretKey, creds.ScalewayProjectID); err != nil {
+		return err
+	}
+	target := fmt.Sprintf("%s/instance/v1/zones/%s-1/servers?per_page=1&project=%s",
+		c.endpoint(scalewayAPIURL), region, url.QueryEscape(creds.ScalewayProjectID))
+	return c.get(ctx, target, map[string]string{"X-Auth-Token": creds.ScalewaySecretKey})
+}
+
+// checkOVH reads the Public Cloud project the deployment is created in.
+func (c *CredentialChecker) checkOVH(ctx context.Context, creds *models.ProviderCredentials) error {
+	if err := requireCredentials("application key, application secret, consumer key and service name",
+		creds.OVHApplicationKey, creds.OVHApplicationSecret, creds.OVHConsumerKey, creds.OVHServiceName); err != nil {
+		return err
+	}
+	endpoint := creds.OVHEndpoint
+	if endpoint == "" {
+		endpoint = "ovh-eu"
+	}
+
+	client, err := ovh.NewClient(endpoint, creds.OVHApplicationKey, creds.OVHApplicationSecret, creds.OVHConsumerKey)
+	if err != nil {
+		return &CredentialError{Kind: CredentialErrorAuth, Err: err}
+	}
+	client.Client = c.client
+
+	var project struct{}
+	err = client.GetWithContext(ctx, "/cloud/project/"+url.PathEscape(creds.OVHServiceName), &project)
+	var apiErr *ovh.APIError
+	if errors.As(err, &apiErr) {
+		return statusError(apiErr.Code, apiErr.Message)
+	}
+	return err
+}
+
+// checkExoscale lists the instances in the region's zone. Exoscale signs
+// requests instead of sending the secret.
+func (c *CredentialChecker) checkExoscale(ctx context.Context, zone string, creds *models.ProviderCredentials) error {
+	if err := requireCredentials("API key and secret", creds.ExoscaleAPIKey, creds.ExoscaleAPISecret); err != nil {
+		return err
+	}
+	target := fmt.Sprintf(c.endpoint(exoscaleAPIURL), zone) + "/instance"
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("failed to parse exoscale url: %w", err)
+	}
+
+	expires := time.Now().Add(10 * time.Minute).Unix()
+	message := fmt.Sprintf("GET %s\n\n\n\n%d", u.EscapedPath(), expires)
+	mac := hmac.New(sha256.New, []byte(creds.ExoscaleAPISecret))
+	mac.Write([]byte(message))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return c.get(ctx, target, map[string]string{
+		"Authorization": fmt.Sprintf("EXO2-HMAC-SHA256 credential=%s,expires=%d,signature=%s",
+			creds.ExoscaleAPIKey, expires, signature),
+	})
+}
+
+// checkContabo gets an OAuth token for the API user and lists one instance
+// with it.
+func (c *CredentialChecker) checkContabo(ctx context.Context, creds *models.ProviderCredentials) error {
+	if err := requireCredentials("client ID, client secret, API user and API password",
+		creds.ContaboClientID, creds.ContaboClientSecret, creds.ContaboAPIUser, creds.ContaboAPIPassword); err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"client_id":     {creds.ContaboClientID},
+		"client_secret": {creds.ContaboClientSecret},
+		"username":      {creds.ContaboAPIUser},
+		"password":      {creds.ContaboAPIPassword},
+		"grant_type":    {"password"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(contaboAuthURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The token endpoint answers a wrong user or password with 400
+	// invalid_grant and an unknown client with 401.
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return &CredentialError{Kind: CredentialErrorAuth, Err: fmt.Errorf("token request returned %s", resp.Status)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	return c.get(ctx, c.endpoint(contaboAPIURL)+"/compute/instances?size=1", map[string]string{
+		"Authorization": "Bearer " + token.AccessToken,
+		"x-request-id":  uuid.NewString(),
+	})
+}
+
+// get sends an authenticated GET to target and maps an error status to a
+// *CredentialError.
+func (c *CredentialChecker) get(ctx context.Context, target string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach provider API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drain for connection reuse
+		return nil
+	}
+	return statusError(resp.StatusCode, readErrorBody(resp.Body))
+}
+
+// statusError maps an HTTP error status from a provider API to a
+// *CredentialError.
+func statusError(status int, message string) error {
+	err := fmt.Errorf("provider API returned %d", status)
+	if message != "" {
+		err = fmt.Errorf("provider API returned %d: %s", status, message)
+	}
+
+	switch {
+	case status == http.StatusUnauthorized:
+		return &CredentialError{Kind: CredentialErrorAuth, Err: err}
+	case status == http.StatusPaymentRequired || status == http.StatusTooManyRequests:
+		return &CredentialError{Kind: CredentialErrorQuota, Err: err}
+	case status >= 400 && status < 500:
+		// Forbidden, or a project or service the credentials cannot see
+		return &CredentialError{Kind: CredentialErrorPermission, Err: err}
+	default:
+		return &CredentialError{Kind: CredentialErrorUnavailable, Err: err}
+	}
+}
+
+// requireCredentials checks that all values of the credentials described by
+// what are set. It returns ErrCredentialsNotSupplied if none are.
+func requireCredentials(what string, values ...string) error {
+	set := 0
+	for _, v := range values {
+		if v != "" {
+			set++
+		}
+	}
+	switch set {
+	case len(values):
+		return nil
+	case 0:
+		return ErrCredentialsNotSupplied
+	default:
+		return &CredentialError{Kind: CredentialErrorAuth, Err: fmt.Errorf("%s required", what)}
+	}
+}
+
+// readErrorBody reads a short error message from a response body.
+func readErrorBody(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 512)) //nolint:errcheck // best-effort error body read
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,128 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/janovincze/philotes/internal/api/models"
+)
+
+// newTestChecker returns a CredentialChecker whose provider APIs are served
+// by handler.
+func newTestChecker(t *testing.T, handler http.HandlerFunc) *CredentialChecker {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := NewCredentialChecker(server.Client())
+	c.endpoints = map[string]string{
+		hetznerAPIURL:      server.URL,
+		scalewayAPIURL:     server.URL,
+		digitalOceanAPIURL: server.URL,
+		contaboAuthURL:     server.URL + "/token",
+		contaboAPIURL:      server.URL,
+		exoscaleAPIURL:     server.URL + "/%s",
+	}
+	return c
+}
+
+func TestCredentialChecker_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantKind CredentialErrorKind
+	}{
+		{"valid", http.StatusOK, ""},
+		{"rejected", http.StatusUnauthorized, CredentialErrorAuth},
+		{"forbidden", http.StatusForbidden, CredentialErrorPermission},
+		{"rate limited", http.StatusTooManyRequests, CredentialErrorQuota},
+		{"provider error", http.StatusServiceUnavailable, CredentialErrorUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestChecker(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/locations" || r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("request = %s %s, want the locations with the bearer token", r.URL.Path, r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+			})
+
+			err := c.Check(context.Background(), "hetzner", "nbg1", &models.ProviderCredentials{HetznerToken: "token"})
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+				return
+			}
+
+			var credErr *CredentialError
+			if !errors.As(err, &credErr) {
+				t.Fatalf("Check() error = %v, want a *CredentialError", err)
+			}
+			if credErr.Kind != tt.wantKind || credErr.Provider != "hetzner" {
+				t.Errorf("Check() = %s error for %s, want %s for hetzner", credErr.Kind, credErr.Provider, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestCredentialChecker_Check_Credentials(t *testing.T) {
+	c := newTestChecker(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.URL.Path == "/ch-gva-2/instance":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "EXO2-HMAC-SHA256 credential=key,expires=") {
+				t.Errorf("Authorization = %q, want an EXO2 signature", r.Header.Get("Authorization"))
+			}
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	if err := c.Check(ctx, "digitalocean", "fra1", nil); !errors.Is(err, ErrCredentialsNotSupplied) {
+		t.Errorf("Check(no credentials) error = %v, want ErrCredentialsNotSupplied", err)
+	}
+
+	var credErr *CredentialError
+	err := c.Check(ctx, "scaleway", "fr-par", &models.ProviderCredentials{ScalewaySecretKey: "secret"})
+	if !errors.As(err, &credErr) || credErr.Kind != CredentialErrorAuth {
+		t.Errorf("Check(partial credentials) error = %v, want an auth error", err)
+	}
+
+	err = c.Check(ctx, "contabo", "EU", &models.ProviderCredentials{
+		ContaboClientID: "id", ContaboClientSecret: "secret", ContaboAPIUser: "user", ContaboAPIPassword: "wrong",
+	})
+	if !errors.As(err, &credErr) || credErr.Kind != CredentialErrorAuth {
+		t.Errorf("Check(contabo invalid grant) error = %v, want an auth error", err)
+	}
+
+	if err := c.Check(ctx, "exoscale", "ch-gva-2", &models.ProviderCredentials{ExoscaleAPIKey: "key", ExoscaleAPISecret: "secret"}); err != nil {
+		t.Errorf("Check(exoscale) error = %v", err)
+	}
+}
+
+func TestCredentialError_Suggestion(t *testing.T) {
+	tests := []struct {
+		kind     CredentialErrorKind
+		wantCode string
+	}{
+		{CredentialErrorAuth, "AUTH_FAILED"},
+		{CredentialErrorPermission, "PERMISSION_DENIED"},
+		{CredentialErrorQuota, "QUOTA_EXCEEDED"},
+		{CredentialErrorUnavailable, "PROVIDER_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			err := &CredentialError{Provider: "hetzner", Kind: tt.kind}
+			if got := GetErrorSuggestion(err, "auth"); got.Code != tt.wantCode {
+				t.Errorf("GetErrorSuggestion() code = %s, want %s", got.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package installer
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Destroy check errors.
+var (
+	ErrDeleteProtected            = errors.New("deployment has delete protection enabled")
+	ErrConfirmationMismatch       = errors.New("confirmation does not match the deployment")
+	ErrProductionOverrideRequired = errors.New("destroying a production deployment requires an explicit override")
+)
+
+// productionEnvironment is the environment whose deployments need an
+// explicit override to be destroyed.
+const productionEnvironment = "production"
+
+// DestroyRequest is a request to destroy a deployment's infrastructure.
+type DestroyRequest struct {
+	// DeploymentID is the deployment to destroy.
+	DeploymentID uuid.UUID
+	// Name is the deployment name.
+	Name string
+	// StackName is the Pulumi stack of the deployment.
+	StackName string
+	// Environment is the deployment environment.
+	Environment string
+	// DeleteProtection is set if the deployment is protected from destroy.
+	DeleteProtection bool
+	// Confirmation must equal ConfirmationToken of the deployment.
+	Confirmation string
+	// OverrideProduction allows destroying a production deployment.
+	OverrideProduction bool
+}
+
+// ConfirmationToken returns the token confirming the destroy of a
+// deployment: its name and stack name, joined by a slash.
+func ConfirmationToken(name, stackName string) string {
+	return name + "/" + stackName
+}
+
+// Check returns an error if the request may not destroy the deployment:
+// the deployment must not be delete protected, the confirmation must match
+// it, and production deployments need the override.
+func (r *DestroyRequest) Check() error {
+	if r.DeleteProtection {
+		return ErrDeleteProtected
+	}
+	if r.StackName == "" || r.Confirmation != ConfirmationToken(r.Name, r.StackName) {
+		return ErrConfirmationMismatch
+	}
+	if r.Environment == productionEnvironment && !r.OverrideProduction {
+		return ErrProductionOverrideRequired
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDestroyRequest_Check(t *testing.T) {
+	valid := DestroyRequest{
+		Name:         "analytics",
+		StackName:    "organization/hetzner-1a2b3c4d",
+		Environment:  "staging",
+		Confirmation: "analytics/organization/hetzner-1a2b3c4d",
+	}
+	if err := valid.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *DestroyRequest)
+		want   error
+	}{
+		{"delete protected", func(r *DestroyRequest) { r.DeleteProtection = true }, ErrDeleteProtected},
+		{"protected despite override", func(r *DestroyRequest) {
+			r.DeleteProtection = true
+			r.OverrideProduction = true
+		}, ErrDeleteProtected},
+		{"no confirmation", func(r *DestroyRequest) { r.Confirmation = "" }, ErrConfirmationMismatch},
+		{"name only", func(r *DestroyRequest) { r.Confirmation = "analytics" }, ErrConfirmationMismatch},
+		{"no stack", func(r *DestroyRequest) {
+			r.StackName = ""
+			r.Confirmation = "analytics/"
+		}, ErrConfirmationMismatch},
+		{"production", func(r *DestroyRequest) { r.Environment = "production" }, ErrProductionOverrideRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+			if err := r.Check(); !errors.Is(err, tt.want) {
+				t.Errorf("Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	production := valid
+	production.Environment = "production"
+	production.OverrideProduction = true
+	if err := production.Check(); err != nil {
+		t.Errorf("Check() of an overridden production destroy error = %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package installer
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ResourceChange is a change a deployment would make to a resource.
+type ResourceChange struct {
+	// Op is the planned operation (create, update, replace, delete, import).
+	Op string `json:"op"`
+	// Type is the Pulumi resource type.
+	Type string `json:"type"`
+	// Name is the resource name.
+	Name string `json:"name"`
+	// URN is the Pulumi URN of the resource.
+	URN string `json:"urn"`
+	// Step is the deployment step the resource belongs to.
+	Step string `json:"step"`
+	// Diffs are the properties that would change.
+	Diffs []string `json:"diffs,omitempty"`
+}
+
+// DeploymentPreview holds the changes a deployment would make.
+type DeploymentPreview struct {
+	// DeploymentID is the previewed deployment.
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	// Changes are the planned resource changes.
+	Changes []ResourceChange `json:"changes"`
+	// Summary counts the resources by operation, including unchanged ones.
+	Summary map[string]int `json:"summary"`
+}
+
+// previewOps are the operations listed as planned changes. The steps of a
+// replacement (create-replacement, delete-replaced) are listed as the
+// replace itself; unchanged and read resources are left out.
+var previewOps = map[string]bool{
+	"create":  true,
+	"update":  true,
+	"replace": true,
+	"delete":  true,
+	"import":  true,
+}
+
+// resourceChange returns the planned change of a resource previewed with op,
+// or false if op changes nothing worth listing.
+func (r *DeploymentRunner) resourceChange(op, resourceType, urn string, diffs []string) (ResourceChange, bool) {
+	if !previewOps[op] || resourceType == "pulumi:pulumi:Stack" {
+		return ResourceChange{}, false
+	}
+
+	name := urn
+	if i := strings.LastIndex(urn, "::"); i >= 0 {
+		name = urn[i+2:]
+	}
+
+	return ResourceChange{
+		Op:    op,
+		Type:  resourceType,
+		Name:  name,
+		URN:   urn,
+		Step:  r.mapResourceTypeToStep(resourceType),
+		Diffs: diffs,
+	}, true
+}
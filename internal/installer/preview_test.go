@@ -0,0 +1,39 @@
+package installer
+
+import (
+	"testing"
+)
+
+func TestDeploymentRunner_ResourceChange(t *testing.T) {
+	r := &DeploymentRunner{}
+	urn := "urn:pulumi:dev::philotes::hcloud:index/server:Server::control-plane"
+
+	change, ok := r.resourceChange("update", "hcloud:index/server:Server", urn, []string{"serverType"})
+	if !ok {
+		t.Fatal("resourceChange(update) = false, want a change")
+	}
+	if change.Name != "control-plane" || change.Step != "compute" || change.Op != "update" {
+		t.Errorf("resourceChange(update) = %+v, want the control-plane server in compute", change)
+	}
+	if len(change.Diffs) != 1 || change.Diffs[0] != "serverType" {
+		t.Errorf("Diffs = %v, want [serverType]", change.Diffs)
+	}
+
+	tests := []struct {
+		name         string
+		op           string
+		resourceType string
+	}{
+		{"unchanged", "same", "hcloud:index/server:Server"},
+		{"replacement step", "create-replacement", "hcloud:index/server:Server"},
+		{"read", "read", "hcloud:index/server:Server"},
+		{"stack", "create", "pulumi:pulumi:Stack"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if change, ok := r.resourceChange(tt.op, tt.resourceType, urn, nil); ok {
+				t.Errorf("resourceChange() = %+v, want no change listed", change)
+			}
+		})
+	}
+}
@@ -168,6 +168,17 @@ var providerTimeEstimates = map[string]TimeEstimates{
 		Health:   30000,
 		SSL:      30000,
 	},
+	"digitalocean": {
+		Auth:     5000,
+		Network:  20000,
+		Compute:  60000,  // 60s per droplet
+		K3s:      120000, // 2min
+		Storage:  45000,
+		Catalog:  45000,
+		Philotes: 90000,
+		Health:   30000,
+		SSL:      30000,
+	},
 }
 
 // getTimeEstimates returns time estimates for a provider.
@@ -310,6 +321,53 @@ func GetDeploymentSteps(provider string, workerCount int) []DeploymentStep {
 	}
 }
 
+// GetClusterDeploymentSteps returns the step definitions for a deployment
+// to an existing cluster, which only installs Philotes on it.
+func GetClusterDeploymentSteps() []DeploymentStep {
+	estimates := getTimeEstimates("")
+
+	return []DeploymentStep{
+		{
+			ID:              "auth",
+			Name:            "Authenticating",
+			Description:     "Verifying cluster access",
+			Status:          StepStatusPending,
+			EstimatedTimeMs: estimates.Auth,
+		},
+		{
+			ID:              "philotes",
+			Name:            "Deploying Philotes",
+			Description:     "Installing Philotes services",
+			Status:          StepStatusPending,
+			EstimatedTimeMs: estimates.Philotes,
+			SubSteps: []SubStep{
+				{ID: "api", Name: "Deploying API Server", Status: StepStatusPending},
+				{ID: "worker", Name: "Deploying CDC Worker", Status: StepStatusPending},
+				{ID: "dashboard", Name: "Deploying Dashboard", Status: StepStatusPending},
+			},
+		},
+		{
+			ID:              "health",
+			Name:            "Health Verification",
+			Description:     "Running health checks",
+			Status:          StepStatusPending,
+			EstimatedTimeMs: estimates.Health,
+			SubSteps: []SubStep{
+				{ID: "pods", Name: "Checking Pod Status", Status: StepStatusPending},
+				{ID: "services", Name: "Verifying Services", Status: StepStatusPending},
+				{ID: "endpoints", Name: "Testing Endpoints", Status: StepStatusPending},
+			},
+		},
+		{
+			ID:              "ready",
+			Name:            "Ready!",
+			Description:     "Deployment complete",
+			Status:          StepStatusPending,
+			EstimatedTimeMs: 0,
+		},
+	}
+}
+
 // CalculateTotalEstimate returns the total estimated time for all steps.
 func CalculateTotalEstimate(steps []DeploymentStep) int64 {
 	var total int64
@@ -374,3 +432,18 @@ func StepIDToIndex(steps []DeploymentStep, stepID string) int {
 	}
 	return -1
 }
+
+// StepsBefore returns the IDs of the steps before a step, or nil if there is
+// no such step.
+func StepsBefore(steps []DeploymentStep, stepID string) map[string]bool {
+	stepIndex := StepIDToIndex(steps, stepID)
+	if stepIndex < 0 {
+		return nil
+	}
+
+	before := make(map[string]bool, stepIndex)
+	for i := 0; i < stepIndex; i++ {
+		before[steps[i].ID] = true
+	}
+	return before
+}
@@ -104,8 +104,28 @@ func TestGetDeploymentSteps_StepOrder(t *testing.T) {
 	}
 }
 
+func TestGetClusterDeploymentSteps(t *testing.T) {
+	steps := GetClusterDeploymentSteps()
+
+	// An existing cluster skips the infrastructure steps
+	expectedOrder := []string{"auth", "philotes", "health", "ready"}
+
+	if len(steps) != len(expectedOrder) {
+		t.Fatalf("expected %d steps, got %d", len(expectedOrder), len(steps))
+	}
+
+	for i, expected := range expectedOrder {
+		if steps[i].ID != expected {
+			t.Errorf("step %d: expected ID %s, got %s", i, expected, steps[i].ID)
+		}
+		if steps[i].Status != StepStatusPending {
+			t.Errorf("step %s: expected pending status, got %s", steps[i].ID, steps[i].Status)
+		}
+	}
+}
+
 func TestGetDeploymentSteps_ProviderTimeEstimates(t *testing.T) {
-	providers := []string{"hetzner", "scaleway", "ovh", "exoscale", "contabo"}
+	providers := []string{"hetzner", "scaleway", "ovh", "exoscale", "contabo", "digitalocean"}
 
 	for _, provider := range providers {
 		t.Run(provider, func(t *testing.T) {
@@ -311,6 +331,25 @@ func TestStepIDToIndex(t *testing.T) {
 	}
 }
 
+func TestStepsBefore(t *testing.T) {
+	steps := []DeploymentStep{
+		{ID: "auth"},
+		{ID: "network"},
+		{ID: "compute"},
+	}
+
+	before := StepsBefore(steps, "compute")
+	if len(before) != 2 || !before["auth"] || !before["network"] {
+		t.Errorf("StepsBefore(compute) = %v, want auth and network", before)
+	}
+	if before := StepsBefore(steps, "auth"); before == nil || len(before) != 0 {
+		t.Errorf("StepsBefore(auth) = %v, want no steps", before)
+	}
+	if before := StepsBefore(steps, "nonexistent"); before != nil {
+		t.Errorf("StepsBefore(nonexistent) = %v, want nil", before)
+	}
+}
+
 func TestGenerateComputeSubSteps(t *testing.T) {
 	tests := []struct {
 		workerCount int
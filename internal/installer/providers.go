@@ -14,6 +14,7 @@ func GetProviders() []models.Provider {
 		getOVHProvider(),
 		getExoscaleProvider(),
 		getContaboProvider(),
+		getDigitalOceanProvider(),
 	}
 }
 
@@ -84,12 +85,7 @@ func getHetznerProvider() models.Provider {
 
 // calculateHetznerCost calculates the total monthly cost for a Hetzner deployment.
 func calculateHetznerCost(cpType, workerType string, workerCount, storageGB int) float64 {
-	cpCost := hetznerServerCosts[cpType]
-	workerCost := hetznerServerCosts[workerType] * float64(workerCount)
-	storageCost := float64(storageGB) * 0.047 // €0.047/GB/month
-	lbCost := 5.39                            // lb11
-
-	return cpCost + workerCost + storageCost + lbCost
+	return providerPricing["hetzner"].estimate(cpType, workerType, workerCount, storageGB).Total
 }
 
 // hetznerServerCosts maps server types to monthly costs in EUR.
@@ -160,12 +156,7 @@ func getScalewayProvider() models.Provider {
 
 // calculateScalewayCost calculates the total monthly cost for a Scaleway deployment.
 func calculateScalewayCost(cpType, workerType string, workerCount, storageGB int) float64 {
-	cpCost := scalewayServerCosts[cpType]
-	workerCost := scalewayServerCosts[workerType] * float64(workerCount)
-	storageCost := float64(storageGB) * 0.08 // €0.08/GB/month
-	lbCost := 9.99                           // standard LB
-
-	return cpCost + workerCost + storageCost + lbCost
+	return providerPricing["scaleway"].estimate(cpType, workerType, workerCount, storageGB).Total
 }
 
 // scalewayServerCosts maps instance types to monthly costs in EUR.
@@ -237,12 +228,7 @@ func getOVHProvider() models.Provider {
 
 // calculateOVHCost calculates the total monthly cost for an OVH deployment.
 func calculateOVHCost(cpType, workerType string, workerCount, storageGB int) float64 {
-	cpCost := ovhServerCosts[cpType]
-	workerCost := ovhServerCosts[workerType] * float64(workerCount)
-	storageCost := float64(storageGB) * 0.06 // €0.06/GB/month
-	lbCost := 9.99                           // small load balancer
-
-	return cpCost + workerCost + storageCost + lbCost
+	return providerPricing["ovh"].estimate(cpType, workerType, workerCount, storageGB).Total
 }
 
 // ovhServerCosts maps instance types to monthly costs in EUR.
@@ -315,12 +301,7 @@ func getExoscaleProvider() models.Provider {
 
 // calculateExoscaleCost calculates the total monthly cost for an Exoscale deployment.
 func calculateExoscaleCost(cpType, workerType string, workerCount, storageGB int) float64 {
-	cpCost := exoscaleServerCosts[cpType]
-	workerCost := exoscaleServerCosts[workerType] * float64(workerCount)
-	storageCost := float64(storageGB) * 0.10 // €0.10/GB/month
-	lbCost := 15.00                          // NLB base cost
-
-	return cpCost + workerCost + storageCost + lbCost
+	return providerPricing["exoscale"].estimate(cpType, workerType, workerCount, storageGB).Total
 }
 
 // exoscaleServerCosts maps instance types to monthly costs in EUR.
@@ -393,12 +374,7 @@ func getContaboProvider() models.Provider {
 // calculateContaboCost calculates the total monthly cost for a Contabo deployment.
 // Note: Contabo has no managed LB and storage is included in VPS plans.
 func calculateContaboCost(cpType, workerType string, workerCount int) float64 {
-	cpCost := contaboServerCosts[cpType]
-	workerCost := contaboServerCosts[workerType] * float64(workerCount)
-	// Storage is included in VPS plans
-	// No managed LB available, uses ingress controller
-
-	return cpCost + workerCost
+	return providerPricing["contabo"].estimate(cpType, workerType, workerCount, 0).Total
 }
 
 // contaboServerCosts maps VPS plan types to monthly costs in EUR.
@@ -410,14 +386,90 @@ var contaboServerCosts = map[string]float64{
 	"VPS-XXL": 38.99, // 12 vCPU, 120GB RAM, 3.2TB SSD
 }
 
+// getDigitalOceanProvider returns the DigitalOcean provider configuration.
+func getDigitalOceanProvider() models.Provider {
+	return models.Provider{
+		ID:             "digitalocean",
+		Name:           "DigitalOcean",
+		Description:    "Developer-friendly cloud with simple pricing. Data centers in Europe, North America and Asia-Pacific.",
+		LogoURL:        "/images/providers/digitalocean.svg",
+		OAuthSupported: false,
+		Regions: []models.ProviderRegion{
+			{ID: "fra1", Name: "Frankfurt", Location: "Germany", IsDefault: true, IsAvailable: true},
+			{ID: "ams3", Name: "Amsterdam", Location: "Netherlands", IsAvailable: true},
+			{ID: "lon1", Name: "London", Location: "United Kingdom", IsAvailable: true},
+			{ID: "nyc3", Name: "New York", Location: "United States", IsAvailable: true},
+			{ID: "sfo3", Name: "San Francisco", Location: "United States", IsAvailable: true},
+			{ID: "tor1", Name: "Toronto", Location: "Canada", IsAvailable: true},
+			{ID: "sgp1", Name: "Singapore", Location: "Singapore", IsAvailable: true},
+			{ID: "blr1", Name: "Bangalore", Location: "India", IsAvailable: true},
+			{ID: "syd1", Name: "Sydney", Location: "Australia", IsAvailable: true},
+		},
+		Sizes: []models.ProviderSize{
+			{
+				ID:               models.DeploymentSizeSmall,
+				Name:             "Small",
+				Description:      "Suitable for development and small workloads",
+				MonthlyCostEUR:   calculateDigitalOceanCost("s-2vcpu-4gb", "s-2vcpu-4gb", 2, 50),
+				ControlPlaneType: "s-2vcpu-4gb",
+				WorkerType:       "s-2vcpu-4gb",
+				WorkerCount:      2,
+				StorageSizeGB:    50,
+				VCPU:             6,
+				MemoryGB:         12,
+			},
+			{
+				ID:               models.DeploymentSizeMedium,
+				Name:             "Medium",
+				Description:      "Suitable for production workloads with moderate traffic",
+				MonthlyCostEUR:   calculateDigitalOceanCost("s-2vcpu-4gb", "s-4vcpu-8gb", 3, 100),
+				ControlPlaneType: "s-2vcpu-4gb",
+				WorkerType:       "s-4vcpu-8gb",
+				WorkerCount:      3,
+				StorageSizeGB:    100,
+				VCPU:             14,
+				MemoryGB:         28,
+			},
+			{
+				ID:               models.DeploymentSizeLarge,
+				Name:             "Large",
+				Description:      "Suitable for high-traffic production workloads",
+				MonthlyCostEUR:   calculateDigitalOceanCost("s-4vcpu-8gb", "s-8vcpu-16gb", 5, 200),
+				ControlPlaneType: "s-4vcpu-8gb",
+				WorkerType:       "s-8vcpu-16gb",
+				WorkerCount:      5,
+				StorageSizeGB:    200,
+				VCPU:             44,
+				MemoryGB:         88,
+			},
+		},
+	}
+}
+
+// calculateDigitalOceanCost calculates the total monthly cost for a DigitalOcean deployment.
+func calculateDigitalOceanCost(cpType, workerType string, workerCount, storageGB int) float64 {
+	return providerPricing["digitalocean"].estimate(cpType, workerType, workerCount, storageGB).Total
+}
+
+// digitalOceanServerCosts maps droplet sizes to monthly costs in EUR
+// (USD list prices converted).
+var digitalOceanServerCosts = map[string]float64{
+	"s-1vcpu-2gb":  11.00, // 1 vCPU, 2GB RAM
+	"s-2vcpu-2gb":  16.50, // 2 vCPU, 2GB RAM
+	"s-2vcpu-4gb":  22.00, // 2 vCPU, 4GB RAM
+	"s-4vcpu-8gb":  44.00, // 4 vCPU, 8GB RAM
+	"s-8vcpu-16gb": 88.00, // 8 vCPU, 16GB RAM
+}
+
 // ValidateProvider checks if the given provider ID is supported.
 func ValidateProvider(providerID string) bool {
 	validProviders := map[string]bool{
-		"hetzner":  true,
-		"scaleway": true,
-		"ovh":      true,
-		"exoscale": true,
-		"contabo":  true,
+		"hetzner":      true,
+		"scaleway":     true,
+		"ovh":          true,
+		"exoscale":     true,
+		"contabo":      true,
+		"digitalocean": true,
 	}
 	return validProviders[providerID]
 }
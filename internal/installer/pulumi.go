@@ -3,6 +3,7 @@ package installer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -15,6 +16,7 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/auto"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/events"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optdestroy"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
 	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
 
 	"github.com/janovincze/philotes/internal/api/models"
@@ -25,6 +27,7 @@ type DeploymentRunner struct {
 	workDir      string
 	pulumiOrg    string
 	logger       *slog.Logger
+	credentials  *CredentialChecker
 	mu           sync.RWMutex
 	activeStacks map[uuid.UUID]*auto.Stack
 }
@@ -50,6 +53,7 @@ func NewDeploymentRunner(cfg DeploymentRunnerConfig) *DeploymentRunner {
 		workDir:      cfg.WorkDir,
 		pulumiOrg:    cfg.PulumiOrg,
 		logger:       logger.With("component", "deployment-runner"),
+		credentials:  NewCredentialChecker(nil),
 		activeStacks: make(map[uuid.UUID]*auto.Stack),
 	}
 }
@@ -60,7 +64,7 @@ type DeploymentConfig struct {
 	DeploymentID uuid.UUID
 	// StackName is the name of the Pulumi stack to create/use.
 	StackName string
-	// Provider is the cloud provider (hetzner, scaleway, ovh, exoscale, contabo).
+	// Provider is the cloud provider (hetzner, scaleway, ovh, exoscale, contabo, digitalocean).
 	Provider string
 	// Region is the cloud region.
 	Region string
@@ -87,6 +91,20 @@ func (c *DeploymentConfig) WorkerCount() int {
 	return 2 // Default
 }
 
+// ExistingCluster reports whether the deployment installs Philotes on an
+// existing cluster instead of provisioning one.
+func (c *DeploymentConfig) ExistingCluster() bool {
+	return c.Config != nil && c.Config.Mode == models.DeploymentModeExistingCluster
+}
+
+// Steps returns the steps the deployment runs.
+func (c *DeploymentConfig) Steps() []DeploymentStep {
+	if c.ExistingCluster() {
+		return GetClusterDeploymentSteps()
+	}
+	return GetDeploymentSteps(c.Provider, c.WorkerCount())
+}
+
 // DeploymentResult holds the result of a deployment.
 type DeploymentResult struct {
 	// ControlPlaneIP is the IP address of the control plane node.
@@ -113,11 +131,7 @@ func (r *DeploymentRunner) Deploy(ctx context.Context, cfg *DeploymentConfig, lo
 		"region", cfg.Region,
 	)
 
-	// Generate stack name if not provided
-	stackName := cfg.StackName
-	if stackName == "" {
-		stackName = fmt.Sprintf("%s/%s-%s", r.pulumiOrg, cfg.Provider, cfg.DeploymentID.String()[:8])
-	}
+	stackName := r.StackName(cfg)
 
 	logCallback("info", "initializing", "Initializing Pulumi stack")
 
@@ -228,24 +242,41 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 		"region", cfg.Region,
 	)
 
-	// Complete auth step (credentials already validated)
-	tracker.CompleteStep(cfg.DeploymentID, "auth")
+	// An existing cluster only gets the Philotes release
+	startStep := "network"
+	if cfg.ExistingCluster() {
+		startStep = "philotes"
+	}
 
-	// Generate stack name if not provided
-	stackName := cfg.StackName
-	if stackName == "" {
-		stackName = fmt.Sprintf("%s/%s-%s", r.pulumiOrg, cfg.Provider, cfg.DeploymentID.String()[:8])
+	return r.deployWithTracker(ctx, cfg, startStep, nil, logCallback, tracker)
+}
+
+// deployWithTracker runs a deployment with progress tracking, starting at
+// startStep. The steps in done were completed by an earlier run: Pulumi
+// leaves their resources as they are, and their resources neither change
+// the progress nor are logged again.
+func (r *DeploymentRunner) deployWithTracker(ctx context.Context, cfg *DeploymentConfig, startStep string, done map[string]bool, logCallback LogCallback, tracker *ProgressTracker) (*DeploymentResult, error) {
+	if !done["auth"] {
+		if err := r.checkCredentials(ctx, cfg, logCallback); err != nil {
+			tracker.FailStep(cfg.DeploymentID, "auth", err)
+			return nil, err
+		}
+		tracker.CompleteStep(cfg.DeploymentID, "auth")
 	}
 
-	// Start network step
-	tracker.StartStep(cfg.DeploymentID, "network")
-	logCallback("info", "network", "Initializing Pulumi stack")
+	stackName := r.StackName(cfg)
+
+	// Start the first step; a resumed step was started by the retry
+	if done == nil {
+		tracker.StartStep(cfg.DeploymentID, startStep)
+	}
+	logCallback("info", startStep, "Initializing Pulumi stack")
 
 	// Create or select the stack
 	stack, err := r.createOrSelectStack(ctx, stackName)
 	if err != nil {
-		tracker.FailStep(cfg.DeploymentID, "network", err)
-		logCallback("error", "network", fmt.Sprintf("Failed to initialize stack: %v", err))
+		tracker.FailStep(cfg.DeploymentID, startStep, err)
+		logCallback("error", startStep, fmt.Sprintf("Failed to initialize stack: %v", err))
 		return nil, fmt.Errorf("failed to create/select stack: %w", err)
 	}
 
@@ -260,7 +291,7 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 		r.mu.Unlock()
 	}()
 
-	logCallback("info", "network", "Configuring deployment parameters")
+	logCallback("info", startStep, "Configuring deployment parameters")
 
 	// Set stack configuration
 	tempFiles, err := r.configureStack(ctx, stack, cfg)
@@ -272,12 +303,12 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 		}
 	}()
 	if err != nil {
-		tracker.FailStep(cfg.DeploymentID, "network", err)
-		logCallback("error", "network", fmt.Sprintf("Failed to configure stack: %v", err))
+		tracker.FailStep(cfg.DeploymentID, startStep, err)
+		logCallback("error", startStep, fmt.Sprintf("Failed to configure stack: %v", err))
 		return nil, fmt.Errorf("failed to configure stack: %w", err)
 	}
 
-	logCallback("info", "network", "Provisioning cloud infrastructure")
+	logCallback("info", startStep, "Provisioning cloud infrastructure")
 
 	// Create event stream channel for logging
 	eventsChan := make(chan events.EngineEvent)
@@ -285,7 +316,7 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 	// Start a goroutine to process events with tracker
 	go func() {
 		for event := range eventsChan {
-			r.processEventWithTracker(event, cfg.DeploymentID, logCallback, tracker)
+			r.processEventWithTracker(event, cfg.DeploymentID, done, logCallback, tracker)
 		}
 	}()
 
@@ -310,8 +341,11 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 	}
 
 	// Complete remaining steps
-	tracker.CompleteStep(cfg.DeploymentID, "health")
-	tracker.CompleteStep(cfg.DeploymentID, "ssl")
+	for _, stepID := range []string{"health", "ssl"} {
+		if !done[stepID] {
+			tracker.CompleteStep(cfg.DeploymentID, stepID)
+		}
+	}
 
 	logCallback("info", "completed", "Deployment completed successfully")
 
@@ -335,7 +369,8 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 		}
 	}
 
-	// Derive URLs from load balancer IP
+	// Derive URLs from load balancer IP. An existing cluster has no load
+	// balancer of ours, so its URLs come from the domain only.
 	if deployResult.LoadBalancerIP != "" {
 		if cfg.Config != nil && cfg.Config.Domain != "" {
 			deployResult.DashboardURL = fmt.Sprintf("https://%s", cfg.Config.Domain)
@@ -344,6 +379,9 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 			deployResult.DashboardURL = fmt.Sprintf("http://%s", deployResult.LoadBalancerIP)
 			deployResult.APIURL = fmt.Sprintf("http://%s:8080", deployResult.LoadBalancerIP)
 		}
+	} else if cfg.ExistingCluster() && cfg.Config.Domain != "" {
+		deployResult.DashboardURL = fmt.Sprintf("https://%s", cfg.Config.Domain)
+		deployResult.APIURL = fmt.Sprintf("https://api.%s", cfg.Config.Domain)
 	}
 
 	r.logger.Info("deployment with tracker completed",
@@ -355,6 +393,28 @@ func (r *DeploymentRunner) DeployWithTracker(ctx context.Context, cfg *Deploymen
 	return deployResult, nil
 }
 
+// checkCredentials verifies the provider credentials before any resources
+// are created. An existing cluster is reached with its kubeconfig instead.
+func (r *DeploymentRunner) checkCredentials(ctx context.Context, cfg *DeploymentConfig, logCallback LogCallback) error {
+	if cfg.ExistingCluster() {
+		return nil
+	}
+
+	logCallback("info", "auth", "Verifying provider credentials")
+	err := r.credentials.Check(ctx, cfg.Provider, cfg.Region, cfg.Credentials)
+	switch {
+	case err == nil:
+		logCallback("info", "auth", "Provider credentials verified")
+		return nil
+	case errors.Is(err, ErrCredentialsNotSupplied):
+		logCallback("warn", "auth", "No provider credentials supplied; the provider authenticates from its environment")
+		return nil
+	default:
+		logCallback("error", "auth", fmt.Sprintf("Credential validation failed: %v", err))
+		return fmt.Errorf("failed to verify credentials: %w", err)
+	}
+}
+
 // DeployFromStep runs a deployment starting from a specific step (for retries).
 func (r *DeploymentRunner) DeployFromStep(ctx context.Context, cfg *DeploymentConfig, fromStep string, logCallback LogCallback, tracker *ProgressTracker) (*DeploymentResult, error) {
 	r.logger.Info("resuming deployment from step",
@@ -362,14 +422,123 @@ func (r *DeploymentRunner) DeployFromStep(ctx context.Context, cfg *DeploymentCo
 		"from_step", fromStep,
 	)
 
-	// For now, retry means running the full deployment again
-	// The Pulumi state will handle idempotency for already-created resources
-	return r.DeployWithTracker(ctx, cfg, logCallback, tracker)
+	// The steps before fromStep completed in the failed run. Pulumi only
+	// creates the resources that are missing, so those steps are kept done
+	// and their resources are not reported again.
+	var done map[string]bool
+	if progress := tracker.GetProgress(cfg.DeploymentID); progress != nil {
+		done = StepsBefore(progress.Steps, fromStep)
+	}
+	if done == nil {
+		return nil, fmt.Errorf("unknown deployment step %q", fromStep)
+	}
+
+	return r.deployWithTracker(ctx, cfg, fromStep, done, logCallback, tracker)
+}
+
+// Preview runs pulumi preview with the configuration Deploy would use and
+// returns the changes Deploy would make, without making them.
+func (r *DeploymentRunner) Preview(ctx context.Context, cfg *DeploymentConfig, logCallback LogCallback) (*DeploymentPreview, error) {
+	r.mu.RLock()
+	_, active := r.activeStacks[cfg.DeploymentID]
+	r.mu.RUnlock()
+	if active {
+		return nil, fmt.Errorf("deployment %s is running", cfg.DeploymentID)
+	}
+
+	r.logger.Info("previewing deployment",
+		"deployment_id", cfg.DeploymentID,
+		"provider", cfg.Provider,
+		"region", cfg.Region,
+	)
+
+	stackName := r.StackName(cfg)
+
+	logCallback("info", "preview", "Initializing Pulumi stack")
+
+	// Create or select the stack
+	stack, err := r.createOrSelectStack(ctx, stackName)
+	if err != nil {
+		logCallback("error", "preview", fmt.Sprintf("Failed to initialize stack: %v", err))
+		return nil, fmt.Errorf("failed to create/select stack: %w", err)
+	}
+
+	// Set stack configuration
+	tempFiles, err := r.configureStack(ctx, stack, cfg)
+	defer func() {
+		for _, f := range tempFiles {
+			if removeErr := os.Remove(f); removeErr != nil {
+				r.logger.Debug("failed to remove temp file", "path", f, "error", removeErr)
+			}
+		}
+	}()
+	if err != nil {
+		logCallback("error", "preview", fmt.Sprintf("Failed to configure stack: %v", err))
+		return nil, fmt.Errorf("failed to configure stack: %w", err)
+	}
+
+	logCallback("info", "preview", "Previewing infrastructure changes")
+
+	preview := &DeploymentPreview{
+		DeploymentID: cfg.DeploymentID,
+		Changes:      []ResourceChange{},
+		Summary:      make(map[string]int),
+	}
+
+	// Collect the planned changes from the resource pre events
+	eventsChan := make(chan events.EngineEvent)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for event := range eventsChan {
+			e := event.ResourcePreEvent
+			if e == nil {
+				continue
+			}
+			change, ok := r.resourceChange(string(e.Metadata.Op), e.Metadata.Type, e.Metadata.URN, e.Metadata.Diffs)
+			if ok {
+				preview.Changes = append(preview.Changes, change)
+			}
+		}
+	}()
+
+	// Run pulumi preview
+	result, err := stack.Preview(ctx,
+		optpreview.EventStreams(eventsChan),
+		optpreview.ProgressStreams(io.Discard),
+	)
+	<-collected
+	if err != nil {
+		logCallback("error", "preview", fmt.Sprintf("Preview failed: %v", err))
+		return nil, fmt.Errorf("preview failed: %w", err)
+	}
+
+	for op, count := range result.ChangeSummary {
+		preview.Summary[string(op)] = count
+	}
+
+	logCallback("info", "preview", fmt.Sprintf("Preview completed with %d planned changes", len(preview.Changes)))
+	return preview, nil
 }
 
-// Destroy destroys a deployment.
-func (r *DeploymentRunner) Destroy(ctx context.Context, stackName string, logCallback LogCallback) error {
-	r.logger.Info("destroying deployment", "stack", stackName)
+// StackName returns the Pulumi stack of a deployment: its configured stack,
+// or one named after its provider and ID.
+func (r *DeploymentRunner) StackName(cfg *DeploymentConfig) string {
+	if cfg.StackName != "" {
+		return cfg.StackName
+	}
+	return fmt.Sprintf("%s/%s-%s", r.pulumiOrg, cfg.Provider, cfg.DeploymentID.String()[:8])
+}
+
+// Destroy destroys a deployment's infrastructure, once req passes its
+// checks.
+func (r *DeploymentRunner) Destroy(ctx context.Context, req *DestroyRequest, logCallback LogCallback) error {
+	if err := req.Check(); err != nil {
+		return err
+	}
+
+	stackName := req.StackName
+	r.logger.Info("destroying deployment", "deployment_id", req.DeploymentID, "stack", stackName)
 
 	logCallback("info", "destroying", "Destroying cloud infrastructure")
 
@@ -452,6 +621,10 @@ func (r *DeploymentRunner) createOrSelectStack(ctx context.Context, stackName st
 // configureStack sets the stack configuration.
 // Returns the path to any temp files created (for cleanup) and an error if any.
 func (r *DeploymentRunner) configureStack(ctx context.Context, stack auto.Stack, cfg *DeploymentConfig) (tempFiles []string, err error) {
+	if cfg.ExistingCluster() {
+		return nil, r.configureClusterStack(ctx, stack, cfg)
+	}
+
 	// Get size configuration
 	sizeConfig := GetSizeConfig(cfg.Provider, cfg.Size)
 	if sizeConfig == nil {
@@ -508,6 +681,37 @@ func (r *DeploymentRunner) configureStack(ctx context.Context, stack auto.Stack,
 	return tempFiles, nil
 }
 
+// configureClusterStack sets the configuration of a deployment to an
+// existing cluster. The kubeconfig takes the place of cloud credentials and
+// is validated before it is stored in the stack.
+func (r *DeploymentRunner) configureClusterStack(ctx context.Context, stack auto.Stack, cfg *DeploymentConfig) error {
+	if cfg.Credentials == nil || cfg.Credentials.Kubeconfig == "" {
+		return fmt.Errorf("a kubeconfig is required to deploy to an existing cluster")
+	}
+	if err := ValidateKubeconfig(cfg.Credentials.Kubeconfig); err != nil {
+		return fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	configs := map[string]string{
+		"philotes:mode":        string(models.DeploymentModeExistingCluster),
+		"philotes:environment": cfg.Environment,
+	}
+	if cfg.Config.ChartVersion != "" {
+		configs["philotes:chartVersion"] = cfg.Config.ChartVersion
+	}
+
+	for key, value := range configs {
+		if err := stack.SetConfig(ctx, key, auto.ConfigValue{Value: value}); err != nil {
+			return fmt.Errorf("failed to set config %s: %w", key, err)
+		}
+	}
+
+	if err := stack.SetConfig(ctx, "philotes:kubeconfig", auto.ConfigValue{Value: cfg.Credentials.Kubeconfig, Secret: true}); err != nil {
+		return fmt.Errorf("failed to set kubeconfig: %w", err)
+	}
+	return nil
+}
+
 // setProviderCredentials sets provider-specific credentials as secrets.
 func (r *DeploymentRunner) setProviderCredentials(ctx context.Context, stack auto.Stack, provider string, creds *models.ProviderCredentials) error {
 	switch provider {
@@ -597,6 +801,13 @@ func (r *DeploymentRunner) setProviderCredentials(ctx context.Context, stack aut
 				return fmt.Errorf("failed to set contabo api password: %w", err)
 			}
 		}
+
+	case "digitalocean":
+		if creds.DigitalOceanToken != "" {
+			if err := stack.SetConfig(ctx, "digitalocean:token", auto.ConfigValue{Value: creds.DigitalOceanToken, Secret: true}); err != nil {
+				return fmt.Errorf("failed to set digitalocean token: %w", err)
+			}
+		}
 	}
 
 	return nil
@@ -662,21 +873,29 @@ var resourceTypeToStep = map[string]string{
 	"hcloud:index/firewall:Firewall":                     "network",
 	"scaleway:index/vpcPrivateNetwork:VpcPrivateNetwork": "network",
 	"exoscale:index/securityGroup:SecurityGroup":         "network",
+	"digitalocean:index/vpc:Vpc":                         "network",
+	"digitalocean:index/firewall:Firewall":               "network",
+	"digitalocean:index/tag:Tag":                         "network",
 
 	// Compute resources
-	"hcloud:index/server:Server":       "compute",
-	"hcloud:index/sshKey:SshKey":       "compute",
-	"scaleway:index/instance:Instance": "compute",
-	"exoscale:index/compute:Compute":   "compute",
+	"hcloud:index/server:Server":         "compute",
+	"hcloud:index/sshKey:SshKey":         "compute",
+	"scaleway:index/instance:Instance":   "compute",
+	"exoscale:index/compute:Compute":     "compute",
+	"digitalocean:index/droplet:Droplet": "compute",
+	"digitalocean:index/sshKey:SshKey":   "compute",
 
 	// Load balancer
 	"hcloud:index/loadBalancer:LoadBalancer":               "compute",
 	"hcloud:index/loadBalancerService:LoadBalancerService": "compute",
 	"hcloud:index/loadBalancerTarget:LoadBalancerTarget":   "compute",
+	"digitalocean:index/loadBalancer:LoadBalancer":         "compute",
 
 	// Volume/storage
-	"hcloud:index/volume:Volume":                     "storage",
-	"hcloud:index/volumeAttachment:VolumeAttachment": "storage",
+	"hcloud:index/volume:Volume":                           "storage",
+	"hcloud:index/volumeAttachment:VolumeAttachment":       "storage",
+	"digitalocean:index/volume:Volume":                     "storage",
+	"digitalocean:index/volumeAttachment:VolumeAttachment": "storage",
 
 	// Kubernetes resources
 	"command:remote:Command": "k3s",
@@ -686,7 +905,8 @@ var resourceTypeToStep = map[string]string{
 }
 
 // processEventWithTracker processes events and updates the progress tracker.
-func (r *DeploymentRunner) processEventWithTracker(event events.EngineEvent, deploymentID uuid.UUID, logCallback LogCallback, tracker *ProgressTracker) {
+// Resources of the steps in done are ignored.
+func (r *DeploymentRunner) processEventWithTracker(event events.EngineEvent, deploymentID uuid.UUID, done map[string]bool, logCallback LogCallback, tracker *ProgressTracker) {
 	// Handle diagnostic events
 	if e := event.DiagnosticEvent; e != nil {
 		level := "info"
@@ -707,6 +927,9 @@ func (r *DeploymentRunner) processEventWithTracker(event events.EngineEvent, dep
 		if e.Metadata.Type != "" {
 			// Determine which step this resource belongs to
 			stepID := r.mapResourceTypeToStep(e.Metadata.Type)
+			if done[stepID] {
+				return
+			}
 			if stepID != "" {
 				// Get current progress
 				progress := tracker.GetProgress(deploymentID)
@@ -738,6 +961,9 @@ func (r *DeploymentRunner) processEventWithTracker(event events.EngineEvent, dep
 	if e := event.ResOutputsEvent; e != nil {
 		if e.Metadata.Type != "" {
 			stepID := r.mapResourceTypeToStep(e.Metadata.Type)
+			if done[stepID] {
+				return
+			}
 			resourceName := r.extractResourceName(e.Metadata.URN)
 
 			// Track created resource
@@ -100,8 +100,9 @@ func (o *DeploymentOrchestrator) RetryDeployment(ctx context.Context, deployment
 		return ErrNotRetryable
 	}
 
-	// Reset the failed step and subsequent steps
-	o.tracker.ResetStep(deploymentID, failedStep.ID)
+	// Keep the steps before the failed one done and reset it and the
+	// subsequent steps
+	o.tracker.ResumeFrom(deploymentID, failedStep.ID)
 
 	// Log the retry
 	o.logger.Info("retrying deployment",
@@ -36,10 +36,19 @@ func NewProgressTracker(hub *LogHub, logger *slog.Logger) *ProgressTracker {
 
 // InitProgress initializes progress tracking for a deployment.
 func (t *ProgressTracker) InitProgress(deploymentID uuid.UUID, provider string, workerCount int) *DeploymentProgress {
+	t.logger.Debug("initializing progress tracking",
+		"deployment_id", deploymentID,
+		"provider", provider,
+		"worker_count", workerCount,
+	)
+	return t.InitSteps(deploymentID, GetDeploymentSteps(provider, workerCount))
+}
+
+// InitSteps initializes progress tracking for a deployment running steps.
+func (t *ProgressTracker) InitSteps(deploymentID uuid.UUID, steps []DeploymentStep) *DeploymentProgress {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	steps := GetDeploymentSteps(provider, workerCount)
 	now := time.Now()
 
 	progress := &DeploymentProgress{
@@ -57,8 +66,6 @@ func (t *ProgressTracker) InitProgress(deploymentID uuid.UUID, provider string,
 
 	t.logger.Debug("initialized progress tracking",
 		"deployment_id", deploymentID,
-		"provider", provider,
-		"worker_count", workerCount,
 		"total_steps", len(steps),
 	)
 
@@ -307,6 +314,46 @@ func (t *ProgressTracker) ResetStep(deploymentID uuid.UUID, stepID string) {
 		return
 	}
 
+	t.resetFrom(deploymentID, progress, stepIndex)
+}
+
+// ResumeFrom prepares a deployment to resume from a step: the steps before
+// it are marked completed, keeping the completion times of those that
+// already were, and the step and all following steps are reset to pending.
+func (t *ProgressTracker) ResumeFrom(deploymentID uuid.UUID, stepID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	progress := t.progress[deploymentID]
+	if progress == nil {
+		return
+	}
+
+	stepIndex := StepIDToIndex(progress.Steps, stepID)
+	if stepIndex < 0 {
+		return
+	}
+
+	now := time.Now()
+	for i := 0; i < stepIndex; i++ {
+		step := &progress.Steps[i]
+		if step.Status == StepStatusCompleted || step.Status == StepStatusSkipped {
+			continue
+		}
+		step.Status = StepStatusCompleted
+		step.CompletedAt = &now
+		step.Error = nil
+		for j := range step.SubSteps {
+			step.SubSteps[j].Status = StepStatusCompleted
+		}
+	}
+
+	t.resetFrom(deploymentID, progress, stepIndex)
+}
+
+// resetFrom resets the step at stepIndex and all following steps to
+// pending. Must be called with the lock held.
+func (t *ProgressTracker) resetFrom(deploymentID uuid.UUID, progress *DeploymentProgress, stepIndex int) {
 	// Reset this step and all following steps
 	for i := stepIndex; i < len(progress.Steps); i++ {
 		step := &progress.Steps[i]
@@ -334,7 +381,7 @@ func (t *ProgressTracker) ResetStep(deploymentID uuid.UUID, stepID string) {
 
 	t.logger.Debug("reset step",
 		"deployment_id", deploymentID,
-		"step_id", stepID,
+		"step_id", progress.Steps[stepIndex].ID,
 		"from_index", stepIndex,
 	)
 
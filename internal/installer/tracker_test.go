@@ -352,6 +352,91 @@ func TestProgressTracker_ResetStep(t *testing.T) {
 	}
 }
 
+func TestProgressTracker_ResumeFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		completed  []string
+		failedStep string
+	}{
+		{"k3s", []string{"auth", "network", "compute"}, "k3s"},
+		{"ssl", []string{"auth", "network", "compute", "k3s", "storage", "catalog", "philotes", "health"}, "ssl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewProgressTracker(nil, nil)
+			deploymentID := uuid.New()
+			tracker.InitProgress(deploymentID, "hetzner", 2)
+
+			for _, stepID := range tt.completed {
+				tracker.StartStep(deploymentID, stepID)
+				tracker.CompleteStep(deploymentID, stepID)
+			}
+			tracker.StartStep(deploymentID, tt.failedStep)
+			tracker.FailStep(deploymentID, tt.failedStep, errors.New("timeout"))
+
+			progress := tracker.GetProgress(deploymentID)
+			completedAt := *findStepByID(progress.Steps, "network").CompletedAt
+
+			tracker.ResumeFrom(deploymentID, tt.failedStep)
+
+			for _, stepID := range tt.completed {
+				if step := findStepByID(progress.Steps, stepID); step.Status != StepStatusCompleted {
+					t.Errorf("%s status = %s, want completed", stepID, step.Status)
+				}
+			}
+			if got := *findStepByID(progress.Steps, "network").CompletedAt; !got.Equal(completedAt) {
+				t.Error("network completion time changed")
+			}
+
+			failed := findStepByID(progress.Steps, tt.failedStep)
+			if failed.Status != StepStatusPending || failed.Error != nil {
+				t.Errorf("%s = %s with error %v, want pending without an error", tt.failedStep, failed.Status, failed.Error)
+			}
+			if progress.CurrentStepIndex != StepIDToIndex(progress.Steps, tt.failedStep) {
+				t.Errorf("CurrentStepIndex = %d, want the index of %s", progress.CurrentStepIndex, tt.failedStep)
+			}
+			if want := len(tt.completed) * 100 / len(progress.Steps); progress.OverallProgress != want {
+				t.Errorf("OverallProgress = %d, want %d", progress.OverallProgress, want)
+			}
+
+			// The resumed step runs and completes as in a first run
+			tracker.StartStep(deploymentID, tt.failedStep)
+			tracker.CompleteStep(deploymentID, tt.failedStep)
+			if failed.Status != StepStatusCompleted {
+				t.Errorf("%s status = %s after resuming, want completed", tt.failedStep, failed.Status)
+			}
+		})
+	}
+}
+
+func TestProgressTracker_ResumeFrom_CompletesEarlierSteps(t *testing.T) {
+	tracker := NewProgressTracker(nil, nil)
+	deploymentID := uuid.New()
+	tracker.InitProgress(deploymentID, "hetzner", 2)
+
+	// Resuming from k3s marks the steps before it done even if the run
+	// that failed never reported them complete
+	tracker.StartStep(deploymentID, "compute")
+	tracker.ResumeFrom(deploymentID, "k3s")
+
+	progress := tracker.GetProgress(deploymentID)
+	for _, stepID := range []string{"auth", "network", "compute"} {
+		step := findStepByID(progress.Steps, stepID)
+		if step.Status != StepStatusCompleted || step.CompletedAt == nil {
+			t.Errorf("%s status = %s, want completed", stepID, step.Status)
+		}
+		for _, sub := range step.SubSteps {
+			if sub.Status != StepStatusCompleted {
+				t.Errorf("%s sub-step %s status = %s, want completed", stepID, sub.ID, sub.Status)
+			}
+		}
+	}
+	if step := findStepByID(progress.Steps, "storage"); step.Status != StepStatusPending {
+		t.Errorf("storage status = %s, want pending", step.Status)
+	}
+}
+
 func TestProgressTracker_MarkComplete(t *testing.T) {
 	tracker := NewProgressTracker(nil, nil)
 	deploymentID := uuid.New()
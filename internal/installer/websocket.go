@@ -64,11 +64,36 @@ type StepUpdate struct {
 	ElapsedTimeMs int64 `json:"elapsed_time_ms"`
 }
 
-// LogHub manages WebSocket connections for deployment log streaming.
+// Log streaming limits.
+const (
+	// logHistorySize is the number of messages kept per deployment and
+	// replayed to clients that connect after a deployment started.
+	logHistorySize = 1000
+
+	// subscriberQueueSize is the number of messages queued per client. A
+	// client that falls further behind loses its oldest queued messages.
+	subscriberQueueSize = logHistorySize
+
+	// writeTimeout bounds writing a message to a client.
+	writeTimeout = 10 * time.Second
+
+	// pongTimeout is how long a client may go without answering pings.
+	pongTimeout = 60 * time.Second
+
+	// pingInterval is how often clients are pinged.
+	pingInterval = 30 * time.Second
+)
+
+// LogHub manages WebSocket connections for deployment log streaming. It
+// keeps the recent messages of each deployment, so clients connecting late
+// first receive the history. Each client has its own queue and writer, so a
+// slow client neither blocks the deployment nor the other clients.
 type LogHub struct {
 	// connections maps deployment IDs to their subscribers.
-	connections map[uuid.UUID]map[*websocket.Conn]bool
-	// mu protects the connections map.
+	connections map[uuid.UUID]map[*subscriber]bool
+	// history maps deployment IDs to their recent messages.
+	history map[uuid.UUID]*messageRing
+	// mu protects the connections and history maps.
 	mu sync.RWMutex
 	// logger is the structured logger.
 	logger *slog.Logger
@@ -83,7 +108,8 @@ func NewLogHub(logger *slog.Logger) *LogHub {
 	}
 
 	return &LogHub{
-		connections: make(map[uuid.UUID]map[*websocket.Conn]bool),
+		connections: make(map[uuid.UUID]map[*subscriber]bool),
+		history:     make(map[uuid.UUID]*messageRing),
 		logger:      logger.With("component", "log-hub"),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
@@ -96,77 +122,144 @@ func NewLogHub(logger *slog.Logger) *LogHub {
 	}
 }
 
-// Subscribe adds a WebSocket connection to receive logs for a deployment.
-func (h *LogHub) Subscribe(deploymentID uuid.UUID, conn *websocket.Conn) {
+// subscriber is a client streaming a deployment's messages.
+type subscriber struct {
+	conn *websocket.Conn
+	// send queues the encoded messages for the writer.
+	send chan []byte
+	// done is closed when the client disconnects or is closed.
+	done      chan struct{}
+	closeOnce sync.Once
+	// dropped counts the messages dropped because the client fell behind.
+	dropped int
+}
+
+func newSubscriber(conn *websocket.Conn) *subscriber {
+	return &subscriber{
+		conn: conn,
+		send: make(chan []byte, subscriberQueueSize),
+		done: make(chan struct{}),
+	}
+}
+
+// enqueue queues a message, dropping the oldest queued message if the queue
+// is full. Calls must not be concurrent; the hub holds its lock.
+func (s *subscriber) enqueue(data []byte) {
+	for {
+		select {
+		case s.send <- data:
+			return
+		default:
+		}
+		select {
+		case <-s.send:
+			s.dropped++
+		default:
+		}
+	}
+}
+
+// close disconnects the subscriber.
+func (s *subscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// messageRing keeps the most recent messages up to logHistorySize.
+type messageRing struct {
+	messages [][]byte
+	// start is the index of the oldest message once the ring is full.
+	start int
+}
+
+// add adds a message, replacing the oldest one if the ring is full.
+func (r *messageRing) add(data []byte) {
+	if len(r.messages) < logHistorySize {
+		r.messages = append(r.messages, data)
+		return
+	}
+	r.messages[r.start] = data
+	r.start = (r.start + 1) % len(r.messages)
+}
+
+// all returns the messages from oldest to newest.
+func (r *messageRing) all() [][]byte {
+	all := make([][]byte, 0, len(r.messages))
+	all = append(all, r.messages[r.start:]...)
+	return append(all, r.messages[:r.start]...)
+}
+
+// subscribe replays the deployment's history to a subscriber and adds it to
+// the deployment's subscribers.
+func (h *LogHub) subscribe(deploymentID uuid.UUID, sub *subscriber) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if ring := h.history[deploymentID]; ring != nil {
+		for _, data := range ring.all() {
+			sub.enqueue(data)
+		}
+	}
+
 	if h.connections[deploymentID] == nil {
-		h.connections[deploymentID] = make(map[*websocket.Conn]bool)
+		h.connections[deploymentID] = make(map[*subscriber]bool)
 	}
-	h.connections[deploymentID][conn] = true
+	h.connections[deploymentID][sub] = true
 
 	h.logger.Debug("client subscribed", "deployment_id", deploymentID)
 }
 
-// Unsubscribe removes a WebSocket connection from a deployment's subscribers.
-func (h *LogHub) Unsubscribe(deploymentID uuid.UUID, conn *websocket.Conn) {
+// unsubscribe removes a subscriber from a deployment's subscribers.
+func (h *LogHub) unsubscribe(deploymentID uuid.UUID, sub *subscriber) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if conns, ok := h.connections[deploymentID]; ok {
-		delete(conns, conn)
-		if len(conns) == 0 {
+	if subs, ok := h.connections[deploymentID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
 			delete(h.connections, deploymentID)
 		}
 	}
 
+	if sub.dropped > 0 {
+		h.logger.Debug("client fell behind", "deployment_id", deploymentID, "dropped", sub.dropped)
+	}
 	h.logger.Debug("client unsubscribed", "deployment_id", deploymentID)
 }
 
-// Broadcast sends a message to all subscribers of a deployment.
+// Broadcast sends a message to all subscribers of a deployment and adds it
+// to the deployment's history.
 func (h *LogHub) Broadcast(deploymentID uuid.UUID, msg LogMessage) {
-	// Copy connection references while holding the lock to avoid data race
-	h.mu.RLock()
-	connMap := h.connections[deploymentID]
-	if len(connMap) == 0 {
-		h.mu.RUnlock()
-		return
-	}
-	// Make a copy of the connections slice to avoid race with Unsubscribe
-	conns := make([]*websocket.Conn, 0, len(connMap))
-	for conn := range connMap {
-		conns = append(conns, conn)
-	}
-	h.mu.RUnlock()
-
 	data, err := json.Marshal(msg)
 	if err != nil {
 		h.logger.Error("failed to marshal log message", "error", err)
 		return
 	}
 
-	var toRemove []*websocket.Conn
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	for _, conn := range conns {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			h.logger.Debug("failed to send message", "error", err)
-			toRemove = append(toRemove, conn)
-		}
+	ring := h.history[deploymentID]
+	if ring == nil {
+		ring = &messageRing{}
+		h.history[deploymentID] = ring
 	}
+	ring.add(data)
 
-	// Remove failed connections
-	if len(toRemove) > 0 {
-		h.mu.Lock()
-		for _, conn := range toRemove {
-			if connMap, ok := h.connections[deploymentID]; ok {
-				delete(connMap, conn)
-			}
-		}
-		h.mu.Unlock()
+	for sub := range h.connections[deploymentID] {
+		sub.enqueue(data)
 	}
 }
 
+// Forget drops the history of a deployment, such as after it was deleted.
+func (h *LogHub) Forget(deploymentID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.history, deploymentID)
+}
+
 // BroadcastLog is a convenience method to broadcast a log message.
 func (h *LogHub) BroadcastLog(deploymentID uuid.UUID, level, step, message string) {
 	h.Broadcast(deploymentID, LogMessage{
@@ -225,15 +318,17 @@ func (h *LogHub) BroadcastErrorWithSuggestions(deploymentID uuid.UUID, stepID st
 
 // HandleWebSocket handles WebSocket connections for deployment logs.
 // This is the HTTP handler that should be mounted at the WebSocket endpoint.
+// The client first receives a connection confirmation, then the
+// deployment's history, then its messages as they are broadcast.
 func (h *LogHub) HandleWebSocket(w http.ResponseWriter, r *http.Request, deploymentID uuid.UUID) error {
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return err
 	}
 
-	h.Subscribe(deploymentID, conn)
+	sub := newSubscriber(conn)
 
-	// Send initial connection confirmation
+	// Queue the connection confirmation ahead of the history
 	msg := LogMessage{
 		Type:         "connected",
 		DeploymentID: deploymentID,
@@ -241,47 +336,63 @@ func (h *LogHub) HandleWebSocket(w http.ResponseWriter, r *http.Request, deploym
 		Message:      "Connected to deployment log stream",
 	}
 	if data, err := json.Marshal(msg); err == nil {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			h.logger.Debug("failed to send connection confirmation", "error", err)
-		}
+		sub.enqueue(data)
 	}
 
-	// Handle connection lifecycle in a goroutine
-	go h.handleConnection(deploymentID, conn)
+	h.subscribe(deploymentID, sub)
+
+	// Handle connection lifecycle in goroutines
+	go h.readMessages(sub)
+	go h.writeMessages(deploymentID, sub)
 
 	return nil
 }
 
-// handleConnection manages a WebSocket connection's lifecycle.
-func (h *LogHub) handleConnection(deploymentID uuid.UUID, conn *websocket.Conn) {
-	defer func() {
-		h.Unsubscribe(deploymentID, conn)
-		conn.Close()
-	}()
+// readMessages reads from a client, mainly for ping/pong and close
+// handling, until it disconnects.
+func (h *LogHub) readMessages(sub *subscriber) {
+	defer sub.close()
 
-	// Set up ping/pong to detect dead connections
-	conn.SetPongHandler(func(string) error {
-		return conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	_ = sub.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	sub.conn.SetPongHandler(func(string) error {
+		return sub.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 	})
 
-	// Start ping ticker
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	// Read messages (mainly for ping/pong and close handling)
-	go func() {
-		for {
-			_, _, err := conn.ReadMessage()
-			if err != nil {
-				return
-			}
+	for {
+		if _, _, err := sub.conn.ReadMessage(); err != nil {
+			return
 		}
+	}
+}
+
+// writeMessages writes the queued messages and pings to a client until it
+// disconnects. It is the only writer of the connection.
+func (h *LogHub) writeMessages(deploymentID uuid.UUID, sub *subscriber) {
+	defer func() {
+		h.unsubscribe(deploymentID, sub)
+		sub.close()
+		sub.conn.Close()
 	}()
 
-	// Send pings periodically
-	for range ticker.C {
-		if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+	// Ping periodically to detect dead connections
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.done:
 			return
+		case data := <-sub.send:
+			_ = sub.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := sub.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				h.logger.Debug("failed to send message", "error", err)
+				return
+			}
+		case <-ticker.C:
+			_ = sub.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := sub.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
@@ -291,9 +402,9 @@ func (h *LogHub) Close() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	for deploymentID, conns := range h.connections {
-		for conn := range conns {
-			conn.Close()
+	for deploymentID, subs := range h.connections {
+		for sub := range subs {
+			sub.close()
 		}
 		delete(h.connections, deploymentID)
 	}
@@ -336,8 +447,7 @@ func (o *DeploymentOrchestrator) GetTracker() *ProgressTracker {
 // StartDeployment starts a deployment asynchronously with WebSocket log streaming.
 func (o *DeploymentOrchestrator) StartDeployment(ctx context.Context, cfg *DeploymentConfig, statusCallback func(status string, err error)) {
 	// Initialize progress tracking
-	workerCount := cfg.WorkerCount()
-	o.tracker.InitProgress(cfg.DeploymentID, cfg.Provider, workerCount)
+	o.tracker.InitSteps(cfg.DeploymentID, cfg.Steps())
 
 	// Create log callback that broadcasts to WebSocket subscribers
 	logCallback := o.hub.CreateLogCallback(cfg.DeploymentID)
@@ -368,6 +478,44 @@ func (o *DeploymentOrchestrator) StartDeployment(ctx context.Context, cfg *Deplo
 	}()
 }
 
+// PreviewDeployment previews the changes a deployment would make, streaming
+// its logs like a deployment's.
+func (o *DeploymentOrchestrator) PreviewDeployment(ctx context.Context, cfg *DeploymentConfig) (*DeploymentPreview, error) {
+	return o.runner.Preview(ctx, cfg, o.hub.CreateLogCallback(cfg.DeploymentID))
+}
+
+// StackName returns the Pulumi stack of a deployment.
+func (o *DeploymentOrchestrator) StackName(cfg *DeploymentConfig) string {
+	return o.runner.StackName(cfg)
+}
+
+// DestroyDeployment destroys a deployment's infrastructure asynchronously
+// with WebSocket log streaming. It returns the check error without starting
+// if req may not destroy the deployment.
+func (o *DeploymentOrchestrator) DestroyDeployment(ctx context.Context, req *DestroyRequest, statusCallback func(status string, err error)) error {
+	if err := req.Check(); err != nil {
+		return err
+	}
+
+	logCallback := o.hub.CreateLogCallback(req.DeploymentID)
+
+	go func() {
+		o.hub.BroadcastStatus(req.DeploymentID, "destroying")
+		statusCallback("destroying", nil)
+
+		if err := o.runner.Destroy(ctx, req, logCallback); err != nil {
+			o.hub.BroadcastStatus(req.DeploymentID, "failed")
+			statusCallback("failed", err)
+			return
+		}
+
+		o.hub.BroadcastStatus(req.DeploymentID, "destroyed")
+		statusCallback("destroyed", nil)
+	}()
+
+	return nil
+}
+
 // GetProgress returns the current progress for a deployment.
 func (o *DeploymentOrchestrator) GetProgress(deploymentID uuid.UUID) *DeploymentProgress {
 	return o.tracker.GetProgress(deploymentID)
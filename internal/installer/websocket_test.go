@@ -0,0 +1,138 @@
+package installer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+func TestMessageRing(t *testing.T) {
+	var ring messageRing
+	for i := 0; i < logHistorySize+2; i++ {
+		ring.add([]byte(strconv.Itoa(i)))
+	}
+
+	all := ring.all()
+	if len(all) != logHistorySize {
+		t.Fatalf("len(all()) = %d, want %d", len(all), logHistorySize)
+	}
+	if first, last := string(all[0]), string(all[len(all)-1]); first != "2" || last != strconv.Itoa(logHistorySize+1) {
+		t.Errorf("all() = %s ... %s, want the oldest two messages dropped", first, last)
+	}
+}
+
+func TestSubscriber_EnqueueDropsOldest(t *testing.T) {
+	sub := newSubscriber(nil)
+	for i := 0; i < subscriberQueueSize+3; i++ {
+		sub.enqueue([]byte(strconv.Itoa(i)))
+	}
+
+	if sub.dropped != 3 {
+		t.Errorf("dropped = %d, want 3", sub.dropped)
+	}
+	if first := <-sub.send; string(first) != "3" {
+		t.Errorf("first queued message = %s, want message 3", first)
+	}
+}
+
+// dialLogHub serves hub's stream of a deployment and connects a client.
+func dialLogHub(t *testing.T, hub *LogHub, deploymentID uuid.UUID) *websocket.Conn {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := hub.HandleWebSocket(w, r, deploymentID); err != nil {
+			t.Errorf("HandleWebSocket() error = %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readLogMessage reads the next message from a client connection.
+func readLogMessage(t *testing.T, conn *websocket.Conn) LogMessage {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	var msg LogMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	return msg
+}
+
+func TestLogHub_ReplaysHistoryToLateClients(t *testing.T) {
+	hub := NewLogHub(nil)
+	defer hub.Close()
+	deploymentID := uuid.New()
+
+	// Messages broadcast before the client connects
+	hub.BroadcastStatus(deploymentID, "provisioning")
+	hub.BroadcastLog(deploymentID, "info", "network", "Initializing Pulumi stack")
+
+	conn := dialLogHub(t, hub, deploymentID)
+
+	if msg := readLogMessage(t, conn); msg.Type != "connected" {
+		t.Errorf("first message type = %q, want connected", msg.Type)
+	}
+	if msg := readLogMessage(t, conn); msg.Type != "status" || msg.Status != "provisioning" {
+		t.Errorf("second message = %+v, want the provisioning status", msg)
+	}
+	if msg := readLogMessage(t, conn); msg.Type != "log" || msg.Message != "Initializing Pulumi stack" {
+		t.Errorf("third message = %+v, want the replayed log", msg)
+	}
+
+	// Messages broadcast afterwards follow the history
+	hub.BroadcastLog(deploymentID, "info", "compute", "Creating server")
+	if msg := readLogMessage(t, conn); msg.Step != "compute" || msg.Message != "Creating server" {
+		t.Errorf("live message = %+v, want the compute log", msg)
+	}
+
+	hub.Forget(deploymentID)
+	late := dialLogHub(t, hub, deploymentID)
+	readLogMessage(t, late)
+	hub.BroadcastLog(deploymentID, "info", "k3s", "Installing K3s")
+	if msg := readLogMessage(t, late); msg.Step != "k3s" {
+		t.Errorf("message after Forget() = %+v, want no history replayed", msg)
+	}
+}
+
+func TestLogHub_UnsubscribesDisconnectedClients(t *testing.T) {
+	hub := NewLogHub(nil)
+	defer hub.Close()
+	deploymentID := uuid.New()
+
+	conn := dialLogHub(t, hub, deploymentID)
+	readLogMessage(t, conn)
+	conn.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		hub.mu.RLock()
+		subscribers := len(hub.connections[deploymentID])
+		hub.mu.RUnlock()
+		if subscribers == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d subscribers left after the client disconnected", subscribers)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
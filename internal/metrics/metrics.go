@@ -2,10 +2,12 @@
 package metrics
 
 import (
+	"net/http"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var registerOnce sync.Once
@@ -30,6 +32,11 @@ const (
 	LabelMethod    = "method"
 	LabelStatus    = "status"
 	LabelErrorType = "error_type"
+	LabelResult    = "result"
+	LabelKind      = "kind"
+	LabelPolicy    = "policy"
+	LabelSink      = "sink"
+	LabelSignal    = "signal"
 )
 
 var (
@@ -57,6 +64,63 @@ var (
 		[]string{LabelSource, LabelTable},
 	)
 
+	// CDCEventsReadTotal counts the changes read from the source.
+	CDCEventsReadTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "events_read_total",
+			Help:      "Total number of change events read from the source",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCEventsWrittenTotal counts the events the pipeline wrote to the buffer.
+	CDCEventsWrittenTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "events_written_total",
+			Help:      "Total number of events written to the buffer",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCCheckpointLagBytes tracks how many bytes of WAL the last saved
+	// checkpoint trails the last processed event.
+	CDCCheckpointLagBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "checkpoint_lag_bytes",
+			Help:      "Bytes of WAL between the last processed event and the last saved checkpoint",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCBackpressurePausesTotal counts pipeline pauses due to backpressure,
+	// by the signal that triggered them.
+	CDCBackpressurePausesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "backpressure_pauses_total",
+			Help:      "Total number of times backpressure paused the pipeline, by the signal that triggered the pause",
+		},
+		[]string{LabelSource, LabelSignal},
+	)
+
+	// CDCBackpressureResumesTotal counts pipeline resumes after backpressure cleared.
+	CDCBackpressureResumesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "backpressure_resumes_total",
+			Help:      "Total number of times the pipeline resumed after backpressure cleared",
+		},
+		[]string{LabelSource},
+	)
+
 	// CDCErrorsTotal counts the total number of CDC errors.
 	CDCErrorsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -91,6 +155,117 @@ var (
 		[]string{LabelSource},
 	)
 
+	// CDCReplicationGap is 1 while a pipeline is halted on a replication gap.
+	CDCReplicationGap = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "replication_gap",
+			Help:      "Whether the pipeline is halted on a replication gap (1) or not (0)",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCReplicationLagBytes tracks how many bytes of WAL the replication slot trails the source.
+	CDCReplicationLagBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "replication_lag_bytes",
+			Help:      "Bytes of WAL between the source's current position and the slot's confirmed flush position",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCDuplicatesDroppedTotal counts redelivered changes dropped by source-side deduplication.
+	CDCDuplicatesDroppedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "duplicates_dropped_total",
+			Help:      "Total number of redelivered changes dropped at or below the deduplication high-water-mark",
+		},
+		[]string{LabelSource},
+	)
+
+	// CDCKeyConflictsTotal counts keys a batch inserted more than once with differing rows.
+	CDCKeyConflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "key_conflicts_total",
+			Help:      "Total number of keys with conflicting non-identical rows in a batch, by the policy applied",
+		},
+		[]string{LabelSource, LabelTable, LabelPolicy},
+	)
+
+	// CDCKeyConflictsLastBatch is the number of conflicting keys in the most
+	// recent batch that contained the table.
+	CDCKeyConflictsLastBatch = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "key_conflicts_last_batch",
+			Help:      "Number of keys with conflicting non-identical rows in the most recent batch for the table",
+		},
+		[]string{LabelSource, LabelTable},
+	)
+
+	// CDCSinkBatchesTotal counts batches written to each sink, by result.
+	CDCSinkBatchesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "sink_batches_total",
+			Help:      "Total number of batches written to each sink, by result",
+		},
+		[]string{LabelSource, LabelSink, LabelResult},
+	)
+
+	// CDCOrphanedReplicationObjects tracks unreferenced Philotes-managed slots and publications.
+	CDCOrphanedReplicationObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "orphaned_replication_objects",
+			Help:      "Number of Philotes-managed replication slots or publications no pipeline references",
+		},
+		[]string{LabelSource, LabelKind},
+	)
+
+	// SnapshotVerificationsTotal counts post-snapshot verification runs.
+	SnapshotVerificationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "snapshot_verifications_total",
+			Help:      "Total number of post-snapshot verification runs",
+		},
+		[]string{LabelSource, LabelTable, LabelResult},
+	)
+
+	// SnapshotVerificationDiscrepancies tracks discrepancies found by the last verification.
+	SnapshotVerificationDiscrepancies = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "snapshot_verification_discrepancies",
+			Help:      "Number of discrepancies found by the last post-snapshot verification",
+		},
+		[]string{LabelSource, LabelTable},
+	)
+
+	// CDCPipelineEventsTotal counts the events pipelines emitted, by kind.
+	CDCPipelineEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemCDC,
+			Name:      "pipeline_events_total",
+			Help:      "Total number of events emitted by CDC pipelines",
+		},
+		[]string{LabelSource, LabelKind},
+	)
+
 	// API Metrics
 
 	// APIRequestsTotal counts the total number of API requests.
@@ -165,6 +340,18 @@ var (
 		[]string{LabelSource, LabelTable},
 	)
 
+	// IcebergCommitConflictsTotal counts Iceberg commits rejected because a
+	// concurrent writer changed the table first.
+	IcebergCommitConflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemIceberg,
+			Name:      "commit_conflicts_total",
+			Help:      "Total number of Iceberg commits that conflicted with a concurrent writer",
+		},
+		[]string{LabelSource, LabelTable},
+	)
+
 	// IcebergFilesWrittenTotal counts the total number of Parquet files written.
 	IcebergFilesWrittenTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -187,6 +374,41 @@ var (
 		[]string{LabelSource, LabelTable},
 	)
 
+	// IcebergCompactionFilesRewrittenTotal counts small data files replaced
+	// by compaction.
+	IcebergCompactionFilesRewrittenTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemIceberg,
+			Name:      "compaction_files_rewritten_total",
+			Help:      "Total number of small data files rewritten by compaction",
+		},
+		[]string{LabelTable},
+	)
+
+	// IcebergCompactionBytesReclaimedTotal counts the bytes of rewritten data
+	// files deleted from storage once no snapshot references them.
+	IcebergCompactionBytesReclaimedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemIceberg,
+			Name:      "compaction_bytes_reclaimed_total",
+			Help:      "Total bytes of rewritten data files deleted from storage",
+		},
+		[]string{LabelTable},
+	)
+
+	// IcebergSnapshotsExpiredTotal counts snapshots removed by maintenance.
+	IcebergSnapshotsExpiredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemIceberg,
+			Name:      "snapshots_expired_total",
+			Help:      "Total number of Iceberg snapshots expired",
+		},
+		[]string{LabelTable},
+	)
+
 	// Buffer Metrics
 
 	// BufferDepth tracks the number of unprocessed events in the buffer.
@@ -200,6 +422,30 @@ var (
 		[]string{LabelSource},
 	)
 
+	// BufferTableBacklog tracks the unprocessed events of each table among
+	// those the batch processor last read.
+	BufferTableBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemBuffer,
+			Name:      "table_backlog",
+			Help:      "Number of unprocessed events of a table in the buffer",
+		},
+		[]string{LabelSource, LabelTable},
+	)
+
+	// BufferTableLagSeconds tracks how long the oldest unprocessed event of
+	// each table has been waiting in the buffer.
+	BufferTableLagSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemBuffer,
+			Name:      "table_lag_seconds",
+			Help:      "Age of the oldest unprocessed event of a table in the buffer in seconds",
+		},
+		[]string{LabelSource, LabelTable},
+	)
+
 	// BufferBatchesTotal counts the total number of batches processed.
 	BufferBatchesTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -222,6 +468,19 @@ var (
 		[]string{LabelSource},
 	)
 
+	// BufferFlushDuration tracks how long flushing a batch from the buffer
+	// takes, including retries.
+	BufferFlushDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemBuffer,
+			Name:      "flush_duration_seconds",
+			Help:      "Duration of batch flushes from the buffer in seconds",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{LabelSource, LabelStatus},
+	)
+
 	// BufferDLQTotal counts events sent to dead letter queue.
 	BufferDLQTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -233,14 +492,51 @@ var (
 		[]string{LabelSource},
 	)
 
+	// BufferWriterSlots tracks the shared writer slots each pipeline holds.
+	BufferWriterSlots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemBuffer,
+			Name:      "writer_slots",
+			Help:      "Number of shared writer slots currently allocated to the pipeline",
+		},
+		[]string{LabelSource},
+	)
+
+	// BufferWriterSlotGrantsTotal counts the shared writer slots granted to each pipeline.
+	BufferWriterSlotGrantsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SubsystemBuffer,
+			Name:      "writer_slot_grants_total",
+			Help:      "Total number of shared writer slots granted to the pipeline",
+		},
+		[]string{LabelSource},
+	)
+
 	// allMetrics contains all metrics for registration.
 	allMetrics = []prometheus.Collector{
 		// CDC
 		CDCEventsTotal,
+		CDCEventsReadTotal,
+		CDCEventsWrittenTotal,
+		CDCCheckpointLagBytes,
+		CDCBackpressurePausesTotal,
+		CDCBackpressureResumesTotal,
 		CDCLagSeconds,
 		CDCErrorsTotal,
 		CDCRetriesTotal,
 		CDCPipelineState,
+		CDCReplicationGap,
+		CDCReplicationLagBytes,
+		CDCDuplicatesDroppedTotal,
+		CDCKeyConflictsTotal,
+		CDCKeyConflictsLastBatch,
+		CDCSinkBatchesTotal,
+		CDCOrphanedReplicationObjects,
+		SnapshotVerificationsTotal,
+		SnapshotVerificationDiscrepancies,
+		CDCPipelineEventsTotal,
 		// API
 		APIRequestsTotal,
 		APIRequestDuration,
@@ -249,13 +545,22 @@ var (
 		// Iceberg
 		IcebergCommitsTotal,
 		IcebergCommitDuration,
+		IcebergCommitConflictsTotal,
 		IcebergFilesWrittenTotal,
 		IcebergBytesWrittenTotal,
+		IcebergCompactionFilesRewrittenTotal,
+		IcebergCompactionBytesReclaimedTotal,
+		IcebergSnapshotsExpiredTotal,
 		// Buffer
 		BufferDepth,
+		BufferTableBacklog,
+		BufferTableLagSeconds,
 		BufferBatchesTotal,
 		BufferEventsProcessedTotal,
+		BufferFlushDuration,
 		BufferDLQTotal,
+		BufferWriterSlots,
+		BufferWriterSlotGrantsTotal,
 	}
 )
 
@@ -269,6 +574,19 @@ func Register() {
 	})
 }
 
+// Gatherer registers all Philotes metrics with the default Prometheus
+// registry and returns it. In-process readers, such as the internal alerting
+// provider, see the same values it serves on /metrics.
+func Gatherer() prometheus.Gatherer {
+	Register()
+	return prometheus.DefaultGatherer
+}
+
+// Handler returns an HTTP handler serving the metrics of Gatherer.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Gatherer(), promhttp.HandlerOpts{})
+}
+
 // RegisterWith registers all Philotes metrics with the given registry.
 // NOTE: This function should only be called once per registry. Calling it
 // multiple times with the same registry will panic due to duplicate registration.
@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -39,12 +42,49 @@ func TestRegisterWith(t *testing.T) {
 	}
 
 	// Verify the allMetrics slice has expected count
-	expectedCount := 17 // Total number of metrics defined
+	expectedCount := 41 // Total number of metrics defined
 	if len(allMetrics) != expectedCount {
 		t.Errorf("expected %d metrics in allMetrics, got %d", expectedCount, len(allMetrics))
 	}
 }
 
+func TestHandler(t *testing.T) {
+	CDCEventsReadTotal.WithLabelValues("handler-test").Add(3)
+	BufferFlushDuration.WithLabelValues("handler-test", "success").Observe(0.2)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`philotes_cdc_events_read_total{source="handler-test"} 3`,
+		`philotes_buffer_flush_duration_seconds_count{source="handler-test",status="success"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+
+	// The same values are readable in process
+	mfs, err := Gatherer().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	found := false
+	for _, mf := range mfs {
+		if mf.GetName() == "philotes_cdc_events_read_total" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected gatherer to include philotes_cdc_events_read_total")
+	}
+}
+
 func TestMetricLabels(t *testing.T) {
 	// Test that metrics can be used with expected labels without panicking
 	tests := []struct {
@@ -81,6 +121,66 @@ func TestMetricLabels(t *testing.T) {
 				CDCPipelineState.WithLabelValues("source1").Set(2)
 			},
 		},
+		{
+			name: "CDCEventsReadTotal",
+			fn: func() {
+				CDCEventsReadTotal.WithLabelValues("source1").Inc()
+			},
+		},
+		{
+			name: "CDCEventsWrittenTotal",
+			fn: func() {
+				CDCEventsWrittenTotal.WithLabelValues("source1").Inc()
+			},
+		},
+		{
+			name: "CDCCheckpointLagBytes",
+			fn: func() {
+				CDCCheckpointLagBytes.WithLabelValues("source1").Set(256)
+			},
+		},
+		{
+			name: "CDCBackpressurePausesTotal",
+			fn: func() {
+				CDCBackpressurePausesTotal.WithLabelValues("source1", "flush_latency").Inc()
+			},
+		},
+		{
+			name: "CDCBackpressureResumesTotal",
+			fn: func() {
+				CDCBackpressureResumesTotal.WithLabelValues("source1").Inc()
+			},
+		},
+		{
+			name: "CDCReplicationGap",
+			fn: func() {
+				CDCReplicationGap.WithLabelValues("source1").Set(0)
+			},
+		},
+		{
+			name: "CDCDuplicatesDroppedTotal",
+			fn: func() {
+				CDCDuplicatesDroppedTotal.WithLabelValues("source1").Inc()
+			},
+		},
+		{
+			name: "SnapshotVerificationsTotal",
+			fn: func() {
+				SnapshotVerificationsTotal.WithLabelValues("source1", "public.users", "passed").Inc()
+			},
+		},
+		{
+			name: "SnapshotVerificationDiscrepancies",
+			fn: func() {
+				SnapshotVerificationDiscrepancies.WithLabelValues("source1", "public.users").Set(0)
+			},
+		},
+		{
+			name: "CDCPipelineEventsTotal",
+			fn: func() {
+				CDCPipelineEventsTotal.WithLabelValues("source1", "verification_discrepancy").Inc()
+			},
+		},
 		{
 			name: "APIRequestsTotal",
 			fn: func() {
@@ -99,6 +199,12 @@ func TestMetricLabels(t *testing.T) {
 				IcebergCommitsTotal.WithLabelValues("source1", "public.users").Inc()
 			},
 		},
+		{
+			name: "IcebergCommitConflictsTotal",
+			fn: func() {
+				IcebergCommitConflictsTotal.WithLabelValues("source1", "public.users").Inc()
+			},
+		},
 		{
 			name: "IcebergCommitDuration",
 			fn: func() {
@@ -123,12 +229,30 @@ func TestMetricLabels(t *testing.T) {
 				BufferEventsProcessedTotal.WithLabelValues("source1").Add(50)
 			},
 		},
+		{
+			name: "BufferFlushDuration",
+			fn: func() {
+				BufferFlushDuration.WithLabelValues("source1", "success").Observe(0.2)
+			},
+		},
 		{
 			name: "BufferDLQTotal",
 			fn: func() {
 				BufferDLQTotal.WithLabelValues("source1").Inc()
 			},
 		},
+		{
+			name: "BufferWriterSlots",
+			fn: func() {
+				BufferWriterSlots.WithLabelValues("source1").Set(1)
+			},
+		},
+		{
+			name: "BufferWriterSlotGrantsTotal",
+			fn: func() {
+				BufferWriterSlotGrantsTotal.WithLabelValues("source1").Inc()
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -149,6 +273,7 @@ func TestLabelConstants(t *testing.T) {
 		"method":     LabelMethod,
 		"status":     LabelStatus,
 		"error_type": LabelErrorType,
+		"signal":     LabelSignal,
 	}
 
 	for expected, got := range labels {
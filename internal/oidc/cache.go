@@ -0,0 +1,214 @@
+package oidc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultCacheTTL is how long discovery documents and key sets are cached
+// when the identity provider does not say.
+const DefaultCacheTTL = time.Hour
+
+// cacheEntry is a cached value and when it expires.
+type cacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// Cache caches discovery documents and JSON Web Key Sets by issuer URL, so
+// logins do not fetch them from the identity provider each time. Entries
+// expire after the max-age of the provider's Cache-Control header, or the
+// cache TTL without one, and are refreshed in the background before they
+// expire. Concurrent fetches of the same document share one request.
+type Cache struct {
+	httpClient *http.Client
+	ttl        time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+
+	group     singleflight.Group
+	mu        sync.RWMutex
+	discovery map[string]cacheEntry[*DiscoveryConfig]
+	jwks      map[string]cacheEntry[*JSONWebKeySet]
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCache creates a new Cache. A ttl of zero or less uses DefaultCacheTTL.
+func NewCache(ttl time.Duration, logger *slog.Logger) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Cache{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		ttl:        ttl,
+		logger:     logger.With("component", "oidc-cache"),
+		now:        time.Now,
+		discovery:  make(map[string]cacheEntry[*DiscoveryConfig]),
+		jwks:       make(map[string]cacheEntry[*JSONWebKeySet]),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Discovery returns the discovery document of an issuer.
+func (c *Cache) Discovery(ctx context.Context, issuerURL string) (*DiscoveryConfig, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+
+	c.mu.RLock()
+	entry, ok := c.discovery[issuerURL]
+	c.mu.RUnlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	return c.refreshDiscovery(ctx, issuerURL)
+}
+
+// JWKS returns the JSON Web Key Set of an issuer.
+func (c *Cache) JWKS(ctx context.Context, issuerURL string) (*JSONWebKeySet, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+
+	c.mu.RLock()
+	entry, ok := c.jwks[issuerURL]
+	c.mu.RUnlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	return c.refreshJWKS(ctx, issuerURL)
+}
+
+// refreshDiscovery fetches and caches the discovery document of an issuer.
+func (c *Cache) refreshDiscovery(ctx context.Context, issuerURL string) (*DiscoveryConfig, error) {
+	v, err, _ := c.group.Do("discovery:"+issuerURL, func() (any, error) {
+		config, header, err := fetchDiscovery(ctx, c.httpClient, issuerURL)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.discovery[issuerURL] = cacheEntry[*DiscoveryConfig]{value: config, expiresAt: c.expiresAt(header)}
+		c.mu.Unlock()
+		return config, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*DiscoveryConfig), nil
+}
+
+// refreshJWKS fetches and caches the JSON Web Key Set of an issuer.
+func (c *Cache) refreshJWKS(ctx context.Context, issuerURL string) (*JSONWebKeySet, error) {
+	config, err := c.Discovery(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err, _ := c.group.Do("jwks:"+issuerURL, func() (any, error) {
+		jwks, header, err := fetchJWKS(ctx, c.httpClient, config.JWKSURI)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.jwks[issuerURL] = cacheEntry[*JSONWebKeySet]{value: jwks, expiresAt: c.expiresAt(header)}
+		c.mu.Unlock()
+		return jwks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*JSONWebKeySet), nil
+}
+
+// expiresAt returns when a response with the given headers expires: after
+// its Cache-Control max-age, immediately if it must not be cached, and
+// after the cache TTL otherwise.
+func (c *Cache) expiresAt(header http.Header) time.Time {
+	now := c.now()
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return now
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				return now.Add(time.Duration(seconds) * time.Second)
+			}
+		}
+	}
+	return now.Add(c.ttl)
+}
+
+// Start starts refreshing cached entries in the background before they
+// expire, checking every interval.
+func (c *Cache) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+				c.refreshExpiring(ctx, interval)
+			}
+		}
+	}()
+}
+
+// Stop stops the background refresh.
+func (c *Cache) Stop() {
+	close(c.stopCh)
+	c.wg.Wait()
+}
+
+// refreshExpiring refreshes the entries that expire within the given
+// window. An entry that fails to refresh is kept until it expires.
+func (c *Cache) refreshExpiring(ctx context.Context, window time.Duration) {
+	deadline := c.now().Add(window)
+
+	var discovery, jwks []string
+	c.mu.RLock()
+	for issuerURL, entry := range c.discovery {
+		if entry.expiresAt.Before(deadline) {
+			discovery = append(discovery, issuerURL)
+		}
+	}
+	for issuerURL, entry := range c.jwks {
+		if entry.expiresAt.Before(deadline) {
+			jwks = append(jwks, issuerURL)
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, issuerURL := range discovery {
+		if _, err := c.refreshDiscovery(ctx, issuerURL); err != nil {
+			c.logger.Warn("failed to refresh discovery document", "issuer", issuerURL, "error", err)
+		}
+	}
+	for _, issuerURL := range jwks {
+		if _, err := c.refreshJWKS(ctx, issuerURL); err != nil {
+			c.logger.Warn("failed to refresh jwks", "issuer", issuerURL, "error", err)
+		}
+	}
+}
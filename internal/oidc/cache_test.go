@@ -0,0 +1,244 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discoveryServer serves a discovery document and key set, counting the
+// requests for each. Responses carry the given Cache-Control header.
+type discoveryServer struct {
+	*httptest.Server
+	discoveryHits atomic.Int32
+	jwksHits      atomic.Int32
+	cacheControl  string
+	release       chan struct{}
+}
+
+func newDiscoveryServer(t *testing.T, cacheControl string) *discoveryServer {
+	t.Helper()
+	s := &discoveryServer{cacheControl: cacheControl}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.release != nil {
+			<-s.release
+		}
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			s.discoveryHits.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]string{ //nolint:errcheck // test helper
+				"issuer":                 s.URL,
+				"authorization_endpoint": s.URL + "/authorize",
+				"token_endpoint":         s.URL + "/token",
+				"jwks_uri":               s.URL + "/jwks",
+			})
+		case "/jwks":
+			s.jwksHits.Add(1)
+			_, _ = w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"k1","use":"sig","alg":"RS256","n":"AQAB","e":"AQAB"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestCache(ttl time.Duration) (*Cache, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCache(ttl, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCache_DiscoveryCachedUntilTTL(t *testing.T) {
+	server := newDiscoveryServer(t, "")
+	cache, now := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	for range 3 {
+		client := NewClient(server.URL+"/", "client-id", []string{"openid"})
+		client.SetCache(cache)
+		if _, err := client.AuthorizationURL(ctx, "state", "nonce", "challenge", "https://app.example.com/cb"); err != nil {
+			t.Fatalf("AuthorizationURL() error = %v", err)
+		}
+	}
+	if got := server.discoveryHits.Load(); got != 1 {
+		t.Errorf("discovery fetched %d times, want 1", got)
+	}
+
+	*now = now.Add(59 * time.Minute)
+	if _, err := cache.Discovery(ctx, server.URL); err != nil {
+		t.Fatalf("Discovery() error = %v", err)
+	}
+	if got := server.discoveryHits.Load(); got != 1 {
+		t.Errorf("discovery fetched %d times before expiry, want 1", got)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if _, err := cache.Discovery(ctx, server.URL); err != nil {
+		t.Fatalf("Discovery() error = %v", err)
+	}
+	if got := server.discoveryHits.Load(); got != 2 {
+		t.Errorf("discovery fetched %d times after expiry, want 2", got)
+	}
+}
+
+func TestCache_HonorsCacheControl(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		after        time.Duration
+		wantHits     int32
+	}{
+		{"public, max-age=60", 30 * time.Second, 1},
+		{"public, max-age=60", 90 * time.Second, 2},
+		{"no-store", 0, 2},
+		{"no-cache", 0, 2},
+		{"max-age=invalid", 30 * time.Minute, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cacheControl, func(t *testing.T) {
+			server := newDiscoveryServer(t, tt.cacheControl)
+			cache, now := newTestCache(time.Hour)
+			ctx := context.Background()
+
+			if _, err := cache.Discovery(ctx, server.URL); err != nil {
+				t.Fatalf("Discovery() error = %v", err)
+			}
+			*now = now.Add(tt.after)
+			if _, err := cache.Discovery(ctx, server.URL); err != nil {
+				t.Fatalf("Discovery() error = %v", err)
+			}
+			if got := server.discoveryHits.Load(); got != tt.wantHits {
+				t.Errorf("discovery fetched %d times, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestCache_JWKS(t *testing.T) {
+	server := newDiscoveryServer(t, "max-age=300")
+	cache, now := newTestCache(time.Hour)
+	client := NewClient(server.URL, "client-id", nil)
+	client.SetCache(cache)
+	ctx := context.Background()
+
+	for range 2 {
+		jwks, err := client.JWKS(ctx)
+		if err != nil {
+			t.Fatalf("JWKS() error = %v", err)
+		}
+		if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "k1" || jwks.Keys[0].KeyType != "RSA" {
+			t.Errorf("JWKS() = %+v", jwks)
+		}
+	}
+	if server.jwksHits.Load() != 1 || server.discoveryHits.Load() != 1 {
+		t.Errorf("fetched discovery %d and jwks %d times, want once each", server.discoveryHits.Load(), server.jwksHits.Load())
+	}
+
+	*now = now.Add(6 * time.Minute)
+	if _, err := client.JWKS(ctx); err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+	if got := server.jwksHits.Load(); got != 2 {
+		t.Errorf("jwks fetched %d times after max-age, want 2", got)
+	}
+}
+
+func TestCache_ConcurrentLoginsShareOneFetch(t *testing.T) {
+	server := newDiscoveryServer(t, "")
+	server.release = make(chan struct{})
+	cache, _ := newTestCache(time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Discovery(context.Background(), server.URL)
+			errs <- err
+		}()
+	}
+
+	// Let the requests pile up behind the first fetch before answering it
+	time.Sleep(50 * time.Millisecond)
+	close(server.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Discovery() error = %v", err)
+		}
+	}
+	if got := server.discoveryHits.Load(); got != 1 {
+		t.Errorf("discovery fetched %d times by concurrent logins, want 1", got)
+	}
+}
+
+func TestCache_RefreshExpiring(t *testing.T) {
+	server := newDiscoveryServer(t, "")
+	cache, now := newTestCache(time.Hour)
+	ctx := context.Background()
+
+	if _, err := cache.JWKS(ctx, server.URL); err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+
+	// Entries far from expiry are left alone
+	cache.refreshExpiring(ctx, time.Minute)
+	if server.discoveryHits.Load() != 1 || server.jwksHits.Load() != 1 {
+		t.Fatalf("entries refreshed %d and %d times before their window", server.discoveryHits.Load(), server.jwksHits.Load())
+	}
+
+	// Entries about to expire are refreshed ahead of it, so a login after
+	// the original expiry is still served from the cache
+	*now = now.Add(59*time.Minute + 30*time.Second)
+	cache.refreshExpiring(ctx, time.Minute)
+	if server.discoveryHits.Load() != 2 || server.jwksHits.Load() != 2 {
+		t.Errorf("entries refreshed %d and %d times, want refreshed once", server.discoveryHits.Load(), server.jwksHits.Load())
+	}
+
+	*now = now.Add(time.Minute)
+	if _, err := cache.JWKS(ctx, server.URL); err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+	if server.discoveryHits.Load() != 2 || server.jwksHits.Load() != 2 {
+		t.Errorf("refreshed entries were fetched again")
+	}
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := newDiscoveryServer(t, "")
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer failing.Close()
+	cache, _ := newTestCache(time.Hour)
+
+	if _, err := cache.Discovery(context.Background(), failing.URL); err == nil {
+		t.Fatal("Discovery() error = nil, want the endpoint error")
+	}
+	fail.Store(false)
+	if _, err := cache.Discovery(context.Background(), failing.URL); err != nil {
+		t.Errorf("Discovery() after recovery error = %v", err)
+	}
+}
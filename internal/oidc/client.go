@@ -24,6 +24,7 @@ type Client struct {
 	clientID   string
 	scopes     []string
 	config     *DiscoveryConfig
+	cache      *Cache
 }
 
 // DiscoveryConfig holds OIDC discovery document configuration.
@@ -36,6 +37,28 @@ type DiscoveryConfig struct {
 	ScopesSupported       []string `json:"scopes_supported"`
 }
 
+// JSONWebKeySet is a provider's set of ID token signing keys.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey is a public key of a JSON Web Key Set.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Elliptic curve keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
 // TokenResponse represents the token endpoint response.
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -54,6 +77,7 @@ type IDTokenClaims struct {
 	ExpiresAt     int64    `json:"exp"`
 	IssuedAt      int64    `json:"iat"`
 	Nonce         string   `json:"nonce,omitempty"`
+	SessionID     string   `json:"sid,omitempty"`
 	Email         string   `json:"email,omitempty"`
 	EmailVerified bool     `json:"email_verified,omitempty"`
 	Name          string   `json:"name,omitempty"`
@@ -91,36 +115,104 @@ func NewClient(issuerURL, clientID string, scopes []string) *Client {
 	}
 }
 
+// SetCache sets the cache discovery documents and key sets are read
+// through. Without one they are fetched by every client.
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
 // Discover fetches the OIDC discovery document.
 func (c *Client) Discover(ctx context.Context) (*DiscoveryConfig, error) {
 	if c.config != nil {
 		return c.config, nil
 	}
 
-	discoveryURL := c.issuerURL + "/.well-known/openid-configuration"
+	var config *DiscoveryConfig
+	var err error
+	if c.cache != nil {
+		config, err = c.cache.Discovery(ctx, c.issuerURL)
+	} else {
+		config, _, err = fetchDiscovery(ctx, c.httpClient, c.issuerURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.config = config
+	return config, nil
+}
+
+// JWKS fetches the provider's JSON Web Key Set.
+func (c *Client) JWKS(ctx context.Context) (*JSONWebKeySet, error) {
+	if c.cache != nil {
+		return c.cache.JWKS(ctx, c.issuerURL)
+	}
+
+	config, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jwks, _, err := fetchJWKS(ctx, c.httpClient, config.JWKSURI)
+	return jwks, err
+}
+
+// fetchDiscovery fetches the discovery document of an issuer. It also
+// returns the response headers.
+func fetchDiscovery(ctx context.Context, httpClient *http.Client, issuerURL string) (*DiscoveryConfig, http.Header, error) {
+	discoveryURL := issuerURL + "/.well-known/openid-configuration"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, http.NoBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create discovery request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch discovery document: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body) //nolint:errcheck // best-effort read for error message
-		return nil, fmt.Errorf("discovery endpoint returned %d: %s", resp.StatusCode, string(body))
+		return nil, nil, fmt.Errorf("discovery endpoint returned %d: %s", resp.StatusCode, string(body))
 	}
 
 	var config DiscoveryConfig
 	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+
+	return &config, resp.Header, nil
+}
+
+// fetchJWKS fetches a JSON Web Key Set. It also returns the response
+// headers.
+func fetchJWKS(ctx context.Context, httpClient *http.Client, jwksURI string) (*JSONWebKeySet, http.Header, error) {
+	if jwksURI == "" {
+		return nil, nil, fmt.Errorf("jwks_uri not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, http.NoBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body) //nolint:errcheck // best-effort read for error message
+		return nil, nil, fmt.Errorf("jwks endpoint returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var jwks JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode jwks: %w", err)
 	}
 
-	c.config = &config
-	return &config, nil
+	return &jwks, resp.Header, nil
 }
 
 // AuthorizationURL builds the authorization URL with PKCE.
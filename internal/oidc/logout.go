@@ -0,0 +1,167 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// BackChannelLogoutEvent is the event a logout token carries in its events
+// claim.
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// logoutTokenMaxAge is how long after it was issued a logout token is
+// accepted.
+const logoutTokenMaxAge = 5 * time.Minute
+
+// ErrInvalidLogoutToken is returned for a logout token that is malformed,
+// not signed by the provider, or not meant for this client.
+var ErrInvalidLogoutToken = errors.New("invalid logout token")
+
+// LogoutTokenClaims represents the claims of an OIDC back-channel logout
+// token. It identifies the user by subject, the provider session by session
+// ID, or both.
+type LogoutTokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID string                     `json:"sid,omitempty"`
+	Events    map[string]json.RawMessage `json:"events"`
+	Nonce     string                     `json:"nonce,omitempty"`
+}
+
+// ParseLogoutToken parses a back-channel logout token and verifies its
+// signature against the provider's key set, its issuer and audience, and
+// the claims the specification requires of it.
+func (c *Client) ParseLogoutToken(ctx context.Context, logoutToken string) (*LogoutTokenClaims, error) {
+	jwks, err := c.JWKS(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider keys: %w", err)
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(c.issuerURL),
+		jwt.WithAudience(c.clientID),
+		jwt.WithIssuedAt(),
+	)
+
+	var claims LogoutTokenClaims
+	_, err = parser.ParseWithClaims(logoutToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return jwks.publicKey(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLogoutToken, err)
+	}
+
+	if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > logoutTokenMaxAge {
+		return nil, fmt.Errorf("%w: issued too long ago", ErrInvalidLogoutToken)
+	}
+	if _, ok := claims.Events[BackChannelLogoutEvent]; !ok {
+		return nil, fmt.Errorf("%w: missing back-channel logout event", ErrInvalidLogoutToken)
+	}
+	if claims.Subject == "" && claims.SessionID == "" {
+		return nil, fmt.Errorf("%w: neither sub nor sid is set", ErrInvalidLogoutToken)
+	}
+	if claims.Nonce != "" {
+		return nil, fmt.Errorf("%w: nonce must not be set", ErrInvalidLogoutToken)
+	}
+
+	return &claims, nil
+}
+
+// publicKey returns the key with the given key ID. Without a key ID the
+// set must hold a single key.
+func (s *JSONWebKeySet) publicKey(kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		if len(s.Keys) != 1 {
+			return nil, fmt.Errorf("token has no key ID and the provider has %d keys", len(s.Keys))
+		}
+		return s.Keys[0].PublicKey()
+	}
+
+	for _, key := range s.Keys {
+		if key.KeyID == kid {
+			return key.PublicKey()
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// PublicKey returns the RSA or elliptic curve public key of the JSON Web
+// Key.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeKeyParameter(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decodeKeyParameter(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeKeyParameter(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decodeKeyParameter(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x.Bytes()) > size || len(y.Bytes()) > size {
+			return nil, fmt.Errorf("EC coordinates do not fit curve %s", k.Curve)
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		x.FillBytes(point[1 : 1+size])
+		y.FillBytes(point[1+size:])
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// decodeKeyParameter decodes a base64url-encoded key parameter.
+func decodeKeyParameter(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,174 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// logoutProvider serves a discovery document and a key set holding an RSA
+// and an EC signing key.
+type logoutProvider struct {
+	*httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func newLogoutProvider(t *testing.T) *logoutProvider {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &logoutProvider{rsaKey: rsaKey, ecKey: ecKey}
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	ecBytes, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{
+		{KeyType: "RSA", KeyID: "rsa", N: encode(rsaKey.N.Bytes()), E: encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{KeyType: "EC", KeyID: "ec", Curve: "P-256", X: encode(ecBytes[1:33]), Y: encode(ecBytes[33:])},
+	}}
+
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{ //nolint:errcheck // test helper
+				"issuer":   p.URL,
+				"jwks_uri": p.URL + "/jwks",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(w).Encode(jwks) //nolint:errcheck // test helper
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// sign signs logout token claims with the provider's RSA key.
+func (p *logoutProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "rsa"
+	signed, err := token.SignedString(p.rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// logoutClaims returns valid logout token claims for the provider.
+func (p *logoutProvider) logoutClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":    p.URL,
+		"aud":    "client-id",
+		"iat":    time.Now().Unix(),
+		"jti":    "logout-1",
+		"sub":    "user-1",
+		"sid":    "session-1",
+		"events": map[string]any{BackChannelLogoutEvent: map[string]any{}},
+	}
+}
+
+func TestParseLogoutToken(t *testing.T) {
+	p := newLogoutProvider(t)
+	client := NewClient(p.URL, "client-id", nil)
+
+	claims, err := client.ParseLogoutToken(context.Background(), p.sign(t, p.logoutClaims()))
+	if err != nil {
+		t.Fatalf("ParseLogoutToken() error = %v", err)
+	}
+	if claims.Subject != "user-1" || claims.SessionID != "session-1" {
+		t.Errorf("ParseLogoutToken() = sub %q sid %q", claims.Subject, claims.SessionID)
+	}
+
+	ecToken := jwt.NewWithClaims(jwt.SigningMethodES256, p.logoutClaims())
+	ecToken.Header["kid"] = "ec"
+	signed, err := ecToken.SignedString(p.ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ParseLogoutToken(context.Background(), signed); err != nil {
+		t.Errorf("ParseLogoutToken() of an EC-signed token error = %v", err)
+	}
+}
+
+func TestParseLogoutToken_Rejects(t *testing.T) {
+	p := newLogoutProvider(t)
+	client := NewClient(p.URL, "client-id", nil)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, p.logoutClaims())
+	forged.Header["kid"] = "rsa"
+	forgedToken, err := forged.SignedString(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, p.logoutClaims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(name string, value any) string {
+		claims := p.logoutClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return p.sign(t, claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"forged signature", forgedToken},
+		{"symmetric signature", hmacToken},
+		{"other issuer", with("iss", "https://other.example.com")},
+		{"other audience", with("aud", "other-client")},
+		{"stale", with("iat", time.Now().Add(-time.Hour).Unix())},
+		{"missing event", with("events", map[string]any{"other": map[string]any{}})},
+		{"no subject or session", func() string {
+			claims := p.logoutClaims()
+			delete(claims, "sub")
+			delete(claims, "sid")
+			return p.sign(t, claims)
+		}()},
+		{"nonce", with("nonce", "n")},
+		{"malformed", "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.ParseLogoutToken(context.Background(), tt.token); !errors.Is(err, ErrInvalidLogoutToken) {
+				t.Errorf("ParseLogoutToken() error = %v, want ErrInvalidLogoutToken", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package query
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CacheStatus reports whether a result was served from the result cache.
+type CacheStatus string
+
+// Cache statuses.
+const (
+	CacheHit  CacheStatus = "hit"
+	CacheMiss CacheStatus = "miss"
+)
+
+// CachedResult is a result read to the end and kept in memory.
+type CachedResult struct {
+	QueryID  string
+	Columns  []Column
+	Rows     [][]interface{}
+	CachedAt time.Time
+}
+
+// Materialize reads result to the end, or until it holds more than maxRows
+// rows, and closes it. One row beyond maxRows is kept so that replaying the
+// result shows it was cut off.
+func Materialize(result Result, maxRows int, now time.Time) (*CachedResult, error) {
+	defer result.Close()
+
+	cached := &CachedResult{
+		QueryID:  result.ID(),
+		Columns:  result.Columns(),
+		Rows:     [][]interface{}{},
+		CachedAt: now,
+	}
+	for len(cached.Rows) <= maxRows {
+		rows, err := result.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		cached.Rows = append(cached.Rows, rows...)
+	}
+	if len(cached.Rows) > maxRows+1 {
+		cached.Rows = cached.Rows[:maxRows+1]
+	}
+
+	return cached, nil
+}
+
+// Replay returns a Result serving the cached rows, reporting status.
+func (r *CachedResult) Replay(status CacheStatus) *ReplayedResult {
+	return &ReplayedResult{cached: r, status: status}
+}
+
+// ReplayedResult is a Result served from memory.
+type ReplayedResult struct {
+	cached *CachedResult
+	status CacheStatus
+	done   bool
+}
+
+// ID returns the ID of the query the rows were read from.
+func (r *ReplayedResult) ID() string {
+	return r.cached.QueryID
+}
+
+// Columns returns the result columns.
+func (r *ReplayedResult) Columns() []Column {
+	return r.cached.Columns
+}
+
+// Next returns all rows as a single page. It returns io.EOF after it.
+func (r *ReplayedResult) Next() ([][]interface{}, error) {
+	if r.done || len(r.cached.Rows) == 0 {
+		return nil, io.EOF
+	}
+	r.done = true
+	return r.cached.Rows, nil
+}
+
+// Close does nothing; the rows stay cached.
+func (r *ReplayedResult) Close() {}
+
+// CacheStatus returns whether the rows were served from the cache and when
+// they were read from the engine.
+func (r *ReplayedResult) CacheStatus() (CacheStatus, time.Time) {
+	return r.status, r.cached.CachedAt
+}
+
+var _ Result = (*ReplayedResult)(nil)
+
+// SnapshotSource reports the current snapshots of tables, so that results
+// cached before a table changed are not served after it.
+type SnapshotSource interface {
+	// CurrentSnapshot returns the current snapshot ID of a table, or 0 when
+	// the table does not exist or has no snapshot.
+	CurrentSnapshot(ctx context.Context, schema, table string) (int64, error)
+}
+
+// TableVersions returns the current snapshots of the tables stmt reads in
+// the session's catalog as a string for CacheKey. Unqualified names resolve
+// to the session schema.
+func TableVersions(ctx context.Context, source SnapshotSource, stmt *Statement, session Session) (string, error) {
+	var versions []string
+	seen := make(map[string]bool)
+	for _, ref := range stmt.Tables() {
+		if ref.Catalog != "" && !strings.EqualFold(ref.Catalog, session.Catalog) {
+			continue
+		}
+		schema := ref.Schema
+		if schema == "" {
+			schema = session.Schema
+		}
+		name := schema + "." + ref.Table
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		snapshotID, err := source.CurrentSnapshot(ctx, schema, ref.Table)
+		if err != nil {
+			return "", fmt.Errorf("failed to get snapshot of %s: %w", name, err)
+		}
+		versions = append(versions, fmt.Sprintf("%s@%d", name, snapshotID))
+	}
+	slices.Sort(versions)
+	return strings.Join(versions, ","), nil
+}
+
+// CacheKey identifies a result by everything that can change it: the engine,
+// the session, the tenant, the statement with its whitespace and comments
+// normalized, the parameters and the versions of the tables it reads.
+func CacheKey(engine string, session Session, tenantID *uuid.UUID, sql string, params []Parameter, versions string) string {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	write(engine)
+	write(session.Catalog)
+	write(session.Schema)
+	if tenantID != nil {
+		write(tenantID.String())
+	} else {
+		write("")
+	}
+	write(NormalizeSQL(sql))
+	for _, p := range params {
+		value := fmt.Sprint(p.Value)
+		if t, ok := p.Value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+		write(string(p.Type) + ":" + value)
+	}
+	write(versions)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NormalizeSQL strips comments and collapses whitespace outside of string
+// literals and quoted identifiers, so that statements differing only in
+// layout share cache entries. Malformed input is returned unchanged.
+func NormalizeSQL(sql string) string {
+	var b strings.Builder
+	runes := []rune(sql)
+	space := false
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = true
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return sql
+			}
+			i += 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+			space = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+			space = true
+		default:
+			j := i + 1
+			if r == '\'' || r == '"' {
+				var err error
+				if j, err = skipQuoted(runes, i, r); err != nil {
+					return sql
+				}
+			}
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(string(runes[i:j]))
+			i = j
+		}
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(b.String(), ";"))
+}
+
+// cacheEntry is an element of the cache's LRU list.
+type cacheEntry struct {
+	key    string
+	result *CachedResult
+}
+
+// cacheCall is a load in progress that identical requests wait for.
+type cacheCall struct {
+	done   chan struct{}
+	result *CachedResult
+	err    error
+}
+
+// Cache is an in-memory LRU cache of query results whose entries expire
+// after a TTL. Concurrent loads of the same key are coalesced into one.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu       sync.Mutex
+	lru      *list.List
+	entries  map[string]*list.Element
+	inflight map[string]*cacheCall
+}
+
+// NewCache creates a cache holding up to maxEntries results for ttl each.
+func NewCache(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		inflight:   make(map[string]*cacheCall),
+	}
+}
+
+// Len returns the number of cached results, including expired ones not yet
+// evicted.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Get returns the cached result of key if it has not expired.
+func (c *Cache) Get(key string) (*CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key)
+}
+
+func (c *Cache) get(key string) (*CachedResult, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.now().Sub(entry.result.CachedAt) >= c.ttl {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.result, true
+}
+
+// Add caches result under key, evicting the least recently used results
+// beyond the maximum.
+func (c *Cache) Add(key string, result *CachedResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(key, result)
+}
+
+func (c *Cache) add(key string, result *CachedResult) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).result = result
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, result: result})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Load returns the cached result of key, or calls load and caches its
+// result. With refresh the cached result is ignored and replaced. Callers
+// loading a key that is already being loaded wait for that load and share
+// its result, which they report as a hit.
+func (c *Cache) Load(ctx context.Context, key string, refresh bool, load func() (*CachedResult, error)) (*CachedResult, CacheStatus, error) {
+	c.mu.Lock()
+	if !refresh {
+		if result, ok := c.get(key); ok {
+			c.mu.Unlock()
+			return result, CacheHit, nil
+		}
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			if call.err != nil {
+				return nil, "", call.err
+			}
+			return call.result, CacheHit, nil
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	call.result, call.err = load()
+
+	c.mu.Lock()
+	if call.err == nil {
+		c.add(key, call.result)
+	}
+	delete(c.inflight, key)
+	c.mu.Unlock()
+	close(call.done)
+
+	if call.err != nil {
+		return nil, "", call.err
+	}
+	return call.result, CacheMiss, nil
+}
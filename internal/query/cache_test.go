@@ -0,0 +1,276 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pagedResult is a Result serving fixed pages.
+type pagedResult struct {
+	pages  [][][]interface{}
+	closed bool
+}
+
+func (r *pagedResult) ID() string        { return "q1" }
+func (r *pagedResult) Columns() []Column { return []Column{{Name: "n", Type: "bigint"}} }
+func (r *pagedResult) Close()            { r.closed = true }
+
+func (r *pagedResult) Next() ([][]interface{}, error) {
+	if len(r.pages) == 0 {
+		return nil, io.EOF
+	}
+	page := r.pages[0]
+	r.pages = r.pages[1:]
+	return page, nil
+}
+
+func cachedRows(n int, at time.Time) *CachedResult {
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i)}
+	}
+	return &CachedResult{QueryID: "q1", Rows: rows, CachedAt: at}
+}
+
+func TestMaterialize(t *testing.T) {
+	now := time.Now()
+	result := &pagedResult{pages: [][][]interface{}{
+		{{int64(1)}, {int64(2)}},
+		{{int64(3)}, {int64(4)}},
+		{{int64(5)}},
+	}}
+
+	cached, err := Materialize(result, 3, now)
+	if err != nil {
+		t.Fatalf("Materialize() error = %v", err)
+	}
+	if !result.closed {
+		t.Error("Materialize() did not close the result")
+	}
+	if len(cached.Rows) != 4 {
+		t.Errorf("rows = %d, want the 3 allowed rows and one showing truncation", len(cached.Rows))
+	}
+	if len(result.pages) != 1 {
+		t.Errorf("unread pages = %d, want reading to stop after the limit", len(result.pages))
+	}
+
+	replay := cached.Replay(CacheHit)
+	rows, err := replay.Next()
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("Replay().Next() = %d rows, %v; want 4 rows", len(rows), err)
+	}
+	if _, err := replay.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Replay().Next() after the rows error = %v, want io.EOF", err)
+	}
+	if status, at := replay.CacheStatus(); status != CacheHit || !at.Equal(now) {
+		t.Errorf("CacheStatus() = %s, %v; want hit, %v", status, at, now)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(time.Hour, 2)
+	now := time.Now()
+
+	cache.Add("a", cachedRows(1, now))
+	cache.Add("b", cachedRows(1, now))
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	cache.Add("c", cachedRows(1, now))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Get(b) hit, want the least recently used entry evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Get(%s) missed", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestCache_Expires(t *testing.T) {
+	cache := NewCache(time.Minute, 10)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Add("a", cachedRows(1, now))
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) missed before the TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Get(a) hit after the TTL")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, want the expired entry removed", cache.Len())
+	}
+}
+
+func TestCache_LoadCoalescesConcurrentRequests(t *testing.T) {
+	cache := NewCache(time.Hour, 10)
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func() (*CachedResult, error) {
+		loads.Add(1)
+		<-release
+		return cachedRows(3, time.Now()), nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	statuses := make(chan CacheStatus, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, status, err := cache.Load(context.Background(), "k", false, load)
+			if err != nil || len(result.Rows) != 3 {
+				t.Errorf("Load() = %v, %v; want 3 rows", result, err)
+			}
+			statuses <- status
+		}()
+	}
+	close(release)
+	wg.Wait()
+	close(statuses)
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads = %d, want concurrent requests coalesced into 1", n)
+	}
+	counts := map[CacheStatus]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[CacheMiss] != 1 || counts[CacheHit] != callers-1 {
+		t.Errorf("statuses = %v, want 1 miss and %d hits", counts, callers-1)
+	}
+}
+
+func TestCache_LoadRefreshAndErrors(t *testing.T) {
+	cache := NewCache(time.Hour, 10)
+	ctx := context.Background()
+
+	_, _, err := cache.Load(ctx, "k", false, func() (*CachedResult, error) {
+		return nil, ErrStatementFailed
+	})
+	if !errors.Is(err, ErrStatementFailed) {
+		t.Fatalf("Load() error = %v, want ErrStatementFailed", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("a failed load was cached")
+	}
+
+	first := cachedRows(1, time.Now())
+	if _, status, _ := cache.Load(ctx, "k", false, func() (*CachedResult, error) { return first, nil }); status != CacheMiss {
+		t.Errorf("first Load() status = %s, want miss", status)
+	}
+	if got, status, _ := cache.Load(ctx, "k", false, nil); status != CacheHit || got != first {
+		t.Errorf("second Load() = %p, %s; want the cached result as a hit", got, status)
+	}
+
+	second := cachedRows(2, time.Now())
+	if got, status, _ := cache.Load(ctx, "k", true, func() (*CachedResult, error) { return second, nil }); status != CacheMiss || got != second {
+		t.Errorf("refreshing Load() = %p, %s; want a new result as a miss", got, status)
+	}
+	if got, _ := cache.Get("k"); got != second {
+		t.Error("refreshing Load() did not replace the cached result")
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT  *\n\tFROM orders ;", "SELECT * FROM orders"},
+		{"SELECT 1 -- one\n, 2 /* two */ FROM t", "SELECT 1 , 2 FROM t"},
+		{"SELECT 'a  b', \"c  d\"  FROM t", "SELECT 'a  b', \"c  d\" FROM t"},
+		{"SELECT 'unterminated", "SELECT 'unterminated"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSQL(tt.sql); got != tt.want {
+			t.Errorf("NormalizeSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	session := Session{Catalog: "iceberg", Schema: "philotes"}
+	tenant := uuid.New()
+	day := Parameter{Type: ParameterDate, Value: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	base := CacheKey("trino", session, &tenant, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@1")
+
+	if got := CacheKey("trino", session, &tenant, "SELECT *\n  FROM t WHERE d = ?;", []Parameter{day}, "philotes.t@1"); got != base {
+		t.Error("keys of statements differing in layout differ")
+	}
+
+	otherTenant := uuid.New()
+	otherDay := Parameter{Type: ParameterDate, Value: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+	for name, key := range map[string]string{
+		"engine":   CacheKey("duckdb", session, &tenant, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@1"),
+		"schema":   CacheKey("trino", Session{Catalog: "iceberg", Schema: "other"}, &tenant, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@1"),
+		"tenant":   CacheKey("trino", session, &otherTenant, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@1"),
+		"global":   CacheKey("trino", session, nil, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@1"),
+		"literal":  CacheKey("trino", session, &tenant, "SELECT * FROM t WHERE d = ? AND x = 'a'", []Parameter{day}, "philotes.t@1"),
+		"params":   CacheKey("trino", session, &tenant, "SELECT * FROM t WHERE d = ?", []Parameter{otherDay}, "philotes.t@1"),
+		"snapshot": CacheKey("trino", session, &tenant, "SELECT * FROM t WHERE d = ?", []Parameter{day}, "philotes.t@2"),
+	} {
+		if key == base {
+			t.Errorf("key with a different %s equals the base key", name)
+		}
+	}
+}
+
+// fakeSnapshots serves snapshot IDs by schema.table.
+type fakeSnapshots map[string]int64
+
+func (f fakeSnapshots) CurrentSnapshot(_ context.Context, schema, table string) (int64, error) {
+	name := schema + "." + table
+	if name == "philotes.broken" {
+		return 0, fmt.Errorf("catalog unavailable")
+	}
+	return f[name], nil
+}
+
+func TestTableVersions(t *testing.T) {
+	source := fakeSnapshots{"philotes.orders": 7, "sales.customers": 3}
+	session := Session{Catalog: "iceberg", Schema: "philotes"}
+
+	stmt, err := ParseStatement(`WITH recent AS (SELECT * FROM orders)
+		SELECT * FROM recent JOIN iceberg.sales.customers c ON c.id = recent.customer_id
+		JOIN orders o ON o.id = recent.id JOIN system.runtime.nodes n ON true`)
+	if err != nil {
+		t.Fatalf("ParseStatement() error = %v", err)
+	}
+
+	got, err := TableVersions(context.Background(), source, stmt, session)
+	if err != nil {
+		t.Fatalf("TableVersions() error = %v", err)
+	}
+	if want := "philotes.orders@7,philotes.recent@0,sales.customers@3"; got != want {
+		t.Errorf("TableVersions() = %q, want %q", got, want)
+	}
+
+	source["philotes.orders"] = 8
+	if changed, _ := TableVersions(context.Background(), source, stmt, session); changed == got {
+		t.Error("TableVersions() did not change with a new snapshot")
+	}
+
+	broken, _ := ParseStatement("SELECT * FROM broken")
+	if _, err := TableVersions(context.Background(), source, broken, session); err == nil {
+		t.Error("TableVersions() error = nil, want the snapshot lookup error")
+	}
+}
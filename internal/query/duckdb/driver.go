@@ -0,0 +1,7 @@
+//go:build duckdb
+
+package duckdb
+
+// The DuckDB driver links the DuckDB library through cgo, so it is only part
+// of builds that ask for it with -tags duckdb.
+import _ "github.com/duckdb/duckdb-go/v2"
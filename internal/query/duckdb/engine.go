@@ -0,0 +1,369 @@
+// Package duckdb provides an embedded DuckDB query engine that reads the
+// Iceberg tables through the Iceberg catalog and object storage, for
+// deployments without a Trino cluster.
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// driverName is the database/sql driver name of DuckDB. The driver needs
+// cgo and is only compiled in with the duckdb build tag.
+const driverName = "duckdb"
+
+// pageSize is the number of rows returned by a call to Result.Next.
+const pageSize = 1000
+
+// ErrDriverUnavailable is returned when the binary was built without the
+// DuckDB driver.
+var ErrDriverUnavailable = errors.New("DuckDB driver is not available: build with -tags duckdb")
+
+// Engine runs read-only queries on an embedded, in-memory DuckDB database.
+type Engine struct {
+	db       *sql.DB
+	cfg      config.DuckDBConfig
+	attached bool
+	logger   *slog.Logger
+}
+
+// NewEngine opens a DuckDB database, loads the Iceberg and S3 extensions,
+// registers the object storage credentials and attaches the Iceberg catalog
+// read-only as cfg.Catalog.
+func NewEngine(ctx context.Context, cfg config.DuckDBConfig, iceberg config.IcebergConfig, storage config.StorageConfig, logger *slog.Logger) (*Engine, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !slices.Contains(sql.Drivers(), driverName) {
+		return nil, ErrDriverUnavailable
+	}
+
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
+	}
+
+	for _, stmt := range setupStatements(cfg, iceberg, storage) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set up DuckDB: %w", err)
+		}
+	}
+
+	return &Engine{
+		db:       db,
+		cfg:      cfg,
+		attached: iceberg.CatalogURL != "",
+		logger:   logger.With("component", "duckdb-engine"),
+	}, nil
+}
+
+// setupStatements returns the statements preparing a new database. Settings
+// are locked last so queries cannot change them.
+func setupStatements(cfg config.DuckDBConfig, iceberg config.IcebergConfig, storage config.StorageConfig) []string {
+	stmts := []string{"INSTALL httpfs", "LOAD httpfs", "INSTALL iceberg", "LOAD iceberg"}
+
+	if cfg.MemoryLimit != "" {
+		stmts = append(stmts, "SET GLOBAL memory_limit = "+quoteLiteral(cfg.MemoryLimit))
+	}
+	if cfg.Threads > 0 {
+		stmts = append(stmts, fmt.Sprintf("SET GLOBAL threads = %d", cfg.Threads))
+	}
+	if storage.Provider == config.StorageProviderAzure {
+		stmts = append(stmts, "INSTALL azure", "LOAD azure")
+	}
+	if storage.Endpoint != "" || storage.Provider == config.StorageProviderGCS || storage.Provider == config.StorageProviderAzure {
+		stmts = append(stmts, storageSecretStatement(storage))
+	}
+	if iceberg.CatalogURL != "" {
+		stmts = append(stmts, fmt.Sprintf("ATTACH %s AS %s (TYPE iceberg, ENDPOINT %s, AUTHORIZATION_TYPE 'none', READ_ONLY)",
+			quoteLiteral(iceberg.Warehouse),
+			quoteIdentifier(cfg.Catalog),
+			quoteLiteral(strings.TrimSuffix(iceberg.CatalogURL, "/")+"/catalog"),
+		))
+	}
+	if !cfg.AllowLocalFiles {
+		stmts = append(stmts, "SET GLOBAL disabled_filesystems = 'LocalFileSystem'")
+	}
+	stmts = append(stmts,
+		"SET GLOBAL autoinstall_known_extensions = false",
+		"SET GLOBAL autoload_known_extensions = false",
+		"SET GLOBAL lock_configuration = true",
+	)
+
+	return stmts
+}
+
+// storageSecretStatement returns the statement registering the object
+// storage credentials of the configured provider. An S3 endpoint may carry
+// a scheme, which then decides whether SSL is used.
+func storageSecretStatement(storage config.StorageConfig) string {
+	switch storage.Provider {
+	case config.StorageProviderGCS:
+		return fmt.Sprintf("CREATE SECRET philotes_storage (TYPE gcs, KEY_ID %s, SECRET %s)",
+			quoteLiteral(storage.AccessKey),
+			quoteLiteral(storage.SecretKey),
+		)
+	case config.StorageProviderAzure:
+		return fmt.Sprintf("CREATE SECRET philotes_storage (TYPE azure, CONNECTION_STRING %s)",
+			quoteLiteral(azureConnectionString(storage)),
+		)
+	}
+
+	endpoint, useSSL := storage.Endpoint, storage.UseSSL
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		endpoint, useSSL = u.Host, u.Scheme == "https"
+	}
+
+	return fmt.Sprintf("CREATE SECRET philotes_storage (TYPE s3, KEY_ID %s, SECRET %s, ENDPOINT %s, URL_STYLE 'path', USE_SSL %t)",
+		quoteLiteral(storage.AccessKey),
+		quoteLiteral(storage.SecretKey),
+		quoteLiteral(endpoint),
+		useSSL,
+	)
+}
+
+// azureConnectionString returns the Azure Storage connection string of the
+// storage account. A custom endpoint, such as an Azurite emulator, is the
+// blob endpoint.
+func azureConnectionString(storage config.StorageConfig) string {
+	account := "AccountName=" + storage.AccessKey + ";AccountKey=" + storage.SecretKey
+	if storage.Endpoint == "" {
+		return "DefaultEndpointsProtocol=https;" + account + ";EndpointSuffix=core.windows.net"
+	}
+
+	endpoint := storage.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		scheme := "http"
+		if storage.UseSSL {
+			scheme = "https"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+	return account + ";BlobEndpoint=" + endpoint
+}
+
+// Name returns the engine name.
+func (e *Engine) Name() string {
+	return config.QueryEngineDuckDB
+}
+
+// DefaultSession returns the session of the configured catalog and schema,
+// or of DuckDB's own database when no Iceberg catalog is attached.
+func (e *Engine) DefaultSession() query.Session {
+	if !e.attached {
+		return query.Session{Catalog: "memory", Schema: "main"}
+	}
+	return query.Session{Catalog: e.cfg.Catalog, Schema: e.cfg.Schema}
+}
+
+// Query runs a read-only statement with params bound to its placeholders
+// and returns its result. The query is bounded by QueryTimeout; the result
+// must be closed, which interrupts the query if it has not finished.
+func (e *Engine) Query(ctx context.Context, statement string, session query.Session, params ...query.Parameter) (query.Result, error) {
+	stmt, err := query.ParseStatement(statement)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", query.ErrStatementFailed, err)
+	}
+	if !stmt.ReadOnly() {
+		return nil, query.ErrReadOnly
+	}
+
+	queryCtx, cancel := ctx, context.CancelFunc(func() {})
+	if e.cfg.QueryTimeout > 0 {
+		queryCtx, cancel = context.WithTimeout(ctx, e.cfg.QueryTimeout)
+	}
+
+	// Connections are pooled, so every query sets its session explicitly
+	conn, err := e.db.Conn(queryCtx)
+	if err != nil {
+		cancel()
+		return nil, e.queryErr(queryCtx, fmt.Errorf("failed to get connection: %w", err))
+	}
+
+	if _, err := conn.ExecContext(queryCtx, "USE "+e.useTarget(session)); err != nil {
+		conn.Close()
+		cancel()
+		return nil, e.queryErr(queryCtx, fmt.Errorf("%w: %v", query.ErrStatementFailed, err))
+	}
+
+	args := make([]interface{}, len(params))
+	for i, p := range params {
+		args[i] = p.Value
+	}
+
+	rows, err := conn.QueryContext(queryCtx, stmt.SQL, args...)
+	if err != nil {
+		conn.Close()
+		cancel()
+		return nil, e.queryErr(queryCtx, fmt.Errorf("%w: %v", query.ErrStatementFailed, err))
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		conn.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+
+	columns := make([]query.Column, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = query.Column{Name: ct.Name(), Type: strings.ToLower(ct.DatabaseTypeName())}
+	}
+
+	return &Result{
+		engine:  e,
+		ctx:     queryCtx,
+		cancel:  cancel,
+		conn:    conn,
+		rows:    rows,
+		id:      uuid.NewString(),
+		columns: columns,
+	}, nil
+}
+
+// Close closes the database.
+func (e *Engine) Close() error {
+	return e.db.Close()
+}
+
+// useTarget returns the quoted catalog and schema of session, completed
+// from the default session.
+func (e *Engine) useTarget(session query.Session) string {
+	defaults := e.DefaultSession()
+	if session.Catalog == "" {
+		session.Catalog = defaults.Catalog
+	}
+	if session.Schema == "" {
+		session.Schema = defaults.Schema
+	}
+	return quoteIdentifier(session.Catalog) + "." + quoteIdentifier(session.Schema)
+}
+
+// queryErr maps a context deadline of the query to query.ErrQueryTimeout.
+func (e *Engine) queryErr(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w (%s)", query.ErrQueryTimeout, e.cfg.QueryTimeout)
+	}
+	return err
+}
+
+var _ query.Engine = (*Engine)(nil)
+
+// Result is the result of a running DuckDB query.
+type Result struct {
+	engine  *Engine
+	ctx     context.Context
+	cancel  context.CancelFunc
+	conn    *sql.Conn
+	rows    *sql.Rows
+	id      string
+	columns []query.Column
+	done    bool
+	closed  bool
+}
+
+// ID returns the query ID. DuckDB has no query IDs; it is generated for
+// correlating logs.
+func (r *Result) ID() string {
+	return r.id
+}
+
+// Columns returns the result columns.
+func (r *Result) Columns() []query.Column {
+	return r.columns
+}
+
+// Next returns up to pageSize rows. It returns io.EOF after the last row.
+func (r *Result) Next() ([][]interface{}, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	var page [][]interface{}
+	for len(page) < pageSize {
+		if !r.rows.Next() {
+			r.done = true
+			if err := r.rows.Err(); err != nil {
+				return nil, r.engine.queryErr(r.ctx, fmt.Errorf("%w: %v", query.ErrStatementFailed, err))
+			}
+			break
+		}
+
+		values := make([]interface{}, len(r.columns))
+		targets := make([]interface{}, len(r.columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := r.rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		for i, v := range values {
+			values[i] = normalizeValue(v)
+		}
+		page = append(page, values)
+	}
+
+	if len(page) == 0 {
+		return nil, io.EOF
+	}
+	return page, nil
+}
+
+// Close releases the result, interrupting the query if it is still running.
+func (r *Result) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	r.cancel()
+	r.rows.Close()
+	r.conn.Close()
+}
+
+// normalizeValue converts a scanned value into one that encodes to JSON the
+// way Trino results do: text as strings and other driver types, such as
+// decimals and UUIDs, in their string form.
+func normalizeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil, bool, string, time.Time,
+		int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64,
+		[]interface{}, map[string]interface{}:
+		return value
+	case []byte:
+		if utf8.Valid(value) {
+			return string(value)
+		}
+		return value
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return value
+	}
+}
+
+// quoteLiteral quotes s as a SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdentifier quotes s as a SQL identifier.
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,170 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+func TestSetupStatements(t *testing.T) {
+	cfg := config.DuckDBConfig{Catalog: "iceberg", MemoryLimit: "2GB", Threads: 4}
+	iceberg := config.IcebergConfig{CatalogURL: "http://lakekeeper:8181/", Warehouse: "philotes"}
+	storage := config.StorageConfig{Endpoint: "minio:9000", AccessKey: "admin", SecretKey: "it's-secret"}
+
+	stmts := setupStatements(cfg, iceberg, storage)
+	joined := strings.Join(stmts, "\n")
+
+	for _, want := range []string{
+		"LOAD iceberg",
+		"SET GLOBAL memory_limit = '2GB'",
+		"SET GLOBAL threads = 4",
+		"SECRET 'it''s-secret'",
+		"ENDPOINT 'minio:9000'",
+		"USE_SSL false",
+		`ATTACH 'philotes' AS "iceberg" (TYPE iceberg, ENDPOINT 'http://lakekeeper:8181/catalog', AUTHORIZATION_TYPE 'none', READ_ONLY)`,
+		"SET GLOBAL disabled_filesystems = 'LocalFileSystem'",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("setup statements do not contain %q:\n%s", want, joined)
+		}
+	}
+	if stmts[len(stmts)-1] != "SET GLOBAL lock_configuration = true" {
+		t.Errorf("last setup statement = %q, want the configuration to be locked last", stmts[len(stmts)-1])
+	}
+
+	cfg.AllowLocalFiles = true
+	if slices.Contains(setupStatements(cfg, iceberg, storage), "SET GLOBAL disabled_filesystems = 'LocalFileSystem'") {
+		t.Error("setup statements disable local files although AllowLocalFiles is set")
+	}
+}
+
+func TestStorageSecretStatement(t *testing.T) {
+	tests := []struct {
+		storage config.StorageConfig
+		want    string
+	}{
+		{config.StorageConfig{Endpoint: "localhost:9000"}, "ENDPOINT 'localhost:9000', URL_STYLE 'path', USE_SSL false"},
+		{config.StorageConfig{Endpoint: "localhost:9000", UseSSL: true}, "ENDPOINT 'localhost:9000', URL_STYLE 'path', USE_SSL true"},
+		{config.StorageConfig{Endpoint: "https://s3.example.com"}, "ENDPOINT 's3.example.com', URL_STYLE 'path', USE_SSL true"},
+		{config.StorageConfig{Endpoint: "http://minio:9000", UseSSL: true}, "ENDPOINT 'minio:9000', URL_STYLE 'path', USE_SSL false"},
+		{config.StorageConfig{Provider: config.StorageProviderGCS, AccessKey: "GOOG1", SecretKey: "hmac"}, "(TYPE gcs, KEY_ID 'GOOG1', SECRET 'hmac')"},
+		{config.StorageConfig{Provider: config.StorageProviderAzure, AccessKey: "acct", SecretKey: "a2V5"}, "CONNECTION_STRING 'DefaultEndpointsProtocol=https;AccountName=acct;AccountKey=a2V5;EndpointSuffix=core.windows.net'"},
+		{config.StorageConfig{Provider: config.StorageProviderAzure, Endpoint: "azurite:10000/acct", AccessKey: "acct", SecretKey: "a2V5"}, "CONNECTION_STRING 'AccountName=acct;AccountKey=a2V5;BlobEndpoint=http://azurite:10000/acct'"},
+	}
+
+	for _, tt := range tests {
+		if got := storageSecretStatement(tt.storage); !strings.Contains(got, tt.want) {
+			t.Errorf("storageSecretStatement(%q) = %s, want it to contain %s", tt.storage.Endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	if got := normalizeValue([]byte("acme")); got != "acme" {
+		t.Errorf("normalizeValue(text bytes) = %v, want a string", got)
+	}
+	if got, ok := normalizeValue([]byte{0xff, 0xfe}).([]byte); !ok || len(got) != 2 {
+		t.Errorf("normalizeValue(binary) = %v, want the bytes unchanged", got)
+	}
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := normalizeValue(ts); got != ts {
+		t.Errorf("normalizeValue(time) = %v, want the time unchanged", got)
+	}
+	if got := normalizeValue(stringer("1.50")); got != "1.50" {
+		t.Errorf("normalizeValue(decimal) = %v, want its string form", got)
+	}
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestNewEngine_DriverUnavailable(t *testing.T) {
+	if slices.Contains(sql.Drivers(), driverName) {
+		t.Skip("DuckDB driver is compiled in")
+	}
+
+	_, err := NewEngine(context.Background(), config.DuckDBConfig{}, config.IcebergConfig{}, config.StorageConfig{}, nil)
+	if !errors.Is(err, ErrDriverUnavailable) {
+		t.Errorf("NewEngine() error = %v, want ErrDriverUnavailable", err)
+	}
+}
+
+// newFixtureEngine returns an engine without catalog or object storage that
+// may read local files, for querying the local fixture table.
+func newFixtureEngine(t *testing.T) *Engine {
+	t.Helper()
+
+	if !slices.Contains(sql.Drivers(), driverName) {
+		t.Skip("DuckDB driver is not compiled in; run with -tags duckdb")
+	}
+
+	engine, err := NewEngine(context.Background(), config.DuckDBConfig{QueryTimeout: time.Minute, AllowLocalFiles: true},
+		config.IcebergConfig{}, config.StorageConfig{}, nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	return engine
+}
+
+func TestEngine_QueryIcebergTable(t *testing.T) {
+	engine := newFixtureEngine(t)
+	metadataPath := writeIcebergFixture(t, t.TempDir())
+
+	result, err := engine.Query(context.Background(),
+		"SELECT customer, sum(amount) AS total FROM iceberg_scan('"+metadataPath+"') GROUP BY customer ORDER BY customer",
+		engine.DefaultSession())
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer result.Close()
+
+	columns := result.Columns()
+	if len(columns) != 2 || columns[0].Name != "customer" || columns[0].Type != "varchar" || columns[1].Type != "double" {
+		t.Errorf("Columns() = %+v, want customer varchar and total double", columns)
+	}
+
+	rows, err := result.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0][0] != "acme" || rows[0][1] != 42.5 || rows[1][0] != "globex" {
+		t.Errorf("rows = %v, want acme 42.5 and globex 7.25", rows)
+	}
+	if _, err := result.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() after the last row error = %v, want io.EOF", err)
+	}
+}
+
+func TestEngine_ReadOnly(t *testing.T) {
+	engine := newFixtureEngine(t)
+
+	for _, stmt := range []string{
+		"CREATE TABLE t (id INTEGER)",
+		"INSTALL spatial",
+		"SET GLOBAL disabled_filesystems = ''",
+		"COPY (SELECT 1) TO 'out.csv'",
+	} {
+		if _, err := engine.Query(context.Background(), stmt, engine.DefaultSession()); !errors.Is(err, query.ErrReadOnly) {
+			t.Errorf("Query(%q) error = %v, want ErrReadOnly", stmt, err)
+		}
+	}
+}
+
+func TestEngine_QueryError(t *testing.T) {
+	engine := newFixtureEngine(t)
+
+	_, err := engine.Query(context.Background(), "SELECT * FROM missing_table", engine.DefaultSession())
+	if !errors.Is(err, query.ErrStatementFailed) {
+		t.Errorf("Query() error = %v, want ErrStatementFailed", err)
+	}
+}
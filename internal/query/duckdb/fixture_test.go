@@ -0,0 +1,284 @@
+package duckdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// fixtureOrder is a row of the fixture table.
+type fixtureOrder struct {
+	ID       int64   `parquet:"name=id, type=INT64, fieldid=1"`
+	Customer string  `parquet:"name=customer, type=BYTE_ARRAY, convertedtype=UTF8, fieldid=2"`
+	Amount   float64 `parquet:"name=amount, type=DOUBLE, fieldid=3"`
+}
+
+// fixtureOrders are the rows of the fixture table.
+var fixtureOrders = []fixtureOrder{
+	{ID: 1, Customer: "acme", Amount: 12.5},
+	{ID: 2, Customer: "globex", Amount: 7.25},
+	{ID: 3, Customer: "acme", Amount: 30},
+}
+
+const fixtureSnapshotID = 1
+
+// fixtureTableSchema is the Iceberg schema of the fixture table.
+const fixtureTableSchema = `{"type":"struct","schema-id":0,"fields":[` +
+	`{"id":1,"name":"id","required":true,"type":"long"},` +
+	`{"id":2,"name":"customer","required":true,"type":"string"},` +
+	`{"id":3,"name":"amount","required":true,"type":"double"}]}`
+
+// manifestEntrySchema is the Avro schema of a v2 manifest, reduced to the
+// required fields.
+const manifestEntrySchema = `{"type":"record","name":"manifest_entry","fields":[
+	{"name":"status","type":"int","field-id":0},
+	{"name":"snapshot_id","type":["null","long"],"default":null,"field-id":1},
+	{"name":"sequence_number","type":["null","long"],"default":null,"field-id":3},
+	{"name":"file_sequence_number","type":["null","long"],"default":null,"field-id":4},
+	{"name":"data_file","field-id":2,"type":{"type":"record","name":"r2","fields":[
+		{"name":"content","type":"int","field-id":134},
+		{"name":"file_path","type":"string","field-id":100},
+		{"name":"file_format","type":"string","field-id":101},
+		{"name":"partition","field-id":102,"type":{"type":"record","name":"r102","fields":[]}},
+		{"name":"record_count","type":"long","field-id":103},
+		{"name":"file_size_in_bytes","type":"long","field-id":104}
+	]}}
+]}`
+
+// manifestFileSchema is the Avro schema of a v2 manifest list.
+const manifestFileSchema = `{"type":"record","name":"manifest_file","fields":[
+	{"name":"manifest_path","type":"string","field-id":500},
+	{"name":"manifest_length","type":"long","field-id":501},
+	{"name":"partition_spec_id","type":"int","field-id":502},
+	{"name":"content","type":"int","field-id":517},
+	{"name":"sequence_number","type":"long","field-id":515},
+	{"name":"min_sequence_number","type":"long","field-id":516},
+	{"name":"added_snapshot_id","type":"long","field-id":503},
+	{"name":"added_files_count","type":"int","field-id":504},
+	{"name":"existing_files_count","type":"int","field-id":505},
+	{"name":"deleted_files_count","type":"int","field-id":506},
+	{"name":"added_rows_count","type":"long","field-id":512},
+	{"name":"existing_rows_count","type":"long","field-id":513},
+	{"name":"deleted_rows_count","type":"long","field-id":514}
+]}`
+
+type manifestDataFile struct {
+	Content         int32    `avro:"content"`
+	FilePath        string   `avro:"file_path"`
+	FileFormat      string   `avro:"file_format"`
+	Partition       struct{} `avro:"partition"`
+	RecordCount     int64    `avro:"record_count"`
+	FileSizeInBytes int64    `avro:"file_size_in_bytes"`
+}
+
+type manifestEntry struct {
+	Status             int32            `avro:"status"`
+	SnapshotID         *int64           `avro:"snapshot_id"`
+	SequenceNumber     *int64           `avro:"sequence_number"`
+	FileSequenceNumber *int64           `avro:"file_sequence_number"`
+	DataFile           manifestDataFile `avro:"data_file"`
+}
+
+type manifestFile struct {
+	ManifestPath       string `avro:"manifest_path"`
+	ManifestLength     int64  `avro:"manifest_length"`
+	PartitionSpecID    int32  `avro:"partition_spec_id"`
+	Content            int32  `avro:"content"`
+	SequenceNumber     int64  `avro:"sequence_number"`
+	MinSequenceNumber  int64  `avro:"min_sequence_number"`
+	AddedSnapshotID    int64  `avro:"added_snapshot_id"`
+	AddedFilesCount    int32  `avro:"added_files_count"`
+	ExistingFilesCount int32  `avro:"existing_files_count"`
+	DeletedFilesCount  int32  `avro:"deleted_files_count"`
+	AddedRowsCount     int64  `avro:"added_rows_count"`
+	ExistingRowsCount  int64  `avro:"existing_rows_count"`
+	DeletedRowsCount   int64  `avro:"deleted_rows_count"`
+}
+
+// writeIcebergFixture writes an unpartitioned Iceberg v2 table holding
+// fixtureOrders to dir and returns the path of its metadata file.
+func writeIcebergFixture(t *testing.T, dir string) string {
+	t.Helper()
+
+	for _, sub := range []string{"data", "metadata"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("failed to create fixture directory: %v", err)
+		}
+	}
+
+	// Data file
+	fw := buffer.NewBufferFile()
+	pw, err := writer.NewParquetWriter(fw, new(fixtureOrder), 1)
+	if err != nil {
+		t.Fatalf("failed to create parquet writer: %v", err)
+	}
+	for i := range fixtureOrders {
+		if err := pw.Write(&fixtureOrders[i]); err != nil {
+			t.Fatalf("failed to write fixture row: %v", err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		t.Fatalf("failed to close parquet writer: %v", err)
+	}
+	dataPath := filepath.Join(dir, "data", "00000-orders.parquet")
+	writeFixtureFile(t, dataPath, fw.Bytes())
+
+	// Manifest
+	snapshotID, sequence := int64(fixtureSnapshotID), int64(1)
+	manifest := encodeAvro(t, manifestEntrySchema, map[string][]byte{
+		"schema":            []byte(fixtureTableSchema),
+		"schema-id":         []byte("0"),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte("0"),
+		"format-version":    []byte("2"),
+		"content":           []byte("data"),
+	}, manifestEntry{
+		Status:             1,
+		SnapshotID:         &snapshotID,
+		SequenceNumber:     &sequence,
+		FileSequenceNumber: &sequence,
+		DataFile: manifestDataFile{
+			FilePath:        dataPath,
+			FileFormat:      "PARQUET",
+			RecordCount:     int64(len(fixtureOrders)),
+			FileSizeInBytes: int64(len(fw.Bytes())),
+		},
+	})
+	manifestPath := filepath.Join(dir, "metadata", "orders-m0.avro")
+	writeFixtureFile(t, manifestPath, manifest)
+
+	// Manifest list
+	manifestList := encodeAvro(t, manifestFileSchema, map[string][]byte{
+		"snapshot-id":     []byte("1"),
+		"sequence-number": []byte("1"),
+		"format-version":  []byte("2"),
+	}, manifestFile{
+		ManifestPath:      manifestPath,
+		ManifestLength:    int64(len(manifest)),
+		SequenceNumber:    1,
+		MinSequenceNumber: 1,
+		AddedSnapshotID:   fixtureSnapshotID,
+		AddedFilesCount:   1,
+		AddedRowsCount:    int64(len(fixtureOrders)),
+	})
+	manifestListPath := filepath.Join(dir, "metadata", "snap-1.avro")
+	writeFixtureFile(t, manifestListPath, manifestList)
+
+	// Table metadata
+	now := time.Now().UnixMilli()
+	metadata := map[string]interface{}{
+		"format-version":        2,
+		"table-uuid":            "9c12d441-03fe-4693-9a96-a0705ddf69c1",
+		"location":              dir,
+		"last-sequence-number":  1,
+		"last-updated-ms":       now,
+		"last-column-id":        3,
+		"current-schema-id":     0,
+		"schemas":               []json.RawMessage{json.RawMessage(fixtureTableSchema)},
+		"default-spec-id":       0,
+		"partition-specs":       []interface{}{map[string]interface{}{"spec-id": 0, "fields": []interface{}{}}},
+		"last-partition-id":     999,
+		"default-sort-order-id": 0,
+		"sort-orders":           []interface{}{map[string]interface{}{"order-id": 0, "fields": []interface{}{}}},
+		"properties":            map[string]string{},
+		"current-snapshot-id":   fixtureSnapshotID,
+		"snapshots": []interface{}{map[string]interface{}{
+			"snapshot-id":     fixtureSnapshotID,
+			"sequence-number": 1,
+			"timestamp-ms":    now,
+			"manifest-list":   manifestListPath,
+			"summary":         map[string]string{"operation": "append"},
+			"schema-id":       0,
+		}},
+		"snapshot-log": []interface{}{map[string]interface{}{"snapshot-id": fixtureSnapshotID, "timestamp-ms": now}},
+		"metadata-log": []interface{}{},
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("failed to encode table metadata: %v", err)
+	}
+	metadataPath := filepath.Join(dir, "metadata", "v1.metadata.json")
+	writeFixtureFile(t, metadataPath, data)
+	writeFixtureFile(t, filepath.Join(dir, "metadata", "version-hint.text"), []byte("1"))
+
+	return metadataPath
+}
+
+// encodeAvro encodes records as an Avro container file, keeping the
+// field-id properties of schema.
+func encodeAvro(t *testing.T, schema string, meta map[string][]byte, records ...interface{}) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc, err := ocf.NewEncoder(schema, &buf, ocf.WithMetadata(meta), ocf.WithSchemaMarshaler(ocf.FullSchemaMarshaler))
+	if err != nil {
+		t.Fatalf("failed to create avro encoder: %v", err)
+	}
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			t.Fatalf("failed to encode avro record: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("failed to close avro encoder: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeFixtureFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// TestIcebergFixture checks that the fixture encodes, so it is validated
+// even where the DuckDB driver is not compiled in.
+func TestIcebergFixture(t *testing.T) {
+	metadataPath := writeIcebergFixture(t, t.TempDir())
+
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	var metadata struct {
+		Snapshots []struct {
+			ManifestList string `json:"manifest-list"`
+		} `json:"snapshots"`
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("failed to decode metadata: %v", err)
+	}
+	if len(metadata.Snapshots) != 1 {
+		t.Fatalf("snapshots = %d, want 1", len(metadata.Snapshots))
+	}
+
+	f, err := os.Open(metadata.Snapshots[0].ManifestList)
+	if err != nil {
+		t.Fatalf("failed to open manifest list: %v", err)
+	}
+	defer f.Close()
+
+	dec, err := ocf.NewDecoder(f)
+	if err != nil {
+		t.Fatalf("failed to decode manifest list: %v", err)
+	}
+	var list manifestFile
+	if !dec.HasNext() {
+		t.Fatal("manifest list is empty")
+	}
+	if err := dec.Decode(&list); err != nil {
+		t.Fatalf("failed to decode manifest list entry: %v", err)
+	}
+	if list.AddedRowsCount != int64(len(fixtureOrders)) {
+		t.Errorf("added_rows_count = %d, want %d", list.AddedRowsCount, len(fixtureOrders))
+	}
+}
@@ -0,0 +1,35 @@
+package duckdb
+
+import (
+	"context"
+
+	"github.com/janovincze/philotes/internal/cdc/health"
+)
+
+// HealthChecker returns a health checker for the engine. It checks that the
+// database answers and, when a catalog is attached, that the catalog can be
+// listed.
+func (e *Engine) HealthChecker() *health.ComponentChecker {
+	checker := health.NewComponentChecker("duckdb", e.checkHealth)
+	checker.SetComponent(health.ComponentQueryEngine)
+	return checker
+}
+
+// checkHealth runs a trivial query against the default session.
+func (e *Engine) checkHealth(ctx context.Context) (health.Status, string, error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return health.StatusUnhealthy, "DuckDB unavailable", err
+	}
+	defer conn.Close()
+
+	if e.attached {
+		if _, err := conn.ExecContext(ctx, "USE "+e.useTarget(e.DefaultSession())); err != nil {
+			return health.StatusDegraded, "Iceberg catalog unavailable to DuckDB", err
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT 1"); err != nil {
+		return health.StatusUnhealthy, "DuckDB unavailable", err
+	}
+	return health.StatusHealthy, "DuckDB OK", nil
+}
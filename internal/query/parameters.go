@@ -0,0 +1,190 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParameterType is the type of a statement parameter.
+type ParameterType string
+
+// Parameter types.
+const (
+	ParameterString    ParameterType = "string"
+	ParameterInteger   ParameterType = "integer"
+	ParameterNumber    ParameterType = "number"
+	ParameterBoolean   ParameterType = "boolean"
+	ParameterDate      ParameterType = "date"
+	ParameterTimestamp ParameterType = "timestamp"
+)
+
+// dateLayout is the format of date parameter values.
+const dateLayout = "2006-01-02"
+
+// IsValid reports whether t is a known parameter type.
+func (t ParameterType) IsValid() bool {
+	switch t {
+	case ParameterString, ParameterInteger, ParameterNumber, ParameterBoolean, ParameterDate, ParameterTimestamp:
+		return true
+	default:
+		return false
+	}
+}
+
+// Parameter is a typed value bound to a positional ? placeholder. Value is
+// a string, int64, float64, bool or time.Time.
+type Parameter struct {
+	Type  ParameterType
+	Value interface{}
+}
+
+// ConvertParameter converts a decoded JSON value to a parameter of type t.
+// Numbers, booleans, dates and timestamps are also accepted as strings.
+func ConvertParameter(t ParameterType, value interface{}) (Parameter, error) {
+	p := Parameter{Type: t}
+
+	switch t {
+	case ParameterString:
+		s, ok := value.(string)
+		if !ok {
+			return p, errors.New("must be a string")
+		}
+		p.Value = s
+	case ParameterInteger:
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+				return p, errors.New("must be an integer")
+			}
+			p.Value = int64(v)
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return p, errors.New("must be an integer")
+			}
+			p.Value = n
+		default:
+			return p, errors.New("must be an integer")
+		}
+	case ParameterNumber:
+		switch v := value.(type) {
+		case float64:
+			p.Value = v
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+				return p, errors.New("must be a number")
+			}
+			p.Value = f
+		default:
+			return p, errors.New("must be a number")
+		}
+	case ParameterBoolean:
+		switch v := value.(type) {
+		case bool:
+			p.Value = v
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return p, errors.New("must be a boolean")
+			}
+			p.Value = b
+		default:
+			return p, errors.New("must be a boolean")
+		}
+	case ParameterDate:
+		s, ok := value.(string)
+		if !ok {
+			return p, fmt.Errorf("must be a date (%s)", dateLayout)
+		}
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return p, fmt.Errorf("must be a date (%s)", dateLayout)
+		}
+		p.Value = d
+	case ParameterTimestamp:
+		s, ok := value.(string)
+		if !ok {
+			return p, errors.New("must be an RFC 3339 timestamp")
+		}
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return p, errors.New("must be an RFC 3339 timestamp")
+		}
+		p.Value = ts.UTC()
+	default:
+		return p, fmt.Errorf("unknown parameter type %q", t)
+	}
+
+	return p, nil
+}
+
+// placeholderPattern matches a {{name}} placeholder, allowing spaces inside
+// the braces.
+var placeholderPattern = regexp.MustCompile(`^\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// BindPlaceholders replaces the {{name}} placeholders of sql with positional
+// ? placeholders and returns the rewritten SQL and the placeholder names in
+// order of appearance; a name used twice appears twice. Placeholders inside
+// string literals, quoted identifiers and comments are left alone.
+func BindPlaceholders(sql string) (string, []string, error) {
+	var b strings.Builder
+	var names []string
+	runes := []rune(sql)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			j := i
+			for j < len(runes) && runes[j] != '\n' {
+				j++
+			}
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return "", nil, errors.New("unterminated comment")
+			}
+			j := i + 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case r == '\'' || r == '"':
+			j, err := skipQuoted(runes, i, r)
+			if err != nil {
+				return "", nil, errors.New("unterminated quoted text")
+			}
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case r == '{' && i+1 < len(runes) && runes[i+1] == '{':
+			rest := string(runes[i:])
+			m := placeholderPattern.FindStringSubmatch(rest)
+			if m == nil {
+				return "", nil, fmt.Errorf("invalid placeholder at %q", truncate(rest, 20))
+			}
+			names = append(names, m[1])
+			b.WriteByte('?')
+			i += len([]rune(m[0]))
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+
+	return b.String(), names, nil
+}
+
+// truncate shortens s to n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
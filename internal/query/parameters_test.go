@@ -0,0 +1,86 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql       string
+		wantSQL   string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			sql:       "SELECT * FROM orders WHERE created >= {{start_date}} AND created < {{ end_date }}",
+			wantSQL:   "SELECT * FROM orders WHERE created >= ? AND created < ?",
+			wantNames: []string{"start_date", "end_date"},
+		},
+		{
+			sql:       "SELECT {{n}}, {{n}} + 1",
+			wantSQL:   "SELECT ?, ? + 1",
+			wantNames: []string{"n", "n"},
+		},
+		{
+			sql:     "SELECT '{{not_a_param}}', \"{{col}}\" -- {{comment}}\n/* {{block}} */ FROM t",
+			wantSQL: "SELECT '{{not_a_param}}', \"{{col}}\" -- {{comment}}\n/* {{block}} */ FROM t",
+		},
+		{sql: "SELECT {{1bad}}", wantErr: true},
+		{sql: "SELECT {{open", wantErr: true},
+		{sql: "SELECT 'unterminated", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		gotSQL, gotNames, err := BindPlaceholders(tt.sql)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("BindPlaceholders(%q) error = %v, wantErr %v", tt.sql, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if gotSQL != tt.wantSQL {
+			t.Errorf("BindPlaceholders(%q) SQL = %q, want %q", tt.sql, gotSQL, tt.wantSQL)
+		}
+		if !reflect.DeepEqual(gotNames, tt.wantNames) {
+			t.Errorf("BindPlaceholders(%q) names = %v, want %v", tt.sql, gotNames, tt.wantNames)
+		}
+	}
+}
+
+func TestConvertParameter(t *testing.T) {
+	tests := []struct {
+		typ     ParameterType
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{ParameterString, "acme", "acme", false},
+		{ParameterString, 1.0, nil, true},
+		{ParameterInteger, 42.0, int64(42), false},
+		{ParameterInteger, "42", int64(42), false},
+		{ParameterInteger, 4.2, nil, true},
+		{ParameterNumber, 4.2, 4.2, false},
+		{ParameterNumber, "NaN", nil, true},
+		{ParameterBoolean, true, true, false},
+		{ParameterBoolean, "false", false, false},
+		{ParameterDate, "2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{ParameterDate, "01/03/2026", nil, true},
+		{ParameterTimestamp, "2026-03-01T10:00:00+02:00", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), false},
+		{ParameterTimestamp, "2026-03-01", nil, true},
+		{ParameterType("uuid"), "x", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ConvertParameter(tt.typ, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ConvertParameter(%s, %v) error = %v, wantErr %v", tt.typ, tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Value != tt.want {
+			t.Errorf("ConvertParameter(%s, %v) = %v, want %v", tt.typ, tt.value, got.Value, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package query defines the engines behind the query endpoint and the
+// statement checks applied before a statement reaches an engine.
+package query
+
+import (
+	"context"
+	"errors"
+
+	"github.com/janovincze/philotes/internal/cdc/health"
+)
+
+var (
+	// ErrQueryTimeout is returned when a query runs longer than its timeout.
+	ErrQueryTimeout = errors.New("query exceeded the query timeout")
+
+	// ErrNotEnabled is returned when the query engine is not enabled.
+	ErrNotEnabled = errors.New("query engine is not enabled")
+
+	// ErrReadOnly is returned when a statement other than a query is sent to
+	// a read-only engine.
+	ErrReadOnly = errors.New("query engine is read-only")
+
+	// ErrStatementFailed is matched by errors the engine reports for a
+	// statement, such as syntax errors or unknown tables.
+	ErrStatementFailed = errors.New("statement failed")
+)
+
+// Column describes a result column.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Session is the context a query runs in.
+type Session struct {
+	Catalog string
+	Schema  string
+}
+
+// Result is the result of a running query. Rows are fetched page by page as
+// Next is called.
+type Result interface {
+	// ID returns the engine's query ID.
+	ID() string
+
+	// Columns returns the result columns.
+	Columns() []Column
+
+	// Next returns the next page of rows. It returns io.EOF after the last
+	// page.
+	Next() ([][]interface{}, error)
+
+	// Close releases the result, cancelling the query if it is still
+	// running.
+	Close()
+}
+
+// ScanStats is implemented by results that report how much data the engine
+// read.
+type ScanStats interface {
+	// BytesScanned returns the bytes the engine has read for the query so
+	// far.
+	BytesScanned() int64
+}
+
+// Engine runs statements.
+type Engine interface {
+	// Name returns the engine name.
+	Name() string
+
+	// DefaultSession returns the session of the configured catalog and
+	// schema.
+	DefaultSession() Session
+
+	// Query submits a statement and returns its result once the column
+	// metadata is known. params are bound to the statement's positional ?
+	// placeholders in order. The result must be closed.
+	Query(ctx context.Context, statement string, session Session, params ...Parameter) (Result, error)
+
+	// HealthChecker returns the health checker of the engine.
+	HealthChecker() *health.ComponentChecker
+}
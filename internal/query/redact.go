@@ -0,0 +1,108 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// whereClauseEnd are the keywords ending a WHERE clause at its nesting
+// level.
+var whereClauseEnd = map[string]bool{
+	"GROUP":     true,
+	"HAVING":    true,
+	"WINDOW":    true,
+	"ORDER":     true,
+	"LIMIT":     true,
+	"OFFSET":    true,
+	"FETCH":     true,
+	"UNION":     true,
+	"INTERSECT": true,
+	"EXCEPT":    true,
+	"RETURNING": true,
+}
+
+// RedactLiterals replaces the string and numeric literals in the WHERE
+// clauses of sql with ?, so that values filtered on are not kept in logs and
+// history. Literals elsewhere, comments and quoted identifiers are kept.
+// Malformed input is returned unchanged.
+func RedactLiterals(sql string) string {
+	var b strings.Builder
+	runes := []rune(sql)
+
+	// whereDepths holds the parenthesis depths of the enclosing WHERE
+	// clauses; literals are redacted while it is not empty.
+	var whereDepths []int
+	depth := 0
+	inWhere := func() bool { return len(whereDepths) > 0 }
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			j := i
+			for j < len(runes) && runes[j] != '\n' {
+				j++
+			}
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return sql
+			}
+			j := i + 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case r == '\'' || r == '"':
+			j, err := skipQuoted(runes, i, r)
+			if err != nil {
+				return sql
+			}
+			if r == '\'' && inWhere() {
+				b.WriteByte('?')
+			} else {
+				b.WriteString(string(runes[i:j]))
+			}
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '$') {
+				j++
+			}
+			word := strings.ToUpper(string(runes[i:j]))
+			switch {
+			case word == "WHERE":
+				whereDepths = append(whereDepths, depth)
+			case whereClauseEnd[word] && inWhere() && whereDepths[len(whereDepths)-1] == depth:
+				whereDepths = whereDepths[:len(whereDepths)-1]
+			}
+			b.WriteString(string(runes[i:j]))
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || unicode.IsLetter(runes[j])) {
+				j++
+			}
+			if inWhere() {
+				b.WriteByte('?')
+			} else {
+				b.WriteString(string(runes[i:j]))
+			}
+			i = j
+		default:
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				for inWhere() && whereDepths[len(whereDepths)-1] > depth {
+					whereDepths = whereDepths[:len(whereDepths)-1]
+				}
+			}
+			b.WriteRune(r)
+			i++
+		}
+	}
+
+	return b.String()
+}
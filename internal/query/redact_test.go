@@ -0,0 +1,60 @@
+package query
+
+import "testing"
+
+func TestRedactLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "where literals",
+			sql:  "SELECT name, 42 FROM users WHERE email = 'a@example.com' AND age > 30",
+			want: "SELECT name, 42 FROM users WHERE email = ? AND age > ?",
+		},
+		{
+			name: "escaped quote",
+			sql:  "SELECT * FROM t WHERE name = 'O''Brien'",
+			want: "SELECT * FROM t WHERE name = ?",
+		},
+		{
+			name: "clause ends at group by",
+			sql:  "SELECT substr(x, 1, 3) FROM t WHERE d >= DATE '2026-01-01' GROUP BY 1 LIMIT 10",
+			want: "SELECT substr(x, 1, 3) FROM t WHERE d >= DATE ? GROUP BY 1 LIMIT 10",
+		},
+		{
+			name: "subquery in from",
+			sql:  "SELECT * FROM (SELECT * FROM t WHERE a = 1) s JOIN u ON u.k = 'x' LIMIT 5",
+			want: "SELECT * FROM (SELECT * FROM t WHERE a = ?) s JOIN u ON u.k = 'x' LIMIT 5",
+		},
+		{
+			name: "subquery in where",
+			sql:  "SELECT * FROM t WHERE id IN (SELECT id FROM u GROUP BY id HAVING count(*) > 2) AND code = 'x' ORDER BY 1",
+			want: "SELECT * FROM t WHERE id IN (SELECT id FROM u GROUP BY id HAVING count(*) > ?) AND code = ? ORDER BY 1",
+		},
+		{
+			name: "identifiers and comments kept",
+			sql:  "SELECT \"col 1\" FROM t2 -- where x = 'y'\nWHERE \"v2\" = 'secret' /* 'z' */",
+			want: "SELECT \"col 1\" FROM t2 -- where x = 'y'\nWHERE \"v2\" = ? /* 'z' */",
+		},
+		{
+			name: "placeholders kept",
+			sql:  "DELETE FROM t WHERE a = ? AND b = 7.5",
+			want: "DELETE FROM t WHERE a = ? AND b = ?",
+		},
+		{
+			name: "malformed",
+			sql:  "SELECT * FROM t WHERE a = 'open",
+			want: "SELECT * FROM t WHERE a = 'open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactLiterals(tt.sql); got != tt.want {
+				t.Errorf("RedactLiterals() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMultipleStatements is returned for SQL holding more than one statement.
+	ErrMultipleStatements = errors.New("only a single statement can be executed")
+
+	// ErrEmptyStatement is returned for SQL without a statement.
+	ErrEmptyStatement = errors.New("statement is empty")
+)
+
+// readOnlyKeywords are the leading keywords of statements that only read.
+var readOnlyKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"EXPLAIN":  true,
+}
+
+// relationKeywords are followed by a table reference.
+var relationKeywords = map[string]bool{
+	"FROM":   true,
+	"JOIN":   true,
+	"INTO":   true,
+	"TABLE":  true,
+	"UPDATE": true,
+	"USING":  true,
+}
+
+// tokenKind classifies SQL tokens.
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenSymbol
+)
+
+// token is a lexical SQL token. Comments and string literals are dropped.
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// keyword reports whether the token is the unquoted keyword kw.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, kw)
+}
+
+// Statement is a single parsed SQL statement.
+type Statement struct {
+	// SQL is the statement without a trailing semicolon.
+	SQL    string
+	tokens []token
+}
+
+// ParseStatement splits sql into tokens, dropping a trailing semicolon. It
+// rejects empty input and input holding several statements.
+func ParseStatement(sql string) (*Statement, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(tokens) > 0 && tokens[len(tokens)-1].value == ";" && tokens[len(tokens)-1].kind == tokenSymbol {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, ErrEmptyStatement
+	}
+	for _, t := range tokens {
+		if t.kind == tokenSymbol && t.value == ";" {
+			return nil, ErrMultipleStatements
+		}
+	}
+
+	trimmed := strings.TrimSpace(sql)
+	for strings.HasSuffix(trimmed, ";") {
+		trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, ";"))
+	}
+
+	return &Statement{SQL: trimmed, tokens: tokens}, nil
+}
+
+// ReadOnly reports whether the statement only reads data: a query, SHOW,
+// DESCRIBE or EXPLAIN. EXPLAIN ANALYZE runs the statement and is only
+// read-only if the explained statement is.
+func (s *Statement) ReadOnly() bool {
+	tokens := s.tokens
+	for len(tokens) > 0 && tokens[0].kind == tokenSymbol && tokens[0].value == "(" {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || !readOnlyKeywords[strings.ToUpper(tokens[0].value)] || tokens[0].kind != tokenWord {
+		return false
+	}
+
+	if tokens[0].keyword("EXPLAIN") && len(tokens) > 1 && tokens[1].keyword("ANALYZE") {
+		rest := tokens[2:]
+		if len(rest) > 0 && rest[0].keyword("VERBOSE") {
+			rest = rest[1:]
+		}
+		return (&Statement{tokens: rest}).ReadOnly()
+	}
+	return true
+}
+
+// TableReference is a table named in a statement.
+type TableReference struct {
+	Catalog string
+	Schema  string
+	Table   string
+}
+
+// TableReferences returns the qualified table references of the statement:
+// names following FROM, JOIN, INTO, TABLE, UPDATE and USING, and further
+// entries of comma-separated FROM lists. Unqualified names resolve to the
+// session schema and are not returned. FROM inside function arguments, as in
+// EXTRACT(YEAR FROM t.ts), is not a table reference.
+func (s *Statement) TableReferences() []TableReference {
+	refs, _ := s.relations()
+	return qualified(refs)
+}
+
+// Tables returns the table references of the statement like TableReferences,
+// including unqualified names, which have an empty Schema. Unqualified names
+// may also be names of common table expressions.
+func (s *Statement) Tables() []TableReference {
+	refs, _ := s.relations()
+	return refs
+}
+
+// qualified returns the references of refs naming a schema.
+func qualified(refs []TableReference) []TableReference {
+	var out []TableReference
+	for _, ref := range refs {
+		if ref.Schema != "" {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// TableFunctions returns the names of the table functions the statement
+// reads from, such as iceberg_scan in FROM iceberg_scan('s3://...').
+func (s *Statement) TableFunctions() []string {
+	_, functions := s.relations()
+	return functions
+}
+
+// relations returns the table references, qualified or not, and the table
+// functions following relation keywords.
+func (s *Statement) relations() ([]TableReference, []string) {
+	var refs []TableReference
+	var functions []string
+	tokens := s.tokens
+	inCall := functionArguments(tokens)
+
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != tokenWord || !relationKeywords[strings.ToUpper(tokens[i].value)] || inCall[i] {
+			continue
+		}
+
+		for {
+			parts, next := readName(tokens, i+1)
+			if len(parts) > 0 && next < len(tokens) && tokens[next].kind == tokenSymbol && tokens[next].value == "(" {
+				functions = append(functions, strings.Join(parts, "."))
+				i = next - 1
+				break
+			}
+			if len(parts) > 0 {
+				ref := TableReference{Table: parts[len(parts)-1]}
+				if len(parts) >= 2 {
+					ref.Schema = parts[len(parts)-2]
+				}
+				if len(parts) >= 3 {
+					ref.Catalog = parts[len(parts)-3]
+				}
+				refs = append(refs, ref)
+			}
+			if len(parts) == 0 {
+				break
+			}
+
+			// Skip an optional alias, then continue a comma-separated list
+			if next < len(tokens) && tokens[next].keyword("AS") {
+				next++
+			}
+			if next < len(tokens) && (tokens[next].kind == tokenQuoted ||
+				(tokens[next].kind == tokenWord && !isReserved(tokens[next].value))) {
+				next++
+			}
+			if next < len(tokens) && tokens[next].kind == tokenSymbol && tokens[next].value == "," {
+				i = next
+				continue
+			}
+			i = next - 1
+			break
+		}
+	}
+
+	return refs, functions
+}
+
+// subqueryIntroducers may precede a parenthesized subquery or expression;
+// any other word before a parenthesis is a function name.
+var subqueryIntroducers = map[string]bool{
+	"IN": true, "EXISTS": true, "AS": true, "FROM": true, "JOIN": true, "ON": true,
+	"WHERE": true, "AND": true, "OR": true, "NOT": true, "ANY": true, "ALL": true,
+	"SOME": true, "SELECT": true, "LATERAL": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "WHEN": true, "THEN": true, "ELSE": true, "HAVING": true,
+	"BY": true, "TABLE": true, "USING": true, "INTO": true, "VALUES": true, "WITH": true,
+}
+
+// functionArguments marks the tokens directly inside the parentheses of a
+// function call.
+func functionArguments(tokens []token) []bool {
+	inCall := make([]bool, len(tokens))
+	var stack []bool
+
+	for i, t := range tokens {
+		if t.kind == tokenSymbol {
+			switch t.value {
+			case "(":
+				call := i > 0 && tokens[i-1].kind == tokenWord && !subqueryIntroducers[strings.ToUpper(tokens[i-1].value)]
+				stack = append(stack, call)
+				continue
+			case ")":
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				continue
+			}
+		}
+		inCall[i] = len(stack) > 0 && stack[len(stack)-1]
+	}
+
+	return inCall
+}
+
+// readName reads a dotted name starting at tokens[i] and returns its parts
+// and the index after it.
+func readName(tokens []token, i int) ([]string, int) {
+	var parts []string
+	for i < len(tokens) {
+		t := tokens[i]
+		if t.kind == tokenSymbol || (t.kind == tokenWord && len(parts) == 0 && isReserved(t.value)) {
+			break
+		}
+		parts = append(parts, t.value)
+		i++
+		if i < len(tokens) && tokens[i].kind == tokenSymbol && tokens[i].value == "." {
+			i++
+			continue
+		}
+		break
+	}
+	return parts, i
+}
+
+// reservedWords may follow a table reference and are never aliases or names.
+var reservedWords = map[string]bool{
+	"SELECT": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "FETCH": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true,
+	"FULL": true, "CROSS": true, "NATURAL": true, "ON": true, "USING": true,
+	"WINDOW": true, "SET": true, "VALUES": true, "LATERAL": true, "UNNEST": true,
+	"WITH": true, "FOR": true, "TABLESAMPLE": true, "MATCH_RECOGNIZE": true,
+	"WHEN": true, "AS": true,
+}
+
+// isReserved reports whether word is a reserved word.
+func isReserved(word string) bool {
+	return reservedWords[strings.ToUpper(word)]
+}
+
+// TenantSchemaPrefix returns the prefix of the schemas a tenant may query.
+func TenantSchemaPrefix(tenantID uuid.UUID) string {
+	return "tenant_" + strings.ReplaceAll(tenantID.String(), "-", "") + "_"
+}
+
+// TenantSchema returns the tenant's schema for schema, prefixing it unless
+// it already carries the tenant prefix.
+func TenantSchema(tenantID uuid.UUID, schema string) string {
+	prefix := TenantSchemaPrefix(tenantID)
+	if strings.HasPrefix(strings.ToLower(schema), prefix) {
+		return schema
+	}
+	return prefix + schema
+}
+
+// ValidateSchemaAccess checks that every qualified table reference of the
+// statement is in catalog and in a schema carrying prefix. Listing catalogs
+// or schemas is rejected, and SHOW TABLES must name an allowed schema. Table
+// functions are rejected as they can read data outside of any schema.
+func (s *Statement) ValidateSchemaAccess(catalog, prefix string) error {
+	refs, functions := s.relations()
+	refs = qualified(refs)
+	if len(functions) > 0 {
+		return fmt.Errorf("table function %s is not allowed", functions[0])
+	}
+
+	if len(s.tokens) > 1 && s.tokens[0].keyword("SHOW") {
+		switch {
+		case s.tokens[1].keyword("CATALOGS"), s.tokens[1].keyword("SCHEMAS"):
+			return fmt.Errorf("listing %s is not allowed", strings.ToLower(s.tokens[1].value))
+		case s.tokens[1].keyword("TABLES") && len(s.tokens) > 2 && (s.tokens[2].keyword("FROM") || s.tokens[2].keyword("IN")):
+			// The name is a schema, not a table: validate it as one
+			parts, _ := readName(s.tokens, 3)
+			if len(parts) > 0 {
+				ref := TableReference{Schema: parts[len(parts)-1]}
+				if len(parts) > 1 {
+					ref.Catalog = parts[len(parts)-2]
+				}
+				refs = []TableReference{ref}
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		if ref.Catalog != "" && !strings.EqualFold(ref.Catalog, catalog) {
+			return fmt.Errorf("access to catalog %s is not allowed", ref.Catalog)
+		}
+		if !strings.HasPrefix(strings.ToLower(ref.Schema), prefix) {
+			return fmt.Errorf("access to schema %s is not allowed", ref.Schema)
+		}
+	}
+	return nil
+}
+
+// tokenize splits sql into tokens, skipping whitespace, comments and string
+// literals. Quoted identifiers keep their unescaped name.
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(sql)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+		case r == '\'':
+			j, err := skipQuoted(runes, i, '\'')
+			if err != nil {
+				return nil, errors.New("unterminated string literal")
+			}
+			i = j
+		case r == '"':
+			j, err := skipQuoted(runes, i, '"')
+			if err != nil {
+				return nil, errors.New("unterminated quoted identifier")
+			}
+			name := strings.ReplaceAll(string(runes[i+1:j-1]), `""`, `"`)
+			tokens = append(tokens, token{kind: tokenQuoted, value: name})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[i:j])})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || unicode.IsLetter(runes[j])) {
+				j++
+			}
+			i = j
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(r)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// skipQuoted returns the index after the quoted text starting at runes[i],
+// where a doubled quote escapes the quote.
+func skipQuoted(runes []rune, i int, quote rune) (int, error) {
+	for j := i + 1; j < len(runes); j++ {
+		if runes[j] != quote {
+			continue
+		}
+		if j+1 < len(runes) && runes[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1, nil
+	}
+	return 0, errors.New("unterminated quote")
+}
@@ -0,0 +1,124 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		sql      string
+		wantErr  error
+		readOnly bool
+	}{
+		{"SELECT * FROM orders", nil, true},
+		{"  with t AS (SELECT 1) SELECT * FROM t;", nil, true},
+		{"SHOW TABLES", nil, true},
+		{"EXPLAIN SELECT 1", nil, true},
+		{"EXPLAIN ANALYZE DELETE FROM orders", nil, false},
+		{"-- comment\nSELECT ';' AS x", nil, true},
+		{"INSERT INTO orders VALUES (1)", nil, false},
+		{"DROP TABLE orders", nil, false},
+		{"SELECT 1; DROP TABLE orders", ErrMultipleStatements, false},
+		{"  ; ", ErrEmptyStatement, false},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ParseStatement(tt.sql)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseStatement(%q) error = %v, want %v", tt.sql, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseStatement(%q) error = %v", tt.sql, err)
+			continue
+		}
+		if got := stmt.ReadOnly(); got != tt.readOnly {
+			t.Errorf("ParseStatement(%q).ReadOnly() = %v, want %v", tt.sql, got, tt.readOnly)
+		}
+	}
+}
+
+func TestStatement_TableReferences(t *testing.T) {
+	stmt, err := ParseStatement(`SELECT EXTRACT(YEAR FROM o.ts) FROM iceberg.sales.orders o, sales."Line Items" AS l JOIN customers c ON c.id = o.customer_id`)
+	if err != nil {
+		t.Fatalf("ParseStatement() error = %v", err)
+	}
+
+	refs := stmt.TableReferences()
+	want := []TableReference{
+		{Catalog: "iceberg", Schema: "sales", Table: "orders"},
+		{Schema: "sales", Table: "Line Items"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("TableReferences() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("TableReferences()[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
+
+func TestStatement_TableFunctions(t *testing.T) {
+	stmt, err := ParseStatement("SELECT count(*) FROM iceberg_scan('s3://philotes/orders') o JOIN t ON o.id = t.id")
+	if err != nil {
+		t.Fatalf("ParseStatement() error = %v", err)
+	}
+
+	functions := stmt.TableFunctions()
+	if len(functions) != 1 || functions[0] != "iceberg_scan" {
+		t.Errorf("TableFunctions() = %v, want [iceberg_scan]", functions)
+	}
+}
+
+func TestStatement_ValidateSchemaAccess(t *testing.T) {
+	tenantID := uuid.New()
+	prefix := TenantSchemaPrefix(tenantID)
+	own := TenantSchema(tenantID, "sales")
+
+	tests := []struct {
+		sql     string
+		wantErr bool
+	}{
+		{"SELECT * FROM orders", false},
+		{"SELECT * FROM " + own + ".orders", false},
+		{"SELECT * FROM iceberg." + own + ".orders", false},
+		{"SELECT * FROM other.orders", true},
+		{"SELECT * FROM hive." + own + ".orders", true},
+		{"SELECT * FROM orders WHERE id IN (SELECT id FROM other.orders)", true},
+		{"SHOW TABLES FROM " + own, false},
+		{"SHOW TABLES FROM other", true},
+		{"SHOW SCHEMAS", true},
+		{"SHOW CATALOGS", true},
+		{"SELECT * FROM iceberg_scan('s3://philotes/other/orders')", true},
+		{"SELECT * FROM TABLE(system.query(query => 'SELECT 1'))", true},
+		{"SELECT * FROM orders CROSS JOIN UNNEST(orders.items) AS i", false},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ParseStatement(tt.sql)
+		if err != nil {
+			t.Fatalf("ParseStatement(%q) error = %v", tt.sql, err)
+		}
+		err = stmt.ValidateSchemaAccess("iceberg", prefix)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSchemaAccess(%q) error = %v, wantErr %v", tt.sql, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTenantSchema(t *testing.T) {
+	tenantID := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	if got := TenantSchema(tenantID, "sales"); got != "tenant_6ba7b8109dad11d180b400c04fd430c8_sales" {
+		t.Errorf("TenantSchema() = %s", got)
+	}
+	if got := TenantSchema(tenantID, TenantSchema(tenantID, "sales")); got != "tenant_6ba7b8109dad11d180b400c04fd430c8_sales" {
+		t.Errorf("TenantSchema() of a tenant schema = %s, want it unchanged", got)
+	}
+}
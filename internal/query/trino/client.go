@@ -0,0 +1,436 @@
+// Package trino provides a client for running SQL on a Trino coordinator
+// through its REST protocol.
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// defaultUser is the Trino user for queries when none is configured.
+const defaultUser = "philotes"
+
+// preparedStatementName is the name statements with parameters are
+// prepared under.
+const preparedStatementName = "philotes_statement"
+
+// busyRetryInterval is how long to wait before retrying a request the
+// coordinator answered with 502, 503 or 504, as the protocol asks.
+const busyRetryInterval = 100 * time.Millisecond
+
+// QueryError is an error reported by Trino for a query.
+type QueryError struct {
+	Message   string `json:"message"`
+	ErrorCode int    `json:"errorCode"`
+	ErrorName string `json:"errorName"`
+	ErrorType string `json:"errorType"`
+}
+
+// Error implements the error interface.
+func (e *QueryError) Error() string {
+	if e.ErrorName != "" {
+		return fmt.Sprintf("%s: %s", e.ErrorName, e.Message)
+	}
+	return e.Message
+}
+
+// Is reports a Trino query error as query.ErrStatementFailed.
+func (e *QueryError) Is(target error) bool {
+	return target == query.ErrStatementFailed
+}
+
+// queryResults is a page of the Trino statement protocol.
+type queryResults struct {
+	ID      string          `json:"id"`
+	NextURI string          `json:"nextUri"`
+	Columns []query.Column  `json:"columns"`
+	Data    [][]interface{} `json:"data"`
+	Error   *QueryError     `json:"error"`
+	Stats   struct {
+		State          string `json:"state"`
+		ProcessedBytes int64  `json:"processedBytes"`
+	} `json:"stats"`
+}
+
+// Client runs queries on a Trino coordinator.
+type Client struct {
+	cfg        config.TrinoConfig
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	// Cached health check result
+	healthMu      sync.Mutex
+	healthChecked time.Time
+	healthErr     error
+}
+
+// NewClient creates a new Trino client.
+func NewClient(cfg config.TrinoConfig, logger *slog.Logger) *Client {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{},
+		logger:     logger.With("component", "trino-client"),
+	}
+}
+
+// Name returns the engine name.
+func (c *Client) Name() string {
+	return config.QueryEngineTrino
+}
+
+// DefaultSession returns the session of the configured catalog and schema.
+func (c *Client) DefaultSession() query.Session {
+	return query.Session{Catalog: c.cfg.Catalog, Schema: c.cfg.Schema}
+}
+
+// Query submits a statement and returns its result once the column metadata
+// is known. A statement with parameters is sent as a prepared statement and
+// executed with the parameters as typed literals. The query is bounded by
+// QueryTimeout; the result must be closed, which cancels the query if it has
+// not finished.
+func (c *Client) Query(ctx context.Context, statement string, session query.Session, params ...query.Parameter) (query.Result, error) {
+	if !c.cfg.Enabled {
+		return nil, query.ErrNotEnabled
+	}
+
+	body := statement
+	if len(params) > 0 {
+		literals := make([]string, len(params))
+		for i, p := range params {
+			lit, err := literal(p)
+			if err != nil {
+				return nil, err
+			}
+			literals[i] = lit
+		}
+		body = "EXECUTE " + preparedStatementName + " USING " + strings.Join(literals, ", ")
+	}
+
+	queryCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.cfg.QueryTimeout > 0 {
+		queryCtx, cancel = context.WithTimeout(ctx, c.cfg.QueryTimeout)
+	}
+
+	req, err := http.NewRequestWithContext(queryCtx, http.MethodPost, c.url("/v1/statement"), strings.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if session.Catalog != "" {
+		req.Header.Set("X-Trino-Catalog", session.Catalog)
+	}
+	if session.Schema != "" {
+		req.Header.Set("X-Trino-Schema", session.Schema)
+	}
+	if len(params) > 0 {
+		req.Header.Set("X-Trino-Prepared-Statement", preparedStatementName+"="+url.QueryEscape(statement))
+	}
+	if c.cfg.QueryTimeout > 0 {
+		// Let the coordinator stop the query too, not just the client
+		req.Header.Set("X-Trino-Session", fmt.Sprintf("query_max_run_time=%ds", int(c.cfg.QueryTimeout.Seconds())))
+	}
+
+	result := &Result{client: c, ctx: queryCtx, cancel: cancel}
+	page, err := c.do(queryCtx, req)
+	if err != nil {
+		result.Close()
+		return nil, c.queryErr(queryCtx, err)
+	}
+	result.apply(page)
+
+	// Columns arrive with the first page that has them, which may be
+	// preceded by pages only reporting progress.
+	for result.columns == nil && result.nextURI != "" {
+		if err := result.fetch(); err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+	if result.err != nil {
+		result.Close()
+		return nil, result.err
+	}
+
+	return result, nil
+}
+
+// QueryAll runs a statement with params and returns all of its rows.
+func (c *Client) QueryAll(ctx context.Context, statement string, session query.Session, params ...query.Parameter) ([]query.Column, [][]interface{}, error) {
+	result, err := c.Query(ctx, statement, session, params...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close()
+
+	var rows [][]interface{}
+	for {
+		page, err := result.Next()
+		if errors.Is(err, io.EOF) {
+			return result.Columns(), rows, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, page...)
+	}
+}
+
+// Ping checks that the coordinator is reachable and has finished starting.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/v1/info"), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Trino returned status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Starting bool `json:"starting"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to decode cluster info: %w", err)
+	}
+	if info.Starting {
+		return errors.New("Trino coordinator is starting")
+	}
+	return nil
+}
+
+// do sends a protocol request, retrying while the coordinator is busy, and
+// decodes the returned page.
+func (c *Client) do(ctx context.Context, req *http.Request) (*queryResults, error) {
+	c.authenticate(req)
+
+	for {
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var page queryResults
+			err := json.NewDecoder(resp.Body).Decode(&page)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode query response: %w", err)
+			}
+			return &page, nil
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			resp.Body.Close()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(busyRetryInterval):
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		default:
+			body, readErr := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("query failed with status %d (failed to read body: %v)", resp.StatusCode, readErr)
+			}
+			return nil, fmt.Errorf("query failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+	}
+}
+
+// authenticate sets the user and credentials of a request.
+func (c *Client) authenticate(req *http.Request) {
+	user := c.cfg.Username
+	if user == "" {
+		user = defaultUser
+	}
+	req.Header.Set("X-Trino-User", user)
+
+	if c.cfg.Username != "" && c.cfg.Password != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+}
+
+// cancelQuery asks the coordinator to stop a query. It runs detached from
+// the query context, which is usually done by then.
+func (c *Client) cancelQuery(ctx context.Context, nextURI string) {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(cancelCtx, http.MethodDelete, nextURI, http.NoBody)
+	if err != nil {
+		return
+	}
+	c.authenticate(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Debug("failed to cancel query", "error", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// queryErr maps a context deadline of the query to ErrQueryTimeout.
+func (c *Client) queryErr(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w (%s)", query.ErrQueryTimeout, c.cfg.QueryTimeout)
+	}
+	return err
+}
+
+// literal renders a parameter as a typed SQL literal.
+func literal(p query.Parameter) (string, error) {
+	switch v := p.Value.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case int64:
+		return "BIGINT '" + strconv.FormatInt(v, 10) + "'", nil
+	case float64:
+		return "DOUBLE '" + strconv.FormatFloat(v, 'g', -1, 64) + "'", nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v)), nil
+	case time.Time:
+		if p.Type == query.ParameterDate {
+			return "DATE '" + v.Format("2006-01-02") + "'", nil
+		}
+		return "TIMESTAMP '" + v.UTC().Format("2006-01-02 15:04:05.000000") + " UTC'", nil
+	default:
+		return "", fmt.Errorf("unsupported parameter value of type %T", p.Value)
+	}
+}
+
+// url returns the coordinator URL of path.
+func (c *Client) url(path string) string {
+	return strings.TrimSuffix(c.cfg.URL, "/") + path
+}
+
+var _ query.Engine = (*Client)(nil)
+
+// Result is the result of a running query. Rows are fetched page by page
+// from the coordinator as Next is called.
+type Result struct {
+	client  *Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	id      string
+	nextURI string
+	columns []query.Column
+	pending [][]interface{}
+	bytes   int64
+	err     error
+	closed  bool
+}
+
+// ID returns the Trino query ID.
+func (r *Result) ID() string {
+	return r.id
+}
+
+// Columns returns the result columns.
+func (r *Result) Columns() []query.Column {
+	return r.columns
+}
+
+// BytesScanned returns the bytes Trino reported as processed by the query so
+// far.
+func (r *Result) BytesScanned() int64 {
+	return r.bytes
+}
+
+// Next returns the next page of rows. It returns io.EOF after the last page.
+func (r *Result) Next() ([][]interface{}, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		if r.nextURI == "" {
+			return nil, io.EOF
+		}
+		if err := r.fetch(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := r.pending
+	r.pending = nil
+	return rows, nil
+}
+
+// Close releases the result, cancelling the query if it is still running.
+func (r *Result) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	if r.nextURI != "" {
+		r.client.cancelQuery(r.ctx, r.nextURI)
+	}
+	r.cancel()
+}
+
+// fetch follows nextUri to the next page.
+func (r *Result) fetch() error {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.nextURI, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	page, err := r.client.do(r.ctx, req)
+	if err != nil {
+		r.err = r.client.queryErr(r.ctx, err)
+		return r.err
+	}
+	r.apply(page)
+	return r.err
+}
+
+// apply takes over the state of a page.
+func (r *Result) apply(page *queryResults) {
+	if page.ID != "" {
+		r.id = page.ID
+	}
+	r.nextURI = page.NextURI
+	if page.Columns != nil && r.columns == nil {
+		r.columns = page.Columns
+	}
+	r.pending = append(r.pending, page.Data...)
+	if page.Stats.ProcessedBytes > r.bytes {
+		r.bytes = page.Stats.ProcessedBytes
+	}
+	if page.Error != nil {
+		r.err = page.Error
+		r.nextURI = ""
+	}
+}
@@ -0,0 +1,182 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/query"
+)
+
+// newTestServer returns a Trino stub answering the statement protocol with
+// pages: the submission returns pages[0] and every nextUri the following one.
+func newTestServer(t *testing.T, pages []string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var cancelled atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			cancelled.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		idx := 0
+		if r.Method == http.MethodGet {
+			if _, err := fmt.Sscan(strings.TrimPrefix(r.URL.Path, "/v1/statement/q/"), &idx); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		page := strings.ReplaceAll(pages[idx], "NEXT", srv.URL+"/v1/statement/q/"+strconv.Itoa(idx+1))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(page)) //nolint:errcheck // test stub
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &cancelled
+}
+
+func newTestClient(url string, timeout time.Duration) *Client {
+	return NewClient(config.TrinoConfig{Enabled: true, URL: url, Catalog: "iceberg", Schema: "default", QueryTimeout: timeout}, nil)
+}
+
+func TestClient_Query(t *testing.T) {
+	srv, _ := newTestServer(t, []string{
+		`{"id":"q1","nextUri":"NEXT","stats":{"state":"QUEUED"}}`,
+		`{"id":"q1","nextUri":"NEXT","columns":[{"name":"id","type":"bigint"},{"name":"name","type":"varchar"}],"data":[[1,"a"]]}`,
+		`{"id":"q1","nextUri":"NEXT","data":[[2,"b"],[3,"c"]]}`,
+		`{"id":"q1","stats":{"state":"FINISHED"}}`,
+	})
+
+	columns, rows, err := newTestClient(srv.URL, time.Minute).QueryAll(context.Background(), "SELECT id, name FROM t", query.Session{})
+	if err != nil {
+		t.Fatalf("QueryAll() error = %v", err)
+	}
+	if len(columns) != 2 || columns[0].Name != "id" || columns[1].Type != "varchar" {
+		t.Errorf("columns = %+v, want id bigint and name varchar", columns)
+	}
+	if len(rows) != 3 || rows[2][1] != "c" {
+		t.Errorf("rows = %v, want 3 rows", rows)
+	}
+}
+
+func TestResult_BytesScanned(t *testing.T) {
+	srv, _ := newTestServer(t, []string{
+		`{"id":"q1","nextUri":"NEXT","columns":[{"name":"n","type":"bigint"}],"stats":{"state":"RUNNING","processedBytes":1024}}`,
+		`{"id":"q1","data":[[1]],"stats":{"state":"FINISHED","processedBytes":4096}}`,
+	})
+
+	result, err := newTestClient(srv.URL, time.Minute).Query(context.Background(), "SELECT n FROM t", query.Session{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer result.Close()
+
+	for {
+		if _, err := result.Next(); err != nil {
+			break
+		}
+	}
+	scanned, ok := result.(query.ScanStats)
+	if !ok {
+		t.Fatal("result does not implement query.ScanStats")
+	}
+	if got := scanned.BytesScanned(); got != 4096 {
+		t.Errorf("BytesScanned() = %d, want 4096", got)
+	}
+}
+
+func TestClient_QueryWithParameters(t *testing.T) {
+	var prepared, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prepared = r.Header.Get("X-Trino-Prepared-Statement")
+		data, _ := io.ReadAll(r.Body) //nolint:errcheck // test stub
+		body = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"q1","columns":[{"name":"n","type":"bigint"}],"data":[[1]]}`)) //nolint:errcheck // test stub
+	}))
+	t.Cleanup(srv.Close)
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, _, err := newTestClient(srv.URL, time.Minute).QueryAll(context.Background(),
+		"SELECT count(*) FROM orders WHERE customer = ? AND day >= ? AND total > ?", query.Session{},
+		query.Parameter{Type: query.ParameterString, Value: "o'brien"},
+		query.Parameter{Type: query.ParameterDate, Value: day},
+		query.Parameter{Type: query.ParameterNumber, Value: 1.5},
+	)
+	if err != nil {
+		t.Fatalf("QueryAll() error = %v", err)
+	}
+
+	if want := "philotes_statement=SELECT+count%28%2A%29+FROM+orders+WHERE+customer+%3D+%3F+AND+day+%3E%3D+%3F+AND+total+%3E+%3F"; prepared != want {
+		t.Errorf("X-Trino-Prepared-Statement = %q, want %q", prepared, want)
+	}
+	if want := "EXECUTE philotes_statement USING 'o''brien', DATE '2026-03-01', DOUBLE '1.5'"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestClient_QueryError(t *testing.T) {
+	srv, _ := newTestServer(t, []string{
+		`{"id":"q1","nextUri":"NEXT"}`,
+		`{"id":"q1","error":{"message":"line 1:15: Table 'iceberg.default.nope' does not exist","errorCode":46,"errorName":"TABLE_NOT_FOUND","errorType":"USER_ERROR"}}`,
+	})
+
+	_, err := newTestClient(srv.URL, time.Minute).Query(context.Background(), "SELECT * FROM nope", query.Session{})
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || queryErr.ErrorName != "TABLE_NOT_FOUND" {
+		t.Fatalf("Query() error = %v, want TABLE_NOT_FOUND QueryError", err)
+	}
+	if !errors.Is(err, query.ErrStatementFailed) {
+		t.Errorf("Query() error = %v, want it to match query.ErrStatementFailed", err)
+	}
+}
+
+func TestClient_QueryTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	_, err := newTestClient(srv.URL, 50*time.Millisecond).Query(context.Background(), "SELECT 1", query.Session{})
+	if !errors.Is(err, query.ErrQueryTimeout) {
+		t.Fatalf("Query() error = %v, want ErrQueryTimeout", err)
+	}
+}
+
+func TestClient_CloseCancelsRunningQuery(t *testing.T) {
+	srv, cancelled := newTestServer(t, []string{
+		`{"id":"q1","nextUri":"NEXT","columns":[{"name":"n","type":"integer"}],"data":[[1]]}`,
+		`{"id":"q1","data":[[2]]}`,
+	})
+
+	result, err := newTestClient(srv.URL, time.Minute).Query(context.Background(), "SELECT n FROM t", query.Session{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	result.Close()
+
+	if cancelled.Load() != 1 {
+		t.Errorf("cancel requests = %d, want 1", cancelled.Load())
+	}
+}
+
+func TestClient_NotEnabled(t *testing.T) {
+	c := NewClient(config.TrinoConfig{}, nil)
+	if _, err := c.Query(context.Background(), "SELECT 1", query.Session{}); !errors.Is(err, query.ErrNotEnabled) {
+		t.Errorf("Query() error = %v, want ErrNotEnabled", err)
+	}
+}
@@ -0,0 +1,34 @@
+package trino
+
+import (
+	"context"
+	"time"
+
+	"github.com/janovincze/philotes/internal/cdc/health"
+)
+
+// HealthChecker returns a health checker for the coordinator. The check
+// result is reused for HealthCheckInterval so frequent health requests do
+// not load the coordinator.
+func (c *Client) HealthChecker() *health.ComponentChecker {
+	checker := health.NewComponentChecker("trino", c.checkHealth)
+	checker.SetComponent(health.ComponentQueryEngine)
+	return checker
+}
+
+// checkHealth pings the coordinator unless the last result is recent enough.
+func (c *Client) checkHealth(ctx context.Context) (health.Status, string, error) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	now := time.Now()
+	if c.healthChecked.IsZero() || now.Sub(c.healthChecked) >= c.cfg.HealthCheckInterval {
+		c.healthErr = c.Ping(ctx)
+		c.healthChecked = now
+	}
+
+	if c.healthErr != nil {
+		return health.StatusUnhealthy, "Trino coordinator unavailable", c.healthErr
+	}
+	return health.StatusHealthy, "Trino coordinator OK", nil
+}
@@ -0,0 +1,391 @@
+// Package digitalocean provides DigitalOcean cloud provider implementation.
+package digitalocean
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/janovincze/philotes/internal/scaling/cloudprovider"
+)
+
+const baseURL = "https://api.digitalocean.com/v2"
+
+// invalidTagChars matches the characters DigitalOcean does not allow in tags.
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9:_-]`)
+
+// Provider implements cloudprovider.NodeProvider for DigitalOcean.
+type Provider struct {
+	httpClient *http.Client
+	logger     *slog.Logger
+	config     cloudprovider.ProviderConfig
+	token      string
+}
+
+// New creates a new DigitalOcean provider.
+func New(token string, logger *slog.Logger, config cloudprovider.ProviderConfig) (*Provider, error) {
+	if token == "" {
+		return nil, cloudprovider.ErrInvalidCredentials
+	}
+
+	return &Provider{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		logger:     logger.With("provider", "digitalocean"),
+		config:     config,
+		token:      token,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "digitalocean"
+}
+
+// Regions returns available DigitalOcean regions.
+func (p *Provider) Regions() []string {
+	return []string{"fra1", "ams3", "lon1", "nyc1", "nyc3", "sfo3", "tor1", "sgp1", "blr1", "syd1"}
+}
+
+// droplet is a droplet as returned by the DigitalOcean API.
+type droplet struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Size   string `json:"size_slug"`
+	Region struct {
+		Slug string `json:"slug"`
+	} `json:"region"`
+	Networks struct {
+		V4 []struct {
+			IPAddress string `json:"ip_address"`
+			Type      string `json:"type"`
+		} `json:"v4"`
+	} `json:"networks"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateServer creates a new droplet. Spot instances are not available on
+// DigitalOcean, so opts.UseSpot is ignored.
+func (p *Provider) CreateServer(ctx context.Context, opts cloudprovider.CreateServerOptions) (*cloudprovider.Server, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	p.logger.Info("creating server",
+		"name", opts.Name,
+		"region", opts.Region,
+		"type", opts.InstanceType,
+	)
+
+	// Build labels
+	labels := make(map[string]string)
+	for k, v := range p.config.DefaultLabels {
+		labels[k] = v
+	}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+
+	type createRequest struct {
+		Name     string   `json:"name"`
+		Region   string   `json:"region"`
+		Size     string   `json:"size"`
+		Image    string   `json:"image"`
+		SSHKeys  []string `json:"ssh_keys,omitempty"`
+		UserData string   `json:"user_data,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+		VPCUUID  string   `json:"vpc_uuid,omitempty"`
+	}
+
+	reqBody := createRequest{
+		Name:     opts.Name,
+		Region:   opts.Region,
+		Size:     opts.InstanceType,
+		Image:    opts.Image,
+		SSHKeys:  opts.SSHKeyIDs, // IDs or fingerprints
+		UserData: opts.UserData,
+		Tags:     labelsToTags(labels),
+		VPCUUID:  opts.NetworkID,
+	}
+
+	var createResp struct {
+		Droplet droplet `json:"droplet"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/droplets", reqBody, &createResp); err != nil {
+		return nil, fmt.Errorf("failed to create server: %w", err)
+	}
+
+	d := createResp.Droplet
+	p.logger.Info("server created", "id", d.ID, "name", d.Name)
+
+	// Add to firewall if specified
+	if opts.FirewallID != "" {
+		fwBody := map[string][]int64{"droplet_ids": {d.ID}}
+		if err := p.do(ctx, http.MethodPost, "/firewalls/"+url.PathEscape(opts.FirewallID)+"/droplets", fwBody, nil); err != nil {
+			p.logger.Warn("failed to add server to firewall", "id", d.ID, "firewall", opts.FirewallID, "error", err)
+		}
+	}
+
+	server := toServer(&d)
+	server.Labels = labels
+	return server, nil
+}
+
+// DeleteServer deletes a droplet.
+func (p *Provider) DeleteServer(ctx context.Context, serverID string) error {
+	p.logger.Info("deleting server", "id", serverID)
+
+	if err := p.do(ctx, http.MethodDelete, "/droplets/"+url.PathEscape(serverID), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete server: %w", err)
+	}
+
+	p.logger.Info("server deleted", "id", serverID)
+	return nil
+}
+
+// GetServer retrieves a droplet by ID.
+func (p *Provider) GetServer(ctx context.Context, serverID string) (*cloudprovider.Server, error) {
+	var getResp struct {
+		Droplet droplet `json:"droplet"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/droplets/"+url.PathEscape(serverID), nil, &getResp); err != nil {
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+	return toServer(&getResp.Droplet), nil
+}
+
+// ListServers lists droplets matching the given labels. The API filters by
+// a single tag, so the remaining labels are matched here.
+func (p *Provider) ListServers(ctx context.Context, labels map[string]string) ([]cloudprovider.Server, error) {
+	tags := labelsToTags(labels)
+
+	query := url.Values{"per_page": {"200"}}
+	if len(tags) > 0 {
+		query.Set("tag_name", tags[0])
+	}
+
+	var result []cloudprovider.Server
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var listResp struct {
+			Droplets []droplet `json:"droplets"`
+			Links    struct {
+				Pages struct {
+					Next string `json:"next"`
+				} `json:"pages"`
+			} `json:"links"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/droplets?"+query.Encode(), nil, &listResp); err != nil {
+			return nil, fmt.Errorf("failed to list servers: %w", err)
+		}
+
+		for i := range listResp.Droplets {
+			if hasTags(listResp.Droplets[i].Tags, tags) {
+				result = append(result, *toServer(&listResp.Droplets[i]))
+			}
+		}
+
+		if listResp.Links.Pages.Next == "" {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// GetInstanceType retrieves a droplet size by name.
+func (p *Provider) GetInstanceType(ctx context.Context, typeName, region string) (*cloudprovider.InstanceType, error) {
+	types, err := p.ListInstanceTypes(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	for i := range types {
+		if types[i].Name == typeName {
+			return &types[i], nil
+		}
+	}
+	return nil, fmt.Errorf("instance type not found: %s", typeName)
+}
+
+// ListInstanceTypes lists the droplet sizes available in a region, or in
+// any region if region is empty. Hourly costs are in USD.
+func (p *Provider) ListInstanceTypes(ctx context.Context, region string) ([]cloudprovider.InstanceType, error) {
+	var sizesResp struct {
+		Sizes []struct {
+			Slug        string   `json:"slug"`
+			Memory      int      `json:"memory"`
+			VCPUs       int      `json:"vcpus"`
+			Disk        int      `json:"disk"`
+			PriceHourly float64  `json:"price_hourly"`
+			Regions     []string `json:"regions"`
+			Available   bool     `json:"available"`
+		} `json:"sizes"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/sizes?per_page=200", nil, &sizesResp); err != nil {
+		return nil, fmt.Errorf("failed to list sizes: %w", err)
+	}
+
+	result := make([]cloudprovider.InstanceType, 0, len(sizesResp.Sizes))
+	for _, s := range sizesResp.Sizes {
+		if !s.Available {
+			continue
+		}
+		if region != "" && !slices.Contains(s.Regions, region) {
+			continue
+		}
+		result = append(result, cloudprovider.InstanceType{
+			Name:        s.Slug,
+			CPUCores:    s.VCPUs,
+			MemoryMB:    s.Memory,
+			DiskGB:      s.Disk,
+			HourlyCost:  s.PriceHourly,
+			SpotSupport: false, // DigitalOcean doesn't have spot instances
+		})
+	}
+
+	return result, nil
+}
+
+// IsServerReady checks if the droplet is ready.
+func (p *Provider) IsServerReady(ctx context.Context, serverID string) (bool, error) {
+	server, err := p.GetServer(ctx, serverID)
+	if err != nil {
+		return false, err
+	}
+
+	return server.Status == cloudprovider.ServerStatusRunning, nil
+}
+
+// do sends a request to the DigitalOcean API, decoding the response into
+// out if it is not nil. API errors are mapped to the cloudprovider errors
+// where one applies.
+func (p *Provider) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return cloudprovider.ErrServerNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return cloudprovider.ErrInvalidCredentials
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return cloudprovider.ErrRateLimited
+	case resp.StatusCode >= 300:
+		respBody, _ := io.ReadAll(resp.Body) //nolint:errcheck // best-effort error body read
+		if resp.StatusCode == http.StatusUnprocessableEntity && strings.Contains(string(respBody), "limit") {
+			return cloudprovider.ErrQuotaExceeded
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// toServer converts a droplet to a cloudprovider.Server.
+func toServer(d *droplet) *cloudprovider.Server {
+	server := &cloudprovider.Server{
+		ID:        strconv.FormatInt(d.ID, 10),
+		Name:      d.Name,
+		Status:    mapStatus(d.Status),
+		Region:    d.Region.Slug,
+		Type:      d.Size,
+		Labels:    tagsToLabels(d.Tags),
+		CreatedAt: d.CreatedAt,
+	}
+	for _, n := range d.Networks.V4 {
+		switch n.Type {
+		case "public":
+			server.PublicIP = n.IPAddress
+		case "private":
+			server.PrivateIP = n.IPAddress
+		}
+	}
+	return server
+}
+
+// mapStatus maps DigitalOcean droplet status to cloudprovider status.
+func mapStatus(status string) cloudprovider.ServerStatus {
+	switch status {
+	case "new":
+		return cloudprovider.ServerStatusCreating
+	case "active":
+		return cloudprovider.ServerStatusRunning
+	case "off":
+		return cloudprovider.ServerStatusStopped
+	case "archive":
+		return cloudprovider.ServerStatusDeleted
+	default:
+		return cloudprovider.ServerStatusUnknown
+	}
+}
+
+// labelsToTags converts labels to "key:value" tags, the way DigitalOcean
+// droplets are labelled. Characters tags cannot hold are replaced with "_",
+// so labels like "philotes.io/pool" become "philotes_io_pool".
+func labelsToTags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for k, v := range labels {
+		tags = append(tags, invalidTagChars.ReplaceAllString(k, "_")+":"+invalidTagChars.ReplaceAllString(v, "_"))
+	}
+	return tags
+}
+
+// tagsToLabels converts "key:value" tags back to labels, skipping other tags.
+func tagsToLabels(tags []string) map[string]string {
+	labels := make(map[string]string)
+	for _, tag := range tags {
+		if k, v, ok := strings.Cut(tag, ":"); ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// hasTags reports whether tags contains every one of want.
+func hasTags(tags, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(tags, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -116,6 +116,21 @@ type NodeProvider interface {
 	Regions() []string
 }
 
+// ReclamationNotice reports a spot server the provider has reclaimed or is
+// about to reclaim.
+type ReclamationNotice struct {
+	ServerID     string     `json:"server_id"`
+	NoticedAt    time.Time  `json:"noticed_at"`
+	TerminatesAt *time.Time `json:"terminates_at,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+}
+
+// SpotReclaimer is implemented by providers that publish reclamation notices
+// for spot servers. Providers without it are checked by polling GetServer.
+type SpotReclaimer interface {
+	ListReclamations(ctx context.Context, labels map[string]string) ([]ReclamationNotice, error)
+}
+
 // ProviderConfig holds common configuration for cloud providers.
 type ProviderConfig struct {
 	// Provider-specific credentials (varies by provider)
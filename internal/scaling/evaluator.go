@@ -15,9 +15,10 @@ import (
 
 // Evaluator queries Prometheus and evaluates scaling rules.
 type Evaluator struct {
-	prometheusURL string
-	httpClient    *http.Client
-	logger        *slog.Logger
+	prometheusURL   string
+	httpClient      *http.Client
+	logger          *slog.Logger
+	defaultCooldown time.Duration
 }
 
 // prometheusResponse represents the response from Prometheus query API.
@@ -52,6 +53,16 @@ func NewEvaluator(prometheusURL string, logger *slog.Logger) *Evaluator {
 	}
 }
 
+// SetDefaultCooldown sets the cooldown applied to policies without their own.
+func (e *Evaluator) SetDefaultCooldown(d time.Duration) {
+	e.defaultCooldown = d
+}
+
+// DefaultCooldown returns the cooldown applied to policies without their own.
+func (e *Evaluator) DefaultCooldown() time.Duration {
+	return e.defaultCooldown
+}
+
 // EvaluateRule queries Prometheus and checks if the rule condition is met.
 func (e *Evaluator) EvaluateRule(ctx context.Context, rule *Rule) (*EvaluationResult, error) {
 	value, err := e.queryMetric(ctx, rule.Metric)
@@ -89,8 +100,7 @@ func (e *Evaluator) EvaluatePolicy(ctx context.Context, policy *Policy, state *S
 	}
 
 	// Check cooldown period
-	if state.IsInCooldown(policy.CooldownDuration()) {
-		cooldownRemaining := policy.CooldownDuration() - time.Since(*state.LastScaleTime)
+	if cooldownRemaining := state.CooldownRemaining(policy.CooldownDuration(e.defaultCooldown)); cooldownRemaining > 0 {
 		decision.CooldownRemaining = cooldownRemaining
 		decision.Reason = fmt.Sprintf("in cooldown (%.0fs remaining)", cooldownRemaining.Seconds())
 		e.logger.Debug("policy in cooldown",
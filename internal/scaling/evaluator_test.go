@@ -0,0 +1,157 @@
+package scaling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestEvaluator returns an evaluator backed by a Prometheus stub that
+// reports value for every query.
+func newTestEvaluator(t *testing.T, value string) *Evaluator {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[0,"` + value + `"]}]}}`)) //nolint:errcheck // test stub
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewEvaluator(srv.URL, nil)
+}
+
+// scaleUpPolicy returns a policy whose single scale-up rule triggers when the
+// metric exceeds 80.
+func scaleUpPolicy(cooldownSeconds *int) *Policy {
+	return &Policy{
+		ID:              uuid.New(),
+		Name:            "workers",
+		TargetType:      TargetNodes,
+		MinReplicas:     1,
+		MaxReplicas:     10,
+		CooldownSeconds: cooldownSeconds,
+		ScaleUpRules: []Rule{{
+			ID:        uuid.New(),
+			RuleType:  RuleTypeScaleUp,
+			Metric:    "cpu_utilization",
+			Operator:  OpGreaterThan,
+			Threshold: 80,
+			ScaleBy:   1,
+		}},
+	}
+}
+
+func ago(d time.Duration) *time.Time {
+	t := time.Now().Add(-d)
+	return &t
+}
+
+func TestEvaluatePolicy_PolicyCooldown(t *testing.T) {
+	e := newTestEvaluator(t, "90")
+	cooldown := 60
+	policy := scaleUpPolicy(&cooldown)
+
+	state := &State{CurrentReplicas: 2, LastScaleTime: ago(10 * time.Second)}
+	decision, err := e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if decision.ShouldExecute {
+		t.Fatal("EvaluatePolicy() executes within the policy cooldown")
+	}
+	if decision.CooldownRemaining < 45*time.Second || decision.CooldownRemaining > 50*time.Second {
+		t.Errorf("CooldownRemaining = %v, want about 50s", decision.CooldownRemaining)
+	}
+
+	state.LastScaleTime = ago(2 * time.Minute)
+	decision, err = e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if !decision.ShouldExecute || decision.DesiredReplicas != 3 {
+		t.Errorf("EvaluatePolicy() = %+v, want scale up to 3 after the cooldown", decision)
+	}
+}
+
+func TestEvaluatePolicy_DefaultCooldown(t *testing.T) {
+	e := newTestEvaluator(t, "90")
+	e.SetDefaultCooldown(10 * time.Minute)
+	policy := scaleUpPolicy(nil)
+
+	state := &State{CurrentReplicas: 2, LastScaleTime: ago(2 * time.Minute)}
+	decision, err := e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if decision.ShouldExecute || decision.CooldownRemaining <= 7*time.Minute {
+		t.Errorf("EvaluatePolicy() = %+v, want the 10m default cooldown to apply", decision)
+	}
+
+	zero := 0
+	policy.CooldownSeconds = &zero
+	decision, err = e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if !decision.ShouldExecute {
+		t.Error("EvaluatePolicy() does not execute with an explicit zero cooldown")
+	}
+}
+
+func TestEvaluatePolicy_BackToBackScaleEvents(t *testing.T) {
+	e := newTestEvaluator(t, "90")
+	cooldown := 180
+	policy := scaleUpPolicy(&cooldown)
+	state := &State{CurrentReplicas: 2}
+
+	first, err := e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if !first.ShouldExecute {
+		t.Fatal("first EvaluatePolicy() does not scale up")
+	}
+
+	// The decision was made five minutes ago, but provisioning took four
+	// minutes, so the cooldown runs from the completion a minute ago.
+	state.CurrentReplicas = first.DesiredReplicas
+	state.LastScaleTime = ago(5 * time.Minute)
+	state.RecordScaleCompleted(*ago(time.Minute))
+
+	second, err := e.EvaluatePolicy(context.Background(), policy, state)
+	if err != nil {
+		t.Fatalf("EvaluatePolicy() error = %v", err)
+	}
+	if second.ShouldExecute {
+		t.Fatal("second EvaluatePolicy() scales again within the cooldown after completion")
+	}
+	if second.CooldownRemaining < 115*time.Second || second.CooldownRemaining > 120*time.Second {
+		t.Errorf("CooldownRemaining = %v, want about 2m", second.CooldownRemaining)
+	}
+}
+
+func TestState_RecordScaleCompleted(t *testing.T) {
+	decided := time.Now().Add(-time.Minute)
+	state := &State{LastScaleTime: &decided}
+
+	state.RecordScaleCompleted(decided.Add(-time.Hour))
+	if !state.LastScaleTime.Equal(decided) {
+		t.Errorf("LastScaleTime = %v, want %v: an earlier completion must not shorten the cooldown", state.LastScaleTime, decided)
+	}
+
+	completed := decided.Add(30 * time.Second)
+	state.RecordScaleCompleted(completed)
+	if !state.LastScaleTime.Equal(completed) {
+		t.Errorf("LastScaleTime = %v, want %v", state.LastScaleTime, completed)
+	}
+
+	empty := &State{}
+	empty.RecordScaleCompleted(completed)
+	if empty.LastScaleTime == nil || !empty.LastScaleTime.Equal(completed) {
+		t.Errorf("LastScaleTime = %v, want %v", empty.LastScaleTime, completed)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -22,6 +23,14 @@ type Executor interface {
 	Name() string
 }
 
+// CompletionTracker is implemented by executors that record scaling
+// operations and know when the last one for a target finished.
+type CompletionTracker interface {
+	// LastScaleCompleted returns when the last scaling operation of the
+	// target reached a terminal state, or nil if there was none.
+	LastScaleCompleted(ctx context.Context, targetType TargetType, targetID *uuid.UUID) (*time.Time, error)
+}
+
 // KEDAExecutor is a stub implementation of the Executor interface for KEDA.
 // In a real implementation, this would connect to the Kubernetes API to modify ScaledObject resources.
 type KEDAExecutor struct {
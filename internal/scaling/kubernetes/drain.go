@@ -3,6 +3,7 @@ package kubernetes
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -17,6 +18,18 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// ErrDrainTimeout is returned when a drain does not complete within its timeout.
+var ErrDrainTimeout = errors.New("drain timed out")
+
+const (
+	// evictionRetryInterval is how long to wait before retrying an eviction
+	// blocked by a PodDisruptionBudget.
+	evictionRetryInterval = 5 * time.Second
+
+	// uncordonTimeout bounds reverting the cordon after a failed drain.
+	uncordonTimeout = 30 * time.Second
+)
+
 // DrainOptions configures node drain behavior.
 type DrainOptions struct {
 	// GracePeriodSeconds is the grace period for pod termination
@@ -112,7 +125,10 @@ func (d *Drainer) UncordonNode(ctx context.Context, nodeName string) error {
 	return nil
 }
 
-// DrainNode drains all pods from a node.
+// DrainNode cordons a node and evicts its pods through the Eviction API, so
+// PodDisruptionBudgets are honored: evictions a budget blocks are retried
+// until opts.Timeout. If the drain fails or times out, the node is
+// uncordoned again and the returned error wraps ErrDrainTimeout on timeout.
 func (d *Drainer) DrainNode(ctx context.Context, nodeName string, opts DrainOptions) error {
 	d.logger.Info("draining node", "node", nodeName, "timeout", opts.Timeout)
 
@@ -121,7 +137,24 @@ func (d *Drainer) DrainNode(ctx context.Context, nodeName string, opts DrainOpti
 		return err
 	}
 
-	// Get pods on the node
+	// The timeout covers the whole drain, from listing pods to their deletion
+	drainCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	if err := d.drainPods(drainCtx, nodeName, opts); err != nil {
+		if drainCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("%w after %s: %w", ErrDrainTimeout, opts.Timeout, err)
+		}
+		d.revertCordon(ctx, nodeName)
+		return err
+	}
+
+	d.logger.Info("node drained successfully", "node", nodeName)
+	return nil
+}
+
+// drainPods evicts the pods of a cordoned node and waits for their deletion.
+func (d *Drainer) drainPods(ctx context.Context, nodeName string, opts DrainOptions) error {
 	pods, err := d.getPodsForDrain(ctx, nodeName, opts)
 	if err != nil {
 		return err
@@ -134,14 +167,11 @@ func (d *Drainer) DrainNode(ctx context.Context, nodeName string, opts DrainOpti
 
 	d.logger.Info("draining pods", "node", nodeName, "count", len(pods))
 
-	// Create a context with timeout
-	drainCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
-	defer cancel()
-
 	// Evict pods
 	for i := range pods {
-		if err := d.evictPod(drainCtx, &pods[i], opts); err != nil {
-			if !opts.Force {
+		if err := d.evictPodWithRetry(ctx, &pods[i], opts); err != nil {
+			// A disruption budget is never overridden, even with Force
+			if !opts.Force || apierrors.IsTooManyRequests(err) || ctx.Err() != nil {
 				return fmt.Errorf("failed to evict pod %s/%s: %w", pods[i].Namespace, pods[i].Name, err)
 			}
 			d.logger.Warn("failed to evict pod, continuing due to force flag",
@@ -150,17 +180,24 @@ func (d *Drainer) DrainNode(ctx context.Context, nodeName string, opts DrainOpti
 	}
 
 	// Wait for pods to be deleted
-	if err := d.waitForPodsDeleted(drainCtx, nodeName, pods, opts); err != nil {
-		if !opts.Force {
-			return fmt.Errorf("timed out waiting for pods to be deleted: %w", err)
-		}
-		d.logger.Warn("timed out waiting for pods, continuing due to force flag", "error", err)
+	if err := d.waitForPodsDeleted(ctx, nodeName, pods, opts); err != nil {
+		return fmt.Errorf("timed out waiting for pods to be deleted: %w", err)
 	}
 
-	d.logger.Info("node drained successfully", "node", nodeName)
 	return nil
 }
 
+// revertCordon uncordons a node after a failed drain so it does not stay
+// unschedulable. It runs even if ctx is already done.
+func (d *Drainer) revertCordon(ctx context.Context, nodeName string) {
+	uncordonCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), uncordonTimeout)
+	defer cancel()
+
+	if err := d.UncordonNode(uncordonCtx, nodeName); err != nil {
+		d.logger.Error("failed to uncordon node after failed drain", "node", nodeName, "error", err)
+	}
+}
+
 // getPodsForDrain returns pods that need to be drained from a node.
 func (d *Drainer) getPodsForDrain(ctx context.Context, nodeName string, opts DrainOptions) ([]corev1.Pod, error) {
 	fieldSelector := fields.SelectorFromSet(fields.Set{
@@ -215,6 +252,23 @@ func (d *Drainer) getPodsForDrain(ctx context.Context, nodeName string, opts Dra
 	return filteredPods, nil
 }
 
+// evictPodWithRetry evicts a pod, retrying while a PodDisruptionBudget
+// blocks the eviction until ctx is done.
+func (d *Drainer) evictPodWithRetry(ctx context.Context, pod *corev1.Pod, opts DrainOptions) error {
+	for {
+		err := d.evictPod(ctx, pod, opts)
+		if err == nil || !apierrors.IsTooManyRequests(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("eviction blocked by PodDisruptionBudget: %w", err)
+		case <-time.After(evictionRetryInterval):
+		}
+	}
+}
+
 // evictPod evicts a single pod using the Eviction API.
 func (d *Drainer) evictPod(ctx context.Context, pod *corev1.Pod, opts DrainOptions) error {
 	d.logger.Debug("evicting pod", "pod", pod.Name, "namespace", pod.Namespace)
@@ -224,16 +278,18 @@ func (d *Drainer) evictPod(ctx context.Context, pod *corev1.Pod, opts DrainOptio
 			Name:      pod.Name,
 			Namespace: pod.Namespace,
 		},
-		DeleteOptions: &metav1.DeleteOptions{
-			GracePeriodSeconds: &opts.GracePeriodSeconds,
-		},
+		DeleteOptions: &metav1.DeleteOptions{},
+	}
+	// A negative grace period leaves the pod's own termination grace period
+	if opts.GracePeriodSeconds >= 0 {
+		eviction.DeleteOptions.GracePeriodSeconds = &opts.GracePeriodSeconds
 	}
 
 	err := d.client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
 	if err != nil {
 		if apierrors.IsTooManyRequests(err) {
-			// PDB prevents eviction, wait and retry
-			d.logger.Debug("PDB preventing eviction, will retry", "pod", pod.Name)
+			// PDB prevents eviction, the caller retries
+			d.logger.Debug("PDB preventing eviction, will retry", "pod", pod.Name, "namespace", pod.Namespace)
 			return err
 		}
 		if apierrors.IsNotFound(err) {
@@ -37,6 +37,7 @@ func NewManager(repo *Repository, executor Executor, cfg config.ScalingConfig, l
 	}
 
 	evaluator := NewEvaluator(cfg.PrometheusURL, logger)
+	evaluator.SetDefaultCooldown(time.Duration(cfg.DefaultCooldownSeconds) * time.Second)
 
 	return &Manager{
 		repo:      repo,
@@ -204,6 +205,8 @@ func (m *Manager) evaluatePolicy(ctx context.Context, policy *Policy) error {
 		}
 	}
 
+	m.syncLastScaleCompletion(ctx, policy, state)
+
 	// Sync current replicas from executor
 	currentReplicas, err := m.executor.GetCurrentReplicas(ctx, policy.TargetType, policy.TargetID)
 	if err != nil {
@@ -242,6 +245,34 @@ func (m *Manager) evaluatePolicy(ctx context.Context, policy *Policy) error {
 	return nil
 }
 
+// syncLastScaleCompletion starts the cooldown from the last finished scaling
+// operation of the target when the executor tracks operations, so slow
+// scaling does not eat into the cooldown.
+func (m *Manager) syncLastScaleCompletion(ctx context.Context, policy *Policy, state *State) {
+	tracker, ok := m.executor.(CompletionTracker)
+	if !ok {
+		return
+	}
+
+	completedAt, err := tracker.LastScaleCompleted(ctx, policy.TargetType, policy.TargetID)
+	if err != nil {
+		m.logger.Warn("failed to get last scaling completion",
+			"policy_id", policy.ID,
+			"error", err,
+		)
+		return
+	}
+	if completedAt != nil {
+		state.RecordScaleCompleted(*completedAt)
+	}
+}
+
+// CooldownDuration returns the effective cooldown of a policy, falling back
+// to the configured default when the policy does not set one.
+func (m *Manager) CooldownDuration(policy *Policy) time.Duration {
+	return policy.CooldownDuration(m.evaluator.DefaultCooldown())
+}
+
 // executeDecision executes a scaling decision.
 func (m *Manager) executeDecision(ctx context.Context, decision *Decision, state *State) error {
 	policy := decision.Policy
@@ -336,6 +367,8 @@ func (m *Manager) EvaluatePolicyNow(ctx context.Context, policyID string, dryRun
 		}
 	}
 
+	m.syncLastScaleCompletion(ctx, policy, state)
+
 	// Sync current replicas from executor
 	currentReplicas, err := m.executor.GetCurrentReplicas(ctx, policy.TargetType, policy.TargetID)
 	if err == nil {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +23,7 @@ type NodeExecutor struct {
 	k8sClient *kubernetes.Client
 	drainer   *kubernetes.Drainer
 	monitor   *kubernetes.Monitor
+	notifier  *OperationNotifier
 	logger    *slog.Logger
 
 	// Configuration
@@ -29,9 +31,15 @@ type NodeExecutor struct {
 	drainTimeout     time.Duration
 	drainGracePeriod time.Duration
 
+	// Spot fallback: pools with spotFailureThreshold spot failures within
+	// spotFailureWindow provision on-demand instances instead
+	spotFailureThreshold int
+	spotFailureWindow    time.Duration
+
 	// State tracking for concurrent operations
-	pendingOps map[uuid.UUID]*PendingOperation
-	mu         sync.Mutex
+	pendingOps   map[uuid.UUID]*PendingOperation
+	spotFailures map[uuid.UUID][]time.Time
+	mu           sync.Mutex
 }
 
 // PendingOperation tracks an in-flight scaling operation.
@@ -48,6 +56,11 @@ type NodeExecutorConfig struct {
 	NodeReadyTimeout time.Duration
 	DrainTimeout     time.Duration
 	DrainGracePeriod time.Duration
+
+	// SpotFailureThreshold is the number of spot failures or reclamations
+	// within SpotFailureWindow after which a pool falls back to on-demand.
+	SpotFailureThreshold int
+	SpotFailureWindow    time.Duration
 }
 
 // DefaultNodeExecutorConfig returns default configuration.
@@ -56,6 +69,9 @@ func DefaultNodeExecutorConfig() NodeExecutorConfig {
 		NodeReadyTimeout: 10 * time.Minute,
 		DrainTimeout:     5 * time.Minute,
 		DrainGracePeriod: 30 * time.Second,
+
+		SpotFailureThreshold: 3,
+		SpotFailureWindow:    time.Hour,
 	}
 }
 
@@ -79,24 +95,46 @@ func NewNodeExecutor(
 	}
 
 	return &NodeExecutor{
-		poolRepo:         poolRepo,
-		providers:        providers,
-		k8sClient:        k8sClient,
-		drainer:          drainer,
-		monitor:          monitor,
-		logger:           logger.With("component", "node-executor"),
-		nodeReadyTimeout: config.NodeReadyTimeout,
-		drainTimeout:     config.DrainTimeout,
-		drainGracePeriod: config.DrainGracePeriod,
-		pendingOps:       make(map[uuid.UUID]*PendingOperation),
+		poolRepo:             poolRepo,
+		providers:            providers,
+		k8sClient:            k8sClient,
+		drainer:              drainer,
+		monitor:              monitor,
+		logger:               logger.With("component", "node-executor"),
+		nodeReadyTimeout:     config.NodeReadyTimeout,
+		drainTimeout:         config.DrainTimeout,
+		drainGracePeriod:     config.DrainGracePeriod,
+		spotFailureThreshold: config.SpotFailureThreshold,
+		spotFailureWindow:    config.SpotFailureWindow,
+		pendingOps:           make(map[uuid.UUID]*PendingOperation),
+		spotFailures:         make(map[uuid.UUID][]time.Time),
 	}
 }
 
+// SetNotifier sets the notifier of completed scaling operations.
+func (e *NodeExecutor) SetNotifier(notifier *OperationNotifier) {
+	e.notifier = notifier
+}
+
 // Name returns the executor name.
 func (e *NodeExecutor) Name() string {
 	return "node"
 }
 
+// LastScaleCompleted returns when the last scaling operation of a node pool
+// finished, so the pool's cooldown runs from the end of the operation.
+func (e *NodeExecutor) LastScaleCompleted(ctx context.Context, targetType TargetType, targetID *uuid.UUID) (*time.Time, error) {
+	if targetType != TargetNodes || targetID == nil {
+		return nil, nil
+	}
+
+	completedAt, err := e.poolRepo.GetLastOperationCompletion(ctx, *targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last operation completion: %w", err)
+	}
+	return completedAt, nil
+}
+
 // GetCurrentReplicas returns the current node count for a node pool.
 // For node scaling, targetID should be the node pool ID.
 func (e *NodeExecutor) GetCurrentReplicas(ctx context.Context, targetType TargetType, targetID *uuid.UUID) (int, error) {
@@ -183,26 +221,32 @@ func (e *NodeExecutor) Scale(ctx context.Context, targetType TargetType, targetI
 		"dry_run", dryRun,
 	)
 
+	// Create operation record
+	op := &nodepool.ScalingOperation{
+		PoolID:              pool.ID,
+		Action:              action,
+		PreviousCount:       currentNodes,
+		TargetCount:         targetNodes,
+		Status:              nodepool.OperationStatusInProgress,
+		Reason:              fmt.Sprintf("Scaling from %d to %d nodes", currentNodes, targetNodes),
+		TriggeredBy:         "policy",
+		EstimatedCostChange: e.estimateCostChange(ctx, pool, targetNodes-currentNodes),
+		DryRun:              dryRun,
+	}
+
 	if dryRun {
 		e.logger.Info("[DRY-RUN] would scale node pool",
 			"pool", pool.Name,
 			"from", currentNodes,
 			"to", targetNodes,
 		)
+		op.ID = uuid.New()
+		op.Status = nodepool.OperationStatusCompleted
+		op.StartedAt = time.Now()
+		e.notifier.Notify(ctx, pool, op)
 		return nil
 	}
 
-	// Create operation record
-	op := &nodepool.ScalingOperation{
-		PoolID:        pool.ID,
-		Action:        action,
-		PreviousCount: currentNodes,
-		TargetCount:   targetNodes,
-		Status:        nodepool.OperationStatusInProgress,
-		Reason:        fmt.Sprintf("Scaling from %d to %d nodes", currentNodes, targetNodes),
-		TriggeredBy:   "policy",
-	}
-
 	op, err = e.poolRepo.CreateOperation(ctx, op)
 	if err != nil {
 		return fmt.Errorf("failed to create operation record: %w", err)
@@ -247,10 +291,32 @@ func (e *NodeExecutor) Scale(ctx context.Context, targetType TargetType, targetI
 	if updateErr != nil {
 		e.logger.Error("failed to update operation status", "error", updateErr)
 	}
+	e.notifyCompleted(ctx, pool, op, finalCount)
 
 	return nil
 }
 
+// notifyCompleted notifies the completion of an operation with finalCount
+// nodes.
+func (e *NodeExecutor) notifyCompleted(ctx context.Context, pool *nodepool.NodePool, op *nodepool.ScalingOperation, finalCount int) {
+	completedAt := time.Now()
+	op.Status = nodepool.OperationStatusCompleted
+	op.ActualCount = &finalCount
+	op.CompletedAt = &completedAt
+	e.notifier.Notify(ctx, pool, op)
+}
+
+// estimateCostChange estimates the hourly cost change of adding delta nodes
+// of the pool's instance type, nil if the type has no pricing.
+func (e *NodeExecutor) estimateCostChange(ctx context.Context, pool *nodepool.NodePool, delta int) *float64 {
+	pricing, err := e.poolRepo.GetPricing(ctx, pool.Provider, pool.InstanceType, pool.Region)
+	if err != nil {
+		return nil
+	}
+	change := float64(delta) * pricing.HourlyCost
+	return &change
+}
+
 // scaleUp adds nodes to the pool.
 func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, currentCount, targetCount int, op *nodepool.ScalingOperation) error {
 	provider, ok := e.providers.Get(pool.Provider.String())
@@ -261,23 +327,48 @@ func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, cur
 	nodesToAdd := targetCount - currentCount
 	e.logger.Info("adding nodes to pool", "pool", pool.Name, "count", nodesToAdd)
 
+	instanceType, pricing, err := e.selectInstanceType(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("failed to select instance type: %w", err)
+	}
+
+	// Without a known spot node count the spot share cannot be honored
+	spotPricing := pricing
+	spotNodes, err := e.countSpotNodes(ctx, pool)
+	if err != nil {
+		e.logger.Warn("failed to count spot nodes, provisioning on-demand", "pool", pool.Name, "error", err)
+		spotPricing = nil
+	}
+
 	var createdNodeIDs []uuid.UUID
 
 	for i := 0; i < nodesToAdd; i++ {
 		nodeName := fmt.Sprintf("%s-node-%d", pool.Name, time.Now().UnixNano())
-
-		// Create server via cloud provider
-		server, err := provider.CreateServer(ctx, cloudprovider.CreateServerOptions{
+		opts := cloudprovider.CreateServerOptions{
 			Name:         nodeName,
 			Region:       pool.Region,
-			InstanceType: pool.InstanceType,
+			InstanceType: instanceType,
 			Image:        pool.Image,
 			SSHKeyIDs:    []string{pool.SSHKeyID},
 			UserData:     pool.UserDataTemplate,
 			Labels:       pool.Labels,
 			NetworkID:    pool.NetworkID,
 			FirewallID:   pool.FirewallID,
-		})
+			UseSpot:      e.useSpot(pool, spotPricing, spotNodes, currentCount+len(createdNodeIDs)),
+		}
+
+		// Create server via cloud provider, retrying on-demand if spot
+		// capacity is unavailable
+		server, err := provider.CreateServer(ctx, opts)
+		if err != nil && opts.UseSpot {
+			e.logger.Warn("failed to create spot server, falling back to on-demand",
+				"pool", pool.Name,
+				"error", err,
+			)
+			e.recordSpotFailure(pool.ID)
+			opts.UseSpot = false
+			server, err = provider.CreateServer(ctx, opts)
+		}
 		if err != nil {
 			e.logger.Error("failed to create server",
 				"pool", pool.Name,
@@ -294,7 +385,9 @@ func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, cur
 			Status:       nodepool.NodeStatusCreating,
 			PublicIP:     server.PublicIP,
 			PrivateIP:    server.PrivateIP,
-			InstanceType: pool.InstanceType,
+			InstanceType: instanceType,
+			HourlyCost:   hourlyCost(pricing, opts.UseSpot),
+			IsSpot:       opts.UseSpot,
 		}
 
 		node, err = e.poolRepo.CreateNode(ctx, node)
@@ -307,10 +400,14 @@ func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, cur
 		}
 
 		createdNodeIDs = append(createdNodeIDs, node.ID)
+		if node.IsSpot {
+			spotNodes++
+		}
 		e.logger.Info("created node",
 			"node_id", node.ID,
 			"server_id", server.ID,
 			"pool", pool.Name,
+			"spot", node.IsSpot,
 		)
 
 		// Update node status to joining
@@ -321,7 +418,7 @@ func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, cur
 	}
 
 	// Update operation with affected nodes
-	err := e.poolRepo.UpdateOperationNodesAffected(ctx, op.ID, createdNodeIDs)
+	err = e.poolRepo.UpdateOperationNodesAffected(ctx, op.ID, createdNodeIDs)
 	if err != nil {
 		e.logger.Warn("failed to update operation nodes", "error", err)
 	}
@@ -343,6 +440,38 @@ func (e *NodeExecutor) scaleUp(ctx context.Context, pool *nodepool.NodePool, cur
 	return nil
 }
 
+// selectInstanceType returns the instance type to provision for a pool and
+// its pricing: the cheapest type meeting the pool's requirements, or its
+// fixed instance type when it has none. Pricing is nil when the fixed type
+// has no cached pricing.
+func (e *NodeExecutor) selectInstanceType(ctx context.Context, pool *nodepool.NodePool) (string, *nodepool.InstanceTypePricing, error) {
+	if pool.Requirements == nil {
+		pricing, err := e.poolRepo.GetPricing(ctx, pool.Provider, pool.InstanceType, pool.Region)
+		if err != nil {
+			e.logger.Debug("no pricing for instance type", "pool", pool.Name, "error", err)
+			return pool.InstanceType, nil, nil
+		}
+		return pool.InstanceType, pricing, nil
+	}
+
+	pricing, err := e.poolRepo.ListPricingForProvider(ctx, pool.Provider, pool.Region)
+	if err != nil {
+		return "", nil, err
+	}
+
+	selected, err := nodepool.SelectInstanceType(*pool.Requirements, pool.Region, pricing, pool.SpotEnabled)
+	if err != nil {
+		return "", nil, err
+	}
+
+	e.logger.Info("selected instance type",
+		"pool", pool.Name,
+		"instance_type", selected.InstanceType,
+		"hourly_cost", selected.HourlyCost,
+	)
+	return selected.InstanceType, selected, nil
+}
+
 // scaleDown removes nodes from the pool.
 func (e *NodeExecutor) scaleDown(ctx context.Context, pool *nodepool.NodePool, currentCount, targetCount int, op *nodepool.ScalingOperation) error {
 	provider, ok := e.providers.Get(pool.Provider.String())
@@ -379,6 +508,7 @@ func (e *NodeExecutor) scaleDown(ctx context.Context, pool *nodepool.NodePool, c
 	}
 
 	removedNodeIDs := make([]uuid.UUID, 0, len(nodeNames))
+	var drainFailures []string
 
 	for _, nodeName := range nodeNames {
 		// Get node from database
@@ -402,11 +532,18 @@ func (e *NodeExecutor) scaleDown(ctx context.Context, pool *nodepool.NodePool, c
 
 			drainErr := e.drainer.DrainNode(ctx, nodeName, drainOpts)
 			if drainErr != nil {
-				e.logger.Warn("failed to drain node",
+				// Deleting the node now could violate a PodDisruptionBudget,
+				// so keep it; the drainer has already uncordoned it
+				e.logger.Warn("failed to drain node, aborting its removal",
 					"node", nodeName,
 					"error", drainErr,
 				)
-				// Continue with deletion anyway after marking as failed
+				drainFailures = append(drainFailures, fmt.Sprintf("drain of node %s aborted: %v", nodeName, drainErr))
+
+				if statusErr := e.poolRepo.UpdateNodeStatus(ctx, node.ID, nodepool.NodeStatusReady, ""); statusErr != nil {
+					e.logger.Warn("failed to update node status", "error", statusErr)
+				}
+				continue
 			}
 		}
 
@@ -470,6 +607,9 @@ func (e *NodeExecutor) scaleDown(ctx context.Context, pool *nodepool.NodePool, c
 	}
 
 	if len(removedNodeIDs) < nodesToRemove {
+		if len(drainFailures) > 0 {
+			return fmt.Errorf("only removed %d of %d nodes: %s", len(removedNodeIDs), nodesToRemove, strings.Join(drainFailures, "; "))
+		}
 		return fmt.Errorf("only removed %d of %d nodes", len(removedNodeIDs), nodesToRemove)
 	}
 
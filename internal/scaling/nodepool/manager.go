@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ type Manager struct {
 	repo   *Repository
 	logger *slog.Logger
 
+	// defaultCooldown applies to pools whose scaling policy sets no cooldown
+	defaultCooldown time.Duration
+
 	// Pool-level locks to prevent concurrent operations on the same pool
 	poolLocks map[uuid.UUID]*sync.Mutex
 	locksMu   sync.Mutex
@@ -34,6 +38,12 @@ func NewManager(repo *Repository, logger *slog.Logger) *Manager {
 	}
 }
 
+// SetDefaultCooldown sets the cooldown reported for pools whose scaling
+// policy does not set one. It should match the scaling engine's default.
+func (m *Manager) SetDefaultCooldown(d time.Duration) {
+	m.defaultCooldown = d
+}
+
 // getPoolLock returns a lock for the given pool ID.
 func (m *Manager) getPoolLock(poolID uuid.UUID) *sync.Mutex {
 	m.locksMu.Lock()
@@ -276,21 +286,51 @@ func (m *Manager) GetPoolStatus(ctx context.Context, poolID uuid.UUID) (*PoolSta
 		}
 	}
 
+	cooldown := m.defaultCooldown
+	cooldownSeconds, err := m.repo.GetPoolCooldownSeconds(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if cooldownSeconds != nil {
+		cooldown = time.Duration(*cooldownSeconds) * time.Second
+	}
+
+	lastCompleted, err := m.repo.GetLastOperationCompletion(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := cooldownRemaining(cooldown, lastCompleted, time.Now())
+
 	return &PoolStatus{
-		ID:           pool.ID,
-		Name:         pool.Name,
-		Provider:     pool.Provider,
-		Region:       pool.Region,
-		InstanceType: pool.InstanceType,
-		MinNodes:     pool.MinNodes,
-		MaxNodes:     pool.MaxNodes,
-		CurrentNodes: len(nodes),
-		ReadyNodes:   readyCount,
-		Enabled:      pool.Enabled,
-		HourlyCost:   totalCost,
+		ID:                       pool.ID,
+		Name:                     pool.Name,
+		Provider:                 pool.Provider,
+		Region:                   pool.Region,
+		InstanceType:             pool.InstanceType,
+		MinNodes:                 pool.MinNodes,
+		MaxNodes:                 pool.MaxNodes,
+		CurrentNodes:             len(nodes),
+		ReadyNodes:               readyCount,
+		Enabled:                  pool.Enabled,
+		HourlyCost:               totalCost,
+		CooldownSeconds:          int(cooldown.Seconds()),
+		CooldownRemainingSeconds: int(math.Ceil(remaining.Seconds())),
 	}, nil
 }
 
+// cooldownRemaining returns how much of cooldown is left at now after a
+// scaling operation completed at lastCompleted.
+func cooldownRemaining(cooldown time.Duration, lastCompleted *time.Time, now time.Time) time.Duration {
+	if lastCompleted == nil {
+		return 0
+	}
+	remaining := cooldown - now.Sub(*lastCompleted)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 // GetAllPoolStatuses returns status for all node pools.
 func (m *Manager) GetAllPoolStatuses(ctx context.Context) ([]PoolStatus, error) {
 	pools, err := m.repo.ListPools(ctx, false)
@@ -330,6 +370,107 @@ func (m *Manager) EstimatePoolCost(ctx context.Context, provider Provider, insta
 	return pricing.HourlyCost * float64(nodeCount), nil
 }
 
+// GetCostReport returns the current cost of every node pool. When from and
+// to are set, the report also sums the estimated cost change of the scaling
+// operations started within that range.
+func (m *Manager) GetCostReport(ctx context.Context, from, to *time.Time) (*CostReport, error) {
+	pools, err := m.repo.ListPools(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CostReport{
+		Pools:       make([]PoolCost, 0, len(pools)),
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+	}
+	if from != nil && to != nil {
+		report.TotalCostChange = new(float64)
+	}
+
+	// Pricing is listed once per provider and region
+	pricing := make(map[string]map[string]*InstanceTypePricing)
+	for i := range pools {
+		pool := &pools[i]
+
+		key := pool.Provider.String() + "/" + pool.Region
+		if _, ok := pricing[key]; !ok {
+			list, listErr := m.repo.ListPricingForProvider(ctx, pool.Provider, pool.Region)
+			if listErr != nil {
+				return nil, listErr
+			}
+			byType := make(map[string]*InstanceTypePricing, len(list))
+			for j := range list {
+				byType[list[j].InstanceType] = &list[j]
+			}
+			pricing[key] = byType
+		}
+
+		active, countErr := m.repo.CountActiveNodesForPool(ctx, pool.ID)
+		if countErr != nil {
+			return nil, countErr
+		}
+		spot, countErr := m.repo.CountActiveSpotNodesForPool(ctx, pool.ID)
+		if countErr != nil {
+			return nil, countErr
+		}
+
+		cost := poolCost(pool, active, spot, pricing[key][pool.InstanceType])
+		if report.TotalCostChange != nil {
+			change, sumErr := m.repo.SumOperationCostChange(ctx, pool.ID, *from, *to)
+			if sumErr != nil {
+				return nil, sumErr
+			}
+			cost.CostChange = &change
+			*report.TotalCostChange += change
+		}
+
+		report.Pools = append(report.Pools, cost)
+		report.TotalHourlyCost += cost.HourlyCost
+		report.TotalMonthlyCost += cost.MonthlyCost
+		report.SpotHourlyCost += cost.SpotHourlyCost
+		report.OnDemandHourlyCost += cost.OnDemandHourly
+		report.ScaleToZeroMonthlySavings += cost.ScaleToZeroMonthlySavings
+	}
+
+	return report, nil
+}
+
+// poolCost computes the cost of a pool's active nodes. Spot nodes without a
+// spot price are charged at the on-demand price.
+func poolCost(pool *NodePool, activeNodes, spotNodes int, pricing *InstanceTypePricing) PoolCost {
+	cost := PoolCost{
+		PoolID:        pool.ID,
+		Name:          pool.Name,
+		Provider:      pool.Provider,
+		Region:        pool.Region,
+		InstanceType:  pool.InstanceType,
+		ActiveNodes:   activeNodes,
+		SpotNodes:     spotNodes,
+		OnDemandNodes: activeNodes - spotNodes,
+	}
+	if pricing == nil {
+		return cost
+	}
+
+	spotPrice := pricing.HourlyCost
+	if pricing.SpotHourlyCost != nil {
+		spotPrice = *pricing.SpotHourlyCost
+	}
+
+	cost.PricingAvailable = true
+	cost.SpotHourlyCost = float64(cost.SpotNodes) * spotPrice
+	cost.OnDemandHourly = float64(cost.OnDemandNodes) * pricing.HourlyCost
+	cost.HourlyCost = cost.SpotHourlyCost + cost.OnDemandHourly
+	cost.MonthlyCost = cost.HourlyCost * HoursPerMonth
+	if pool.MinNodes == 0 {
+		cost.ScaleToZeroMonthlySavings = cost.MonthlyCost
+	}
+
+	return cost
+}
+
 // ReconcilePoolNodeCount updates the pool's current_nodes to match actual count.
 func (m *Manager) ReconcilePoolNodeCount(ctx context.Context, poolID uuid.UUID) error {
 	lock := m.getPoolLock(poolID)
@@ -0,0 +1,63 @@
+package nodepool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolCost(t *testing.T) {
+	spotPrice := 0.01
+	pricing := &InstanceTypePricing{HourlyCost: 0.04, SupportsSpot: true, SpotHourlyCost: &spotPrice}
+	pool := &NodePool{Name: "workers", InstanceType: "cx22", MinNodes: 0}
+
+	cost := poolCost(pool, 5, 2, pricing)
+	if cost.OnDemandNodes != 3 || !cost.PricingAvailable {
+		t.Fatalf("poolCost() = %+v, want 3 on-demand nodes with pricing", cost)
+	}
+	if !approx(cost.SpotHourlyCost, 0.02) || !approx(cost.OnDemandHourly, 0.12) || !approx(cost.HourlyCost, 0.14) {
+		t.Errorf("hourly costs = spot %v, on-demand %v, total %v, want 0.02, 0.12, 0.14",
+			cost.SpotHourlyCost, cost.OnDemandHourly, cost.HourlyCost)
+	}
+	if !approx(cost.MonthlyCost, 0.14*HoursPerMonth) || cost.ScaleToZeroMonthlySavings != cost.MonthlyCost {
+		t.Errorf("monthly = %v, savings = %v, want %v for both", cost.MonthlyCost, cost.ScaleToZeroMonthlySavings, 0.14*HoursPerMonth)
+	}
+
+	pool.MinNodes = 1
+	if cost := poolCost(pool, 5, 2, pricing); cost.ScaleToZeroMonthlySavings != 0 {
+		t.Errorf("savings = %v for a pool that cannot scale to zero, want 0", cost.ScaleToZeroMonthlySavings)
+	}
+
+	if cost := poolCost(pool, 2, 0, nil); cost.PricingAvailable || cost.HourlyCost != 0 || cost.ActiveNodes != 2 {
+		t.Errorf("poolCost(no pricing) = %+v, want node counts only", cost)
+	}
+}
+
+func approx(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
+
+func TestCooldownRemaining(t *testing.T) {
+	now := time.Now()
+	completed := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		cooldown      time.Duration
+		lastCompleted *time.Time
+		want          time.Duration
+	}{
+		{"never scaled", 5 * time.Minute, nil, 0},
+		{"within cooldown", 5 * time.Minute, &completed, 4 * time.Minute},
+		{"cooldown elapsed", 30 * time.Second, &completed, 0},
+		{"no cooldown", 0, &completed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cooldownRemaining(tt.cooldown, tt.lastCompleted, now); got != tt.want {
+				t.Errorf("cooldownRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,17 +12,18 @@ import (
 type Provider string
 
 const (
-	ProviderHetzner  Provider = "hetzner"
-	ProviderScaleway Provider = "scaleway"
-	ProviderOVH      Provider = "ovh"
-	ProviderExoscale Provider = "exoscale"
-	ProviderContabo  Provider = "contabo"
+	ProviderHetzner      Provider = "hetzner"
+	ProviderScaleway     Provider = "scaleway"
+	ProviderOVH          Provider = "ovh"
+	ProviderExoscale     Provider = "exoscale"
+	ProviderContabo      Provider = "contabo"
+	ProviderDigitalOcean Provider = "digitalocean"
 )
 
 // IsValid checks if the provider is valid.
 func (p Provider) IsValid() bool {
 	switch p {
-	case ProviderHetzner, ProviderScaleway, ProviderOVH, ProviderExoscale, ProviderContabo:
+	case ProviderHetzner, ProviderScaleway, ProviderOVH, ProviderExoscale, ProviderContabo, ProviderDigitalOcean:
 		return true
 	}
 	return false
@@ -101,8 +102,20 @@ type OperationAction string
 const (
 	OperationActionScaleUp   OperationAction = "scale_up"
 	OperationActionScaleDown OperationAction = "scale_down"
+	// OperationActionSpotReplace replaces a spot node reclaimed by the provider.
+	OperationActionSpotReplace OperationAction = "spot_replace"
 )
 
+// DefaultSpotMaxPercent is the share of a spot-enabled pool allowed to run on
+// spot instances when none is configured.
+const DefaultSpotMaxPercent = 50
+
+// InstanceRequirements are the minimum resources of a pool's instance type.
+type InstanceRequirements struct {
+	MinCPUCores int `json:"min_cpu_cores,omitempty"`
+	MinMemoryMB int `json:"min_memory_mb,omitempty"`
+}
+
 // Taint represents a Kubernetes taint to apply to nodes.
 type Taint struct {
 	Key    string `json:"key"`
@@ -128,8 +141,19 @@ type NodePool struct {
 	NetworkID        string            `json:"network_id,omitempty"`
 	FirewallID       string            `json:"firewall_id,omitempty"`
 	Enabled          bool              `json:"enabled"`
-	CreatedAt        time.Time         `json:"created_at"`
-	UpdatedAt        time.Time         `json:"updated_at"`
+	SpotEnabled      bool              `json:"spot_enabled"`
+	SpotMaxPercent   int               `json:"spot_max_percent"`
+
+	// Requirements, when set, makes scale-up pick the cheapest instance type
+	// meeting them instead of InstanceType.
+	Requirements *InstanceRequirements `json:"requirements,omitempty"`
+
+	// NotifyScaling sends a notification for every completed scaling
+	// operation of the pool when a scaling notifier is configured.
+	NotifyScaling bool `json:"notify_scaling"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Validate validates the node pool configuration.
@@ -155,9 +179,29 @@ func (p *NodePool) Validate() error {
 	if p.MinNodes > p.MaxNodes {
 		return fmt.Errorf("min_nodes (%d) cannot be greater than max_nodes (%d)", p.MinNodes, p.MaxNodes)
 	}
+	if p.SpotMaxPercent < 0 || p.SpotMaxPercent > 99 {
+		return fmt.Errorf("spot_max_percent must be between 0 and 99")
+	}
+	if p.Requirements != nil {
+		if p.Requirements.MinCPUCores < 0 || p.Requirements.MinMemoryMB < 0 {
+			return fmt.Errorf("requirements cannot be negative")
+		}
+		if p.Requirements.MinCPUCores == 0 && p.Requirements.MinMemoryMB == 0 {
+			return fmt.Errorf("requirements must set min_cpu_cores or min_memory_mb")
+		}
+	}
 	return nil
 }
 
+// CanAddSpotNode returns true if one more spot node keeps the pool within
+// SpotMaxPercent, given its current spot and total active node counts.
+func (p *NodePool) CanAddSpotNode(spotNodes, totalNodes int) bool {
+	if !p.SpotEnabled {
+		return false
+	}
+	return (spotNodes+1)*100 <= p.SpotMaxPercent*(totalNodes+1)
+}
+
 // CanScaleUp returns true if the pool can scale up.
 func (p *NodePool) CanScaleUp() bool {
 	return p.Enabled && p.CurrentNodes < p.MaxNodes
@@ -271,4 +315,53 @@ type PoolStatus struct {
 	ReadyNodes   int       `json:"ready_nodes"`
 	Enabled      bool      `json:"enabled"`
 	HourlyCost   float64   `json:"hourly_cost"`
+
+	// CooldownSeconds is the effective cooldown of the pool's scaling policy
+	// and CooldownRemainingSeconds how much of it is left since the last
+	// scaling operation finished. No scaling happens while it is above zero.
+	CooldownSeconds          int `json:"cooldown_seconds"`
+	CooldownRemainingSeconds int `json:"cooldown_remaining_seconds"`
+}
+
+// HoursPerMonth is the average number of hours in a month, used to project
+// hourly costs to monthly costs.
+const HoursPerMonth = 730
+
+// PoolCost represents the current cost of a node pool.
+type PoolCost struct {
+	PoolID           uuid.UUID `json:"pool_id"`
+	Name             string    `json:"name"`
+	Provider         Provider  `json:"provider"`
+	Region           string    `json:"region"`
+	InstanceType     string    `json:"instance_type"`
+	ActiveNodes      int       `json:"active_nodes"`
+	SpotNodes        int       `json:"spot_nodes"`
+	OnDemandNodes    int       `json:"on_demand_nodes"`
+	PricingAvailable bool      `json:"pricing_available"`
+	SpotHourlyCost   float64   `json:"spot_hourly_cost"`
+	OnDemandHourly   float64   `json:"on_demand_hourly_cost"`
+	HourlyCost       float64   `json:"hourly_cost"`
+	MonthlyCost      float64   `json:"monthly_cost"`
+
+	// ScaleToZeroMonthlySavings is the monthly cost saved if the pool scaled
+	// to zero nodes; only pools with min_nodes 0 can.
+	ScaleToZeroMonthlySavings float64 `json:"scale_to_zero_monthly_savings"`
+
+	// CostChange is the sum of the estimated cost changes of the pool's
+	// scaling operations within the report's time range.
+	CostChange *float64 `json:"cost_change,omitempty"`
+}
+
+// CostReport represents the current and historical cost of all node pools.
+type CostReport struct {
+	Pools                     []PoolCost `json:"pools"`
+	TotalHourlyCost           float64    `json:"total_hourly_cost"`
+	TotalMonthlyCost          float64    `json:"total_monthly_cost"`
+	SpotHourlyCost            float64    `json:"spot_hourly_cost"`
+	OnDemandHourlyCost        float64    `json:"on_demand_hourly_cost"`
+	ScaleToZeroMonthlySavings float64    `json:"scale_to_zero_monthly_savings"`
+	From                      *time.Time `json:"from,omitempty"`
+	To                        *time.Time `json:"to,omitempty"`
+	TotalCostChange           *float64   `json:"total_cost_change,omitempty"`
+	GeneratedAt               time.Time  `json:"generated_at"`
 }
@@ -0,0 +1,49 @@
+package nodepool
+
+import "testing"
+
+func TestNodePool_CanAddSpotNode(t *testing.T) {
+	pool := &NodePool{SpotEnabled: true, SpotMaxPercent: 50}
+
+	tests := []struct {
+		name       string
+		spotNodes  int
+		totalNodes int
+		want       bool
+	}{
+		{"empty pool", 0, 0, false},
+		{"one on-demand node", 0, 1, true},
+		{"half spot", 1, 2, false},
+		{"below share", 1, 3, true},
+		{"at share", 2, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pool.CanAddSpotNode(tt.spotNodes, tt.totalNodes); got != tt.want {
+				t.Errorf("CanAddSpotNode(%d, %d) = %v, want %v", tt.spotNodes, tt.totalNodes, got, tt.want)
+			}
+		})
+	}
+
+	pool.SpotEnabled = false
+	if pool.CanAddSpotNode(0, 10) {
+		t.Error("CanAddSpotNode() = true for a pool without spot")
+	}
+}
+
+func TestNodePool_ValidateSpotMaxPercent(t *testing.T) {
+	pool := &NodePool{Name: "workers", Provider: ProviderHetzner, Region: "fsn1", InstanceType: "cx22", MaxNodes: 3}
+
+	for _, percent := range []int{0, 50, 99} {
+		pool.SpotMaxPercent = percent
+		if err := pool.Validate(); err != nil {
+			t.Errorf("Validate() with spot_max_percent %d: %v", percent, err)
+		}
+	}
+	for _, percent := range []int{-1, 100} {
+		pool.SpotMaxPercent = percent
+		if err := pool.Validate(); err == nil {
+			t.Errorf("Validate() accepted spot_max_percent %d", percent)
+		}
+	}
+}
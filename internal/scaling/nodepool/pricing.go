@@ -0,0 +1,302 @@
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/scaling/cloudprovider"
+)
+
+var (
+	// pricingStalenessGauge tracks the age of the oldest stored price per provider.
+	pricingStalenessGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "philotes",
+			Subsystem: "scaling",
+			Name:      "pricing_staleness_seconds",
+			Help:      "Age in seconds of the least recently updated instance price for a provider",
+		},
+		[]string{"provider"},
+	)
+
+	// pricingRefreshCounter tracks pricing refreshes by result.
+	pricingRefreshCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "philotes",
+			Subsystem: "scaling",
+			Name:      "pricing_refreshes_total",
+			Help:      "Total number of instance pricing refreshes by result",
+		},
+		[]string{"provider", "result"},
+	)
+)
+
+// PricingSource supplies current instance pricing for a provider. Providers
+// with a pricing API are adapted with NewProviderPricingSource; providers
+// without one can supply a maintained list with NewStaticPricingSource.
+type PricingSource interface {
+	// Provider returns the provider the pricing belongs to.
+	Provider() Provider
+
+	// FetchPricing returns current pricing. On partial failure it returns
+	// the pricing it could fetch along with an error.
+	FetchPricing(ctx context.Context) ([]InstanceTypePricing, error)
+}
+
+// PricingStore persists instance pricing.
+type PricingStore interface {
+	UpsertPricing(ctx context.Context, pricing *InstanceTypePricing) error
+	ListPricingForProvider(ctx context.Context, provider Provider, region string) ([]InstanceTypePricing, error)
+}
+
+var _ PricingStore = (*Repository)(nil)
+
+// staticPricingSource serves a fixed pricing list.
+type staticPricingSource struct {
+	provider Provider
+	pricing  []InstanceTypePricing
+}
+
+// NewStaticPricingSource creates a PricingSource for a provider without a
+// pricing API, serving a maintained list of prices.
+func NewStaticPricingSource(provider Provider, pricing []InstanceTypePricing) PricingSource {
+	return &staticPricingSource{provider: provider, pricing: pricing}
+}
+
+// Provider returns the provider the pricing belongs to.
+func (s *staticPricingSource) Provider() Provider {
+	return s.provider
+}
+
+// FetchPricing returns a copy of the maintained pricing list.
+func (s *staticPricingSource) FetchPricing(context.Context) ([]InstanceTypePricing, error) {
+	pricing := make([]InstanceTypePricing, len(s.pricing))
+	copy(pricing, s.pricing)
+	for i := range pricing {
+		pricing[i].Provider = s.provider
+	}
+	return pricing, nil
+}
+
+// providerPricingSource fetches pricing from a cloud provider's instance types.
+type providerPricingSource struct {
+	provider cloudprovider.NodeProvider
+	regions  []string
+}
+
+// NewProviderPricingSource creates a PricingSource that lists the instance
+// types of a cloud provider in each of the given regions, or in all of the
+// provider's regions when none are given.
+func NewProviderPricingSource(provider cloudprovider.NodeProvider, regions []string) PricingSource {
+	if len(regions) == 0 {
+		regions = provider.Regions()
+	}
+	return &providerPricingSource{provider: provider, regions: regions}
+}
+
+// Provider returns the provider the pricing belongs to.
+func (s *providerPricingSource) Provider() Provider {
+	return Provider(s.provider.Name())
+}
+
+// FetchPricing lists instance types per region. Regions that fail are
+// skipped and reported in the returned error.
+func (s *providerPricingSource) FetchPricing(ctx context.Context) ([]InstanceTypePricing, error) {
+	var pricing []InstanceTypePricing
+	var errs []error
+
+	for _, region := range s.regions {
+		types, err := s.provider.ListInstanceTypes(ctx, region)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+			continue
+		}
+
+		for _, t := range types {
+			p := InstanceTypePricing{
+				Provider:     s.Provider(),
+				InstanceType: t.Name,
+				Region:       region,
+				HourlyCost:   t.HourlyCost,
+				CPUCores:     t.CPUCores,
+				MemoryMB:     t.MemoryMB,
+				SupportsSpot: t.SpotSupport,
+			}
+			if t.DiskGB > 0 {
+				disk := t.DiskGB
+				p.DiskGB = &disk
+			}
+			pricing = append(pricing, p)
+		}
+	}
+
+	return pricing, errors.Join(errs...)
+}
+
+// PricingRefresher periodically refreshes stored instance pricing from
+// pricing sources. Failed refreshes keep the stored pricing.
+type PricingRefresher struct {
+	store    PricingStore
+	sources  []PricingSource
+	interval time.Duration
+	logger   *slog.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPricingRefresher creates a new PricingRefresher. Only sources of the
+// providers listed in cfg.PricingRefreshProviders are refreshed.
+func NewPricingRefresher(store PricingStore, sources []PricingSource, cfg config.NodeScalingConfig, logger *slog.Logger) *PricingRefresher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	enabled := make(map[Provider]bool, len(cfg.PricingRefreshProviders))
+	for _, name := range cfg.PricingRefreshProviders {
+		enabled[Provider(name)] = true
+	}
+
+	var optedIn []PricingSource
+	for _, source := range sources {
+		if enabled[source.Provider()] {
+			optedIn = append(optedIn, source)
+		}
+	}
+
+	return &PricingRefresher{
+		store:    store,
+		sources:  optedIn,
+		interval: cfg.PricingRefreshInterval,
+		logger:   logger.With("component", "pricing-refresher"),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start starts the refresh loop in the background.
+func (r *PricingRefresher) Start(ctx context.Context) {
+	if len(r.sources) == 0 || r.interval <= 0 {
+		r.logger.Info("pricing refresh disabled")
+		return
+	}
+
+	r.logger.Info("starting pricing refresher", "providers", len(r.sources), "interval", r.interval)
+
+	r.wg.Add(1)
+	go r.runLoop(ctx)
+}
+
+// Stop stops the refresh loop.
+func (r *PricingRefresher) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+func (r *PricingRefresher) runLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh refreshes the pricing of every source once. A failing source or
+// entry is logged and skipped, leaving its stored pricing in place.
+func (r *PricingRefresher) Refresh(ctx context.Context) {
+	for _, source := range r.sources {
+		provider := source.Provider()
+		result := r.refreshSource(ctx, source)
+		pricingRefreshCounter.WithLabelValues(provider.String(), result).Inc()
+		r.updateStaleness(ctx, provider)
+	}
+}
+
+// refreshSource upserts the pricing of one source and returns the refresh
+// result: success, partial or error.
+func (r *PricingRefresher) refreshSource(ctx context.Context, source PricingSource) string {
+	provider := source.Provider()
+
+	pricing, fetchErr := source.FetchPricing(ctx)
+	if fetchErr != nil {
+		r.logger.Warn("failed to fetch pricing, keeping stored pricing",
+			"provider", provider,
+			"fetched", len(pricing),
+			"error", fetchErr,
+		)
+		if len(pricing) == 0 {
+			return "error"
+		}
+	}
+
+	valid, updated := 0, 0
+	for i := range pricing {
+		p := &pricing[i]
+		if !validPricing(p) {
+			r.logger.Debug("skipping incomplete pricing", "provider", provider, "instance_type", p.InstanceType, "region", p.Region)
+			continue
+		}
+		valid++
+		if err := r.store.UpsertPricing(ctx, p); err != nil {
+			r.logger.Warn("failed to store pricing", "provider", provider, "instance_type", p.InstanceType, "error", err)
+			continue
+		}
+		updated++
+	}
+
+	r.logger.Info("refreshed pricing", "provider", provider, "updated", updated, "fetched", len(pricing))
+
+	switch {
+	case valid > 0 && updated == 0:
+		return "error"
+	case fetchErr != nil || updated < valid:
+		return "partial"
+	default:
+		return "success"
+	}
+}
+
+// updateStaleness sets the staleness metric from the least recently updated
+// stored price of a provider.
+func (r *PricingRefresher) updateStaleness(ctx context.Context, provider Provider) {
+	pricing, err := r.store.ListPricingForProvider(ctx, provider, "")
+	if err != nil {
+		r.logger.Warn("failed to list pricing", "provider", provider, "error", err)
+		return
+	}
+	if len(pricing) == 0 {
+		return
+	}
+
+	oldest := pricing[0].LastUpdated
+	for i := range pricing {
+		if pricing[i].LastUpdated.Before(oldest) {
+			oldest = pricing[i].LastUpdated
+		}
+	}
+	pricingStalenessGauge.WithLabelValues(provider.String()).Set(time.Since(oldest).Seconds())
+}
+
+// validPricing reports whether pricing is complete enough to store. Providers
+// report a zero price for instance types unavailable in a region.
+func validPricing(p *InstanceTypePricing) bool {
+	return p.InstanceType != "" && p.Region != "" && p.HourlyCost > 0 && p.CPUCores > 0 && p.MemoryMB > 0
+}
@@ -0,0 +1,77 @@
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/config"
+)
+
+type fakePricingStore struct {
+	stored  map[string]InstanceTypePricing
+	failing string
+}
+
+func (s *fakePricingStore) UpsertPricing(_ context.Context, p *InstanceTypePricing) error {
+	if p.InstanceType == s.failing {
+		return errors.New("upsert failed")
+	}
+	p.LastUpdated = time.Now()
+	s.stored[p.InstanceType] = *p
+	return nil
+}
+
+func (s *fakePricingStore) ListPricingForProvider(context.Context, Provider, string) ([]InstanceTypePricing, error) {
+	var pricing []InstanceTypePricing
+	for _, p := range s.stored {
+		pricing = append(pricing, p)
+	}
+	return pricing, nil
+}
+
+type failingPricingSource struct{}
+
+func (failingPricingSource) Provider() Provider { return ProviderHetzner }
+
+func (failingPricingSource) FetchPricing(context.Context) ([]InstanceTypePricing, error) {
+	return nil, errors.New("api unavailable")
+}
+
+func TestPricingRefresher(t *testing.T) {
+	stale := InstanceTypePricing{Provider: ProviderHetzner, InstanceType: "cx22", Region: "nbg1", HourlyCost: 0.0065}
+	store := &fakePricingStore{stored: map[string]InstanceTypePricing{"cx22": stale}, failing: "V3"}
+
+	contabo := NewStaticPricingSource(ProviderContabo, []InstanceTypePricing{
+		{InstanceType: "V1", Region: "EU", HourlyCost: 0.0069, CPUCores: 4, MemoryMB: 8192},
+		{InstanceType: "V2", Region: "EU", CPUCores: 6, MemoryMB: 16384}, // no price
+		{InstanceType: "V3", Region: "EU", HourlyCost: 0.0194, CPUCores: 8, MemoryMB: 30720},
+	})
+	ovh := NewStaticPricingSource(ProviderOVH, []InstanceTypePricing{
+		{InstanceType: "b2-7", Region: "GRA", HourlyCost: 0.07, CPUCores: 2, MemoryMB: 7168},
+	})
+
+	cfg := config.NodeScalingConfig{PricingRefreshProviders: []string{"hetzner", "contabo"}, PricingRefreshInterval: time.Hour}
+	r := NewPricingRefresher(store, []PricingSource{failingPricingSource{}, contabo, ovh}, cfg, nil)
+	if len(r.sources) != 2 {
+		t.Fatalf("refresher has %d sources, want the 2 opted-in providers", len(r.sources))
+	}
+
+	if got := r.refreshSource(context.Background(), failingPricingSource{}); got != "error" {
+		t.Errorf("refreshSource(failing) = %q, want error", got)
+	}
+	if store.stored["cx22"] != stale {
+		t.Error("failed refresh changed stored pricing")
+	}
+
+	if got := r.refreshSource(context.Background(), contabo); got != "partial" {
+		t.Errorf("refreshSource(contabo) = %q, want partial", got)
+	}
+	if p, ok := store.stored["V1"]; !ok || p.Provider != ProviderContabo {
+		t.Errorf("stored V1 = %+v, want contabo pricing", p)
+	}
+	if _, ok := store.stored["V2"]; ok {
+		t.Error("stored pricing without a price")
+	}
+}
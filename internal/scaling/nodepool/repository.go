@@ -46,12 +46,15 @@ func (r *Repository) CreatePool(ctx context.Context, pool *NodePool) (*NodePool,
 		return nil, fmt.Errorf("failed to marshal taints: %w", err)
 	}
 
+	minCPUCores, minMemoryMB := requirementColumns(pool.Requirements)
+
 	query := `
 		INSERT INTO philotes.node_pools (
 			name, provider, region, instance_type, image, min_nodes, max_nodes,
 			current_nodes, labels, taints, user_data_template, ssh_key_id,
-			network_id, firewall_id, enabled
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			network_id, firewall_id, enabled, spot_enabled, spot_max_percent,
+			min_cpu_cores, min_memory_mb, notify_scaling
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING id, created_at, updated_at`
 
 	err = r.db.QueryRow(ctx, query,
@@ -70,6 +73,11 @@ func (r *Repository) CreatePool(ctx context.Context, pool *NodePool) (*NodePool,
 		pool.NetworkID,
 		pool.FirewallID,
 		pool.Enabled,
+		pool.SpotEnabled,
+		pool.SpotMaxPercent,
+		minCPUCores,
+		minMemoryMB,
+		pool.NotifyScaling,
 	).Scan(&pool.ID, &pool.CreatedAt, &pool.UpdatedAt)
 
 	if err != nil {
@@ -87,7 +95,8 @@ func (r *Repository) GetPool(ctx context.Context, id uuid.UUID) (*NodePool, erro
 	query := `
 		SELECT id, name, provider, region, instance_type, image, min_nodes, max_nodes,
 			   current_nodes, labels, taints, user_data_template, ssh_key_id,
-			   network_id, firewall_id, enabled, created_at, updated_at
+			   network_id, firewall_id, enabled, spot_enabled, spot_max_percent,
+			   min_cpu_cores, min_memory_mb, notify_scaling, created_at, updated_at
 		FROM philotes.node_pools
 		WHERE id = $1`
 
@@ -99,7 +108,8 @@ func (r *Repository) GetPoolByName(ctx context.Context, name string) (*NodePool,
 	query := `
 		SELECT id, name, provider, region, instance_type, image, min_nodes, max_nodes,
 			   current_nodes, labels, taints, user_data_template, ssh_key_id,
-			   network_id, firewall_id, enabled, created_at, updated_at
+			   network_id, firewall_id, enabled, spot_enabled, spot_max_percent,
+			   min_cpu_cores, min_memory_mb, notify_scaling, created_at, updated_at
 		FROM philotes.node_pools
 		WHERE name = $1`
 
@@ -111,7 +121,8 @@ func (r *Repository) ListPools(ctx context.Context, enabledOnly bool) ([]NodePoo
 	query := `
 		SELECT id, name, provider, region, instance_type, image, min_nodes, max_nodes,
 			   current_nodes, labels, taints, user_data_template, ssh_key_id,
-			   network_id, firewall_id, enabled, created_at, updated_at
+			   network_id, firewall_id, enabled, spot_enabled, spot_max_percent,
+			   min_cpu_cores, min_memory_mb, notify_scaling, created_at, updated_at
 		FROM philotes.node_pools`
 
 	if enabledOnly {
@@ -149,12 +160,16 @@ func (r *Repository) UpdatePool(ctx context.Context, pool *NodePool) error {
 		return fmt.Errorf("failed to marshal taints: %w", err)
 	}
 
+	minCPUCores, minMemoryMB := requirementColumns(pool.Requirements)
+
 	query := `
 		UPDATE philotes.node_pools
 		SET name = $2, provider = $3, region = $4, instance_type = $5, image = $6,
 			min_nodes = $7, max_nodes = $8, current_nodes = $9, labels = $10,
 			taints = $11, user_data_template = $12, ssh_key_id = $13,
-			network_id = $14, firewall_id = $15, enabled = $16
+			network_id = $14, firewall_id = $15, enabled = $16,
+			spot_enabled = $17, spot_max_percent = $18,
+			min_cpu_cores = $19, min_memory_mb = $20, notify_scaling = $21
 		WHERE id = $1
 		RETURNING updated_at`
 
@@ -175,6 +190,11 @@ func (r *Repository) UpdatePool(ctx context.Context, pool *NodePool) error {
 		pool.NetworkID,
 		pool.FirewallID,
 		pool.Enabled,
+		pool.SpotEnabled,
+		pool.SpotMaxPercent,
+		minCPUCores,
+		minMemoryMB,
+		pool.NotifyScaling,
 	).Scan(&pool.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -397,6 +417,21 @@ func (r *Repository) CountActiveNodesForPool(ctx context.Context, poolID uuid.UU
 	return count, nil
 }
 
+// CountActiveSpotNodesForPool counts active spot nodes in a pool.
+func (r *Repository) CountActiveSpotNodesForPool(ctx context.Context, poolID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM philotes.node_pool_nodes
+		WHERE pool_id = $1 AND is_spot AND deleted_at IS NULL AND status NOT IN ('deleted', 'failed')`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, poolID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count spot nodes: %w", err)
+	}
+	return count, nil
+}
+
 // ============================================================================
 // Scaling Operation Operations
 // ============================================================================
@@ -483,6 +518,61 @@ func (r *Repository) ListOperationsForPool(ctx context.Context, poolID uuid.UUID
 	return ops, rows.Err()
 }
 
+// SumOperationCostChange sums the estimated cost change of a pool's
+// completed, non-dry-run scaling operations started within [from, to).
+func (r *Repository) SumOperationCostChange(ctx context.Context, poolID uuid.UUID, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(estimated_cost_change), 0)
+		FROM philotes.node_scaling_operations
+		WHERE pool_id = $1 AND status = 'completed' AND NOT dry_run
+		  AND started_at >= $2 AND started_at < $3`
+
+	var sum float64
+	err := r.db.QueryRow(ctx, query, poolID, from, to).Scan(&sum)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum operation cost change: %w", err)
+	}
+	return sum, nil
+}
+
+// GetLastOperationCompletion returns when the last non-dry-run scaling
+// operation of a pool reached a terminal state, or nil if none has.
+func (r *Repository) GetLastOperationCompletion(ctx context.Context, poolID uuid.UUID) (*time.Time, error) {
+	query := `
+		SELECT MAX(completed_at)
+		FROM philotes.node_scaling_operations
+		WHERE pool_id = $1 AND status IN ('completed', 'failed', 'canceled') AND NOT dry_run`
+
+	var completedAt *time.Time
+	err := r.db.QueryRow(ctx, query, poolID).Scan(&completedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last operation completion: %w", err)
+	}
+	return completedAt, nil
+}
+
+// GetPoolCooldownSeconds returns the cooldown of the scaling policy targeting
+// a pool, preferring enabled policies. It returns nil if the pool has no
+// policy or the policy uses the default cooldown.
+func (r *Repository) GetPoolCooldownSeconds(ctx context.Context, poolID uuid.UUID) (*int, error) {
+	query := `
+		SELECT cooldown_seconds
+		FROM scaling_policies
+		WHERE target_type = 'nodes' AND target_id = $1
+		ORDER BY enabled DESC, created_at
+		LIMIT 1`
+
+	var cooldown *int
+	err := r.db.QueryRow(ctx, query, poolID).Scan(&cooldown)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pool cooldown: %w", err)
+	}
+	return cooldown, nil
+}
+
 // UpdateOperationStatus updates a scaling operation's status.
 func (r *Repository) UpdateOperationStatus(ctx context.Context, id uuid.UUID, status OperationStatus, actualCount *int, errorMessage string) error {
 	var completedAt *time.Time
@@ -607,6 +697,41 @@ func (r *Repository) ListPricingForProvider(ctx context.Context, provider Provid
 	return pricing, rows.Err()
 }
 
+// UpsertPricing inserts or updates pricing for an instance type and marks it
+// as updated now. A missing spot price keeps the stored one.
+func (r *Repository) UpsertPricing(ctx context.Context, pricing *InstanceTypePricing) error {
+	query := `
+		INSERT INTO philotes.instance_type_pricing (
+			provider, instance_type, region, hourly_cost, cpu_cores, memory_mb,
+			disk_gb, supports_spot, spot_hourly_cost, last_updated
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (provider, instance_type, region) DO UPDATE SET
+			hourly_cost = EXCLUDED.hourly_cost,
+			cpu_cores = EXCLUDED.cpu_cores,
+			memory_mb = EXCLUDED.memory_mb,
+			disk_gb = EXCLUDED.disk_gb,
+			supports_spot = EXCLUDED.supports_spot,
+			spot_hourly_cost = COALESCE(EXCLUDED.spot_hourly_cost, philotes.instance_type_pricing.spot_hourly_cost),
+			last_updated = NOW()
+		RETURNING id, last_updated`
+
+	err := r.db.QueryRow(ctx, query,
+		pricing.Provider,
+		pricing.InstanceType,
+		pricing.Region,
+		pricing.HourlyCost,
+		pricing.CPUCores,
+		pricing.MemoryMB,
+		pricing.DiskGB,
+		pricing.SupportsSpot,
+		pricing.SpotHourlyCost,
+	).Scan(&pricing.ID, &pricing.LastUpdated)
+	if err != nil {
+		return fmt.Errorf("failed to upsert pricing: %w", err)
+	}
+	return nil
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
@@ -614,6 +739,7 @@ func (r *Repository) ListPricingForProvider(ctx context.Context, provider Provid
 func (r *Repository) scanPool(row pgx.Row) (*NodePool, error) {
 	pool := &NodePool{}
 	var labelsJSON, taintsJSON []byte
+	var minCPUCores, minMemoryMB *int
 
 	err := row.Scan(
 		&pool.ID,
@@ -632,6 +758,11 @@ func (r *Repository) scanPool(row pgx.Row) (*NodePool, error) {
 		&pool.NetworkID,
 		&pool.FirewallID,
 		&pool.Enabled,
+		&pool.SpotEnabled,
+		&pool.SpotMaxPercent,
+		&minCPUCores,
+		&minMemoryMB,
+		&pool.NotifyScaling,
 		&pool.CreatedAt,
 		&pool.UpdatedAt,
 	)
@@ -649,6 +780,7 @@ func (r *Repository) scanPool(row pgx.Row) (*NodePool, error) {
 	if err := json.Unmarshal(taintsJSON, &pool.Taints); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal taints: %w", err)
 	}
+	pool.Requirements = requirementsFromColumns(minCPUCores, minMemoryMB)
 
 	return pool, nil
 }
@@ -656,6 +788,7 @@ func (r *Repository) scanPool(row pgx.Row) (*NodePool, error) {
 func (r *Repository) scanPoolFromRows(rows pgx.Rows) (*NodePool, error) {
 	pool := &NodePool{}
 	var labelsJSON, taintsJSON []byte
+	var minCPUCores, minMemoryMB *int
 
 	err := rows.Scan(
 		&pool.ID,
@@ -674,6 +807,11 @@ func (r *Repository) scanPoolFromRows(rows pgx.Rows) (*NodePool, error) {
 		&pool.NetworkID,
 		&pool.FirewallID,
 		&pool.Enabled,
+		&pool.SpotEnabled,
+		&pool.SpotMaxPercent,
+		&minCPUCores,
+		&minMemoryMB,
+		&pool.NotifyScaling,
 		&pool.CreatedAt,
 		&pool.UpdatedAt,
 	)
@@ -688,10 +826,36 @@ func (r *Repository) scanPoolFromRows(rows pgx.Rows) (*NodePool, error) {
 	if err := json.Unmarshal(taintsJSON, &pool.Taints); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal taints: %w", err)
 	}
+	pool.Requirements = requirementsFromColumns(minCPUCores, minMemoryMB)
 
 	return pool, nil
 }
 
+// requirementColumns returns the column values of pool requirements, NULL
+// when the pool has none.
+func requirementColumns(req *InstanceRequirements) (minCPUCores, minMemoryMB *int) {
+	if req == nil {
+		return nil, nil
+	}
+	return &req.MinCPUCores, &req.MinMemoryMB
+}
+
+// requirementsFromColumns returns the pool requirements stored in columns,
+// nil when none are set.
+func requirementsFromColumns(minCPUCores, minMemoryMB *int) *InstanceRequirements {
+	if minCPUCores == nil && minMemoryMB == nil {
+		return nil
+	}
+	req := &InstanceRequirements{}
+	if minCPUCores != nil {
+		req.MinCPUCores = *minCPUCores
+	}
+	if minMemoryMB != nil {
+		req.MinMemoryMB = *minMemoryMB
+	}
+	return req
+}
+
 func (r *Repository) scanNode(row pgx.Row) (*Node, error) {
 	node := &Node{}
 	err := row.Scan(
@@ -0,0 +1,44 @@
+package nodepool
+
+import "errors"
+
+// ErrNoEligibleInstanceType is returned when no priced instance type meets a
+// pool's requirements.
+var ErrNoEligibleInstanceType = errors.New("no instance type meets the requirements")
+
+// SelectInstanceType returns the cheapest instance type in region meeting
+// the requirements. With preferSpot, spot-capable types are preferred and
+// compared by their spot price; types without spot support are only chosen
+// when no spot-capable type qualifies.
+func SelectInstanceType(req InstanceRequirements, region string, pricing []InstanceTypePricing, preferSpot bool) (*InstanceTypePricing, error) {
+	var best, bestSpot *InstanceTypePricing
+	for i := range pricing {
+		p := &pricing[i]
+		if p.Region != region || p.CPUCores < req.MinCPUCores || p.MemoryMB < req.MinMemoryMB {
+			continue
+		}
+		if best == nil || p.HourlyCost < best.HourlyCost {
+			best = p
+		}
+		if preferSpot && p.SupportsSpot && (bestSpot == nil || spotCost(p) < spotCost(bestSpot)) {
+			bestSpot = p
+		}
+	}
+
+	if bestSpot != nil {
+		return bestSpot, nil
+	}
+	if best == nil {
+		return nil, ErrNoEligibleInstanceType
+	}
+	return best, nil
+}
+
+// spotCost returns the spot price of an instance type, or its on-demand
+// price when no spot price is known.
+func spotCost(p *InstanceTypePricing) float64 {
+	if p.SpotHourlyCost != nil {
+		return *p.SpotHourlyCost
+	}
+	return p.HourlyCost
+}
@@ -0,0 +1,44 @@
+package nodepool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelectInstanceType(t *testing.T) {
+	spot := func(cost float64) *float64 { return &cost }
+	pricing := []InstanceTypePricing{
+		{InstanceType: "small", Region: "nbg1", HourlyCost: 0.01, CPUCores: 2, MemoryMB: 4096},
+		{InstanceType: "medium", Region: "nbg1", HourlyCost: 0.03, CPUCores: 4, MemoryMB: 8192},
+		{InstanceType: "medium-spot", Region: "nbg1", HourlyCost: 0.04, CPUCores: 4, MemoryMB: 8192, SupportsSpot: true, SpotHourlyCost: spot(0.012)},
+		{InstanceType: "large", Region: "nbg1", HourlyCost: 0.06, CPUCores: 8, MemoryMB: 16384},
+		{InstanceType: "medium-cheap", Region: "fsn1", HourlyCost: 0.02, CPUCores: 4, MemoryMB: 8192},
+	}
+
+	tests := []struct {
+		name       string
+		req        InstanceRequirements
+		preferSpot bool
+		want       string
+	}{
+		{"cheapest meeting cpu", InstanceRequirements{MinCPUCores: 3}, false, "medium"},
+		{"cheapest meeting memory", InstanceRequirements{MinMemoryMB: 10000}, false, "large"},
+		{"spot preferred", InstanceRequirements{MinCPUCores: 4}, true, "medium-spot"},
+		{"no spot candidate", InstanceRequirements{MinCPUCores: 8}, true, "large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectInstanceType(tt.req, "nbg1", pricing, tt.preferSpot)
+			if err != nil {
+				t.Fatalf("SelectInstanceType() error = %v", err)
+			}
+			if got.InstanceType != tt.want {
+				t.Errorf("SelectInstanceType() = %s, want %s", got.InstanceType, tt.want)
+			}
+		})
+	}
+
+	if _, err := SelectInstanceType(InstanceRequirements{MinCPUCores: 16}, "nbg1", pricing, false); !errors.Is(err, ErrNoEligibleInstanceType) {
+		t.Errorf("SelectInstanceType(too large) error = %v, want ErrNoEligibleInstanceType", err)
+	}
+}
@@ -0,0 +1,196 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/scaling/nodepool"
+)
+
+// notificationTimeout bounds the delivery of a scaling notification.
+const notificationTimeout = 30 * time.Second
+
+// Scaling event kinds reported in notifications.
+const (
+	EventKindScaleUp     = "scale_up"
+	EventKindScaleDown   = "scale_down"
+	EventKindScaleToZero = "scale_to_zero"
+	EventKindColdStart   = "cold_start"
+	EventKindSpotReplace = "spot_replace"
+)
+
+// ChannelStore looks up notification channels.
+type ChannelStore interface {
+	GetChannel(ctx context.Context, id uuid.UUID) (*alerting.NotificationChannel, error)
+}
+
+// OperationNotifier sends a notification through an alerting channel for
+// every completed scaling operation of the node pools that opt in.
+type OperationNotifier struct {
+	channel *alerting.NotificationChannel
+	sender  alerting.ChannelSender
+	logger  *slog.Logger
+}
+
+// NewOperationNotifier creates a new OperationNotifier sending through sender,
+// which was created for channel.
+func NewOperationNotifier(channel *alerting.NotificationChannel, sender alerting.ChannelSender, logger *slog.Logger) *OperationNotifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &OperationNotifier{
+		channel: channel,
+		sender:  sender,
+		logger:  logger.With("component", "scaling-notifier"),
+	}
+}
+
+// LoadOperationNotifier creates the OperationNotifier for the channel
+// configured in cfg.NotificationChannelID. It returns nil when no channel is
+// configured.
+func LoadOperationNotifier(ctx context.Context, store ChannelStore, factory alerting.ChannelFactory, cfg config.NodeScalingConfig, logger *slog.Logger) (*OperationNotifier, error) {
+	if cfg.NotificationChannelID == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(cfg.NotificationChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification channel ID %q: %w", cfg.NotificationChannelID, err)
+	}
+
+	channel, err := store.GetChannel(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channel: %w", err)
+	}
+	if !channel.Enabled {
+		return nil, fmt.Errorf("notification channel %s is disabled", channel.Name)
+	}
+
+	sender, err := factory(channel.Type, channel.Config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel sender for %s: %w", channel.Type, err)
+	}
+
+	return NewOperationNotifier(channel, sender, logger), nil
+}
+
+// Notify sends a notification for a completed operation of pool if the pool
+// opted in. Delivery errors are logged and do not affect the operation.
+func (n *OperationNotifier) Notify(ctx context.Context, pool *nodepool.NodePool, op *nodepool.ScalingOperation) {
+	if n == nil || !pool.NotifyScaling {
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+
+	if err := n.sender.Send(sendCtx, n.notification(pool, op)); err != nil {
+		n.logger.Warn("failed to send scaling notification",
+			"pool", pool.Name,
+			"operation_id", op.ID,
+			"channel", n.channel.Name,
+			"error", err,
+		)
+	}
+}
+
+// notification builds the notification of an operation. The details are
+// carried in the alert labels, which every channel renders.
+func (n *OperationNotifier) notification(pool *nodepool.NodePool, op *nodepool.ScalingOperation) alerting.Notification {
+	kind := operationEventKind(op)
+
+	finalCount := op.TargetCount
+	if op.ActualCount != nil {
+		finalCount = *op.ActualCount
+	}
+
+	labels := map[string]string{
+		"pool":           pool.Name,
+		"provider":       pool.Provider.String(),
+		"region":         pool.Region,
+		"event":          kind,
+		"previous_count": strconv.Itoa(op.PreviousCount),
+		"target_count":   strconv.Itoa(op.TargetCount),
+		"actual_count":   strconv.Itoa(finalCount),
+		"simulated":      strconv.FormatBool(op.DryRun),
+	}
+	if op.Reason != "" {
+		labels["reason"] = op.Reason
+	}
+	if op.TriggeredBy != "" {
+		labels["triggered_by"] = op.TriggeredBy
+	}
+	if op.EstimatedCostChange != nil {
+		labels["hourly_cost_change"] = strconv.FormatFloat(*op.EstimatedCostChange, 'f', 4, 64)
+	}
+
+	name := fmt.Sprintf("Node pool %s: %s", pool.Name, kind)
+	description := fmt.Sprintf("Node pool %s scaled from %d to %d nodes", pool.Name, op.PreviousCount, finalCount)
+	if op.DryRun {
+		name = "[SIMULATED] " + name
+		description = fmt.Sprintf("Node pool %s would scale from %d to %d nodes", pool.Name, op.PreviousCount, op.TargetCount)
+	}
+	if op.Reason != "" {
+		description += ": " + op.Reason
+	}
+	if op.EstimatedCostChange != nil {
+		description += fmt.Sprintf(" (cost change: %+.4f/h)", *op.EstimatedCostChange)
+	}
+
+	firedAt := op.StartedAt
+	if op.CompletedAt != nil {
+		firedAt = *op.CompletedAt
+	}
+
+	rule := &alerting.AlertRule{
+		ID:          pool.ID,
+		Name:        name,
+		Description: description,
+		MetricName:  "node_pool_scaling",
+		Severity:    alerting.SeverityInfo,
+		Labels:      map[string]string{"pool": pool.Name},
+		Enabled:     true,
+	}
+
+	return alerting.Notification{
+		Alert: &alerting.AlertInstance{
+			ID:          op.ID,
+			RuleID:      pool.ID,
+			Fingerprint: op.ID.String(),
+			Status:      alerting.StatusFiring,
+			Labels:      labels,
+			FiredAt:     firedAt,
+		},
+		Rule:    rule,
+		Channel: n.channel,
+		Event:   alerting.EventScaling,
+	}
+}
+
+// operationEventKind classifies an operation for notifications: scaling a
+// pool to zero nodes and scaling it up from zero are reported as
+// scale-to-zero and cold start.
+func operationEventKind(op *nodepool.ScalingOperation) string {
+	switch op.Action {
+	case nodepool.OperationActionSpotReplace:
+		return EventKindSpotReplace
+	case nodepool.OperationActionScaleDown:
+		if op.TargetCount == 0 {
+			return EventKindScaleToZero
+		}
+		return EventKindScaleDown
+	default:
+		if op.PreviousCount == 0 {
+			return EventKindColdStart
+		}
+		return EventKindScaleUp
+	}
+}
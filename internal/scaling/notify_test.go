@@ -0,0 +1,99 @@
+package scaling
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/alerting"
+	"github.com/janovincze/philotes/internal/scaling/nodepool"
+)
+
+type recordingSender struct {
+	sent []alerting.Notification
+}
+
+func (s *recordingSender) Type() alerting.ChannelType {
+	return alerting.ChannelWebhook
+}
+
+func (s *recordingSender) Send(_ context.Context, notification alerting.Notification) error {
+	s.sent = append(s.sent, notification)
+	return nil
+}
+
+func TestOperationEventKind(t *testing.T) {
+	tests := []struct {
+		action   nodepool.OperationAction
+		previous int
+		target   int
+		want     string
+	}{
+		{nodepool.OperationActionScaleUp, 2, 4, EventKindScaleUp},
+		{nodepool.OperationActionScaleUp, 0, 1, EventKindColdStart},
+		{nodepool.OperationActionScaleDown, 4, 2, EventKindScaleDown},
+		{nodepool.OperationActionScaleDown, 2, 0, EventKindScaleToZero},
+		{nodepool.OperationActionSpotReplace, 2, 3, EventKindSpotReplace},
+	}
+
+	for _, tt := range tests {
+		op := &nodepool.ScalingOperation{Action: tt.action, PreviousCount: tt.previous, TargetCount: tt.target}
+		if got := operationEventKind(op); got != tt.want {
+			t.Errorf("operationEventKind(%s %d->%d) = %s, want %s", tt.action, tt.previous, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestOperationNotifier_Notify(t *testing.T) {
+	sender := &recordingSender{}
+	n := NewOperationNotifier(&alerting.NotificationChannel{Name: "ops"}, sender, nil)
+
+	pool := &nodepool.NodePool{ID: uuid.New(), Name: "workers", Provider: nodepool.ProviderHetzner, Region: "fsn1"}
+	actual := 4
+	cost := 0.08
+	op := &nodepool.ScalingOperation{
+		ID:                  uuid.New(),
+		Action:              nodepool.OperationActionScaleUp,
+		PreviousCount:       2,
+		TargetCount:         4,
+		ActualCount:         &actual,
+		Reason:              "Scaling from 2 to 4 nodes",
+		EstimatedCostChange: &cost,
+	}
+
+	n.Notify(context.Background(), pool, op)
+	if len(sender.sent) != 0 {
+		t.Fatalf("sent %d notifications for a pool that did not opt in, want 0", len(sender.sent))
+	}
+
+	pool.NotifyScaling = true
+	n.Notify(context.Background(), pool, op)
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sender.sent))
+	}
+
+	got := sender.sent[0]
+	if got.Event != alerting.EventScaling {
+		t.Errorf("Event = %s, want %s", got.Event, alerting.EventScaling)
+	}
+	labels := got.Alert.Labels
+	if labels["pool"] != "workers" || labels["event"] != EventKindScaleUp || labels["previous_count"] != "2" ||
+		labels["actual_count"] != "4" || labels["hourly_cost_change"] != "0.0800" || labels["simulated"] != "false" {
+		t.Errorf("Labels = %v, want pool, counts, cost change and simulated=false", labels)
+	}
+	if !strings.Contains(got.Rule.Description, "from 2 to 4 nodes") || !strings.Contains(got.Rule.Description, "+0.0800/h") {
+		t.Errorf("Description = %q, want counts and cost change", got.Rule.Description)
+	}
+
+	op.DryRun = true
+	n.Notify(context.Background(), pool, op)
+	simulated := sender.sent[1]
+	if !strings.HasPrefix(simulated.Rule.Name, "[SIMULATED]") || simulated.Alert.Labels["simulated"] != "true" {
+		t.Errorf("dry-run notification = %q %v, want it labeled as simulated", simulated.Rule.Name, simulated.Alert.Labels)
+	}
+
+	var nilNotifier *OperationNotifier
+	nilNotifier.Notify(context.Background(), pool, op)
+}
@@ -0,0 +1,425 @@
+// Package scaletozero scales the CDC workers of idle pipelines to zero and
+// cold-starts them when their source has new changes.
+package scaletozero
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/scaling"
+	"github.com/janovincze/philotes/internal/scaling/idle"
+	"github.com/janovincze/philotes/internal/scaling/wake"
+)
+
+// coldStartPollInterval is how often a cold-started worker is checked for
+// readiness.
+const coldStartPollInterval = 5 * time.Second
+
+// Activity is what a pipeline has left to replicate.
+type Activity struct {
+	// BufferDepth is the number of buffered events not yet written to the sink.
+	BufferDepth int64
+
+	// SlotLagBytes is how much WAL the source has written past the
+	// replication slot's confirmed position.
+	SlotLagBytes int64
+
+	// LastEventAt is when the pipeline last buffered an event, zero if unknown.
+	LastEventAt time.Time
+}
+
+// Pending reports whether the pipeline has changes waiting to be replicated.
+func (a Activity) Pending() bool {
+	return a.BufferDepth > 0 || a.SlotLagBytes > 0
+}
+
+// ActivitySource reports the activity of pipelines. It must work while the
+// pipeline's worker is scaled to zero, e.g. by reading the slot lag from the
+// source database directly.
+type ActivitySource interface {
+	PipelineActivity(ctx context.Context, pipelineID uuid.UUID) (Activity, error)
+}
+
+// PolicyStore is the scaling policy persistence used by the controller.
+type PolicyStore interface {
+	ListPolicies(ctx context.Context, enabledOnly bool) ([]scaling.Policy, error)
+	UpsertState(ctx context.Context, state *scaling.State) error
+	CreateHistory(ctx context.Context, history *scaling.History) (*scaling.History, error)
+}
+
+// CostRecorder records the savings of scaled-to-zero time.
+type CostRecorder interface {
+	RecordCostSavings(ctx context.Context, savings *scaling.CostSavings) error
+}
+
+// Waker cold-starts scaled-to-zero policies.
+type Waker interface {
+	Wake(ctx context.Context, policyID uuid.UUID, reason scaling.WakeReason) (*wake.Result, error)
+}
+
+// Controller applies scale to zero to CDC worker deployments. Each enabled
+// cdc-worker policy with scale_to_zero set and a pipeline as its target is
+// checked every IdleCheckInterval: a worker whose pipeline has had nothing to
+// replicate for DefaultIdleThreshold is scaled to zero, unless it was woken
+// within DefaultKeepAliveWindow; a scaled-to-zero worker whose pipeline has
+// pending changes is cold-started and expected ready within ColdStartTimeout.
+type Controller struct {
+	store    PolicyStore
+	activity ActivitySource
+	detector *idle.Detector
+	waker    Waker
+	executor scaling.Executor
+	costs    CostRecorder
+	cfg      config.ScaleToZeroConfig
+	dryRun   bool
+	logger   *slog.Logger
+	now      func() time.Time
+
+	pollInterval time.Duration
+
+	mu sync.Mutex
+	// savedUntil is when scaled-to-zero time was last recorded as savings, per policy
+	savedUntil map[uuid.UUID]time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewController creates a new Controller. costs may be nil when cost
+// tracking is disabled.
+func NewController(
+	store PolicyStore,
+	activity ActivitySource,
+	detector *idle.Detector,
+	waker Waker,
+	executor scaling.Executor,
+	costs CostRecorder,
+	cfg config.ScaleToZeroConfig,
+	dryRun bool,
+	logger *slog.Logger,
+) *Controller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Controller{
+		store:        store,
+		activity:     activity,
+		detector:     detector,
+		waker:        waker,
+		executor:     executor,
+		costs:        costs,
+		cfg:          cfg,
+		dryRun:       dryRun,
+		logger:       logger.With("component", "scale-to-zero-controller"),
+		now:          time.Now,
+		pollInterval: coldStartPollInterval,
+		savedUntil:   make(map[uuid.UUID]time.Time),
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start starts the controller loop. It is a no-op when the idle check
+// interval is not positive.
+func (c *Controller) Start(ctx context.Context) {
+	if c.cfg.IdleCheckInterval <= 0 {
+		c.logger.Info("scale to zero disabled")
+		return
+	}
+
+	c.logger.Info("starting scale-to-zero controller",
+		"check_interval", c.cfg.IdleCheckInterval,
+		"idle_threshold", c.cfg.DefaultIdleThreshold,
+		"keep_alive_window", c.cfg.DefaultKeepAliveWindow,
+	)
+
+	c.wg.Add(1)
+	go c.runLoop(ctx)
+}
+
+// Stop stops the controller loop.
+func (c *Controller) Stop() {
+	close(c.stopCh)
+	c.wg.Wait()
+}
+
+func (c *Controller) runLoop(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cfg.IdleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Reconcile(ctx); err != nil {
+			c.logger.Error("scale-to-zero reconciliation failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile checks every scale-to-zero worker policy once.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	policies, err := c.store.ListPolicies(ctx, true)
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	for i := range policies {
+		policy := &policies[i]
+		if policy.TargetType != scaling.TargetCDCWorker || !policy.ScaleToZero || policy.TargetID == nil {
+			continue
+		}
+		if err := c.reconcilePolicy(ctx, policy); err != nil {
+			c.logger.Error("failed to apply scale to zero",
+				"policy_id", policy.ID,
+				"policy_name", policy.Name,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// action is what the controller does with a worker.
+type action int
+
+const (
+	actionNone action = iota
+	actionScaleToZero
+	actionColdStart
+)
+
+// decide returns the action for a worker given its idle state, nil if the
+// worker is not tracked yet, and its pipeline's activity.
+func (c *Controller) decide(state *scaling.IdleState, activity Activity, now time.Time) action {
+	if state == nil {
+		return actionNone
+	}
+
+	if state.IsScaledToZero {
+		if activity.Pending() {
+			return actionColdStart
+		}
+		return actionNone
+	}
+
+	if activity.Pending() {
+		return actionNone
+	}
+
+	lastActive := state.LastActivityAt
+	if activity.LastEventAt.After(lastActive) {
+		lastActive = activity.LastEventAt
+	}
+	if now.Sub(lastActive) < c.cfg.DefaultIdleThreshold {
+		return actionNone
+	}
+
+	// A recently woken worker stays up for the keep-alive window, so a
+	// trickle of changes does not bounce it up and down
+	if state.LastWakeAt != nil && now.Sub(*state.LastWakeAt) < c.cfg.DefaultKeepAliveWindow {
+		return actionNone
+	}
+
+	return actionScaleToZero
+}
+
+func (c *Controller) reconcilePolicy(ctx context.Context, policy *scaling.Policy) error {
+	activity, err := c.activity.PipelineActivity(ctx, *policy.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to get pipeline activity: %w", err)
+	}
+
+	state, err := c.detector.GetIdleState(ctx, policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get idle state: %w", err)
+	}
+
+	now := c.now()
+	switch c.decide(state, activity, now) {
+	case actionScaleToZero:
+		return c.scaleToZero(ctx, policy, now)
+	case actionColdStart:
+		c.recordSavings(ctx, policy, state, now)
+		return c.coldStart(ctx, policy)
+	}
+
+	if state != nil && state.IsScaledToZero {
+		c.recordSavings(ctx, policy, state, now)
+		return nil
+	}
+
+	// Pending changes, new events and untracked workers reset the idle timer
+	if state == nil || activity.Pending() || activity.LastEventAt.After(state.LastActivityAt) {
+		if err := c.detector.RecordActivity(ctx, policy.ID); err != nil {
+			return fmt.Errorf("failed to record activity: %w", err)
+		}
+	}
+	return nil
+}
+
+// scaleToZero scales the policy's worker to zero replicas.
+func (c *Controller) scaleToZero(ctx context.Context, policy *scaling.Policy, now time.Time) error {
+	current, err := c.executor.GetCurrentReplicas(ctx, policy.TargetType, policy.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to get current replicas: %w", err)
+	}
+
+	if err := c.executor.Scale(ctx, policy.TargetType, policy.TargetID, 0, c.dryRun); err != nil {
+		return fmt.Errorf("failed to scale to zero: %w", err)
+	}
+
+	history := &scaling.History{
+		ID:               uuid.New(),
+		PolicyID:         &policy.ID,
+		PolicyName:       policy.Name,
+		Action:           scaling.ActionScaleDown,
+		TargetType:       policy.TargetType,
+		TargetID:         policy.TargetID,
+		PreviousReplicas: current,
+		NewReplicas:      0,
+		Reason:           fmt.Sprintf("pipeline idle for %s", c.cfg.DefaultIdleThreshold),
+		TriggeredBy:      "scale_to_zero",
+		DryRun:           c.dryRun,
+		ExecutedAt:       now,
+	}
+	if _, err := c.store.CreateHistory(ctx, history); err != nil {
+		c.logger.Warn("failed to record scale-to-zero history", "policy_id", policy.ID, "error", err)
+	}
+
+	if c.dryRun {
+		return nil
+	}
+
+	if err := c.store.UpsertState(ctx, &scaling.State{
+		PolicyID:        policy.ID,
+		CurrentReplicas: 0,
+		LastScaleTime:   &now,
+		LastScaleAction: string(scaling.ActionScaleDown),
+	}); err != nil {
+		c.logger.Warn("failed to update scaling state", "policy_id", policy.ID, "error", err)
+	}
+
+	if err := c.detector.MarkScaledToZero(ctx, policy.ID); err != nil {
+		return fmt.Errorf("failed to mark scaled to zero: %w", err)
+	}
+
+	c.mu.Lock()
+	c.savedUntil[policy.ID] = now
+	c.mu.Unlock()
+
+	c.logger.Info("scaled idle pipeline worker to zero",
+		"policy_id", policy.ID,
+		"pipeline_id", policy.TargetID,
+		"previous_replicas", current,
+	)
+	return nil
+}
+
+// coldStart wakes the policy's worker and waits for it to be running.
+func (c *Controller) coldStart(ctx context.Context, policy *scaling.Policy) error {
+	c.logger.Info("cold-starting pipeline worker with pending changes",
+		"policy_id", policy.ID,
+		"pipeline_id", policy.TargetID,
+	)
+
+	result, err := c.waker.Wake(ctx, policy.ID, scaling.WakeReasonDatabaseActivity)
+	if err != nil {
+		return fmt.Errorf("failed to wake worker: %w", err)
+	}
+
+	c.mu.Lock()
+	delete(c.savedUntil, policy.ID)
+	c.mu.Unlock()
+
+	if c.dryRun || c.cfg.ColdStartTimeout <= 0 {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.cfg.ColdStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		replicas, err := c.executor.GetCurrentReplicas(waitCtx, policy.TargetType, policy.TargetID)
+		if err == nil && replicas >= result.TargetReplicas {
+			c.logger.Info("pipeline worker cold-started", "policy_id", policy.ID, "replicas", replicas)
+			return nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("worker not running within cold start timeout %s", c.cfg.ColdStartTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordSavings records the scaled-to-zero time since it was last recorded.
+func (c *Controller) recordSavings(ctx context.Context, policy *scaling.Policy, state *scaling.IdleState, now time.Time) {
+	if !c.cfg.EnableCostTracking || c.costs == nil {
+		return
+	}
+
+	c.mu.Lock()
+	from, ok := c.savedUntil[policy.ID]
+	if !ok && state.ScaledToZeroAt != nil {
+		from = *state.ScaledToZeroAt
+		ok = true
+	}
+	if ok {
+		c.savedUntil[policy.ID] = now
+	}
+	c.mu.Unlock()
+
+	if !ok || !now.After(from) {
+		return
+	}
+
+	if err := c.costs.RecordCostSavings(ctx, savingsFor(policy, from, now)); err != nil {
+		c.logger.Warn("failed to record cost savings", "policy_id", policy.ID, "error", err)
+	}
+}
+
+// savingsFor estimates the savings of a policy's worker being scaled to zero
+// from from to to, costed at the policy's maximum hourly cost when it has one.
+func savingsFor(policy *scaling.Policy, from, to time.Time) *scaling.CostSavings {
+	seconds := int64(to.Sub(from).Seconds())
+	savings := &scaling.CostSavings{
+		PolicyID:            policy.ID,
+		Date:                to.UTC().Truncate(24 * time.Hour),
+		ScaledToZeroSeconds: seconds,
+	}
+	if policy.MaxHourlyCost != nil {
+		hourlyCents := int(*policy.MaxHourlyCost * 100)
+		savings.HourlyCostCents = &hourlyCents
+		savings.EstimatedSavingsCents = int(float64(hourlyCents) * float64(seconds) / 3600)
+	}
+	return savings
+}
+
+// Ensure the repositories implement the controller's stores.
+var (
+	_ PolicyStore  = (*scaling.Repository)(nil)
+	_ CostRecorder = (idle.Repository)(nil)
+	_ Waker        = (*wake.Trigger)(nil)
+)
@@ -0,0 +1,63 @@
+package scaletozero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/config"
+	"github.com/janovincze/philotes/internal/scaling"
+)
+
+func TestController_decide(t *testing.T) {
+	c := &Controller{cfg: config.ScaleToZeroConfig{
+		DefaultIdleThreshold:   30 * time.Minute,
+		DefaultKeepAliveWindow: 5 * time.Minute,
+	}}
+	now := time.Now()
+	idleHour := now.Add(-time.Hour)
+	justWoken := now.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		state    *scaling.IdleState
+		activity Activity
+		want     action
+	}{
+		{"untracked", nil, Activity{}, actionNone},
+		{"idle", &scaling.IdleState{LastActivityAt: idleHour}, Activity{}, actionScaleToZero},
+		{"recently active", &scaling.IdleState{LastActivityAt: now.Add(-time.Minute)}, Activity{}, actionNone},
+		{"recent event", &scaling.IdleState{LastActivityAt: idleHour}, Activity{LastEventAt: now.Add(-time.Minute)}, actionNone},
+		{"buffered events", &scaling.IdleState{LastActivityAt: idleHour}, Activity{BufferDepth: 3}, actionNone},
+		{"keep-alive window", &scaling.IdleState{LastActivityAt: idleHour, LastWakeAt: &justWoken}, Activity{}, actionNone},
+		{"at zero without changes", &scaling.IdleState{LastActivityAt: idleHour, IsScaledToZero: true}, Activity{}, actionNone},
+		{"at zero with slot lag", &scaling.IdleState{LastActivityAt: idleHour, IsScaledToZero: true}, Activity{SlotLagBytes: 512}, actionColdStart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.decide(tt.state, tt.activity, now); got != tt.want {
+				t.Errorf("decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSavingsFor(t *testing.T) {
+	cost := 0.5
+	policy := &scaling.Policy{ID: uuid.New(), MaxHourlyCost: &cost}
+	to := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	savings := savingsFor(policy, to.Add(-2*time.Hour), to)
+	if savings.ScaledToZeroSeconds != 7200 || savings.EstimatedSavingsCents != 100 {
+		t.Errorf("savingsFor() = %+v, want 7200s saving 100 cents", savings)
+	}
+	if !savings.Date.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want the day the savings were recorded", savings.Date)
+	}
+
+	policy.MaxHourlyCost = nil
+	if savings := savingsFor(policy, to.Add(-time.Hour), to); savings.EstimatedSavingsCents != 0 || savings.HourlyCostCents != nil {
+		t.Errorf("savingsFor(no cost) = %+v, want time only", savings)
+	}
+}
@@ -0,0 +1,314 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janovincze/philotes/internal/scaling/cloudprovider"
+	"github.com/janovincze/philotes/internal/scaling/kubernetes"
+	"github.com/janovincze/philotes/internal/scaling/nodepool"
+)
+
+// countSpotNodes returns the number of active spot nodes of a pool.
+func (e *NodeExecutor) countSpotNodes(ctx context.Context, pool *nodepool.NodePool) (int, error) {
+	if !pool.SpotEnabled {
+		return 0, nil
+	}
+
+	nodes, err := e.poolRepo.ListNodesForPool(ctx, pool.ID, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	spotNodes := 0
+	for i := range nodes {
+		if nodes[i].IsSpot {
+			spotNodes++
+		}
+	}
+	return spotNodes, nil
+}
+
+// useSpot reports whether the next node of the pool should be a spot
+// instance: the pool opts in, the instance type supports spot, the pool stays
+// within its spot share and has not fallen back to on-demand.
+func (e *NodeExecutor) useSpot(pool *nodepool.NodePool, pricing *nodepool.InstanceTypePricing, spotNodes, totalNodes int) bool {
+	if pricing == nil || !pricing.SupportsSpot {
+		return false
+	}
+	if !pool.CanAddSpotNode(spotNodes, totalNodes) {
+		return false
+	}
+	return !e.spotFallbackActive(pool.ID, time.Now())
+}
+
+// recordSpotFailure counts a failed spot provisioning or a reclamation
+// against the pool.
+func (e *NodeExecutor) recordSpotFailure(poolID uuid.UUID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spotFailures[poolID] = append(e.spotFailures[poolID], time.Now())
+}
+
+// spotFallbackActive returns true if the pool had too many spot failures
+// within the failure window and should be provisioned on-demand.
+func (e *NodeExecutor) spotFallbackActive(poolID uuid.UUID, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var recent []time.Time
+	for _, t := range e.spotFailures[poolID] {
+		if now.Sub(t) < e.spotFailureWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) == 0 {
+		delete(e.spotFailures, poolID)
+		return false
+	}
+	e.spotFailures[poolID] = recent
+
+	return e.spotFailureThreshold > 0 && len(recent) >= e.spotFailureThreshold
+}
+
+// hourlyCost returns the hourly cost of a node from the instance type pricing.
+func hourlyCost(pricing *nodepool.InstanceTypePricing, spot bool) *float64 {
+	if pricing == nil {
+		return nil
+	}
+	if spot && pricing.SpotHourlyCost != nil {
+		cost := *pricing.SpotHourlyCost
+		return &cost
+	}
+	cost := pricing.HourlyCost
+	return &cost
+}
+
+// WatchSpotReclamations runs HandleSpotReclamations every interval until ctx
+// is done.
+func (e *NodeExecutor) WatchSpotReclamations(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.HandleSpotReclamations(ctx); err != nil {
+			e.logger.Error("failed to handle spot reclamations", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleSpotReclamations detects spot nodes reclaimed by their provider in
+// every spot-enabled pool, removes them and provisions replacement capacity.
+// Each reclamation is recorded as a spot_replace scaling operation.
+func (e *NodeExecutor) HandleSpotReclamations(ctx context.Context) error {
+	pools, err := e.poolRepo.ListPools(ctx, true)
+	if err != nil {
+		return fmt.Errorf("failed to list pools: %w", err)
+	}
+
+	for i := range pools {
+		pool := &pools[i]
+		if !pool.SpotEnabled {
+			continue
+		}
+		if err := e.handlePoolReclamations(ctx, pool); err != nil {
+			e.logger.Error("failed to handle spot reclamations for pool", "pool", pool.Name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// handlePoolReclamations replaces the reclaimed spot nodes of a pool.
+func (e *NodeExecutor) handlePoolReclamations(ctx context.Context, pool *nodepool.NodePool) error {
+	provider, ok := e.providers.Get(pool.Provider.String())
+	if !ok {
+		return fmt.Errorf("provider %s not registered", pool.Provider)
+	}
+
+	nodes, err := e.poolRepo.ListNodesForPool(ctx, pool.ID, true)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	reclaimed := e.findReclaimedNodes(ctx, provider, pool, nodes)
+	if len(reclaimed) == 0 {
+		return nil
+	}
+
+	count := len(nodes)
+	for i := range nodes {
+		node := &nodes[i]
+		reason, ok := reclaimed[node.ProviderID]
+		if !ok {
+			continue
+		}
+
+		e.logger.Warn("spot node reclaimed by provider",
+			"pool", pool.Name,
+			"node_id", node.ID,
+			"provider_id", node.ProviderID,
+			"reason", reason,
+		)
+		e.recordSpotFailure(pool.ID)
+		e.retireNode(ctx, provider, node, "spot instance reclaimed: "+reason)
+		count--
+
+		if err := e.replaceReclaimedNode(ctx, pool, node, reason, count); err != nil {
+			e.logger.Error("failed to replace reclaimed spot node", "pool", pool.Name, "node_id", node.ID, "error", err)
+		} else {
+			count++
+		}
+	}
+
+	return nil
+}
+
+// findReclaimedNodes returns the provider IDs of the pool's reclaimed spot
+// nodes mapped to the reclamation reason. Reclamation notices are used when
+// the provider publishes them; otherwise spot servers the provider reports
+// as gone are considered reclaimed.
+func (e *NodeExecutor) findReclaimedNodes(ctx context.Context, provider cloudprovider.NodeProvider, pool *nodepool.NodePool, nodes []nodepool.Node) map[string]string {
+	reclaimed := make(map[string]string)
+
+	if reclaimer, ok := provider.(cloudprovider.SpotReclaimer); ok {
+		notices, err := reclaimer.ListReclamations(ctx, pool.Labels)
+		if err != nil {
+			e.logger.Warn("failed to list reclamation notices", "pool", pool.Name, "error", err)
+		}
+		for _, notice := range notices {
+			reason := notice.Reason
+			if reason == "" {
+				reason = "reclamation notice"
+			}
+			reclaimed[notice.ServerID] = reason
+		}
+	}
+
+	for i := range nodes {
+		node := &nodes[i]
+		if !node.IsSpot || node.ProviderID == "" {
+			continue
+		}
+		if _, ok := reclaimed[node.ProviderID]; ok {
+			continue
+		}
+
+		server, err := provider.GetServer(ctx, node.ProviderID)
+		switch {
+		case errors.Is(err, cloudprovider.ErrServerNotFound):
+			reclaimed[node.ProviderID] = "server no longer exists"
+		case err != nil:
+			e.logger.Debug("failed to get server", "provider_id", node.ProviderID, "error", err)
+		case server.Status.IsTerminated():
+			reclaimed[node.ProviderID] = fmt.Sprintf("server %s", server.Status)
+		}
+	}
+
+	// Only keep spot nodes of this pool
+	spot := make(map[string]bool)
+	for i := range nodes {
+		if nodes[i].IsSpot {
+			spot[nodes[i].ProviderID] = true
+		}
+	}
+	for id := range reclaimed {
+		if !spot[id] {
+			delete(reclaimed, id)
+		}
+	}
+
+	return reclaimed
+}
+
+// retireNode drains a node, deletes its server and Kubernetes node if they
+// still exist, and marks it failed and deleted.
+func (e *NodeExecutor) retireNode(ctx context.Context, provider cloudprovider.NodeProvider, node *nodepool.Node, reason string) {
+	if e.drainer != nil && node.NodeName != "" {
+		drainOpts := kubernetes.DefaultDrainOptions()
+		drainOpts.Timeout = e.drainTimeout
+		drainOpts.GracePeriodSeconds = int64(e.drainGracePeriod.Seconds())
+
+		if err := e.drainer.DrainNode(ctx, node.NodeName, drainOpts); err != nil {
+			e.logger.Warn("failed to drain node", "node", node.NodeName, "error", err)
+		}
+	}
+
+	if err := provider.DeleteServer(ctx, node.ProviderID); err != nil && !errors.Is(err, cloudprovider.ErrServerNotFound) {
+		e.logger.Warn("failed to delete server", "provider_id", node.ProviderID, "error", err)
+	}
+
+	if e.k8sClient != nil && node.NodeName != "" {
+		if err := e.k8sClient.DeleteNode(ctx, node.NodeName); err != nil {
+			e.logger.Warn("failed to delete K8s node", "node", node.NodeName, "error", err)
+		}
+	}
+
+	if err := e.poolRepo.UpdateNodeStatus(ctx, node.ID, nodepool.NodeStatusFailed, reason); err != nil {
+		e.logger.Warn("failed to update node status", "error", err)
+	}
+	if err := e.poolRepo.SoftDeleteNode(ctx, node.ID); err != nil {
+		e.logger.Warn("failed to soft delete node", "error", err)
+	}
+}
+
+// replaceReclaimedNode records the reclamation of node as a scaling
+// operation and provisions a replacement for it.
+func (e *NodeExecutor) replaceReclaimedNode(ctx context.Context, pool *nodepool.NodePool, node *nodepool.Node, reason string, currentCount int) error {
+	op := &nodepool.ScalingOperation{
+		PoolID:        pool.ID,
+		Action:        nodepool.OperationActionSpotReplace,
+		PreviousCount: currentCount,
+		TargetCount:   currentCount + 1,
+		Status:        nodepool.OperationStatusInProgress,
+		Reason:        fmt.Sprintf("Spot node %s reclaimed by provider: %s", node.ProviderID, reason),
+		TriggeredBy:   "spot_reclamation",
+	}
+
+	op, err := e.poolRepo.CreateOperation(ctx, op)
+	if err != nil {
+		return fmt.Errorf("failed to create operation record: %w", err)
+	}
+
+	e.mu.Lock()
+	e.pendingOps[op.ID] = &PendingOperation{
+		PoolID:      pool.ID,
+		OperationID: op.ID,
+		Action:      op.Action,
+		TargetCount: op.TargetCount,
+		StartTime:   time.Now(),
+	}
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		delete(e.pendingOps, op.ID)
+		e.mu.Unlock()
+	}()
+
+	scalingErr := e.scaleUp(ctx, pool, currentCount, currentCount+1, op)
+
+	finalCount, _ := e.GetCurrentReplicas(ctx, TargetNodes, &pool.ID) //nolint:errcheck // best-effort count for status update
+	status, errMsg := nodepool.OperationStatusCompleted, ""
+	if scalingErr != nil {
+		status, errMsg = nodepool.OperationStatusFailed, scalingErr.Error()
+	}
+	if err := e.poolRepo.UpdateOperationStatus(ctx, op.ID, status, &finalCount, errMsg); err != nil {
+		e.logger.Error("failed to update operation status", "error", err)
+	}
+	if scalingErr == nil {
+		e.notifyCompleted(ctx, pool, op, finalCount)
+	}
+
+	return scalingErr
+}
@@ -145,7 +145,7 @@ type Policy struct {
 	TargetID        *uuid.UUID `json:"target_id,omitempty"`
 	MinReplicas     int        `json:"min_replicas"`
 	MaxReplicas     int        `json:"max_replicas"`
-	CooldownSeconds int        `json:"cooldown_seconds"`
+	CooldownSeconds *int       `json:"cooldown_seconds,omitempty"` // nil uses the configured default
 	MaxHourlyCost   *float64   `json:"max_hourly_cost,omitempty"`
 	ScaleToZero     bool       `json:"scale_to_zero"`
 	Enabled         bool       `json:"enabled"`
@@ -173,7 +173,7 @@ func (p *Policy) Validate() error {
 	if p.MinReplicas > p.MaxReplicas {
 		return fmt.Errorf("min_replicas (%d) cannot be greater than max_replicas (%d)", p.MinReplicas, p.MaxReplicas)
 	}
-	if p.CooldownSeconds < 0 {
+	if p.CooldownSeconds != nil && *p.CooldownSeconds < 0 {
 		return fmt.Errorf("cooldown_seconds must be >= 0")
 	}
 	if p.MaxHourlyCost != nil && *p.MaxHourlyCost < 0 {
@@ -207,9 +207,13 @@ func (p *Policy) Validate() error {
 	return nil
 }
 
-// CooldownDuration returns the cooldown as a time.Duration.
-func (p *Policy) CooldownDuration() time.Duration {
-	return time.Duration(p.CooldownSeconds) * time.Second
+// CooldownDuration returns the cooldown as a time.Duration, or
+// defaultCooldown when the policy does not set one.
+func (p *Policy) CooldownDuration(defaultCooldown time.Duration) time.Duration {
+	if p.CooldownSeconds == nil {
+		return defaultCooldown
+	}
+	return time.Duration(*p.CooldownSeconds) * time.Second
 }
 
 // ClampReplicas clamps the given replica count to policy limits.
@@ -324,6 +328,24 @@ func (s *State) IsInCooldown(cooldownDuration time.Duration) bool {
 	return time.Since(*s.LastScaleTime) < cooldownDuration
 }
 
+// CooldownRemaining returns how much of the cooldown is left, zero when the
+// policy is not in cooldown.
+func (s *State) CooldownRemaining(cooldownDuration time.Duration) time.Duration {
+	if !s.IsInCooldown(cooldownDuration) {
+		return 0
+	}
+	return cooldownDuration - time.Since(*s.LastScaleTime)
+}
+
+// RecordScaleCompleted moves the last scale time to completedAt if the
+// scaling finished after the decision that started it, so the cooldown runs
+// from the end of slow scaling operations.
+func (s *State) RecordScaleCompleted(completedAt time.Time) {
+	if s.LastScaleTime == nil || completedAt.After(*s.LastScaleTime) {
+		s.LastScaleTime = &completedAt
+	}
+}
+
 // Decision represents a scaling decision made by the evaluator.
 type Decision struct {
 	Policy            *Policy
@@ -0,0 +1,390 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+// AWSConfig holds AWS Secrets Manager configuration.
+//
+// Credentials come from the standard AWS environment: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, or AWS_ROLE_ARN and
+// AWS_WEB_IDENTITY_TOKEN_FILE as set for IAM roles for service accounts.
+type AWSConfig struct {
+	// Region is the AWS region of the secrets.
+	Region string
+
+	// Endpoint is the Secrets Manager endpoint URL. Empty uses the
+	// regional endpoint.
+	Endpoint string
+
+	// SecretIDs names the secrets holding each credential. Each is a JSON
+	// object with the same keys as the Vault secrets, such as "password".
+	SecretIDs vault.SecretPaths
+
+	// RefreshInterval is how often cached secrets are refreshed.
+	RefreshInterval time.Duration
+}
+
+// AWSSecretProvider retrieves secrets from AWS Secrets Manager. Secret
+// references (vault://name#key) name a secret and a key of its JSON value.
+type AWSSecretProvider struct {
+	httpClient      *http.Client
+	endpoint        string
+	stsEndpoint     string
+	region          string
+	ids             vault.SecretPaths
+	refreshInterval time.Duration
+	logger          *slog.Logger
+
+	mu    sync.RWMutex
+	cache map[string]cachedSecret // secret ID -> value
+
+	credMu sync.Mutex
+	creds  awsCredentials
+
+	cancelCtx context.Context
+	cancel    context.CancelFunc
+}
+
+// cachedSecret is a secret's JSON value and when it was read.
+type cachedSecret struct {
+	values  map[string]any
+	fetched time.Time
+}
+
+// NewAWSSecretProvider creates an AWS Secrets Manager provider, reads the
+// configured secrets and starts refreshing them.
+func NewAWSSecretProvider(ctx context.Context, cfg AWSConfig, logger *slog.Logger) (*AWSSecretProvider, error) {
+	p, err := newAWSSecretProvider(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Refresh(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to fetch initial secrets: %w", err)
+	}
+	p.StartRefreshLoop()
+
+	p.logger.Info("using aws secrets manager secret provider", "region", p.region)
+	return p, nil
+}
+
+// newAWSSecretProvider creates a provider without reading any secret.
+func newAWSSecretProvider(cfg AWSConfig, logger *slog.Logger) (*AWSSecretProvider, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("aws region is required")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 5 * time.Minute
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AWSSecretProvider{
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		stsEndpoint:     fmt.Sprintf("https://sts.%s.amazonaws.com", cfg.Region),
+		region:          cfg.Region,
+		ids:             cfg.SecretIDs,
+		refreshInterval: cfg.RefreshInterval,
+		logger:          logger.With("component", "aws-secrets"),
+		cache:           make(map[string]cachedSecret),
+		cancelCtx:       ctx,
+		cancel:          cancel,
+	}, nil
+}
+
+// GetDatabasePassword returns the buffer database password.
+func (p *AWSSecretProvider) GetDatabasePassword(ctx context.Context) (string, error) {
+	password, err := p.GetSecretValue(ctx, p.ids.DatabaseBuffer, vault.SecretKeyPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to get database password: %w", err)
+	}
+	return password, nil
+}
+
+// GetSourcePassword returns the source database password.
+func (p *AWSSecretProvider) GetSourcePassword(ctx context.Context) (string, error) {
+	password, err := p.GetSecretValue(ctx, p.ids.DatabaseSource, vault.SecretKeyPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to get source password: %w", err)
+	}
+	return password, nil
+}
+
+// GetStorageCredentials returns the object storage credentials.
+func (p *AWSSecretProvider) GetStorageCredentials(ctx context.Context) (accessKey, secretKey string, err error) {
+	accessKey, err = p.GetSecretValue(ctx, p.ids.StorageMinio, vault.SecretKeyAccessKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get storage credentials: %w", err)
+	}
+	secretKey, err = p.GetSecretValue(ctx, p.ids.StorageMinio, vault.SecretKeySecretKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get storage credentials: %w", err)
+	}
+	return accessKey, secretKey, nil
+}
+
+// GetSecretValue returns the value of key in the JSON value of the secret
+// named path. The secret is read again once it is older than the refresh
+// interval.
+func (p *AWSSecretProvider) GetSecretValue(ctx context.Context, path, key string) (string, error) {
+	p.mu.RLock()
+	cached, ok := p.cache[path]
+	p.mu.RUnlock()
+
+	if !ok || time.Since(cached.fetched) > p.refreshInterval {
+		var err error
+		if cached, err = p.fetch(ctx, path); err != nil {
+			return "", err
+		}
+	}
+
+	value, ok := cached.values[key]
+	if !ok {
+		return "", fmt.Errorf("key %s in secret %s: %w", key, path, vault.ErrSecretNotFound)
+	}
+	strValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("value for key %s in secret %s is not a string", key, path)
+	}
+	return strValue, nil
+}
+
+// Refresh reads the configured secrets and every secret read before again.
+func (p *AWSSecretProvider) Refresh(ctx context.Context) error {
+	p.logger.Debug("refreshing secrets from aws secrets manager")
+
+	ids := []string{p.ids.DatabaseBuffer, p.ids.DatabaseSource, p.ids.StorageMinio}
+	p.mu.RLock()
+	for id := range p.cache {
+		ids = append(ids, id)
+	}
+	p.mu.RUnlock()
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := p.fetch(ctx, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to refresh some secrets: %w", errors.Join(errs...))
+	}
+
+	p.logger.Debug("secrets refreshed successfully")
+	return nil
+}
+
+// StartRefreshLoop starts a background goroutine to periodically refresh secrets.
+func (p *AWSSecretProvider) StartRefreshLoop() {
+	go func() {
+		ticker := time.NewTicker(p.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.cancelCtx.Done():
+				p.logger.Info("stopping secret refresh loop")
+				return
+			case <-ticker.C:
+				if err := p.Refresh(p.cancelCtx); err != nil {
+					p.logger.Warn("failed to refresh secrets", "error", err)
+				}
+			}
+		}
+	}()
+
+	p.logger.Info("started secret refresh loop", "interval", p.refreshInterval)
+}
+
+// Close stops the refresh loop.
+func (p *AWSSecretProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// fetch reads a secret from Secrets Manager and caches its JSON value.
+func (p *AWSSecretProvider) fetch(ctx context.Context, id string) (cachedSecret, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return cachedSecret{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	resp, err := p.call(ctx, "secretsmanager.GetSecretValue", body)
+	if err != nil {
+		return cachedSecret{}, fmt.Errorf("failed to get secret %s: %w", id, err)
+	}
+
+	var out struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return cachedSecret{}, fmt.Errorf("failed to decode secret %s: %w", id, err)
+	}
+	if out.SecretString == nil {
+		return cachedSecret{}, fmt.Errorf("secret %s has no string value", id)
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &values); err != nil {
+		return cachedSecret{}, fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+
+	cached := cachedSecret{values: values, fetched: time.Now()}
+	p.mu.Lock()
+	p.cache[id] = cached
+	p.mu.Unlock()
+	return cached, nil
+}
+
+// call sends a signed Secrets Manager request and returns the response body.
+func (p *AWSSecretProvider) call(ctx context.Context, target string, body []byte) ([]byte, error) {
+	creds, err := p.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signV4(req, body, creds, p.region, "secretsmanager", time.Now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return data, nil
+	}
+
+	var apiErr struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(data, &apiErr)
+	if strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+		return nil, fmt.Errorf("%s: %w", apiErr.Message, vault.ErrSecretNotFound)
+	}
+	return nil, fmt.Errorf("secrets manager returned %d: %s %s", resp.StatusCode, apiErr.Type, apiErr.Message)
+}
+
+// awsCredentials are AWS credentials, which expire if they are temporary.
+type awsCredentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+// credentials returns the credentials of the environment: static keys, or
+// temporary ones for the web identity role, renewed before they expire.
+func (p *AWSSecretProvider) credentials(ctx context.Context) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN == "" || tokenFile == "" {
+		return awsCredentials{}, errors.New("no aws credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+
+	p.credMu.Lock()
+	defer p.credMu.Unlock()
+	if p.creds.AccessKeyID != "" && time.Until(p.creds.Expiration) > 5*time.Minute {
+		return p.creds, nil
+	}
+
+	creds, err := p.assumeRoleWithWebIdentity(ctx, roleARN, tokenFile)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	p.creds = creds
+	return creds, nil
+}
+
+// assumeRoleWithWebIdentity exchanges the web identity token for temporary
+// credentials of the role. The request is not signed; the token is the
+// proof of identity.
+func (p *AWSSecretProvider) assumeRoleWithWebIdentity(ctx context.Context, roleARN, tokenFile string) (awsCredentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {"philotes"},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.stsEndpoint+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume role: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, fmt.Errorf("failed to assume role: sts returned %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Credentials awsCredentials `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to decode sts response: %w", err)
+	}
+	if out.Credentials.AccessKeyID == "" {
+		return awsCredentials{}, errors.New("failed to assume role: sts returned no credentials")
+	}
+
+	p.logger.Debug("assumed web identity role", "role", roleARN, "expiration", out.Credentials.Expiration)
+	return out.Credentials, nil
+}
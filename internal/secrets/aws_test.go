@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case of the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+// fakeSecretsManager serves GetSecretValue from a map of secret strings.
+func fakeSecretsManager(t *testing.T, secrets map[string]string, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q", target)
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") {
+			t.Errorf("Authorization = %q, want a signature for secretsmanager in eu-west-1", auth)
+		}
+
+		var req struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		value, ok := secrets[req.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": req.SecretId, "SecretString": value})
+	}))
+}
+
+func TestAWSSecretProvider(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var calls atomic.Int32
+	server := fakeSecretsManager(t, map[string]string{
+		"philotes/database/buffer": `{"password":"buffer-pw"}`,
+		"philotes/database/source": `{"password":"source-pw"}`,
+		"philotes/storage/minio":   `{"access_key":"ak","secret_key":"sk"}`,
+		"philotes/api":             `{"token":"tok","port":8080}`,
+	}, &calls)
+	defer server.Close()
+
+	p, err := New(context.Background(), Config{
+		Backend: BackendAWS,
+		AWS: AWSConfig{
+			Region:   "eu-west-1",
+			Endpoint: server.URL,
+			SecretIDs: vault.SecretPaths{
+				DatabaseBuffer: "philotes/database/buffer",
+				DatabaseSource: "philotes/database/source",
+				StorageMinio:   "philotes/storage/minio",
+			},
+			RefreshInterval: time.Hour,
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+	ctx := context.Background()
+
+	if got, err := p.GetDatabasePassword(ctx); err != nil || got != "buffer-pw" {
+		t.Errorf("GetDatabasePassword() = %q, %v", got, err)
+	}
+	if got, err := p.GetSourcePassword(ctx); err != nil || got != "source-pw" {
+		t.Errorf("GetSourcePassword() = %q, %v", got, err)
+	}
+	if ak, sk, err := p.GetStorageCredentials(ctx); err != nil || ak != "ak" || sk != "sk" {
+		t.Errorf("GetStorageCredentials() = %q, %q, %v", ak, sk, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("secrets read %d times, want each configured secret read once and then cached", calls.Load())
+	}
+
+	// Secret references resolve through the provider
+	got, err := vault.NewResolver(p).Resolve(ctx, "vault://philotes/api#token")
+	if err != nil || got != "tok" {
+		t.Errorf("Resolve() = %q, %v", got, err)
+	}
+	if _, err := p.GetSecretValue(ctx, "philotes/api", "port"); err == nil {
+		t.Error("GetSecretValue() of a number error = nil, want an error")
+	}
+	if _, err := p.GetSecretValue(ctx, "philotes/api", "missing"); !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Errorf("GetSecretValue() of a missing key error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := p.GetSecretValue(ctx, "philotes/missing", "token"); !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Errorf("GetSecretValue() of a missing secret error = %v, want ErrSecretNotFound", err)
+	}
+
+	if err := p.Refresh(ctx); err != nil {
+		t.Errorf("Refresh() error = %v", err)
+	}
+}
+
+func TestAWSSecretProviderFailsWithoutSecrets(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var calls atomic.Int32
+	server := fakeSecretsManager(t, map[string]string{}, &calls)
+	defer server.Close()
+
+	_, err := NewAWSSecretProvider(context.Background(), AWSConfig{
+		Region:    "eu-west-1",
+		Endpoint:  server.URL,
+		SecretIDs: vault.SecretPaths{DatabaseBuffer: "philotes/database/buffer"},
+	}, nil)
+	if !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Errorf("NewAWSSecretProvider() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestAWSSecretProviderWebIdentity(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/philotes")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+
+	var assumed atomic.Int32
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assumed.Add(1)
+		_ = r.ParseForm()
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "jwt" || r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/philotes" {
+			t.Errorf("STS form = %v", r.Form)
+		}
+		expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>` +
+			`<AccessKeyId>AKID</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>session</SessionToken>` +
+			`<Expiration>` + expiration + `</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer sts.Close()
+
+	var calls atomic.Int32
+	server := fakeSecretsManager(t, map[string]string{"philotes/api": `{"token":"tok"}`}, &calls)
+	defer server.Close()
+
+	p, err := newAWSSecretProvider(AWSConfig{Region: "eu-west-1", Endpoint: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("newAWSSecretProvider() error = %v", err)
+	}
+	defer p.Close()
+	p.stsEndpoint = sts.URL
+
+	for _, path := range []string{"philotes/api", "philotes/missing"} {
+		_, _ = p.GetSecretValue(context.Background(), path, "token")
+	}
+	if got, err := p.GetSecretValue(context.Background(), "philotes/api", "token"); err != nil || got != "tok" {
+		t.Errorf("GetSecretValue() = %q, %v", got, err)
+	}
+	if assumed.Load() != 1 {
+		t.Errorf("role assumed %d times, want the temporary credentials reused", assumed.Load())
+	}
+}
+
+func TestNewUnknownBackend(t *testing.T) {
+	if _, err := New(context.Background(), Config{Backend: "gcp"}, nil); err == nil {
+		t.Fatal("New() error = nil, want an error for an unknown backend")
+	}
+	p, err := New(context.Background(), Config{Backend: BackendEnv}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := p.(*vault.EnvSecretProvider); !ok {
+		t.Errorf("New() = %T, want the environment provider", p)
+	}
+}
@@ -0,0 +1,89 @@
+// Package secrets provides the secret provider abstraction and selects its
+// backend: HashiCorp Vault, AWS Secrets Manager or environment variables.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/janovincze/philotes/internal/vault"
+)
+
+// Secret backends selectable by configuration.
+const (
+	// BackendVault reads secrets from HashiCorp Vault.
+	BackendVault = "vault"
+
+	// BackendAWS reads secrets from AWS Secrets Manager.
+	BackendAWS = "aws"
+
+	// BackendEnv reads secrets from environment variables.
+	BackendEnv = "env"
+)
+
+// Provider retrieves secrets from a secret backend. Secrets are cached and
+// refreshed by the provider, so callers may ask for them each time they are
+// used.
+type Provider interface {
+	// GetDatabasePassword returns the buffer database password
+	GetDatabasePassword(ctx context.Context) (string, error)
+
+	// GetSourcePassword returns the source database password
+	GetSourcePassword(ctx context.Context) (string, error)
+
+	// GetStorageCredentials returns the object storage access and secret keys
+	GetStorageCredentials(ctx context.Context) (accessKey, secretKey string, err error)
+
+	// GetSecretValue returns the value of key in the secret at path
+	GetSecretValue(ctx context.Context, path, key string) (string, error)
+
+	// Refresh refreshes all cached secrets
+	Refresh(ctx context.Context) error
+
+	// Close cleans up resources
+	Close() error
+}
+
+// Config selects and configures the secret backend.
+type Config struct {
+	// Backend is the secret backend (BackendVault, BackendAWS or
+	// BackendEnv). Empty uses environment variables.
+	Backend string
+
+	// Vault configures the Vault backend.
+	Vault vault.Config
+
+	// AWS configures the AWS Secrets Manager backend.
+	AWS AWSConfig
+}
+
+// New creates the provider of the configured backend. The Vault backend
+// falls back to environment variables as configured; the AWS backend fails
+// if its secrets cannot be read.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (Provider, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	switch cfg.Backend {
+	case BackendVault:
+		vaultCfg := cfg.Vault
+		vaultCfg.Enabled = true
+		return vault.NewSecretProvider(ctx, &vaultCfg, logger)
+	case BackendAWS:
+		return NewAWSSecretProvider(ctx, cfg.AWS, logger)
+	case "", BackendEnv:
+		logger.Info("using environment secrets")
+		return vault.NewEnvSecretProvider(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown secret backend %q", cfg.Backend)
+	}
+}
+
+// Ensure the backends implement Provider.
+var (
+	_ Provider = (*vault.VaultSecretProvider)(nil)
+	_ Provider = (*vault.EnvSecretProvider)(nil)
+	_ Provider = (*AWSSecretProvider)(nil)
+)
@@ -0,0 +1,158 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secret reference schemes.
+const (
+	// SchemeVault references a key of a secret in the secret provider,
+	// written as vault://path#key.
+	SchemeVault = "vault"
+
+	// SchemeEnv references an environment variable, written as env://NAME.
+	SchemeEnv = "env"
+)
+
+// ErrSecretNotFound is returned when a referenced secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Reference is a parsed secret reference.
+type Reference struct {
+	// Scheme is the backend the secret is read from.
+	Scheme string
+
+	// Path is the secret path (vault) or variable name (env).
+	Path string
+
+	// Key is the key within the secret; empty for env references.
+	Key string
+}
+
+// String returns the reference in its vault://path#key or env://NAME form.
+func (r Reference) String() string {
+	if r.Key == "" {
+		return r.Scheme + "://" + r.Path
+	}
+	return r.Scheme + "://" + r.Path + "#" + r.Key
+}
+
+// IsReference reports whether value looks like a secret reference rather
+// than a literal value.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SchemeVault+"://") || strings.HasPrefix(value, SchemeEnv+"://")
+}
+
+// ParseReference parses a secret reference.
+func ParseReference(value string) (Reference, error) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return Reference{}, fmt.Errorf("invalid secret reference %q: missing scheme", value)
+	}
+
+	switch scheme {
+	case SchemeVault:
+		path, key, ok := strings.Cut(rest, "#")
+		path = strings.Trim(path, "/")
+		if !ok || path == "" || key == "" {
+			return Reference{}, fmt.Errorf("invalid secret reference %q: expected vault://path#key", value)
+		}
+		return Reference{Scheme: scheme, Path: path, Key: key}, nil
+	case SchemeEnv:
+		if rest == "" || strings.ContainsAny(rest, "#/ ") {
+			return Reference{}, fmt.Errorf("invalid secret reference %q: expected env://NAME", value)
+		}
+		return Reference{Scheme: scheme, Path: rest}, nil
+	default:
+		return Reference{}, fmt.Errorf("invalid secret reference %q: unsupported scheme %q", value, scheme)
+	}
+}
+
+// Resolver resolves secret references through a SecretProvider. Values that
+// are not references are returned unchanged, so any credential field may hold
+// either a literal or a reference.
+type Resolver struct {
+	provider SecretProvider
+}
+
+// NewResolver creates a new Resolver backed by provider.
+func NewResolver(provider SecretProvider) *Resolver {
+	return &Resolver{provider: provider}
+}
+
+// Resolve returns the secret value referenced by value, or value itself if it
+// is not a reference. Vault references are cached and refreshed by the
+// provider, so Resolve is cheap enough to call each time a secret is used.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch ref.Scheme {
+	case SchemeEnv:
+		secret, ok := os.LookupEnv(ref.Path)
+		if !ok {
+			return "", fmt.Errorf("%s: %w", ref, ErrSecretNotFound)
+		}
+		return secret, nil
+	default:
+		secret, err := r.provider.GetSecretValue(ctx, ref.Path, ref.Key)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", ref, err)
+		}
+		return secret, nil
+	}
+}
+
+// ResolveConfig returns a copy of config with every string value that is a
+// secret reference replaced by the secret it references. Nested maps are
+// resolved recursively.
+func (r *Resolver) ResolveConfig(ctx context.Context, config map[string]any) (map[string]any, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	resolved := make(map[string]any, len(config))
+	for key, value := range config {
+		switch v := value.(type) {
+		case string:
+			secret, err := r.Resolve(ctx, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			resolved[key] = secret
+		case map[string]any:
+			nested, err := r.ResolveConfig(ctx, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%w", key, err)
+			}
+			resolved[key] = nested
+		default:
+			resolved[key] = value
+		}
+	}
+	return resolved, nil
+}
+
+// envSecretName returns the environment variable that holds a generic secret
+// when Vault is not in use, e.g. philotes/kafka#sasl_password becomes
+// PHILOTES_SECRET_PHILOTES_KAFKA_SASL_PASSWORD.
+func envSecretName(path, key string) string {
+	name := strings.ToUpper(path + "_" + key)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "PHILOTES_SECRET_" + name
+}
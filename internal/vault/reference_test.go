@@ -0,0 +1,228 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockProvider serves generic secrets from memory.
+type mockProvider struct {
+	EnvSecretProvider
+	secrets map[string]map[string]string // path -> key -> value
+	calls   int
+}
+
+func (p *mockProvider) GetSecretValue(_ context.Context, path, key string) (string, error) {
+	p.calls++
+	value, ok := p.secrets[path][key]
+	if !ok {
+		return "", fmt.Errorf("key %s in secret %s: %w", key, path, ErrSecretNotFound)
+	}
+	return value, nil
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Reference
+		wantErr bool
+	}{
+		{value: "vault://philotes/kafka#sasl_password", want: Reference{Scheme: SchemeVault, Path: "philotes/kafka", Key: "sasl_password"}},
+		{value: "vault:///tenants/acme/#api_key", want: Reference{Scheme: SchemeVault, Path: "tenants/acme", Key: "api_key"}},
+		{value: "env://KAFKA_PASSWORD", want: Reference{Scheme: SchemeEnv, Path: "KAFKA_PASSWORD"}},
+		{value: "vault://philotes/kafka", wantErr: true},
+		{value: "vault://#key", wantErr: true},
+		{value: "env://", wantErr: true},
+		{value: "aws://secret#key", wantErr: true},
+		{value: "plain-password", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseReference(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	provider := &mockProvider{secrets: map[string]map[string]string{
+		"philotes/kafka": {"sasl_password": "kafka-secret"},
+	}}
+	resolver := NewResolver(provider)
+	ctx := context.Background()
+
+	got, err := resolver.Resolve(ctx, "vault://philotes/kafka#sasl_password")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got != "kafka-secret" {
+		t.Errorf("Resolve() = %q, want kafka-secret", got)
+	}
+
+	// Literal values are returned unchanged without touching the provider
+	calls := provider.calls
+	if got, err := resolver.Resolve(ctx, "literal"); err != nil || got != "literal" {
+		t.Errorf("Resolve(literal) = %q, %v", got, err)
+	}
+	if provider.calls != calls {
+		t.Error("expected literal value not to be looked up")
+	}
+
+	t.Setenv("PHILOTES_TEST_WEBHOOK_TOKEN", "env-secret")
+	if got, err := resolver.Resolve(ctx, "env://PHILOTES_TEST_WEBHOOK_TOKEN"); err != nil || got != "env-secret" {
+		t.Errorf("Resolve(env) = %q, %v", got, err)
+	}
+
+	_, err = resolver.Resolve(ctx, "vault://philotes/kafka#missing")
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Resolve(missing) error = %v, want ErrSecretNotFound", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "vault://philotes/kafka#missing") {
+		t.Errorf("expected error to name the reference, got %v", err)
+	}
+}
+
+func TestResolver_ResolveConfig(t *testing.T) {
+	provider := &mockProvider{secrets: map[string]map[string]string{
+		"philotes/smtp": {"password": "smtp-secret"},
+	}}
+	resolver := NewResolver(provider)
+
+	config := map[string]any{
+		"smtp_host": "smtp.example.com",
+		"smtp_port": float64(587),
+		"auth": map[string]any{
+			"password": "vault://philotes/smtp#password",
+		},
+	}
+
+	resolved, err := resolver.ResolveConfig(context.Background(), config)
+	if err != nil {
+		t.Fatalf("ResolveConfig() error = %v", err)
+	}
+	if got := resolved["auth"].(map[string]any)["password"]; got != "smtp-secret" {
+		t.Errorf("resolved password = %v, want smtp-secret", got)
+	}
+	if resolved["smtp_port"] != float64(587) || resolved["smtp_host"] != "smtp.example.com" {
+		t.Errorf("non-secret values changed: %v", resolved)
+	}
+	if got := config["auth"].(map[string]any)["password"]; got != "vault://philotes/smtp#password" {
+		t.Error("expected the original config to be left unchanged")
+	}
+
+	config["auth"].(map[string]any)["password"] = "vault://philotes/smtp#missing"
+	if _, err := resolver.ResolveConfig(context.Background(), config); err == nil ||
+		!strings.HasPrefix(err.Error(), "auth.password:") {
+		t.Errorf("ResolveConfig(missing) error = %v, want error naming auth.password", err)
+	}
+}
+
+func TestEnvSecretProvider_GetSecretValue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	provider := NewEnvSecretProvider(logger)
+
+	t.Setenv("PHILOTES_SECRET_PHILOTES_KAFKA_SASL_PASSWORD", "kafka-secret")
+
+	got, err := provider.GetSecretValue(context.Background(), "philotes/kafka", "sasl_password")
+	if err != nil {
+		t.Fatalf("GetSecretValue() error = %v", err)
+	}
+	if got != "kafka-secret" {
+		t.Errorf("GetSecretValue() = %q, want kafka-secret", got)
+	}
+
+	if _, err := provider.GetSecretValue(context.Background(), "philotes/kafka", "other"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecretValue(missing) error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestVaultSecretProvider_GetSecretValue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	callCount := 0
+	password := "first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if r.URL.Path != "/v1/secret/data/philotes/kafka" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resp := map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{
+					"sasl_password": password,
+				},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		Enabled:         true,
+		Address:         server.URL,
+		AuthMethod:      AuthMethodToken,
+		Token:           "test-token",
+		SecretMountPath: "secret",
+	}
+
+	client, err := NewClient(cfg, logger)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	provider := NewVaultSecretProvider(client, SecretPaths{}, time.Hour, logger)
+	defer provider.Close()
+
+	ctx := context.Background()
+	resolver := NewResolver(provider)
+
+	for i := 0; i < 2; i++ {
+		got, err := resolver.Resolve(ctx, "vault://philotes/kafka#sasl_password")
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if got != "first" {
+			t.Errorf("Resolve() = %q, want first", got)
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("expected 1 vault call with caching, got %d", callCount)
+	}
+
+	// Refresh picks up rotated values for referenced secrets
+	password = "rotated"
+	_ = provider.Refresh(ctx)
+
+	got, err := resolver.Resolve(ctx, "vault://philotes/kafka#sasl_password")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got != "rotated" {
+		t.Errorf("Resolve() after refresh = %q, want rotated", got)
+	}
+
+	if _, err := resolver.Resolve(ctx, "vault://philotes/kafka#missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Resolve(missing key) error = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,6 +21,9 @@ type SecretProvider interface {
 	// GetStorageCredentials returns MinIO/S3 access and secret keys
 	GetStorageCredentials(ctx context.Context) (accessKey, secretKey string, err error)
 
+	// GetSecretValue returns the value of key in the secret at path
+	GetSecretValue(ctx context.Context, path, key string) (string, error)
+
 	// Refresh refreshes all cached secrets
 	Refresh(ctx context.Context) error
 
@@ -44,6 +48,7 @@ type secretCache struct {
 	sourcePassword   string
 	storageAccessKey string
 	storageSecretKey string
+	values           map[string]string // path#key -> value
 	lastRefresh      time.Time
 }
 
@@ -156,6 +161,61 @@ func (p *VaultSecretProvider) GetStorageCredentials(ctx context.Context) (access
 	return ak, sk, nil
 }
 
+// GetSecretValue returns the value of key in the secret at path.
+func (p *VaultSecretProvider) GetSecretValue(ctx context.Context, path, key string) (string, error) {
+	cacheKey := path + "#" + key
+
+	// Read cache under read lock
+	p.mu.RLock()
+	cachedValue, ok := p.cache.values[cacheKey]
+	needsRefresh := p.needsRefresh()
+	p.mu.RUnlock()
+
+	// Return cached value if valid
+	if ok && !needsRefresh {
+		return cachedValue, nil
+	}
+
+	// Fetch from Vault
+	value, err := p.getSecretValue(ctx, path, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Update cache under write lock
+	p.mu.Lock()
+	if p.cache.values == nil {
+		p.cache.values = make(map[string]string)
+	}
+	p.cache.values[cacheKey] = value
+	if needsRefresh {
+		p.cache.lastRefresh = time.Now()
+	}
+	p.mu.Unlock()
+
+	return value, nil
+}
+
+// getSecretValue fetches a single key from Vault.
+func (p *VaultSecretProvider) getSecretValue(ctx context.Context, path, key string) (string, error) {
+	data, err := p.client.GetSecret(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", path, err)
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s in secret %s: %w", key, path, ErrSecretNotFound)
+	}
+
+	strValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("value for key %s in secret %s is not a string", key, path)
+	}
+
+	return strValue, nil
+}
+
 // Refresh refreshes all cached secrets.
 func (p *VaultSecretProvider) Refresh(ctx context.Context) error {
 	p.logger.Debug("refreshing secrets from vault")
@@ -194,6 +254,25 @@ func (p *VaultSecretProvider) Refresh(ctx context.Context) error {
 		p.mu.Unlock()
 	}
 
+	// Refresh generic secrets that have been referenced
+	p.mu.RLock()
+	cacheKeys := make([]string, 0, len(p.cache.values))
+	for cacheKey := range p.cache.values {
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	p.mu.RUnlock()
+
+	for _, cacheKey := range cacheKeys {
+		path, key, _ := strings.Cut(cacheKey, "#")
+		if value, err := p.getSecretValue(ctx, path, key); err != nil {
+			errs = append(errs, err)
+		} else {
+			p.mu.Lock()
+			p.cache.values[cacheKey] = value
+			p.mu.Unlock()
+		}
+	}
+
 	p.mu.Lock()
 	p.cache.lastRefresh = time.Now()
 	p.mu.Unlock()
@@ -285,6 +364,17 @@ func (p *EnvSecretProvider) GetStorageCredentials(_ context.Context) (accessKey,
 	return accessKey, secretKey, nil
 }
 
+// GetSecretValue returns a generic secret from the environment variable
+// derived from its path and key (see envSecretName).
+func (p *EnvSecretProvider) GetSecretValue(_ context.Context, path, key string) (string, error) {
+	name := envSecretName(path, key)
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%s not set: %w", name, ErrSecretNotFound)
+	}
+	return value, nil
+}
+
 // Refresh is a no-op for environment variables.
 func (p *EnvSecretProvider) Refresh(_ context.Context) error {
 	p.logger.Debug("refresh called on env provider (no-op)")
@@ -1,7 +1,7 @@
 "use client"
 
 import { useRouter } from "next/navigation"
-import { Cloud, Server, Globe, Zap, Shield } from "lucide-react"
+import { Cloud, Server, Globe, Zap, Shield, Droplet } from "lucide-react"
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card"
 import { Button } from "@/components/ui/button"
 import { Skeleton } from "@/components/ui/skeleton"
@@ -15,6 +15,7 @@ const providerIcons: Record<string, React.ReactNode> = {
   ovh: <Globe className="h-8 w-8" />,
   exoscale: <Shield className="h-8 w-8" />,
   contabo: <Zap className="h-8 w-8" />,
+  digitalocean: <Droplet className="h-8 w-8" />,
 }
 
 export default function InstallPage() {
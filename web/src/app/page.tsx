@@ -33,6 +33,7 @@ function PipelineStatusBadge({ status }: { status: PipelineStatus }) {
   const variants: Record<PipelineStatus, "default" | "secondary" | "destructive" | "outline"> = {
     running: "default",
     starting: "secondary",
+    paused: "secondary",
     stopping: "secondary",
     stopped: "outline",
     error: "destructive",
@@ -349,6 +349,35 @@ function renderProviderFields(
         </div>
       )
 
+    case "digitalocean":
+      return (
+        <div className="space-y-3">
+          <div>
+            <Label htmlFor="digitalocean_token">API Token</Label>
+            <Input
+              id="digitalocean_token"
+              type={inputType}
+              placeholder="Enter your DigitalOcean API token"
+              value={credentials.digitalocean_token || ""}
+              onChange={(e) => updateCredential("digitalocean_token", e.target.value)}
+              required
+            />
+            <p className="text-xs text-muted-foreground mt-1">
+              Generate a token with read and write scopes at{" "}
+              <a
+                href="https://cloud.digitalocean.com/account/api/tokens"
+                target="_blank"
+                rel="noopener noreferrer"
+                className="text-primary hover:underline"
+              >
+                DigitalOcean Control Panel
+              </a>
+              {" → API → Tokens"}
+            </p>
+          </div>
+        </div>
+      )
+
     default:
       return (
         <p className="text-muted-foreground">
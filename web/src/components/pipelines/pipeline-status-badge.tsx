@@ -13,6 +13,7 @@ export function PipelineStatusBadge({ status, className }: PipelineStatusBadgePr
   const variants: Record<PipelineStatus, "default" | "secondary" | "destructive" | "outline"> = {
     running: "default",
     starting: "secondary",
+    paused: "secondary",
     stopping: "secondary",
     stopped: "outline",
     error: "destructive",
@@ -27,6 +28,7 @@ export function PipelineStatusBadge({ status, className }: PipelineStatusBadgePr
           "h-2 w-2 rounded-full",
           status === "running" && "bg-green-500",
           status === "starting" && "bg-yellow-500",
+          status === "paused" && "bg-blue-500",
           status === "stopping" && "bg-yellow-500",
           status === "stopped" && "bg-gray-400",
           status === "error" && "bg-red-500",
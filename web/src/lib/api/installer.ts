@@ -16,7 +16,10 @@ import type {
   CleanupResourcesResponse,
   CreatedResource,
   RetryInfo,
+  DeploymentPreview,
+  DeploymentPreviewResponse,
   DeploymentLogMessage,
+  DestroyDeploymentInput,
 } from "./types"
 
 const BASE_PATH = "/api/v1/installer"
@@ -46,6 +49,16 @@ export const installerApi = {
       .then((res) => res.estimate)
   },
 
+  estimateCost(
+    provider: string,
+    region: string,
+    size: "small" | "medium" | "large"
+  ): Promise<CostEstimate> {
+    return apiClient
+      .post<CostEstimateResponse>(`${BASE_PATH}/estimate`, { provider, region, size })
+      .then((res) => res.estimate)
+  },
+
   // Deployment endpoints
   listDeployments(): Promise<Deployment[]> {
     return apiClient
@@ -92,6 +105,22 @@ export const installerApi = {
     return apiClient.post(`${BASE_PATH}/deployments/${deploymentId}/retry`)
   },
 
+  previewDeployment(deploymentId: string): Promise<DeploymentPreview> {
+    return apiClient
+      .post<DeploymentPreviewResponse>(`${BASE_PATH}/deployments/${deploymentId}/preview`)
+      .then((res) => res.preview)
+  },
+
+  destroyDeployment(deploymentId: string, input: DestroyDeploymentInput): Promise<{ message: string; deployment_id: string }> {
+    return apiClient.post(`${BASE_PATH}/deployments/${deploymentId}/destroy`, input)
+  },
+
+  updateDeleteProtection(deploymentId: string, enabled: boolean): Promise<Deployment> {
+    return apiClient
+      .put<DeploymentResponse>(`${BASE_PATH}/deployments/${deploymentId}/delete-protection`, { enabled })
+      .then((res) => res.deployment)
+  },
+
   getCleanupResources(deploymentId: string): Promise<CreatedResource[]> {
     return apiClient
       .get<CleanupResourcesResponse>(`${BASE_PATH}/deployments/${deploymentId}/cleanup-preview`)
@@ -110,7 +139,7 @@ export function createDeploymentLogsWebSocket(
   onError?: (error: Event) => void,
   onClose?: () => void
 ): WebSocket {
-  const wsUrl = getWebSocketUrl(`${BASE_PATH}/deployments/${deploymentId}/logs/stream`)
+  const wsUrl = getWebSocketUrl(`${BASE_PATH}/deployments/${deploymentId}/logs/ws`)
   const ws = new WebSocket(wsUrl)
 
   ws.onmessage = (event) => {
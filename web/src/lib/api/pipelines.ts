@@ -1,5 +1,5 @@
 import { apiClient } from "./client"
-import type { Pipeline, CreatePipelineInput, TableMapping } from "./types"
+import type { Pipeline, CreatePipelineInput, TableMapping, PipelineBufferStats } from "./types"
 
 export const pipelinesApi = {
   /**
@@ -51,6 +51,20 @@ export const pipelinesApi = {
     return apiClient.post<Pipeline>(`/api/v1/pipelines/${id}/stop`)
   },
 
+  /**
+   * Pause a running pipeline
+   */
+  pause(id: string): Promise<Pipeline> {
+    return apiClient.post<Pipeline>(`/api/v1/pipelines/${id}/pause`)
+  },
+
+  /**
+   * Resume a paused pipeline
+   */
+  resume(id: string): Promise<Pipeline> {
+    return apiClient.post<Pipeline>(`/api/v1/pipelines/${id}/resume`)
+  },
+
   /**
    * Get pipeline status
    */
@@ -58,6 +72,13 @@ export const pipelinesApi = {
     return apiClient.get<Pipeline>(`/api/v1/pipelines/${id}/status`)
   },
 
+  /**
+   * Get buffer retention and cleanup stats
+   */
+  getBufferStats(id: string): Promise<PipelineBufferStats> {
+    return apiClient.get<PipelineBufferStats>(`/api/v1/pipelines/${id}/buffer`)
+  },
+
   /**
    * Add table mapping to pipeline
    */
@@ -1,5 +1,13 @@
 import { apiClient } from "./client"
-import type { Source, CreateSourceInput, TableDiscoveryResponse, ConnectionTestResult } from "./types"
+import type {
+  Source,
+  CreateSourceInput,
+  TableDiscoveryResponse,
+  SchemaDiscoveryResponse,
+  ConnectionTestResult,
+  SourceValidationResult,
+  SourceHealth,
+} from "./types"
 
 export const sourcesApi = {
   /**
@@ -46,6 +54,27 @@ export const sourcesApi = {
     )
   },
 
+  /**
+   * Check that the source is ready to replicate the given tables
+   */
+  validate(id: string, tables?: string[]): Promise<SourceValidationResult> {
+    return apiClient.post<SourceValidationResult>(`/api/v1/sources/${id}/validate`, { tables })
+  },
+
+  /**
+   * Check that the source database is reachable
+   */
+  health(id: string): Promise<SourceHealth> {
+    return apiClient.get<SourceHealth>(`/api/v1/sources/${id}/health`)
+  },
+
+  /**
+   * Discover schemas from source
+   */
+  discoverSchemas(id: string): Promise<SchemaDiscoveryResponse> {
+    return apiClient.get<SchemaDiscoveryResponse>(`/api/v1/sources/${id}/schemas`)
+  },
+
   /**
    * Discover tables from source
    */
@@ -1,7 +1,7 @@
 // API Response Types
 
 export type SourceStatus = "inactive" | "active" | "error"
-export type PipelineStatus = "stopped" | "starting" | "running" | "stopping" | "error"
+export type PipelineStatus = "stopped" | "starting" | "running" | "paused" | "stopping" | "error"
 export type HealthStatus = "healthy" | "unhealthy" | "degraded" | "unknown"
 
 export interface Source {
@@ -39,15 +39,32 @@ export interface ColumnInfo {
   default?: string
 }
 
+export type ReplicaIdentity = "default" | "full" | "index" | "nothing"
+
 export interface TableInfo {
   schema: string
   name: string
   columns: ColumnInfo[]
+  replica_identity: ReplicaIdentity
+  cdc_ready: boolean
+  cdc_problem?: string
 }
 
 export interface TableDiscoveryResponse {
   tables: TableInfo[]
   count: number
+  discovered_at: string
+}
+
+export interface SchemaInfo {
+  name: string
+  table_count: number
+}
+
+export interface SchemaDiscoveryResponse {
+  schemas: SchemaInfo[]
+  count: number
+  discovered_at: string
 }
 
 export interface ConnectionTestResult {
@@ -58,6 +75,20 @@ export interface ConnectionTestResult {
   error_detail?: string
 }
 
+export interface SourceValidationResult {
+  valid: boolean
+  errors?: { field: string; message: string }[]
+}
+
+export interface SourceHealth {
+  source_id: string
+  status: HealthStatus
+  message: string
+  latency_ms?: number
+  failure?: { code: string; message: string; remediation: string }
+  checked_at: string
+}
+
 export interface TableMapping {
   id: string
   source_table: string
@@ -80,6 +111,19 @@ export interface Pipeline {
   stopped_at?: string
 }
 
+// Buffer state recorded by the pipeline's worker after its last cleanup run
+export interface PipelineBufferStats {
+  pipeline_id: string
+  reported: boolean
+  unprocessed_events: number
+  processed_events: number
+  oldest_unprocessed_at?: string
+  oldest_unprocessed_age_seconds: number
+  last_cleanup_at?: string
+  last_cleanup_deleted: number
+  updated_at?: string
+}
+
 export interface CreateTableMappingInput {
   schema?: string
   table: string
@@ -295,6 +339,8 @@ export type DeploymentStatus =
   | "completed"
   | "failed"
   | "canceled"
+  | "destroying"
+  | "destroyed"
 
 export type DeploymentSize = "small" | "medium" | "large"
 
@@ -329,7 +375,10 @@ export interface Provider {
   oauth_supported: boolean
 }
 
+export type DeploymentMode = "provision" | "existing-cluster"
+
 export interface DeploymentConfig {
+  mode?: DeploymentMode
   domain?: string
   ssh_public_key?: string
   chart_version?: string
@@ -354,6 +403,7 @@ export interface Deployment {
   size: DeploymentSize
   status: DeploymentStatus
   environment: string
+  delete_protection: boolean
   config?: DeploymentConfig
   outputs?: DeploymentOutput
   pulumi_stack_name?: string
@@ -375,6 +425,7 @@ export interface DeploymentLog {
 
 export interface CostEstimate {
   provider: string
+  region?: string
   size: string
   control_plane: number
   workers: number
@@ -405,13 +456,19 @@ export interface ProviderCredentials {
   contabo_client_secret?: string
   contabo_api_user?: string
   contabo_api_password?: string
+  // DigitalOcean
+  digitalocean_token?: string
+  // Existing cluster
+  kubeconfig?: string
 }
 
 export interface CreateDeploymentInput {
   name: string
-  provider: string
-  region: string
-  size: DeploymentSize
+  // An existing cluster needs credentials.kubeconfig instead of a provider, region and size
+  mode?: DeploymentMode
+  provider?: string
+  region?: string
+  size?: DeploymentSize
   environment?: string
   domain?: string
   ssh_public_key?: string
@@ -419,6 +476,14 @@ export interface CreateDeploymentInput {
   worker_count?: number
   storage_size_gb?: number
   credentials?: ProviderCredentials
+  delete_protection?: boolean
+}
+
+export interface DestroyDeploymentInput {
+  // Must be "<deployment name>/<stack name>"
+  confirmation: string
+  // Required to destroy a production deployment
+  override_production?: boolean
 }
 
 // Installer API Responses
@@ -513,6 +578,25 @@ export interface CleanupResourcesResponse {
   count: number
 }
 
+export interface ResourceChange {
+  op: "create" | "update" | "replace" | "delete" | "import"
+  type: string
+  name: string
+  urn: string
+  step: string
+  diffs?: string[]
+}
+
+export interface DeploymentPreview {
+  deployment_id: string
+  changes: ResourceChange[]
+  summary: Record<string, number>
+}
+
+export interface DeploymentPreviewResponse {
+  preview: DeploymentPreview
+}
+
 export interface RetryInfo {
   can_retry: boolean
   failed_step?: DeploymentStep
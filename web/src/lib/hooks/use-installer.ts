@@ -192,6 +192,8 @@ export function useDeploymentLogsStream(deploymentId: string) {
             setStatus(message.status || null)
             break
           case "connected":
+            // The server replays the history after connecting
+            setLogs([])
             setConnected(true)
             break
           case "progress":
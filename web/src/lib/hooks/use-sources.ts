@@ -57,6 +57,14 @@ export function useTestSourceConnection() {
   })
 }
 
+export function useDiscoverSchemas(sourceId: string) {
+  return useQuery({
+    queryKey: ["sources", sourceId, "schemas"],
+    queryFn: () => sourcesApi.discoverSchemas(sourceId),
+    enabled: !!sourceId,
+  })
+}
+
 export function useDiscoverTables(sourceId: string, schema?: string) {
   return useQuery({
     queryKey: ["sources", sourceId, "tables", schema],